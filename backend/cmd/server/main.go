@@ -19,6 +19,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/handler"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/repository"
 	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/setup"
 	"github.com/Wei-Shaw/sub2api/internal/web"
@@ -61,6 +62,7 @@ func main() {
 	// Parse command line flags
 	setupMode := flag.Bool("setup", false, "Run setup wizard in CLI mode")
 	showVersion := flag.Bool("version", false, "Show version information")
+	encryptCredentials := flag.Bool("encrypt-credentials", false, "Encrypt plaintext account credentials in the database and exit")
 	flag.Parse()
 
 	if *showVersion {
@@ -76,6 +78,12 @@ func main() {
 		return
 	}
 
+	// One-off migration: encrypt existing plaintext account credentials
+	if *encryptCredentials {
+		runEncryptCredentials()
+		return
+	}
+
 	// Check if setup is needed
 	if setup.NeedsSetup() {
 		// Check if auto-setup is enabled (for Docker deployment)
@@ -128,6 +136,28 @@ func runSetupServer() {
 	}
 }
 
+// runEncryptCredentials 将存量账号凭证中的明文敏感字段加密落库。
+// 需先配置 security.credential_encryption.enabled=true 与 master_key。
+func runEncryptCredentials() {
+	cfg, err := config.LoadForBootstrap()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	client, _, err := repository.InitEnt(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	updated, err := repository.EncryptAccountCredentials(ctx, client, cfg)
+	if err != nil {
+		log.Fatalf("Encrypt credentials failed after %d accounts: %v", updated, err)
+	}
+	log.Printf("Encrypted credentials for %d accounts", updated)
+}
+
 func runMainServer() {
 	cfg, err := config.LoadForBootstrap()
 	if err != nil {
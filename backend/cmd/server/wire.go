@@ -82,12 +82,20 @@ func provideCleanup(
 	tokenRefresh *service.TokenRefreshService,
 	accountExpiry *service.AccountExpiryService,
 	subscriptionExpiry *service.SubscriptionExpiryService,
+	childTokenExpiry *service.ChildTokenExpiryService,
 	usageCleanup *service.UsageCleanupService,
 	idempotencyCleanup *service.IdempotencyCleanupService,
+	usageAnomaly *service.UsageAnomalyService,
+	errorPassthrough *service.ErrorPassthroughService,
+	regionUpstream *service.RegionAwareHTTPUpstream,
+	proxyUpstream *service.ProxyFailoverHTTPUpstream,
 	pricing *service.PricingService,
 	emailQueue *service.EmailQueueService,
 	billingCache *service.BillingCacheService,
+	billingDBHealth *service.BillingDBHealthService,
 	usageRecordWorkerPool *service.UsageRecordWorkerPool,
+	usageRecordJournalReplayer *service.UsageRecordJournalReplayer,
+	usageEventExporter *service.UsageEventExporter,
 	subscriptionService *service.SubscriptionService,
 	oauth *service.OAuthService,
 	openaiOAuth *service.OpenAIOAuthService,
@@ -98,6 +106,8 @@ func provideCleanup(
 	backupSvc *service.BackupService,
 	paymentOrderExpiry *service.PaymentOrderExpiryService,
 	channelMonitorRunner *service.ChannelMonitorRunner,
+	trafficReplay *service.TrafficReplayService,
+	messageBatch *service.MessageBatchService,
 ) func() {
 	return func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -164,6 +174,30 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"UsageAnomalyService", func() error {
+				if usageAnomaly != nil {
+					usageAnomaly.Stop()
+				}
+				return nil
+			}},
+			{"ErrorPassthroughService", func() error {
+				if errorPassthrough != nil {
+					errorPassthrough.Stop()
+				}
+				return nil
+			}},
+			{"RegionAwareHTTPUpstream", func() error {
+				if regionUpstream != nil {
+					regionUpstream.Stop()
+				}
+				return nil
+			}},
+			{"ProxyFailoverHTTPUpstream", func() error {
+				if proxyUpstream != nil {
+					proxyUpstream.Stop()
+				}
+				return nil
+			}},
 			{"TokenRefreshService", func() error {
 				tokenRefresh.Stop()
 				return nil
@@ -176,6 +210,10 @@ func provideCleanup(
 				subscriptionExpiry.Stop()
 				return nil
 			}},
+			{"ChildTokenExpiryService", func() error {
+				childTokenExpiry.Stop()
+				return nil
+			}},
 			{"SubscriptionService", func() error {
 				if subscriptionService != nil {
 					subscriptionService.Stop()
@@ -194,12 +232,24 @@ func provideCleanup(
 				billingCache.Stop()
 				return nil
 			}},
+			{"BillingDBHealthService", func() error {
+				billingDBHealth.Stop()
+				return nil
+			}},
 			{"UsageRecordWorkerPool", func() error {
 				if usageRecordWorkerPool != nil {
 					usageRecordWorkerPool.Stop()
 				}
 				return nil
 			}},
+			{"UsageRecordJournalReplayer", func() error {
+				usageRecordJournalReplayer.Stop()
+				return nil
+			}},
+			{"UsageEventExporter", func() error {
+				usageEventExporter.Stop()
+				return nil
+			}},
 			{"OAuthService", func() error {
 				oauth.Stop()
 				return nil
@@ -246,6 +296,18 @@ func provideCleanup(
 				}
 				return nil
 			}},
+			{"TrafficReplayService", func() error {
+				if trafficReplay != nil {
+					trafficReplay.Stop()
+				}
+				return nil
+			}},
+			{"MessageBatchService", func() error {
+				if messageBatch != nil {
+					messageBatch.Stop()
+				}
+				return nil
+			}},
 		}
 
 		infraSteps := []cleanupStep{
@@ -41,10 +41,12 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 	)
 	accountExpirySvc := service.NewAccountExpiryService(nil, time.Second)
 	subscriptionExpirySvc := service.NewSubscriptionExpiryService(nil, time.Second)
+	childTokenExpirySvc := service.NewChildTokenExpiryService(nil, time.Second)
 	pricingSvc := service.NewPricingService(cfg, nil)
 	emailQueueSvc := service.NewEmailQueueService(nil, 1)
 	billingCacheSvc := service.NewBillingCacheService(nil, nil, nil, nil, nil, nil, cfg)
 	idempotencyCleanupSvc := service.NewIdempotencyCleanupService(nil, cfg)
+	usageAnomalySvc := service.NewUsageAnomalyService(nil, nil, nil, cfg)
 	schedulerSnapshotSvc := service.NewSchedulerSnapshotService(nil, nil, nil, nil, cfg)
 	opsSystemLogSinkSvc := service.NewOpsSystemLogSink(nil)
 
@@ -61,12 +63,20 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		tokenRefreshSvc,
 		accountExpirySvc,
 		subscriptionExpirySvc,
+		childTokenExpirySvc,
 		&service.UsageCleanupService{},
 		idempotencyCleanupSvc,
+		usageAnomalySvc,
+		nil, // errorPassthrough
+		nil, // regionUpstream
+		nil, // proxyUpstream
 		pricingSvc,
 		emailQueueSvc,
 		billingCacheSvc,
+		&service.BillingDBHealthService{},
 		&service.UsageRecordWorkerPool{},
+		&service.UsageRecordJournalReplayer{},
+		&service.UsageEventExporter{},
 		&service.SubscriptionService{},
 		oauthSvc,
 		openAIOAuthSvc,
@@ -77,6 +87,8 @@ func TestProvideCleanup_WithMinimalDependencies_NoPanic(t *testing.T) {
 		nil, // backupSvc
 		nil, // paymentOrderExpiry
 		nil, // channelMonitorRunner
+		nil, // trafficReplay
+		nil, // messageBatch
 	)
 
 	require.NotPanics(t, func() {
@@ -13,6 +13,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/apikey"
 	"github.com/Wei-Shaw/sub2api/ent/group"
 	"github.com/Wei-Shaw/sub2api/ent/user"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // APIKey is the model entity for the APIKey schema.
@@ -42,6 +43,26 @@ type APIKey struct {
 	IPWhitelist []string `json:"ip_whitelist,omitempty"`
 	// Blocked IPs/CIDRs
 	IPBlacklist []string `json:"ip_blacklist,omitempty"`
+	// Permission scopes, e.g. ["chat", "platform:openai"] (empty = unrestricted)
+	Scopes []string `json:"scopes,omitempty"`
+	// Concurrency wait-queue priority: high/normal/low (empty = inherit from group)
+	Priority string `json:"priority,omitempty"`
+	// Content moderation override: enabled/disabled (empty = inherit from group)
+	ModerationMode string `json:"moderation_mode,omitempty"`
+	// Drop oldest messages when the prompt exceeds the model context window
+	ContextAutoTrim bool `json:"context_auto_trim,omitempty"`
+	// Max request body size in bytes (0 = inherit from group/endpoint class)
+	MaxBodySize int64 `json:"max_body_size,omitempty"`
+	// Parent key ID when this key is a short-lived child token (0 = regular key)
+	ParentID int64 `json:"parent_id,omitempty"`
+	// Coalesce identical concurrent non-stream requests onto one upstream call
+	RequestCoalescing bool `json:"request_coalescing,omitempty"`
+	// Token bucket capacity in requests (0 = disabled)
+	TokenBucketBurst int `json:"token_bucket_burst,omitempty"`
+	// Token bucket refill rate in requests per second
+	TokenBucketRefillRate float64 `json:"token_bucket_refill_rate,omitempty"`
+	// Max estimated cost in USD for a single request (0 = unlimited)
+	MaxCostPerRequest float64 `json:"max_cost_per_request,omitempty"`
 	// Quota limit in USD for this API key (0 = unlimited)
 	Quota float64 `json:"quota,omitempty"`
 	// Used quota amount in USD
@@ -66,6 +87,12 @@ type APIKey struct {
 	Window1dStart *time.Time `json:"window_1d_start,omitempty"`
 	// Start time of the current 7d rate limit window
 	Window7dStart *time.Time `json:"window_7d_start,omitempty"`
+	// Account tag selector combined with the group selector during scheduling
+	AccountTagSelector domain.AccountTagSelector `json:"account_tag_selector,omitempty"`
+	// Upstream platforms this key may be routed to (empty = unrestricted)
+	AllowedPlatforms []string `json:"allowed_platforms,omitempty"`
+	// Model patterns this key may request (empty = unrestricted)
+	AllowedModels []string `json:"allowed_models,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the APIKeyQuery when eager-loading is set.
 	Edges        APIKeyEdges `json:"edges"`
@@ -121,13 +148,15 @@ func (*APIKey) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case apikey.FieldIPWhitelist, apikey.FieldIPBlacklist:
+		case apikey.FieldIPWhitelist, apikey.FieldIPBlacklist, apikey.FieldScopes, apikey.FieldAccountTagSelector, apikey.FieldAllowedPlatforms, apikey.FieldAllowedModels:
 			values[i] = new([]byte)
-		case apikey.FieldQuota, apikey.FieldQuotaUsed, apikey.FieldRateLimit5h, apikey.FieldRateLimit1d, apikey.FieldRateLimit7d, apikey.FieldUsage5h, apikey.FieldUsage1d, apikey.FieldUsage7d:
+		case apikey.FieldContextAutoTrim, apikey.FieldRequestCoalescing:
+			values[i] = new(sql.NullBool)
+		case apikey.FieldTokenBucketRefillRate, apikey.FieldMaxCostPerRequest, apikey.FieldQuota, apikey.FieldQuotaUsed, apikey.FieldRateLimit5h, apikey.FieldRateLimit1d, apikey.FieldRateLimit7d, apikey.FieldUsage5h, apikey.FieldUsage1d, apikey.FieldUsage7d:
 			values[i] = new(sql.NullFloat64)
-		case apikey.FieldID, apikey.FieldUserID, apikey.FieldGroupID:
+		case apikey.FieldID, apikey.FieldUserID, apikey.FieldGroupID, apikey.FieldMaxBodySize, apikey.FieldParentID, apikey.FieldTokenBucketBurst:
 			values[i] = new(sql.NullInt64)
-		case apikey.FieldKey, apikey.FieldName, apikey.FieldStatus:
+		case apikey.FieldKey, apikey.FieldName, apikey.FieldStatus, apikey.FieldPriority, apikey.FieldModerationMode:
 			values[i] = new(sql.NullString)
 		case apikey.FieldCreatedAt, apikey.FieldUpdatedAt, apikey.FieldDeletedAt, apikey.FieldLastUsedAt, apikey.FieldExpiresAt, apikey.FieldWindow5hStart, apikey.FieldWindow1dStart, apikey.FieldWindow7dStart:
 			values[i] = new(sql.NullTime)
@@ -225,6 +254,68 @@ func (_m *APIKey) assignValues(columns []string, values []any) error {
 					return fmt.Errorf("unmarshal field ip_blacklist: %w", err)
 				}
 			}
+		case apikey.FieldScopes:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field scopes", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.Scopes); err != nil {
+					return fmt.Errorf("unmarshal field scopes: %w", err)
+				}
+			}
+		case apikey.FieldPriority:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field priority", values[i])
+			} else if value.Valid {
+				_m.Priority = value.String
+			}
+		case apikey.FieldModerationMode:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field moderation_mode", values[i])
+			} else if value.Valid {
+				_m.ModerationMode = value.String
+			}
+		case apikey.FieldContextAutoTrim:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field context_auto_trim", values[i])
+			} else if value.Valid {
+				_m.ContextAutoTrim = value.Bool
+			}
+		case apikey.FieldMaxBodySize:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field max_body_size", values[i])
+			} else if value.Valid {
+				_m.MaxBodySize = value.Int64
+			}
+		case apikey.FieldParentID:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field parent_id", values[i])
+			} else if value.Valid {
+				_m.ParentID = value.Int64
+			}
+		case apikey.FieldRequestCoalescing:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field request_coalescing", values[i])
+			} else if value.Valid {
+				_m.RequestCoalescing = value.Bool
+			}
+		case apikey.FieldTokenBucketBurst:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field token_bucket_burst", values[i])
+			} else if value.Valid {
+				_m.TokenBucketBurst = int(value.Int64)
+			}
+		case apikey.FieldTokenBucketRefillRate:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field token_bucket_refill_rate", values[i])
+			} else if value.Valid {
+				_m.TokenBucketRefillRate = value.Float64
+			}
+		case apikey.FieldMaxCostPerRequest:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field max_cost_per_request", values[i])
+			} else if value.Valid {
+				_m.MaxCostPerRequest = value.Float64
+			}
 		case apikey.FieldQuota:
 			if value, ok := values[i].(*sql.NullFloat64); !ok {
 				return fmt.Errorf("unexpected type %T for field quota", values[i])
@@ -301,6 +392,30 @@ func (_m *APIKey) assignValues(columns []string, values []any) error {
 				_m.Window7dStart = new(time.Time)
 				*_m.Window7dStart = value.Time
 			}
+		case apikey.FieldAccountTagSelector:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field account_tag_selector", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AccountTagSelector); err != nil {
+					return fmt.Errorf("unmarshal field account_tag_selector: %w", err)
+				}
+			}
+		case apikey.FieldAllowedPlatforms:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field allowed_platforms", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AllowedPlatforms); err != nil {
+					return fmt.Errorf("unmarshal field allowed_platforms: %w", err)
+				}
+			}
+		case apikey.FieldAllowedModels:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field allowed_models", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AllowedModels); err != nil {
+					return fmt.Errorf("unmarshal field allowed_models: %w", err)
+				}
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -391,6 +506,36 @@ func (_m *APIKey) String() string {
 	builder.WriteString("ip_blacklist=")
 	builder.WriteString(fmt.Sprintf("%v", _m.IPBlacklist))
 	builder.WriteString(", ")
+	builder.WriteString("scopes=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Scopes))
+	builder.WriteString(", ")
+	builder.WriteString("priority=")
+	builder.WriteString(_m.Priority)
+	builder.WriteString(", ")
+	builder.WriteString("moderation_mode=")
+	builder.WriteString(_m.ModerationMode)
+	builder.WriteString(", ")
+	builder.WriteString("context_auto_trim=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ContextAutoTrim))
+	builder.WriteString(", ")
+	builder.WriteString("max_body_size=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MaxBodySize))
+	builder.WriteString(", ")
+	builder.WriteString("parent_id=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ParentID))
+	builder.WriteString(", ")
+	builder.WriteString("request_coalescing=")
+	builder.WriteString(fmt.Sprintf("%v", _m.RequestCoalescing))
+	builder.WriteString(", ")
+	builder.WriteString("token_bucket_burst=")
+	builder.WriteString(fmt.Sprintf("%v", _m.TokenBucketBurst))
+	builder.WriteString(", ")
+	builder.WriteString("token_bucket_refill_rate=")
+	builder.WriteString(fmt.Sprintf("%v", _m.TokenBucketRefillRate))
+	builder.WriteString(", ")
+	builder.WriteString("max_cost_per_request=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MaxCostPerRequest))
+	builder.WriteString(", ")
 	builder.WriteString("quota=")
 	builder.WriteString(fmt.Sprintf("%v", _m.Quota))
 	builder.WriteString(", ")
@@ -434,6 +579,15 @@ func (_m *APIKey) String() string {
 		builder.WriteString("window_7d_start=")
 		builder.WriteString(v.Format(time.ANSIC))
 	}
+	builder.WriteString(", ")
+	builder.WriteString("account_tag_selector=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AccountTagSelector))
+	builder.WriteString(", ")
+	builder.WriteString("allowed_platforms=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowedPlatforms))
+	builder.WriteString(", ")
+	builder.WriteString("allowed_models=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AllowedModels))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -8,6 +8,7 @@ import (
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 const (
@@ -37,6 +38,26 @@ const (
 	FieldIPWhitelist = "ip_whitelist"
 	// FieldIPBlacklist holds the string denoting the ip_blacklist field in the database.
 	FieldIPBlacklist = "ip_blacklist"
+	// FieldScopes holds the string denoting the scopes field in the database.
+	FieldScopes = "scopes"
+	// FieldPriority holds the string denoting the priority field in the database.
+	FieldPriority = "priority"
+	// FieldModerationMode holds the string denoting the moderation_mode field in the database.
+	FieldModerationMode = "moderation_mode"
+	// FieldContextAutoTrim holds the string denoting the context_auto_trim field in the database.
+	FieldContextAutoTrim = "context_auto_trim"
+	// FieldMaxBodySize holds the string denoting the max_body_size field in the database.
+	FieldMaxBodySize = "max_body_size"
+	// FieldParentID holds the string denoting the parent_id field in the database.
+	FieldParentID = "parent_id"
+	// FieldRequestCoalescing holds the string denoting the request_coalescing field in the database.
+	FieldRequestCoalescing = "request_coalescing"
+	// FieldTokenBucketBurst holds the string denoting the token_bucket_burst field in the database.
+	FieldTokenBucketBurst = "token_bucket_burst"
+	// FieldTokenBucketRefillRate holds the string denoting the token_bucket_refill_rate field in the database.
+	FieldTokenBucketRefillRate = "token_bucket_refill_rate"
+	// FieldMaxCostPerRequest holds the string denoting the max_cost_per_request field in the database.
+	FieldMaxCostPerRequest = "max_cost_per_request"
 	// FieldQuota holds the string denoting the quota field in the database.
 	FieldQuota = "quota"
 	// FieldQuotaUsed holds the string denoting the quota_used field in the database.
@@ -61,6 +82,12 @@ const (
 	FieldWindow1dStart = "window_1d_start"
 	// FieldWindow7dStart holds the string denoting the window_7d_start field in the database.
 	FieldWindow7dStart = "window_7d_start"
+	// FieldAccountTagSelector holds the string denoting the account_tag_selector field in the database.
+	FieldAccountTagSelector = "account_tag_selector"
+	// FieldAllowedPlatforms holds the string denoting the allowed_platforms field in the database.
+	FieldAllowedPlatforms = "allowed_platforms"
+	// FieldAllowedModels holds the string denoting the allowed_models field in the database.
+	FieldAllowedModels = "allowed_models"
 	// EdgeUser holds the string denoting the user edge name in mutations.
 	EdgeUser = "user"
 	// EdgeGroup holds the string denoting the group edge name in mutations.
@@ -106,6 +133,16 @@ var Columns = []string{
 	FieldLastUsedAt,
 	FieldIPWhitelist,
 	FieldIPBlacklist,
+	FieldScopes,
+	FieldPriority,
+	FieldModerationMode,
+	FieldContextAutoTrim,
+	FieldMaxBodySize,
+	FieldParentID,
+	FieldRequestCoalescing,
+	FieldTokenBucketBurst,
+	FieldTokenBucketRefillRate,
+	FieldMaxCostPerRequest,
 	FieldQuota,
 	FieldQuotaUsed,
 	FieldExpiresAt,
@@ -118,6 +155,9 @@ var Columns = []string{
 	FieldWindow5hStart,
 	FieldWindow1dStart,
 	FieldWindow7dStart,
+	FieldAccountTagSelector,
+	FieldAllowedPlatforms,
+	FieldAllowedModels,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -152,6 +192,28 @@ var (
 	DefaultStatus string
 	// StatusValidator is a validator for the "status" field. It is called by the builders before save.
 	StatusValidator func(string) error
+	// DefaultPriority holds the default value on creation for the "priority" field.
+	DefaultPriority string
+	// PriorityValidator is a validator for the "priority" field. It is called by the builders before save.
+	PriorityValidator func(string) error
+	// DefaultModerationMode holds the default value on creation for the "moderation_mode" field.
+	DefaultModerationMode string
+	// ModerationModeValidator is a validator for the "moderation_mode" field. It is called by the builders before save.
+	ModerationModeValidator func(string) error
+	// DefaultContextAutoTrim holds the default value on creation for the "context_auto_trim" field.
+	DefaultContextAutoTrim bool
+	// DefaultMaxBodySize holds the default value on creation for the "max_body_size" field.
+	DefaultMaxBodySize int64
+	// DefaultParentID holds the default value on creation for the "parent_id" field.
+	DefaultParentID int64
+	// DefaultRequestCoalescing holds the default value on creation for the "request_coalescing" field.
+	DefaultRequestCoalescing bool
+	// DefaultTokenBucketBurst holds the default value on creation for the "token_bucket_burst" field.
+	DefaultTokenBucketBurst int
+	// DefaultTokenBucketRefillRate holds the default value on creation for the "token_bucket_refill_rate" field.
+	DefaultTokenBucketRefillRate float64
+	// DefaultMaxCostPerRequest holds the default value on creation for the "max_cost_per_request" field.
+	DefaultMaxCostPerRequest float64
 	// DefaultQuota holds the default value on creation for the "quota" field.
 	DefaultQuota float64
 	// DefaultQuotaUsed holds the default value on creation for the "quota_used" field.
@@ -168,6 +230,12 @@ var (
 	DefaultUsage1d float64
 	// DefaultUsage7d holds the default value on creation for the "usage_7d" field.
 	DefaultUsage7d float64
+	// DefaultAccountTagSelector holds the default value on creation for the "account_tag_selector" field.
+	DefaultAccountTagSelector domain.AccountTagSelector
+	// DefaultAllowedPlatforms holds the default value on creation for the "allowed_platforms" field.
+	DefaultAllowedPlatforms []string
+	// DefaultAllowedModels holds the default value on creation for the "allowed_models" field.
+	DefaultAllowedModels []string
 )
 
 // OrderOption defines the ordering options for the APIKey queries.
@@ -223,6 +291,51 @@ func ByLastUsedAt(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldLastUsedAt, opts...).ToFunc()
 }
 
+// ByPriority orders the results by the priority field.
+func ByPriority(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPriority, opts...).ToFunc()
+}
+
+// ByModerationMode orders the results by the moderation_mode field.
+func ByModerationMode(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldModerationMode, opts...).ToFunc()
+}
+
+// ByContextAutoTrim orders the results by the context_auto_trim field.
+func ByContextAutoTrim(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldContextAutoTrim, opts...).ToFunc()
+}
+
+// ByMaxBodySize orders the results by the max_body_size field.
+func ByMaxBodySize(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMaxBodySize, opts...).ToFunc()
+}
+
+// ByParentID orders the results by the parent_id field.
+func ByParentID(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldParentID, opts...).ToFunc()
+}
+
+// ByRequestCoalescing orders the results by the request_coalescing field.
+func ByRequestCoalescing(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldRequestCoalescing, opts...).ToFunc()
+}
+
+// ByTokenBucketBurst orders the results by the token_bucket_burst field.
+func ByTokenBucketBurst(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldTokenBucketBurst, opts...).ToFunc()
+}
+
+// ByTokenBucketRefillRate orders the results by the token_bucket_refill_rate field.
+func ByTokenBucketRefillRate(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldTokenBucketRefillRate, opts...).ToFunc()
+}
+
+// ByMaxCostPerRequest orders the results by the max_cost_per_request field.
+func ByMaxCostPerRequest(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMaxCostPerRequest, opts...).ToFunc()
+}
+
 // ByQuota orders the results by the quota field.
 func ByQuota(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldQuota, opts...).ToFunc()
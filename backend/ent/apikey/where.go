@@ -100,6 +100,51 @@ func LastUsedAt(v time.Time) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldLastUsedAt, v))
 }
 
+// Priority applies equality check predicate on the "priority" field. It's identical to PriorityEQ.
+func Priority(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPriority, v))
+}
+
+// ModerationMode applies equality check predicate on the "moderation_mode" field. It's identical to ModerationModeEQ.
+func ModerationMode(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldModerationMode, v))
+}
+
+// ContextAutoTrim applies equality check predicate on the "context_auto_trim" field. It's identical to ContextAutoTrimEQ.
+func ContextAutoTrim(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldContextAutoTrim, v))
+}
+
+// MaxBodySize applies equality check predicate on the "max_body_size" field. It's identical to MaxBodySizeEQ.
+func MaxBodySize(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMaxBodySize, v))
+}
+
+// ParentID applies equality check predicate on the "parent_id" field. It's identical to ParentIDEQ.
+func ParentID(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldParentID, v))
+}
+
+// RequestCoalescing applies equality check predicate on the "request_coalescing" field. It's identical to RequestCoalescingEQ.
+func RequestCoalescing(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRequestCoalescing, v))
+}
+
+// TokenBucketBurst applies equality check predicate on the "token_bucket_burst" field. It's identical to TokenBucketBurstEQ.
+func TokenBucketBurst(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTokenBucketBurst, v))
+}
+
+// TokenBucketRefillRate applies equality check predicate on the "token_bucket_refill_rate" field. It's identical to TokenBucketRefillRateEQ.
+func TokenBucketRefillRate(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTokenBucketRefillRate, v))
+}
+
+// MaxCostPerRequest applies equality check predicate on the "max_cost_per_request" field. It's identical to MaxCostPerRequestEQ.
+func MaxCostPerRequest(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMaxCostPerRequest, v))
+}
+
 // Quota applies equality check predicate on the "quota" field. It's identical to QuotaEQ.
 func Quota(v float64) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldQuota, v))
@@ -605,6 +650,366 @@ func IPBlacklistNotNil() predicate.APIKey {
 	return predicate.APIKey(sql.FieldNotNull(FieldIPBlacklist))
 }
 
+// ScopesIsNil applies the IsNil predicate on the "scopes" field.
+func ScopesIsNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldIsNull(FieldScopes))
+}
+
+// ScopesNotNil applies the NotNil predicate on the "scopes" field.
+func ScopesNotNil() predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotNull(FieldScopes))
+}
+
+// PriorityEQ applies the EQ predicate on the "priority" field.
+func PriorityEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldPriority, v))
+}
+
+// PriorityNEQ applies the NEQ predicate on the "priority" field.
+func PriorityNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldPriority, v))
+}
+
+// PriorityIn applies the In predicate on the "priority" field.
+func PriorityIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldPriority, vs...))
+}
+
+// PriorityNotIn applies the NotIn predicate on the "priority" field.
+func PriorityNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldPriority, vs...))
+}
+
+// PriorityGT applies the GT predicate on the "priority" field.
+func PriorityGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldPriority, v))
+}
+
+// PriorityGTE applies the GTE predicate on the "priority" field.
+func PriorityGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldPriority, v))
+}
+
+// PriorityLT applies the LT predicate on the "priority" field.
+func PriorityLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldPriority, v))
+}
+
+// PriorityLTE applies the LTE predicate on the "priority" field.
+func PriorityLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldPriority, v))
+}
+
+// PriorityContains applies the Contains predicate on the "priority" field.
+func PriorityContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldPriority, v))
+}
+
+// PriorityHasPrefix applies the HasPrefix predicate on the "priority" field.
+func PriorityHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldPriority, v))
+}
+
+// PriorityHasSuffix applies the HasSuffix predicate on the "priority" field.
+func PriorityHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldPriority, v))
+}
+
+// PriorityEqualFold applies the EqualFold predicate on the "priority" field.
+func PriorityEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldPriority, v))
+}
+
+// PriorityContainsFold applies the ContainsFold predicate on the "priority" field.
+func PriorityContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldPriority, v))
+}
+
+// ModerationModeEQ applies the EQ predicate on the "moderation_mode" field.
+func ModerationModeEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldModerationMode, v))
+}
+
+// ModerationModeNEQ applies the NEQ predicate on the "moderation_mode" field.
+func ModerationModeNEQ(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldModerationMode, v))
+}
+
+// ModerationModeIn applies the In predicate on the "moderation_mode" field.
+func ModerationModeIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldModerationMode, vs...))
+}
+
+// ModerationModeNotIn applies the NotIn predicate on the "moderation_mode" field.
+func ModerationModeNotIn(vs ...string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldModerationMode, vs...))
+}
+
+// ModerationModeGT applies the GT predicate on the "moderation_mode" field.
+func ModerationModeGT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldModerationMode, v))
+}
+
+// ModerationModeGTE applies the GTE predicate on the "moderation_mode" field.
+func ModerationModeGTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldModerationMode, v))
+}
+
+// ModerationModeLT applies the LT predicate on the "moderation_mode" field.
+func ModerationModeLT(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldModerationMode, v))
+}
+
+// ModerationModeLTE applies the LTE predicate on the "moderation_mode" field.
+func ModerationModeLTE(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldModerationMode, v))
+}
+
+// ModerationModeContains applies the Contains predicate on the "moderation_mode" field.
+func ModerationModeContains(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContains(FieldModerationMode, v))
+}
+
+// ModerationModeHasPrefix applies the HasPrefix predicate on the "moderation_mode" field.
+func ModerationModeHasPrefix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasPrefix(FieldModerationMode, v))
+}
+
+// ModerationModeHasSuffix applies the HasSuffix predicate on the "moderation_mode" field.
+func ModerationModeHasSuffix(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldHasSuffix(FieldModerationMode, v))
+}
+
+// ModerationModeEqualFold applies the EqualFold predicate on the "moderation_mode" field.
+func ModerationModeEqualFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEqualFold(FieldModerationMode, v))
+}
+
+// ModerationModeContainsFold applies the ContainsFold predicate on the "moderation_mode" field.
+func ModerationModeContainsFold(v string) predicate.APIKey {
+	return predicate.APIKey(sql.FieldContainsFold(FieldModerationMode, v))
+}
+
+// ContextAutoTrimEQ applies the EQ predicate on the "context_auto_trim" field.
+func ContextAutoTrimEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldContextAutoTrim, v))
+}
+
+// ContextAutoTrimNEQ applies the NEQ predicate on the "context_auto_trim" field.
+func ContextAutoTrimNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldContextAutoTrim, v))
+}
+
+// MaxBodySizeEQ applies the EQ predicate on the "max_body_size" field.
+func MaxBodySizeEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMaxBodySize, v))
+}
+
+// ParentIDEQ applies the EQ predicate on the "parent_id" field.
+func ParentIDEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldParentID, v))
+}
+
+// MaxBodySizeNEQ applies the NEQ predicate on the "max_body_size" field.
+func MaxBodySizeNEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldMaxBodySize, v))
+}
+
+// ParentIDNEQ applies the NEQ predicate on the "parent_id" field.
+func ParentIDNEQ(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldParentID, v))
+}
+
+// MaxBodySizeIn applies the In predicate on the "max_body_size" field.
+func MaxBodySizeIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldMaxBodySize, vs...))
+}
+
+// ParentIDIn applies the In predicate on the "parent_id" field.
+func ParentIDIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldParentID, vs...))
+}
+
+// MaxBodySizeNotIn applies the NotIn predicate on the "max_body_size" field.
+func MaxBodySizeNotIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldMaxBodySize, vs...))
+}
+
+// ParentIDNotIn applies the NotIn predicate on the "parent_id" field.
+func ParentIDNotIn(vs ...int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldParentID, vs...))
+}
+
+// MaxBodySizeGT applies the GT predicate on the "max_body_size" field.
+func MaxBodySizeGT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldMaxBodySize, v))
+}
+
+// ParentIDGT applies the GT predicate on the "parent_id" field.
+func ParentIDGT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldParentID, v))
+}
+
+// MaxBodySizeGTE applies the GTE predicate on the "max_body_size" field.
+func MaxBodySizeGTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldMaxBodySize, v))
+}
+
+// ParentIDGTE applies the GTE predicate on the "parent_id" field.
+func ParentIDGTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldParentID, v))
+}
+
+// MaxBodySizeLT applies the LT predicate on the "max_body_size" field.
+func MaxBodySizeLT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldMaxBodySize, v))
+}
+
+// ParentIDLT applies the LT predicate on the "parent_id" field.
+func ParentIDLT(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldParentID, v))
+}
+
+// MaxBodySizeLTE applies the LTE predicate on the "max_body_size" field.
+func MaxBodySizeLTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldMaxBodySize, v))
+}
+
+// ParentIDLTE applies the LTE predicate on the "parent_id" field.
+func ParentIDLTE(v int64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldParentID, v))
+}
+
+// RequestCoalescingEQ applies the EQ predicate on the "request_coalescing" field.
+func RequestCoalescingEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldRequestCoalescing, v))
+}
+
+// RequestCoalescingNEQ applies the NEQ predicate on the "request_coalescing" field.
+func RequestCoalescingNEQ(v bool) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldRequestCoalescing, v))
+}
+
+// TokenBucketBurstEQ applies the EQ predicate on the "token_bucket_burst" field.
+func TokenBucketBurstEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTokenBucketBurst, v))
+}
+
+// TokenBucketBurstNEQ applies the NEQ predicate on the "token_bucket_burst" field.
+func TokenBucketBurstNEQ(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldTokenBucketBurst, v))
+}
+
+// TokenBucketBurstIn applies the In predicate on the "token_bucket_burst" field.
+func TokenBucketBurstIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldTokenBucketBurst, vs...))
+}
+
+// TokenBucketBurstNotIn applies the NotIn predicate on the "token_bucket_burst" field.
+func TokenBucketBurstNotIn(vs ...int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldTokenBucketBurst, vs...))
+}
+
+// TokenBucketBurstGT applies the GT predicate on the "token_bucket_burst" field.
+func TokenBucketBurstGT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldTokenBucketBurst, v))
+}
+
+// TokenBucketBurstGTE applies the GTE predicate on the "token_bucket_burst" field.
+func TokenBucketBurstGTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldTokenBucketBurst, v))
+}
+
+// TokenBucketBurstLT applies the LT predicate on the "token_bucket_burst" field.
+func TokenBucketBurstLT(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldTokenBucketBurst, v))
+}
+
+// TokenBucketBurstLTE applies the LTE predicate on the "token_bucket_burst" field.
+func TokenBucketBurstLTE(v int) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldTokenBucketBurst, v))
+}
+
+// TokenBucketRefillRateEQ applies the EQ predicate on the "token_bucket_refill_rate" field.
+func TokenBucketRefillRateEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldTokenBucketRefillRate, v))
+}
+
+// TokenBucketRefillRateNEQ applies the NEQ predicate on the "token_bucket_refill_rate" field.
+func TokenBucketRefillRateNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldTokenBucketRefillRate, v))
+}
+
+// TokenBucketRefillRateIn applies the In predicate on the "token_bucket_refill_rate" field.
+func TokenBucketRefillRateIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldTokenBucketRefillRate, vs...))
+}
+
+// TokenBucketRefillRateNotIn applies the NotIn predicate on the "token_bucket_refill_rate" field.
+func TokenBucketRefillRateNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldTokenBucketRefillRate, vs...))
+}
+
+// TokenBucketRefillRateGT applies the GT predicate on the "token_bucket_refill_rate" field.
+func TokenBucketRefillRateGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldTokenBucketRefillRate, v))
+}
+
+// TokenBucketRefillRateGTE applies the GTE predicate on the "token_bucket_refill_rate" field.
+func TokenBucketRefillRateGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldTokenBucketRefillRate, v))
+}
+
+// TokenBucketRefillRateLT applies the LT predicate on the "token_bucket_refill_rate" field.
+func TokenBucketRefillRateLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldTokenBucketRefillRate, v))
+}
+
+// TokenBucketRefillRateLTE applies the LTE predicate on the "token_bucket_refill_rate" field.
+func TokenBucketRefillRateLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldTokenBucketRefillRate, v))
+}
+
+// MaxCostPerRequestEQ applies the EQ predicate on the "max_cost_per_request" field.
+func MaxCostPerRequestEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldEQ(FieldMaxCostPerRequest, v))
+}
+
+// MaxCostPerRequestNEQ applies the NEQ predicate on the "max_cost_per_request" field.
+func MaxCostPerRequestNEQ(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNEQ(FieldMaxCostPerRequest, v))
+}
+
+// MaxCostPerRequestIn applies the In predicate on the "max_cost_per_request" field.
+func MaxCostPerRequestIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldIn(FieldMaxCostPerRequest, vs...))
+}
+
+// MaxCostPerRequestNotIn applies the NotIn predicate on the "max_cost_per_request" field.
+func MaxCostPerRequestNotIn(vs ...float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldNotIn(FieldMaxCostPerRequest, vs...))
+}
+
+// MaxCostPerRequestGT applies the GT predicate on the "max_cost_per_request" field.
+func MaxCostPerRequestGT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGT(FieldMaxCostPerRequest, v))
+}
+
+// MaxCostPerRequestGTE applies the GTE predicate on the "max_cost_per_request" field.
+func MaxCostPerRequestGTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldGTE(FieldMaxCostPerRequest, v))
+}
+
+// MaxCostPerRequestLT applies the LT predicate on the "max_cost_per_request" field.
+func MaxCostPerRequestLT(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLT(FieldMaxCostPerRequest, v))
+}
+
+// MaxCostPerRequestLTE applies the LTE predicate on the "max_cost_per_request" field.
+func MaxCostPerRequestLTE(v float64) predicate.APIKey {
+	return predicate.APIKey(sql.FieldLTE(FieldMaxCostPerRequest, v))
+}
+
 // QuotaEQ applies the EQ predicate on the "quota" field.
 func QuotaEQ(v float64) predicate.APIKey {
 	return predicate.APIKey(sql.FieldEQ(FieldQuota, v))
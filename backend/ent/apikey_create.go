@@ -15,6 +15,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/group"
 	"github.com/Wei-Shaw/sub2api/ent/usagelog"
 	"github.com/Wei-Shaw/sub2api/ent/user"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // APIKeyCreate is the builder for creating a APIKey entity.
@@ -139,6 +140,138 @@ func (_c *APIKeyCreate) SetIPBlacklist(v []string) *APIKeyCreate {
 	return _c
 }
 
+// SetScopes sets the "scopes" field.
+func (_c *APIKeyCreate) SetScopes(v []string) *APIKeyCreate {
+	_c.mutation.SetScopes(v)
+	return _c
+}
+
+// SetPriority sets the "priority" field.
+func (_c *APIKeyCreate) SetPriority(v string) *APIKeyCreate {
+	_c.mutation.SetPriority(v)
+	return _c
+}
+
+// SetNillablePriority sets the "priority" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillablePriority(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetPriority(*v)
+	}
+	return _c
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (_c *APIKeyCreate) SetModerationMode(v string) *APIKeyCreate {
+	_c.mutation.SetModerationMode(v)
+	return _c
+}
+
+// SetNillableModerationMode sets the "moderation_mode" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableModerationMode(v *string) *APIKeyCreate {
+	if v != nil {
+		_c.SetModerationMode(*v)
+	}
+	return _c
+}
+
+// SetContextAutoTrim sets the "context_auto_trim" field.
+func (_c *APIKeyCreate) SetContextAutoTrim(v bool) *APIKeyCreate {
+	_c.mutation.SetContextAutoTrim(v)
+	return _c
+}
+
+// SetNillableContextAutoTrim sets the "context_auto_trim" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableContextAutoTrim(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetContextAutoTrim(*v)
+	}
+	return _c
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (_c *APIKeyCreate) SetMaxBodySize(v int64) *APIKeyCreate {
+	_c.mutation.SetMaxBodySize(v)
+	return _c
+}
+
+// SetParentID sets the "parent_id" field.
+func (_c *APIKeyCreate) SetParentID(v int64) *APIKeyCreate {
+	_c.mutation.SetParentID(v)
+	return _c
+}
+
+// SetNillableMaxBodySize sets the "max_body_size" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableMaxBodySize(v *int64) *APIKeyCreate {
+	if v != nil {
+		_c.SetMaxBodySize(*v)
+	}
+	return _c
+}
+
+// SetNillableParentID sets the "parent_id" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableParentID(v *int64) *APIKeyCreate {
+	if v != nil {
+		_c.SetParentID(*v)
+	}
+	return _c
+}
+
+// SetRequestCoalescing sets the "request_coalescing" field.
+func (_c *APIKeyCreate) SetRequestCoalescing(v bool) *APIKeyCreate {
+	_c.mutation.SetRequestCoalescing(v)
+	return _c
+}
+
+// SetNillableRequestCoalescing sets the "request_coalescing" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableRequestCoalescing(v *bool) *APIKeyCreate {
+	if v != nil {
+		_c.SetRequestCoalescing(*v)
+	}
+	return _c
+}
+
+// SetTokenBucketBurst sets the "token_bucket_burst" field.
+func (_c *APIKeyCreate) SetTokenBucketBurst(v int) *APIKeyCreate {
+	_c.mutation.SetTokenBucketBurst(v)
+	return _c
+}
+
+// SetNillableTokenBucketBurst sets the "token_bucket_burst" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableTokenBucketBurst(v *int) *APIKeyCreate {
+	if v != nil {
+		_c.SetTokenBucketBurst(*v)
+	}
+	return _c
+}
+
+// SetTokenBucketRefillRate sets the "token_bucket_refill_rate" field.
+func (_c *APIKeyCreate) SetTokenBucketRefillRate(v float64) *APIKeyCreate {
+	_c.mutation.SetTokenBucketRefillRate(v)
+	return _c
+}
+
+// SetNillableTokenBucketRefillRate sets the "token_bucket_refill_rate" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableTokenBucketRefillRate(v *float64) *APIKeyCreate {
+	if v != nil {
+		_c.SetTokenBucketRefillRate(*v)
+	}
+	return _c
+}
+
+// SetMaxCostPerRequest sets the "max_cost_per_request" field.
+func (_c *APIKeyCreate) SetMaxCostPerRequest(v float64) *APIKeyCreate {
+	_c.mutation.SetMaxCostPerRequest(v)
+	return _c
+}
+
+// SetNillableMaxCostPerRequest sets the "max_cost_per_request" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableMaxCostPerRequest(v *float64) *APIKeyCreate {
+	if v != nil {
+		_c.SetMaxCostPerRequest(*v)
+	}
+	return _c
+}
+
 // SetQuota sets the "quota" field.
 func (_c *APIKeyCreate) SetQuota(v float64) *APIKeyCreate {
 	_c.mutation.SetQuota(v)
@@ -307,6 +440,32 @@ func (_c *APIKeyCreate) SetNillableWindow7dStart(v *time.Time) *APIKeyCreate {
 	return _c
 }
 
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (_c *APIKeyCreate) SetAccountTagSelector(v domain.AccountTagSelector) *APIKeyCreate {
+	_c.mutation.SetAccountTagSelector(v)
+	return _c
+}
+
+// SetNillableAccountTagSelector sets the "account_tag_selector" field if the given value is not nil.
+func (_c *APIKeyCreate) SetNillableAccountTagSelector(v *domain.AccountTagSelector) *APIKeyCreate {
+	if v != nil {
+		_c.SetAccountTagSelector(*v)
+	}
+	return _c
+}
+
+// SetAllowedPlatforms sets the "allowed_platforms" field.
+func (_c *APIKeyCreate) SetAllowedPlatforms(v []string) *APIKeyCreate {
+	_c.mutation.SetAllowedPlatforms(v)
+	return _c
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_c *APIKeyCreate) SetAllowedModels(v []string) *APIKeyCreate {
+	_c.mutation.SetAllowedModels(v)
+	return _c
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_c *APIKeyCreate) SetUser(v *User) *APIKeyCreate {
 	return _c.SetUserID(v.ID)
@@ -387,6 +546,42 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultStatus
 		_c.mutation.SetStatus(v)
 	}
+	if _, ok := _c.mutation.Priority(); !ok {
+		v := apikey.DefaultPriority
+		_c.mutation.SetPriority(v)
+	}
+	if _, ok := _c.mutation.ModerationMode(); !ok {
+		v := apikey.DefaultModerationMode
+		_c.mutation.SetModerationMode(v)
+	}
+	if _, ok := _c.mutation.ContextAutoTrim(); !ok {
+		v := apikey.DefaultContextAutoTrim
+		_c.mutation.SetContextAutoTrim(v)
+	}
+	if _, ok := _c.mutation.MaxBodySize(); !ok {
+		v := apikey.DefaultMaxBodySize
+		_c.mutation.SetMaxBodySize(v)
+	}
+	if _, ok := _c.mutation.ParentID(); !ok {
+		v := apikey.DefaultParentID
+		_c.mutation.SetParentID(v)
+	}
+	if _, ok := _c.mutation.RequestCoalescing(); !ok {
+		v := apikey.DefaultRequestCoalescing
+		_c.mutation.SetRequestCoalescing(v)
+	}
+	if _, ok := _c.mutation.TokenBucketBurst(); !ok {
+		v := apikey.DefaultTokenBucketBurst
+		_c.mutation.SetTokenBucketBurst(v)
+	}
+	if _, ok := _c.mutation.TokenBucketRefillRate(); !ok {
+		v := apikey.DefaultTokenBucketRefillRate
+		_c.mutation.SetTokenBucketRefillRate(v)
+	}
+	if _, ok := _c.mutation.MaxCostPerRequest(); !ok {
+		v := apikey.DefaultMaxCostPerRequest
+		_c.mutation.SetMaxCostPerRequest(v)
+	}
 	if _, ok := _c.mutation.Quota(); !ok {
 		v := apikey.DefaultQuota
 		_c.mutation.SetQuota(v)
@@ -419,6 +614,18 @@ func (_c *APIKeyCreate) defaults() error {
 		v := apikey.DefaultUsage7d
 		_c.mutation.SetUsage7d(v)
 	}
+	if _, ok := _c.mutation.AccountTagSelector(); !ok {
+		v := apikey.DefaultAccountTagSelector
+		_c.mutation.SetAccountTagSelector(v)
+	}
+	if _, ok := _c.mutation.AllowedPlatforms(); !ok {
+		v := apikey.DefaultAllowedPlatforms
+		_c.mutation.SetAllowedPlatforms(v)
+	}
+	if _, ok := _c.mutation.AllowedModels(); !ok {
+		v := apikey.DefaultAllowedModels
+		_c.mutation.SetAllowedModels(v)
+	}
 	return nil
 }
 
@@ -457,6 +664,43 @@ func (_c *APIKeyCreate) check() error {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
+	if _, ok := _c.mutation.Priority(); !ok {
+		return &ValidationError{Name: "priority", err: errors.New(`ent: missing required field "APIKey.priority"`)}
+	}
+	if v, ok := _c.mutation.Priority(); ok {
+		if err := apikey.PriorityValidator(v); err != nil {
+			return &ValidationError{Name: "priority", err: fmt.Errorf(`ent: validator failed for field "APIKey.priority": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.ModerationMode(); !ok {
+		return &ValidationError{Name: "moderation_mode", err: errors.New(`ent: missing required field "APIKey.moderation_mode"`)}
+	}
+	if v, ok := _c.mutation.ModerationMode(); ok {
+		if err := apikey.ModerationModeValidator(v); err != nil {
+			return &ValidationError{Name: "moderation_mode", err: fmt.Errorf(`ent: validator failed for field "APIKey.moderation_mode": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.ContextAutoTrim(); !ok {
+		return &ValidationError{Name: "context_auto_trim", err: errors.New(`ent: missing required field "APIKey.context_auto_trim"`)}
+	}
+	if _, ok := _c.mutation.MaxBodySize(); !ok {
+		return &ValidationError{Name: "max_body_size", err: errors.New(`ent: missing required field "APIKey.max_body_size"`)}
+	}
+	if _, ok := _c.mutation.ParentID(); !ok {
+		return &ValidationError{Name: "parent_id", err: errors.New(`ent: missing required field "APIKey.parent_id"`)}
+	}
+	if _, ok := _c.mutation.RequestCoalescing(); !ok {
+		return &ValidationError{Name: "request_coalescing", err: errors.New(`ent: missing required field "APIKey.request_coalescing"`)}
+	}
+	if _, ok := _c.mutation.TokenBucketBurst(); !ok {
+		return &ValidationError{Name: "token_bucket_burst", err: errors.New(`ent: missing required field "APIKey.token_bucket_burst"`)}
+	}
+	if _, ok := _c.mutation.TokenBucketRefillRate(); !ok {
+		return &ValidationError{Name: "token_bucket_refill_rate", err: errors.New(`ent: missing required field "APIKey.token_bucket_refill_rate"`)}
+	}
+	if _, ok := _c.mutation.MaxCostPerRequest(); !ok {
+		return &ValidationError{Name: "max_cost_per_request", err: errors.New(`ent: missing required field "APIKey.max_cost_per_request"`)}
+	}
 	if _, ok := _c.mutation.Quota(); !ok {
 		return &ValidationError{Name: "quota", err: errors.New(`ent: missing required field "APIKey.quota"`)}
 	}
@@ -481,6 +725,15 @@ func (_c *APIKeyCreate) check() error {
 	if _, ok := _c.mutation.Usage7d(); !ok {
 		return &ValidationError{Name: "usage_7d", err: errors.New(`ent: missing required field "APIKey.usage_7d"`)}
 	}
+	if _, ok := _c.mutation.AccountTagSelector(); !ok {
+		return &ValidationError{Name: "account_tag_selector", err: errors.New(`ent: missing required field "APIKey.account_tag_selector"`)}
+	}
+	if _, ok := _c.mutation.AllowedPlatforms(); !ok {
+		return &ValidationError{Name: "allowed_platforms", err: errors.New(`ent: missing required field "APIKey.allowed_platforms"`)}
+	}
+	if _, ok := _c.mutation.AllowedModels(); !ok {
+		return &ValidationError{Name: "allowed_models", err: errors.New(`ent: missing required field "APIKey.allowed_models"`)}
+	}
 	if len(_c.mutation.UserIDs()) == 0 {
 		return &ValidationError{Name: "user", err: errors.New(`ent: missing required edge "APIKey.user"`)}
 	}
@@ -547,6 +800,46 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldIPBlacklist, field.TypeJSON, value)
 		_node.IPBlacklist = value
 	}
+	if value, ok := _c.mutation.Scopes(); ok {
+		_spec.SetField(apikey.FieldScopes, field.TypeJSON, value)
+		_node.Scopes = value
+	}
+	if value, ok := _c.mutation.Priority(); ok {
+		_spec.SetField(apikey.FieldPriority, field.TypeString, value)
+		_node.Priority = value
+	}
+	if value, ok := _c.mutation.ModerationMode(); ok {
+		_spec.SetField(apikey.FieldModerationMode, field.TypeString, value)
+		_node.ModerationMode = value
+	}
+	if value, ok := _c.mutation.ContextAutoTrim(); ok {
+		_spec.SetField(apikey.FieldContextAutoTrim, field.TypeBool, value)
+		_node.ContextAutoTrim = value
+	}
+	if value, ok := _c.mutation.MaxBodySize(); ok {
+		_spec.SetField(apikey.FieldMaxBodySize, field.TypeInt64, value)
+		_node.MaxBodySize = value
+	}
+	if value, ok := _c.mutation.ParentID(); ok {
+		_spec.SetField(apikey.FieldParentID, field.TypeInt64, value)
+		_node.ParentID = value
+	}
+	if value, ok := _c.mutation.RequestCoalescing(); ok {
+		_spec.SetField(apikey.FieldRequestCoalescing, field.TypeBool, value)
+		_node.RequestCoalescing = value
+	}
+	if value, ok := _c.mutation.TokenBucketBurst(); ok {
+		_spec.SetField(apikey.FieldTokenBucketBurst, field.TypeInt, value)
+		_node.TokenBucketBurst = value
+	}
+	if value, ok := _c.mutation.TokenBucketRefillRate(); ok {
+		_spec.SetField(apikey.FieldTokenBucketRefillRate, field.TypeFloat64, value)
+		_node.TokenBucketRefillRate = value
+	}
+	if value, ok := _c.mutation.MaxCostPerRequest(); ok {
+		_spec.SetField(apikey.FieldMaxCostPerRequest, field.TypeFloat64, value)
+		_node.MaxCostPerRequest = value
+	}
 	if value, ok := _c.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 		_node.Quota = value
@@ -595,6 +888,18 @@ func (_c *APIKeyCreate) createSpec() (*APIKey, *sqlgraph.CreateSpec) {
 		_spec.SetField(apikey.FieldWindow7dStart, field.TypeTime, value)
 		_node.Window7dStart = &value
 	}
+	if value, ok := _c.mutation.AccountTagSelector(); ok {
+		_spec.SetField(apikey.FieldAccountTagSelector, field.TypeJSON, value)
+		_node.AccountTagSelector = value
+	}
+	if value, ok := _c.mutation.AllowedPlatforms(); ok {
+		_spec.SetField(apikey.FieldAllowedPlatforms, field.TypeJSON, value)
+		_node.AllowedPlatforms = value
+	}
+	if value, ok := _c.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+		_node.AllowedModels = value
+	}
 	if nodes := _c.mutation.UserIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -847,6 +1152,162 @@ func (u *APIKeyUpsert) ClearIPBlacklist() *APIKeyUpsert {
 	return u
 }
 
+// SetScopes sets the "scopes" field.
+func (u *APIKeyUpsert) SetScopes(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldScopes, v)
+	return u
+}
+
+// UpdateScopes sets the "scopes" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateScopes() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldScopes)
+	return u
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (u *APIKeyUpsert) ClearScopes() *APIKeyUpsert {
+	u.SetNull(apikey.FieldScopes)
+	return u
+}
+
+// SetPriority sets the "priority" field.
+func (u *APIKeyUpsert) SetPriority(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldPriority, v)
+	return u
+}
+
+// UpdatePriority sets the "priority" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdatePriority() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldPriority)
+	return u
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (u *APIKeyUpsert) SetModerationMode(v string) *APIKeyUpsert {
+	u.Set(apikey.FieldModerationMode, v)
+	return u
+}
+
+// UpdateModerationMode sets the "moderation_mode" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateModerationMode() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldModerationMode)
+	return u
+}
+
+// SetContextAutoTrim sets the "context_auto_trim" field.
+func (u *APIKeyUpsert) SetContextAutoTrim(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldContextAutoTrim, v)
+	return u
+}
+
+// UpdateContextAutoTrim sets the "context_auto_trim" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateContextAutoTrim() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldContextAutoTrim)
+	return u
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (u *APIKeyUpsert) SetMaxBodySize(v int64) *APIKeyUpsert {
+	u.Set(apikey.FieldMaxBodySize, v)
+	return u
+}
+
+// SetParentID sets the "parent_id" field.
+func (u *APIKeyUpsert) SetParentID(v int64) *APIKeyUpsert {
+	u.Set(apikey.FieldParentID, v)
+	return u
+}
+
+// UpdateMaxBodySize sets the "max_body_size" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateMaxBodySize() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldMaxBodySize)
+	return u
+}
+
+// UpdateParentID sets the "parent_id" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateParentID() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldParentID)
+	return u
+}
+
+// AddMaxBodySize adds v to the "max_body_size" field.
+func (u *APIKeyUpsert) AddMaxBodySize(v int64) *APIKeyUpsert {
+	u.Add(apikey.FieldMaxBodySize, v)
+	return u
+}
+
+// AddParentID adds v to the "parent_id" field.
+func (u *APIKeyUpsert) AddParentID(v int64) *APIKeyUpsert {
+	u.Add(apikey.FieldParentID, v)
+	return u
+}
+
+// SetRequestCoalescing sets the "request_coalescing" field.
+func (u *APIKeyUpsert) SetRequestCoalescing(v bool) *APIKeyUpsert {
+	u.Set(apikey.FieldRequestCoalescing, v)
+	return u
+}
+
+// UpdateRequestCoalescing sets the "request_coalescing" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateRequestCoalescing() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldRequestCoalescing)
+	return u
+}
+
+// SetTokenBucketBurst sets the "token_bucket_burst" field.
+func (u *APIKeyUpsert) SetTokenBucketBurst(v int) *APIKeyUpsert {
+	u.Set(apikey.FieldTokenBucketBurst, v)
+	return u
+}
+
+// UpdateTokenBucketBurst sets the "token_bucket_burst" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateTokenBucketBurst() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldTokenBucketBurst)
+	return u
+}
+
+// AddTokenBucketBurst adds v to the "token_bucket_burst" field.
+func (u *APIKeyUpsert) AddTokenBucketBurst(v int) *APIKeyUpsert {
+	u.Add(apikey.FieldTokenBucketBurst, v)
+	return u
+}
+
+// SetTokenBucketRefillRate sets the "token_bucket_refill_rate" field.
+func (u *APIKeyUpsert) SetTokenBucketRefillRate(v float64) *APIKeyUpsert {
+	u.Set(apikey.FieldTokenBucketRefillRate, v)
+	return u
+}
+
+// UpdateTokenBucketRefillRate sets the "token_bucket_refill_rate" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateTokenBucketRefillRate() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldTokenBucketRefillRate)
+	return u
+}
+
+// AddTokenBucketRefillRate adds v to the "token_bucket_refill_rate" field.
+func (u *APIKeyUpsert) AddTokenBucketRefillRate(v float64) *APIKeyUpsert {
+	u.Add(apikey.FieldTokenBucketRefillRate, v)
+	return u
+}
+
+// SetMaxCostPerRequest sets the "max_cost_per_request" field.
+func (u *APIKeyUpsert) SetMaxCostPerRequest(v float64) *APIKeyUpsert {
+	u.Set(apikey.FieldMaxCostPerRequest, v)
+	return u
+}
+
+// UpdateMaxCostPerRequest sets the "max_cost_per_request" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateMaxCostPerRequest() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldMaxCostPerRequest)
+	return u
+}
+
+// AddMaxCostPerRequest adds v to the "max_cost_per_request" field.
+func (u *APIKeyUpsert) AddMaxCostPerRequest(v float64) *APIKeyUpsert {
+	u.Add(apikey.FieldMaxCostPerRequest, v)
+	return u
+}
+
 // SetQuota sets the "quota" field.
 func (u *APIKeyUpsert) SetQuota(v float64) *APIKeyUpsert {
 	u.Set(apikey.FieldQuota, v)
@@ -1063,6 +1524,42 @@ func (u *APIKeyUpsert) ClearWindow7dStart() *APIKeyUpsert {
 	return u
 }
 
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (u *APIKeyUpsert) SetAccountTagSelector(v domain.AccountTagSelector) *APIKeyUpsert {
+	u.Set(apikey.FieldAccountTagSelector, v)
+	return u
+}
+
+// UpdateAccountTagSelector sets the "account_tag_selector" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateAccountTagSelector() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldAccountTagSelector)
+	return u
+}
+
+// SetAllowedPlatforms sets the "allowed_platforms" field.
+func (u *APIKeyUpsert) SetAllowedPlatforms(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldAllowedPlatforms, v)
+	return u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsert) SetAllowedModels(v []string) *APIKeyUpsert {
+	u.Set(apikey.FieldAllowedModels, v)
+	return u
+}
+
+// UpdateAllowedPlatforms sets the "allowed_platforms" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateAllowedPlatforms() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldAllowedPlatforms)
+	return u
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsert) UpdateAllowedModels() *APIKeyUpsert {
+	u.SetExcluded(apikey.FieldAllowedModels)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1283,6 +1780,188 @@ func (u *APIKeyUpsertOne) ClearIPBlacklist() *APIKeyUpsertOne {
 	})
 }
 
+// SetScopes sets the "scopes" field.
+func (u *APIKeyUpsertOne) SetScopes(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetScopes(v)
+	})
+}
+
+// UpdateScopes sets the "scopes" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateScopes() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateScopes()
+	})
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (u *APIKeyUpsertOne) ClearScopes() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearScopes()
+	})
+}
+
+// SetPriority sets the "priority" field.
+func (u *APIKeyUpsertOne) SetPriority(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPriority(v)
+	})
+}
+
+// UpdatePriority sets the "priority" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdatePriority() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePriority()
+	})
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (u *APIKeyUpsertOne) SetModerationMode(v string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetModerationMode(v)
+	})
+}
+
+// UpdateModerationMode sets the "moderation_mode" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateModerationMode() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateModerationMode()
+	})
+}
+
+// SetContextAutoTrim sets the "context_auto_trim" field.
+func (u *APIKeyUpsertOne) SetContextAutoTrim(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetContextAutoTrim(v)
+	})
+}
+
+// UpdateContextAutoTrim sets the "context_auto_trim" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateContextAutoTrim() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateContextAutoTrim()
+	})
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (u *APIKeyUpsertOne) SetMaxBodySize(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetMaxBodySize(v)
+	})
+}
+
+// SetParentID sets the "parent_id" field.
+func (u *APIKeyUpsertOne) SetParentID(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetParentID(v)
+	})
+}
+
+// AddMaxBodySize adds v to the "max_body_size" field.
+func (u *APIKeyUpsertOne) AddMaxBodySize(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddMaxBodySize(v)
+	})
+}
+
+// AddParentID adds v to the "parent_id" field.
+func (u *APIKeyUpsertOne) AddParentID(v int64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddParentID(v)
+	})
+}
+
+// UpdateMaxBodySize sets the "max_body_size" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateMaxBodySize() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateMaxBodySize()
+	})
+}
+
+// UpdateParentID sets the "parent_id" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateParentID() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateParentID()
+	})
+}
+
+// SetRequestCoalescing sets the "request_coalescing" field.
+func (u *APIKeyUpsertOne) SetRequestCoalescing(v bool) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRequestCoalescing(v)
+	})
+}
+
+// UpdateRequestCoalescing sets the "request_coalescing" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateRequestCoalescing() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRequestCoalescing()
+	})
+}
+
+// SetTokenBucketBurst sets the "token_bucket_burst" field.
+func (u *APIKeyUpsertOne) SetTokenBucketBurst(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTokenBucketBurst(v)
+	})
+}
+
+// AddTokenBucketBurst adds v to the "token_bucket_burst" field.
+func (u *APIKeyUpsertOne) AddTokenBucketBurst(v int) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTokenBucketBurst(v)
+	})
+}
+
+// UpdateTokenBucketBurst sets the "token_bucket_burst" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateTokenBucketBurst() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTokenBucketBurst()
+	})
+}
+
+// SetTokenBucketRefillRate sets the "token_bucket_refill_rate" field.
+func (u *APIKeyUpsertOne) SetTokenBucketRefillRate(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTokenBucketRefillRate(v)
+	})
+}
+
+// AddTokenBucketRefillRate adds v to the "token_bucket_refill_rate" field.
+func (u *APIKeyUpsertOne) AddTokenBucketRefillRate(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTokenBucketRefillRate(v)
+	})
+}
+
+// UpdateTokenBucketRefillRate sets the "token_bucket_refill_rate" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateTokenBucketRefillRate() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTokenBucketRefillRate()
+	})
+}
+
+// SetMaxCostPerRequest sets the "max_cost_per_request" field.
+func (u *APIKeyUpsertOne) SetMaxCostPerRequest(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetMaxCostPerRequest(v)
+	})
+}
+
+// AddMaxCostPerRequest adds v to the "max_cost_per_request" field.
+func (u *APIKeyUpsertOne) AddMaxCostPerRequest(v float64) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddMaxCostPerRequest(v)
+	})
+}
+
+// UpdateMaxCostPerRequest sets the "max_cost_per_request" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateMaxCostPerRequest() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateMaxCostPerRequest()
+	})
+}
+
 // SetQuota sets the "quota" field.
 func (u *APIKeyUpsertOne) SetQuota(v float64) *APIKeyUpsertOne {
 	return u.Update(func(s *APIKeyUpsert) {
@@ -1535,6 +2214,48 @@ func (u *APIKeyUpsertOne) ClearWindow7dStart() *APIKeyUpsertOne {
 	})
 }
 
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (u *APIKeyUpsertOne) SetAccountTagSelector(v domain.AccountTagSelector) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAccountTagSelector(v)
+	})
+}
+
+// UpdateAccountTagSelector sets the "account_tag_selector" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateAccountTagSelector() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAccountTagSelector()
+	})
+}
+
+// SetAllowedPlatforms sets the "allowed_platforms" field.
+func (u *APIKeyUpsertOne) SetAllowedPlatforms(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedPlatforms(v)
+	})
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsertOne) SetAllowedModels(v []string) *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedModels(v)
+	})
+}
+
+// UpdateAllowedPlatforms sets the "allowed_platforms" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateAllowedPlatforms() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedPlatforms()
+	})
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsertOne) UpdateAllowedModels() *APIKeyUpsertOne {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedModels()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1921,6 +2642,188 @@ func (u *APIKeyUpsertBulk) ClearIPBlacklist() *APIKeyUpsertBulk {
 	})
 }
 
+// SetScopes sets the "scopes" field.
+func (u *APIKeyUpsertBulk) SetScopes(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetScopes(v)
+	})
+}
+
+// UpdateScopes sets the "scopes" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateScopes() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateScopes()
+	})
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (u *APIKeyUpsertBulk) ClearScopes() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.ClearScopes()
+	})
+}
+
+// SetPriority sets the "priority" field.
+func (u *APIKeyUpsertBulk) SetPriority(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetPriority(v)
+	})
+}
+
+// UpdatePriority sets the "priority" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdatePriority() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdatePriority()
+	})
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (u *APIKeyUpsertBulk) SetModerationMode(v string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetModerationMode(v)
+	})
+}
+
+// UpdateModerationMode sets the "moderation_mode" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateModerationMode() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateModerationMode()
+	})
+}
+
+// SetContextAutoTrim sets the "context_auto_trim" field.
+func (u *APIKeyUpsertBulk) SetContextAutoTrim(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetContextAutoTrim(v)
+	})
+}
+
+// UpdateContextAutoTrim sets the "context_auto_trim" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateContextAutoTrim() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateContextAutoTrim()
+	})
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (u *APIKeyUpsertBulk) SetMaxBodySize(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetMaxBodySize(v)
+	})
+}
+
+// SetParentID sets the "parent_id" field.
+func (u *APIKeyUpsertBulk) SetParentID(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetParentID(v)
+	})
+}
+
+// AddMaxBodySize adds v to the "max_body_size" field.
+func (u *APIKeyUpsertBulk) AddMaxBodySize(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddMaxBodySize(v)
+	})
+}
+
+// AddParentID adds v to the "parent_id" field.
+func (u *APIKeyUpsertBulk) AddParentID(v int64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddParentID(v)
+	})
+}
+
+// UpdateMaxBodySize sets the "max_body_size" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateMaxBodySize() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateMaxBodySize()
+	})
+}
+
+// UpdateParentID sets the "parent_id" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateParentID() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateParentID()
+	})
+}
+
+// SetRequestCoalescing sets the "request_coalescing" field.
+func (u *APIKeyUpsertBulk) SetRequestCoalescing(v bool) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetRequestCoalescing(v)
+	})
+}
+
+// UpdateRequestCoalescing sets the "request_coalescing" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateRequestCoalescing() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateRequestCoalescing()
+	})
+}
+
+// SetTokenBucketBurst sets the "token_bucket_burst" field.
+func (u *APIKeyUpsertBulk) SetTokenBucketBurst(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTokenBucketBurst(v)
+	})
+}
+
+// AddTokenBucketBurst adds v to the "token_bucket_burst" field.
+func (u *APIKeyUpsertBulk) AddTokenBucketBurst(v int) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTokenBucketBurst(v)
+	})
+}
+
+// UpdateTokenBucketBurst sets the "token_bucket_burst" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateTokenBucketBurst() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTokenBucketBurst()
+	})
+}
+
+// SetTokenBucketRefillRate sets the "token_bucket_refill_rate" field.
+func (u *APIKeyUpsertBulk) SetTokenBucketRefillRate(v float64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetTokenBucketRefillRate(v)
+	})
+}
+
+// AddTokenBucketRefillRate adds v to the "token_bucket_refill_rate" field.
+func (u *APIKeyUpsertBulk) AddTokenBucketRefillRate(v float64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddTokenBucketRefillRate(v)
+	})
+}
+
+// UpdateTokenBucketRefillRate sets the "token_bucket_refill_rate" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateTokenBucketRefillRate() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateTokenBucketRefillRate()
+	})
+}
+
+// SetMaxCostPerRequest sets the "max_cost_per_request" field.
+func (u *APIKeyUpsertBulk) SetMaxCostPerRequest(v float64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetMaxCostPerRequest(v)
+	})
+}
+
+// AddMaxCostPerRequest adds v to the "max_cost_per_request" field.
+func (u *APIKeyUpsertBulk) AddMaxCostPerRequest(v float64) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.AddMaxCostPerRequest(v)
+	})
+}
+
+// UpdateMaxCostPerRequest sets the "max_cost_per_request" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateMaxCostPerRequest() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateMaxCostPerRequest()
+	})
+}
+
 // SetQuota sets the "quota" field.
 func (u *APIKeyUpsertBulk) SetQuota(v float64) *APIKeyUpsertBulk {
 	return u.Update(func(s *APIKeyUpsert) {
@@ -2173,6 +3076,48 @@ func (u *APIKeyUpsertBulk) ClearWindow7dStart() *APIKeyUpsertBulk {
 	})
 }
 
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (u *APIKeyUpsertBulk) SetAccountTagSelector(v domain.AccountTagSelector) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAccountTagSelector(v)
+	})
+}
+
+// UpdateAccountTagSelector sets the "account_tag_selector" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateAccountTagSelector() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAccountTagSelector()
+	})
+}
+
+// SetAllowedPlatforms sets the "allowed_platforms" field.
+func (u *APIKeyUpsertBulk) SetAllowedPlatforms(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedPlatforms(v)
+	})
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (u *APIKeyUpsertBulk) SetAllowedModels(v []string) *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.SetAllowedModels(v)
+	})
+}
+
+// UpdateAllowedPlatforms sets the "allowed_platforms" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateAllowedPlatforms() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedPlatforms()
+	})
+}
+
+// UpdateAllowedModels sets the "allowed_models" field to the value that was provided on create.
+func (u *APIKeyUpsertBulk) UpdateAllowedModels() *APIKeyUpsertBulk {
+	return u.Update(func(s *APIKeyUpsert) {
+		s.UpdateAllowedModels()
+	})
+}
+
 // Exec executes the query.
 func (u *APIKeyUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
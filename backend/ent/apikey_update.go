@@ -17,6 +17,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/predicate"
 	"github.com/Wei-Shaw/sub2api/ent/usagelog"
 	"github.com/Wei-Shaw/sub2api/ent/user"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
 )
 
 // APIKeyUpdate is the builder for updating APIKey entities.
@@ -190,6 +191,185 @@ func (_u *APIKeyUpdate) ClearIPBlacklist() *APIKeyUpdate {
 	return _u
 }
 
+// SetScopes sets the "scopes" field.
+func (_u *APIKeyUpdate) SetScopes(v []string) *APIKeyUpdate {
+	_u.mutation.SetScopes(v)
+	return _u
+}
+
+// AppendScopes appends value to the "scopes" field.
+func (_u *APIKeyUpdate) AppendScopes(v []string) *APIKeyUpdate {
+	_u.mutation.AppendScopes(v)
+	return _u
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (_u *APIKeyUpdate) ClearScopes() *APIKeyUpdate {
+	_u.mutation.ClearScopes()
+	return _u
+}
+
+// SetPriority sets the "priority" field.
+func (_u *APIKeyUpdate) SetPriority(v string) *APIKeyUpdate {
+	_u.mutation.SetPriority(v)
+	return _u
+}
+
+// SetNillablePriority sets the "priority" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillablePriority(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetPriority(*v)
+	}
+	return _u
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (_u *APIKeyUpdate) SetModerationMode(v string) *APIKeyUpdate {
+	_u.mutation.SetModerationMode(v)
+	return _u
+}
+
+// SetNillableModerationMode sets the "moderation_mode" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableModerationMode(v *string) *APIKeyUpdate {
+	if v != nil {
+		_u.SetModerationMode(*v)
+	}
+	return _u
+}
+
+// SetContextAutoTrim sets the "context_auto_trim" field.
+func (_u *APIKeyUpdate) SetContextAutoTrim(v bool) *APIKeyUpdate {
+	_u.mutation.SetContextAutoTrim(v)
+	return _u
+}
+
+// SetNillableContextAutoTrim sets the "context_auto_trim" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableContextAutoTrim(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetContextAutoTrim(*v)
+	}
+	return _u
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (_u *APIKeyUpdate) SetMaxBodySize(v int64) *APIKeyUpdate {
+	_u.mutation.ResetMaxBodySize()
+	_u.mutation.SetMaxBodySize(v)
+	return _u
+}
+
+// SetParentID sets the "parent_id" field.
+func (_u *APIKeyUpdate) SetParentID(v int64) *APIKeyUpdate {
+	_u.mutation.ResetParentID()
+	_u.mutation.SetParentID(v)
+	return _u
+}
+
+// SetNillableMaxBodySize sets the "max_body_size" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableMaxBodySize(v *int64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetMaxBodySize(*v)
+	}
+	return _u
+}
+
+// SetNillableParentID sets the "parent_id" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableParentID(v *int64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetParentID(*v)
+	}
+	return _u
+}
+
+// AddMaxBodySize adds value to the "max_body_size" field.
+func (_u *APIKeyUpdate) AddMaxBodySize(v int64) *APIKeyUpdate {
+	_u.mutation.AddMaxBodySize(v)
+	return _u
+}
+
+// AddParentID adds value to the "parent_id" field.
+func (_u *APIKeyUpdate) AddParentID(v int64) *APIKeyUpdate {
+	_u.mutation.AddParentID(v)
+	return _u
+}
+
+// SetRequestCoalescing sets the "request_coalescing" field.
+func (_u *APIKeyUpdate) SetRequestCoalescing(v bool) *APIKeyUpdate {
+	_u.mutation.SetRequestCoalescing(v)
+	return _u
+}
+
+// SetNillableRequestCoalescing sets the "request_coalescing" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableRequestCoalescing(v *bool) *APIKeyUpdate {
+	if v != nil {
+		_u.SetRequestCoalescing(*v)
+	}
+	return _u
+}
+
+// SetTokenBucketBurst sets the "token_bucket_burst" field.
+func (_u *APIKeyUpdate) SetTokenBucketBurst(v int) *APIKeyUpdate {
+	_u.mutation.ResetTokenBucketBurst()
+	_u.mutation.SetTokenBucketBurst(v)
+	return _u
+}
+
+// SetNillableTokenBucketBurst sets the "token_bucket_burst" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableTokenBucketBurst(v *int) *APIKeyUpdate {
+	if v != nil {
+		_u.SetTokenBucketBurst(*v)
+	}
+	return _u
+}
+
+// AddTokenBucketBurst adds value to the "token_bucket_burst" field.
+func (_u *APIKeyUpdate) AddTokenBucketBurst(v int) *APIKeyUpdate {
+	_u.mutation.AddTokenBucketBurst(v)
+	return _u
+}
+
+// SetTokenBucketRefillRate sets the "token_bucket_refill_rate" field.
+func (_u *APIKeyUpdate) SetTokenBucketRefillRate(v float64) *APIKeyUpdate {
+	_u.mutation.ResetTokenBucketRefillRate()
+	_u.mutation.SetTokenBucketRefillRate(v)
+	return _u
+}
+
+// SetNillableTokenBucketRefillRate sets the "token_bucket_refill_rate" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableTokenBucketRefillRate(v *float64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetTokenBucketRefillRate(*v)
+	}
+	return _u
+}
+
+// AddTokenBucketRefillRate adds value to the "token_bucket_refill_rate" field.
+func (_u *APIKeyUpdate) AddTokenBucketRefillRate(v float64) *APIKeyUpdate {
+	_u.mutation.AddTokenBucketRefillRate(v)
+	return _u
+}
+
+// SetMaxCostPerRequest sets the "max_cost_per_request" field.
+func (_u *APIKeyUpdate) SetMaxCostPerRequest(v float64) *APIKeyUpdate {
+	_u.mutation.ResetMaxCostPerRequest()
+	_u.mutation.SetMaxCostPerRequest(v)
+	return _u
+}
+
+// SetNillableMaxCostPerRequest sets the "max_cost_per_request" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableMaxCostPerRequest(v *float64) *APIKeyUpdate {
+	if v != nil {
+		_u.SetMaxCostPerRequest(*v)
+	}
+	return _u
+}
+
+// AddMaxCostPerRequest adds value to the "max_cost_per_request" field.
+func (_u *APIKeyUpdate) AddMaxCostPerRequest(v float64) *APIKeyUpdate {
+	_u.mutation.AddMaxCostPerRequest(v)
+	return _u
+}
+
 // SetQuota sets the "quota" field.
 func (_u *APIKeyUpdate) SetQuota(v float64) *APIKeyUpdate {
 	_u.mutation.ResetQuota()
@@ -438,6 +618,44 @@ func (_u *APIKeyUpdate) ClearWindow7dStart() *APIKeyUpdate {
 	return _u
 }
 
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (_u *APIKeyUpdate) SetAccountTagSelector(v domain.AccountTagSelector) *APIKeyUpdate {
+	_u.mutation.SetAccountTagSelector(v)
+	return _u
+}
+
+// SetNillableAccountTagSelector sets the "account_tag_selector" field if the given value is not nil.
+func (_u *APIKeyUpdate) SetNillableAccountTagSelector(v *domain.AccountTagSelector) *APIKeyUpdate {
+	if v != nil {
+		_u.SetAccountTagSelector(*v)
+	}
+	return _u
+}
+
+// SetAllowedPlatforms sets the "allowed_platforms" field.
+func (_u *APIKeyUpdate) SetAllowedPlatforms(v []string) *APIKeyUpdate {
+	_u.mutation.SetAllowedPlatforms(v)
+	return _u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_u *APIKeyUpdate) SetAllowedModels(v []string) *APIKeyUpdate {
+	_u.mutation.SetAllowedModels(v)
+	return _u
+}
+
+// AppendAllowedPlatforms appends value to the "allowed_platforms" field.
+func (_u *APIKeyUpdate) AppendAllowedPlatforms(v []string) *APIKeyUpdate {
+	_u.mutation.AppendAllowedPlatforms(v)
+	return _u
+}
+
+// AppendAllowedModels appends value to the "allowed_models" field.
+func (_u *APIKeyUpdate) AppendAllowedModels(v []string) *APIKeyUpdate {
+	_u.mutation.AppendAllowedModels(v)
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdate) SetUser(v *User) *APIKeyUpdate {
 	return _u.SetUserID(v.ID)
@@ -560,6 +778,16 @@ func (_u *APIKeyUpdate) check() error {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.Priority(); ok {
+		if err := apikey.PriorityValidator(v); err != nil {
+			return &ValidationError{Name: "priority", err: fmt.Errorf(`ent: validator failed for field "APIKey.priority": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.ModerationMode(); ok {
+		if err := apikey.ModerationModeValidator(v); err != nil {
+			return &ValidationError{Name: "moderation_mode", err: fmt.Errorf(`ent: validator failed for field "APIKey.moderation_mode": %w`, err)}
+		}
+	}
 	if _u.mutation.UserCleared() && len(_u.mutation.UserIDs()) > 0 {
 		return errors.New(`ent: clearing a required unique edge "APIKey.user"`)
 	}
@@ -624,6 +852,59 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.IPBlacklistCleared() {
 		_spec.ClearField(apikey.FieldIPBlacklist, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.Scopes(); ok {
+		_spec.SetField(apikey.FieldScopes, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedScopes(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldScopes, value)
+		})
+	}
+	if _u.mutation.ScopesCleared() {
+		_spec.ClearField(apikey.FieldScopes, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.Priority(); ok {
+		_spec.SetField(apikey.FieldPriority, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ModerationMode(); ok {
+		_spec.SetField(apikey.FieldModerationMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ContextAutoTrim(); ok {
+		_spec.SetField(apikey.FieldContextAutoTrim, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.MaxBodySize(); ok {
+		_spec.SetField(apikey.FieldMaxBodySize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.ParentID(); ok {
+		_spec.SetField(apikey.FieldParentID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedMaxBodySize(); ok {
+		_spec.AddField(apikey.FieldMaxBodySize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedParentID(); ok {
+		_spec.AddField(apikey.FieldParentID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.RequestCoalescing(); ok {
+		_spec.SetField(apikey.FieldRequestCoalescing, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.TokenBucketBurst(); ok {
+		_spec.SetField(apikey.FieldTokenBucketBurst, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedTokenBucketBurst(); ok {
+		_spec.AddField(apikey.FieldTokenBucketBurst, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.TokenBucketRefillRate(); ok {
+		_spec.SetField(apikey.FieldTokenBucketRefillRate, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedTokenBucketRefillRate(); ok {
+		_spec.AddField(apikey.FieldTokenBucketRefillRate, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.MaxCostPerRequest(); ok {
+		_spec.SetField(apikey.FieldMaxCostPerRequest, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedMaxCostPerRequest(); ok {
+		_spec.AddField(apikey.FieldMaxCostPerRequest, field.TypeFloat64, value)
+	}
 	if value, ok := _u.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 	}
@@ -696,6 +977,25 @@ func (_u *APIKeyUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.AccountTagSelector(); ok {
+		_spec.SetField(apikey.FieldAccountTagSelector, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AllowedPlatforms(); ok {
+		_spec.SetField(apikey.FieldAllowedPlatforms, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedPlatforms(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedPlatforms, value)
+		})
+	}
+	if value, ok := _u.mutation.AppendedAllowedModels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedModels, value)
+		})
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -977,6 +1277,185 @@ func (_u *APIKeyUpdateOne) ClearIPBlacklist() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetScopes sets the "scopes" field.
+func (_u *APIKeyUpdateOne) SetScopes(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetScopes(v)
+	return _u
+}
+
+// AppendScopes appends value to the "scopes" field.
+func (_u *APIKeyUpdateOne) AppendScopes(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendScopes(v)
+	return _u
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (_u *APIKeyUpdateOne) ClearScopes() *APIKeyUpdateOne {
+	_u.mutation.ClearScopes()
+	return _u
+}
+
+// SetPriority sets the "priority" field.
+func (_u *APIKeyUpdateOne) SetPriority(v string) *APIKeyUpdateOne {
+	_u.mutation.SetPriority(v)
+	return _u
+}
+
+// SetNillablePriority sets the "priority" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillablePriority(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetPriority(*v)
+	}
+	return _u
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (_u *APIKeyUpdateOne) SetModerationMode(v string) *APIKeyUpdateOne {
+	_u.mutation.SetModerationMode(v)
+	return _u
+}
+
+// SetNillableModerationMode sets the "moderation_mode" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableModerationMode(v *string) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetModerationMode(*v)
+	}
+	return _u
+}
+
+// SetContextAutoTrim sets the "context_auto_trim" field.
+func (_u *APIKeyUpdateOne) SetContextAutoTrim(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetContextAutoTrim(v)
+	return _u
+}
+
+// SetNillableContextAutoTrim sets the "context_auto_trim" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableContextAutoTrim(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetContextAutoTrim(*v)
+	}
+	return _u
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (_u *APIKeyUpdateOne) SetMaxBodySize(v int64) *APIKeyUpdateOne {
+	_u.mutation.ResetMaxBodySize()
+	_u.mutation.SetMaxBodySize(v)
+	return _u
+}
+
+// SetParentID sets the "parent_id" field.
+func (_u *APIKeyUpdateOne) SetParentID(v int64) *APIKeyUpdateOne {
+	_u.mutation.ResetParentID()
+	_u.mutation.SetParentID(v)
+	return _u
+}
+
+// SetNillableMaxBodySize sets the "max_body_size" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableMaxBodySize(v *int64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetMaxBodySize(*v)
+	}
+	return _u
+}
+
+// SetNillableParentID sets the "parent_id" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableParentID(v *int64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetParentID(*v)
+	}
+	return _u
+}
+
+// AddMaxBodySize adds value to the "max_body_size" field.
+func (_u *APIKeyUpdateOne) AddMaxBodySize(v int64) *APIKeyUpdateOne {
+	_u.mutation.AddMaxBodySize(v)
+	return _u
+}
+
+// AddParentID adds value to the "parent_id" field.
+func (_u *APIKeyUpdateOne) AddParentID(v int64) *APIKeyUpdateOne {
+	_u.mutation.AddParentID(v)
+	return _u
+}
+
+// SetRequestCoalescing sets the "request_coalescing" field.
+func (_u *APIKeyUpdateOne) SetRequestCoalescing(v bool) *APIKeyUpdateOne {
+	_u.mutation.SetRequestCoalescing(v)
+	return _u
+}
+
+// SetNillableRequestCoalescing sets the "request_coalescing" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableRequestCoalescing(v *bool) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetRequestCoalescing(*v)
+	}
+	return _u
+}
+
+// SetTokenBucketBurst sets the "token_bucket_burst" field.
+func (_u *APIKeyUpdateOne) SetTokenBucketBurst(v int) *APIKeyUpdateOne {
+	_u.mutation.ResetTokenBucketBurst()
+	_u.mutation.SetTokenBucketBurst(v)
+	return _u
+}
+
+// SetNillableTokenBucketBurst sets the "token_bucket_burst" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableTokenBucketBurst(v *int) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetTokenBucketBurst(*v)
+	}
+	return _u
+}
+
+// AddTokenBucketBurst adds value to the "token_bucket_burst" field.
+func (_u *APIKeyUpdateOne) AddTokenBucketBurst(v int) *APIKeyUpdateOne {
+	_u.mutation.AddTokenBucketBurst(v)
+	return _u
+}
+
+// SetTokenBucketRefillRate sets the "token_bucket_refill_rate" field.
+func (_u *APIKeyUpdateOne) SetTokenBucketRefillRate(v float64) *APIKeyUpdateOne {
+	_u.mutation.ResetTokenBucketRefillRate()
+	_u.mutation.SetTokenBucketRefillRate(v)
+	return _u
+}
+
+// SetNillableTokenBucketRefillRate sets the "token_bucket_refill_rate" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableTokenBucketRefillRate(v *float64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetTokenBucketRefillRate(*v)
+	}
+	return _u
+}
+
+// AddTokenBucketRefillRate adds value to the "token_bucket_refill_rate" field.
+func (_u *APIKeyUpdateOne) AddTokenBucketRefillRate(v float64) *APIKeyUpdateOne {
+	_u.mutation.AddTokenBucketRefillRate(v)
+	return _u
+}
+
+// SetMaxCostPerRequest sets the "max_cost_per_request" field.
+func (_u *APIKeyUpdateOne) SetMaxCostPerRequest(v float64) *APIKeyUpdateOne {
+	_u.mutation.ResetMaxCostPerRequest()
+	_u.mutation.SetMaxCostPerRequest(v)
+	return _u
+}
+
+// SetNillableMaxCostPerRequest sets the "max_cost_per_request" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableMaxCostPerRequest(v *float64) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetMaxCostPerRequest(*v)
+	}
+	return _u
+}
+
+// AddMaxCostPerRequest adds value to the "max_cost_per_request" field.
+func (_u *APIKeyUpdateOne) AddMaxCostPerRequest(v float64) *APIKeyUpdateOne {
+	_u.mutation.AddMaxCostPerRequest(v)
+	return _u
+}
+
 // SetQuota sets the "quota" field.
 func (_u *APIKeyUpdateOne) SetQuota(v float64) *APIKeyUpdateOne {
 	_u.mutation.ResetQuota()
@@ -1225,6 +1704,44 @@ func (_u *APIKeyUpdateOne) ClearWindow7dStart() *APIKeyUpdateOne {
 	return _u
 }
 
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (_u *APIKeyUpdateOne) SetAccountTagSelector(v domain.AccountTagSelector) *APIKeyUpdateOne {
+	_u.mutation.SetAccountTagSelector(v)
+	return _u
+}
+
+// SetNillableAccountTagSelector sets the "account_tag_selector" field if the given value is not nil.
+func (_u *APIKeyUpdateOne) SetNillableAccountTagSelector(v *domain.AccountTagSelector) *APIKeyUpdateOne {
+	if v != nil {
+		_u.SetAccountTagSelector(*v)
+	}
+	return _u
+}
+
+// SetAllowedPlatforms sets the "allowed_platforms" field.
+func (_u *APIKeyUpdateOne) SetAllowedPlatforms(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetAllowedPlatforms(v)
+	return _u
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (_u *APIKeyUpdateOne) SetAllowedModels(v []string) *APIKeyUpdateOne {
+	_u.mutation.SetAllowedModels(v)
+	return _u
+}
+
+// AppendAllowedPlatforms appends value to the "allowed_platforms" field.
+func (_u *APIKeyUpdateOne) AppendAllowedPlatforms(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendAllowedPlatforms(v)
+	return _u
+}
+
+// AppendAllowedModels appends value to the "allowed_models" field.
+func (_u *APIKeyUpdateOne) AppendAllowedModels(v []string) *APIKeyUpdateOne {
+	_u.mutation.AppendAllowedModels(v)
+	return _u
+}
+
 // SetUser sets the "user" edge to the User entity.
 func (_u *APIKeyUpdateOne) SetUser(v *User) *APIKeyUpdateOne {
 	return _u.SetUserID(v.ID)
@@ -1360,6 +1877,16 @@ func (_u *APIKeyUpdateOne) check() error {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "APIKey.status": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.Priority(); ok {
+		if err := apikey.PriorityValidator(v); err != nil {
+			return &ValidationError{Name: "priority", err: fmt.Errorf(`ent: validator failed for field "APIKey.priority": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.ModerationMode(); ok {
+		if err := apikey.ModerationModeValidator(v); err != nil {
+			return &ValidationError{Name: "moderation_mode", err: fmt.Errorf(`ent: validator failed for field "APIKey.moderation_mode": %w`, err)}
+		}
+	}
 	if _u.mutation.UserCleared() && len(_u.mutation.UserIDs()) > 0 {
 		return errors.New(`ent: clearing a required unique edge "APIKey.user"`)
 	}
@@ -1441,6 +1968,59 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.IPBlacklistCleared() {
 		_spec.ClearField(apikey.FieldIPBlacklist, field.TypeJSON)
 	}
+	if value, ok := _u.mutation.Scopes(); ok {
+		_spec.SetField(apikey.FieldScopes, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedScopes(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldScopes, value)
+		})
+	}
+	if _u.mutation.ScopesCleared() {
+		_spec.ClearField(apikey.FieldScopes, field.TypeJSON)
+	}
+	if value, ok := _u.mutation.Priority(); ok {
+		_spec.SetField(apikey.FieldPriority, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ModerationMode(); ok {
+		_spec.SetField(apikey.FieldModerationMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.ContextAutoTrim(); ok {
+		_spec.SetField(apikey.FieldContextAutoTrim, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.MaxBodySize(); ok {
+		_spec.SetField(apikey.FieldMaxBodySize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.ParentID(); ok {
+		_spec.SetField(apikey.FieldParentID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedMaxBodySize(); ok {
+		_spec.AddField(apikey.FieldMaxBodySize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedParentID(); ok {
+		_spec.AddField(apikey.FieldParentID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.RequestCoalescing(); ok {
+		_spec.SetField(apikey.FieldRequestCoalescing, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.TokenBucketBurst(); ok {
+		_spec.SetField(apikey.FieldTokenBucketBurst, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.AddedTokenBucketBurst(); ok {
+		_spec.AddField(apikey.FieldTokenBucketBurst, field.TypeInt, value)
+	}
+	if value, ok := _u.mutation.TokenBucketRefillRate(); ok {
+		_spec.SetField(apikey.FieldTokenBucketRefillRate, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedTokenBucketRefillRate(); ok {
+		_spec.AddField(apikey.FieldTokenBucketRefillRate, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.MaxCostPerRequest(); ok {
+		_spec.SetField(apikey.FieldMaxCostPerRequest, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedMaxCostPerRequest(); ok {
+		_spec.AddField(apikey.FieldMaxCostPerRequest, field.TypeFloat64, value)
+	}
 	if value, ok := _u.mutation.Quota(); ok {
 		_spec.SetField(apikey.FieldQuota, field.TypeFloat64, value)
 	}
@@ -1513,6 +2093,25 @@ func (_u *APIKeyUpdateOne) sqlSave(ctx context.Context) (_node *APIKey, err erro
 	if _u.mutation.Window7dStartCleared() {
 		_spec.ClearField(apikey.FieldWindow7dStart, field.TypeTime)
 	}
+	if value, ok := _u.mutation.AccountTagSelector(); ok {
+		_spec.SetField(apikey.FieldAccountTagSelector, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AllowedPlatforms(); ok {
+		_spec.SetField(apikey.FieldAllowedPlatforms, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AllowedModels(); ok {
+		_spec.SetField(apikey.FieldAllowedModels, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedAllowedPlatforms(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedPlatforms, value)
+		})
+	}
+	if value, ok := _u.mutation.AppendedAllowedModels(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, apikey.FieldAllowedModels, value)
+		})
+	}
 	if _u.mutation.UserCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
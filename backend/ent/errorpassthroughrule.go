@@ -47,7 +47,13 @@ type ErrorPassthroughRule struct {
 	// SkipMonitoring holds the value of the "skip_monitoring" field.
 	SkipMonitoring bool `json:"skip_monitoring,omitempty"`
 	// Description holds the value of the "description" field.
-	Description  *string `json:"description,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// ExpiresAt holds the value of the "expires_at" field.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// HitCount holds the value of the "hit_count" field.
+	HitCount int64 `json:"hit_count,omitempty"`
+	// LastHitAt holds the value of the "last_hit_at" field.
+	LastHitAt    *time.Time `json:"last_hit_at,omitempty"`
 	selectValues sql.SelectValues
 }
 
@@ -60,11 +66,11 @@ func (*ErrorPassthroughRule) scanValues(columns []string) ([]any, error) {
 			values[i] = new([]byte)
 		case errorpassthroughrule.FieldEnabled, errorpassthroughrule.FieldPassthroughCode, errorpassthroughrule.FieldPassthroughBody, errorpassthroughrule.FieldSkipMonitoring:
 			values[i] = new(sql.NullBool)
-		case errorpassthroughrule.FieldID, errorpassthroughrule.FieldPriority, errorpassthroughrule.FieldResponseCode:
+		case errorpassthroughrule.FieldID, errorpassthroughrule.FieldPriority, errorpassthroughrule.FieldResponseCode, errorpassthroughrule.FieldHitCount:
 			values[i] = new(sql.NullInt64)
 		case errorpassthroughrule.FieldName, errorpassthroughrule.FieldMatchMode, errorpassthroughrule.FieldCustomMessage, errorpassthroughrule.FieldDescription:
 			values[i] = new(sql.NullString)
-		case errorpassthroughrule.FieldCreatedAt, errorpassthroughrule.FieldUpdatedAt:
+		case errorpassthroughrule.FieldCreatedAt, errorpassthroughrule.FieldUpdatedAt, errorpassthroughrule.FieldExpiresAt, errorpassthroughrule.FieldLastHitAt:
 			values[i] = new(sql.NullTime)
 		default:
 			values[i] = new(sql.UnknownType)
@@ -186,6 +192,26 @@ func (_m *ErrorPassthroughRule) assignValues(columns []string, values []any) err
 				_m.Description = new(string)
 				*_m.Description = value.String
 			}
+		case errorpassthroughrule.FieldExpiresAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field expires_at", values[i])
+			} else if value.Valid {
+				_m.ExpiresAt = new(time.Time)
+				*_m.ExpiresAt = value.Time
+			}
+		case errorpassthroughrule.FieldHitCount:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field hit_count", values[i])
+			} else if value.Valid {
+				_m.HitCount = value.Int64
+			}
+		case errorpassthroughrule.FieldLastHitAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field last_hit_at", values[i])
+			} else if value.Valid {
+				_m.LastHitAt = new(time.Time)
+				*_m.LastHitAt = value.Time
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -272,6 +298,19 @@ func (_m *ErrorPassthroughRule) String() string {
 		builder.WriteString("description=")
 		builder.WriteString(*v)
 	}
+	builder.WriteString(", ")
+	if v := _m.ExpiresAt; v != nil {
+		builder.WriteString("expires_at=")
+		builder.WriteString(v.Format(time.ANSIC))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("hit_count=")
+	builder.WriteString(fmt.Sprintf("%v", _m.HitCount))
+	builder.WriteString(", ")
+	if v := _m.LastHitAt; v != nil {
+		builder.WriteString("last_hit_at=")
+		builder.WriteString(v.Format(time.ANSIC))
+	}
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -43,6 +43,12 @@ const (
 	FieldSkipMonitoring = "skip_monitoring"
 	// FieldDescription holds the string denoting the description field in the database.
 	FieldDescription = "description"
+	// FieldExpiresAt holds the string denoting the expires_at field in the database.
+	FieldExpiresAt = "expires_at"
+	// FieldHitCount holds the string denoting the hit_count field in the database.
+	FieldHitCount = "hit_count"
+	// FieldLastHitAt holds the string denoting the last_hit_at field in the database.
+	FieldLastHitAt = "last_hit_at"
 	// Table holds the table name of the errorpassthroughrule in the database.
 	Table = "error_passthrough_rules"
 )
@@ -65,6 +71,9 @@ var Columns = []string{
 	FieldCustomMessage,
 	FieldSkipMonitoring,
 	FieldDescription,
+	FieldExpiresAt,
+	FieldHitCount,
+	FieldLastHitAt,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -100,6 +109,8 @@ var (
 	DefaultPassthroughBody bool
 	// DefaultSkipMonitoring holds the default value on creation for the "skip_monitoring" field.
 	DefaultSkipMonitoring bool
+	// DefaultHitCount holds the default value on creation for the "hit_count" field.
+	DefaultHitCount int64
 )
 
 // OrderOption defines the ordering options for the ErrorPassthroughRule queries.
@@ -169,3 +180,18 @@ func BySkipMonitoring(opts ...sql.OrderTermOption) OrderOption {
 func ByDescription(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldDescription, opts...).ToFunc()
 }
+
+// ByExpiresAt orders the results by the expires_at field.
+func ByExpiresAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldExpiresAt, opts...).ToFunc()
+}
+
+// ByHitCount orders the results by the hit_count field.
+func ByHitCount(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldHitCount, opts...).ToFunc()
+}
+
+// ByLastHitAt orders the results by the last_hit_at field.
+func ByLastHitAt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldLastHitAt, opts...).ToFunc()
+}
@@ -114,6 +114,21 @@ func Description(v string) predicate.ErrorPassthroughRule {
 	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldDescription, v))
 }
 
+// ExpiresAt applies equality check predicate on the "expires_at" field. It's identical to ExpiresAtEQ.
+func ExpiresAt(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldExpiresAt, v))
+}
+
+// HitCount applies equality check predicate on the "hit_count" field. It's identical to HitCountEQ.
+func HitCount(v int64) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldHitCount, v))
+}
+
+// LastHitAt applies equality check predicate on the "last_hit_at" field. It's identical to LastHitAtEQ.
+func LastHitAt(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldLastHitAt, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.ErrorPassthroughRule {
 	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldCreatedAt, v))
@@ -634,6 +649,146 @@ func DescriptionContainsFold(v string) predicate.ErrorPassthroughRule {
 	return predicate.ErrorPassthroughRule(sql.FieldContainsFold(FieldDescription, v))
 }
 
+// ExpiresAtEQ applies the EQ predicate on the "expires_at" field.
+func ExpiresAtEQ(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldExpiresAt, v))
+}
+
+// ExpiresAtNEQ applies the NEQ predicate on the "expires_at" field.
+func ExpiresAtNEQ(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNEQ(FieldExpiresAt, v))
+}
+
+// ExpiresAtIn applies the In predicate on the "expires_at" field.
+func ExpiresAtIn(vs ...time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldIn(FieldExpiresAt, vs...))
+}
+
+// ExpiresAtNotIn applies the NotIn predicate on the "expires_at" field.
+func ExpiresAtNotIn(vs ...time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNotIn(FieldExpiresAt, vs...))
+}
+
+// ExpiresAtGT applies the GT predicate on the "expires_at" field.
+func ExpiresAtGT(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldGT(FieldExpiresAt, v))
+}
+
+// ExpiresAtGTE applies the GTE predicate on the "expires_at" field.
+func ExpiresAtGTE(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldGTE(FieldExpiresAt, v))
+}
+
+// ExpiresAtLT applies the LT predicate on the "expires_at" field.
+func ExpiresAtLT(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldLT(FieldExpiresAt, v))
+}
+
+// ExpiresAtLTE applies the LTE predicate on the "expires_at" field.
+func ExpiresAtLTE(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldLTE(FieldExpiresAt, v))
+}
+
+// ExpiresAtIsNil applies the IsNil predicate on the "expires_at" field.
+func ExpiresAtIsNil() predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldIsNull(FieldExpiresAt))
+}
+
+// ExpiresAtNotNil applies the NotNil predicate on the "expires_at" field.
+func ExpiresAtNotNil() predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNotNull(FieldExpiresAt))
+}
+
+// HitCountEQ applies the EQ predicate on the "hit_count" field.
+func HitCountEQ(v int64) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldHitCount, v))
+}
+
+// HitCountNEQ applies the NEQ predicate on the "hit_count" field.
+func HitCountNEQ(v int64) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNEQ(FieldHitCount, v))
+}
+
+// HitCountIn applies the In predicate on the "hit_count" field.
+func HitCountIn(vs ...int64) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldIn(FieldHitCount, vs...))
+}
+
+// HitCountNotIn applies the NotIn predicate on the "hit_count" field.
+func HitCountNotIn(vs ...int64) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNotIn(FieldHitCount, vs...))
+}
+
+// HitCountGT applies the GT predicate on the "hit_count" field.
+func HitCountGT(v int64) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldGT(FieldHitCount, v))
+}
+
+// HitCountGTE applies the GTE predicate on the "hit_count" field.
+func HitCountGTE(v int64) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldGTE(FieldHitCount, v))
+}
+
+// HitCountLT applies the LT predicate on the "hit_count" field.
+func HitCountLT(v int64) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldLT(FieldHitCount, v))
+}
+
+// HitCountLTE applies the LTE predicate on the "hit_count" field.
+func HitCountLTE(v int64) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldLTE(FieldHitCount, v))
+}
+
+// LastHitAtEQ applies the EQ predicate on the "last_hit_at" field.
+func LastHitAtEQ(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldEQ(FieldLastHitAt, v))
+}
+
+// LastHitAtNEQ applies the NEQ predicate on the "last_hit_at" field.
+func LastHitAtNEQ(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNEQ(FieldLastHitAt, v))
+}
+
+// LastHitAtIn applies the In predicate on the "last_hit_at" field.
+func LastHitAtIn(vs ...time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldIn(FieldLastHitAt, vs...))
+}
+
+// LastHitAtNotIn applies the NotIn predicate on the "last_hit_at" field.
+func LastHitAtNotIn(vs ...time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNotIn(FieldLastHitAt, vs...))
+}
+
+// LastHitAtGT applies the GT predicate on the "last_hit_at" field.
+func LastHitAtGT(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldGT(FieldLastHitAt, v))
+}
+
+// LastHitAtGTE applies the GTE predicate on the "last_hit_at" field.
+func LastHitAtGTE(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldGTE(FieldLastHitAt, v))
+}
+
+// LastHitAtLT applies the LT predicate on the "last_hit_at" field.
+func LastHitAtLT(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldLT(FieldLastHitAt, v))
+}
+
+// LastHitAtLTE applies the LTE predicate on the "last_hit_at" field.
+func LastHitAtLTE(v time.Time) predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldLTE(FieldLastHitAt, v))
+}
+
+// LastHitAtIsNil applies the IsNil predicate on the "last_hit_at" field.
+func LastHitAtIsNil() predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldIsNull(FieldLastHitAt))
+}
+
+// LastHitAtNotNil applies the NotNil predicate on the "last_hit_at" field.
+func LastHitAtNotNil() predicate.ErrorPassthroughRule {
+	return predicate.ErrorPassthroughRule(sql.FieldNotNull(FieldLastHitAt))
+}
+
 // And groups predicates with the AND operator between them.
 func And(predicates ...predicate.ErrorPassthroughRule) predicate.ErrorPassthroughRule {
 	return predicate.ErrorPassthroughRule(sql.AndPredicates(predicates...))
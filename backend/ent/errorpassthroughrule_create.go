@@ -200,6 +200,48 @@ func (_c *ErrorPassthroughRuleCreate) SetNillableDescription(v *string) *ErrorPa
 	return _c
 }
 
+// SetExpiresAt sets the "expires_at" field.
+func (_c *ErrorPassthroughRuleCreate) SetExpiresAt(v time.Time) *ErrorPassthroughRuleCreate {
+	_c.mutation.SetExpiresAt(v)
+	return _c
+}
+
+// SetNillableExpiresAt sets the "expires_at" field if the given value is not nil.
+func (_c *ErrorPassthroughRuleCreate) SetNillableExpiresAt(v *time.Time) *ErrorPassthroughRuleCreate {
+	if v != nil {
+		_c.SetExpiresAt(*v)
+	}
+	return _c
+}
+
+// SetHitCount sets the "hit_count" field.
+func (_c *ErrorPassthroughRuleCreate) SetHitCount(v int64) *ErrorPassthroughRuleCreate {
+	_c.mutation.SetHitCount(v)
+	return _c
+}
+
+// SetNillableHitCount sets the "hit_count" field if the given value is not nil.
+func (_c *ErrorPassthroughRuleCreate) SetNillableHitCount(v *int64) *ErrorPassthroughRuleCreate {
+	if v != nil {
+		_c.SetHitCount(*v)
+	}
+	return _c
+}
+
+// SetLastHitAt sets the "last_hit_at" field.
+func (_c *ErrorPassthroughRuleCreate) SetLastHitAt(v time.Time) *ErrorPassthroughRuleCreate {
+	_c.mutation.SetLastHitAt(v)
+	return _c
+}
+
+// SetNillableLastHitAt sets the "last_hit_at" field if the given value is not nil.
+func (_c *ErrorPassthroughRuleCreate) SetNillableLastHitAt(v *time.Time) *ErrorPassthroughRuleCreate {
+	if v != nil {
+		_c.SetLastHitAt(*v)
+	}
+	return _c
+}
+
 // Mutation returns the ErrorPassthroughRuleMutation object of the builder.
 func (_c *ErrorPassthroughRuleCreate) Mutation() *ErrorPassthroughRuleMutation {
 	return _c.mutation
@@ -267,6 +309,10 @@ func (_c *ErrorPassthroughRuleCreate) defaults() {
 		v := errorpassthroughrule.DefaultSkipMonitoring
 		_c.mutation.SetSkipMonitoring(v)
 	}
+	if _, ok := _c.mutation.HitCount(); !ok {
+		v := errorpassthroughrule.DefaultHitCount
+		_c.mutation.SetHitCount(v)
+	}
 }
 
 // check runs all checks and user-defined validators on the builder.
@@ -308,6 +354,9 @@ func (_c *ErrorPassthroughRuleCreate) check() error {
 	if _, ok := _c.mutation.SkipMonitoring(); !ok {
 		return &ValidationError{Name: "skip_monitoring", err: errors.New(`ent: missing required field "ErrorPassthroughRule.skip_monitoring"`)}
 	}
+	if _, ok := _c.mutation.HitCount(); !ok {
+		return &ValidationError{Name: "hit_count", err: errors.New(`ent: missing required field "ErrorPassthroughRule.hit_count"`)}
+	}
 	return nil
 }
 
@@ -395,6 +444,18 @@ func (_c *ErrorPassthroughRuleCreate) createSpec() (*ErrorPassthroughRule, *sqlg
 		_spec.SetField(errorpassthroughrule.FieldDescription, field.TypeString, value)
 		_node.Description = &value
 	}
+	if value, ok := _c.mutation.ExpiresAt(); ok {
+		_spec.SetField(errorpassthroughrule.FieldExpiresAt, field.TypeTime, value)
+		_node.ExpiresAt = &value
+	}
+	if value, ok := _c.mutation.HitCount(); ok {
+		_spec.SetField(errorpassthroughrule.FieldHitCount, field.TypeInt64, value)
+		_node.HitCount = value
+	}
+	if value, ok := _c.mutation.LastHitAt(); ok {
+		_spec.SetField(errorpassthroughrule.FieldLastHitAt, field.TypeTime, value)
+		_node.LastHitAt = &value
+	}
 	return _node, _spec
 }
 
@@ -663,6 +724,60 @@ func (u *ErrorPassthroughRuleUpsert) ClearDescription() *ErrorPassthroughRuleUps
 	return u
 }
 
+// SetExpiresAt sets the "expires_at" field.
+func (u *ErrorPassthroughRuleUpsert) SetExpiresAt(v time.Time) *ErrorPassthroughRuleUpsert {
+	u.Set(errorpassthroughrule.FieldExpiresAt, v)
+	return u
+}
+
+// UpdateExpiresAt sets the "expires_at" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsert) UpdateExpiresAt() *ErrorPassthroughRuleUpsert {
+	u.SetExcluded(errorpassthroughrule.FieldExpiresAt)
+	return u
+}
+
+// ClearExpiresAt clears the value of the "expires_at" field.
+func (u *ErrorPassthroughRuleUpsert) ClearExpiresAt() *ErrorPassthroughRuleUpsert {
+	u.SetNull(errorpassthroughrule.FieldExpiresAt)
+	return u
+}
+
+// SetHitCount sets the "hit_count" field.
+func (u *ErrorPassthroughRuleUpsert) SetHitCount(v int64) *ErrorPassthroughRuleUpsert {
+	u.Set(errorpassthroughrule.FieldHitCount, v)
+	return u
+}
+
+// UpdateHitCount sets the "hit_count" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsert) UpdateHitCount() *ErrorPassthroughRuleUpsert {
+	u.SetExcluded(errorpassthroughrule.FieldHitCount)
+	return u
+}
+
+// AddHitCount adds v to the "hit_count" field.
+func (u *ErrorPassthroughRuleUpsert) AddHitCount(v int64) *ErrorPassthroughRuleUpsert {
+	u.Add(errorpassthroughrule.FieldHitCount, v)
+	return u
+}
+
+// SetLastHitAt sets the "last_hit_at" field.
+func (u *ErrorPassthroughRuleUpsert) SetLastHitAt(v time.Time) *ErrorPassthroughRuleUpsert {
+	u.Set(errorpassthroughrule.FieldLastHitAt, v)
+	return u
+}
+
+// UpdateLastHitAt sets the "last_hit_at" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsert) UpdateLastHitAt() *ErrorPassthroughRuleUpsert {
+	u.SetExcluded(errorpassthroughrule.FieldLastHitAt)
+	return u
+}
+
+// ClearLastHitAt clears the value of the "last_hit_at" field.
+func (u *ErrorPassthroughRuleUpsert) ClearLastHitAt() *ErrorPassthroughRuleUpsert {
+	u.SetNull(errorpassthroughrule.FieldLastHitAt)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -960,6 +1075,69 @@ func (u *ErrorPassthroughRuleUpsertOne) ClearDescription() *ErrorPassthroughRule
 	})
 }
 
+// SetExpiresAt sets the "expires_at" field.
+func (u *ErrorPassthroughRuleUpsertOne) SetExpiresAt(v time.Time) *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.SetExpiresAt(v)
+	})
+}
+
+// UpdateExpiresAt sets the "expires_at" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsertOne) UpdateExpiresAt() *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.UpdateExpiresAt()
+	})
+}
+
+// ClearExpiresAt clears the value of the "expires_at" field.
+func (u *ErrorPassthroughRuleUpsertOne) ClearExpiresAt() *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.ClearExpiresAt()
+	})
+}
+
+// SetHitCount sets the "hit_count" field.
+func (u *ErrorPassthroughRuleUpsertOne) SetHitCount(v int64) *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.SetHitCount(v)
+	})
+}
+
+// AddHitCount adds v to the "hit_count" field.
+func (u *ErrorPassthroughRuleUpsertOne) AddHitCount(v int64) *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.AddHitCount(v)
+	})
+}
+
+// UpdateHitCount sets the "hit_count" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsertOne) UpdateHitCount() *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.UpdateHitCount()
+	})
+}
+
+// SetLastHitAt sets the "last_hit_at" field.
+func (u *ErrorPassthroughRuleUpsertOne) SetLastHitAt(v time.Time) *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.SetLastHitAt(v)
+	})
+}
+
+// UpdateLastHitAt sets the "last_hit_at" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsertOne) UpdateLastHitAt() *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.UpdateLastHitAt()
+	})
+}
+
+// ClearLastHitAt clears the value of the "last_hit_at" field.
+func (u *ErrorPassthroughRuleUpsertOne) ClearLastHitAt() *ErrorPassthroughRuleUpsertOne {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.ClearLastHitAt()
+	})
+}
+
 // Exec executes the query.
 func (u *ErrorPassthroughRuleUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1423,6 +1601,69 @@ func (u *ErrorPassthroughRuleUpsertBulk) ClearDescription() *ErrorPassthroughRul
 	})
 }
 
+// SetExpiresAt sets the "expires_at" field.
+func (u *ErrorPassthroughRuleUpsertBulk) SetExpiresAt(v time.Time) *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.SetExpiresAt(v)
+	})
+}
+
+// UpdateExpiresAt sets the "expires_at" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsertBulk) UpdateExpiresAt() *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.UpdateExpiresAt()
+	})
+}
+
+// ClearExpiresAt clears the value of the "expires_at" field.
+func (u *ErrorPassthroughRuleUpsertBulk) ClearExpiresAt() *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.ClearExpiresAt()
+	})
+}
+
+// SetHitCount sets the "hit_count" field.
+func (u *ErrorPassthroughRuleUpsertBulk) SetHitCount(v int64) *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.SetHitCount(v)
+	})
+}
+
+// AddHitCount adds v to the "hit_count" field.
+func (u *ErrorPassthroughRuleUpsertBulk) AddHitCount(v int64) *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.AddHitCount(v)
+	})
+}
+
+// UpdateHitCount sets the "hit_count" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsertBulk) UpdateHitCount() *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.UpdateHitCount()
+	})
+}
+
+// SetLastHitAt sets the "last_hit_at" field.
+func (u *ErrorPassthroughRuleUpsertBulk) SetLastHitAt(v time.Time) *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.SetLastHitAt(v)
+	})
+}
+
+// UpdateLastHitAt sets the "last_hit_at" field to the value that was provided on create.
+func (u *ErrorPassthroughRuleUpsertBulk) UpdateLastHitAt() *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.UpdateLastHitAt()
+	})
+}
+
+// ClearLastHitAt clears the value of the "last_hit_at" field.
+func (u *ErrorPassthroughRuleUpsertBulk) ClearLastHitAt() *ErrorPassthroughRuleUpsertBulk {
+	return u.Update(func(s *ErrorPassthroughRuleUpsert) {
+		s.ClearLastHitAt()
+	})
+}
+
 // Exec executes the query.
 func (u *ErrorPassthroughRuleUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -261,6 +261,67 @@ func (_u *ErrorPassthroughRuleUpdate) ClearDescription() *ErrorPassthroughRuleUp
 	return _u
 }
 
+// SetExpiresAt sets the "expires_at" field.
+func (_u *ErrorPassthroughRuleUpdate) SetExpiresAt(v time.Time) *ErrorPassthroughRuleUpdate {
+	_u.mutation.SetExpiresAt(v)
+	return _u
+}
+
+// SetNillableExpiresAt sets the "expires_at" field if the given value is not nil.
+func (_u *ErrorPassthroughRuleUpdate) SetNillableExpiresAt(v *time.Time) *ErrorPassthroughRuleUpdate {
+	if v != nil {
+		_u.SetExpiresAt(*v)
+	}
+	return _u
+}
+
+// ClearExpiresAt clears the value of the "expires_at" field.
+func (_u *ErrorPassthroughRuleUpdate) ClearExpiresAt() *ErrorPassthroughRuleUpdate {
+	_u.mutation.ClearExpiresAt()
+	return _u
+}
+
+// SetHitCount sets the "hit_count" field.
+func (_u *ErrorPassthroughRuleUpdate) SetHitCount(v int64) *ErrorPassthroughRuleUpdate {
+	_u.mutation.ResetHitCount()
+	_u.mutation.SetHitCount(v)
+	return _u
+}
+
+// SetNillableHitCount sets the "hit_count" field if the given value is not nil.
+func (_u *ErrorPassthroughRuleUpdate) SetNillableHitCount(v *int64) *ErrorPassthroughRuleUpdate {
+	if v != nil {
+		_u.SetHitCount(*v)
+	}
+	return _u
+}
+
+// AddHitCount adds value to the "hit_count" field.
+func (_u *ErrorPassthroughRuleUpdate) AddHitCount(v int64) *ErrorPassthroughRuleUpdate {
+	_u.mutation.AddHitCount(v)
+	return _u
+}
+
+// SetLastHitAt sets the "last_hit_at" field.
+func (_u *ErrorPassthroughRuleUpdate) SetLastHitAt(v time.Time) *ErrorPassthroughRuleUpdate {
+	_u.mutation.SetLastHitAt(v)
+	return _u
+}
+
+// SetNillableLastHitAt sets the "last_hit_at" field if the given value is not nil.
+func (_u *ErrorPassthroughRuleUpdate) SetNillableLastHitAt(v *time.Time) *ErrorPassthroughRuleUpdate {
+	if v != nil {
+		_u.SetLastHitAt(*v)
+	}
+	return _u
+}
+
+// ClearLastHitAt clears the value of the "last_hit_at" field.
+func (_u *ErrorPassthroughRuleUpdate) ClearLastHitAt() *ErrorPassthroughRuleUpdate {
+	_u.mutation.ClearLastHitAt()
+	return _u
+}
+
 // Mutation returns the ErrorPassthroughRuleMutation object of the builder.
 func (_u *ErrorPassthroughRuleUpdate) Mutation() *ErrorPassthroughRuleMutation {
 	return _u.mutation
@@ -410,6 +471,24 @@ func (_u *ErrorPassthroughRuleUpdate) sqlSave(ctx context.Context) (_node int, e
 	if _u.mutation.DescriptionCleared() {
 		_spec.ClearField(errorpassthroughrule.FieldDescription, field.TypeString)
 	}
+	if value, ok := _u.mutation.ExpiresAt(); ok {
+		_spec.SetField(errorpassthroughrule.FieldExpiresAt, field.TypeTime, value)
+	}
+	if _u.mutation.ExpiresAtCleared() {
+		_spec.ClearField(errorpassthroughrule.FieldExpiresAt, field.TypeTime)
+	}
+	if value, ok := _u.mutation.HitCount(); ok {
+		_spec.SetField(errorpassthroughrule.FieldHitCount, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedHitCount(); ok {
+		_spec.AddField(errorpassthroughrule.FieldHitCount, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.LastHitAt(); ok {
+		_spec.SetField(errorpassthroughrule.FieldLastHitAt, field.TypeTime, value)
+	}
+	if _u.mutation.LastHitAtCleared() {
+		_spec.ClearField(errorpassthroughrule.FieldLastHitAt, field.TypeTime)
+	}
 	if _node, err = sqlgraph.UpdateNodes(ctx, _u.driver, _spec); err != nil {
 		if _, ok := err.(*sqlgraph.NotFoundError); ok {
 			err = &NotFoundError{errorpassthroughrule.Label}
@@ -662,6 +741,67 @@ func (_u *ErrorPassthroughRuleUpdateOne) ClearDescription() *ErrorPassthroughRul
 	return _u
 }
 
+// SetExpiresAt sets the "expires_at" field.
+func (_u *ErrorPassthroughRuleUpdateOne) SetExpiresAt(v time.Time) *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.SetExpiresAt(v)
+	return _u
+}
+
+// SetNillableExpiresAt sets the "expires_at" field if the given value is not nil.
+func (_u *ErrorPassthroughRuleUpdateOne) SetNillableExpiresAt(v *time.Time) *ErrorPassthroughRuleUpdateOne {
+	if v != nil {
+		_u.SetExpiresAt(*v)
+	}
+	return _u
+}
+
+// ClearExpiresAt clears the value of the "expires_at" field.
+func (_u *ErrorPassthroughRuleUpdateOne) ClearExpiresAt() *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.ClearExpiresAt()
+	return _u
+}
+
+// SetHitCount sets the "hit_count" field.
+func (_u *ErrorPassthroughRuleUpdateOne) SetHitCount(v int64) *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.ResetHitCount()
+	_u.mutation.SetHitCount(v)
+	return _u
+}
+
+// SetNillableHitCount sets the "hit_count" field if the given value is not nil.
+func (_u *ErrorPassthroughRuleUpdateOne) SetNillableHitCount(v *int64) *ErrorPassthroughRuleUpdateOne {
+	if v != nil {
+		_u.SetHitCount(*v)
+	}
+	return _u
+}
+
+// AddHitCount adds value to the "hit_count" field.
+func (_u *ErrorPassthroughRuleUpdateOne) AddHitCount(v int64) *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.AddHitCount(v)
+	return _u
+}
+
+// SetLastHitAt sets the "last_hit_at" field.
+func (_u *ErrorPassthroughRuleUpdateOne) SetLastHitAt(v time.Time) *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.SetLastHitAt(v)
+	return _u
+}
+
+// SetNillableLastHitAt sets the "last_hit_at" field if the given value is not nil.
+func (_u *ErrorPassthroughRuleUpdateOne) SetNillableLastHitAt(v *time.Time) *ErrorPassthroughRuleUpdateOne {
+	if v != nil {
+		_u.SetLastHitAt(*v)
+	}
+	return _u
+}
+
+// ClearLastHitAt clears the value of the "last_hit_at" field.
+func (_u *ErrorPassthroughRuleUpdateOne) ClearLastHitAt() *ErrorPassthroughRuleUpdateOne {
+	_u.mutation.ClearLastHitAt()
+	return _u
+}
+
 // Mutation returns the ErrorPassthroughRuleMutation object of the builder.
 func (_u *ErrorPassthroughRuleUpdateOne) Mutation() *ErrorPassthroughRuleMutation {
 	return _u.mutation
@@ -841,6 +981,24 @@ func (_u *ErrorPassthroughRuleUpdateOne) sqlSave(ctx context.Context) (_node *Er
 	if _u.mutation.DescriptionCleared() {
 		_spec.ClearField(errorpassthroughrule.FieldDescription, field.TypeString)
 	}
+	if value, ok := _u.mutation.ExpiresAt(); ok {
+		_spec.SetField(errorpassthroughrule.FieldExpiresAt, field.TypeTime, value)
+	}
+	if _u.mutation.ExpiresAtCleared() {
+		_spec.ClearField(errorpassthroughrule.FieldExpiresAt, field.TypeTime)
+	}
+	if value, ok := _u.mutation.HitCount(); ok {
+		_spec.SetField(errorpassthroughrule.FieldHitCount, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedHitCount(); ok {
+		_spec.AddField(errorpassthroughrule.FieldHitCount, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.LastHitAt(); ok {
+		_spec.SetField(errorpassthroughrule.FieldLastHitAt, field.TypeTime, value)
+	}
+	if _u.mutation.LastHitAtCleared() {
+		_spec.ClearField(errorpassthroughrule.FieldLastHitAt, field.TypeTime)
+	}
 	_node = &ErrorPassthroughRule{config: _u.config}
 	_spec.Assign = _node.assignValues
 	_spec.ScanValues = _node.scanValues
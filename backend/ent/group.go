@@ -81,6 +81,26 @@ type Group struct {
 	MessagesDispatchModelConfig domain.OpenAIMessagesDispatchModelConfig `json:"messages_dispatch_model_config,omitempty"`
 	// 分组 RPM 上限，0 表示不限制；设置后接管该分组用户的限流
 	RpmLimit int `json:"rpm_limit,omitempty"`
+	// 并发等待队列优先级：high/normal/low
+	Priority string `json:"priority,omitempty"`
+	// 请求参数策略：默认/强制 temperature、top_p、reasoning_effort 与 max_tokens 上限
+	RequestParamOverrides domain.RequestParamOverrides `json:"request_param_overrides,omitempty"`
+	// 分组系统提示词，为空表示不注入
+	SystemPrompt string `json:"system_prompt,omitempty"`
+	// 分组系统提示词注入位置：prepend/append
+	SystemPromptMode string `json:"system_prompt_mode,omitempty"`
+	// 敏感信息过滤策略：空（关闭）/mask/reject
+	PiiRedactionMode string `json:"pii_redaction_mode,omitempty"`
+	// 请求体最大字节数（0 表示使用端点类别默认值）
+	MaxBodySize int64 `json:"max_body_size,omitempty"`
+	// 是否启用内容审核（API Key 可单独覆盖）
+	ModerationEnabled bool `json:"moderation_enabled,omitempty"`
+	// 自定义响应头：静态响应头与额外透传的上游响应头
+	ResponseHeaders domain.GroupResponseHeaders `json:"response_headers,omitempty"`
+	// 所属组织 ID，为空表示平台分组
+	OrganizationID *int64 `json:"organization_id,omitempty"`
+	// 账号标签选择器：require 全部命中、exclude 均不命中
+	AccountTagSelector domain.AccountTagSelector `json:"account_tag_selector,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the GroupQuery when eager-loading is set.
 	Edges        GroupEdges `json:"edges"`
@@ -187,15 +207,15 @@ func (*Group) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
-		case group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldMessagesDispatchModelConfig:
+		case group.FieldModelRouting, group.FieldSupportedModelScopes, group.FieldMessagesDispatchModelConfig, group.FieldRequestParamOverrides, group.FieldResponseHeaders, group.FieldAccountTagSelector:
 			values[i] = new([]byte)
-		case group.FieldIsExclusive, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet:
+		case group.FieldIsExclusive, group.FieldClaudeCodeOnly, group.FieldModelRoutingEnabled, group.FieldMcpXMLInject, group.FieldAllowMessagesDispatch, group.FieldRequireOauthOnly, group.FieldRequirePrivacySet, group.FieldModerationEnabled:
 			values[i] = new(sql.NullBool)
 		case group.FieldRateMultiplier, group.FieldDailyLimitUsd, group.FieldWeeklyLimitUsd, group.FieldMonthlyLimitUsd, group.FieldImagePrice1k, group.FieldImagePrice2k, group.FieldImagePrice4k:
 			values[i] = new(sql.NullFloat64)
-		case group.FieldID, group.FieldDefaultValidityDays, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldRpmLimit:
+		case group.FieldID, group.FieldDefaultValidityDays, group.FieldFallbackGroupID, group.FieldFallbackGroupIDOnInvalidRequest, group.FieldSortOrder, group.FieldRpmLimit, group.FieldMaxBodySize, group.FieldOrganizationID:
 			values[i] = new(sql.NullInt64)
-		case group.FieldName, group.FieldDescription, group.FieldStatus, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel:
+		case group.FieldName, group.FieldDescription, group.FieldStatus, group.FieldPlatform, group.FieldSubscriptionType, group.FieldDefaultMappedModel, group.FieldPriority, group.FieldSystemPrompt, group.FieldSystemPromptMode, group.FieldPiiRedactionMode:
 			values[i] = new(sql.NullString)
 		case group.FieldCreatedAt, group.FieldUpdatedAt, group.FieldDeletedAt:
 			values[i] = new(sql.NullTime)
@@ -422,6 +442,73 @@ func (_m *Group) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.RpmLimit = int(value.Int64)
 			}
+		case group.FieldPriority:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field priority", values[i])
+			} else if value.Valid {
+				_m.Priority = value.String
+			}
+		case group.FieldRequestParamOverrides:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field request_param_overrides", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.RequestParamOverrides); err != nil {
+					return fmt.Errorf("unmarshal field request_param_overrides: %w", err)
+				}
+			}
+		case group.FieldSystemPrompt:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field system_prompt", values[i])
+			} else if value.Valid {
+				_m.SystemPrompt = value.String
+			}
+		case group.FieldSystemPromptMode:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field system_prompt_mode", values[i])
+			} else if value.Valid {
+				_m.SystemPromptMode = value.String
+			}
+		case group.FieldPiiRedactionMode:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field pii_redaction_mode", values[i])
+			} else if value.Valid {
+				_m.PiiRedactionMode = value.String
+			}
+		case group.FieldMaxBodySize:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field max_body_size", values[i])
+			} else if value.Valid {
+				_m.MaxBodySize = value.Int64
+			}
+		case group.FieldModerationEnabled:
+			if value, ok := values[i].(*sql.NullBool); !ok {
+				return fmt.Errorf("unexpected type %T for field moderation_enabled", values[i])
+			} else if value.Valid {
+				_m.ModerationEnabled = value.Bool
+			}
+		case group.FieldResponseHeaders:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field response_headers", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.ResponseHeaders); err != nil {
+					return fmt.Errorf("unmarshal field response_headers: %w", err)
+				}
+			}
+		case group.FieldOrganizationID:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field organization_id", values[i])
+			} else if value.Valid {
+				_m.OrganizationID = new(int64)
+				*_m.OrganizationID = value.Int64
+			}
+		case group.FieldAccountTagSelector:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field account_tag_selector", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.AccountTagSelector); err != nil {
+					return fmt.Errorf("unmarshal field account_tag_selector: %w", err)
+				}
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -610,6 +697,38 @@ func (_m *Group) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("rpm_limit=")
 	builder.WriteString(fmt.Sprintf("%v", _m.RpmLimit))
+	builder.WriteString(", ")
+	builder.WriteString("priority=")
+	builder.WriteString(_m.Priority)
+	builder.WriteString(", ")
+	builder.WriteString("request_param_overrides=")
+	builder.WriteString(fmt.Sprintf("%v", _m.RequestParamOverrides))
+	builder.WriteString(", ")
+	builder.WriteString("system_prompt=")
+	builder.WriteString(_m.SystemPrompt)
+	builder.WriteString(", ")
+	builder.WriteString("system_prompt_mode=")
+	builder.WriteString(_m.SystemPromptMode)
+	builder.WriteString(", ")
+	builder.WriteString("pii_redaction_mode=")
+	builder.WriteString(_m.PiiRedactionMode)
+	builder.WriteString(", ")
+	builder.WriteString("max_body_size=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MaxBodySize))
+	builder.WriteString(", ")
+	builder.WriteString("moderation_enabled=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ModerationEnabled))
+	builder.WriteString(", ")
+	builder.WriteString("response_headers=")
+	builder.WriteString(fmt.Sprintf("%v", _m.ResponseHeaders))
+	builder.WriteString(", ")
+	if v := _m.OrganizationID; v != nil {
+		builder.WriteString("organization_id=")
+		builder.WriteString(fmt.Sprintf("%v", *v))
+	}
+	builder.WriteString(", ")
+	builder.WriteString("account_tag_selector=")
+	builder.WriteString(fmt.Sprintf("%v", _m.AccountTagSelector))
 	builder.WriteByte(')')
 	return builder.String()
 }
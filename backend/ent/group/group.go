@@ -78,6 +78,26 @@ const (
 	FieldMessagesDispatchModelConfig = "messages_dispatch_model_config"
 	// FieldRpmLimit holds the string denoting the rpm_limit field in the database.
 	FieldRpmLimit = "rpm_limit"
+	// FieldPriority holds the string denoting the priority field in the database.
+	FieldPriority = "priority"
+	// FieldRequestParamOverrides holds the string denoting the request_param_overrides field in the database.
+	FieldRequestParamOverrides = "request_param_overrides"
+	// FieldSystemPrompt holds the string denoting the system_prompt field in the database.
+	FieldSystemPrompt = "system_prompt"
+	// FieldSystemPromptMode holds the string denoting the system_prompt_mode field in the database.
+	FieldSystemPromptMode = "system_prompt_mode"
+	// FieldPiiRedactionMode holds the string denoting the pii_redaction_mode field in the database.
+	FieldPiiRedactionMode = "pii_redaction_mode"
+	// FieldMaxBodySize holds the string denoting the max_body_size field in the database.
+	FieldMaxBodySize = "max_body_size"
+	// FieldModerationEnabled holds the string denoting the moderation_enabled field in the database.
+	FieldModerationEnabled = "moderation_enabled"
+	// FieldResponseHeaders holds the string denoting the response_headers field in the database.
+	FieldResponseHeaders = "response_headers"
+	// FieldOrganizationID holds the string denoting the organization_id field in the database.
+	FieldOrganizationID = "organization_id"
+	// FieldAccountTagSelector holds the string denoting the account_tag_selector field in the database.
+	FieldAccountTagSelector = "account_tag_selector"
 	// EdgeAPIKeys holds the string denoting the api_keys edge name in mutations.
 	EdgeAPIKeys = "api_keys"
 	// EdgeRedeemCodes holds the string denoting the redeem_codes edge name in mutations.
@@ -184,6 +204,16 @@ var Columns = []string{
 	FieldDefaultMappedModel,
 	FieldMessagesDispatchModelConfig,
 	FieldRpmLimit,
+	FieldPriority,
+	FieldRequestParamOverrides,
+	FieldSystemPrompt,
+	FieldSystemPromptMode,
+	FieldPiiRedactionMode,
+	FieldMaxBodySize,
+	FieldModerationEnabled,
+	FieldResponseHeaders,
+	FieldOrganizationID,
+	FieldAccountTagSelector,
 }
 
 var (
@@ -263,6 +293,30 @@ var (
 	DefaultMessagesDispatchModelConfig domain.OpenAIMessagesDispatchModelConfig
 	// DefaultRpmLimit holds the default value on creation for the "rpm_limit" field.
 	DefaultRpmLimit int
+	// DefaultPriority holds the default value on creation for the "priority" field.
+	DefaultPriority string
+	// PriorityValidator is a validator for the "priority" field. It is called by the builders before save.
+	PriorityValidator func(string) error
+	// DefaultRequestParamOverrides holds the default value on creation for the "request_param_overrides" field.
+	DefaultRequestParamOverrides domain.RequestParamOverrides
+	// DefaultSystemPrompt holds the default value on creation for the "system_prompt" field.
+	DefaultSystemPrompt string
+	// DefaultSystemPromptMode holds the default value on creation for the "system_prompt_mode" field.
+	DefaultSystemPromptMode string
+	// SystemPromptModeValidator is a validator for the "system_prompt_mode" field. It is called by the builders before save.
+	SystemPromptModeValidator func(string) error
+	// DefaultPiiRedactionMode holds the default value on creation for the "pii_redaction_mode" field.
+	DefaultPiiRedactionMode string
+	// PiiRedactionModeValidator is a validator for the "pii_redaction_mode" field. It is called by the builders before save.
+	PiiRedactionModeValidator func(string) error
+	// DefaultMaxBodySize holds the default value on creation for the "max_body_size" field.
+	DefaultMaxBodySize int64
+	// DefaultModerationEnabled holds the default value on creation for the "moderation_enabled" field.
+	DefaultModerationEnabled bool
+	// DefaultResponseHeaders holds the default value on creation for the "response_headers" field.
+	DefaultResponseHeaders domain.GroupResponseHeaders
+	// DefaultAccountTagSelector holds the default value on creation for the "account_tag_selector" field.
+	DefaultAccountTagSelector domain.AccountTagSelector
 )
 
 // OrderOption defines the ordering options for the Group queries.
@@ -413,6 +467,41 @@ func ByRpmLimit(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldRpmLimit, opts...).ToFunc()
 }
 
+// ByPriority orders the results by the priority field.
+func ByPriority(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPriority, opts...).ToFunc()
+}
+
+// BySystemPrompt orders the results by the system_prompt field.
+func BySystemPrompt(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSystemPrompt, opts...).ToFunc()
+}
+
+// BySystemPromptMode orders the results by the system_prompt_mode field.
+func BySystemPromptMode(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldSystemPromptMode, opts...).ToFunc()
+}
+
+// ByPiiRedactionMode orders the results by the pii_redaction_mode field.
+func ByPiiRedactionMode(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldPiiRedactionMode, opts...).ToFunc()
+}
+
+// ByMaxBodySize orders the results by the max_body_size field.
+func ByMaxBodySize(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMaxBodySize, opts...).ToFunc()
+}
+
+// ByModerationEnabled orders the results by the moderation_enabled field.
+func ByModerationEnabled(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldModerationEnabled, opts...).ToFunc()
+}
+
+// ByOrganizationID orders the results by the organization_id field.
+func ByOrganizationID(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldOrganizationID, opts...).ToFunc()
+}
+
 // ByAPIKeysCount orders the results by api_keys count.
 func ByAPIKeysCount(opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
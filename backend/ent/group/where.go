@@ -195,6 +195,41 @@ func RpmLimit(v int) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldRpmLimit, v))
 }
 
+// Priority applies equality check predicate on the "priority" field. It's identical to PriorityEQ.
+func Priority(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldPriority, v))
+}
+
+// SystemPrompt applies equality check predicate on the "system_prompt" field. It's identical to SystemPromptEQ.
+func SystemPrompt(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSystemPrompt, v))
+}
+
+// SystemPromptMode applies equality check predicate on the "system_prompt_mode" field. It's identical to SystemPromptModeEQ.
+func SystemPromptMode(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSystemPromptMode, v))
+}
+
+// PiiRedactionMode applies equality check predicate on the "pii_redaction_mode" field. It's identical to PiiRedactionModeEQ.
+func PiiRedactionMode(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldPiiRedactionMode, v))
+}
+
+// MaxBodySize applies equality check predicate on the "max_body_size" field. It's identical to MaxBodySizeEQ.
+func MaxBodySize(v int64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldMaxBodySize, v))
+}
+
+// ModerationEnabled applies equality check predicate on the "moderation_enabled" field. It's identical to ModerationEnabledEQ.
+func ModerationEnabled(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldModerationEnabled, v))
+}
+
+// OrganizationID applies equality check predicate on the "organization_id" field. It's identical to OrganizationIDEQ.
+func OrganizationID(v int64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldOrganizationID, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.Group {
 	return predicate.Group(sql.FieldEQ(FieldCreatedAt, v))
@@ -1365,6 +1400,366 @@ func RpmLimitLTE(v int) predicate.Group {
 	return predicate.Group(sql.FieldLTE(FieldRpmLimit, v))
 }
 
+// PriorityEQ applies the EQ predicate on the "priority" field.
+func PriorityEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldPriority, v))
+}
+
+// PriorityNEQ applies the NEQ predicate on the "priority" field.
+func PriorityNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldPriority, v))
+}
+
+// PriorityIn applies the In predicate on the "priority" field.
+func PriorityIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldPriority, vs...))
+}
+
+// PriorityNotIn applies the NotIn predicate on the "priority" field.
+func PriorityNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldPriority, vs...))
+}
+
+// PriorityGT applies the GT predicate on the "priority" field.
+func PriorityGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldPriority, v))
+}
+
+// PriorityGTE applies the GTE predicate on the "priority" field.
+func PriorityGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldPriority, v))
+}
+
+// PriorityLT applies the LT predicate on the "priority" field.
+func PriorityLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldPriority, v))
+}
+
+// PriorityLTE applies the LTE predicate on the "priority" field.
+func PriorityLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldPriority, v))
+}
+
+// PriorityContains applies the Contains predicate on the "priority" field.
+func PriorityContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldPriority, v))
+}
+
+// PriorityHasPrefix applies the HasPrefix predicate on the "priority" field.
+func PriorityHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldPriority, v))
+}
+
+// PriorityHasSuffix applies the HasSuffix predicate on the "priority" field.
+func PriorityHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldPriority, v))
+}
+
+// PriorityEqualFold applies the EqualFold predicate on the "priority" field.
+func PriorityEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldPriority, v))
+}
+
+// PriorityContainsFold applies the ContainsFold predicate on the "priority" field.
+func PriorityContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldPriority, v))
+}
+
+// SystemPromptEQ applies the EQ predicate on the "system_prompt" field.
+func SystemPromptEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSystemPrompt, v))
+}
+
+// SystemPromptNEQ applies the NEQ predicate on the "system_prompt" field.
+func SystemPromptNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldSystemPrompt, v))
+}
+
+// SystemPromptIn applies the In predicate on the "system_prompt" field.
+func SystemPromptIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldSystemPrompt, vs...))
+}
+
+// SystemPromptNotIn applies the NotIn predicate on the "system_prompt" field.
+func SystemPromptNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldSystemPrompt, vs...))
+}
+
+// SystemPromptGT applies the GT predicate on the "system_prompt" field.
+func SystemPromptGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldSystemPrompt, v))
+}
+
+// SystemPromptGTE applies the GTE predicate on the "system_prompt" field.
+func SystemPromptGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldSystemPrompt, v))
+}
+
+// SystemPromptLT applies the LT predicate on the "system_prompt" field.
+func SystemPromptLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldSystemPrompt, v))
+}
+
+// SystemPromptLTE applies the LTE predicate on the "system_prompt" field.
+func SystemPromptLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldSystemPrompt, v))
+}
+
+// SystemPromptContains applies the Contains predicate on the "system_prompt" field.
+func SystemPromptContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldSystemPrompt, v))
+}
+
+// SystemPromptHasPrefix applies the HasPrefix predicate on the "system_prompt" field.
+func SystemPromptHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldSystemPrompt, v))
+}
+
+// SystemPromptHasSuffix applies the HasSuffix predicate on the "system_prompt" field.
+func SystemPromptHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldSystemPrompt, v))
+}
+
+// SystemPromptEqualFold applies the EqualFold predicate on the "system_prompt" field.
+func SystemPromptEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldSystemPrompt, v))
+}
+
+// SystemPromptContainsFold applies the ContainsFold predicate on the "system_prompt" field.
+func SystemPromptContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldSystemPrompt, v))
+}
+
+// SystemPromptModeEQ applies the EQ predicate on the "system_prompt_mode" field.
+func SystemPromptModeEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldSystemPromptMode, v))
+}
+
+// SystemPromptModeNEQ applies the NEQ predicate on the "system_prompt_mode" field.
+func SystemPromptModeNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldSystemPromptMode, v))
+}
+
+// SystemPromptModeIn applies the In predicate on the "system_prompt_mode" field.
+func SystemPromptModeIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldSystemPromptMode, vs...))
+}
+
+// SystemPromptModeNotIn applies the NotIn predicate on the "system_prompt_mode" field.
+func SystemPromptModeNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldSystemPromptMode, vs...))
+}
+
+// SystemPromptModeGT applies the GT predicate on the "system_prompt_mode" field.
+func SystemPromptModeGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldSystemPromptMode, v))
+}
+
+// SystemPromptModeGTE applies the GTE predicate on the "system_prompt_mode" field.
+func SystemPromptModeGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldSystemPromptMode, v))
+}
+
+// SystemPromptModeLT applies the LT predicate on the "system_prompt_mode" field.
+func SystemPromptModeLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldSystemPromptMode, v))
+}
+
+// SystemPromptModeLTE applies the LTE predicate on the "system_prompt_mode" field.
+func SystemPromptModeLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldSystemPromptMode, v))
+}
+
+// SystemPromptModeContains applies the Contains predicate on the "system_prompt_mode" field.
+func SystemPromptModeContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldSystemPromptMode, v))
+}
+
+// SystemPromptModeHasPrefix applies the HasPrefix predicate on the "system_prompt_mode" field.
+func SystemPromptModeHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldSystemPromptMode, v))
+}
+
+// SystemPromptModeHasSuffix applies the HasSuffix predicate on the "system_prompt_mode" field.
+func SystemPromptModeHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldSystemPromptMode, v))
+}
+
+// SystemPromptModeEqualFold applies the EqualFold predicate on the "system_prompt_mode" field.
+func SystemPromptModeEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldSystemPromptMode, v))
+}
+
+// SystemPromptModeContainsFold applies the ContainsFold predicate on the "system_prompt_mode" field.
+func SystemPromptModeContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldSystemPromptMode, v))
+}
+
+// PiiRedactionModeEQ applies the EQ predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldPiiRedactionMode, v))
+}
+
+// PiiRedactionModeNEQ applies the NEQ predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeNEQ(v string) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldPiiRedactionMode, v))
+}
+
+// PiiRedactionModeIn applies the In predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldPiiRedactionMode, vs...))
+}
+
+// PiiRedactionModeNotIn applies the NotIn predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeNotIn(vs ...string) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldPiiRedactionMode, vs...))
+}
+
+// PiiRedactionModeGT applies the GT predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeGT(v string) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldPiiRedactionMode, v))
+}
+
+// PiiRedactionModeGTE applies the GTE predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeGTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldPiiRedactionMode, v))
+}
+
+// PiiRedactionModeLT applies the LT predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeLT(v string) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldPiiRedactionMode, v))
+}
+
+// PiiRedactionModeLTE applies the LTE predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeLTE(v string) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldPiiRedactionMode, v))
+}
+
+// PiiRedactionModeContains applies the Contains predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeContains(v string) predicate.Group {
+	return predicate.Group(sql.FieldContains(FieldPiiRedactionMode, v))
+}
+
+// PiiRedactionModeHasPrefix applies the HasPrefix predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeHasPrefix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasPrefix(FieldPiiRedactionMode, v))
+}
+
+// PiiRedactionModeHasSuffix applies the HasSuffix predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeHasSuffix(v string) predicate.Group {
+	return predicate.Group(sql.FieldHasSuffix(FieldPiiRedactionMode, v))
+}
+
+// PiiRedactionModeEqualFold applies the EqualFold predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeEqualFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldEqualFold(FieldPiiRedactionMode, v))
+}
+
+// PiiRedactionModeContainsFold applies the ContainsFold predicate on the "pii_redaction_mode" field.
+func PiiRedactionModeContainsFold(v string) predicate.Group {
+	return predicate.Group(sql.FieldContainsFold(FieldPiiRedactionMode, v))
+}
+
+// MaxBodySizeEQ applies the EQ predicate on the "max_body_size" field.
+func MaxBodySizeEQ(v int64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldMaxBodySize, v))
+}
+
+// MaxBodySizeNEQ applies the NEQ predicate on the "max_body_size" field.
+func MaxBodySizeNEQ(v int64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldMaxBodySize, v))
+}
+
+// MaxBodySizeIn applies the In predicate on the "max_body_size" field.
+func MaxBodySizeIn(vs ...int64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldMaxBodySize, vs...))
+}
+
+// MaxBodySizeNotIn applies the NotIn predicate on the "max_body_size" field.
+func MaxBodySizeNotIn(vs ...int64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldMaxBodySize, vs...))
+}
+
+// MaxBodySizeGT applies the GT predicate on the "max_body_size" field.
+func MaxBodySizeGT(v int64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldMaxBodySize, v))
+}
+
+// MaxBodySizeGTE applies the GTE predicate on the "max_body_size" field.
+func MaxBodySizeGTE(v int64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldMaxBodySize, v))
+}
+
+// MaxBodySizeLT applies the LT predicate on the "max_body_size" field.
+func MaxBodySizeLT(v int64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldMaxBodySize, v))
+}
+
+// MaxBodySizeLTE applies the LTE predicate on the "max_body_size" field.
+func MaxBodySizeLTE(v int64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldMaxBodySize, v))
+}
+
+// ModerationEnabledEQ applies the EQ predicate on the "moderation_enabled" field.
+func ModerationEnabledEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldModerationEnabled, v))
+}
+
+// ModerationEnabledNEQ applies the NEQ predicate on the "moderation_enabled" field.
+func ModerationEnabledNEQ(v bool) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldModerationEnabled, v))
+}
+
+// OrganizationIDEQ applies the EQ predicate on the "organization_id" field.
+func OrganizationIDEQ(v int64) predicate.Group {
+	return predicate.Group(sql.FieldEQ(FieldOrganizationID, v))
+}
+
+// OrganizationIDNEQ applies the NEQ predicate on the "organization_id" field.
+func OrganizationIDNEQ(v int64) predicate.Group {
+	return predicate.Group(sql.FieldNEQ(FieldOrganizationID, v))
+}
+
+// OrganizationIDIn applies the In predicate on the "organization_id" field.
+func OrganizationIDIn(vs ...int64) predicate.Group {
+	return predicate.Group(sql.FieldIn(FieldOrganizationID, vs...))
+}
+
+// OrganizationIDNotIn applies the NotIn predicate on the "organization_id" field.
+func OrganizationIDNotIn(vs ...int64) predicate.Group {
+	return predicate.Group(sql.FieldNotIn(FieldOrganizationID, vs...))
+}
+
+// OrganizationIDGT applies the GT predicate on the "organization_id" field.
+func OrganizationIDGT(v int64) predicate.Group {
+	return predicate.Group(sql.FieldGT(FieldOrganizationID, v))
+}
+
+// OrganizationIDGTE applies the GTE predicate on the "organization_id" field.
+func OrganizationIDGTE(v int64) predicate.Group {
+	return predicate.Group(sql.FieldGTE(FieldOrganizationID, v))
+}
+
+// OrganizationIDLT applies the LT predicate on the "organization_id" field.
+func OrganizationIDLT(v int64) predicate.Group {
+	return predicate.Group(sql.FieldLT(FieldOrganizationID, v))
+}
+
+// OrganizationIDLTE applies the LTE predicate on the "organization_id" field.
+func OrganizationIDLTE(v int64) predicate.Group {
+	return predicate.Group(sql.FieldLTE(FieldOrganizationID, v))
+}
+
+// OrganizationIDIsNil applies the IsNil predicate on the "organization_id" field.
+func OrganizationIDIsNil() predicate.Group {
+	return predicate.Group(sql.FieldIsNull(FieldOrganizationID))
+}
+
+// OrganizationIDNotNil applies the NotNil predicate on the "organization_id" field.
+func OrganizationIDNotNil() predicate.Group {
+	return predicate.Group(sql.FieldNotNull(FieldOrganizationID))
+}
+
 // HasAPIKeys applies the HasEdge predicate on the "api_keys" edge.
 func HasAPIKeys() predicate.Group {
 	return predicate.Group(func(s *sql.Selector) {
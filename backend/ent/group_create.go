@@ -439,6 +439,146 @@ func (_c *GroupCreate) SetNillableRpmLimit(v *int) *GroupCreate {
 	return _c
 }
 
+// SetPriority sets the "priority" field.
+func (_c *GroupCreate) SetPriority(v string) *GroupCreate {
+	_c.mutation.SetPriority(v)
+	return _c
+}
+
+// SetNillablePriority sets the "priority" field if the given value is not nil.
+func (_c *GroupCreate) SetNillablePriority(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetPriority(*v)
+	}
+	return _c
+}
+
+// SetRequestParamOverrides sets the "request_param_overrides" field.
+func (_c *GroupCreate) SetRequestParamOverrides(v domain.RequestParamOverrides) *GroupCreate {
+	_c.mutation.SetRequestParamOverrides(v)
+	return _c
+}
+
+// SetNillableRequestParamOverrides sets the "request_param_overrides" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableRequestParamOverrides(v *domain.RequestParamOverrides) *GroupCreate {
+	if v != nil {
+		_c.SetRequestParamOverrides(*v)
+	}
+	return _c
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (_c *GroupCreate) SetSystemPrompt(v string) *GroupCreate {
+	_c.mutation.SetSystemPrompt(v)
+	return _c
+}
+
+// SetNillableSystemPrompt sets the "system_prompt" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableSystemPrompt(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetSystemPrompt(*v)
+	}
+	return _c
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (_c *GroupCreate) SetSystemPromptMode(v string) *GroupCreate {
+	_c.mutation.SetSystemPromptMode(v)
+	return _c
+}
+
+// SetNillableSystemPromptMode sets the "system_prompt_mode" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableSystemPromptMode(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetSystemPromptMode(*v)
+	}
+	return _c
+}
+
+// SetPiiRedactionMode sets the "pii_redaction_mode" field.
+func (_c *GroupCreate) SetPiiRedactionMode(v string) *GroupCreate {
+	_c.mutation.SetPiiRedactionMode(v)
+	return _c
+}
+
+// SetNillablePiiRedactionMode sets the "pii_redaction_mode" field if the given value is not nil.
+func (_c *GroupCreate) SetNillablePiiRedactionMode(v *string) *GroupCreate {
+	if v != nil {
+		_c.SetPiiRedactionMode(*v)
+	}
+	return _c
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (_c *GroupCreate) SetMaxBodySize(v int64) *GroupCreate {
+	_c.mutation.SetMaxBodySize(v)
+	return _c
+}
+
+// SetNillableMaxBodySize sets the "max_body_size" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableMaxBodySize(v *int64) *GroupCreate {
+	if v != nil {
+		_c.SetMaxBodySize(*v)
+	}
+	return _c
+}
+
+// SetModerationEnabled sets the "moderation_enabled" field.
+func (_c *GroupCreate) SetModerationEnabled(v bool) *GroupCreate {
+	_c.mutation.SetModerationEnabled(v)
+	return _c
+}
+
+// SetNillableModerationEnabled sets the "moderation_enabled" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableModerationEnabled(v *bool) *GroupCreate {
+	if v != nil {
+		_c.SetModerationEnabled(*v)
+	}
+	return _c
+}
+
+// SetResponseHeaders sets the "response_headers" field.
+func (_c *GroupCreate) SetResponseHeaders(v domain.GroupResponseHeaders) *GroupCreate {
+	_c.mutation.SetResponseHeaders(v)
+	return _c
+}
+
+// SetNillableResponseHeaders sets the "response_headers" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableResponseHeaders(v *domain.GroupResponseHeaders) *GroupCreate {
+	if v != nil {
+		_c.SetResponseHeaders(*v)
+	}
+	return _c
+}
+
+// SetOrganizationID sets the "organization_id" field.
+func (_c *GroupCreate) SetOrganizationID(v int64) *GroupCreate {
+	_c.mutation.SetOrganizationID(v)
+	return _c
+}
+
+// SetNillableOrganizationID sets the "organization_id" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableOrganizationID(v *int64) *GroupCreate {
+	if v != nil {
+		_c.SetOrganizationID(*v)
+	}
+	return _c
+}
+
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (_c *GroupCreate) SetAccountTagSelector(v domain.AccountTagSelector) *GroupCreate {
+	_c.mutation.SetAccountTagSelector(v)
+	return _c
+}
+
+// SetNillableAccountTagSelector sets the "account_tag_selector" field if the given value is not nil.
+func (_c *GroupCreate) SetNillableAccountTagSelector(v *domain.AccountTagSelector) *GroupCreate {
+	if v != nil {
+		_c.SetAccountTagSelector(*v)
+	}
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *GroupCreate) AddAPIKeyIDs(ids ...int64) *GroupCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -648,6 +788,42 @@ func (_c *GroupCreate) defaults() error {
 		v := group.DefaultRpmLimit
 		_c.mutation.SetRpmLimit(v)
 	}
+	if _, ok := _c.mutation.Priority(); !ok {
+		v := group.DefaultPriority
+		_c.mutation.SetPriority(v)
+	}
+	if _, ok := _c.mutation.RequestParamOverrides(); !ok {
+		v := group.DefaultRequestParamOverrides
+		_c.mutation.SetRequestParamOverrides(v)
+	}
+	if _, ok := _c.mutation.SystemPrompt(); !ok {
+		v := group.DefaultSystemPrompt
+		_c.mutation.SetSystemPrompt(v)
+	}
+	if _, ok := _c.mutation.SystemPromptMode(); !ok {
+		v := group.DefaultSystemPromptMode
+		_c.mutation.SetSystemPromptMode(v)
+	}
+	if _, ok := _c.mutation.PiiRedactionMode(); !ok {
+		v := group.DefaultPiiRedactionMode
+		_c.mutation.SetPiiRedactionMode(v)
+	}
+	if _, ok := _c.mutation.MaxBodySize(); !ok {
+		v := group.DefaultMaxBodySize
+		_c.mutation.SetMaxBodySize(v)
+	}
+	if _, ok := _c.mutation.ModerationEnabled(); !ok {
+		v := group.DefaultModerationEnabled
+		_c.mutation.SetModerationEnabled(v)
+	}
+	if _, ok := _c.mutation.ResponseHeaders(); !ok {
+		v := group.DefaultResponseHeaders
+		_c.mutation.SetResponseHeaders(v)
+	}
+	if _, ok := _c.mutation.AccountTagSelector(); !ok {
+		v := group.DefaultAccountTagSelector
+		_c.mutation.SetAccountTagSelector(v)
+	}
 	return nil
 }
 
@@ -738,6 +914,48 @@ func (_c *GroupCreate) check() error {
 	if _, ok := _c.mutation.RpmLimit(); !ok {
 		return &ValidationError{Name: "rpm_limit", err: errors.New(`ent: missing required field "Group.rpm_limit"`)}
 	}
+	if _, ok := _c.mutation.Priority(); !ok {
+		return &ValidationError{Name: "priority", err: errors.New(`ent: missing required field "Group.priority"`)}
+	}
+	if v, ok := _c.mutation.Priority(); ok {
+		if err := group.PriorityValidator(v); err != nil {
+			return &ValidationError{Name: "priority", err: fmt.Errorf(`ent: validator failed for field "Group.priority": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.RequestParamOverrides(); !ok {
+		return &ValidationError{Name: "request_param_overrides", err: errors.New(`ent: missing required field "Group.request_param_overrides"`)}
+	}
+	if _, ok := _c.mutation.SystemPrompt(); !ok {
+		return &ValidationError{Name: "system_prompt", err: errors.New(`ent: missing required field "Group.system_prompt"`)}
+	}
+	if _, ok := _c.mutation.SystemPromptMode(); !ok {
+		return &ValidationError{Name: "system_prompt_mode", err: errors.New(`ent: missing required field "Group.system_prompt_mode"`)}
+	}
+	if v, ok := _c.mutation.SystemPromptMode(); ok {
+		if err := group.SystemPromptModeValidator(v); err != nil {
+			return &ValidationError{Name: "system_prompt_mode", err: fmt.Errorf(`ent: validator failed for field "Group.system_prompt_mode": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.PiiRedactionMode(); !ok {
+		return &ValidationError{Name: "pii_redaction_mode", err: errors.New(`ent: missing required field "Group.pii_redaction_mode"`)}
+	}
+	if v, ok := _c.mutation.PiiRedactionMode(); ok {
+		if err := group.PiiRedactionModeValidator(v); err != nil {
+			return &ValidationError{Name: "pii_redaction_mode", err: fmt.Errorf(`ent: validator failed for field "Group.pii_redaction_mode": %w`, err)}
+		}
+	}
+	if _, ok := _c.mutation.MaxBodySize(); !ok {
+		return &ValidationError{Name: "max_body_size", err: errors.New(`ent: missing required field "Group.max_body_size"`)}
+	}
+	if _, ok := _c.mutation.ModerationEnabled(); !ok {
+		return &ValidationError{Name: "moderation_enabled", err: errors.New(`ent: missing required field "Group.moderation_enabled"`)}
+	}
+	if _, ok := _c.mutation.ResponseHeaders(); !ok {
+		return &ValidationError{Name: "response_headers", err: errors.New(`ent: missing required field "Group.response_headers"`)}
+	}
+	if _, ok := _c.mutation.AccountTagSelector(); !ok {
+		return &ValidationError{Name: "account_tag_selector", err: errors.New(`ent: missing required field "Group.account_tag_selector"`)}
+	}
 	return nil
 }
 
@@ -889,6 +1107,46 @@ func (_c *GroupCreate) createSpec() (*Group, *sqlgraph.CreateSpec) {
 		_spec.SetField(group.FieldRpmLimit, field.TypeInt, value)
 		_node.RpmLimit = value
 	}
+	if value, ok := _c.mutation.Priority(); ok {
+		_spec.SetField(group.FieldPriority, field.TypeString, value)
+		_node.Priority = value
+	}
+	if value, ok := _c.mutation.RequestParamOverrides(); ok {
+		_spec.SetField(group.FieldRequestParamOverrides, field.TypeJSON, value)
+		_node.RequestParamOverrides = value
+	}
+	if value, ok := _c.mutation.SystemPrompt(); ok {
+		_spec.SetField(group.FieldSystemPrompt, field.TypeString, value)
+		_node.SystemPrompt = value
+	}
+	if value, ok := _c.mutation.SystemPromptMode(); ok {
+		_spec.SetField(group.FieldSystemPromptMode, field.TypeString, value)
+		_node.SystemPromptMode = value
+	}
+	if value, ok := _c.mutation.PiiRedactionMode(); ok {
+		_spec.SetField(group.FieldPiiRedactionMode, field.TypeString, value)
+		_node.PiiRedactionMode = value
+	}
+	if value, ok := _c.mutation.MaxBodySize(); ok {
+		_spec.SetField(group.FieldMaxBodySize, field.TypeInt64, value)
+		_node.MaxBodySize = value
+	}
+	if value, ok := _c.mutation.ModerationEnabled(); ok {
+		_spec.SetField(group.FieldModerationEnabled, field.TypeBool, value)
+		_node.ModerationEnabled = value
+	}
+	if value, ok := _c.mutation.ResponseHeaders(); ok {
+		_spec.SetField(group.FieldResponseHeaders, field.TypeJSON, value)
+		_node.ResponseHeaders = value
+	}
+	if value, ok := _c.mutation.OrganizationID(); ok {
+		_spec.SetField(group.FieldOrganizationID, field.TypeInt64, value)
+		_node.OrganizationID = &value
+	}
+	if value, ok := _c.mutation.AccountTagSelector(); ok {
+		_spec.SetField(group.FieldAccountTagSelector, field.TypeJSON, value)
+		_node.AccountTagSelector = value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1543,6 +1801,144 @@ func (u *GroupUpsert) AddRpmLimit(v int) *GroupUpsert {
 	return u
 }
 
+// SetPriority sets the "priority" field.
+func (u *GroupUpsert) SetPriority(v string) *GroupUpsert {
+	u.Set(group.FieldPriority, v)
+	return u
+}
+
+// UpdatePriority sets the "priority" field to the value that was provided on create.
+func (u *GroupUpsert) UpdatePriority() *GroupUpsert {
+	u.SetExcluded(group.FieldPriority)
+	return u
+}
+
+// SetRequestParamOverrides sets the "request_param_overrides" field.
+func (u *GroupUpsert) SetRequestParamOverrides(v domain.RequestParamOverrides) *GroupUpsert {
+	u.Set(group.FieldRequestParamOverrides, v)
+	return u
+}
+
+// UpdateRequestParamOverrides sets the "request_param_overrides" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateRequestParamOverrides() *GroupUpsert {
+	u.SetExcluded(group.FieldRequestParamOverrides)
+	return u
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (u *GroupUpsert) SetSystemPrompt(v string) *GroupUpsert {
+	u.Set(group.FieldSystemPrompt, v)
+	return u
+}
+
+// UpdateSystemPrompt sets the "system_prompt" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateSystemPrompt() *GroupUpsert {
+	u.SetExcluded(group.FieldSystemPrompt)
+	return u
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (u *GroupUpsert) SetSystemPromptMode(v string) *GroupUpsert {
+	u.Set(group.FieldSystemPromptMode, v)
+	return u
+}
+
+// UpdateSystemPromptMode sets the "system_prompt_mode" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateSystemPromptMode() *GroupUpsert {
+	u.SetExcluded(group.FieldSystemPromptMode)
+	return u
+}
+
+// SetPiiRedactionMode sets the "pii_redaction_mode" field.
+func (u *GroupUpsert) SetPiiRedactionMode(v string) *GroupUpsert {
+	u.Set(group.FieldPiiRedactionMode, v)
+	return u
+}
+
+// UpdatePiiRedactionMode sets the "pii_redaction_mode" field to the value that was provided on create.
+func (u *GroupUpsert) UpdatePiiRedactionMode() *GroupUpsert {
+	u.SetExcluded(group.FieldPiiRedactionMode)
+	return u
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (u *GroupUpsert) SetMaxBodySize(v int64) *GroupUpsert {
+	u.Set(group.FieldMaxBodySize, v)
+	return u
+}
+
+// UpdateMaxBodySize sets the "max_body_size" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateMaxBodySize() *GroupUpsert {
+	u.SetExcluded(group.FieldMaxBodySize)
+	return u
+}
+
+// AddMaxBodySize adds v to the "max_body_size" field.
+func (u *GroupUpsert) AddMaxBodySize(v int64) *GroupUpsert {
+	u.Add(group.FieldMaxBodySize, v)
+	return u
+}
+
+// SetModerationEnabled sets the "moderation_enabled" field.
+func (u *GroupUpsert) SetModerationEnabled(v bool) *GroupUpsert {
+	u.Set(group.FieldModerationEnabled, v)
+	return u
+}
+
+// UpdateModerationEnabled sets the "moderation_enabled" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateModerationEnabled() *GroupUpsert {
+	u.SetExcluded(group.FieldModerationEnabled)
+	return u
+}
+
+// SetResponseHeaders sets the "response_headers" field.
+func (u *GroupUpsert) SetResponseHeaders(v domain.GroupResponseHeaders) *GroupUpsert {
+	u.Set(group.FieldResponseHeaders, v)
+	return u
+}
+
+// UpdateResponseHeaders sets the "response_headers" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateResponseHeaders() *GroupUpsert {
+	u.SetExcluded(group.FieldResponseHeaders)
+	return u
+}
+
+// SetOrganizationID sets the "organization_id" field.
+func (u *GroupUpsert) SetOrganizationID(v int64) *GroupUpsert {
+	u.Set(group.FieldOrganizationID, v)
+	return u
+}
+
+// UpdateOrganizationID sets the "organization_id" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateOrganizationID() *GroupUpsert {
+	u.SetExcluded(group.FieldOrganizationID)
+	return u
+}
+
+// AddOrganizationID adds v to the "organization_id" field.
+func (u *GroupUpsert) AddOrganizationID(v int64) *GroupUpsert {
+	u.Add(group.FieldOrganizationID, v)
+	return u
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (u *GroupUpsert) ClearOrganizationID() *GroupUpsert {
+	u.SetNull(group.FieldOrganizationID)
+	return u
+}
+
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (u *GroupUpsert) SetAccountTagSelector(v domain.AccountTagSelector) *GroupUpsert {
+	u.Set(group.FieldAccountTagSelector, v)
+	return u
+}
+
+// UpdateAccountTagSelector sets the "account_tag_selector" field to the value that was provided on create.
+func (u *GroupUpsert) UpdateAccountTagSelector() *GroupUpsert {
+	u.SetExcluded(group.FieldAccountTagSelector)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -2169,6 +2565,167 @@ func (u *GroupUpsertOne) UpdateRpmLimit() *GroupUpsertOne {
 	})
 }
 
+// SetPriority sets the "priority" field.
+func (u *GroupUpsertOne) SetPriority(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetPriority(v)
+	})
+}
+
+// UpdatePriority sets the "priority" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdatePriority() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdatePriority()
+	})
+}
+
+// SetRequestParamOverrides sets the "request_param_overrides" field.
+func (u *GroupUpsertOne) SetRequestParamOverrides(v domain.RequestParamOverrides) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetRequestParamOverrides(v)
+	})
+}
+
+// UpdateRequestParamOverrides sets the "request_param_overrides" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateRequestParamOverrides() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateRequestParamOverrides()
+	})
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (u *GroupUpsertOne) SetSystemPrompt(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSystemPrompt(v)
+	})
+}
+
+// UpdateSystemPrompt sets the "system_prompt" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateSystemPrompt() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSystemPrompt()
+	})
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (u *GroupUpsertOne) SetSystemPromptMode(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSystemPromptMode(v)
+	})
+}
+
+// UpdateSystemPromptMode sets the "system_prompt_mode" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateSystemPromptMode() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSystemPromptMode()
+	})
+}
+
+// SetPiiRedactionMode sets the "pii_redaction_mode" field.
+func (u *GroupUpsertOne) SetPiiRedactionMode(v string) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetPiiRedactionMode(v)
+	})
+}
+
+// UpdatePiiRedactionMode sets the "pii_redaction_mode" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdatePiiRedactionMode() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdatePiiRedactionMode()
+	})
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (u *GroupUpsertOne) SetMaxBodySize(v int64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetMaxBodySize(v)
+	})
+}
+
+// AddMaxBodySize adds v to the "max_body_size" field.
+func (u *GroupUpsertOne) AddMaxBodySize(v int64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddMaxBodySize(v)
+	})
+}
+
+// UpdateMaxBodySize sets the "max_body_size" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateMaxBodySize() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateMaxBodySize()
+	})
+}
+
+// SetModerationEnabled sets the "moderation_enabled" field.
+func (u *GroupUpsertOne) SetModerationEnabled(v bool) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetModerationEnabled(v)
+	})
+}
+
+// UpdateModerationEnabled sets the "moderation_enabled" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateModerationEnabled() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateModerationEnabled()
+	})
+}
+
+// SetResponseHeaders sets the "response_headers" field.
+func (u *GroupUpsertOne) SetResponseHeaders(v domain.GroupResponseHeaders) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseHeaders(v)
+	})
+}
+
+// UpdateResponseHeaders sets the "response_headers" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateResponseHeaders() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseHeaders()
+	})
+}
+
+// SetOrganizationID sets the "organization_id" field.
+func (u *GroupUpsertOne) SetOrganizationID(v int64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetOrganizationID(v)
+	})
+}
+
+// AddOrganizationID adds v to the "organization_id" field.
+func (u *GroupUpsertOne) AddOrganizationID(v int64) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddOrganizationID(v)
+	})
+}
+
+// UpdateOrganizationID sets the "organization_id" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateOrganizationID() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateOrganizationID()
+	})
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (u *GroupUpsertOne) ClearOrganizationID() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearOrganizationID()
+	})
+}
+
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (u *GroupUpsertOne) SetAccountTagSelector(v domain.AccountTagSelector) *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAccountTagSelector(v)
+	})
+}
+
+// UpdateAccountTagSelector sets the "account_tag_selector" field to the value that was provided on create.
+func (u *GroupUpsertOne) UpdateAccountTagSelector() *GroupUpsertOne {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAccountTagSelector()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -2961,6 +3518,167 @@ func (u *GroupUpsertBulk) UpdateRpmLimit() *GroupUpsertBulk {
 	})
 }
 
+// SetPriority sets the "priority" field.
+func (u *GroupUpsertBulk) SetPriority(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetPriority(v)
+	})
+}
+
+// UpdatePriority sets the "priority" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdatePriority() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdatePriority()
+	})
+}
+
+// SetRequestParamOverrides sets the "request_param_overrides" field.
+func (u *GroupUpsertBulk) SetRequestParamOverrides(v domain.RequestParamOverrides) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetRequestParamOverrides(v)
+	})
+}
+
+// UpdateRequestParamOverrides sets the "request_param_overrides" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateRequestParamOverrides() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateRequestParamOverrides()
+	})
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (u *GroupUpsertBulk) SetSystemPrompt(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSystemPrompt(v)
+	})
+}
+
+// UpdateSystemPrompt sets the "system_prompt" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateSystemPrompt() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSystemPrompt()
+	})
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (u *GroupUpsertBulk) SetSystemPromptMode(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetSystemPromptMode(v)
+	})
+}
+
+// UpdateSystemPromptMode sets the "system_prompt_mode" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateSystemPromptMode() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateSystemPromptMode()
+	})
+}
+
+// SetPiiRedactionMode sets the "pii_redaction_mode" field.
+func (u *GroupUpsertBulk) SetPiiRedactionMode(v string) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetPiiRedactionMode(v)
+	})
+}
+
+// UpdatePiiRedactionMode sets the "pii_redaction_mode" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdatePiiRedactionMode() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdatePiiRedactionMode()
+	})
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (u *GroupUpsertBulk) SetMaxBodySize(v int64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetMaxBodySize(v)
+	})
+}
+
+// AddMaxBodySize adds v to the "max_body_size" field.
+func (u *GroupUpsertBulk) AddMaxBodySize(v int64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddMaxBodySize(v)
+	})
+}
+
+// UpdateMaxBodySize sets the "max_body_size" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateMaxBodySize() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateMaxBodySize()
+	})
+}
+
+// SetModerationEnabled sets the "moderation_enabled" field.
+func (u *GroupUpsertBulk) SetModerationEnabled(v bool) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetModerationEnabled(v)
+	})
+}
+
+// UpdateModerationEnabled sets the "moderation_enabled" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateModerationEnabled() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateModerationEnabled()
+	})
+}
+
+// SetResponseHeaders sets the "response_headers" field.
+func (u *GroupUpsertBulk) SetResponseHeaders(v domain.GroupResponseHeaders) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetResponseHeaders(v)
+	})
+}
+
+// UpdateResponseHeaders sets the "response_headers" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateResponseHeaders() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateResponseHeaders()
+	})
+}
+
+// SetOrganizationID sets the "organization_id" field.
+func (u *GroupUpsertBulk) SetOrganizationID(v int64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetOrganizationID(v)
+	})
+}
+
+// AddOrganizationID adds v to the "organization_id" field.
+func (u *GroupUpsertBulk) AddOrganizationID(v int64) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.AddOrganizationID(v)
+	})
+}
+
+// UpdateOrganizationID sets the "organization_id" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateOrganizationID() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateOrganizationID()
+	})
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (u *GroupUpsertBulk) ClearOrganizationID() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.ClearOrganizationID()
+	})
+}
+
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (u *GroupUpsertBulk) SetAccountTagSelector(v domain.AccountTagSelector) *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.SetAccountTagSelector(v)
+	})
+}
+
+// UpdateAccountTagSelector sets the "account_tag_selector" field to the value that was provided on create.
+func (u *GroupUpsertBulk) UpdateAccountTagSelector() *GroupUpsertBulk {
+	return u.Update(func(s *GroupUpsert) {
+		s.UpdateAccountTagSelector()
+	})
+}
+
 // Exec executes the query.
 func (u *GroupUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
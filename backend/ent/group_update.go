@@ -588,6 +588,166 @@ func (_u *GroupUpdate) AddRpmLimit(v int) *GroupUpdate {
 	return _u
 }
 
+// SetPriority sets the "priority" field.
+func (_u *GroupUpdate) SetPriority(v string) *GroupUpdate {
+	_u.mutation.SetPriority(v)
+	return _u
+}
+
+// SetNillablePriority sets the "priority" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillablePriority(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetPriority(*v)
+	}
+	return _u
+}
+
+// SetRequestParamOverrides sets the "request_param_overrides" field.
+func (_u *GroupUpdate) SetRequestParamOverrides(v domain.RequestParamOverrides) *GroupUpdate {
+	_u.mutation.SetRequestParamOverrides(v)
+	return _u
+}
+
+// SetNillableRequestParamOverrides sets the "request_param_overrides" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableRequestParamOverrides(v *domain.RequestParamOverrides) *GroupUpdate {
+	if v != nil {
+		_u.SetRequestParamOverrides(*v)
+	}
+	return _u
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (_u *GroupUpdate) SetSystemPrompt(v string) *GroupUpdate {
+	_u.mutation.SetSystemPrompt(v)
+	return _u
+}
+
+// SetNillableSystemPrompt sets the "system_prompt" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableSystemPrompt(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetSystemPrompt(*v)
+	}
+	return _u
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (_u *GroupUpdate) SetSystemPromptMode(v string) *GroupUpdate {
+	_u.mutation.SetSystemPromptMode(v)
+	return _u
+}
+
+// SetNillableSystemPromptMode sets the "system_prompt_mode" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableSystemPromptMode(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetSystemPromptMode(*v)
+	}
+	return _u
+}
+
+// SetPiiRedactionMode sets the "pii_redaction_mode" field.
+func (_u *GroupUpdate) SetPiiRedactionMode(v string) *GroupUpdate {
+	_u.mutation.SetPiiRedactionMode(v)
+	return _u
+}
+
+// SetNillablePiiRedactionMode sets the "pii_redaction_mode" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillablePiiRedactionMode(v *string) *GroupUpdate {
+	if v != nil {
+		_u.SetPiiRedactionMode(*v)
+	}
+	return _u
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (_u *GroupUpdate) SetMaxBodySize(v int64) *GroupUpdate {
+	_u.mutation.ResetMaxBodySize()
+	_u.mutation.SetMaxBodySize(v)
+	return _u
+}
+
+// SetNillableMaxBodySize sets the "max_body_size" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableMaxBodySize(v *int64) *GroupUpdate {
+	if v != nil {
+		_u.SetMaxBodySize(*v)
+	}
+	return _u
+}
+
+// AddMaxBodySize adds value to the "max_body_size" field.
+func (_u *GroupUpdate) AddMaxBodySize(v int64) *GroupUpdate {
+	_u.mutation.AddMaxBodySize(v)
+	return _u
+}
+
+// SetModerationEnabled sets the "moderation_enabled" field.
+func (_u *GroupUpdate) SetModerationEnabled(v bool) *GroupUpdate {
+	_u.mutation.SetModerationEnabled(v)
+	return _u
+}
+
+// SetNillableModerationEnabled sets the "moderation_enabled" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableModerationEnabled(v *bool) *GroupUpdate {
+	if v != nil {
+		_u.SetModerationEnabled(*v)
+	}
+	return _u
+}
+
+// SetResponseHeaders sets the "response_headers" field.
+func (_u *GroupUpdate) SetResponseHeaders(v domain.GroupResponseHeaders) *GroupUpdate {
+	_u.mutation.SetResponseHeaders(v)
+	return _u
+}
+
+// SetNillableResponseHeaders sets the "response_headers" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableResponseHeaders(v *domain.GroupResponseHeaders) *GroupUpdate {
+	if v != nil {
+		_u.SetResponseHeaders(*v)
+	}
+	return _u
+}
+
+// SetOrganizationID sets the "organization_id" field.
+func (_u *GroupUpdate) SetOrganizationID(v int64) *GroupUpdate {
+	_u.mutation.ResetOrganizationID()
+	_u.mutation.SetOrganizationID(v)
+	return _u
+}
+
+// SetNillableOrganizationID sets the "organization_id" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableOrganizationID(v *int64) *GroupUpdate {
+	if v != nil {
+		_u.SetOrganizationID(*v)
+	}
+	return _u
+}
+
+// AddOrganizationID adds value to the "organization_id" field.
+func (_u *GroupUpdate) AddOrganizationID(v int64) *GroupUpdate {
+	_u.mutation.AddOrganizationID(v)
+	return _u
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (_u *GroupUpdate) ClearOrganizationID() *GroupUpdate {
+	_u.mutation.ClearOrganizationID()
+	return _u
+}
+
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (_u *GroupUpdate) SetAccountTagSelector(v domain.AccountTagSelector) *GroupUpdate {
+	_u.mutation.SetAccountTagSelector(v)
+	return _u
+}
+
+// SetNillableAccountTagSelector sets the "account_tag_selector" field if the given value is not nil.
+func (_u *GroupUpdate) SetNillableAccountTagSelector(v *domain.AccountTagSelector) *GroupUpdate {
+	if v != nil {
+		_u.SetAccountTagSelector(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdate) AddAPIKeyIDs(ids ...int64) *GroupUpdate {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -878,6 +1038,21 @@ func (_u *GroupUpdate) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.Priority(); ok {
+		if err := group.PriorityValidator(v); err != nil {
+			return &ValidationError{Name: "priority", err: fmt.Errorf(`ent: validator failed for field "Group.priority": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.SystemPromptMode(); ok {
+		if err := group.SystemPromptModeValidator(v); err != nil {
+			return &ValidationError{Name: "system_prompt_mode", err: fmt.Errorf(`ent: validator failed for field "Group.system_prompt_mode": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.PiiRedactionMode(); ok {
+		if err := group.PiiRedactionModeValidator(v); err != nil {
+			return &ValidationError{Name: "pii_redaction_mode", err: fmt.Errorf(`ent: validator failed for field "Group.pii_redaction_mode": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -1057,6 +1232,45 @@ func (_u *GroupUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedRpmLimit(); ok {
 		_spec.AddField(group.FieldRpmLimit, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.Priority(); ok {
+		_spec.SetField(group.FieldPriority, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.RequestParamOverrides(); ok {
+		_spec.SetField(group.FieldRequestParamOverrides, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.SystemPrompt(); ok {
+		_spec.SetField(group.FieldSystemPrompt, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.SystemPromptMode(); ok {
+		_spec.SetField(group.FieldSystemPromptMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.PiiRedactionMode(); ok {
+		_spec.SetField(group.FieldPiiRedactionMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.MaxBodySize(); ok {
+		_spec.SetField(group.FieldMaxBodySize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedMaxBodySize(); ok {
+		_spec.AddField(group.FieldMaxBodySize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.ModerationEnabled(); ok {
+		_spec.SetField(group.FieldModerationEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseHeaders(); ok {
+		_spec.SetField(group.FieldResponseHeaders, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.OrganizationID(); ok {
+		_spec.SetField(group.FieldOrganizationID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedOrganizationID(); ok {
+		_spec.AddField(group.FieldOrganizationID, field.TypeInt64, value)
+	}
+	if _u.mutation.OrganizationIDCleared() {
+		_spec.ClearField(group.FieldOrganizationID, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.AccountTagSelector(); ok {
+		_spec.SetField(group.FieldAccountTagSelector, field.TypeJSON, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1923,6 +2137,166 @@ func (_u *GroupUpdateOne) AddRpmLimit(v int) *GroupUpdateOne {
 	return _u
 }
 
+// SetPriority sets the "priority" field.
+func (_u *GroupUpdateOne) SetPriority(v string) *GroupUpdateOne {
+	_u.mutation.SetPriority(v)
+	return _u
+}
+
+// SetNillablePriority sets the "priority" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillablePriority(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetPriority(*v)
+	}
+	return _u
+}
+
+// SetRequestParamOverrides sets the "request_param_overrides" field.
+func (_u *GroupUpdateOne) SetRequestParamOverrides(v domain.RequestParamOverrides) *GroupUpdateOne {
+	_u.mutation.SetRequestParamOverrides(v)
+	return _u
+}
+
+// SetNillableRequestParamOverrides sets the "request_param_overrides" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableRequestParamOverrides(v *domain.RequestParamOverrides) *GroupUpdateOne {
+	if v != nil {
+		_u.SetRequestParamOverrides(*v)
+	}
+	return _u
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (_u *GroupUpdateOne) SetSystemPrompt(v string) *GroupUpdateOne {
+	_u.mutation.SetSystemPrompt(v)
+	return _u
+}
+
+// SetNillableSystemPrompt sets the "system_prompt" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableSystemPrompt(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetSystemPrompt(*v)
+	}
+	return _u
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (_u *GroupUpdateOne) SetSystemPromptMode(v string) *GroupUpdateOne {
+	_u.mutation.SetSystemPromptMode(v)
+	return _u
+}
+
+// SetNillableSystemPromptMode sets the "system_prompt_mode" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableSystemPromptMode(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetSystemPromptMode(*v)
+	}
+	return _u
+}
+
+// SetPiiRedactionMode sets the "pii_redaction_mode" field.
+func (_u *GroupUpdateOne) SetPiiRedactionMode(v string) *GroupUpdateOne {
+	_u.mutation.SetPiiRedactionMode(v)
+	return _u
+}
+
+// SetNillablePiiRedactionMode sets the "pii_redaction_mode" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillablePiiRedactionMode(v *string) *GroupUpdateOne {
+	if v != nil {
+		_u.SetPiiRedactionMode(*v)
+	}
+	return _u
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (_u *GroupUpdateOne) SetMaxBodySize(v int64) *GroupUpdateOne {
+	_u.mutation.ResetMaxBodySize()
+	_u.mutation.SetMaxBodySize(v)
+	return _u
+}
+
+// SetNillableMaxBodySize sets the "max_body_size" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableMaxBodySize(v *int64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetMaxBodySize(*v)
+	}
+	return _u
+}
+
+// AddMaxBodySize adds value to the "max_body_size" field.
+func (_u *GroupUpdateOne) AddMaxBodySize(v int64) *GroupUpdateOne {
+	_u.mutation.AddMaxBodySize(v)
+	return _u
+}
+
+// SetModerationEnabled sets the "moderation_enabled" field.
+func (_u *GroupUpdateOne) SetModerationEnabled(v bool) *GroupUpdateOne {
+	_u.mutation.SetModerationEnabled(v)
+	return _u
+}
+
+// SetNillableModerationEnabled sets the "moderation_enabled" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableModerationEnabled(v *bool) *GroupUpdateOne {
+	if v != nil {
+		_u.SetModerationEnabled(*v)
+	}
+	return _u
+}
+
+// SetResponseHeaders sets the "response_headers" field.
+func (_u *GroupUpdateOne) SetResponseHeaders(v domain.GroupResponseHeaders) *GroupUpdateOne {
+	_u.mutation.SetResponseHeaders(v)
+	return _u
+}
+
+// SetNillableResponseHeaders sets the "response_headers" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableResponseHeaders(v *domain.GroupResponseHeaders) *GroupUpdateOne {
+	if v != nil {
+		_u.SetResponseHeaders(*v)
+	}
+	return _u
+}
+
+// SetOrganizationID sets the "organization_id" field.
+func (_u *GroupUpdateOne) SetOrganizationID(v int64) *GroupUpdateOne {
+	_u.mutation.ResetOrganizationID()
+	_u.mutation.SetOrganizationID(v)
+	return _u
+}
+
+// SetNillableOrganizationID sets the "organization_id" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableOrganizationID(v *int64) *GroupUpdateOne {
+	if v != nil {
+		_u.SetOrganizationID(*v)
+	}
+	return _u
+}
+
+// AddOrganizationID adds value to the "organization_id" field.
+func (_u *GroupUpdateOne) AddOrganizationID(v int64) *GroupUpdateOne {
+	_u.mutation.AddOrganizationID(v)
+	return _u
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (_u *GroupUpdateOne) ClearOrganizationID() *GroupUpdateOne {
+	_u.mutation.ClearOrganizationID()
+	return _u
+}
+
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (_u *GroupUpdateOne) SetAccountTagSelector(v domain.AccountTagSelector) *GroupUpdateOne {
+	_u.mutation.SetAccountTagSelector(v)
+	return _u
+}
+
+// SetNillableAccountTagSelector sets the "account_tag_selector" field if the given value is not nil.
+func (_u *GroupUpdateOne) SetNillableAccountTagSelector(v *domain.AccountTagSelector) *GroupUpdateOne {
+	if v != nil {
+		_u.SetAccountTagSelector(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *GroupUpdateOne) AddAPIKeyIDs(ids ...int64) *GroupUpdateOne {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -2226,6 +2600,21 @@ func (_u *GroupUpdateOne) check() error {
 			return &ValidationError{Name: "default_mapped_model", err: fmt.Errorf(`ent: validator failed for field "Group.default_mapped_model": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.Priority(); ok {
+		if err := group.PriorityValidator(v); err != nil {
+			return &ValidationError{Name: "priority", err: fmt.Errorf(`ent: validator failed for field "Group.priority": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.SystemPromptMode(); ok {
+		if err := group.SystemPromptModeValidator(v); err != nil {
+			return &ValidationError{Name: "system_prompt_mode", err: fmt.Errorf(`ent: validator failed for field "Group.system_prompt_mode": %w`, err)}
+		}
+	}
+	if v, ok := _u.mutation.PiiRedactionMode(); ok {
+		if err := group.PiiRedactionModeValidator(v); err != nil {
+			return &ValidationError{Name: "pii_redaction_mode", err: fmt.Errorf(`ent: validator failed for field "Group.pii_redaction_mode": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -2422,6 +2811,45 @@ func (_u *GroupUpdateOne) sqlSave(ctx context.Context) (_node *Group, err error)
 	if value, ok := _u.mutation.AddedRpmLimit(); ok {
 		_spec.AddField(group.FieldRpmLimit, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.Priority(); ok {
+		_spec.SetField(group.FieldPriority, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.RequestParamOverrides(); ok {
+		_spec.SetField(group.FieldRequestParamOverrides, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.SystemPrompt(); ok {
+		_spec.SetField(group.FieldSystemPrompt, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.SystemPromptMode(); ok {
+		_spec.SetField(group.FieldSystemPromptMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.PiiRedactionMode(); ok {
+		_spec.SetField(group.FieldPiiRedactionMode, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.MaxBodySize(); ok {
+		_spec.SetField(group.FieldMaxBodySize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedMaxBodySize(); ok {
+		_spec.AddField(group.FieldMaxBodySize, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.ModerationEnabled(); ok {
+		_spec.SetField(group.FieldModerationEnabled, field.TypeBool, value)
+	}
+	if value, ok := _u.mutation.ResponseHeaders(); ok {
+		_spec.SetField(group.FieldResponseHeaders, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.OrganizationID(); ok {
+		_spec.SetField(group.FieldOrganizationID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedOrganizationID(); ok {
+		_spec.AddField(group.FieldOrganizationID, field.TypeInt64, value)
+	}
+	if _u.mutation.OrganizationIDCleared() {
+		_spec.ClearField(group.FieldOrganizationID, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.AccountTagSelector(); ok {
+		_spec.SetField(group.FieldAccountTagSelector, field.TypeJSON, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
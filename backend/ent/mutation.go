@@ -27,6 +27,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/group"
 	"github.com/Wei-Shaw/sub2api/ent/idempotencyrecord"
 	"github.com/Wei-Shaw/sub2api/ent/identityadoptiondecision"
+	"github.com/Wei-Shaw/sub2api/ent/organization"
 	"github.com/Wei-Shaw/sub2api/ent/paymentauditlog"
 	"github.com/Wei-Shaw/sub2api/ent/paymentorder"
 	"github.com/Wei-Shaw/sub2api/ent/paymentproviderinstance"
@@ -74,6 +75,7 @@ const (
 	TypeGroup                         = "Group"
 	TypeIdempotencyRecord             = "IdempotencyRecord"
 	TypeIdentityAdoptionDecision      = "IdentityAdoptionDecision"
+	TypeOrganization                  = "Organization"
 	TypePaymentAuditLog               = "PaymentAuditLog"
 	TypePaymentOrder                  = "PaymentOrder"
 	TypePaymentProviderInstance       = "PaymentProviderInstance"
@@ -98,51 +100,72 @@ const (
 // APIKeyMutation represents an operation that mutates the APIKey nodes in the graph.
 type APIKeyMutation struct {
 	config
-	op                 Op
-	typ                string
-	id                 *int64
-	created_at         *time.Time
-	updated_at         *time.Time
-	deleted_at         *time.Time
-	key                *string
-	name               *string
-	status             *string
-	last_used_at       *time.Time
-	ip_whitelist       *[]string
-	appendip_whitelist []string
-	ip_blacklist       *[]string
-	appendip_blacklist []string
-	quota              *float64
-	addquota           *float64
-	quota_used         *float64
-	addquota_used      *float64
-	expires_at         *time.Time
-	rate_limit_5h      *float64
-	addrate_limit_5h   *float64
-	rate_limit_1d      *float64
-	addrate_limit_1d   *float64
-	rate_limit_7d      *float64
-	addrate_limit_7d   *float64
-	usage_5h           *float64
-	addusage_5h        *float64
-	usage_1d           *float64
-	addusage_1d        *float64
-	usage_7d           *float64
-	addusage_7d        *float64
-	window_5h_start    *time.Time
-	window_1d_start    *time.Time
-	window_7d_start    *time.Time
-	clearedFields      map[string]struct{}
-	user               *int64
-	cleareduser        bool
-	group              *int64
-	clearedgroup       bool
-	usage_logs         map[int64]struct{}
-	removedusage_logs  map[int64]struct{}
-	clearedusage_logs  bool
-	done               bool
-	oldValue           func(context.Context) (*APIKey, error)
-	predicates         []predicate.APIKey
+	op                          Op
+	typ                         string
+	id                          *int64
+	created_at                  *time.Time
+	updated_at                  *time.Time
+	deleted_at                  *time.Time
+	key                         *string
+	name                        *string
+	status                      *string
+	last_used_at                *time.Time
+	ip_whitelist                *[]string
+	appendip_whitelist          []string
+	ip_blacklist                *[]string
+	appendip_blacklist          []string
+	scopes                      *[]string
+	appendscopes                []string
+	priority                    *string
+	moderation_mode             *string
+	context_auto_trim           *bool
+	max_body_size               *int64
+	parent_id                   *int64
+	addmax_body_size            *int64
+	addparent_id                *int64
+	request_coalescing          *bool
+	token_bucket_burst          *int
+	addtoken_bucket_burst       *int
+	token_bucket_refill_rate    *float64
+	addtoken_bucket_refill_rate *float64
+	max_cost_per_request        *float64
+	addmax_cost_per_request     *float64
+	quota                       *float64
+	addquota                    *float64
+	quota_used                  *float64
+	addquota_used               *float64
+	expires_at                  *time.Time
+	rate_limit_5h               *float64
+	addrate_limit_5h            *float64
+	rate_limit_1d               *float64
+	addrate_limit_1d            *float64
+	rate_limit_7d               *float64
+	addrate_limit_7d            *float64
+	usage_5h                    *float64
+	addusage_5h                 *float64
+	usage_1d                    *float64
+	addusage_1d                 *float64
+	usage_7d                    *float64
+	addusage_7d                 *float64
+	window_5h_start             *time.Time
+	window_1d_start             *time.Time
+	window_7d_start             *time.Time
+	account_tag_selector        *domain.AccountTagSelector
+	allowed_platforms           *[]string
+	allowed_models              *[]string
+	appendallowed_platforms     []string
+	appendallowed_models        []string
+	clearedFields               map[string]struct{}
+	user                        *int64
+	cleareduser                 bool
+	group                       *int64
+	clearedgroup                bool
+	usage_logs                  map[int64]struct{}
+	removedusage_logs           map[int64]struct{}
+	clearedusage_logs           bool
+	done                        bool
+	oldValue                    func(context.Context) (*APIKey, error)
+	predicates                  []predicate.APIKey
 }
 
 var _ ent.Mutation = (*APIKeyMutation)(nil)
@@ -736,6 +759,495 @@ func (m *APIKeyMutation) ResetIPBlacklist() {
 	delete(m.clearedFields, apikey.FieldIPBlacklist)
 }
 
+// SetScopes sets the "scopes" field.
+func (m *APIKeyMutation) SetScopes(s []string) {
+	m.scopes = &s
+	m.appendscopes = nil
+}
+
+// Scopes returns the value of the "scopes" field in the mutation.
+func (m *APIKeyMutation) Scopes() (r []string, exists bool) {
+	v := m.scopes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldScopes returns the old "scopes" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldScopes(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldScopes is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldScopes requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldScopes: %w", err)
+	}
+	return oldValue.Scopes, nil
+}
+
+// AppendScopes adds s to the "scopes" field.
+func (m *APIKeyMutation) AppendScopes(s []string) {
+	m.appendscopes = append(m.appendscopes, s...)
+}
+
+// AppendedScopes returns the list of values that were appended to the "scopes" field in this mutation.
+func (m *APIKeyMutation) AppendedScopes() ([]string, bool) {
+	if len(m.appendscopes) == 0 {
+		return nil, false
+	}
+	return m.appendscopes, true
+}
+
+// ClearScopes clears the value of the "scopes" field.
+func (m *APIKeyMutation) ClearScopes() {
+	m.scopes = nil
+	m.appendscopes = nil
+	m.clearedFields[apikey.FieldScopes] = struct{}{}
+}
+
+// ScopesCleared returns if the "scopes" field was cleared in this mutation.
+func (m *APIKeyMutation) ScopesCleared() bool {
+	_, ok := m.clearedFields[apikey.FieldScopes]
+	return ok
+}
+
+// ResetScopes resets all changes to the "scopes" field.
+func (m *APIKeyMutation) ResetScopes() {
+	m.scopes = nil
+	m.appendscopes = nil
+	delete(m.clearedFields, apikey.FieldScopes)
+}
+
+// SetPriority sets the "priority" field.
+func (m *APIKeyMutation) SetPriority(s string) {
+	m.priority = &s
+}
+
+// Priority returns the value of the "priority" field in the mutation.
+func (m *APIKeyMutation) Priority() (r string, exists bool) {
+	v := m.priority
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPriority returns the old "priority" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldPriority(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPriority is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPriority requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPriority: %w", err)
+	}
+	return oldValue.Priority, nil
+}
+
+// ResetPriority resets all changes to the "priority" field.
+func (m *APIKeyMutation) ResetPriority() {
+	m.priority = nil
+}
+
+// SetModerationMode sets the "moderation_mode" field.
+func (m *APIKeyMutation) SetModerationMode(s string) {
+	m.moderation_mode = &s
+}
+
+// ModerationMode returns the value of the "moderation_mode" field in the mutation.
+func (m *APIKeyMutation) ModerationMode() (r string, exists bool) {
+	v := m.moderation_mode
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldModerationMode returns the old "moderation_mode" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldModerationMode(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldModerationMode is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldModerationMode requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldModerationMode: %w", err)
+	}
+	return oldValue.ModerationMode, nil
+}
+
+// ResetModerationMode resets all changes to the "moderation_mode" field.
+func (m *APIKeyMutation) ResetModerationMode() {
+	m.moderation_mode = nil
+}
+
+// SetContextAutoTrim sets the "context_auto_trim" field.
+func (m *APIKeyMutation) SetContextAutoTrim(b bool) {
+	m.context_auto_trim = &b
+}
+
+// ContextAutoTrim returns the value of the "context_auto_trim" field in the mutation.
+func (m *APIKeyMutation) ContextAutoTrim() (r bool, exists bool) {
+	v := m.context_auto_trim
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldContextAutoTrim returns the old "context_auto_trim" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldContextAutoTrim(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldContextAutoTrim is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldContextAutoTrim requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldContextAutoTrim: %w", err)
+	}
+	return oldValue.ContextAutoTrim, nil
+}
+
+// ResetContextAutoTrim resets all changes to the "context_auto_trim" field.
+func (m *APIKeyMutation) ResetContextAutoTrim() {
+	m.context_auto_trim = nil
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (m *APIKeyMutation) SetMaxBodySize(i int64) {
+	m.max_body_size = &i
+	m.addmax_body_size = nil
+}
+
+// SetParentID sets the "parent_id" field.
+func (m *APIKeyMutation) SetParentID(i int64) {
+	m.parent_id = &i
+	m.addparent_id = nil
+}
+
+// MaxBodySize returns the value of the "max_body_size" field in the mutation.
+func (m *APIKeyMutation) MaxBodySize() (r int64, exists bool) {
+	v := m.max_body_size
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ParentID returns the value of the "parent_id" field in the mutation.
+func (m *APIKeyMutation) ParentID() (r int64, exists bool) {
+	v := m.parent_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMaxBodySize returns the old "max_body_size" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldMaxBodySize(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMaxBodySize is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMaxBodySize requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMaxBodySize: %w", err)
+	}
+	return oldValue.MaxBodySize, nil
+}
+
+// OldParentID returns the old "parent_id" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldParentID(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldParentID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldParentID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldParentID: %w", err)
+	}
+	return oldValue.ParentID, nil
+}
+
+// AddMaxBodySize adds i to the "max_body_size" field.
+func (m *APIKeyMutation) AddMaxBodySize(i int64) {
+	if m.addmax_body_size != nil {
+		*m.addmax_body_size += i
+	} else {
+		m.addmax_body_size = &i
+	}
+}
+
+// AddParentID adds i to the "parent_id" field.
+func (m *APIKeyMutation) AddParentID(i int64) {
+	if m.addparent_id != nil {
+		*m.addparent_id += i
+	} else {
+		m.addparent_id = &i
+	}
+}
+
+// AddedMaxBodySize returns the value that was added to the "max_body_size" field in this mutation.
+func (m *APIKeyMutation) AddedMaxBodySize() (r int64, exists bool) {
+	v := m.addmax_body_size
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// AddedParentID returns the value that was added to the "parent_id" field in this mutation.
+func (m *APIKeyMutation) AddedParentID() (r int64, exists bool) {
+	v := m.addparent_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMaxBodySize resets all changes to the "max_body_size" field.
+func (m *APIKeyMutation) ResetMaxBodySize() {
+	m.max_body_size = nil
+	m.addmax_body_size = nil
+}
+
+// ResetParentID resets all changes to the "parent_id" field.
+func (m *APIKeyMutation) ResetParentID() {
+	m.parent_id = nil
+	m.addparent_id = nil
+}
+
+// SetRequestCoalescing sets the "request_coalescing" field.
+func (m *APIKeyMutation) SetRequestCoalescing(b bool) {
+	m.request_coalescing = &b
+}
+
+// RequestCoalescing returns the value of the "request_coalescing" field in the mutation.
+func (m *APIKeyMutation) RequestCoalescing() (r bool, exists bool) {
+	v := m.request_coalescing
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRequestCoalescing returns the old "request_coalescing" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldRequestCoalescing(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRequestCoalescing is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRequestCoalescing requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRequestCoalescing: %w", err)
+	}
+	return oldValue.RequestCoalescing, nil
+}
+
+// ResetRequestCoalescing resets all changes to the "request_coalescing" field.
+func (m *APIKeyMutation) ResetRequestCoalescing() {
+	m.request_coalescing = nil
+}
+
+// SetTokenBucketBurst sets the "token_bucket_burst" field.
+func (m *APIKeyMutation) SetTokenBucketBurst(i int) {
+	m.token_bucket_burst = &i
+	m.addtoken_bucket_burst = nil
+}
+
+// TokenBucketBurst returns the value of the "token_bucket_burst" field in the mutation.
+func (m *APIKeyMutation) TokenBucketBurst() (r int, exists bool) {
+	v := m.token_bucket_burst
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTokenBucketBurst returns the old "token_bucket_burst" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldTokenBucketBurst(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTokenBucketBurst is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTokenBucketBurst requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTokenBucketBurst: %w", err)
+	}
+	return oldValue.TokenBucketBurst, nil
+}
+
+// AddTokenBucketBurst adds i to the "token_bucket_burst" field.
+func (m *APIKeyMutation) AddTokenBucketBurst(i int) {
+	if m.addtoken_bucket_burst != nil {
+		*m.addtoken_bucket_burst += i
+	} else {
+		m.addtoken_bucket_burst = &i
+	}
+}
+
+// AddedTokenBucketBurst returns the value that was added to the "token_bucket_burst" field in this mutation.
+func (m *APIKeyMutation) AddedTokenBucketBurst() (r int, exists bool) {
+	v := m.addtoken_bucket_burst
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetTokenBucketBurst resets all changes to the "token_bucket_burst" field.
+func (m *APIKeyMutation) ResetTokenBucketBurst() {
+	m.token_bucket_burst = nil
+	m.addtoken_bucket_burst = nil
+}
+
+// SetTokenBucketRefillRate sets the "token_bucket_refill_rate" field.
+func (m *APIKeyMutation) SetTokenBucketRefillRate(f float64) {
+	m.token_bucket_refill_rate = &f
+	m.addtoken_bucket_refill_rate = nil
+}
+
+// TokenBucketRefillRate returns the value of the "token_bucket_refill_rate" field in the mutation.
+func (m *APIKeyMutation) TokenBucketRefillRate() (r float64, exists bool) {
+	v := m.token_bucket_refill_rate
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTokenBucketRefillRate returns the old "token_bucket_refill_rate" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldTokenBucketRefillRate(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTokenBucketRefillRate is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTokenBucketRefillRate requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTokenBucketRefillRate: %w", err)
+	}
+	return oldValue.TokenBucketRefillRate, nil
+}
+
+// AddTokenBucketRefillRate adds f to the "token_bucket_refill_rate" field.
+func (m *APIKeyMutation) AddTokenBucketRefillRate(f float64) {
+	if m.addtoken_bucket_refill_rate != nil {
+		*m.addtoken_bucket_refill_rate += f
+	} else {
+		m.addtoken_bucket_refill_rate = &f
+	}
+}
+
+// AddedTokenBucketRefillRate returns the value that was added to the "token_bucket_refill_rate" field in this mutation.
+func (m *APIKeyMutation) AddedTokenBucketRefillRate() (r float64, exists bool) {
+	v := m.addtoken_bucket_refill_rate
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetTokenBucketRefillRate resets all changes to the "token_bucket_refill_rate" field.
+func (m *APIKeyMutation) ResetTokenBucketRefillRate() {
+	m.token_bucket_refill_rate = nil
+	m.addtoken_bucket_refill_rate = nil
+}
+
+// SetMaxCostPerRequest sets the "max_cost_per_request" field.
+func (m *APIKeyMutation) SetMaxCostPerRequest(f float64) {
+	m.max_cost_per_request = &f
+	m.addmax_cost_per_request = nil
+}
+
+// MaxCostPerRequest returns the value of the "max_cost_per_request" field in the mutation.
+func (m *APIKeyMutation) MaxCostPerRequest() (r float64, exists bool) {
+	v := m.max_cost_per_request
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMaxCostPerRequest returns the old "max_cost_per_request" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldMaxCostPerRequest(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMaxCostPerRequest is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMaxCostPerRequest requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMaxCostPerRequest: %w", err)
+	}
+	return oldValue.MaxCostPerRequest, nil
+}
+
+// AddMaxCostPerRequest adds f to the "max_cost_per_request" field.
+func (m *APIKeyMutation) AddMaxCostPerRequest(f float64) {
+	if m.addmax_cost_per_request != nil {
+		*m.addmax_cost_per_request += f
+	} else {
+		m.addmax_cost_per_request = &f
+	}
+}
+
+// AddedMaxCostPerRequest returns the value that was added to the "max_cost_per_request" field in this mutation.
+func (m *APIKeyMutation) AddedMaxCostPerRequest() (r float64, exists bool) {
+	v := m.addmax_cost_per_request
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMaxCostPerRequest resets all changes to the "max_cost_per_request" field.
+func (m *APIKeyMutation) ResetMaxCostPerRequest() {
+	m.max_cost_per_request = nil
+	m.addmax_cost_per_request = nil
+}
+
 // SetQuota sets the "quota" field.
 func (m *APIKeyMutation) SetQuota(f float64) {
 	m.quota = &f
@@ -1380,6 +1892,144 @@ func (m *APIKeyMutation) ResetWindow7dStart() {
 	delete(m.clearedFields, apikey.FieldWindow7dStart)
 }
 
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (m *APIKeyMutation) SetAccountTagSelector(dats domain.AccountTagSelector) {
+	m.account_tag_selector = &dats
+}
+
+// AccountTagSelector returns the value of the "account_tag_selector" field in the mutation.
+func (m *APIKeyMutation) AccountTagSelector() (r domain.AccountTagSelector, exists bool) {
+	v := m.account_tag_selector
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAccountTagSelector returns the old "account_tag_selector" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldAccountTagSelector(ctx context.Context) (v domain.AccountTagSelector, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAccountTagSelector is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAccountTagSelector requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAccountTagSelector: %w", err)
+	}
+	return oldValue.AccountTagSelector, nil
+}
+
+// ResetAccountTagSelector resets all changes to the "account_tag_selector" field.
+func (m *APIKeyMutation) ResetAccountTagSelector() {
+	m.account_tag_selector = nil
+}
+
+// SetAllowedPlatforms sets the "allowed_platforms" field.
+func (m *APIKeyMutation) SetAllowedPlatforms(s []string) {
+	m.allowed_platforms = &s
+	m.appendallowed_platforms = nil
+}
+
+// SetAllowedModels sets the "allowed_models" field.
+func (m *APIKeyMutation) SetAllowedModels(s []string) {
+	m.allowed_models = &s
+	m.appendallowed_models = nil
+}
+
+// AllowedPlatforms returns the value of the "allowed_platforms" field in the mutation.
+func (m *APIKeyMutation) AllowedPlatforms() (r []string, exists bool) {
+	v := m.allowed_platforms
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// AllowedModels returns the value of the "allowed_models" field in the mutation.
+func (m *APIKeyMutation) AllowedModels() (r []string, exists bool) {
+	v := m.allowed_models
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAllowedPlatforms returns the old "allowed_platforms" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldAllowedPlatforms(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowedPlatforms is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowedPlatforms requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowedPlatforms: %w", err)
+	}
+	return oldValue.AllowedPlatforms, nil
+}
+
+// OldAllowedModels returns the old "allowed_models" field's value of the APIKey entity.
+// If the APIKey object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *APIKeyMutation) OldAllowedModels(ctx context.Context) (v []string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAllowedModels is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAllowedModels requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAllowedModels: %w", err)
+	}
+	return oldValue.AllowedModels, nil
+}
+
+// AppendAllowedPlatforms adds s to the "allowed_platforms" field.
+func (m *APIKeyMutation) AppendAllowedPlatforms(s []string) {
+	m.appendallowed_platforms = append(m.appendallowed_platforms, s...)
+}
+
+// AppendAllowedModels adds s to the "allowed_models" field.
+func (m *APIKeyMutation) AppendAllowedModels(s []string) {
+	m.appendallowed_models = append(m.appendallowed_models, s...)
+}
+
+// AppendedAllowedPlatforms returns the list of values that were appended to the "allowed_platforms" field in this mutation.
+func (m *APIKeyMutation) AppendedAllowedPlatforms() ([]string, bool) {
+	if len(m.appendallowed_platforms) == 0 {
+		return nil, false
+	}
+	return m.appendallowed_platforms, true
+}
+
+// AppendedAllowedModels returns the list of values that were appended to the "allowed_models" field in this mutation.
+func (m *APIKeyMutation) AppendedAllowedModels() ([]string, bool) {
+	if len(m.appendallowed_models) == 0 {
+		return nil, false
+	}
+	return m.appendallowed_models, true
+}
+
+// ResetAllowedPlatforms resets all changes to the "allowed_platforms" field.
+func (m *APIKeyMutation) ResetAllowedPlatforms() {
+	m.allowed_platforms = nil
+	m.appendallowed_platforms = nil
+}
+
+// ResetAllowedModels resets all changes to the "allowed_models" field.
+func (m *APIKeyMutation) ResetAllowedModels() {
+	m.allowed_models = nil
+	m.appendallowed_models = nil
+}
+
 // ClearUser clears the "user" edge to the User entity.
 func (m *APIKeyMutation) ClearUser() {
 	m.cleareduser = true
@@ -1522,7 +2172,7 @@ func (m *APIKeyMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *APIKeyMutation) Fields() []string {
-	fields := make([]string, 0, 23)
+	fields := make([]string, 0, 36)
 	if m.created_at != nil {
 		fields = append(fields, apikey.FieldCreatedAt)
 	}
@@ -1556,6 +2206,36 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.ip_blacklist != nil {
 		fields = append(fields, apikey.FieldIPBlacklist)
 	}
+	if m.scopes != nil {
+		fields = append(fields, apikey.FieldScopes)
+	}
+	if m.priority != nil {
+		fields = append(fields, apikey.FieldPriority)
+	}
+	if m.moderation_mode != nil {
+		fields = append(fields, apikey.FieldModerationMode)
+	}
+	if m.context_auto_trim != nil {
+		fields = append(fields, apikey.FieldContextAutoTrim)
+	}
+	if m.max_body_size != nil {
+		fields = append(fields, apikey.FieldMaxBodySize)
+	}
+	if m.parent_id != nil {
+		fields = append(fields, apikey.FieldParentID)
+	}
+	if m.request_coalescing != nil {
+		fields = append(fields, apikey.FieldRequestCoalescing)
+	}
+	if m.token_bucket_burst != nil {
+		fields = append(fields, apikey.FieldTokenBucketBurst)
+	}
+	if m.token_bucket_refill_rate != nil {
+		fields = append(fields, apikey.FieldTokenBucketRefillRate)
+	}
+	if m.max_cost_per_request != nil {
+		fields = append(fields, apikey.FieldMaxCostPerRequest)
+	}
 	if m.quota != nil {
 		fields = append(fields, apikey.FieldQuota)
 	}
@@ -1592,6 +2272,15 @@ func (m *APIKeyMutation) Fields() []string {
 	if m.window_7d_start != nil {
 		fields = append(fields, apikey.FieldWindow7dStart)
 	}
+	if m.account_tag_selector != nil {
+		fields = append(fields, apikey.FieldAccountTagSelector)
+	}
+	if m.allowed_platforms != nil {
+		fields = append(fields, apikey.FieldAllowedPlatforms)
+	}
+	if m.allowed_models != nil {
+		fields = append(fields, apikey.FieldAllowedModels)
+	}
 	return fields
 }
 
@@ -1622,6 +2311,26 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.IPWhitelist()
 	case apikey.FieldIPBlacklist:
 		return m.IPBlacklist()
+	case apikey.FieldScopes:
+		return m.Scopes()
+	case apikey.FieldPriority:
+		return m.Priority()
+	case apikey.FieldModerationMode:
+		return m.ModerationMode()
+	case apikey.FieldContextAutoTrim:
+		return m.ContextAutoTrim()
+	case apikey.FieldMaxBodySize:
+		return m.MaxBodySize()
+	case apikey.FieldParentID:
+		return m.ParentID()
+	case apikey.FieldRequestCoalescing:
+		return m.RequestCoalescing()
+	case apikey.FieldTokenBucketBurst:
+		return m.TokenBucketBurst()
+	case apikey.FieldTokenBucketRefillRate:
+		return m.TokenBucketRefillRate()
+	case apikey.FieldMaxCostPerRequest:
+		return m.MaxCostPerRequest()
 	case apikey.FieldQuota:
 		return m.Quota()
 	case apikey.FieldQuotaUsed:
@@ -1646,6 +2355,12 @@ func (m *APIKeyMutation) Field(name string) (ent.Value, bool) {
 		return m.Window1dStart()
 	case apikey.FieldWindow7dStart:
 		return m.Window7dStart()
+	case apikey.FieldAccountTagSelector:
+		return m.AccountTagSelector()
+	case apikey.FieldAllowedPlatforms:
+		return m.AllowedPlatforms()
+	case apikey.FieldAllowedModels:
+		return m.AllowedModels()
 	}
 	return nil, false
 }
@@ -1677,6 +2392,26 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldIPWhitelist(ctx)
 	case apikey.FieldIPBlacklist:
 		return m.OldIPBlacklist(ctx)
+	case apikey.FieldScopes:
+		return m.OldScopes(ctx)
+	case apikey.FieldPriority:
+		return m.OldPriority(ctx)
+	case apikey.FieldModerationMode:
+		return m.OldModerationMode(ctx)
+	case apikey.FieldContextAutoTrim:
+		return m.OldContextAutoTrim(ctx)
+	case apikey.FieldMaxBodySize:
+		return m.OldMaxBodySize(ctx)
+	case apikey.FieldParentID:
+		return m.OldParentID(ctx)
+	case apikey.FieldRequestCoalescing:
+		return m.OldRequestCoalescing(ctx)
+	case apikey.FieldTokenBucketBurst:
+		return m.OldTokenBucketBurst(ctx)
+	case apikey.FieldTokenBucketRefillRate:
+		return m.OldTokenBucketRefillRate(ctx)
+	case apikey.FieldMaxCostPerRequest:
+		return m.OldMaxCostPerRequest(ctx)
 	case apikey.FieldQuota:
 		return m.OldQuota(ctx)
 	case apikey.FieldQuotaUsed:
@@ -1701,6 +2436,12 @@ func (m *APIKeyMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldWindow1dStart(ctx)
 	case apikey.FieldWindow7dStart:
 		return m.OldWindow7dStart(ctx)
+	case apikey.FieldAccountTagSelector:
+		return m.OldAccountTagSelector(ctx)
+	case apikey.FieldAllowedPlatforms:
+		return m.OldAllowedPlatforms(ctx)
+	case apikey.FieldAllowedModels:
+		return m.OldAllowedModels(ctx)
 	}
 	return nil, fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1787,6 +2528,76 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetIPBlacklist(v)
 		return nil
+	case apikey.FieldScopes:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetScopes(v)
+		return nil
+	case apikey.FieldPriority:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPriority(v)
+		return nil
+	case apikey.FieldModerationMode:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetModerationMode(v)
+		return nil
+	case apikey.FieldContextAutoTrim:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetContextAutoTrim(v)
+		return nil
+	case apikey.FieldMaxBodySize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMaxBodySize(v)
+		return nil
+	case apikey.FieldParentID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetParentID(v)
+		return nil
+	case apikey.FieldRequestCoalescing:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestCoalescing(v)
+		return nil
+	case apikey.FieldTokenBucketBurst:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTokenBucketBurst(v)
+		return nil
+	case apikey.FieldTokenBucketRefillRate:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTokenBucketRefillRate(v)
+		return nil
+	case apikey.FieldMaxCostPerRequest:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMaxCostPerRequest(v)
+		return nil
 	case apikey.FieldQuota:
 		v, ok := value.(float64)
 		if !ok {
@@ -1871,6 +2682,27 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetWindow7dStart(v)
 		return nil
+	case apikey.FieldAccountTagSelector:
+		v, ok := value.(domain.AccountTagSelector)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAccountTagSelector(v)
+		return nil
+	case apikey.FieldAllowedPlatforms:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowedPlatforms(v)
+		return nil
+	case apikey.FieldAllowedModels:
+		v, ok := value.([]string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAllowedModels(v)
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -1879,6 +2711,21 @@ func (m *APIKeyMutation) SetField(name string, value ent.Value) error {
 // this mutation.
 func (m *APIKeyMutation) AddedFields() []string {
 	var fields []string
+	if m.addmax_body_size != nil {
+		fields = append(fields, apikey.FieldMaxBodySize)
+	}
+	if m.addparent_id != nil {
+		fields = append(fields, apikey.FieldParentID)
+	}
+	if m.addtoken_bucket_burst != nil {
+		fields = append(fields, apikey.FieldTokenBucketBurst)
+	}
+	if m.addtoken_bucket_refill_rate != nil {
+		fields = append(fields, apikey.FieldTokenBucketRefillRate)
+	}
+	if m.addmax_cost_per_request != nil {
+		fields = append(fields, apikey.FieldMaxCostPerRequest)
+	}
 	if m.addquota != nil {
 		fields = append(fields, apikey.FieldQuota)
 	}
@@ -1911,6 +2758,16 @@ func (m *APIKeyMutation) AddedFields() []string {
 // was not set, or was not defined in the schema.
 func (m *APIKeyMutation) AddedField(name string) (ent.Value, bool) {
 	switch name {
+	case apikey.FieldMaxBodySize:
+		return m.AddedMaxBodySize()
+	case apikey.FieldParentID:
+		return m.AddedParentID()
+	case apikey.FieldTokenBucketBurst:
+		return m.AddedTokenBucketBurst()
+	case apikey.FieldTokenBucketRefillRate:
+		return m.AddedTokenBucketRefillRate()
+	case apikey.FieldMaxCostPerRequest:
+		return m.AddedMaxCostPerRequest()
 	case apikey.FieldQuota:
 		return m.AddedQuota()
 	case apikey.FieldQuotaUsed:
@@ -1936,6 +2793,41 @@ func (m *APIKeyMutation) AddedField(name string) (ent.Value, bool) {
 // type.
 func (m *APIKeyMutation) AddField(name string, value ent.Value) error {
 	switch name {
+	case apikey.FieldMaxBodySize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMaxBodySize(v)
+		return nil
+	case apikey.FieldParentID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddParentID(v)
+		return nil
+	case apikey.FieldTokenBucketBurst:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddTokenBucketBurst(v)
+		return nil
+	case apikey.FieldTokenBucketRefillRate:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddTokenBucketRefillRate(v)
+		return nil
+	case apikey.FieldMaxCostPerRequest:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMaxCostPerRequest(v)
+		return nil
 	case apikey.FieldQuota:
 		v, ok := value.(float64)
 		if !ok {
@@ -2015,6 +2907,9 @@ func (m *APIKeyMutation) ClearedFields() []string {
 	if m.FieldCleared(apikey.FieldIPBlacklist) {
 		fields = append(fields, apikey.FieldIPBlacklist)
 	}
+	if m.FieldCleared(apikey.FieldScopes) {
+		fields = append(fields, apikey.FieldScopes)
+	}
 	if m.FieldCleared(apikey.FieldExpiresAt) {
 		fields = append(fields, apikey.FieldExpiresAt)
 	}
@@ -2056,6 +2951,9 @@ func (m *APIKeyMutation) ClearField(name string) error {
 	case apikey.FieldIPBlacklist:
 		m.ClearIPBlacklist()
 		return nil
+	case apikey.FieldScopes:
+		m.ClearScopes()
+		return nil
 	case apikey.FieldExpiresAt:
 		m.ClearExpiresAt()
 		return nil
@@ -2109,6 +3007,36 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldIPBlacklist:
 		m.ResetIPBlacklist()
 		return nil
+	case apikey.FieldScopes:
+		m.ResetScopes()
+		return nil
+	case apikey.FieldPriority:
+		m.ResetPriority()
+		return nil
+	case apikey.FieldModerationMode:
+		m.ResetModerationMode()
+		return nil
+	case apikey.FieldContextAutoTrim:
+		m.ResetContextAutoTrim()
+		return nil
+	case apikey.FieldMaxBodySize:
+		m.ResetMaxBodySize()
+		return nil
+	case apikey.FieldParentID:
+		m.ResetParentID()
+		return nil
+	case apikey.FieldRequestCoalescing:
+		m.ResetRequestCoalescing()
+		return nil
+	case apikey.FieldTokenBucketBurst:
+		m.ResetTokenBucketBurst()
+		return nil
+	case apikey.FieldTokenBucketRefillRate:
+		m.ResetTokenBucketRefillRate()
+		return nil
+	case apikey.FieldMaxCostPerRequest:
+		m.ResetMaxCostPerRequest()
+		return nil
 	case apikey.FieldQuota:
 		m.ResetQuota()
 		return nil
@@ -2145,6 +3073,15 @@ func (m *APIKeyMutation) ResetField(name string) error {
 	case apikey.FieldWindow7dStart:
 		m.ResetWindow7dStart()
 		return nil
+	case apikey.FieldAccountTagSelector:
+		m.ResetAccountTagSelector()
+		return nil
+	case apikey.FieldAllowedPlatforms:
+		m.ResetAllowedPlatforms()
+		return nil
+	case apikey.FieldAllowedModels:
+		m.ResetAllowedModels()
+		return nil
 	}
 	return fmt.Errorf("unknown APIKey field %s", name)
 }
@@ -2306,6 +3243,9 @@ type AccountMutation struct {
 	session_window_start      *time.Time
 	session_window_end        *time.Time
 	session_window_status     *string
+	owner_user_id             *int64
+	addowner_user_id          *int64
+	tags_exclusive            *bool
 	clearedFields             map[string]struct{}
 	groups                    map[int64]struct{}
 	removedgroups             map[int64]struct{}
@@ -3702,6 +4642,112 @@ func (m *AccountMutation) ResetSessionWindowStatus() {
 	delete(m.clearedFields, account.FieldSessionWindowStatus)
 }
 
+// SetOwnerUserID sets the "owner_user_id" field.
+func (m *AccountMutation) SetOwnerUserID(i int64) {
+	m.owner_user_id = &i
+	m.addowner_user_id = nil
+}
+
+// OwnerUserID returns the value of the "owner_user_id" field in the mutation.
+func (m *AccountMutation) OwnerUserID() (r int64, exists bool) {
+	v := m.owner_user_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldOwnerUserID returns the old "owner_user_id" field's value of the Account entity.
+// If the Account object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AccountMutation) OldOwnerUserID(ctx context.Context) (v *int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldOwnerUserID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldOwnerUserID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldOwnerUserID: %w", err)
+	}
+	return oldValue.OwnerUserID, nil
+}
+
+// AddOwnerUserID adds i to the "owner_user_id" field.
+func (m *AccountMutation) AddOwnerUserID(i int64) {
+	if m.addowner_user_id != nil {
+		*m.addowner_user_id += i
+	} else {
+		m.addowner_user_id = &i
+	}
+}
+
+// AddedOwnerUserID returns the value that was added to the "owner_user_id" field in this mutation.
+func (m *AccountMutation) AddedOwnerUserID() (r int64, exists bool) {
+	v := m.addowner_user_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearOwnerUserID clears the value of the "owner_user_id" field.
+func (m *AccountMutation) ClearOwnerUserID() {
+	m.owner_user_id = nil
+	m.addowner_user_id = nil
+	m.clearedFields[account.FieldOwnerUserID] = struct{}{}
+}
+
+// OwnerUserIDCleared returns if the "owner_user_id" field was cleared in this mutation.
+func (m *AccountMutation) OwnerUserIDCleared() bool {
+	_, ok := m.clearedFields[account.FieldOwnerUserID]
+	return ok
+}
+
+// ResetOwnerUserID resets all changes to the "owner_user_id" field.
+func (m *AccountMutation) ResetOwnerUserID() {
+	m.owner_user_id = nil
+	m.addowner_user_id = nil
+	delete(m.clearedFields, account.FieldOwnerUserID)
+}
+
+// SetTagsExclusive sets the "tags_exclusive" field.
+func (m *AccountMutation) SetTagsExclusive(b bool) {
+	m.tags_exclusive = &b
+}
+
+// TagsExclusive returns the value of the "tags_exclusive" field in the mutation.
+func (m *AccountMutation) TagsExclusive() (r bool, exists bool) {
+	v := m.tags_exclusive
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldTagsExclusive returns the old "tags_exclusive" field's value of the Account entity.
+// If the Account object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *AccountMutation) OldTagsExclusive(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldTagsExclusive is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldTagsExclusive requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldTagsExclusive: %w", err)
+	}
+	return oldValue.TagsExclusive, nil
+}
+
+// ResetTagsExclusive resets all changes to the "tags_exclusive" field.
+func (m *AccountMutation) ResetTagsExclusive() {
+	m.tags_exclusive = nil
+}
+
 // AddGroupIDs adds the "groups" edge to the Group entity by ids.
 func (m *AccountMutation) AddGroupIDs(ids ...int64) {
 	if m.groups == nil {
@@ -3871,7 +4917,7 @@ func (m *AccountMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *AccountMutation) Fields() []string {
-	fields := make([]string, 0, 28)
+	fields := make([]string, 0, 30)
 	if m.created_at != nil {
 		fields = append(fields, account.FieldCreatedAt)
 	}
@@ -3956,6 +5002,12 @@ func (m *AccountMutation) Fields() []string {
 	if m.session_window_status != nil {
 		fields = append(fields, account.FieldSessionWindowStatus)
 	}
+	if m.owner_user_id != nil {
+		fields = append(fields, account.FieldOwnerUserID)
+	}
+	if m.tags_exclusive != nil {
+		fields = append(fields, account.FieldTagsExclusive)
+	}
 	return fields
 }
 
@@ -4020,6 +5072,10 @@ func (m *AccountMutation) Field(name string) (ent.Value, bool) {
 		return m.SessionWindowEnd()
 	case account.FieldSessionWindowStatus:
 		return m.SessionWindowStatus()
+	case account.FieldOwnerUserID:
+		return m.OwnerUserID()
+	case account.FieldTagsExclusive:
+		return m.TagsExclusive()
 	}
 	return nil, false
 }
@@ -4085,6 +5141,10 @@ func (m *AccountMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldSessionWindowEnd(ctx)
 	case account.FieldSessionWindowStatus:
 		return m.OldSessionWindowStatus(ctx)
+	case account.FieldOwnerUserID:
+		return m.OldOwnerUserID(ctx)
+	case account.FieldTagsExclusive:
+		return m.OldTagsExclusive(ctx)
 	}
 	return nil, fmt.Errorf("unknown Account field %s", name)
 }
@@ -4290,6 +5350,20 @@ func (m *AccountMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetSessionWindowStatus(v)
 		return nil
+	case account.FieldOwnerUserID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetOwnerUserID(v)
+		return nil
+	case account.FieldTagsExclusive:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetTagsExclusive(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Account field %s", name)
 }
@@ -4310,6 +5384,9 @@ func (m *AccountMutation) AddedFields() []string {
 	if m.addrate_multiplier != nil {
 		fields = append(fields, account.FieldRateMultiplier)
 	}
+	if m.addowner_user_id != nil {
+		fields = append(fields, account.FieldOwnerUserID)
+	}
 	return fields
 }
 
@@ -4326,6 +5403,8 @@ func (m *AccountMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedPriority()
 	case account.FieldRateMultiplier:
 		return m.AddedRateMultiplier()
+	case account.FieldOwnerUserID:
+		return m.AddedOwnerUserID()
 	}
 	return nil, false
 }
@@ -4363,6 +5442,13 @@ func (m *AccountMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddRateMultiplier(v)
 		return nil
+	case account.FieldOwnerUserID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddOwnerUserID(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Account numeric field %s", name)
 }
@@ -4416,6 +5502,9 @@ func (m *AccountMutation) ClearedFields() []string {
 	if m.FieldCleared(account.FieldSessionWindowStatus) {
 		fields = append(fields, account.FieldSessionWindowStatus)
 	}
+	if m.FieldCleared(account.FieldOwnerUserID) {
+		fields = append(fields, account.FieldOwnerUserID)
+	}
 	return fields
 }
 
@@ -4475,6 +5564,9 @@ func (m *AccountMutation) ClearField(name string) error {
 	case account.FieldSessionWindowStatus:
 		m.ClearSessionWindowStatus()
 		return nil
+	case account.FieldOwnerUserID:
+		m.ClearOwnerUserID()
+		return nil
 	}
 	return fmt.Errorf("unknown Account nullable field %s", name)
 }
@@ -4567,6 +5659,12 @@ func (m *AccountMutation) ResetField(name string) error {
 	case account.FieldSessionWindowStatus:
 		m.ResetSessionWindowStatus()
 		return nil
+	case account.FieldOwnerUserID:
+		m.ResetOwnerUserID()
+		return nil
+	case account.FieldTagsExclusive:
+		m.ResetTagsExclusive()
+		return nil
 	}
 	return fmt.Errorf("unknown Account field %s", name)
 }
@@ -13445,6 +14543,10 @@ type ErrorPassthroughRuleMutation struct {
 	custom_message    *string
 	skip_monitoring   *bool
 	description       *string
+	expires_at        *time.Time
+	hit_count         *int64
+	addhit_count      *int64
+	last_hit_at       *time.Time
 	clearedFields     map[string]struct{}
 	done              bool
 	oldValue          func(context.Context) (*ErrorPassthroughRule, error)
@@ -14256,6 +15358,160 @@ func (m *ErrorPassthroughRuleMutation) ResetDescription() {
 	delete(m.clearedFields, errorpassthroughrule.FieldDescription)
 }
 
+// SetExpiresAt sets the "expires_at" field.
+func (m *ErrorPassthroughRuleMutation) SetExpiresAt(t time.Time) {
+	m.expires_at = &t
+}
+
+// ExpiresAt returns the value of the "expires_at" field in the mutation.
+func (m *ErrorPassthroughRuleMutation) ExpiresAt() (r time.Time, exists bool) {
+	v := m.expires_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldExpiresAt returns the old "expires_at" field's value of the ErrorPassthroughRule entity.
+// If the ErrorPassthroughRule object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ErrorPassthroughRuleMutation) OldExpiresAt(ctx context.Context) (v *time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldExpiresAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldExpiresAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldExpiresAt: %w", err)
+	}
+	return oldValue.ExpiresAt, nil
+}
+
+// ClearExpiresAt clears the value of the "expires_at" field.
+func (m *ErrorPassthroughRuleMutation) ClearExpiresAt() {
+	m.expires_at = nil
+	m.clearedFields[errorpassthroughrule.FieldExpiresAt] = struct{}{}
+}
+
+// ExpiresAtCleared returns if the "expires_at" field was cleared in this mutation.
+func (m *ErrorPassthroughRuleMutation) ExpiresAtCleared() bool {
+	_, ok := m.clearedFields[errorpassthroughrule.FieldExpiresAt]
+	return ok
+}
+
+// ResetExpiresAt resets all changes to the "expires_at" field.
+func (m *ErrorPassthroughRuleMutation) ResetExpiresAt() {
+	m.expires_at = nil
+	delete(m.clearedFields, errorpassthroughrule.FieldExpiresAt)
+}
+
+// SetHitCount sets the "hit_count" field.
+func (m *ErrorPassthroughRuleMutation) SetHitCount(i int64) {
+	m.hit_count = &i
+	m.addhit_count = nil
+}
+
+// HitCount returns the value of the "hit_count" field in the mutation.
+func (m *ErrorPassthroughRuleMutation) HitCount() (r int64, exists bool) {
+	v := m.hit_count
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldHitCount returns the old "hit_count" field's value of the ErrorPassthroughRule entity.
+// If the ErrorPassthroughRule object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ErrorPassthroughRuleMutation) OldHitCount(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldHitCount is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldHitCount requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldHitCount: %w", err)
+	}
+	return oldValue.HitCount, nil
+}
+
+// AddHitCount adds i to the "hit_count" field.
+func (m *ErrorPassthroughRuleMutation) AddHitCount(i int64) {
+	if m.addhit_count != nil {
+		*m.addhit_count += i
+	} else {
+		m.addhit_count = &i
+	}
+}
+
+// AddedHitCount returns the value that was added to the "hit_count" field in this mutation.
+func (m *ErrorPassthroughRuleMutation) AddedHitCount() (r int64, exists bool) {
+	v := m.addhit_count
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetHitCount resets all changes to the "hit_count" field.
+func (m *ErrorPassthroughRuleMutation) ResetHitCount() {
+	m.hit_count = nil
+	m.addhit_count = nil
+}
+
+// SetLastHitAt sets the "last_hit_at" field.
+func (m *ErrorPassthroughRuleMutation) SetLastHitAt(t time.Time) {
+	m.last_hit_at = &t
+}
+
+// LastHitAt returns the value of the "last_hit_at" field in the mutation.
+func (m *ErrorPassthroughRuleMutation) LastHitAt() (r time.Time, exists bool) {
+	v := m.last_hit_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldLastHitAt returns the old "last_hit_at" field's value of the ErrorPassthroughRule entity.
+// If the ErrorPassthroughRule object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ErrorPassthroughRuleMutation) OldLastHitAt(ctx context.Context) (v *time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldLastHitAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldLastHitAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldLastHitAt: %w", err)
+	}
+	return oldValue.LastHitAt, nil
+}
+
+// ClearLastHitAt clears the value of the "last_hit_at" field.
+func (m *ErrorPassthroughRuleMutation) ClearLastHitAt() {
+	m.last_hit_at = nil
+	m.clearedFields[errorpassthroughrule.FieldLastHitAt] = struct{}{}
+}
+
+// LastHitAtCleared returns if the "last_hit_at" field was cleared in this mutation.
+func (m *ErrorPassthroughRuleMutation) LastHitAtCleared() bool {
+	_, ok := m.clearedFields[errorpassthroughrule.FieldLastHitAt]
+	return ok
+}
+
+// ResetLastHitAt resets all changes to the "last_hit_at" field.
+func (m *ErrorPassthroughRuleMutation) ResetLastHitAt() {
+	m.last_hit_at = nil
+	delete(m.clearedFields, errorpassthroughrule.FieldLastHitAt)
+}
+
 // Where appends a list predicates to the ErrorPassthroughRuleMutation builder.
 func (m *ErrorPassthroughRuleMutation) Where(ps ...predicate.ErrorPassthroughRule) {
 	m.predicates = append(m.predicates, ps...)
@@ -14290,7 +15546,7 @@ func (m *ErrorPassthroughRuleMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *ErrorPassthroughRuleMutation) Fields() []string {
-	fields := make([]string, 0, 15)
+	fields := make([]string, 0, 18)
 	if m.created_at != nil {
 		fields = append(fields, errorpassthroughrule.FieldCreatedAt)
 	}
@@ -14336,6 +15592,15 @@ func (m *ErrorPassthroughRuleMutation) Fields() []string {
 	if m.description != nil {
 		fields = append(fields, errorpassthroughrule.FieldDescription)
 	}
+	if m.expires_at != nil {
+		fields = append(fields, errorpassthroughrule.FieldExpiresAt)
+	}
+	if m.hit_count != nil {
+		fields = append(fields, errorpassthroughrule.FieldHitCount)
+	}
+	if m.last_hit_at != nil {
+		fields = append(fields, errorpassthroughrule.FieldLastHitAt)
+	}
 	return fields
 }
 
@@ -14374,6 +15639,12 @@ func (m *ErrorPassthroughRuleMutation) Field(name string) (ent.Value, bool) {
 		return m.SkipMonitoring()
 	case errorpassthroughrule.FieldDescription:
 		return m.Description()
+	case errorpassthroughrule.FieldExpiresAt:
+		return m.ExpiresAt()
+	case errorpassthroughrule.FieldHitCount:
+		return m.HitCount()
+	case errorpassthroughrule.FieldLastHitAt:
+		return m.LastHitAt()
 	}
 	return nil, false
 }
@@ -14413,6 +15684,12 @@ func (m *ErrorPassthroughRuleMutation) OldField(ctx context.Context, name string
 		return m.OldSkipMonitoring(ctx)
 	case errorpassthroughrule.FieldDescription:
 		return m.OldDescription(ctx)
+	case errorpassthroughrule.FieldExpiresAt:
+		return m.OldExpiresAt(ctx)
+	case errorpassthroughrule.FieldHitCount:
+		return m.OldHitCount(ctx)
+	case errorpassthroughrule.FieldLastHitAt:
+		return m.OldLastHitAt(ctx)
 	}
 	return nil, fmt.Errorf("unknown ErrorPassthroughRule field %s", name)
 }
@@ -14527,6 +15804,27 @@ func (m *ErrorPassthroughRuleMutation) SetField(name string, value ent.Value) er
 		}
 		m.SetDescription(v)
 		return nil
+	case errorpassthroughrule.FieldExpiresAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetExpiresAt(v)
+		return nil
+	case errorpassthroughrule.FieldHitCount:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetHitCount(v)
+		return nil
+	case errorpassthroughrule.FieldLastHitAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetLastHitAt(v)
+		return nil
 	}
 	return fmt.Errorf("unknown ErrorPassthroughRule field %s", name)
 }
@@ -14541,6 +15839,9 @@ func (m *ErrorPassthroughRuleMutation) AddedFields() []string {
 	if m.addresponse_code != nil {
 		fields = append(fields, errorpassthroughrule.FieldResponseCode)
 	}
+	if m.addhit_count != nil {
+		fields = append(fields, errorpassthroughrule.FieldHitCount)
+	}
 	return fields
 }
 
@@ -14553,6 +15854,8 @@ func (m *ErrorPassthroughRuleMutation) AddedField(name string) (ent.Value, bool)
 		return m.AddedPriority()
 	case errorpassthroughrule.FieldResponseCode:
 		return m.AddedResponseCode()
+	case errorpassthroughrule.FieldHitCount:
+		return m.AddedHitCount()
 	}
 	return nil, false
 }
@@ -14576,6 +15879,13 @@ func (m *ErrorPassthroughRuleMutation) AddField(name string, value ent.Value) er
 		}
 		m.AddResponseCode(v)
 		return nil
+	case errorpassthroughrule.FieldHitCount:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddHitCount(v)
+		return nil
 	}
 	return fmt.Errorf("unknown ErrorPassthroughRule numeric field %s", name)
 }
@@ -14602,6 +15912,12 @@ func (m *ErrorPassthroughRuleMutation) ClearedFields() []string {
 	if m.FieldCleared(errorpassthroughrule.FieldDescription) {
 		fields = append(fields, errorpassthroughrule.FieldDescription)
 	}
+	if m.FieldCleared(errorpassthroughrule.FieldExpiresAt) {
+		fields = append(fields, errorpassthroughrule.FieldExpiresAt)
+	}
+	if m.FieldCleared(errorpassthroughrule.FieldLastHitAt) {
+		fields = append(fields, errorpassthroughrule.FieldLastHitAt)
+	}
 	return fields
 }
 
@@ -14634,6 +15950,12 @@ func (m *ErrorPassthroughRuleMutation) ClearField(name string) error {
 	case errorpassthroughrule.FieldDescription:
 		m.ClearDescription()
 		return nil
+	case errorpassthroughrule.FieldExpiresAt:
+		m.ClearExpiresAt()
+		return nil
+	case errorpassthroughrule.FieldLastHitAt:
+		m.ClearLastHitAt()
+		return nil
 	}
 	return fmt.Errorf("unknown ErrorPassthroughRule nullable field %s", name)
 }
@@ -14687,6 +16009,15 @@ func (m *ErrorPassthroughRuleMutation) ResetField(name string) error {
 	case errorpassthroughrule.FieldDescription:
 		m.ResetDescription()
 		return nil
+	case errorpassthroughrule.FieldExpiresAt:
+		m.ResetExpiresAt()
+		return nil
+	case errorpassthroughrule.FieldHitCount:
+		m.ResetHitCount()
+		return nil
+	case errorpassthroughrule.FieldLastHitAt:
+		m.ResetLastHitAt()
+		return nil
 	}
 	return fmt.Errorf("unknown ErrorPassthroughRule field %s", name)
 }
@@ -14789,6 +16120,18 @@ type GroupMutation struct {
 	messages_dispatch_model_config          *domain.OpenAIMessagesDispatchModelConfig
 	rpm_limit                               *int
 	addrpm_limit                            *int
+	priority                                *string
+	request_param_overrides                 *domain.RequestParamOverrides
+	system_prompt                           *string
+	system_prompt_mode                      *string
+	pii_redaction_mode                      *string
+	max_body_size                           *int64
+	addmax_body_size                        *int64
+	moderation_enabled                      *bool
+	response_headers                        *domain.GroupResponseHeaders
+	organization_id                         *int64
+	addorganization_id                      *int64
+	account_tag_selector                    *domain.AccountTagSelector
 	clearedFields                           map[string]struct{}
 	api_keys                                map[int64]struct{}
 	removedapi_keys                         map[int64]struct{}
@@ -16433,6 +17776,420 @@ func (m *GroupMutation) ResetRpmLimit() {
 	m.addrpm_limit = nil
 }
 
+// SetPriority sets the "priority" field.
+func (m *GroupMutation) SetPriority(s string) {
+	m.priority = &s
+}
+
+// Priority returns the value of the "priority" field in the mutation.
+func (m *GroupMutation) Priority() (r string, exists bool) {
+	v := m.priority
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPriority returns the old "priority" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldPriority(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPriority is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPriority requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPriority: %w", err)
+	}
+	return oldValue.Priority, nil
+}
+
+// ResetPriority resets all changes to the "priority" field.
+func (m *GroupMutation) ResetPriority() {
+	m.priority = nil
+}
+
+// SetRequestParamOverrides sets the "request_param_overrides" field.
+func (m *GroupMutation) SetRequestParamOverrides(dpo domain.RequestParamOverrides) {
+	m.request_param_overrides = &dpo
+}
+
+// RequestParamOverrides returns the value of the "request_param_overrides" field in the mutation.
+func (m *GroupMutation) RequestParamOverrides() (r domain.RequestParamOverrides, exists bool) {
+	v := m.request_param_overrides
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRequestParamOverrides returns the old "request_param_overrides" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldRequestParamOverrides(ctx context.Context) (v domain.RequestParamOverrides, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRequestParamOverrides is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRequestParamOverrides requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRequestParamOverrides: %w", err)
+	}
+	return oldValue.RequestParamOverrides, nil
+}
+
+// ResetRequestParamOverrides resets all changes to the "request_param_overrides" field.
+func (m *GroupMutation) ResetRequestParamOverrides() {
+	m.request_param_overrides = nil
+}
+
+// SetSystemPrompt sets the "system_prompt" field.
+func (m *GroupMutation) SetSystemPrompt(s string) {
+	m.system_prompt = &s
+}
+
+// SystemPrompt returns the value of the "system_prompt" field in the mutation.
+func (m *GroupMutation) SystemPrompt() (r string, exists bool) {
+	v := m.system_prompt
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldSystemPrompt returns the old "system_prompt" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldSystemPrompt(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldSystemPrompt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldSystemPrompt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldSystemPrompt: %w", err)
+	}
+	return oldValue.SystemPrompt, nil
+}
+
+// ResetSystemPrompt resets all changes to the "system_prompt" field.
+func (m *GroupMutation) ResetSystemPrompt() {
+	m.system_prompt = nil
+}
+
+// SetSystemPromptMode sets the "system_prompt_mode" field.
+func (m *GroupMutation) SetSystemPromptMode(s string) {
+	m.system_prompt_mode = &s
+}
+
+// SystemPromptMode returns the value of the "system_prompt_mode" field in the mutation.
+func (m *GroupMutation) SystemPromptMode() (r string, exists bool) {
+	v := m.system_prompt_mode
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldSystemPromptMode returns the old "system_prompt_mode" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldSystemPromptMode(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldSystemPromptMode is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldSystemPromptMode requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldSystemPromptMode: %w", err)
+	}
+	return oldValue.SystemPromptMode, nil
+}
+
+// ResetSystemPromptMode resets all changes to the "system_prompt_mode" field.
+func (m *GroupMutation) ResetSystemPromptMode() {
+	m.system_prompt_mode = nil
+}
+
+// SetPiiRedactionMode sets the "pii_redaction_mode" field.
+func (m *GroupMutation) SetPiiRedactionMode(s string) {
+	m.pii_redaction_mode = &s
+}
+
+// PiiRedactionMode returns the value of the "pii_redaction_mode" field in the mutation.
+func (m *GroupMutation) PiiRedactionMode() (r string, exists bool) {
+	v := m.pii_redaction_mode
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldPiiRedactionMode returns the old "pii_redaction_mode" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldPiiRedactionMode(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldPiiRedactionMode is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldPiiRedactionMode requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldPiiRedactionMode: %w", err)
+	}
+	return oldValue.PiiRedactionMode, nil
+}
+
+// ResetPiiRedactionMode resets all changes to the "pii_redaction_mode" field.
+func (m *GroupMutation) ResetPiiRedactionMode() {
+	m.pii_redaction_mode = nil
+}
+
+// SetMaxBodySize sets the "max_body_size" field.
+func (m *GroupMutation) SetMaxBodySize(i int64) {
+	m.max_body_size = &i
+	m.addmax_body_size = nil
+}
+
+// MaxBodySize returns the value of the "max_body_size" field in the mutation.
+func (m *GroupMutation) MaxBodySize() (r int64, exists bool) {
+	v := m.max_body_size
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMaxBodySize returns the old "max_body_size" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldMaxBodySize(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMaxBodySize is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMaxBodySize requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMaxBodySize: %w", err)
+	}
+	return oldValue.MaxBodySize, nil
+}
+
+// AddMaxBodySize adds i to the "max_body_size" field.
+func (m *GroupMutation) AddMaxBodySize(i int64) {
+	if m.addmax_body_size != nil {
+		*m.addmax_body_size += i
+	} else {
+		m.addmax_body_size = &i
+	}
+}
+
+// AddedMaxBodySize returns the value that was added to the "max_body_size" field in this mutation.
+func (m *GroupMutation) AddedMaxBodySize() (r int64, exists bool) {
+	v := m.addmax_body_size
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMaxBodySize resets all changes to the "max_body_size" field.
+func (m *GroupMutation) ResetMaxBodySize() {
+	m.max_body_size = nil
+	m.addmax_body_size = nil
+}
+
+// SetModerationEnabled sets the "moderation_enabled" field.
+func (m *GroupMutation) SetModerationEnabled(b bool) {
+	m.moderation_enabled = &b
+}
+
+// ModerationEnabled returns the value of the "moderation_enabled" field in the mutation.
+func (m *GroupMutation) ModerationEnabled() (r bool, exists bool) {
+	v := m.moderation_enabled
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldModerationEnabled returns the old "moderation_enabled" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldModerationEnabled(ctx context.Context) (v bool, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldModerationEnabled is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldModerationEnabled requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldModerationEnabled: %w", err)
+	}
+	return oldValue.ModerationEnabled, nil
+}
+
+// ResetModerationEnabled resets all changes to the "moderation_enabled" field.
+func (m *GroupMutation) ResetModerationEnabled() {
+	m.moderation_enabled = nil
+}
+
+// SetResponseHeaders sets the "response_headers" field.
+func (m *GroupMutation) SetResponseHeaders(drh domain.GroupResponseHeaders) {
+	m.response_headers = &drh
+}
+
+// ResponseHeaders returns the value of the "response_headers" field in the mutation.
+func (m *GroupMutation) ResponseHeaders() (r domain.GroupResponseHeaders, exists bool) {
+	v := m.response_headers
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldResponseHeaders returns the old "response_headers" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldResponseHeaders(ctx context.Context) (v domain.GroupResponseHeaders, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldResponseHeaders is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldResponseHeaders requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldResponseHeaders: %w", err)
+	}
+	return oldValue.ResponseHeaders, nil
+}
+
+// ResetResponseHeaders resets all changes to the "response_headers" field.
+func (m *GroupMutation) ResetResponseHeaders() {
+	m.response_headers = nil
+}
+
+// SetOrganizationID sets the "organization_id" field.
+func (m *GroupMutation) SetOrganizationID(i int64) {
+	m.organization_id = &i
+	m.addorganization_id = nil
+}
+
+// OrganizationID returns the value of the "organization_id" field in the mutation.
+func (m *GroupMutation) OrganizationID() (r int64, exists bool) {
+	v := m.organization_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldOrganizationID returns the old "organization_id" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldOrganizationID(ctx context.Context) (v *int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldOrganizationID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldOrganizationID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldOrganizationID: %w", err)
+	}
+	return oldValue.OrganizationID, nil
+}
+
+// AddOrganizationID adds i to the "organization_id" field.
+func (m *GroupMutation) AddOrganizationID(i int64) {
+	if m.addorganization_id != nil {
+		*m.addorganization_id += i
+	} else {
+		m.addorganization_id = &i
+	}
+}
+
+// AddedOrganizationID returns the value that was added to the "organization_id" field in this mutation.
+func (m *GroupMutation) AddedOrganizationID() (r int64, exists bool) {
+	v := m.addorganization_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (m *GroupMutation) ClearOrganizationID() {
+	m.organization_id = nil
+	m.addorganization_id = nil
+	m.clearedFields[group.FieldOrganizationID] = struct{}{}
+}
+
+// OrganizationIDCleared returns if the "organization_id" field was cleared in this mutation.
+func (m *GroupMutation) OrganizationIDCleared() bool {
+	_, ok := m.clearedFields[group.FieldOrganizationID]
+	return ok
+}
+
+// ResetOrganizationID resets all changes to the "organization_id" field.
+func (m *GroupMutation) ResetOrganizationID() {
+	m.organization_id = nil
+	m.addorganization_id = nil
+	delete(m.clearedFields, group.FieldOrganizationID)
+}
+
+// SetAccountTagSelector sets the "account_tag_selector" field.
+func (m *GroupMutation) SetAccountTagSelector(dats domain.AccountTagSelector) {
+	m.account_tag_selector = &dats
+}
+
+// AccountTagSelector returns the value of the "account_tag_selector" field in the mutation.
+func (m *GroupMutation) AccountTagSelector() (r domain.AccountTagSelector, exists bool) {
+	v := m.account_tag_selector
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldAccountTagSelector returns the old "account_tag_selector" field's value of the Group entity.
+// If the Group object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *GroupMutation) OldAccountTagSelector(ctx context.Context) (v domain.AccountTagSelector, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldAccountTagSelector is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldAccountTagSelector requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldAccountTagSelector: %w", err)
+	}
+	return oldValue.AccountTagSelector, nil
+}
+
+// ResetAccountTagSelector resets all changes to the "account_tag_selector" field.
+func (m *GroupMutation) ResetAccountTagSelector() {
+	m.account_tag_selector = nil
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
 func (m *GroupMutation) AddAPIKeyIDs(ids ...int64) {
 	if m.api_keys == nil {
@@ -16791,7 +18548,7 @@ func (m *GroupMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *GroupMutation) Fields() []string {
-	fields := make([]string, 0, 31)
+	fields := make([]string, 0, 41)
 	if m.created_at != nil {
 		fields = append(fields, group.FieldCreatedAt)
 	}
@@ -16885,6 +18642,36 @@ func (m *GroupMutation) Fields() []string {
 	if m.rpm_limit != nil {
 		fields = append(fields, group.FieldRpmLimit)
 	}
+	if m.priority != nil {
+		fields = append(fields, group.FieldPriority)
+	}
+	if m.request_param_overrides != nil {
+		fields = append(fields, group.FieldRequestParamOverrides)
+	}
+	if m.system_prompt != nil {
+		fields = append(fields, group.FieldSystemPrompt)
+	}
+	if m.system_prompt_mode != nil {
+		fields = append(fields, group.FieldSystemPromptMode)
+	}
+	if m.pii_redaction_mode != nil {
+		fields = append(fields, group.FieldPiiRedactionMode)
+	}
+	if m.max_body_size != nil {
+		fields = append(fields, group.FieldMaxBodySize)
+	}
+	if m.moderation_enabled != nil {
+		fields = append(fields, group.FieldModerationEnabled)
+	}
+	if m.response_headers != nil {
+		fields = append(fields, group.FieldResponseHeaders)
+	}
+	if m.organization_id != nil {
+		fields = append(fields, group.FieldOrganizationID)
+	}
+	if m.account_tag_selector != nil {
+		fields = append(fields, group.FieldAccountTagSelector)
+	}
 	return fields
 }
 
@@ -16955,6 +18742,26 @@ func (m *GroupMutation) Field(name string) (ent.Value, bool) {
 		return m.MessagesDispatchModelConfig()
 	case group.FieldRpmLimit:
 		return m.RpmLimit()
+	case group.FieldPriority:
+		return m.Priority()
+	case group.FieldRequestParamOverrides:
+		return m.RequestParamOverrides()
+	case group.FieldSystemPrompt:
+		return m.SystemPrompt()
+	case group.FieldSystemPromptMode:
+		return m.SystemPromptMode()
+	case group.FieldPiiRedactionMode:
+		return m.PiiRedactionMode()
+	case group.FieldMaxBodySize:
+		return m.MaxBodySize()
+	case group.FieldModerationEnabled:
+		return m.ModerationEnabled()
+	case group.FieldResponseHeaders:
+		return m.ResponseHeaders()
+	case group.FieldOrganizationID:
+		return m.OrganizationID()
+	case group.FieldAccountTagSelector:
+		return m.AccountTagSelector()
 	}
 	return nil, false
 }
@@ -17026,6 +18833,26 @@ func (m *GroupMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldMessagesDispatchModelConfig(ctx)
 	case group.FieldRpmLimit:
 		return m.OldRpmLimit(ctx)
+	case group.FieldPriority:
+		return m.OldPriority(ctx)
+	case group.FieldRequestParamOverrides:
+		return m.OldRequestParamOverrides(ctx)
+	case group.FieldSystemPrompt:
+		return m.OldSystemPrompt(ctx)
+	case group.FieldSystemPromptMode:
+		return m.OldSystemPromptMode(ctx)
+	case group.FieldPiiRedactionMode:
+		return m.OldPiiRedactionMode(ctx)
+	case group.FieldMaxBodySize:
+		return m.OldMaxBodySize(ctx)
+	case group.FieldModerationEnabled:
+		return m.OldModerationEnabled(ctx)
+	case group.FieldResponseHeaders:
+		return m.OldResponseHeaders(ctx)
+	case group.FieldOrganizationID:
+		return m.OldOrganizationID(ctx)
+	case group.FieldAccountTagSelector:
+		return m.OldAccountTagSelector(ctx)
 	}
 	return nil, fmt.Errorf("unknown Group field %s", name)
 }
@@ -17252,6 +19079,76 @@ func (m *GroupMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetRpmLimit(v)
 		return nil
+	case group.FieldPriority:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPriority(v)
+		return nil
+	case group.FieldRequestParamOverrides:
+		v, ok := value.(domain.RequestParamOverrides)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRequestParamOverrides(v)
+		return nil
+	case group.FieldSystemPrompt:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetSystemPrompt(v)
+		return nil
+	case group.FieldSystemPromptMode:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetSystemPromptMode(v)
+		return nil
+	case group.FieldPiiRedactionMode:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetPiiRedactionMode(v)
+		return nil
+	case group.FieldMaxBodySize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMaxBodySize(v)
+		return nil
+	case group.FieldModerationEnabled:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetModerationEnabled(v)
+		return nil
+	case group.FieldResponseHeaders:
+		v, ok := value.(domain.GroupResponseHeaders)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetResponseHeaders(v)
+		return nil
+	case group.FieldOrganizationID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetOrganizationID(v)
+		return nil
+	case group.FieldAccountTagSelector:
+		v, ok := value.(domain.AccountTagSelector)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetAccountTagSelector(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -17296,6 +19193,12 @@ func (m *GroupMutation) AddedFields() []string {
 	if m.addrpm_limit != nil {
 		fields = append(fields, group.FieldRpmLimit)
 	}
+	if m.addmax_body_size != nil {
+		fields = append(fields, group.FieldMaxBodySize)
+	}
+	if m.addorganization_id != nil {
+		fields = append(fields, group.FieldOrganizationID)
+	}
 	return fields
 }
 
@@ -17328,6 +19231,10 @@ func (m *GroupMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedSortOrder()
 	case group.FieldRpmLimit:
 		return m.AddedRpmLimit()
+	case group.FieldMaxBodySize:
+		return m.AddedMaxBodySize()
+	case group.FieldOrganizationID:
+		return m.AddedOrganizationID()
 	}
 	return nil, false
 }
@@ -17421,6 +19328,20 @@ func (m *GroupMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddRpmLimit(v)
 		return nil
+	case group.FieldMaxBodySize:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMaxBodySize(v)
+		return nil
+	case group.FieldOrganizationID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddOrganizationID(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Group numeric field %s", name)
 }
@@ -17462,6 +19383,9 @@ func (m *GroupMutation) ClearedFields() []string {
 	if m.FieldCleared(group.FieldModelRouting) {
 		fields = append(fields, group.FieldModelRouting)
 	}
+	if m.FieldCleared(group.FieldOrganizationID) {
+		fields = append(fields, group.FieldOrganizationID)
+	}
 	return fields
 }
 
@@ -17509,6 +19433,9 @@ func (m *GroupMutation) ClearField(name string) error {
 	case group.FieldModelRouting:
 		m.ClearModelRouting()
 		return nil
+	case group.FieldOrganizationID:
+		m.ClearOrganizationID()
+		return nil
 	}
 	return fmt.Errorf("unknown Group nullable field %s", name)
 }
@@ -17610,6 +19537,36 @@ func (m *GroupMutation) ResetField(name string) error {
 	case group.FieldRpmLimit:
 		m.ResetRpmLimit()
 		return nil
+	case group.FieldPriority:
+		m.ResetPriority()
+		return nil
+	case group.FieldRequestParamOverrides:
+		m.ResetRequestParamOverrides()
+		return nil
+	case group.FieldSystemPrompt:
+		m.ResetSystemPrompt()
+		return nil
+	case group.FieldSystemPromptMode:
+		m.ResetSystemPromptMode()
+		return nil
+	case group.FieldPiiRedactionMode:
+		m.ResetPiiRedactionMode()
+		return nil
+	case group.FieldMaxBodySize:
+		m.ResetMaxBodySize()
+		return nil
+	case group.FieldModerationEnabled:
+		m.ResetModerationEnabled()
+		return nil
+	case group.FieldResponseHeaders:
+		m.ResetResponseHeaders()
+		return nil
+	case group.FieldOrganizationID:
+		m.ResetOrganizationID()
+		return nil
+	case group.FieldAccountTagSelector:
+		m.ResetAccountTagSelector()
+		return nil
 	}
 	return fmt.Errorf("unknown Group field %s", name)
 }
@@ -19585,6 +21542,714 @@ func (m *IdentityAdoptionDecisionMutation) ResetEdge(name string) error {
 	return fmt.Errorf("unknown IdentityAdoptionDecision edge %s", name)
 }
 
+// OrganizationMutation represents an operation that mutates the Organization nodes in the graph.
+type OrganizationMutation struct {
+	config
+	op                    Op
+	typ                   string
+	id                    *int64
+	created_at            *time.Time
+	updated_at            *time.Time
+	deleted_at            *time.Time
+	name                  *string
+	description           *string
+	status                *string
+	monthly_budget_usd    *float64
+	addmonthly_budget_usd *float64
+	clearedFields         map[string]struct{}
+	done                  bool
+	oldValue              func(context.Context) (*Organization, error)
+	predicates            []predicate.Organization
+}
+
+var _ ent.Mutation = (*OrganizationMutation)(nil)
+
+// organizationOption allows management of the mutation configuration using functional options.
+type organizationOption func(*OrganizationMutation)
+
+// newOrganizationMutation creates new mutation for the Organization entity.
+func newOrganizationMutation(c config, op Op, opts ...organizationOption) *OrganizationMutation {
+	m := &OrganizationMutation{
+		config:        c,
+		op:            op,
+		typ:           TypeOrganization,
+		clearedFields: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// withOrganizationID sets the ID field of the mutation.
+func withOrganizationID(id int64) organizationOption {
+	return func(m *OrganizationMutation) {
+		var (
+			err   error
+			once  sync.Once
+			value *Organization
+		)
+		m.oldValue = func(ctx context.Context) (*Organization, error) {
+			once.Do(func() {
+				if m.done {
+					err = errors.New("querying old values post mutation is not allowed")
+				} else {
+					value, err = m.Client().Organization.Get(ctx, id)
+				}
+			})
+			return value, err
+		}
+		m.id = &id
+	}
+}
+
+// withOrganization sets the old Organization of the mutation.
+func withOrganization(node *Organization) organizationOption {
+	return func(m *OrganizationMutation) {
+		m.oldValue = func(context.Context) (*Organization, error) {
+			return node, nil
+		}
+		m.id = &node.ID
+	}
+}
+
+// Client returns a new `ent.Client` from the mutation. If the mutation was
+// executed in a transaction (ent.Tx), a transactional client is returned.
+func (m OrganizationMutation) Client() *Client {
+	client := &Client{config: m.config}
+	client.init()
+	return client
+}
+
+// Tx returns an `ent.Tx` for mutations that were executed in transactions;
+// it returns an error otherwise.
+func (m OrganizationMutation) Tx() (*Tx, error) {
+	if _, ok := m.driver.(*txDriver); !ok {
+		return nil, errors.New("ent: mutation is not running in a transaction")
+	}
+	tx := &Tx{config: m.config}
+	tx.init()
+	return tx, nil
+}
+
+// ID returns the ID value in the mutation. Note that the ID is only available
+// if it was provided to the builder or after it was returned from the database.
+func (m *OrganizationMutation) ID() (id int64, exists bool) {
+	if m.id == nil {
+		return
+	}
+	return *m.id, true
+}
+
+// IDs queries the database and returns the entity ids that match the mutation's predicate.
+// That means, if the mutation is applied within a transaction with an isolation level such
+// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
+// or updated by the mutation.
+func (m *OrganizationMutation) IDs(ctx context.Context) ([]int64, error) {
+	switch {
+	case m.op.Is(OpUpdateOne | OpDeleteOne):
+		id, exists := m.ID()
+		if exists {
+			return []int64{id}, nil
+		}
+		fallthrough
+	case m.op.Is(OpUpdate | OpDelete):
+		return m.Client().Organization.Query().Where(m.predicates...).IDs(ctx)
+	default:
+		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+	}
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (m *OrganizationMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *OrganizationMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreatedAt returns the old "created_at" field's value of the Organization entity.
+// If the Organization object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OrganizationMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
+	}
+	return oldValue.CreatedAt, nil
+}
+
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *OrganizationMutation) ResetCreatedAt() {
+	m.created_at = nil
+}
+
+// SetUpdatedAt sets the "updated_at" field.
+func (m *OrganizationMutation) SetUpdatedAt(t time.Time) {
+	m.updated_at = &t
+}
+
+// UpdatedAt returns the value of the "updated_at" field in the mutation.
+func (m *OrganizationMutation) UpdatedAt() (r time.Time, exists bool) {
+	v := m.updated_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldUpdatedAt returns the old "updated_at" field's value of the Organization entity.
+// If the Organization object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OrganizationMutation) OldUpdatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldUpdatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldUpdatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldUpdatedAt: %w", err)
+	}
+	return oldValue.UpdatedAt, nil
+}
+
+// ResetUpdatedAt resets all changes to the "updated_at" field.
+func (m *OrganizationMutation) ResetUpdatedAt() {
+	m.updated_at = nil
+}
+
+// SetDeletedAt sets the "deleted_at" field.
+func (m *OrganizationMutation) SetDeletedAt(t time.Time) {
+	m.deleted_at = &t
+}
+
+// DeletedAt returns the value of the "deleted_at" field in the mutation.
+func (m *OrganizationMutation) DeletedAt() (r time.Time, exists bool) {
+	v := m.deleted_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldDeletedAt returns the old "deleted_at" field's value of the Organization entity.
+// If the Organization object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OrganizationMutation) OldDeletedAt(ctx context.Context) (v *time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldDeletedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldDeletedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDeletedAt: %w", err)
+	}
+	return oldValue.DeletedAt, nil
+}
+
+// ClearDeletedAt clears the value of the "deleted_at" field.
+func (m *OrganizationMutation) ClearDeletedAt() {
+	m.deleted_at = nil
+	m.clearedFields[organization.FieldDeletedAt] = struct{}{}
+}
+
+// DeletedAtCleared returns if the "deleted_at" field was cleared in this mutation.
+func (m *OrganizationMutation) DeletedAtCleared() bool {
+	_, ok := m.clearedFields[organization.FieldDeletedAt]
+	return ok
+}
+
+// ResetDeletedAt resets all changes to the "deleted_at" field.
+func (m *OrganizationMutation) ResetDeletedAt() {
+	m.deleted_at = nil
+	delete(m.clearedFields, organization.FieldDeletedAt)
+}
+
+// SetName sets the "name" field.
+func (m *OrganizationMutation) SetName(s string) {
+	m.name = &s
+}
+
+// Name returns the value of the "name" field in the mutation.
+func (m *OrganizationMutation) Name() (r string, exists bool) {
+	v := m.name
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldName returns the old "name" field's value of the Organization entity.
+// If the Organization object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OrganizationMutation) OldName(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldName is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldName requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldName: %w", err)
+	}
+	return oldValue.Name, nil
+}
+
+// ResetName resets all changes to the "name" field.
+func (m *OrganizationMutation) ResetName() {
+	m.name = nil
+}
+
+// SetDescription sets the "description" field.
+func (m *OrganizationMutation) SetDescription(s string) {
+	m.description = &s
+}
+
+// Description returns the value of the "description" field in the mutation.
+func (m *OrganizationMutation) Description() (r string, exists bool) {
+	v := m.description
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldDescription returns the old "description" field's value of the Organization entity.
+// If the Organization object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OrganizationMutation) OldDescription(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldDescription is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldDescription requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldDescription: %w", err)
+	}
+	return oldValue.Description, nil
+}
+
+// ResetDescription resets all changes to the "description" field.
+func (m *OrganizationMutation) ResetDescription() {
+	m.description = nil
+}
+
+// SetStatus sets the "status" field.
+func (m *OrganizationMutation) SetStatus(s string) {
+	m.status = &s
+}
+
+// Status returns the value of the "status" field in the mutation.
+func (m *OrganizationMutation) Status() (r string, exists bool) {
+	v := m.status
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldStatus returns the old "status" field's value of the Organization entity.
+// If the Organization object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OrganizationMutation) OldStatus(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldStatus is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldStatus requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldStatus: %w", err)
+	}
+	return oldValue.Status, nil
+}
+
+// ResetStatus resets all changes to the "status" field.
+func (m *OrganizationMutation) ResetStatus() {
+	m.status = nil
+}
+
+// SetMonthlyBudgetUsd sets the "monthly_budget_usd" field.
+func (m *OrganizationMutation) SetMonthlyBudgetUsd(f float64) {
+	m.monthly_budget_usd = &f
+	m.addmonthly_budget_usd = nil
+}
+
+// MonthlyBudgetUsd returns the value of the "monthly_budget_usd" field in the mutation.
+func (m *OrganizationMutation) MonthlyBudgetUsd() (r float64, exists bool) {
+	v := m.monthly_budget_usd
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMonthlyBudgetUsd returns the old "monthly_budget_usd" field's value of the Organization entity.
+// If the Organization object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *OrganizationMutation) OldMonthlyBudgetUsd(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMonthlyBudgetUsd is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMonthlyBudgetUsd requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMonthlyBudgetUsd: %w", err)
+	}
+	return oldValue.MonthlyBudgetUsd, nil
+}
+
+// AddMonthlyBudgetUsd adds f to the "monthly_budget_usd" field.
+func (m *OrganizationMutation) AddMonthlyBudgetUsd(f float64) {
+	if m.addmonthly_budget_usd != nil {
+		*m.addmonthly_budget_usd += f
+	} else {
+		m.addmonthly_budget_usd = &f
+	}
+}
+
+// AddedMonthlyBudgetUsd returns the value that was added to the "monthly_budget_usd" field in this mutation.
+func (m *OrganizationMutation) AddedMonthlyBudgetUsd() (r float64, exists bool) {
+	v := m.addmonthly_budget_usd
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMonthlyBudgetUsd resets all changes to the "monthly_budget_usd" field.
+func (m *OrganizationMutation) ResetMonthlyBudgetUsd() {
+	m.monthly_budget_usd = nil
+	m.addmonthly_budget_usd = nil
+}
+
+// Where appends a list predicates to the OrganizationMutation builder.
+func (m *OrganizationMutation) Where(ps ...predicate.Organization) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the OrganizationMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *OrganizationMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.Organization, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *OrganizationMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *OrganizationMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (Organization).
+func (m *OrganizationMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *OrganizationMutation) Fields() []string {
+	fields := make([]string, 0, 7)
+	if m.created_at != nil {
+		fields = append(fields, organization.FieldCreatedAt)
+	}
+	if m.updated_at != nil {
+		fields = append(fields, organization.FieldUpdatedAt)
+	}
+	if m.deleted_at != nil {
+		fields = append(fields, organization.FieldDeletedAt)
+	}
+	if m.name != nil {
+		fields = append(fields, organization.FieldName)
+	}
+	if m.description != nil {
+		fields = append(fields, organization.FieldDescription)
+	}
+	if m.status != nil {
+		fields = append(fields, organization.FieldStatus)
+	}
+	if m.monthly_budget_usd != nil {
+		fields = append(fields, organization.FieldMonthlyBudgetUsd)
+	}
+	return fields
+}
+
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *OrganizationMutation) Field(name string) (ent.Value, bool) {
+	switch name {
+	case organization.FieldCreatedAt:
+		return m.CreatedAt()
+	case organization.FieldUpdatedAt:
+		return m.UpdatedAt()
+	case organization.FieldDeletedAt:
+		return m.DeletedAt()
+	case organization.FieldName:
+		return m.Name()
+	case organization.FieldDescription:
+		return m.Description()
+	case organization.FieldStatus:
+		return m.Status()
+	case organization.FieldMonthlyBudgetUsd:
+		return m.MonthlyBudgetUsd()
+	}
+	return nil, false
+}
+
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
+// database failed.
+func (m *OrganizationMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+	switch name {
+	case organization.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	case organization.FieldUpdatedAt:
+		return m.OldUpdatedAt(ctx)
+	case organization.FieldDeletedAt:
+		return m.OldDeletedAt(ctx)
+	case organization.FieldName:
+		return m.OldName(ctx)
+	case organization.FieldDescription:
+		return m.OldDescription(ctx)
+	case organization.FieldStatus:
+		return m.OldStatus(ctx)
+	case organization.FieldMonthlyBudgetUsd:
+		return m.OldMonthlyBudgetUsd(ctx)
+	}
+	return nil, fmt.Errorf("unknown Organization field %s", name)
+}
+
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *OrganizationMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case organization.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	case organization.FieldUpdatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetUpdatedAt(v)
+		return nil
+	case organization.FieldDeletedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetDeletedAt(v)
+		return nil
+	case organization.FieldName:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetName(v)
+		return nil
+	case organization.FieldDescription:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetDescription(v)
+		return nil
+	case organization.FieldStatus:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetStatus(v)
+		return nil
+	case organization.FieldMonthlyBudgetUsd:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMonthlyBudgetUsd(v)
+		return nil
+	}
+	return fmt.Errorf("unknown Organization field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *OrganizationMutation) AddedFields() []string {
+	var fields []string
+	if m.addmonthly_budget_usd != nil {
+		fields = append(fields, organization.FieldMonthlyBudgetUsd)
+	}
+	return fields
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *OrganizationMutation) AddedField(name string) (ent.Value, bool) {
+	switch name {
+	case organization.FieldMonthlyBudgetUsd:
+		return m.AddedMonthlyBudgetUsd()
+	}
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *OrganizationMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	case organization.FieldMonthlyBudgetUsd:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMonthlyBudgetUsd(v)
+		return nil
+	}
+	return fmt.Errorf("unknown Organization numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *OrganizationMutation) ClearedFields() []string {
+	var fields []string
+	if m.FieldCleared(organization.FieldDeletedAt) {
+		fields = append(fields, organization.FieldDeletedAt)
+	}
+	return fields
+}
+
+// FieldCleared returns a boolean indicating if a field with the given name was
+// cleared in this mutation.
+func (m *OrganizationMutation) FieldCleared(name string) bool {
+	_, ok := m.clearedFields[name]
+	return ok
+}
+
+// ClearField clears the value of the field with the given name. It returns an
+// error if the field is not defined in the schema.
+func (m *OrganizationMutation) ClearField(name string) error {
+	switch name {
+	case organization.FieldDeletedAt:
+		m.ClearDeletedAt()
+		return nil
+	}
+	return fmt.Errorf("unknown Organization nullable field %s", name)
+}
+
+// ResetField resets all changes in the mutation for the field with the given name.
+// It returns an error if the field is not defined in the schema.
+func (m *OrganizationMutation) ResetField(name string) error {
+	switch name {
+	case organization.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
+	case organization.FieldUpdatedAt:
+		m.ResetUpdatedAt()
+		return nil
+	case organization.FieldDeletedAt:
+		m.ResetDeletedAt()
+		return nil
+	case organization.FieldName:
+		m.ResetName()
+		return nil
+	case organization.FieldDescription:
+		m.ResetDescription()
+		return nil
+	case organization.FieldStatus:
+		m.ResetStatus()
+		return nil
+	case organization.FieldMonthlyBudgetUsd:
+		m.ResetMonthlyBudgetUsd()
+		return nil
+	}
+	return fmt.Errorf("unknown Organization field %s", name)
+}
+
+// AddedEdges returns all edge names that were set/added in this mutation.
+func (m *OrganizationMutation) AddedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// AddedIDs returns all IDs (to other nodes) that were added for the given edge
+// name in this mutation.
+func (m *OrganizationMutation) AddedIDs(name string) []ent.Value {
+	return nil
+}
+
+// RemovedEdges returns all edge names that were removed in this mutation.
+func (m *OrganizationMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
+// the given name in this mutation.
+func (m *OrganizationMutation) RemovedIDs(name string) []ent.Value {
+	return nil
+}
+
+// ClearedEdges returns all edge names that were cleared in this mutation.
+func (m *OrganizationMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 0)
+	return edges
+}
+
+// EdgeCleared returns a boolean which indicates if the edge with the given name
+// was cleared in this mutation.
+func (m *OrganizationMutation) EdgeCleared(name string) bool {
+	return false
+}
+
+// ClearEdge clears the value of the edge with the given name. It returns an error
+// if that edge is not defined in the schema.
+func (m *OrganizationMutation) ClearEdge(name string) error {
+	return fmt.Errorf("unknown Organization unique edge %s", name)
+}
+
+// ResetEdge resets all changes to the edge with the given name in this mutation.
+// It returns an error if the edge is not defined in the schema.
+func (m *OrganizationMutation) ResetEdge(name string) error {
+	return fmt.Errorf("unknown Organization edge %s", name)
+}
+
 // PaymentAuditLogMutation represents an operation that mutates the PaymentAuditLog nodes in the graph.
 type PaymentAuditLogMutation struct {
 	config
@@ -27395,27 +30060,29 @@ func (m *PromoCodeUsageMutation) ResetEdge(name string) error {
 // ProxyMutation represents an operation that mutates the Proxy nodes in the graph.
 type ProxyMutation struct {
 	config
-	op              Op
-	typ             string
-	id              *int64
-	created_at      *time.Time
-	updated_at      *time.Time
-	deleted_at      *time.Time
-	name            *string
-	protocol        *string
-	host            *string
-	port            *int
-	addport         *int
-	username        *string
-	password        *string
-	status          *string
-	clearedFields   map[string]struct{}
-	accounts        map[int64]struct{}
-	removedaccounts map[int64]struct{}
-	clearedaccounts bool
-	done            bool
-	oldValue        func(context.Context) (*Proxy, error)
-	predicates      []predicate.Proxy
+	op                 Op
+	typ                string
+	id                 *int64
+	created_at         *time.Time
+	updated_at         *time.Time
+	deleted_at         *time.Time
+	name               *string
+	protocol           *string
+	host               *string
+	port               *int
+	addport            *int
+	username           *string
+	password           *string
+	status             *string
+	max_concurrency    *int
+	addmax_concurrency *int
+	clearedFields      map[string]struct{}
+	accounts           map[int64]struct{}
+	removedaccounts    map[int64]struct{}
+	clearedaccounts    bool
+	done               bool
+	oldValue           func(context.Context) (*Proxy, error)
+	predicates         []predicate.Proxy
 }
 
 var _ ent.Mutation = (*ProxyMutation)(nil)
@@ -27935,6 +30602,62 @@ func (m *ProxyMutation) ResetStatus() {
 	m.status = nil
 }
 
+// SetMaxConcurrency sets the "max_concurrency" field.
+func (m *ProxyMutation) SetMaxConcurrency(i int) {
+	m.max_concurrency = &i
+	m.addmax_concurrency = nil
+}
+
+// MaxConcurrency returns the value of the "max_concurrency" field in the mutation.
+func (m *ProxyMutation) MaxConcurrency() (r int, exists bool) {
+	v := m.max_concurrency
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMaxConcurrency returns the old "max_concurrency" field's value of the Proxy entity.
+// If the Proxy object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ProxyMutation) OldMaxConcurrency(ctx context.Context) (v int, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMaxConcurrency is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMaxConcurrency requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMaxConcurrency: %w", err)
+	}
+	return oldValue.MaxConcurrency, nil
+}
+
+// AddMaxConcurrency adds i to the "max_concurrency" field.
+func (m *ProxyMutation) AddMaxConcurrency(i int) {
+	if m.addmax_concurrency != nil {
+		*m.addmax_concurrency += i
+	} else {
+		m.addmax_concurrency = &i
+	}
+}
+
+// AddedMaxConcurrency returns the value that was added to the "max_concurrency" field in this mutation.
+func (m *ProxyMutation) AddedMaxConcurrency() (r int, exists bool) {
+	v := m.addmax_concurrency
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMaxConcurrency resets all changes to the "max_concurrency" field.
+func (m *ProxyMutation) ResetMaxConcurrency() {
+	m.max_concurrency = nil
+	m.addmax_concurrency = nil
+}
+
 // AddAccountIDs adds the "accounts" edge to the Account entity by ids.
 func (m *ProxyMutation) AddAccountIDs(ids ...int64) {
 	if m.accounts == nil {
@@ -28023,7 +30746,7 @@ func (m *ProxyMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *ProxyMutation) Fields() []string {
-	fields := make([]string, 0, 10)
+	fields := make([]string, 0, 11)
 	if m.created_at != nil {
 		fields = append(fields, proxy.FieldCreatedAt)
 	}
@@ -28054,6 +30777,9 @@ func (m *ProxyMutation) Fields() []string {
 	if m.status != nil {
 		fields = append(fields, proxy.FieldStatus)
 	}
+	if m.max_concurrency != nil {
+		fields = append(fields, proxy.FieldMaxConcurrency)
+	}
 	return fields
 }
 
@@ -28082,6 +30808,8 @@ func (m *ProxyMutation) Field(name string) (ent.Value, bool) {
 		return m.Password()
 	case proxy.FieldStatus:
 		return m.Status()
+	case proxy.FieldMaxConcurrency:
+		return m.MaxConcurrency()
 	}
 	return nil, false
 }
@@ -28111,6 +30839,8 @@ func (m *ProxyMutation) OldField(ctx context.Context, name string) (ent.Value, e
 		return m.OldPassword(ctx)
 	case proxy.FieldStatus:
 		return m.OldStatus(ctx)
+	case proxy.FieldMaxConcurrency:
+		return m.OldMaxConcurrency(ctx)
 	}
 	return nil, fmt.Errorf("unknown Proxy field %s", name)
 }
@@ -28190,6 +30920,13 @@ func (m *ProxyMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetStatus(v)
 		return nil
+	case proxy.FieldMaxConcurrency:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMaxConcurrency(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Proxy field %s", name)
 }
@@ -28201,6 +30938,9 @@ func (m *ProxyMutation) AddedFields() []string {
 	if m.addport != nil {
 		fields = append(fields, proxy.FieldPort)
 	}
+	if m.addmax_concurrency != nil {
+		fields = append(fields, proxy.FieldMaxConcurrency)
+	}
 	return fields
 }
 
@@ -28211,6 +30951,8 @@ func (m *ProxyMutation) AddedField(name string) (ent.Value, bool) {
 	switch name {
 	case proxy.FieldPort:
 		return m.AddedPort()
+	case proxy.FieldMaxConcurrency:
+		return m.AddedMaxConcurrency()
 	}
 	return nil, false
 }
@@ -28227,6 +30969,13 @@ func (m *ProxyMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddPort(v)
 		return nil
+	case proxy.FieldMaxConcurrency:
+		v, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMaxConcurrency(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Proxy numeric field %s", name)
 }
@@ -28305,6 +31054,9 @@ func (m *ProxyMutation) ResetField(name string) error {
 	case proxy.FieldStatus:
 		m.ResetStatus()
 		return nil
+	case proxy.FieldMaxConcurrency:
+		m.ResetMaxConcurrency()
+		return nil
 	}
 	return fmt.Errorf("unknown Proxy field %s", name)
 }
@@ -37455,6 +40207,9 @@ type UserMutation struct {
 	addtotal_recharged            *float64
 	rpm_limit                     *int
 	addrpm_limit                  *int
+	organization_id               *int64
+	addorganization_id            *int64
+	org_role                      *string
 	clearedFields                 map[string]struct{}
 	api_keys                      map[int64]struct{}
 	removedapi_keys               map[int64]struct{}
@@ -38602,6 +41357,112 @@ func (m *UserMutation) ResetRpmLimit() {
 	m.addrpm_limit = nil
 }
 
+// SetOrganizationID sets the "organization_id" field.
+func (m *UserMutation) SetOrganizationID(i int64) {
+	m.organization_id = &i
+	m.addorganization_id = nil
+}
+
+// OrganizationID returns the value of the "organization_id" field in the mutation.
+func (m *UserMutation) OrganizationID() (r int64, exists bool) {
+	v := m.organization_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldOrganizationID returns the old "organization_id" field's value of the User entity.
+// If the User object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UserMutation) OldOrganizationID(ctx context.Context) (v *int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldOrganizationID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldOrganizationID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldOrganizationID: %w", err)
+	}
+	return oldValue.OrganizationID, nil
+}
+
+// AddOrganizationID adds i to the "organization_id" field.
+func (m *UserMutation) AddOrganizationID(i int64) {
+	if m.addorganization_id != nil {
+		*m.addorganization_id += i
+	} else {
+		m.addorganization_id = &i
+	}
+}
+
+// AddedOrganizationID returns the value that was added to the "organization_id" field in this mutation.
+func (m *UserMutation) AddedOrganizationID() (r int64, exists bool) {
+	v := m.addorganization_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (m *UserMutation) ClearOrganizationID() {
+	m.organization_id = nil
+	m.addorganization_id = nil
+	m.clearedFields[user.FieldOrganizationID] = struct{}{}
+}
+
+// OrganizationIDCleared returns if the "organization_id" field was cleared in this mutation.
+func (m *UserMutation) OrganizationIDCleared() bool {
+	_, ok := m.clearedFields[user.FieldOrganizationID]
+	return ok
+}
+
+// ResetOrganizationID resets all changes to the "organization_id" field.
+func (m *UserMutation) ResetOrganizationID() {
+	m.organization_id = nil
+	m.addorganization_id = nil
+	delete(m.clearedFields, user.FieldOrganizationID)
+}
+
+// SetOrgRole sets the "org_role" field.
+func (m *UserMutation) SetOrgRole(s string) {
+	m.org_role = &s
+}
+
+// OrgRole returns the value of the "org_role" field in the mutation.
+func (m *UserMutation) OrgRole() (r string, exists bool) {
+	v := m.org_role
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldOrgRole returns the old "org_role" field's value of the User entity.
+// If the User object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *UserMutation) OldOrgRole(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldOrgRole is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldOrgRole requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldOrgRole: %w", err)
+	}
+	return oldValue.OrgRole, nil
+}
+
+// ResetOrgRole resets all changes to the "org_role" field.
+func (m *UserMutation) ResetOrgRole() {
+	m.org_role = nil
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by ids.
 func (m *UserMutation) AddAPIKeyIDs(ids ...int64) {
 	if m.api_keys == nil {
@@ -39284,7 +42145,7 @@ func (m *UserMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *UserMutation) Fields() []string {
-	fields := make([]string, 0, 23)
+	fields := make([]string, 0, 25)
 	if m.created_at != nil {
 		fields = append(fields, user.FieldCreatedAt)
 	}
@@ -39354,6 +42215,12 @@ func (m *UserMutation) Fields() []string {
 	if m.rpm_limit != nil {
 		fields = append(fields, user.FieldRpmLimit)
 	}
+	if m.organization_id != nil {
+		fields = append(fields, user.FieldOrganizationID)
+	}
+	if m.org_role != nil {
+		fields = append(fields, user.FieldOrgRole)
+	}
 	return fields
 }
 
@@ -39408,6 +42275,10 @@ func (m *UserMutation) Field(name string) (ent.Value, bool) {
 		return m.TotalRecharged()
 	case user.FieldRpmLimit:
 		return m.RpmLimit()
+	case user.FieldOrganizationID:
+		return m.OrganizationID()
+	case user.FieldOrgRole:
+		return m.OrgRole()
 	}
 	return nil, false
 }
@@ -39463,6 +42334,10 @@ func (m *UserMutation) OldField(ctx context.Context, name string) (ent.Value, er
 		return m.OldTotalRecharged(ctx)
 	case user.FieldRpmLimit:
 		return m.OldRpmLimit(ctx)
+	case user.FieldOrganizationID:
+		return m.OldOrganizationID(ctx)
+	case user.FieldOrgRole:
+		return m.OldOrgRole(ctx)
 	}
 	return nil, fmt.Errorf("unknown User field %s", name)
 }
@@ -39633,6 +42508,20 @@ func (m *UserMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetRpmLimit(v)
 		return nil
+	case user.FieldOrganizationID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetOrganizationID(v)
+		return nil
+	case user.FieldOrgRole:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetOrgRole(v)
+		return nil
 	}
 	return fmt.Errorf("unknown User field %s", name)
 }
@@ -39656,6 +42545,9 @@ func (m *UserMutation) AddedFields() []string {
 	if m.addrpm_limit != nil {
 		fields = append(fields, user.FieldRpmLimit)
 	}
+	if m.addorganization_id != nil {
+		fields = append(fields, user.FieldOrganizationID)
+	}
 	return fields
 }
 
@@ -39674,6 +42566,8 @@ func (m *UserMutation) AddedField(name string) (ent.Value, bool) {
 		return m.AddedTotalRecharged()
 	case user.FieldRpmLimit:
 		return m.AddedRpmLimit()
+	case user.FieldOrganizationID:
+		return m.AddedOrganizationID()
 	}
 	return nil, false
 }
@@ -39718,6 +42612,13 @@ func (m *UserMutation) AddField(name string, value ent.Value) error {
 		}
 		m.AddRpmLimit(v)
 		return nil
+	case user.FieldOrganizationID:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddOrganizationID(v)
+		return nil
 	}
 	return fmt.Errorf("unknown User numeric field %s", name)
 }
@@ -39744,6 +42645,9 @@ func (m *UserMutation) ClearedFields() []string {
 	if m.FieldCleared(user.FieldBalanceNotifyThreshold) {
 		fields = append(fields, user.FieldBalanceNotifyThreshold)
 	}
+	if m.FieldCleared(user.FieldOrganizationID) {
+		fields = append(fields, user.FieldOrganizationID)
+	}
 	return fields
 }
 
@@ -39776,6 +42680,9 @@ func (m *UserMutation) ClearField(name string) error {
 	case user.FieldBalanceNotifyThreshold:
 		m.ClearBalanceNotifyThreshold()
 		return nil
+	case user.FieldOrganizationID:
+		m.ClearOrganizationID()
+		return nil
 	}
 	return fmt.Errorf("unknown User nullable field %s", name)
 }
@@ -39853,6 +42760,12 @@ func (m *UserMutation) ResetField(name string) error {
 	case user.FieldRpmLimit:
 		m.ResetRpmLimit()
 		return nil
+	case user.FieldOrganizationID:
+		m.ResetOrganizationID()
+		return nil
+	case user.FieldOrgRole:
+		m.ResetOrgRole()
+		return nil
 	}
 	return fmt.Errorf("unknown User field %s", name)
 }
@@ -131,6 +131,20 @@ func (_c *ProxyCreate) SetNillableStatus(v *string) *ProxyCreate {
 	return _c
 }
 
+// SetMaxConcurrency sets the "max_concurrency" field.
+func (_c *ProxyCreate) SetMaxConcurrency(v int) *ProxyCreate {
+	_c.mutation.SetMaxConcurrency(v)
+	return _c
+}
+
+// SetNillableMaxConcurrency sets the "max_concurrency" field if the given value is not nil.
+func (_c *ProxyCreate) SetNillableMaxConcurrency(v *int) *ProxyCreate {
+	if v != nil {
+		_c.SetMaxConcurrency(*v)
+	}
+	return _c
+}
+
 // AddAccountIDs adds the "accounts" edge to the Account entity by IDs.
 func (_c *ProxyCreate) AddAccountIDs(ids ...int64) *ProxyCreate {
 	_c.mutation.AddAccountIDs(ids...)
@@ -201,6 +215,10 @@ func (_c *ProxyCreate) defaults() error {
 		v := proxy.DefaultStatus
 		_c.mutation.SetStatus(v)
 	}
+	if _, ok := _c.mutation.MaxConcurrency(); !ok {
+		v := proxy.DefaultMaxConcurrency
+		_c.mutation.SetMaxConcurrency(v)
+	}
 	return nil
 }
 
@@ -257,6 +275,9 @@ func (_c *ProxyCreate) check() error {
 			return &ValidationError{Name: "status", err: fmt.Errorf(`ent: validator failed for field "Proxy.status": %w`, err)}
 		}
 	}
+	if _, ok := _c.mutation.MaxConcurrency(); !ok {
+		return &ValidationError{Name: "max_concurrency", err: errors.New(`ent: missing required field "Proxy.max_concurrency"`)}
+	}
 	return nil
 }
 
@@ -324,6 +345,10 @@ func (_c *ProxyCreate) createSpec() (*Proxy, *sqlgraph.CreateSpec) {
 		_spec.SetField(proxy.FieldStatus, field.TypeString, value)
 		_node.Status = value
 	}
+	if value, ok := _c.mutation.MaxConcurrency(); ok {
+		_spec.SetField(proxy.FieldMaxConcurrency, field.TypeInt, value)
+		_node.MaxConcurrency = value
+	}
 	if nodes := _c.mutation.AccountsIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -524,6 +549,24 @@ func (u *ProxyUpsert) UpdateStatus() *ProxyUpsert {
 	return u
 }
 
+// SetMaxConcurrency sets the "max_concurrency" field.
+func (u *ProxyUpsert) SetMaxConcurrency(v int) *ProxyUpsert {
+	u.Set(proxy.FieldMaxConcurrency, v)
+	return u
+}
+
+// UpdateMaxConcurrency sets the "max_concurrency" field to the value that was provided on create.
+func (u *ProxyUpsert) UpdateMaxConcurrency() *ProxyUpsert {
+	u.SetExcluded(proxy.FieldMaxConcurrency)
+	return u
+}
+
+// AddMaxConcurrency adds v to the "max_concurrency" field.
+func (u *ProxyUpsert) AddMaxConcurrency(v int) *ProxyUpsert {
+	u.Add(proxy.FieldMaxConcurrency, v)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -723,6 +766,27 @@ func (u *ProxyUpsertOne) UpdateStatus() *ProxyUpsertOne {
 	})
 }
 
+// SetMaxConcurrency sets the "max_concurrency" field.
+func (u *ProxyUpsertOne) SetMaxConcurrency(v int) *ProxyUpsertOne {
+	return u.Update(func(s *ProxyUpsert) {
+		s.SetMaxConcurrency(v)
+	})
+}
+
+// AddMaxConcurrency adds v to the "max_concurrency" field.
+func (u *ProxyUpsertOne) AddMaxConcurrency(v int) *ProxyUpsertOne {
+	return u.Update(func(s *ProxyUpsert) {
+		s.AddMaxConcurrency(v)
+	})
+}
+
+// UpdateMaxConcurrency sets the "max_concurrency" field to the value that was provided on create.
+func (u *ProxyUpsertOne) UpdateMaxConcurrency() *ProxyUpsertOne {
+	return u.Update(func(s *ProxyUpsert) {
+		s.UpdateMaxConcurrency()
+	})
+}
+
 // Exec executes the query.
 func (u *ProxyUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -1088,6 +1152,27 @@ func (u *ProxyUpsertBulk) UpdateStatus() *ProxyUpsertBulk {
 	})
 }
 
+// SetMaxConcurrency sets the "max_concurrency" field.
+func (u *ProxyUpsertBulk) SetMaxConcurrency(v int) *ProxyUpsertBulk {
+	return u.Update(func(s *ProxyUpsert) {
+		s.SetMaxConcurrency(v)
+	})
+}
+
+// AddMaxConcurrency adds v to the "max_concurrency" field.
+func (u *ProxyUpsertBulk) AddMaxConcurrency(v int) *ProxyUpsertBulk {
+	return u.Update(func(s *ProxyUpsert) {
+		s.AddMaxConcurrency(v)
+	})
+}
+
+// UpdateMaxConcurrency sets the "max_concurrency" field to the value that was provided on create.
+func (u *ProxyUpsertBulk) UpdateMaxConcurrency() *ProxyUpsertBulk {
+	return u.Update(func(s *ProxyUpsert) {
+		s.UpdateMaxConcurrency()
+	})
+}
+
 // Exec executes the query.
 func (u *ProxyUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
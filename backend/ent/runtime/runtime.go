@@ -20,6 +20,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/ent/group"
 	"github.com/Wei-Shaw/sub2api/ent/idempotencyrecord"
 	"github.com/Wei-Shaw/sub2api/ent/identityadoptiondecision"
+	"github.com/Wei-Shaw/sub2api/ent/organization"
 	"github.com/Wei-Shaw/sub2api/ent/paymentauditlog"
 	"github.com/Wei-Shaw/sub2api/ent/paymentorder"
 	"github.com/Wei-Shaw/sub2api/ent/paymentproviderinstance"
@@ -108,38 +109,90 @@ func init() {
 	apikey.DefaultStatus = apikeyDescStatus.Default.(string)
 	// apikey.StatusValidator is a validator for the "status" field. It is called by the builders before save.
 	apikey.StatusValidator = apikeyDescStatus.Validators[0].(func(string) error)
+	// apikeyDescPriority is the schema descriptor for priority field.
+	apikeyDescPriority := apikeyFields[9].Descriptor()
+	// apikey.DefaultPriority holds the default value on creation for the priority field.
+	apikey.DefaultPriority = apikeyDescPriority.Default.(string)
+	// apikey.PriorityValidator is a validator for the "priority" field. It is called by the builders before save.
+	apikey.PriorityValidator = apikeyDescPriority.Validators[0].(func(string) error)
+	// apikeyDescModerationMode is the schema descriptor for moderation_mode field.
+	apikeyDescModerationMode := apikeyFields[10].Descriptor()
+	// apikey.DefaultModerationMode holds the default value on creation for the moderation_mode field.
+	apikey.DefaultModerationMode = apikeyDescModerationMode.Default.(string)
+	// apikey.ModerationModeValidator is a validator for the "moderation_mode" field. It is called by the builders before save.
+	apikey.ModerationModeValidator = apikeyDescModerationMode.Validators[0].(func(string) error)
+	// apikeyDescContextAutoTrim is the schema descriptor for context_auto_trim field.
+	apikeyDescContextAutoTrim := apikeyFields[11].Descriptor()
+	// apikey.DefaultContextAutoTrim holds the default value on creation for the context_auto_trim field.
+	apikey.DefaultContextAutoTrim = apikeyDescContextAutoTrim.Default.(bool)
+	// apikeyDescMaxBodySize is the schema descriptor for max_body_size field.
+	apikeyDescMaxBodySize := apikeyFields[12].Descriptor()
+	// apikey.DefaultMaxBodySize holds the default value on creation for the max_body_size field.
+	apikey.DefaultMaxBodySize = apikeyDescMaxBodySize.Default.(int64)
+	// apikeyDescParentID is the schema descriptor for parent_id field.
+	apikeyDescParentID := apikeyFields[13].Descriptor()
+	// apikey.DefaultParentID holds the default value on creation for the parent_id field.
+	apikey.DefaultParentID = apikeyDescParentID.Default.(int64)
+	// apikeyDescRequestCoalescing is the schema descriptor for request_coalescing field.
+	apikeyDescRequestCoalescing := apikeyFields[14].Descriptor()
+	// apikey.DefaultRequestCoalescing holds the default value on creation for the request_coalescing field.
+	apikey.DefaultRequestCoalescing = apikeyDescRequestCoalescing.Default.(bool)
+	// apikeyDescTokenBucketBurst is the schema descriptor for token_bucket_burst field.
+	apikeyDescTokenBucketBurst := apikeyFields[15].Descriptor()
+	// apikey.DefaultTokenBucketBurst holds the default value on creation for the token_bucket_burst field.
+	apikey.DefaultTokenBucketBurst = apikeyDescTokenBucketBurst.Default.(int)
+	// apikeyDescTokenBucketRefillRate is the schema descriptor for token_bucket_refill_rate field.
+	apikeyDescTokenBucketRefillRate := apikeyFields[16].Descriptor()
+	// apikey.DefaultTokenBucketRefillRate holds the default value on creation for the token_bucket_refill_rate field.
+	apikey.DefaultTokenBucketRefillRate = apikeyDescTokenBucketRefillRate.Default.(float64)
+	// apikeyDescMaxCostPerRequest is the schema descriptor for max_cost_per_request field.
+	apikeyDescMaxCostPerRequest := apikeyFields[17].Descriptor()
+	// apikey.DefaultMaxCostPerRequest holds the default value on creation for the max_cost_per_request field.
+	apikey.DefaultMaxCostPerRequest = apikeyDescMaxCostPerRequest.Default.(float64)
 	// apikeyDescQuota is the schema descriptor for quota field.
-	apikeyDescQuota := apikeyFields[8].Descriptor()
+	apikeyDescQuota := apikeyFields[18].Descriptor()
 	// apikey.DefaultQuota holds the default value on creation for the quota field.
 	apikey.DefaultQuota = apikeyDescQuota.Default.(float64)
 	// apikeyDescQuotaUsed is the schema descriptor for quota_used field.
-	apikeyDescQuotaUsed := apikeyFields[9].Descriptor()
+	apikeyDescQuotaUsed := apikeyFields[19].Descriptor()
 	// apikey.DefaultQuotaUsed holds the default value on creation for the quota_used field.
 	apikey.DefaultQuotaUsed = apikeyDescQuotaUsed.Default.(float64)
 	// apikeyDescRateLimit5h is the schema descriptor for rate_limit_5h field.
-	apikeyDescRateLimit5h := apikeyFields[11].Descriptor()
+	apikeyDescRateLimit5h := apikeyFields[21].Descriptor()
 	// apikey.DefaultRateLimit5h holds the default value on creation for the rate_limit_5h field.
 	apikey.DefaultRateLimit5h = apikeyDescRateLimit5h.Default.(float64)
 	// apikeyDescRateLimit1d is the schema descriptor for rate_limit_1d field.
-	apikeyDescRateLimit1d := apikeyFields[12].Descriptor()
+	apikeyDescRateLimit1d := apikeyFields[22].Descriptor()
 	// apikey.DefaultRateLimit1d holds the default value on creation for the rate_limit_1d field.
 	apikey.DefaultRateLimit1d = apikeyDescRateLimit1d.Default.(float64)
 	// apikeyDescRateLimit7d is the schema descriptor for rate_limit_7d field.
-	apikeyDescRateLimit7d := apikeyFields[13].Descriptor()
+	apikeyDescRateLimit7d := apikeyFields[23].Descriptor()
 	// apikey.DefaultRateLimit7d holds the default value on creation for the rate_limit_7d field.
 	apikey.DefaultRateLimit7d = apikeyDescRateLimit7d.Default.(float64)
 	// apikeyDescUsage5h is the schema descriptor for usage_5h field.
-	apikeyDescUsage5h := apikeyFields[14].Descriptor()
+	apikeyDescUsage5h := apikeyFields[24].Descriptor()
 	// apikey.DefaultUsage5h holds the default value on creation for the usage_5h field.
 	apikey.DefaultUsage5h = apikeyDescUsage5h.Default.(float64)
 	// apikeyDescUsage1d is the schema descriptor for usage_1d field.
-	apikeyDescUsage1d := apikeyFields[15].Descriptor()
+	apikeyDescUsage1d := apikeyFields[25].Descriptor()
 	// apikey.DefaultUsage1d holds the default value on creation for the usage_1d field.
 	apikey.DefaultUsage1d = apikeyDescUsage1d.Default.(float64)
 	// apikeyDescUsage7d is the schema descriptor for usage_7d field.
-	apikeyDescUsage7d := apikeyFields[16].Descriptor()
+	apikeyDescUsage7d := apikeyFields[26].Descriptor()
 	// apikey.DefaultUsage7d holds the default value on creation for the usage_7d field.
 	apikey.DefaultUsage7d = apikeyDescUsage7d.Default.(float64)
+	// apikeyDescAccountTagSelector is the schema descriptor for account_tag_selector field.
+	apikeyDescAccountTagSelector := apikeyFields[30].Descriptor()
+	// apikey.DefaultAccountTagSelector holds the default value on creation for the account_tag_selector field.
+	apikey.DefaultAccountTagSelector = apikeyDescAccountTagSelector.Default.(domain.AccountTagSelector)
+	// apikeyDescAllowedPlatforms is the schema descriptor for allowed_platforms field.
+	apikeyDescAllowedPlatforms := apikeyFields[31].Descriptor()
+	// apikey.DefaultAllowedPlatforms holds the default value on creation for the allowed_platforms field.
+	apikey.DefaultAllowedPlatforms = apikeyDescAllowedPlatforms.Default.([]string)
+	// apikeyDescAllowedModels is the schema descriptor for allowed_models field.
+	apikeyDescAllowedModels := apikeyFields[32].Descriptor()
+	// apikey.DefaultAllowedModels holds the default value on creation for the allowed_models field.
+	apikey.DefaultAllowedModels = apikeyDescAllowedModels.Default.([]string)
 	accountMixin := schema.Account{}.Mixin()
 	accountMixinHooks1 := accountMixin[1].Hooks()
 	account.Hooks[0] = accountMixinHooks1[0]
@@ -251,6 +304,10 @@ func init() {
 	accountDescSessionWindowStatus := accountFields[24].Descriptor()
 	// account.SessionWindowStatusValidator is a validator for the "session_window_status" field. It is called by the builders before save.
 	account.SessionWindowStatusValidator = accountDescSessionWindowStatus.Validators[0].(func(string) error)
+	// accountDescTagsExclusive is the schema descriptor for tags_exclusive field.
+	accountDescTagsExclusive := accountFields[26].Descriptor()
+	// account.DefaultTagsExclusive holds the default value on creation for the tags_exclusive field.
+	account.DefaultTagsExclusive = accountDescTagsExclusive.Default.(bool)
 	accountgroupFields := schema.AccountGroup{}.Fields()
 	_ = accountgroupFields
 	// accountgroupDescPriority is the schema descriptor for priority field.
@@ -736,6 +793,10 @@ func init() {
 	errorpassthroughruleDescSkipMonitoring := errorpassthroughruleFields[11].Descriptor()
 	// errorpassthroughrule.DefaultSkipMonitoring holds the default value on creation for the skip_monitoring field.
 	errorpassthroughrule.DefaultSkipMonitoring = errorpassthroughruleDescSkipMonitoring.Default.(bool)
+	// errorpassthroughruleDescHitCount is the schema descriptor for hit_count field.
+	errorpassthroughruleDescHitCount := errorpassthroughruleFields[14].Descriptor()
+	// errorpassthroughrule.DefaultHitCount holds the default value on creation for the hit_count field.
+	errorpassthroughrule.DefaultHitCount = errorpassthroughruleDescHitCount.Default.(int64)
 	groupMixin := schema.Group{}.Mixin()
 	groupMixinHooks1 := groupMixin[1].Hooks()
 	group.Hooks[0] = groupMixinHooks1[0]
@@ -849,6 +910,48 @@ func init() {
 	groupDescRpmLimit := groupFields[27].Descriptor()
 	// group.DefaultRpmLimit holds the default value on creation for the rpm_limit field.
 	group.DefaultRpmLimit = groupDescRpmLimit.Default.(int)
+	// groupDescPriority is the schema descriptor for priority field.
+	groupDescPriority := groupFields[28].Descriptor()
+	// group.DefaultPriority holds the default value on creation for the priority field.
+	group.DefaultPriority = groupDescPriority.Default.(string)
+	// group.PriorityValidator is a validator for the "priority" field. It is called by the builders before save.
+	group.PriorityValidator = groupDescPriority.Validators[0].(func(string) error)
+	// groupDescRequestParamOverrides is the schema descriptor for request_param_overrides field.
+	groupDescRequestParamOverrides := groupFields[29].Descriptor()
+	// group.DefaultRequestParamOverrides holds the default value on creation for the request_param_overrides field.
+	group.DefaultRequestParamOverrides = groupDescRequestParamOverrides.Default.(domain.RequestParamOverrides)
+	// groupDescSystemPrompt is the schema descriptor for system_prompt field.
+	groupDescSystemPrompt := groupFields[30].Descriptor()
+	// group.DefaultSystemPrompt holds the default value on creation for the system_prompt field.
+	group.DefaultSystemPrompt = groupDescSystemPrompt.Default.(string)
+	// groupDescSystemPromptMode is the schema descriptor for system_prompt_mode field.
+	groupDescSystemPromptMode := groupFields[31].Descriptor()
+	// group.DefaultSystemPromptMode holds the default value on creation for the system_prompt_mode field.
+	group.DefaultSystemPromptMode = groupDescSystemPromptMode.Default.(string)
+	// group.SystemPromptModeValidator is a validator for the "system_prompt_mode" field. It is called by the builders before save.
+	group.SystemPromptModeValidator = groupDescSystemPromptMode.Validators[0].(func(string) error)
+	// groupDescPiiRedactionMode is the schema descriptor for pii_redaction_mode field.
+	groupDescPiiRedactionMode := groupFields[32].Descriptor()
+	// group.DefaultPiiRedactionMode holds the default value on creation for the pii_redaction_mode field.
+	group.DefaultPiiRedactionMode = groupDescPiiRedactionMode.Default.(string)
+	// group.PiiRedactionModeValidator is a validator for the "pii_redaction_mode" field. It is called by the builders before save.
+	group.PiiRedactionModeValidator = groupDescPiiRedactionMode.Validators[0].(func(string) error)
+	// groupDescMaxBodySize is the schema descriptor for max_body_size field.
+	groupDescMaxBodySize := groupFields[33].Descriptor()
+	// group.DefaultMaxBodySize holds the default value on creation for the max_body_size field.
+	group.DefaultMaxBodySize = groupDescMaxBodySize.Default.(int64)
+	// groupDescModerationEnabled is the schema descriptor for moderation_enabled field.
+	groupDescModerationEnabled := groupFields[34].Descriptor()
+	// group.DefaultModerationEnabled holds the default value on creation for the moderation_enabled field.
+	group.DefaultModerationEnabled = groupDescModerationEnabled.Default.(bool)
+	// groupDescResponseHeaders is the schema descriptor for response_headers field.
+	groupDescResponseHeaders := groupFields[35].Descriptor()
+	// group.DefaultResponseHeaders holds the default value on creation for the response_headers field.
+	group.DefaultResponseHeaders = groupDescResponseHeaders.Default.(domain.GroupResponseHeaders)
+	// groupDescAccountTagSelector is the schema descriptor for account_tag_selector field.
+	groupDescAccountTagSelector := groupFields[37].Descriptor()
+	// group.DefaultAccountTagSelector holds the default value on creation for the account_tag_selector field.
+	group.DefaultAccountTagSelector = groupDescAccountTagSelector.Default.(domain.AccountTagSelector)
 	idempotencyrecordMixin := schema.IdempotencyRecord{}.Mixin()
 	idempotencyrecordMixinFields0 := idempotencyrecordMixin[0].Fields()
 	_ = idempotencyrecordMixinFields0
@@ -911,6 +1014,57 @@ func init() {
 	identityadoptiondecisionDescDecidedAt := identityadoptiondecisionFields[4].Descriptor()
 	// identityadoptiondecision.DefaultDecidedAt holds the default value on creation for the decided_at field.
 	identityadoptiondecision.DefaultDecidedAt = identityadoptiondecisionDescDecidedAt.Default.(func() time.Time)
+	organizationMixin := schema.Organization{}.Mixin()
+	organizationMixinHooks1 := organizationMixin[1].Hooks()
+	organization.Hooks[0] = organizationMixinHooks1[0]
+	organizationMixinInters1 := organizationMixin[1].Interceptors()
+	organization.Interceptors[0] = organizationMixinInters1[0]
+	organizationMixinFields0 := organizationMixin[0].Fields()
+	_ = organizationMixinFields0
+	organizationFields := schema.Organization{}.Fields()
+	_ = organizationFields
+	// organizationDescCreatedAt is the schema descriptor for created_at field.
+	organizationDescCreatedAt := organizationMixinFields0[0].Descriptor()
+	// organization.DefaultCreatedAt holds the default value on creation for the created_at field.
+	organization.DefaultCreatedAt = organizationDescCreatedAt.Default.(func() time.Time)
+	// organizationDescUpdatedAt is the schema descriptor for updated_at field.
+	organizationDescUpdatedAt := organizationMixinFields0[1].Descriptor()
+	// organization.DefaultUpdatedAt holds the default value on creation for the updated_at field.
+	organization.DefaultUpdatedAt = organizationDescUpdatedAt.Default.(func() time.Time)
+	// organization.UpdateDefaultUpdatedAt holds the default value on update for the updated_at field.
+	organization.UpdateDefaultUpdatedAt = organizationDescUpdatedAt.UpdateDefault.(func() time.Time)
+	// organizationDescName is the schema descriptor for name field.
+	organizationDescName := organizationFields[0].Descriptor()
+	// organization.NameValidator is a validator for the "name" field. It is called by the builders before save.
+	organization.NameValidator = func() func(string) error {
+		validators := organizationDescName.Validators
+		fns := [...]func(string) error{
+			validators[0].(func(string) error),
+			validators[1].(func(string) error),
+		}
+		return func(name string) error {
+			for _, fn := range fns {
+				if err := fn(name); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}()
+	// organizationDescDescription is the schema descriptor for description field.
+	organizationDescDescription := organizationFields[1].Descriptor()
+	// organization.DefaultDescription holds the default value on creation for the description field.
+	organization.DefaultDescription = organizationDescDescription.Default.(string)
+	// organizationDescStatus is the schema descriptor for status field.
+	organizationDescStatus := organizationFields[2].Descriptor()
+	// organization.DefaultStatus holds the default value on creation for the status field.
+	organization.DefaultStatus = organizationDescStatus.Default.(string)
+	// organization.StatusValidator is a validator for the "status" field. It is called by the builders before save.
+	organization.StatusValidator = organizationDescStatus.Validators[0].(func(string) error)
+	// organizationDescMonthlyBudgetUsd is the schema descriptor for monthly_budget_usd field.
+	organizationDescMonthlyBudgetUsd := organizationFields[3].Descriptor()
+	// organization.DefaultMonthlyBudgetUsd holds the default value on creation for the monthly_budget_usd field.
+	organization.DefaultMonthlyBudgetUsd = organizationDescMonthlyBudgetUsd.Default.(float64)
 	paymentauditlogFields := schema.PaymentAuditLog{}.Fields()
 	_ = paymentauditlogFields
 	// paymentauditlogDescOrderID is the schema descriptor for order_id field.
@@ -1333,6 +1487,10 @@ func init() {
 	proxy.DefaultStatus = proxyDescStatus.Default.(string)
 	// proxy.StatusValidator is a validator for the "status" field. It is called by the builders before save.
 	proxy.StatusValidator = proxyDescStatus.Validators[0].(func(string) error)
+	// proxyDescMaxConcurrency is the schema descriptor for max_concurrency field.
+	proxyDescMaxConcurrency := proxyFields[7].Descriptor()
+	// proxy.DefaultMaxConcurrency holds the default value on creation for the max_concurrency field.
+	proxy.DefaultMaxConcurrency = proxyDescMaxConcurrency.Default.(int)
 	redeemcodeFields := schema.RedeemCode{}.Fields()
 	_ = redeemcodeFields
 	// redeemcodeDescCode is the schema descriptor for code field.
@@ -1833,6 +1991,12 @@ func init() {
 	userDescRpmLimit := userFields[19].Descriptor()
 	// user.DefaultRpmLimit holds the default value on creation for the rpm_limit field.
 	user.DefaultRpmLimit = userDescRpmLimit.Default.(int)
+	// userDescOrgRole is the schema descriptor for org_role field.
+	userDescOrgRole := userFields[21].Descriptor()
+	// user.DefaultOrgRole holds the default value on creation for the org_role field.
+	user.DefaultOrgRole = userDescOrgRole.Default.(string)
+	// user.OrgRoleValidator is a validator for the "org_role" field. It is called by the builders before save.
+	user.OrgRoleValidator = userDescOrgRole.Validators[0].(func(string) error)
 	userallowedgroupFields := schema.UserAllowedGroup{}.Fields()
 	_ = userallowedgroupFields
 	// userallowedgroupDescCreatedAt is the schema descriptor for created_at field.
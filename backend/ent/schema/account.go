@@ -193,6 +193,16 @@ func (Account) Fields() []ent.Field {
 			Optional().
 			Nillable().
 			MaxLen(20),
+
+		// owner_user_id: 自助绑定该账号的用户 ID（可选）
+		// 为空表示平台共享账号；非空时仅归属用户的 API Key 可调度该账号
+		field.Int64("owner_user_id").
+			Optional().
+			Nillable(),
+
+		// tags_exclusive: 独占标签（标签本身存放于 account_tags 关联表）；为 true 时仅服务于标签选择器显式要求其标签的分组/API Key
+		field.Bool("tags_exclusive").
+			Default(false),
 	}
 }
 
@@ -228,6 +238,7 @@ func (Account) Indexes() []ent.Index {
 		index.Fields("rate_limited_at"),     // 筛选速率限制账户
 		index.Fields("rate_limit_reset_at"), // 筛选速率限制解除时间
 		index.Fields("overload_until"),      // 筛选过载账户
+		index.Fields("owner_user_id"),       // 按归属用户筛选
 		// 调度热路径复合索引（线上由 SQL 迁移创建部分索引，schema 仅用于模型可读性对齐）
 		index.Fields("platform", "priority"),
 		index.Fields("priority", "status"),
@@ -57,6 +57,39 @@ func (APIKey) Fields() []ent.Field {
 		field.JSON("ip_blacklist", []string{}).
 			Optional().
 			Comment("Blocked IPs/CIDRs"),
+		field.JSON("scopes", []string{}).
+			Optional().
+			Comment("Permission scopes, e.g. [\"chat\", \"platform:openai\"] (empty = unrestricted)"),
+		field.String("priority").
+			MaxLen(10).
+			Default("").
+			Comment("Concurrency wait-queue priority: high/normal/low (empty = inherit from group)"),
+		field.String("moderation_mode").
+			MaxLen(10).
+			Default("").
+			Comment("Content moderation override: enabled/disabled (empty = inherit from group)"),
+		field.Bool("context_auto_trim").
+			Default(false).
+			Comment("Drop oldest messages when the prompt exceeds the model context window"),
+		field.Int64("max_body_size").
+			Default(0).
+			Comment("Max request body size in bytes (0 = inherit from group/endpoint class)"),
+		field.Int64("parent_id").
+			Default(0).
+			Comment("Parent key ID when this key is a short-lived child token (0 = regular key)"),
+		field.Bool("request_coalescing").
+			Default(false).
+			Comment("Coalesce identical concurrent non-stream requests onto one upstream call"),
+		field.Int("token_bucket_burst").
+			Default(0).
+			Comment("Token bucket capacity in requests (0 = disabled)"),
+		field.Float("token_bucket_refill_rate").
+			Default(0).
+			Comment("Token bucket refill rate in requests per second"),
+		field.Float("max_cost_per_request").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Default(0).
+			Comment("Max estimated cost in USD for a single request (0 = unlimited)"),
 
 		// ========== Quota fields ==========
 		// Quota limit in USD (0 = unlimited)
@@ -115,6 +148,21 @@ func (APIKey) Fields() []ent.Field {
 			Optional().
 			Nillable().
 			Comment("Start time of the current 7d rate limit window"),
+
+		field.JSON("account_tag_selector", domain.AccountTagSelector{}).
+			Default(domain.AccountTagSelector{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("Account tag selector combined with the group selector during scheduling"),
+
+		field.JSON("allowed_platforms", []string{}).
+			Default([]string{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("Upstream platforms this key may be routed to (empty = unrestricted)"),
+
+		field.JSON("allowed_models", []string{}).
+			Default([]string{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("Model patterns this key may request (empty = unrestricted)"),
 	}
 }
 
@@ -144,5 +192,6 @@ func (APIKey) Indexes() []ent.Index {
 		// Index for quota queries
 		index.Fields("quota", "quota_used"),
 		index.Fields("expires_at"),
+		index.Fields("parent_id"),
 	}
 }
@@ -115,6 +115,23 @@ func (ErrorPassthroughRule) Fields() []ent.Field {
 		field.Text("description").
 			Optional().
 			Nillable(),
+
+		// expires_at: 规则过期时间，为空表示长期有效
+		// 过期后不再参与匹配，并由后台任务自动禁用，避免临时规则长期残留
+		field.Time("expires_at").
+			Optional().
+			Nillable().
+			SchemaType(map[string]string{dialect.Postgres: "timestamptz"}),
+
+		// hit_count: 规则累计命中次数（各实例定期批量累加）
+		field.Int64("hit_count").
+			Default(0),
+
+		// last_hit_at: 最近一次命中时间
+		field.Time("last_hit_at").
+			Optional().
+			Nillable().
+			SchemaType(map[string]string{dialect.Postgres: "timestamptz"}),
 	}
 }
 
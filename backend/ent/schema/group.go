@@ -150,6 +150,61 @@ func (Group) Fields() []ent.Field {
 		field.Int("rpm_limit").
 			Default(0).
 			Comment("分组 RPM 上限，0 表示不限制；设置后接管该分组用户的限流"),
+
+		// 并发排队优先级：槽位紧张时高优先级等待者先获得槽位。
+		field.String("priority").
+			MaxLen(10).
+			Default(domain.PriorityNormal).
+			Comment("并发等待队列优先级：high/normal/low"),
+
+		// 请求参数策略：转发前注入默认值或裁剪上限（temperature/top_p/reasoning_effort/max_tokens）。
+		field.JSON("request_param_overrides", domain.RequestParamOverrides{}).
+			Default(domain.RequestParamOverrides{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("请求参数策略：默认/强制 temperature、top_p、reasoning_effort 与 max_tokens 上限"),
+
+		// 分组级系统提示词：转发前注入到客户端 system 消息之前或之后。
+		field.Text("system_prompt").
+			Default("").
+			Comment("分组系统提示词，为空表示不注入"),
+		field.String("system_prompt_mode").
+			MaxLen(10).
+			Default(domain.SystemPromptModePrepend).
+			Comment("分组系统提示词注入位置：prepend/append"),
+
+		// 敏感信息过滤：按配置的正则扫描请求文本，脱敏后转发或直接拒绝。
+		field.String("pii_redaction_mode").
+			MaxLen(10).
+			Default("").
+			Comment("敏感信息过滤策略：空（关闭）/mask/reject"),
+
+		// 请求体大小上限：为视觉类等大请求租户单独放宽，0 表示使用端点类别默认值。
+		field.Int64("max_body_size").
+			Default(0).
+			Comment("请求体最大字节数（0 表示使用端点类别默认值）"),
+
+		// 内容审核：转发前调用审核端点或本地规则，命中时拦截请求。
+		field.Bool("moderation_enabled").
+			Default(false).
+			Comment("是否启用内容审核（API Key 可单独覆盖）"),
+
+		// 自定义响应头：注入静态响应头，并额外透传指定的上游响应头。
+		field.JSON("response_headers", domain.GroupResponseHeaders{}).
+			Default(domain.GroupResponseHeaders{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("自定义响应头：静态响应头与额外透传的上游响应头"),
+
+		// 所属组织（可选）：组织分组由组织管理员分配给本组织成员
+		field.Int64("organization_id").
+			Optional().
+			Nillable().
+			Comment("所属组织 ID，为空表示平台分组"),
+
+		// 账号标签选择器：调度时仅选择满足标签要求的账号（专属容量/租户隔离）
+		field.JSON("account_tag_selector", domain.AccountTagSelector{}).
+			Default(domain.AccountTagSelector{}).
+			SchemaType(map[string]string{dialect.Postgres: "jsonb"}).
+			Comment("账号标签选择器：require 全部命中、exclude 均不命中"),
 	}
 }
 
@@ -179,5 +234,6 @@ func (Group) Indexes() []ent.Index {
 		index.Fields("is_exclusive"),
 		index.Fields("deleted_at"),
 		index.Fields("sort_order"),
+		index.Fields("organization_id"),
 	}
 }
@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"github.com/Wei-Shaw/sub2api/ent/schema/mixins"
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Organization 定义组织（团队）实体的 schema。
+//
+// 组织位于用户之上，用于多租户部署：
+//   - 用户通过 users.organization_id 归属组织，org_role=admin 的成员为组织管理员
+//   - 分组可通过 groups.organization_id 归属组织，由组织管理员分配给成员
+//   - monthly_budget_usd 限制组织成员当月的总消费（0 表示不限制）
+type Organization struct {
+	ent.Schema
+}
+
+// Annotations 返回 schema 的注解配置。
+func (Organization) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		entsql.Annotation{Table: "organizations"},
+	}
+}
+
+// Mixin 返回该 schema 使用的混入组件。
+func (Organization) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		mixins.TimeMixin{},
+		mixins.SoftDeleteMixin{},
+	}
+}
+
+// Fields 定义组织实体的所有字段。
+func (Organization) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("name").
+			MaxLen(100).
+			NotEmpty(),
+		field.String("description").
+			SchemaType(map[string]string{dialect.Postgres: "text"}).
+			Default(""),
+		field.String("status").
+			MaxLen(20).
+			Default(domain.StatusActive),
+		// 组织月度预算（USD），按自然月统计成员实际消费，0 表示不限制
+		field.Float("monthly_budget_usd").
+			SchemaType(map[string]string{dialect.Postgres: "decimal(20,8)"}).
+			Default(0),
+	}
+}
+
+// Indexes 定义数据库索引。
+func (Organization) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("status"),
+		index.Fields("deleted_at"),
+	}
+}
@@ -52,6 +52,9 @@ func (Proxy) Fields() []ent.Field {
 		field.String("status").
 			MaxLen(20).
 			Default("active"),
+		// max_concurrency: 经该代理的最大并发上游请求数，0 表示不限制
+		field.Int("max_concurrency").
+			Default(0),
 	}
 }
 
@@ -112,6 +112,14 @@ func (User) Fields() []ent.Field {
 		// 用户级每分钟请求数上限（0 = 不限制）。仅当所在分组未设置 rpm_limit 时作为兜底生效。
 		field.Int("rpm_limit").
 			Default(0),
+
+		// 所属组织（可选）；org_role 为 admin 时可在组织范围内委托管理成员、分组、API Key 与预算
+		field.Int64("organization_id").
+			Optional().
+			Nillable(),
+		field.String("org_role").
+			MaxLen(20).
+			Default(""),
 	}
 }
 
@@ -139,5 +147,6 @@ func (User) Indexes() []ent.Index {
 		// email 字段已在 Fields() 中声明 Unique()，无需重复索引
 		index.Fields("status"),
 		index.Fields("deleted_at"),
+		index.Fields("organization_id"),
 	}
 }
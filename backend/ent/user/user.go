@@ -61,6 +61,10 @@ const (
 	FieldTotalRecharged = "total_recharged"
 	// FieldRpmLimit holds the string denoting the rpm_limit field in the database.
 	FieldRpmLimit = "rpm_limit"
+	// FieldOrganizationID holds the string denoting the organization_id field in the database.
+	FieldOrganizationID = "organization_id"
+	// FieldOrgRole holds the string denoting the org_role field in the database.
+	FieldOrgRole = "org_role"
 	// EdgeAPIKeys holds the string denoting the api_keys edge name in mutations.
 	EdgeAPIKeys = "api_keys"
 	// EdgeRedeemCodes holds the string denoting the redeem_codes edge name in mutations.
@@ -206,6 +210,8 @@ var Columns = []string{
 	FieldBalanceNotifyExtraEmails,
 	FieldTotalRecharged,
 	FieldRpmLimit,
+	FieldOrganizationID,
+	FieldOrgRole,
 }
 
 var (
@@ -276,6 +282,10 @@ var (
 	DefaultTotalRecharged float64
 	// DefaultRpmLimit holds the default value on creation for the "rpm_limit" field.
 	DefaultRpmLimit int
+	// DefaultOrgRole holds the default value on creation for the "org_role" field.
+	DefaultOrgRole string
+	// OrgRoleValidator is a validator for the "org_role" field. It is called by the builders before save.
+	OrgRoleValidator func(string) error
 )
 
 // OrderOption defines the ordering options for the User queries.
@@ -401,6 +411,16 @@ func ByRpmLimit(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldRpmLimit, opts...).ToFunc()
 }
 
+// ByOrganizationID orders the results by the organization_id field.
+func ByOrganizationID(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldOrganizationID, opts...).ToFunc()
+}
+
+// ByOrgRole orders the results by the org_role field.
+func ByOrgRole(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldOrgRole, opts...).ToFunc()
+}
+
 // ByAPIKeysCount orders the results by api_keys count.
 func ByAPIKeysCount(opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
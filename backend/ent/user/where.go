@@ -170,6 +170,16 @@ func RpmLimit(v int) predicate.User {
 	return predicate.User(sql.FieldEQ(FieldRpmLimit, v))
 }
 
+// OrganizationID applies equality check predicate on the "organization_id" field. It's identical to OrganizationIDEQ.
+func OrganizationID(v int64) predicate.User {
+	return predicate.User(sql.FieldEQ(FieldOrganizationID, v))
+}
+
+// OrgRole applies equality check predicate on the "org_role" field. It's identical to OrgRoleEQ.
+func OrgRole(v string) predicate.User {
+	return predicate.User(sql.FieldEQ(FieldOrgRole, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.User {
 	return predicate.User(sql.FieldEQ(FieldCreatedAt, v))
@@ -1340,6 +1350,121 @@ func RpmLimitLTE(v int) predicate.User {
 	return predicate.User(sql.FieldLTE(FieldRpmLimit, v))
 }
 
+// OrganizationIDEQ applies the EQ predicate on the "organization_id" field.
+func OrganizationIDEQ(v int64) predicate.User {
+	return predicate.User(sql.FieldEQ(FieldOrganizationID, v))
+}
+
+// OrganizationIDNEQ applies the NEQ predicate on the "organization_id" field.
+func OrganizationIDNEQ(v int64) predicate.User {
+	return predicate.User(sql.FieldNEQ(FieldOrganizationID, v))
+}
+
+// OrganizationIDIn applies the In predicate on the "organization_id" field.
+func OrganizationIDIn(vs ...int64) predicate.User {
+	return predicate.User(sql.FieldIn(FieldOrganizationID, vs...))
+}
+
+// OrganizationIDNotIn applies the NotIn predicate on the "organization_id" field.
+func OrganizationIDNotIn(vs ...int64) predicate.User {
+	return predicate.User(sql.FieldNotIn(FieldOrganizationID, vs...))
+}
+
+// OrganizationIDGT applies the GT predicate on the "organization_id" field.
+func OrganizationIDGT(v int64) predicate.User {
+	return predicate.User(sql.FieldGT(FieldOrganizationID, v))
+}
+
+// OrganizationIDGTE applies the GTE predicate on the "organization_id" field.
+func OrganizationIDGTE(v int64) predicate.User {
+	return predicate.User(sql.FieldGTE(FieldOrganizationID, v))
+}
+
+// OrganizationIDLT applies the LT predicate on the "organization_id" field.
+func OrganizationIDLT(v int64) predicate.User {
+	return predicate.User(sql.FieldLT(FieldOrganizationID, v))
+}
+
+// OrganizationIDLTE applies the LTE predicate on the "organization_id" field.
+func OrganizationIDLTE(v int64) predicate.User {
+	return predicate.User(sql.FieldLTE(FieldOrganizationID, v))
+}
+
+// OrganizationIDIsNil applies the IsNil predicate on the "organization_id" field.
+func OrganizationIDIsNil() predicate.User {
+	return predicate.User(sql.FieldIsNull(FieldOrganizationID))
+}
+
+// OrganizationIDNotNil applies the NotNil predicate on the "organization_id" field.
+func OrganizationIDNotNil() predicate.User {
+	return predicate.User(sql.FieldNotNull(FieldOrganizationID))
+}
+
+// OrgRoleEQ applies the EQ predicate on the "org_role" field.
+func OrgRoleEQ(v string) predicate.User {
+	return predicate.User(sql.FieldEQ(FieldOrgRole, v))
+}
+
+// OrgRoleNEQ applies the NEQ predicate on the "org_role" field.
+func OrgRoleNEQ(v string) predicate.User {
+	return predicate.User(sql.FieldNEQ(FieldOrgRole, v))
+}
+
+// OrgRoleIn applies the In predicate on the "org_role" field.
+func OrgRoleIn(vs ...string) predicate.User {
+	return predicate.User(sql.FieldIn(FieldOrgRole, vs...))
+}
+
+// OrgRoleNotIn applies the NotIn predicate on the "org_role" field.
+func OrgRoleNotIn(vs ...string) predicate.User {
+	return predicate.User(sql.FieldNotIn(FieldOrgRole, vs...))
+}
+
+// OrgRoleGT applies the GT predicate on the "org_role" field.
+func OrgRoleGT(v string) predicate.User {
+	return predicate.User(sql.FieldGT(FieldOrgRole, v))
+}
+
+// OrgRoleGTE applies the GTE predicate on the "org_role" field.
+func OrgRoleGTE(v string) predicate.User {
+	return predicate.User(sql.FieldGTE(FieldOrgRole, v))
+}
+
+// OrgRoleLT applies the LT predicate on the "org_role" field.
+func OrgRoleLT(v string) predicate.User {
+	return predicate.User(sql.FieldLT(FieldOrgRole, v))
+}
+
+// OrgRoleLTE applies the LTE predicate on the "org_role" field.
+func OrgRoleLTE(v string) predicate.User {
+	return predicate.User(sql.FieldLTE(FieldOrgRole, v))
+}
+
+// OrgRoleContains applies the Contains predicate on the "org_role" field.
+func OrgRoleContains(v string) predicate.User {
+	return predicate.User(sql.FieldContains(FieldOrgRole, v))
+}
+
+// OrgRoleHasPrefix applies the HasPrefix predicate on the "org_role" field.
+func OrgRoleHasPrefix(v string) predicate.User {
+	return predicate.User(sql.FieldHasPrefix(FieldOrgRole, v))
+}
+
+// OrgRoleHasSuffix applies the HasSuffix predicate on the "org_role" field.
+func OrgRoleHasSuffix(v string) predicate.User {
+	return predicate.User(sql.FieldHasSuffix(FieldOrgRole, v))
+}
+
+// OrgRoleEqualFold applies the EqualFold predicate on the "org_role" field.
+func OrgRoleEqualFold(v string) predicate.User {
+	return predicate.User(sql.FieldEqualFold(FieldOrgRole, v))
+}
+
+// OrgRoleContainsFold applies the ContainsFold predicate on the "org_role" field.
+func OrgRoleContainsFold(v string) predicate.User {
+	return predicate.User(sql.FieldContainsFold(FieldOrgRole, v))
+}
+
 // HasAPIKeys applies the HasEdge predicate on the "api_keys" edge.
 func HasAPIKeys() predicate.User {
 	return predicate.User(func(s *sql.Selector) {
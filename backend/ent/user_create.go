@@ -339,6 +339,34 @@ func (_c *UserCreate) SetNillableRpmLimit(v *int) *UserCreate {
 	return _c
 }
 
+// SetOrganizationID sets the "organization_id" field.
+func (_c *UserCreate) SetOrganizationID(v int64) *UserCreate {
+	_c.mutation.SetOrganizationID(v)
+	return _c
+}
+
+// SetNillableOrganizationID sets the "organization_id" field if the given value is not nil.
+func (_c *UserCreate) SetNillableOrganizationID(v *int64) *UserCreate {
+	if v != nil {
+		_c.SetOrganizationID(*v)
+	}
+	return _c
+}
+
+// SetOrgRole sets the "org_role" field.
+func (_c *UserCreate) SetOrgRole(v string) *UserCreate {
+	_c.mutation.SetOrgRole(v)
+	return _c
+}
+
+// SetNillableOrgRole sets the "org_role" field if the given value is not nil.
+func (_c *UserCreate) SetNillableOrgRole(v *string) *UserCreate {
+	if v != nil {
+		_c.SetOrgRole(*v)
+	}
+	return _c
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_c *UserCreate) AddAPIKeyIDs(ids ...int64) *UserCreate {
 	_c.mutation.AddAPIKeyIDs(ids...)
@@ -622,6 +650,10 @@ func (_c *UserCreate) defaults() error {
 		v := user.DefaultRpmLimit
 		_c.mutation.SetRpmLimit(v)
 	}
+	if _, ok := _c.mutation.OrgRole(); !ok {
+		v := user.DefaultOrgRole
+		_c.mutation.SetOrgRole(v)
+	}
 	return nil
 }
 
@@ -708,6 +740,14 @@ func (_c *UserCreate) check() error {
 	if _, ok := _c.mutation.RpmLimit(); !ok {
 		return &ValidationError{Name: "rpm_limit", err: errors.New(`ent: missing required field "User.rpm_limit"`)}
 	}
+	if _, ok := _c.mutation.OrgRole(); !ok {
+		return &ValidationError{Name: "org_role", err: errors.New(`ent: missing required field "User.org_role"`)}
+	}
+	if v, ok := _c.mutation.OrgRole(); ok {
+		if err := user.OrgRoleValidator(v); err != nil {
+			return &ValidationError{Name: "org_role", err: fmt.Errorf(`ent: validator failed for field "User.org_role": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -827,6 +867,14 @@ func (_c *UserCreate) createSpec() (*User, *sqlgraph.CreateSpec) {
 		_spec.SetField(user.FieldRpmLimit, field.TypeInt, value)
 		_node.RpmLimit = value
 	}
+	if value, ok := _c.mutation.OrganizationID(); ok {
+		_spec.SetField(user.FieldOrganizationID, field.TypeInt64, value)
+		_node.OrganizationID = &value
+	}
+	if value, ok := _c.mutation.OrgRole(); ok {
+		_spec.SetField(user.FieldOrgRole, field.TypeString, value)
+		_node.OrgRole = value
+	}
 	if nodes := _c.mutation.APIKeysIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1405,6 +1453,42 @@ func (u *UserUpsert) AddRpmLimit(v int) *UserUpsert {
 	return u
 }
 
+// SetOrganizationID sets the "organization_id" field.
+func (u *UserUpsert) SetOrganizationID(v int64) *UserUpsert {
+	u.Set(user.FieldOrganizationID, v)
+	return u
+}
+
+// UpdateOrganizationID sets the "organization_id" field to the value that was provided on create.
+func (u *UserUpsert) UpdateOrganizationID() *UserUpsert {
+	u.SetExcluded(user.FieldOrganizationID)
+	return u
+}
+
+// AddOrganizationID adds v to the "organization_id" field.
+func (u *UserUpsert) AddOrganizationID(v int64) *UserUpsert {
+	u.Add(user.FieldOrganizationID, v)
+	return u
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (u *UserUpsert) ClearOrganizationID() *UserUpsert {
+	u.SetNull(user.FieldOrganizationID)
+	return u
+}
+
+// SetOrgRole sets the "org_role" field.
+func (u *UserUpsert) SetOrgRole(v string) *UserUpsert {
+	u.Set(user.FieldOrgRole, v)
+	return u
+}
+
+// UpdateOrgRole sets the "org_role" field to the value that was provided on create.
+func (u *UserUpsert) UpdateOrgRole() *UserUpsert {
+	u.SetExcluded(user.FieldOrgRole)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create.
 // Using this option is equivalent to using:
 //
@@ -1835,6 +1919,48 @@ func (u *UserUpsertOne) UpdateRpmLimit() *UserUpsertOne {
 	})
 }
 
+// SetOrganizationID sets the "organization_id" field.
+func (u *UserUpsertOne) SetOrganizationID(v int64) *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.SetOrganizationID(v)
+	})
+}
+
+// AddOrganizationID adds v to the "organization_id" field.
+func (u *UserUpsertOne) AddOrganizationID(v int64) *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.AddOrganizationID(v)
+	})
+}
+
+// UpdateOrganizationID sets the "organization_id" field to the value that was provided on create.
+func (u *UserUpsertOne) UpdateOrganizationID() *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateOrganizationID()
+	})
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (u *UserUpsertOne) ClearOrganizationID() *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.ClearOrganizationID()
+	})
+}
+
+// SetOrgRole sets the "org_role" field.
+func (u *UserUpsertOne) SetOrgRole(v string) *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.SetOrgRole(v)
+	})
+}
+
+// UpdateOrgRole sets the "org_role" field to the value that was provided on create.
+func (u *UserUpsertOne) UpdateOrgRole() *UserUpsertOne {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateOrgRole()
+	})
+}
+
 // Exec executes the query.
 func (u *UserUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -2431,6 +2557,48 @@ func (u *UserUpsertBulk) UpdateRpmLimit() *UserUpsertBulk {
 	})
 }
 
+// SetOrganizationID sets the "organization_id" field.
+func (u *UserUpsertBulk) SetOrganizationID(v int64) *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.SetOrganizationID(v)
+	})
+}
+
+// AddOrganizationID adds v to the "organization_id" field.
+func (u *UserUpsertBulk) AddOrganizationID(v int64) *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.AddOrganizationID(v)
+	})
+}
+
+// UpdateOrganizationID sets the "organization_id" field to the value that was provided on create.
+func (u *UserUpsertBulk) UpdateOrganizationID() *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateOrganizationID()
+	})
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (u *UserUpsertBulk) ClearOrganizationID() *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.ClearOrganizationID()
+	})
+}
+
+// SetOrgRole sets the "org_role" field.
+func (u *UserUpsertBulk) SetOrgRole(v string) *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.SetOrgRole(v)
+	})
+}
+
+// UpdateOrgRole sets the "org_role" field to the value that was provided on create.
+func (u *UserUpsertBulk) UpdateOrgRole() *UserUpsertBulk {
+	return u.Update(func(s *UserUpsert) {
+		s.UpdateOrgRole()
+	})
+}
+
 // Exec executes the query.
 func (u *UserUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -410,6 +410,47 @@ func (_u *UserUpdate) AddRpmLimit(v int) *UserUpdate {
 	return _u
 }
 
+// SetOrganizationID sets the "organization_id" field.
+func (_u *UserUpdate) SetOrganizationID(v int64) *UserUpdate {
+	_u.mutation.ResetOrganizationID()
+	_u.mutation.SetOrganizationID(v)
+	return _u
+}
+
+// SetNillableOrganizationID sets the "organization_id" field if the given value is not nil.
+func (_u *UserUpdate) SetNillableOrganizationID(v *int64) *UserUpdate {
+	if v != nil {
+		_u.SetOrganizationID(*v)
+	}
+	return _u
+}
+
+// AddOrganizationID adds value to the "organization_id" field.
+func (_u *UserUpdate) AddOrganizationID(v int64) *UserUpdate {
+	_u.mutation.AddOrganizationID(v)
+	return _u
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (_u *UserUpdate) ClearOrganizationID() *UserUpdate {
+	_u.mutation.ClearOrganizationID()
+	return _u
+}
+
+// SetOrgRole sets the "org_role" field.
+func (_u *UserUpdate) SetOrgRole(v string) *UserUpdate {
+	_u.mutation.SetOrgRole(v)
+	return _u
+}
+
+// SetNillableOrgRole sets the "org_role" field if the given value is not nil.
+func (_u *UserUpdate) SetNillableOrgRole(v *string) *UserUpdate {
+	if v != nil {
+		_u.SetOrgRole(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *UserUpdate) AddAPIKeyIDs(ids ...int64) *UserUpdate {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -921,6 +962,11 @@ func (_u *UserUpdate) check() error {
 			return &ValidationError{Name: "signup_source", err: fmt.Errorf(`ent: validator failed for field "User.signup_source": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.OrgRole(); ok {
+		if err := user.OrgRoleValidator(v); err != nil {
+			return &ValidationError{Name: "org_role", err: fmt.Errorf(`ent: validator failed for field "User.org_role": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -1035,6 +1081,18 @@ func (_u *UserUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.AddedRpmLimit(); ok {
 		_spec.AddField(user.FieldRpmLimit, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.OrganizationID(); ok {
+		_spec.SetField(user.FieldOrganizationID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedOrganizationID(); ok {
+		_spec.AddField(user.FieldOrganizationID, field.TypeInt64, value)
+	}
+	if _u.mutation.OrganizationIDCleared() {
+		_spec.ClearField(user.FieldOrganizationID, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.OrgRole(); ok {
+		_spec.SetField(user.FieldOrgRole, field.TypeString, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -1978,6 +2036,47 @@ func (_u *UserUpdateOne) AddRpmLimit(v int) *UserUpdateOne {
 	return _u
 }
 
+// SetOrganizationID sets the "organization_id" field.
+func (_u *UserUpdateOne) SetOrganizationID(v int64) *UserUpdateOne {
+	_u.mutation.ResetOrganizationID()
+	_u.mutation.SetOrganizationID(v)
+	return _u
+}
+
+// SetNillableOrganizationID sets the "organization_id" field if the given value is not nil.
+func (_u *UserUpdateOne) SetNillableOrganizationID(v *int64) *UserUpdateOne {
+	if v != nil {
+		_u.SetOrganizationID(*v)
+	}
+	return _u
+}
+
+// AddOrganizationID adds value to the "organization_id" field.
+func (_u *UserUpdateOne) AddOrganizationID(v int64) *UserUpdateOne {
+	_u.mutation.AddOrganizationID(v)
+	return _u
+}
+
+// ClearOrganizationID clears the value of the "organization_id" field.
+func (_u *UserUpdateOne) ClearOrganizationID() *UserUpdateOne {
+	_u.mutation.ClearOrganizationID()
+	return _u
+}
+
+// SetOrgRole sets the "org_role" field.
+func (_u *UserUpdateOne) SetOrgRole(v string) *UserUpdateOne {
+	_u.mutation.SetOrgRole(v)
+	return _u
+}
+
+// SetNillableOrgRole sets the "org_role" field if the given value is not nil.
+func (_u *UserUpdateOne) SetNillableOrgRole(v *string) *UserUpdateOne {
+	if v != nil {
+		_u.SetOrgRole(*v)
+	}
+	return _u
+}
+
 // AddAPIKeyIDs adds the "api_keys" edge to the APIKey entity by IDs.
 func (_u *UserUpdateOne) AddAPIKeyIDs(ids ...int64) *UserUpdateOne {
 	_u.mutation.AddAPIKeyIDs(ids...)
@@ -2502,6 +2601,11 @@ func (_u *UserUpdateOne) check() error {
 			return &ValidationError{Name: "signup_source", err: fmt.Errorf(`ent: validator failed for field "User.signup_source": %w`, err)}
 		}
 	}
+	if v, ok := _u.mutation.OrgRole(); ok {
+		if err := user.OrgRoleValidator(v); err != nil {
+			return &ValidationError{Name: "org_role", err: fmt.Errorf(`ent: validator failed for field "User.org_role": %w`, err)}
+		}
+	}
 	return nil
 }
 
@@ -2633,6 +2737,18 @@ func (_u *UserUpdateOne) sqlSave(ctx context.Context) (_node *User, err error) {
 	if value, ok := _u.mutation.AddedRpmLimit(); ok {
 		_spec.AddField(user.FieldRpmLimit, field.TypeInt, value)
 	}
+	if value, ok := _u.mutation.OrganizationID(); ok {
+		_spec.SetField(user.FieldOrganizationID, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedOrganizationID(); ok {
+		_spec.AddField(user.FieldOrganizationID, field.TypeInt64, value)
+	}
+	if _u.mutation.OrganizationIDCleared() {
+		_spec.ClearField(user.FieldOrganizationID, field.TypeInt64)
+	}
+	if value, ok := _u.mutation.OrgRole(); ok {
+		_spec.SetField(user.FieldOrgRole, field.TypeString, value)
+	}
 	if _u.mutation.APIKeysCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
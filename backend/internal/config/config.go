@@ -6,12 +6,17 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/url"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/expr-lang/expr"
 	"github.com/spf13/viper"
+	"golang.org/x/net/http/httpguts"
 )
 
 const (
@@ -26,6 +31,18 @@ const (
 	UsageRecordOverflowPolicySync   = "sync"
 )
 
+// 计费数据库不可用时的降级模式
+const (
+	BillingDBOutageModeFailClosed = "fail_closed"
+	BillingDBOutageModeFailOpen   = "fail_open"
+)
+
+// 并发槽位 / 粘性会话存储后端
+const (
+	ConcurrencyBackendRedis  = "redis"
+	ConcurrencyBackendMemory = "memory"
+)
+
 // DefaultCSPPolicy is the default Content-Security-Policy with nonce support
 // __CSP_NONCE__ will be replaced with actual nonce at request time by the SecurityHeaders middleware
 const DefaultCSPPolicy = "default-src 'self'; script-src 'self' __CSP_NONCE__ https://challenges.cloudflare.com https://static.cloudflareinsights.com https://*.stripe.com; style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; img-src 'self' data: https:; font-src 'self' data: https://fonts.gstatic.com; connect-src 'self' https:; frame-src https://challenges.cloudflare.com https://*.stripe.com; frame-ancestors 'none'; base-uri 'self'; form-action 'self'"
@@ -89,6 +106,7 @@ type Config struct {
 	Gemini                  GeminiConfig                  `mapstructure:"gemini"`
 	Update                  UpdateConfig                  `mapstructure:"update"`
 	Idempotency             IdempotencyConfig             `mapstructure:"idempotency"`
+	UsageExport             UsageExportConfig             `mapstructure:"usage_export"`
 }
 
 type LogConfig struct {
@@ -121,6 +139,18 @@ type LogSamplingConfig struct {
 	Enabled    bool `mapstructure:"enabled"`
 	Initial    int  `mapstructure:"initial"`
 	Thereafter int  `mapstructure:"thereafter"`
+	// Components 按组件的采样规则（与 enabled 控制的全局采样相互独立）
+	Components []LogComponentSamplingConfig `mapstructure:"components"`
+}
+
+// LogComponentSamplingConfig 按组件的日志采样规则
+type LogComponentSamplingConfig struct {
+	// Component 组件名前缀（最长前缀优先），如 handler.gateway 匹配 handler.gateway.messages
+	Component string `mapstructure:"component"`
+	// Rate 低于 always_level 的日志保留比例（0~1）；携带 request_id 时同一请求的日志统一保留或丢弃
+	Rate float64 `mapstructure:"rate"`
+	// AlwaysLevel 不低于该级别的日志始终保留（debug/info/warn/error，默认 warn）
+	AlwaysLevel string `mapstructure:"always_level"`
 }
 
 type GeminiConfig struct {
@@ -171,6 +201,61 @@ type IdempotencyConfig struct {
 	CleanupBatchSize int `mapstructure:"cleanup_batch_size"`
 }
 
+const (
+	UsageExportBackendKafka = "kafka"
+	UsageExportBackendNATS  = "nats"
+)
+
+// UsageExportConfig 使用记录事件导出配置：每个完成的请求的使用记录以 JSON 发布到 Kafka / NATS。
+// 至少一次投递：发布失败（重试耗尽、队列满、停机未投递）的事件写入数据库死信表，后台定期重投。
+type UsageExportConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Backend 发布后端：kafka / nats
+	Backend string `mapstructure:"backend"`
+	// QueueSize 内存发布队列容量，队列满时事件直接写入死信表
+	QueueSize int `mapstructure:"queue_size"`
+	// BatchSize 单次发布的最大事件数
+	BatchSize int `mapstructure:"batch_size"`
+	// FlushIntervalMs 未凑满批次时的最长等待时间（毫秒）
+	FlushIntervalMs int `mapstructure:"flush_interval_ms"`
+	// MaxAttempts 单批次发布最大尝试次数，耗尽后写入死信表
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// RetryBackoffMs 发布重试的初始退避（毫秒），按次数翻倍
+	RetryBackoffMs int `mapstructure:"retry_backoff_ms"`
+	// PublishTimeoutSeconds 单次发布（含等待 broker 确认）超时（秒）
+	PublishTimeoutSeconds int `mapstructure:"publish_timeout_seconds"`
+	// DeadLetterRedriveIntervalSeconds 死信重投周期（秒）
+	DeadLetterRedriveIntervalSeconds int `mapstructure:"dead_letter_redrive_interval_seconds"`
+	// DeadLetterBatchSize 每轮重投的最大死信数
+	DeadLetterBatchSize int `mapstructure:"dead_letter_batch_size"`
+
+	Kafka UsageExportKafkaConfig `mapstructure:"kafka"`
+	NATS  UsageExportNATSConfig  `mapstructure:"nats"`
+}
+
+// UsageExportKafkaConfig 直连 Kafka broker 发布（acks=all，等待全部 ISR 确认）
+type UsageExportKafkaConfig struct {
+	// Brokers broker 地址列表，如 ["kafka-1:9092", "kafka-2:9092"]
+	Brokers []string `mapstructure:"brokers"`
+	Topic   string   `mapstructure:"topic"`
+	// Username / Password 可选的 SASL/PLAIN 认证
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// TLS 是否使用 TLS 连接 broker
+	TLS bool `mapstructure:"tls"`
+}
+
+// UsageExportNATSConfig 通过 NATS JetStream 发布（等待 JetStream 确认，需预先创建覆盖 subject 的 stream）
+type UsageExportNATSConfig struct {
+	// URL NATS 服务地址，如 nats://nats:4222
+	URL     string `mapstructure:"url"`
+	Subject string `mapstructure:"subject"`
+	// Username / Password / Token 可选的认证信息
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Token    string `mapstructure:"token"`
+}
+
 type LinuxDoConnectConfig struct {
 	Enabled             bool   `mapstructure:"enabled"`
 	ClientID            string `mapstructure:"client_id"`
@@ -471,6 +556,12 @@ type TokenRefreshConfig struct {
 	MaxRetries int `mapstructure:"max_retries"`
 	// 重试退避基础时间（秒）
 	RetryBackoffSeconds int `mapstructure:"retry_backoff_seconds"`
+	// 分布式刷新锁 TTL（秒），多副本部署下同一账号同一时刻只允许一个副本刷新
+	LockTTLSeconds int `mapstructure:"lock_ttl_seconds"`
+	// 锁被其他副本持有时，等待对方刷新结果的最长时间（毫秒），0 表示不等待
+	PeerWaitTimeoutMs int `mapstructure:"peer_wait_timeout_ms"`
+	// 等待对方刷新结果时的轮询间隔（毫秒）
+	PeerWaitPollIntervalMs int `mapstructure:"peer_wait_poll_interval_ms"`
 }
 
 type PricingConfig struct {
@@ -513,6 +604,22 @@ type H2CConfig struct {
 type CORSConfig struct {
 	AllowedOrigins   []string `mapstructure:"allowed_origins"`
 	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	// Gateway 网关路由（/v1、/v1beta、/responses 等）独立的 CORS 策略，
+	// 供浏览器直接调用网关；未启用时网关路由沿用上面的全局配置。
+	Gateway GatewayCORSConfig `mapstructure:"gateway"`
+}
+
+// GatewayCORSConfig 网关路由 CORS 配置
+type GatewayCORSConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	// AllowedHeaders 在默认放行的请求头之外额外放行的请求头；包含 "*" 时回显预检请求的 Access-Control-Request-Headers
+	AllowedHeaders []string `mapstructure:"allowed_headers"`
+	// ExposeHeaders 允许浏览器脚本读取的响应头（如 x-request-id、retry-after）
+	ExposeHeaders []string `mapstructure:"expose_headers"`
+	// MaxAgeSeconds 预检结果缓存时间（秒）
+	MaxAgeSeconds int `mapstructure:"max_age_seconds"`
 }
 
 type SecurityConfig struct {
@@ -521,6 +628,10 @@ type SecurityConfig struct {
 	CSP             CSPConfig            `mapstructure:"csp"`
 	ProxyFallback   ProxyFallbackConfig  `mapstructure:"proxy_fallback"`
 	ProxyProbe      ProxyProbeConfig     `mapstructure:"proxy_probe"`
+	// CredentialEncryption 账号凭证（access_token/refresh_token/api_key 等）落库加密配置
+	CredentialEncryption CredentialEncryptionConfig `mapstructure:"credential_encryption"`
+	// RequestSigning HMAC 签名请求认证（X-Signature / X-Timestamp / X-Nonce）
+	RequestSigning RequestSigningConfig `mapstructure:"request_signing"`
 }
 
 type URLAllowlistConfig struct {
@@ -555,12 +666,46 @@ type ProxyFallbackConfig struct {
 	AllowDirectOnError bool `mapstructure:"allow_direct_on_error"`
 }
 
+// CredentialEncryptionConfig 账号凭证信封加密配置。
+// 每个敏感字段使用随机数据密钥（DEK）进行 AES-256-GCM 加密，DEK 再由主密钥（KEK）包裹后一并存储。
+type CredentialEncryptionConfig struct {
+	// Enabled 写入账号时是否加密敏感凭证字段（读取时始终透明解密已加密字段）
+	Enabled bool `mapstructure:"enabled"`
+	// MasterKey AES-256 主密钥（32 字节 hex 编码，64 个字符）。
+	// 可通过环境变量 SECURITY_CREDENTIAL_ENCRYPTION_MASTER_KEY 由 KMS / Secret Manager 注入。
+	MasterKey string `mapstructure:"master_key"`
+}
+
+// RequestSigningConfig HMAC 签名请求配置。
+// 启用后，网关在请求携带 X-Signature 时改为校验签名，不再要求请求中携带 API Key。
+type RequestSigningConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxClockSkewSeconds 允许的客户端时间戳偏差（秒），nonce 保留时长为其两倍
+	MaxClockSkewSeconds int `mapstructure:"max_clock_skew_seconds"`
+}
+
 type ProxyProbeConfig struct {
 	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"` // 已禁用：禁止跳过 TLS 证书验证
 }
 
 type BillingConfig struct {
 	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	// DBOutage: 计费数据库不可用时的降级策略
+	DBOutage BillingDBOutageConfig `mapstructure:"db_outage"`
+}
+
+// BillingDBOutageConfig 计费数据库不可用时的降级配置
+type BillingDBOutageConfig struct {
+	// Mode: 降级模式
+	// - "fail_closed"（默认）：拒绝新请求并返回明确错误（503 billing_database_unavailable）
+	// - "fail_open"：继续转发请求，使用量写入本地溢出日志，数据库恢复后回放（需启用 gateway.usage_record.journal_enabled）
+	Mode string `mapstructure:"mode"`
+	// ProbeIntervalSeconds: 数据库健康探测间隔（秒）
+	ProbeIntervalSeconds int `mapstructure:"probe_interval_seconds"`
+	// ProbeTimeoutSeconds: 单次探测超时（秒）
+	ProbeTimeoutSeconds int `mapstructure:"probe_timeout_seconds"`
+	// FailureThreshold: 连续探测失败达到该次数后判定数据库不可用
+	FailureThreshold int `mapstructure:"failure_threshold"`
 }
 
 type CircuitBreakerConfig struct {
@@ -571,8 +716,18 @@ type CircuitBreakerConfig struct {
 }
 
 type ConcurrencyConfig struct {
+	// Backend: 并发槽位、等待队列与粘性会话的存储后端
+	// - "redis"（默认）：多实例共享，适用于集群部署
+	// - "memory"：进程内存储，适用于不部署 Redis 的单实例场景；多副本时各副本独立计数。
+	//   此时不连接 Redis：可选缓存直接跳过，邮箱验证码、密码重置与 TOTP 等依赖 Redis 的功能返回不可用
+	// 粘性会话可通过 gateway.sticky_session_store.backend 单独指定（memory 模式下只能为 memory 或留空）
+	Backend string `mapstructure:"backend"`
 	// PingInterval: 并发等待期间的 SSE ping 间隔（秒）
 	PingInterval int `mapstructure:"ping_interval"`
+	// MaxSlotHoldSeconds: 单个并发槽位的最长持有时间（秒），超过视为泄漏并被强制释放；0 表示不回收
+	MaxSlotHoldSeconds int `mapstructure:"max_slot_hold_seconds"`
+	// SlotReaperIntervalSeconds: 卡死槽位回收任务的执行周期（秒）
+	SlotReaperIntervalSeconds int `mapstructure:"slot_reaper_interval_seconds"`
 }
 
 // GatewayConfig API网关相关配置
@@ -580,8 +735,14 @@ type GatewayConfig struct {
 	// 等待上游响应头的超时时间（秒），0表示无超时
 	// 注意：这不影响流式数据传输，只控制等待响应头的时间
 	ResponseHeaderTimeout int `mapstructure:"response_header_timeout"`
-	// 请求体最大字节数，用于网关请求体大小限制
+	// 请求体最大字节数，用于网关请求体大小限制（端点类别/分组/API Key 未单独配置时的默认值）
 	MaxBodySize int64 `mapstructure:"max_body_size"`
+	// BodySizeLimits: 按端点类别覆盖请求体上限
+	BodySizeLimits GatewayBodySizeLimitsConfig `mapstructure:"body_size_limits"`
+	// TokenBucketMaxWaitSeconds: API Key 令牌桶耗尽时请求最多排队等待的秒数，超过直接返回 429
+	TokenBucketMaxWaitSeconds int `mapstructure:"token_bucket_max_wait_seconds"`
+	// UpstreamRequestIDHeader: 向上游透传网关请求 ID（X-Request-ID）时使用的请求头名，为空表示不透传
+	UpstreamRequestIDHeader string `mapstructure:"upstream_request_id_header"`
 	// 非流式上游响应体读取上限（字节），用于防止无界读取导致内存放大
 	UpstreamResponseReadMaxBytes int64 `mapstructure:"upstream_response_read_max_bytes"`
 	// 代理探测响应体读取上限（字节）
@@ -633,8 +794,23 @@ type GatewayConfig struct {
 
 	// StreamDataIntervalTimeout: 流数据间隔超时（秒），0表示禁用
 	StreamDataIntervalTimeout int `mapstructure:"stream_data_interval_timeout"`
+	// StreamTimeoutFailover: 流数据间隔超时且尚未向客户端输出任何内容时，按可 failover 错误处理（切换账号重试）
+	StreamTimeoutFailover bool `mapstructure:"stream_timeout_failover"`
+	// StreamFailoverBuffer: 流式请求在首个内容增量到达前暂存输出（message_start 等前导事件），
+	// 期间上游失败（错误事件、读错误、超时）仍可透明切换账号重试，之后才开始向客户端输出
+	StreamFailoverBuffer bool `mapstructure:"stream_failover_buffer"`
+	// StreamFailoverBufferMaxBytes: 暂存输出的最大字节数，超过后立即开始向客户端输出
+	StreamFailoverBufferMaxBytes int `mapstructure:"stream_failover_buffer_max_bytes"`
+	// CancelUpstreamOnClientDisconnect: 客户端中途断开时立即取消上游流（默认继续读取上游以获取完整 usage），
+	// 取消后按已收到的内容记录部分 usage（缺失的 output tokens 按已输出内容估算）
+	CancelUpstreamOnClientDisconnect bool `mapstructure:"cancel_upstream_on_client_disconnect"`
 	// StreamKeepaliveInterval: 流式 keepalive 间隔（秒），0表示禁用
 	StreamKeepaliveInterval int `mapstructure:"stream_keepalive_interval"`
+	// ToolArgsCoalesceMs: 协议转换流中工具调用参数增量的合并窗口（毫秒），0表示不按时间合并
+	// 部分客户端（如 LangChain）难以处理过细的参数增量，合并后按窗口/字节阈值批量输出
+	ToolArgsCoalesceMs int `mapstructure:"tool_args_coalesce_ms"`
+	// ToolArgsCoalesceBytes: 工具调用参数增量累计达到该字节数时立即输出，0表示不按字节合并
+	ToolArgsCoalesceBytes int `mapstructure:"tool_args_coalesce_bytes"`
 	// MaxLineSize: 上游 SSE 单行最大字节数（0使用默认值）
 	MaxLineSize int `mapstructure:"max_line_size"`
 
@@ -666,6 +842,15 @@ type GatewayConfig struct {
 	// UsageRecord: 使用量记录异步队列配置（有界队列 + 固定 worker）
 	UsageRecord GatewayUsageRecordConfig `mapstructure:"usage_record"`
 
+	// ConversationStore: 服务端会话存储（为无状态客户端按会话 ID 补全历史消息）
+	ConversationStore GatewayConversationStoreConfig `mapstructure:"conversation_store"`
+	// Idempotency: 非流式请求的 Idempotency-Key 响应重放
+	Idempotency GatewayIdempotencyConfig `mapstructure:"idempotency"`
+	// UserAccounts: 用户自助绑定上游账号（BYO account）
+	UserAccounts GatewayUserAccountsConfig `mapstructure:"user_accounts"`
+	// MessageBatch: Claude Messages Batches 接口模拟
+	MessageBatch GatewayMessageBatchConfig `mapstructure:"message_batch"`
+
 	// UserGroupRateCacheTTLSeconds: 用户分组倍率热路径缓存 TTL（秒）
 	UserGroupRateCacheTTLSeconds int `mapstructure:"user_group_rate_cache_ttl_seconds"`
 	// ModelsListCacheTTLSeconds: /v1/models 模型列表短缓存 TTL（秒）
@@ -674,6 +859,222 @@ type GatewayConfig struct {
 	// UserMessageQueue: 用户消息串行队列配置
 	// 对 role:"user" 的真实用户消息实施账号级串行化 + RPM 自适应延迟
 	UserMessageQueue UserMessageQueueConfig `mapstructure:"user_message_queue"`
+
+	// CostAttribution: 单次请求费用归因响应头配置
+	CostAttribution GatewayCostAttributionConfig `mapstructure:"cost_attribution"`
+
+	// CacheStatus: Anthropic 提示缓存命中情况响应头配置
+	CacheStatus GatewayCacheStatusConfig `mapstructure:"cache_status"`
+
+	// SecretLeakGuard: 响应内容密钥泄露防护（替换返回文本中的网关凭证）
+	SecretLeakGuard GatewaySecretLeakGuardConfig `mapstructure:"secret_leak_guard"`
+
+	// StickySessionStore: 粘性会话与 response_id 绑定的存储后端
+	StickySessionStore GatewayStickySessionStoreConfig `mapstructure:"sticky_session_store"`
+
+	// UsageBillingDedup: 记账幂等键的 Redis 预检（跨副本/重试防重复扣费）
+	UsageBillingDedup GatewayUsageBillingDedupConfig `mapstructure:"usage_billing_dedup"`
+
+	// PriorityQueue: 并发等待队列优先级配置
+	PriorityQueue GatewayPriorityQueueConfig `mapstructure:"priority_queue"`
+
+	// PIIRedaction: 分组敏感信息过滤使用的匹配规则（是否启用由分组 pii_redaction_mode 决定）
+	PIIRedaction GatewayPIIRedactionConfig `mapstructure:"pii_redaction"`
+
+	// Moderation: 转发前内容审核配置（是否启用由分组 moderation_enabled / API Key moderation_mode 决定）
+	Moderation GatewayModerationConfig `mapstructure:"moderation"`
+
+	// RegionSelection: 多区域上游 base URL 的延迟探测与故障切换（仅对配置了 credentials.base_urls 的账号生效）
+	RegionSelection GatewayRegionSelectionConfig `mapstructure:"region_selection"`
+
+	// ProxyHealth: 代理健康检查、账号备用代理切换与代理并发上限
+	ProxyHealth GatewayProxyHealthConfig `mapstructure:"proxy_health"`
+
+	// ToolResultLimit: 超大工具调用结果（tool_result / function_call_output）转发前的截断策略
+	ToolResultLimit GatewayToolResultLimitConfig `mapstructure:"tool_result_limit"`
+
+	// ImageLimit: 内联 base64 图片转发前的格式校验与超大图片缩放压缩
+	ImageLimit GatewayImageLimitConfig `mapstructure:"image_limit"`
+
+	// RequestValidation: 转发前按端点对请求体做严格校验，返回精确到字段的错误
+	RequestValidation GatewayRequestValidationConfig `mapstructure:"request_validation"`
+
+	// MockUpstream: 压测模式，所有上游请求由内置模拟上游应答，不消耗真实 token
+	MockUpstream GatewayMockUpstreamConfig `mapstructure:"mock_upstream"`
+}
+
+// GatewayMockUpstreamConfig 模拟上游配置（压测模式）。
+// 启用后网关不再连接任何真实上游，并发控制、等待队列与用量记录链路保持不变。
+type GatewayMockUpstreamConfig struct {
+	// Enabled: 是否启用模拟上游（仅用于压测，切勿在生产环境开启）
+	Enabled bool `mapstructure:"enabled"`
+	// FirstTokenLatencyMs: 首 token 延迟（毫秒）
+	FirstTokenLatencyMs int `mapstructure:"first_token_latency_ms"`
+	// TokensPerSecond: 输出速率（token/秒），0 表示不限速
+	TokensPerSecond int `mapstructure:"tokens_per_second"`
+	// OutputTokens: 每次响应的输出 token 数（请求的 max_tokens 更小时以请求为准）
+	OutputTokens int `mapstructure:"output_tokens"`
+	// ErrorRate: 模拟上游 503 错误的概率（0-1）
+	ErrorRate float64 `mapstructure:"error_rate"`
+}
+
+// GatewayBodySizeLimitsConfig 按端点类别的请求体上限（字节），0 表示使用 gateway.max_body_size。
+// 分组与 API Key 可再单独覆盖，但都不会超过 server.max_request_body_size 这一全局硬上限。
+type GatewayBodySizeLimitsConfig struct {
+	// Chat: 对话类端点（messages / chat completions / responses / Gemini）
+	Chat int64 `mapstructure:"chat"`
+	// Images: 图片生成与编辑端点
+	Images int64 `mapstructure:"images"`
+}
+
+// GatewayRequestValidationConfig 请求体校验配置
+type GatewayRequestValidationConfig struct {
+	// Enabled: 是否启用请求体校验（Anthropic Messages / Chat Completions / Responses）
+	Enabled bool `mapstructure:"enabled"`
+	// AllowUnknownFields: 未建模的顶层字段、内容块类型与输入项类型直接透传而不报错
+	AllowUnknownFields bool `mapstructure:"allow_unknown_fields"`
+}
+
+// GatewayImageLimitConfig 内联图片处理配置
+type GatewayImageLimitConfig struct {
+	// AllowedMediaTypes: 允许的图片类型，为空表示不校验
+	AllowedMediaTypes []string `mapstructure:"allowed_media_types"`
+	// MaxBytes: 单张图片解码后的最大字节数，超过时缩放并重新压缩为 JPEG；0 表示不处理
+	MaxBytes int `mapstructure:"max_bytes"`
+	// MaxDimension: 缩放后图片长边的最大像素数
+	MaxDimension int `mapstructure:"max_dimension"`
+	// JPEGQuality: 重新压缩时使用的初始 JPEG 质量（1-100），仍超限时逐步降低
+	JPEGQuality int `mapstructure:"jpeg_quality"`
+}
+
+// GatewayToolResultLimitConfig 工具调用结果大小限制配置
+type GatewayToolResultLimitConfig struct {
+	// MaxBytes: 单个工具调用结果文本的最大字节数，0 表示不限制
+	MaxBytes int `mapstructure:"max_bytes"`
+	// Mode: 超限处理方式：head_tail 保留开头与结尾（默认），truncate 仅保留开头
+	Mode string `mapstructure:"mode"`
+}
+
+// GatewayProxyHealthConfig 代理健康检查配置
+type GatewayProxyHealthConfig struct {
+	// CheckIntervalSeconds: 主动探测所有活跃代理的周期（秒），0 表示仅根据真实请求的连接错误判定
+	CheckIntervalSeconds int `mapstructure:"check_interval_seconds"`
+	// UnhealthyCooldownSeconds: 请求中出现代理连接错误后暂停优先使用该代理的时长（秒）
+	UnhealthyCooldownSeconds int `mapstructure:"unhealthy_cooldown_seconds"`
+}
+
+// GatewayRegionSelectionConfig 多区域上游选择配置
+type GatewayRegionSelectionConfig struct {
+	// ProbeIntervalSeconds: 区域延迟探测周期（秒），0 表示仅使用真实请求的延迟
+	ProbeIntervalSeconds int `mapstructure:"probe_interval_seconds"`
+	// ProbeTimeoutSeconds: 单次探测超时（秒）
+	ProbeTimeoutSeconds int `mapstructure:"probe_timeout_seconds"`
+	// UnhealthyCooldownSeconds: 区域失败后暂停选择的时长（秒）
+	UnhealthyCooldownSeconds int `mapstructure:"unhealthy_cooldown_seconds"`
+}
+
+// GatewayModerationConfig 内容审核配置
+// 支持 OpenAI /moderations 兼容端点与本地正则规则，两者可同时配置（本地规则先执行）
+type GatewayModerationConfig struct {
+	// Endpoint: OpenAI /moderations 兼容端点完整 URL，为空表示仅使用本地规则
+	Endpoint string `mapstructure:"endpoint"`
+	// APIKey: 审核端点的 Bearer Token
+	APIKey string `mapstructure:"api_key"`
+	// Model: 审核模型（默认 omni-moderation-latest）
+	Model string `mapstructure:"model"`
+	// TimeoutSeconds: 审核请求超时（秒）
+	TimeoutSeconds int `mapstructure:"timeout_seconds"`
+	// FailOpen: 审核端点不可用时是否放行请求（默认 true）
+	FailOpen bool `mapstructure:"fail_open"`
+	// BlockedCategories: 仅拦截这些类别；为空时按端点返回的 flagged 拦截
+	BlockedCategories []string `mapstructure:"blocked_categories"`
+	// LocalRules: 本地正则规则，命中即拦截（类别名为规则名）
+	LocalRules []GatewayModerationRule `mapstructure:"local_rules"`
+}
+
+// GatewayModerationRule 本地内容审核规则
+type GatewayModerationRule struct {
+	Name  string `mapstructure:"name"`
+	Regex string `mapstructure:"regex"`
+}
+
+// GatewayPIIRedactionConfig 敏感信息过滤规则配置
+type GatewayPIIRedactionConfig struct {
+	// Patterns: 自定义匹配规则，为空时使用内置规则（email / api_key / credit_card）
+	Patterns []GatewayPIIRedactionPattern `mapstructure:"patterns"`
+}
+
+// GatewayPIIRedactionPattern 单条敏感信息匹配规则
+type GatewayPIIRedactionPattern struct {
+	// Name: 规则名称，用于日志与默认替换文本
+	Name string `mapstructure:"name"`
+	// Regex: Go RE2 正则表达式
+	Regex string `mapstructure:"regex"`
+	// Replacement: 脱敏替换文本（默认 [REDACTED_<NAME>]）
+	Replacement string `mapstructure:"replacement"`
+}
+
+// GatewayPriorityQueueConfig 并发等待队列优先级配置
+// 启用后，用户/账号并发槽位紧张时按 API Key/分组优先级（high > normal > low）服务等待者，而非 FIFO
+type GatewayPriorityQueueConfig struct {
+	// Enabled: 是否启用（默认关闭，关闭时无额外 Redis 开销）
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// GatewayCostAttributionConfig 单次请求费用归因配置
+// 非流式响应通过响应头返回，流式响应在流末尾追加 SSE 注释行
+type GatewayCostAttributionConfig struct {
+	// Enabled: 是否启用（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// CostHeader: 费用响应头名称（默认 X-Sub2api-Cost）
+	CostHeader string `mapstructure:"cost_header"`
+	// TokensHeader: token 数响应头名称（默认 X-Sub2api-Tokens）
+	TokensHeader string `mapstructure:"tokens_header"`
+	// StreamComment: 流式响应是否在末尾追加 SSE 注释（默认 true）
+	StreamComment bool `mapstructure:"stream_comment"`
+}
+
+// GatewayCacheStatusConfig 提示缓存命中情况配置
+// 非流式响应通过响应头返回 cache_read / cache_creation token 数与命中率，流式响应在流末尾追加 SSE 注释行
+type GatewayCacheStatusConfig struct {
+	// Enabled: 是否启用（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// StreamComment: 流式响应是否在末尾追加 SSE 注释（默认 true）
+	StreamComment bool `mapstructure:"stream_comment"`
+}
+
+// GatewaySecretLeakGuardConfig 响应内容密钥泄露防护配置
+// 扫描返回给客户端的文本（含流式增量），替换网关持有的上游账号凭证、JWT 密钥及自定义正则命中内容
+type GatewaySecretLeakGuardConfig struct {
+	// Enabled: 是否启用（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// IncludeAccountCredentials: 是否将所有启用账号的 API Key/访问令牌纳入匹配（默认 true）
+	IncludeAccountCredentials bool `mapstructure:"include_account_credentials"`
+	// Patterns: 额外的正则匹配规则
+	Patterns []GatewayPIIRedactionPattern `mapstructure:"patterns"`
+	// Replacement: 凭证替换文本（默认 [REDACTED_SECRET]）
+	Replacement string `mapstructure:"replacement"`
+	// RefreshIntervalSeconds: 账号凭证快照刷新间隔（秒，默认 300）
+	RefreshIntervalSeconds int `mapstructure:"refresh_interval_seconds"`
+}
+
+// GatewayStickySessionStoreConfig 粘性会话存储配置
+type GatewayStickySessionStoreConfig struct {
+	// Backend: 粘性会话、response_id 绑定与 Codex turn state 的存储后端
+	// - 留空（默认）：跟随 concurrency.backend
+	// - "redis"：多副本共享，副本重启后绑定不丢失；各副本本地热缓存通过 Redis Pub/Sub 失效
+	// - "memory"：进程内存储，仅适用于单实例
+	Backend string `mapstructure:"backend"`
+}
+
+// GatewayUsageBillingDedupConfig 记账幂等预检配置
+// 以 (request_id, account_id, attempt) 派生的幂等键在 Redis 中占位，重复记录在进入数据库事务前即被拦截；
+// 数据库 usage_billing_dedup 表仍是最终的幂等保证
+type GatewayUsageBillingDedupConfig struct {
+	// Enabled: 是否启用（默认 true；concurrency.backend=memory 时不生效）
+	Enabled bool `mapstructure:"enabled"`
+	// TTLSeconds: 记账完成后幂等键的保留时间（秒，默认 86400）
+	TTLSeconds int `mapstructure:"ttl_seconds"`
 }
 
 // UserMessageQueueConfig 用户消息串行队列配置
@@ -844,6 +1245,76 @@ type GatewayUsageRecordConfig struct {
 	AutoScaleCheckIntervalSeconds int `mapstructure:"auto_scale_check_interval_seconds"`
 	// AutoScaleCooldownSeconds: 自动扩缩容冷却时间（秒）
 	AutoScaleCooldownSeconds int `mapstructure:"auto_scale_cooldown_seconds"`
+
+	// BackpressureQueuePercent: 队列占用率达到该阈值时记录背压告警（0 表示关闭）
+	BackpressureQueuePercent int `mapstructure:"backpressure_queue_percent"`
+	// JournalEnabled: 是否启用本地溢出日志（入库失败/队列溢出的记录落盘，待数据库恢复后回放）
+	JournalEnabled bool `mapstructure:"journal_enabled"`
+	// JournalPath: 本地溢出日志文件路径（JSONL）
+	JournalPath string `mapstructure:"journal_path"`
+	// JournalReplayIntervalSeconds: 溢出日志回放间隔（秒）
+	JournalReplayIntervalSeconds int `mapstructure:"journal_replay_interval_seconds"`
+}
+
+// GatewayConversationStoreConfig 服务端会话存储配置。
+// 客户端通过 X-Conversation-ID 请求头标识会话，网关记录每轮消息，
+// 后续请求只需携带最新消息，网关在转发前补全完整历史（Chat Completions / Messages）。
+type GatewayConversationStoreConfig struct {
+	// Enabled: 是否启用会话存储（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// TTLSeconds: 会话空闲过期时间（秒），每次写入刷新
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// MaxMessages: 单个会话保留的最大消息数，超出时丢弃最早的轮次
+	MaxMessages int `mapstructure:"max_messages"`
+	// MaxBytes: 单个会话历史的最大字节数，超出时丢弃最早的轮次
+	MaxBytes int `mapstructure:"max_bytes"`
+}
+
+// GatewayIdempotencyConfig 网关幂等重放配置。
+// 非流式请求携带 Idempotency-Key 时，成功响应（状态码、部分响应头、响应体）按 API Key 缓存，
+// TTL 内相同请求的重试直接返回缓存结果而不再转发上游，避免客户端网络重试导致重复计费。
+type GatewayIdempotencyConfig struct {
+	// Enabled: 是否处理 Idempotency-Key 请求头（默认开启；未携带请求头的请求不受影响）
+	Enabled bool `mapstructure:"enabled"`
+	// TTLSeconds: 成功响应的缓存时间（秒）
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// ProcessingTimeoutSeconds: 首个请求处理中的占位锁超时（秒），应覆盖上游最长响应时间
+	ProcessingTimeoutSeconds int `mapstructure:"processing_timeout_seconds"`
+	// MaxResponseBytes: 可缓存的最大响应体字节数，超出时不缓存（重试会再次转发）
+	MaxResponseBytes int `mapstructure:"max_response_bytes"`
+}
+
+// GatewayMessageBatchConfig Claude Messages Batches 接口模拟配置。
+// 网关接收 Anthropic 批处理格式，将每条请求按受控并发逐条走 /v1/messages 常规链路（调度、限流、计费不变），
+// 结果存储在数据库中供 results 接口下载，使无批处理权限的 OAuth 账号池也能服务批处理客户端。
+type GatewayMessageBatchConfig struct {
+	// Enabled: 是否启用批处理接口（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// Concurrency: 每个实例同时执行的批处理子请求数
+	Concurrency int `mapstructure:"concurrency"`
+	// MaxRequests: 单个批次最多包含的请求数
+	MaxRequests int `mapstructure:"max_requests"`
+	// RequestTimeoutSeconds: 单条子请求的执行超时（秒）
+	RequestTimeoutSeconds int `mapstructure:"request_timeout_seconds"`
+	// MaxAttempts: 子请求遇到 429/5xx/529 时的最大尝试次数（含首次）
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// ExpireHours: 批次创建后未处理完的请求在该时长后标记为 expired（小时）
+	ExpireHours int `mapstructure:"expire_hours"`
+	// RetentionHours: 已结束批次及结果的保留时长（小时），超出后删除
+	RetentionHours int `mapstructure:"retention_hours"`
+}
+
+// GatewayUserAccountsConfig 用户自助绑定账号配置。
+// 自助绑定的账号仅对归属用户的 API Key 可调度，并优先于分组内的共享账号。
+type GatewayUserAccountsConfig struct {
+	// Enabled: 是否允许普通用户自助绑定账号（默认关闭）
+	Enabled bool `mapstructure:"enabled"`
+	// MaxPerUser: 单个用户最多可绑定的账号数
+	MaxPerUser int `mapstructure:"max_per_user"`
+	// AllowedPlatforms: 允许自助绑定的平台，为空表示不限制
+	AllowedPlatforms []string `mapstructure:"allowed_platforms"`
+	// Concurrency: 自助绑定账号的并发上限
+	Concurrency int `mapstructure:"concurrency"`
 }
 
 // TLSFingerprintConfig TLS指纹伪装配置
@@ -931,6 +1402,9 @@ type GatewaySchedulingConfig struct {
 	// 全量重建周期配置
 	// 全量重建周期（秒），0 表示禁用
 	FullRebuildIntervalSeconds int `mapstructure:"full_rebuild_interval_seconds"`
+
+	// 路由脚本（expr 表达式），在负载感知调度时对候选账号排序或否决；为空表示不启用
+	RoutingScript string `mapstructure:"routing_script"`
 }
 
 func (s *ServerConfig) Address() string {
@@ -1033,6 +1507,13 @@ type OpsConfig struct {
 
 	// Pre-aggregation configuration.
 	Aggregation OpsAggregationConfig `mapstructure:"aggregation"`
+
+	// UsageAnomaly controls the background detector that flags API keys whose
+	// hourly token usage spikes beyond their trailing baseline (e.g. leaked keys).
+	UsageAnomaly OpsUsageAnomalyConfig `mapstructure:"usage_anomaly"`
+
+	// Profiling controls the admin-only pprof endpoints and temporary log level overrides.
+	Profiling OpsProfilingConfig `mapstructure:"profiling"`
 }
 
 type OpsCleanupConfig struct {
@@ -1051,6 +1532,32 @@ type OpsAggregationConfig struct {
 	Enabled bool `mapstructure:"enabled"`
 }
 
+type OpsUsageAnomalyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// CheckIntervalMinutes: 检测周期（分钟）
+	CheckIntervalMinutes int `mapstructure:"check_interval_minutes"`
+	// BaselineHours: 基线窗口（小时），取最近一小时之前该窗口内的平均每小时 token 用量
+	BaselineHours int `mapstructure:"baseline_hours"`
+	// SpikeFactor: 最近一小时用量超过基线均值的倍数时告警
+	SpikeFactor float64 `mapstructure:"spike_factor"`
+	// MinTokens: 最近一小时 token 用量低于该值时不告警，避免低用量 Key 的噪音
+	MinTokens int64 `mapstructure:"min_tokens"`
+	// MaxKeys: 每轮最多检测的 API Key 数（按最近一小时用量降序）
+	MaxKeys int `mapstructure:"max_keys"`
+	// CooldownMinutes: 同一 API Key 两次告警的最小间隔（分钟）
+	CooldownMinutes int `mapstructure:"cooldown_minutes"`
+}
+
+// OpsProfilingConfig 管理端性能剖析配置
+type OpsProfilingConfig struct {
+	// Enabled: 是否开放管理端 pprof 与临时日志级别调整接口（仍需管理员认证）
+	Enabled bool `mapstructure:"enabled"`
+	// MaxProfileSeconds: CPU profile / trace 单次采样的最长时长（秒）
+	MaxProfileSeconds int `mapstructure:"max_profile_seconds"`
+	// MaxLogLevelOverrideMinutes: 临时日志级别覆盖的最长持续时间（分钟），到期自动恢复
+	MaxLogLevelOverrideMinutes int `mapstructure:"max_log_level_override_minutes"`
+}
+
 type OpsMetricsCollectorCacheConfig struct {
 	Enabled bool          `mapstructure:"enabled"`
 	TTL     time.Duration `mapstructure:"ttl"`
@@ -1159,6 +1666,8 @@ type DashboardAggregationRetentionConfig struct {
 	UsageBillingDedupDays int `mapstructure:"usage_billing_dedup_days"`
 	HourlyDays            int `mapstructure:"hourly_days"`
 	DailyDays             int `mapstructure:"daily_days"`
+	// SummaryDays: 维度日汇总（按用户/Key/账号/分组/模型）保留天数，0 表示永久保留；原始日志清理前会先归档到该表
+	SummaryDays int `mapstructure:"summary_days"`
 }
 
 // UsageCleanupConfig 使用记录清理任务配置
@@ -1276,6 +1785,9 @@ func load(allowMissingJWTSecret bool) (*Config, error) {
 	cfg.OIDC.ValidateIDTokenExplicit = hasExplicitConfigOrEnv("oidc_connect.validate_id_token", "OIDC_CONNECT_VALIDATE_ID_TOKEN")
 	cfg.Dashboard.KeyPrefix = strings.TrimSpace(cfg.Dashboard.KeyPrefix)
 	cfg.CORS.AllowedOrigins = normalizeStringSlice(cfg.CORS.AllowedOrigins)
+	cfg.CORS.Gateway.AllowedOrigins = normalizeStringSlice(cfg.CORS.Gateway.AllowedOrigins)
+	cfg.CORS.Gateway.AllowedHeaders = normalizeStringSlice(cfg.CORS.Gateway.AllowedHeaders)
+	cfg.CORS.Gateway.ExposeHeaders = normalizeStringSlice(cfg.CORS.Gateway.ExposeHeaders)
 	cfg.Security.ResponseHeaders.AdditionalAllowed = normalizeStringSlice(cfg.Security.ResponseHeaders.AdditionalAllowed)
 	cfg.Security.ResponseHeaders.ForceRemove = normalizeStringSlice(cfg.Security.ResponseHeaders.ForceRemove)
 	cfg.Security.CSP.Policy = strings.TrimSpace(cfg.Security.CSP.Policy)
@@ -1398,6 +1910,12 @@ func setDefaults() {
 	// CORS
 	viper.SetDefault("cors.allowed_origins", []string{})
 	viper.SetDefault("cors.allow_credentials", true)
+	viper.SetDefault("cors.gateway.enabled", false)
+	viper.SetDefault("cors.gateway.allowed_origins", []string{})
+	viper.SetDefault("cors.gateway.allow_credentials", false)
+	viper.SetDefault("cors.gateway.allowed_headers", []string{})
+	viper.SetDefault("cors.gateway.expose_headers", []string{"x-request-id", "request-id", "retry-after"})
+	viper.SetDefault("cors.gateway.max_age_seconds", 600)
 
 	// Security
 	viper.SetDefault("security.url_allowlist.enabled", false)
@@ -1426,12 +1944,20 @@ func setDefaults() {
 
 	// Security - disable direct fallback on proxy error
 	viper.SetDefault("security.proxy_fallback.allow_direct_on_error", false)
+	viper.SetDefault("security.credential_encryption.enabled", false)
+	viper.SetDefault("security.credential_encryption.master_key", "")
+	viper.SetDefault("security.request_signing.enabled", false)
+	viper.SetDefault("security.request_signing.max_clock_skew_seconds", 300)
 
 	// Billing
 	viper.SetDefault("billing.circuit_breaker.enabled", true)
 	viper.SetDefault("billing.circuit_breaker.failure_threshold", 5)
 	viper.SetDefault("billing.circuit_breaker.reset_timeout_seconds", 30)
 	viper.SetDefault("billing.circuit_breaker.half_open_requests", 3)
+	viper.SetDefault("billing.db_outage.mode", BillingDBOutageModeFailClosed)
+	viper.SetDefault("billing.db_outage.probe_interval_seconds", 5)
+	viper.SetDefault("billing.db_outage.probe_timeout_seconds", 2)
+	viper.SetDefault("billing.db_outage.failure_threshold", 3)
 
 	// Turnstile
 	viper.SetDefault("turnstile.required", false)
@@ -1531,6 +2057,16 @@ func setDefaults() {
 	viper.SetDefault("ops.metrics_collector_cache.enabled", true)
 	// TTL should be slightly larger than collection interval (1m) to maximize cross-replica cache hits.
 	viper.SetDefault("ops.metrics_collector_cache.ttl", 65*time.Second)
+	viper.SetDefault("ops.usage_anomaly.enabled", false)
+	viper.SetDefault("ops.usage_anomaly.check_interval_minutes", 10)
+	viper.SetDefault("ops.usage_anomaly.baseline_hours", 24)
+	viper.SetDefault("ops.usage_anomaly.spike_factor", 5.0)
+	viper.SetDefault("ops.usage_anomaly.min_tokens", 100000)
+	viper.SetDefault("ops.usage_anomaly.max_keys", 200)
+	viper.SetDefault("ops.usage_anomaly.cooldown_minutes", 60)
+	viper.SetDefault("ops.profiling.enabled", true)
+	viper.SetDefault("ops.profiling.max_profile_seconds", 60)
+	viper.SetDefault("ops.profiling.max_log_level_override_minutes", 120)
 
 	// JWT
 	viper.SetDefault("jwt.secret", "")
@@ -1597,6 +2133,7 @@ func setDefaults() {
 	viper.SetDefault("dashboard_aggregation.retention.usage_billing_dedup_days", 365)
 	viper.SetDefault("dashboard_aggregation.retention.hourly_days", 180)
 	viper.SetDefault("dashboard_aggregation.retention.daily_days", 730)
+	viper.SetDefault("dashboard_aggregation.retention.summary_days", 730)
 	viper.SetDefault("dashboard_aggregation.recompute_days", 2)
 
 	// Usage cleanup task
@@ -1616,6 +2153,20 @@ func setDefaults() {
 	viper.SetDefault("idempotency.cleanup_interval_seconds", 60)
 	viper.SetDefault("idempotency.cleanup_batch_size", 500)
 
+	// Usage event export
+	viper.SetDefault("usage_export.enabled", false)
+	viper.SetDefault("usage_export.backend", UsageExportBackendKafka)
+	viper.SetDefault("usage_export.queue_size", 10000)
+	viper.SetDefault("usage_export.batch_size", 100)
+	viper.SetDefault("usage_export.flush_interval_ms", 500)
+	viper.SetDefault("usage_export.max_attempts", 3)
+	viper.SetDefault("usage_export.retry_backoff_ms", 200)
+	viper.SetDefault("usage_export.publish_timeout_seconds", 10)
+	viper.SetDefault("usage_export.dead_letter_redrive_interval_seconds", 60)
+	viper.SetDefault("usage_export.dead_letter_batch_size", 500)
+	viper.SetDefault("usage_export.kafka.topic", "sub2api.usage")
+	viper.SetDefault("usage_export.nats.subject", "sub2api.usage")
+
 	// Gateway
 	viper.SetDefault("gateway.response_header_timeout", 600) // 600秒(10分钟)等待上游响应头，LLM高负载时可能排队较久
 	viper.SetDefault("gateway.log_upstream_error_body", true)
@@ -1675,6 +2226,8 @@ func setDefaults() {
 	viper.SetDefault("gateway.antigravity_fallback_cooldown_minutes", 1)
 	viper.SetDefault("gateway.antigravity_extra_retries", 10)
 	viper.SetDefault("gateway.max_body_size", int64(256*1024*1024))
+	viper.SetDefault("gateway.token_bucket_max_wait_seconds", 30)
+	viper.SetDefault("gateway.upstream_request_id_header", "")
 	viper.SetDefault("gateway.upstream_response_read_max_bytes", DefaultUpstreamResponseReadMaxBytes)
 	viper.SetDefault("gateway.proxy_probe_response_read_max_bytes", int64(1024*1024))
 	viper.SetDefault("gateway.gemini_debug_response_headers", false)
@@ -1688,8 +2241,27 @@ func setDefaults() {
 	viper.SetDefault("gateway.client_idle_ttl_seconds", 900)
 	viper.SetDefault("gateway.concurrency_slot_ttl_minutes", 30) // 并发槽位过期时间（支持超长请求）
 	viper.SetDefault("gateway.stream_data_interval_timeout", 180)
+	viper.SetDefault("gateway.stream_timeout_failover", false)
+	viper.SetDefault("gateway.stream_failover_buffer", false)
+	viper.SetDefault("gateway.stream_failover_buffer_max_bytes", 64*1024)
+	viper.SetDefault("gateway.cancel_upstream_on_client_disconnect", false)
 	viper.SetDefault("gateway.stream_keepalive_interval", 10)
+	viper.SetDefault("gateway.tool_args_coalesce_ms", 0)
+	viper.SetDefault("gateway.tool_args_coalesce_bytes", 0)
 	viper.SetDefault("gateway.max_line_size", 500*1024*1024)
+	viper.SetDefault("gateway.cost_attribution.enabled", false)
+	viper.SetDefault("gateway.cost_attribution.cost_header", "X-Sub2api-Cost")
+	viper.SetDefault("gateway.cost_attribution.tokens_header", "X-Sub2api-Tokens")
+	viper.SetDefault("gateway.cost_attribution.stream_comment", true)
+	viper.SetDefault("gateway.cache_status.enabled", false)
+	viper.SetDefault("gateway.cache_status.stream_comment", true)
+	viper.SetDefault("gateway.secret_leak_guard.enabled", false)
+	viper.SetDefault("gateway.secret_leak_guard.include_account_credentials", true)
+	viper.SetDefault("gateway.secret_leak_guard.replacement", "[REDACTED_SECRET]")
+	viper.SetDefault("gateway.secret_leak_guard.refresh_interval_seconds", 300)
+	viper.SetDefault("gateway.sticky_session_store.backend", "")
+	viper.SetDefault("gateway.usage_billing_dedup.enabled", true)
+	viper.SetDefault("gateway.usage_billing_dedup.ttl_seconds", 86400)
 	viper.SetDefault("gateway.scheduling.sticky_session_max_waiting", 3)
 	viper.SetDefault("gateway.scheduling.sticky_session_wait_timeout", 120*time.Second)
 	viper.SetDefault("gateway.scheduling.fallback_wait_timeout", 30*time.Second)
@@ -1708,6 +2280,26 @@ func setDefaults() {
 	viper.SetDefault("gateway.scheduling.outbox_lag_rebuild_failures", 3)
 	viper.SetDefault("gateway.scheduling.outbox_backlog_rebuild_rows", 10000)
 	viper.SetDefault("gateway.scheduling.full_rebuild_interval_seconds", 300)
+	viper.SetDefault("gateway.scheduling.routing_script", "")
+	viper.SetDefault("gateway.conversation_store.enabled", false)
+	viper.SetDefault("gateway.conversation_store.ttl_seconds", 86400)
+	viper.SetDefault("gateway.conversation_store.max_messages", 200)
+	viper.SetDefault("gateway.conversation_store.max_bytes", 4*1024*1024)
+	viper.SetDefault("gateway.idempotency.enabled", true)
+	viper.SetDefault("gateway.idempotency.ttl_seconds", 3600)
+	viper.SetDefault("gateway.idempotency.processing_timeout_seconds", 600)
+	viper.SetDefault("gateway.idempotency.max_response_bytes", 1024*1024)
+	viper.SetDefault("gateway.user_accounts.enabled", false)
+	viper.SetDefault("gateway.message_batch.enabled", false)
+	viper.SetDefault("gateway.message_batch.concurrency", 4)
+	viper.SetDefault("gateway.message_batch.max_requests", 10000)
+	viper.SetDefault("gateway.message_batch.request_timeout_seconds", 600)
+	viper.SetDefault("gateway.message_batch.max_attempts", 3)
+	viper.SetDefault("gateway.message_batch.expire_hours", 24)
+	viper.SetDefault("gateway.message_batch.retention_hours", 29*24)
+	viper.SetDefault("gateway.user_accounts.max_per_user", 5)
+	viper.SetDefault("gateway.user_accounts.allowed_platforms", []string{})
+	viper.SetDefault("gateway.user_accounts.concurrency", 3)
 	viper.SetDefault("gateway.usage_record.worker_count", 128)
 	viper.SetDefault("gateway.usage_record.queue_size", 16384)
 	viper.SetDefault("gateway.usage_record.task_timeout_seconds", 5)
@@ -1722,10 +2314,40 @@ func setDefaults() {
 	viper.SetDefault("gateway.usage_record.auto_scale_down_step", 16)
 	viper.SetDefault("gateway.usage_record.auto_scale_check_interval_seconds", 3)
 	viper.SetDefault("gateway.usage_record.auto_scale_cooldown_seconds", 10)
+	viper.SetDefault("gateway.usage_record.backpressure_queue_percent", 80)
+	viper.SetDefault("gateway.usage_record.journal_enabled", true)
+	viper.SetDefault("gateway.usage_record.journal_path", "./data/usage_record_journal.jsonl")
+	viper.SetDefault("gateway.usage_record.journal_replay_interval_seconds", 30)
 	viper.SetDefault("gateway.user_group_rate_cache_ttl_seconds", 30)
 	viper.SetDefault("gateway.models_list_cache_ttl_seconds", 15)
 	// TLS指纹伪装配置（默认关闭，需要账号级别单独启用）
 	// 用户消息串行队列默认值
+	viper.SetDefault("gateway.priority_queue.enabled", false)
+	viper.SetDefault("gateway.moderation.endpoint", "")
+	viper.SetDefault("gateway.moderation.api_key", "")
+	viper.SetDefault("gateway.moderation.model", "omni-moderation-latest")
+	viper.SetDefault("gateway.moderation.timeout_seconds", 5)
+	viper.SetDefault("gateway.moderation.fail_open", true)
+	viper.SetDefault("gateway.region_selection.probe_interval_seconds", 30)
+	viper.SetDefault("gateway.region_selection.probe_timeout_seconds", 5)
+	viper.SetDefault("gateway.region_selection.unhealthy_cooldown_seconds", 30)
+	viper.SetDefault("gateway.proxy_health.check_interval_seconds", 60)
+	viper.SetDefault("gateway.proxy_health.unhealthy_cooldown_seconds", 60)
+	viper.SetDefault("gateway.tool_result_limit.max_bytes", 0)
+	viper.SetDefault("gateway.tool_result_limit.mode", "head_tail")
+	viper.SetDefault("gateway.image_limit.allowed_media_types", []string{"image/jpeg", "image/png", "image/gif", "image/webp"})
+	viper.SetDefault("gateway.image_limit.max_bytes", 0)
+	viper.SetDefault("gateway.image_limit.max_dimension", 2048)
+	viper.SetDefault("gateway.image_limit.jpeg_quality", 85)
+	viper.SetDefault("gateway.request_validation.enabled", false)
+	viper.SetDefault("gateway.body_size_limits.chat", int64(0))
+	viper.SetDefault("gateway.body_size_limits.images", int64(0))
+	viper.SetDefault("gateway.request_validation.allow_unknown_fields", true)
+	viper.SetDefault("gateway.mock_upstream.enabled", false)
+	viper.SetDefault("gateway.mock_upstream.first_token_latency_ms", 500)
+	viper.SetDefault("gateway.mock_upstream.tokens_per_second", 50)
+	viper.SetDefault("gateway.mock_upstream.output_tokens", 256)
+	viper.SetDefault("gateway.mock_upstream.error_rate", 0.0)
 	viper.SetDefault("gateway.user_message_queue.enabled", false)
 	viper.SetDefault("gateway.user_message_queue.lock_ttl_ms", 120000)
 	viper.SetDefault("gateway.user_message_queue.wait_timeout_ms", 30000)
@@ -1735,6 +2357,9 @@ func setDefaults() {
 
 	viper.SetDefault("gateway.tls_fingerprint.enabled", true)
 	viper.SetDefault("concurrency.ping_interval", 10)
+	viper.SetDefault("concurrency.backend", ConcurrencyBackendRedis)
+	viper.SetDefault("concurrency.max_slot_hold_seconds", 1200)
+	viper.SetDefault("concurrency.slot_reaper_interval_seconds", 60)
 
 	// TokenRefresh
 	viper.SetDefault("token_refresh.enabled", true)
@@ -1742,6 +2367,9 @@ func setDefaults() {
 	viper.SetDefault("token_refresh.refresh_before_expiry_hours", 0.5) // 提前30分钟刷新（适配Google 1小时token）
 	viper.SetDefault("token_refresh.max_retries", 3)                   // 最多重试3次
 	viper.SetDefault("token_refresh.retry_backoff_seconds", 2)         // 重试退避基础2秒
+	viper.SetDefault("token_refresh.lock_ttl_seconds", 60)             // 分布式刷新锁 TTL
+	viper.SetDefault("token_refresh.peer_wait_timeout_ms", 3000)       // 等待其他副本刷新结果的上限
+	viper.SetDefault("token_refresh.peer_wait_poll_interval_ms", 250)  // 等待期间轮询 DB 的间隔
 
 	// Gemini OAuth - configure via environment variables or config file
 	// GEMINI_OAUTH_CLIENT_ID and GEMINI_OAUTH_CLIENT_SECRET
@@ -1815,6 +2443,25 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("log.sampling.thereafter must be non-negative")
 		}
 	}
+	seenSamplingComponents := make(map[string]struct{}, len(c.Log.Sampling.Components))
+	for i, rule := range c.Log.Sampling.Components {
+		component := strings.TrimSpace(rule.Component)
+		if component == "" {
+			return fmt.Errorf("log.sampling.components[%d].component is required", i)
+		}
+		if _, dup := seenSamplingComponents[component]; dup {
+			return fmt.Errorf("log.sampling.components[%d].component %q is duplicated", i, component)
+		}
+		seenSamplingComponents[component] = struct{}{}
+		if rule.Rate < 0 || rule.Rate > 1 {
+			return fmt.Errorf("log.sampling.components[%d].rate must be between 0 and 1", i)
+		}
+		switch strings.ToLower(strings.TrimSpace(rule.AlwaysLevel)) {
+		case "", "debug", "info", "warn", "error":
+		default:
+			return fmt.Errorf("log.sampling.components[%d].always_level must be one of: debug/info/warn/error", i)
+		}
+	}
 
 	if c.SubscriptionMaintenance.WorkerCount < 0 {
 		return fmt.Errorf("subscription_maintenance.worker_count must be non-negative")
@@ -1875,6 +2522,17 @@ func (c *Config) Validate() error {
 	if c.Security.CSP.Enabled && strings.TrimSpace(c.Security.CSP.Policy) == "" {
 		return fmt.Errorf("security.csp.policy is required when CSP is enabled")
 	}
+	if masterKey := strings.TrimSpace(c.Security.CredentialEncryption.MasterKey); masterKey != "" {
+		key, err := hex.DecodeString(masterKey)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("security.credential_encryption.master_key must be 32 bytes (64 hex chars)")
+		}
+	} else if c.Security.CredentialEncryption.Enabled {
+		return fmt.Errorf("security.credential_encryption.master_key is required when credential encryption is enabled")
+	}
+	if c.Security.RequestSigning.MaxClockSkewSeconds < 0 {
+		return fmt.Errorf("security.request_signing.max_clock_skew_seconds must be non-negative")
+	}
 	if c.LinuxDo.Enabled {
 		if strings.TrimSpace(c.LinuxDo.ClientID) == "" {
 			return fmt.Errorf("linuxdo_connect.client_id is required when linuxdo_connect.enabled=true")
@@ -2055,6 +2713,24 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("billing.circuit_breaker.half_open_requests must be positive")
 		}
 	}
+	switch strings.ToLower(strings.TrimSpace(c.Billing.DBOutage.Mode)) {
+	case BillingDBOutageModeFailClosed:
+	case BillingDBOutageModeFailOpen:
+		if !c.Gateway.UsageRecord.JournalEnabled {
+			return fmt.Errorf("billing.db_outage.mode=fail_open requires gateway.usage_record.journal_enabled=true")
+		}
+	default:
+		return fmt.Errorf("billing.db_outage.mode must be one of: %s/%s", BillingDBOutageModeFailClosed, BillingDBOutageModeFailOpen)
+	}
+	if c.Billing.DBOutage.ProbeIntervalSeconds <= 0 {
+		return fmt.Errorf("billing.db_outage.probe_interval_seconds must be positive")
+	}
+	if c.Billing.DBOutage.ProbeTimeoutSeconds <= 0 {
+		return fmt.Errorf("billing.db_outage.probe_timeout_seconds must be positive")
+	}
+	if c.Billing.DBOutage.FailureThreshold <= 0 {
+		return fmt.Errorf("billing.db_outage.failure_threshold must be positive")
+	}
 	if c.Database.MaxOpenConns <= 0 {
 		return fmt.Errorf("database.max_open_conns must be positive")
 	}
@@ -2140,6 +2816,12 @@ func (c *Config) Validate() error {
 		if c.DashboardAgg.Retention.DailyDays <= 0 {
 			return fmt.Errorf("dashboard_aggregation.retention.daily_days must be positive")
 		}
+		if c.DashboardAgg.Retention.SummaryDays < 0 {
+			return fmt.Errorf("dashboard_aggregation.retention.summary_days must be non-negative")
+		}
+		if c.DashboardAgg.Retention.SummaryDays > 0 && c.DashboardAgg.Retention.SummaryDays < c.DashboardAgg.Retention.UsageLogsDays {
+			return fmt.Errorf("dashboard_aggregation.retention.summary_days must be 0 (keep forever) or greater than or equal to usage_logs_days")
+		}
 		if c.DashboardAgg.RecomputeDays < 0 {
 			return fmt.Errorf("dashboard_aggregation.recompute_days must be non-negative")
 		}
@@ -2170,6 +2852,9 @@ func (c *Config) Validate() error {
 		if c.DashboardAgg.Retention.DailyDays < 0 {
 			return fmt.Errorf("dashboard_aggregation.retention.daily_days must be non-negative")
 		}
+		if c.DashboardAgg.Retention.SummaryDays < 0 {
+			return fmt.Errorf("dashboard_aggregation.retention.summary_days must be non-negative")
+		}
 		if c.DashboardAgg.RecomputeDays < 0 {
 			return fmt.Errorf("dashboard_aggregation.recompute_days must be non-negative")
 		}
@@ -2222,9 +2907,80 @@ func (c *Config) Validate() error {
 	if c.Idempotency.CleanupBatchSize <= 0 {
 		return fmt.Errorf("idempotency.cleanup_batch_size must be positive")
 	}
+	if c.UsageExport.Enabled {
+		switch strings.ToLower(strings.TrimSpace(c.UsageExport.Backend)) {
+		case UsageExportBackendKafka:
+			if len(c.UsageExport.Kafka.Brokers) == 0 {
+				return fmt.Errorf("usage_export.kafka.brokers is required when backend=kafka")
+			}
+			for _, broker := range c.UsageExport.Kafka.Brokers {
+				host, port, err := net.SplitHostPort(strings.TrimSpace(broker))
+				if err == nil && (host == "" || strings.Contains(host, "/")) {
+					err = fmt.Errorf("empty or invalid host")
+				}
+				if err == nil {
+					_, err = strconv.ParseUint(port, 10, 16)
+				}
+				if err != nil {
+					return fmt.Errorf("usage_export.kafka.brokers invalid: %q must be host:port", broker)
+				}
+			}
+			if strings.TrimSpace(c.UsageExport.Kafka.Topic) == "" {
+				return fmt.Errorf("usage_export.kafka.topic is required when backend=kafka")
+			}
+		case UsageExportBackendNATS:
+			if strings.TrimSpace(c.UsageExport.NATS.URL) == "" {
+				return fmt.Errorf("usage_export.nats.url is required when backend=nats")
+			}
+			if strings.TrimSpace(c.UsageExport.NATS.Subject) == "" {
+				return fmt.Errorf("usage_export.nats.subject is required when backend=nats")
+			}
+		default:
+			return fmt.Errorf("usage_export.backend must be one of: %s, %s", UsageExportBackendKafka, UsageExportBackendNATS)
+		}
+		if c.UsageExport.QueueSize <= 0 {
+			return fmt.Errorf("usage_export.queue_size must be positive")
+		}
+		if c.UsageExport.BatchSize <= 0 {
+			return fmt.Errorf("usage_export.batch_size must be positive")
+		}
+		if c.UsageExport.FlushIntervalMs <= 0 {
+			return fmt.Errorf("usage_export.flush_interval_ms must be positive")
+		}
+		if c.UsageExport.MaxAttempts <= 0 {
+			return fmt.Errorf("usage_export.max_attempts must be positive")
+		}
+		if c.UsageExport.RetryBackoffMs < 0 {
+			return fmt.Errorf("usage_export.retry_backoff_ms must be non-negative")
+		}
+		if c.UsageExport.PublishTimeoutSeconds <= 0 {
+			return fmt.Errorf("usage_export.publish_timeout_seconds must be positive")
+		}
+		if c.UsageExport.DeadLetterRedriveIntervalSeconds <= 0 {
+			return fmt.Errorf("usage_export.dead_letter_redrive_interval_seconds must be positive")
+		}
+		if c.UsageExport.DeadLetterBatchSize <= 0 {
+			return fmt.Errorf("usage_export.dead_letter_batch_size must be positive")
+		}
+	}
 	if c.Gateway.MaxBodySize <= 0 {
 		return fmt.Errorf("gateway.max_body_size must be positive")
 	}
+	if c.CORS.Gateway.MaxAgeSeconds < 0 {
+		return fmt.Errorf("cors.gateway.max_age_seconds must be non-negative")
+	}
+	if c.Gateway.BodySizeLimits.Chat < 0 {
+		return fmt.Errorf("gateway.body_size_limits.chat must be non-negative")
+	}
+	if c.Gateway.BodySizeLimits.Images < 0 {
+		return fmt.Errorf("gateway.body_size_limits.images must be non-negative")
+	}
+	if c.Gateway.TokenBucketMaxWaitSeconds < 0 {
+		return fmt.Errorf("gateway.token_bucket_max_wait_seconds must be non-negative")
+	}
+	if name := strings.TrimSpace(c.Gateway.UpstreamRequestIDHeader); name != "" && !httpguts.ValidHeaderFieldName(name) {
+		return fmt.Errorf("gateway.upstream_request_id_header must be a valid header name")
+	}
 	if c.Gateway.UpstreamResponseReadMaxBytes <= 0 {
 		return fmt.Errorf("gateway.upstream_response_read_max_bytes must be positive")
 	}
@@ -2263,6 +3019,9 @@ func (c *Config) Validate() error {
 	if c.Gateway.ConcurrencySlotTTLMinutes <= 0 {
 		return fmt.Errorf("gateway.concurrency_slot_ttl_minutes must be positive")
 	}
+	if c.Gateway.StreamFailoverBuffer && c.Gateway.StreamFailoverBufferMaxBytes <= 0 {
+		return fmt.Errorf("gateway.stream_failover_buffer_max_bytes must be positive when stream_failover_buffer is enabled")
+	}
 	if c.Gateway.StreamDataIntervalTimeout < 0 {
 		return fmt.Errorf("gateway.stream_data_interval_timeout must be non-negative")
 	}
@@ -2277,6 +3036,12 @@ func (c *Config) Validate() error {
 		(c.Gateway.StreamKeepaliveInterval < 5 || c.Gateway.StreamKeepaliveInterval > 30) {
 		return fmt.Errorf("gateway.stream_keepalive_interval must be 0 or between 5-30 seconds")
 	}
+	if c.Gateway.ToolArgsCoalesceMs < 0 || c.Gateway.ToolArgsCoalesceMs > 5000 {
+		return fmt.Errorf("gateway.tool_args_coalesce_ms must be between 0-5000")
+	}
+	if c.Gateway.ToolArgsCoalesceBytes < 0 {
+		return fmt.Errorf("gateway.tool_args_coalesce_bytes must be non-negative")
+	}
 	// 兼容旧键 sticky_previous_response_ttl_seconds
 	if c.Gateway.OpenAIWS.StickyResponseIDTTLSeconds <= 0 && c.Gateway.OpenAIWS.StickyPreviousResponseTTLSeconds > 0 {
 		c.Gateway.OpenAIWS.StickyResponseIDTTLSeconds = c.Gateway.OpenAIWS.StickyPreviousResponseTTLSeconds
@@ -2397,6 +3162,139 @@ func (c *Config) Validate() error {
 	if c.Gateway.MaxLineSize != 0 && c.Gateway.MaxLineSize < 1024*1024 {
 		return fmt.Errorf("gateway.max_line_size must be at least 1MB")
 	}
+	for i, pattern := range c.Gateway.PIIRedaction.Patterns {
+		if strings.TrimSpace(pattern.Name) == "" {
+			return fmt.Errorf("gateway.pii_redaction.patterns[%d].name is required", i)
+		}
+		if _, err := regexp.Compile(pattern.Regex); err != nil || pattern.Regex == "" {
+			return fmt.Errorf("gateway.pii_redaction.patterns[%d].regex is invalid: %q", i, pattern.Regex)
+		}
+	}
+	if c.Gateway.UsageBillingDedup.TTLSeconds < 0 {
+		return fmt.Errorf("gateway.usage_billing_dedup.ttl_seconds must be non-negative")
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Gateway.StickySessionStore.Backend)) {
+	case "", ConcurrencyBackendRedis, ConcurrencyBackendMemory:
+	default:
+		return fmt.Errorf("gateway.sticky_session_store.backend must be one of: %s, %s", ConcurrencyBackendRedis, ConcurrencyBackendMemory)
+	}
+	if c.Gateway.SecretLeakGuard.RefreshIntervalSeconds < 0 {
+		return fmt.Errorf("gateway.secret_leak_guard.refresh_interval_seconds must be non-negative")
+	}
+	for i, pattern := range c.Gateway.SecretLeakGuard.Patterns {
+		if strings.TrimSpace(pattern.Name) == "" {
+			return fmt.Errorf("gateway.secret_leak_guard.patterns[%d].name is required", i)
+		}
+		if _, err := regexp.Compile(pattern.Regex); err != nil || pattern.Regex == "" {
+			return fmt.Errorf("gateway.secret_leak_guard.patterns[%d].regex is invalid: %q", i, pattern.Regex)
+		}
+	}
+	if c.Gateway.Moderation.TimeoutSeconds < 0 {
+		return fmt.Errorf("gateway.moderation.timeout_seconds must be non-negative")
+	}
+	for i, rule := range c.Gateway.Moderation.LocalRules {
+		if strings.TrimSpace(rule.Name) == "" {
+			return fmt.Errorf("gateway.moderation.local_rules[%d].name is required", i)
+		}
+		if _, err := regexp.Compile(rule.Regex); err != nil || rule.Regex == "" {
+			return fmt.Errorf("gateway.moderation.local_rules[%d].regex is invalid: %q", i, rule.Regex)
+		}
+	}
+	if c.Gateway.RegionSelection.ProbeIntervalSeconds < 0 {
+		return fmt.Errorf("gateway.region_selection.probe_interval_seconds must be non-negative")
+	}
+	if c.Gateway.RegionSelection.ProbeTimeoutSeconds < 0 {
+		return fmt.Errorf("gateway.region_selection.probe_timeout_seconds must be non-negative")
+	}
+	if c.Gateway.RegionSelection.UnhealthyCooldownSeconds < 0 {
+		return fmt.Errorf("gateway.region_selection.unhealthy_cooldown_seconds must be non-negative")
+	}
+	if c.Gateway.ProxyHealth.CheckIntervalSeconds < 0 {
+		return fmt.Errorf("gateway.proxy_health.check_interval_seconds must be non-negative")
+	}
+	if c.Gateway.ProxyHealth.UnhealthyCooldownSeconds < 0 {
+		return fmt.Errorf("gateway.proxy_health.unhealthy_cooldown_seconds must be non-negative")
+	}
+	if c.Gateway.ToolResultLimit.MaxBytes < 0 {
+		return fmt.Errorf("gateway.tool_result_limit.max_bytes must be non-negative")
+	}
+	if mode := c.Gateway.ToolResultLimit.Mode; mode != "" && mode != "truncate" && mode != "head_tail" {
+		return fmt.Errorf("gateway.tool_result_limit.mode must be one of: truncate, head_tail")
+	}
+	if c.Gateway.ImageLimit.MaxBytes < 0 {
+		return fmt.Errorf("gateway.image_limit.max_bytes must be non-negative")
+	}
+	if c.Gateway.ImageLimit.MaxBytes > 0 {
+		if c.Gateway.ImageLimit.MaxDimension <= 0 {
+			return fmt.Errorf("gateway.image_limit.max_dimension must be positive when max_bytes is set")
+		}
+		if q := c.Gateway.ImageLimit.JPEGQuality; q < 1 || q > 100 {
+			return fmt.Errorf("gateway.image_limit.jpeg_quality must be between 1 and 100")
+		}
+	}
+	if c.Gateway.MockUpstream.FirstTokenLatencyMs < 0 {
+		return fmt.Errorf("gateway.mock_upstream.first_token_latency_ms must be non-negative")
+	}
+	if c.Gateway.MockUpstream.TokensPerSecond < 0 {
+		return fmt.Errorf("gateway.mock_upstream.tokens_per_second must be non-negative")
+	}
+	if c.Gateway.MockUpstream.Enabled && c.Gateway.MockUpstream.OutputTokens <= 0 {
+		return fmt.Errorf("gateway.mock_upstream.output_tokens must be positive when mock upstream is enabled")
+	}
+	if r := c.Gateway.MockUpstream.ErrorRate; r < 0 || r > 1 {
+		return fmt.Errorf("gateway.mock_upstream.error_rate must be between 0 and 1")
+	}
+	if c.Gateway.ConversationStore.Enabled {
+		if c.Gateway.ConversationStore.TTLSeconds <= 0 {
+			return fmt.Errorf("gateway.conversation_store.ttl_seconds must be positive")
+		}
+		if c.Gateway.ConversationStore.MaxMessages <= 0 {
+			return fmt.Errorf("gateway.conversation_store.max_messages must be positive")
+		}
+		if c.Gateway.ConversationStore.MaxBytes <= 0 {
+			return fmt.Errorf("gateway.conversation_store.max_bytes must be positive")
+		}
+	}
+	if c.Gateway.Idempotency.Enabled {
+		if c.Gateway.Idempotency.TTLSeconds <= 0 {
+			return fmt.Errorf("gateway.idempotency.ttl_seconds must be positive")
+		}
+		if c.Gateway.Idempotency.ProcessingTimeoutSeconds <= 0 {
+			return fmt.Errorf("gateway.idempotency.processing_timeout_seconds must be positive")
+		}
+		if c.Gateway.Idempotency.MaxResponseBytes <= 0 {
+			return fmt.Errorf("gateway.idempotency.max_response_bytes must be positive")
+		}
+	}
+	if c.Gateway.MessageBatch.Enabled {
+		mb := c.Gateway.MessageBatch
+		if mb.Concurrency <= 0 {
+			return fmt.Errorf("gateway.message_batch.concurrency must be positive")
+		}
+		if mb.MaxRequests <= 0 {
+			return fmt.Errorf("gateway.message_batch.max_requests must be positive")
+		}
+		if mb.RequestTimeoutSeconds <= 0 {
+			return fmt.Errorf("gateway.message_batch.request_timeout_seconds must be positive")
+		}
+		if mb.MaxAttempts <= 0 {
+			return fmt.Errorf("gateway.message_batch.max_attempts must be positive")
+		}
+		if mb.ExpireHours <= 0 {
+			return fmt.Errorf("gateway.message_batch.expire_hours must be positive")
+		}
+		if mb.RetentionHours <= 0 {
+			return fmt.Errorf("gateway.message_batch.retention_hours must be positive")
+		}
+	}
+	if c.Gateway.UserAccounts.Enabled {
+		if c.Gateway.UserAccounts.MaxPerUser <= 0 {
+			return fmt.Errorf("gateway.user_accounts.max_per_user must be positive")
+		}
+		if c.Gateway.UserAccounts.Concurrency <= 0 {
+			return fmt.Errorf("gateway.user_accounts.concurrency must be positive")
+		}
+	}
 	if c.Gateway.UsageRecord.WorkerCount <= 0 {
 		return fmt.Errorf("gateway.usage_record.worker_count must be positive")
 	}
@@ -2455,6 +3353,17 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("gateway.usage_record.auto_scale_cooldown_seconds must be non-negative")
 		}
 	}
+	if c.Gateway.UsageRecord.BackpressureQueuePercent < 0 || c.Gateway.UsageRecord.BackpressureQueuePercent > 100 {
+		return fmt.Errorf("gateway.usage_record.backpressure_queue_percent must be between 0-100")
+	}
+	if c.Gateway.UsageRecord.JournalEnabled {
+		if strings.TrimSpace(c.Gateway.UsageRecord.JournalPath) == "" {
+			return fmt.Errorf("gateway.usage_record.journal_path is required when journal_enabled=true")
+		}
+		if c.Gateway.UsageRecord.JournalReplayIntervalSeconds <= 0 {
+			return fmt.Errorf("gateway.usage_record.journal_replay_interval_seconds must be positive when journal_enabled=true")
+		}
+	}
 	if c.Gateway.UserGroupRateCacheTTLSeconds <= 0 {
 		return fmt.Errorf("gateway.user_group_rate_cache_ttl_seconds must be positive")
 	}
@@ -2488,6 +3397,11 @@ func (c *Config) Validate() error {
 	if c.Gateway.Scheduling.DbFallbackMaxQPS < 0 {
 		return fmt.Errorf("gateway.scheduling.db_fallback_max_qps must be non-negative")
 	}
+	if script := strings.TrimSpace(c.Gateway.Scheduling.RoutingScript); script != "" {
+		if _, err := expr.Compile(script); err != nil {
+			return fmt.Errorf("gateway.scheduling.routing_script is invalid: %w", err)
+		}
+	}
 	if c.Gateway.Scheduling.OutboxPollIntervalSeconds <= 0 {
 		return fmt.Errorf("gateway.scheduling.outbox_poll_interval_seconds must be positive")
 	}
@@ -2526,9 +3440,53 @@ func (c *Config) Validate() error {
 	if c.Ops.Cleanup.Enabled && strings.TrimSpace(c.Ops.Cleanup.Schedule) == "" {
 		return fmt.Errorf("ops.cleanup.schedule is required when ops.cleanup.enabled=true")
 	}
+	if c.Ops.UsageAnomaly.Enabled {
+		if c.Ops.UsageAnomaly.CheckIntervalMinutes <= 0 {
+			return fmt.Errorf("ops.usage_anomaly.check_interval_minutes must be positive")
+		}
+		if c.Ops.UsageAnomaly.BaselineHours <= 0 {
+			return fmt.Errorf("ops.usage_anomaly.baseline_hours must be positive")
+		}
+		if c.Ops.UsageAnomaly.SpikeFactor <= 1 {
+			return fmt.Errorf("ops.usage_anomaly.spike_factor must be greater than 1")
+		}
+		if c.Ops.UsageAnomaly.MinTokens < 0 {
+			return fmt.Errorf("ops.usage_anomaly.min_tokens must be non-negative")
+		}
+		if c.Ops.UsageAnomaly.MaxKeys <= 0 {
+			return fmt.Errorf("ops.usage_anomaly.max_keys must be positive")
+		}
+		if c.Ops.UsageAnomaly.CooldownMinutes < 0 {
+			return fmt.Errorf("ops.usage_anomaly.cooldown_minutes must be non-negative")
+		}
+	}
+	if c.Ops.Profiling.Enabled {
+		if c.Ops.Profiling.MaxProfileSeconds <= 0 {
+			return fmt.Errorf("ops.profiling.max_profile_seconds must be positive")
+		}
+		if c.Ops.Profiling.MaxLogLevelOverrideMinutes <= 0 {
+			return fmt.Errorf("ops.profiling.max_log_level_override_minutes must be positive")
+		}
+	}
 	if c.Concurrency.PingInterval < 5 || c.Concurrency.PingInterval > 30 {
 		return fmt.Errorf("concurrency.ping_interval must be between 5-30 seconds")
 	}
+	switch strings.ToLower(strings.TrimSpace(c.Concurrency.Backend)) {
+	case "", ConcurrencyBackendRedis, ConcurrencyBackendMemory:
+	default:
+		return fmt.Errorf("concurrency.backend must be one of: %s, %s", ConcurrencyBackendRedis, ConcurrencyBackendMemory)
+	}
+	// memory 模式不连接 Redis，粘性会话无法单独使用 Redis 存储
+	if strings.EqualFold(strings.TrimSpace(c.Concurrency.Backend), ConcurrencyBackendMemory) &&
+		strings.EqualFold(strings.TrimSpace(c.Gateway.StickySessionStore.Backend), ConcurrencyBackendRedis) {
+		return fmt.Errorf("gateway.sticky_session_store.backend=redis requires concurrency.backend=redis")
+	}
+	if c.Concurrency.MaxSlotHoldSeconds < 0 {
+		return fmt.Errorf("concurrency.max_slot_hold_seconds must be non-negative")
+	}
+	if c.Concurrency.MaxSlotHoldSeconds > 0 && c.Concurrency.SlotReaperIntervalSeconds <= 0 {
+		return fmt.Errorf("concurrency.slot_reaper_interval_seconds must be positive when max_slot_hold_seconds is set")
+	}
 	return nil
 }
 
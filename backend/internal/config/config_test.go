@@ -289,6 +289,24 @@ func TestLoadForcedCodexInstructionsTemplate(t *testing.T) {
 	require.Equal(t, "server-prefix\n\n{{ .ExistingInstructions }}", cfg.Gateway.ForcedCodexInstructionsTemplate)
 }
 
+func TestLoadLogComponentSampling(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	tempDir := t.TempDir()
+	configYAML := "log:\n  sampling:\n    components:\n" +
+		"      - component: handler.gateway\n        rate: 0.01\n" +
+		"      - component: service.openai_gateway\n        rate: 0.1\n        always_level: info\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte(configYAML), 0o644))
+	t.Setenv("DATA_DIR", tempDir)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, []LogComponentSamplingConfig{
+		{Component: "handler.gateway", Rate: 0.01},
+		{Component: "service.openai_gateway", Rate: 0.1, AlwaysLevel: "info"},
+	}, cfg.Log.Sampling.Components)
+}
+
 func TestLoadDefaultSecurityToggles(t *testing.T) {
 	resetViperWithJWTSecret(t)
 
@@ -598,6 +616,9 @@ func TestLoadDefaultDashboardAggregationConfig(t *testing.T) {
 	if cfg.DashboardAgg.Retention.DailyDays != 730 {
 		t.Fatalf("DashboardAgg.Retention.DailyDays = %d, want 730", cfg.DashboardAgg.Retention.DailyDays)
 	}
+	if cfg.DashboardAgg.Retention.SummaryDays != 730 {
+		t.Fatalf("DashboardAgg.Retention.SummaryDays = %d, want 730", cfg.DashboardAgg.Retention.SummaryDays)
+	}
 	if cfg.DashboardAgg.RecomputeDays != 2 {
 		t.Fatalf("DashboardAgg.RecomputeDays = %d, want 2", cfg.DashboardAgg.RecomputeDays)
 	}
@@ -1217,6 +1238,21 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Gateway.MaxBodySize = 0 },
 			wantErr: "gateway.max_body_size",
 		},
+		{
+			name:    "gateway token bucket max wait",
+			mutate:  func(c *Config) { c.Gateway.TokenBucketMaxWaitSeconds = -1 },
+			wantErr: "gateway.token_bucket_max_wait_seconds",
+		},
+		{
+			name:    "gateway message batch concurrency",
+			mutate:  func(c *Config) { c.Gateway.MessageBatch.Enabled = true; c.Gateway.MessageBatch.Concurrency = 0 },
+			wantErr: "gateway.message_batch.concurrency",
+		},
+		{
+			name:    "gateway upstream request id header",
+			mutate:  func(c *Config) { c.Gateway.UpstreamRequestIDHeader = "X Bad:Header" },
+			wantErr: "gateway.upstream_request_id_header",
+		},
 		{
 			name:    "gateway max idle conns",
 			mutate:  func(c *Config) { c.Gateway.MaxIdleConns = 0 },
@@ -1262,6 +1298,16 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Gateway.StreamKeepaliveInterval = 4 },
 			wantErr: "gateway.stream_keepalive_interval",
 		},
+		{
+			name:    "gateway tool args coalesce window range",
+			mutate:  func(c *Config) { c.Gateway.ToolArgsCoalesceMs = 5001 },
+			wantErr: "gateway.tool_args_coalesce_ms",
+		},
+		{
+			name:    "gateway tool args coalesce bytes negative",
+			mutate:  func(c *Config) { c.Gateway.ToolArgsCoalesceBytes = -1 },
+			wantErr: "gateway.tool_args_coalesce_bytes",
+		},
 		{
 			name:    "gateway openai ws oauth max conns factor",
 			mutate:  func(c *Config) { c.Gateway.OpenAIWS.OAuthMaxConnsFactor = 0 },
@@ -1360,6 +1406,27 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Gateway.UsageRecord.AutoScaleCheckIntervalSeconds = 0 },
 			wantErr: "gateway.usage_record.auto_scale_check_interval_seconds",
 		},
+		{
+			name:    "gateway usage record backpressure percent",
+			mutate:  func(c *Config) { c.Gateway.UsageRecord.BackpressureQueuePercent = 101 },
+			wantErr: "gateway.usage_record.backpressure_queue_percent",
+		},
+		{
+			name: "gateway usage record journal path",
+			mutate: func(c *Config) {
+				c.Gateway.UsageRecord.JournalEnabled = true
+				c.Gateway.UsageRecord.JournalPath = " "
+			},
+			wantErr: "gateway.usage_record.journal_path",
+		},
+		{
+			name: "gateway usage record journal replay interval",
+			mutate: func(c *Config) {
+				c.Gateway.UsageRecord.JournalEnabled = true
+				c.Gateway.UsageRecord.JournalReplayIntervalSeconds = 0
+			},
+			wantErr: "gateway.usage_record.journal_replay_interval_seconds",
+		},
 		{
 			name:    "gateway user group rate cache ttl",
 			mutate:  func(c *Config) { c.Gateway.UserGroupRateCacheTTLSeconds = 0 },
@@ -1385,6 +1452,21 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Gateway.Scheduling.OutboxLagRebuildFailures = 0 },
 			wantErr: "gateway.scheduling.outbox_lag_rebuild_failures",
 		},
+		{
+			name:    "gateway scheduling routing script",
+			mutate:  func(c *Config) { c.Gateway.Scheduling.RoutingScript = "filter(candidates," },
+			wantErr: "gateway.scheduling.routing_script",
+		},
+		{
+			name:    "concurrency backend",
+			mutate:  func(c *Config) { c.Concurrency.Backend = "etcd" },
+			wantErr: "concurrency.backend",
+		},
+		{
+			name:    "gateway mock upstream error rate",
+			mutate:  func(c *Config) { c.Gateway.MockUpstream.ErrorRate = 1.5 },
+			wantErr: "gateway.mock_upstream.error_rate",
+		},
 		{
 			name: "gateway outbox lag rebuild",
 			mutate: func(c *Config) {
@@ -1424,6 +1506,37 @@ func TestValidateConfigErrors(t *testing.T) {
 			},
 			wantErr: "log.sampling.initial",
 		},
+		{
+			name: "log sampling component name",
+			mutate: func(c *Config) {
+				c.Log.Sampling.Components = []LogComponentSamplingConfig{{Component: " ", Rate: 0.5}}
+			},
+			wantErr: "log.sampling.components[0].component",
+		},
+		{
+			name: "log sampling component rate",
+			mutate: func(c *Config) {
+				c.Log.Sampling.Components = []LogComponentSamplingConfig{{Component: "handler.gateway", Rate: 1.5}}
+			},
+			wantErr: "log.sampling.components[0].rate",
+		},
+		{
+			name: "log sampling component always level",
+			mutate: func(c *Config) {
+				c.Log.Sampling.Components = []LogComponentSamplingConfig{{Component: "handler.gateway", Rate: 0.1, AlwaysLevel: "fatal"}}
+			},
+			wantErr: "log.sampling.components[0].always_level",
+		},
+		{
+			name: "log sampling component duplicated",
+			mutate: func(c *Config) {
+				c.Log.Sampling.Components = []LogComponentSamplingConfig{
+					{Component: "handler.gateway", Rate: 0.1},
+					{Component: "handler.gateway", Rate: 0.2},
+				}
+			},
+			wantErr: "log.sampling.components[1].component",
+		},
 		{
 			name:    "ops metrics collector ttl",
 			mutate:  func(c *Config) { c.Ops.MetricsCollectorCache.TTL = -1 },
@@ -1439,6 +1552,16 @@ func TestValidateConfigErrors(t *testing.T) {
 			mutate:  func(c *Config) { c.Ops.Cleanup.MinuteMetricsRetentionDays = -1 },
 			wantErr: "ops.cleanup.minute_metrics_retention_days",
 		},
+		{
+			name:    "ops profiling max profile seconds",
+			mutate:  func(c *Config) { c.Ops.Profiling.MaxProfileSeconds = 0 },
+			wantErr: "ops.profiling.max_profile_seconds",
+		},
+		{
+			name:    "ops profiling max log level override minutes",
+			mutate:  func(c *Config) { c.Ops.Profiling.MaxLogLevelOverrideMinutes = -1 },
+			wantErr: "ops.profiling.max_log_level_override_minutes",
+		},
 	}
 
 	for _, tt := range cases {
@@ -1754,3 +1877,159 @@ func TestLoad_DefaultGatewayUsageRecordConfig(t *testing.T) {
 		t.Fatalf("auto_scale_cooldown_seconds = %d, want 10", cfg.Gateway.UsageRecord.AutoScaleCooldownSeconds)
 	}
 }
+
+func TestValidateGatewaySecretLeakGuardConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Gateway.SecretLeakGuard.Enabled || !cfg.Gateway.SecretLeakGuard.IncludeAccountCredentials {
+		t.Fatalf("unexpected secret_leak_guard defaults: %+v", cfg.Gateway.SecretLeakGuard)
+	}
+	if cfg.Gateway.SecretLeakGuard.RefreshIntervalSeconds != 300 {
+		t.Fatalf("RefreshIntervalSeconds = %d, want 300", cfg.Gateway.SecretLeakGuard.RefreshIntervalSeconds)
+	}
+
+	cfg.Gateway.SecretLeakGuard.Patterns = []GatewayPIIRedactionPattern{{Name: "internal", Regex: "("}}
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "gateway.secret_leak_guard.patterns[0].regex") {
+		t.Fatalf("Validate() expected secret_leak_guard regex error, got: %v", err)
+	}
+}
+
+func TestValidateGatewayStickySessionStoreBackend(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Gateway.StickySessionStore.Backend != "" {
+		t.Fatalf("StickySessionStore.Backend = %q, want empty", cfg.Gateway.StickySessionStore.Backend)
+	}
+
+	cfg.Gateway.StickySessionStore.Backend = "etcd"
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "gateway.sticky_session_store.backend") {
+		t.Fatalf("Validate() expected sticky_session_store.backend error, got: %v", err)
+	}
+
+	cfg.Concurrency.Backend = ConcurrencyBackendMemory
+	cfg.Gateway.StickySessionStore.Backend = ConcurrencyBackendRedis
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "requires concurrency.backend=redis") {
+		t.Fatalf("Validate() expected redis sticky store to be rejected in memory mode, got: %v", err)
+	}
+	cfg.Gateway.StickySessionStore.Backend = ConcurrencyBackendMemory
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() unexpected error for memory sticky store: %v", err)
+	}
+}
+
+func TestValidateBillingDBOutageConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Billing.DBOutage.Mode != BillingDBOutageModeFailClosed {
+		t.Fatalf("DBOutage.Mode = %q, want %q", cfg.Billing.DBOutage.Mode, BillingDBOutageModeFailClosed)
+	}
+
+	cfg.Billing.DBOutage.Mode = BillingDBOutageModeFailOpen
+	cfg.Gateway.UsageRecord.JournalEnabled = false
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "requires gateway.usage_record.journal_enabled") {
+		t.Fatalf("Validate() expected journal requirement error, got: %v", err)
+	}
+
+	cfg.Gateway.UsageRecord.JournalEnabled = true
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() fail_open with journal error: %v", err)
+	}
+
+	cfg.Billing.DBOutage.Mode = "ignore"
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "billing.db_outage.mode") {
+		t.Fatalf("Validate() expected billing.db_outage.mode error, got: %v", err)
+	}
+
+	cfg.Billing.DBOutage.Mode = BillingDBOutageModeFailClosed
+	cfg.Billing.DBOutage.FailureThreshold = 0
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "billing.db_outage.failure_threshold") {
+		t.Fatalf("Validate() expected failure_threshold error, got: %v", err)
+	}
+}
+
+func TestValidateUsageExportConfig(t *testing.T) {
+	resetViperWithJWTSecret(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.UsageExport.Enabled || cfg.UsageExport.Backend != UsageExportBackendKafka {
+		t.Fatalf("UsageExport defaults = enabled:%v backend:%q", cfg.UsageExport.Enabled, cfg.UsageExport.Backend)
+	}
+
+	cases := []struct {
+		name    string
+		mutate  func(c *Config)
+		wantErr string
+	}{
+		{
+			name:    "unknown backend",
+			mutate:  func(c *Config) { c.UsageExport.Backend = "pulsar" },
+			wantErr: "usage_export.backend",
+		},
+		{
+			name:    "kafka requires brokers",
+			mutate:  func(c *Config) { c.UsageExport.Kafka.Brokers = nil },
+			wantErr: "usage_export.kafka.brokers",
+		},
+		{
+			name:    "kafka brokers must be host:port",
+			mutate:  func(c *Config) { c.UsageExport.Kafka.Brokers = []string{"kafka:9092", "kafka"} },
+			wantErr: "usage_export.kafka.brokers invalid",
+		},
+		{
+			name: "nats requires url",
+			mutate: func(c *Config) {
+				c.UsageExport.Backend = UsageExportBackendNATS
+				c.UsageExport.NATS.URL = ""
+			},
+			wantErr: "usage_export.nats.url",
+		},
+		{
+			name:    "batch size",
+			mutate:  func(c *Config) { c.UsageExport.BatchSize = 0 },
+			wantErr: "usage_export.batch_size",
+		},
+		{
+			name:    "dead letter batch size",
+			mutate:  func(c *Config) { c.UsageExport.DeadLetterBatchSize = 0 },
+			wantErr: "usage_export.dead_letter_batch_size",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *cfg
+			c.UsageExport.Enabled = true
+			c.UsageExport.Kafka.Brokers = []string{"kafka:9092"}
+			c.UsageExport.NATS.URL = "nats://nats:4222"
+			if err := c.Validate(); err != nil {
+				t.Fatalf("Validate() baseline error: %v", err)
+			}
+			tt.mutate(&c)
+			err := c.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package domain
+
+// AccountTagSelector restricts which upstream accounts a group or API key may
+// be scheduled onto, based on account tags.
+//
+// An account matches when it carries every tag in Require and none of the
+// tags in Exclude. Accounts marked tags-exclusive additionally only serve
+// requests whose selector requires at least one of their tags, which pins
+// dedicated capacity to the tenants that asked for it.
+type AccountTagSelector struct {
+	Require []string `json:"require,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// IsEmpty reports whether the selector imposes no restriction.
+func (s AccountTagSelector) IsEmpty() bool {
+	return len(s.Require) == 0 && len(s.Exclude) == 0
+}
@@ -16,12 +16,19 @@ const (
 	RoleUser  = "user"
 )
 
+// Organization role constants
+const (
+	OrgRoleMember = "member"
+	OrgRoleAdmin  = "admin"
+)
+
 // Platform constants
 const (
 	PlatformAnthropic   = "anthropic"
 	PlatformOpenAI      = "openai"
 	PlatformGemini      = "gemini"
 	PlatformAntigravity = "antigravity"
+	PlatformCustom      = "custom" // 自定义 OpenAI 兼容上游（base_url / 认证头模板 / 用量路径由账号凭证配置）
 )
 
 // Account type constants
@@ -32,6 +39,8 @@ const (
 	AccountTypeUpstream       = "upstream"        // 上游透传类型账号（通过 Base URL + API Key 连接上游）
 	AccountTypeBedrock        = "bedrock"         // AWS Bedrock 类型账号（通过 SigV4 签名或 API Key 连接 Bedrock，由 credentials.auth_mode 区分）
 	AccountTypeServiceAccount = "service_account" // Google Service Account 类型账号（用于 Vertex AI）
+	AccountTypeOllama         = "ollama"          // Ollama 本地模型账号（仅 custom 平台，base_url 直连 /api/chat，无需认证）
+	AccountTypeAzure          = "azure"           // Azure OpenAI 账号（仅 custom 平台，模型名映射为部署名，api-key 头认证）
 )
 
 // Redeem type constants
@@ -60,6 +69,31 @@ const (
 	SubscriptionTypeSubscription = "subscription" // 订阅模式（按限额控制）
 )
 
+// Request priority constants（并发等待队列优先级）
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// Group system prompt position constants（分组系统提示词注入位置）
+const (
+	SystemPromptModePrepend = "prepend"
+	SystemPromptModeAppend  = "append"
+)
+
+// Group PII redaction policy constants（分组敏感信息过滤策略，空字符串表示关闭）
+const (
+	PIIRedactionModeMask   = "mask"
+	PIIRedactionModeReject = "reject"
+)
+
+// API key moderation override constants（API Key 内容审核覆盖，空字符串表示继承分组）
+const (
+	ModerationModeEnabled  = "enabled"
+	ModerationModeDisabled = "disabled"
+)
+
 // Subscription status constants
 const (
 	SubscriptionStatusActive    = "active"
@@ -0,0 +1,18 @@
+package domain
+
+// GroupResponseHeaders is a per-group response header policy for gateway
+// responses.
+//
+// Static headers are set on every response of the group (e.g. CORS or
+// X-Provider hints). ExposeUpstream lists upstream header names that are
+// passed through to clients in addition to the global allowlist; an entry
+// ending with "*" matches by prefix (e.g. "anthropic-ratelimit-*").
+type GroupResponseHeaders struct {
+	Static         map[string]string `json:"static,omitempty"`
+	ExposeUpstream []string          `json:"expose_upstream,omitempty"`
+}
+
+// IsEmpty reports whether the policy changes nothing.
+func (h GroupResponseHeaders) IsEmpty() bool {
+	return len(h.Static) == 0 && len(h.ExposeUpstream) == 0
+}
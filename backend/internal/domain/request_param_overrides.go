@@ -0,0 +1,20 @@
+package domain
+
+// RequestParamOverrides is a per-group sampling-parameter policy applied to
+// request bodies before they are forwarded upstream.
+//
+// Temperature, TopP and ReasoningEffort are injected when the client omits
+// them; with Force set they replace whatever the client sent. MaxTokensCap
+// always clamps the output-token limit (and fills it in when absent).
+type RequestParamOverrides struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	TopP            *float64 `json:"top_p,omitempty"`
+	ReasoningEffort string   `json:"reasoning_effort,omitempty"`
+	MaxTokensCap    int      `json:"max_tokens_cap,omitempty"`
+	Force           bool     `json:"force,omitempty"`
+}
+
+// IsEmpty reports whether the policy changes nothing.
+func (o RequestParamOverrides) IsEmpty() bool {
+	return o.Temperature == nil && o.TopP == nil && o.ReasoningEffort == "" && o.MaxTokensCap <= 0
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// UpstreamRateLimitSnapshot is the latest rate-limit / quota state an upstream
+// reported for an account through its response headers:
+//
+//   - Anthropic: anthropic-ratelimit-{requests,tokens,input-tokens,output-tokens}-{limit,remaining,reset}
+//   - OpenAI:    x-ratelimit-{limit,remaining,reset}-{requests,tokens}
+//   - Copilot:   x-quota-snapshot-<quota> (e.g. chat, completions, premium_interactions)
+type UpstreamRateLimitSnapshot struct {
+	Requests     *UpstreamRateLimitWindow         `json:"requests,omitempty"`
+	Tokens       *UpstreamRateLimitWindow         `json:"tokens,omitempty"`
+	InputTokens  *UpstreamRateLimitWindow         `json:"input_tokens,omitempty"`
+	OutputTokens *UpstreamRateLimitWindow         `json:"output_tokens,omitempty"`
+	Quotas       map[string]*UpstreamQuotaSummary `json:"quotas,omitempty"`
+	SampledAt    time.Time                        `json:"sampled_at"`
+}
+
+// UpstreamRateLimitWindow is one limit/remaining counter pair.
+type UpstreamRateLimitWindow struct {
+	Limit     int64      `json:"limit"`
+	Remaining int64      `json:"remaining"`
+	ResetAt   *time.Time `json:"reset_at,omitempty"`
+}
+
+// UpstreamQuotaSummary is a percentage-based quota such as a Copilot quota snapshot.
+type UpstreamQuotaSummary struct {
+	Entitlement      int64      `json:"entitlement"`
+	RemainingPercent float64    `json:"remaining_percent"`
+	Overage          float64    `json:"overage,omitempty"`
+	OverageAllowed   bool       `json:"overage_allowed,omitempty"`
+	Unlimited        bool       `json:"unlimited,omitempty"`
+	ResetAt          *time.Time `json:"reset_at,omitempty"`
+}
+
+// IsEmpty reports whether the snapshot carries no counters.
+func (s *UpstreamRateLimitSnapshot) IsEmpty() bool {
+	return s == nil || (s.Requests == nil && s.Tokens == nil && s.InputTokens == nil && s.OutputTokens == nil && len(s.Quotas) == 0)
+}
+
+// RemainingFraction returns the tightest remaining/limit ratio (0-1) across
+// all counters that are still in effect at now. Counters whose reset time has
+// passed, unlimited quotas and quotas that allow overage are ignored; ok is
+// false when nothing applies.
+func (s *UpstreamRateLimitSnapshot) RemainingFraction(now time.Time) (fraction float64, ok bool) {
+	if s.IsEmpty() {
+		return 0, false
+	}
+	fraction = 1
+	consider := func(f float64, resetAt *time.Time) {
+		if resetAt != nil && !resetAt.After(now) {
+			return
+		}
+		f = min(max(f, 0), 1)
+		if !ok || f < fraction {
+			fraction = f
+		}
+		ok = true
+	}
+	for _, w := range []*UpstreamRateLimitWindow{s.Requests, s.Tokens, s.InputTokens, s.OutputTokens} {
+		if w != nil && w.Limit > 0 {
+			consider(float64(w.Remaining)/float64(w.Limit), w.ResetAt)
+		}
+	}
+	for _, q := range s.Quotas {
+		if q != nil && !q.Unlimited && !q.OverageAllowed {
+			consider(q.RemainingPercent/100, q.ResetAt)
+		}
+	}
+	return fraction, ok
+}
@@ -34,12 +34,14 @@ import (
 // OAuthHandler handles OAuth-related operations for accounts
 type OAuthHandler struct {
 	oauthService *service.OAuthService
+	adminService service.AdminService
 }
 
 // NewOAuthHandler creates a new OAuth handler
-func NewOAuthHandler(oauthService *service.OAuthService) *OAuthHandler {
+func NewOAuthHandler(oauthService *service.OAuthService, adminService service.AdminService) *OAuthHandler {
 	return &OAuthHandler{
 		oauthService: oauthService,
+		adminService: adminService,
 	}
 }
 
@@ -98,7 +100,7 @@ type CreateAccountRequest struct {
 	Name                    string         `json:"name" binding:"required"`
 	Notes                   *string        `json:"notes"`
 	Platform                string         `json:"platform" binding:"required"`
-	Type                    string         `json:"type" binding:"required,oneof=oauth setup-token apikey upstream bedrock service_account"`
+	Type                    string         `json:"type" binding:"required,oneof=oauth setup-token apikey upstream bedrock service_account ollama azure"`
 	Credentials             map[string]any `json:"credentials" binding:"required"`
 	Extra                   map[string]any `json:"extra"`
 	ProxyID                 *int64         `json:"proxy_id"`
@@ -109,6 +111,8 @@ type CreateAccountRequest struct {
 	GroupIDs                []int64        `json:"group_ids"`
 	ExpiresAt               *int64         `json:"expires_at"`
 	AutoPauseOnExpired      *bool          `json:"auto_pause_on_expired"`
+	Tags                    []string       `json:"tags"`
+	TagsExclusive           bool           `json:"tags_exclusive"`
 	ConfirmMixedChannelRisk *bool          `json:"confirm_mixed_channel_risk"` // 用户确认混合渠道风险
 }
 
@@ -117,7 +121,7 @@ type CreateAccountRequest struct {
 type UpdateAccountRequest struct {
 	Name                    string         `json:"name"`
 	Notes                   *string        `json:"notes"`
-	Type                    string         `json:"type" binding:"omitempty,oneof=oauth setup-token apikey upstream bedrock service_account"`
+	Type                    string         `json:"type" binding:"omitempty,oneof=oauth setup-token apikey upstream bedrock service_account ollama azure"`
 	Credentials             map[string]any `json:"credentials"`
 	Extra                   map[string]any `json:"extra"`
 	ProxyID                 *int64         `json:"proxy_id"`
@@ -129,6 +133,8 @@ type UpdateAccountRequest struct {
 	GroupIDs                *[]int64       `json:"group_ids"`
 	ExpiresAt               *int64         `json:"expires_at"`
 	AutoPauseOnExpired      *bool          `json:"auto_pause_on_expired"`
+	Tags                    *[]string      `json:"tags"`
+	TagsExclusive           *bool          `json:"tags_exclusive"`
 	ConfirmMixedChannelRisk *bool          `json:"confirm_mixed_channel_risk"` // 用户确认混合渠道风险
 }
 
@@ -157,6 +163,7 @@ type BulkUpdateAccountFilters struct {
 	Group       string `json:"group"`
 	Search      string `json:"search"`
 	PrivacyMode string `json:"privacy_mode"`
+	Tag         string `json:"tag"`
 }
 
 // CheckMixedChannelRequest represents check mixed channel risk request
@@ -224,6 +231,7 @@ func (h *AccountHandler) buildAccountResponseWithRuntime(ctx context.Context, ac
 
 // List handles listing all accounts with pagination
 // GET /api/v1/admin/accounts
+// Accounts are platform-wide upstream credentials and are not scoped by organization.
 func (h *AccountHandler) List(c *gin.Context) {
 	page, pageSize := response.ParsePagination(c)
 	platform := c.Query("platform")
@@ -231,6 +239,7 @@ func (h *AccountHandler) List(c *gin.Context) {
 	status := c.Query("status")
 	search := c.Query("search")
 	privacyMode := strings.TrimSpace(c.Query("privacy_mode"))
+	tag := strings.ToLower(strings.TrimSpace(c.Query("tag")))
 	sortBy := c.DefaultQuery("sort_by", "name")
 	sortOrder := c.DefaultQuery("sort_order", "asc")
 	// 标准化和验证 search 参数
@@ -258,7 +267,7 @@ func (h *AccountHandler) List(c *gin.Context) {
 		}
 	}
 
-	accounts, total, err := h.adminService.ListAccounts(c.Request.Context(), page, pageSize, platform, accountType, status, search, groupID, privacyMode, sortBy, sortOrder)
+	accounts, total, err := h.adminService.ListAccounts(c.Request.Context(), page, pageSize, platform, accountType, status, search, groupID, privacyMode, tag, sortBy, sortOrder)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
@@ -544,6 +553,8 @@ func (h *AccountHandler) Create(c *gin.Context) {
 			GroupIDs:              req.GroupIDs,
 			ExpiresAt:             req.ExpiresAt,
 			AutoPauseOnExpired:    req.AutoPauseOnExpired,
+			Tags:                  req.Tags,
+			TagsExclusive:         req.TagsExclusive,
 			SkipMixedChannelCheck: skipCheck,
 		})
 		if execErr != nil {
@@ -619,6 +630,8 @@ func (h *AccountHandler) Update(c *gin.Context) {
 		GroupIDs:              req.GroupIDs,
 		ExpiresAt:             req.ExpiresAt,
 		AutoPauseOnExpired:    req.AutoPauseOnExpired,
+		Tags:                  req.Tags,
+		TagsExclusive:         req.TagsExclusive,
 		SkipMixedChannelCheck: skipCheck,
 	})
 	if err != nil {
@@ -705,6 +718,87 @@ func (h *AccountHandler) Test(c *gin.Context) {
 	}
 }
 
+// Verify handles platform-specific credential verification
+// POST /api/v1/admin/accounts/:id/verify
+func (h *AccountHandler) Verify(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	result, err := h.accountTestService.VerifyAccountCredentials(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	if h.rateLimitService != nil {
+		if err := h.rateLimitService.ApplyCredentialVerification(c.Request.Context(), result); err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+
+	response.Success(c, result)
+}
+
+// SuggestModelMapping fetches the account's upstream model list and returns model mapping suggestions
+// POST /api/v1/admin/accounts/:id/model-mapping/suggest
+func (h *AccountHandler) SuggestModelMapping(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	result, err := h.accountTestService.SuggestModelMapping(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+// ApplyModelMappingRequest represents the request to accept model mapping suggestions in bulk
+type ApplyModelMappingRequest struct {
+	Mapping map[string]string `json:"mapping" binding:"required"`
+	// Replace 为 true 时替换整个 model_mapping，否则合并到现有映射
+	Replace bool `json:"replace"`
+}
+
+// ApplyModelMapping merges accepted model mapping entries into the account credentials
+// POST /api/v1/admin/accounts/:id/model-mapping/apply
+func (h *AccountHandler) ApplyModelMapping(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	var req ApplyModelMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	account, err := h.adminService.GetAccount(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	account, err = h.adminService.UpdateAccount(c.Request.Context(), accountID, &service.UpdateAccountInput{
+		Credentials: service.MergeModelMapping(account.Credentials, req.Mapping, req.Replace),
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, h.buildAccountResponseWithRuntime(c.Request.Context(), account))
+}
+
 // RecoverState handles unified recovery of recoverable account runtime state.
 // POST /api/v1/admin/accounts/:id/recover-state
 func (h *AccountHandler) RecoverState(c *gin.Context) {
@@ -1444,6 +1538,51 @@ func (h *AccountHandler) BulkUpdate(c *gin.Context) {
 	response.Success(c, result)
 }
 
+// BulkUpdateAccountTagsRequest 批量增删账号标签；account_ids 为空时按 filters 选择账号
+type BulkUpdateAccountTagsRequest struct {
+	AccountIDs []int64                   `json:"account_ids"`
+	Filters    *BulkUpdateAccountFilters `json:"filters"`
+	Add        []string                  `json:"add"`
+	Remove     []string                  `json:"remove"`
+}
+
+// BulkUpdateTags handles bulk adding/removing account tags
+// POST /api/v1/admin/accounts/bulk-tags
+func (h *AccountHandler) BulkUpdateTags(c *gin.Context) {
+	var req BulkUpdateAccountTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if len(req.AccountIDs) == 0 && req.Filters == nil {
+		response.BadRequest(c, "account_ids or filters is required")
+		return
+	}
+
+	result, err := h.adminService.BulkUpdateAccountTags(c.Request.Context(), &service.BulkUpdateAccountTagsInput{
+		AccountIDs: req.AccountIDs,
+		Filters:    toServiceBulkUpdateAccountFilters(req.Filters),
+		Add:        req.Add,
+		Remove:     req.Remove,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+// ListTags returns all account tags in use with their account counts
+// GET /api/v1/admin/accounts/tags
+func (h *AccountHandler) ListTags(c *gin.Context) {
+	tags, err := h.adminService.ListAccountTags(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, tags)
+}
+
 func toServiceBulkUpdateAccountFilters(filters *BulkUpdateAccountFilters) *service.BulkUpdateAccountFilters {
 	if filters == nil {
 		return nil
@@ -1455,6 +1594,7 @@ func toServiceBulkUpdateAccountFilters(filters *BulkUpdateAccountFilters) *servi
 		Group:       filters.Group,
 		Search:      filters.Search,
 		PrivacyMode: filters.PrivacyMode,
+		Tag:         strings.ToLower(strings.TrimSpace(filters.Tag)),
 	}
 }
 
@@ -1530,6 +1670,138 @@ func (h *OAuthHandler) ExchangeCode(c *gin.Context) {
 	response.Success(c, tokenInfo)
 }
 
+// CreateAccountFromOAuthRequest represents the request for creating an account from an OAuth code
+type CreateAccountFromOAuthRequest struct {
+	SessionID   string  `json:"session_id" binding:"required"`
+	Code        string  `json:"code" binding:"required"`
+	ProxyID     *int64  `json:"proxy_id"`
+	Name        string  `json:"name"`
+	Notes       *string `json:"notes"`
+	Concurrency int     `json:"concurrency"`
+	Priority    int     `json:"priority"`
+	GroupIDs    []int64 `json:"group_ids"`
+}
+
+// CreateAccountFromOAuth exchanges the pasted authorization code and creates the Anthropic account in one step.
+// The account type (oauth / setup-token) follows the scope of the session created by
+// generate-auth-url or generate-setup-token-url.
+// POST /api/v1/admin/accounts/create-from-oauth
+func (h *OAuthHandler) CreateAccountFromOAuth(c *gin.Context) {
+	var req CreateAccountFromOAuthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	grant, err := h.oauthService.ExchangeCodeForAccount(c.Request.Context(), &service.ExchangeCodeInput{
+		SessionID: req.SessionID,
+		Code:      req.Code,
+		ProxyID:   req.ProxyID,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = grant.TokenInfo.EmailAddress
+	}
+	if name == "" {
+		name = "Claude OAuth Account"
+	}
+
+	account, err := h.adminService.CreateAccount(c.Request.Context(), &service.CreateAccountInput{
+		Name:        name,
+		Notes:       req.Notes,
+		Platform:    service.PlatformAnthropic,
+		Type:        grant.AccountType,
+		Credentials: grant.Credentials,
+		Extra:       grant.Extra,
+		ProxyID:     req.ProxyID,
+		Concurrency: req.Concurrency,
+		Priority:    req.Priority,
+		GroupIDs:    req.GroupIDs,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.AccountFromService(account))
+}
+
+// ReauthorizeAccountRequest represents the request for re-authorizing an existing account
+type ReauthorizeAccountRequest struct {
+	SessionID string `json:"session_id" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+	ProxyID   *int64 `json:"proxy_id"`
+}
+
+// ReauthorizeAccount exchanges a fresh authorization code and replaces the tokens of an existing
+// Anthropic OAuth / setup-token account, keeping its other credential fields and clearing any error state.
+// POST /api/v1/admin/accounts/:id/reauthorize
+func (h *OAuthHandler) ReauthorizeAccount(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+	var req ReauthorizeAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	account, err := h.adminService.GetAccount(ctx, accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if account.Platform != service.PlatformAnthropic || !account.IsOAuth() {
+		response.BadRequest(c, "Account is not an Anthropic OAuth or setup-token account")
+		return
+	}
+
+	proxyID := req.ProxyID
+	if proxyID == nil {
+		proxyID = account.ProxyID
+	}
+	grant, err := h.oauthService.ExchangeCodeForAccount(ctx, &service.ExchangeCodeInput{
+		SessionID: req.SessionID,
+		Code:      req.Code,
+		ProxyID:   proxyID,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	if grant.AccountType != account.Type {
+		response.BadRequest(c, fmt.Sprintf("Authorization scope does not match account type %s", account.Type))
+		return
+	}
+
+	credentials := service.MergeCredentials(account.Credentials, grant.Credentials)
+	credentials["_token_version"] = time.Now().UnixMilli()
+	extra := service.MergeCredentials(account.Extra, grant.Extra)
+
+	if _, err := h.adminService.UpdateAccount(ctx, accountID, &service.UpdateAccountInput{
+		Credentials: credentials,
+		Extra:       extra,
+	}); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	updated, err := h.adminService.ClearAccountError(ctx, accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, dto.AccountFromService(updated))
+}
+
 // ExchangeSetupTokenCode exchanges authorization code for setup token
 // POST /api/v1/admin/accounts/exchange-setup-token-code
 func (h *OAuthHandler) ExchangeSetupTokenCode(c *gin.Context) {
@@ -1627,6 +1899,24 @@ func (h *AccountHandler) GetUsage(c *gin.Context) {
 	response.Success(c, usage)
 }
 
+// GetForecast handles projecting when the account's quota windows will be exhausted
+// GET /api/v1/admin/accounts/:id/forecast
+func (h *AccountHandler) GetForecast(c *gin.Context) {
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	forecast, err := h.accountUsageService.GetUsageForecast(c.Request.Context(), accountID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, forecast)
+}
+
 // ClearRateLimit handles clearing account rate limit status
 // POST /api/v1/admin/accounts/:id/clear-rate-limit
 func (h *AccountHandler) ClearRateLimit(c *gin.Context) {
@@ -1913,6 +2203,22 @@ func (h *AccountHandler) GetAvailableModels(c *gin.Context) {
 		return
 	}
 
+	// Handle custom provider accounts: no built-in model list, only model_mapping keys
+	// (Ollama accounts without mapping expose their local models via the gateway /v1/models)
+	if account.Platform == service.PlatformCustom {
+		models := make([]openai.Model, 0)
+		for requestedModel := range account.GetModelMapping() {
+			models = append(models, openai.Model{
+				ID:          requestedModel,
+				Object:      "model",
+				Type:        "model",
+				DisplayName: requestedModel,
+			})
+		}
+		response.Success(c, models)
+		return
+	}
+
 	// Handle Claude/Anthropic accounts
 	// For OAuth and Setup-Token accounts: return default models
 	if account.IsOAuth() {
@@ -2067,7 +2373,7 @@ func (h *AccountHandler) BatchRefreshTier(c *gin.Context) {
 	accounts := make([]*service.Account, 0)
 
 	if len(req.AccountIDs) == 0 {
-		allAccounts, _, err := h.adminService.ListAccounts(ctx, 1, 10000, "gemini", "oauth", "", "", 0, "", "name", "asc")
+		allAccounts, _, err := h.adminService.ListAccounts(ctx, 1, 10000, "gemini", "oauth", "", "", 0, "", "", "name", "asc")
 		if err != nil {
 			response.ErrorFrom(c, err)
 			return
@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountRotationHandler handles admin CRUD for scheduled account rotation policies.
+type AccountRotationHandler struct {
+	rotationService *service.AccountRotationService
+}
+
+// NewAccountRotationHandler creates a new AccountRotationHandler.
+func NewAccountRotationHandler(rotationService *service.AccountRotationService) *AccountRotationHandler {
+	return &AccountRotationHandler{rotationService: rotationService}
+}
+
+// List returns all account rotation policies.
+// GET /api/v1/admin/account-rotation-policies
+func (h *AccountRotationHandler) List(c *gin.Context) {
+	policies, err := h.rotationService.List(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, policies)
+}
+
+// Create creates an account rotation policy.
+// POST /api/v1/admin/account-rotation-policies
+func (h *AccountRotationHandler) Create(c *gin.Context) {
+	var req service.AccountRotationPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	created, err := h.rotationService.Create(c.Request.Context(), &req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, created)
+}
+
+// Update replaces an account rotation policy.
+// PUT /api/v1/admin/account-rotation-policies/:id
+func (h *AccountRotationHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid policy ID")
+		return
+	}
+	var req service.AccountRotationPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	updated, err := h.rotationService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
+// Delete deletes an account rotation policy.
+// DELETE /api/v1/admin/account-rotation-policies/:id
+func (h *AccountRotationHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid policy ID")
+		return
+	}
+	if err := h.rotationService.Delete(c.Request.Context(), id); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Account rotation policy deleted successfully"})
+}
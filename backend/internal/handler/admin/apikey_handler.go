@@ -26,6 +26,24 @@ func NewAdminAPIKeyHandler(adminService service.AdminService) *AdminAPIKeyHandle
 type AdminUpdateAPIKeyGroupRequest struct {
 	GroupID             *int64 `json:"group_id"`               // nil=不修改, 0=解绑, >0=绑定到目标分组
 	ResetRateLimitUsage *bool  `json:"reset_rate_limit_usage"` // true=重置 5h/1d/7d 限速用量
+	// Priority 并发等待队列优先级：nil=不修改, ""=继承分组, high/normal/low
+	Priority *string `json:"priority"`
+	// ModerationMode 内容审核覆盖：nil=不修改, ""=继承分组, enabled/disabled
+	ModerationMode *string `json:"moderation_mode"`
+	// ContextAutoTrim 超出上下文窗口时自动裁剪最早的消息：nil=不修改
+	ContextAutoTrim *bool `json:"context_auto_trim"`
+	// MaxBodySize 请求体最大字节数：nil=不修改, 0=继承分组/端点类别配置
+	MaxBodySize *int64 `json:"max_body_size"`
+	// RequestCoalescing 相同的并发非流式请求合并为一次上游调用：nil=不修改
+	RequestCoalescing *bool `json:"request_coalescing"`
+	// TokenBucketBurst 令牌桶容量（请求数）：nil=不修改, 0=关闭令牌桶
+	TokenBucketBurst *int `json:"token_bucket_burst"`
+	// TokenBucketRefillRate 令牌桶每秒补充的请求数：nil=不修改
+	TokenBucketRefillRate *float64 `json:"token_bucket_refill_rate"`
+	// MaxCostPerRequest 单次请求预估费用上限（USD）：nil=不修改, 0=不限制
+	MaxCostPerRequest *float64 `json:"max_cost_per_request"`
+	// AccountTagSelector 账号标签选择器：nil=不修改, {}=清除
+	AccountTagSelector *service.AccountTagSelector `json:"account_tag_selector"`
 }
 
 // UpdateGroup handles updating an API key's admin-managed fields.
@@ -44,6 +62,62 @@ func (h *AdminAPIKeyHandler) UpdateGroup(c *gin.Context) {
 	}
 
 	var resetKey *service.APIKey
+	if req.Priority != nil {
+		resetKey, err = h.adminService.AdminUpdateAPIKeyPriority(c.Request.Context(), keyID, *req.Priority)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.ModerationMode != nil {
+		resetKey, err = h.adminService.AdminUpdateAPIKeyModerationMode(c.Request.Context(), keyID, *req.ModerationMode)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.ContextAutoTrim != nil {
+		resetKey, err = h.adminService.AdminUpdateAPIKeyContextAutoTrim(c.Request.Context(), keyID, *req.ContextAutoTrim)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.MaxBodySize != nil {
+		resetKey, err = h.adminService.AdminUpdateAPIKeyMaxBodySize(c.Request.Context(), keyID, *req.MaxBodySize)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.RequestCoalescing != nil {
+		resetKey, err = h.adminService.AdminUpdateAPIKeyRequestCoalescing(c.Request.Context(), keyID, *req.RequestCoalescing)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.TokenBucketBurst != nil || req.TokenBucketRefillRate != nil {
+		resetKey, err = h.adminService.AdminUpdateAPIKeyTokenBucket(c.Request.Context(), keyID, req.TokenBucketBurst, req.TokenBucketRefillRate)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.MaxCostPerRequest != nil {
+		resetKey, err = h.adminService.AdminUpdateAPIKeyMaxCostPerRequest(c.Request.Context(), keyID, *req.MaxCostPerRequest)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
+	if req.AccountTagSelector != nil {
+		resetKey, err = h.adminService.AdminUpdateAPIKeyAccountTagSelector(c.Request.Context(), keyID, *req.AccountTagSelector)
+		if err != nil {
+			response.ErrorFrom(c, err)
+			return
+		}
+	}
 	if req.ResetRateLimitUsage != nil && *req.ResetRateLimitUsage {
 		resetKey, err = h.adminService.AdminResetAPIKeyRateLimitUsage(c.Request.Context(), keyID)
 		if err != nil {
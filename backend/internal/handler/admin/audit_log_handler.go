@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLogHandler handles querying the admin audit log
+type AuditLogHandler struct {
+	auditService *service.AdminAuditService
+}
+
+// NewAuditLogHandler creates a new admin audit log handler
+func NewAuditLogHandler(auditService *service.AdminAuditService) *AuditLogHandler {
+	return &AuditLogHandler{auditService: auditService}
+}
+
+// List handles listing admin audit logs (newest first)
+// GET /api/v1/admin/audit-logs
+// Query params: actor_user_id, resource, resource_id, method, start_date, end_date (YYYY-MM-DD), timezone
+func (h *AuditLogHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+
+	filters := service.AdminAuditLogFilters{
+		Resource: strings.TrimSpace(c.Query("resource")),
+		Method:   strings.TrimSpace(c.Query("method")),
+	}
+	var ok bool
+	if filters.ActorUserID, ok = parseAuditLogIDQuery(c, "actor_user_id"); !ok {
+		return
+	}
+	if filters.ResourceID, ok = parseAuditLogIDQuery(c, "resource_id"); !ok {
+		return
+	}
+
+	userTZ := c.Query("timezone")
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", startDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid start_date format, use YYYY-MM-DD")
+			return
+		}
+		filters.StartTime = &t
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		t, err := timezone.ParseInUserLocation("2006-01-02", endDateStr, userTZ)
+		if err != nil {
+			response.BadRequest(c, "Invalid end_date format, use YYYY-MM-DD")
+			return
+		}
+		// Use half-open range [start, end), move to next calendar day start (DST-safe).
+		t = t.AddDate(0, 0, 1)
+		filters.EndTime = &t
+	}
+
+	logs, total, err := h.auditService.List(c.Request.Context(), page, pageSize, filters)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.AdminAuditLog, 0, len(logs))
+	for i := range logs {
+		out = append(out, *dto.AdminAuditLogFromService(&logs[i]))
+	}
+	response.Paginated(c, out, total, page, pageSize)
+}
+
+func parseAuditLogIDQuery(c *gin.Context, name string) (*int64, bool) {
+	raw := strings.TrimSpace(c.Query(name))
+	if raw == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "Invalid "+name)
+		return nil, false
+	}
+	return &id, true
+}
@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// DebugHandler handles admin debugging tools.
+type DebugHandler struct {
+	opsService    *service.OpsService
+	apiKeyService *service.APIKeyService
+}
+
+// NewDebugHandler creates a new DebugHandler.
+func NewDebugHandler(opsService *service.OpsService, apiKeyService *service.APIKeyService) *DebugHandler {
+	return &DebugHandler{opsService: opsService, apiKeyService: apiKeyService}
+}
+
+type debugForwardRequest struct {
+	APIKeyID  int64             `json:"api_key_id" binding:"required"`
+	AccountID int64             `json:"account_id" binding:"required"`
+	Path      string            `json:"path"`
+	Headers   map[string]string `json:"headers"`
+	Body      json.RawMessage   `json:"body" binding:"required"`
+}
+
+// Forward sends a request as the given API key directly to the given account,
+// bypassing account selection, and returns the upstream request/response details.
+// POST /api/v1/admin/debug/forward
+func (h *DebugHandler) Forward(c *gin.Context) {
+	if h.opsService == nil || h.apiKeyService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Debug service not available")
+		return
+	}
+
+	var req debugForwardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	apiKey, err := h.apiKeyService.GetByID(c.Request.Context(), req.APIKeyID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	result, err := h.opsService.DebugForward(c.Request.Context(), service.OpsDebugForwardInput{
+		APIKey:    apiKey,
+		AccountID: req.AccountID,
+		Path:      req.Path,
+		Headers:   req.Headers,
+		Body:      req.Body,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -2,6 +2,7 @@ package admin
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/model"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
@@ -34,6 +35,7 @@ type CreateErrorPassthroughRuleRequest struct {
 	CustomMessage   *string  `json:"custom_message"`
 	SkipMonitoring  *bool    `json:"skip_monitoring"`
 	Description     *string  `json:"description"`
+	ExpiresAt       *string  `json:"expires_at"` // RFC3339，空字符串表示永不过期
 }
 
 // UpdateErrorPassthroughRuleRequest 更新规则请求（部分更新，所有字段可选）
@@ -51,6 +53,7 @@ type UpdateErrorPassthroughRuleRequest struct {
 	CustomMessage   *string  `json:"custom_message"`
 	SkipMonitoring  *bool    `json:"skip_monitoring"`
 	Description     *string  `json:"description"`
+	ExpiresAt       *string  `json:"expires_at"` // RFC3339，空字符串表示永不过期
 }
 
 // List 获取所有规则
@@ -130,6 +133,14 @@ func (h *ErrorPassthroughHandler) Create(c *gin.Context) {
 	rule.ResponseCode = req.ResponseCode
 	rule.CustomMessage = req.CustomMessage
 	rule.Description = req.Description
+	if req.ExpiresAt != nil {
+		expiresAt, err := parseRuleExpiresAt(*req.ExpiresAt)
+		if err != nil {
+			response.BadRequest(c, "Invalid expires_at format: "+err.Error())
+			return
+		}
+		rule.ExpiresAt = expiresAt
+	}
 
 	// 确保切片不为 nil
 	if rule.ErrorCodes == nil {
@@ -197,6 +208,7 @@ func (h *ErrorPassthroughHandler) Update(c *gin.Context) {
 		CustomMessage:   existing.CustomMessage,
 		SkipMonitoring:  existing.SkipMonitoring,
 		Description:     existing.Description,
+		ExpiresAt:       existing.ExpiresAt,
 	}
 
 	// 应用请求中提供的更新
@@ -239,6 +251,14 @@ func (h *ErrorPassthroughHandler) Update(c *gin.Context) {
 	if req.SkipMonitoring != nil {
 		rule.SkipMonitoring = *req.SkipMonitoring
 	}
+	if req.ExpiresAt != nil {
+		expiresAt, err := parseRuleExpiresAt(*req.ExpiresAt)
+		if err != nil {
+			response.BadRequest(c, "Invalid expires_at format: "+err.Error())
+			return
+		}
+		rule.ExpiresAt = expiresAt
+	}
 
 	// 确保切片不为 nil
 	if rule.ErrorCodes == nil {
@@ -280,3 +300,15 @@ func (h *ErrorPassthroughHandler) Delete(c *gin.Context) {
 
 	response.Success(c, gin.H{"message": "Rule deleted successfully"})
 }
+
+// parseRuleExpiresAt 解析规则过期时间，空字符串表示清除过期时间
+func parseRuleExpiresAt(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
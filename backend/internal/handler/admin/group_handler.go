@@ -84,7 +84,7 @@ func NewGroupHandler(adminService service.AdminService, dashboardService *servic
 type CreateGroupRequest struct {
 	Name             string             `json:"name" binding:"required"`
 	Description      string             `json:"description"`
-	Platform         string             `json:"platform" binding:"omitempty,oneof=anthropic openai gemini antigravity"`
+	Platform         string             `json:"platform" binding:"omitempty,oneof=anthropic openai gemini antigravity custom"`
 	RateMultiplier   float64            `json:"rate_multiplier"`
 	IsExclusive      bool               `json:"is_exclusive"`
 	SubscriptionType string             `json:"subscription_type" binding:"omitempty,oneof=standard subscription"`
@@ -112,6 +112,23 @@ type CreateGroupRequest struct {
 	MessagesDispatchModelConfig service.OpenAIMessagesDispatchModelConfig `json:"messages_dispatch_model_config"`
 	// 分组 RPM 上限（0 = 不限制）
 	RPMLimit int `json:"rpm_limit"`
+	// 并发等待队列优先级（默认 normal）
+	Priority string `json:"priority" binding:"omitempty,oneof=high normal low"`
+	// 请求参数策略（默认/强制 temperature、top_p、reasoning_effort 与 max_tokens 上限）
+	RequestParamOverrides service.RequestParamOverrides `json:"request_param_overrides"`
+	// 分组系统提示词及注入位置（默认 prepend）
+	SystemPrompt     string `json:"system_prompt"`
+	SystemPromptMode string `json:"system_prompt_mode" binding:"omitempty,oneof=prepend append"`
+	// 敏感信息过滤策略（空表示关闭）
+	PIIRedactionMode string `json:"pii_redaction_mode" binding:"omitempty,oneof=mask reject"`
+	// 转发前是否执行内容审核
+	ModerationEnabled bool `json:"moderation_enabled"`
+	// 请求体最大字节数（0 表示使用端点类别默认值）
+	MaxBodySize int64 `json:"max_body_size" binding:"omitempty,min=0"`
+	// 自定义响应头（静态响应头与额外透传的上游响应头）
+	ResponseHeaders service.GroupResponseHeaders `json:"response_headers"`
+	// 账号标签选择器（require 全部命中、exclude 均不命中）
+	AccountTagSelector service.AccountTagSelector `json:"account_tag_selector"`
 	// 从指定分组复制账号（创建后自动绑定）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -120,7 +137,7 @@ type CreateGroupRequest struct {
 type UpdateGroupRequest struct {
 	Name             string             `json:"name"`
 	Description      string             `json:"description"`
-	Platform         string             `json:"platform" binding:"omitempty,oneof=anthropic openai gemini antigravity"`
+	Platform         string             `json:"platform" binding:"omitempty,oneof=anthropic openai gemini antigravity custom"`
 	RateMultiplier   *float64           `json:"rate_multiplier"`
 	IsExclusive      *bool              `json:"is_exclusive"`
 	Status           string             `json:"status" binding:"omitempty,oneof=active inactive"`
@@ -149,6 +166,23 @@ type UpdateGroupRequest struct {
 	MessagesDispatchModelConfig *service.OpenAIMessagesDispatchModelConfig `json:"messages_dispatch_model_config"`
 	// 分组 RPM 上限（0 = 不限制）；nil 表示未提供不改动
 	RPMLimit *int `json:"rpm_limit"`
+	// 并发等待队列优先级；nil 表示未提供不改动
+	Priority *string `json:"priority" binding:"omitempty,oneof=high normal low"`
+	// 请求参数策略；nil 表示未提供不改动
+	RequestParamOverrides *service.RequestParamOverrides `json:"request_param_overrides"`
+	// 分组系统提示词及注入位置；nil 表示未提供不改动
+	SystemPrompt     *string `json:"system_prompt"`
+	SystemPromptMode *string `json:"system_prompt_mode" binding:"omitempty,oneof=prepend append"`
+	// 敏感信息过滤策略；nil 表示未提供不改动，空字符串表示关闭
+	PIIRedactionMode *string `json:"pii_redaction_mode"`
+	// 是否执行内容审核；nil 表示未提供不改动
+	ModerationEnabled *bool `json:"moderation_enabled"`
+	// 请求体最大字节数；nil 表示未提供不改动，0 表示使用端点类别默认值
+	MaxBodySize *int64 `json:"max_body_size"`
+	// 自定义响应头；nil 表示未提供不改动
+	ResponseHeaders *service.GroupResponseHeaders `json:"response_headers"`
+	// 账号标签选择器；nil 表示未提供不改动
+	AccountTagSelector *service.AccountTagSelector `json:"account_tag_selector"`
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64 `json:"copy_accounts_from_group_ids"`
 }
@@ -174,8 +208,12 @@ func (h *GroupHandler) List(c *gin.Context) {
 		val := isExclusiveStr == "true"
 		isExclusive = &val
 	}
+	organizationID, ok := parseOrganizationIDQuery(c)
+	if !ok {
+		return
+	}
 
-	groups, total, err := h.adminService.ListGroups(c.Request.Context(), page, pageSize, platform, status, search, isExclusive, sortBy, sortOrder)
+	groups, total, err := h.adminService.ListGroups(c.Request.Context(), page, pageSize, platform, status, search, isExclusive, organizationID, sortBy, sortOrder)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
@@ -267,6 +305,15 @@ func (h *GroupHandler) Create(c *gin.Context) {
 		DefaultMappedModel:              req.DefaultMappedModel,
 		MessagesDispatchModelConfig:     req.MessagesDispatchModelConfig,
 		RPMLimit:                        req.RPMLimit,
+		Priority:                        req.Priority,
+		RequestParamOverrides:           req.RequestParamOverrides,
+		SystemPrompt:                    req.SystemPrompt,
+		SystemPromptMode:                req.SystemPromptMode,
+		PIIRedactionMode:                req.PIIRedactionMode,
+		ModerationEnabled:               req.ModerationEnabled,
+		MaxBodySize:                     req.MaxBodySize,
+		ResponseHeaders:                 req.ResponseHeaders,
+		AccountTagSelector:              req.AccountTagSelector,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
@@ -319,6 +366,15 @@ func (h *GroupHandler) Update(c *gin.Context) {
 		DefaultMappedModel:              req.DefaultMappedModel,
 		MessagesDispatchModelConfig:     req.MessagesDispatchModelConfig,
 		RPMLimit:                        req.RPMLimit,
+		Priority:                        req.Priority,
+		RequestParamOverrides:           req.RequestParamOverrides,
+		SystemPrompt:                    req.SystemPrompt,
+		SystemPromptMode:                req.SystemPromptMode,
+		PIIRedactionMode:                req.PIIRedactionMode,
+		ModerationEnabled:               req.ModerationEnabled,
+		MaxBodySize:                     req.MaxBodySize,
+		ResponseHeaders:                 req.ResponseHeaders,
+		AccountTagSelector:              req.AccountTagSelector,
 		CopyAccountsFromGroupIDs:        req.CopyAccountsFromGroupIDs,
 	})
 	if err != nil {
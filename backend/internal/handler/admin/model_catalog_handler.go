@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ModelCatalogHandler handles admin queries and overrides for the model metadata registry.
+type ModelCatalogHandler struct {
+	modelCatalog *service.ModelCatalogService
+}
+
+// NewModelCatalogHandler creates a new ModelCatalogHandler.
+func NewModelCatalogHandler(modelCatalog *service.ModelCatalogService) *ModelCatalogHandler {
+	return &ModelCatalogHandler{modelCatalog: modelCatalog}
+}
+
+// Resolve returns the merged metadata of a model.
+// GET /api/v1/admin/model-catalog?model=xxx
+func (h *ModelCatalogHandler) Resolve(c *gin.Context) {
+	model := strings.TrimSpace(c.Query("model"))
+	if model == "" {
+		response.BadRequest(c, "model is required")
+		return
+	}
+	response.Success(c, h.modelCatalog.Resolve(c.Request.Context(), model))
+}
+
+// ListOverrides returns all admin model metadata overrides.
+// GET /api/v1/admin/model-catalog/overrides
+func (h *ModelCatalogHandler) ListOverrides(c *gin.Context) {
+	overrides, err := h.modelCatalog.ListOverrides(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, overrides)
+}
+
+// UpsertOverride creates or replaces the override of a model.
+// PUT /api/v1/admin/model-catalog/overrides
+func (h *ModelCatalogHandler) UpsertOverride(c *gin.Context) {
+	var req service.ModelCatalogEntry
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	saved, err := h.modelCatalog.UpsertOverride(c.Request.Context(), &req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, saved)
+}
+
+// DeleteOverride removes the override of a model.
+// DELETE /api/v1/admin/model-catalog/overrides?model=xxx
+func (h *ModelCatalogHandler) DeleteOverride(c *gin.Context) {
+	model := strings.TrimSpace(c.Query("model"))
+	if model == "" {
+		response.BadRequest(c, "model is required")
+		return
+	}
+	if err := h.modelCatalog.DeleteOverride(c.Request.Context(), model); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Model catalog override deleted successfully"})
+}
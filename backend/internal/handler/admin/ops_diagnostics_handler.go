@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// DownloadDiagnosticsBundle returns a sanitized diagnostics bundle (zip) for bug reports.
+// GET /api/v1/admin/ops/diagnostics?profiles=true&error_limit=200
+func (h *OpsHandler) DownloadDiagnosticsBundle(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+
+	opts := service.OpsDiagnosticsOptions{}
+	if v := strings.TrimSpace(c.Query("profiles")); v != "" {
+		includeProfiles, err := strconv.ParseBool(v)
+		if err != nil {
+			response.BadRequest(c, "Invalid profiles")
+			return
+		}
+		opts.IncludeProfiles = includeProfiles
+	}
+	if v := strings.TrimSpace(c.Query("error_limit")); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			response.BadRequest(c, "Invalid error_limit")
+			return
+		}
+		opts.ErrorLimit = limit
+	}
+
+	var buf bytes.Buffer
+	if err := h.opsService.WriteDiagnosticsBundle(c.Request.Context(), &buf, opts); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("sub2api-diagnostics-%s.zip", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
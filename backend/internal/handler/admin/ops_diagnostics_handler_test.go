@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func newOpsDiagnosticsTestRouter(handler *OpsHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/diagnostics", handler.DownloadDiagnosticsBundle)
+	return r
+}
+
+func TestOpsDiagnosticsHandler_Unavailable(t *testing.T) {
+	r := newOpsDiagnosticsTestRouter(NewOpsHandler(nil, nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/diagnostics", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status=%d, want 503", w.Code)
+	}
+}
+
+func TestOpsDiagnosticsHandler_InvalidParams(t *testing.T) {
+	svc := service.NewOpsService(nil, nil, &config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil)
+	r := newOpsDiagnosticsTestRouter(NewOpsHandler(svc, nil))
+
+	for _, query := range []string{"profiles=maybe", "error_limit=0", "error_limit=abc"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/diagnostics?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("query=%s status=%d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestOpsDiagnosticsHandler_DownloadsZip(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.JWT.Secret = "jwt-secret-value"
+	svc := service.NewOpsService(nil, nil, cfg, nil, nil, nil, nil, nil, nil, nil, nil)
+	r := newOpsDiagnosticsTestRouter(NewOpsHandler(svc, nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/diagnostics?profiles=false", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, want 200, body=%s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Fatalf("content-type=%q, want application/zip", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "attachment") || !strings.Contains(cd, ".zip") {
+		t.Fatalf("unexpected content-disposition %q", cd)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	if !names["manifest.json"] || !names["config.json"] || names["heap.pprof"] {
+		t.Fatalf("unexpected bundle files: %v", names)
+	}
+}
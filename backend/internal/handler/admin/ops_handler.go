@@ -16,7 +16,8 @@ import (
 )
 
 type OpsHandler struct {
-	opsService *service.OpsService
+	opsService          *service.OpsService
+	requestTraceService *service.OpsRequestTraceService
 }
 
 // GetErrorLogByID returns ops error log detail.
@@ -70,8 +71,8 @@ func parseOpsViewParam(c *gin.Context) string {
 	}
 }
 
-func NewOpsHandler(opsService *service.OpsService) *OpsHandler {
-	return &OpsHandler{opsService: opsService}
+func NewOpsHandler(opsService *service.OpsService, requestTraceService *service.OpsRequestTraceService) *OpsHandler {
+	return &OpsHandler{opsService: opsService, requestTraceService: requestTraceService}
 }
 
 // GetErrorLogs lists ops error logs.
@@ -675,6 +676,15 @@ func (h *OpsHandler) ListRequestDetails(c *gin.Context) {
 		filter.GroupID = &id
 	}
 
+	if v := strings.TrimSpace(c.Query("status_code")); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 100 || parsed > 599 {
+			response.BadRequest(c, "Invalid status_code")
+			return
+		}
+		filter.StatusCode = &parsed
+	}
+
 	if v := strings.TrimSpace(c.Query("min_duration_ms")); v != "" {
 		parsed, err := strconv.Atoi(v)
 		if err != nil || parsed < 0 {
@@ -706,6 +716,23 @@ func (h *OpsHandler) ListRequestDetails(c *gin.Context) {
 	response.Paginated(c, out.Items, out.Total, out.Page, out.PageSize)
 }
 
+// GetRequestTrace returns the stored trace of a single request: upstream error events,
+// latency breakdown, selected account and the request body snapshot.
+// GET /api/v1/admin/ops/requests/:id (id = request_id or client_request_id)
+func (h *OpsHandler) GetRequestTrace(c *gin.Context) {
+	if h.requestTraceService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+
+	trace, err := h.requestTraceService.GetRequestTrace(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, trace)
+}
+
 type opsRetryRequest struct {
 	Mode            string `json:"mode"`
 	PinnedAccountID *int64 `json:"pinned_account_id"`
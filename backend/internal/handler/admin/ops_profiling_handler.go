@@ -0,0 +1,147 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// pprof 默认采样时长（与 net/http/pprof 保持一致）
+var opsPprofDefaultSeconds = map[string]int{
+	"profile": 30,
+	"trace":   1,
+}
+
+// ServePprof exposes net/http/pprof profiles behind admin auth.
+// GET /api/v1/admin/ops/pprof/
+// GET /api/v1/admin/ops/pprof/:profile (profile, trace, symbol, goroutine, heap, allocs, block, mutex, threadcreate)
+func (h *OpsHandler) ServePprof(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireProfilingEnabled(); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+
+	name := strings.Trim(c.Param("profile"), "/")
+	if defaultSeconds, ok := opsPprofDefaultSeconds[name]; ok {
+		seconds, explicit := defaultSeconds, false
+		if v := strings.TrimSpace(c.Query("seconds")); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed <= 0 {
+				response.BadRequest(c, "Invalid seconds")
+				return
+			}
+			seconds, explicit = parsed, true
+		}
+		maxSeconds := int(h.opsService.MaxProfileDuration() / time.Second)
+		if seconds > maxSeconds {
+			if explicit {
+				response.BadRequest(c, "seconds exceeds max "+strconv.Itoa(maxSeconds))
+				return
+			}
+			seconds = maxSeconds
+		}
+		query := c.Request.URL.Query()
+		query.Set("seconds", strconv.Itoa(seconds))
+		c.Request.URL.RawQuery = query.Encode()
+	}
+
+	c.Header("Cache-Control", "no-store")
+	switch name {
+	case "":
+		pprof.Index(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "cmdline":
+		// 命令行参数可能包含敏感信息，不对外暴露
+		response.NotFound(c, "Unknown profile")
+	default:
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// GetLogLevelOverride returns the current log level and temporary override status.
+// GET /api/v1/admin/ops/runtime/log-level
+func (h *OpsHandler) GetLogLevelOverride(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	if err := h.opsService.RequireProfilingEnabled(); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, h.opsService.GetLogLevelOverride())
+}
+
+type setLogLevelOverrideRequest struct {
+	Level           string `json:"level" binding:"required"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+// SetLogLevelOverride temporarily changes the log level; it reverts automatically when the duration elapses.
+// PUT /api/v1/admin/ops/runtime/log-level
+func (h *OpsHandler) SetLogLevelOverride(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+
+	var req setLogLevelOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	if req.DurationMinutes < 0 {
+		response.BadRequest(c, "Invalid duration_minutes")
+		return
+	}
+
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	updated, err := h.opsService.SetLogLevelOverride(req.Level, time.Duration(req.DurationMinutes)*time.Minute, subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
+// ClearLogLevelOverride ends the temporary override and restores the baseline log level.
+// DELETE /api/v1/admin/ops/runtime/log-level
+func (h *OpsHandler) ClearLogLevelOverride(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	updated, err := h.opsService.ClearLogLevelOverride(subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
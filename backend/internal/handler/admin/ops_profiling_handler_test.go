@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func newOpsProfilingTestRouter(cfg *config.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	svc := service.NewOpsService(nil, nil, cfg, nil, nil, nil, nil, nil, nil, nil, nil)
+	h := NewOpsHandler(svc, nil)
+	r := gin.New()
+	r.GET("/pprof/*profile", h.ServePprof)
+	r.PUT("/log-level", h.SetLogLevelOverride)
+	return r
+}
+
+func newOpsProfilingTestConfig(enabled bool) *config.Config {
+	cfg := &config.Config{}
+	cfg.Ops.Profiling = config.OpsProfilingConfig{Enabled: enabled, MaxProfileSeconds: 5, MaxLogLevelOverrideMinutes: 10}
+	return cfg
+}
+
+func TestOpsProfilingHandler_Disabled(t *testing.T) {
+	r := newOpsProfilingTestRouter(newOpsProfilingTestConfig(false))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pprof/goroutine", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status=%d, want 404", w.Code)
+	}
+}
+
+func TestOpsProfilingHandler_ServesProfiles(t *testing.T) {
+	r := newOpsProfilingTestRouter(newOpsProfilingTestConfig(true))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pprof/", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine") {
+		t.Fatalf("index status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pprof/goroutine?debug=1", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Fatalf("goroutine status=%d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pprof/cmdline", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("cmdline status=%d, want 404", w.Code)
+	}
+}
+
+func TestOpsProfilingHandler_RejectsInvalidSeconds(t *testing.T) {
+	r := newOpsProfilingTestRouter(newOpsProfilingTestConfig(true))
+
+	for _, query := range []string{"seconds=0", "seconds=abc", "seconds=6"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pprof/profile?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("query=%s status=%d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestOpsProfilingHandler_SetLogLevelInvalidBody(t *testing.T) {
+	r := newOpsProfilingTestRouter(newOpsProfilingTestConfig(true))
+
+	for _, body := range []string{`{}`, `{"level":"debug","duration_minutes":-1}`} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/log-level", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("body=%s status=%d, want 400", body, w.Code)
+		}
+	}
+}
@@ -63,6 +63,22 @@ func (h *OpsHandler) GetConcurrencyStats(c *gin.Context) {
 	response.Success(c, payload)
 }
 
+// GetConcurrencySlots returns raw concurrency slot occupancy per account/user, the slots held by
+// this instance (with request IDs) and stuck-slot reaper statistics.
+// GET /api/v1/admin/ops/concurrency-slots
+func (h *OpsHandler) GetConcurrencySlots(c *gin.Context) {
+	if h.opsService == nil {
+		response.Error(c, http.StatusServiceUnavailable, "Ops service not available")
+		return
+	}
+	snapshot, err := h.opsService.GetConcurrencySlotSnapshot(c.Request.Context())
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, snapshot)
+}
+
 // GetUserConcurrencyStats returns real-time concurrency usage for all active users.
 // GET /api/v1/admin/ops/user-concurrency
 func (h *OpsHandler) GetUserConcurrencyStats(c *gin.Context) {
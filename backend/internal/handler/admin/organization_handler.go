@@ -0,0 +1,253 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler handles admin organization management
+type OrganizationHandler struct {
+	organizationService *service.OrganizationService
+}
+
+// NewOrganizationHandler creates a new admin organization handler
+func NewOrganizationHandler(organizationService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService}
+}
+
+type CreateOrganizationRequest struct {
+	Name             string  `json:"name" binding:"required"`
+	Description      string  `json:"description"`
+	Status           string  `json:"status" binding:"omitempty,oneof=active disabled"`
+	MonthlyBudgetUSD float64 `json:"monthly_budget_usd"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name             *string  `json:"name"`
+	Description      *string  `json:"description"`
+	Status           *string  `json:"status" binding:"omitempty,oneof=active disabled"`
+	MonthlyBudgetUSD *float64 `json:"monthly_budget_usd"`
+}
+
+type AddOrganizationMemberRequest struct {
+	UserID  int64  `json:"user_id" binding:"required"`
+	OrgRole string `json:"org_role" binding:"omitempty,oneof=member admin"`
+}
+
+// List handles listing organizations
+// GET /api/v1/admin/organizations
+func (h *OrganizationHandler) List(c *gin.Context) {
+	page, pageSize := response.ParsePagination(c)
+	search := strings.TrimSpace(c.Query("search"))
+	if len(search) > 100 {
+		search = search[:100]
+	}
+	filters := service.OrganizationListFilters{
+		Status: strings.TrimSpace(c.Query("status")),
+		Search: search,
+	}
+
+	orgs, total, err := h.organizationService.List(c.Request.Context(), page, pageSize, filters, c.DefaultQuery("sort_by", "created_at"), c.DefaultQuery("sort_order", "desc"))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.Organization, 0, len(orgs))
+	for i := range orgs {
+		out = append(out, *dto.OrganizationFromService(&orgs[i]))
+	}
+	response.Paginated(c, out, total, page, pageSize)
+}
+
+// GetByID handles getting an organization by ID
+// GET /api/v1/admin/organizations/:id
+func (h *OrganizationHandler) GetByID(c *gin.Context) {
+	orgID, ok := parseOrganizationIDParam(c)
+	if !ok {
+		return
+	}
+	org, err := h.organizationService.Get(c.Request.Context(), orgID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.OrganizationFromService(org))
+}
+
+// Create handles creating an organization
+// POST /api/v1/admin/organizations
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	org, err := h.organizationService.Create(c.Request.Context(), &service.CreateOrganizationInput{
+		Name:             req.Name,
+		Description:      req.Description,
+		Status:           req.Status,
+		MonthlyBudgetUSD: req.MonthlyBudgetUSD,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.OrganizationFromService(org))
+}
+
+// Update handles updating an organization
+// PUT /api/v1/admin/organizations/:id
+func (h *OrganizationHandler) Update(c *gin.Context) {
+	orgID, ok := parseOrganizationIDParam(c)
+	if !ok {
+		return
+	}
+	var req UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	org, err := h.organizationService.Update(c.Request.Context(), orgID, &service.UpdateOrganizationInput{
+		Name:             req.Name,
+		Description:      req.Description,
+		Status:           req.Status,
+		MonthlyBudgetUSD: req.MonthlyBudgetUSD,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.OrganizationFromService(org))
+}
+
+// Delete handles deleting an organization (must have no members)
+// DELETE /api/v1/admin/organizations/:id
+func (h *OrganizationHandler) Delete(c *gin.Context) {
+	orgID, ok := parseOrganizationIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.organizationService.Delete(c.Request.Context(), orgID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Organization deleted successfully"})
+}
+
+// AddMember handles adding a user to an organization
+// POST /api/v1/admin/organizations/:id/members
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	orgID, ok := parseOrganizationIDParam(c)
+	if !ok {
+		return
+	}
+	var req AddOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	user, err := h.organizationService.AddMember(c.Request.Context(), orgID, req.UserID, req.OrgRole)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UserFromServiceAdmin(user))
+}
+
+// RemoveMember handles removing a user from an organization
+// DELETE /api/v1/admin/organizations/:id/members/:user_id
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, ok := parseOrganizationIDParam(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil || userID <= 0 {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+	if err := h.organizationService.RemoveMember(c.Request.Context(), orgID, userID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Member removed successfully"})
+}
+
+// AssignGroup handles assigning an exclusive group to an organization
+// PUT /api/v1/admin/organizations/:id/groups/:group_id
+func (h *OrganizationHandler) AssignGroup(c *gin.Context) {
+	orgID, ok := parseOrganizationIDParam(c)
+	if !ok {
+		return
+	}
+	groupID, ok := parseOrganizationGroupIDParam(c)
+	if !ok {
+		return
+	}
+	group, err := h.organizationService.AssignGroup(c.Request.Context(), orgID, groupID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.GroupFromServiceAdmin(group))
+}
+
+// UnassignGroup handles detaching a group from an organization
+// DELETE /api/v1/admin/organizations/:id/groups/:group_id
+func (h *OrganizationHandler) UnassignGroup(c *gin.Context) {
+	orgID, ok := parseOrganizationIDParam(c)
+	if !ok {
+		return
+	}
+	groupID, ok := parseOrganizationGroupIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.organizationService.UnassignGroup(c.Request.Context(), orgID, groupID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Group detached successfully"})
+}
+
+func parseOrganizationIDParam(c *gin.Context) (int64, bool) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orgID <= 0 {
+		response.BadRequest(c, "Invalid organization ID")
+		return 0, false
+	}
+	return orgID, true
+}
+
+func parseOrganizationGroupIDParam(c *gin.Context) (int64, bool) {
+	groupID, err := strconv.ParseInt(c.Param("group_id"), 10, 64)
+	if err != nil || groupID <= 0 {
+		response.BadRequest(c, "Invalid group ID")
+		return 0, false
+	}
+	return groupID, true
+}
+
+// parseOrganizationIDQuery 解析列表接口的 organization_id 过滤参数（未传返回 nil）。
+// 支持该参数的管理端列表：用户、分组、订阅、使用记录。以下列表不做组织过滤：
+//   - 账号：上游账号为平台级资源，由平台管理员统一维护，组织只能通过分组间接使用；
+//   - 兑换码：由平台签发，兑换前不归属任何组织；
+//   - API Key：管理端没有全局 API Key 列表，仅能经 /users/:id 与 /groups/:id 查看，上层列表已支持组织过滤。
+func parseOrganizationIDQuery(c *gin.Context) (*int64, bool) {
+	raw := strings.TrimSpace(c.Query("organization_id"))
+	if raw == "" {
+		return nil, true
+	}
+	orgID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || orgID <= 0 {
+		response.BadRequest(c, "Invalid organization_id")
+		return nil, false
+	}
+	return &orgID, true
+}
@@ -32,6 +32,8 @@ type CreateProxyRequest struct {
 	Port     int    `json:"port" binding:"required,min=1,max=65535"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// MaxConcurrency 经该代理的最大并发上游请求数，0 表示不限制
+	MaxConcurrency int `json:"max_concurrency" binding:"omitempty,min=0"`
 }
 
 // UpdateProxyRequest represents update proxy request
@@ -43,6 +45,8 @@ type UpdateProxyRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Status   string `json:"status" binding:"omitempty,oneof=active inactive"`
+	// MaxConcurrency 未传时保持不变
+	MaxConcurrency *int `json:"max_concurrency" binding:"omitempty,min=0"`
 }
 
 // List handles listing all proxies with pagination
@@ -135,12 +139,13 @@ func (h *ProxyHandler) Create(c *gin.Context) {
 
 	executeAdminIdempotentJSON(c, "admin.proxies.create", req, service.DefaultWriteIdempotencyTTL(), func(ctx context.Context) (any, error) {
 		proxy, err := h.adminService.CreateProxy(ctx, &service.CreateProxyInput{
-			Name:     strings.TrimSpace(req.Name),
-			Protocol: strings.TrimSpace(req.Protocol),
-			Host:     strings.TrimSpace(req.Host),
-			Port:     req.Port,
-			Username: strings.TrimSpace(req.Username),
-			Password: strings.TrimSpace(req.Password),
+			Name:           strings.TrimSpace(req.Name),
+			Protocol:       strings.TrimSpace(req.Protocol),
+			Host:           strings.TrimSpace(req.Host),
+			Port:           req.Port,
+			Username:       strings.TrimSpace(req.Username),
+			Password:       strings.TrimSpace(req.Password),
+			MaxConcurrency: req.MaxConcurrency,
 		})
 		if err != nil {
 			return nil, err
@@ -165,13 +170,14 @@ func (h *ProxyHandler) Update(c *gin.Context) {
 	}
 
 	proxy, err := h.adminService.UpdateProxy(c.Request.Context(), proxyID, &service.UpdateProxyInput{
-		Name:     strings.TrimSpace(req.Name),
-		Protocol: strings.TrimSpace(req.Protocol),
-		Host:     strings.TrimSpace(req.Host),
-		Port:     req.Port,
-		Username: strings.TrimSpace(req.Username),
-		Password: strings.TrimSpace(req.Password),
-		Status:   strings.TrimSpace(req.Status),
+		Name:           strings.TrimSpace(req.Name),
+		Protocol:       strings.TrimSpace(req.Protocol),
+		Host:           strings.TrimSpace(req.Host),
+		Port:           req.Port,
+		Username:       strings.TrimSpace(req.Username),
+		Password:       strings.TrimSpace(req.Password),
+		Status:         strings.TrimSpace(req.Status),
+		MaxConcurrency: req.MaxConcurrency,
 	})
 	if err != nil {
 		response.ErrorFrom(c, err)
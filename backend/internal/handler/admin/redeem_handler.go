@@ -54,6 +54,7 @@ type CreateAndRedeemCodeRequest struct {
 
 // List handles listing all redeem codes with pagination
 // GET /api/v1/admin/redeem-codes
+// Redeem codes are issued by the platform and are not scoped by organization.
 func (h *RedeemHandler) List(c *gin.Context) {
 	page, pageSize := response.ParsePagination(c)
 	codeType := c.Query("type")
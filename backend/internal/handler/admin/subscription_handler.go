@@ -61,6 +61,7 @@ type AdjustSubscriptionRequest struct {
 
 // List handles listing all subscriptions with pagination and filters
 // GET /api/v1/admin/subscriptions
+// organization_id restricts results to subscriptions of the organization's members.
 func (h *SubscriptionHandler) List(c *gin.Context) {
 	page, pageSize := response.ParsePagination(c)
 
@@ -76,6 +77,10 @@ func (h *SubscriptionHandler) List(c *gin.Context) {
 			groupID = &id
 		}
 	}
+	organizationID, ok := parseOrganizationIDQuery(c)
+	if !ok {
+		return
+	}
 	status := c.Query("status")
 	platform := c.Query("platform")
 
@@ -83,7 +88,7 @@ func (h *SubscriptionHandler) List(c *gin.Context) {
 	sortBy := c.DefaultQuery("sort_by", "created_at")
 	sortOrder := c.DefaultQuery("sort_order", "desc")
 
-	subscriptions, pagination, err := h.subscriptionService.List(c.Request.Context(), page, pageSize, userID, groupID, status, platform, sortBy, sortOrder)
+	subscriptions, pagination, err := h.subscriptionService.List(c.Request.Context(), page, pageSize, userID, groupID, organizationID, status, platform, sortBy, sortOrder)
 	if err != nil {
 		response.ErrorFrom(c, err)
 		return
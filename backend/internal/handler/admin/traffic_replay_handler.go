@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TrafficReplayHandler handles admin traffic replay jobs (replaying usage logs against an account).
+type TrafficReplayHandler struct {
+	replayService *service.TrafficReplayService
+}
+
+// NewTrafficReplayHandler creates a new TrafficReplayHandler.
+func NewTrafficReplayHandler(replayService *service.TrafficReplayService) *TrafficReplayHandler {
+	return &TrafficReplayHandler{replayService: replayService}
+}
+
+// Create starts a traffic replay job in the background.
+// POST /api/v1/admin/traffic-replays
+func (h *TrafficReplayHandler) Create(c *gin.Context) {
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok || subject.UserID <= 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	var req service.TrafficReplayInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	job, err := h.replayService.Start(c.Request.Context(), subject.UserID, req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, job)
+}
+
+// List returns retained traffic replay jobs without per-sample results.
+// GET /api/v1/admin/traffic-replays
+func (h *TrafficReplayHandler) List(c *gin.Context) {
+	response.Success(c, h.replayService.List())
+}
+
+// Get returns a traffic replay job with its comparison report and samples.
+// GET /api/v1/admin/traffic-replays/:id
+func (h *TrafficReplayHandler) Get(c *gin.Context) {
+	job, err := h.replayService.Get(strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, job)
+}
+
+// Cancel stops a running traffic replay job.
+// POST /api/v1/admin/traffic-replays/:id/cancel
+func (h *TrafficReplayHandler) Cancel(c *gin.Context) {
+	job, err := h.replayService.Cancel(strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, job)
+}
@@ -109,8 +109,14 @@ func (h *UsageHandler) List(c *gin.Context) {
 		groupID = id
 	}
 
+	organizationID, ok := parseOrganizationIDQuery(c)
+	if !ok {
+		return
+	}
+
 	model := c.Query("model")
 	billingMode := strings.TrimSpace(c.Query("billing_mode"))
+	requestID := strings.TrimSpace(c.Query("request_id"))
 
 	var requestType *int16
 	var stream *bool
@@ -183,8 +189,12 @@ func (h *UsageHandler) List(c *gin.Context) {
 		BillingMode: billingMode,
 		StartTime:   startTime,
 		EndTime:     endTime,
+		RequestID:   requestID,
 		ExactTotal:  exactTotal,
 	}
+	if organizationID != nil {
+		filters.OrganizationID = *organizationID
+	}
 
 	records, result, err := h.usageService.ListWithFilters(c.Request.Context(), params, filters)
 	if err != nil {
@@ -240,6 +250,11 @@ func (h *UsageHandler) Stats(c *gin.Context) {
 		groupID = id
 	}
 
+	organizationID, ok := parseOrganizationIDQuery(c)
+	if !ok {
+		return
+	}
+
 	model := c.Query("model")
 	billingMode := strings.TrimSpace(c.Query("billing_mode"))
 
@@ -324,6 +339,9 @@ func (h *UsageHandler) Stats(c *gin.Context) {
 		StartTime:   &startTime,
 		EndTime:     &endTime,
 	}
+	if organizationID != nil {
+		filters.OrganizationID = *organizationID
+	}
 
 	stats, err := h.usageService.GetStatsWithFilters(c.Request.Context(), filters)
 	if err != nil {
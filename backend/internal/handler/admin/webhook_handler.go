@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles admin webhook notification configuration.
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// GetConfig returns the webhook notification config.
+// GET /api/v1/admin/webhooks/config
+func (h *WebhookHandler) GetConfig(c *gin.Context) {
+	cfg, err := h.webhookService.GetConfig(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "Failed to get webhook config")
+		return
+	}
+	response.Success(c, cfg)
+}
+
+// UpdateConfig replaces the webhook notification config.
+// PUT /api/v1/admin/webhooks/config
+func (h *WebhookHandler) UpdateConfig(c *gin.Context) {
+	var req service.WebhookConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+	updated, err := h.webhookService.UpdateConfig(c.Request.Context(), &req)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, updated)
+}
+
+// Test sends a test event to the endpoint at the given index of the saved config.
+// POST /api/v1/admin/webhooks/endpoints/:index/test
+func (h *WebhookHandler) Test(c *gin.Context) {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		response.BadRequest(c, "Invalid endpoint index")
+		return
+	}
+	if err := h.webhookService.SendTest(c.Request.Context(), index); err != nil {
+		if errors.Is(err, service.ErrWebhookEndpointNotFound) {
+			response.ErrorFrom(c, err)
+			return
+		}
+		response.Error(c, http.StatusBadGateway, "Webhook delivery failed: "+err.Error())
+		return
+	}
+	response.Success(c, gin.H{"delivered": true})
+}
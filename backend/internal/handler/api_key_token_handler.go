@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyTokenHandler 子令牌接口（/v1/tokens）：以 API Key 认证，签发/列出/撤销短期子令牌
+type APIKeyTokenHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+// NewAPIKeyTokenHandler creates a new APIKeyTokenHandler
+func NewAPIKeyTokenHandler(apiKeyService *service.APIKeyService) *APIKeyTokenHandler {
+	return &APIKeyTokenHandler{apiKeyService: apiKeyService}
+}
+
+// createAPIKeyTokenRequest 签发子令牌请求体
+type createAPIKeyTokenRequest struct {
+	Name          string   `json:"name"`
+	TTLSeconds    int      `json:"ttl_seconds"`
+	AllowedModels []string `json:"allowed_models"`
+	Budget        float64  `json:"budget"`
+}
+
+// apiKeyTokenResponse 子令牌对象；key 仅在签发时返回
+type apiKeyTokenResponse struct {
+	ID            int64      `json:"id"`
+	Type          string     `json:"type"`
+	Key           string     `json:"key,omitempty"`
+	Name          string     `json:"name"`
+	ParentID      int64      `json:"parent_id"`
+	Status        string     `json:"status"`
+	AllowedModels []string   `json:"allowed_models"`
+	Budget        float64    `json:"budget"`
+	BudgetUsed    float64    `json:"budget_used"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func toAPIKeyTokenResponse(token *service.APIKey, withKey bool) apiKeyTokenResponse {
+	out := apiKeyTokenResponse{
+		ID:            token.ID,
+		Type:          "api_key_token",
+		Name:          token.Name,
+		ParentID:      token.ParentID,
+		Status:        token.Status,
+		AllowedModels: token.AllowedModels,
+		Budget:        token.Quota,
+		BudgetUsed:    token.QuotaUsed,
+		ExpiresAt:     token.ExpiresAt,
+		CreatedAt:     token.CreatedAt,
+	}
+	if out.AllowedModels == nil {
+		out.AllowedModels = []string{}
+	}
+	if withKey {
+		out.Key = token.Key
+	}
+	return out
+}
+
+// Create 签发子令牌
+// POST /v1/tokens
+func (h *APIKeyTokenHandler) Create(c *gin.Context) {
+	apiKey, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	var req createAPIKeyTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			messageBatchError(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		messageBatchError(c, http.StatusBadRequest, "invalid_request_error", "Invalid request body: "+err.Error())
+		return
+	}
+
+	token, err := h.apiKeyService.CreateChildToken(c.Request.Context(), apiKey, service.CreateChildTokenRequest{
+		Name:          req.Name,
+		TTLSeconds:    req.TTLSeconds,
+		AllowedModels: req.AllowedModels,
+		Budget:        req.Budget,
+	})
+	if err != nil {
+		writeAPIKeyTokenError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toAPIKeyTokenResponse(token, true))
+}
+
+// List 列出当前 API Key 签发的子令牌
+// GET /v1/tokens
+func (h *APIKeyTokenHandler) List(c *gin.Context) {
+	apiKey, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	tokens, err := h.apiKeyService.ListChildTokens(c.Request.Context(), apiKey.ID)
+	if err != nil {
+		writeAPIKeyTokenError(c, err)
+		return
+	}
+	data := make([]apiKeyTokenResponse, 0, len(tokens))
+	for i := range tokens {
+		data = append(data, toAPIKeyTokenResponse(&tokens[i], false))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// Revoke 撤销子令牌并退还未使用的预算
+// DELETE /v1/tokens/:id
+func (h *APIKeyTokenHandler) Revoke(c *gin.Context) {
+	apiKey, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		messageBatchError(c, http.StatusNotFound, "not_found_error", "token not found")
+		return
+	}
+	if err := h.apiKeyService.RevokeChildToken(c.Request.Context(), apiKey.ID, id); err != nil {
+		writeAPIKeyTokenError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "type": "api_key_token_deleted"})
+}
+
+func (h *APIKeyTokenHandler) authContext(c *gin.Context) (*service.APIKey, bool) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		messageBatchError(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return nil, false
+	}
+	return apiKey, true
+}
+
+func writeAPIKeyTokenError(c *gin.Context, err error) {
+	status := infraerrors.Code(err)
+	message := infraerrors.Message(err)
+	errType := "api_error"
+	switch status {
+	case http.StatusBadRequest:
+		errType = "invalid_request_error"
+	case http.StatusUnauthorized:
+		errType = "authentication_error"
+	case http.StatusForbidden:
+		errType = "permission_error"
+	case http.StatusNotFound:
+		errType = "not_found_error"
+	default:
+		status = http.StatusInternalServerError
+		message = "Internal server error"
+		_ = c.Error(err)
+	}
+	messageBatchError(c, status, errType, message)
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// AdminAuditLog 管理员操作审计记录；快照与请求体均已脱敏
+type AdminAuditLog struct {
+	ID          int64           `json:"id"`
+	ActorUserID int64           `json:"actor_user_id"`
+	ActorEmail  string          `json:"actor_email"`
+	AuthMethod  string          `json:"auth_method"`
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	Resource    string          `json:"resource"`
+	ResourceID  *int64          `json:"resource_id"`
+	StatusCode  int             `json:"status_code"`
+	IP          string          `json:"ip"`
+	UserAgent   string          `json:"user_agent"`
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+	Before      json.RawMessage `json:"before,omitempty"`
+	After       json.RawMessage `json:"after,omitempty"`
+	Diff        json.RawMessage `json:"diff,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func AdminAuditLogFromService(l *service.AdminAuditLog) *AdminAuditLog {
+	if l == nil {
+		return nil
+	}
+	return &AdminAuditLog{
+		ID:          l.ID,
+		ActorUserID: l.ActorUserID,
+		ActorEmail:  l.ActorEmail,
+		AuthMethod:  l.AuthMethod,
+		Method:      l.Method,
+		Path:        l.Path,
+		Resource:    l.Resource,
+		ResourceID:  l.ResourceID,
+		StatusCode:  l.StatusCode,
+		IP:          l.IP,
+		UserAgent:   l.UserAgent,
+		RequestBody: l.RequestBody,
+		Before:      l.Before,
+		After:       l.After,
+		Diff:        l.Diff,
+		CreatedAt:   l.CreatedAt,
+	}
+}
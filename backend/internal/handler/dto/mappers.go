@@ -30,6 +30,8 @@ func UserFromServiceShallow(u *service.User) *User {
 		BalanceNotifyExtraEmails:   NotifyEmailEntriesFromService(u.BalanceNotifyExtraEmails),
 		TotalRecharged:             u.TotalRecharged,
 		RPMLimit:                   u.RPMLimit,
+		OrganizationID:             u.OrganizationID,
+		OrgRole:                    u.OrgRole,
 	}
 }
 
@@ -78,31 +80,44 @@ func APIKeyFromService(k *service.APIKey) *APIKey {
 		return nil
 	}
 	out := &APIKey{
-		ID:            k.ID,
-		UserID:        k.UserID,
-		Key:           k.Key,
-		Name:          k.Name,
-		GroupID:       k.GroupID,
-		Status:        k.Status,
-		IPWhitelist:   k.IPWhitelist,
-		IPBlacklist:   k.IPBlacklist,
-		LastUsedAt:    k.LastUsedAt,
-		Quota:         k.Quota,
-		QuotaUsed:     k.QuotaUsed,
-		ExpiresAt:     k.ExpiresAt,
-		CreatedAt:     k.CreatedAt,
-		UpdatedAt:     k.UpdatedAt,
-		RateLimit5h:   k.RateLimit5h,
-		RateLimit1d:   k.RateLimit1d,
-		RateLimit7d:   k.RateLimit7d,
-		Usage5h:       k.EffectiveUsage5h(),
-		Usage1d:       k.EffectiveUsage1d(),
-		Usage7d:       k.EffectiveUsage7d(),
-		Window5hStart: k.Window5hStart,
-		Window1dStart: k.Window1dStart,
-		Window7dStart: k.Window7dStart,
-		User:          UserFromServiceShallow(k.User),
-		Group:         GroupFromServiceShallow(k.Group),
+		ID:                    k.ID,
+		UserID:                k.UserID,
+		Key:                   k.Key,
+		Name:                  k.Name,
+		GroupID:               k.GroupID,
+		Status:                k.Status,
+		IPWhitelist:           k.IPWhitelist,
+		IPBlacklist:           k.IPBlacklist,
+		Scopes:                k.Scopes,
+		Priority:              k.Priority,
+		ModerationMode:        k.ModerationMode,
+		ContextAutoTrim:       k.ContextAutoTrim,
+		MaxBodySize:           k.MaxBodySize,
+		RequestCoalescing:     k.RequestCoalescing,
+		TokenBucketBurst:      k.TokenBucketBurst,
+		TokenBucketRefillRate: k.TokenBucketRefillRate,
+		MaxCostPerRequest:     k.MaxCostPerRequest,
+		AccountTagSelector:    k.AccountTagSelector,
+		AllowedPlatforms:      k.AllowedPlatforms,
+		ParentID:              k.ParentID,
+		AllowedModels:         k.AllowedModels,
+		LastUsedAt:            k.LastUsedAt,
+		Quota:                 k.Quota,
+		QuotaUsed:             k.QuotaUsed,
+		ExpiresAt:             k.ExpiresAt,
+		CreatedAt:             k.CreatedAt,
+		UpdatedAt:             k.UpdatedAt,
+		RateLimit5h:           k.RateLimit5h,
+		RateLimit1d:           k.RateLimit1d,
+		RateLimit7d:           k.RateLimit7d,
+		Usage5h:               k.EffectiveUsage5h(),
+		Usage1d:               k.EffectiveUsage1d(),
+		Usage7d:               k.EffectiveUsage7d(),
+		Window5hStart:         k.Window5hStart,
+		Window1dStart:         k.Window1dStart,
+		Window7dStart:         k.Window7dStart,
+		User:                  UserFromServiceShallow(k.User),
+		Group:                 GroupFromServiceShallow(k.Group),
 	}
 	if k.Window5hStart != nil && !service.IsWindowExpired(k.Window5hStart, service.RateLimitWindow5h) {
 		t := k.Window5hStart.Add(service.RateLimitWindow5h)
@@ -147,6 +162,15 @@ func GroupFromServiceAdmin(g *service.Group) *AdminGroup {
 		MCPXMLInject:                g.MCPXMLInject,
 		DefaultMappedModel:          g.DefaultMappedModel,
 		MessagesDispatchModelConfig: g.MessagesDispatchModelConfig,
+		RequestParamOverrides:       g.RequestParamOverrides,
+		SystemPrompt:                g.SystemPrompt,
+		SystemPromptMode:            g.SystemPromptMode,
+		PIIRedactionMode:            g.PIIRedactionMode,
+		ModerationEnabled:           g.ModerationEnabled,
+		MaxBodySize:                 g.MaxBodySize,
+		ResponseHeaders:             g.ResponseHeaders,
+		AccountTagSelector:          g.AccountTagSelector,
+		OrganizationID:              g.OrganizationID,
 		SupportedModelScopes:        g.SupportedModelScopes,
 		AccountCount:                g.AccountCount,
 		ActiveAccountCount:          g.ActiveAccountCount,
@@ -186,6 +210,7 @@ func groupFromServiceBase(g *service.Group) Group {
 		RequireOAuthOnly:                g.RequireOAuthOnly,
 		RequirePrivacySet:               g.RequirePrivacySet,
 		RPMLimit:                        g.RPMLimit,
+		Priority:                        g.Priority,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
@@ -216,6 +241,9 @@ func AccountFromServiceShallow(a *service.Account) *Account {
 		CreatedAt:               a.CreatedAt,
 		UpdatedAt:               a.UpdatedAt,
 		Schedulable:             a.Schedulable,
+		OwnerUserID:             a.OwnerUserID,
+		Tags:                    a.Tags,
+		TagsExclusive:           a.TagsExclusive,
 		RateLimitedAt:           a.RateLimitedAt,
 		RateLimitResetAt:        a.RateLimitResetAt,
 		OverloadUntil:           a.OverloadUntil,
@@ -226,6 +254,8 @@ func AccountFromServiceShallow(a *service.Account) *Account {
 		SessionWindowStatus:     a.SessionWindowStatus,
 		GroupIDs:                a.GroupIDs,
 	}
+	out.CooldownUntil, out.CooldownReason = a.ActiveCooldown(time.Now())
+	out.UpstreamRateLimit = a.GetUpstreamRateLimit()
 
 	// 提取 5h 窗口费用控制和会话数量控制配置（仅 Anthropic OAuth/SetupToken 账号有效）
 	if a.IsAnthropicOAuthOrSetupToken() {
@@ -356,6 +386,30 @@ func AccountFromServiceShallow(a *service.Account) *Account {
 	return out
 }
 
+// UserAccountFromService 用户侧账号视图，不回显凭证
+func UserAccountFromService(a *service.Account) *UserAccount {
+	if a == nil {
+		return nil
+	}
+	out := &UserAccount{
+		ID:           a.ID,
+		Name:         a.Name,
+		Platform:     a.Platform,
+		Type:         a.Type,
+		Status:       a.Status,
+		ErrorMessage: a.ErrorMessage,
+		Schedulable:  a.Schedulable,
+		GroupIDs:     a.GroupIDs,
+		LastUsedAt:   a.LastUsedAt,
+		CreatedAt:    a.CreatedAt,
+	}
+	if out.GroupIDs == nil {
+		out.GroupIDs = []int64{}
+	}
+	out.CooldownUntil, out.CooldownReason = a.ActiveCooldown(time.Now())
+	return out
+}
+
 func AccountFromService(a *service.Account) *Account {
 	if a == nil {
 		return nil
@@ -405,15 +459,16 @@ func ProxyFromService(p *service.Proxy) *Proxy {
 		return nil
 	}
 	return &Proxy{
-		ID:        p.ID,
-		Name:      p.Name,
-		Protocol:  p.Protocol,
-		Host:      p.Host,
-		Port:      p.Port,
-		Username:  p.Username,
-		Status:    p.Status,
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
+		ID:             p.ID,
+		Name:           p.Name,
+		Protocol:       p.Protocol,
+		Host:           p.Host,
+		Port:           p.Port,
+		Username:       p.Username,
+		Status:         p.Status,
+		MaxConcurrency: p.MaxConcurrency,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
 	}
 }
 
@@ -563,6 +618,10 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 	if requestedModel == "" {
 		requestedModel = l.Model
 	}
+	var cacheHitRatio *float64
+	if ratio, ok := service.PromptCacheHitRatio(l.InputTokens, l.CacheCreationTokens, l.CacheReadTokens); ok {
+		cacheHitRatio = &ratio
+	}
 	return UsageLog{
 		ID:                    l.ID,
 		UserID:                l.UserID,
@@ -582,6 +641,7 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		CacheReadTokens:       l.CacheReadTokens,
 		CacheCreation5mTokens: l.CacheCreation5mTokens,
 		CacheCreation1hTokens: l.CacheCreation1hTokens,
+		CacheHitRatio:         cacheHitRatio,
 		InputCost:             l.InputCost,
 		OutputCost:            l.OutputCost,
 		CacheCreationCost:     l.CacheCreationCost,
@@ -600,6 +660,7 @@ func usageLogFromServiceUser(l *service.UsageLog) UsageLog {
 		MediaType:             l.MediaType,
 		UserAgent:             l.UserAgent,
 		CacheTTLOverridden:    l.CacheTTLOverridden,
+		PIIRedactions:         l.PIIRedactions,
 		BillingMode:           l.BillingMode,
 		CreatedAt:             l.CreatedAt,
 		User:                  UserFromServiceShallow(l.User),
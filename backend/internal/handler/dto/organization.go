@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type Organization struct {
+	ID               int64   `json:"id"`
+	Name             string  `json:"name"`
+	Description      string  `json:"description"`
+	Status           string  `json:"status"`
+	MonthlyBudgetUSD float64 `json:"monthly_budget_usd"`
+	MemberCount      int64   `json:"member_count"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationOverview 组织管理员首页概览
+type OrganizationOverview struct {
+	Organization
+	MonthSpendUSD float64 `json:"month_spend_usd"`
+}
+
+func OrganizationFromService(o *service.Organization) *Organization {
+	if o == nil {
+		return nil
+	}
+	return &Organization{
+		ID:               o.ID,
+		Name:             o.Name,
+		Description:      o.Description,
+		Status:           o.Status,
+		MonthlyBudgetUSD: o.MonthlyBudgetUSD,
+		MemberCount:      o.MemberCount,
+		CreatedAt:        o.CreatedAt,
+		UpdatedAt:        o.UpdatedAt,
+	}
+}
+
+func OrganizationOverviewFromService(o *service.OrganizationOverview) *OrganizationOverview {
+	if o == nil || o.Organization == nil {
+		return nil
+	}
+	out := &OrganizationOverview{
+		Organization:  *OrganizationFromService(o.Organization),
+		MonthSpendUSD: o.MonthSpendUSD,
+	}
+	out.MemberCount = o.MemberCount
+	return out
+}
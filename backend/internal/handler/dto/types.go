@@ -29,6 +29,10 @@ type User struct {
 	// RPMLimit 用户级每分钟请求数上限（0 = 不限制），仅在所用分组未设置 rpm_limit 时作为兜底生效。
 	RPMLimit int `json:"rpm_limit"`
 
+	// 所属组织与组织内角色（member / admin），未加入组织时省略
+	OrganizationID *int64 `json:"organization_id,omitempty"`
+	OrgRole        string `json:"org_role,omitempty"`
+
 	APIKeys       []APIKey           `json:"api_keys,omitempty"`
 	Subscriptions []UserSubscription `json:"subscriptions,omitempty"`
 }
@@ -46,20 +50,33 @@ type AdminUser struct {
 }
 
 type APIKey struct {
-	ID          int64      `json:"id"`
-	UserID      int64      `json:"user_id"`
-	Key         string     `json:"key"`
-	Name        string     `json:"name"`
-	GroupID     *int64     `json:"group_id"`
-	Status      string     `json:"status"`
-	IPWhitelist []string   `json:"ip_whitelist"`
-	IPBlacklist []string   `json:"ip_blacklist"`
-	LastUsedAt  *time.Time `json:"last_used_at"`
-	Quota       float64    `json:"quota"`      // Quota limit in USD (0 = unlimited)
-	QuotaUsed   float64    `json:"quota_used"` // Used quota amount in USD
-	ExpiresAt   *time.Time `json:"expires_at"` // Expiration time (nil = never expires)
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID                    int64                     `json:"id"`
+	UserID                int64                     `json:"user_id"`
+	Key                   string                    `json:"key"`
+	Name                  string                    `json:"name"`
+	GroupID               *int64                    `json:"group_id"`
+	Status                string                    `json:"status"`
+	IPWhitelist           []string                  `json:"ip_whitelist"`
+	IPBlacklist           []string                  `json:"ip_blacklist"`
+	Scopes                []string                  `json:"scopes"`
+	Priority              string                    `json:"priority"`
+	ModerationMode        string                    `json:"moderation_mode"`
+	ContextAutoTrim       bool                      `json:"context_auto_trim"`
+	MaxBodySize           int64                     `json:"max_body_size"`
+	RequestCoalescing     bool                      `json:"request_coalescing"`
+	TokenBucketBurst      int                       `json:"token_bucket_burst"`
+	TokenBucketRefillRate float64                   `json:"token_bucket_refill_rate"`
+	MaxCostPerRequest     float64                   `json:"max_cost_per_request"`
+	AccountTagSelector    domain.AccountTagSelector `json:"account_tag_selector"`
+	AllowedPlatforms      []string                  `json:"allowed_platforms"`
+	ParentID              int64                     `json:"parent_id"`      // Parent key ID for child tokens (0 = regular key)
+	AllowedModels         []string                  `json:"allowed_models"` // Model patterns this key may request (empty = unrestricted)
+	LastUsedAt            *time.Time                `json:"last_used_at"`
+	Quota                 float64                   `json:"quota"`      // Quota limit in USD (0 = unlimited)
+	QuotaUsed             float64                   `json:"quota_used"` // Used quota amount in USD
+	ExpiresAt             *time.Time                `json:"expires_at"` // Expiration time (nil = never expires)
+	CreatedAt             time.Time                 `json:"created_at"`
+	UpdatedAt             time.Time                 `json:"updated_at"`
 
 	// Rate limit fields
 	RateLimit5h   float64    `json:"rate_limit_5h"`
@@ -114,6 +131,9 @@ type Group struct {
 	// RPMLimit 分组级每分钟请求数上限（0 = 不限制），设置后覆盖用户级 rpm_limit。
 	RPMLimit int `json:"rpm_limit"`
 
+	// Priority 并发等待队列优先级（high/normal/low）。
+	Priority string `json:"priority"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -134,6 +154,31 @@ type AdminGroup struct {
 	DefaultMappedModel          string                                   `json:"default_mapped_model"`
 	MessagesDispatchModelConfig domain.OpenAIMessagesDispatchModelConfig `json:"messages_dispatch_model_config"`
 
+	// 请求参数策略（默认/强制参数与 max_tokens 上限）
+	RequestParamOverrides domain.RequestParamOverrides `json:"request_param_overrides"`
+
+	// 分组系统提示词及注入位置（prepend/append）
+	SystemPrompt     string `json:"system_prompt"`
+	SystemPromptMode string `json:"system_prompt_mode"`
+
+	// 敏感信息过滤策略（空/mask/reject）
+	PIIRedactionMode string `json:"pii_redaction_mode"`
+
+	// 转发前是否执行内容审核
+	ModerationEnabled bool `json:"moderation_enabled"`
+
+	// 请求体最大字节数（0 表示使用端点类别默认值）
+	MaxBodySize int64 `json:"max_body_size"`
+
+	// 自定义响应头（静态响应头与额外透传的上游响应头）
+	ResponseHeaders domain.GroupResponseHeaders `json:"response_headers"`
+
+	// 账号标签选择器（require 全部命中、exclude 均不命中）
+	AccountTagSelector domain.AccountTagSelector `json:"account_tag_selector"`
+
+	// 所属组织（nil 表示平台分组）
+	OrganizationID *int64 `json:"organization_id"`
+
 	// 支持的模型系列（仅 antigravity 平台使用）
 	SupportedModelScopes    []string       `json:"supported_model_scopes"`
 	AccountGroups           []AccountGroup `json:"account_groups,omitempty"`
@@ -168,10 +213,21 @@ type Account struct {
 
 	Schedulable bool `json:"schedulable"`
 
+	// OwnerUserID 自助绑定该账号的用户；nil 表示平台共享账号
+	OwnerUserID *int64 `json:"owner_user_id,omitempty"`
+
+	// 账号标签；tags_exclusive 为 true 时仅服务 require 其标签的分组/API Key
+	Tags          []string `json:"tags"`
+	TagsExclusive bool     `json:"tags_exclusive"`
+
 	RateLimitedAt    *time.Time `json:"rate_limited_at"`
 	RateLimitResetAt *time.Time `json:"rate_limit_reset_at"`
 	OverloadUntil    *time.Time `json:"overload_until"`
 
+	// 当前生效的冷却结束时间（限流/过载/临时不可调度中最晚者），到期自动恢复调度
+	CooldownUntil  *time.Time `json:"cooldown_until,omitempty"`
+	CooldownReason string     `json:"cooldown_reason,omitempty"`
+
 	TempUnschedulableUntil  *time.Time `json:"temp_unschedulable_until"`
 	TempUnschedulableReason string     `json:"temp_unschedulable_reason"`
 
@@ -179,6 +235,9 @@ type Account struct {
 	SessionWindowEnd    *time.Time `json:"session_window_end"`
 	SessionWindowStatus string     `json:"session_window_status"`
 
+	// UpstreamRateLimit 最近一次上游响应头中的限流/配额快照（Anthropic / OpenAI / Copilot）
+	UpstreamRateLimit *domain.UpstreamRateLimitSnapshot `json:"upstream_rate_limit,omitempty"`
+
 	// 5h窗口费用控制（仅 Anthropic OAuth/SetupToken 账号有效）
 	// 从 extra 字段提取，方便前端显示和编辑
 	WindowCostLimit         *float64 `json:"window_cost_limit,omitempty"`
@@ -259,16 +318,18 @@ type AccountGroup struct {
 }
 
 type Proxy struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Protocol  string    `json:"protocol"`
-	Host      string    `json:"host"`
-	Port      int       `json:"port"`
-	Username  string    `json:"username"`
-	Password  string    `json:"-"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"-"`
+	Status   string `json:"status"`
+	// MaxConcurrency 经该代理的最大并发上游请求数，0 表示不限制
+	MaxConcurrency int       `json:"max_concurrency"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 type ProxyWithAccountCount struct {
@@ -380,6 +441,8 @@ type UsageLog struct {
 
 	CacheCreation5mTokens int `json:"cache_creation_5m_tokens"`
 	CacheCreation1hTokens int `json:"cache_creation_1h_tokens"`
+	// CacheHitRatio 提示缓存命中率 cache_read / (input + cache_creation + cache_read)，无缓存读写时为空
+	CacheHitRatio *float64 `json:"cache_hit_ratio,omitempty"`
 
 	InputCost         float64 `json:"input_cost"`
 	OutputCost        float64 `json:"output_cost"`
@@ -407,6 +470,9 @@ type UsageLog struct {
 	// Cache TTL Override 标记
 	CacheTTLOverridden bool `json:"cache_ttl_overridden"`
 
+	// PIIRedactions 请求内容敏感信息脱敏次数
+	PIIRedactions int `json:"pii_redactions,omitempty"`
+
 	// BillingMode 计费模式：token/image
 	BillingMode *string `json:"billing_mode,omitempty"`
 
@@ -557,3 +623,19 @@ type PromoCodeUsage struct {
 
 	User *User `json:"user,omitempty"`
 }
+
+// UserAccount 用户自助绑定的账号（用户侧视图，不含凭证与调度内部字段）
+type UserAccount struct {
+	ID             int64      `json:"id"`
+	Name           string     `json:"name"`
+	Platform       string     `json:"platform"`
+	Type           string     `json:"type"`
+	Status         string     `json:"status"`
+	ErrorMessage   string     `json:"error_message"`
+	Schedulable    bool       `json:"schedulable"`
+	GroupIDs       []int64    `json:"group_ids"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CooldownUntil  *time.Time `json:"cooldown_until,omitempty"`
+	CooldownReason string     `json:"cooldown_reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -17,10 +17,12 @@ import (
 const (
 	EndpointMessages          = "/v1/messages"
 	EndpointChatCompletions   = "/v1/chat/completions"
+	EndpointCompletions       = "/v1/completions"
 	EndpointResponses         = "/v1/responses"
 	EndpointImagesGenerations = "/v1/images/generations"
 	EndpointImagesEdits       = "/v1/images/edits"
 	EndpointGeminiModels      = "/v1beta/models"
+	EndpointGeminiCodeAssist  = "/v1internal"
 )
 
 // gin.Context keys used by the middleware and helpers below.
@@ -44,6 +46,8 @@ func NormalizeInboundEndpoint(path string) string {
 	switch {
 	case strings.Contains(path, EndpointChatCompletions):
 		return EndpointChatCompletions
+	case strings.Contains(path, EndpointCompletions) || path == "/completions":
+		return EndpointCompletions
 	case strings.Contains(path, EndpointMessages):
 		return EndpointMessages
 	case strings.Contains(path, EndpointImagesGenerations) || strings.Contains(path, "/images/generations"):
@@ -54,6 +58,8 @@ func NormalizeInboundEndpoint(path string) string {
 		return EndpointResponses
 	case strings.Contains(path, EndpointGeminiModels):
 		return EndpointGeminiModels
+	case strings.Contains(path, EndpointGeminiCodeAssist):
+		return EndpointGeminiCodeAssist
 	default:
 		return path
 	}
@@ -97,6 +103,13 @@ func DeriveUpstreamEndpoint(inbound, rawRequestPath, platform string) string {
 			return EndpointGeminiModels
 		}
 		return EndpointMessages
+
+	case service.PlatformCustom:
+		// Custom providers speak OpenAI Chat Completions; Azure OpenAI accounts also serve Responses.
+		if inbound == EndpointResponses {
+			return EndpointResponses
+		}
+		return EndpointChatCompletions
 	}
 
 	// Unknown platform — fall back to inbound.
@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// GatewayForwarder 描述 GatewayHandler 上兼容协议端点（Chat Completions / Responses 及 custom 平台）
+// 与上游转发实现之间的差异部分。请求预处理、并发槽位、选号、failover 与用量记录由
+// GatewayHandler.serveWithForwarder 统一驱动，新增兼容协议端点只需实现该接口。
+//
+// 适用范围仅限上述兼容端点：/v1/messages、OpenAIGatewayHandler 与 Gemini 原生接口仍各自维护调度循环
+// （分组降级、用户消息队列、号池重试等平台特有步骤），上游请求构造、流式/非流式响应处理与用量提取
+// 仍由各 service 的 Forward 实现负责，不属于该接口。
+type GatewayForwarder interface {
+	// Format 入站请求格式，用于请求体校验、图片处理、敏感信息过滤与参数策略
+	Format() service.RequestParamFormat
+	// Protocol 传给 ParseGatewayRequest 的协议标识
+	Protocol() string
+	// LogPrefix 日志组件与事件名前缀
+	LogPrefix() string
+	// Forward 向选中的账号转发请求并写回客户端响应；返回 *service.UpstreamFailoverError 时可切换账号重试
+	Forward(ctx context.Context, c *gin.Context, account *service.Account, req *GatewayForwardRequest) (*service.ForwardResult, error)
+	// WriteError 按协议格式输出错误响应
+	WriteError(c *gin.Context, status int, errType, message string)
+	// WriteFailoverExhausted 账号切换耗尽时按协议格式输出错误
+	WriteFailoverExhausted(c *gin.Context, lastErr *service.UpstreamFailoverError, streamStarted bool)
+}
+
+// GatewayForwardRequest 单次转发的请求参数（已应用渠道模型映射）
+type GatewayForwardRequest struct {
+	Body   []byte
+	Parsed *service.ParsedRequest
+	// HasBoundSession 请求开始时是否已绑定粘性会话（Antigravity 重试策略依赖）
+	HasBoundSession bool
+}
+
+// serveWithForwarder 兼容协议端点的统一处理流程：请求预处理 → 用户并发槽位 → 计费校验 →
+// 选号与账号槽位 → 转发（failover 循环）→ 异步记录用量。
+func (h *GatewayHandler) serveWithForwarder(c *gin.Context, f GatewayForwarder) {
+	format := f.Format()
+	streamStarted := false
+
+	requestStart := time.Now()
+
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		f.WriteError(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		f.WriteError(c, http.StatusInternalServerError, "api_error", "User context not found")
+		return
+	}
+	reqLog := requestLogger(
+		c,
+		"handler.gateway."+f.Protocol(),
+		zap.Int64("user_id", subject.UserID),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+	)
+
+	// Read request body
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			f.WriteError(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		f.WriteError(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		return
+	}
+
+	if len(body) == 0 {
+		f.WriteError(c, http.StatusBadRequest, "invalid_request_error", "Request body is empty")
+		return
+	}
+
+	// 请求体严格校验：在任何改写之前检查客户端原始请求
+	if err := h.requestValidator.Validate(body, format); err != nil {
+		f.WriteError(c, http.StatusBadRequest, "invalid_request_error", infraerrors.Message(err))
+		return
+	}
+
+	// 请求体预处理：图片、工具结果截断、上下文裁剪、敏感信息过滤、内容审核、参数策略、推理强度、系统提示词与费用上限
+	body, piiRedactions, ok := h.requestPreprocessor().Apply(c, body, apiKey, format, f.WriteError)
+	if !ok {
+		return
+	}
+
+	setOpsRequestContext(c, "", false, body)
+
+	// Validate JSON
+	if !gjson.ValidBytes(body) {
+		f.WriteError(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		return
+	}
+
+	// Extract model and stream
+	modelResult := gjson.GetBytes(body, "model")
+	if !modelResult.Exists() || modelResult.Type != gjson.String || modelResult.String() == "" {
+		f.WriteError(c, http.StatusBadRequest, "invalid_request_error", "model is required")
+		return
+	}
+	reqModel := modelResult.String()
+	reqStream := gjson.GetBytes(body, "stream").Bool()
+	reqLog = reqLog.With(zap.String("model", reqModel), zap.Bool("stream", reqStream))
+
+	setOpsRequestContext(c, reqModel, reqStream, body)
+	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(reqStream, false)))
+
+	// 解析渠道级模型映射
+	channelMapping, _ := h.gatewayService.ResolveChannelMappingAndRestrict(c.Request.Context(), apiKey.GroupID, reqModel)
+
+	// Claude Code only restriction: 兼容协议端点不可能来自 Claude Code 客户端，直接拒绝
+	if apiKey.Group != nil && apiKey.Group.ClaudeCodeOnly {
+		f.WriteError(c, http.StatusForbidden, "permission_error",
+			"This group is restricted to Claude Code clients (/v1/messages only)")
+		return
+	}
+
+	// Error passthrough binding
+	if h.errorPassthroughService != nil {
+		service.BindErrorPassthroughService(c, h.errorPassthroughService)
+	}
+
+	subscription, _ := middleware2.GetSubscriptionFromContext(c)
+
+	service.SetOpsLatencyMs(c, service.OpsAuthLatencyMsKey, time.Since(requestStart).Milliseconds())
+
+	// 1. Acquire user concurrency slot
+	maxWait := service.CalculateMaxWait(subject.Concurrency)
+	canWait, err := h.concurrencyHelper.IncrementWaitCount(c.Request.Context(), subject.UserID, maxWait)
+	waitCounted := false
+	if err != nil {
+		reqLog.Warn(f.LogPrefix()+".user_wait_counter_increment_failed", zap.Error(err))
+	} else if !canWait {
+		h.concurrencyHelper.SetUserRateLimitHeaders(c, subject.UserID, subject.Concurrency)
+		f.WriteError(c, http.StatusTooManyRequests, "rate_limit_error", "Too many pending requests, please retry later")
+		return
+	}
+	if err == nil && canWait {
+		waitCounted = true
+	}
+	defer func() {
+		if waitCounted {
+			h.concurrencyHelper.DecrementWaitCount(c.Request.Context(), subject.UserID)
+		}
+	}()
+
+	userReleaseFunc, err := h.concurrencyHelper.AcquireUserSlotWithWait(c, subject.UserID, subject.Concurrency, reqStream, &streamStarted)
+	if err != nil {
+		reqLog.Warn(f.LogPrefix()+".user_slot_acquire_failed", zap.Error(err))
+		h.concurrencyHelper.SetUserRateLimitHeaders(c, subject.UserID, subject.Concurrency)
+		h.handleConcurrencyError(c, err, "user", streamStarted)
+		return
+	}
+	if waitCounted {
+		h.concurrencyHelper.DecrementWaitCount(c.Request.Context(), subject.UserID)
+		waitCounted = false
+	}
+	userReleaseFunc = wrapReleaseOnDone(c.Request.Context(), userReleaseFunc)
+	if userReleaseFunc != nil {
+		defer userReleaseFunc()
+	}
+
+	// 2. Re-check billing
+	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
+		reqLog.Info(f.LogPrefix()+".billing_check_failed", zap.Error(err))
+		status, code, message, retryAfter := billingErrorDetails(err)
+		setBillingRateLimitHeaders(c, err, retryAfter)
+		f.WriteError(c, status, code, message)
+		return
+	}
+
+	// Parse request for session hash
+	parsedReq, _ := service.ParseGatewayRequest(body, f.Protocol())
+	if parsedReq == nil {
+		parsedReq = &service.ParsedRequest{Model: reqModel, Stream: reqStream, Body: body}
+	}
+	parsedReq.SessionContext = &service.SessionContext{
+		ClientIP:  ip.GetClientIP(c),
+		UserAgent: c.GetHeader("User-Agent"),
+		APIKeyID:  apiKey.ID,
+	}
+	sessionHash := h.gatewayService.GenerateSessionHash(parsedReq)
+	// Antigravity 账号的重试策略依赖是否已绑定粘性会话
+	hasBoundSession := false
+	if sessionHash != "" {
+		boundAccountID, _ := h.gatewayService.GetCachedSessionAccountID(c.Request.Context(), apiKey.GroupID, sessionHash)
+		hasBoundSession = boundAccountID > 0
+	}
+
+	c.Request = c.Request.WithContext(service.WithRoutingScriptRequest(c.Request.Context(), body, c.Request.Header))
+
+	// 3. Account selection + failover loop
+	fs := NewFailoverState(h.maxAccountSwitches, false)
+
+	for {
+		selection, err := h.gatewayService.SelectAccountWithLoadAwareness(c.Request.Context(), apiKey.GroupID, sessionHash, reqModel, fs.FailedAccountIDs, "", int64(0))
+		if err != nil {
+			if len(fs.FailedAccountIDs) == 0 {
+				f.WriteError(c, http.StatusServiceUnavailable, "api_error", "No available accounts: "+err.Error())
+				return
+			}
+			action := fs.HandleSelectionExhausted(c.Request.Context())
+			switch action {
+			case FailoverContinue:
+				continue
+			case FailoverCanceled:
+				return
+			default:
+				if fs.LastFailoverErr != nil {
+					f.WriteFailoverExhausted(c, fs.LastFailoverErr, streamStarted)
+				} else {
+					f.WriteError(c, http.StatusBadGateway, "server_error", "All available accounts exhausted")
+				}
+				return
+			}
+		}
+		account := selection.Account
+		setOpsSelectedAccount(c, account.ID, account.Platform)
+
+		// 4. Acquire account concurrency slot
+		accountReleaseFunc := selection.ReleaseFunc
+		if !selection.Acquired {
+			if selection.WaitPlan == nil {
+				f.WriteError(c, http.StatusServiceUnavailable, "api_error", "No available accounts")
+				return
+			}
+			accountReleaseFunc, err = h.concurrencyHelper.AcquireAccountSlotWithWaitTimeout(
+				c,
+				account.ID,
+				selection.WaitPlan.MaxConcurrency,
+				selection.WaitPlan.Timeout,
+				reqStream,
+				&streamStarted,
+			)
+			if err != nil {
+				reqLog.Warn(f.LogPrefix()+".account_slot_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+				h.concurrencyHelper.SetAccountRetryAfter(c, account.ID, selection.WaitPlan.MaxConcurrency)
+				h.handleConcurrencyError(c, err, "account", streamStarted)
+				return
+			}
+		}
+		accountReleaseFunc = wrapReleaseOnDone(c.Request.Context(), accountReleaseFunc)
+
+		// 5. Forward request
+		writerSizeBeforeForward := c.Writer.Size()
+		// 开启 stream_failover_buffer 时暂存首个内容增量前的输出，使上游在输出内容前失败仍可切换账号
+		streamBuffer := startStreamFailoverBuffer(c, h.cfg, reqStream)
+		forwardBody := body
+		if channelMapping.Mapped {
+			forwardBody = h.gatewayService.ReplaceModelInBody(body, channelMapping.MappedModel)
+		}
+		result, err := f.Forward(c.Request.Context(), c, account, &GatewayForwardRequest{
+			Body:            forwardBody,
+			Parsed:          parsedReq,
+			HasBoundSession: hasBoundSession,
+		})
+		streamBuffer.finish(c, err)
+
+		if accountReleaseFunc != nil {
+			accountReleaseFunc()
+		}
+
+		if err != nil {
+			var failoverErr *service.UpstreamFailoverError
+			if errors.As(err, &failoverErr) {
+				if c.Writer.Size() != writerSizeBeforeForward {
+					f.WriteFailoverExhausted(c, failoverErr, true)
+					return
+				}
+				action := fs.HandleFailoverError(c.Request.Context(), h.gatewayService, account.ID, account.Platform, failoverErr)
+				switch action {
+				case FailoverContinue:
+					continue
+				case FailoverExhausted:
+					f.WriteFailoverExhausted(c, fs.LastFailoverErr, streamStarted)
+					return
+				case FailoverCanceled:
+					return
+				}
+			}
+			h.ensureForwardErrorResponse(c, streamStarted)
+			reqLog.Error(f.LogPrefix()+".forward_failed",
+				zap.Int64("account_id", account.ID),
+				zap.Error(err),
+			)
+			return
+		}
+
+		// 6. Record usage
+		userAgent := c.GetHeader("User-Agent")
+		clientIP := ip.GetClientIP(c)
+		requestPayloadHash := service.HashUsageRequestPayload(body)
+		inboundEndpoint := GetInboundEndpoint(c)
+		upstreamEndpoint := GetUpstreamEndpoint(c, account.Platform)
+
+		h.submitUsageRecordTask(func(ctx context.Context) {
+			if err := h.gatewayService.RecordUsage(ctx, &service.RecordUsageInput{
+				Result:             result,
+				APIKey:             apiKey,
+				User:               apiKey.User,
+				Account:            account,
+				Subscription:       subscription,
+				InboundEndpoint:    inboundEndpoint,
+				UpstreamEndpoint:   upstreamEndpoint,
+				UserAgent:          userAgent,
+				IPAddress:          clientIP,
+				RequestPayloadHash: requestPayloadHash,
+				PIIRedactions:      piiRedactions,
+				APIKeyService:      h.apiKeyService,
+				ChannelUsageFields: channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
+			}); err != nil {
+				reqLog.Error(f.LogPrefix()+".record_usage_failed",
+					zap.Int64("account_id", account.ID),
+					zap.Error(err),
+				)
+			}
+		})
+		return
+	}
+}
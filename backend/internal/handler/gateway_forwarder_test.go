@@ -0,0 +1,51 @@
+//go:build unit
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ GatewayForwarder = chatCompletionsForwarder{}
+	_ GatewayForwarder = responsesForwarder{}
+	_ GatewayForwarder = customProviderForwarder{}
+	_ GatewayForwarder = customResponsesForwarder{}
+)
+
+func TestServeWithForwarder_WritesErrorsInForwarderFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &GatewayHandler{}
+
+	cases := []struct {
+		name      string
+		forwarder GatewayForwarder
+		errorKey  string
+	}{
+		{name: "chat completions", forwarder: chatCompletionsForwarder{h: h}, errorKey: "type"},
+		{name: "responses", forwarder: responsesForwarder{h: h}, errorKey: "code"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(""))
+			c.Set(string(middleware2.ContextKeyAPIKey), &service.APIKey{ID: 1, User: &service.User{ID: 1}})
+			c.Set(string(middleware2.ContextKeyUser), middleware2.AuthSubject{UserID: 1, Concurrency: 1})
+
+			h.serveWithForwarder(c, tc.forwarder)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Contains(t, w.Body.String(), `"`+tc.errorKey+`":"invalid_request_error"`)
+			require.Contains(t, w.Body.String(), "Request body is empty")
+		})
+	}
+}
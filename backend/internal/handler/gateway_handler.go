@@ -17,6 +17,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/antigravity"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	pkgerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
@@ -51,6 +52,12 @@ type GatewayHandler struct {
 	maxAccountSwitchesGemini  int
 	cfg                       *config.Config
 	settingService            *service.SettingService
+	piiRedactor               *service.PIIRedactor
+	toolResultLimiter         *service.ToolResultLimiter
+	imageProcessor            *service.ImageProcessor
+	requestValidator          *service.RequestValidator
+	contextTrimmer            *service.ContextTrimmer
+	contentModerator          *service.ContentModerator
 }
 
 // NewGatewayHandler creates a new GatewayHandler
@@ -68,6 +75,7 @@ func NewGatewayHandler(
 	userMsgQueueService *service.UserMessageQueueService,
 	cfg *config.Config,
 	settingService *service.SettingService,
+	contextTrimmer *service.ContextTrimmer,
 ) *GatewayHandler {
 	pingInterval := time.Duration(0)
 	maxAccountSwitches := 10
@@ -104,6 +112,12 @@ func NewGatewayHandler(
 		maxAccountSwitchesGemini:  maxAccountSwitchesGemini,
 		cfg:                       cfg,
 		settingService:            settingService,
+		piiRedactor:               service.NewPIIRedactor(cfg),
+		toolResultLimiter:         service.NewToolResultLimiter(cfg),
+		imageProcessor:            service.NewImageProcessor(cfg),
+		requestValidator:          service.NewRequestValidator(cfg),
+		contextTrimmer:            contextTrimmer,
+		contentModerator:          service.NewContentModerator(cfg),
 	}
 }
 
@@ -147,6 +161,18 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	// 请求体严格校验：在任何改写之前检查客户端原始请求
+	if err := h.requestValidator.Validate(body, service.RequestParamFormatAnthropic); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", infraerrors.Message(err))
+		return
+	}
+
+	// 请求体预处理：图片、工具结果截断、上下文裁剪、敏感信息过滤、内容审核、参数策略、推理强度、系统提示词与费用上限
+	body, piiRedactions, ok := h.requestPreprocessor().Apply(c, body, apiKey, service.RequestParamFormatAnthropic, h.errorResponse)
+	if !ok {
+		return
+	}
+
 	setOpsRequestContext(c, "", false, body)
 
 	parsedReq, err := service.ParseGatewayRequest(body, domain.PlatformAnthropic)
@@ -209,6 +235,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 		// On error, allow request to proceed
 	} else if !canWait {
 		reqLog.Info("gateway.user_wait_queue_full", zap.Int("max_wait", maxWait))
+		h.concurrencyHelper.SetUserRateLimitHeaders(c, subject.UserID, subject.Concurrency)
 		h.errorResponse(c, http.StatusTooManyRequests, "rate_limit_error", "Too many pending requests, please retry later")
 		return
 	}
@@ -226,6 +253,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	userReleaseFunc, err := h.concurrencyHelper.AcquireUserSlotWithWait(c, subject.UserID, subject.Concurrency, reqStream, &streamStarted)
 	if err != nil {
 		reqLog.Warn("gateway.user_slot_acquire_failed", zap.Error(err))
+		h.concurrencyHelper.SetUserRateLimitHeaders(c, subject.UserID, subject.Concurrency)
 		h.handleConcurrencyError(c, err, "user", streamStarted)
 		return
 	}
@@ -244,9 +272,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
 		reqLog.Info("gateway.billing_eligibility_check_failed", zap.Error(err))
 		status, code, message, retryAfter := billingErrorDetails(err)
-		if retryAfter > 0 {
-			c.Header("Retry-After", strconv.Itoa(retryAfter))
-		}
+		setBillingRateLimitHeaders(c, err, retryAfter)
 		h.handleStreamingAwareError(c, status, code, message, streamStarted)
 		return
 	}
@@ -300,6 +326,8 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 	} else {
 		reqLog.Info("sticky.no_session_key", zap.String("session_hash", sessionHash))
 	}
+	c.Request = c.Request.WithContext(service.WithRoutingScriptRequest(c.Request.Context(), body, c.Request.Header))
+
 	// 判断是否真的绑定了粘性会话：有 sessionKey 且已经绑定到某个账号
 	hasBoundSession := sessionKey != "" && sessionBoundAccountID > 0
 
@@ -383,6 +411,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 						zap.Int64("account_id", account.ID),
 						zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
 					)
+					h.concurrencyHelper.SetAccountRetryAfter(c, account.ID, selection.WaitPlan.MaxConcurrency)
 					h.handleStreamingAwareError(c, http.StatusTooManyRequests, "rate_limit_error", "Too many pending requests, please retry later", streamStarted)
 					return
 				}
@@ -407,6 +436,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				if err != nil {
 					reqLog.Warn("gateway.account_slot_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 					releaseWait()
+					h.concurrencyHelper.SetAccountRetryAfter(c, account.ID, selection.WaitPlan.MaxConcurrency)
 					h.handleConcurrencyError(c, err, "account", streamStarted)
 					return
 				}
@@ -427,11 +457,14 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 			// 记录 Forward 前已写入字节数，Forward 后若增加则说明 SSE 内容已发，禁止 failover
 			writerSizeBeforeForward := c.Writer.Size()
+			// 开启 stream_failover_buffer 时暂存首个内容增量前的输出，使上游在输出内容前失败仍可切换账号
+			streamBuffer := startStreamFailoverBuffer(c, h.cfg, reqStream)
 			if account.Platform == service.PlatformAntigravity {
 				result, err = h.antigravityGatewayService.ForwardGemini(requestCtx, c, account, reqModel, "generateContent", reqStream, body, hasBoundSession)
 			} else {
 				result, err = h.geminiCompatService.Forward(requestCtx, c, account, body)
 			}
+			streamBuffer.finish(c, err)
 			if accountReleaseFunc != nil {
 				accountReleaseFunc()
 			}
@@ -510,6 +543,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 					UserAgent:          userAgent,
 					IPAddress:          clientIP,
 					RequestPayloadHash: requestPayloadHash,
+					PIIRedactions:      piiRedactions,
 					ForceCacheBilling:  fs.ForceCacheBilling,
 					APIKeyService:      h.apiKeyService,
 					ChannelUsageFields: channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
@@ -635,6 +669,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 						zap.Int64("account_id", account.ID),
 						zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
 					)
+					h.concurrencyHelper.SetAccountRetryAfter(c, account.ID, selection.WaitPlan.MaxConcurrency)
 					h.handleStreamingAwareError(c, http.StatusTooManyRequests, "rate_limit_error", "Too many pending requests, please retry later", streamStarted)
 					return
 				}
@@ -659,6 +694,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 				if err != nil {
 					reqLog.Warn("gateway.account_slot_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
 					releaseWait()
+					h.concurrencyHelper.SetAccountRetryAfter(c, account.ID, selection.WaitPlan.MaxConcurrency)
 					h.handleConcurrencyError(c, err, "account", streamStarted)
 					return
 				}
@@ -743,11 +779,14 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 			}
 			// 记录 Forward 前已写入字节数，Forward 后若增加则说明 SSE 内容已发，禁止 failover
 			writerSizeBeforeForward := c.Writer.Size()
+			// 开启 stream_failover_buffer 时暂存首个内容增量前的输出，使上游在输出内容前失败仍可切换账号
+			streamBuffer := startStreamFailoverBuffer(c, h.cfg, reqStream)
 			if account.Platform == service.PlatformAntigravity && account.Type != service.AccountTypeAPIKey {
 				result, err = h.antigravityGatewayService.Forward(requestCtx, c, account, body, hasBoundSession)
 			} else {
 				result, err = h.gatewayService.Forward(requestCtx, c, account, parsedReq)
 			}
+			streamBuffer.finish(c, err)
 
 			// 兜底释放串行锁（正常情况已通过回调提前释放）
 			if queueRelease != nil {
@@ -795,9 +834,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 						fallbackAPIKey := cloneAPIKeyWithGroup(apiKey, fallbackGroup)
 						if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), fallbackAPIKey.User, fallbackAPIKey, fallbackGroup, nil); err != nil {
 							status, code, message, retryAfter := billingErrorDetails(err)
-							if retryAfter > 0 {
-								c.Header("Retry-After", strconv.Itoa(retryAfter))
-							}
+							setBillingRateLimitHeaders(c, err, retryAfter)
 							h.handleStreamingAwareError(c, status, code, message, streamStarted)
 							return
 						}
@@ -898,6 +935,7 @@ func (h *GatewayHandler) Messages(c *gin.Context) {
 					UserAgent:          userAgent,
 					IPAddress:          clientIP,
 					RequestPayloadHash: requestPayloadHash,
+					PIIRedactions:      piiRedactions,
 					ForceCacheBilling:  fs.ForceCacheBilling,
 					APIKeyService:      h.apiKeyService,
 					ChannelUsageFields: channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
@@ -983,6 +1021,24 @@ func (h *GatewayHandler) AntigravityModels(c *gin.Context) {
 	})
 }
 
+// AggregatorModels 返回聚合端点可用的模型列表，合并分组内各可调度平台的模型，ID 带平台命名空间
+// GET /aggregator/v1/models
+func (h *GatewayHandler) AggregatorModels(c *gin.Context) {
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+
+	var groupID *int64
+	var platform string
+	if apiKey != nil && apiKey.Group != nil {
+		groupID = &apiKey.Group.ID
+		platform = apiKey.Group.Platform
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"object": "list",
+		"data":   h.gatewayService.ListAggregatorModels(c.Request.Context(), groupID, platform),
+	})
+}
+
 func cloneAPIKeyWithGroup(apiKey *service.APIKey, group *service.Group) *service.APIKey {
 	if apiKey == nil || group == nil {
 		return apiKey
@@ -1369,7 +1425,7 @@ func (h *GatewayHandler) handleStreamingAwareError(c *gin.Context, status int, e
 		flusher, ok := c.Writer.(http.Flusher)
 		if ok {
 			// SSE 错误事件固定 schema，使用 Quote 直拼可避免额外 Marshal 分配。
-			errorEvent := `data: {"type":"error","error":{"type":` + strconv.Quote(errType) + `,"message":` + strconv.Quote(message) + `}}` + "\n\n"
+			errorEvent := `data: {"type":"error","error":{"type":` + strconv.Quote(errType) + `,"message":` + strconv.Quote(message) + `}` + sseRequestIDField(c) + `}` + "\n\n"
 			if _, err := fmt.Fprint(c.Writer, errorEvent); err != nil {
 				_ = c.Error(err)
 			}
@@ -1382,6 +1438,18 @@ func (h *GatewayHandler) handleStreamingAwareError(c *gin.Context, status int, e
 	h.errorResponse(c, status, errType, message)
 }
 
+// sseRequestIDField 返回追加到 SSE 错误事件顶层的 request_id 字段（与 X-Request-ID 响应头一致），无请求 ID 时为空
+func sseRequestIDField(c *gin.Context) string {
+	if c == nil || c.Request == nil {
+		return ""
+	}
+	requestID, _ := c.Request.Context().Value(ctxkey.RequestID).(string)
+	if requestID == "" {
+		return ""
+	}
+	return `,"request_id":` + strconv.Quote(requestID)
+}
+
 // ensureForwardErrorResponse 在 Forward 返回错误但尚未写响应时补写统一错误响应。
 func (h *GatewayHandler) ensureForwardErrorResponse(c *gin.Context, streamStarted bool) bool {
 	if c == nil || c.Writer == nil || c.Writer.Written() {
@@ -1515,9 +1583,7 @@ func (h *GatewayHandler) CountTokens(c *gin.Context) {
 	// 【注意】不计算并发，但需要校验订阅/余额
 	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
 		status, code, message, retryAfter := billingErrorDetails(err)
-		if retryAfter > 0 {
-			c.Header("Retry-After", strconv.Itoa(retryAfter))
-		}
+		setBillingRateLimitHeaders(c, err, retryAfter)
 		h.errorResponse(c, status, code, message)
 		return
 	}
@@ -1761,6 +1827,9 @@ func sendMockInterceptResponse(c *gin.Context, model string, interceptType Inter
 }
 
 func billingErrorDetails(err error) (status int, code, message string, retryAfter int) {
+	if errors.Is(err, service.ErrBillingDatabaseUnavailable) {
+		return http.StatusServiceUnavailable, "billing_database_unavailable", pkgerrors.Message(err), 0
+	}
 	if errors.Is(err, service.ErrBillingServiceUnavailable) {
 		msg := pkgerrors.Message(err)
 		if msg == "" {
@@ -1768,23 +1837,21 @@ func billingErrorDetails(err error) (status int, code, message string, retryAfte
 		}
 		return http.StatusServiceUnavailable, "billing_service_error", msg, 0
 	}
-	if errors.Is(err, service.ErrAPIKeyRateLimit5hExceeded) {
+	// API Key 额度窗口超限：Retry-After 为窗口剩余时间（由计费服务附带在错误上）
+	if errors.Is(err, service.ErrAPIKeyRateLimit5hExceeded) ||
+		errors.Is(err, service.ErrAPIKeyRateLimit1dExceeded) ||
+		errors.Is(err, service.ErrAPIKeyRateLimit7dExceeded) {
 		msg := pkgerrors.Message(err)
-		return http.StatusTooManyRequests, "rate_limit_exceeded", msg, 0
-	}
-	if errors.Is(err, service.ErrAPIKeyRateLimit1dExceeded) {
-		msg := pkgerrors.Message(err)
-		return http.StatusTooManyRequests, "rate_limit_exceeded", msg, 0
-	}
-	if errors.Is(err, service.ErrAPIKeyRateLimit7dExceeded) {
-		msg := pkgerrors.Message(err)
-		return http.StatusTooManyRequests, "rate_limit_exceeded", msg, 0
+		return http.StatusTooManyRequests, "rate_limit_exceeded", msg, service.RetryAfterSecondsFromError(err)
 	}
 	// 用户/分组 RPM 超限统一映射为 HTTP 429；保留与其它 rate_limit 一致的错误码便于客户端分类。
 	// 返回 Retry-After 秒数（当前分钟剩余秒数），让 SDK 自动退避。
 	if errors.Is(err, service.ErrGroupRPMExceeded) || errors.Is(err, service.ErrUserRPMExceeded) {
 		msg := pkgerrors.Message(err)
-		retrySeconds := 60 - int(time.Now().Unix()%60)
+		retrySeconds := service.RetryAfterSecondsFromError(err)
+		if retrySeconds <= 0 {
+			retrySeconds = 60 - int(time.Now().Unix()%60)
+		}
 		return http.StatusTooManyRequests, "rate_limit_exceeded", msg, retrySeconds
 	}
 	msg := pkgerrors.Message(err)
@@ -1798,6 +1865,17 @@ func billingErrorDetails(err error) (status int, code, message string, retryAfte
 	return http.StatusForbidden, "billing_error", msg, 0
 }
 
+// setBillingRateLimitHeaders 计费检查返回 429 时写入 Retry-After；错误携带限流状态时同时写入 X-RateLimit-*
+func setBillingRateLimitHeaders(c *gin.Context, err error, retryAfter int) {
+	if state := service.RateLimitStateFromError(err); state != nil {
+		middleware2.SetRateLimitHeaders(c, state)
+		return
+	}
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+	}
+}
+
 func (h *GatewayHandler) metadataBridgeEnabled() bool {
 	if h == nil || h.cfg == nil {
 		return true
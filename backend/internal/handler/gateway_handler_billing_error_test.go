@@ -2,9 +2,12 @@ package handler
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 )
 
@@ -46,9 +49,50 @@ func TestBillingErrorDetails_BillingServiceUnavailableMapsTo503(t *testing.T) {
 	require.Equal(t, 0, retryAfter, "non-RPM errors should not set Retry-After")
 }
 
+func TestBillingErrorDetails_BillingDatabaseUnavailableMapsTo503(t *testing.T) {
+	status, code, msg, _ := billingErrorDetails(service.ErrBillingDatabaseUnavailable)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, "billing_database_unavailable", code)
+	require.Contains(t, msg, "Billing database is unavailable")
+}
+
 func TestBillingErrorDetails_UnknownErrorFallsBackTo403(t *testing.T) {
 	status, code, msg, _ := billingErrorDetails(service.ErrInsufficientBalance)
 	require.Equal(t, http.StatusForbidden, status)
 	require.Equal(t, "billing_error", code)
 	require.NotEmpty(t, msg)
 }
+
+func TestSetBillingRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 未携带限流状态：仅写 Retry-After
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	setBillingRateLimitHeaders(c, service.ErrUserRPMExceeded, 12)
+	require.Equal(t, "12", w.Header().Get("Retry-After"))
+	require.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+
+	// 携带限流状态：Retry-After 与 X-RateLimit-* 均按状态写入
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	err := service.ErrAPIKeyRateLimit5hExceeded.WithMetadata(map[string]string{
+		"retry_after":         "3600",
+		"ratelimit_limit":     "2.5",
+		"ratelimit_remaining": "0",
+		"ratelimit_reset":     "3600",
+	})
+	_, _, _, retryAfter := billingErrorDetails(err)
+	require.Equal(t, 3600, retryAfter)
+	setBillingRateLimitHeaders(c, err, retryAfter)
+	require.Equal(t, "3600", w.Header().Get("Retry-After"))
+	require.Equal(t, "2.5", w.Header().Get("X-RateLimit-Limit"))
+	require.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	require.Equal(t, "3600", w.Header().Get("X-RateLimit-Reset"))
+}
+
+func TestQueueRetryAfterSeconds(t *testing.T) {
+	require.Equal(t, queueRetryRoundSeconds, queueRetryAfterSeconds(0, 0, 4))
+	require.Equal(t, 2*queueRetryRoundSeconds, queueRetryAfterSeconds(4, 3, 4))
+	require.Equal(t, int(maxConcurrencyWait/time.Second), queueRetryAfterSeconds(100, 100, 1), "capped by slot wait timeout")
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CustomChatCompletions handles OpenAI Chat Completions requests for custom provider groups.
+// POST /v1/chat/completions, POST /chat/completions
+// Requests are passed through to the OpenAI-compatible upstream configured on each account
+// (base_url, auth header template and usage JSON paths), so new compatible backends
+// such as OpenRouter, DeepSeek, vLLM or Ollama need no code changes.
+func (h *GatewayHandler) CustomChatCompletions(c *gin.Context) {
+	h.serveWithForwarder(c, customProviderForwarder{h: h})
+}
+
+// customProviderForwarder 自定义 OpenAI 兼容上游的 GatewayForwarder 实现，错误格式与 Chat Completions 一致
+type customProviderForwarder struct {
+	h *GatewayHandler
+}
+
+func (f customProviderForwarder) Format() service.RequestParamFormat {
+	return service.RequestParamFormatChatCompletions
+}
+
+func (f customProviderForwarder) Protocol() string { return "chat_completions" }
+
+func (f customProviderForwarder) LogPrefix() string { return "gateway.custom" }
+
+func (f customProviderForwarder) Forward(ctx context.Context, c *gin.Context, account *service.Account, req *GatewayForwardRequest) (*service.ForwardResult, error) {
+	return f.h.gatewayService.ForwardCustomChatCompletions(ctx, c, account, req.Body)
+}
+
+func (f customProviderForwarder) WriteError(c *gin.Context, status int, errType, message string) {
+	f.h.chatCompletionsErrorResponse(c, status, errType, message)
+}
+
+func (f customProviderForwarder) WriteFailoverExhausted(c *gin.Context, lastErr *service.UpstreamFailoverError, streamStarted bool) {
+	f.h.handleCCFailoverExhausted(c, lastErr, streamStarted)
+}
+
+// CustomResponses handles OpenAI Responses API requests for custom provider groups.
+// POST /v1/responses, POST /responses
+// Only Azure OpenAI accounts expose the Responses API; other custom accounts reject the request.
+func (h *GatewayHandler) CustomResponses(c *gin.Context) {
+	h.serveWithForwarder(c, customResponsesForwarder{h: h})
+}
+
+// customResponsesForwarder custom 平台 Responses 端点的 GatewayForwarder 实现
+type customResponsesForwarder struct {
+	h *GatewayHandler
+}
+
+func (f customResponsesForwarder) Format() service.RequestParamFormat {
+	return service.RequestParamFormatResponses
+}
+
+func (f customResponsesForwarder) Protocol() string { return "responses" }
+
+func (f customResponsesForwarder) LogPrefix() string { return "gateway.custom_responses" }
+
+func (f customResponsesForwarder) Forward(ctx context.Context, c *gin.Context, account *service.Account, req *GatewayForwardRequest) (*service.ForwardResult, error) {
+	return f.h.gatewayService.ForwardCustomResponses(ctx, c, account, req.Body)
+}
+
+func (f customResponsesForwarder) WriteError(c *gin.Context, status int, errType, message string) {
+	f.h.responsesErrorResponse(c, status, errType, message)
+}
+
+func (f customResponsesForwarder) WriteFailoverExhausted(c *gin.Context, lastErr *service.UpstreamFailoverError, streamStarted bool) {
+	f.h.handleResponsesFailoverExhausted(c, lastErr, streamStarted)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	anthropicModelsDefaultLimit = 20
+	anthropicModelsMaxLimit     = 1000
+	// anthropicModelPlaceholderCreatedAt 非内置模型无发布时间，沿用 Models 接口的占位值
+	anthropicModelPlaceholderCreatedAt = "2024-01-01T00:00:00Z"
+)
+
+// IsAnthropicModelsRequest 判断 /v1/models 请求是否来自 Anthropic 格式客户端（如 Claude Code 携带 anthropic-version）
+func IsAnthropicModelsRequest(c *gin.Context) bool {
+	return strings.TrimSpace(c.GetHeader("anthropic-version")) != ""
+}
+
+// AnthropicModels 以 Anthropic 列表格式返回当前 Key 分组实际可用的模型（经账号模型映射后的请求侧模型名）
+// GET /v1/models（anthropic-version 请求头）、GET /anthropic/v1/models
+func (h *GatewayHandler) AnthropicModels(c *gin.Context) {
+	limit := anthropicModelsDefaultLimit
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 || v > anthropicModelsMaxLimit {
+			h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "limit must be an integer between 1 and 1000")
+			return
+		}
+		limit = v
+	}
+
+	page, hasMore := paginateAnthropicModels(h.anthropicServableModels(c), strings.TrimSpace(c.Query("before_id")), strings.TrimSpace(c.Query("after_id")), limit)
+	resp := gin.H{"data": page, "has_more": hasMore, "first_id": nil, "last_id": nil}
+	if len(page) > 0 {
+		resp["first_id"] = page[0].ID
+		resp["last_id"] = page[len(page)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// AnthropicGetModel 以 Anthropic 格式返回单个模型，分组不可用的模型返回 404
+// GET /v1/models/:model_id、GET /anthropic/v1/models/:model_id
+func (h *GatewayHandler) AnthropicGetModel(c *gin.Context) {
+	modelID := strings.TrimSpace(c.Param("model_id"))
+	for _, model := range h.anthropicServableModels(c) {
+		if model.ID == modelID {
+			c.JSON(http.StatusOK, model)
+			return
+		}
+	}
+	h.errorResponse(c, http.StatusNotFound, "not_found_error", "model: "+modelID)
+}
+
+// OpenAIModels 以 OpenAI 列表格式返回当前 Key 实际可用的模型（分组平台、账号模型映射、Key 平台与模型限制）
+// GET /openai/v1/models
+func (h *GatewayHandler) OpenAIModels(c *gin.Context) {
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+	forcePlatform, _ := middleware2.GetForcePlatformFromContext(c)
+	c.JSON(http.StatusOK, gin.H{
+		"object": "list",
+		"data":   h.gatewayService.ListAPIKeyModels(c.Request.Context(), apiKey, strings.TrimSpace(forcePlatform)),
+	})
+}
+
+// anthropicServableModels 汇总分组账号的可用模型；分组账号均未配置模型映射时回退到 Claude 默认模型列表。
+// 结果按 API Key 的模型白名单过滤。
+func (h *GatewayHandler) anthropicServableModels(c *gin.Context) []claude.Model {
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+	var groupID *int64
+	if apiKey != nil && apiKey.Group != nil {
+		groupID = &apiKey.Group.ID
+	}
+
+	var models []claude.Model
+	if ids := h.gatewayService.GetAvailableModels(c.Request.Context(), groupID, ""); len(ids) > 0 {
+		models = make([]claude.Model, 0, len(ids))
+		for _, id := range ids {
+			models = append(models, anthropicModelFromID(id))
+		}
+	} else {
+		models = append([]claude.Model(nil), claude.DefaultModels...)
+	}
+
+	if apiKey == nil || len(apiKey.AllowedModels) == 0 {
+		return models
+	}
+	filtered := models[:0]
+	for _, model := range models {
+		if apiKey.IsModelAllowed(model.ID) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
+// anthropicModelFromID 内置模型沿用其展示名与发布时间，其余以模型 ID 作为展示名
+func anthropicModelFromID(id string) claude.Model {
+	for _, model := range claude.DefaultModels {
+		if model.ID == id {
+			return model
+		}
+	}
+	return claude.Model{ID: id, Type: "model", DisplayName: id, CreatedAt: anthropicModelPlaceholderCreatedAt}
+}
+
+// paginateAnthropicModels 按 Anthropic 游标语义分页：after_id 向后翻页，before_id 向前翻页（优先）。
+// 游标不存在时按无游标处理。
+func paginateAnthropicModels(models []claude.Model, beforeID, afterID string, limit int) ([]claude.Model, bool) {
+	indexOf := func(id string) int {
+		for i, model := range models {
+			if model.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+	if beforeID != "" {
+		if end := indexOf(beforeID); end >= 0 {
+			start := max(end-limit, 0)
+			return models[start:end], start > 0
+		}
+	}
+	start := 0
+	if afterID != "" {
+		if idx := indexOf(afterID); idx >= 0 {
+			start = idx + 1
+		}
+	}
+	end := min(start+limit, len(models))
+	return models[start:end], end < len(models)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func anthropicModelIDs(models []claude.Model) []string {
+	ids := make([]string, 0, len(models))
+	for _, m := range models {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestPaginateAnthropicModels(t *testing.T) {
+	models := []claude.Model{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}}
+
+	page, hasMore := paginateAnthropicModels(models, "", "", 2)
+	require.Equal(t, []string{"a", "b"}, anthropicModelIDs(page))
+	require.True(t, hasMore)
+
+	page, hasMore = paginateAnthropicModels(models, "", "b", 2)
+	require.Equal(t, []string{"c", "d"}, anthropicModelIDs(page))
+	require.True(t, hasMore)
+
+	page, hasMore = paginateAnthropicModels(models, "", "d", 2)
+	require.Equal(t, []string{"e"}, anthropicModelIDs(page))
+	require.False(t, hasMore)
+
+	page, hasMore = paginateAnthropicModels(models, "d", "", 2)
+	require.Equal(t, []string{"b", "c"}, anthropicModelIDs(page))
+	require.True(t, hasMore)
+
+	page, hasMore = paginateAnthropicModels(models, "b", "", 20)
+	require.Equal(t, []string{"a"}, anthropicModelIDs(page))
+	require.False(t, hasMore)
+
+	page, hasMore = paginateAnthropicModels(models, "", "missing", 20)
+	require.Len(t, page, 5)
+	require.False(t, hasMore)
+}
+
+func TestAnthropicModelFromID(t *testing.T) {
+	builtin := claude.DefaultModels[0]
+	require.Equal(t, builtin, anthropicModelFromID(builtin.ID))
+
+	custom := anthropicModelFromID("claude-custom")
+	require.Equal(t, "claude-custom", custom.DisplayName)
+	require.Equal(t, "model", custom.Type)
+	require.Equal(t, anthropicModelPlaceholderCreatedAt, custom.CreatedAt)
+}
+
+func TestIsAnthropicModelsRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	require.False(t, IsAnthropicModelsRequest(c))
+
+	c.Request.Header.Set("anthropic-version", "2023-06-01")
+	require.True(t, IsAnthropicModelsRequest(c))
+}
@@ -76,7 +76,7 @@ func (f *fakeGroupRepo) DeleteCascade(context.Context, int64) ([]int64, error) {
 func (f *fakeGroupRepo) List(context.Context, pagination.PaginationParams) ([]service.Group, *pagination.PaginationResult, error) {
 	return nil, nil, nil
 }
-func (f *fakeGroupRepo) ListWithFilters(context.Context, pagination.PaginationParams, string, string, string, *bool) ([]service.Group, *pagination.PaginationResult, error) {
+func (f *fakeGroupRepo) ListWithFilters(context.Context, pagination.PaginationParams, string, string, string, *bool, *int64) ([]service.Group, *pagination.PaginationResult, error) {
 	return nil, nil, nil
 }
 func (f *fakeGroupRepo) ListActive(context.Context) ([]service.Group, error) { return nil, nil }
@@ -138,6 +138,18 @@ func (f *fakeConcurrencyCache) GetAccountConcurrencyBatch(_ context.Context, acc
 }
 func (f *fakeConcurrencyCache) CleanupExpiredAccountSlots(context.Context, int64) error { return nil }
 func (f *fakeConcurrencyCache) CleanupStaleProcessSlots(context.Context, string) error  { return nil }
+func (f *fakeConcurrencyCache) ListSlotOccupancy(context.Context) ([]service.SlotOccupancy, error) {
+	return nil, nil
+}
+func (f *fakeConcurrencyCache) AddPriorityWaiter(context.Context, string, int64, int, string) error {
+	return nil
+}
+func (f *fakeConcurrencyCache) RemovePriorityWaiter(context.Context, string, int64, int, string) error {
+	return nil
+}
+func (f *fakeConcurrencyCache) HasHigherPriorityWaiters(context.Context, string, int64, int) (bool, error) {
+	return false, nil
+}
 
 func newTestGatewayHandler(t *testing.T, group *service.Group, accounts []*service.Account) (*GatewayHandler, func()) {
 	t.Helper()
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"math/rand/v2"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -20,6 +24,21 @@ var claudeCodeValidator = service.NewClaudeCodeValidator()
 
 const claudeCodeParsedRequestContextKey = "claude_code_parsed_request"
 
+// applyContextAutoTrim 对开启 context_auto_trim 的 API Key 裁剪超出模型上下文窗口的历史消息，
+// 并通过响应头告知客户端裁剪情况
+func applyContextAutoTrim(c *gin.Context, trimmer *service.ContextTrimmer, body []byte, apiKey *service.APIKey, model string, format service.RequestParamFormat) []byte {
+	body, result := trimmer.Apply(c.Request.Context(), body, apiKey, model, format)
+	if result != nil {
+		c.Header(service.ContextTrimmedHeader, result.HeaderValue())
+	}
+	return body
+}
+
+// applyReasoningEffort 将 X-Reasoning-Effort 统一推理强度（未指定时为分组默认值）改写为目标格式与模型对应的上游字段
+func applyReasoningEffort(c *gin.Context, body []byte, apiKey *service.APIKey, model string, format service.RequestParamFormat) ([]byte, error) {
+	return service.ApplyReasoningEffort(body, apiKey.Group, format, model, c.GetHeader(apicompat.ReasoningEffortHeader))
+}
+
 // SetClaudeCodeClientContext 检查请求是否来自 Claude Code 客户端，并设置到 context 中
 // 返回更新后的 context
 func SetClaudeCodeClientContext(c *gin.Context, body []byte, parsedReq *service.ParsedRequest) {
@@ -125,6 +144,8 @@ const (
 	backoffMultiplier = 1.5
 	// maxBackoff 最大退避时间
 	maxBackoff = 2 * time.Second
+	// queueRetryRoundSeconds 网关 429 估算 Retry-After 时每轮排队的秒数
+	queueRetryRoundSeconds = 2
 )
 
 // SSEPingFormat defines the format of SSE ping events for different platforms
@@ -247,14 +268,16 @@ func (h *ConcurrencyHelper) TryAcquireAccountSlot(ctx context.Context, accountID
 func (h *ConcurrencyHelper) AcquireUserSlotWithWait(c *gin.Context, userID int64, maxConcurrency int, isStream bool, streamStarted *bool) (func(), error) {
 	ctx := c.Request.Context()
 
-	// Try to acquire immediately
-	releaseFunc, acquired, err := h.TryAcquireUserSlot(ctx, userID, maxConcurrency)
-	if err != nil {
-		return nil, err
-	}
+	// Try to acquire immediately (unless higher-priority requests are already waiting)
+	if !h.concurrencyService.ShouldYieldToHigherPriority(ctx, service.PriorityWaitScopeUser, userID, requestPriority(c)) {
+		releaseFunc, acquired, err := h.TryAcquireUserSlot(ctx, userID, maxConcurrency)
+		if err != nil {
+			return nil, err
+		}
 
-	if acquired {
-		return releaseFunc, nil
+		if acquired {
+			return releaseFunc, nil
+		}
 	}
 
 	// Need to wait - handle streaming ping if needed
@@ -267,14 +290,16 @@ func (h *ConcurrencyHelper) AcquireUserSlotWithWait(c *gin.Context, userID int64
 func (h *ConcurrencyHelper) AcquireAccountSlotWithWait(c *gin.Context, accountID int64, maxConcurrency int, isStream bool, streamStarted *bool) (func(), error) {
 	ctx := c.Request.Context()
 
-	// Try to acquire immediately
-	releaseFunc, acquired, err := h.TryAcquireAccountSlot(ctx, accountID, maxConcurrency)
-	if err != nil {
-		return nil, err
-	}
+	// Try to acquire immediately (unless higher-priority requests are already waiting)
+	if !h.concurrencyService.ShouldYieldToHigherPriority(ctx, service.PriorityWaitScopeAccount, accountID, requestPriority(c)) {
+		releaseFunc, acquired, err := h.TryAcquireAccountSlot(ctx, accountID, maxConcurrency)
+		if err != nil {
+			return nil, err
+		}
 
-	if acquired {
-		return releaseFunc, nil
+		if acquired {
+			return releaseFunc, nil
+		}
 	}
 
 	// Need to wait - handle streaming ping if needed
@@ -292,7 +317,15 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 	defer cancel()
 
+	// 优先级等待队列：登记当前请求的优先级，更高优先级有等待者时本轮不尝试获取槽位
+	priority := requestPriority(c)
+	leaveWait := h.concurrencyService.EnterPriorityWait(ctx, slotType, id, priority)
+	defer leaveWait()
+
 	acquireSlot := func() (*service.AcquireResult, error) {
+		if h.concurrencyService.ShouldYieldToHigherPriority(ctx, slotType, id, priority) {
+			return &service.AcquireResult{Acquired: false}, nil
+		}
 		if slotType == "user" {
 			return h.concurrencyService.AcquireUserSlot(ctx, id, maxConcurrency)
 		}
@@ -371,11 +404,65 @@ func (h *ConcurrencyHelper) waitForSlotWithPingTimeout(c *gin.Context, slotType
 	}
 }
 
+// SetUserRateLimitHeaders 用户并发 / 等待队列超限返回 429 前，按用户槽位与等待队列的实时状态写入
+// Retry-After 与 X-RateLimit-*（Limit 为用户并发上限，Remaining 为空闲槽位数）。
+func (h *ConcurrencyHelper) SetUserRateLimitHeaders(c *gin.Context, userID int64, maxConcurrency int) {
+	if maxConcurrency <= 0 {
+		return
+	}
+	current, waiting := h.userLoad(c.Request.Context(), userID, maxConcurrency)
+	middleware2.SetRateLimitHeaders(c, &service.RateLimitState{
+		Limit:        float64(maxConcurrency),
+		Remaining:    float64(max(maxConcurrency-current, 0)),
+		ResetSeconds: queueRetryAfterSeconds(current, waiting, maxConcurrency),
+	})
+}
+
+// SetAccountRetryAfter 账号并发 / 等待队列超限返回 429 前，按账号排队深度写入 Retry-After。
+// 账号容量属于内部调度信息，不写 X-RateLimit-*。
+func (h *ConcurrencyHelper) SetAccountRetryAfter(c *gin.Context, accountID int64, maxConcurrency int) {
+	if maxConcurrency <= 0 {
+		return
+	}
+	current, waiting := maxConcurrency, 0
+	loads, err := h.concurrencyService.GetAccountsLoadBatch(c.Request.Context(), []service.AccountWithConcurrency{{ID: accountID, MaxConcurrency: maxConcurrency}})
+	if err == nil && loads[accountID] != nil {
+		current, waiting = loads[accountID].CurrentConcurrency, loads[accountID].WaitingCount
+	}
+	c.Header("Retry-After", strconv.Itoa(queueRetryAfterSeconds(current, waiting, maxConcurrency)))
+}
+
+// userLoad 读取用户当前占用槽位数与等待数；读取失败时按槽位已满、无排队处理
+func (h *ConcurrencyHelper) userLoad(ctx context.Context, userID int64, maxConcurrency int) (current, waiting int) {
+	loads, err := h.concurrencyService.GetUsersLoadBatch(ctx, []service.UserWithConcurrency{{ID: userID, MaxConcurrency: maxConcurrency}})
+	if err != nil || loads[userID] == nil {
+		return maxConcurrency, 0
+	}
+	return loads[userID].CurrentConcurrency, loads[userID].WaitingCount
+}
+
+// queueRetryAfterSeconds 按排队轮次估算重试等待秒数：
+// 占用中与排队中的请求每 maxConcurrency 个为一轮，每轮按 queueRetryRoundSeconds 计，上限为槽位等待超时。
+func queueRetryAfterSeconds(current, waiting, maxConcurrency int) int {
+	if maxConcurrency <= 0 {
+		maxConcurrency = 1
+	}
+	rounds := (current + waiting + maxConcurrency - 1) / maxConcurrency
+	seconds := max(rounds, 1) * queueRetryRoundSeconds
+	return min(seconds, int(maxConcurrencyWait/time.Second))
+}
+
 // AcquireAccountSlotWithWaitTimeout acquires an account slot with a custom timeout (keeps SSE ping).
 func (h *ConcurrencyHelper) AcquireAccountSlotWithWaitTimeout(c *gin.Context, accountID int64, maxConcurrency int, timeout time.Duration, isStream bool, streamStarted *bool) (func(), error) {
 	return h.waitForSlotWithPingTimeout(c, "account", accountID, maxConcurrency, timeout, isStream, streamStarted, true)
 }
 
+// requestPriority 返回当前请求 API Key 的生效优先级（未鉴权时为 normal）。
+func requestPriority(c *gin.Context) string {
+	apiKey, _ := middleware2.GetAPIKeyFromContext(c)
+	return apiKey.EffectivePriority()
+}
+
 // nextBackoff 计算下一次退避时间
 // 性能优化：使用指数退避 + 随机抖动，避免惊群效应
 // current: 当前退避时间
@@ -398,3 +485,11 @@ func nextBackoff(current time.Duration) time.Duration {
 	}
 	return jittered
 }
+
+// moderationErrorType 将内容审核错误映射为网关错误类型：审核端点不可用为 api_error，其余为 invalid_request_error
+func moderationErrorType(err error) string {
+	if infraerrors.IsServiceUnavailable(err) {
+		return "api_error"
+	}
+	return "invalid_request_error"
+}
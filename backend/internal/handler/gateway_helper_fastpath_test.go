@@ -92,6 +92,21 @@ func (m *concurrencyCacheMock) CleanupExpiredAccountSlots(ctx context.Context, a
 func (m *concurrencyCacheMock) CleanupStaleProcessSlots(ctx context.Context, activeRequestPrefix string) error {
 	return nil
 }
+func (m *concurrencyCacheMock) ListSlotOccupancy(context.Context) ([]service.SlotOccupancy, error) {
+	return nil, nil
+}
+
+func (m *concurrencyCacheMock) AddPriorityWaiter(ctx context.Context, scope string, id int64, rank int, requestID string) error {
+	return nil
+}
+
+func (m *concurrencyCacheMock) RemovePriorityWaiter(ctx context.Context, scope string, id int64, rank int, requestID string) error {
+	return nil
+}
+
+func (m *concurrencyCacheMock) HasHigherPriorityWaiters(ctx context.Context, scope string, id int64, rank int) (bool, error) {
+	return false, nil
+}
 
 func TestConcurrencyHelper_TryAcquireUserSlot(t *testing.T) {
 	cache := &concurrencyCacheMock{
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -24,6 +25,10 @@ type helperConcurrencyCacheStub struct {
 	userAcquireCalls    int
 	accountReleaseCalls int
 	userReleaseCalls    int
+
+	higherPriorityWaiting bool
+	priorityWaiterAdds    int
+	priorityWaiterRemoves int
 }
 
 func (s *helperConcurrencyCacheStub) AcquireAccountSlot(ctx context.Context, accountID int64, maxConcurrency int, requestID string) (bool, error) {
@@ -123,6 +128,29 @@ func (s *helperConcurrencyCacheStub) CleanupExpiredAccountSlots(ctx context.Cont
 func (s *helperConcurrencyCacheStub) CleanupStaleProcessSlots(ctx context.Context, activeRequestPrefix string) error {
 	return nil
 }
+func (s *helperConcurrencyCacheStub) ListSlotOccupancy(context.Context) ([]service.SlotOccupancy, error) {
+	return nil, nil
+}
+
+func (s *helperConcurrencyCacheStub) AddPriorityWaiter(ctx context.Context, scope string, id int64, rank int, requestID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priorityWaiterAdds++
+	return nil
+}
+
+func (s *helperConcurrencyCacheStub) RemovePriorityWaiter(ctx context.Context, scope string, id int64, rank int, requestID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priorityWaiterRemoves++
+	return nil
+}
+
+func (s *helperConcurrencyCacheStub) HasHigherPriorityWaiters(ctx context.Context, scope string, id int64, rank int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.higherPriorityWaiting, nil
+}
 
 func newHelperTestContext(method, path string) (*gin.Context, *httptest.ResponseRecorder) {
 	gin.SetMode(gin.TestMode)
@@ -311,6 +339,37 @@ func TestAcquireAccountSlotWithWaitTimeout_ImmediateAttemptBeforeBackoff(t *test
 	require.GreaterOrEqual(t, cache.accountAcquireCalls, 1)
 }
 
+func TestAcquireUserSlotWithWait_YieldsToHigherPriorityWaiters(t *testing.T) {
+	cache := &helperConcurrencyCacheStub{
+		userSeq:               []bool{true, true, true},
+		higherPriorityWaiting: true,
+	}
+	concurrency := service.NewConcurrencyService(cache)
+	concurrency.SetPriorityQueueEnabled(true)
+	helper := NewConcurrencyHelper(concurrency, SSEPingFormatNone, 5*time.Millisecond)
+
+	c, _ := newHelperTestContext(http.MethodPost, "/v1/messages")
+	c.Set(string(middleware.ContextKeyAPIKey), &service.APIKey{ID: 1, Priority: service.PriorityLow})
+	streamStarted := false
+	release, err := helper.waitForSlotWithPingTimeout(c, "user", 7, 1, 150*time.Millisecond, false, &streamStarted, true)
+	require.Nil(t, release)
+	var cErr *ConcurrencyError
+	require.ErrorAs(t, err, &cErr)
+	require.True(t, cErr.IsTimeout)
+	require.Zero(t, cache.userAcquireCalls, "low priority waiter must not take slots while higher priority waiters exist")
+	require.Equal(t, 1, cache.priorityWaiterAdds)
+	require.Equal(t, 1, cache.priorityWaiterRemoves)
+
+	// 最高优先级不让位，立即获取槽位
+	c, _ = newHelperTestContext(http.MethodPost, "/v1/messages")
+	c.Set(string(middleware.ContextKeyAPIKey), &service.APIKey{ID: 2, Priority: service.PriorityHigh})
+	release, err = helper.AcquireUserSlotWithWait(c, 7, 1, false, &streamStarted)
+	require.NoError(t, err)
+	require.NotNil(t, release)
+	release()
+	require.Equal(t, 1, cache.userAcquireCalls)
+}
+
 type helperConcurrencyCacheStubWithError struct {
 	helperConcurrencyCacheStub
 	err error
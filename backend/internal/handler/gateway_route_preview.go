@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// RoutePreview handles dry-run routing for Anthropic-compatible groups
+// (anthropic / gemini / antigravity). It resolves channel mapping and selects
+// an account exactly like /v1/messages, but never calls upstream.
+// POST /gateway/route-preview
+func (h *GatewayHandler) RoutePreview(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.errorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+
+	body, ok := readRoutePreviewBody(c, h.errorResponse)
+	if !ok {
+		return
+	}
+	parsedReq, err := service.ParseGatewayRequest(body, domain.PlatformAnthropic)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		return
+	}
+	if parsedReq.Model == "" {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "model is required")
+		return
+	}
+	SetClaudeCodeClientContext(c, body, parsedReq)
+
+	parsedReq.GroupID = apiKey.GroupID
+	parsedReq.SessionContext = &service.SessionContext{
+		ClientIP:  ip.GetClientIP(c),
+		UserAgent: c.GetHeader("User-Agent"),
+		APIKeyID:  apiKey.ID,
+	}
+	sessionKey := h.gatewayService.GenerateSessionHash(parsedReq)
+	if apiKey.Group != nil && apiKey.Group.Platform == service.PlatformGemini && sessionKey != "" {
+		sessionKey = "gemini:" + sessionKey
+	}
+
+	preview, err := h.gatewayService.PreviewRoute(c.Request.Context(), service.RoutePreviewInput{
+		APIKey:     apiKey,
+		Model:      parsedReq.Model,
+		Body:       body,
+		SessionKey: sessionKey,
+	})
+	if err != nil {
+		h.errorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// RoutePreview handles dry-run routing for OpenAI groups.
+// POST /gateway/route-preview
+func (h *OpenAIGatewayHandler) RoutePreview(c *gin.Context) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		h.anthropicErrorResponse(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return
+	}
+
+	body, ok := readRoutePreviewBody(c, h.anthropicErrorResponse)
+	if !ok {
+		return
+	}
+	if !gjson.ValidBytes(body) {
+		h.anthropicErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		return
+	}
+	model := gjson.GetBytes(body, "model").String()
+	if model == "" {
+		h.anthropicErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "model is required")
+		return
+	}
+
+	preview, err := h.gatewayService.PreviewRoute(c.Request.Context(), service.RoutePreviewInput{
+		APIKey:     apiKey,
+		Model:      model,
+		Body:       body,
+		SessionKey: h.gatewayService.GenerateSessionHash(c, body),
+	})
+	if err != nil {
+		h.anthropicErrorResponse(c, http.StatusServiceUnavailable, "api_error", "No available accounts: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+func readRoutePreviewBody(c *gin.Context, writeError func(*gin.Context, int, string, string)) ([]byte, bool) {
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			writeError(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return nil, false
+		}
+		writeError(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		return nil, false
+	}
+	if len(body) == 0 {
+		writeError(c, http.StatusBadRequest, "invalid_request_error", "Request body is empty")
+		return nil, false
+	}
+	return body, true
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// geminiCodeAssistProjectID 网关对 gemini-cli 声明的 Code Assist 项目。
+// 客户端后续请求携带的 project 会被忽略，实际项目由调度到的账号决定。
+const geminiCodeAssistProjectID = "sub2api-gateway"
+
+// GeminiCodeAssist handles the Google Code Assist API surface used by gemini-cli
+// (CODE_ASSIST_ENDPOINT pointed at the gateway).
+// POST /v1internal:{action}
+//
+// loadCodeAssist / onboardUser 由网关直接应答（账号项目不暴露给客户端）；
+// generateContent / streamGenerateContent / countTokens 解包后复用 Gemini 原生链路，
+// 响应再包装回 Code Assist 格式。
+func (h *GatewayHandler) GeminiCodeAssist(c *gin.Context) {
+	apiKey, ok := middleware.GetAPIKeyFromContext(c)
+	if !ok || apiKey == nil {
+		googleError(c, http.StatusUnauthorized, "Invalid API key")
+		return
+	}
+	if apiKey.Group == nil || apiKey.Group.Platform != service.PlatformGemini {
+		googleError(c, http.StatusBadRequest, "API key group platform is not gemini")
+		return
+	}
+
+	action := geminiCodeAssistAction(c.Request.URL.Path)
+	switch action {
+	case "loadCodeAssist":
+		c.JSON(http.StatusOK, gin.H{
+			"currentTier":             geminiCodeAssistTier(),
+			"allowedTiers":            []gin.H{geminiCodeAssistTier()},
+			"cloudaicompanionProject": geminiCodeAssistProjectID,
+		})
+	case "onboardUser":
+		c.JSON(http.StatusOK, gin.H{
+			"done": true,
+			"response": gin.H{
+				"cloudaicompanionProject": gin.H{"id": geminiCodeAssistProjectID, "name": geminiCodeAssistProjectID},
+			},
+		})
+	case "generateContent", "streamGenerateContent", "countTokens":
+		serveGeminiCodeAssistModels(c, action, h.GeminiV1BetaModels)
+	default:
+		googleError(c, http.StatusNotFound, "Unsupported Code Assist action: "+action)
+	}
+}
+
+func geminiCodeAssistAction(path string) string {
+	if i := strings.LastIndex(path, "/v1internal:"); i >= 0 {
+		return path[i+len("/v1internal:"):]
+	}
+	return ""
+}
+
+func geminiCodeAssistTier() gin.H {
+	return gin.H{
+		"id":                                 "standard-tier",
+		"name":                               "sub2api",
+		"isDefault":                          true,
+		"userDefinedCloudaicompanionProject": false,
+	}
+}
+
+// serveGeminiCodeAssistModels 将 Code Assist 请求（{model, project, request}）解包为
+// Gemini 原生请求交给 next（GeminiV1BetaModels），并把响应包装为 {"response": ...}。
+func serveGeminiCodeAssistModels(c *gin.Context, action string, next gin.HandlerFunc) {
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			googleError(c, http.StatusRequestEntityTooLarge, buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		googleError(c, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	inner, model, err := unwrapGeminiCodeAssistRequest(body)
+	if err != nil {
+		googleError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(inner))
+	c.Request.ContentLength = int64(len(inner))
+	c.Params = append(c.Params, gin.Param{Key: "modelAction", Value: "/" + model + ":" + action})
+
+	w := &geminiCodeAssistWriter{ResponseWriter: c.Writer, wrap: action != "countTokens"}
+	c.Writer = w
+	defer func() {
+		w.finish()
+		c.Writer = w.ResponseWriter
+	}()
+	next(c)
+}
+
+type geminiCodeAssistRequestError struct{ msg string }
+
+func (e *geminiCodeAssistRequestError) Error() string { return e.msg }
+
+// unwrapGeminiCodeAssistRequest 提取 Code Assist 请求中的模型与原生请求体。
+// generateContent: {"model": "...", "project": "...", "request": {...}}
+// countTokens:     {"request": {"model": "models/...", "contents": [...]}}
+func unwrapGeminiCodeAssistRequest(body []byte) ([]byte, string, error) {
+	if len(body) == 0 || !gjson.ValidBytes(body) {
+		return nil, "", &geminiCodeAssistRequestError{"Failed to parse request body"}
+	}
+	request := gjson.GetBytes(body, "request")
+	if !request.IsObject() {
+		return nil, "", &geminiCodeAssistRequestError{"request is required"}
+	}
+	model := strings.TrimSpace(gjson.GetBytes(body, "model").String())
+	if model == "" {
+		model = strings.TrimSpace(request.Get("model").String())
+	}
+	model = strings.TrimPrefix(model, "models/")
+	if model == "" {
+		return nil, "", &geminiCodeAssistRequestError{"model is required"}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(request.Raw), &fields); err != nil {
+		return nil, "", &geminiCodeAssistRequestError{"Failed to parse request body"}
+	}
+	// Code Assist 专有字段，原生 Gemini API 不接受
+	delete(fields, "model")
+	delete(fields, "session_id")
+	inner, err := json.Marshal(fields)
+	if err != nil {
+		return nil, "", err
+	}
+	return inner, model, nil
+}
+
+// geminiCodeAssistWriter 将原生 Gemini 响应包装为 Code Assist 格式：
+// JSON 响应整体包装为 {"response": ...}，SSE 逐条包装 data 负载；非 2xx 响应原样透传。
+type geminiCodeAssistWriter struct {
+	gin.ResponseWriter
+	wrap    bool
+	decided bool
+	mode    legacyCompletionsMode
+	buf     bytes.Buffer
+}
+
+func (w *geminiCodeAssistWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	status := w.ResponseWriter.Status()
+	contentType := w.Header().Get("Content-Type")
+	switch {
+	case !w.wrap || status < 200 || status >= 300:
+		w.mode = legacyCompletionsPassthrough
+	case strings.Contains(contentType, "text/event-stream"):
+		w.mode = legacyCompletionsStream
+	case strings.Contains(contentType, "application/json"):
+		w.mode = legacyCompletionsBuffer
+	default:
+		w.mode = legacyCompletionsPassthrough
+	}
+}
+
+func (w *geminiCodeAssistWriter) Write(p []byte) (int, error) {
+	w.decide()
+	switch w.mode {
+	case legacyCompletionsBuffer:
+		return w.buf.Write(p)
+	case legacyCompletionsStream:
+		w.buf.Write(p)
+		if err := w.flushCompleteLines(); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	default:
+		return w.ResponseWriter.Write(p)
+	}
+}
+
+func (w *geminiCodeAssistWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *geminiCodeAssistWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *geminiCodeAssistWriter) flushCompleteLines() error {
+	data := w.buf.Bytes()
+	idx := bytes.LastIndexByte(data, '\n')
+	if idx < 0 {
+		return nil
+	}
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(data[:idx+1], []byte("\n")) {
+		out.Write(wrapGeminiCodeAssistSSELine(line))
+	}
+	rest := append([]byte(nil), data[idx+1:]...)
+	w.buf.Reset()
+	w.buf.Write(rest)
+	_, err := w.ResponseWriter.Write(out.Bytes())
+	return err
+}
+
+func (w *geminiCodeAssistWriter) finish() {
+	switch w.mode {
+	case legacyCompletionsBuffer:
+		body := w.buf.Bytes()
+		if gjson.ValidBytes(body) {
+			body = wrapGeminiCodeAssistPayload(body)
+		}
+		w.Header().Del("Content-Length")
+		_, _ = w.ResponseWriter.Write(body)
+	case legacyCompletionsStream:
+		if w.buf.Len() > 0 {
+			_, _ = w.ResponseWriter.Write(wrapGeminiCodeAssistSSELine(w.buf.Bytes()))
+		}
+		w.ResponseWriter.Flush()
+	}
+	w.buf.Reset()
+}
+
+func wrapGeminiCodeAssistPayload(payload []byte) []byte {
+	out := make([]byte, 0, len(payload)+len(`{"response":}`))
+	out = append(out, `{"response":`...)
+	out = append(out, payload...)
+	return append(out, '}')
+}
+
+// wrapGeminiCodeAssistSSELine 将 "data: {...}" 行包装为 "data: {"response": {...}}"，其他行原样返回。
+func wrapGeminiCodeAssistSSELine(line []byte) []byte {
+	payload, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return line
+	}
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) == 0 || trimmed[0] != '{' || gjson.GetBytes(trimmed, "response").Exists() {
+		return line
+	}
+	suffix := payload[len(bytes.TrimRight(payload, "\r\n")):]
+	wrapped := wrapGeminiCodeAssistPayload(trimmed)
+	out := make([]byte, 0, len("data: ")+len(wrapped)+len(suffix))
+	out = append(out, "data: "...)
+	out = append(out, wrapped...)
+	return append(out, suffix...)
+}
@@ -0,0 +1,107 @@
+//go:build unit
+
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newGeminiCodeAssistTestContext(action, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1internal:"+action, strings.NewReader(body))
+	return c, rec
+}
+
+func TestGeminiCodeAssistAction(t *testing.T) {
+	require.Equal(t, "loadCodeAssist", geminiCodeAssistAction("/v1internal:loadCodeAssist"))
+	require.Equal(t, "streamGenerateContent", geminiCodeAssistAction("/v1internal:streamGenerateContent"))
+	require.Equal(t, "", geminiCodeAssistAction("/v1beta/models"))
+}
+
+func TestUnwrapGeminiCodeAssistRequest(t *testing.T) {
+	inner, model, err := unwrapGeminiCodeAssistRequest([]byte(`{"model":"gemini-2.5-pro","project":"p","user_prompt_id":"u","request":{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"session_id":"s"}}`))
+	require.NoError(t, err)
+	require.Equal(t, "gemini-2.5-pro", model)
+	require.JSONEq(t, `{"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`, string(inner))
+
+	// countTokens 的模型位于 request.model
+	inner, model, err = unwrapGeminiCodeAssistRequest([]byte(`{"request":{"model":"models/gemini-2.5-flash","contents":[]}}`))
+	require.NoError(t, err)
+	require.Equal(t, "gemini-2.5-flash", model)
+	require.JSONEq(t, `{"contents":[]}`, string(inner))
+
+	_, _, err = unwrapGeminiCodeAssistRequest([]byte(`{"model":"m"}`))
+	require.EqualError(t, err, "request is required")
+	_, _, err = unwrapGeminiCodeAssistRequest([]byte(`{"request":{}}`))
+	require.EqualError(t, err, "model is required")
+	_, _, err = unwrapGeminiCodeAssistRequest([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestServeGeminiCodeAssistModels_GenerateContent(t *testing.T) {
+	c, rec := newGeminiCodeAssistTestContext("generateContent", `{"model":"gemini-2.5-pro","project":"p","request":{"contents":[]}}`)
+
+	var forwarded string
+	serveGeminiCodeAssistModels(c, "generateContent", func(c *gin.Context) {
+		raw, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		forwarded = string(raw)
+		require.Equal(t, "/gemini-2.5-pro:generateContent", c.Param("modelAction"))
+		c.JSON(http.StatusOK, gin.H{"candidates": []gin.H{{"content": gin.H{"parts": []gin.H{{"text": "hi"}}}}}})
+	})
+
+	require.JSONEq(t, `{"contents":[]}`, forwarded)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Contains(t, resp["response"], "candidates")
+}
+
+func TestServeGeminiCodeAssistModels_Streaming(t *testing.T) {
+	c, rec := newGeminiCodeAssistTestContext("streamGenerateContent", `{"model":"gemini-2.5-pro","request":{"contents":[]}}`)
+
+	serveGeminiCodeAssistModels(c, "streamGenerateContent", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: {\"candidates\":[{\"index\":0}]}\n\n")
+		_, _ = c.Writer.WriteString("data: {\"candidates\":[{\"index\":0}],\"usageMetadata\":{}}")
+	})
+
+	require.Equal(t,
+		"data: {\"response\":{\"candidates\":[{\"index\":0}]}}\n\n"+
+			"data: {\"response\":{\"candidates\":[{\"index\":0}],\"usageMetadata\":{}}}",
+		rec.Body.String())
+}
+
+func TestServeGeminiCodeAssistModels_PassthroughCountTokensAndErrors(t *testing.T) {
+	c, rec := newGeminiCodeAssistTestContext("countTokens", `{"request":{"model":"models/gemini-2.5-pro","contents":[]}}`)
+	serveGeminiCodeAssistModels(c, "countTokens", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"totalTokens": 3})
+	})
+	require.JSONEq(t, `{"totalTokens":3}`, rec.Body.String())
+
+	c, rec = newGeminiCodeAssistTestContext("generateContent", `{"model":"gemini-2.5-pro","request":{"contents":[]}}`)
+	serveGeminiCodeAssistModels(c, "generateContent", func(c *gin.Context) {
+		googleError(c, http.StatusTooManyRequests, "rate limited")
+	})
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.NotContains(t, rec.Body.String(), `"response"`)
+}
+
+func TestServeGeminiCodeAssistModels_InvalidBody(t *testing.T) {
+	c, rec := newGeminiCodeAssistTestContext("generateContent", `{"model":"gemini-2.5-pro"}`)
+	called := false
+	serveGeminiCodeAssistModels(c, "generateContent", func(c *gin.Context) { called = true })
+	require.False(t, called)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
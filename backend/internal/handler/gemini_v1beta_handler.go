@@ -9,7 +9,6 @@ import (
 	"errors"
 	"net/http"
 	"regexp"
-	"strconv"
 	"strings"
 
 	"github.com/Wei-Shaw/sub2api/internal/domain"
@@ -132,6 +131,8 @@ func (h *GatewayHandler) GeminiV1BetaGetModel(c *gin.Context) {
 // GeminiV1BetaModels proxies Gemini native REST endpoints like:
 // POST /v1beta/models/{model}:generateContent
 // POST /v1beta/models/{model}:streamGenerateContent?alt=sse
+// POST /v1beta/models/{model}:countTokens
+// 同时挂载于 /gemini/v1beta 前缀。
 func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 	apiKey, ok := middleware.GetAPIKeyFromContext(c)
 	if !ok || apiKey == nil {
@@ -182,6 +183,18 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		return
 	}
 
+	// 请求体预处理：countTokens 同样会把内容发往上游，一并做敏感信息过滤与审核；
+	// 参数策略、推理强度、系统提示词与费用上限仅作用于生成类请求（countTokens 不接受 generationConfig）
+	body, piiRedactions, ok := h.requestPreprocessor().ApplyWithOptions(c, body, apiKey, service.RequestParamFormatGemini, requestPreprocessOptions{
+		Model:          modelName,
+		SkipGeneration: action != "generateContent" && !stream,
+	}, func(c *gin.Context, status int, _ string, message string) {
+		googleError(c, status, message)
+	})
+	if !ok {
+		return
+	}
+
 	setOpsRequestContext(c, modelName, stream, body)
 	setOpsEndpointContext(c, "", int16(service.RequestTypeFromLegacy(stream, false)))
 
@@ -206,6 +219,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		reqLog.Warn("gemini.user_wait_counter_increment_failed", zap.Error(err))
 	} else if !canWait {
 		reqLog.Info("gemini.user_wait_queue_full", zap.Int("max_wait", maxWait))
+		geminiConcurrency.SetUserRateLimitHeaders(c, authSubject.UserID, authSubject.Concurrency)
 		googleError(c, http.StatusTooManyRequests, "Too many pending requests, please retry later")
 		return
 	}
@@ -226,6 +240,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 	userReleaseFunc, err := geminiConcurrency.AcquireUserSlotWithWait(c, authSubject.UserID, authSubject.Concurrency, stream, &streamStarted)
 	if err != nil {
 		reqLog.Warn("gemini.user_slot_acquire_failed", zap.Error(err))
+		geminiConcurrency.SetUserRateLimitHeaders(c, authSubject.UserID, authSubject.Concurrency)
 		googleError(c, http.StatusTooManyRequests, err.Error())
 		return
 	}
@@ -243,9 +258,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
 		reqLog.Info("gemini.billing_eligibility_check_failed", zap.Error(err))
 		status, _, message, retryAfter := billingErrorDetails(err)
-		if retryAfter > 0 {
-			c.Header("Retry-After", strconv.Itoa(retryAfter))
-		}
+		setBillingRateLimitHeaders(c, err, retryAfter)
 		googleError(c, status, message)
 		return
 	}
@@ -284,6 +297,8 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 		}
 	}
 
+	c.Request = c.Request.WithContext(service.WithRoutingScriptRequest(c.Request.Context(), body, c.Request.Header))
+
 	// === Gemini 内容摘要会话 Fallback 逻辑 ===
 	// 当原有会话标识无效时（sessionBoundAccountID == 0），尝试基于内容摘要链匹配
 	var geminiDigestChain string
@@ -426,6 +441,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 					zap.Int64("account_id", account.ID),
 					zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
 				)
+				geminiConcurrency.SetAccountRetryAfter(c, account.ID, selection.WaitPlan.MaxConcurrency)
 				googleError(c, http.StatusTooManyRequests, "Too many pending requests, please retry later")
 				return
 			}
@@ -448,6 +464,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 			)
 			if err != nil {
 				reqLog.Warn("gemini.account_slot_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+				geminiConcurrency.SetAccountRetryAfter(c, account.ID, selection.WaitPlan.MaxConcurrency)
 				googleError(c, http.StatusTooManyRequests, err.Error())
 				return
 			}
@@ -530,6 +547,7 @@ func (h *GatewayHandler) GeminiV1BetaModels(c *gin.Context) {
 				UserAgent:             userAgent,
 				IPAddress:             clientIP,
 				RequestPayloadHash:    requestPayloadHash,
+				PIIRedactions:         piiRedactions,
 				LongContextThreshold:  200000, // Gemini 200K 阈值
 				LongContextMultiplier: 2.0,    // 超出部分双倍计费
 				ForceCacheBilling:     fs.ForceCacheBilling,
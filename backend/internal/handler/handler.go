@@ -35,6 +35,13 @@ type AdminHandlers struct {
 	ChannelMonitorTemplate *admin.ChannelMonitorRequestTemplateHandler
 	Payment                *admin.PaymentHandler
 	Affiliate              *admin.AffiliateHandler
+	Debug                  *admin.DebugHandler
+	Webhook                *admin.WebhookHandler
+	AccountRotation        *admin.AccountRotationHandler
+	ModelCatalog           *admin.ModelCatalogHandler
+	Organization           *admin.OrganizationHandler
+	AuditLog               *admin.AuditLogHandler
+	TrafficReplay          *admin.TrafficReplayHandler
 }
 
 // Handlers contains all HTTP handlers
@@ -50,11 +57,15 @@ type Handlers struct {
 	Admin            *AdminHandlers
 	Gateway          *GatewayHandler
 	OpenAIGateway    *OpenAIGatewayHandler
+	MessageBatch     *MessageBatchHandler
+	APIKeyToken      *APIKeyTokenHandler
 	Setting          *SettingHandler
 	Totp             *TotpHandler
 	Payment          *PaymentHandler
 	PaymentWebhook   *PaymentWebhookHandler
 	AvailableChannel *AvailableChannelHandler
+	UserAccount      *UserAccountHandler
+	Org              *OrgHandler
 }
 
 // BuildInfo contains build-time information
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/gin-gonic/gin"
+)
+
+// Completions handles legacy OpenAI Completions API requests for OpenAI platform groups.
+// POST /v1/completions
+func (h *OpenAIGatewayHandler) Completions(c *gin.Context) {
+	serveLegacyCompletions(c, h.ChatCompletions, h.errorResponse)
+}
+
+// Completions handles legacy OpenAI Completions API requests for Anthropic platform groups.
+// POST /v1/completions
+func (h *GatewayHandler) Completions(c *gin.Context) {
+	serveLegacyCompletions(c, h.ChatCompletions, h.chatCompletionsErrorResponse)
+}
+
+// serveLegacyCompletions 将旧版 Completions 请求（prompt）转换为 Chat Completions 请求交给 chatCompletions 处理，
+// 并把 Chat Completions 响应（含流式）转换回 text_completion 格式。
+// 上游已不再支持旧版接口，计费、调度、用量记录均沿用 Chat Completions 链路。
+func serveLegacyCompletions(c *gin.Context, chatCompletions gin.HandlerFunc, errorResponse func(c *gin.Context, status int, errType, message string)) {
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			errorResponse(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		return
+	}
+	if len(body) == 0 {
+		errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Request body is empty")
+		return
+	}
+
+	chatBody, err := apicompat.CompletionsToChatCompletions(body)
+	if err != nil {
+		if errors.Is(err, apicompat.ErrUnsupportedCompletionsRequest) {
+			errorResponse(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(chatBody))
+	c.Request.ContentLength = int64(len(chatBody))
+
+	w := &legacyCompletionsWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	defer func() {
+		w.finish()
+		c.Writer = w.ResponseWriter
+	}()
+	chatCompletions(c)
+}
+
+type legacyCompletionsMode int
+
+const (
+	legacyCompletionsUndecided legacyCompletionsMode = iota
+	// legacyCompletionsPassthrough 错误响应等非 Chat Completions 结果原样透传
+	legacyCompletionsPassthrough
+	// legacyCompletionsBuffer 非流式 JSON 响应：缓冲完整响应体后一次性转换
+	legacyCompletionsBuffer
+	// legacyCompletionsStream SSE 响应：按行转换 chat.completion.chunk
+	legacyCompletionsStream
+)
+
+// legacyCompletionsWriter 将 Chat Completions 响应改写为旧版 Completions 格式。
+// 根据首次写入时的状态码与 Content-Type 决定处理方式，非 2xx 响应保持原样。
+type legacyCompletionsWriter struct {
+	gin.ResponseWriter
+	mode legacyCompletionsMode
+	buf  bytes.Buffer
+}
+
+func (w *legacyCompletionsWriter) decide() {
+	if w.mode != legacyCompletionsUndecided {
+		return
+	}
+	status := w.ResponseWriter.Status()
+	contentType := w.Header().Get("Content-Type")
+	switch {
+	case status < 200 || status >= 300:
+		w.mode = legacyCompletionsPassthrough
+	case strings.Contains(contentType, "text/event-stream"):
+		w.mode = legacyCompletionsStream
+	case strings.Contains(contentType, "application/json"):
+		w.mode = legacyCompletionsBuffer
+	default:
+		w.mode = legacyCompletionsPassthrough
+	}
+}
+
+func (w *legacyCompletionsWriter) Write(p []byte) (int, error) {
+	w.decide()
+	switch w.mode {
+	case legacyCompletionsBuffer:
+		return w.buf.Write(p)
+	case legacyCompletionsStream:
+		w.buf.Write(p)
+		if err := w.flushCompleteLines(); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	default:
+		return w.ResponseWriter.Write(p)
+	}
+}
+
+func (w *legacyCompletionsWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written 缓冲模式下响应体尚未真正写出，但对处理器而言响应已开始。
+func (w *legacyCompletionsWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// flushCompleteLines 转换并写出缓冲区中所有完整的 SSE 行，不完整的行留待下次写入。
+func (w *legacyCompletionsWriter) flushCompleteLines() error {
+	data := w.buf.Bytes()
+	idx := bytes.LastIndexByte(data, '\n')
+	if idx < 0 {
+		return nil
+	}
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(data[:idx+1], []byte("\n")) {
+		out.Write(convertLegacyCompletionsSSELine(line))
+	}
+	rest := append([]byte(nil), data[idx+1:]...)
+	w.buf.Reset()
+	w.buf.Write(rest)
+	_, err := w.ResponseWriter.Write(out.Bytes())
+	return err
+}
+
+// finish 在处理器返回后写出缓冲的内容。
+func (w *legacyCompletionsWriter) finish() {
+	switch w.mode {
+	case legacyCompletionsBuffer:
+		body := w.buf.Bytes()
+		var resp apicompat.ChatCompletionsResponse
+		if err := json.Unmarshal(body, &resp); err == nil && resp.Object == "chat.completion" {
+			if converted, err := json.Marshal(apicompat.ChatCompletionsToCompletions(&resp)); err == nil {
+				body = converted
+			}
+		}
+		w.Header().Del("Content-Length")
+		_, _ = w.ResponseWriter.Write(body)
+	case legacyCompletionsStream:
+		if w.buf.Len() > 0 {
+			_, _ = w.ResponseWriter.Write(convertLegacyCompletionsSSELine(w.buf.Bytes()))
+		}
+		w.ResponseWriter.Flush()
+	}
+	w.buf.Reset()
+}
+
+// convertLegacyCompletionsSSELine 将 "data: {chat.completion.chunk}" 行转换为 text_completion 块，
+// 其他行（[DONE]、空行、注释、错误事件）原样返回。
+func convertLegacyCompletionsSSELine(line []byte) []byte {
+	payload, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return line
+	}
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return line
+	}
+	var chunk apicompat.ChatCompletionsChunk
+	if err := json.Unmarshal(trimmed, &chunk); err != nil || chunk.Object != "chat.completion.chunk" {
+		return line
+	}
+	converted, err := json.Marshal(apicompat.ChatChunkToCompletionsChunk(&chunk))
+	if err != nil {
+		return line
+	}
+	suffix := payload[len(bytes.TrimRight(payload, "\r\n")):]
+	out := make([]byte, 0, len("data: ")+len(converted)+len(suffix))
+	out = append(out, "data: "...)
+	out = append(out, converted...)
+	return append(out, suffix...)
+}
@@ -0,0 +1,89 @@
+//go:build unit
+
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newLegacyCompletionsTestContext(body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/completions", strings.NewReader(body))
+	return c, rec
+}
+
+func legacyCompletionsTestError(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, gin.H{"error": gin.H{"type": errType, "message": message}})
+}
+
+func TestServeLegacyCompletions_NonStreaming(t *testing.T) {
+	c, rec := newLegacyCompletionsTestContext(`{"model":"gpt-4o-mini","prompt":"Say hi","max_tokens":8}`)
+
+	var forwarded map[string]any
+	serveLegacyCompletions(c, func(c *gin.Context) {
+		raw, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(raw, &forwarded))
+		c.JSON(http.StatusOK, gin.H{
+			"id": "chatcmpl-1", "object": "chat.completion", "created": 1, "model": "gpt-4o-mini",
+			"choices": []gin.H{{"index": 0, "message": gin.H{"role": "assistant", "content": "Hi"}, "finish_reason": "stop"}},
+			"usage":   gin.H{"prompt_tokens": 2, "completion_tokens": 1, "total_tokens": 3},
+		})
+	}, legacyCompletionsTestError)
+
+	require.NotContains(t, forwarded, "prompt")
+	require.Equal(t, "Say hi", forwarded["messages"].([]any)[0].(map[string]any)["content"])
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, "text_completion", resp["object"])
+	require.Equal(t, "cmpl-1", resp["id"])
+	require.Equal(t, "Hi", resp["choices"].([]any)[0].(map[string]any)["text"])
+}
+
+func TestServeLegacyCompletions_Streaming(t *testing.T) {
+	c, rec := newLegacyCompletionsTestContext(`{"model":"m","prompt":"x","stream":true}`)
+
+	serveLegacyCompletions(c, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString(`data: {"id":"chatcmpl-2","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{"content":"He`)
+		_, _ = c.Writer.WriteString(`llo"},"finish_reason":null}]}` + "\n\n")
+		_, _ = c.Writer.WriteString(`data: {"id":"chatcmpl-2","object":"chat.completion.chunk","created":1,"model":"m","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}` + "\n\n")
+		_, _ = c.Writer.WriteString("data: [DONE]\n\n")
+		c.Writer.Flush()
+	}, legacyCompletionsTestError)
+
+	body := rec.Body.String()
+	require.Contains(t, body, `"object":"text_completion"`)
+	require.Contains(t, body, `"text":"Hello"`)
+	require.Contains(t, body, `"finish_reason":"stop"`)
+	require.Contains(t, body, "data: [DONE]\n\n")
+	require.NotContains(t, body, "chat.completion.chunk")
+}
+
+func TestServeLegacyCompletions_ErrorsPassThrough(t *testing.T) {
+	c, rec := newLegacyCompletionsTestContext(`{"model":"m","prompt":"x"}`)
+	serveLegacyCompletions(c, func(c *gin.Context) {
+		legacyCompletionsTestError(c, http.StatusTooManyRequests, "rate_limit_error", "slow down")
+	}, legacyCompletionsTestError)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Contains(t, rec.Body.String(), "slow down")
+
+	c, rec = newLegacyCompletionsTestContext(`{"model":"m","prompt":[1,2]}`)
+	called := false
+	serveLegacyCompletions(c, func(c *gin.Context) { called = true }, legacyCompletionsTestError)
+	require.False(t, called)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
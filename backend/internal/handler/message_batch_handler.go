@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// MessageBatchHandler Claude Messages Batches 接口（/v1/messages/batches、/anthropic/v1/messages/batches）
+type MessageBatchHandler struct {
+	batchService *service.MessageBatchService
+}
+
+// NewMessageBatchHandler creates a new MessageBatchHandler
+func NewMessageBatchHandler(batchService *service.MessageBatchService) *MessageBatchHandler {
+	return &MessageBatchHandler{batchService: batchService}
+}
+
+// SetDispatcher 设置执行批处理子请求的网关路由引擎（路由注册完成后调用）
+func (h *MessageBatchHandler) SetDispatcher(dispatcher http.Handler) {
+	h.batchService.SetDispatcher(dispatcher)
+}
+
+// messageBatchResponse Anthropic message_batch 对象
+type messageBatchResponse struct {
+	ID                string                            `json:"id"`
+	Type              string                            `json:"type"`
+	ProcessingStatus  string                            `json:"processing_status"`
+	RequestCounts     service.MessageBatchRequestCounts `json:"request_counts"`
+	EndedAt           *time.Time                        `json:"ended_at"`
+	CreatedAt         time.Time                         `json:"created_at"`
+	ExpiresAt         time.Time                         `json:"expires_at"`
+	ArchivedAt        *time.Time                        `json:"archived_at"`
+	CancelInitiatedAt *time.Time                        `json:"cancel_initiated_at"`
+	ResultsURL        *string                           `json:"results_url"`
+}
+
+func toMessageBatchResponse(batch *service.MessageBatch) messageBatchResponse {
+	out := messageBatchResponse{
+		ID:                batch.ID,
+		Type:              "message_batch",
+		ProcessingStatus:  batch.ProcessingStatus,
+		RequestCounts:     batch.RequestCounts,
+		EndedAt:           batch.EndedAt,
+		CreatedAt:         batch.CreatedAt,
+		ExpiresAt:         batch.ExpiresAt,
+		CancelInitiatedAt: batch.CancelInitiatedAt,
+	}
+	// 与 Anthropic 一致：仅在批次结束后返回 results_url
+	if batch.ProcessingStatus == service.MessageBatchStatusEnded && batch.ResultsURL != "" {
+		url := batch.ResultsURL
+		out.ResultsURL = &url
+	}
+	return out
+}
+
+// Create 创建批次
+// POST /v1/messages/batches
+func (h *MessageBatchHandler) Create(c *gin.Context) {
+	apiKey, subject, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+	if err != nil {
+		if maxErr, ok := extractMaxBytesError(err); ok {
+			messageBatchError(c, http.StatusRequestEntityTooLarge, "invalid_request_error", buildBodyTooLargeMessage(maxErr.Limit))
+			return
+		}
+		messageBatchError(c, http.StatusBadRequest, "invalid_request_error", "Failed to read request body")
+		return
+	}
+
+	batch, err := h.batchService.Create(c.Request.Context(), service.MessageBatchCreateInput{
+		UserID:         subject.UserID,
+		APIKeyID:       apiKey.ID,
+		ClientIP:       ip.GetClientIP(c),
+		AnthropicBeta:  strings.TrimSpace(c.GetHeader("anthropic-beta")),
+		ResultsBaseURL: messageBatchBaseURL(c),
+		Body:           body,
+	})
+	if err != nil {
+		writeMessageBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toMessageBatchResponse(batch))
+}
+
+// List 列出当前 API Key 的批次
+// GET /v1/messages/batches
+func (h *MessageBatchHandler) List(c *gin.Context) {
+	apiKey, _, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	limit := 20
+	if raw := c.Query("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 || v > 1000 {
+			messageBatchError(c, http.StatusBadRequest, "invalid_request_error", "limit must be an integer between 1 and 1000")
+			return
+		}
+		limit = v
+	}
+	beforeID := strings.TrimSpace(c.Query("before_id"))
+	afterID := strings.TrimSpace(c.Query("after_id"))
+
+	batches, hasMore, err := h.batchService.List(c.Request.Context(), apiKey.ID, beforeID, afterID, limit)
+	if err != nil {
+		writeMessageBatchError(c, err)
+		return
+	}
+	data := make([]messageBatchResponse, 0, len(batches))
+	for _, batch := range batches {
+		data = append(data, toMessageBatchResponse(batch))
+	}
+	resp := gin.H{"data": data, "has_more": hasMore, "first_id": nil, "last_id": nil}
+	if len(data) > 0 {
+		resp["first_id"] = data[0].ID
+		resp["last_id"] = data[len(data)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Get 查询批次
+// GET /v1/messages/batches/:batch_id
+func (h *MessageBatchHandler) Get(c *gin.Context) {
+	apiKey, _, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	batch, err := h.batchService.Get(c.Request.Context(), apiKey.ID, c.Param("batch_id"))
+	if err != nil {
+		writeMessageBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toMessageBatchResponse(batch))
+}
+
+// Cancel 取消批次
+// POST /v1/messages/batches/:batch_id/cancel
+func (h *MessageBatchHandler) Cancel(c *gin.Context) {
+	apiKey, _, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	batch, err := h.batchService.Cancel(c.Request.Context(), apiKey.ID, c.Param("batch_id"))
+	if err != nil {
+		writeMessageBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toMessageBatchResponse(batch))
+}
+
+// Delete 删除已结束的批次
+// DELETE /v1/messages/batches/:batch_id
+func (h *MessageBatchHandler) Delete(c *gin.Context) {
+	apiKey, _, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	id := c.Param("batch_id")
+	if err := h.batchService.Delete(c.Request.Context(), apiKey.ID, id); err != nil {
+		writeMessageBatchError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": id, "type": "message_batch_deleted"})
+}
+
+// Results 以 JSONL 流式返回批次结果（按请求顺序）
+// GET /v1/messages/batches/:batch_id/results
+func (h *MessageBatchHandler) Results(c *gin.Context) {
+	apiKey, _, ok := h.authContext(c)
+	if !ok {
+		return
+	}
+	id := c.Param("batch_id")
+	ctx := c.Request.Context()
+	results, err := h.batchService.Results(ctx, apiKey.ID, id, -1)
+	if err != nil {
+		writeMessageBatchError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/binary")
+	c.Status(http.StatusOK)
+	for len(results) > 0 {
+		for _, req := range results {
+			if _, err := c.Writer.Write(append(service.MessageBatchResultLine(req), '\n')); err != nil {
+				return
+			}
+		}
+		if len(results) < service.MessageBatchResultsPageSize {
+			return
+		}
+		c.Writer.Flush()
+		if results, err = h.batchService.Results(ctx, apiKey.ID, id, results[len(results)-1].Index); err != nil {
+			// 响应已开始，无法再返回错误状态码；中断输出由客户端重试
+			_ = c.Error(err)
+			return
+		}
+	}
+}
+
+func (h *MessageBatchHandler) authContext(c *gin.Context) (*service.APIKey, middleware2.AuthSubject, bool) {
+	apiKey, ok := middleware2.GetAPIKeyFromContext(c)
+	if !ok {
+		messageBatchError(c, http.StatusUnauthorized, "authentication_error", "Invalid API key")
+		return nil, middleware2.AuthSubject{}, false
+	}
+	subject, ok := middleware2.GetAuthSubjectFromContext(c)
+	if !ok {
+		messageBatchError(c, http.StatusInternalServerError, "api_error", "User context not found")
+		return nil, middleware2.AuthSubject{}, false
+	}
+	if !h.batchService.Enabled() {
+		writeMessageBatchError(c, service.ErrMessageBatchDisabled)
+		return nil, middleware2.AuthSubject{}, false
+	}
+	return apiKey, subject, true
+}
+
+// messageBatchBaseURL 返回当前路由前缀下批次资源的外部 URL（如 https://host/anthropic/v1/messages/batches）
+func messageBatchBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	} else if proto := strings.TrimSpace(strings.Split(c.GetHeader("X-Forwarded-Proto"), ",")[0]); proto == "https" || proto == "http" {
+		scheme = proto
+	}
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	return scheme + "://" + c.Request.Host + strings.TrimRight(path, "/")
+}
+
+func writeMessageBatchError(c *gin.Context, err error) {
+	status := infraerrors.Code(err)
+	errType := "api_error"
+	message := "Internal server error"
+	switch status {
+	case http.StatusBadRequest:
+		errType, message = "invalid_request_error", infraerrors.Message(err)
+	case http.StatusNotFound:
+		errType, message = "not_found_error", infraerrors.Message(err)
+	default:
+		status = http.StatusInternalServerError
+		_ = c.Error(err)
+	}
+	messageBatchError(c, status, errType, message)
+}
+
+func messageBatchError(c *gin.Context, status int, errType, message string) {
+	c.JSON(status, gin.H{
+		"type":  "error",
+		"error": gin.H{"type": errType, "message": message},
+	})
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"strconv"
 	"time"
 
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
@@ -62,6 +62,18 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	// 请求体严格校验：在任何改写之前检查客户端原始请求
+	if err := h.requestValidator.Validate(body, service.RequestParamFormatChatCompletions); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", infraerrors.Message(err))
+		return
+	}
+
+	// 请求体预处理：图片、工具结果截断、上下文裁剪、敏感信息过滤、内容审核、参数策略、推理强度、系统提示词与费用上限
+	body, piiRedactions, ok := h.requestPreprocessor().Apply(c, body, apiKey, service.RequestParamFormatChatCompletions, h.errorResponse)
+	if !ok {
+		return
+	}
+
 	if !gjson.ValidBytes(body) {
 		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
 		return
@@ -103,9 +115,7 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
 		reqLog.Info("openai_chat_completions.billing_eligibility_check_failed", zap.Error(err))
 		status, code, message, retryAfter := billingErrorDetails(err)
-		if retryAfter > 0 {
-			c.Header("Retry-After", strconv.Itoa(retryAfter))
-		}
+		setBillingRateLimitHeaders(c, err, retryAfter)
 		h.handleStreamingAwareError(c, status, code, message, streamStarted)
 		return
 	}
@@ -279,6 +289,7 @@ func (h *OpenAIGatewayHandler) ChatCompletions(c *gin.Context) {
 				UpstreamEndpoint:   GetUpstreamEndpoint(c, account.Platform),
 				UserAgent:          userAgent,
 				IPAddress:          clientIP,
+				PIIRedactions:      piiRedactions,
 				APIKeyService:      h.apiKeyService,
 				ChannelUsageFields: channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
 			}); err != nil {
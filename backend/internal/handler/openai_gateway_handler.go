@@ -12,11 +12,14 @@ import (
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	middleware2 "github.com/Wei-Shaw/sub2api/internal/server/middleware"
 	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
 
 	coderws "github.com/coder/websocket"
 	"github.com/gin-gonic/gin"
@@ -35,6 +38,12 @@ type OpenAIGatewayHandler struct {
 	concurrencyHelper       *ConcurrencyHelper
 	maxAccountSwitches      int
 	cfg                     *config.Config
+	piiRedactor             *service.PIIRedactor
+	toolResultLimiter       *service.ToolResultLimiter
+	imageProcessor          *service.ImageProcessor
+	requestValidator        *service.RequestValidator
+	contextTrimmer          *service.ContextTrimmer
+	contentModerator        *service.ContentModerator
 }
 
 func resolveOpenAIForwardDefaultMappedModel(apiKey *service.APIKey, fallbackModel string) string {
@@ -63,6 +72,7 @@ func NewOpenAIGatewayHandler(
 	usageRecordWorkerPool *service.UsageRecordWorkerPool,
 	errorPassthroughService *service.ErrorPassthroughService,
 	cfg *config.Config,
+	contextTrimmer *service.ContextTrimmer,
 ) *OpenAIGatewayHandler {
 	pingInterval := time.Duration(0)
 	maxAccountSwitches := 3
@@ -81,6 +91,12 @@ func NewOpenAIGatewayHandler(
 		concurrencyHelper:       NewConcurrencyHelper(concurrencyService, SSEPingFormatComment, pingInterval),
 		maxAccountSwitches:      maxAccountSwitches,
 		cfg:                     cfg,
+		piiRedactor:             service.NewPIIRedactor(cfg),
+		toolResultLimiter:       service.NewToolResultLimiter(cfg),
+		imageProcessor:          service.NewImageProcessor(cfg),
+		requestValidator:        service.NewRequestValidator(cfg),
+		contextTrimmer:          contextTrimmer,
+		contentModerator:        service.NewContentModerator(cfg),
 	}
 }
 
@@ -135,6 +151,18 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 		return
 	}
 
+	// 请求体严格校验：在任何改写之前检查客户端原始请求
+	if err := h.requestValidator.Validate(body, service.RequestParamFormatResponses); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", infraerrors.Message(err))
+		return
+	}
+
+	// 请求体预处理：图片、工具结果截断、上下文裁剪、敏感信息过滤、内容审核、参数策略、推理强度、系统提示词与费用上限
+	body, piiRedactions, ok := h.requestPreprocessor().Apply(c, body, apiKey, service.RequestParamFormatResponses, h.errorResponse)
+	if !ok {
+		return
+	}
+
 	setOpsRequestContext(c, "", false, body)
 	sessionHashBody := body
 	if service.IsOpenAIResponsesCompactPathForTest(c) {
@@ -187,11 +215,6 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 			h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "previous_response_id must be a response.id (resp_*), not a message id")
 			return
 		}
-		reqLog.Warn("openai.request_validation_failed",
-			zap.String("reason", "previous_response_id_requires_wsv2"),
-		)
-		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "previous_response_id is only supported on Responses WebSocket v2")
-		return
 	}
 
 	setOpsRequestContext(c, reqModel, reqStream, body)
@@ -229,9 +252,7 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
 		reqLog.Info("openai.billing_eligibility_check_failed", zap.Error(err))
 		status, code, message, retryAfter := billingErrorDetails(err)
-		if retryAfter > 0 {
-			c.Header("Retry-After", strconv.Itoa(retryAfter))
-		}
+		setBillingRateLimitHeaders(c, err, retryAfter)
 		h.handleStreamingAwareError(c, status, code, message, streamStarted)
 		return
 	}
@@ -407,6 +428,7 @@ func (h *OpenAIGatewayHandler) Responses(c *gin.Context) {
 				UserAgent:          userAgent,
 				IPAddress:          clientIP,
 				RequestPayloadHash: requestPayloadHash,
+				PIIRedactions:      piiRedactions,
 				APIKeyService:      h.apiKeyService,
 				ChannelUsageFields: channelMapping.ToUsageFields(reqModel, result.UpstreamModel),
 			}); err != nil {
@@ -492,7 +514,7 @@ func (h *OpenAIGatewayHandler) logOpenAIRemoteCompactOutcome(c *gin.Context, sta
 			}
 		}
 		if c.Writer != nil {
-			if upstreamRequestID := strings.TrimSpace(c.Writer.Header().Get("x-request-id")); upstreamRequestID != "" {
+			if upstreamRequestID := strings.TrimSpace(c.Writer.Header().Get(responseheaders.UpstreamRequestIDHeader)); upstreamRequestID != "" {
 				fields = append(fields, zap.String("upstream_request_id", upstreamRequestID))
 			} else if upstreamRequestID := strings.TrimSpace(c.Writer.Header().Get("X-Request-Id")); upstreamRequestID != "" {
 				fields = append(fields, zap.String("upstream_request_id", upstreamRequestID))
@@ -560,6 +582,18 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 		return
 	}
 
+	// 请求体严格校验：在任何改写之前检查客户端原始请求
+	if err := h.requestValidator.Validate(body, service.RequestParamFormatAnthropic); err != nil {
+		h.anthropicErrorResponse(c, http.StatusBadRequest, "invalid_request_error", infraerrors.Message(err))
+		return
+	}
+
+	// 请求体预处理：图片、工具结果截断、上下文裁剪、敏感信息过滤、内容审核、参数策略、推理强度、系统提示词与费用上限
+	body, piiRedactions, ok := h.requestPreprocessor().Apply(c, body, apiKey, service.RequestParamFormatAnthropic, h.anthropicErrorResponse)
+	if !ok {
+		return
+	}
+
 	if !gjson.ValidBytes(body) {
 		h.anthropicErrorResponse(c, http.StatusBadRequest, "invalid_request_error", "Failed to parse request body")
 		return
@@ -604,9 +638,7 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 	if err := h.billingCacheService.CheckBillingEligibility(c.Request.Context(), apiKey.User, apiKey, apiKey.Group, subscription); err != nil {
 		reqLog.Info("openai_messages.billing_eligibility_check_failed", zap.Error(err))
 		status, code, message, retryAfter := billingErrorDetails(err)
-		if retryAfter > 0 {
-			c.Header("Retry-After", strconv.Itoa(retryAfter))
-		}
+		setBillingRateLimitHeaders(c, err, retryAfter)
 		h.anthropicStreamingAwareError(c, status, code, message, streamStarted)
 		return
 	}
@@ -780,6 +812,7 @@ func (h *OpenAIGatewayHandler) Messages(c *gin.Context) {
 				UserAgent:          userAgent,
 				IPAddress:          clientIP,
 				RequestPayloadHash: requestPayloadHash,
+				PIIRedactions:      piiRedactions,
 				APIKeyService:      h.apiKeyService,
 				ChannelUsageFields: channelMappingMsg.ToUsageFields(reqModel, result.UpstreamModel),
 			}); err != nil {
@@ -818,13 +851,17 @@ func (h *OpenAIGatewayHandler) anthropicStreamingAwareError(c *gin.Context, stat
 	if streamStarted {
 		flusher, ok := c.Writer.(http.Flusher)
 		if ok {
-			errPayload, _ := json.Marshal(gin.H{
+			payload := gin.H{
 				"type": "error",
 				"error": gin.H{
 					"type":    errType,
 					"message": message,
 				},
-			})
+			}
+			if requestID, _ := c.Request.Context().Value(ctxkey.RequestID).(string); requestID != "" {
+				payload["request_id"] = requestID
+			}
+			errPayload, _ := json.Marshal(payload)
 			fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", errPayload) //nolint:errcheck
 			flusher.Flush()
 		}
@@ -874,7 +911,7 @@ func (h *OpenAIGatewayHandler) validateFunctionCallOutputRequest(c *gin.Context,
 		reqLog.Warn("openai.request_validation_failed",
 			zap.String("reason", "function_call_output_missing_call_id"),
 		)
-		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "function_call_output requires call_id on HTTP requests; continuation via previous_response_id is only supported on Responses WebSocket v2")
+		h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "function_call_output requires call_id on HTTP requests; continuation via previous_response_id requires Responses WebSocket v2 or an API key account")
 		return false
 	}
 	if validation.HasItemReferenceForAllCallIDs {
@@ -884,7 +921,7 @@ func (h *OpenAIGatewayHandler) validateFunctionCallOutputRequest(c *gin.Context,
 	reqLog.Warn("openai.request_validation_failed",
 		zap.String("reason", "function_call_output_missing_item_reference"),
 	)
-	h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "function_call_output requires item_reference ids matching each call_id on HTTP requests; continuation via previous_response_id is only supported on Responses WebSocket v2")
+	h.errorResponse(c, http.StatusBadRequest, "invalid_request_error", "function_call_output requires item_reference ids matching each call_id on HTTP requests; continuation via previous_response_id requires Responses WebSocket v2 or an API key account")
 	return false
 }
 
@@ -900,6 +937,7 @@ func (h *OpenAIGatewayHandler) acquireResponsesUserSlot(
 	userReleaseFunc, userAcquired, err := h.concurrencyHelper.TryAcquireUserSlot(ctx, userID, userConcurrency)
 	if err != nil {
 		reqLog.Warn("openai.user_slot_acquire_failed", zap.Error(err))
+		h.concurrencyHelper.SetUserRateLimitHeaders(c, userID, userConcurrency)
 		h.handleConcurrencyError(c, err, "user", *streamStarted)
 		return nil, false
 	}
@@ -914,6 +952,7 @@ func (h *OpenAIGatewayHandler) acquireResponsesUserSlot(
 		// 按现有降级语义：等待计数异常时放行后续抢槽流程
 	} else if !canWait {
 		reqLog.Info("openai.user_wait_queue_full", zap.Int("max_wait", maxWait))
+		h.concurrencyHelper.SetUserRateLimitHeaders(c, userID, userConcurrency)
 		h.errorResponse(c, http.StatusTooManyRequests, "rate_limit_error", "Too many pending requests, please retry later")
 		return nil, false
 	}
@@ -928,6 +967,7 @@ func (h *OpenAIGatewayHandler) acquireResponsesUserSlot(
 	userReleaseFunc, err = h.concurrencyHelper.AcquireUserSlotWithWait(c, userID, userConcurrency, reqStream, streamStarted)
 	if err != nil {
 		reqLog.Warn("openai.user_slot_acquire_failed_after_wait", zap.Error(err))
+		h.concurrencyHelper.SetUserRateLimitHeaders(c, userID, userConcurrency)
 		h.handleConcurrencyError(c, err, "user", *streamStarted)
 		return nil, false
 	}
@@ -971,6 +1011,7 @@ func (h *OpenAIGatewayHandler) acquireResponsesAccountSlot(
 	)
 	if err != nil {
 		reqLog.Warn("openai.account_slot_quick_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+		h.concurrencyHelper.SetAccountRetryAfter(c, account.ID, selection.WaitPlan.MaxConcurrency)
 		h.handleConcurrencyError(c, err, "account", *streamStarted)
 		return nil, false
 	}
@@ -989,6 +1030,7 @@ func (h *OpenAIGatewayHandler) acquireResponsesAccountSlot(
 			zap.Int64("account_id", account.ID),
 			zap.Int("max_waiting", selection.WaitPlan.MaxWaiting),
 		)
+		h.concurrencyHelper.SetAccountRetryAfter(c, account.ID, selection.WaitPlan.MaxConcurrency)
 		h.handleStreamingAwareError(c, http.StatusTooManyRequests, "rate_limit_error", "Too many pending requests, please retry later", *streamStarted)
 		return nil, false
 	}
@@ -1012,6 +1054,7 @@ func (h *OpenAIGatewayHandler) acquireResponsesAccountSlot(
 	)
 	if err != nil {
 		reqLog.Warn("openai.account_slot_acquire_failed", zap.Int64("account_id", account.ID), zap.Error(err))
+		h.concurrencyHelper.SetAccountRetryAfter(c, account.ID, selection.WaitPlan.MaxConcurrency)
 		h.handleConcurrencyError(c, err, "account", *streamStarted)
 		return nil, false
 	}
@@ -1523,7 +1566,7 @@ func (h *OpenAIGatewayHandler) handleStreamingAwareError(c *gin.Context, status
 		flusher, ok := c.Writer.(http.Flusher)
 		if ok {
 			// SSE 错误事件固定 schema，使用 Quote 直拼可避免额外 Marshal 分配。
-			errorEvent := "event: error\ndata: " + `{"error":{"type":` + strconv.Quote(errType) + `,"message":` + strconv.Quote(message) + `}}` + "\n\n"
+			errorEvent := "event: error\ndata: " + `{"error":{"type":` + strconv.Quote(errType) + `,"message":` + strconv.Quote(message) + `}` + sseRequestIDField(c) + `}` + "\n\n"
 			if _, err := fmt.Fprint(c.Writer, errorEvent); err != nil {
 				_ = c.Error(err)
 			}
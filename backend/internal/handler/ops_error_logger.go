@@ -470,6 +470,10 @@ func OpsErrorLoggerMiddleware(ops *service.OpsService) gin.HandlerFunc {
 			releaseOpsCaptureWriter(w)
 		}()
 		c.Writer = w
+		if ops != nil && c.Request != nil {
+			// 注入上游耗时记录器：上游 HTTP 客户端据此记录每次尝试的 DNS/建连/TLS/首字节耗时
+			c.Request = c.Request.WithContext(service.WithOpsUpstreamTimingRecorder(c.Request.Context()))
+		}
 		c.Next()
 
 		if ops == nil {
@@ -971,6 +975,14 @@ func applyOpsLatencyFieldsFromContext(c *gin.Context, entry *service.OpsInsertEr
 	entry.UpstreamLatencyMs = getContextLatencyMs(c, service.OpsUpstreamLatencyMsKey)
 	entry.ResponseLatencyMs = getContextLatencyMs(c, service.OpsResponseLatencyMsKey)
 	entry.TimeToFirstTokenMs = getContextLatencyMs(c, service.OpsTimeToFirstTokenMsKey)
+	if c.Request != nil {
+		if timing := service.OpsUpstreamTimingFromContext(c.Request.Context()); timing != nil {
+			entry.UpstreamDNSMs = timing.DNSMs
+			entry.UpstreamConnectMs = timing.ConnectMs
+			entry.UpstreamTLSMs = timing.TLSMs
+			entry.UpstreamFirstByteMs = timing.FirstByteMs
+		}
+	}
 }
 
 func getContextLatencyMs(c *gin.Context, key string) *int64 {
@@ -1066,7 +1078,7 @@ func guessPlatformFromPath(path string) string {
 	switch {
 	case strings.HasPrefix(p, "/antigravity/"):
 		return service.PlatformAntigravity
-	case strings.HasPrefix(p, "/v1beta/"):
+	case strings.HasPrefix(p, "/v1beta/"), strings.HasPrefix(p, "/gemini/"):
 		return service.PlatformGemini
 	case strings.Contains(p, "/responses"), strings.Contains(p, "/images/"):
 		return service.PlatformOpenAI
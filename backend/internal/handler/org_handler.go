@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// orgAPIKeyVisiblePrefix 组织管理员查看成员 API Key 时保留的明文前缀长度
+const orgAPIKeyVisiblePrefix = 8
+
+// OrgHandler 组织管理员接口：仅能管理自己所在组织的成员、分组与 API Key。
+type OrgHandler struct {
+	organizationService *service.OrganizationService
+}
+
+// NewOrgHandler 创建组织管理员 handler
+func NewOrgHandler(organizationService *service.OrganizationService) *OrgHandler {
+	return &OrgHandler{organizationService: organizationService}
+}
+
+// UpdateOrgMemberRequest 更新成员请求；未传字段不修改
+type UpdateOrgMemberRequest struct {
+	Status  *string `json:"status" binding:"omitempty,oneof=active disabled"`
+	OrgRole *string `json:"org_role" binding:"omitempty,oneof=member admin"`
+}
+
+// SetOrgMemberGroupsRequest 设置成员可用的组织分组
+type SetOrgMemberGroupsRequest struct {
+	GroupIDs []int64 `json:"group_ids"`
+}
+
+// UpdateOrgAPIKeyRequest 更新成员 API Key 请求；未传字段不修改
+type UpdateOrgAPIKeyRequest struct {
+	Status     *string  `json:"status" binding:"omitempty,oneof=active inactive"`
+	Quota      *float64 `json:"quota"`
+	ResetQuota *bool    `json:"reset_quota"`
+}
+
+// resolveOrg 解析当前用户管理的组织；非组织管理员返回 403
+func (h *OrgHandler) resolveOrg(c *gin.Context) (*service.Organization, int64, bool) {
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return nil, 0, false
+	}
+	org, err := h.organizationService.ResolveAdminOrg(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return nil, 0, false
+	}
+	return org, subject.UserID, true
+}
+
+// Overview 组织概览（成员数、本月消费与预算）
+// GET /api/v1/org
+func (h *OrgHandler) Overview(c *gin.Context) {
+	org, _, ok := h.resolveOrg(c)
+	if !ok {
+		return
+	}
+	overview, err := h.organizationService.Overview(c.Request.Context(), org)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.OrganizationOverviewFromService(overview))
+}
+
+// ListMembers 列出组织成员
+// GET /api/v1/org/members
+func (h *OrgHandler) ListMembers(c *gin.Context) {
+	org, _, ok := h.resolveOrg(c)
+	if !ok {
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	search := strings.TrimSpace(c.Query("search"))
+	if runes := []rune(search); len(runes) > 100 {
+		search = string(runes[:100])
+	}
+	users, total, err := h.organizationService.ListMembers(c.Request.Context(), org.ID, page, pageSize, service.UserListFilters{
+		Status: c.Query("status"),
+		Search: search,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.User, 0, len(users))
+	for i := range users {
+		out = append(out, *dto.UserFromServiceShallow(&users[i]))
+	}
+	response.Paginated(c, out, total, page, pageSize)
+}
+
+// UpdateMember 更新成员状态 / 组织角色
+// PUT /api/v1/org/members/:id
+func (h *OrgHandler) UpdateMember(c *gin.Context) {
+	org, actorID, ok := h.resolveOrg(c)
+	if !ok {
+		return
+	}
+	memberID, ok := parseOrgPathID(c, "id", "Invalid member ID")
+	if !ok {
+		return
+	}
+	var req UpdateOrgMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	user, err := h.organizationService.UpdateMember(c.Request.Context(), org.ID, actorID, memberID, &service.UpdateOrgMemberInput{
+		Status:  req.Status,
+		OrgRole: req.OrgRole,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UserFromServiceShallow(user))
+}
+
+// RemoveMember 将成员移出组织
+// DELETE /api/v1/org/members/:id
+func (h *OrgHandler) RemoveMember(c *gin.Context) {
+	org, actorID, ok := h.resolveOrg(c)
+	if !ok {
+		return
+	}
+	memberID, ok := parseOrgPathID(c, "id", "Invalid member ID")
+	if !ok {
+		return
+	}
+	if err := h.organizationService.RemoveMemberAsAdmin(c.Request.Context(), org.ID, actorID, memberID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Member removed successfully"})
+}
+
+// ListGroups 列出组织分组
+// GET /api/v1/org/groups
+func (h *OrgHandler) ListGroups(c *gin.Context) {
+	org, _, ok := h.resolveOrg(c)
+	if !ok {
+		return
+	}
+	groups, err := h.organizationService.ListGroups(c.Request.Context(), org.ID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.Group, 0, len(groups))
+	for i := range groups {
+		out = append(out, *dto.GroupFromService(&groups[i]))
+	}
+	response.Success(c, out)
+}
+
+// SetMemberGroups 设置成员可用的组织分组（非组织分组保持不变）
+// PUT /api/v1/org/members/:id/groups
+func (h *OrgHandler) SetMemberGroups(c *gin.Context) {
+	org, _, ok := h.resolveOrg(c)
+	if !ok {
+		return
+	}
+	memberID, ok := parseOrgPathID(c, "id", "Invalid member ID")
+	if !ok {
+		return
+	}
+	var req SetOrgMemberGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	user, err := h.organizationService.SetMemberGroups(c.Request.Context(), org.ID, memberID, req.GroupIDs)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UserFromServiceShallow(user))
+}
+
+// ListMemberAPIKeys 列出成员的 API Key（密钥脱敏）
+// GET /api/v1/org/members/:id/api-keys
+func (h *OrgHandler) ListMemberAPIKeys(c *gin.Context) {
+	org, _, ok := h.resolveOrg(c)
+	if !ok {
+		return
+	}
+	memberID, ok := parseOrgPathID(c, "id", "Invalid member ID")
+	if !ok {
+		return
+	}
+	page, pageSize := response.ParsePagination(c)
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	keys, result, err := h.organizationService.ListMemberAPIKeys(c.Request.Context(), org.ID, memberID, params)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.APIKey, 0, len(keys))
+	for i := range keys {
+		out = append(out, *orgAPIKeyView(&keys[i]))
+	}
+	response.Paginated(c, out, result.Total, page, pageSize)
+}
+
+// UpdateAPIKey 更新成员 API Key 的状态与额度
+// PUT /api/v1/org/api-keys/:id
+func (h *OrgHandler) UpdateAPIKey(c *gin.Context) {
+	org, _, ok := h.resolveOrg(c)
+	if !ok {
+		return
+	}
+	keyID, ok := parseOrgPathID(c, "id", "Invalid API key ID")
+	if !ok {
+		return
+	}
+	var req UpdateOrgAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	key, err := h.organizationService.UpdateMemberAPIKey(c.Request.Context(), org.ID, keyID, &service.UpdateOrgAPIKeyInput{
+		Status:     req.Status,
+		Quota:      req.Quota,
+		ResetQuota: req.ResetQuota,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, orgAPIKeyView(key))
+}
+
+// orgAPIKeyView 组织管理员视角的 API Key：密钥只保留前缀，避免管理员冒用成员身份
+func orgAPIKeyView(k *service.APIKey) *dto.APIKey {
+	out := dto.APIKeyFromService(k)
+	if out == nil {
+		return nil
+	}
+	if len(out.Key) > orgAPIKeyVisiblePrefix {
+		out.Key = out.Key[:orgAPIKeyVisiblePrefix] + "***"
+	} else {
+		out.Key = "***"
+	}
+	return out
+}
+
+func parseOrgPathID(c *gin.Context, name, message string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, message)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// requestErrorWriter 按端点协议格式输出错误响应
+type requestErrorWriter func(c *gin.Context, status int, errType, message string)
+
+// requestCostLimitChecker API Key 单次请求费用上限校验（GatewayService / OpenAIGatewayService 均实现）
+type requestCostLimitChecker interface {
+	CheckRequestCostLimit(ctx context.Context, apiKey *service.APIKey, model string, body []byte) error
+}
+
+// requestPreprocessor 各网关端点共用的请求体预处理流水线，确保所有入口按相同顺序执行同一组改写与拦截
+type requestPreprocessor struct {
+	imageProcessor    *service.ImageProcessor
+	toolResultLimiter *service.ToolResultLimiter
+	contextTrimmer    *service.ContextTrimmer
+	piiRedactor       *service.PIIRedactor
+	contentModerator  *service.ContentModerator
+	costLimiter       requestCostLimitChecker
+}
+
+// requestPreprocessOptions 预处理的端点差异项
+type requestPreprocessOptions struct {
+	// Model 请求模型；为空时从请求体 model 字段读取（Gemini 原生接口的模型位于 URL 路径）
+	Model string
+	// SkipGeneration 跳过仅作用于生成类请求的步骤：参数策略、推理强度、系统提示词与费用上限（如 countTokens）
+	SkipGeneration bool
+}
+
+func (h *GatewayHandler) requestPreprocessor() requestPreprocessor {
+	return requestPreprocessor{
+		imageProcessor:    h.imageProcessor,
+		toolResultLimiter: h.toolResultLimiter,
+		contextTrimmer:    h.contextTrimmer,
+		piiRedactor:       h.piiRedactor,
+		contentModerator:  h.contentModerator,
+		costLimiter:       h.gatewayService,
+	}
+}
+
+func (h *OpenAIGatewayHandler) requestPreprocessor() requestPreprocessor {
+	return requestPreprocessor{
+		imageProcessor:    h.imageProcessor,
+		toolResultLimiter: h.toolResultLimiter,
+		contextTrimmer:    h.contextTrimmer,
+		piiRedactor:       h.piiRedactor,
+		contentModerator:  h.contentModerator,
+		costLimiter:       h.gatewayService,
+	}
+}
+
+// Apply 依次执行图片处理、工具结果截断、上下文自动裁剪、敏感信息过滤、内容审核、请求参数策略、
+// 推理强度、分组系统提示词与单次请求费用上限校验。
+// 返回改写后的请求体与敏感信息过滤命中数；ok=false 表示已通过 writeError 输出错误响应。
+func (p requestPreprocessor) Apply(c *gin.Context, body []byte, apiKey *service.APIKey, format service.RequestParamFormat, writeError requestErrorWriter) ([]byte, int, bool) {
+	return p.ApplyWithOptions(c, body, apiKey, format, requestPreprocessOptions{}, writeError)
+}
+
+// ApplyWithOptions 同 Apply，按 opts 处理端点差异
+func (p requestPreprocessor) ApplyWithOptions(c *gin.Context, body []byte, apiKey *service.APIKey, format service.RequestParamFormat, opts requestPreprocessOptions, writeError requestErrorWriter) ([]byte, int, bool) {
+	model := func(body []byte) string {
+		if opts.Model != "" {
+			return opts.Model
+		}
+		return gjson.GetBytes(body, "model").String()
+	}
+
+	// 内联图片校验与超大图片压缩：拒绝不支持的图片格式，避免超大图片触发上游请求体大小限制
+	body, err := p.imageProcessor.Apply(body, format)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "invalid_request_error", infraerrors.Message(err))
+		return nil, 0, false
+	}
+
+	// 超大工具调用结果截断与上下文窗口自动裁剪：先于敏感信息过滤与审核，避免扫描将被丢弃的内容
+	body = p.toolResultLimiter.Apply(body, format)
+	body = applyContextAutoTrim(c, p.contextTrimmer, body, apiKey, model(body), format)
+
+	// 分组敏感信息过滤与内容审核：先于提示词注入，仅检查客户端提交的内容
+	body, piiRedactions, err := p.piiRedactor.Apply(body, apiKey.Group, format)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "invalid_request_error", infraerrors.Message(err))
+		return nil, 0, false
+	}
+	if err := p.contentModerator.Check(c.Request.Context(), body, apiKey, format); err != nil {
+		writeError(c, infraerrors.Code(err), moderationErrorType(err), infraerrors.Message(err))
+		return nil, 0, false
+	}
+	if opts.SkipGeneration {
+		return body, piiRedactions, true
+	}
+
+	// 分组请求参数策略、推理强度与系统提示词
+	body = service.ApplyRequestParamOverrides(body, apiKey.Group, format)
+	body, err = applyReasoningEffort(c, body, apiKey, model(body), format)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, "invalid_request_error", infraerrors.Message(err))
+		return nil, 0, false
+	}
+	body = service.ApplyGroupSystemPrompt(body, apiKey.Group, format)
+
+	// API Key 单次请求费用上限：按提示词估算 + max_tokens 预估费用，可能超限时在转发前拒绝
+	if err := p.costLimiter.CheckRequestCostLimit(c.Request.Context(), apiKey, model(body), body); err != nil {
+		writeError(c, infraerrors.Code(err), "invalid_request_error", infraerrors.Message(err))
+		return nil, 0, false
+	}
+	return body, piiRedactions, true
+}
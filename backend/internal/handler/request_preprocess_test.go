@@ -0,0 +1,77 @@
+//go:build unit
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCostLimiter struct {
+	models []string
+	err    error
+}
+
+func (f *fakeCostLimiter) CheckRequestCostLimit(_ context.Context, _ *service.APIKey, model string, _ []byte) error {
+	f.models = append(f.models, model)
+	return f.err
+}
+
+func newPreprocessTestContext() *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	return c
+}
+
+func TestRequestPreprocessor_CostLimitUsesBodyModel(t *testing.T) {
+	limiter := &fakeCostLimiter{}
+	p := requestPreprocessor{costLimiter: limiter}
+
+	body, redactions, ok := p.Apply(newPreprocessTestContext(), []byte(`{"model":"claude-sonnet-4-5"}`), &service.APIKey{}, service.RequestParamFormatAnthropic, func(*gin.Context, int, string, string) {
+		t.Fatal("unexpected error response")
+	})
+	require.True(t, ok)
+	require.Zero(t, redactions)
+	require.JSONEq(t, `{"model":"claude-sonnet-4-5"}`, string(body))
+	require.Equal(t, []string{"claude-sonnet-4-5"}, limiter.models)
+}
+
+func TestRequestPreprocessor_SkipGenerationSkipsCostLimit(t *testing.T) {
+	limiter := &fakeCostLimiter{err: infraerrors.BadRequest("COST_LIMIT", "too expensive")}
+	p := requestPreprocessor{costLimiter: limiter}
+
+	_, _, ok := p.ApplyWithOptions(newPreprocessTestContext(), []byte(`{"contents":[]}`), &service.APIKey{}, service.RequestParamFormatGemini, requestPreprocessOptions{
+		Model:          "gemini-2.5-pro",
+		SkipGeneration: true,
+	}, func(*gin.Context, int, string, string) {
+		t.Fatal("unexpected error response")
+	})
+	require.True(t, ok)
+	require.Empty(t, limiter.models)
+}
+
+func TestRequestPreprocessor_WritesCostLimitError(t *testing.T) {
+	limiter := &fakeCostLimiter{err: infraerrors.BadRequest("COST_LIMIT", "too expensive")}
+	p := requestPreprocessor{costLimiter: limiter}
+
+	var gotStatus int
+	var gotType, gotMessage string
+	_, _, ok := p.ApplyWithOptions(newPreprocessTestContext(), []byte(`{"contents":[]}`), &service.APIKey{}, service.RequestParamFormatGemini, requestPreprocessOptions{
+		Model: "gemini-2.5-pro",
+	}, func(_ *gin.Context, status int, errType, message string) {
+		gotStatus, gotType, gotMessage = status, errType, message
+	})
+	require.False(t, ok)
+	require.Equal(t, []string{"gemini-2.5-pro"}, limiter.models)
+	require.Equal(t, http.StatusBadRequest, gotStatus)
+	require.Equal(t, "invalid_request_error", gotType)
+	require.Equal(t, "too expensive", gotMessage)
+}
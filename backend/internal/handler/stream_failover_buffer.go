@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// streamFailoverBuffer 在首个内容增量到达前暂存流式输出（message_start 等前导事件）。
+// 暂存期间 Written() 为 false、Size() 不变，Forward 内"尚未向客户端输出"的 failover 判断与
+// handler 层的写入字节守卫均按未输出处理，上游失败时可丢弃暂存内容并切换账号重试。
+// 检测到内容增量或暂存超过上限后一次性写出，之后直接透传。
+type streamFailoverBuffer struct {
+	gin.ResponseWriter
+	buf       bytes.Buffer
+	scanned   int
+	limit     int
+	status    int
+	header    http.Header
+	committed bool
+}
+
+// startStreamFailoverBuffer 为流式请求挂载 failover 缓冲；未开启、非流式或已向客户端输出（如排队 ping）时返回 nil。
+func startStreamFailoverBuffer(c *gin.Context, cfg *config.Config, reqStream bool) *streamFailoverBuffer {
+	if !reqStream || cfg == nil || !cfg.Gateway.StreamFailoverBuffer || c.Writer.Written() {
+		return nil
+	}
+	b := &streamFailoverBuffer{
+		ResponseWriter: c.Writer,
+		limit:          cfg.Gateway.StreamFailoverBufferMaxBytes,
+		status:         http.StatusOK,
+		header:         c.Writer.Header().Clone(),
+	}
+	c.Writer = b
+	return b
+}
+
+// finish 在 Forward 返回后卸载缓冲：failover 错误且尚未输出时丢弃暂存内容并恢复响应头，
+// 以便下一个账号从头输出；其余情况写出暂存内容。
+func (b *streamFailoverBuffer) finish(c *gin.Context, err error) {
+	if b == nil {
+		return
+	}
+	c.Writer = b.ResponseWriter
+	if b.committed {
+		return
+	}
+	var failoverErr *service.UpstreamFailoverError
+	if errors.As(err, &failoverErr) {
+		header := b.ResponseWriter.Header()
+		for name := range header {
+			delete(header, name)
+		}
+		for name, values := range b.header {
+			header[name] = values
+		}
+		return
+	}
+	b.commit()
+}
+
+func (b *streamFailoverBuffer) commit() {
+	if b.committed {
+		return
+	}
+	b.committed = true
+	b.ResponseWriter.WriteHeader(b.status)
+	if b.buf.Len() > 0 {
+		_, _ = b.ResponseWriter.Write(b.buf.Bytes())
+	}
+	b.ResponseWriter.Flush()
+	b.buf = bytes.Buffer{}
+}
+
+func (b *streamFailoverBuffer) WriteHeader(code int) {
+	if b.committed {
+		b.ResponseWriter.WriteHeader(code)
+		return
+	}
+	b.status = code
+}
+
+func (b *streamFailoverBuffer) WriteHeaderNow() {
+	if b.committed {
+		b.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (b *streamFailoverBuffer) Write(p []byte) (int, error) {
+	if b.committed {
+		return b.ResponseWriter.Write(p)
+	}
+	b.buf.Write(p)
+	if b.buf.Len() > b.limit || b.scanContent() {
+		b.commit()
+	}
+	return len(p), nil
+}
+
+func (b *streamFailoverBuffer) WriteString(s string) (int, error) {
+	return b.Write([]byte(s))
+}
+
+func (b *streamFailoverBuffer) Flush() {
+	if b.committed {
+		b.ResponseWriter.Flush()
+	}
+}
+
+func (b *streamFailoverBuffer) Status() int {
+	if b.committed {
+		return b.ResponseWriter.Status()
+	}
+	return b.status
+}
+
+func (b *streamFailoverBuffer) Written() bool {
+	return b.committed && b.ResponseWriter.Written()
+}
+
+// scanContent 逐行检查新暂存的完整 SSE data 行，判断是否已出现内容增量
+func (b *streamFailoverBuffer) scanContent() bool {
+	data := b.buf.Bytes()
+	for {
+		idx := bytes.IndexByte(data[b.scanned:], '\n')
+		if idx < 0 {
+			return false
+		}
+		line := bytes.TrimSpace(data[b.scanned : b.scanned+idx])
+		b.scanned += idx + 1
+		if payload, ok := bytes.CutPrefix(line, []byte("data:")); ok && sseDataHasContent(bytes.TrimSpace(payload)) {
+			return true
+		}
+	}
+}
+
+// sseDataHasContent 判断 SSE data 是否携带模型输出内容（Anthropic / Chat Completions / Responses / Gemini）
+func sseDataHasContent(data []byte) bool {
+	if len(data) == 0 || data[0] != '{' {
+		return false
+	}
+	eventType := gjson.GetBytes(data, "type").String()
+	if eventType == "content_block_delta" || (strings.HasPrefix(eventType, "response.") && strings.HasSuffix(eventType, ".delta")) {
+		return true
+	}
+	if delta := gjson.GetBytes(data, "choices.0.delta"); delta.Exists() {
+		return delta.Get("content").String() != "" || delta.Get("reasoning_content").String() != "" || delta.Get("tool_calls").Exists()
+	}
+	return gjson.GetBytes(data, "candidates.0.content.parts").Exists()
+}
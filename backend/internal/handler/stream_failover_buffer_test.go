@@ -0,0 +1,98 @@
+//go:build unit
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const streamFailoverContentDeltaSSE = "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"hi\"}}\n\n"
+
+func newStreamFailoverBufferTestContext() (*gin.Context, *httptest.ResponseRecorder, *config.Config) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	cfg := &config.Config{}
+	cfg.Gateway.StreamFailoverBuffer = true
+	cfg.Gateway.StreamFailoverBufferMaxBytes = 64 * 1024
+	return c, w, cfg
+}
+
+func TestStreamFailoverBuffer_DiscardsPreambleOnFailover(t *testing.T) {
+	c, w, cfg := newStreamFailoverBufferTestContext()
+	sizeBefore := c.Writer.Size()
+
+	buf := startStreamFailoverBuffer(c, cfg, true)
+	require.NotNil(t, buf)
+	c.Header("x-request-id", "upstream_a")
+	c.Header("Content-Type", "text/event-stream")
+	_, _ = c.Writer.WriteString(partialMessageStartSSE)
+	c.Writer.Flush()
+
+	// 尚无内容增量：对 Forward 与写入字节守卫表现为未输出
+	require.False(t, c.Writer.Written())
+	require.Equal(t, sizeBefore, c.Writer.Size())
+	require.Zero(t, w.Body.Len())
+
+	buf.finish(c, &service.UpstreamFailoverError{StatusCode: http.StatusBadGateway})
+	require.Equal(t, sizeBefore, c.Writer.Size())
+	require.Empty(t, c.Writer.Header().Get("x-request-id"))
+
+	// 下一个账号正常输出，客户端只看到一份 message_start
+	buf = startStreamFailoverBuffer(c, cfg, true)
+	_, _ = c.Writer.WriteString(partialMessageStartSSE)
+	_, _ = c.Writer.WriteString(streamFailoverContentDeltaSSE)
+	require.True(t, c.Writer.Written())
+	require.Equal(t, partialMessageStartSSE+streamFailoverContentDeltaSSE, w.Body.String())
+	buf.finish(c, nil)
+	require.Equal(t, partialMessageStartSSE+streamFailoverContentDeltaSSE, w.Body.String())
+}
+
+func TestStreamFailoverBuffer_CommitsOnNonFailoverErrorAndLimit(t *testing.T) {
+	c, w, cfg := newStreamFailoverBufferTestContext()
+	buf := startStreamFailoverBuffer(c, cfg, true)
+	c.Status(http.StatusBadGateway)
+	_, _ = c.Writer.WriteString(`{"type":"error"}`)
+	buf.finish(c, errors.New("upstream failed"))
+	require.Equal(t, http.StatusBadGateway, w.Code)
+	require.Equal(t, `{"type":"error"}`, w.Body.String())
+
+	c, w, cfg = newStreamFailoverBufferTestContext()
+	cfg.Gateway.StreamFailoverBufferMaxBytes = 16
+	startStreamFailoverBuffer(c, cfg, true)
+	_, _ = c.Writer.WriteString(partialMessageStartSSE)
+	require.True(t, c.Writer.Written())
+	require.Equal(t, partialMessageStartSSE, w.Body.String())
+}
+
+func TestStreamFailoverBuffer_Disabled(t *testing.T) {
+	c, _, cfg := newStreamFailoverBufferTestContext()
+	require.Nil(t, startStreamFailoverBuffer(c, cfg, false))
+	cfg.Gateway.StreamFailoverBuffer = false
+	require.Nil(t, startStreamFailoverBuffer(c, cfg, true))
+
+	// nil 缓冲可安全调用
+	var buf *streamFailoverBuffer
+	buf.finish(c, nil)
+}
+
+func TestSSEDataHasContent(t *testing.T) {
+	require.True(t, sseDataHasContent([]byte(`{"type":"content_block_delta","delta":{"text":"a"}}`)))
+	require.True(t, sseDataHasContent([]byte(`{"type":"response.output_text.delta","delta":"a"}`)))
+	require.True(t, sseDataHasContent([]byte(`{"choices":[{"delta":{"content":"a"}}]}`)))
+	require.True(t, sseDataHasContent([]byte(`{"choices":[{"delta":{"tool_calls":[{"index":0}]}}]}`)))
+	require.True(t, sseDataHasContent([]byte(`{"candidates":[{"content":{"parts":[{"text":"a"}]}}]}`)))
+	require.False(t, sseDataHasContent([]byte(`{"type":"message_start","message":{}}`)))
+	require.False(t, sseDataHasContent([]byte(`{"choices":[{"delta":{"role":"assistant","content":""}}]}`)))
+	require.False(t, sseDataHasContent([]byte(`{"type":"response.created"}`)))
+	require.False(t, sseDataHasContent([]byte(`[DONE]`)))
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/handler/dto"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/response"
+	"github.com/Wei-Shaw/sub2api/internal/server/middleware"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserAccountHandler 处理用户自助绑定上游账号（BYO account）。
+// 绑定的账号仅对当前用户的 API Key 可调度；响应不回显凭证。
+type UserAccountHandler struct {
+	userAccountService *service.UserAccountService
+}
+
+// NewUserAccountHandler 创建用户自助账号 handler
+func NewUserAccountHandler(userAccountService *service.UserAccountService) *UserAccountHandler {
+	return &UserAccountHandler{userAccountService: userAccountService}
+}
+
+// CreateUserAccountRequest 绑定账号请求
+type CreateUserAccountRequest struct {
+	Name        string         `json:"name"`
+	Platform    string         `json:"platform" binding:"required"`
+	Type        string         `json:"type" binding:"required"`
+	Credentials map[string]any `json:"credentials" binding:"required"`
+	GroupIDs    []int64        `json:"group_ids" binding:"required"`
+}
+
+// UpdateUserAccountRequest 更新账号请求；未传字段不修改
+type UpdateUserAccountRequest struct {
+	Name        *string        `json:"name"`
+	Credentials map[string]any `json:"credentials"`
+	GroupIDs    *[]int64       `json:"group_ids"`
+	Schedulable *bool          `json:"schedulable"`
+}
+
+// List 列出当前用户绑定的账号
+// GET /api/v1/accounts
+func (h *UserAccountHandler) List(c *gin.Context) {
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	accounts, err := h.userAccountService.List(c.Request.Context(), subject.UserID)
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	out := make([]dto.UserAccount, 0, len(accounts))
+	for i := range accounts {
+		out = append(out, *dto.UserAccountFromService(&accounts[i]))
+	}
+	response.Success(c, gin.H{"enabled": h.userAccountService.Enabled(), "items": out})
+}
+
+// Create 绑定账号
+// POST /api/v1/accounts
+func (h *UserAccountHandler) Create(c *gin.Context) {
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req CreateUserAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	account, err := h.userAccountService.Create(c.Request.Context(), subject.UserID, &service.UserAccountInput{
+		Name:        req.Name,
+		Platform:    req.Platform,
+		Type:        req.Type,
+		Credentials: req.Credentials,
+		GroupIDs:    req.GroupIDs,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UserAccountFromService(account))
+}
+
+// Update 更新绑定的账号
+// PUT /api/v1/accounts/:id
+func (h *UserAccountHandler) Update(c *gin.Context) {
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	var req UpdateUserAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+
+	account, err := h.userAccountService.Update(c.Request.Context(), subject.UserID, accountID, &service.UserAccountUpdateInput{
+		Name:        req.Name,
+		Credentials: req.Credentials,
+		GroupIDs:    req.GroupIDs,
+		Schedulable: req.Schedulable,
+	})
+	if err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, dto.UserAccountFromService(account))
+}
+
+// Delete 解绑账号
+// DELETE /api/v1/accounts/:id
+func (h *UserAccountHandler) Delete(c *gin.Context) {
+	subject, ok := middleware.GetAuthSubjectFromContext(c)
+	if !ok {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	accountID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid account ID")
+		return
+	}
+
+	if err := h.userAccountService.Delete(c.Request.Context(), subject.UserID, accountID); err != nil {
+		response.ErrorFrom(c, err)
+		return
+	}
+	response.Success(c, gin.H{"message": "Account deleted successfully"})
+}
@@ -38,6 +38,13 @@ func ProvideAdminHandlers(
 	channelMonitorTemplateHandler *admin.ChannelMonitorRequestTemplateHandler,
 	paymentHandler *admin.PaymentHandler,
 	affiliateHandler *admin.AffiliateHandler,
+	debugHandler *admin.DebugHandler,
+	webhookHandler *admin.WebhookHandler,
+	accountRotationHandler *admin.AccountRotationHandler,
+	modelCatalogHandler *admin.ModelCatalogHandler,
+	organizationHandler *admin.OrganizationHandler,
+	auditLogHandler *admin.AuditLogHandler,
+	trafficReplayHandler *admin.TrafficReplayHandler,
 ) *AdminHandlers {
 	return &AdminHandlers{
 		Dashboard:              dashboardHandler,
@@ -69,6 +76,13 @@ func ProvideAdminHandlers(
 		ChannelMonitorTemplate: channelMonitorTemplateHandler,
 		Payment:                paymentHandler,
 		Affiliate:              affiliateHandler,
+		Debug:                  debugHandler,
+		Webhook:                webhookHandler,
+		AccountRotation:        accountRotationHandler,
+		ModelCatalog:           modelCatalogHandler,
+		Organization:           organizationHandler,
+		AuditLog:               auditLogHandler,
+		TrafficReplay:          trafficReplayHandler,
 	}
 }
 
@@ -95,11 +109,15 @@ func ProvideHandlers(
 	adminHandlers *AdminHandlers,
 	gatewayHandler *GatewayHandler,
 	openaiGatewayHandler *OpenAIGatewayHandler,
+	messageBatchHandler *MessageBatchHandler,
+	apiKeyTokenHandler *APIKeyTokenHandler,
 	settingHandler *SettingHandler,
 	totpHandler *TotpHandler,
 	paymentHandler *PaymentHandler,
 	paymentWebhookHandler *PaymentWebhookHandler,
 	availableChannelHandler *AvailableChannelHandler,
+	userAccountHandler *UserAccountHandler,
+	orgHandler *OrgHandler,
 	_ *service.IdempotencyCoordinator,
 	_ *service.IdempotencyCleanupService,
 ) *Handlers {
@@ -115,11 +133,15 @@ func ProvideHandlers(
 		Admin:            adminHandlers,
 		Gateway:          gatewayHandler,
 		OpenAIGateway:    openaiGatewayHandler,
+		MessageBatch:     messageBatchHandler,
+		APIKeyToken:      apiKeyTokenHandler,
 		Setting:          settingHandler,
 		Totp:             totpHandler,
 		Payment:          paymentHandler,
 		PaymentWebhook:   paymentWebhookHandler,
 		AvailableChannel: availableChannelHandler,
+		UserAccount:      userAccountHandler,
+		Org:              orgHandler,
 	}
 }
 
@@ -136,11 +158,15 @@ var ProviderSet = wire.NewSet(
 	NewChannelMonitorUserHandler,
 	NewGatewayHandler,
 	NewOpenAIGatewayHandler,
+	NewMessageBatchHandler,
+	NewAPIKeyTokenHandler,
 	NewTotpHandler,
 	ProvideSettingHandler,
 	NewPaymentHandler,
 	NewPaymentWebhookHandler,
 	NewAvailableChannelHandler,
+	NewUserAccountHandler,
+	NewOrgHandler,
 
 	// Admin handlers
 	admin.NewDashboardHandler,
@@ -172,6 +198,13 @@ var ProviderSet = wire.NewSet(
 	admin.NewChannelMonitorRequestTemplateHandler,
 	admin.NewPaymentHandler,
 	admin.NewAffiliateHandler,
+	admin.NewDebugHandler,
+	admin.NewWebhookHandler,
+	admin.NewAccountRotationHandler,
+	admin.NewModelCatalogHandler,
+	admin.NewOrganizationHandler,
+	admin.NewAuditLogHandler,
+	admin.NewTrafficReplayHandler,
 
 	// AdminHandlers and Handlers constructors
 	ProvideAdminHandlers,
@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -59,17 +60,23 @@ var rateLimitRun = func(ctx context.Context, client *redis.Client, key string, w
 }
 
 // RateLimiter Redis 速率限制器
+// redisClient 为 nil（concurrency.backend=memory 不部署 Redis）时使用进程内固定窗口计数
 type RateLimiter struct {
 	redis  *redis.Client
 	prefix string
+	local  *localRateWindows
 }
 
 // NewRateLimiter 创建速率限制器实例
 func NewRateLimiter(redisClient *redis.Client) *RateLimiter {
-	return &RateLimiter{
+	r := &RateLimiter{
 		redis:  redisClient,
 		prefix: "rate_limit:",
 	}
+	if redisClient == nil {
+		r.local = newLocalRateWindows()
+	}
+	return r
 }
 
 // Limit 返回速率限制中间件
@@ -95,6 +102,15 @@ func (r *RateLimiter) LimitWithOptions(key string, limit int, window time.Durati
 
 		windowMillis := windowTTLMillis(window)
 
+		if r.local != nil {
+			if r.local.incr(redisKey, time.Duration(windowMillis)*time.Millisecond) > int64(limit) {
+				abortRateLimit(c)
+				return
+			}
+			c.Next()
+			return
+		}
+
 		// 使用 Lua 脚本原子操作增加计数并设置过期
 		count, repaired, err := rateLimitRun(ctx, r.redis, redisKey, windowMillis)
 		if err != nil {
@@ -129,6 +145,48 @@ func windowTTLMillis(window time.Duration) int64 {
 	return ttl
 }
 
+// localRateWindows 进程内固定窗口计数，语义与 Redis 脚本一致：窗口内首次请求开始计时
+type localRateWindows struct {
+	mu        sync.Mutex
+	windows   map[string]localRateWindow
+	lastSweep time.Time
+	nowFn     func() time.Time
+}
+
+type localRateWindow struct {
+	count     int64
+	expiresAt time.Time
+}
+
+func newLocalRateWindows() *localRateWindows {
+	return &localRateWindows{
+		windows: make(map[string]localRateWindow),
+		nowFn:   time.Now,
+	}
+}
+
+func (l *localRateWindows) incr(key string, window time.Duration) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.nowFn()
+	// 周期性清理过期窗口，避免按 IP 生成的键常驻内存
+	if now.Sub(l.lastSweep) >= time.Minute {
+		l.lastSweep = now
+		for k, w := range l.windows {
+			if !now.Before(w.expiresAt) {
+				delete(l.windows, k)
+			}
+		}
+	}
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.expiresAt) {
+		w = localRateWindow{expiresAt: now.Add(window)}
+	}
+	w.count++
+	l.windows[key] = w
+	return w.count
+}
+
 func abortRateLimit(c *gin.Context) {
 	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 		"error":   "rate limit exceeded",
@@ -141,3 +141,35 @@ func TestRateLimiterSuccessAndLimit(t *testing.T) {
 	router.ServeHTTP(recorder, req)
 	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
 }
+
+func TestRateLimiterWithoutRedisUsesLocalWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewRateLimiter(nil)
+	now := time.Unix(1700000000, 0)
+	limiter.local.nowFn = func() time.Time { return now }
+
+	router := gin.New()
+	router.Use(limiter.LimitWithOptions("auth", 2, time.Minute, RateLimitOptions{
+		FailureMode: RateLimitFailClose,
+	}))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	do := func(ip string) int {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = ip + ":1234"
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusOK, do("10.0.0.1"))
+	require.Equal(t, http.StatusOK, do("10.0.0.1"))
+	require.Equal(t, http.StatusTooManyRequests, do("10.0.0.1"))
+	require.Equal(t, http.StatusOK, do("10.0.0.2"))
+
+	now = now.Add(time.Minute)
+	require.Equal(t, http.StatusOK, do("10.0.0.1"))
+}
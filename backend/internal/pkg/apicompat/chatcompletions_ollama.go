@@ -0,0 +1,243 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChatCompletionsToOllama converts a Chat Completions request into an Ollama
+// /api/chat request. Images are only forwarded when supplied as base64 data
+// URIs because Ollama cannot fetch remote URLs. Tool results are linked back
+// to the originating tool call by name, since Ollama has no tool call IDs.
+func ChatCompletionsToOllama(req *ChatCompletionsRequest) (*OllamaChatRequest, error) {
+	out := &OllamaChatRequest{
+		Model:  req.Model,
+		Stream: req.Stream,
+		Tools:  req.Tools,
+	}
+
+	toolNames := make(map[string]string)
+	for _, m := range req.Messages {
+		msg, err := chatMessageToOllama(m, toolNames)
+		if err != nil {
+			return nil, err
+		}
+		out.Messages = append(out.Messages, msg)
+	}
+
+	opts := &OllamaOptions{Temperature: req.Temperature, TopP: req.TopP}
+	if req.MaxCompletionTokens != nil {
+		opts.NumPredict = req.MaxCompletionTokens
+	} else if req.MaxTokens != nil {
+		opts.NumPredict = req.MaxTokens
+	}
+	if len(req.Stop) > 0 {
+		var single string
+		if err := json.Unmarshal(req.Stop, &single); err == nil {
+			opts.Stop = []string{single}
+		} else if err := json.Unmarshal(req.Stop, &opts.Stop); err != nil {
+			return nil, fmt.Errorf("parse stop: %w", err)
+		}
+	}
+	if opts.Temperature != nil || opts.TopP != nil || opts.NumPredict != nil || len(opts.Stop) > 0 {
+		out.Options = opts
+	}
+	return out, nil
+}
+
+func chatMessageToOllama(m ChatMessage, toolNames map[string]string) (OllamaMessage, error) {
+	role := m.Role
+	if role == "developer" {
+		role = "system"
+	}
+	msg := OllamaMessage{Role: role}
+
+	switch role {
+	case "assistant":
+		text, err := parseAssistantContent(m.Content)
+		if err != nil {
+			return msg, err
+		}
+		msg.Content = text
+		msg.Thinking = m.ReasoningContent
+		for _, tc := range m.ToolCalls {
+			toolNames[tc.ID] = tc.Function.Name
+			msg.ToolCalls = append(msg.ToolCalls, OllamaToolCall{Function: OllamaFunctionCall{
+				Name:      tc.Function.Name,
+				Arguments: ollamaToolArguments(tc.Function.Arguments),
+			}})
+		}
+		return msg, nil
+	case "tool":
+		msg.ToolName = toolNames[m.ToolCallID]
+	}
+
+	parsed, err := parseChatMessageContent(m.Content)
+	if err != nil {
+		return msg, fmt.Errorf("parse %s content: %w", m.Role, err)
+	}
+	if parsed.Text != nil {
+		msg.Content = *parsed.Text
+		return msg, nil
+	}
+	msg.Content = flattenChatContentParts(parsed.Parts)
+	for _, p := range parsed.Parts {
+		if p.Type != "image_url" || p.ImageURL == nil || isEmptyBase64DataURI(p.ImageURL.URL) {
+			continue
+		}
+		if _, data, ok := strings.Cut(p.ImageURL.URL, ";base64,"); ok && strings.HasPrefix(p.ImageURL.URL, "data:") {
+			msg.Images = append(msg.Images, data)
+		}
+	}
+	return msg, nil
+}
+
+// ollamaToolArguments converts a Chat Completions arguments string into the
+// JSON object Ollama expects, falling back to an empty object.
+func ollamaToolArguments(args string) json.RawMessage {
+	trimmed := strings.TrimSpace(args)
+	if trimmed == "" || !json.Valid([]byte(trimmed)) || trimmed[0] != '{' {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(trimmed)
+}
+
+// OllamaToChatCompletions converts a non-streaming Ollama /api/chat response
+// into a Chat Completions response. model is the client-facing model name.
+func OllamaToChatCompletions(resp *OllamaChatResponse, model string) *ChatCompletionsResponse {
+	msg := ChatMessage{Role: "assistant", ReasoningContent: resp.Message.Thinking}
+	content, _ := json.Marshal(resp.Message.Content)
+	msg.Content = content
+	msg.ToolCalls = ollamaToolCallsToChat(resp.Message.ToolCalls)
+
+	return &ChatCompletionsResponse{
+		ID:      generateChatCmplID(),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: []ChatChoice{{
+			Index:        0,
+			Message:      msg,
+			FinishReason: ollamaFinishReason(resp.DoneReason, len(msg.ToolCalls) > 0),
+		}},
+		Usage: ollamaChatUsage(resp),
+	}
+}
+
+// OllamaChatStreamState tracks state while converting Ollama NDJSON stream
+// lines into Chat Completions chunks.
+type OllamaChatStreamState struct {
+	ID           string
+	Model        string
+	Created      int64
+	IncludeUsage bool
+
+	sentRole     bool
+	toolCallSeen int
+}
+
+// NewOllamaChatStreamState returns an initialised stream state.
+func NewOllamaChatStreamState(model string, includeUsage bool) *OllamaChatStreamState {
+	return &OllamaChatStreamState{
+		ID:           generateChatCmplID(),
+		Model:        model,
+		Created:      time.Now().Unix(),
+		IncludeUsage: includeUsage,
+	}
+}
+
+// OllamaChunkToChatChunks converts one Ollama stream line into zero or more
+// Chat Completions chunks. The final (done) line produces the finish chunk
+// and, when requested, a trailing usage-only chunk.
+func OllamaChunkToChatChunks(line *OllamaChatResponse, state *OllamaChatStreamState) []ChatCompletionsChunk {
+	var chunks []ChatCompletionsChunk
+
+	delta := ChatDelta{}
+	hasDelta := false
+	if !state.sentRole {
+		state.sentRole = true
+		delta.Role = "assistant"
+		hasDelta = true
+	}
+	if line.Message.Content != "" {
+		delta.Content = stringPtr(line.Message.Content)
+		hasDelta = true
+	}
+	if line.Message.Thinking != "" {
+		delta.ReasoningContent = stringPtr(line.Message.Thinking)
+		hasDelta = true
+	}
+	if len(line.Message.ToolCalls) > 0 {
+		calls := ollamaToolCallsToChat(line.Message.ToolCalls)
+		for i := range calls {
+			idx := state.toolCallSeen + i
+			calls[i].Index = &idx
+		}
+		state.toolCallSeen += len(calls)
+		delta.ToolCalls = calls
+		hasDelta = true
+	}
+	if hasDelta {
+		chunks = append(chunks, state.chunk([]ChatChunkChoice{{Index: 0, Delta: delta}}, nil))
+	}
+
+	if !line.Done {
+		return chunks
+	}
+	finish := ollamaFinishReason(line.DoneReason, state.toolCallSeen > 0)
+	chunks = append(chunks, state.chunk([]ChatChunkChoice{{Index: 0, Delta: ChatDelta{}, FinishReason: &finish}}, nil))
+	if state.IncludeUsage {
+		chunks = append(chunks, state.chunk([]ChatChunkChoice{}, ollamaChatUsage(line)))
+	}
+	return chunks
+}
+
+func (s *OllamaChatStreamState) chunk(choices []ChatChunkChoice, usage *ChatUsage) ChatCompletionsChunk {
+	return ChatCompletionsChunk{
+		ID:      s.ID,
+		Object:  "chat.completion.chunk",
+		Created: s.Created,
+		Model:   s.Model,
+		Choices: choices,
+		Usage:   usage,
+	}
+}
+
+func ollamaToolCallsToChat(calls []OllamaToolCall) []ChatToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]ChatToolCall, 0, len(calls))
+	for _, tc := range calls {
+		args := string(tc.Function.Arguments)
+		if args == "" {
+			args = "{}"
+		}
+		out = append(out, ChatToolCall{
+			ID:       "call_" + strings.TrimPrefix(generateItemID(), "item_"),
+			Type:     "function",
+			Function: ChatFunctionCall{Name: tc.Function.Name, Arguments: args},
+		})
+	}
+	return out
+}
+
+func ollamaFinishReason(doneReason string, hasToolCalls bool) string {
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	if doneReason == "length" {
+		return "length"
+	}
+	return "stop"
+}
+
+func ollamaChatUsage(resp *OllamaChatResponse) *ChatUsage {
+	return &ChatUsage{
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+		TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+	}
+}
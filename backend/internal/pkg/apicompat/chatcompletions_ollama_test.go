@@ -0,0 +1,87 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatCompletionsToOllama(t *testing.T) {
+	body := []byte(`{
+		"model":"llama3.1",
+		"stream":true,
+		"max_tokens":64,
+		"temperature":0.3,
+		"stop":"END",
+		"messages":[
+			{"role":"developer","content":"be brief"},
+			{"role":"user","content":[{"type":"text","text":"what is this"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}},{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]},
+			{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"q\":\"x\"}"}}]},
+			{"role":"tool","tool_call_id":"call_1","content":"found"}
+		],
+		"tools":[{"type":"function","function":{"name":"lookup","parameters":{"type":"object"}}}]
+	}`)
+	var req ChatCompletionsRequest
+	require.NoError(t, json.Unmarshal(body, &req))
+
+	out, err := ChatCompletionsToOllama(&req)
+	require.NoError(t, err)
+	assert.Equal(t, "llama3.1", out.Model)
+	assert.True(t, out.Stream)
+	require.Len(t, out.Tools, 1)
+	require.NotNil(t, out.Options)
+	assert.Equal(t, 64, *out.Options.NumPredict)
+	assert.Equal(t, []string{"END"}, out.Options.Stop)
+
+	require.Len(t, out.Messages, 4)
+	assert.Equal(t, OllamaMessage{Role: "system", Content: "be brief"}, out.Messages[0])
+	assert.Equal(t, "what is this", out.Messages[1].Content)
+	assert.Equal(t, []string{"AAAA"}, out.Messages[1].Images)
+	require.Len(t, out.Messages[2].ToolCalls, 1)
+	assert.JSONEq(t, `{"q":"x"}`, string(out.Messages[2].ToolCalls[0].Function.Arguments))
+	assert.Equal(t, OllamaMessage{Role: "tool", Content: "found", ToolName: "lookup"}, out.Messages[3])
+}
+
+func TestOllamaToChatCompletions(t *testing.T) {
+	resp := &OllamaChatResponse{
+		Model:           "llama3.1",
+		Message:         OllamaMessage{Role: "assistant", Content: "hi"},
+		Done:            true,
+		DoneReason:      "length",
+		PromptEvalCount: 10,
+		EvalCount:       4,
+	}
+	out := OllamaToChatCompletions(resp, "local-llama")
+	assert.Equal(t, "chat.completion", out.Object)
+	assert.Equal(t, "local-llama", out.Model)
+	require.Len(t, out.Choices, 1)
+	assert.Equal(t, "length", out.Choices[0].FinishReason)
+	assert.JSONEq(t, `"hi"`, string(out.Choices[0].Message.Content))
+	assert.Equal(t, &ChatUsage{PromptTokens: 10, CompletionTokens: 4, TotalTokens: 14}, out.Usage)
+}
+
+func TestOllamaChunkToChatChunks(t *testing.T) {
+	state := NewOllamaChatStreamState("local-llama", true)
+
+	chunks := OllamaChunkToChatChunks(&OllamaChatResponse{Message: OllamaMessage{Content: "he"}}, state)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "assistant", chunks[0].Choices[0].Delta.Role)
+	assert.Equal(t, "he", *chunks[0].Choices[0].Delta.Content)
+
+	chunks = OllamaChunkToChatChunks(&OllamaChatResponse{Message: OllamaMessage{
+		ToolCalls: []OllamaToolCall{{Function: OllamaFunctionCall{Name: "lookup", Arguments: json.RawMessage(`{"q":1}`)}}},
+	}}, state)
+	require.Len(t, chunks, 1)
+	assert.Empty(t, chunks[0].Choices[0].Delta.Role)
+	require.Len(t, chunks[0].Choices[0].Delta.ToolCalls, 1)
+	assert.Equal(t, 0, *chunks[0].Choices[0].Delta.ToolCalls[0].Index)
+
+	chunks = OllamaChunkToChatChunks(&OllamaChatResponse{Done: true, DoneReason: "stop", PromptEvalCount: 7, EvalCount: 3}, state)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "tool_calls", *chunks[0].Choices[0].FinishReason)
+	assert.Empty(t, chunks[1].Choices)
+	assert.Equal(t, 10, chunks[1].Usage.TotalTokens)
+	assert.Equal(t, state.ID, chunks[1].ID)
+}
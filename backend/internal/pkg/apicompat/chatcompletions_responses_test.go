@@ -827,6 +827,40 @@ func TestFinalizeResponsesChatStream_AfterCompleted(t *testing.T) {
 	assert.Nil(t, FinalizeResponsesChatStream(state))
 }
 
+func TestFinalizeChatStreamUsage(t *testing.T) {
+	fallback := &ChatUsage{PromptTokens: 20, CompletionTokens: 3, TotalTokens: 23}
+
+	// Upstream reported usage: no extra chunk.
+	state := NewResponsesEventToChatState()
+	state.IncludeUsage = true
+	ResponsesEventToChatChunks(&ResponsesStreamEvent{
+		Type:     "response.completed",
+		Response: &ResponsesResponse{Status: "completed", Usage: &ResponsesUsage{InputTokens: 10, OutputTokens: 5}},
+	}, state)
+	assert.True(t, state.UsageSent)
+	assert.Nil(t, FinalizeChatStreamUsage(state, fallback))
+
+	// All-zero upstream usage is treated as missing; the fallback is emitted once.
+	state = NewResponsesEventToChatState()
+	state.IncludeUsage = true
+	ResponsesEventToChatChunks(&ResponsesStreamEvent{Type: "response.output_text.delta", Delta: "héllo"}, state)
+	chunks := ResponsesEventToChatChunks(&ResponsesStreamEvent{
+		Type:     "response.completed",
+		Response: &ResponsesResponse{Status: "completed", Usage: &ResponsesUsage{}},
+	}, state)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, 5, state.OutputChars)
+	chunks = FinalizeChatStreamUsage(state, fallback)
+	require.Len(t, chunks, 1)
+	assert.Empty(t, chunks[0].Choices)
+	assert.Equal(t, 23, chunks[0].Usage.TotalTokens)
+	assert.Nil(t, FinalizeChatStreamUsage(state, fallback))
+
+	// Client did not ask for usage.
+	state = NewResponsesEventToChatState()
+	assert.Nil(t, FinalizeChatStreamUsage(state, fallback))
+}
+
 func TestChatChunkToSSE(t *testing.T) {
 	chunk := ChatCompletionsChunk{
 		ID:      "chatcmpl-test",
@@ -0,0 +1,105 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionsToChatCompletions_StringPrompt(t *testing.T) {
+	body := []byte(`{"model":"gpt-3.5-turbo-instruct","prompt":"Say hi","max_tokens":16,"temperature":0.2,"stop":["\n"],"stream":true,"stream_options":{"include_usage":true},"echo":false,"logprobs":null,"best_of":1,"user":"u1"}`)
+
+	out, err := CompletionsToChatCompletions(body)
+	require.NoError(t, err)
+
+	var req map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(out, &req))
+	for _, key := range []string{"prompt", "echo", "logprobs", "best_of"} {
+		assert.NotContains(t, req, key)
+	}
+	assert.JSONEq(t, `[{"role":"user","content":"Say hi"}]`, string(req["messages"]))
+	assert.JSONEq(t, `16`, string(req["max_tokens"]))
+	assert.JSONEq(t, `["\n"]`, string(req["stop"]))
+	assert.JSONEq(t, `true`, string(req["stream"]))
+	assert.JSONEq(t, `{"include_usage":true}`, string(req["stream_options"]))
+	assert.JSONEq(t, `"u1"`, string(req["user"]))
+}
+
+func TestCompletionsToChatCompletions_SingleElementArrayPrompt(t *testing.T) {
+	out, err := CompletionsToChatCompletions([]byte(`{"model":"m","prompt":["hello"]}`))
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"content":"hello"`)
+}
+
+func TestCompletionsToChatCompletions_Unsupported(t *testing.T) {
+	cases := []string{
+		`{"model":"m"}`,
+		`{"model":"m","prompt":["a","b"]}`,
+		`{"model":"m","prompt":[1,2,3]}`,
+		`{"model":"m","prompt":"a","suffix":"b"}`,
+		`{"model":"m","prompt":"a","echo":true}`,
+		`{"model":"m","prompt":"a","logprobs":5}`,
+		`{"model":"m","prompt":"a","best_of":3}`,
+	}
+	for _, body := range cases {
+		_, err := CompletionsToChatCompletions([]byte(body))
+		require.Error(t, err, body)
+		assert.True(t, errors.Is(err, ErrUnsupportedCompletionsRequest), body)
+	}
+}
+
+func TestChatCompletionsToCompletions(t *testing.T) {
+	resp := &ChatCompletionsResponse{
+		ID:      "chatcmpl-abc",
+		Object:  "chat.completion",
+		Created: 1700000000,
+		Model:   "gpt-4o-mini",
+		Choices: []ChatChoice{{
+			Index:        0,
+			Message:      ChatMessage{Role: "assistant", Content: json.RawMessage(`"Hi there"`)},
+			FinishReason: "length",
+		}},
+		Usage: &ChatUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+	}
+
+	out := ChatCompletionsToCompletions(resp)
+	assert.Equal(t, "cmpl-abc", out.ID)
+	assert.Equal(t, "text_completion", out.Object)
+	require.Len(t, out.Choices, 1)
+	assert.Equal(t, "Hi there", out.Choices[0].Text)
+	require.NotNil(t, out.Choices[0].FinishReason)
+	assert.Equal(t, "length", *out.Choices[0].FinishReason)
+	assert.Equal(t, 5, out.Usage.TotalTokens)
+
+	data, err := json.Marshal(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"logprobs":null`)
+}
+
+func TestChatChunkToCompletionsChunk(t *testing.T) {
+	content := "Hel"
+	stop := "stop"
+	chunk := &ChatCompletionsChunk{
+		ID:    "chatcmpl-1",
+		Model: "m",
+		Choices: []ChatChunkChoice{
+			{Index: 0, Delta: ChatDelta{Content: &content}},
+		},
+	}
+	out := ChatChunkToCompletionsChunk(chunk)
+	assert.Equal(t, "cmpl-1", out.ID)
+	assert.Equal(t, "Hel", out.Choices[0].Text)
+	assert.Nil(t, out.Choices[0].FinishReason)
+
+	final := &ChatCompletionsChunk{
+		ID:      "chatcmpl-1",
+		Choices: []ChatChunkChoice{{Index: 0, Delta: ChatDelta{}, FinishReason: &stop}},
+	}
+	out = ChatChunkToCompletionsChunk(final)
+	assert.Equal(t, "", out.Choices[0].Text)
+	require.NotNil(t, out.Choices[0].FinishReason)
+	assert.Equal(t, "stop", *out.Choices[0].FinishReason)
+}
@@ -0,0 +1,196 @@
+package apicompat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Legacy Completions API (POST /v1/completions) ↔ Chat Completions
+// ---------------------------------------------------------------------------
+
+// CompletionsResponse is the (non-streaming and streaming) response body of the
+// legacy Completions API. Streaming chunks use the same shape with
+// object "text_completion".
+type CompletionsResponse struct {
+	ID                string              `json:"id"`
+	Object            string              `json:"object"` // "text_completion"
+	Created           int64               `json:"created"`
+	Model             string              `json:"model"`
+	Choices           []CompletionsChoice `json:"choices"`
+	Usage             *ChatUsage          `json:"usage,omitempty"`
+	SystemFingerprint string              `json:"system_fingerprint,omitempty"`
+}
+
+// CompletionsChoice is a single legacy completion choice.
+type CompletionsChoice struct {
+	Text         string          `json:"text"`
+	Index        int             `json:"index"`
+	Logprobs     json.RawMessage `json:"logprobs"`
+	FinishReason *string         `json:"finish_reason"`
+}
+
+// completionsOnlyFields are request fields of the legacy API that have no Chat
+// Completions equivalent and are dropped (after validation) during conversion.
+var completionsOnlyFields = []string{"prompt", "suffix", "echo", "logprobs", "best_of"}
+
+// ErrUnsupportedCompletionsRequest is returned for legacy requests that cannot be
+// expressed as a single chat conversation.
+var ErrUnsupportedCompletionsRequest = errors.New("unsupported completions request")
+
+// CompletionsToChatCompletions converts a legacy Completions request body into a
+// Chat Completions request body. The prompt becomes a single user message; all
+// other fields shared by both APIs (model, max_tokens, temperature, top_p, n,
+// stop, stream, stream_options, penalties, seed, user, logit_bias, ...) are
+// carried over unchanged.
+//
+// Only text prompts are supported: a string, or an array holding exactly one
+// string. Token-id prompts, batched prompts, suffix, echo, logprobs and
+// best_of > 1 are rejected with ErrUnsupportedCompletionsRequest.
+func CompletionsToChatCompletions(body []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("parse completions request: %w", err)
+	}
+
+	prompt, err := parseCompletionsPrompt(fields["prompt"])
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := fields["suffix"]; ok && !isJSONNullOrEmptyString(raw) {
+		return nil, fmt.Errorf("%w: suffix is not supported", ErrUnsupportedCompletionsRequest)
+	}
+	if raw, ok := fields["echo"]; ok && strings.TrimSpace(string(raw)) == "true" {
+		return nil, fmt.Errorf("%w: echo is not supported", ErrUnsupportedCompletionsRequest)
+	}
+	if raw, ok := fields["logprobs"]; ok && !isJSONNullOrZero(raw) {
+		return nil, fmt.Errorf("%w: logprobs is not supported", ErrUnsupportedCompletionsRequest)
+	}
+	if raw, ok := fields["best_of"]; ok && !isJSONNullOrZero(raw) && strings.TrimSpace(string(raw)) != "1" {
+		return nil, fmt.Errorf("%w: best_of is not supported", ErrUnsupportedCompletionsRequest)
+	}
+
+	for _, key := range completionsOnlyFields {
+		delete(fields, key)
+	}
+	content, err := json.Marshal(prompt)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := json.Marshal([]ChatMessage{{Role: "user", Content: content}})
+	if err != nil {
+		return nil, err
+	}
+	fields["messages"] = messages
+	return json.Marshal(fields)
+}
+
+func parseCompletionsPrompt(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", fmt.Errorf("%w: prompt is required", ErrUnsupportedCompletionsRequest)
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var arr []json.RawMessage
+	if err := json.Unmarshal(raw, &arr); err != nil {
+		return "", fmt.Errorf("%w: prompt must be a string", ErrUnsupportedCompletionsRequest)
+	}
+	if len(arr) != 1 {
+		return "", fmt.Errorf("%w: batched prompts are not supported", ErrUnsupportedCompletionsRequest)
+	}
+	if err := json.Unmarshal(arr[0], &s); err != nil {
+		return "", fmt.Errorf("%w: token id prompts are not supported", ErrUnsupportedCompletionsRequest)
+	}
+	return s, nil
+}
+
+func isJSONNullOrZero(raw json.RawMessage) bool {
+	v := strings.TrimSpace(string(raw))
+	return v == "" || v == "null" || v == "0" || v == "false"
+}
+
+func isJSONNullOrEmptyString(raw json.RawMessage) bool {
+	v := strings.TrimSpace(string(raw))
+	return v == "" || v == "null" || v == `""`
+}
+
+// ChatCompletionsToCompletions converts a non-streaming Chat Completions
+// response into a legacy Completions response. The assistant message content
+// becomes the choice text.
+func ChatCompletionsToCompletions(resp *ChatCompletionsResponse) *CompletionsResponse {
+	out := &CompletionsResponse{
+		ID:                completionsIDFromChat(resp.ID),
+		Object:            "text_completion",
+		Created:           resp.Created,
+		Model:             resp.Model,
+		Choices:           make([]CompletionsChoice, 0, len(resp.Choices)),
+		Usage:             resp.Usage,
+		SystemFingerprint: resp.SystemFingerprint,
+	}
+	for _, choice := range resp.Choices {
+		text, _ := parseChatContent(choice.Message.Content)
+		out.Choices = append(out.Choices, CompletionsChoice{
+			Text:         text,
+			Index:        choice.Index,
+			Logprobs:     json.RawMessage("null"),
+			FinishReason: completionsFinishReason(choice.FinishReason),
+		})
+	}
+	return out
+}
+
+// ChatChunkToCompletionsChunk converts a streaming Chat Completions chunk into a
+// legacy Completions stream chunk. Role-only and reasoning deltas become empty
+// text; usage-only chunks keep their empty choices.
+func ChatChunkToCompletionsChunk(chunk *ChatCompletionsChunk) *CompletionsResponse {
+	out := &CompletionsResponse{
+		ID:                completionsIDFromChat(chunk.ID),
+		Object:            "text_completion",
+		Created:           chunk.Created,
+		Model:             chunk.Model,
+		Choices:           make([]CompletionsChoice, 0, len(chunk.Choices)),
+		Usage:             chunk.Usage,
+		SystemFingerprint: chunk.SystemFingerprint,
+	}
+	for _, choice := range chunk.Choices {
+		text := ""
+		if choice.Delta.Content != nil {
+			text = *choice.Delta.Content
+		}
+		var finishReason *string
+		if choice.FinishReason != nil {
+			finishReason = completionsFinishReason(*choice.FinishReason)
+		}
+		out.Choices = append(out.Choices, CompletionsChoice{
+			Text:         text,
+			Index:        choice.Index,
+			Logprobs:     json.RawMessage("null"),
+			FinishReason: finishReason,
+		})
+	}
+	return out
+}
+
+// completionsIDFromChat maps a "chatcmpl-" id to the legacy "cmpl-" prefix.
+func completionsIDFromChat(id string) string {
+	if rest, ok := strings.CutPrefix(id, "chatcmpl-"); ok {
+		return "cmpl-" + rest
+	}
+	return id
+}
+
+// completionsFinishReason maps chat finish reasons onto the legacy set
+// ("stop" | "length" | "content_filter").
+func completionsFinishReason(reason string) *string {
+	switch reason {
+	case "":
+		return nil
+	case "tool_calls", "function_call":
+		reason = "stop"
+	}
+	return &reason
+}
@@ -0,0 +1,276 @@
+package apicompat
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ReasoningEffortHeader is the unified gateway parameter for reasoning
+// control. Clients set one provider-neutral level and the gateway rewrites it
+// into whatever field the upstream platform/model expects.
+const ReasoningEffortHeader = "X-Reasoning-Effort"
+
+// Unified reasoning effort levels, ordered from least to most reasoning.
+const (
+	ReasoningEffortNone    = "none"
+	ReasoningEffortMinimal = "minimal"
+	ReasoningEffortLow     = "low"
+	ReasoningEffortMedium  = "medium"
+	ReasoningEffortHigh    = "high"
+	ReasoningEffortXHigh   = "xhigh"
+)
+
+var reasoningEffortOrder = []string{
+	ReasoningEffortNone,
+	ReasoningEffortMinimal,
+	ReasoningEffortLow,
+	ReasoningEffortMedium,
+	ReasoningEffortHigh,
+	ReasoningEffortXHigh,
+}
+
+// ReasoningFormat identifies the request body protocol an effort level is
+// written into.
+type ReasoningFormat int
+
+const (
+	ReasoningFormatAnthropic ReasoningFormat = iota
+	ReasoningFormatChatCompletions
+	ReasoningFormatResponses
+	ReasoningFormatGemini
+)
+
+// anthropicMinThinkingBudget is the smallest thinking.budget_tokens Anthropic accepts.
+const anthropicMinThinkingBudget = 1024
+
+// NormalizeReasoningEffort canonicalizes a unified effort value. Separators
+// and case are ignored, and common aliases are accepted:
+//
+//	off / disabled     → none
+//	max / extra-high   → xhigh
+//
+// ok is false for unknown values.
+func NormalizeReasoningEffort(raw string) (string, bool) {
+	value := strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(raw)))
+	switch value {
+	case "none", "off", "disabled":
+		return ReasoningEffortNone, true
+	case "minimal", "low", "medium", "high":
+		return value, true
+	case "xhigh", "extrahigh", "max":
+		return ReasoningEffortXHigh, true
+	default:
+		return "", false
+	}
+}
+
+// ApplyReasoningEffort writes a normalized effort level into the field the
+// upstream expects for the given body format and model:
+//
+//	Anthropic         output_config.effort + thinking.budget_tokens
+//	Chat Completions  reasoning_effort (clamped to the levels the model accepts)
+//	Responses         reasoning.effort (clamped to the levels the model accepts)
+//	Gemini 2.5        generationConfig.thinkingConfig.thinkingBudget
+//	Gemini 3          generationConfig.thinkingConfig.thinkingLevel
+//
+// When force is false a value the client already set is kept. Models without
+// reasoning controls, unknown effort values and invalid JSON bodies are
+// returned unchanged.
+func ApplyReasoningEffort(body []byte, format ReasoningFormat, model, effort string, force bool) []byte {
+	effort, ok := NormalizeReasoningEffort(effort)
+	if !ok || !gjson.ValidBytes(body) {
+		return body
+	}
+	switch format {
+	case ReasoningFormatAnthropic:
+		return applyAnthropicReasoningEffort(body, model, effort, force)
+	case ReasoningFormatChatCompletions:
+		return applyOpenAIReasoningEffort(body, "reasoning_effort", model, effort, force)
+	case ReasoningFormatResponses:
+		return applyOpenAIReasoningEffort(body, "reasoning.effort", model, effort, force)
+	case ReasoningFormatGemini:
+		return applyGeminiReasoningEffort(body, model, effort, force)
+	}
+	return body
+}
+
+func applyAnthropicReasoningEffort(body []byte, model, effort string, force bool) []byte {
+	if !anthropicModelSupportsThinking(model) {
+		return body
+	}
+	if !force && gjson.GetBytes(body, "output_config.effort").Exists() {
+		return body
+	}
+
+	if effort == ReasoningEffortNone {
+		body = deleteJSONPath(body, "output_config.effort")
+		return setJSONPath(body, "thinking", map[string]any{"type": "disabled"})
+	}
+
+	anthropicEffort := effort
+	switch effort {
+	case ReasoningEffortMinimal:
+		anthropicEffort = "low"
+	case ReasoningEffortXHigh:
+		anthropicEffort = "max"
+	}
+	body = setJSONPath(body, "output_config.effort", anthropicEffort)
+
+	// Low efforts keep thinking off (same as the Responses → Anthropic conversion);
+	// higher efforts enable it unless the client configured thinking itself.
+	if anthropicEffort == "low" || (!force && gjson.GetBytes(body, "thinking").Exists()) {
+		return body
+	}
+	budget := defaultThinkingBudget(anthropicEffort)
+	if maxTokens := gjson.GetBytes(body, "max_tokens").Int(); maxTokens > 0 && int64(budget) >= maxTokens {
+		// budget_tokens must stay below max_tokens
+		budget = int(maxTokens) - 1
+	}
+	if budget < anthropicMinThinkingBudget {
+		return body
+	}
+	return setJSONPath(body, "thinking", map[string]any{"type": "enabled", "budget_tokens": budget})
+}
+
+// anthropicModelSupportsThinking reports whether a Claude model accepts
+// extended thinking. Claude 3 models before 3.7 do not; unknown or empty
+// models are assumed to support it.
+func anthropicModelSupportsThinking(model string) bool {
+	m := reasoningModelID(model)
+	return !strings.HasPrefix(m, "claude-3-") || strings.HasPrefix(m, "claude-3-7")
+}
+
+func applyOpenAIReasoningEffort(body []byte, path, model, effort string, force bool) []byte {
+	levels, ok := openAIReasoningLevels(model)
+	if !ok {
+		return body
+	}
+	if !force && gjson.GetBytes(body, path).Exists() {
+		return body
+	}
+	return setJSONPath(body, path, clampReasoningEffort(effort, levels))
+}
+
+// openAIReasoningLevels returns the effort levels an OpenAI model accepts.
+// ok is false for models without reasoning support. An empty level list means
+// the model is unknown and the value is passed through as is.
+func openAIReasoningLevels(model string) ([]string, bool) {
+	m := reasoningModelID(model)
+	switch {
+	case m == "":
+		return nil, true
+	case strings.HasPrefix(m, "o1"), strings.HasPrefix(m, "o3"), strings.HasPrefix(m, "o4"), strings.HasPrefix(m, "codex-mini"):
+		return []string{ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh}, true
+	case strings.HasPrefix(m, "gpt-5."):
+		return reasoningEffortOrder, true
+	case strings.HasPrefix(m, "gpt-5"):
+		// gpt-5 cannot disable reasoning; "none" arrived with gpt-5.1
+		return reasoningEffortOrder[1:], true
+	case strings.HasPrefix(m, "gpt-"):
+		return nil, false
+	default:
+		return nil, true
+	}
+}
+
+func applyGeminiReasoningEffort(body []byte, model, effort string, force bool) []byte {
+	m := reasoningModelID(model)
+	if !force && gjson.GetBytes(body, "generationConfig.thinkingConfig").Exists() {
+		return body
+	}
+	switch {
+	case strings.HasPrefix(m, "gemini-3"):
+		level := "high"
+		if effort == ReasoningEffortNone || effort == ReasoningEffortMinimal || effort == ReasoningEffortLow {
+			level = "low"
+		}
+		body = deleteJSONPath(body, "generationConfig.thinkingConfig.thinkingBudget")
+		return setJSONPath(body, "generationConfig.thinkingConfig.thinkingLevel", level)
+	case strings.HasPrefix(m, "gemini-2.5"):
+		body = deleteJSONPath(body, "generationConfig.thinkingConfig.thinkingLevel")
+		return setJSONPath(body, "generationConfig.thinkingConfig.thinkingBudget", geminiThinkingBudget(m, effort))
+	default:
+		return body
+	}
+}
+
+// geminiThinkingBudget maps an effort level to a Gemini 2.5 thinking budget.
+// Pro models cannot disable thinking (minimum 128) and allow up to 32768
+// tokens; Flash models allow 0 and cap at 24576.
+func geminiThinkingBudget(model, effort string) int {
+	pro := strings.Contains(model, "-pro")
+	switch effort {
+	case ReasoningEffortNone:
+		if pro {
+			return 128
+		}
+		return 0
+	case ReasoningEffortMinimal:
+		return 512
+	case ReasoningEffortLow:
+		return 1024
+	case ReasoningEffortMedium:
+		return 8192
+	case ReasoningEffortHigh:
+		return 24576
+	default:
+		if pro {
+			return 32768
+		}
+		return 24576
+	}
+}
+
+// clampReasoningEffort returns effort if the model accepts it, otherwise the
+// nearest accepted level (preferring the higher one on ties).
+func clampReasoningEffort(effort string, levels []string) string {
+	if len(levels) == 0 {
+		return effort
+	}
+	target := reasoningEffortRank(effort)
+	best, bestDist := levels[0], -1
+	for _, level := range levels {
+		dist := reasoningEffortRank(level) - target
+		if dist < 0 {
+			dist = -dist
+		}
+		if bestDist < 0 || dist <= bestDist {
+			best, bestDist = level, dist
+		}
+	}
+	return best
+}
+
+func reasoningEffortRank(effort string) int {
+	for i, level := range reasoningEffortOrder {
+		if level == effort {
+			return i
+		}
+	}
+	return -1
+}
+
+// reasoningModelID lowercases a model name and strips any "provider/" prefix.
+func reasoningModelID(model string) string {
+	m := strings.ToLower(strings.TrimSpace(model))
+	if idx := strings.LastIndex(m, "/"); idx >= 0 {
+		m = m[idx+1:]
+	}
+	return m
+}
+
+func setJSONPath(body []byte, path string, value any) []byte {
+	if updated, err := sjson.SetBytes(body, path, value); err == nil {
+		return updated
+	}
+	return body
+}
+
+func deleteJSONPath(body []byte, path string) []byte {
+	if updated, err := sjson.DeleteBytes(body, path); err == nil {
+		return updated
+	}
+	return body
+}
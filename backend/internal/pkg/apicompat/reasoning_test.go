@@ -0,0 +1,89 @@
+package apicompat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestNormalizeReasoningEffort(t *testing.T) {
+	tests := map[string]string{
+		" High ":     "high",
+		"x-high":     "xhigh",
+		"max":        "xhigh",
+		"Extra_High": "xhigh",
+		"off":        "none",
+		"minimal":    "minimal",
+	}
+	for raw, want := range tests {
+		got, ok := NormalizeReasoningEffort(raw)
+		require.True(t, ok, raw)
+		assert.Equal(t, want, got, raw)
+	}
+	_, ok := NormalizeReasoningEffort("extreme")
+	assert.False(t, ok)
+}
+
+func TestApplyReasoningEffort_Anthropic(t *testing.T) {
+	body := []byte(`{"model":"claude-sonnet-4-5","max_tokens":8000,"messages":[]}`)
+
+	out := ApplyReasoningEffort(body, ReasoningFormatAnthropic, "claude-sonnet-4-5", "xhigh", true)
+	assert.Equal(t, "max", gjson.GetBytes(out, "output_config.effort").String())
+	assert.Equal(t, "enabled", gjson.GetBytes(out, "thinking.type").String())
+	// budget_tokens 必须小于 max_tokens
+	assert.Equal(t, int64(7999), gjson.GetBytes(out, "thinking.budget_tokens").Int())
+
+	out = ApplyReasoningEffort(body, ReasoningFormatAnthropic, "claude-sonnet-4-5", "low", true)
+	assert.Equal(t, "low", gjson.GetBytes(out, "output_config.effort").String())
+	assert.False(t, gjson.GetBytes(out, "thinking").Exists())
+
+	withEffort := []byte(`{"model":"claude-opus-4-5","output_config":{"effort":"high"},"thinking":{"type":"enabled","budget_tokens":4096}}`)
+	out = ApplyReasoningEffort(withEffort, ReasoningFormatAnthropic, "claude-opus-4-5", "none", true)
+	assert.False(t, gjson.GetBytes(out, "output_config.effort").Exists())
+	assert.Equal(t, "disabled", gjson.GetBytes(out, "thinking.type").String())
+
+	// 非强制时保留客户端已有配置
+	out = ApplyReasoningEffort(withEffort, ReasoningFormatAnthropic, "claude-opus-4-5", "low", false)
+	assert.Equal(t, string(withEffort), string(out))
+
+	// 不支持扩展思考的模型原样返回
+	legacy := []byte(`{"model":"claude-3-5-haiku-20241022"}`)
+	assert.Equal(t, string(legacy), string(ApplyReasoningEffort(legacy, ReasoningFormatAnthropic, "claude-3-5-haiku-20241022", "high", true)))
+}
+
+func TestApplyReasoningEffort_OpenAI(t *testing.T) {
+	out := ApplyReasoningEffort([]byte(`{"model":"o3-mini"}`), ReasoningFormatChatCompletions, "o3-mini", "xhigh", true)
+	assert.Equal(t, "high", gjson.GetBytes(out, "reasoning_effort").String())
+
+	out = ApplyReasoningEffort([]byte(`{"model":"o4-mini"}`), ReasoningFormatChatCompletions, "o4-mini", "minimal", true)
+	assert.Equal(t, "low", gjson.GetBytes(out, "reasoning_effort").String())
+
+	out = ApplyReasoningEffort([]byte(`{"model":"gpt-5"}`), ReasoningFormatResponses, "gpt-5", "none", true)
+	assert.Equal(t, "minimal", gjson.GetBytes(out, "reasoning.effort").String())
+
+	out = ApplyReasoningEffort([]byte(`{"model":"gpt-5.1","reasoning":{"effort":"high","summary":"auto"}}`), ReasoningFormatResponses, "openai/gpt-5.1", "none", true)
+	assert.Equal(t, "none", gjson.GetBytes(out, "reasoning.effort").String())
+	assert.Equal(t, "auto", gjson.GetBytes(out, "reasoning.summary").String())
+
+	// 非推理模型不注入
+	raw := []byte(`{"model":"gpt-4o"}`)
+	assert.Equal(t, string(raw), string(ApplyReasoningEffort(raw, ReasoningFormatChatCompletions, "gpt-4o", "high", true)))
+}
+
+func TestApplyReasoningEffort_Gemini(t *testing.T) {
+	body := []byte(`{"contents":[],"generationConfig":{"thinkingConfig":{"thinkingBudget":4096}}}`)
+
+	out := ApplyReasoningEffort(body, ReasoningFormatGemini, "gemini-2.5-flash", "none", true)
+	assert.Equal(t, int64(0), gjson.GetBytes(out, "generationConfig.thinkingConfig.thinkingBudget").Int())
+
+	out = ApplyReasoningEffort(body, ReasoningFormatGemini, "gemini-2.5-pro", "none", true)
+	assert.Equal(t, int64(128), gjson.GetBytes(out, "generationConfig.thinkingConfig.thinkingBudget").Int())
+
+	out = ApplyReasoningEffort(body, ReasoningFormatGemini, "gemini-3-pro-preview", "medium", true)
+	assert.Equal(t, "high", gjson.GetBytes(out, "generationConfig.thinkingConfig.thinkingLevel").String())
+	assert.False(t, gjson.GetBytes(out, "generationConfig.thinkingConfig.thinkingBudget").Exists())
+
+	assert.Equal(t, string(body), string(ApplyReasoningEffort(body, ReasoningFormatGemini, "gemini-2.5-flash", "high", false)))
+}
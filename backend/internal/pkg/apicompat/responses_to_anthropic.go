@@ -177,6 +177,10 @@ type ResponsesEventToAnthropicState struct {
 	ResponseID string
 	Model      string
 	Created    int64
+
+	// ToolArgsCoalesce batches input_json_delta events; zero value emits each delta.
+	ToolArgsCoalesce ToolArgsCoalesceConfig
+	toolArgs         toolArgsBuffer
 }
 
 // NewResponsesEventToAnthropicState returns an initialised stream state.
@@ -193,6 +197,19 @@ func ResponsesEventToAnthropicEvents(
 	evt *ResponsesStreamEvent,
 	state *ResponsesEventToAnthropicState,
 ) []AnthropicStreamEvent {
+	if evt.Type == "response.function_call_arguments.delta" {
+		return resToAnthHandleFuncArgsDelta(evt, state)
+	}
+	// Emit buffered argument deltas before anything that may close the block.
+	pending := flushAnthToolArgs(state)
+	events := resToAnthHandleEvent(evt, state)
+	if len(pending) == 0 {
+		return events
+	}
+	return append(pending, events...)
+}
+
+func resToAnthHandleEvent(evt *ResponsesStreamEvent, state *ResponsesEventToAnthropicState) []AnthropicStreamEvent {
 	switch evt.Type {
 	case "response.created":
 		return resToAnthHandleCreated(evt, state)
@@ -202,8 +219,6 @@ func ResponsesEventToAnthropicEvents(
 		return resToAnthHandleTextDelta(evt, state)
 	case "response.output_text.done":
 		return resToAnthHandleBlockDone(state)
-	case "response.function_call_arguments.delta":
-		return resToAnthHandleFuncArgsDelta(evt, state)
 	case "response.function_call_arguments.done":
 		return resToAnthHandleFuncArgsDone(evt, state)
 	case "response.output_item.done":
@@ -226,7 +241,7 @@ func FinalizeResponsesAnthropicStream(state *ResponsesEventToAnthropicState) []A
 		return nil
 	}
 
-	var events []AnthropicStreamEvent
+	events := flushAnthToolArgs(state)
 	events = append(events, closeCurrentBlock(state)...)
 
 	events = append(events,
@@ -394,14 +409,38 @@ func resToAnthHandleFuncArgsDelta(evt *ResponsesStreamEvent, state *ResponsesEve
 		return nil
 	}
 
-	return []AnthropicStreamEvent{{
+	if !state.ToolArgsCoalesce.Enabled() {
+		return []AnthropicStreamEvent{makeAnthInputJSONDelta(blockIdx, evt.Delta)}
+	}
+	var events []AnthropicStreamEvent
+	if state.toolArgs.pending() && state.toolArgs.index != blockIdx {
+		events = flushAnthToolArgs(state)
+	}
+	state.toolArgs.add(blockIdx, evt.Delta)
+	if state.toolArgs.due(state.ToolArgsCoalesce) {
+		events = append(events, flushAnthToolArgs(state)...)
+	}
+	return events
+}
+
+// flushAnthToolArgs emits the coalesced input_json_delta, if any.
+func flushAnthToolArgs(state *ResponsesEventToAnthropicState) []AnthropicStreamEvent {
+	if !state.toolArgs.pending() {
+		return nil
+	}
+	blockIdx, args := state.toolArgs.take()
+	return []AnthropicStreamEvent{makeAnthInputJSONDelta(blockIdx, args)}
+}
+
+func makeAnthInputJSONDelta(blockIdx int, partialJSON string) AnthropicStreamEvent {
+	return AnthropicStreamEvent{
 		Type:  "content_block_delta",
 		Index: &blockIdx,
 		Delta: &AnthropicDelta{
 			Type:        "input_json_delta",
-			PartialJSON: evt.Delta,
+			PartialJSON: partialJSON,
 		},
-	}}
+	}
 }
 
 func resToAnthHandleFuncArgsDone(evt *ResponsesStreamEvent, state *ResponsesEventToAnthropicState) []AnthropicStreamEvent {
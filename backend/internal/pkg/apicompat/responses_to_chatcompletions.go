@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // ---------------------------------------------------------------------------
@@ -133,6 +134,12 @@ type ResponsesEventToChatState struct {
 	OutputIndexToToolIndex map[int]int // Responses output_index → Chat tool_calls index
 	IncludeUsage           bool
 	Usage                  *ChatUsage
+	UsageSent              bool // true after a usage-only chunk has been emitted
+	OutputChars            int  // runes of text, reasoning and tool arguments emitted so far
+
+	// ToolArgsCoalesce batches tool-call argument deltas; zero value emits each delta.
+	ToolArgsCoalesce ToolArgsCoalesceConfig
+	toolArgs         toolArgsBuffer
 }
 
 // NewResponsesEventToChatState returns an initialised stream state.
@@ -147,6 +154,20 @@ func NewResponsesEventToChatState() *ResponsesEventToChatState {
 // ResponsesEventToChatChunks converts a single Responses SSE event into zero
 // or more Chat Completions chunks, updating state as it goes.
 func ResponsesEventToChatChunks(evt *ResponsesStreamEvent, state *ResponsesEventToChatState) []ChatCompletionsChunk {
+	if evt.Type == "response.function_call_arguments.delta" {
+		return resToChatHandleFuncArgsDelta(evt, state)
+	}
+	// Any other event ends the current run of argument deltas; emit what is
+	// buffered first so chunk order matches the upstream stream.
+	pending := flushChatToolArgs(state)
+	chunks := resToChatHandleEvent(evt, state)
+	if len(pending) == 0 {
+		return chunks
+	}
+	return append(pending, chunks...)
+}
+
+func resToChatHandleEvent(evt *ResponsesStreamEvent, state *ResponsesEventToChatState) []ChatCompletionsChunk {
 	switch evt.Type {
 	case "response.created":
 		return resToChatHandleCreated(evt, state)
@@ -154,8 +175,6 @@ func ResponsesEventToChatChunks(evt *ResponsesStreamEvent, state *ResponsesEvent
 		return resToChatHandleTextDelta(evt, state)
 	case "response.output_item.added":
 		return resToChatHandleOutputItemAdded(evt, state)
-	case "response.function_call_arguments.delta":
-		return resToChatHandleFuncArgsDelta(evt, state)
 	case "response.reasoning_summary_text.delta":
 		return resToChatHandleReasoningDelta(evt, state)
 	case "response.reasoning_summary_text.done":
@@ -182,22 +201,27 @@ func FinalizeResponsesChatStream(state *ResponsesEventToChatState) []ChatComplet
 		finishReason = "tool_calls"
 	}
 
-	chunks := []ChatCompletionsChunk{makeChatFinishChunk(state, finishReason)}
+	chunks := append(flushChatToolArgs(state), makeChatFinishChunk(state, finishReason))
 
 	if state.IncludeUsage && state.Usage != nil {
-		chunks = append(chunks, ChatCompletionsChunk{
-			ID:      state.ID,
-			Object:  "chat.completion.chunk",
-			Created: state.Created,
-			Model:   state.Model,
-			Choices: []ChatChunkChoice{},
-			Usage:   state.Usage,
-		})
+		chunks = append(chunks, makeChatUsageChunk(state, state.Usage))
 	}
 
 	return chunks
 }
 
+// FinalizeChatStreamUsage emits a trailing usage-only chunk when the client
+// asked for stream_options.include_usage but the upstream stream never carried
+// usage (e.g. it ended without a completion event). The caller supplies the
+// usage it extracted or estimated; nil skips the chunk. Call it after
+// FinalizeResponsesChatStream and before writing [DONE].
+func FinalizeChatStreamUsage(state *ResponsesEventToChatState, usage *ChatUsage) []ChatCompletionsChunk {
+	if !state.IncludeUsage || state.UsageSent || usage == nil {
+		return nil
+	}
+	return []ChatCompletionsChunk{makeChatUsageChunk(state, usage)}
+}
+
 // ChatChunkToSSE formats a ChatCompletionsChunk as an SSE data line.
 func ChatChunkToSSE(chunk ChatCompletionsChunk) (string, error) {
 	data, err := json.Marshal(chunk)
@@ -233,6 +257,7 @@ func resToChatHandleTextDelta(evt *ResponsesStreamEvent, state *ResponsesEventTo
 		return nil
 	}
 	state.SawText = true
+	state.OutputChars += utf8.RuneCountInString(evt.Delta)
 	content := evt.Delta
 	return []ChatCompletionsChunk{makeChatDeltaChunk(state, ChatDelta{Content: &content})}
 }
@@ -268,21 +293,47 @@ func resToChatHandleFuncArgsDelta(evt *ResponsesStreamEvent, state *ResponsesEve
 	if !ok {
 		return nil
 	}
+	state.OutputChars += utf8.RuneCountInString(evt.Delta)
 
-	return []ChatCompletionsChunk{makeChatDeltaChunk(state, ChatDelta{
+	if !state.ToolArgsCoalesce.Enabled() {
+		return []ChatCompletionsChunk{makeChatToolArgsChunk(state, idx, evt.Delta)}
+	}
+	var chunks []ChatCompletionsChunk
+	if state.toolArgs.pending() && state.toolArgs.index != idx {
+		chunks = flushChatToolArgs(state)
+	}
+	state.toolArgs.add(idx, evt.Delta)
+	if state.toolArgs.due(state.ToolArgsCoalesce) {
+		chunks = append(chunks, flushChatToolArgs(state)...)
+	}
+	return chunks
+}
+
+// flushChatToolArgs emits the coalesced argument deltas, if any.
+func flushChatToolArgs(state *ResponsesEventToChatState) []ChatCompletionsChunk {
+	if !state.toolArgs.pending() {
+		return nil
+	}
+	idx, args := state.toolArgs.take()
+	return []ChatCompletionsChunk{makeChatToolArgsChunk(state, idx, args)}
+}
+
+func makeChatToolArgsChunk(state *ResponsesEventToChatState, idx int, args string) ChatCompletionsChunk {
+	return makeChatDeltaChunk(state, ChatDelta{
 		ToolCalls: []ChatToolCall{{
 			Index: &idx,
 			Function: ChatFunctionCall{
-				Arguments: evt.Delta,
+				Arguments: args,
 			},
 		}},
-	})}
+	})
 }
 
 func resToChatHandleReasoningDelta(evt *ResponsesStreamEvent, state *ResponsesEventToChatState) []ChatCompletionsChunk {
 	if evt.Delta == "" {
 		return nil
 	}
+	state.OutputChars += utf8.RuneCountInString(evt.Delta)
 	reasoning := evt.Delta
 	return []ChatCompletionsChunk{makeChatDeltaChunk(state, ChatDelta{ReasoningContent: &reasoning})}
 }
@@ -292,8 +343,9 @@ func resToChatHandleCompleted(evt *ResponsesStreamEvent, state *ResponsesEventTo
 	finishReason := "stop"
 
 	if evt.Response != nil {
-		if evt.Response.Usage != nil {
-			u := evt.Response.Usage
+		// An all-zero usage block means the upstream never reported usage;
+		// leave it unset so FinalizeChatStreamUsage can supply a fallback.
+		if u := evt.Response.Usage; u != nil && (u.InputTokens > 0 || u.OutputTokens > 0) {
 			usage := &ChatUsage{
 				PromptTokens:     u.InputTokens,
 				CompletionTokens: u.OutputTokens,
@@ -325,14 +377,7 @@ func resToChatHandleCompleted(evt *ResponsesStreamEvent, state *ResponsesEventTo
 	chunks = append(chunks, makeChatFinishChunk(state, finishReason))
 
 	if state.IncludeUsage && state.Usage != nil {
-		chunks = append(chunks, ChatCompletionsChunk{
-			ID:      state.ID,
-			Object:  "chat.completion.chunk",
-			Created: state.Created,
-			Model:   state.Model,
-			Choices: []ChatChunkChoice{},
-			Usage:   state.Usage,
-		})
+		chunks = append(chunks, makeChatUsageChunk(state, state.Usage))
 	}
 
 	return chunks
@@ -352,6 +397,20 @@ func makeChatDeltaChunk(state *ResponsesEventToChatState, delta ChatDelta) ChatC
 	}
 }
 
+// makeChatUsageChunk builds a usage-only chunk (empty choices) and marks the
+// usage as sent so it is emitted at most once per stream.
+func makeChatUsageChunk(state *ResponsesEventToChatState, usage *ChatUsage) ChatCompletionsChunk {
+	state.UsageSent = true
+	return ChatCompletionsChunk{
+		ID:      state.ID,
+		Object:  "chat.completion.chunk",
+		Created: state.Created,
+		Model:   state.Model,
+		Choices: []ChatChunkChoice{},
+		Usage:   usage,
+	}
+}
+
 func makeChatFinishChunk(state *ResponsesEventToChatState, finishReason string) ChatCompletionsChunk {
 	empty := ""
 	return ChatCompletionsChunk{
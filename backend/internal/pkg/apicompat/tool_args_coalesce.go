@@ -0,0 +1,61 @@
+package apicompat
+
+import (
+	"strings"
+	"time"
+)
+
+// ToolArgsCoalesceConfig batches streamed tool-call argument deltas before
+// they are emitted by the stream converters. Some clients (e.g. LangChain)
+// struggle with very fine-grained argument deltas. The zero value disables
+// coalescing so every upstream delta is emitted as-is.
+type ToolArgsCoalesceConfig struct {
+	// Window flushes buffered arguments once the oldest buffered delta is at
+	// least this old (checked as new events arrive). 0 disables time-based flushing.
+	Window time.Duration
+	// MaxBytes flushes buffered arguments once at least this many bytes are
+	// buffered. 0 disables size-based flushing.
+	MaxBytes int
+}
+
+// Enabled reports whether argument deltas should be buffered at all.
+func (c ToolArgsCoalesceConfig) Enabled() bool {
+	return c.Window > 0 || c.MaxBytes > 0
+}
+
+// toolArgsNow is overridden in tests.
+var toolArgsNow = time.Now
+
+// toolArgsBuffer holds argument deltas for a single tool call (identified by
+// its output index in the target protocol) until they are flushed.
+type toolArgsBuffer struct {
+	index int
+	args  strings.Builder
+	since time.Time
+}
+
+func (b *toolArgsBuffer) pending() bool {
+	return b.args.Len() > 0
+}
+
+func (b *toolArgsBuffer) add(index int, delta string) {
+	if !b.pending() {
+		b.index = index
+		b.since = toolArgsNow()
+	}
+	b.args.WriteString(delta)
+}
+
+func (b *toolArgsBuffer) due(cfg ToolArgsCoalesceConfig) bool {
+	if cfg.MaxBytes > 0 && b.args.Len() >= cfg.MaxBytes {
+		return true
+	}
+	return cfg.Window > 0 && toolArgsNow().Sub(b.since) >= cfg.Window
+}
+
+// take returns the buffered arguments and resets the buffer.
+func (b *toolArgsBuffer) take() (int, string) {
+	args := b.args.String()
+	b.args.Reset()
+	return b.index, args
+}
@@ -0,0 +1,120 @@
+package apicompat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setToolArgsNow(t *testing.T, now *time.Time) {
+	t.Helper()
+	prev := toolArgsNow
+	toolArgsNow = func() time.Time { return *now }
+	t.Cleanup(func() { toolArgsNow = prev })
+}
+
+func chatArgsDelta(outputIndex int, delta string) *ResponsesStreamEvent {
+	return &ResponsesStreamEvent{Type: "response.function_call_arguments.delta", OutputIndex: outputIndex, Delta: delta}
+}
+
+func newCoalescingChatState(cfg ToolArgsCoalesceConfig) *ResponsesEventToChatState {
+	state := NewResponsesEventToChatState()
+	state.SentRole = true
+	state.ToolArgsCoalesce = cfg
+	ResponsesEventToChatChunks(&ResponsesStreamEvent{
+		Type:        "response.output_item.added",
+		OutputIndex: 0,
+		Item:        &ResponsesOutput{Type: "function_call", CallID: "call_1", Name: "get_weather"},
+	}, state)
+	return state
+}
+
+func TestResponsesEventToChatChunks_ToolArgsCoalesceBytes(t *testing.T) {
+	state := newCoalescingChatState(ToolArgsCoalesceConfig{MaxBytes: 10})
+
+	assert.Empty(t, ResponsesEventToChatChunks(chatArgsDelta(0, `{"ci`), state))
+	assert.Empty(t, ResponsesEventToChatChunks(chatArgsDelta(0, `ty":`), state))
+
+	chunks := ResponsesEventToChatChunks(chatArgsDelta(0, `"Tokyo"`), state)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, `{"city":"Tokyo"`, chunks[0].Choices[0].Delta.ToolCalls[0].Function.Arguments)
+
+	// The tail is flushed before the finish chunk.
+	assert.Empty(t, ResponsesEventToChatChunks(chatArgsDelta(0, `}`), state))
+	chunks = FinalizeResponsesChatStream(state)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, `}`, chunks[0].Choices[0].Delta.ToolCalls[0].Function.Arguments)
+	require.NotNil(t, chunks[1].Choices[0].FinishReason)
+	assert.Equal(t, "tool_calls", *chunks[1].Choices[0].FinishReason)
+}
+
+func TestResponsesEventToChatChunks_ToolArgsCoalesceWindow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	setToolArgsNow(t, &now)
+	state := newCoalescingChatState(ToolArgsCoalesceConfig{Window: 50 * time.Millisecond})
+
+	assert.Empty(t, ResponsesEventToChatChunks(chatArgsDelta(0, `{"a"`), state))
+	now = now.Add(20 * time.Millisecond)
+	assert.Empty(t, ResponsesEventToChatChunks(chatArgsDelta(0, `:1`), state))
+	now = now.Add(30 * time.Millisecond)
+	chunks := ResponsesEventToChatChunks(chatArgsDelta(0, `}`), state)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, `{"a":1}`, chunks[0].Choices[0].Delta.ToolCalls[0].Function.Arguments)
+}
+
+func TestResponsesEventToChatChunks_ToolArgsCoalesceFlushesOnOtherEvents(t *testing.T) {
+	state := newCoalescingChatState(ToolArgsCoalesceConfig{MaxBytes: 1024})
+
+	assert.Empty(t, ResponsesEventToChatChunks(chatArgsDelta(0, `{"city":"Tokyo"}`), state))
+
+	// A second tool call starts: buffered arguments of the first one come out first.
+	chunks := ResponsesEventToChatChunks(&ResponsesStreamEvent{
+		Type:        "response.output_item.added",
+		OutputIndex: 1,
+		Item:        &ResponsesOutput{Type: "function_call", CallID: "call_2", Name: "get_time"},
+	}, state)
+	require.Len(t, chunks, 2)
+	first := chunks[0].Choices[0].Delta.ToolCalls[0]
+	assert.Equal(t, 0, *first.Index)
+	assert.Equal(t, `{"city":"Tokyo"}`, first.Function.Arguments)
+	assert.Equal(t, "call_2", chunks[1].Choices[0].Delta.ToolCalls[0].ID)
+
+	// Interleaved deltas for different calls flush the previous call's buffer.
+	assert.Empty(t, ResponsesEventToChatChunks(chatArgsDelta(1, `{"tz"`), state))
+	chunks = ResponsesEventToChatChunks(chatArgsDelta(0, `x`), state)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, 1, *chunks[0].Choices[0].Delta.ToolCalls[0].Index)
+	assert.Equal(t, `{"tz"`, chunks[0].Choices[0].Delta.ToolCalls[0].Function.Arguments)
+}
+
+func TestResponsesEventToChatChunks_ToolArgsCoalesceDisabledByDefault(t *testing.T) {
+	state := newCoalescingChatState(ToolArgsCoalesceConfig{})
+	chunks := ResponsesEventToChatChunks(chatArgsDelta(0, `{`), state)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, `{`, chunks[0].Choices[0].Delta.ToolCalls[0].Function.Arguments)
+}
+
+func TestResponsesEventToAnthropicEvents_ToolArgsCoalesce(t *testing.T) {
+	state := NewResponsesEventToAnthropicState()
+	state.ToolArgsCoalesce = ToolArgsCoalesceConfig{MaxBytes: 1024}
+	ResponsesEventToAnthropicEvents(&ResponsesStreamEvent{
+		Type:     "response.created",
+		Response: &ResponsesResponse{ID: "resp_1", Model: "gpt-5.2"},
+	}, state)
+	ResponsesEventToAnthropicEvents(&ResponsesStreamEvent{
+		Type:        "response.output_item.added",
+		OutputIndex: 0,
+		Item:        &ResponsesOutput{Type: "function_call", CallID: "call_1", Name: "get_weather"},
+	}, state)
+
+	assert.Empty(t, ResponsesEventToAnthropicEvents(chatArgsDelta(0, `{"city":`), state))
+	assert.Empty(t, ResponsesEventToAnthropicEvents(chatArgsDelta(0, `"Tokyo"}`), state))
+
+	events := ResponsesEventToAnthropicEvents(&ResponsesStreamEvent{Type: "response.function_call_arguments.done"}, state)
+	require.Len(t, events, 2)
+	assert.Equal(t, "input_json_delta", events[0].Delta.Type)
+	assert.Equal(t, `{"city":"Tokyo"}`, events[0].Delta.PartialJSON)
+	assert.Equal(t, "content_block_stop", events[1].Type)
+}
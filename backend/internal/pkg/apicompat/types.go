@@ -489,6 +489,77 @@ type ChatDelta struct {
 	ToolCalls        []ChatToolCall `json:"tool_calls,omitempty"`
 }
 
+// ---------------------------------------------------------------------------
+// Ollama native API types
+// ---------------------------------------------------------------------------
+
+// OllamaChatRequest is the request body for POST /api/chat.
+type OllamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []OllamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Tools    []ChatTool      `json:"tools,omitempty"`
+	Format   json.RawMessage `json:"format,omitempty"`
+	Options  *OllamaOptions  `json:"options,omitempty"`
+}
+
+// OllamaOptions holds the sampling options supported by Ollama.
+type OllamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+// OllamaMessage is a single message in an Ollama chat conversation.
+// Images carry raw base64 data without the data URI prefix.
+type OllamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+// OllamaToolCall is a tool call made by the model. Unlike Chat Completions,
+// arguments are a JSON object rather than a string.
+type OllamaToolCall struct {
+	Function OllamaFunctionCall `json:"function"`
+}
+
+// OllamaFunctionCall contains the function name and arguments.
+type OllamaFunctionCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// OllamaChatResponse is the non-streaming response from POST /api/chat, and
+// also the shape of each NDJSON line when streaming. Token counts are only
+// present on the final (done=true) object.
+type OllamaChatResponse struct {
+	Model           string        `json:"model"`
+	CreatedAt       string        `json:"created_at"`
+	Message         OllamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason,omitempty"`
+	PromptEvalCount int           `json:"prompt_eval_count,omitempty"`
+	EvalCount       int           `json:"eval_count,omitempty"`
+}
+
+// OllamaTagsResponse is the response from GET /api/tags.
+type OllamaTagsResponse struct {
+	Models []OllamaModel `json:"models"`
+}
+
+// OllamaModel is a locally available model listed by /api/tags.
+type OllamaModel struct {
+	Name       string `json:"name"`
+	Model      string `json:"model"`
+	ModifiedAt string `json:"modified_at"`
+	Size       int64  `json:"size"`
+}
+
 // ---------------------------------------------------------------------------
 // Shared constants
 // ---------------------------------------------------------------------------
@@ -0,0 +1,573 @@
+package apicompat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidationError describes a single invalid field in a request body.
+// Path uses JSON-path-like notation, e.g. "messages[2].content[0].type".
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + " " + e.Message
+}
+
+// ValidateOptions controls how strict request validation is.
+type ValidateOptions struct {
+	// AllowUnknownFields passes through top-level fields, content block types
+	// and input item types that are not modeled here instead of rejecting them.
+	AllowUnknownFields bool
+}
+
+// Documented top-level fields that are not modeled by the request structs
+// but are forwarded untouched.
+var (
+	anthropicPassthroughFields = []string{
+		"top_k", "service_tier", "container", "mcp_servers", "context_management", "speed", "inference_geo",
+	}
+	chatPassthroughFields = []string{
+		"n", "presence_penalty", "frequency_penalty", "logit_bias", "logprobs", "top_logprobs", "user", "seed",
+		"response_format", "parallel_tool_calls", "modalities", "audio", "prediction", "store", "metadata",
+		"web_search_options", "verbosity", "prompt_cache_key", "safety_identifier",
+	}
+	responsesPassthroughFields = []string{
+		"previous_response_id", "metadata", "parallel_tool_calls", "text", "truncation", "user", "background",
+		"prompt", "prompt_cache_key", "safety_identifier", "max_tool_calls", "top_logprobs", "conversation",
+		"stream_options", "context_management",
+	}
+)
+
+var (
+	anthropicRequestFields = jsonFieldNames(AnthropicRequest{}, anthropicPassthroughFields...)
+	chatRequestFields      = jsonFieldNames(ChatCompletionsRequest{}, chatPassthroughFields...)
+	responsesRequestFields = jsonFieldNames(ResponsesRequest{}, responsesPassthroughFields...)
+)
+
+var anthropicBlockTypes = map[string]struct{}{
+	"text": {}, "image": {}, "document": {}, "search_result": {}, "tool_use": {}, "tool_result": {},
+	"thinking": {}, "redacted_thinking": {}, "server_tool_use": {}, "web_search_tool_result": {},
+	"web_fetch_tool_result": {}, "code_execution_tool_result": {}, "bash_code_execution_tool_result": {},
+	"text_editor_code_execution_tool_result": {}, "mcp_tool_use": {}, "mcp_tool_result": {},
+	"container_upload": {}, "tool_search_tool_result": {}, "tool_reference": {},
+}
+
+var chatContentPartTypes = map[string]struct{}{
+	"text": {}, "image_url": {}, "input_audio": {}, "file": {}, "refusal": {},
+}
+
+var responsesItemTypes = map[string]struct{}{
+	"message": {}, "function_call": {}, "function_call_output": {}, "reasoning": {}, "item_reference": {},
+	"web_search_call": {}, "file_search_call": {}, "computer_call": {}, "computer_call_output": {},
+	"image_generation_call": {}, "code_interpreter_call": {}, "local_shell_call": {}, "local_shell_call_output": {},
+	"mcp_call": {}, "mcp_list_tools": {}, "mcp_approval_request": {}, "mcp_approval_response": {},
+	"custom_tool_call": {}, "custom_tool_call_output": {}, "compaction": {},
+}
+
+var responsesContentPartTypes = map[string]struct{}{
+	"input_text": {}, "output_text": {}, "input_image": {}, "input_file": {}, "input_audio": {}, "refusal": {},
+	"summary_text": {},
+}
+
+// ---------------------------------------------------------------------------
+// Anthropic Messages
+// ---------------------------------------------------------------------------
+
+// anthropicRequestShape decodes the scalar fields through AnthropicRequest
+// while keeping array elements raw so that errors can carry their index.
+type anthropicRequestShape struct {
+	AnthropicRequest
+	Messages []json.RawMessage `json:"messages"`
+	Tools    []json.RawMessage `json:"tools,omitempty"`
+}
+
+// ValidateAnthropicRequest validates a POST /v1/messages request body.
+func ValidateAnthropicRequest(body []byte, opts ValidateOptions) error {
+	var req anthropicRequestShape
+	if err := decodeRequestRoot(body, &req, anthropicRequestFields, opts); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Model) == "" {
+		return invalidField("model", "is required")
+	}
+	if req.MaxTokens <= 0 {
+		return invalidField("max_tokens", "must be a positive integer")
+	}
+	if req.Temperature != nil && (*req.Temperature < 0 || *req.Temperature > 1) {
+		return invalidField("temperature", "must be between 0 and 1")
+	}
+	if req.Thinking != nil {
+		switch req.Thinking.Type {
+		case "enabled":
+			if req.Thinking.BudgetTokens <= 0 {
+				return invalidField("thinking.budget_tokens", "must be a positive integer when thinking is enabled")
+			}
+		case "adaptive", "disabled":
+		default:
+			return invalidField("thinking.type", "must be one of: enabled, adaptive, disabled")
+		}
+	}
+	if len(req.System) > 0 && jsonKind(req.System) != "null" {
+		if err := validateAnthropicContent(req.System, "system", opts); err != nil {
+			return err
+		}
+	}
+	if len(req.Messages) == 0 {
+		return invalidField("messages", "must contain at least one message")
+	}
+	for i, raw := range req.Messages {
+		path := indexPath("messages", i)
+		var msg AnthropicMessage
+		if err := decodeTyped(raw, &msg, path); err != nil {
+			return err
+		}
+		if msg.Role != "user" && msg.Role != "assistant" {
+			return invalidField(path+".role", "must be one of: user, assistant")
+		}
+		if err := validateAnthropicContent(msg.Content, path+".content", opts); err != nil {
+			return err
+		}
+	}
+	for i, raw := range req.Tools {
+		path := indexPath("tools", i)
+		var tool AnthropicTool
+		if err := decodeTyped(raw, &tool, path); err != nil {
+			return err
+		}
+		if tool.Name == "" {
+			return invalidField(path+".name", "is required")
+		}
+		if (tool.Type == "" || tool.Type == "custom") && len(tool.InputSchema) == 0 {
+			return invalidField(path+".input_schema", "is required")
+		}
+	}
+	return nil
+}
+
+// validateAnthropicContent validates message content, system prompts and
+// tool_result content: a string or an array of content blocks.
+func validateAnthropicContent(raw json.RawMessage, path string, opts ValidateOptions) error {
+	switch jsonKind(raw) {
+	case "string":
+		return nil
+	case "array":
+	case "":
+		return invalidField(path, "is required")
+	default:
+		return invalidField(path, "must be a string or an array of content blocks")
+	}
+	var blocks []json.RawMessage
+	if err := decodeTyped(raw, &blocks, path); err != nil {
+		return err
+	}
+	for i, rawBlock := range blocks {
+		blockPath := indexPath(path, i)
+		var block AnthropicContentBlock
+		if err := decodeTyped(rawBlock, &block, blockPath); err != nil {
+			return err
+		}
+		if block.Type == "" {
+			return invalidField(blockPath+".type", "is required")
+		}
+		if _, ok := anthropicBlockTypes[block.Type]; !ok && !opts.AllowUnknownFields {
+			return invalidField(blockPath+".type", fmt.Sprintf("invalid: unknown content block type %q", block.Type))
+		}
+		switch block.Type {
+		case "text":
+			if block.Text == "" {
+				return invalidField(blockPath+".text", "must be a non-empty string")
+			}
+		case "image", "document":
+			if block.Source == nil {
+				return invalidField(blockPath+".source", "is required")
+			}
+		case "tool_use":
+			if block.ID == "" {
+				return invalidField(blockPath+".id", "is required")
+			}
+			if block.Name == "" {
+				return invalidField(blockPath+".name", "is required")
+			}
+		case "tool_result":
+			if block.ToolUseID == "" {
+				return invalidField(blockPath+".tool_use_id", "is required")
+			}
+			if len(block.Content) > 0 && jsonKind(block.Content) != "null" {
+				if err := validateAnthropicContent(block.Content, blockPath+".content", opts); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// OpenAI Chat Completions
+// ---------------------------------------------------------------------------
+
+type chatCompletionsRequestShape struct {
+	ChatCompletionsRequest
+	Messages []json.RawMessage `json:"messages"`
+	Tools    []json.RawMessage `json:"tools,omitempty"`
+}
+
+// ValidateChatCompletionsRequest validates a POST /v1/chat/completions request body.
+func ValidateChatCompletionsRequest(body []byte, opts ValidateOptions) error {
+	var req chatCompletionsRequestShape
+	if err := decodeRequestRoot(body, &req, chatRequestFields, opts); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Model) == "" {
+		return invalidField("model", "is required")
+	}
+	if req.Temperature != nil && (*req.Temperature < 0 || *req.Temperature > 2) {
+		return invalidField("temperature", "must be between 0 and 2")
+	}
+	if len(req.Messages) == 0 {
+		return invalidField("messages", "must contain at least one message")
+	}
+	for i, raw := range req.Messages {
+		if err := validateChatMessage(raw, indexPath("messages", i), opts); err != nil {
+			return err
+		}
+	}
+	for i, raw := range req.Tools {
+		path := indexPath("tools", i)
+		var tool ChatTool
+		if err := decodeTyped(raw, &tool, path); err != nil {
+			return err
+		}
+		if tool.Type == "" {
+			return invalidField(path+".type", "is required")
+		}
+		if tool.Type == "function" && (tool.Function == nil || tool.Function.Name == "") {
+			return invalidField(path+".function.name", "is required")
+		}
+	}
+	return nil
+}
+
+func validateChatMessage(raw json.RawMessage, path string, opts ValidateOptions) error {
+	var msg ChatMessage
+	if err := decodeTyped(raw, &msg, path); err != nil {
+		return err
+	}
+	switch msg.Role {
+	case "system", "developer", "user", "assistant", "tool", "function":
+	default:
+		return invalidField(path+".role", "must be one of: system, developer, user, assistant, tool, function")
+	}
+	if msg.Role == "tool" && msg.ToolCallID == "" {
+		return invalidField(path+".tool_call_id", "is required for tool messages")
+	}
+	for k, call := range msg.ToolCalls {
+		callPath := fmt.Sprintf("%s.tool_calls[%d]", path, k)
+		if call.ID == "" {
+			return invalidField(callPath+".id", "is required")
+		}
+		if call.Function.Name == "" {
+			return invalidField(callPath+".function.name", "is required")
+		}
+	}
+
+	contentPath := path + ".content"
+	switch jsonKind(msg.Content) {
+	case "string":
+		return nil
+	case "", "null":
+		// assistant messages that only carry tool calls may omit content
+		if msg.Role == "assistant" {
+			return nil
+		}
+		return invalidField(contentPath, "is required")
+	case "array":
+	default:
+		return invalidField(contentPath, "must be a string or an array of content parts")
+	}
+	var parts []json.RawMessage
+	if err := decodeTyped(msg.Content, &parts, contentPath); err != nil {
+		return err
+	}
+	for i, rawPart := range parts {
+		partPath := indexPath(contentPath, i)
+		var part ChatContentPart
+		if err := decodeTyped(rawPart, &part, partPath); err != nil {
+			return err
+		}
+		if part.Type == "" {
+			return invalidField(partPath+".type", "is required")
+		}
+		if _, ok := chatContentPartTypes[part.Type]; !ok && !opts.AllowUnknownFields {
+			return invalidField(partPath+".type", fmt.Sprintf("invalid: unknown content part type %q", part.Type))
+		}
+		if part.Type == "image_url" && (part.ImageURL == nil || part.ImageURL.URL == "") {
+			return invalidField(partPath+".image_url.url", "is required")
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// OpenAI Responses
+// ---------------------------------------------------------------------------
+
+type responsesRequestShape struct {
+	ResponsesRequest
+	Tools []json.RawMessage `json:"tools,omitempty"`
+}
+
+// responsesItemHeader carries the fields shared by all Responses input items.
+// function_call_output.output may be a string or an array, so it stays raw.
+type responsesItemHeader struct {
+	Type   string          `json:"type"`
+	Role   string          `json:"role"`
+	CallID string          `json:"call_id"`
+	Output json.RawMessage `json:"output"`
+}
+
+// ValidateResponsesRequest validates a POST /v1/responses request body.
+func ValidateResponsesRequest(body []byte, opts ValidateOptions) error {
+	var req responsesRequestShape
+	if err := decodeRequestRoot(body, &req, responsesRequestFields, opts); err != nil {
+		return err
+	}
+	if strings.TrimSpace(req.Model) == "" {
+		return invalidField("model", "is required")
+	}
+	if req.Temperature != nil && (*req.Temperature < 0 || *req.Temperature > 2) {
+		return invalidField("temperature", "must be between 0 and 2")
+	}
+	switch jsonKind(req.Input) {
+	case "", "null", "string":
+	case "array":
+		var items []json.RawMessage
+		if err := decodeTyped(req.Input, &items, "input"); err != nil {
+			return err
+		}
+		for i, raw := range items {
+			if err := validateResponsesInputItem(raw, indexPath("input", i), opts); err != nil {
+				return err
+			}
+		}
+	default:
+		return invalidField("input", "must be a string or an array of input items")
+	}
+	for i, raw := range req.Tools {
+		path := indexPath("tools", i)
+		var tool ResponsesTool
+		if err := decodeTyped(raw, &tool, path); err != nil {
+			return err
+		}
+		if tool.Type == "" {
+			return invalidField(path+".type", "is required")
+		}
+		if tool.Type == "function" && tool.Name == "" {
+			return invalidField(path+".name", "is required")
+		}
+	}
+	return nil
+}
+
+func validateResponsesInputItem(raw json.RawMessage, path string, opts ValidateOptions) error {
+	var header responsesItemHeader
+	if err := decodeTyped(raw, &header, path); err != nil {
+		return err
+	}
+	itemType := header.Type
+	if itemType == "" {
+		itemType = "message"
+	}
+	if _, ok := responsesItemTypes[itemType]; !ok && !opts.AllowUnknownFields {
+		return invalidField(path+".type", fmt.Sprintf("invalid: unknown input item type %q", itemType))
+	}
+	switch itemType {
+	case "message":
+		var item ResponsesInputItem
+		if err := decodeTyped(raw, &item, path); err != nil {
+			return err
+		}
+		switch item.Role {
+		case "user", "assistant", "system", "developer":
+		default:
+			return invalidField(path+".role", "must be one of: user, assistant, system, developer")
+		}
+		return validateResponsesMessageContent(item.Content, path+".content", opts)
+	case "function_call":
+		var item ResponsesInputItem
+		if err := decodeTyped(raw, &item, path); err != nil {
+			return err
+		}
+		if item.CallID == "" {
+			return invalidField(path+".call_id", "is required")
+		}
+		if item.Name == "" {
+			return invalidField(path+".name", "is required")
+		}
+	case "function_call_output":
+		if header.CallID == "" {
+			return invalidField(path+".call_id", "is required")
+		}
+		if kind := jsonKind(header.Output); kind != "string" && kind != "array" {
+			return invalidField(path+".output", "must be a string or an array of content parts")
+		}
+	}
+	return nil
+}
+
+func validateResponsesMessageContent(raw json.RawMessage, path string, opts ValidateOptions) error {
+	switch jsonKind(raw) {
+	case "string":
+		return nil
+	case "array":
+	case "":
+		return invalidField(path, "is required")
+	default:
+		return invalidField(path, "must be a string or an array of content parts")
+	}
+	var parts []json.RawMessage
+	if err := decodeTyped(raw, &parts, path); err != nil {
+		return err
+	}
+	for i, rawPart := range parts {
+		partPath := indexPath(path, i)
+		var part ResponsesContentPart
+		if err := decodeTyped(rawPart, &part, partPath); err != nil {
+			return err
+		}
+		if part.Type == "" {
+			return invalidField(partPath+".type", "is required")
+		}
+		if _, ok := responsesContentPartTypes[part.Type]; !ok && !opts.AllowUnknownFields {
+			return invalidField(partPath+".type", fmt.Sprintf("invalid: unknown content part type %q", part.Type))
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+func invalidField(path, message string) *ValidationError {
+	return &ValidationError{Path: path, Message: message}
+}
+
+func indexPath(base string, i int) string {
+	return fmt.Sprintf("%s[%d]", base, i)
+}
+
+// decodeRequestRoot decodes the request root into dst and, unless unknown
+// fields are allowed, rejects top-level fields outside the known set.
+func decodeRequestRoot(body []byte, dst any, known map[string]struct{}, opts ValidateOptions) error {
+	if jsonKind(body) != "object" {
+		return invalidField("", "request body must be a JSON object")
+	}
+	if err := decodeTyped(body, dst, ""); err != nil {
+		return err
+	}
+	if opts.AllowUnknownFields {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return invalidField("", "request body must be a JSON object")
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := known[name]; !ok {
+			return invalidField(name, "is not a supported field")
+		}
+	}
+	return nil
+}
+
+// decodeTyped unmarshals raw into dst and maps type mismatches to a field path.
+func decodeTyped(raw json.RawMessage, dst any, path string) error {
+	err := json.Unmarshal(raw, dst)
+	if err == nil {
+		return nil
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		fieldPath := path
+		if typeErr.Field != "" {
+			if fieldPath != "" {
+				fieldPath += "."
+			}
+			fieldPath += typeErr.Field
+		}
+		return invalidField(fieldPath, fmt.Sprintf("has invalid type %s", typeErr.Value))
+	}
+	if path == "" {
+		return invalidField("", "request body is not valid JSON")
+	}
+	return invalidField(path, "is not valid JSON")
+}
+
+// jsonKind returns "object", "array", "string", "number", "bool", "null",
+// or "" for empty input.
+func jsonKind(raw []byte) string {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return ""
+	}
+	switch trimmed[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+// jsonFieldNames collects the JSON names of a struct's fields (including
+// embedded structs) plus extra names.
+func jsonFieldNames(v any, extra ...string) map[string]struct{} {
+	names := make(map[string]struct{})
+	collectJSONFieldNames(reflect.TypeOf(v), names)
+	for _, name := range extra {
+		names[name] = struct{}{}
+	}
+	return names
+}
+
+func collectJSONFieldNames(t reflect.Type, names map[string]struct{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			collectJSONFieldNames(field.Type, names)
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = struct{}{}
+	}
+}
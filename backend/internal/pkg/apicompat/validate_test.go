@@ -0,0 +1,111 @@
+package apicompat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validationMessage(t *testing.T, err error) string {
+	t.Helper()
+	require.Error(t, err)
+	var vErr *ValidationError
+	require.ErrorAs(t, err, &vErr)
+	return vErr.Error()
+}
+
+// ---------------------------------------------------------------------------
+// ValidateAnthropicRequest tests
+// ---------------------------------------------------------------------------
+
+func TestValidateAnthropicRequest_Valid(t *testing.T) {
+	body := []byte(`{"model":"claude-sonnet-4-5","max_tokens":1024,"system":[{"type":"text","text":"sys"}],
+		"messages":[
+			{"role":"user","content":"hi"},
+			{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"ls","input":{}}]},
+			{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"ok"}]}]}
+		],
+		"tools":[{"name":"ls","input_schema":{"type":"object"}},{"type":"web_search_20250305","name":"web_search"}],
+		"top_k":5}`)
+	assert.NoError(t, ValidateAnthropicRequest(body, ValidateOptions{}))
+}
+
+func TestValidateAnthropicRequest_FieldErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"not an object", `[]`, "request body must be a JSON object"},
+		{"missing model", `{"max_tokens":1,"messages":[{"role":"user","content":"x"}]}`, "model is required"},
+		{"wrong type", `{"model":"m","max_tokens":"1","messages":[]}`, "max_tokens has invalid type string"},
+		{"bad role", `{"model":"m","max_tokens":1,"messages":[{"role":"system","content":"x"}]}`, "messages[0].role must be one of: user, assistant"},
+		{"bad block type", `{"model":"m","max_tokens":1,"messages":[{"role":"user","content":"a"},{"role":"assistant","content":"b"},{"role":"user","content":[{"type":"txt","text":"c"}]}]}`, `messages[2].content[0].type invalid: unknown content block type "txt"`},
+		{"nested type error", `{"model":"m","max_tokens":1,"messages":[{"role":"user","content":[{"type":"text","text":5}]}]}`, "messages[0].content[0].text has invalid type number"},
+		{"tool_result without id", `{"model":"m","max_tokens":1,"messages":[{"role":"user","content":[{"type":"tool_result","content":"x"}]}]}`, "messages[0].content[0].tool_use_id is required"},
+		{"tool without schema", `{"model":"m","max_tokens":1,"messages":[{"role":"user","content":"x"}],"tools":[{"name":"f"}]}`, "tools[0].input_schema is required"},
+		{"unknown field", `{"model":"m","max_tokens":1,"messages":[{"role":"user","content":"x"}],"foo":1}`, "foo is not a supported field"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, validationMessage(t, ValidateAnthropicRequest([]byte(tc.body), ValidateOptions{})))
+		})
+	}
+}
+
+func TestValidateAnthropicRequest_AllowUnknownFields(t *testing.T) {
+	body := []byte(`{"model":"m","max_tokens":1,"future_flag":true,"messages":[{"role":"user","content":[{"type":"future_block"}]}]}`)
+	assert.NoError(t, ValidateAnthropicRequest(body, ValidateOptions{AllowUnknownFields: true}))
+	assert.Error(t, ValidateAnthropicRequest(body, ValidateOptions{}))
+}
+
+// ---------------------------------------------------------------------------
+// ValidateChatCompletionsRequest tests
+// ---------------------------------------------------------------------------
+
+func TestValidateChatCompletionsRequest(t *testing.T) {
+	valid := []byte(`{"model":"gpt-5","messages":[
+		{"role":"system","content":"s"},
+		{"role":"user","content":[{"type":"text","text":"hi"},{"type":"image_url","image_url":{"url":"https://x/y.png"}}]},
+		{"role":"assistant","content":null,"tool_calls":[{"id":"c1","type":"function","function":{"name":"f","arguments":"{}"}}]},
+		{"role":"tool","tool_call_id":"c1","content":"done"}
+	],"tools":[{"type":"function","function":{"name":"f"}}],"seed":1}`)
+	assert.NoError(t, ValidateChatCompletionsRequest(valid, ValidateOptions{}))
+
+	err := ValidateChatCompletionsRequest([]byte(`{"model":"m","messages":[{"role":"tool","content":"x"}]}`), ValidateOptions{})
+	assert.Equal(t, "messages[0].tool_call_id is required for tool messages", validationMessage(t, err))
+
+	err = ValidateChatCompletionsRequest([]byte(`{"model":"m","messages":[{"role":"user","content":[{"type":"image_url","image_url":{}}]}]}`), ValidateOptions{})
+	assert.Equal(t, "messages[0].content[0].image_url.url is required", validationMessage(t, err))
+
+	err = ValidateChatCompletionsRequest([]byte(`{"model":"m","messages":[{"role":"user"}]}`), ValidateOptions{})
+	assert.Equal(t, "messages[0].content is required", validationMessage(t, err))
+
+	err = ValidateChatCompletionsRequest([]byte(`{"model":"m","temperature":3,"messages":[{"role":"user","content":"x"}]}`), ValidateOptions{})
+	assert.Equal(t, "temperature must be between 0 and 2", validationMessage(t, err))
+}
+
+// ---------------------------------------------------------------------------
+// ValidateResponsesRequest tests
+// ---------------------------------------------------------------------------
+
+func TestValidateResponsesRequest(t *testing.T) {
+	valid := []byte(`{"model":"gpt-5","input":[
+		{"role":"user","content":[{"type":"input_text","text":"hi"}]},
+		{"type":"reasoning","summary":[]},
+		{"type":"function_call","call_id":"c1","name":"f","arguments":"{}"},
+		{"type":"function_call_output","call_id":"c1","output":[{"type":"input_text","text":"ok"}]}
+	],"previous_response_id":"r1"}`)
+	assert.NoError(t, ValidateResponsesRequest(valid, ValidateOptions{}))
+	assert.NoError(t, ValidateResponsesRequest([]byte(`{"model":"gpt-5","input":"hello"}`), ValidateOptions{}))
+
+	err := ValidateResponsesRequest([]byte(`{"model":"m","input":[{"type":"function_call_output","output":"x"}]}`), ValidateOptions{})
+	assert.Equal(t, "input[0].call_id is required", validationMessage(t, err))
+
+	err = ValidateResponsesRequest([]byte(`{"model":"m","input":[{"role":"user","content":[{"type":"text","text":"x"}]}]}`), ValidateOptions{})
+	assert.Equal(t, `input[0].content[0].type invalid: unknown content part type "text"`, validationMessage(t, err))
+
+	err = ValidateResponsesRequest([]byte(`{"model":"m","input":{"role":"user"}}`), ValidateOptions{})
+	assert.Equal(t, "input must be a string or an array of input items", validationMessage(t, err))
+}
@@ -36,6 +36,12 @@ const (
 	ThinkingEnabled Key = "ctx_thinking_enabled"
 	// Group 认证后的分组信息，由 API Key 认证中间件设置
 	Group Key = "ctx_group"
+	// UserID 认证后 API Key 所属用户 ID（int64），由 API Key 认证中间件设置
+	UserID Key = "ctx_user_id"
+	// AccountTagSelector 认证后 API Key 的账号标签选择器（service.AccountTagSelector），调度时与分组选择器合并
+	AccountTagSelector Key = "ctx_account_tag_selector"
+	// AllowedPlatforms 认证后 API Key 允许使用的上游平台（[]string），调度时过滤候选账号
+	AllowedPlatforms Key = "ctx_allowed_platforms"
 
 	// IsMaxTokensOneHaikuRequest 标识当前请求是否为 max_tokens=1 + haiku 模型的探测请求
 	// 用于 ClaudeCodeOnly 验证绕过（绕过 system prompt 检查，但仍需验证 User-Agent）
@@ -55,4 +61,7 @@ const (
 
 	// ClaudeCodeVersion stores the extracted Claude Code version from User-Agent (e.g. "2.1.22")
 	ClaudeCodeVersion Key = "ctx_claude_code_version"
+
+	// OpsUpstreamTiming 上游网络阶段耗时记录器（*service.OpsUpstreamTimingRecorder），由 Ops 错误日志中间件注入
+	OpsUpstreamTiming Key = "ctx_ops_upstream_timing"
 )
@@ -0,0 +1,196 @@
+package httputil
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// supportedResponseEncodings lists the Content-Encoding values DecompressResponseBody
+// can decode. Accept-Encoding forwarded upstream is restricted to this set so an
+// upstream never answers with a coding the gateway cannot parse.
+var supportedResponseEncodings = map[string]bool{
+	"gzip":    true,
+	"x-gzip":  true,
+	"br":      true,
+	"deflate": true,
+	"zstd":    true,
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// DecompressResponseBody transparently decodes resp.Body according to its
+// Content-Encoding (gzip, br, deflate, zstd, or a comma-separated stack of
+// them). On success Content-Encoding and Content-Length are removed because the
+// body handed to callers is identity-encoded. Unknown or malformed encodings are
+// left untouched so the raw body can still be relayed.
+func DecompressResponseBody(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	encodings := parseContentEncoding(resp.Header.Get("Content-Encoding"))
+	if len(encodings) == 0 {
+		return
+	}
+	for _, enc := range encodings {
+		if !supportedResponseEncodings[enc] {
+			return
+		}
+	}
+
+	body := &decompressedBody{closers: []io.Closer{resp.Body}}
+	var reader io.Reader = resp.Body
+	// Stacked codings are listed in the order applied; decode in reverse.
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoded, closer, err := newDecodingReader(encodings[i], reader)
+		if err != nil {
+			return // leave the body as-is when the stream cannot be decoded
+		}
+		reader = decoded
+		if closer != nil {
+			body.closers = append(body.closers, closer)
+		}
+	}
+	body.reader = reader
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// DecodeSniffedBody decodes a fully-read upstream body that is compressed but
+// carries no Content-Encoding header (seen on some OpenAI-compatible upstreams
+// for error payloads). Only self-identifying formats (gzip, zstd) are sniffed;
+// anything else, including decode failures, returns the input unchanged.
+func DecodeSniffedBody(body []byte) []byte {
+	var enc string
+	switch {
+	case bytes.HasPrefix(body, gzipMagic):
+		enc = "gzip"
+	case bytes.HasPrefix(body, zstdMagic):
+		enc = "zstd"
+	default:
+		return body
+	}
+	reader, closer, err := newDecodingReader(enc, bytes.NewReader(body))
+	if err != nil {
+		return body
+	}
+	if closer != nil {
+		defer func() { _ = closer.Close() }()
+	}
+	decoded, err := io.ReadAll(io.LimitReader(reader, maxDecompressedBodySize))
+	if err != nil {
+		return body
+	}
+	return decoded
+}
+
+// SanitizeAcceptEncoding rewrites any Accept-Encoding header in h (matched
+// case-insensitively, so raw wire-cased keys are covered) to the codings the
+// gateway can decode, preserving the client's order and q-values. The header is
+// dropped entirely when nothing decodable remains, which means identity.
+func SanitizeAcceptEncoding(h http.Header) {
+	for key, values := range h {
+		if !strings.EqualFold(key, "Accept-Encoding") {
+			continue
+		}
+		var kept []string
+		for _, value := range values {
+			for _, part := range strings.Split(value, ",") {
+				part = strings.TrimSpace(part)
+				coding := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+				if supportedResponseEncodings[coding] || coding == "identity" {
+					kept = append(kept, part)
+				}
+			}
+		}
+		if len(kept) == 0 {
+			delete(h, key)
+			continue
+		}
+		h[key] = []string{strings.Join(kept, ", ")}
+	}
+}
+
+func parseContentEncoding(value string) []string {
+	var encodings []string
+	for _, part := range strings.Split(value, ",") {
+		enc := strings.ToLower(strings.TrimSpace(part))
+		if enc == "" || enc == "identity" {
+			continue
+		}
+		encodings = append(encodings, enc)
+	}
+	return encodings
+}
+
+func newDecodingReader(encoding string, r io.Reader) (io.Reader, io.Closer, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gr, gr, nil
+	case "br":
+		return brotli.NewReader(r), nil, nil
+	case "deflate":
+		// HTTP deflate is zlib-wrapped per spec, but some servers send raw deflate.
+		br := bufio.NewReader(r)
+		if header, err := br.Peek(2); err == nil && isZlibHeader(header) {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, nil, err
+			}
+			return zr, zr, nil
+		}
+		fr := flate.NewReader(br)
+		return fr, fr, nil
+	case "zstd":
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		rc := dec.IOReadCloser()
+		return rc, rc, nil
+	default:
+		return nil, nil, errors.New("unsupported Content-Encoding")
+	}
+}
+
+func isZlibHeader(b []byte) bool {
+	return len(b) >= 2 && b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// decompressedBody combines the decoding reader with the closers of every
+// layer; closers[0] is the original response body.
+type decompressedBody struct {
+	reader  io.Reader
+	closers []io.Closer
+}
+
+func (d *decompressedBody) Read(p []byte) (int, error) {
+	return d.reader.Read(p)
+}
+
+func (d *decompressedBody) Close() error {
+	for i := len(d.closers) - 1; i > 0; i-- {
+		_ = d.closers[i].Close()
+	}
+	return d.closers[0].Close()
+}
@@ -0,0 +1,138 @@
+package httputil
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func newResponseWithBody(body []byte, encoding string) *http.Response {
+	resp := &http.Response{
+		Header:        http.Header{},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	if encoding != "" {
+		resp.Header.Set("Content-Encoding", encoding)
+	}
+	return resp
+}
+
+func readDecompressed(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	DecompressResponseBody(resp)
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("close body: %v", err)
+	}
+	return string(got)
+}
+
+func gzipBytes(t *testing.T, raw []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(raw); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompressResponseBody_Encodings(t *testing.T) {
+	var brBuf bytes.Buffer
+	bw := brotli.NewWriter(&brBuf)
+	_, _ = bw.Write([]byte(samplePayload))
+	_ = bw.Close()
+
+	var zlibBuf bytes.Buffer
+	zw := zlib.NewWriter(&zlibBuf)
+	_, _ = zw.Write([]byte(samplePayload))
+	_ = zw.Close()
+
+	var rawDeflate bytes.Buffer
+	fw, _ := flate.NewWriter(&rawDeflate, flate.DefaultCompression)
+	_, _ = fw.Write([]byte(samplePayload))
+	_ = fw.Close()
+
+	enc, _ := zstd.NewWriter(nil)
+	zstdBody := enc.EncodeAll([]byte(samplePayload), nil)
+	_ = enc.Close()
+
+	cases := []struct {
+		name     string
+		body     []byte
+		encoding string
+	}{
+		{"gzip", gzipBytes(t, []byte(samplePayload)), "gzip"},
+		{"x-gzip", gzipBytes(t, []byte(samplePayload)), "X-Gzip"},
+		{"br", brBuf.Bytes(), "br"},
+		{"zlib deflate", zlibBuf.Bytes(), "deflate"},
+		{"raw deflate", rawDeflate.Bytes(), "deflate"},
+		{"zstd", zstdBody, "zstd"},
+		{"stacked", gzipBytes(t, zstdBody), "zstd, gzip"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := newResponseWithBody(tc.body, tc.encoding)
+			if got := readDecompressed(t, resp); got != samplePayload {
+				t.Fatalf("body mismatch: got %q", got)
+			}
+			if resp.Header.Get("Content-Encoding") != "" || resp.ContentLength != -1 {
+				t.Fatalf("encoding headers not cleared: %v len=%d", resp.Header, resp.ContentLength)
+			}
+		})
+	}
+}
+
+func TestDecompressResponseBody_LeavesUnsupportedUntouched(t *testing.T) {
+	resp := newResponseWithBody([]byte(samplePayload), "compress")
+	if got := readDecompressed(t, resp); got != samplePayload {
+		t.Fatalf("body mismatch: got %q", got)
+	}
+	if resp.Header.Get("Content-Encoding") != "compress" {
+		t.Fatalf("unsupported Content-Encoding should be preserved")
+	}
+}
+
+func TestDecodeSniffedBody(t *testing.T) {
+	if got := DecodeSniffedBody(gzipBytes(t, []byte(samplePayload))); string(got) != samplePayload {
+		t.Fatalf("gzip sniff mismatch: got %q", got)
+	}
+	if got := DecodeSniffedBody([]byte(samplePayload)); string(got) != samplePayload {
+		t.Fatalf("plain body should pass through: got %q", got)
+	}
+	// 魔数匹配但内容损坏时保持原样
+	corrupt := []byte{0x1f, 0x8b, 0x00}
+	if got := DecodeSniffedBody(corrupt); !bytes.Equal(got, corrupt) {
+		t.Fatalf("corrupt body should pass through: got %v", got)
+	}
+}
+
+func TestSanitizeAcceptEncoding(t *testing.T) {
+	h := http.Header{}
+	h["accept-encoding"] = []string{"gzip, compress;q=0.5, br;q=0.8, identity"}
+	SanitizeAcceptEncoding(h)
+	if got := h["accept-encoding"]; len(got) != 1 || got[0] != "gzip, br;q=0.8, identity" {
+		t.Fatalf("unexpected Accept-Encoding: %v", got)
+	}
+
+	h = http.Header{}
+	h.Set("Accept-Encoding", "compress, sdch")
+	SanitizeAcceptEncoding(h)
+	if _, ok := h["Accept-Encoding"]; ok {
+		t.Fatalf("Accept-Encoding without decodable codings should be removed")
+	}
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	componentFieldKey = "component"
+	requestIDFieldKey = "request_id"
+
+	componentSamplingBuckets = 10000
+)
+
+// componentSamplingRule 为编译后的组件采样规则。
+type componentSamplingRule struct {
+	prefix      string
+	threshold   uint64 // 保留比例 * componentSamplingBuckets
+	alwaysLevel zapcore.Level
+}
+
+// componentSamplerCore 按 component 字段（最长前缀匹配）对低于 alwaysLevel 的日志按比例采样。
+// 携带 request_id 时按请求 ID 哈希决定，同一请求的日志要么全部保留、要么全部丢弃；
+// 否则逐条随机采样。component / request_id 需通过 With 附加（handler 的 requestLogger、LegacyPrintf 均如此）。
+type componentSamplerCore struct {
+	zapcore.Core
+	rules     []componentSamplingRule
+	rule      *componentSamplingRule
+	requestID string
+}
+
+func newComponentSamplerCore(core zapcore.Core, rules []ComponentSamplingRule) zapcore.Core {
+	compiled := compileComponentSamplingRules(rules)
+	if len(compiled) == 0 {
+		return core
+	}
+	return &componentSamplerCore{Core: core, rules: compiled}
+}
+
+func compileComponentSamplingRules(rules []ComponentSamplingRule) []componentSamplingRule {
+	out := make([]componentSamplingRule, 0, len(rules))
+	for _, rule := range rules {
+		prefix := strings.TrimSpace(rule.Component)
+		if prefix == "" {
+			continue
+		}
+		alwaysLevel, ok := parseLevel(rule.AlwaysLevel)
+		if !ok {
+			alwaysLevel = LevelWarn
+		}
+		rate := min(max(rule.Rate, 0), 1)
+		out = append(out, componentSamplingRule{
+			prefix:      prefix,
+			threshold:   uint64(rate * componentSamplingBuckets),
+			alwaysLevel: alwaysLevel,
+		})
+	}
+	// 最长前缀优先
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i].prefix) > len(out[j].prefix) })
+	return out
+}
+
+func (c *componentSamplerCore) matchRule(component string) *componentSamplingRule {
+	for i := range c.rules {
+		prefix := c.rules[i].prefix
+		if component == prefix || strings.HasPrefix(component, prefix+".") {
+			return &c.rules[i]
+		}
+	}
+	return nil
+}
+
+func (c *componentSamplerCore) With(fields []zapcore.Field) zapcore.Core {
+	next := &componentSamplerCore{
+		Core:      c.Core.With(fields),
+		rules:     c.rules,
+		rule:      c.rule,
+		requestID: c.requestID,
+	}
+	for _, f := range fields {
+		if f.Type != zapcore.StringType {
+			continue
+		}
+		switch f.Key {
+		case componentFieldKey:
+			next.rule = c.matchRule(f.String)
+		case requestIDFieldKey:
+			next.requestID = f.String
+		}
+	}
+	return next
+}
+
+func (c *componentSamplerCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.rule != nil && entry.Level < c.rule.alwaysLevel && !c.sampled() {
+		return ce
+	}
+	return c.Core.Check(entry, ce)
+}
+
+func (c *componentSamplerCore) sampled() bool {
+	if c.rule.threshold >= componentSamplingBuckets {
+		return true
+	}
+	if c.rule.threshold == 0 {
+		return false
+	}
+	var bucket uint64
+	if c.requestID != "" {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(c.requestID))
+		bucket = h.Sum64() % componentSamplingBuckets
+	} else {
+		bucket = rand.Uint64N(componentSamplingBuckets)
+	}
+	return bucket < c.rule.threshold
+}
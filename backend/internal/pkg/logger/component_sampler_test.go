@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newComponentSampledLogger(rules ...ComponentSamplingRule) (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(newComponentSamplerCore(core, rules)), logs
+}
+
+func TestComponentSampler_NoRulesReturnsInnerCore(t *testing.T) {
+	core, _ := observer.New(zapcore.DebugLevel)
+	if got := newComponentSamplerCore(core, []ComponentSamplingRule{{Component: " ", Rate: 0.5}}); got != core {
+		t.Fatalf("expected inner core when no valid rules")
+	}
+}
+
+func TestComponentSampler_AlwaysKeepsErrorsAndUnmatchedComponents(t *testing.T) {
+	l, logs := newComponentSampledLogger(ComponentSamplingRule{Component: "handler.gateway", Rate: 0})
+
+	gw := l.With(zap.String("component", "handler.gateway.messages"))
+	gw.Info("gateway-info")
+	gw.Debug("gateway-debug")
+	gw.Warn("gateway-warn")
+	gw.Error("gateway-error")
+	// 前缀需按组件层级匹配，handler.gateway_x 不命中 handler.gateway
+	l.With(zap.String("component", "handler.gateway_x")).Info("other-info")
+	l.Info("no-component")
+
+	var got []string
+	for _, entry := range logs.All() {
+		got = append(got, entry.Message)
+	}
+	want := []string{"gateway-warn", "gateway-error", "other-info", "no-component"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("logged=%v, want %v", got, want)
+	}
+}
+
+func TestComponentSampler_LongestPrefixAndAlwaysLevel(t *testing.T) {
+	l, logs := newComponentSampledLogger(
+		ComponentSamplingRule{Component: "handler", Rate: 1},
+		ComponentSamplingRule{Component: "handler.openai_gateway", Rate: 0, AlwaysLevel: "info"},
+	)
+
+	oa := l.With(zap.String("component", "handler.openai_gateway.responses"))
+	oa.Debug("openai-debug")
+	oa.Info("openai-info")
+	l.With(zap.String("component", "handler.gateway")).Debug("gateway-debug")
+
+	if logs.FilterMessage("openai-debug").Len() != 0 {
+		t.Fatalf("debug below always_level should be dropped")
+	}
+	if logs.FilterMessage("openai-info").Len() != 1 || logs.FilterMessage("gateway-debug").Len() != 1 {
+		t.Fatalf("unexpected logs: %v", logs.All())
+	}
+}
+
+func TestComponentSampler_SamplesWholeRequests(t *testing.T) {
+	l, logs := newComponentSampledLogger(ComponentSamplingRule{Component: "handler.gateway", Rate: 0.2})
+
+	const requests = 2000
+	for i := 0; i < requests; i++ {
+		reqLog := l.With(zap.String("request_id", fmt.Sprintf("req-%d", i))).
+			With(zap.String("component", "handler.gateway.messages"))
+		reqLog.Info("start", zap.Int("req", i))
+		reqLog.Debug("event", zap.Int("req", i))
+		reqLog.Info("done", zap.Int("req", i))
+	}
+
+	perRequest := map[int64]int{}
+	for _, entry := range logs.All() {
+		perRequest[entry.ContextMap()["req"].(int64)]++
+	}
+	for req, n := range perRequest {
+		if n != 3 {
+			t.Fatalf("request %d logged %d/3 entries, want all-or-nothing", req, n)
+		}
+	}
+	if kept := len(perRequest); kept < requests/10 || kept > requests*3/10 {
+		t.Fatalf("kept %d/%d requests, want about 20%%", kept, requests)
+	}
+}
@@ -3,6 +3,14 @@ package logger
 import "github.com/Wei-Shaw/sub2api/internal/config"
 
 func OptionsFromConfig(cfg config.LogConfig) InitOptions {
+	var components []ComponentSamplingRule
+	for _, rule := range cfg.Sampling.Components {
+		components = append(components, ComponentSamplingRule{
+			Component:   rule.Component,
+			Rate:        rule.Rate,
+			AlwaysLevel: rule.AlwaysLevel,
+		})
+	}
 	return InitOptions{
 		Level:           cfg.Level,
 		Format:          cfg.Format,
@@ -26,6 +34,7 @@ func OptionsFromConfig(cfg config.LogConfig) InitOptions {
 			Enabled:    cfg.Sampling.Enabled,
 			Initial:    cfg.Sampling.Initial,
 			Thereafter: cfg.Sampling.Thereafter,
+			Components: components,
 		},
 	}
 }
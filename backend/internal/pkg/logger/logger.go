@@ -298,6 +298,7 @@ func buildLogger(options InitOptions) (*zap.Logger, zap.AtomicLevel, error) {
 	if options.Sampling.Enabled {
 		core = zapcore.NewSamplerWithOptions(core, samplingTick(), options.Sampling.Initial, options.Sampling.Thereafter)
 	}
+	core = newComponentSamplerCore(core, options.Sampling.Components)
 	core = sinkCore.Wrap(core)
 
 	stacktraceLevel, _ := parseStacktraceLevel(options.StacktraceLevel)
@@ -43,6 +43,18 @@ type SamplingOptions struct {
 	Enabled    bool
 	Initial    int
 	Thereafter int
+	// Components 按组件的采样规则，与上面的全局采样相互独立。
+	Components []ComponentSamplingRule
+}
+
+// ComponentSamplingRule 按 component 前缀对低级别日志按比例采样。
+type ComponentSamplingRule struct {
+	// Component 组件名前缀，如 "handler.gateway" 匹配 "handler.gateway.messages"
+	Component string
+	// Rate 低于 AlwaysLevel 的日志保留比例（0~1）；同一请求按 request_id 统一取舍
+	Rate float64
+	// AlwaysLevel 不低于该级别的日志始终保留，默认 warn
+	AlwaysLevel string
 }
 
 func (o InitOptions) normalized() InitOptions {
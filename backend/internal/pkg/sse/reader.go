@@ -0,0 +1,188 @@
+// Package sse provides a Server-Sent Events reader shared by the gateway stream relays.
+//
+// Unlike bufio.Scanner, Reader has no fixed token size: lines grow up to a
+// configurable maximum (gateway.max_line_size) and exceeding it is reported as
+// ErrLineTooLong instead of silently ending the stream. ReadEvent assembles
+// complete events per the SSE spec (multi-line data fields, comments, CRLF).
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	// DefaultMaxLineSize 未配置时的单行上限
+	DefaultMaxLineSize = 40 * 1024 * 1024
+	readerBufferSize   = 64 * 1024
+)
+
+// ErrLineTooLong 单行超过 maxLineSize。
+// 与 bufio.ErrTooLong 相同，便于已有的 errors.Is(err, bufio.ErrTooLong) 判断继续生效。
+var ErrLineTooLong = bufio.ErrTooLong
+
+// Event 一个完整的 SSE 事件
+type Event struct {
+	// Event event 字段（未设置时为空）
+	Event string
+	// Data 所有 data 行以 "\n" 拼接后的内容
+	Data string
+	// ID id 字段
+	ID string
+	// HasData 是否出现过 data 字段（区分空 data 与无 data）
+	HasData bool
+}
+
+// Reader 基于 bufio.Reader 的 SSE 读取器。
+// 提供与 bufio.Scanner 兼容的 Scan/Text/Err 方法，可直接替换逐行扫描的转发循环。
+type Reader struct {
+	r           *bufio.Reader
+	maxLineSize int
+	line        []byte
+	err         error
+}
+
+// NewReader 创建 SSE 读取器；maxLineSize<=0 时使用 DefaultMaxLineSize
+func NewReader(r io.Reader, maxLineSize int) *Reader {
+	if maxLineSize <= 0 {
+		maxLineSize = DefaultMaxLineSize
+	}
+	return &Reader{
+		r:           bufio.NewReaderSize(r, readerBufferSize),
+		maxLineSize: maxLineSize,
+	}
+}
+
+// Reset 复用 Reader 读取新的数据源（用于对象池）
+func (r *Reader) Reset(src io.Reader, maxLineSize int) {
+	if maxLineSize <= 0 {
+		maxLineSize = DefaultMaxLineSize
+	}
+	r.r.Reset(src)
+	r.maxLineSize = maxLineSize
+	r.err = nil
+	// 超长行留下的大缓冲不随对象池长期驻留
+	if cap(r.line) > readerBufferSize {
+		r.line = nil
+	} else {
+		r.line = r.line[:0]
+	}
+}
+
+// ReadLine 读取一行（不含行尾 \n 或 \r\n）。
+// 流结束时返回 io.EOF；末尾无换行的残余内容会先作为一行返回。
+func (r *Reader) ReadLine() (string, error) {
+	r.line = r.line[:0]
+	for {
+		chunk, err := r.r.ReadSlice('\n')
+		if len(r.line)+len(chunk) > r.maxLineSize+2 { // 允许行尾 \r\n
+			return "", ErrLineTooLong
+		}
+		r.line = append(r.line, chunk...)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			if len(r.line) > 0 && errors.Is(err, io.EOF) {
+				return string(trimLineEnding(r.line)), nil
+			}
+			return "", err
+		}
+		line := trimLineEnding(r.line)
+		if len(line) > r.maxLineSize {
+			return "", ErrLineTooLong
+		}
+		return string(line), nil
+	}
+}
+
+// Scan 读取下一行，语义同 bufio.Scanner.Scan
+func (r *Reader) Scan() bool {
+	if r.err != nil {
+		return false
+	}
+	line, err := r.ReadLine()
+	if err != nil {
+		r.err = err
+		return false
+	}
+	r.line = append(r.line[:0], line...)
+	return true
+}
+
+// Text 返回最近一次 Scan 读到的行
+func (r *Reader) Text() string {
+	return string(r.line)
+}
+
+// Err 返回第一个非 EOF 错误，语义同 bufio.Scanner.Err
+func (r *Reader) Err() error {
+	if errors.Is(r.err, io.EOF) {
+		return nil
+	}
+	return r.err
+}
+
+// ReadEvent 读取下一个完整事件（以空行分隔）。
+// 注释行与仅含未知字段的事件会被跳过；流结束时返回 io.EOF，结束前未以空行收尾的事件仍会返回。
+func (r *Reader) ReadEvent() (*Event, error) {
+	var ev Event
+	var data strings.Builder
+	pending := false
+	for {
+		line, err := r.ReadLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) && pending {
+				ev.Data = data.String()
+				return &ev, nil
+			}
+			r.err = err
+			return nil, err
+		}
+		if line == "" {
+			if pending {
+				ev.Data = data.String()
+				return &ev, nil
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value := parseField(line)
+		switch field {
+		case "event":
+			ev.Event = value
+			pending = true
+		case "data":
+			if ev.HasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			ev.HasData = true
+			pending = true
+		case "id":
+			ev.ID = value
+			pending = true
+		}
+	}
+}
+
+// parseField 按 SSE 规范拆分 "field: value"（冒号后的单个空格会被去除）
+func parseField(line string) (string, string) {
+	idx := strings.IndexByte(line, ':')
+	if idx < 0 {
+		return line, ""
+	}
+	value := line[idx+1:]
+	value = strings.TrimPrefix(value, " ")
+	return line[:idx], value
+}
+
+func trimLineEnding(b []byte) []byte {
+	b = bytes.TrimSuffix(b, []byte("\n"))
+	return bytes.TrimSuffix(b, []byte("\r"))
+}
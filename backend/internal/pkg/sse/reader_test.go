@@ -0,0 +1,83 @@
+package sse
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReader_ScanLinesBeyondScannerDefault(t *testing.T) {
+	// bufio.Scanner 默认 64KB token 上限，超过时会直接终止扫描
+	big := strings.Repeat("x", 2*1024*1024)
+	r := NewReader(strings.NewReader("data: "+big+"\r\ndata: tail"), 4*1024*1024)
+
+	if !r.Scan() {
+		t.Fatalf("Scan() = false, err = %v", r.Err())
+	}
+	if got := r.Text(); got != "data: "+big {
+		t.Fatalf("unexpected first line length %d", len(got))
+	}
+	if !r.Scan() || r.Text() != "data: tail" {
+		t.Fatalf("expected trailing line without newline, got %q err=%v", r.Text(), r.Err())
+	}
+	if r.Scan() {
+		t.Fatalf("expected end of stream")
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil at EOF", err)
+	}
+}
+
+func TestReader_LineTooLong(t *testing.T) {
+	r := NewReader(strings.NewReader("data: "+strings.Repeat("x", 1024)+"\n"), 512)
+	if r.Scan() {
+		t.Fatalf("expected Scan() to fail on oversized line")
+	}
+	if !errors.Is(r.Err(), ErrLineTooLong) {
+		t.Fatalf("Err() = %v, want ErrLineTooLong", r.Err())
+	}
+
+	// 恰好等于上限的行（含 \r\n 行尾）仍可读取
+	r = NewReader(strings.NewReader(strings.Repeat("y", 512)+"\r\n"), 512)
+	if line, err := r.ReadLine(); err != nil || len(line) != 512 {
+		t.Fatalf("ReadLine() = %d bytes, %v", len(line), err)
+	}
+}
+
+func TestReader_ReadEvent(t *testing.T) {
+	stream := ": keepalive\n\n" +
+		"event: message_start\ndata: {\"a\":1}\n\n" +
+		"data: line1\ndata:line2\nid: 7\n\n" +
+		"event: ping\n\n" +
+		"data: last"
+	r := NewReader(strings.NewReader(stream), 0)
+
+	ev, err := r.ReadEvent()
+	if err != nil || ev.Event != "message_start" || ev.Data != `{"a":1}` || !ev.HasData {
+		t.Fatalf("unexpected first event %+v, err=%v", ev, err)
+	}
+
+	ev, err = r.ReadEvent()
+	if err != nil || ev.Data != "line1\nline2" || ev.ID != "7" || ev.Event != "" {
+		t.Fatalf("unexpected multi-line event %+v, err=%v", ev, err)
+	}
+
+	ev, err = r.ReadEvent()
+	if err != nil || ev.Event != "ping" || ev.HasData {
+		t.Fatalf("unexpected data-less event %+v, err=%v", ev, err)
+	}
+
+	// 流结束前未以空行收尾的事件仍会返回
+	ev, err = r.ReadEvent()
+	if err != nil || ev.Data != "last" {
+		t.Fatalf("unexpected trailing event %+v, err=%v", ev, err)
+	}
+
+	if _, err = r.ReadEvent(); !errors.Is(err, io.EOF) {
+		t.Fatalf("ReadEvent() err = %v, want io.EOF", err)
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil at EOF", err)
+	}
+}
@@ -245,6 +245,10 @@ type UsageLogFilters struct {
 	BillingMode string
 	StartTime   *time.Time
 	EndTime     *time.Time
+	// RequestID matches the gateway request ID (X-Request-ID), with or without the "client:" prefix.
+	RequestID string
+	// OrganizationID restricts results to users that belong to the organization.
+	OrganizationID int64
 	// ExactTotal requests exact COUNT(*) for pagination. Default false for fast large-table paging.
 	ExactTotal bool
 }
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	dbaccount "github.com/Wei-Shaw/sub2api/ent/account"
+	"github.com/Wei-Shaw/sub2api/ent/schema/mixins"
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// encryptedCredentialPrefix 标记已加密的凭证值。
+// 存储格式：enc:v1:<base64(被主密钥包裹的 DEK)>:<base64(nonce + ciphertext + tag)>
+const encryptedCredentialPrefix = "enc:v1:"
+
+// credentialEncryptBatchSize 迁移命令每批处理的账号数量。
+const credentialEncryptBatchSize = 200
+
+// sensitiveCredentialKeys 需要落库加密的凭证字段。
+// 仅加密字符串值；expires_at、project_id、model_mapping 等非敏感字段保持明文，便于排查与后台展示。
+var sensitiveCredentialKeys = map[string]struct{}{
+	"access_token":          {},
+	"refresh_token":         {},
+	"id_token":              {},
+	"api_key":               {},
+	"session_key":           {},
+	"session_token":         {},
+	"service_account_json":  {},
+	"aws_secret_access_key": {},
+	"aws_session_token":     {},
+}
+
+// credentialCipher 对账号凭证中的敏感字段执行信封加密（envelope encryption）。
+//
+// 每个字段值使用一次性随机数据密钥（DEK）进行 AES-256-GCM 加密，
+// DEK 再由主密钥（KEK）以 AES-256-GCM 包裹后与密文一同存储。
+// 轮换主密钥时只需重新包裹 DEK，无需改动密文本身。
+type credentialCipher struct {
+	kek cipher.AEAD
+	// encrypt 为 false 时只解密不加密：关闭加密后仍能读取历史密文。
+	encrypt bool
+}
+
+// newCredentialCipher 根据配置创建凭证加密器。
+// 未配置主密钥时返回 nil，此时读写均为明文透传。
+func newCredentialCipher(cfg *config.Config) (*credentialCipher, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	encCfg := cfg.Security.CredentialEncryption
+	masterKey := strings.TrimSpace(encCfg.MasterKey)
+	if masterKey == "" {
+		if encCfg.Enabled {
+			return nil, errors.New("credential encryption enabled but master key is empty")
+		}
+		return nil, nil
+	}
+	key, err := hex.DecodeString(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential master key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("credential master key must be 32 bytes (64 hex chars), got %d bytes", len(key))
+	}
+	kek, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialCipher{kek: kek, encrypt: encCfg.Enabled}, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return gcm, nil
+}
+
+func gcmSeal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func gcmOpen(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+}
+
+func isEncryptedCredential(value string) bool {
+	return strings.HasPrefix(value, encryptedCredentialPrefix)
+}
+
+// encryptValue 使用新的 DEK 加密单个凭证值。
+func (c *credentialCipher) encryptValue(plaintext string) (string, error) {
+	dek := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", fmt.Errorf("generate data key: %w", err)
+	}
+	dekAEAD, err := newAESGCM(dek)
+	if err != nil {
+		return "", err
+	}
+	payload, err := gcmSeal(dekAEAD, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	wrappedDEK, err := gcmSeal(c.kek, dek)
+	if err != nil {
+		return "", err
+	}
+	return encryptedCredentialPrefix +
+		base64.StdEncoding.EncodeToString(wrappedDEK) + ":" +
+		base64.StdEncoding.EncodeToString(payload), nil
+}
+
+// decryptValue 解密 encryptValue 生成的凭证值。
+func (c *credentialCipher) decryptValue(value string) (string, error) {
+	wrappedPart, payloadPart, ok := strings.Cut(strings.TrimPrefix(value, encryptedCredentialPrefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted credential")
+	}
+	wrappedDEK, err := base64.StdEncoding.DecodeString(wrappedPart)
+	if err != nil {
+		return "", fmt.Errorf("decode data key: %w", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(payloadPart)
+	if err != nil {
+		return "", fmt.Errorf("decode payload: %w", err)
+	}
+	dek, err := gcmOpen(c.kek, wrappedDEK)
+	if err != nil {
+		return "", fmt.Errorf("unwrap data key: %w", err)
+	}
+	dekAEAD, err := newAESGCM(dek)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := gcmOpen(dekAEAD, payload)
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// EncryptCredentials 返回敏感字段已加密的凭证副本，不修改入参。
+// 已加密的值保持不变，保证重复写入与迁移的幂等性。
+func (c *credentialCipher) EncryptCredentials(in map[string]any) (map[string]any, error) {
+	if c == nil || !c.encrypt || len(in) == 0 {
+		return in, nil
+	}
+	out := make(map[string]any, len(in))
+	for k, v := range in {
+		out[k] = v
+		s, ok := v.(string)
+		if !ok || s == "" || isEncryptedCredential(s) {
+			continue
+		}
+		if _, sensitive := sensitiveCredentialKeys[k]; !sensitive {
+			continue
+		}
+		enc, err := c.encryptValue(s)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt credential %s: %w", k, err)
+		}
+		out[k] = enc
+	}
+	return out, nil
+}
+
+// DecryptCredentials 原地解密凭证中的密文字段。
+// 单个字段解密失败时保留原值并记录日志，避免一个坏字段导致整个账号无法加载。
+func (c *credentialCipher) DecryptCredentials(accountID int64, credentials map[string]any) {
+	if c == nil {
+		return
+	}
+	for k, v := range credentials {
+		s, ok := v.(string)
+		if !ok || !isEncryptedCredential(s) {
+			continue
+		}
+		plain, err := c.decryptValue(s)
+		if err != nil {
+			logger.LegacyPrintf("repository.account", "[CredentialCipher] decrypt failed: account=%d key=%s err=%v", accountID, k, err)
+			continue
+		}
+		credentials[k] = plain
+	}
+}
+
+// EncryptAccountCredentials 将存量账号中仍为明文的敏感凭证字段加密落库。
+// 包含已软删除的账号；已加密字段会被跳过，可重复执行。返回实际被改写的账号数。
+func EncryptAccountCredentials(ctx context.Context, client *dbent.Client, cfg *config.Config) (int, error) {
+	c, err := newCredentialCipher(cfg)
+	if err != nil {
+		return 0, err
+	}
+	if c == nil || !c.encrypt {
+		return 0, errors.New("security.credential_encryption must be enabled with a master key")
+	}
+
+	ctx = mixins.SkipSoftDelete(ctx)
+	updated := 0
+	var lastID int64
+	for {
+		accounts, err := client.Account.Query().
+			Where(dbaccount.IDGT(lastID)).
+			Order(dbent.Asc(dbaccount.FieldID)).
+			Limit(credentialEncryptBatchSize).
+			All(ctx)
+		if err != nil {
+			return updated, err
+		}
+		if len(accounts) == 0 {
+			return updated, nil
+		}
+		for _, acc := range accounts {
+			lastID = acc.ID
+			if !hasPlaintextSensitiveCredential(acc.Credentials) {
+				continue
+			}
+			encrypted, err := c.EncryptCredentials(acc.Credentials)
+			if err != nil {
+				return updated, fmt.Errorf("account %d: %w", acc.ID, err)
+			}
+			if err := client.Account.UpdateOneID(acc.ID).SetCredentials(encrypted).Exec(ctx); err != nil {
+				return updated, fmt.Errorf("account %d: %w", acc.ID, err)
+			}
+			updated++
+		}
+	}
+}
+
+func hasPlaintextSensitiveCredential(credentials map[string]any) bool {
+	for k := range sensitiveCredentialKeys {
+		if s, ok := credentials[k].(string); ok && s != "" && !isEncryptedCredential(s) {
+			return true
+		}
+	}
+	return false
+}
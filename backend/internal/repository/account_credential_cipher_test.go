@@ -0,0 +1,99 @@
+//go:build unit
+
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+const testCredentialMasterKey = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func newTestCredentialCipher(t *testing.T, enabled bool) *credentialCipher {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Security.CredentialEncryption.Enabled = enabled
+	cfg.Security.CredentialEncryption.MasterKey = testCredentialMasterKey
+	c, err := newCredentialCipher(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	return c
+}
+
+func TestCredentialCipher_RoundTrip(t *testing.T) {
+	c := newTestCredentialCipher(t, true)
+	in := map[string]any{
+		"access_token":  "sk-ant-oat01-secret",
+		"refresh_token": "rt-secret",
+		"expires_at":    "1700000000",
+		"model_mapping": map[string]any{"a": "b"},
+	}
+
+	stored, err := c.EncryptCredentials(in)
+	require.NoError(t, err)
+	require.Equal(t, "sk-ant-oat01-secret", in["access_token"], "input must not be mutated")
+	require.True(t, strings.HasPrefix(stored["access_token"].(string), encryptedCredentialPrefix))
+	require.True(t, strings.HasPrefix(stored["refresh_token"].(string), encryptedCredentialPrefix))
+	require.Equal(t, "1700000000", stored["expires_at"])
+	require.Equal(t, in["model_mapping"], stored["model_mapping"])
+
+	again, err := c.EncryptCredentials(stored)
+	require.NoError(t, err)
+	require.Equal(t, stored["access_token"], again["access_token"], "already encrypted values are kept")
+
+	c.DecryptCredentials(1, stored)
+	require.Equal(t, "sk-ant-oat01-secret", stored["access_token"])
+	require.Equal(t, "rt-secret", stored["refresh_token"])
+}
+
+func TestCredentialCipher_DisabledStillDecrypts(t *testing.T) {
+	enc := newTestCredentialCipher(t, true)
+	stored, err := enc.EncryptCredentials(map[string]any{"api_key": "sk-test"})
+	require.NoError(t, err)
+
+	dec := newTestCredentialCipher(t, false)
+	plain, err := dec.EncryptCredentials(map[string]any{"api_key": "sk-new"})
+	require.NoError(t, err)
+	require.Equal(t, "sk-new", plain["api_key"])
+
+	dec.DecryptCredentials(1, stored)
+	require.Equal(t, "sk-test", stored["api_key"])
+}
+
+func TestCredentialCipher_WrongKeyKeepsCiphertext(t *testing.T) {
+	stored, err := newTestCredentialCipher(t, true).EncryptCredentials(map[string]any{"api_key": "sk-test"})
+	require.NoError(t, err)
+	ciphertext := stored["api_key"]
+
+	cfg := &config.Config{}
+	cfg.Security.CredentialEncryption.MasterKey = strings.Repeat("ff", 32)
+	other, err := newCredentialCipher(cfg)
+	require.NoError(t, err)
+
+	other.DecryptCredentials(1, stored)
+	require.Equal(t, ciphertext, stored["api_key"])
+}
+
+func TestNewCredentialCipher_Config(t *testing.T) {
+	c, err := newCredentialCipher(&config.Config{})
+	require.NoError(t, err)
+	require.Nil(t, c)
+
+	var nilCipher *credentialCipher
+	in := map[string]any{"api_key": "sk-test"}
+	out, err := nilCipher.EncryptCredentials(in)
+	require.NoError(t, err)
+	require.Equal(t, "sk-test", out["api_key"])
+
+	cfg := &config.Config{}
+	cfg.Security.CredentialEncryption.Enabled = true
+	_, err = newCredentialCipher(cfg)
+	require.Error(t, err)
+
+	cfg.Security.CredentialEncryption.MasterKey = "abcd"
+	_, err = newCredentialCipher(cfg)
+	require.Error(t, err)
+}
@@ -25,6 +25,7 @@ import (
 	dbgroup "github.com/Wei-Shaw/sub2api/ent/group"
 	dbpredicate "github.com/Wei-Shaw/sub2api/ent/predicate"
 	dbproxy "github.com/Wei-Shaw/sub2api/ent/proxy"
+	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 	"github.com/Wei-Shaw/sub2api/internal/service"
@@ -41,6 +42,7 @@ import (
 //   - client: Ent 客户端，用于类型安全的 ORM 操作
 //   - sql: 原生 SQL 执行器，用于复杂查询和批量操作
 //   - schedulerCache: 调度器缓存，用于在账号状态变更时同步快照
+//   - credentials: 凭证加密器，写入时加密敏感字段、读取时透明解密（未配置主密钥时为 nil）
 type accountRepository struct {
 	client *dbent.Client // Ent ORM 客户端
 	sql    sqlExecutor   // 原生 SQL 执行接口
@@ -49,6 +51,7 @@ type accountRepository struct {
 	// Used to proactively sync account snapshot to cache when status changes,
 	// ensuring sticky sessions can promptly detect unavailable accounts.
 	schedulerCache service.SchedulerCache
+	credentials    *credentialCipher
 }
 
 var schedulerNeutralExtraKeyPrefixes = []string{
@@ -62,12 +65,20 @@ var schedulerNeutralExtraKeyPrefixes = []string{
 var schedulerNeutralExtraKeys = map[string]struct{}{
 	"codex_usage_updated_at":     {},
 	"session_window_utilization": {},
+	"upstream_rate_limit":        {},
 }
 
 // NewAccountRepository 创建账户仓储实例。
 // 这是对外暴露的构造函数，返回接口类型以便于依赖注入。
-func NewAccountRepository(client *dbent.Client, sqlDB *sql.DB, schedulerCache service.SchedulerCache) service.AccountRepository {
-	return newAccountRepositoryWithSQL(client, sqlDB, schedulerCache)
+func NewAccountRepository(client *dbent.Client, sqlDB *sql.DB, schedulerCache service.SchedulerCache, cfg *config.Config) service.AccountRepository {
+	repo := newAccountRepositoryWithSQL(client, sqlDB, schedulerCache)
+	credentials, err := newCredentialCipher(cfg)
+	if err != nil {
+		// 配置校验已拦截非法主密钥，这里仅作兜底，避免启动失败。
+		logger.LegacyPrintf("repository.account", "[CredentialCipher] disabled: %v", err)
+	}
+	repo.credentials = credentials
+	return repo
 }
 
 // newAccountRepositoryWithSQL 是内部构造函数，支持依赖注入 SQL 执行器。
@@ -80,13 +91,17 @@ func (r *accountRepository) Create(ctx context.Context, account *service.Account
 	if account == nil {
 		return service.ErrAccountNilInput
 	}
+	credentials, err := r.credentials.EncryptCredentials(normalizeJSONMap(account.Credentials))
+	if err != nil {
+		return err
+	}
 
 	builder := r.client.Account.Create().
 		SetName(account.Name).
 		SetNillableNotes(account.Notes).
 		SetPlatform(account.Platform).
 		SetType(account.Type).
-		SetCredentials(normalizeJSONMap(account.Credentials)).
+		SetCredentials(credentials).
 		SetExtra(normalizeJSONMap(account.Extra)).
 		SetConcurrency(account.Concurrency).
 		SetPriority(account.Priority).
@@ -101,6 +116,10 @@ func (r *accountRepository) Create(ctx context.Context, account *service.Account
 	if account.LoadFactor != nil {
 		builder.SetLoadFactor(*account.LoadFactor)
 	}
+	if account.OwnerUserID != nil {
+		builder.SetOwnerUserID(*account.OwnerUserID)
+	}
+	builder.SetTagsExclusive(account.TagsExclusive)
 
 	if account.ProxyID != nil {
 		builder.SetProxyID(*account.ProxyID)
@@ -205,6 +224,10 @@ func (r *accountRepository) GetByIDs(ctx context.Context, ids []int64) ([]*servi
 	if err != nil {
 		return nil, err
 	}
+	tagsByAccount, err := r.loadAccountTags(ctx, accountIDs)
+	if err != nil {
+		return nil, err
+	}
 
 	outByID := make(map[int64]*service.Account, len(entAccounts))
 	for _, entAcc := range entAccounts {
@@ -212,6 +235,7 @@ func (r *accountRepository) GetByIDs(ctx context.Context, ids []int64) ([]*servi
 		if out == nil {
 			continue
 		}
+		r.credentials.DecryptCredentials(out.ID, out.Credentials)
 
 		// Prefer the preloaded proxy edge when available.
 		if entAcc.Edges.Proxy != nil {
@@ -227,6 +251,7 @@ func (r *accountRepository) GetByIDs(ctx context.Context, ids []int64) ([]*servi
 		if ags, ok := accountGroupsByAccount[entAcc.ID]; ok {
 			out.AccountGroups = ags
 		}
+		out.Tags = tagsByAccount[entAcc.ID]
 		outByID[entAcc.ID] = out
 	}
 
@@ -317,13 +342,17 @@ func (r *accountRepository) Update(ctx context.Context, account *service.Account
 	if account == nil {
 		return nil
 	}
+	credentials, err := r.credentials.EncryptCredentials(normalizeJSONMap(account.Credentials))
+	if err != nil {
+		return err
+	}
 
 	builder := r.client.Account.UpdateOneID(account.ID).
 		SetName(account.Name).
 		SetNillableNotes(account.Notes).
 		SetPlatform(account.Platform).
 		SetType(account.Type).
-		SetCredentials(normalizeJSONMap(account.Credentials)).
+		SetCredentials(credentials).
 		SetExtra(normalizeJSONMap(account.Extra)).
 		SetConcurrency(account.Concurrency).
 		SetPriority(account.Priority).
@@ -340,6 +369,12 @@ func (r *accountRepository) Update(ctx context.Context, account *service.Account
 	} else {
 		builder.ClearLoadFactor()
 	}
+	if account.OwnerUserID != nil {
+		builder.SetOwnerUserID(*account.OwnerUserID)
+	} else {
+		builder.ClearOwnerUserID()
+	}
+	builder.SetTagsExclusive(account.TagsExclusive)
 
 	if account.ProxyID != nil {
 		builder.SetProxyID(*account.ProxyID)
@@ -405,8 +440,12 @@ func (r *accountRepository) Update(ctx context.Context, account *service.Account
 }
 
 func (r *accountRepository) UpdateCredentials(ctx context.Context, id int64, credentials map[string]any) error {
-	_, err := r.client.Account.UpdateOneID(id).
-		SetCredentials(normalizeJSONMap(credentials)).
+	encrypted, err := r.credentials.EncryptCredentials(normalizeJSONMap(credentials))
+	if err != nil {
+		return err
+	}
+	_, err = r.client.Account.UpdateOneID(id).
+		SetCredentials(encrypted).
 		Save(ctx)
 	if err != nil {
 		return translatePersistenceError(err, service.ErrAccountNotFound, nil)
@@ -457,10 +496,10 @@ func (r *accountRepository) Delete(ctx context.Context, id int64) error {
 }
 
 func (r *accountRepository) List(ctx context.Context, params pagination.PaginationParams) ([]service.Account, *pagination.PaginationResult, error) {
-	return r.ListWithFilters(ctx, params, "", "", "", "", 0, "")
+	return r.ListWithFilters(ctx, params, "", "", "", "", 0, "", "")
 }
 
-func (r *accountRepository) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode string) ([]service.Account, *pagination.PaginationResult, error) {
+func (r *accountRepository) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode, tag string) ([]service.Account, *pagination.PaginationResult, error) {
 	q := r.client.Account.Query()
 
 	if platform != "" {
@@ -552,6 +591,9 @@ func (r *accountRepository) ListWithFilters(ctx context.Context, params paginati
 			}
 		}))
 	}
+	if tag != "" {
+		q = q.Where(accountHasTagPredicate(tag))
+	}
 
 	total, err := q.Count(ctx)
 	if err != nil {
@@ -656,6 +698,17 @@ func (r *accountRepository) ListByPlatform(ctx context.Context, platform string)
 	return r.accountsToService(ctx, accounts)
 }
 
+func (r *accountRepository) ListByOwnerUserID(ctx context.Context, userID int64) ([]service.Account, error) {
+	accounts, err := r.client.Account.Query().
+		Where(dbaccount.OwnerUserIDEQ(userID)).
+		Order(dbent.Asc(dbaccount.FieldID)).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.accountsToService(ctx, accounts)
+}
+
 func (r *accountRepository) UpdateLastUsed(ctx context.Context, id int64) error {
 	now := time.Now()
 	_, err := r.client.Account.Update().
@@ -1423,7 +1476,11 @@ func (r *accountRepository) BulkUpdate(ctx context.Context, ids []int64, updates
 	}
 	// JSONB 需要合并而非覆盖，使用 raw SQL 保持旧行为。
 	if len(updates.Credentials) > 0 {
-		payload, err := json.Marshal(updates.Credentials)
+		credentials, err := r.credentials.EncryptCredentials(updates.Credentials)
+		if err != nil {
+			return 0, err
+		}
+		payload, err := json.Marshal(credentials)
 		if err != nil {
 			return 0, err
 		}
@@ -1567,6 +1624,10 @@ func (r *accountRepository) accountsToService(ctx context.Context, accounts []*d
 	if err != nil {
 		return nil, err
 	}
+	tagsByAccount, err := r.loadAccountTags(ctx, accountIDs)
+	if err != nil {
+		return nil, err
+	}
 
 	outAccounts := make([]service.Account, 0, len(accounts))
 	for _, acc := range accounts {
@@ -1574,6 +1635,7 @@ func (r *accountRepository) accountsToService(ctx context.Context, accounts []*d
 		if out == nil {
 			continue
 		}
+		r.credentials.DecryptCredentials(out.ID, out.Credentials)
 		if acc.ProxyID != nil {
 			if proxy, ok := proxyMap[*acc.ProxyID]; ok {
 				out.Proxy = proxy
@@ -1588,6 +1650,7 @@ func (r *accountRepository) accountsToService(ctx context.Context, accounts []*d
 		if ags, ok := accountGroupsByAccount[acc.ID]; ok {
 			out.AccountGroups = ags
 		}
+		out.Tags = tagsByAccount[acc.ID]
 		outAccounts = append(outAccounts, *out)
 	}
 
@@ -1681,6 +1744,137 @@ func (r *accountRepository) loadAccountGroupIDs(ctx context.Context, accountID i
 	return ids, nil
 }
 
+// loadAccountTags 批量加载账号标签（account_tags 关联表），按标签名排序。
+func (r *accountRepository) loadAccountTags(ctx context.Context, accountIDs []int64) (map[int64][]string, error) {
+	tagsByAccount := make(map[int64][]string)
+	if len(accountIDs) == 0 {
+		return tagsByAccount, nil
+	}
+	rows, err := r.sql.QueryContext(ctx,
+		`SELECT account_id, tag FROM account_tags WHERE account_id = ANY($1) ORDER BY account_id, tag`,
+		pq.Array(accountIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var accountID int64
+		var tag string
+		if err := rows.Scan(&accountID, &tag); err != nil {
+			return nil, err
+		}
+		tagsByAccount[accountID] = append(tagsByAccount[accountID], tag)
+	}
+	return tagsByAccount, rows.Err()
+}
+
+// accountHasTagPredicate 过滤带有指定标签的账号
+func accountHasTagPredicate(tag string) dbpredicate.Account {
+	return dbpredicate.Account(func(s *entsql.Selector) {
+		s.Where(entsql.In(
+			s.C(dbaccount.FieldID),
+			entsql.Select("account_id").From(entsql.Table("account_tags")).Where(entsql.EQ("tag", tag)),
+		))
+	})
+}
+
+// SetTags 以给定标签整体替换账号标签（单条语句完成删除与插入）。
+func (r *accountRepository) SetTags(ctx context.Context, accountID int64, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	_, err := r.sql.ExecContext(ctx, `
+		WITH removed AS (
+			DELETE FROM account_tags WHERE account_id = $1 AND NOT (tag = ANY($2))
+		)
+		INSERT INTO account_tags (account_id, tag)
+		SELECT $1, t.tag FROM unnest($2::text[]) AS t(tag)
+		ON CONFLICT (account_id, tag) DO NOTHING`,
+		accountID, pq.Array(tags))
+	if err != nil {
+		return err
+	}
+	r.afterAccountTagsChanged(ctx, []int64{accountID})
+	return nil
+}
+
+// AddTags 为一批账号追加标签，返回新增的 (账号, 标签) 条数。
+func (r *accountRepository) AddTags(ctx context.Context, accountIDs []int64, tags []string) (int64, error) {
+	if len(accountIDs) == 0 || len(tags) == 0 {
+		return 0, nil
+	}
+	result, err := r.sql.ExecContext(ctx, `
+		INSERT INTO account_tags (account_id, tag)
+		SELECT a.id, t.tag FROM accounts a CROSS JOIN unnest($2::text[]) AS t(tag)
+		WHERE a.id = ANY($1) AND a.deleted_at IS NULL
+		ON CONFLICT (account_id, tag) DO NOTHING`,
+		pq.Array(accountIDs), pq.Array(tags))
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if rows > 0 {
+		r.afterAccountTagsChanged(ctx, accountIDs)
+	}
+	return rows, nil
+}
+
+// RemoveTags 从一批账号移除标签，返回删除的 (账号, 标签) 条数。
+func (r *accountRepository) RemoveTags(ctx context.Context, accountIDs []int64, tags []string) (int64, error) {
+	if len(accountIDs) == 0 || len(tags) == 0 {
+		return 0, nil
+	}
+	result, err := r.sql.ExecContext(ctx,
+		`DELETE FROM account_tags WHERE account_id = ANY($1) AND tag = ANY($2)`,
+		pq.Array(accountIDs), pq.Array(tags))
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if rows > 0 {
+		r.afterAccountTagsChanged(ctx, accountIDs)
+	}
+	return rows, nil
+}
+
+// ListTags 列出所有在用标签及其账号数（已删除账号不计入）。
+func (r *accountRepository) ListTags(ctx context.Context) ([]service.AccountTagCount, error) {
+	rows, err := r.sql.QueryContext(ctx, `
+		SELECT t.tag, COUNT(*)
+		FROM account_tags t
+		JOIN accounts a ON a.id = t.account_id AND a.deleted_at IS NULL
+		GROUP BY t.tag
+		ORDER BY t.tag`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	out := make([]service.AccountTagCount, 0)
+	for rows.Next() {
+		var item service.AccountTagCount
+		if err := rows.Scan(&item.Tag, &item.AccountCount); err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+// afterAccountTagsChanged 标签变更影响调度过滤，需通知 outbox 并立即刷新账号快照。
+func (r *accountRepository) afterAccountTagsChanged(ctx context.Context, accountIDs []int64) {
+	payload := map[string]any{"account_ids": accountIDs}
+	if err := enqueueSchedulerOutbox(ctx, r.sql, service.SchedulerOutboxEventAccountBulkChanged, nil, nil, payload); err != nil {
+		logger.LegacyPrintf("repository.account", "[SchedulerOutbox] enqueue account tags change failed: err=%v", err)
+	}
+	r.syncSchedulerAccountSnapshots(ctx, accountIDs)
+}
+
 func mergeGroupIDs(a []int64, b []int64) []int64 {
 	seen := make(map[int64]struct{}, len(a)+len(b))
 	out := make([]int64, 0, len(a)+len(b))
@@ -1750,6 +1944,8 @@ func accountEntityToService(m *dbent.Account) *service.Account {
 		SessionWindowStart:      m.SessionWindowStart,
 		SessionWindowEnd:        m.SessionWindowEnd,
 		SessionWindowStatus:     derefString(m.SessionWindowStatus),
+		OwnerUserID:             m.OwnerUserID,
+		TagsExclusive:           m.TagsExclusive,
 	}
 }
 
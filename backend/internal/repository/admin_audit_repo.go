@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type adminAuditRepository struct {
+	db *sql.DB
+}
+
+func NewAdminAuditRepository(db *sql.DB) service.AdminAuditRepository {
+	return &adminAuditRepository{db: db}
+}
+
+// Insert appends one audit row. The table rejects UPDATE/DELETE via trigger, so there is no
+// corresponding update path here.
+func (r *adminAuditRepository) Insert(ctx context.Context, entry *service.AdminAuditLog) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("nil admin audit repository")
+	}
+	if entry == nil {
+		return fmt.Errorf("nil admin audit entry")
+	}
+	query := `
+		INSERT INTO admin_audit_logs (
+			actor_user_id, actor_email, auth_method, method, path, resource, resource_id,
+			status_code, ip, user_agent, request_body, before_state, after_state, diff
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, created_at
+	`
+	args := []any{
+		entry.ActorUserID,
+		entry.ActorEmail,
+		entry.AuthMethod,
+		entry.Method,
+		entry.Path,
+		entry.Resource,
+		nullInt64(entry.ResourceID),
+		entry.StatusCode,
+		entry.IP,
+		entry.UserAgent,
+		adminAuditJSONArg(entry.RequestBody),
+		adminAuditJSONArg(entry.Before),
+		adminAuditJSONArg(entry.After),
+		adminAuditJSONArg(entry.Diff),
+	}
+	return scanSingleRow(ctx, r.db, query, args, &entry.ID, &entry.CreatedAt)
+}
+
+func (r *adminAuditRepository) List(
+	ctx context.Context,
+	params pagination.PaginationParams,
+	filters service.AdminAuditLogFilters,
+) ([]service.AdminAuditLog, *pagination.PaginationResult, error) {
+	if r == nil || r.db == nil {
+		return nil, nil, fmt.Errorf("nil admin audit repository")
+	}
+
+	conditions := make([]string, 0, 6)
+	args := make([]any, 0, 8)
+	addCondition := func(clause string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+	if filters.ActorUserID != nil {
+		addCondition("actor_user_id = $%d", *filters.ActorUserID)
+	}
+	if resource := strings.TrimSpace(filters.Resource); resource != "" {
+		addCondition("resource = $%d", resource)
+	}
+	if filters.ResourceID != nil {
+		addCondition("resource_id = $%d", *filters.ResourceID)
+	}
+	if method := strings.TrimSpace(filters.Method); method != "" {
+		addCondition("method = $%d", strings.ToUpper(method))
+	}
+	if filters.StartTime != nil {
+		addCondition("created_at >= $%d", *filters.StartTime)
+	}
+	if filters.EndTime != nil {
+		addCondition("created_at < $%d", *filters.EndTime)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := scanSingleRow(ctx, r.db, "SELECT COUNT(*) FROM admin_audit_logs "+where, args, &total); err != nil {
+		return nil, nil, err
+	}
+
+	query := `
+		SELECT id, actor_user_id, actor_email, auth_method, method, path, resource, resource_id,
+			status_code, ip, user_agent, request_body, before_state, after_state, diff, created_at
+		FROM admin_audit_logs ` + where + fmt.Sprintf(`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	rows, err := r.db.QueryContext(ctx, query, append(args, params.Limit(), params.Offset())...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.AdminAuditLog, 0, params.Limit())
+	for rows.Next() {
+		var (
+			item                             service.AdminAuditLog
+			resourceID                       sql.NullInt64
+			requestBody, before, after, diff []byte
+		)
+		if err := rows.Scan(
+			&item.ID, &item.ActorUserID, &item.ActorEmail, &item.AuthMethod, &item.Method, &item.Path,
+			&item.Resource, &resourceID, &item.StatusCode, &item.IP, &item.UserAgent,
+			&requestBody, &before, &after, &diff, &item.CreatedAt,
+		); err != nil {
+			return nil, nil, err
+		}
+		item.ResourceID = nullInt64Ptr(resourceID)
+		item.RequestBody = requestBody
+		item.Before = before
+		item.After = after
+		item.Diff = diff
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return out, paginationResultFromTotal(total, params), nil
+}
+
+// adminAuditJSONArg stores empty snapshots as SQL NULL rather than an empty JSON document.
+func adminAuditJSONArg(raw []byte) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
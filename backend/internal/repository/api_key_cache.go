@@ -16,6 +16,7 @@ const (
 	apiKeyRateLimitKeyPrefix   = "apikey:ratelimit:"
 	apiKeyRateLimitDuration    = 24 * time.Hour
 	apiKeyAuthCachePrefix      = "apikey:auth:"
+	apiKeyNoncePrefix          = "apikey:nonce:"
 	authCacheInvalidateChannel = "auth:cache:invalidate"
 )
 
@@ -28,11 +29,18 @@ func apiKeyAuthCacheKey(key string) string {
 	return fmt.Sprintf("%s%s", apiKeyAuthCachePrefix, key)
 }
 
+func apiKeyNonceKey(keyID int64, nonce string) string {
+	return fmt.Sprintf("%s%d:%s", apiKeyNoncePrefix, keyID, nonce)
+}
+
 type apiKeyCache struct {
 	rdb *redis.Client
 }
 
 func NewAPIKeyCache(rdb *redis.Client) service.APIKeyCache {
+	if rdb == nil {
+		return nil
+	}
 	return &apiKeyCache{rdb: rdb}
 }
 
@@ -59,6 +67,10 @@ func (c *apiKeyCache) DeleteCreateAttemptCount(ctx context.Context, userID int64
 	return c.rdb.Del(ctx, key).Err()
 }
 
+func (c *apiKeyCache) ReserveRequestNonce(ctx context.Context, keyID int64, nonce string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, apiKeyNonceKey(keyID, nonce), 1, ttl).Result()
+}
+
 func (c *apiKeyCache) IncrementDailyUsage(ctx context.Context, apiKey string) error {
 	return c.rdb.Incr(ctx, apiKey).Err()
 }
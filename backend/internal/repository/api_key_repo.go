@@ -50,7 +50,19 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 		SetNillableExpiresAt(key.ExpiresAt).
 		SetRateLimit5h(key.RateLimit5h).
 		SetRateLimit1d(key.RateLimit1d).
-		SetRateLimit7d(key.RateLimit7d)
+		SetRateLimit7d(key.RateLimit7d).
+		SetPriority(key.Priority).
+		SetModerationMode(key.ModerationMode).
+		SetContextAutoTrim(key.ContextAutoTrim).
+		SetMaxBodySize(key.MaxBodySize).
+		SetRequestCoalescing(key.RequestCoalescing).
+		SetTokenBucketBurst(key.TokenBucketBurst).
+		SetTokenBucketRefillRate(key.TokenBucketRefillRate).
+		SetMaxCostPerRequest(key.MaxCostPerRequest).
+		SetAccountTagSelector(key.AccountTagSelector).
+		SetAllowedPlatforms(nonNilStrings(key.AllowedPlatforms)).
+		SetParentID(key.ParentID).
+		SetAllowedModels(nonNilStrings(key.AllowedModels))
 
 	if len(key.IPWhitelist) > 0 {
 		builder.SetIPWhitelist(key.IPWhitelist)
@@ -58,6 +70,9 @@ func (r *apiKeyRepository) Create(ctx context.Context, key *service.APIKey) erro
 	if len(key.IPBlacklist) > 0 {
 		builder.SetIPBlacklist(key.IPBlacklist)
 	}
+	if len(key.Scopes) > 0 {
+		builder.SetScopes(key.Scopes)
+	}
 
 	created, err := builder.Save(ctx)
 	if err == nil {
@@ -128,6 +143,19 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 			apikey.FieldStatus,
 			apikey.FieldIPWhitelist,
 			apikey.FieldIPBlacklist,
+			apikey.FieldScopes,
+			apikey.FieldPriority,
+			apikey.FieldModerationMode,
+			apikey.FieldContextAutoTrim,
+			apikey.FieldMaxBodySize,
+			apikey.FieldParentID,
+			apikey.FieldRequestCoalescing,
+			apikey.FieldTokenBucketBurst,
+			apikey.FieldTokenBucketRefillRate,
+			apikey.FieldMaxCostPerRequest,
+			apikey.FieldAccountTagSelector,
+			apikey.FieldAllowedPlatforms,
+			apikey.FieldAllowedModels,
 			apikey.FieldQuota,
 			apikey.FieldQuotaUsed,
 			apikey.FieldExpiresAt,
@@ -153,6 +181,7 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				user.FieldLastLoginAt,
 				user.FieldLastActiveAt,
 				user.FieldRpmLimit,
+				user.FieldOrganizationID,
 			)
 		}).
 		WithGroup(func(q *dbent.GroupQuery) {
@@ -180,6 +209,16 @@ func (r *apiKeyRepository) GetByKeyForAuth(ctx context.Context, key string) (*se
 				group.FieldDefaultMappedModel,
 				group.FieldMessagesDispatchModelConfig,
 				group.FieldRpmLimit,
+				group.FieldPriority,
+				group.FieldRequestParamOverrides,
+				group.FieldSystemPrompt,
+				group.FieldSystemPromptMode,
+				group.FieldPiiRedactionMode,
+				group.FieldModerationEnabled,
+				group.FieldMaxBodySize,
+				group.FieldResponseHeaders,
+				group.FieldAccountTagSelector,
+				group.FieldOrganizationID,
 			)
 		}).
 		Only(ctx)
@@ -212,6 +251,17 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 		SetUsage5h(key.Usage5h).
 		SetUsage1d(key.Usage1d).
 		SetUsage7d(key.Usage7d).
+		SetPriority(key.Priority).
+		SetModerationMode(key.ModerationMode).
+		SetContextAutoTrim(key.ContextAutoTrim).
+		SetMaxBodySize(key.MaxBodySize).
+		SetRequestCoalescing(key.RequestCoalescing).
+		SetTokenBucketBurst(key.TokenBucketBurst).
+		SetTokenBucketRefillRate(key.TokenBucketRefillRate).
+		SetMaxCostPerRequest(key.MaxCostPerRequest).
+		SetAccountTagSelector(key.AccountTagSelector).
+		SetAllowedPlatforms(nonNilStrings(key.AllowedPlatforms)).
+		SetAllowedModels(nonNilStrings(key.AllowedModels)).
 		SetUpdatedAt(now)
 	if key.GroupID != nil {
 		builder.SetGroupID(*key.GroupID)
@@ -255,6 +305,13 @@ func (r *apiKeyRepository) Update(ctx context.Context, key *service.APIKey) erro
 		builder.ClearIPBlacklist()
 	}
 
+	// 权限范围
+	if len(key.Scopes) > 0 {
+		builder.SetScopes(key.Scopes)
+	} else {
+		builder.ClearScopes()
+	}
+
 	affected, err := builder.Save(ctx)
 	if err != nil {
 		return err
@@ -300,7 +357,8 @@ func (r *apiKeyRepository) Delete(ctx context.Context, id int64) error {
 }
 
 func (r *apiKeyRepository) ListByUserID(ctx context.Context, userID int64, params pagination.PaginationParams, filters service.APIKeyListFilters) ([]service.APIKey, *pagination.PaginationResult, error) {
-	q := r.activeQuery().Where(apikey.UserIDEQ(userID))
+	// 子令牌由父 Key 通过 /v1/tokens 管理，不出现在用户的 Key 列表中
+	q := r.activeQuery().Where(apikey.UserIDEQ(userID), apikey.ParentIDEQ(0))
 
 	// Apply filters
 	if filters.Search != "" {
@@ -360,6 +418,62 @@ func (r *apiKeyRepository) VerifyOwnership(ctx context.Context, userID int64, ap
 	return ids, nil
 }
 
+// ListByParentID 列出父 Key 签发的未删除子令牌，按创建时间倒序
+func (r *apiKeyRepository) ListByParentID(ctx context.Context, parentID int64) ([]service.APIKey, error) {
+	keys, err := r.activeQuery().
+		Where(apikey.ParentIDEQ(parentID)).
+		Order(dbent.Desc(apikey.FieldID)).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]service.APIKey, 0, len(keys))
+	for i := range keys {
+		out = append(out, *apiKeyEntityToService(keys[i]))
+	}
+	return out, nil
+}
+
+// ListKeysByParentKey 列出父 Key（按 key 查找）签发的未删除子令牌的 key，用于父 Key 变更时级联失效认证缓存
+func (r *apiKeyRepository) ListKeysByParentKey(ctx context.Context, parentKey string) ([]string, error) {
+	rows, err := r.sql.QueryContext(ctx, `
+		SELECT c.key
+		FROM api_keys c
+		JOIN api_keys p ON p.id = c.parent_id
+		WHERE p.key = $1 AND c.deleted_at IS NULL`, parentKey)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// ListExpiredChildTokens 列出已过期但尚未删除的子令牌，按 ID 升序分批返回
+func (r *apiKeyRepository) ListExpiredChildTokens(ctx context.Context, now time.Time, limit int) ([]service.APIKey, error) {
+	keys, err := r.activeQuery().
+		Where(apikey.ParentIDGT(0), apikey.ExpiresAtLTE(now)).
+		Order(dbent.Asc(apikey.FieldID)).
+		Limit(limit).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]service.APIKey, 0, len(keys))
+	for i := range keys {
+		out = append(out, *apiKeyEntityToService(keys[i]))
+	}
+	return out, nil
+}
+
 func (r *apiKeyRepository) CountByUserID(ctx context.Context, userID int64) (int64, error) {
 	count, err := r.activeQuery().Where(apikey.UserIDEQ(userID)).Count(ctx)
 	return int64(count), err
@@ -536,6 +650,56 @@ func (r *apiKeyRepository) IncrementQuotaUsedAndGetState(ctx context.Context, id
 	return state, nil
 }
 
+// ReserveQuota 在额度充足时原子地预留 amount（quota - quota_used >= amount），返回是否预留成功。
+// 预留后额度耗尽时同步标记为 quota_exhausted。
+func (r *apiKeyRepository) ReserveQuota(ctx context.Context, id int64, amount float64) (bool, error) {
+	query := `
+		UPDATE api_keys
+		SET
+			quota_used = quota_used + $1,
+			status = CASE
+				WHEN quota_used + $1 >= quota THEN $2
+				ELSE status
+			END,
+			updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL AND quota > 0 AND quota - quota_used >= $1
+		RETURNING id
+	`
+
+	var reservedID int64
+	if err := scanSingleRow(ctx, r.sql, query, []any{amount, service.StatusAPIKeyQuotaExhausted, id}, &reservedID); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteChildToken 软删除子令牌并返回其未使用的预算（quota - quota_used，最小为 0）。
+// 条件更新保证并发撤销与过期清理只有一方拿到预算，已删除时返回 ErrAPIKeyNotFound。
+func (r *apiKeyRepository) DeleteChildToken(ctx context.Context, id int64) (float64, error) {
+	tombstoneKey := fmt.Sprintf("__deleted__%d__%d", id, time.Now().UnixNano())
+	query := `
+		UPDATE api_keys
+		SET
+			key = $1,
+			deleted_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $2 AND parent_id > 0 AND deleted_at IS NULL
+		RETURNING GREATEST(quota - quota_used, 0)
+	`
+
+	var unused float64
+	if err := scanSingleRow(ctx, r.sql, query, []any{tombstoneKey, id}, &unused); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, service.ErrAPIKeyNotFound
+		}
+		return 0, err
+	}
+	return unused, nil
+}
+
 func (r *apiKeyRepository) UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error {
 	affected, err := r.client.APIKey.Update().
 		Where(apikey.IDEQ(id), apikey.DeletedAtIsNil()).
@@ -609,34 +773,56 @@ func (r *apiKeyRepository) GetRateLimitData(ctx context.Context, id int64) (resu
 	return data, rows.Err()
 }
 
+// apiKeyAllowedPlatforms 未限制时写入空数组而非 JSON null
+// nonNilStrings 将 nil 切片转为空切片，NOT NULL 的 JSON 列写入 [] 而非 null
+func nonNilStrings(v []string) []string {
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
+
 func apiKeyEntityToService(m *dbent.APIKey) *service.APIKey {
 	if m == nil {
 		return nil
 	}
 	out := &service.APIKey{
-		ID:            m.ID,
-		UserID:        m.UserID,
-		Key:           m.Key,
-		Name:          m.Name,
-		Status:        m.Status,
-		IPWhitelist:   m.IPWhitelist,
-		IPBlacklist:   m.IPBlacklist,
-		LastUsedAt:    m.LastUsedAt,
-		CreatedAt:     m.CreatedAt,
-		UpdatedAt:     m.UpdatedAt,
-		GroupID:       m.GroupID,
-		Quota:         m.Quota,
-		QuotaUsed:     m.QuotaUsed,
-		ExpiresAt:     m.ExpiresAt,
-		RateLimit5h:   m.RateLimit5h,
-		RateLimit1d:   m.RateLimit1d,
-		RateLimit7d:   m.RateLimit7d,
-		Usage5h:       m.Usage5h,
-		Usage1d:       m.Usage1d,
-		Usage7d:       m.Usage7d,
-		Window5hStart: m.Window5hStart,
-		Window1dStart: m.Window1dStart,
-		Window7dStart: m.Window7dStart,
+		ID:                    m.ID,
+		UserID:                m.UserID,
+		Key:                   m.Key,
+		Name:                  m.Name,
+		Status:                m.Status,
+		IPWhitelist:           m.IPWhitelist,
+		IPBlacklist:           m.IPBlacklist,
+		Scopes:                m.Scopes,
+		Priority:              m.Priority,
+		ModerationMode:        m.ModerationMode,
+		ContextAutoTrim:       m.ContextAutoTrim,
+		MaxBodySize:           m.MaxBodySize,
+		ParentID:              m.ParentID,
+		RequestCoalescing:     m.RequestCoalescing,
+		TokenBucketBurst:      m.TokenBucketBurst,
+		TokenBucketRefillRate: m.TokenBucketRefillRate,
+		MaxCostPerRequest:     m.MaxCostPerRequest,
+		AccountTagSelector:    m.AccountTagSelector,
+		AllowedPlatforms:      m.AllowedPlatforms,
+		AllowedModels:         m.AllowedModels,
+		LastUsedAt:            m.LastUsedAt,
+		CreatedAt:             m.CreatedAt,
+		UpdatedAt:             m.UpdatedAt,
+		GroupID:               m.GroupID,
+		Quota:                 m.Quota,
+		QuotaUsed:             m.QuotaUsed,
+		ExpiresAt:             m.ExpiresAt,
+		RateLimit5h:           m.RateLimit5h,
+		RateLimit1d:           m.RateLimit1d,
+		RateLimit7d:           m.RateLimit7d,
+		Usage5h:               m.Usage5h,
+		Usage1d:               m.Usage1d,
+		Usage7d:               m.Usage7d,
+		Window5hStart:         m.Window5hStart,
+		Window1dStart:         m.Window1dStart,
+		Window7dStart:         m.Window7dStart,
 	}
 	if m.Edges.User != nil {
 		out.User = userEntityToService(m.Edges.User)
@@ -672,6 +858,8 @@ func userEntityToService(u *dbent.User) *service.User {
 		BalanceNotifyThreshold:     u.BalanceNotifyThreshold,
 		TotalRecharged:             u.TotalRecharged,
 		RPMLimit:                   u.RpmLimit,
+		OrganizationID:             u.OrganizationID,
+		OrgRole:                    u.OrgRole,
 		CreatedAt:                  u.CreatedAt,
 		UpdatedAt:                  u.UpdatedAt,
 	}
@@ -717,6 +905,16 @@ func groupEntityToService(g *dbent.Group) *service.Group {
 		DefaultMappedModel:              g.DefaultMappedModel,
 		MessagesDispatchModelConfig:     g.MessagesDispatchModelConfig,
 		RPMLimit:                        g.RpmLimit,
+		Priority:                        g.Priority,
+		RequestParamOverrides:           g.RequestParamOverrides,
+		SystemPrompt:                    g.SystemPrompt,
+		SystemPromptMode:                g.SystemPromptMode,
+		PIIRedactionMode:                g.PiiRedactionMode,
+		ModerationEnabled:               g.ModerationEnabled,
+		MaxBodySize:                     g.MaxBodySize,
+		ResponseHeaders:                 g.ResponseHeaders,
+		AccountTagSelector:              g.AccountTagSelector,
+		OrganizationID:                  g.OrganizationID,
 		CreatedAt:                       g.CreatedAt,
 		UpdatedAt:                       g.UpdatedAt,
 	}
@@ -502,6 +502,64 @@ func (s *APIKeyRepoSuite) TestIncrementQuotaUsedAndGetState() {
 	s.Require().Equal(service.StatusAPIKeyQuotaExhausted, got.Status)
 }
 
+func (s *APIKeyRepoSuite) TestReserveQuota() {
+	user := s.mustCreateUser("reserve-quota@test.com")
+	key := s.mustCreateApiKey(user.ID, "sk-reserve-quota", "Reserve", nil)
+	key.Quota = 5
+	key.QuotaUsed = 1
+	s.Require().NoError(s.repo.Update(s.ctx, key), "Update quota")
+
+	ok, err := s.repo.ReserveQuota(s.ctx, key.ID, 5)
+	s.Require().NoError(err, "ReserveQuota over remaining")
+	s.Require().False(ok, "超出剩余额度时不应预留")
+
+	ok, err = s.repo.ReserveQuota(s.ctx, key.ID, 4)
+	s.Require().NoError(err, "ReserveQuota")
+	s.Require().True(ok)
+
+	got, err := s.repo.GetByID(s.ctx, key.ID)
+	s.Require().NoError(err, "GetByID")
+	s.Require().Equal(5.0, got.QuotaUsed)
+	s.Require().Equal(service.StatusAPIKeyQuotaExhausted, got.Status)
+}
+
+func (s *APIKeyRepoSuite) TestDeleteChildToken_ReturnsUnusedBudget() {
+	user := s.mustCreateUser("delete-child@test.com")
+	parent := s.mustCreateApiKey(user.ID, "sk-delete-child-parent", "Parent", nil)
+	expiresAt := time.Now().Add(-time.Minute)
+	child := &service.APIKey{
+		UserID:    user.ID,
+		Key:       "sk-delete-child",
+		Name:      "Child",
+		Status:    service.StatusActive,
+		ParentID:  parent.ID,
+		Quota:     3,
+		ExpiresAt: &expiresAt,
+	}
+	s.Require().NoError(s.repo.Create(s.ctx, child), "create child token")
+	_, err := s.repo.IncrementQuotaUsed(s.ctx, child.ID, 1)
+	s.Require().NoError(err, "IncrementQuotaUsed")
+
+	expired, err := s.repo.ListExpiredChildTokens(s.ctx, time.Now(), 10)
+	s.Require().NoError(err, "ListExpiredChildTokens")
+	s.Require().Len(expired, 1)
+	s.Require().Equal(child.ID, expired[0].ID)
+
+	keys, err := s.repo.ListKeysByParentKey(s.ctx, parent.Key)
+	s.Require().NoError(err, "ListKeysByParentKey")
+	s.Require().Equal([]string{child.Key}, keys)
+
+	unused, err := s.repo.DeleteChildToken(s.ctx, child.ID)
+	s.Require().NoError(err, "DeleteChildToken")
+	s.Require().Equal(2.0, unused)
+
+	_, err = s.repo.DeleteChildToken(s.ctx, child.ID)
+	s.Require().ErrorIs(err, service.ErrAPIKeyNotFound, "重复删除不应再次返回预算")
+
+	_, err = s.repo.DeleteChildToken(s.ctx, parent.ID)
+	s.Require().ErrorIs(err, service.ErrAPIKeyNotFound, "普通 Key 不应被当作子令牌删除")
+}
+
 // TestIncrementQuotaUsed_Concurrent 使用真实数据库验证并发原子性。
 // 注意：此测试使用 testEntClient（非事务隔离），数据会真正写入数据库。
 func TestIncrementQuotaUsed_Concurrent(t *testing.T) {
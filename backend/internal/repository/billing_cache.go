@@ -139,6 +139,9 @@ type billingCache struct {
 }
 
 func NewBillingCache(rdb *redis.Client) service.BillingCache {
+	if rdb == nil {
+		return nil
+	}
 	return &billingCache{rdb: rdb}
 }
 
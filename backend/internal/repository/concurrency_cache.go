@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/redis/go-redis/v9"
@@ -31,6 +32,8 @@ const (
 	waitQueueKeyPrefix = "concurrency:wait:"
 	// 账号级等待队列计数器格式: wait:account:{accountID}
 	accountWaitKeyPrefix = "wait:account:"
+	// 优先级等待队列（有序集合）格式: concurrency:prio_wait:{scope}:{id}:{rank}
+	priorityWaitKeyPrefix = "concurrency:prio_wait:"
 
 	// 默认槽位过期时间（分钟），可通过配置覆盖
 	defaultSlotTTLMinutes = 15
@@ -165,6 +168,34 @@ var (
 		return 1
 	`)
 
+	// addPriorityWaiterScript 登记优先级等待者
+	// KEYS[1] = 优先级等待队列键
+	// ARGV[1] = requestID
+	// ARGV[2] = TTL（秒）
+	addPriorityWaiterScript = redis.NewScript(`
+		local timeResult = redis.call('TIME')
+		local now = tonumber(timeResult[1])
+		redis.call('ZADD', KEYS[1], now, ARGV[1])
+		redis.call('EXPIRE', KEYS[1], tonumber(ARGV[2]))
+		return 1
+	`)
+
+	// hasHigherPriorityWaitersScript 清理过期等待者后，判断任一更高优先级队列是否非空
+	// KEYS = 所有更高优先级的等待队列键
+	// ARGV[1] = TTL（秒）
+	hasHigherPriorityWaitersScript = redis.NewScript(`
+		local ttl = tonumber(ARGV[1])
+		local timeResult = redis.call('TIME')
+		local expireBefore = tonumber(timeResult[1]) - ttl
+		for i = 1, #KEYS do
+			redis.call('ZREMRANGEBYSCORE', KEYS[i], '-inf', expireBefore)
+			if redis.call('ZCARD', KEYS[i]) > 0 then
+				return 1
+			end
+		end
+		return 0
+	`)
+
 	// startupCleanupScript 清理非当前进程前缀的槽位成员。
 	// KEYS 是有序集合键列表，ARGV[1] 是当前进程前缀，ARGV[2] 是槽位 TTL。
 	// 遍历每个 KEYS[i]，移除前缀不匹配的成员，清空后删 key，否则刷新 EXPIRE。
@@ -200,6 +231,9 @@ type concurrencyCache struct {
 // slotTTLMinutes: 槽位过期时间（分钟），0 或负数使用默认值 15 分钟
 // waitQueueTTLSeconds: 等待队列过期时间（秒），0 或负数使用 slot TTL
 func NewConcurrencyCache(rdb *redis.Client, slotTTLMinutes int, waitQueueTTLSeconds int) service.ConcurrencyCache {
+	if rdb == nil {
+		return nil
+	}
 	if slotTTLMinutes <= 0 {
 		slotTTLMinutes = defaultSlotTTLMinutes
 	}
@@ -230,6 +264,10 @@ func accountWaitKey(accountID int64) string {
 	return fmt.Sprintf("%s%d", accountWaitKeyPrefix, accountID)
 }
 
+func priorityWaitKey(scope string, id int64, rank int) string {
+	return fmt.Sprintf("%s%s:%d:%d", priorityWaitKeyPrefix, scope, id, rank)
+}
+
 // Account slot operations
 
 func (c *concurrencyCache) AcquireAccountSlot(ctx context.Context, accountID int64, maxConcurrency int, requestID string) (bool, error) {
@@ -367,6 +405,32 @@ func (c *concurrencyCache) GetAccountWaitingCount(ctx context.Context, accountID
 	return val, nil
 }
 
+// 优先级等待队列
+
+func (c *concurrencyCache) AddPriorityWaiter(ctx context.Context, scope string, id int64, rank int, requestID string) error {
+	key := priorityWaitKey(scope, id, rank)
+	return addPriorityWaiterScript.Run(ctx, c.rdb, []string{key}, requestID, c.waitQueueTTLSeconds).Err()
+}
+
+func (c *concurrencyCache) RemovePriorityWaiter(ctx context.Context, scope string, id int64, rank int, requestID string) error {
+	return c.rdb.ZRem(ctx, priorityWaitKey(scope, id, rank), requestID).Err()
+}
+
+func (c *concurrencyCache) HasHigherPriorityWaiters(ctx context.Context, scope string, id int64, rank int) (bool, error) {
+	if rank <= 0 {
+		return false, nil
+	}
+	keys := make([]string, 0, rank)
+	for r := 0; r < rank; r++ {
+		keys = append(keys, priorityWaitKey(scope, id, r))
+	}
+	result, err := hasHigherPriorityWaitersScript.Run(ctx, c.rdb, keys, c.waitQueueTTLSeconds).Int()
+	if err != nil {
+		return false, err
+	}
+	return result == 1, nil
+}
+
 func (c *concurrencyCache) GetAccountsLoadBatch(ctx context.Context, accounts []service.AccountWithConcurrency) (map[int64]*service.AccountLoadInfo, error) {
 	if len(accounts) == 0 {
 		return map[int64]*service.AccountLoadInfo{}, nil
@@ -499,8 +563,8 @@ func (c *concurrencyCache) CleanupStaleProcessSlots(ctx context.Context, activeR
 		return nil
 	}
 
-	// 1. 清理有序集合中非当前进程前缀的成员
-	slotPatterns := []string{accountSlotKeyPrefix + "*", userSlotKeyPrefix + "*"}
+	// 1. 清理有序集合（槽位与优先级等待队列）中非当前进程前缀的成员
+	slotPatterns := []string{accountSlotKeyPrefix + "*", userSlotKeyPrefix + "*", priorityWaitKeyPrefix + "*"}
 	for _, pattern := range slotPatterns {
 		if err := c.cleanupSlotsByPattern(ctx, pattern, activeRequestPrefix); err != nil {
 			return err
@@ -562,3 +626,83 @@ func (c *concurrencyCache) deleteKeysByPattern(ctx context.Context, pattern stri
 	}
 	return nil
 }
+
+// ListSlotOccupancy 扫描所有账号 / 用户槽位键，返回未过期槽位数与最早槽位的获取时间（只读，不清理）。
+func (c *concurrencyCache) ListSlotOccupancy(ctx context.Context) ([]service.SlotOccupancy, error) {
+	now, err := c.rdb.Time(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis TIME: %w", err)
+	}
+	minScore := strconv.FormatInt(now.Unix()-int64(c.slotTTLSeconds), 10)
+
+	result := make([]service.SlotOccupancy, 0)
+	scopes := []struct {
+		scope  string
+		prefix string
+	}{
+		{scope: service.SlotScopeAccount, prefix: accountSlotKeyPrefix},
+		{scope: service.SlotScopeUser, prefix: userSlotKeyPrefix},
+	}
+	for _, sc := range scopes {
+		const scanCount = 200
+		var cursor uint64
+		for {
+			keys, nextCursor, err := c.rdb.Scan(ctx, cursor, sc.prefix+"*", scanCount).Result()
+			if err != nil {
+				return nil, fmt.Errorf("scan %s: %w", sc.prefix, err)
+			}
+			occupancy, err := c.readSlotOccupancy(ctx, sc.scope, sc.prefix, keys, minScore)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, occupancy...)
+			cursor = nextCursor
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (c *concurrencyCache) readSlotOccupancy(ctx context.Context, scope, prefix string, keys []string, minScore string) ([]service.SlotOccupancy, error) {
+	type slotCmd struct {
+		ownerID  int64
+		countCmd *redis.IntCmd
+		oldest   *redis.ZSliceCmd
+	}
+	pipe := c.rdb.Pipeline()
+	cmds := make([]slotCmd, 0, len(keys))
+	for _, key := range keys {
+		ownerID, err := strconv.ParseInt(key[len(prefix):], 10, 64)
+		if err != nil {
+			continue
+		}
+		cmds = append(cmds, slotCmd{
+			ownerID:  ownerID,
+			countCmd: pipe.ZCount(ctx, key, minScore, "+inf"),
+			oldest:   pipe.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: minScore, Max: "+inf", Count: 1}),
+		})
+	}
+	if len(cmds) == 0 {
+		return nil, nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("pipeline exec: %w", err)
+	}
+
+	result := make([]service.SlotOccupancy, 0, len(cmds))
+	for _, cmd := range cmds {
+		count := int(cmd.countCmd.Val())
+		if count == 0 {
+			continue
+		}
+		entry := service.SlotOccupancy{Scope: scope, OwnerID: cmd.ownerID, Count: count}
+		if oldest := cmd.oldest.Val(); len(oldest) > 0 {
+			acquiredAt := time.Unix(int64(oldest[0].Score), 0).UTC()
+			entry.OldestAcquiredAt = &acquiredAt
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
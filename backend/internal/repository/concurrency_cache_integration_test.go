@@ -251,6 +251,38 @@ func (s *ConcurrencyCacheSuite) TestAccountWaitQueue_IncrementAndDecrement() {
 	require.Equal(s.T(), 1, val, "expected account wait count 1")
 }
 
+func (s *ConcurrencyCacheSuite) TestPriorityWaiters() {
+	userID := int64(40)
+	scope := service.PriorityWaitScopeUser
+
+	has, err := s.cache.HasHigherPriorityWaiters(s.ctx, scope, userID, 2)
+	require.NoError(s.T(), err, "HasHigherPriorityWaiters empty")
+	require.False(s.T(), has)
+
+	require.NoError(s.T(), s.cache.AddPriorityWaiter(s.ctx, scope, userID, 1, "req-normal"), "AddPriorityWaiter normal")
+
+	has, err = s.cache.HasHigherPriorityWaiters(s.ctx, scope, userID, 2)
+	require.NoError(s.T(), err)
+	require.True(s.T(), has, "low priority should see normal waiter")
+
+	has, err = s.cache.HasHigherPriorityWaiters(s.ctx, scope, userID, 1)
+	require.NoError(s.T(), err)
+	require.False(s.T(), has, "normal priority should not yield to itself")
+
+	has, err = s.cache.HasHigherPriorityWaiters(s.ctx, scope, userID, 0)
+	require.NoError(s.T(), err)
+	require.False(s.T(), has, "high priority never yields")
+
+	ttl, err := s.rdb.TTL(s.ctx, priorityWaitKey(scope, userID, 1)).Result()
+	require.NoError(s.T(), err, "TTL priority wait key")
+	s.AssertTTLWithin(ttl, 1*time.Second, testSlotTTL)
+
+	require.NoError(s.T(), s.cache.RemovePriorityWaiter(s.ctx, scope, userID, 1, "req-normal"), "RemovePriorityWaiter")
+	has, err = s.cache.HasHigherPriorityWaiters(s.ctx, scope, userID, 2)
+	require.NoError(s.T(), err)
+	require.False(s.T(), has, "expected no waiters after removal")
+}
+
 func (s *ConcurrencyCacheSuite) TestCleanupStaleProcessSlots() {
 	accountID := int64(901)
 	userID := int64(902)
@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// memoryConcurrencyCache 进程内并发控制缓存（concurrency.backend=memory）
+//
+// 与 Redis 实现保持相同语义：槽位按获取时间过期、等待计数在每次递增时刷新 TTL、
+// 优先级等待者按加入时间过期。状态仅在当前进程内可见，多副本部署时各副本独立计数，
+// 适用于不部署 Redis 的单实例场景。
+type memoryConcurrencyCache struct {
+	mu      sync.Mutex
+	slotTTL time.Duration
+	waitTTL time.Duration
+
+	// slots 键与 Redis 实现一致（concurrency:account:{id} / concurrency:user:{id}），成员为 requestID
+	slots map[string]map[string]time.Time
+	// waits 等待队列计数（concurrency:wait:{userID} / wait:account:{accountID}）
+	waits map[string]*memoryWaitCounter
+	// priorityWaiters 优先级等待队列（concurrency:prio_wait:{scope}:{id}:{rank}）
+	priorityWaiters map[string]map[string]time.Time
+
+	lastSweep time.Time
+	nowFn     func() time.Time
+}
+
+type memoryWaitCounter struct {
+	count     int
+	expiresAt time.Time
+}
+
+// NewMemoryConcurrencyCache 创建进程内并发控制缓存，TTL 参数含义与 NewConcurrencyCache 相同
+func NewMemoryConcurrencyCache(slotTTLMinutes int, waitQueueTTLSeconds int) service.ConcurrencyCache {
+	if slotTTLMinutes <= 0 {
+		slotTTLMinutes = defaultSlotTTLMinutes
+	}
+	if waitQueueTTLSeconds <= 0 {
+		waitQueueTTLSeconds = slotTTLMinutes * 60
+	}
+	return &memoryConcurrencyCache{
+		slotTTL:         time.Duration(slotTTLMinutes) * time.Minute,
+		waitTTL:         time.Duration(waitQueueTTLSeconds) * time.Second,
+		slots:           make(map[string]map[string]time.Time),
+		waits:           make(map[string]*memoryWaitCounter),
+		priorityWaiters: make(map[string]map[string]time.Time),
+		nowFn:           time.Now,
+	}
+}
+
+// sweepLocked 周期性清理所有过期条目，避免不再访问的键常驻内存
+func (c *memoryConcurrencyCache) sweepLocked(now time.Time) {
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = now
+	for key := range c.slots {
+		c.liveSlotsLocked(key, now)
+	}
+	for key, counter := range c.waits {
+		if !now.Before(counter.expiresAt) {
+			delete(c.waits, key)
+		}
+	}
+	for key, members := range c.priorityWaiters {
+		for member, addedAt := range members {
+			if now.Sub(addedAt) >= c.waitTTL {
+				delete(members, member)
+			}
+		}
+		if len(members) == 0 {
+			delete(c.priorityWaiters, key)
+		}
+	}
+}
+
+// liveSlotsLocked 清理过期槽位并返回剩余槽位（空集合会被删除并返回 nil）
+func (c *memoryConcurrencyCache) liveSlotsLocked(key string, now time.Time) map[string]time.Time {
+	members := c.slots[key]
+	for member, acquiredAt := range members {
+		if now.Sub(acquiredAt) >= c.slotTTL {
+			delete(members, member)
+		}
+	}
+	if len(members) == 0 {
+		delete(c.slots, key)
+		return nil
+	}
+	return members
+}
+
+func (c *memoryConcurrencyCache) acquire(key string, maxConcurrency int, requestID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowFn()
+	c.sweepLocked(now)
+
+	members := c.liveSlotsLocked(key, now)
+	if _, exists := members[requestID]; exists {
+		members[requestID] = now
+		return true
+	}
+	if len(members) >= maxConcurrency {
+		return false
+	}
+	if members == nil {
+		members = make(map[string]time.Time)
+		c.slots[key] = members
+	}
+	members[requestID] = now
+	return true
+}
+
+func (c *memoryConcurrencyCache) release(key string, requestID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if members, ok := c.slots[key]; ok {
+		delete(members, requestID)
+		if len(members) == 0 {
+			delete(c.slots, key)
+		}
+	}
+}
+
+func (c *memoryConcurrencyCache) count(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.liveSlotsLocked(key, c.nowFn()))
+}
+
+func (c *memoryConcurrencyCache) waitingLocked(key string, now time.Time) int {
+	counter, ok := c.waits[key]
+	if !ok {
+		return 0
+	}
+	if !now.Before(counter.expiresAt) {
+		delete(c.waits, key)
+		return 0
+	}
+	return counter.count
+}
+
+func (c *memoryConcurrencyCache) incrementWait(key string, maxWait int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowFn()
+	current := c.waitingLocked(key, now)
+	if current >= maxWait {
+		return false
+	}
+	c.waits[key] = &memoryWaitCounter{count: current + 1, expiresAt: now.Add(c.waitTTL)}
+	return true
+}
+
+func (c *memoryConcurrencyCache) decrementWait(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.waitingLocked(key, c.nowFn()) > 0 {
+		c.waits[key].count--
+	}
+}
+
+// Account slot operations
+
+func (c *memoryConcurrencyCache) AcquireAccountSlot(_ context.Context, accountID int64, maxConcurrency int, requestID string) (bool, error) {
+	return c.acquire(accountSlotKey(accountID), maxConcurrency, requestID), nil
+}
+
+func (c *memoryConcurrencyCache) ReleaseAccountSlot(_ context.Context, accountID int64, requestID string) error {
+	c.release(accountSlotKey(accountID), requestID)
+	return nil
+}
+
+func (c *memoryConcurrencyCache) GetAccountConcurrency(_ context.Context, accountID int64) (int, error) {
+	return c.count(accountSlotKey(accountID)), nil
+}
+
+func (c *memoryConcurrencyCache) GetAccountConcurrencyBatch(_ context.Context, accountIDs []int64) (map[int64]int, error) {
+	result := make(map[int64]int, len(accountIDs))
+	for _, accountID := range accountIDs {
+		result[accountID] = c.count(accountSlotKey(accountID))
+	}
+	return result, nil
+}
+
+// Account wait queue operations
+
+func (c *memoryConcurrencyCache) IncrementAccountWaitCount(_ context.Context, accountID int64, maxWait int) (bool, error) {
+	return c.incrementWait(accountWaitKey(accountID), maxWait), nil
+}
+
+func (c *memoryConcurrencyCache) DecrementAccountWaitCount(_ context.Context, accountID int64) error {
+	c.decrementWait(accountWaitKey(accountID))
+	return nil
+}
+
+func (c *memoryConcurrencyCache) GetAccountWaitingCount(_ context.Context, accountID int64) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.waitingLocked(accountWaitKey(accountID), c.nowFn()), nil
+}
+
+// User slot operations
+
+func (c *memoryConcurrencyCache) AcquireUserSlot(_ context.Context, userID int64, maxConcurrency int, requestID string) (bool, error) {
+	return c.acquire(userSlotKey(userID), maxConcurrency, requestID), nil
+}
+
+func (c *memoryConcurrencyCache) ReleaseUserSlot(_ context.Context, userID int64, requestID string) error {
+	c.release(userSlotKey(userID), requestID)
+	return nil
+}
+
+func (c *memoryConcurrencyCache) GetUserConcurrency(_ context.Context, userID int64) (int, error) {
+	return c.count(userSlotKey(userID)), nil
+}
+
+// Wait queue operations
+
+func (c *memoryConcurrencyCache) IncrementWaitCount(_ context.Context, userID int64, maxWait int) (bool, error) {
+	return c.incrementWait(waitQueueKey(userID), maxWait), nil
+}
+
+func (c *memoryConcurrencyCache) DecrementWaitCount(_ context.Context, userID int64) error {
+	c.decrementWait(waitQueueKey(userID))
+	return nil
+}
+
+// 优先级等待队列
+
+func (c *memoryConcurrencyCache) AddPriorityWaiter(_ context.Context, scope string, id int64, rank int, requestID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := priorityWaitKey(scope, id, rank)
+	members := c.priorityWaiters[key]
+	if members == nil {
+		members = make(map[string]time.Time)
+		c.priorityWaiters[key] = members
+	}
+	members[requestID] = c.nowFn()
+	return nil
+}
+
+func (c *memoryConcurrencyCache) RemovePriorityWaiter(_ context.Context, scope string, id int64, rank int, requestID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := priorityWaitKey(scope, id, rank)
+	if members, ok := c.priorityWaiters[key]; ok {
+		delete(members, requestID)
+		if len(members) == 0 {
+			delete(c.priorityWaiters, key)
+		}
+	}
+	return nil
+}
+
+func (c *memoryConcurrencyCache) HasHigherPriorityWaiters(_ context.Context, scope string, id int64, rank int) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowFn()
+	for r := 0; r < rank; r++ {
+		for _, addedAt := range c.priorityWaiters[priorityWaitKey(scope, id, r)] {
+			if now.Sub(addedAt) < c.waitTTL {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// 批量负载查询
+
+func (c *memoryConcurrencyCache) GetAccountsLoadBatch(_ context.Context, accounts []service.AccountWithConcurrency) (map[int64]*service.AccountLoadInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowFn()
+	loadMap := make(map[int64]*service.AccountLoadInfo, len(accounts))
+	for _, acc := range accounts {
+		current := len(c.liveSlotsLocked(accountSlotKey(acc.ID), now))
+		waiting := c.waitingLocked(accountWaitKey(acc.ID), now)
+		loadRate := 0
+		if acc.MaxConcurrency > 0 {
+			loadRate = (current + waiting) * 100 / acc.MaxConcurrency
+		}
+		loadMap[acc.ID] = &service.AccountLoadInfo{
+			AccountID:          acc.ID,
+			CurrentConcurrency: current,
+			WaitingCount:       waiting,
+			LoadRate:           loadRate,
+		}
+	}
+	return loadMap, nil
+}
+
+func (c *memoryConcurrencyCache) GetUsersLoadBatch(_ context.Context, users []service.UserWithConcurrency) (map[int64]*service.UserLoadInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowFn()
+	loadMap := make(map[int64]*service.UserLoadInfo, len(users))
+	for _, u := range users {
+		current := len(c.liveSlotsLocked(userSlotKey(u.ID), now))
+		waiting := c.waitingLocked(waitQueueKey(u.ID), now)
+		loadRate := 0
+		if u.MaxConcurrency > 0 {
+			loadRate = (current + waiting) * 100 / u.MaxConcurrency
+		}
+		loadMap[u.ID] = &service.UserLoadInfo{
+			UserID:             u.ID,
+			CurrentConcurrency: current,
+			WaitingCount:       waiting,
+			LoadRate:           loadRate,
+		}
+	}
+	return loadMap, nil
+}
+
+func (c *memoryConcurrencyCache) CleanupExpiredAccountSlots(_ context.Context, accountID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowFn()
+	c.liveSlotsLocked(accountSlotKey(accountID), now)
+	c.sweepLocked(now)
+	return nil
+}
+
+// CleanupStaleProcessSlots 进程内状态随进程重启清空，无需清理
+func (c *memoryConcurrencyCache) CleanupStaleProcessSlots(context.Context, string) error {
+	return nil
+}
+
+func (c *memoryConcurrencyCache) ListSlotOccupancy(context.Context) ([]service.SlotOccupancy, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowFn()
+	result := make([]service.SlotOccupancy, 0, len(c.slots))
+	for key := range c.slots {
+		members := c.liveSlotsLocked(key, now)
+		if len(members) == 0 {
+			continue
+		}
+		scope, prefix := service.SlotScopeAccount, accountSlotKeyPrefix
+		if strings.HasPrefix(key, userSlotKeyPrefix) {
+			scope, prefix = service.SlotScopeUser, userSlotKeyPrefix
+		}
+		ownerID, err := strconv.ParseInt(strings.TrimPrefix(key, prefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		entry := service.SlotOccupancy{Scope: scope, OwnerID: ownerID, Count: len(members)}
+		for _, acquiredAt := range members {
+			if entry.OldestAcquiredAt == nil || acquiredAt.Before(*entry.OldestAcquiredAt) {
+				oldest := acquiredAt.UTC()
+				entry.OldestAcquiredAt = &oldest
+			}
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
@@ -0,0 +1,197 @@
+//go:build unit
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMemoryConcurrencyCache(now *time.Time) *memoryConcurrencyCache {
+	cache := NewMemoryConcurrencyCache(1, 30).(*memoryConcurrencyCache)
+	cache.nowFn = func() time.Time { return *now }
+	return cache
+}
+
+func TestMemoryConcurrencyCache_AccountSlots(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	cache := newTestMemoryConcurrencyCache(&now)
+
+	ok, err := cache.AcquireAccountSlot(ctx, 1, 2, "req-1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, _ = cache.AcquireAccountSlot(ctx, 1, 2, "req-2")
+	require.True(t, ok)
+	ok, _ = cache.AcquireAccountSlot(ctx, 1, 2, "req-3")
+	require.False(t, ok, "slot limit reached")
+
+	// 已持有的 requestID 重复获取只刷新时间，不占用新槽位
+	ok, _ = cache.AcquireAccountSlot(ctx, 1, 2, "req-1")
+	require.True(t, ok)
+
+	count, _ := cache.GetAccountConcurrency(ctx, 1)
+	require.Equal(t, 2, count)
+
+	require.NoError(t, cache.ReleaseAccountSlot(ctx, 1, "req-2"))
+	counts, _ := cache.GetAccountConcurrencyBatch(ctx, []int64{1, 2})
+	require.Equal(t, map[int64]int{1: 1, 2: 0}, counts)
+
+	// 超过槽位 TTL 后自动过期
+	now = now.Add(2 * time.Minute)
+	count, _ = cache.GetAccountConcurrency(ctx, 1)
+	require.Zero(t, count)
+	require.Empty(t, cache.slots)
+}
+
+func TestMemoryConcurrencyCache_WaitCountsAndLoad(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	cache := newTestMemoryConcurrencyCache(&now)
+
+	ok, _ := cache.IncrementAccountWaitCount(ctx, 5, 1)
+	require.True(t, ok)
+	ok, _ = cache.IncrementAccountWaitCount(ctx, 5, 1)
+	require.False(t, ok, "max wait reached")
+
+	_, _ = cache.AcquireAccountSlot(ctx, 5, 4, "req-1")
+	loads, err := cache.GetAccountsLoadBatch(ctx, []service.AccountWithConcurrency{{ID: 5, MaxConcurrency: 4}})
+	require.NoError(t, err)
+	require.Equal(t, 1, loads[5].CurrentConcurrency)
+	require.Equal(t, 1, loads[5].WaitingCount)
+	require.Equal(t, 50, loads[5].LoadRate)
+
+	require.NoError(t, cache.DecrementAccountWaitCount(ctx, 5))
+	require.NoError(t, cache.DecrementAccountWaitCount(ctx, 5))
+	waiting, _ := cache.GetAccountWaitingCount(ctx, 5)
+	require.Zero(t, waiting, "decrement floors at zero")
+
+	ok, _ = cache.IncrementWaitCount(ctx, 9, 3)
+	require.True(t, ok)
+	now = now.Add(31 * time.Second)
+	users, _ := cache.GetUsersLoadBatch(ctx, []service.UserWithConcurrency{{ID: 9, MaxConcurrency: 1}})
+	require.Zero(t, users[9].WaitingCount, "wait counter expires after TTL")
+}
+
+func TestMemoryConcurrencyCache_PriorityWaitersAndOccupancy(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	cache := newTestMemoryConcurrencyCache(&now)
+
+	require.NoError(t, cache.AddPriorityWaiter(ctx, "account", 3, 0, "req-a"))
+	higher, _ := cache.HasHigherPriorityWaiters(ctx, "account", 3, 1)
+	require.True(t, higher)
+	higher, _ = cache.HasHigherPriorityWaiters(ctx, "account", 3, 0)
+	require.False(t, higher)
+	require.NoError(t, cache.RemovePriorityWaiter(ctx, "account", 3, 0, "req-a"))
+	higher, _ = cache.HasHigherPriorityWaiters(ctx, "account", 3, 1)
+	require.False(t, higher)
+
+	_, _ = cache.AcquireAccountSlot(ctx, 3, 5, "req-1")
+	now = now.Add(10 * time.Second)
+	_, _ = cache.AcquireAccountSlot(ctx, 3, 5, "req-2")
+	_, _ = cache.AcquireUserSlot(ctx, 8, 5, "req-1")
+
+	occupancy, err := cache.ListSlotOccupancy(ctx)
+	require.NoError(t, err)
+	require.Len(t, occupancy, 2)
+	for _, entry := range occupancy {
+		if entry.Scope == service.SlotScopeAccount {
+			require.Equal(t, int64(3), entry.OwnerID)
+			require.Equal(t, 2, entry.Count)
+			require.Equal(t, time.Unix(1_700_000_000, 0).UTC(), *entry.OldestAcquiredAt)
+		} else {
+			require.Equal(t, service.SlotScopeUser, entry.Scope)
+			require.Equal(t, int64(8), entry.OwnerID)
+		}
+	}
+}
+
+func TestMemoryGatewayCache_StickySession(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	cache := NewMemoryGatewayCache().(*memoryGatewayCache)
+	cache.nowFn = func() time.Time { return now }
+
+	_, err := cache.GetSessionAccountID(ctx, 1, "hash")
+	require.True(t, errors.Is(err, redis.Nil))
+
+	require.NoError(t, cache.SetSessionAccountID(ctx, 1, "hash", 42, time.Minute))
+	accountID, err := cache.GetSessionAccountID(ctx, 1, "hash")
+	require.NoError(t, err)
+	require.Equal(t, int64(42), accountID)
+	_, err = cache.GetSessionAccountID(ctx, 2, "hash")
+	require.True(t, errors.Is(err, redis.Nil), "sessions are isolated per group")
+
+	now = now.Add(50 * time.Second)
+	require.NoError(t, cache.RefreshSessionTTL(ctx, 1, "hash", time.Minute))
+	now = now.Add(50 * time.Second)
+	_, err = cache.GetSessionAccountID(ctx, 1, "hash")
+	require.NoError(t, err, "refresh extends expiry")
+
+	now = now.Add(time.Minute)
+	_, err = cache.GetSessionAccountID(ctx, 1, "hash")
+	require.True(t, errors.Is(err, redis.Nil))
+
+	require.NoError(t, cache.SetSessionAccountID(ctx, 1, "hash", 7, time.Minute))
+	require.NoError(t, cache.DeleteSessionAccountID(ctx, 1, "hash"))
+	_, err = cache.GetSessionAccountID(ctx, 1, "hash")
+	require.True(t, errors.Is(err, redis.Nil))
+}
+
+func TestMemoryIdentityCache_FingerprintAndMaskedSession(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	cache := NewMemoryIdentityCache().(*memoryIdentityCache)
+	cache.nowFn = func() time.Time { return now }
+
+	_, err := cache.GetFingerprint(ctx, 1)
+	require.True(t, errors.Is(err, redis.Nil))
+
+	fp := &service.Fingerprint{ClientID: "client-1", UserAgent: "claude-cli/2.0.0"}
+	require.NoError(t, cache.SetFingerprint(ctx, 1, fp))
+	fp.ClientID = "mutated"
+	got, err := cache.GetFingerprint(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "client-1", got.ClientID, "stored fingerprint is a copy")
+
+	sessionID, err := cache.GetMaskedSessionID(ctx, 1)
+	require.NoError(t, err)
+	require.Empty(t, sessionID)
+	require.NoError(t, cache.SetMaskedSessionID(ctx, 1, "session-1"))
+	sessionID, _ = cache.GetMaskedSessionID(ctx, 1)
+	require.Equal(t, "session-1", sessionID)
+
+	now = now.Add(maskedSessionTTL)
+	sessionID, _ = cache.GetMaskedSessionID(ctx, 1)
+	require.Empty(t, sessionID)
+	_, err = cache.GetFingerprint(ctx, 1)
+	require.NoError(t, err, "fingerprint outlives masked session")
+
+	now = now.Add(fingerprintTTL)
+	_, err = cache.GetFingerprint(ctx, 1)
+	require.True(t, errors.Is(err, redis.Nil))
+}
+
+func TestMemoryBackend_DoesNotConnectRedis(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Concurrency.Backend = config.ConcurrencyBackendMemory
+
+	rdb := ProvideRedis(cfg)
+	require.Nil(t, rdb)
+	require.Nil(t, NewAPIKeyCache(rdb))
+	require.Nil(t, NewBillingCache(rdb))
+	require.Nil(t, NewEmailCache(rdb))
+	require.Nil(t, ProvideSchedulerCache(rdb, cfg))
+	require.Nil(t, ProvideSessionLimitCache(rdb, cfg))
+	require.IsType(t, &memoryConcurrencyCache{}, ProvideConcurrencyCache(rdb, cfg))
+	require.IsType(t, &memoryGatewayCache{}, ProvideGatewayCache(rdb, cfg))
+	require.IsType(t, &memoryIdentityCache{}, ProvideIdentityCache(rdb, cfg))
+}
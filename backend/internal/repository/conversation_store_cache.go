@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const conversationStorePrefix = "conversation:"
+
+type conversationStoreCache struct {
+	rdb *redis.Client
+}
+
+// NewConversationStoreCache 创建服务端会话历史缓存
+func NewConversationStoreCache(rdb *redis.Client) service.ConversationStoreCache {
+	if rdb == nil {
+		return nil
+	}
+	return &conversationStoreCache{rdb: rdb}
+}
+
+func (c *conversationStoreCache) GetConversation(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.rdb.Get(ctx, conversationStorePrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (c *conversationStoreCache) SetConversation(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return c.rdb.Set(ctx, conversationStorePrefix+key, data, ttl).Err()
+}
@@ -96,6 +96,13 @@ func (r *dashboardAggregationRepository) aggregateRangeInTx(ctx context.Context,
 	if err := r.upsertDailyAggregates(ctx, dayStart, dayEnd); err != nil {
 		return err
 	}
+	if err := r.upsertHourlySummaries(ctx, hourStart, hourEnd); err != nil {
+		return err
+	}
+	// 增量聚合时维度日汇总由小时汇总累加，避免每轮扫描当天全部原始日志。
+	if err := r.upsertDailySummariesFromHourly(ctx, dayStart, dayEnd); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -152,6 +159,21 @@ func (r *dashboardAggregationRepository) recomputeRangeInTx(ctx context.Context,
 	if _, err := r.sql.ExecContext(ctx, "DELETE FROM usage_dashboard_daily_users WHERE bucket_date >= $1::date AND bucket_date < $2::date", dayStart, dayEnd); err != nil {
 		return err
 	}
+	// 维度汇总只清理仍有原始日志的区间：早于最早原始日志的部分已被保留策略清理，汇总是唯一数据来源。
+	if _, err := r.sql.ExecContext(ctx, `
+		DELETE FROM usage_hourly_summaries
+		WHERE bucket_start >= $1 AND bucket_start < $2
+			AND bucket_start >= (SELECT date_trunc('hour', MIN(created_at) AT TIME ZONE $3) AT TIME ZONE $3 FROM usage_logs)
+	`, hourStart, hourEnd, timezone.Name()); err != nil {
+		return err
+	}
+	if _, err := r.sql.ExecContext(ctx, `
+		DELETE FROM usage_daily_summaries
+		WHERE bucket_date >= $1::date AND bucket_date < $2::date
+			AND bucket_date >= (SELECT (MIN(created_at) AT TIME ZONE $3)::date FROM usage_logs)
+	`, dayStart, dayEnd, timezone.Name()); err != nil {
+		return err
+	}
 
 	if err := r.insertHourlyActiveUsers(ctx, hourStart, hourEnd); err != nil {
 		return err
@@ -165,6 +187,12 @@ func (r *dashboardAggregationRepository) recomputeRangeInTx(ctx context.Context,
 	if err := r.upsertDailyAggregates(ctx, dayStart, dayEnd); err != nil {
 		return err
 	}
+	if err := r.upsertHourlySummaries(ctx, hourStart, hourEnd); err != nil {
+		return err
+	}
+	if err := r.upsertDailySummaries(ctx, dayStart, dayEnd); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -200,6 +228,9 @@ func (r *dashboardAggregationRepository) CleanupAggregates(ctx context.Context,
 	if _, err := r.sql.ExecContext(ctx, "DELETE FROM usage_dashboard_hourly_users WHERE bucket_start < $1", hourlyCutoffUTC); err != nil {
 		return err
 	}
+	if _, err := r.sql.ExecContext(ctx, "DELETE FROM usage_hourly_summaries WHERE bucket_start < $1", hourlyCutoffUTC); err != nil {
+		return err
+	}
 	if _, err := r.sql.ExecContext(ctx, "DELETE FROM usage_dashboard_daily WHERE bucket_date < $1::date", dailyCutoffUTC); err != nil {
 		return err
 	}
@@ -209,7 +240,50 @@ func (r *dashboardAggregationRepository) CleanupAggregates(ctx context.Context,
 	return nil
 }
 
+// CleanupUsageSummaries 清理超过保留窗口的维度日汇总。
+func (r *dashboardAggregationRepository) CleanupUsageSummaries(ctx context.Context, cutoff time.Time) error {
+	_, err := r.sql.ExecContext(ctx, "DELETE FROM usage_daily_summaries WHERE bucket_date < $1::date", cutoff.UTC())
+	return err
+}
+
+// HasUsageSummaries 维度小时汇总表是否已有数据（用于判断升级后是否需要一次性回填）。
+// 小时汇总晚于日汇总引入，以其为准可让已有日汇总的实例同样补齐小时汇总。
+func (r *dashboardAggregationRepository) HasUsageSummaries(ctx context.Context) (bool, error) {
+	var exists bool
+	if err := scanSingleRow(ctx, r.sql, "SELECT EXISTS(SELECT 1 FROM usage_hourly_summaries)", nil, &exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// SummarizeUsageRange 按整天重算 [start, end) 覆盖日期的维度小时 / 日汇总（幂等）。
+func (r *dashboardAggregationRepository) SummarizeUsageRange(ctx context.Context, start, end time.Time) error {
+	if r == nil || r.sql == nil {
+		return nil
+	}
+	loc := timezone.Location()
+	dayStart := truncateToDay(start.In(loc))
+	dayEnd := truncateToDay(end.In(loc))
+	if end.In(loc).After(dayEnd) {
+		dayEnd = dayEnd.Add(24 * time.Hour)
+	}
+	if !dayEnd.After(dayStart) {
+		return nil
+	}
+	if err := r.upsertHourlySummaries(ctx, dayStart, dayEnd); err != nil {
+		return err
+	}
+	return r.upsertDailySummaries(ctx, dayStart, dayEnd)
+}
+
+// CleanupUsageLogs 先把 cutoff 之前的原始日志归档到维度日汇总，再删除原始日志。
+// cutoff 向下对齐到应用时区的整天，保证被汇总的日期原始数据完整。
 func (r *dashboardAggregationRepository) CleanupUsageLogs(ctx context.Context, cutoff time.Time) error {
+	cutoff = truncateToDay(cutoff.In(timezone.Location()))
+	if err := r.archiveUsageLogsBefore(ctx, cutoff); err != nil {
+		return fmt.Errorf("archive usage logs before cleanup: %w", err)
+	}
+
 	isPartitioned, err := r.isUsageLogsPartitioned(ctx)
 	if err != nil {
 		return err
@@ -241,6 +315,26 @@ func (r *dashboardAggregationRepository) CleanupUsageLogs(ctx context.Context, c
 	}
 }
 
+// archiveUsageLogsBefore 汇总 cutoff 之前仍存在的原始日志（按天分批，避免单条语句扫描过大范围）。
+func (r *dashboardAggregationRepository) archiveUsageLogsBefore(ctx context.Context, cutoff time.Time) error {
+	var earliest sql.NullTime
+	if err := scanSingleRow(ctx, r.sql, "SELECT MIN(created_at) FROM usage_logs WHERE created_at < $1", []any{cutoff.UTC()}, &earliest); err != nil {
+		return err
+	}
+	if !earliest.Valid {
+		return nil
+	}
+	day := truncateToDay(earliest.Time.In(cutoff.Location()))
+	for day.Before(cutoff) {
+		next := day.Add(24 * time.Hour)
+		if err := r.upsertDailySummaries(ctx, day, next); err != nil {
+			return err
+		}
+		day = next
+	}
+	return nil
+}
+
 func (r *dashboardAggregationRepository) CleanupUsageBillingDedup(ctx context.Context, cutoff time.Time) error {
 	for {
 		res, err := r.sql.ExecContext(ctx, `
@@ -462,6 +556,159 @@ func (r *dashboardAggregationRepository) upsertDailyAggregates(ctx context.Conte
 	return err
 }
 
+// upsertDailySummaries 从原始日志重算 [start, end) 内各日期的维度汇总；start/end 须为整天边界。
+func (r *dashboardAggregationRepository) upsertDailySummaries(ctx context.Context, start, end time.Time) error {
+	return r.upsertUsageSummaries(ctx, "usage_daily_summaries", "bucket_date", "(created_at AT TIME ZONE $3)::date", start, end)
+}
+
+// upsertHourlySummaries 从原始日志重算 [start, end) 内各小时的维度汇总；start/end 须为整点边界。
+func (r *dashboardAggregationRepository) upsertHourlySummaries(ctx context.Context, start, end time.Time) error {
+	return r.upsertUsageSummaries(ctx, "usage_hourly_summaries", "bucket_start", "date_trunc('hour', created_at AT TIME ZONE $3) AT TIME ZONE $3", start, end)
+}
+
+// upsertUsageSummaries 按 bucketExpr 分桶，将原始日志汇总写入维度汇总表（table/bucketColumn 为内部常量）。
+func (r *dashboardAggregationRepository) upsertUsageSummaries(ctx context.Context, table, bucketColumn, bucketExpr string, start, end time.Time) error {
+	tzName := timezone.Name()
+	query := fmt.Sprintf(`
+		INSERT INTO %[5]s (
+			%[6]s,
+			user_id,
+			api_key_id,
+			account_id,
+			group_id,
+			model,
+			requested_model,
+			upstream_model,
+			request_type,
+			stream,
+			billing_type,
+			total_requests,
+			input_tokens,
+			output_tokens,
+			cache_creation_tokens,
+			cache_read_tokens,
+			total_cost,
+			actual_cost,
+			account_cost,
+			total_duration_ms,
+			computed_at
+		)
+		SELECT
+			%[7]s AS bucket,
+			user_id,
+			api_key_id,
+			account_id,
+			COALESCE(group_id, 0),
+			model,
+			COALESCE(NULLIF(TRIM(requested_model), ''), model),
+			COALESCE(NULLIF(TRIM(upstream_model), ''), NULLIF(TRIM(requested_model), ''), model),
+			CASE
+				WHEN request_type <> %[1]d THEN request_type
+				WHEN openai_ws_mode THEN %[2]d
+				WHEN stream THEN %[3]d
+				ELSE %[4]d
+			END,
+			stream,
+			billing_type,
+			COUNT(*),
+			COALESCE(SUM(input_tokens), 0),
+			COALESCE(SUM(output_tokens), 0),
+			COALESCE(SUM(cache_creation_tokens), 0),
+			COALESCE(SUM(cache_read_tokens), 0),
+			COALESCE(SUM(total_cost), 0),
+			COALESCE(SUM(actual_cost), 0),
+			COALESCE(SUM(COALESCE(account_stats_cost, total_cost) * COALESCE(account_rate_multiplier, 1)), 0),
+			COALESCE(SUM(COALESCE(duration_ms, 0)), 0),
+			NOW()
+		FROM usage_logs
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11
+		ON CONFLICT (%[6]s, user_id, api_key_id, account_id, group_id, model, requested_model, upstream_model, request_type, stream, billing_type)
+		DO UPDATE SET
+			total_requests = EXCLUDED.total_requests,
+			input_tokens = EXCLUDED.input_tokens,
+			output_tokens = EXCLUDED.output_tokens,
+			cache_creation_tokens = EXCLUDED.cache_creation_tokens,
+			cache_read_tokens = EXCLUDED.cache_read_tokens,
+			total_cost = EXCLUDED.total_cost,
+			actual_cost = EXCLUDED.actual_cost,
+			account_cost = EXCLUDED.account_cost,
+			total_duration_ms = EXCLUDED.total_duration_ms,
+			computed_at = EXCLUDED.computed_at
+	`, int16(service.RequestTypeUnknown), int16(service.RequestTypeWSV2), int16(service.RequestTypeStream), int16(service.RequestTypeSync),
+		table, bucketColumn, bucketExpr)
+	_, err := r.sql.ExecContext(ctx, query, start, end, tzName)
+	return err
+}
+
+// upsertDailySummariesFromHourly 由维度小时汇总累加出 [start, end) 内各日期的维度日汇总。
+func (r *dashboardAggregationRepository) upsertDailySummariesFromHourly(ctx context.Context, start, end time.Time) error {
+	query := `
+		INSERT INTO usage_daily_summaries (
+			bucket_date,
+			user_id,
+			api_key_id,
+			account_id,
+			group_id,
+			model,
+			requested_model,
+			upstream_model,
+			request_type,
+			stream,
+			billing_type,
+			total_requests,
+			input_tokens,
+			output_tokens,
+			cache_creation_tokens,
+			cache_read_tokens,
+			total_cost,
+			actual_cost,
+			account_cost,
+			total_duration_ms,
+			computed_at
+		)
+		SELECT
+			(bucket_start AT TIME ZONE $3)::date AS bucket_date,
+			user_id,
+			api_key_id,
+			account_id,
+			group_id,
+			model,
+			requested_model,
+			upstream_model,
+			request_type,
+			stream,
+			billing_type,
+			SUM(total_requests),
+			SUM(input_tokens),
+			SUM(output_tokens),
+			SUM(cache_creation_tokens),
+			SUM(cache_read_tokens),
+			SUM(total_cost),
+			SUM(actual_cost),
+			SUM(account_cost),
+			SUM(total_duration_ms),
+			NOW()
+		FROM usage_hourly_summaries
+		WHERE bucket_start >= $1 AND bucket_start < $2
+		GROUP BY 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11
+		ON CONFLICT (bucket_date, user_id, api_key_id, account_id, group_id, model, requested_model, upstream_model, request_type, stream, billing_type)
+		DO UPDATE SET
+			total_requests = EXCLUDED.total_requests,
+			input_tokens = EXCLUDED.input_tokens,
+			output_tokens = EXCLUDED.output_tokens,
+			cache_creation_tokens = EXCLUDED.cache_creation_tokens,
+			cache_read_tokens = EXCLUDED.cache_read_tokens,
+			total_cost = EXCLUDED.total_cost,
+			actual_cost = EXCLUDED.actual_cost,
+			account_cost = EXCLUDED.account_cost,
+			total_duration_ms = EXCLUDED.total_duration_ms,
+			computed_at = EXCLUDED.computed_at
+	`
+	_, err := r.sql.ExecContext(ctx, query, start, end, timezone.Name())
+	return err
+}
+
 func (r *dashboardAggregationRepository) isUsageLogsPartitioned(ctx context.Context) (bool, error) {
 	query := `
 		SELECT EXISTS(
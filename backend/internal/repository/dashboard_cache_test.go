@@ -4,11 +4,15 @@ import (
 	"testing"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 )
 
 func TestNewDashboardCacheKeyPrefix(t *testing.T) {
-	cache := NewDashboardCache(nil, &config.Config{
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	cache := NewDashboardCache(rdb, &config.Config{
 		Dashboard: config.DashboardCacheConfig{
 			KeyPrefix: "prod",
 		},
@@ -17,7 +21,7 @@ func TestNewDashboardCacheKeyPrefix(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, "prod:", impl.keyPrefix)
 
-	cache = NewDashboardCache(nil, &config.Config{
+	cache = NewDashboardCache(rdb, &config.Config{
 		Dashboard: config.DashboardCacheConfig{
 			KeyPrefix: "staging:",
 		},
@@ -25,4 +29,6 @@ func TestNewDashboardCacheKeyPrefix(t *testing.T) {
 	impl, ok = cache.(*dashboardCache)
 	require.True(t, ok)
 	require.Equal(t, "staging:", impl.keyPrefix)
+
+	require.Nil(t, NewDashboardCache(nil, &config.Config{}))
 }
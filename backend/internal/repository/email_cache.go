@@ -47,6 +47,9 @@ type emailCache struct {
 }
 
 func NewEmailCache(rdb *redis.Client) service.EmailCache {
+	if rdb == nil {
+		return nil
+	}
 	return &emailCache{rdb: rdb}
 }
 
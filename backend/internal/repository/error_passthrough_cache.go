@@ -26,6 +26,9 @@ type errorPassthroughCache struct {
 
 // NewErrorPassthroughCache 创建错误透传规则缓存
 func NewErrorPassthroughCache(rdb *redis.Client) service.ErrorPassthroughCache {
+	if rdb == nil {
+		return nil
+	}
 	return &errorPassthroughCache{
 		rdb: rdb,
 	}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/ent"
 	"github.com/Wei-Shaw/sub2api/ent/errorpassthroughrule"
@@ -75,6 +76,9 @@ func (r *errorPassthroughRepository) Create(ctx context.Context, rule *model.Err
 	if rule.Description != nil {
 		builder.SetDescription(*rule.Description)
 	}
+	if rule.ExpiresAt != nil {
+		builder.SetExpiresAt(*rule.ExpiresAt)
+	}
 
 	created, err := builder.Save(ctx)
 	if err != nil {
@@ -125,6 +129,11 @@ func (r *errorPassthroughRepository) Update(ctx context.Context, rule *model.Err
 	} else {
 		builder.ClearDescription()
 	}
+	if rule.ExpiresAt != nil {
+		builder.SetExpiresAt(*rule.ExpiresAt)
+	} else {
+		builder.ClearExpiresAt()
+	}
 
 	updated, err := builder.Save(ctx)
 	if err != nil {
@@ -138,6 +147,42 @@ func (r *errorPassthroughRepository) Delete(ctx context.Context, id int64) error
 	return r.client.ErrorPassthroughRule.DeleteOneID(id).Exec(ctx)
 }
 
+// IncrementHits 累加规则命中次数，并在 lastHitAt 更新时刷新最近命中时间
+func (r *errorPassthroughRepository) IncrementHits(ctx context.Context, id int64, delta int64, lastHitAt time.Time) error {
+	if delta <= 0 {
+		return nil
+	}
+	if err := r.client.ErrorPassthroughRule.Update().
+		Where(errorpassthroughrule.IDEQ(id)).
+		AddHitCount(delta).
+		Exec(ctx); err != nil {
+		return err
+	}
+	// 多实例并发刷新时只保留更晚的命中时间
+	return r.client.ErrorPassthroughRule.Update().
+		Where(
+			errorpassthroughrule.IDEQ(id),
+			errorpassthroughrule.Or(
+				errorpassthroughrule.LastHitAtIsNil(),
+				errorpassthroughrule.LastHitAtLT(lastHitAt),
+			),
+		).
+		SetLastHitAt(lastHitAt).
+		Exec(ctx)
+}
+
+// DisableExpired 禁用已过期但仍处于启用状态的规则，返回禁用数量
+func (r *errorPassthroughRepository) DisableExpired(ctx context.Context, now time.Time) (int, error) {
+	return r.client.ErrorPassthroughRule.Update().
+		Where(
+			errorpassthroughrule.EnabledEQ(true),
+			errorpassthroughrule.ExpiresAtNotNil(),
+			errorpassthroughrule.ExpiresAtLTE(now),
+		).
+		SetEnabled(false).
+		Save(ctx)
+}
+
 // toModel 将 Ent 实体转换为服务模型
 func (r *errorPassthroughRepository) toModel(e *ent.ErrorPassthroughRule) *model.ErrorPassthroughRule {
 	rule := &model.ErrorPassthroughRule{
@@ -152,6 +197,9 @@ func (r *errorPassthroughRepository) toModel(e *ent.ErrorPassthroughRule) *model
 		PassthroughCode: e.PassthroughCode,
 		PassthroughBody: e.PassthroughBody,
 		SkipMonitoring:  e.SkipMonitoring,
+		ExpiresAt:       e.ExpiresAt,
+		HitCount:        e.HitCount,
+		LastHitAt:       e.LastHitAt,
 		CreatedAt:       e.CreatedAt,
 		UpdatedAt:       e.UpdatedAt,
 	}
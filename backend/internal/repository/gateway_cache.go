@@ -3,19 +3,29 @@ package repository
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/redis/go-redis/v9"
 )
 
-const stickySessionPrefix = "sticky_session:"
+const (
+	stickySessionPrefix = "sticky_session:"
+	// stickyStatePrefix 多副本共享的字符串粘性状态（如 Codex turn state）
+	stickyStatePrefix = "sticky_state:"
+	// stickyInvalidateChannel 粘性绑定变更通知频道，各副本据此清理本地热缓存
+	stickyInvalidateChannel = "sticky_session:invalidate"
+)
 
 type gatewayCache struct {
 	rdb *redis.Client
 }
 
 func NewGatewayCache(rdb *redis.Client) service.GatewayCache {
+	if rdb == nil {
+		return nil
+	}
 	return &gatewayCache{rdb: rdb}
 }
 
@@ -51,3 +61,53 @@ func (c *gatewayCache) DeleteSessionAccountID(ctx context.Context, groupID int64
 	key := buildSessionKey(groupID, sessionHash)
 	return c.rdb.Del(ctx, key).Err()
 }
+
+func (c *gatewayCache) GetStickyState(ctx context.Context, key string) (string, error) {
+	return c.rdb.Get(ctx, stickyStatePrefix+key).Result()
+}
+
+func (c *gatewayCache) SetStickyState(ctx context.Context, key, value string, ttl time.Duration) error {
+	return c.rdb.Set(ctx, stickyStatePrefix+key, value, ttl).Err()
+}
+
+func (c *gatewayCache) DeleteStickyState(ctx context.Context, key string) error {
+	return c.rdb.Del(ctx, stickyStatePrefix+key).Err()
+}
+
+// PublishStickyInvalidation 通知所有副本某个粘性绑定已变更
+func (c *gatewayCache) PublishStickyInvalidation(ctx context.Context, message string) error {
+	return c.rdb.Publish(ctx, stickyInvalidateChannel, message).Err()
+}
+
+// SubscribeStickyInvalidation 订阅粘性绑定变更通知，ctx 取消后退出
+func (c *gatewayCache) SubscribeStickyInvalidation(ctx context.Context, handler func(message string)) error {
+	pubsub := c.rdb.Subscribe(ctx, stickyInvalidateChannel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return fmt.Errorf("subscribe to sticky session invalidation: %w", err)
+	}
+
+	go func() {
+		defer func() {
+			if err := pubsub.Close(); err != nil {
+				log.Printf("Warning: failed to close sticky session invalidation pubsub: %v", err)
+			}
+		}()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				if msg != nil {
+					handler(msg.Payload)
+				}
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// memoryGatewayCache 进程内粘性会话缓存（concurrency.backend=memory）
+// 未命中时返回 redis.Nil，与 Redis 实现的调用方约定一致。
+type memoryGatewayCache struct {
+	mu        sync.Mutex
+	sessions  map[string]memorySessionEntry
+	lastSweep time.Time
+	nowFn     func() time.Time
+}
+
+type memorySessionEntry struct {
+	accountID int64
+	expiresAt time.Time // 零值表示不过期
+}
+
+func (e memorySessionEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+func NewMemoryGatewayCache() service.GatewayCache {
+	return &memoryGatewayCache{
+		sessions: make(map[string]memorySessionEntry),
+		nowFn:    time.Now,
+	}
+}
+
+func memorySessionExpiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+// sweepLocked 周期性清理过期会话，避免不再访问的键常驻内存
+func (c *memoryGatewayCache) sweepLocked(now time.Time) {
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = now
+	for key, entry := range c.sessions {
+		if entry.expired(now) {
+			delete(c.sessions, key)
+		}
+	}
+}
+
+func (c *memoryGatewayCache) GetSessionAccountID(_ context.Context, groupID int64, sessionHash string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := buildSessionKey(groupID, sessionHash)
+	entry, ok := c.sessions[key]
+	if !ok {
+		return 0, redis.Nil
+	}
+	if entry.expired(c.nowFn()) {
+		delete(c.sessions, key)
+		return 0, redis.Nil
+	}
+	return entry.accountID, nil
+}
+
+func (c *memoryGatewayCache) SetSessionAccountID(_ context.Context, groupID int64, sessionHash string, accountID int64, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowFn()
+	c.sweepLocked(now)
+	c.sessions[buildSessionKey(groupID, sessionHash)] = memorySessionEntry{
+		accountID: accountID,
+		expiresAt: memorySessionExpiry(now, ttl),
+	}
+	return nil
+}
+
+func (c *memoryGatewayCache) RefreshSessionTTL(_ context.Context, groupID int64, sessionHash string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.nowFn()
+	key := buildSessionKey(groupID, sessionHash)
+	entry, ok := c.sessions[key]
+	if !ok || entry.expired(now) {
+		return nil
+	}
+	entry.expiresAt = memorySessionExpiry(now, ttl)
+	c.sessions[key] = entry
+	return nil
+}
+
+func (c *memoryGatewayCache) DeleteSessionAccountID(_ context.Context, groupID int64, sessionHash string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sessions, buildSessionKey(groupID, sessionHash))
+	return nil
+}
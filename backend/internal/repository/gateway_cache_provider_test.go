@@ -0,0 +1,27 @@
+//go:build unit
+
+package repository
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsesMemoryStickySessionStore(t *testing.T) {
+	cfg := &config.Config{}
+	require.False(t, usesMemoryStickySessionStore(cfg))
+
+	// 留空时跟随 concurrency.backend
+	cfg.Concurrency.Backend = config.ConcurrencyBackendMemory
+	require.True(t, usesMemoryStickySessionStore(cfg))
+
+	// 显式配置优先：并发计数在本地，粘性会话仍共享
+	cfg.Gateway.StickySessionStore.Backend = config.ConcurrencyBackendRedis
+	require.False(t, usesMemoryStickySessionStore(cfg))
+
+	cfg.Concurrency.Backend = config.ConcurrencyBackendRedis
+	cfg.Gateway.StickySessionStore.Backend = "Memory"
+	require.True(t, usesMemoryStickySessionStore(cfg))
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const gatewayIdempotencyPrefix = "gateway_idempotency:"
+
+type gatewayIdempotencyCache struct {
+	rdb *redis.Client
+}
+
+// NewGatewayIdempotencyCache 创建网关 Idempotency-Key 响应缓存
+func NewGatewayIdempotencyCache(rdb *redis.Client) service.GatewayIdempotencyCache {
+	if rdb == nil {
+		return nil
+	}
+	return &gatewayIdempotencyCache{rdb: rdb}
+}
+
+func (c *gatewayIdempotencyCache) ClaimIdempotency(ctx context.Context, key string, data []byte, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, gatewayIdempotencyPrefix+key, data, ttl).Result()
+}
+
+func (c *gatewayIdempotencyCache) GetIdempotency(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.rdb.Get(ctx, gatewayIdempotencyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (c *gatewayIdempotencyCache) SetIdempotency(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return c.rdb.Set(ctx, gatewayIdempotencyPrefix+key, data, ttl).Err()
+}
+
+func (c *gatewayIdempotencyCache) DeleteIdempotency(ctx context.Context, key string) error {
+	return c.rdb.Del(ctx, gatewayIdempotencyPrefix+key).Err()
+}
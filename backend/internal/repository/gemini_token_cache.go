@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -15,11 +17,26 @@ const (
 	oauthRefreshLockKeyPrefix = "oauth:refresh_lock:"
 )
 
+// releaseRefreshLockScript 仅当锁仍由当前持有者持有时才删除，
+// 避免锁 TTL 过期后误删其他副本新获取的锁。
+var releaseRefreshLockScript = redis.NewScript(`
+	if redis.call('GET', KEYS[1]) == ARGV[1] then
+		return redis.call('DEL', KEYS[1])
+	end
+	return 0
+`)
+
 type geminiTokenCache struct {
 	rdb *redis.Client
+	// lockOwners 记录本进程持有的刷新锁令牌（cacheKey -> token）。
+	// 调用方在进程内已按 cacheKey 互斥，因此同一 cacheKey 同一时刻至多一条记录。
+	lockOwners sync.Map
 }
 
 func NewGeminiTokenCache(rdb *redis.Client) service.GeminiTokenCache {
+	if rdb == nil {
+		return nil
+	}
 	return &geminiTokenCache{rdb: rdb}
 }
 
@@ -40,10 +57,31 @@ func (c *geminiTokenCache) DeleteAccessToken(ctx context.Context, cacheKey strin
 
 func (c *geminiTokenCache) AcquireRefreshLock(ctx context.Context, cacheKey string, ttl time.Duration) (bool, error) {
 	key := fmt.Sprintf("%s%s", oauthRefreshLockKeyPrefix, cacheKey)
-	return c.rdb.SetNX(ctx, key, 1, ttl).Result()
+	token := uuid.NewString()
+	acquired, err := c.rdb.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !acquired {
+		return acquired, err
+	}
+	c.lockOwners.Store(cacheKey, token)
+	return true, nil
 }
 
 func (c *geminiTokenCache) ReleaseRefreshLock(ctx context.Context, cacheKey string) error {
 	key := fmt.Sprintf("%s%s", oauthRefreshLockKeyPrefix, cacheKey)
-	return c.rdb.Del(ctx, key).Err()
+	value, ok := c.lockOwners.LoadAndDelete(cacheKey)
+	if !ok {
+		// 本进程未持有该锁（或已释放），不能删除其他副本的锁
+		return nil
+	}
+	token, _ := value.(string)
+	return releaseRefreshLockScript.Run(ctx, c.rdb, []string{key}, token).Err()
+}
+
+func (c *geminiTokenCache) IsRefreshLockHeld(ctx context.Context, cacheKey string) (bool, error) {
+	key := fmt.Sprintf("%s%s", oauthRefreshLockKeyPrefix, cacheKey)
+	n, err := c.rdb.Exists(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
 }
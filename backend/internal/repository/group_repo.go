@@ -64,7 +64,22 @@ func (r *groupRepository) Create(ctx context.Context, groupIn *service.Group) er
 		SetRequirePrivacySet(groupIn.RequirePrivacySet).
 		SetDefaultMappedModel(groupIn.DefaultMappedModel).
 		SetMessagesDispatchModelConfig(groupIn.MessagesDispatchModelConfig).
-		SetRpmLimit(groupIn.RPMLimit)
+		SetRpmLimit(groupIn.RPMLimit).
+		SetRequestParamOverrides(groupIn.RequestParamOverrides).
+		SetSystemPrompt(groupIn.SystemPrompt).
+		SetPiiRedactionMode(groupIn.PIIRedactionMode).
+		SetModerationEnabled(groupIn.ModerationEnabled).
+		SetMaxBodySize(groupIn.MaxBodySize).
+		SetResponseHeaders(groupIn.ResponseHeaders).
+		SetAccountTagSelector(groupIn.AccountTagSelector).
+		SetNillableOrganizationID(groupIn.OrganizationID)
+
+	if groupIn.Priority != "" {
+		builder = builder.SetPriority(groupIn.Priority)
+	}
+	if groupIn.SystemPromptMode != "" {
+		builder = builder.SetSystemPromptMode(groupIn.SystemPromptMode)
+	}
 
 	// 设置模型路由配置
 	if groupIn.ModelRouting != nil {
@@ -132,7 +147,21 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		SetRequirePrivacySet(groupIn.RequirePrivacySet).
 		SetDefaultMappedModel(groupIn.DefaultMappedModel).
 		SetMessagesDispatchModelConfig(groupIn.MessagesDispatchModelConfig).
-		SetRpmLimit(groupIn.RPMLimit)
+		SetRpmLimit(groupIn.RPMLimit).
+		SetRequestParamOverrides(groupIn.RequestParamOverrides).
+		SetSystemPrompt(groupIn.SystemPrompt).
+		SetPiiRedactionMode(groupIn.PIIRedactionMode).
+		SetModerationEnabled(groupIn.ModerationEnabled).
+		SetMaxBodySize(groupIn.MaxBodySize).
+		SetResponseHeaders(groupIn.ResponseHeaders).
+		SetAccountTagSelector(groupIn.AccountTagSelector)
+
+	if groupIn.Priority != "" {
+		builder = builder.SetPriority(groupIn.Priority)
+	}
+	if groupIn.SystemPromptMode != "" {
+		builder = builder.SetSystemPromptMode(groupIn.SystemPromptMode)
+	}
 
 	// 显式处理可空字段：nil 需要 clear，非 nil 需要 set。
 	if groupIn.DailyLimitUSD != nil {
@@ -166,6 +195,12 @@ func (r *groupRepository) Update(ctx context.Context, groupIn *service.Group) er
 		builder = builder.ClearImagePrice4k()
 	}
 
+	if groupIn.OrganizationID != nil {
+		builder = builder.SetOrganizationID(*groupIn.OrganizationID)
+	} else {
+		builder = builder.ClearOrganizationID()
+	}
+
 	// 处理 FallbackGroupID：nil 时清除，否则设置
 	if groupIn.FallbackGroupID != nil {
 		builder = builder.SetFallbackGroupID(*groupIn.FallbackGroupID)
@@ -212,10 +247,10 @@ func (r *groupRepository) Delete(ctx context.Context, id int64) error {
 }
 
 func (r *groupRepository) List(ctx context.Context, params pagination.PaginationParams) ([]service.Group, *pagination.PaginationResult, error) {
-	return r.ListWithFilters(ctx, params, "", "", "", nil)
+	return r.ListWithFilters(ctx, params, "", "", "", nil, nil)
 }
 
-func (r *groupRepository) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, status, search string, isExclusive *bool) ([]service.Group, *pagination.PaginationResult, error) {
+func (r *groupRepository) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, status, search string, isExclusive *bool, organizationID *int64) ([]service.Group, *pagination.PaginationResult, error) {
 	q := r.client.Group.Query()
 
 	if platform != "" {
@@ -233,6 +268,9 @@ func (r *groupRepository) ListWithFilters(ctx context.Context, params pagination
 	if isExclusive != nil {
 		q = q.Where(group.IsExclusiveEQ(*isExclusive))
 	}
+	if organizationID != nil {
+		q = q.Where(group.OrganizationIDEQ(*organizationID))
+	}
 
 	total, err := q.Clone().Count(ctx)
 	if err != nil {
@@ -1,23 +1,24 @@
 package repository
 
 import (
-	"compress/flate"
-	"compress/gzip"
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/andybalholm/brotli"
-
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/proxyurl"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/proxyutil"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
@@ -56,11 +57,15 @@ var errUpstreamClientLimitReached = errors.New("upstream client cache limit reac
 // poolSettings 连接池配置参数
 // 封装 Transport 所需的各项连接池参数
 type poolSettings struct {
-	maxIdleConns          int           // 最大空闲连接总数
-	maxIdleConnsPerHost   int           // 每主机最大空闲连接数
-	maxConnsPerHost       int           // 每主机最大连接数（含活跃）
-	idleConnTimeout       time.Duration // 空闲连接超时时间
-	responseHeaderTimeout time.Duration // 等待响应头超时时间
+	maxIdleConns          int               // 最大空闲连接总数
+	maxIdleConnsPerHost   int               // 每主机最大空闲连接数
+	maxConnsPerHost       int               // 每主机最大连接数（含活跃）
+	idleConnTimeout       time.Duration     // 空闲连接超时时间
+	responseHeaderTimeout time.Duration     // 等待响应头超时时间
+	forceHTTP1            bool              // 禁用 HTTP/2 协商（账号级配置）
+	clientCert            *tls.Certificate  // mTLS 客户端证书（账号级配置）
+	hostMap               map[string]string // 静态域名映射，仅直连生效（账号级配置）
+	tlsServerName         string            // TLS SNI 覆盖（账号级配置）
 }
 
 // upstreamClientEntry 上游客户端缓存条目
@@ -71,6 +76,39 @@ type upstreamClientEntry struct {
 	poolKey  string       // 连接池配置标识（用于检测配置变更）
 	lastUsed int64        // 最后使用时间戳（纳秒），用于 LRU 淘汰
 	inFlight int64        // 当前进行中的请求数，>0 时不可淘汰
+
+	// 连接复用统计（通过 httptrace 采集，客户端回收时输出日志）
+	connNew    int64
+	connReused int64
+	trace      *httptrace.ClientTrace
+}
+
+// newUpstreamClientEntry 创建客户端条目并初始化连接复用统计
+func newUpstreamClientEntry(client *http.Client, cacheKey, proxyKey, poolKey string) *upstreamClientEntry {
+	entry := &upstreamClientEntry{
+		client:   client,
+		proxyKey: proxyKey,
+		poolKey:  poolKey,
+	}
+	entry.trace = &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				atomic.AddInt64(&entry.connReused, 1)
+				return
+			}
+			atomic.AddInt64(&entry.connNew, 1)
+			slog.Debug("upstream_conn_new", "cache_key", cacheKey, "pool_key", poolKey)
+		},
+	}
+	return entry
+}
+
+// withConnTrace 为请求挂载连接复用统计
+func (e *upstreamClientEntry) withConnTrace(req *http.Request) *http.Request {
+	if e == nil || e.trace == nil {
+		return req
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), e.trace))
 }
 
 // httpUpstreamService 通用 HTTP 上游服务
@@ -132,23 +170,31 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 		return nil, err
 	}
 
-	// 获取或创建对应的客户端，并标记请求占用
-	entry, err := s.acquireClient(proxyURL, accountID, accountConcurrency)
+	// 获取或创建对应的客户端，并标记请求占用（账号级连接池参数通过请求 context 传入）
+	transportOpts := service.UpstreamTransportOptionsFromContext(req.Context())
+	entry, err := s.acquireClient(proxyURL, accountID, accountConcurrency, transportOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	// 执行请求
-	resp, err := entry.client.Do(req)
+	// 透传的客户端 Accept-Encoding 仅保留可解码的编码，避免上游返回无法解析的压缩格式
+	httputil.SanitizeAcceptEncoding(req.Header)
+	s.setUpstreamRequestID(req)
+
+	// 执行请求（管理员调试转发时记录上游交互，Ops 记录 DNS/建连/TLS/首字节耗时）
+	capture := service.BeginUpstreamCapture(req)
+	resp, err := entry.client.Do(service.TraceOpsUpstreamTiming(entry.withConnTrace(req)))
 	if err != nil {
+		service.FinishUpstreamCapture(capture, nil, err)
 		// 请求失败，立即减少计数
 		atomic.AddInt64(&entry.inFlight, -1)
 		atomic.StoreInt64(&entry.lastUsed, time.Now().UnixNano())
 		return nil, err
 	}
 
-	// 如果上游返回了压缩内容，解压后再交给业务层
-	decompressResponseBody(resp)
+	// 如果上游返回了压缩内容，解压后再交给业务层（gjson 解析 / usage 提取均基于明文）
+	httputil.DecompressResponseBody(resp)
+	service.FinishUpstreamCapture(capture, resp, nil)
 
 	// 包装响应体，在关闭时自动减少计数并更新时间戳
 	// 这确保了流式响应（如 SSE）在完全读取前不会被淘汰
@@ -164,10 +210,17 @@ func (s *httpUpstreamService) Do(req *http.Request, proxyURL string, accountID i
 //
 // profile 为 nil 时不启用 TLS 指纹，行为与 Do 方法相同。
 // profile 非 nil 时使用指定的 Profile 进行 TLS 指纹伪装。
+// 账号配置了 mTLS 客户端证书或 SNI 覆盖时使用标准 TLS 握手，不做指纹伪装。
 func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile) (*http.Response, error) {
 	if profile == nil {
 		return s.Do(req, proxyURL, accountID, accountConcurrency)
 	}
+	if req != nil {
+		if opts := service.UpstreamTransportOptionsFromContext(req.Context()); opts.ClientCert != nil || opts.TLSServerName != "" {
+			slog.Debug("tls_fingerprint_skipped_for_custom_tls", "account_id", accountID)
+			return s.Do(req, proxyURL, accountID, accountConcurrency)
+		}
+	}
 
 	targetHost := ""
 	if req != nil && req.URL != nil {
@@ -183,21 +236,27 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 		return nil, err
 	}
 
-	entry, err := s.acquireClientWithTLS(proxyURL, accountID, accountConcurrency, profile)
+	transportOpts := service.UpstreamTransportOptionsFromContext(req.Context())
+	entry, err := s.acquireClientWithTLS(proxyURL, accountID, accountConcurrency, profile, transportOpts)
 	if err != nil {
 		slog.Debug("tls_fingerprint_acquire_client_failed", "account_id", accountID, "error", err)
 		return nil, err
 	}
 
-	resp, err := entry.client.Do(req)
+	httputil.SanitizeAcceptEncoding(req.Header)
+	s.setUpstreamRequestID(req)
+	capture := service.BeginUpstreamCapture(req)
+	resp, err := entry.client.Do(service.TraceOpsUpstreamTiming(entry.withConnTrace(req)))
 	if err != nil {
+		service.FinishUpstreamCapture(capture, nil, err)
 		atomic.AddInt64(&entry.inFlight, -1)
 		atomic.StoreInt64(&entry.lastUsed, time.Now().UnixNano())
 		slog.Debug("tls_fingerprint_request_failed", "account_id", accountID, "error", err)
 		return nil, err
 	}
 
-	decompressResponseBody(resp)
+	httputil.DecompressResponseBody(resp)
+	service.FinishUpstreamCapture(capture, resp, nil)
 
 	resp.Body = wrapTrackedBody(resp.Body, func() {
 		atomic.AddInt64(&entry.inFlight, -1)
@@ -208,21 +267,21 @@ func (s *httpUpstreamService) DoWithTLS(req *http.Request, proxyURL string, acco
 }
 
 // acquireClientWithTLS 获取或创建带 TLS 指纹的客户端
-func (s *httpUpstreamService) acquireClientWithTLS(proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile) (*upstreamClientEntry, error) {
-	return s.getClientEntryWithTLS(proxyURL, accountID, accountConcurrency, profile, true, true)
+func (s *httpUpstreamService) acquireClientWithTLS(proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile, opts service.UpstreamTransportOptions) (*upstreamClientEntry, error) {
+	return s.getClientEntryWithTLS(proxyURL, accountID, accountConcurrency, profile, opts, true, true)
 }
 
 // getClientEntryWithTLS 获取或创建带 TLS 指纹的客户端条目
 // TLS 指纹客户端使用独立的缓存键，与普通客户端隔离
-func (s *httpUpstreamService) getClientEntryWithTLS(proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile, markInFlight bool, enforceLimit bool) (*upstreamClientEntry, error) {
+func (s *httpUpstreamService) getClientEntryWithTLS(proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile, opts service.UpstreamTransportOptions, markInFlight bool, enforceLimit bool) (*upstreamClientEntry, error) {
 	isolation := s.getIsolationMode()
 	proxyKey, parsedProxy, err := normalizeProxyURL(proxyURL)
 	if err != nil {
 		return nil, err
 	}
 	// TLS 指纹客户端使用独立的缓存键，加 "tls:" 前缀
-	cacheKey := "tls:" + buildCacheKey(isolation, proxyKey, accountID)
-	poolKey := s.buildPoolKey(isolation, accountConcurrency) + ":tls"
+	cacheKey := "tls:" + buildCacheKey(effectiveIsolation(isolation, opts), proxyKey, accountID)
+	poolKey := s.buildPoolKey(isolation, accountConcurrency, opts) + ":tls"
 
 	now := time.Now()
 	nowUnix := now.UnixNano()
@@ -273,7 +332,7 @@ func (s *httpUpstreamService) getClientEntryWithTLS(proxyURL string, accountID i
 
 	// 创建带 TLS 指纹的 Transport
 	slog.Debug("tls_fingerprint_creating_new_client", "account_id", accountID, "cache_key", cacheKey, "proxy", proxyKey)
-	settings := s.resolvePoolSettings(isolation, accountConcurrency)
+	settings := s.resolvePoolSettings(isolation, accountConcurrency, opts)
 	transport, err := buildUpstreamTransportWithTLSFingerprint(settings, parsedProxy, profile)
 	if err != nil {
 		s.mu.Unlock()
@@ -285,11 +344,7 @@ func (s *httpUpstreamService) getClientEntryWithTLS(proxyURL string, accountID i
 		client.CheckRedirect = s.redirectChecker
 	}
 
-	entry := &upstreamClientEntry{
-		client:   client,
-		proxyKey: proxyKey,
-		poolKey:  poolKey,
-	}
+	entry := newUpstreamClientEntry(client, cacheKey, proxyKey, poolKey)
 	atomic.StoreInt64(&entry.lastUsed, nowUnix)
 	if markInFlight {
 		atomic.StoreInt64(&entry.inFlight, 1)
@@ -302,6 +357,20 @@ func (s *httpUpstreamService) getClientEntryWithTLS(proxyURL string, accountID i
 	return entry, nil
 }
 
+// setUpstreamRequestID 按 gateway.upstream_request_id_header 将网关请求 ID 透传给上游，便于与上游日志对账
+func (s *httpUpstreamService) setUpstreamRequestID(req *http.Request) {
+	if s.cfg == nil || req == nil {
+		return
+	}
+	header := strings.TrimSpace(s.cfg.Gateway.UpstreamRequestIDHeader)
+	if header == "" {
+		return
+	}
+	if requestID, _ := req.Context().Value(ctxkey.RequestID).(string); strings.TrimSpace(requestID) != "" {
+		req.Header.Set(header, strings.TrimSpace(requestID))
+	}
+}
+
 func (s *httpUpstreamService) shouldValidateResolvedIP() bool {
 	if s.cfg == nil {
 		return false
@@ -323,6 +392,10 @@ func (s *httpUpstreamService) validateRequestHost(req *http.Request) error {
 	if host == "" {
 		return errors.New("request host is empty")
 	}
+	// 静态域名映射的账号校验映射后的 IP
+	if ip, ok := service.UpstreamTransportOptionsFromContext(req.Context()).HostMap[strings.ToLower(host)]; ok {
+		host = ip
+	}
 	if err := urlvalidator.ValidateResolvedIP(host); err != nil {
 		return err
 	}
@@ -338,8 +411,8 @@ func (s *httpUpstreamService) redirectChecker(req *http.Request, via []*http.Req
 
 // acquireClient 获取或创建客户端，并标记为进行中请求
 // 用于请求路径，避免在获取后被淘汰
-func (s *httpUpstreamService) acquireClient(proxyURL string, accountID int64, accountConcurrency int) (*upstreamClientEntry, error) {
-	return s.getClientEntry(proxyURL, accountID, accountConcurrency, true, true)
+func (s *httpUpstreamService) acquireClient(proxyURL string, accountID int64, accountConcurrency int, opts service.UpstreamTransportOptions) (*upstreamClientEntry, error) {
+	return s.getClientEntry(proxyURL, accountID, accountConcurrency, opts, true, true)
 }
 
 // getOrCreateClient 获取或创建客户端
@@ -358,13 +431,13 @@ func (s *httpUpstreamService) acquireClient(proxyURL string, accountID int64, ac
 //   - account: 按账户隔离，同一账户共享客户端（代理变更时重建）
 //   - account_proxy: 按账户+代理组合隔离，最细粒度
 func (s *httpUpstreamService) getOrCreateClient(proxyURL string, accountID int64, accountConcurrency int) (*upstreamClientEntry, error) {
-	return s.getClientEntry(proxyURL, accountID, accountConcurrency, false, false)
+	return s.getClientEntry(proxyURL, accountID, accountConcurrency, service.UpstreamTransportOptions{}, false, false)
 }
 
 // getClientEntry 获取或创建客户端条目
 // markInFlight=true 时会标记进行中请求，用于请求路径防止被淘汰
 // enforceLimit=true 时会限制客户端数量，超限且无法淘汰时返回错误
-func (s *httpUpstreamService) getClientEntry(proxyURL string, accountID int64, accountConcurrency int, markInFlight bool, enforceLimit bool) (*upstreamClientEntry, error) {
+func (s *httpUpstreamService) getClientEntry(proxyURL string, accountID int64, accountConcurrency int, opts service.UpstreamTransportOptions, markInFlight bool, enforceLimit bool) (*upstreamClientEntry, error) {
 	// 获取隔离模式
 	isolation := s.getIsolationMode()
 	// 标准化代理 URL 并解析
@@ -373,9 +446,9 @@ func (s *httpUpstreamService) getClientEntry(proxyURL string, accountID int64, a
 		return nil, err
 	}
 	// 构建缓存键（根据隔离策略不同）
-	cacheKey := buildCacheKey(isolation, proxyKey, accountID)
+	cacheKey := buildCacheKey(effectiveIsolation(isolation, opts), proxyKey, accountID)
 	// 构建连接池配置键（用于检测配置变更）
-	poolKey := s.buildPoolKey(isolation, accountConcurrency)
+	poolKey := s.buildPoolKey(isolation, accountConcurrency, opts)
 
 	now := time.Now()
 	nowUnix := now.UnixNano()
@@ -418,7 +491,7 @@ func (s *httpUpstreamService) getClientEntry(proxyURL string, accountID int64, a
 	}
 
 	// 缓存未命中或需要重建，创建新客户端
-	settings := s.resolvePoolSettings(isolation, accountConcurrency)
+	settings := s.resolvePoolSettings(isolation, accountConcurrency, opts)
 	transport, err := buildUpstreamTransport(settings, parsedProxy)
 	if err != nil {
 		s.mu.Unlock()
@@ -428,11 +501,7 @@ func (s *httpUpstreamService) getClientEntry(proxyURL string, accountID int64, a
 	if s.shouldValidateResolvedIP() {
 		client.CheckRedirect = s.redirectChecker
 	}
-	entry := &upstreamClientEntry{
-		client:   client,
-		proxyKey: proxyKey,
-		poolKey:  poolKey,
-	}
+	entry := newUpstreamClientEntry(client, cacheKey, proxyKey, poolKey)
 	atomic.StoreInt64(&entry.lastUsed, nowUnix)
 	if markInFlight {
 		atomic.StoreInt64(&entry.inFlight, 1)
@@ -469,6 +538,7 @@ func (s *httpUpstreamService) shouldReuseEntry(entry *upstreamClientEntry, isola
 //   - entry: 客户端条目
 func (s *httpUpstreamService) removeClientLocked(key string, entry *upstreamClientEntry) {
 	delete(s.clients, key)
+	logConnReuseStats(key, entry)
 	if entry != nil && entry.client != nil {
 		// 关闭空闲连接，释放系统资源
 		// 注意：这不会中断活跃连接
@@ -597,6 +667,7 @@ func (s *httpUpstreamService) clientIdleTTL() time.Duration {
 // 参数:
 //   - isolation: 隔离模式
 //   - accountConcurrency: 账户并发限制
+//   - opts: 账号级连接池参数（零值表示不覆盖）
 //
 // 返回:
 //   - poolSettings: 连接池配置
@@ -604,7 +675,7 @@ func (s *httpUpstreamService) clientIdleTTL() time.Duration {
 // 说明:
 //   - 账户隔离模式下，连接池大小与账户并发数对应
 //   - 这确保了单账户不会占用过多连接资源
-func (s *httpUpstreamService) resolvePoolSettings(isolation string, accountConcurrency int) poolSettings {
+func (s *httpUpstreamService) resolvePoolSettings(isolation string, accountConcurrency int, opts service.UpstreamTransportOptions) poolSettings {
 	settings := defaultPoolSettings(s.cfg)
 	// 账户隔离模式下，根据账户并发数调整连接池大小
 	if (isolation == config.ConnectionPoolIsolationAccount || isolation == config.ConnectionPoolIsolationAccountProxy) && accountConcurrency > 0 {
@@ -612,6 +683,20 @@ func (s *httpUpstreamService) resolvePoolSettings(isolation string, accountConcu
 		settings.maxIdleConnsPerHost = accountConcurrency
 		settings.maxConnsPerHost = accountConcurrency
 	}
+	// 账号级参数优先于全局配置与并发数推导值
+	if opts.MaxIdleConns > 0 {
+		settings.maxIdleConnsPerHost = opts.MaxIdleConns
+		if settings.maxIdleConns < opts.MaxIdleConns {
+			settings.maxIdleConns = opts.MaxIdleConns
+		}
+	}
+	if opts.IdleConnTimeout > 0 {
+		settings.idleConnTimeout = opts.IdleConnTimeout
+	}
+	settings.forceHTTP1 = opts.ForceHTTP1
+	settings.clientCert = opts.ClientCert
+	settings.hostMap = opts.HostMap
+	settings.tlsServerName = opts.TLSServerName
 	return settings
 }
 
@@ -621,16 +706,50 @@ func (s *httpUpstreamService) resolvePoolSettings(isolation string, accountConcu
 // 参数:
 //   - isolation: 隔离模式
 //   - accountConcurrency: 账户并发限制
+//   - opts: 账号级连接池参数（零值表示不覆盖）
 //
 // 返回:
 //   - string: 配置键
-func (s *httpUpstreamService) buildPoolKey(isolation string, accountConcurrency int) string {
+func (s *httpUpstreamService) buildPoolKey(isolation string, accountConcurrency int, opts service.UpstreamTransportOptions) string {
+	key := "default"
 	if isolation == config.ConnectionPoolIsolationAccount || isolation == config.ConnectionPoolIsolationAccountProxy {
 		if accountConcurrency > 0 {
-			return fmt.Sprintf("account:%d", accountConcurrency)
+			key = fmt.Sprintf("account:%d", accountConcurrency)
 		}
 	}
-	return "default"
+	// 账号级连接池参数变更时需要重建客户端
+	if optsKey := opts.Key(); optsKey != "" {
+		key += "|" + optsKey
+	}
+	return key
+}
+
+// effectiveIsolation 返回实际使用的隔离模式
+// 账号配置了独立连接池参数时，proxy 模式下也按账号+代理隔离，避免与其他账号共享连接池
+func effectiveIsolation(isolation string, opts service.UpstreamTransportOptions) string {
+	if isolation == config.ConnectionPoolIsolationProxy && !opts.IsZero() {
+		return config.ConnectionPoolIsolationAccountProxy
+	}
+	return isolation
+}
+
+// logConnReuseStats 客户端回收时输出连接复用统计
+func logConnReuseStats(key string, entry *upstreamClientEntry) {
+	if entry == nil {
+		return
+	}
+	connNew := atomic.LoadInt64(&entry.connNew)
+	connReused := atomic.LoadInt64(&entry.connReused)
+	total := connNew + connReused
+	if total == 0 {
+		return
+	}
+	slog.Info("upstream_client_conn_reuse",
+		"cache_key", key,
+		"pool_key", entry.poolKey,
+		"conn_new", connNew,
+		"conn_reused", connReused,
+		"reuse_ratio", float64(connReused)/float64(total))
 }
 
 // buildCacheKey 构建客户端缓存键
@@ -759,6 +878,9 @@ func defaultPoolSettings(cfg *config.Config) poolSettings {
 //   - MaxConnsPerHost: 每主机最大连接数（达到后新请求等待）
 //   - IdleConnTimeout: 空闲连接超时（超时后关闭）
 //   - ResponseHeaderTimeout: 等待响应头超时（不影响流式传输）
+//   - TLSNextProto: forceHTTP1 时置为空 map，强制使用 HTTP/1.1
+//   - TLSClientConfig: 配置了 mTLS 客户端证书时在握手中出示；配置了 SNI 覆盖时替换握手的 ServerName
+//   - DialContext: 配置了静态域名映射且直连时，按映射 IP 建立连接
 func buildUpstreamTransport(settings poolSettings, proxyURL *url.URL) (*http.Transport, error) {
 	transport := &http.Transport{
 		MaxIdleConns:          settings.maxIdleConns,
@@ -767,6 +889,22 @@ func buildUpstreamTransport(settings poolSettings, proxyURL *url.URL) (*http.Tra
 		IdleConnTimeout:       settings.idleConnTimeout,
 		ResponseHeaderTimeout: settings.responseHeaderTimeout,
 	}
+	if settings.clientCert != nil || settings.tlsServerName != "" {
+		tlsConfig := &tls.Config{ServerName: settings.tlsServerName}
+		if settings.clientCert != nil {
+			tlsConfig.Certificates = []tls.Certificate{*settings.clientCert}
+		}
+		transport.TLSClientConfig = tlsConfig
+		// 自定义 TLSClientConfig 会关闭默认的 HTTP/2 协商，需显式开启
+		transport.ForceAttemptHTTP2 = !settings.forceHTTP1
+	}
+	if len(settings.hostMap) > 0 && proxyURL == nil {
+		transport.DialContext = hostMapDialContext(settings.hostMap)
+	}
+	if settings.forceHTTP1 {
+		// 非 nil 的空 TLSNextProto 会禁用 HTTP/2 协商
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
 	if err := proxyutil.ConfigureTransportProxy(transport, proxyURL); err != nil {
 		return nil, err
 	}
@@ -804,7 +942,11 @@ func buildUpstreamTransportWithTLSFingerprint(settings poolSettings, proxyURL *u
 	if proxyURL == nil {
 		// 直连：使用 TLSFingerprintDialer
 		slog.Debug("tls_fingerprint_transport_direct")
-		dialer := tlsfingerprint.NewDialer(profile, nil)
+		var baseDialer func(ctx context.Context, network, addr string) (net.Conn, error)
+		if len(settings.hostMap) > 0 {
+			baseDialer = hostMapDialContext(settings.hostMap)
+		}
+		dialer := tlsfingerprint.NewDialer(profile, baseDialer)
 		transport.DialTLSContext = dialer.DialTLSContext
 	} else {
 		scheme := strings.ToLower(proxyURL.Scheme)
@@ -831,6 +973,19 @@ func buildUpstreamTransportWithTLSFingerprint(settings poolSettings, proxyURL *u
 	return transport, nil
 }
 
+// hostMapDialContext 返回按静态域名映射改写拨号地址的 DialContext，未命中映射的域名正常解析
+func hostMapDialContext(hostMap map[string]string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			if ip, ok := hostMap[strings.ToLower(host)]; ok {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
 // trackedBody 带跟踪功能的响应体包装器
 // 在 Close 时执行回调，用于更新请求计数
 type trackedBody struct {
@@ -864,56 +1019,3 @@ func wrapTrackedBody(body io.ReadCloser, onClose func()) io.ReadCloser {
 	}
 	return &trackedBody{ReadCloser: body, onClose: onClose}
 }
-
-// decompressResponseBody 根据 Content-Encoding 解压响应体。
-// 当请求显式设置了 accept-encoding 时，Go 的 Transport 不会自动解压，需要手动处理。
-// 解压成功后会删除 Content-Encoding 和 Content-Length header（长度已不准确）。
-func decompressResponseBody(resp *http.Response) {
-	if resp == nil || resp.Body == nil {
-		return
-	}
-	ce := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
-	if ce == "" {
-		return
-	}
-
-	var reader io.Reader
-	switch ce {
-	case "gzip":
-		gr, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			return // 解压失败，保持原样
-		}
-		reader = gr
-	case "br":
-		reader = brotli.NewReader(resp.Body)
-	case "deflate":
-		reader = flate.NewReader(resp.Body)
-	default:
-		return
-	}
-
-	originalBody := resp.Body
-	resp.Body = &decompressedBody{reader: reader, closer: originalBody}
-	resp.Header.Del("Content-Encoding")
-	resp.Header.Del("Content-Length") // 解压后长度不确定
-	resp.ContentLength = -1
-}
-
-// decompressedBody 组合解压 reader 和原始 body 的 close。
-type decompressedBody struct {
-	reader io.Reader
-	closer io.Closer
-}
-
-func (d *decompressedBody) Read(p []byte) (int, error) {
-	return d.reader.Read(p)
-}
-
-func (d *decompressedBody) Close() error {
-	// 如果 reader 本身也是 Closer（如 gzip.Reader），先关闭它
-	if rc, ok := d.reader.(io.Closer); ok {
-		_ = rc.Close()
-	}
-	return d.closer.Close()
-}
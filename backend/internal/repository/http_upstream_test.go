@@ -1,13 +1,21 @@
 package repository
 
 import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -65,7 +73,7 @@ func (s *HTTPUpstreamSuite) TestCustomResponseHeaderTimeout() {
 // 验证解析失败时拒绝回退到直连模式
 func (s *HTTPUpstreamSuite) TestGetOrCreateClient_InvalidURLReturnsError() {
 	svc := s.newService()
-	_, err := svc.getClientEntry("://bad-proxy-url", 1, 1, false, false)
+	_, err := svc.getClientEntry("://bad-proxy-url", 1, 1, service.UpstreamTransportOptions{}, false, false)
 	require.Error(s.T(), err, "expected error for invalid proxy URL")
 }
 
@@ -87,11 +95,11 @@ func (s *HTTPUpstreamSuite) TestAcquireClient_OverLimitReturnsError() {
 		MaxUpstreamClients:      1,
 	}
 	svc := s.newService()
-	entry1, err := svc.acquireClient("http://proxy-a:8080", 1, 1)
+	entry1, err := svc.acquireClient("http://proxy-a:8080", 1, 1, service.UpstreamTransportOptions{})
 	require.NoError(s.T(), err, "expected first acquire to succeed")
 	require.NotNil(s.T(), entry1, "expected entry")
 
-	entry2, err := svc.acquireClient("http://proxy-b:8080", 2, 1)
+	entry2, err := svc.acquireClient("http://proxy-b:8080", 2, 1, service.UpstreamTransportOptions{})
 	require.Error(s.T(), err, "expected error when cache limit reached")
 	require.Nil(s.T(), entry2, "expected nil entry when cache limit reached")
 }
@@ -116,6 +124,27 @@ func (s *HTTPUpstreamSuite) TestDo_WithoutProxy_GoesDirect() {
 	require.Equal(s.T(), "direct", string(b), "unexpected body")
 }
 
+// TestDo_ForwardsGatewayRequestID 测试网关请求 ID 透传
+// 验证配置 upstream_request_id_header 后按请求 context 中的 request_id 设置上游请求头
+func (s *HTTPUpstreamSuite) TestDo_ForwardsGatewayRequestID() {
+	var got atomic.Value
+	upstream := newLocalTestServer(s.T(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.Store(r.Header.Get("X-Client-Request-Id"))
+	}))
+	s.T().Cleanup(upstream.Close)
+
+	s.cfg.Gateway.UpstreamRequestIDHeader = "X-Client-Request-Id"
+	up := NewHTTPUpstream(s.cfg)
+
+	ctx := context.WithValue(context.Background(), ctxkey.RequestID, "rid-1")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL+"/x", nil)
+	require.NoError(s.T(), err, "NewRequest")
+	resp, err := up.Do(req, "", 1, 1)
+	require.NoError(s.T(), err, "Do")
+	_ = resp.Body.Close()
+	require.Equal(s.T(), "rid-1", got.Load())
+}
+
 // TestDo_WithHTTPProxy_UsesProxy 测试 HTTP 代理功能
 // 验证请求通过代理服务器转发，使用绝对 URI 格式
 func (s *HTTPUpstreamSuite) TestDo_WithHTTPProxy_UsesProxy() {
@@ -276,6 +305,138 @@ func (s *HTTPUpstreamSuite) TestIdleTTLDoesNotEvictActive() {
 	require.True(s.T(), hasEntry(svc, entry1), "有活跃请求时不应回收")
 }
 
+// TestAccountTransportOptions_SeparatePoolInProxyMode 测试账号级连接池参数
+// 验证 proxy 隔离模式下配置了账号级参数的账号不与其他账号共享连接池，且参数生效
+func (s *HTTPUpstreamSuite) TestAccountTransportOptions_SeparatePoolInProxyMode() {
+	s.cfg.Gateway = config.GatewayConfig{ConnectionPoolIsolation: config.ConnectionPoolIsolationProxy}
+	svc := s.newService()
+	shared := mustGetOrCreateClient(s.T(), svc, "http://proxy.local:8080", 1, 3)
+	opts := service.UpstreamTransportOptions{MaxIdleConns: 300, IdleConnTimeout: 30 * time.Second, ForceHTTP1: true}
+	tuned, err := svc.getClientEntry("http://proxy.local:8080", 2, 3, opts, false, false)
+	require.NoError(s.T(), err)
+	require.NotSame(s.T(), shared, tuned, "配置了账号级参数的账号应使用独立连接池")
+	require.Equal(s.T(), 2, len(svc.clients))
+
+	transport, ok := tuned.client.Transport.(*http.Transport)
+	require.True(s.T(), ok, "expected *http.Transport")
+	require.Equal(s.T(), 300, transport.MaxIdleConnsPerHost)
+	require.Equal(s.T(), 300, transport.MaxIdleConns)
+	require.Equal(s.T(), 30*time.Second, transport.IdleConnTimeout)
+	require.NotNil(s.T(), transport.TLSNextProto, "ForceHTTP1 应禁用 HTTP/2 协商")
+	require.Empty(s.T(), transport.TLSNextProto)
+}
+
+// TestAccountTransportOptions_ChangeRebuildsClient 测试账号级参数变更
+// 验证参数变更后重建客户端
+func (s *HTTPUpstreamSuite) TestAccountTransportOptions_ChangeRebuildsClient() {
+	s.cfg.Gateway = config.GatewayConfig{ConnectionPoolIsolation: config.ConnectionPoolIsolationAccount}
+	svc := s.newService()
+	entry1, err := svc.getClientEntry("", 1, 3, service.UpstreamTransportOptions{MaxIdleConns: 10}, false, false)
+	require.NoError(s.T(), err)
+	entry2, err := svc.getClientEntry("", 1, 3, service.UpstreamTransportOptions{MaxIdleConns: 20}, false, false)
+	require.NoError(s.T(), err)
+	require.NotSame(s.T(), entry1, entry2, "参数变更应重建客户端")
+	require.Equal(s.T(), 1, len(svc.clients))
+}
+
+// TestAccountTransportOptions_ClientCertificate 测试账号级 mTLS 客户端证书
+// 验证证书被挂载到 Transport 且仍保留 HTTP/2 协商
+func (s *HTTPUpstreamSuite) TestAccountTransportOptions_ClientCertificate() {
+	s.cfg.Gateway = config.GatewayConfig{ConnectionPoolIsolation: config.ConnectionPoolIsolationProxy}
+	svc := s.newService()
+	cert := &tls.Certificate{Certificate: [][]byte{{0x01}}}
+	entry, err := svc.getClientEntry("", 1, 3, service.UpstreamTransportOptions{ClientCert: cert}, false, false)
+	require.NoError(s.T(), err)
+	_, ok := svc.clients["account:1|proxy:direct"]
+	require.True(s.T(), ok, "配置证书的账号不应与其他账号共享连接池")
+
+	transport, ok := entry.client.Transport.(*http.Transport)
+	require.True(s.T(), ok, "expected *http.Transport")
+	require.NotNil(s.T(), transport.TLSClientConfig)
+	require.Len(s.T(), transport.TLSClientConfig.Certificates, 1)
+	require.True(s.T(), transport.ForceAttemptHTTP2)
+}
+
+// TestAccountTransportOptions_HostMapAndSNI 测试账号级静态域名映射与 SNI 覆盖
+// 验证直连时按映射 IP 拨号，且 SNI 写入 TLSClientConfig
+func (s *HTTPUpstreamSuite) TestAccountTransportOptions_HostMapAndSNI() {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Host)
+	}))
+	s.T().Cleanup(upstream.Close)
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(upstream.URL, "http://"))
+	require.NoError(s.T(), err)
+
+	svc := s.newService()
+	opts := service.UpstreamTransportOptions{
+		HostMap:       map[string]string{"api.blocked.invalid": "127.0.0.1"},
+		TLSServerName: "front.example.com",
+	}
+	entry, err := svc.getClientEntry("", 1, 1, opts, false, false)
+	require.NoError(s.T(), err)
+	transport, ok := entry.client.Transport.(*http.Transport)
+	require.True(s.T(), ok, "expected *http.Transport")
+	require.Equal(s.T(), "front.example.com", transport.TLSClientConfig.ServerName)
+
+	resp, err := entry.client.Get("http://API.blocked.invalid:" + port + "/")
+	require.NoError(s.T(), err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	require.Equal(s.T(), "API.blocked.invalid:"+port, string(body), "Host 头应保持原域名")
+}
+
+// TestDo_RecordsConnReuse 测试连接复用统计
+// 验证同一客户端的连续请求能统计到新建与复用连接
+func (s *HTTPUpstreamSuite) TestDo_RecordsConnReuse() {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	s.T().Cleanup(upstream.Close)
+
+	svc := s.newService()
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodGet, upstream.URL, nil)
+		require.NoError(s.T(), err)
+		resp, err := svc.Do(req, "", 1, 1)
+		require.NoError(s.T(), err)
+		_, _ = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	entry := mustGetOrCreateClient(s.T(), svc, "", 1, 1)
+	require.Equal(s.T(), int64(1), atomic.LoadInt64(&entry.connNew))
+	require.Equal(s.T(), int64(1), atomic.LoadInt64(&entry.connReused))
+}
+
+// TestDo_DecompressesResponseAndSanitizesAcceptEncoding 测试响应解压与 Accept-Encoding 过滤
+// 验证客户端透传的不可解码编码被剔除，且 gzip 响应交给业务层前已解压
+func (s *HTTPUpstreamSuite) TestDo_DecompressesResponseAndSanitizesAcceptEncoding() {
+	var gotAcceptEncoding string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAcceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Type", "application/json")
+		gw := gzip.NewWriter(w)
+		_, _ = io.WriteString(gw, `{"usage":{"input_tokens":3}}`)
+		_ = gw.Close()
+	}))
+	s.T().Cleanup(upstream.Close)
+
+	req, err := http.NewRequest(http.MethodPost, upstream.URL, nil)
+	require.NoError(s.T(), err)
+	req.Header["accept-encoding"] = []string{"gzip, compress;q=0.5, br"}
+
+	resp, err := s.newService().Do(req, "", 1, 1)
+	require.NoError(s.T(), err)
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(s.T(), err)
+
+	require.Equal(s.T(), "gzip, br", gotAcceptEncoding)
+	require.Equal(s.T(), `{"usage":{"input_tokens":3}}`, string(body))
+	require.Empty(s.T(), resp.Header.Get("Content-Encoding"))
+}
+
 // TestHTTPUpstreamSuite 运行测试套件
 func TestHTTPUpstreamSuite(t *testing.T) {
 	suite.Run(t, new(HTTPUpstreamSuite))
@@ -32,6 +32,9 @@ type identityCache struct {
 }
 
 func NewIdentityCache(rdb *redis.Client) service.IdentityCache {
+	if rdb == nil {
+		return nil
+	}
 	return &identityCache{rdb: rdb}
 }
 
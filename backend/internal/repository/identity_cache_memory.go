@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+// memoryIdentityCache 进程内账号指纹与伪装会话缓存（concurrency.backend=memory）
+// 指纹需要在请求之间保持稳定，不能像其他可选缓存那样直接降级为 nil。
+// 指纹未命中时返回 redis.Nil，与 Redis 实现的调用方约定一致。
+type memoryIdentityCache struct {
+	mu             sync.Mutex
+	fingerprints   map[int64]memoryFingerprintEntry
+	maskedSessions map[int64]memoryMaskedSessionEntry
+	nowFn          func() time.Time
+}
+
+type memoryFingerprintEntry struct {
+	fp        service.Fingerprint
+	expiresAt time.Time
+}
+
+type memoryMaskedSessionEntry struct {
+	sessionID string
+	expiresAt time.Time
+}
+
+func NewMemoryIdentityCache() service.IdentityCache {
+	return &memoryIdentityCache{
+		fingerprints:   make(map[int64]memoryFingerprintEntry),
+		maskedSessions: make(map[int64]memoryMaskedSessionEntry),
+		nowFn:          time.Now,
+	}
+}
+
+func (c *memoryIdentityCache) GetFingerprint(_ context.Context, accountID int64) (*service.Fingerprint, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.fingerprints[accountID]
+	if !ok {
+		return nil, redis.Nil
+	}
+	if !c.nowFn().Before(entry.expiresAt) {
+		delete(c.fingerprints, accountID)
+		return nil, redis.Nil
+	}
+	fp := entry.fp
+	return &fp, nil
+}
+
+func (c *memoryIdentityCache) SetFingerprint(_ context.Context, accountID int64, fp *service.Fingerprint) error {
+	if fp == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fingerprints[accountID] = memoryFingerprintEntry{
+		fp:        *fp,
+		expiresAt: c.nowFn().Add(fingerprintTTL),
+	}
+	return nil
+}
+
+func (c *memoryIdentityCache) GetMaskedSessionID(_ context.Context, accountID int64) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.maskedSessions[accountID]
+	if !ok {
+		return "", nil
+	}
+	if !c.nowFn().Before(entry.expiresAt) {
+		delete(c.maskedSessions, accountID)
+		return "", nil
+	}
+	return entry.sessionID, nil
+}
+
+func (c *memoryIdentityCache) SetMaskedSessionID(_ context.Context, accountID int64, sessionID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maskedSessions[accountID] = memoryMaskedSessionEntry{
+		sessionID: sessionID,
+		expiresAt: c.nowFn().Add(maskedSessionTTL),
+	}
+	return nil
+}
@@ -33,6 +33,9 @@ type internal500CounterCache struct {
 
 // NewInternal500CounterCache 创建 INTERNAL 500 连续失败计数器缓存实例
 func NewInternal500CounterCache(rdb *redis.Client) service.Internal500CounterCache {
+	if rdb == nil {
+		return nil
+	}
 	return &internal500CounterCache{rdb: rdb}
 }
 
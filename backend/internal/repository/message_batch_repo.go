@@ -0,0 +1,346 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// messageBatchInsertChunk 单条 INSERT 语句写入的子请求数（每条 4 个参数，远低于 PostgreSQL 65535 参数上限）
+const messageBatchInsertChunk = 1000
+
+type messageBatchRepository struct {
+	db *sql.DB
+}
+
+func NewMessageBatchRepository(db *sql.DB) service.MessageBatchRepository {
+	return &messageBatchRepository{db: db}
+}
+
+// messageBatchSelectColumns 批次字段与按状态聚合的请求计数（需配合 LEFT JOIN message_batch_requests r 与 GROUP BY b.id）
+const messageBatchSelectColumns = `
+	b.id, b.user_id, b.api_key_id, b.processing_status, b.client_ip, b.anthropic_beta, b.results_url,
+	b.created_at, b.expires_at, b.cancel_initiated_at, b.ended_at,
+	COUNT(r.idx) FILTER (WHERE r.status IN ('pending', 'processing')),
+	COUNT(r.idx) FILTER (WHERE r.status = 'succeeded'),
+	COUNT(r.idx) FILTER (WHERE r.status = 'errored'),
+	COUNT(r.idx) FILTER (WHERE r.status = 'canceled'),
+	COUNT(r.idx) FILTER (WHERE r.status = 'expired')`
+
+type messageBatchScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMessageBatch(row messageBatchScanner) (*service.MessageBatch, error) {
+	var (
+		batch           service.MessageBatch
+		cancelInitiated sql.NullTime
+		endedAt         sql.NullTime
+	)
+	if err := row.Scan(
+		&batch.ID,
+		&batch.UserID,
+		&batch.APIKeyID,
+		&batch.ProcessingStatus,
+		&batch.ClientIP,
+		&batch.AnthropicBeta,
+		&batch.ResultsURL,
+		&batch.CreatedAt,
+		&batch.ExpiresAt,
+		&cancelInitiated,
+		&endedAt,
+		&batch.RequestCounts.Processing,
+		&batch.RequestCounts.Succeeded,
+		&batch.RequestCounts.Errored,
+		&batch.RequestCounts.Canceled,
+		&batch.RequestCounts.Expired,
+	); err != nil {
+		return nil, err
+	}
+	if cancelInitiated.Valid {
+		t := cancelInitiated.Time
+		batch.CancelInitiatedAt = &t
+	}
+	if endedAt.Valid {
+		t := endedAt.Time
+		batch.EndedAt = &t
+	}
+	return &batch, nil
+}
+
+func (r *messageBatchRepository) Create(ctx context.Context, batch *service.MessageBatch, requests []service.MessageBatchRequest) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO message_batches (
+			id, user_id, api_key_id, processing_status, client_ip, anthropic_beta, results_url, created_at, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		batch.ID, batch.UserID, batch.APIKeyID, batch.ProcessingStatus, batch.ClientIP,
+		batch.AnthropicBeta, batch.ResultsURL, batch.CreatedAt, batch.ExpiresAt,
+	); err != nil {
+		return fmt.Errorf("insert message batch: %w", err)
+	}
+
+	for start := 0; start < len(requests); start += messageBatchInsertChunk {
+		chunk := requests[start:min(start+messageBatchInsertChunk, len(requests))]
+		values := make([]string, 0, len(chunk))
+		args := make([]any, 0, len(chunk)*4)
+		for _, req := range chunk {
+			n := len(args)
+			values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+			args = append(args, batch.ID, req.Index, req.CustomID, string(req.Params))
+		}
+		query := "INSERT INTO message_batch_requests (batch_id, idx, custom_id, params) VALUES " + strings.Join(values, ", ")
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("insert message batch requests: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *messageBatchRepository) GetByID(ctx context.Context, id string) (*service.MessageBatch, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+messageBatchSelectColumns+`
+		FROM message_batches b
+		LEFT JOIN message_batch_requests r ON r.batch_id = b.id
+		WHERE b.id = $1
+		GROUP BY b.id`, id)
+	batch, err := scanMessageBatch(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrMessageBatchNotFound
+	}
+	return batch, err
+}
+
+func (r *messageBatchRepository) ListByAPIKey(ctx context.Context, apiKeyID int64, beforeID, afterID string, limit int) ([]*service.MessageBatch, bool, error) {
+	args := []any{apiKeyID, limit + 1}
+	cursor := ""
+	order := "DESC"
+	switch {
+	case afterID != "":
+		// after_id：游标之后（更早创建）的批次
+		args = append(args, afterID)
+		cursor = "AND (b.created_at, b.id) < (SELECT created_at, id FROM message_batches WHERE id = $3)"
+	case beforeID != "":
+		// before_id：游标之前（更晚创建）的批次，按升序取紧邻的一页后再反转
+		args = append(args, beforeID)
+		cursor = "AND (b.created_at, b.id) > (SELECT created_at, id FROM message_batches WHERE id = $3)"
+		order = "ASC"
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+messageBatchSelectColumns+`
+		FROM message_batches b
+		LEFT JOIN message_batch_requests r ON r.batch_id = b.id
+		WHERE b.api_key_id = $1 `+cursor+`
+		GROUP BY b.id
+		ORDER BY b.created_at `+order+`, b.id `+order+`
+		LIMIT $2`, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*service.MessageBatch, 0, limit)
+	for rows.Next() {
+		batch, err := scanMessageBatch(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		out = append(out, batch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(out) > limit
+	if hasMore {
+		out = out[:limit]
+	}
+	if order == "ASC" {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+	return out, hasMore, nil
+}
+
+func (r *messageBatchRepository) Cancel(ctx context.Context, id string, now time.Time, canceledResult []byte) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE message_batches
+		SET processing_status = 'canceling', cancel_initiated_at = $2
+		WHERE id = $1 AND processing_status = 'in_progress'`, id, now)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return tx.Commit()
+	}
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE message_batch_requests
+		SET status = 'canceled', result = $2, completed_at = $3
+		WHERE batch_id = $1 AND status = 'pending'`, id, string(canceledResult), now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *messageBatchRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM message_batches WHERE id = $1`, id)
+	return err
+}
+
+func (r *messageBatchRepository) ListResults(ctx context.Context, id string, afterIndex, limit int) ([]service.MessageBatchRequest, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT idx, custom_id, status, COALESCE(result, '')
+		FROM message_batch_requests
+		WHERE batch_id = $1 AND idx > $2
+		ORDER BY idx
+		LIMIT $3`, id, afterIndex, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]service.MessageBatchRequest, 0, limit)
+	for rows.Next() {
+		req := service.MessageBatchRequest{BatchID: id}
+		var result string
+		if err := rows.Scan(&req.Index, &req.CustomID, &req.Status, &result); err != nil {
+			return nil, err
+		}
+		req.Result = []byte(result)
+		out = append(out, req)
+	}
+	return out, rows.Err()
+}
+
+func (r *messageBatchRepository) ClaimPending(ctx context.Context, now, staleBefore time.Time, limit int) ([]service.MessageBatchWork, error) {
+	// 按批次创建顺序领取；FOR UPDATE SKIP LOCKED 保证多实例不会重复领取同一请求
+	rows, err := r.db.QueryContext(ctx, `
+		WITH picked AS (
+			SELECT r.batch_id, r.idx
+			FROM message_batch_requests r
+			JOIN message_batches b ON b.id = r.batch_id
+			WHERE b.processing_status = 'in_progress'
+				AND b.expires_at > $1
+				AND (
+					(r.status = 'pending' AND (r.next_attempt_at IS NULL OR r.next_attempt_at <= $1))
+					OR (r.status = 'processing' AND r.claimed_at < $2)
+				)
+			ORDER BY b.created_at, r.idx
+			LIMIT $3
+			FOR UPDATE OF r SKIP LOCKED
+		)
+		UPDATE message_batch_requests r
+		SET status = 'processing', claimed_at = $1, attempts = r.attempts + 1
+		FROM picked, message_batches b
+		WHERE r.batch_id = picked.batch_id AND r.idx = picked.idx AND b.id = r.batch_id
+		RETURNING r.batch_id, r.idx, r.custom_id, r.params, r.attempts, b.api_key_id, b.client_ip, b.anthropic_beta`,
+		now, staleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []service.MessageBatchWork
+	for rows.Next() {
+		var work service.MessageBatchWork
+		var params string
+		if err := rows.Scan(&work.BatchID, &work.Index, &work.CustomID, &params, &work.Attempts,
+			&work.APIKeyID, &work.ClientIP, &work.AnthropicBeta); err != nil {
+			return nil, err
+		}
+		work.Params = []byte(params)
+		work.Status = service.MessageBatchRequestProcessing
+		out = append(out, work)
+	}
+	return out, rows.Err()
+}
+
+func (r *messageBatchRepository) CompleteRequest(ctx context.Context, batchID string, index int, status string, result []byte, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE message_batch_requests
+		SET status = $3, result = $4, completed_at = $5, next_attempt_at = NULL
+		WHERE batch_id = $1 AND idx = $2 AND status = 'processing'`,
+		batchID, index, status, string(result), now)
+	return err
+}
+
+func (r *messageBatchRepository) ReleaseRequest(ctx context.Context, batchID string, index int, notBefore time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE message_batch_requests
+		SET status = 'pending', claimed_at = NULL, next_attempt_at = $3
+		WHERE batch_id = $1 AND idx = $2 AND status = 'processing'`,
+		batchID, index, notBefore)
+	return err
+}
+
+func (r *messageBatchRepository) SweepRequests(ctx context.Context, now, staleBefore time.Time, expiredResult, canceledResult []byte) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE message_batch_requests r
+		SET status = CASE WHEN b.processing_status = 'canceling' THEN 'canceled' ELSE 'expired' END,
+			result = CASE WHEN b.processing_status = 'canceling' THEN $4 ELSE $3 END,
+			completed_at = $1
+		FROM message_batches b
+		WHERE b.id = r.batch_id
+			AND (
+				(b.processing_status = 'in_progress' AND b.expires_at <= $1
+					AND (r.status = 'pending' OR (r.status = 'processing' AND r.claimed_at < $2)))
+				OR (b.processing_status = 'canceling' AND r.status IN ('pending', 'processing')
+					AND (r.claimed_at IS NULL OR r.claimed_at < $2))
+			)`,
+		now, staleBefore, string(expiredResult), string(canceledResult))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *messageBatchRepository) FinalizeBatches(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE message_batches b
+		SET processing_status = 'ended', ended_at = $1
+		WHERE b.processing_status <> 'ended'
+			AND NOT EXISTS (
+				SELECT 1 FROM message_batch_requests r
+				WHERE r.batch_id = b.id AND r.status IN ('pending', 'processing')
+			)`, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *messageBatchRepository) DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM message_batches
+		WHERE processing_status = 'ended' AND ended_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
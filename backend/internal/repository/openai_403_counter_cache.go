@@ -27,6 +27,9 @@ type openAI403CounterCache struct {
 }
 
 func NewOpenAI403CounterCache(rdb *redis.Client) service.OpenAI403CounterCache {
+	if rdb == nil {
+		return nil
+	}
 	return &openAI403CounterCache{rdb: rdb}
 }
 
@@ -54,6 +54,10 @@ INSERT INTO ops_error_logs (
   upstream_latency_ms,
   response_latency_ms,
   time_to_first_token_ms,
+  upstream_dns_ms,
+  upstream_connect_ms,
+  upstream_tls_ms,
+  upstream_first_byte_ms,
   request_body,
   request_body_truncated,
   request_body_bytes,
@@ -62,7 +66,7 @@ INSERT INTO ops_error_logs (
   retry_count,
   created_at
 ) VALUES (
-  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42,$43
+  $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42,$43,$44,$45,$46,$47
 )`
 
 func NewOpsRepository(db *sql.DB) service.OpsRepository {
@@ -170,6 +174,10 @@ func opsInsertErrorLogArgs(input *service.OpsInsertErrorLogInput) []any {
 		opsNullInt64(input.UpstreamLatencyMs),
 		opsNullInt64(input.ResponseLatencyMs),
 		opsNullInt64(input.TimeToFirstTokenMs),
+		opsNullInt64(input.UpstreamDNSMs),
+		opsNullInt64(input.UpstreamConnectMs),
+		opsNullInt64(input.UpstreamTLSMs),
+		opsNullInt64(input.UpstreamFirstByteMs),
 		opsNullString(input.RequestBodyJSON),
 		input.RequestBodyTruncated,
 		opsNullInt(input.RequestBodyBytes),
@@ -429,6 +437,10 @@ SELECT
   e.upstream_latency_ms,
   e.response_latency_ms,
   e.time_to_first_token_ms,
+  e.upstream_dns_ms,
+  e.upstream_connect_ms,
+  e.upstream_tls_ms,
+  e.upstream_first_byte_ms,
   COALESCE(e.request_body::text, ''),
   e.request_body_truncated,
   e.request_body_bytes,
@@ -456,6 +468,10 @@ LIMIT 1`
 	var upstreamLatency sql.NullInt64
 	var responseLatency sql.NullInt64
 	var ttft sql.NullInt64
+	var upstreamDNS sql.NullInt64
+	var upstreamConnect sql.NullInt64
+	var upstreamTLS sql.NullInt64
+	var upstreamFirstByte sql.NullInt64
 	var requestBodyBytes sql.NullInt64
 	var requestType sql.NullInt64
 
@@ -506,6 +522,10 @@ LIMIT 1`
 		&upstreamLatency,
 		&responseLatency,
 		&ttft,
+		&upstreamDNS,
+		&upstreamConnect,
+		&upstreamTLS,
+		&upstreamFirstByte,
 		&out.RequestBody,
 		&out.RequestBodyTruncated,
 		&requestBodyBytes,
@@ -572,6 +592,22 @@ LIMIT 1`
 		v := ttft.Int64
 		out.TimeToFirstTokenMs = &v
 	}
+	if upstreamDNS.Valid {
+		v := upstreamDNS.Int64
+		out.UpstreamDNSMs = &v
+	}
+	if upstreamConnect.Valid {
+		v := upstreamConnect.Int64
+		out.UpstreamConnectMs = &v
+	}
+	if upstreamTLS.Valid {
+		v := upstreamTLS.Int64
+		out.UpstreamTLSMs = &v
+	}
+	if upstreamFirstByte.Valid {
+		v := upstreamFirstByte.Int64
+		out.UpstreamFirstByteMs = &v
+	}
 	if requestBodyBytes.Valid {
 		v := int(requestBodyBytes.Int64)
 		out.RequestBodyBytes = &v
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// opsFailoverKindPredicate matches upstream_errors events (aliased ev) that switched accounts.
+var opsFailoverKindPredicate = "split_part(ev->>'kind', ':', 1) IN ('" + strings.Join(service.OpsFailoverEventKinds, "', '") + "')"
+
+// opsFailoverEventsCTE expands ops_error_logs.upstream_errors into one row per failover event.
+// Platform prefers the event's own platform (the failed account) over the request platform.
+func opsFailoverEventsCTE(where string) string {
+	return `
+WITH failover_events AS (
+  SELECT
+    id AS log_id,
+    created_at,
+    COALESCE(status_code, 0) AS status_code,
+    COALESCE(NULLIF(ev->>'account_id', '')::bigint, 0) AS account_id,
+    COALESCE(ev->>'account_name', '') AS account_name,
+    COALESCE(NULLIF(ev->>'platform', ''), platform, '') AS event_platform,
+    COALESCE(NULLIF(ev->>'upstream_status_code', '')::int, 0) AS upstream_status_code,
+    COALESCE(ev->>'message', '') AS message
+  FROM ops_error_logs
+  CROSS JOIN LATERAL jsonb_array_elements(
+    COALESCE(NULLIF(upstream_errors, 'null'::jsonb), '[]'::jsonb)
+  ) AS ev
+  ` + where + `
+    AND upstream_errors IS NOT NULL
+    AND ` + opsFailoverKindPredicate + `
+)
+`
+}
+
+func (r *opsRepository) GetFailoverTrend(ctx context.Context, filter *service.OpsDashboardFilter, bucketSeconds int) (*service.OpsFailoverTrendResponse, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if filter == nil {
+		return nil, fmt.Errorf("nil filter")
+	}
+	if filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+		return nil, fmt.Errorf("start_time/end_time required")
+	}
+
+	if bucketSeconds != 60 && bucketSeconds != 300 && bucketSeconds != 3600 {
+		bucketSeconds = 60
+	}
+
+	start := filter.StartTime.UTC()
+	end := filter.EndTime.UTC()
+	where, args, _ := buildErrorWhere(filter, start, end, 1)
+
+	q := opsFailoverEventsCTE(where) + `
+SELECT
+  ` + opsBucketExprForError(bucketSeconds) + ` AS bucket,
+  COUNT(*) AS failover_count,
+  COUNT(DISTINCT log_id) AS request_count
+FROM failover_events
+GROUP BY 1
+ORDER BY 1 ASC`
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	existing := make(map[int64]*service.OpsFailoverTrendPoint)
+	for rows.Next() {
+		var bucket time.Time
+		p := &service.OpsFailoverTrendPoint{}
+		if err := rows.Scan(&bucket, &p.FailoverCount, &p.RequestCount); err != nil {
+			return nil, err
+		}
+		p.BucketStart = bucket.UTC()
+		existing[p.BucketStart.Unix()] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	points := make([]*service.OpsFailoverTrendPoint, 0, len(existing))
+	if endMinus := end.Add(-time.Nanosecond); start.Before(end) && !endMinus.Before(start) {
+		step := time.Duration(bucketSeconds) * time.Second
+		last := opsFloorToBucketStart(endMinus, bucketSeconds)
+		for cursor := opsFloorToBucketStart(start, bucketSeconds); !cursor.After(last); cursor = cursor.Add(step) {
+			if p, ok := existing[cursor.Unix()]; ok {
+				points = append(points, p)
+				continue
+			}
+			points = append(points, &service.OpsFailoverTrendPoint{BucketStart: cursor})
+		}
+	}
+
+	return &service.OpsFailoverTrendResponse{
+		Bucket: opsBucketLabel(bucketSeconds),
+		Points: points,
+	}, nil
+}
+
+func (r *opsRepository) GetFailoverStats(ctx context.Context, filter *service.OpsDashboardFilter, topN int) (*service.OpsFailoverStatsResponse, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops repository")
+	}
+	if filter == nil {
+		return nil, fmt.Errorf("nil filter")
+	}
+	if filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+		return nil, fmt.Errorf("start_time/end_time required")
+	}
+	if topN <= 0 {
+		topN = 10
+	}
+
+	start := filter.StartTime.UTC()
+	end := filter.EndTime.UTC()
+	where, baseArgs, next := buildErrorWhere(filter, start, end, 1)
+	cte := opsFailoverEventsCTE(where)
+	limit := fmt.Sprintf("\nLIMIT $%d", next)
+	topArgs := append(append(make([]any, 0, len(baseArgs)+1), baseArgs...), topN)
+
+	resp := &service.OpsFailoverStatsResponse{
+		StartTime:    start,
+		EndTime:      end,
+		TopN:         topN,
+		Summary:      &service.OpsFailoverSummary{},
+		ByAccount:    []*service.OpsFailoverAccountItem{},
+		ByPlatform:   []*service.OpsFailoverPlatformItem{},
+		ByStatusCode: []*service.OpsFailoverStatusCodeItem{},
+		TopMessages:  []*service.OpsFailoverMessageItem{},
+	}
+
+	summary := resp.Summary
+	if err := r.db.QueryRowContext(ctx, cte+`
+SELECT
+  COUNT(*),
+  COUNT(DISTINCT log_id),
+  COUNT(DISTINCT log_id) FILTER (WHERE status_code < 400)
+FROM failover_events`, baseArgs...).Scan(&summary.FailoverCount, &summary.RequestCount, &summary.RecoveredCount); err != nil {
+		return nil, err
+	}
+	if summary.RequestCount > 0 {
+		summary.AvgSwitchesPerRequest = roundTo4DP(float64(summary.FailoverCount) / float64(summary.RequestCount))
+	}
+	if summary.FailoverCount == 0 {
+		return resp, nil
+	}
+
+	if err := r.queryFailoverRows(ctx, cte+`
+SELECT account_id, MAX(account_name), MAX(event_platform), COUNT(*) AS failover_count, COUNT(DISTINCT log_id)
+FROM failover_events
+GROUP BY account_id
+ORDER BY failover_count DESC, account_id ASC`+limit, topArgs, func(scan func(...any) error) error {
+		item := &service.OpsFailoverAccountItem{}
+		if err := scan(&item.AccountID, &item.AccountName, &item.Platform, &item.FailoverCount, &item.RequestCount); err != nil {
+			return err
+		}
+		resp.ByAccount = append(resp.ByAccount, item)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := r.queryFailoverRows(ctx, cte+`
+SELECT event_platform, COUNT(*) AS failover_count, COUNT(DISTINCT log_id)
+FROM failover_events
+GROUP BY event_platform
+ORDER BY failover_count DESC, event_platform ASC`, baseArgs, func(scan func(...any) error) error {
+		item := &service.OpsFailoverPlatformItem{}
+		if err := scan(&item.Platform, &item.FailoverCount, &item.RequestCount); err != nil {
+			return err
+		}
+		resp.ByPlatform = append(resp.ByPlatform, item)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := r.queryFailoverRows(ctx, cte+`
+SELECT upstream_status_code, COUNT(*) AS failover_count
+FROM failover_events
+GROUP BY upstream_status_code
+ORDER BY failover_count DESC, upstream_status_code ASC`, baseArgs, func(scan func(...any) error) error {
+		item := &service.OpsFailoverStatusCodeItem{}
+		if err := scan(&item.StatusCode, &item.FailoverCount); err != nil {
+			return err
+		}
+		resp.ByStatusCode = append(resp.ByStatusCode, item)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := r.queryFailoverRows(ctx, cte+`
+SELECT message, COUNT(*) AS failover_count, COUNT(DISTINCT account_id), MAX(created_at)
+FROM failover_events
+WHERE message <> ''
+GROUP BY message
+ORDER BY failover_count DESC, message ASC`+limit, topArgs, func(scan func(...any) error) error {
+		item := &service.OpsFailoverMessageItem{}
+		if err := scan(&item.Message, &item.FailoverCount, &item.AccountCount, &item.LastSeenAt); err != nil {
+			return err
+		}
+		item.LastSeenAt = item.LastSeenAt.UTC()
+		resp.TopMessages = append(resp.TopMessages, item)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *opsRepository) queryFailoverRows(ctx context.Context, q string, args []any, scanRow func(scan func(...any) error) error) error {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		if err := scanRow(rows.Scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpsRepositoryGetFailoverStats(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &opsRepository{db: db}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	filter := &service.OpsDashboardFilter{StartTime: start, EndTime: end, Platform: " Anthropic "}
+
+	mock.ExpectQuery(`FROM failover_events$`).
+		WithArgs(start, end, "anthropic").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "requests", "recovered"}).AddRow(int64(9), int64(6), int64(5)))
+	mock.ExpectQuery(`GROUP BY account_id\s+ORDER BY failover_count DESC, account_id ASC\s+LIMIT \$4`).
+		WithArgs(start, end, "anthropic", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "account_name", "platform", "failover_count", "requests"}).
+			AddRow(int64(7), "acc-7", "anthropic", int64(6), int64(5)).
+			AddRow(int64(8), "acc-8", "anthropic", int64(3), int64(3)))
+	mock.ExpectQuery(`GROUP BY event_platform`).
+		WithArgs(start, end, "anthropic").
+		WillReturnRows(sqlmock.NewRows([]string{"platform", "failover_count", "requests"}).AddRow("anthropic", int64(9), int64(6)))
+	mock.ExpectQuery(`GROUP BY upstream_status_code`).
+		WithArgs(start, end, "anthropic").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "failover_count"}).AddRow(529, int64(7)).AddRow(0, int64(2)))
+	mock.ExpectQuery(`WHERE message <> ''\s+GROUP BY message`).
+		WithArgs(start, end, "anthropic", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"message", "failover_count", "accounts", "last_seen"}).
+			AddRow("overloaded", int64(7), int64(2), end.Add(-time.Minute)))
+
+	resp, err := repo.GetFailoverStats(context.Background(), filter, 5)
+	require.NoError(t, err)
+	require.Equal(t, int64(9), resp.Summary.FailoverCount)
+	require.Equal(t, int64(5), resp.Summary.RecoveredCount)
+	require.InDelta(t, 1.5, resp.Summary.AvgSwitchesPerRequest, 0.0001)
+	require.Len(t, resp.ByAccount, 2)
+	require.Equal(t, int64(7), resp.ByAccount[0].AccountID)
+	require.Len(t, resp.ByPlatform, 1)
+	require.Equal(t, 529, resp.ByStatusCode[0].StatusCode)
+	require.Equal(t, "overloaded", resp.TopMessages[0].Message)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpsRepositoryGetFailoverStats_NoFailovers(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &opsRepository{db: db}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	mock.ExpectQuery(`FROM failover_events$`).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "requests", "recovered"}).AddRow(int64(0), int64(0), int64(0)))
+
+	resp, err := repo.GetFailoverStats(context.Background(), &service.OpsDashboardFilter{StartTime: start, EndTime: end}, 0)
+	require.NoError(t, err)
+	require.Equal(t, 10, resp.TopN)
+	require.Zero(t, resp.Summary.AvgSwitchesPerRequest)
+	require.Empty(t, resp.ByAccount)
+	require.NotNil(t, resp.TopMessages)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpsRepositoryGetFailoverTrend_FillsBuckets(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &opsRepository{db: db}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(3 * time.Minute)
+
+	mock.ExpectQuery(`date_trunc\('minute', created_at\) AS bucket`).
+		WithArgs(start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "failover_count", "request_count"}).
+			AddRow(start.Add(time.Minute), int64(4), int64(2)))
+
+	resp, err := repo.GetFailoverTrend(context.Background(), &service.OpsDashboardFilter{StartTime: start, EndTime: end}, 60)
+	require.NoError(t, err)
+	require.Equal(t, "1m", resp.Bucket)
+	require.Len(t, resp.Points, 3)
+	require.Zero(t, resp.Points[0].FailoverCount)
+	require.Equal(t, int64(4), resp.Points[1].FailoverCount)
+	require.Equal(t, int64(2), resp.Points[1].RequestCount)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
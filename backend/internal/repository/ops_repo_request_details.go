@@ -71,6 +71,10 @@ func (r *opsRepository) ListRequestDetails(ctx context.Context, filter *service.
 			)
 		}
 
+		if filter.StatusCode != nil {
+			addCondition(fmt.Sprintf("COALESCE(status_code, 200) = $%d", len(args)+1), *filter.StatusCode)
+		}
+
 		if filter.MinDurationMs != nil {
 			addCondition(fmt.Sprintf("duration_ms >= $%d", len(args)+1), *filter.MinDurationMs)
 		}
@@ -59,7 +59,7 @@ error_buckets AS (
 switch_buckets AS (
   SELECT ` + errorBucketExpr + ` AS bucket,
          COALESCE(SUM(CASE
-           WHEN split_part(ev->>'kind', ':', 1) IN ('failover', 'retry_exhausted_failover', 'failover_on_400') THEN 1
+           WHEN ` + opsFailoverKindPredicate + ` THEN 1
            ELSE 0
          END), 0) AS switch_count
   FROM ops_error_logs
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// opsRequestTraceMaxErrorLogs caps how many ops_error_logs rows are attached to one trace.
+const opsRequestTraceMaxErrorLogs = 50
+
+type opsRequestTraceRepository struct {
+	db *sql.DB
+}
+
+func NewOpsRequestTraceRepository(db *sql.DB) service.OpsRequestTraceRepository {
+	return &opsRequestTraceRepository{db: db}
+}
+
+// GetRequestTrace joins the usage log (success) and all ops error logs (failures and recovered
+// upstream errors) that share the request id. Returns (nil, nil) when nothing is stored.
+func (r *opsRequestTraceRepository) GetRequestTrace(ctx context.Context, requestID string) (*service.OpsRequestTrace, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("nil ops request trace repository")
+	}
+	requestID = strings.TrimSpace(requestID)
+	if requestID == "" {
+		return nil, fmt.Errorf("invalid request id")
+	}
+
+	trace, err := r.loadUsageLog(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.loadErrorLogs(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if trace == nil && len(rows) == 0 {
+		return nil, nil
+	}
+
+	if trace == nil {
+		// Failed request: the last row with a client-visible error status is the final outcome.
+		final := rows[len(rows)-1]
+		for i := len(rows) - 1; i >= 0; i-- {
+			if rows[i].log.StatusCode != nil && *rows[i].log.StatusCode >= 400 {
+				final = rows[i]
+				break
+			}
+		}
+		trace = &service.OpsRequestTrace{
+			RequestID:        final.requestID,
+			ClientRequestID:  final.clientRequestID,
+			Kind:             service.OpsRequestKindError,
+			CreatedAt:        final.log.CreatedAt,
+			Platform:         final.platform,
+			Model:            final.model,
+			RequestedModel:   final.requestedModel,
+			UpstreamModel:    final.upstreamModel,
+			InboundEndpoint:  final.inboundEndpoint,
+			UpstreamEndpoint: final.upstreamEndpoint,
+			Stream:           final.stream,
+			StatusCode:       final.log.StatusCode,
+			UserID:           final.userID,
+			APIKeyID:         final.apiKeyID,
+			GroupID:          final.groupID,
+			AccountID:        final.log.AccountID,
+			AccountName:      final.log.AccountName,
+		}
+	}
+
+	for _, row := range rows {
+		trace.ErrorLogs = append(trace.ErrorLogs, row.log)
+		if row.upstreamErrors != "" {
+			trace.UpstreamErrorsRaw = append(trace.UpstreamErrorsRaw, row.upstreamErrors)
+		}
+		if trace.ClientRequestID == "" {
+			trace.ClientRequestID = row.clientRequestID
+		}
+		// Auth/routing/upstream/response latencies are only recorded on error logs.
+		mergeOpsTraceLatencies(&trace.Latencies, row.latencies)
+		if row.requestBody != "" {
+			trace.RequestBody = row.requestBody
+			trace.RequestBodyTruncated = row.requestBodyTruncated
+			trace.RequestBodyBytes = row.requestBodyBytes
+		}
+	}
+	return trace, nil
+}
+
+func (r *opsRequestTraceRepository) loadUsageLog(ctx context.Context, requestID string) (*service.OpsRequestTrace, error) {
+	q := `
+SELECT
+  ul.id,
+  ul.created_at,
+  ul.request_id,
+  COALESCE(NULLIF(g.platform, ''), NULLIF(a.platform, ''), ''),
+  COALESCE(ul.model, ''),
+  COALESCE(ul.requested_model, ''),
+  COALESCE(ul.upstream_model, ''),
+  COALESCE(ul.inbound_endpoint, ''),
+  COALESCE(ul.upstream_endpoint, ''),
+  ul.stream,
+  ul.user_id,
+  ul.api_key_id,
+  ul.group_id,
+  ul.account_id,
+  COALESCE(a.name, ''),
+  ul.duration_ms,
+  ul.first_token_ms,
+  ul.input_tokens,
+  ul.output_tokens,
+  ul.cache_creation_tokens,
+  ul.cache_read_tokens,
+  ul.total_cost,
+  ul.actual_cost
+FROM usage_logs ul
+LEFT JOIN groups g ON g.id = ul.group_id
+LEFT JOIN accounts a ON a.id = ul.account_id
+WHERE ul.request_id = $1
+ORDER BY ul.created_at DESC
+LIMIT 1`
+
+	var (
+		trace      service.OpsRequestTrace
+		usage      service.OpsRequestTraceUsage
+		userID     sql.NullInt64
+		apiKeyID   sql.NullInt64
+		groupID    sql.NullInt64
+		accountID  sql.NullInt64
+		durationMs sql.NullInt64
+		firstToken sql.NullInt64
+	)
+	err := r.db.QueryRowContext(ctx, q, requestID).Scan(
+		&usage.UsageLogID,
+		&trace.CreatedAt,
+		&trace.RequestID,
+		&trace.Platform,
+		&trace.Model,
+		&trace.RequestedModel,
+		&trace.UpstreamModel,
+		&trace.InboundEndpoint,
+		&trace.UpstreamEndpoint,
+		&trace.Stream,
+		&userID,
+		&apiKeyID,
+		&groupID,
+		&accountID,
+		&trace.AccountName,
+		&durationMs,
+		&firstToken,
+		&usage.InputTokens,
+		&usage.OutputTokens,
+		&usage.CacheCreationTokens,
+		&usage.CacheReadTokens,
+		&usage.TotalCost,
+		&usage.ActualCost,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	trace.Kind = service.OpsRequestKindSuccess
+	trace.UserID = nullInt64Ptr(userID)
+	trace.APIKeyID = nullInt64Ptr(apiKeyID)
+	trace.GroupID = nullInt64Ptr(groupID)
+	trace.AccountID = nullInt64Ptr(accountID)
+	trace.Latencies.DurationMs = nullInt64Ptr(durationMs)
+	trace.Latencies.TimeToFirstTokenMs = nullInt64Ptr(firstToken)
+	trace.Usage = &usage
+	return &trace, nil
+}
+
+// opsTraceErrorRow is an ops_error_logs row plus the request-level columns used to build the trace.
+type opsTraceErrorRow struct {
+	log *service.OpsRequestTraceErrorLog
+
+	requestID        string
+	clientRequestID  string
+	platform         string
+	model            string
+	requestedModel   string
+	upstreamModel    string
+	inboundEndpoint  string
+	upstreamEndpoint string
+	stream           bool
+	userID           *int64
+	apiKeyID         *int64
+	groupID          *int64
+	latencies        service.OpsRequestTraceLatencies
+
+	upstreamErrors       string
+	requestBody          string
+	requestBodyTruncated bool
+	requestBodyBytes     *int
+}
+
+func (r *opsRequestTraceRepository) loadErrorLogs(ctx context.Context, requestID string) ([]*opsTraceErrorRow, error) {
+	q := `
+SELECT
+  e.id,
+  e.created_at,
+  COALESCE(e.request_id, ''),
+  COALESCE(e.client_request_id, ''),
+  e.error_phase,
+  e.error_type,
+  e.severity,
+  e.status_code,
+  e.upstream_status_code,
+  COALESCE(e.error_message, ''),
+  COALESCE(NULLIF(e.platform, ''), NULLIF(g.platform, ''), NULLIF(a.platform, ''), ''),
+  COALESCE(e.model, ''),
+  COALESCE(e.requested_model, ''),
+  COALESCE(e.upstream_model, ''),
+  COALESCE(e.inbound_endpoint, ''),
+  COALESCE(e.upstream_endpoint, ''),
+  e.stream,
+  e.user_id,
+  e.api_key_id,
+  e.group_id,
+  e.account_id,
+  COALESCE(a.name, ''),
+  e.auth_latency_ms,
+  e.routing_latency_ms,
+  e.upstream_latency_ms,
+  e.response_latency_ms,
+  e.time_to_first_token_ms,
+  e.duration_ms,
+  COALESCE(e.upstream_errors::text, ''),
+  COALESCE(e.request_body::text, ''),
+  e.request_body_truncated,
+  e.request_body_bytes
+FROM ops_error_logs e
+LEFT JOIN groups g ON g.id = e.group_id
+LEFT JOIN accounts a ON a.id = e.account_id
+WHERE e.request_id = $1 OR e.client_request_id = $1
+ORDER BY e.created_at ASC, e.id ASC
+LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, q, requestID, opsRequestTraceMaxErrorLogs)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := make([]*opsTraceErrorRow, 0, 4)
+	for rows.Next() {
+		var (
+			row                = &opsTraceErrorRow{log: &service.OpsRequestTraceErrorLog{}}
+			createdAt          time.Time
+			statusCode         sql.NullInt64
+			upstreamStatusCode sql.NullInt64
+			userID             sql.NullInt64
+			apiKeyID           sql.NullInt64
+			groupID            sql.NullInt64
+			accountID          sql.NullInt64
+			authLatency        sql.NullInt64
+			routingLatency     sql.NullInt64
+			upstreamLatency    sql.NullInt64
+			responseLatency    sql.NullInt64
+			ttft               sql.NullInt64
+			durationMs         sql.NullInt64
+			requestBodyBytes   sql.NullInt64
+		)
+		if err := rows.Scan(
+			&row.log.ID,
+			&createdAt,
+			&row.requestID,
+			&row.clientRequestID,
+			&row.log.Phase,
+			&row.log.Type,
+			&row.log.Severity,
+			&statusCode,
+			&upstreamStatusCode,
+			&row.log.Message,
+			&row.platform,
+			&row.model,
+			&row.requestedModel,
+			&row.upstreamModel,
+			&row.inboundEndpoint,
+			&row.upstreamEndpoint,
+			&row.stream,
+			&userID,
+			&apiKeyID,
+			&groupID,
+			&accountID,
+			&row.log.AccountName,
+			&authLatency,
+			&routingLatency,
+			&upstreamLatency,
+			&responseLatency,
+			&ttft,
+			&durationMs,
+			&row.upstreamErrors,
+			&row.requestBody,
+			&row.requestBodyTruncated,
+			&requestBodyBytes,
+		); err != nil {
+			return nil, err
+		}
+
+		row.log.CreatedAt = createdAt
+		row.log.StatusCode = nullIntPtr(statusCode)
+		row.log.UpstreamStatusCode = nullIntPtr(upstreamStatusCode)
+		row.log.AccountID = nullInt64Ptr(accountID)
+		row.userID = nullInt64Ptr(userID)
+		row.apiKeyID = nullInt64Ptr(apiKeyID)
+		row.groupID = nullInt64Ptr(groupID)
+		row.requestBodyBytes = nullIntPtr(requestBodyBytes)
+		row.latencies = service.OpsRequestTraceLatencies{
+			AuthMs:             nullInt64Ptr(authLatency),
+			RoutingMs:          nullInt64Ptr(routingLatency),
+			UpstreamMs:         nullInt64Ptr(upstreamLatency),
+			ResponseMs:         nullInt64Ptr(responseLatency),
+			TimeToFirstTokenMs: nullInt64Ptr(ttft),
+			DurationMs:         nullInt64Ptr(durationMs),
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// mergeOpsTraceLatencies fills latencies missing on dst from src; later rows win for fields both have.
+func mergeOpsTraceLatencies(dst *service.OpsRequestTraceLatencies, src service.OpsRequestTraceLatencies) {
+	if src.AuthMs != nil {
+		dst.AuthMs = src.AuthMs
+	}
+	if src.RoutingMs != nil {
+		dst.RoutingMs = src.RoutingMs
+	}
+	if src.UpstreamMs != nil {
+		dst.UpstreamMs = src.UpstreamMs
+	}
+	if src.ResponseMs != nil {
+		dst.ResponseMs = src.ResponseMs
+	}
+	if dst.TimeToFirstTokenMs == nil {
+		dst.TimeToFirstTokenMs = src.TimeToFirstTokenMs
+	}
+	if dst.DurationMs == nil {
+		dst.DurationMs = src.DurationMs
+	}
+}
+
+func nullInt64Ptr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	out := v.Int64
+	return &out
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	out := int(v.Int64)
+	return &out
+}
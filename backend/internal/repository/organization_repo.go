@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	dbent "github.com/Wei-Shaw/sub2api/ent"
+	"github.com/Wei-Shaw/sub2api/ent/organization"
+	dbuser "github.com/Wei-Shaw/sub2api/ent/user"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	entsql "entgo.io/ent/dialect/sql"
+)
+
+type organizationRepository struct {
+	client *dbent.Client
+	sql    sqlExecutor
+}
+
+func NewOrganizationRepository(client *dbent.Client, sqlDB *sql.DB) service.OrganizationRepository {
+	return &organizationRepository{client: client, sql: sqlDB}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, org *service.Organization) error {
+	client := clientFromContext(ctx, r.client)
+	created, err := client.Organization.Create().
+		SetName(org.Name).
+		SetDescription(org.Description).
+		SetStatus(org.Status).
+		SetMonthlyBudgetUsd(org.MonthlyBudgetUSD).
+		Save(ctx)
+	if err != nil {
+		return translatePersistenceError(err, nil, service.ErrOrganizationNameExists)
+	}
+	org.ID = created.ID
+	org.CreatedAt = created.CreatedAt
+	org.UpdatedAt = created.UpdatedAt
+	return nil
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id int64) (*service.Organization, error) {
+	m, err := r.client.Organization.Query().
+		Where(organization.IDEQ(id)).
+		Only(ctx)
+	if err != nil {
+		return nil, translatePersistenceError(err, service.ErrOrganizationNotFound, nil)
+	}
+	return organizationEntityToService(m), nil
+}
+
+func (r *organizationRepository) Update(ctx context.Context, org *service.Organization) error {
+	client := clientFromContext(ctx, r.client)
+	updated, err := client.Organization.UpdateOneID(org.ID).
+		SetName(org.Name).
+		SetDescription(org.Description).
+		SetStatus(org.Status).
+		SetMonthlyBudgetUsd(org.MonthlyBudgetUSD).
+		Save(ctx)
+	if err != nil {
+		return translatePersistenceError(err, service.ErrOrganizationNotFound, service.ErrOrganizationNameExists)
+	}
+	org.UpdatedAt = updated.UpdatedAt
+	return nil
+}
+
+func (r *organizationRepository) Delete(ctx context.Context, id int64) error {
+	client := clientFromContext(ctx, r.client)
+	_, err := client.Organization.Delete().Where(organization.IDEQ(id)).Exec(ctx)
+	return err
+}
+
+func (r *organizationRepository) List(
+	ctx context.Context,
+	params pagination.PaginationParams,
+	filters service.OrganizationListFilters,
+) ([]service.Organization, *pagination.PaginationResult, error) {
+	q := r.client.Organization.Query()
+
+	if filters.Status != "" {
+		q = q.Where(organization.StatusEQ(filters.Status))
+	}
+	if filters.Search != "" {
+		q = q.Where(
+			organization.Or(
+				organization.NameContainsFold(filters.Search),
+				organization.DescriptionContainsFold(filters.Search),
+			),
+		)
+	}
+
+	total, err := q.Count(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	itemsQuery := q.
+		Offset(params.Offset()).
+		Limit(params.Limit())
+	for _, order := range organizationListOrders(params) {
+		itemsQuery = itemsQuery.Order(order)
+	}
+
+	items, err := itemsQuery.All(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make([]service.Organization, 0, len(items))
+	for i := range items {
+		out = append(out, *organizationEntityToService(items[i]))
+	}
+	return out, paginationResultFromTotal(int64(total), params), nil
+}
+
+func organizationListOrders(params pagination.PaginationParams) []func(*entsql.Selector) {
+	sortOrder := params.NormalizedSortOrder(pagination.SortOrderDesc)
+	field := organization.FieldCreatedAt
+	switch strings.ToLower(strings.TrimSpace(params.SortBy)) {
+	case "name":
+		field = organization.FieldName
+	case "status":
+		field = organization.FieldStatus
+	case "monthly_budget_usd":
+		field = organization.FieldMonthlyBudgetUsd
+	case "id":
+		field = organization.FieldID
+	}
+
+	order := dbent.Desc
+	if sortOrder == pagination.SortOrderAsc {
+		order = dbent.Asc
+	}
+	if field == organization.FieldID {
+		return []func(*entsql.Selector){order(field)}
+	}
+	return []func(*entsql.Selector){order(field), order(organization.FieldID)}
+}
+
+func (r *organizationRepository) CountMembers(ctx context.Context, orgID int64) (int64, error) {
+	n, err := r.client.User.Query().
+		Where(dbuser.OrganizationIDEQ(orgID)).
+		Count(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int64(n), nil
+}
+
+func (r *organizationRepository) GetSpendSince(ctx context.Context, orgID int64, since time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(ul.actual_cost), 0)
+		FROM usage_logs ul
+		JOIN users u ON u.id = ul.user_id
+		WHERE u.organization_id = $1 AND ul.created_at >= $2
+	`
+	var spend float64
+	if err := scanSingleRow(ctx, r.sql, query, []any{orgID, since}, &spend); err != nil {
+		return 0, err
+	}
+	return spend, nil
+}
+
+func organizationEntityToService(m *dbent.Organization) *service.Organization {
+	if m == nil {
+		return nil
+	}
+	return &service.Organization{
+		ID:               m.ID,
+		Name:             m.Name,
+		Description:      m.Description,
+		Status:           m.Status,
+		MonthlyBudgetUSD: m.MonthlyBudgetUsd,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+	}
+}
@@ -20,6 +20,9 @@ type proxyLatencyCache struct {
 }
 
 func NewProxyLatencyCache(rdb *redis.Client) service.ProxyLatencyCache {
+	if rdb == nil {
+		return nil
+	}
 	return &proxyLatencyCache{rdb: rdb}
 }
 
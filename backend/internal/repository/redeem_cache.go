@@ -30,6 +30,9 @@ type redeemCache struct {
 }
 
 func NewRedeemCache(rdb *redis.Client) service.RedeemCache {
+	if rdb == nil {
+		return nil
+	}
 	return &redeemCache{rdb: rdb}
 }
 
@@ -37,6 +37,9 @@ type refreshTokenCache struct {
 
 // NewRefreshTokenCache creates a new RefreshTokenCache implementation.
 func NewRefreshTokenCache(rdb *redis.Client) service.RefreshTokenCache {
+	if rdb == nil {
+		return nil
+	}
 	return &refreshTokenCache{rdb: rdb}
 }
 
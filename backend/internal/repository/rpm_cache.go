@@ -42,6 +42,9 @@ type RPMCacheImpl struct {
 
 // NewRPMCache 创建 RPM 计数器缓存
 func NewRPMCache(rdb *redis.Client) service.RPMCache {
+	if rdb == nil {
+		return nil
+	}
 	return &RPMCacheImpl{rdb: rdb}
 }
 
@@ -76,6 +76,9 @@ type schedulerCache struct {
 }
 
 func NewSchedulerCache(rdb *redis.Client) service.SchedulerCache {
+	if rdb == nil {
+		return nil
+	}
 	return newSchedulerCacheWithChunkSizes(rdb, defaultSchedulerSnapshotMGetChunkSize, defaultSchedulerSnapshotWriteChunkSize)
 }
 
@@ -434,6 +437,9 @@ func buildSchedulerMetadataAccount(account service.Account) service.Account {
 		Type:                    account.Type,
 		Concurrency:             account.Concurrency,
 		LoadFactor:              account.LoadFactor,
+		OwnerUserID:             account.OwnerUserID,
+		Tags:                    account.Tags,
+		TagsExclusive:           account.TagsExclusive,
 		Priority:                account.Priority,
 		RateMultiplier:          account.RateMultiplier,
 		Status:                  account.Status,
@@ -546,6 +552,9 @@ func filterSchedulerExtra(extra map[string]any) map[string]any {
 		"responses_websockets_v2_enabled",
 		"openai_ws_enabled",
 		"openai_ws_force_http",
+		"maintenance_windows",
+		"maintenance_timezone",
+		"upstream_rate_limit",
 	}
 	filtered := make(map[string]any)
 	for _, key := range keys {
@@ -38,6 +38,9 @@ type tempUnschedCache struct {
 }
 
 func NewTempUnschedCache(rdb *redis.Client) service.TempUnschedCache {
+	if rdb == nil {
+		return nil
+	}
 	return &tempUnschedCache{rdb: rdb}
 }
 
@@ -31,6 +31,9 @@ type timeoutCounterCache struct {
 
 // NewTimeoutCounterCache 创建超时计数器缓存实例
 func NewTimeoutCounterCache(rdb *redis.Client) service.TimeoutCounterCache {
+	if rdb == nil {
+		return nil
+	}
 	return &timeoutCounterCache{rdb: rdb}
 }
 
@@ -26,6 +26,9 @@ type tlsFingerprintProfileCache struct {
 
 // NewTLSFingerprintProfileCache 创建 TLS 指纹模板缓存
 func NewTLSFingerprintProfileCache(rdb *redis.Client) service.TLSFingerprintProfileCache {
+	if rdb == nil {
+		return nil
+	}
 	return &tlsFingerprintProfileCache{
 		rdb: rdb,
 	}
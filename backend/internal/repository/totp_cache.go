@@ -25,6 +25,9 @@ type TotpCache struct {
 
 // NewTotpCache creates a new TOTP cache
 func NewTotpCache(rdb *redis.Client) service.TotpCache {
+	if rdb == nil {
+		return nil
+	}
 	return &TotpCache{rdb: rdb}
 }
 
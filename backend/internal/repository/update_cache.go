@@ -15,6 +15,9 @@ type updateCache struct {
 }
 
 func NewUpdateCache(rdb *redis.Client) service.UpdateCache {
+	if rdb == nil {
+		return nil
+	}
 	return &updateCache{rdb: rdb}
 }
 
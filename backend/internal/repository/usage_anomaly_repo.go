@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+type usageAnomalyRepository struct {
+	sql sqlExecutor
+}
+
+// NewUsageAnomalyRepository creates the repository backing API key usage anomaly detection.
+func NewUsageAnomalyRepository(sqlDB *sql.DB) service.UsageAnomalyRepository {
+	return &usageAnomalyRepository{sql: sqlDB}
+}
+
+func (r *usageAnomalyRepository) ListAPIKeyTokenWindows(ctx context.Context, baselineStart, currentStart, end time.Time, minTokens int64, limit int) (results []service.APIKeyTokenWindowUsage, err error) {
+	query := `
+		WITH windows AS (
+			SELECT
+				api_key_id,
+				COALESCE(SUM(CASE WHEN created_at >= $2 THEN input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens ELSE 0 END), 0) AS current_tokens,
+				COALESCE(SUM(CASE WHEN created_at < $2 THEN input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens ELSE 0 END), 0) AS baseline_tokens
+			FROM usage_logs
+			WHERE created_at >= $1 AND created_at < $3
+			GROUP BY api_key_id
+		)
+		SELECT w.api_key_id, COALESCE(k.name, ''), COALESCE(k.user_id, 0), w.current_tokens, w.baseline_tokens
+		FROM windows w
+		LEFT JOIN api_keys k ON k.id = w.api_key_id
+		WHERE w.current_tokens > 0 AND w.current_tokens >= $4
+		ORDER BY w.current_tokens DESC
+		LIMIT $5
+	`
+	rows, err := r.sql.QueryContext(ctx, query, baselineStart, currentStart, end, minTokens, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+			results = nil
+		}
+	}()
+
+	results = make([]service.APIKeyTokenWindowUsage, 0)
+	for rows.Next() {
+		var row service.APIKeyTokenWindowUsage
+		if err = rows.Scan(&row.APIKeyID, &row.KeyName, &row.UserID, &row.CurrentTokens, &row.BaselineTokens); err != nil {
+			return nil, err
+		}
+		results = append(results, row)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageAnomalyRepositoryListAPIKeyTokenWindows(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := NewUsageAnomalyRepository(db)
+
+	end := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	currentStart := end.Add(-time.Hour)
+	baselineStart := currentStart.Add(-24 * time.Hour)
+
+	mock.ExpectQuery("FROM usage_logs").
+		WithArgs(baselineStart, currentStart, end, int64(1000), 50).
+		WillReturnRows(sqlmock.NewRows([]string{"api_key_id", "name", "user_id", "current_tokens", "baseline_tokens"}).
+			AddRow(int64(7), "leaky", int64(3), int64(90000), int64(24000)))
+
+	rows, err := repo.ListAPIKeyTokenWindows(context.Background(), baselineStart, currentStart, end, 1000, 50)
+	require.NoError(t, err)
+	require.Equal(t, []service.APIKeyTokenWindowUsage{
+		{APIKeyID: 7, KeyName: "leaky", UserID: 3, CurrentTokens: 90000, BaselineTokens: 24000},
+	}, rows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const usageBillingDedupKeyPrefix = "usage_billing:dedup:"
+
+type usageBillingDedupCache struct {
+	rdb *redis.Client
+}
+
+func NewUsageBillingDedupCache(rdb *redis.Client) service.UsageBillingDedupCache {
+	if rdb == nil {
+		return nil
+	}
+	return &usageBillingDedupCache{rdb: rdb}
+}
+
+func (c *usageBillingDedupCache) ClaimUsageBilling(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, usageBillingDedupKeyPrefix+key, "processing", ttl).Result()
+}
+
+func (c *usageBillingDedupCache) MarkUsageBillingApplied(ctx context.Context, key string, ttl time.Duration) error {
+	return c.rdb.Set(ctx, usageBillingDedupKeyPrefix+key, "applied", ttl).Err()
+}
+
+func (c *usageBillingDedupCache) ReleaseUsageBilling(ctx context.Context, key string) error {
+	return c.rdb.Del(ctx, usageBillingDedupKeyPrefix+key).Err()
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/lib/pq"
+)
+
+type usageEventDeadLetterRepository struct {
+	db *sql.DB
+}
+
+func NewUsageEventDeadLetterRepository(db *sql.DB) service.UsageEventDeadLetterRepository {
+	return &usageEventDeadLetterRepository{db: db}
+}
+
+func (r *usageEventDeadLetterRepository) Insert(ctx context.Context, letters []*service.UsageEventDeadLetter) (err error) {
+	if len(letters) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO usage_event_dead_letters
+			(event_id, backend, payload, reason, attempts, last_error, created_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+	`)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	for _, letter := range letters {
+		if letter == nil {
+			continue
+		}
+		createdAt := letter.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		nextAttemptAt := letter.NextAttemptAt
+		if nextAttemptAt.IsZero() {
+			nextAttemptAt = createdAt
+		}
+		if _, err = stmt.ExecContext(ctx,
+			letter.EventID, letter.Backend, string(letter.Payload), letter.Reason,
+			letter.Attempts, letter.LastError, createdAt, nextAttemptAt,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *usageEventDeadLetterRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*service.UsageEventDeadLetter, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, event_id, backend, payload, reason, attempts, COALESCE(last_error, ''), created_at, next_attempt_at
+		FROM usage_event_dead_letters
+		WHERE next_attempt_at <= $1
+		ORDER BY id ASC
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	letters := make([]*service.UsageEventDeadLetter, 0, limit)
+	for rows.Next() {
+		letter := &service.UsageEventDeadLetter{}
+		if err := rows.Scan(
+			&letter.ID, &letter.EventID, &letter.Backend, &letter.Payload, &letter.Reason,
+			&letter.Attempts, &letter.LastError, &letter.CreatedAt, &letter.NextAttemptAt,
+		); err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+func (r *usageEventDeadLetterRepository) Delete(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM usage_event_dead_letters WHERE id = ANY($1)`, pq.Array(ids))
+	return err
+}
+
+func (r *usageEventDeadLetterRepository) MarkFailed(ctx context.Context, ids []int64, lastError string, nextAttemptAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE usage_event_dead_letters
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = ANY($1)
+	`, pq.Array(ids), lastError, nextAttemptAt)
+	return err
+}
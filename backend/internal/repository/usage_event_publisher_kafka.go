@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+const (
+	kafkaDialTimeout    = 5 * time.Second
+	kafkaBatchTimeout   = 10 * time.Millisecond
+	kafkaEventIDHeader  = "event_id"
+	kafkaWriterMaxTries = 1
+)
+
+// kafkaUsageEventPublisher 直连 Kafka broker 发布使用记录事件。
+// 同步写入且 acks=all，WriteMessages 在全部 ISR 确认后才返回；任一消息失败即视为整批失败
+// （整批重发，下游按 event_id header 去重）。重试与退避由 UsageEventExporter 统一负责。
+type kafkaUsageEventPublisher struct {
+	writer *kafka.Writer
+}
+
+func newKafkaUsageEventPublisher(cfg config.UsageExportKafkaConfig, batchSize int, timeout time.Duration) (*kafkaUsageEventPublisher, error) {
+	brokers := make([]string, 0, len(cfg.Brokers))
+	for _, broker := range cfg.Brokers {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("kafka brokers are required")
+	}
+	topic := strings.TrimSpace(cfg.Topic)
+	if topic == "" {
+		return nil, fmt.Errorf("kafka topic is required")
+	}
+
+	transport := &kafka.Transport{
+		DialTimeout: kafkaDialTimeout,
+		ClientID:    "sub2api-usage-export",
+	}
+	if cfg.TLS {
+		transport.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if cfg.Username != "" || cfg.Password != "" {
+		transport.SASL = plain.Mechanism{Username: cfg.Username, Password: cfg.Password}
+	}
+
+	return &kafkaUsageEventPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			MaxAttempts:  kafkaWriterMaxTries,
+			BatchSize:    batchSize,
+			BatchTimeout: kafkaBatchTimeout,
+			ReadTimeout:  timeout,
+			WriteTimeout: timeout,
+			RequiredAcks: kafka.RequireAll,
+			Transport:    transport,
+		},
+	}, nil
+}
+
+func (p *kafkaUsageEventPublisher) Backend() string {
+	return config.UsageExportBackendKafka
+}
+
+func (p *kafkaUsageEventPublisher) Publish(ctx context.Context, msgs []service.UsageEventMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	return p.writer.WriteMessages(ctx, kafkaMessagesFromUsageEvents(msgs)...)
+}
+
+func (p *kafkaUsageEventPublisher) Close() error {
+	return p.writer.Close()
+}
+
+func kafkaMessagesFromUsageEvents(msgs []service.UsageEventMessage) []kafka.Message {
+	out := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		record := kafka.Message{Value: msg.Payload}
+		if msg.Key != "" {
+			record.Key = []byte(msg.Key)
+		}
+		if msg.EventID != "" {
+			record.Headers = []kafka.Header{{Key: kafkaEventIDHeader, Value: []byte(msg.EventID)}}
+		}
+		out = append(out, record)
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+//go:build unit
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKafkaUsageEventPublisher_ConfiguresWriter(t *testing.T) {
+	p, err := newKafkaUsageEventPublisher(config.UsageExportKafkaConfig{
+		Brokers:  []string{" kafka-1:9092 ", "", "kafka-2:9092"},
+		Topic:    "sub2api.usage",
+		Username: "svc",
+		Password: "secret",
+		TLS:      true,
+	}, 50, 5*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+
+	w := p.writer
+	require.Equal(t, "kafka-1:9092,kafka-2:9092", w.Addr.String())
+	require.Equal(t, "sub2api.usage", w.Topic)
+	require.Equal(t, kafka.RequireAll, w.RequiredAcks)
+	require.False(t, w.Async)
+	require.Equal(t, 50, w.BatchSize)
+	require.Equal(t, 5*time.Second, w.WriteTimeout)
+
+	transport, ok := w.Transport.(*kafka.Transport)
+	require.True(t, ok)
+	require.NotNil(t, transport.TLS)
+	require.Equal(t, plain.Mechanism{Username: "svc", Password: "secret"}, transport.SASL)
+}
+
+func TestNewKafkaUsageEventPublisher_RequiresBrokersAndTopic(t *testing.T) {
+	_, err := newKafkaUsageEventPublisher(config.UsageExportKafkaConfig{Brokers: []string{" "}, Topic: "t"}, 10, time.Second)
+	require.ErrorContains(t, err, "brokers")
+
+	_, err = newKafkaUsageEventPublisher(config.UsageExportKafkaConfig{Brokers: []string{"kafka:9092"}}, 10, time.Second)
+	require.ErrorContains(t, err, "topic")
+}
+
+func TestKafkaMessagesFromUsageEvents(t *testing.T) {
+	msgs := kafkaMessagesFromUsageEvents([]service.UsageEventMessage{
+		{EventID: "evt-1", Key: "42", Payload: []byte(`{"a":1}`)},
+		{Payload: []byte(`{"b":2}`)},
+	})
+	require.Len(t, msgs, 2)
+	require.Equal(t, []byte("42"), msgs[0].Key)
+	require.Equal(t, []byte(`{"a":1}`), msgs[0].Value)
+	require.Equal(t, []kafka.Header{{Key: kafkaEventIDHeader, Value: []byte("evt-1")}}, msgs[0].Headers)
+	require.Nil(t, msgs[1].Key)
+	require.Empty(t, msgs[1].Headers)
+}
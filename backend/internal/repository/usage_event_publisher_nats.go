@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	natsDialTimeout     = 5 * time.Second
+	natsMaxPendingAcks  = 4096
+	natsClientName      = "sub2api-usage-export"
+	natsPubRetryAttempt = 0
+)
+
+// natsUsageEventPublisher 通过 NATS JetStream 发布使用记录事件。
+// 每条消息以 PublishMsgAsync 发出并携带 Nats-Msg-Id（= event_id，JetStream 去重窗口内重复发布只入库一次），
+// 等待整批 PubAck 后才视为发布成功。断线重连与写超时由 nats.go 负责，重试与退避由 UsageEventExporter 统一负责。
+type natsUsageEventPublisher struct {
+	subject string
+	conn    *nats.Conn
+	js      jetstream.JetStream
+}
+
+func newNATSUsageEventPublisher(cfg config.UsageExportNATSConfig, timeout time.Duration) (*natsUsageEventPublisher, error) {
+	url := strings.TrimSpace(cfg.URL)
+	if url == "" {
+		return nil, fmt.Errorf("nats url is required")
+	}
+	if !strings.Contains(url, "://") {
+		url = "nats://" + url
+	}
+	subject := strings.TrimSpace(cfg.Subject)
+	if subject == "" {
+		return nil, fmt.Errorf("nats subject is required")
+	}
+
+	opts := []nats.Option{
+		nats.Name(natsClientName),
+		nats.Timeout(natsDialTimeout),
+		// 每次写出都受 FlusherTimeout 约束，broker 不读时不会无限阻塞
+		nats.FlusherTimeout(timeout),
+		// broker 暂不可用时后台持续重连，期间的事件经重试后进入死信表
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	}
+	if cfg.Username != "" || cfg.Password != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, nats.Token(cfg.Token))
+	}
+	conn, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("connect nats: %w", err)
+	}
+	js, err := jetstream.New(conn,
+		jetstream.WithPublishAsyncMaxPending(natsMaxPendingAcks),
+		jetstream.WithPublishAsyncTimeout(timeout),
+	)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("init jetstream: %w", err)
+	}
+	return &natsUsageEventPublisher{subject: subject, conn: conn, js: js}, nil
+}
+
+func (p *natsUsageEventPublisher) Backend() string {
+	return config.UsageExportBackendNATS
+}
+
+func (p *natsUsageEventPublisher) Publish(ctx context.Context, msgs []service.UsageEventMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	futures := make([]jetstream.PubAckFuture, 0, len(msgs))
+	for _, msg := range msgs {
+		m := nats.NewMsg(p.subject)
+		m.Data = msg.Payload
+		opts := []jetstream.PublishOpt{jetstream.WithRetryAttempts(natsPubRetryAttempt)}
+		if msg.EventID != "" {
+			opts = append(opts, jetstream.WithMsgID(msg.EventID))
+		}
+		future, err := p.js.PublishMsgAsync(m, opts...)
+		if err != nil {
+			return fmt.Errorf("publish to jetstream: %w", err)
+		}
+		futures = append(futures, future)
+	}
+
+	for i, future := range futures {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for %d JetStream acks: %w", len(futures)-i, ctx.Err())
+		case <-future.Ok():
+		case err := <-future.Err():
+			return fmt.Errorf("JetStream rejected message: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *natsUsageEventPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
@@ -0,0 +1,216 @@
+//go:build unit
+
+package repository
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJetStreamServer 模拟最小的 NATS 服务端：握手、订阅收件箱，并对每条 HPUB 回复 PubAck。
+type fakeJetStreamServer struct {
+	t        *testing.T
+	listener net.Listener
+	// ack 根据消息头生成回执（返回 hdr 非空表示以 HMSG 状态消息回复）
+	ack func(header string) (hdr string, payload string)
+
+	mu       sync.Mutex
+	connects []string
+	headers  []string
+	payloads []string
+	conns    int
+}
+
+func newFakeJetStreamServer(t *testing.T) *fakeJetStreamServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeJetStreamServer{t: t, listener: ln}
+	s.ack = func(string) (string, string) { return "", `{"stream":"USAGE","seq":1}` }
+	t.Cleanup(func() { _ = ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeJetStreamServer) url() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+func (s *fakeJetStreamServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns++
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeJetStreamServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	_, _ = io.WriteString(conn, `INFO {"server_id":"fake","headers":true,"max_payload":1048576}`+"\r\n")
+	reader := bufio.NewReader(conn)
+	inbox := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		op, args, _ := strings.Cut(line, " ")
+		switch op {
+		case "CONNECT":
+			s.mu.Lock()
+			s.connects = append(s.connects, args)
+			s.mu.Unlock()
+		case "PING":
+			_, _ = io.WriteString(conn, "PONG\r\n")
+		case "SUB":
+			inbox = strings.TrimSuffix(strings.Fields(args)[0], "*")
+		case "HPUB":
+			fields := strings.Fields(args)
+			hdrLen, _ := strconv.Atoi(fields[2])
+			totalLen, _ := strconv.Atoi(fields[3])
+			buf := make([]byte, totalLen+2)
+			if _, err := io.ReadFull(reader, buf); err != nil {
+				return
+			}
+			header := string(buf[:hdrLen])
+			s.mu.Lock()
+			s.headers = append(s.headers, header)
+			s.payloads = append(s.payloads, string(buf[hdrLen:totalLen]))
+			ack := s.ack
+			s.mu.Unlock()
+			require.True(s.t, strings.HasPrefix(fields[1], inbox))
+
+			hdr, payload := ack(header)
+			if hdr != "" {
+				fmt.Fprintf(conn, "HMSG %s 1 %d %d\r\n%s%s\r\n", fields[1], len(hdr), len(hdr)+len(payload), hdr, payload)
+			} else {
+				fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", fields[1], len(payload), payload)
+			}
+		}
+	}
+}
+
+func TestNATSUsageEventPublisher_PublishWaitsForAcks(t *testing.T) {
+	srv := newFakeJetStreamServer(t)
+	p, err := newNATSUsageEventPublisher(config.UsageExportNATSConfig{URL: srv.url(), Subject: "sub2api.usage", Token: "tok"}, 5*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+
+	msgs := []service.UsageEventMessage{
+		{EventID: "a:1", Payload: []byte(`{"event_id":"a:1"}`)},
+		{EventID: "b:2", Payload: []byte(`{"event_id":"b:2"}`)},
+	}
+	require.NoError(t, p.Publish(context.Background(), msgs))
+	require.NoError(t, p.Publish(context.Background(), msgs[:1]))
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	require.Equal(t, 1, srv.conns, "connection should be reused")
+	require.Len(t, srv.connects, 1)
+	require.Contains(t, srv.connects[0], `"auth_token":"tok"`)
+	require.Contains(t, srv.connects[0], `"headers":true`)
+	require.Equal(t, []string{`{"event_id":"a:1"}`, `{"event_id":"b:2"}`, `{"event_id":"a:1"}`}, srv.payloads)
+	require.Contains(t, srv.headers[1], "Nats-Msg-Id: b:2\r\n")
+}
+
+func TestNATSUsageEventPublisher_AckErrors(t *testing.T) {
+	srv := newFakeJetStreamServer(t)
+	p, err := newNATSUsageEventPublisher(config.UsageExportNATSConfig{URL: srv.url(), Subject: "sub2api.usage"}, 5*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+	msgs := []service.UsageEventMessage{{EventID: "a:1", Payload: []byte(`{}`)}}
+
+	srv.mu.Lock()
+	srv.ack = func(string) (string, string) { return "NATS/1.0 503\r\n\r\n", "" }
+	srv.mu.Unlock()
+	require.ErrorIs(t, p.Publish(context.Background(), msgs), jetstream.ErrNoStreamResponse)
+
+	srv.mu.Lock()
+	srv.ack = func(string) (string, string) {
+		return "", `{"error":{"code":503,"err_code":10077,"description":"maximum messages exceeded"}}`
+	}
+	srv.mu.Unlock()
+	require.ErrorContains(t, p.Publish(context.Background(), msgs), "maximum messages exceeded")
+
+	srv.mu.Lock()
+	srv.ack = func(string) (string, string) { return "", `{"stream":"USAGE","seq":9}` }
+	srv.mu.Unlock()
+	require.NoError(t, p.Publish(context.Background(), msgs))
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	require.Equal(t, 1, srv.conns, "ack errors should not drop the connection")
+}
+
+func TestNATSUsageEventPublisher_AckTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = io.WriteString(conn, `INFO {"headers":true,"max_payload":1048576}`+"\r\n")
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "PING") {
+				_, _ = io.WriteString(conn, "PONG\r\n")
+			}
+		}
+	}()
+
+	p, err := newNATSUsageEventPublisher(config.UsageExportNATSConfig{URL: ln.Addr().String(), Subject: "sub2api.usage"}, 5*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = p.Publish(ctx, []service.UsageEventMessage{{EventID: "a:1", Payload: []byte(`{}`)}})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNewNATSUsageEventPublisher_Validation(t *testing.T) {
+	_, err := newNATSUsageEventPublisher(config.UsageExportNATSConfig{Subject: "s"}, time.Second)
+	require.ErrorContains(t, err, "url")
+
+	_, err = newNATSUsageEventPublisher(config.UsageExportNATSConfig{URL: "nats://127.0.0.1:4222"}, time.Second)
+	require.ErrorContains(t, err, "subject")
+}
+
+func TestNewNATSUsageEventPublisher_BrokerDownKeepsReconnecting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	// broker 不可达不是初始化错误：连接在后台重连，期间的发布失败交由导出器重试/死信
+	p, err := newNATSUsageEventPublisher(config.UsageExportNATSConfig{URL: addr, Subject: "sub2api.usage"}, time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+	require.False(t, p.conn.IsConnected())
+}
@@ -638,7 +638,10 @@ func (s *UsageLogRepoSuite) TestGetUserStats() {
 func (s *UsageLogRepoSuite) TestListWithFilters() {
 	user := mustCreateUser(s.T(), s.client, &service.User{Email: "filters@test.com"})
 	apiKey := mustCreateApiKey(s.T(), s.client, &service.APIKey{UserID: user.ID, Key: "sk-filters", Name: "k"})
-	account := mustCreateAccount(s.T(), s.client, &service.Account{Name: "acc-filters"})
+	account := mustCreateAccount(s.T(), s.client, &service.Account{
+		Name:        "acc-filters",
+		Credentials: map[string]any{"api_key": "sk-secret"},
+	})
 
 	s.createUsageLog(user, apiKey, account, 10, 20, 0.5, time.Now())
 
@@ -647,6 +650,9 @@ func (s *UsageLogRepoSuite) TestListWithFilters() {
 	s.Require().NoError(err, "ListWithFilters")
 	s.Require().Len(logs, 1)
 	s.Require().Equal(int64(1), page.Total)
+	s.Require().NotNil(logs[0].Account)
+	s.Require().Equal("acc-filters", logs[0].Account.Name)
+	s.Require().Nil(logs[0].Account.Credentials, "usage log accounts must not carry credentials")
 }
 
 // --- GetDashboardStats ---
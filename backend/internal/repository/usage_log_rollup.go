@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+)
+
+// usageRollupSource describes which per-dimension rollup table can answer a query for a time range.
+// Rollups are maintained by the dashboard aggregation job (see dashboardAggregationRepository).
+type usageRollupSource struct {
+	table      string
+	bucketExpr string
+	rangeCond  string
+	rangeArgs  []any
+}
+
+// selectUsageRollup picks the coarsest rollup whose buckets exactly tile [startTime, endTime).
+// Daily rollups are only eligible when allowDaily is set (i.e. the output is not finer than a day).
+func (r *usageLogRepository) selectUsageRollup(startTime, endTime time.Time, allowDaily bool) (usageRollupSource, bool) {
+	if r == nil || !r.rollupsEnabled || !endTime.After(startTime) {
+		return usageRollupSource{}, false
+	}
+	loc := timezone.Location()
+	startLocal := startTime.In(loc)
+	endLocal := endTime.In(loc)
+	if allowDaily && isLocalDayBoundary(startLocal) && isLocalDayBoundary(endLocal) {
+		// Dates are passed as strings so the cast does not depend on the DB session timezone.
+		return usageRollupSource{
+			table:      "usage_daily_summaries",
+			bucketExpr: "bucket_date::timestamp",
+			rangeCond:  "bucket_date >= $1::date AND bucket_date < $2::date",
+			rangeArgs:  []any{startLocal.Format("2006-01-02"), endLocal.Format("2006-01-02")},
+		}, true
+	}
+	if isLocalHourBoundary(startLocal) && isLocalHourBoundary(endLocal) {
+		return usageRollupSource{
+			table:      "usage_hourly_summaries",
+			bucketExpr: "bucket_start",
+			rangeCond:  "bucket_start >= $1 AND bucket_start < $2",
+			rangeArgs:  []any{startTime, endTime},
+		}, true
+	}
+	return usageRollupSource{}, false
+}
+
+func isLocalHourBoundary(t time.Time) bool {
+	return t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+func isLocalDayBoundary(t time.Time) bool {
+	return t.Hour() == 0 && isLocalHourBoundary(t)
+}
+
+// appendUsageRollupFilters mirrors the raw usage_logs filters against rollup columns. Rollups store the
+// normalized request_type, so a request type that cannot be normalized is not answerable from them.
+func appendUsageRollupFilters(query string, args []any, userID, apiKeyID, accountID, groupID int64, model string, requestType *int16, stream *bool, billingType *int8) (string, []any, bool) {
+	if userID > 0 {
+		query += fmt.Sprintf(" AND user_id = $%d", len(args)+1)
+		args = append(args, userID)
+	}
+	if apiKeyID > 0 {
+		query += fmt.Sprintf(" AND api_key_id = $%d", len(args)+1)
+		args = append(args, apiKeyID)
+	}
+	if accountID > 0 {
+		query += fmt.Sprintf(" AND account_id = $%d", len(args)+1)
+		args = append(args, accountID)
+	}
+	if groupID > 0 {
+		query += fmt.Sprintf(" AND group_id = $%d", len(args)+1)
+		args = append(args, groupID)
+	}
+	query, args = appendRawUsageLogModelQueryFilter(query, args, model)
+	if requestType != nil {
+		normalized := service.RequestTypeFromInt16(*requestType)
+		if normalized == service.RequestTypeUnknown {
+			return query, args, false
+		}
+		query += fmt.Sprintf(" AND request_type = $%d", len(args)+1)
+		args = append(args, int16(normalized))
+	} else if stream != nil {
+		query += fmt.Sprintf(" AND stream = $%d", len(args)+1)
+		args = append(args, *stream)
+	}
+	if billingType != nil {
+		query += fmt.Sprintf(" AND billing_type = $%d", len(args)+1)
+		args = append(args, int16(*billingType))
+	}
+	return query, args, true
+}
+
+// getUsageTrendFromRollups answers a filtered trend query from rollups. ok=false means the range or
+// filters are not answerable from rollups and the caller should query raw logs.
+func (r *usageLogRepository) getUsageTrendFromRollups(ctx context.Context, startTime, endTime time.Time, granularity string, userID, apiKeyID, accountID, groupID int64, model string, requestType *int16, stream *bool, billingType *int8) (results []TrendDataPoint, ok bool, err error) {
+	dateFormat := safeDateFormat(granularity)
+	src, ok := r.selectUsageRollup(startTime, endTime, dateFormat != dateFormatWhitelist["hour"])
+	if !ok {
+		return nil, false, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			TO_CHAR(%s, '%s') as date,
+			COALESCE(SUM(total_requests), 0) as requests,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(cache_creation_tokens), 0) as cache_creation_tokens,
+			COALESCE(SUM(cache_read_tokens), 0) as cache_read_tokens,
+			COALESCE(SUM(input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens), 0) as total_tokens,
+			COALESCE(SUM(total_cost), 0) as cost,
+			COALESCE(SUM(actual_cost), 0) as actual_cost
+		FROM %s
+		WHERE %s
+	`, src.bucketExpr, dateFormat, src.table, src.rangeCond)
+	query, args, ok := appendUsageRollupFilters(query, src.rangeArgs, userID, apiKeyID, accountID, groupID, model, requestType, stream, billingType)
+	if !ok {
+		return nil, false, nil
+	}
+	query += " GROUP BY date ORDER BY date ASC"
+
+	rows, err := r.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, true, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+			results = nil
+		}
+	}()
+
+	results, err = scanTrendRows(rows)
+	if err != nil {
+		return nil, true, err
+	}
+	return results, true, nil
+}
+
+// getModelStatsFromRollups answers a filtered model stats query from rollups; see getUsageTrendFromRollups.
+func (r *usageLogRepository) getModelStatsFromRollups(ctx context.Context, startTime, endTime time.Time, userID, apiKeyID, accountID, groupID int64, requestType *int16, stream *bool, billingType *int8, source string) (results []ModelStat, ok bool, err error) {
+	src, ok := r.selectUsageRollup(startTime, endTime, true)
+	if !ok {
+		return nil, false, nil
+	}
+
+	actualCostExpr := "COALESCE(SUM(actual_cost), 0) as actual_cost"
+	// 与原始日志查询一致：仅按 account_id 聚合时，实际费用使用账号倍率费用。
+	if accountID > 0 && userID == 0 && apiKeyID == 0 {
+		actualCostExpr = "COALESCE(SUM(account_cost), 0) as actual_cost"
+	}
+	modelExpr := resolveRollupModelDimensionExpression(source)
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s as model,
+			COALESCE(SUM(total_requests), 0) as requests,
+			COALESCE(SUM(input_tokens), 0) as input_tokens,
+			COALESCE(SUM(output_tokens), 0) as output_tokens,
+			COALESCE(SUM(cache_creation_tokens), 0) as cache_creation_tokens,
+			COALESCE(SUM(cache_read_tokens), 0) as cache_read_tokens,
+			COALESCE(SUM(input_tokens + output_tokens + cache_creation_tokens + cache_read_tokens), 0) as total_tokens,
+			COALESCE(SUM(total_cost), 0) as cost,
+			%s,
+			COALESCE(SUM(account_cost), 0) as account_cost
+		FROM %s
+		WHERE %s
+	`, modelExpr, actualCostExpr, src.table, src.rangeCond)
+	query, args, ok := appendUsageRollupFilters(query, src.rangeArgs, userID, apiKeyID, accountID, groupID, "", requestType, stream, billingType)
+	if !ok {
+		return nil, false, nil
+	}
+	query += fmt.Sprintf(" GROUP BY %s ORDER BY total_tokens DESC", modelExpr)
+
+	rows, err := r.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, true, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil && err == nil {
+			err = closeErr
+			results = nil
+		}
+	}()
+
+	results, err = scanModelStatsRows(rows)
+	if err != nil {
+		return nil, true, err
+	}
+	return results, true, nil
+}
+
+// resolveRollupModelDimensionExpression is the rollup counterpart of resolveModelDimensionExpression:
+// requested_model / upstream_model are already resolved with the same fallbacks when rolled up.
+func resolveRollupModelDimensionExpression(modelType string) string {
+	switch usagestats.NormalizeModelSource(modelType) {
+	case usagestats.ModelSourceUpstream:
+		return "upstream_model"
+	case usagestats.ModelSourceMapping:
+		return "(requested_model || ' -> ' || upstream_model)"
+	default:
+		return "requested_model"
+	}
+}
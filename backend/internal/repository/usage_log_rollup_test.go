@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+var rollupTrendColumns = []string{"date", "requests", "input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens", "total_tokens", "cost", "actual_cost"}
+
+func TestUsageLogRepositoryTrendReadsDailyRollupForDayAlignedRange(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db, rollupsEnabled: true}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, timezone.Location())
+	end := start.AddDate(0, 0, 7)
+
+	mock.ExpectQuery("FROM usage_daily_summaries\\s+WHERE bucket_date >= \\$1::date AND bucket_date < \\$2::date AND user_id = \\$3").
+		WithArgs("2025-01-01", "2025-01-08", int64(7)).
+		WillReturnRows(sqlmock.NewRows(rollupTrendColumns).AddRow("2025-01-01", 3, 10, 20, 0, 0, 30, 0.3, 0.2))
+
+	trend, err := repo.GetUsageTrendWithFilters(context.Background(), start, end, "day", 7, 0, 0, 0, "", nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, trend, 1)
+	require.Equal(t, int64(3), trend[0].Requests)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageLogRepositoryTrendReadsHourlyRollupForHourGranularity(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db, rollupsEnabled: true}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, timezone.Location())
+	end := start.Add(24 * time.Hour)
+	requestType := int16(service.RequestTypeStream)
+
+	mock.ExpectQuery("FROM usage_hourly_summaries\\s+WHERE bucket_start >= \\$1 AND bucket_start < \\$2 AND request_type = \\$3").
+		WithArgs(start, end, requestType).
+		WillReturnRows(sqlmock.NewRows(rollupTrendColumns).AddRow("2025-01-01 00:00", 1, 1, 1, 0, 0, 2, 0.1, 0.1))
+
+	trend, err := repo.GetUsageTrendWithFilters(context.Background(), start, end, "hour", 0, 0, 0, 0, "", &requestType, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, trend, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUsageLogRepositoryTrendFallsBackToRawLogs(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 30, 0, 0, timezone.Location())
+	end := start.Add(2 * time.Hour)
+
+	t.Run("unaligned range", func(t *testing.T) {
+		db, mock := newSQLMock(t)
+		repo := &usageLogRepository{sql: db, rollupsEnabled: true}
+		mock.ExpectQuery("FROM usage_logs").
+			WithArgs(start, end, int64(7)).
+			WillReturnRows(sqlmock.NewRows(rollupTrendColumns))
+
+		_, err := repo.GetUsageTrendWithFilters(context.Background(), start, end, "hour", 7, 0, 0, 0, "", nil, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty rollup", func(t *testing.T) {
+		db, mock := newSQLMock(t)
+		repo := &usageLogRepository{sql: db, rollupsEnabled: true}
+		alignedStart := start.Truncate(time.Hour)
+		mock.ExpectQuery("FROM usage_hourly_summaries").
+			WillReturnRows(sqlmock.NewRows(rollupTrendColumns))
+		mock.ExpectQuery("FROM usage_logs").
+			WithArgs(alignedStart, end.Truncate(time.Hour), int64(7)).
+			WillReturnRows(sqlmock.NewRows(rollupTrendColumns))
+
+		_, err := repo.GetUsageTrendWithFilters(context.Background(), alignedStart, end.Truncate(time.Hour), "hour", 7, 0, 0, 0, "", nil, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown request type", func(t *testing.T) {
+		db, mock := newSQLMock(t)
+		repo := &usageLogRepository{sql: db, rollupsEnabled: true}
+		requestType := int16(service.RequestTypeUnknown)
+		mock.ExpectQuery("FROM usage_logs").
+			WillReturnRows(sqlmock.NewRows(rollupTrendColumns))
+
+		_, err := repo.GetUsageTrendWithFilters(context.Background(), start.Truncate(time.Hour), end.Truncate(time.Hour), "hour", 0, 0, 0, 0, "", &requestType, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUsageLogRepositoryModelStatsReadsRollupWithAccountCost(t *testing.T) {
+	db, mock := newSQLMock(t)
+	repo := &usageLogRepository{sql: db, rollupsEnabled: true}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, timezone.Location())
+	end := start.AddDate(0, 0, 1)
+
+	mock.ExpectQuery("\\(requested_model \\|\\| ' -> ' \\|\\| upstream_model\\) as model.*COALESCE\\(SUM\\(account_cost\\), 0\\) as actual_cost.*FROM usage_daily_summaries.*AND account_id = \\$3").
+		WithArgs("2025-01-01", "2025-01-02", int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"model", "requests", "input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens", "total_tokens", "cost", "actual_cost", "account_cost"}).
+			AddRow("claude -> claude-upstream", 2, 5, 5, 0, 0, 10, 1.0, 1.5, 1.5))
+
+	stats, err := repo.GetModelStatsWithFiltersBySource(context.Background(), start, end, 0, 0, 9, 0, nil, nil, nil, usagestats.ModelSourceMapping)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.Equal(t, "claude -> claude-upstream", stats[0].Model)
+	require.InDelta(t, 1.5, stats[0].ActualCost, 1e-9)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -61,6 +61,9 @@ type userMsgQueueCache struct {
 
 // NewUserMsgQueueCache 创建用户消息队列缓存
 func NewUserMsgQueueCache(rdb *redis.Client) service.UserMsgQueueCache {
+	if rdb == nil {
+		return nil
+	}
 	return &userMsgQueueCache{rdb: rdb}
 }
 
@@ -30,6 +30,9 @@ type userRPMCacheImpl struct {
 
 // NewUserRPMCache 创建用户/分组级 RPM 计数器。
 func NewUserRPMCache(rdb *redis.Client) service.UserRPMCache {
+	if rdb == nil {
+		return nil
+	}
 	return &userRPMCacheImpl{rdb: rdb}
 }
 
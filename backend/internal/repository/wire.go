@@ -3,10 +3,14 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	entsql "entgo.io/ent/dialect/sql"
 	"github.com/Wei-Shaw/sub2api/ent"
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
@@ -14,6 +18,7 @@ import (
 
 // ProvideConcurrencyCache 创建并发控制缓存，从配置读取 TTL 参数
 // 性能优化：TTL 可配置，支持长时间运行的 LLM 请求场景
+// concurrency.backend=memory 时使用进程内实现，不依赖 Redis
 func ProvideConcurrencyCache(rdb *redis.Client, cfg *config.Config) service.ConcurrencyCache {
 	waitTTLSeconds := int(cfg.Gateway.Scheduling.StickySessionWaitTimeout.Seconds())
 	if cfg.Gateway.Scheduling.FallbackWaitTimeout > cfg.Gateway.Scheduling.StickySessionWaitTimeout {
@@ -22,9 +27,66 @@ func ProvideConcurrencyCache(rdb *redis.Client, cfg *config.Config) service.Conc
 	if waitTTLSeconds <= 0 {
 		waitTTLSeconds = cfg.Gateway.ConcurrencySlotTTLMinutes * 60
 	}
+	if usesMemoryConcurrencyBackend(cfg) {
+		logger.LegacyPrintf("repository.concurrency", "Concurrency backend: memory (slots and wait queues are local to this instance)")
+		return NewMemoryConcurrencyCache(cfg.Gateway.ConcurrencySlotTTLMinutes, waitTTLSeconds)
+	}
 	return NewConcurrencyCache(rdb, cfg.Gateway.ConcurrencySlotTTLMinutes, waitTTLSeconds)
 }
 
+// ProvideGatewayCache 创建粘性会话缓存：gateway.sticky_session_store.backend 优先，留空时跟随 concurrency.backend
+func ProvideGatewayCache(rdb *redis.Client, cfg *config.Config) service.GatewayCache {
+	if usesMemoryStickySessionStore(cfg) {
+		logger.LegacyPrintf("repository.gateway_cache", "Sticky session store: memory (bindings are local to this instance)")
+		return NewMemoryGatewayCache()
+	}
+	return NewGatewayCache(rdb)
+}
+
+// ProvideIdentityCache 创建账号指纹缓存；concurrency.backend=memory 时使用进程内实现，保证同一账号的指纹在请求间保持稳定
+func ProvideIdentityCache(rdb *redis.Client, cfg *config.Config) service.IdentityCache {
+	if usesMemoryConcurrencyBackend(cfg) {
+		return NewMemoryIdentityCache()
+	}
+	return NewIdentityCache(rdb)
+}
+
+func usesMemoryStickySessionStore(cfg *config.Config) bool {
+	if cfg == nil {
+		return false
+	}
+	if backend := strings.ToLower(strings.TrimSpace(cfg.Gateway.StickySessionStore.Backend)); backend != "" {
+		return backend == config.ConcurrencyBackendMemory
+	}
+	return usesMemoryConcurrencyBackend(cfg)
+}
+
+func usesMemoryConcurrencyBackend(cfg *config.Config) bool {
+	return cfg != nil && strings.EqualFold(strings.TrimSpace(cfg.Concurrency.Backend), config.ConcurrencyBackendMemory)
+}
+
+// ProvideUsageBillingRepository 创建记账仓储，并在前面叠加 Redis 幂等键预检
+// concurrency.backend=memory 时不部署 Redis，仅依赖数据库去重
+func ProvideUsageBillingRepository(client *ent.Client, sqlDB *sql.DB, rdb *redis.Client, cfg *config.Config) service.UsageBillingRepository {
+	repo := NewUsageBillingRepository(client, sqlDB)
+	if usesMemoryConcurrencyBackend(cfg) {
+		return repo
+	}
+	return service.NewDedupUsageBillingRepository(repo, NewUsageBillingDedupCache(rdb), cfg)
+}
+
+// ProvideBillingDBPinger 以共享的 *sql.DB 作为计费数据库健康探测
+func ProvideBillingDBPinger(sqlDB *sql.DB) service.BillingDBPinger {
+	return sqlDB
+}
+
+// ProvideUsageLogRepository 创建使用日志仓储；启用看板聚合时，带过滤条件的趋势 / 模型统计优先读取维度汇总表
+func ProvideUsageLogRepository(client *ent.Client, sqlDB *sql.DB, cfg *config.Config) service.UsageLogRepository {
+	repo := newUsageLogRepositoryWithSQL(client, sqlDB)
+	repo.rollupsEnabled = cfg != nil && cfg.DashboardAgg.Enabled
+	return repo
+}
+
 // ProvideGitHubReleaseClient 创建 GitHub Release 客户端
 // 从配置中读取代理设置，支持国内服务器通过代理访问 GitHub
 func ProvideGitHubReleaseClient(cfg *config.Config) service.GitHubReleaseClient {
@@ -47,6 +109,30 @@ func ProvideSessionLimitCache(rdb *redis.Client, cfg *config.Config) service.Ses
 	return NewSessionLimitCache(rdb, defaultIdleTimeoutMinutes)
 }
 
+// ProvideHTTPUpstream 创建上游 HTTP 客户端，并包装多区域 base URL 的延迟选择与故障切换
+func ProvideHTTPUpstream(cfg *config.Config, accountRepo service.AccountRepository, proxyUpstream *service.ProxyFailoverHTTPUpstream) *service.RegionAwareHTTPUpstream {
+	return service.NewRegionAwareHTTPUpstream(proxyUpstream, accountRepo, cfg)
+}
+
+// ProvideProxyFailoverHTTPUpstream 创建带代理健康检查、备用代理切换与代理并发上限的上游 HTTP 客户端
+func ProvideProxyFailoverHTTPUpstream(
+	cfg *config.Config,
+	proxyRepo service.ProxyRepository,
+	accountRepo service.AccountRepository,
+	prober service.ProxyExitInfoProber,
+	latencyCache service.ProxyLatencyCache,
+) *service.ProxyFailoverHTTPUpstream {
+	var inner service.HTTPUpstream = NewHTTPUpstream(cfg)
+	if cfg.Gateway.MockUpstream.Enabled {
+		// 压测模式：上游请求全部由模拟上游应答
+		logger.LegacyPrintf("repository.http_upstream", "[MockUpstream] enabled: upstream requests are answered by the built-in load test mock")
+		inner = service.NewMockHTTPUpstream(cfg.Gateway.MockUpstream)
+	}
+	upstream := service.NewProxyFailoverHTTPUpstream(inner, proxyRepo, accountRepo, prober, latencyCache, cfg)
+	upstream.Start()
+	return upstream
+}
+
 // ProvideSchedulerCache 创建调度快照缓存，并注入快照分块参数。
 func ProvideSchedulerCache(rdb *redis.Client, cfg *config.Config) service.SchedulerCache {
 	mgetChunkSize := defaultSchedulerSnapshotMGetChunkSize
@@ -59,14 +145,46 @@ func ProvideSchedulerCache(rdb *redis.Client, cfg *config.Config) service.Schedu
 			writeChunkSize = cfg.Gateway.Scheduling.SnapshotWriteChunkSize
 		}
 	}
+	if rdb == nil {
+		return nil
+	}
 	return newSchedulerCacheWithChunkSizes(rdb, mgetChunkSize, writeChunkSize)
 }
 
+// ProvideUsageEventPublisher 按 usage_export.backend 创建使用记录事件发布器；未启用时返回 nil（导出关闭）。
+// 已启用但初始化失败时返回错误，避免导出被静默关闭。
+func ProvideUsageEventPublisher(cfg *config.Config) (service.UsageEventPublisher, error) {
+	if cfg == nil || !cfg.UsageExport.Enabled {
+		return nil, nil
+	}
+	exportCfg := cfg.UsageExport
+	timeout := time.Duration(exportCfg.PublishTimeoutSeconds) * time.Second
+	switch strings.ToLower(strings.TrimSpace(exportCfg.Backend)) {
+	case config.UsageExportBackendNATS:
+		publisher, err := newNATSUsageEventPublisher(exportCfg.NATS, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("init usage event publisher: %w", err)
+		}
+		return publisher, nil
+	default:
+		publisher, err := newKafkaUsageEventPublisher(exportCfg.Kafka, exportCfg.BatchSize, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("init usage event publisher: %w", err)
+		}
+		return publisher, nil
+	}
+}
+
 // ProviderSet is the Wire provider set for all repositories
 var ProviderSet = wire.NewSet(
+	ProvideBillingDBPinger,
+	ProvideUsageEventPublisher,
+	NewUsageEventDeadLetterRepository,
 	NewUserRepository,
 	NewAPIKeyRepository,
 	NewGroupRepository,
+	NewOrganizationRepository,
+	NewAdminAuditRepository,
 	NewAccountRepository,
 	NewScheduledTestPlanRepository,   // 定时测试计划仓储
 	NewScheduledTestResultRepository, // 定时测试结果仓储
@@ -75,13 +193,16 @@ var ProviderSet = wire.NewSet(
 	NewPromoCodeRepository,
 	NewAnnouncementRepository,
 	NewAnnouncementReadRepository,
-	NewUsageLogRepository,
-	NewUsageBillingRepository,
+	ProvideUsageLogRepository,
+	ProvideUsageBillingRepository,
 	NewIdempotencyRepository,
 	NewUsageCleanupRepository,
+	NewUsageAnomalyRepository,
 	NewDashboardAggregationRepository,
 	NewSettingRepository,
 	NewOpsRepository,
+	NewOpsRequestTraceRepository,
+	NewMessageBatchRepository,
 	NewUserSubscriptionRepository,
 	NewUserAttributeDefinitionRepository,
 	NewUserAttributeValueRepository,
@@ -94,7 +215,7 @@ var ProviderSet = wire.NewSet(
 	NewAffiliateRepository,
 
 	// Cache implementations
-	NewGatewayCache,
+	ProvideGatewayCache,
 	NewBillingCache,
 	NewAPIKeyCache,
 	NewTempUnschedCache,
@@ -108,7 +229,7 @@ var ProviderSet = wire.NewSet(
 	NewUserMsgQueueCache,
 	NewDashboardCache,
 	NewEmailCache,
-	NewIdentityCache,
+	ProvideIdentityCache,
 	NewRedeemCache,
 	NewUpdateCache,
 	NewGeminiTokenCache,
@@ -119,6 +240,8 @@ var ProviderSet = wire.NewSet(
 	NewRefreshTokenCache,
 	NewErrorPassthroughCache,
 	NewTLSFingerprintProfileCache,
+	NewConversationStoreCache,
+	NewGatewayIdempotencyCache,
 
 	// Encryptors
 	NewAESEncryptor,
@@ -134,7 +257,9 @@ var ProviderSet = wire.NewSet(
 	NewProxyExitInfoProber,
 	NewClaudeUsageFetcher,
 	NewClaudeOAuthClient,
-	NewHTTPUpstream,
+	ProvideHTTPUpstream,
+	ProvideProxyFailoverHTTPUpstream,
+	wire.Bind(new(service.HTTPUpstream), new(*service.RegionAwareHTTPUpstream)),
 	NewOpenAIOAuthClient,
 	NewGeminiOAuthClient,
 	NewGeminiCliCodeAssistClient,
@@ -189,8 +314,14 @@ func ProvideSQLDB(client *ent.Client) (*sql.DB, error) {
 //   - 速率限制
 //   - 实时统计数据
 //
+// concurrency.backend=memory 时不部署 Redis，返回 nil：
+// 各 Redis 缓存构造函数随之返回 nil，由调用方按未配置缓存处理（跳过缓存或返回不可用错误）。
+//
 // 依赖：config.Config
 // 提供：*redis.Client
 func ProvideRedis(cfg *config.Config) *redis.Client {
+	if usesMemoryConcurrencyBackend(cfg) {
+		return nil
+	}
 	return InitRedis(cfg)
 }
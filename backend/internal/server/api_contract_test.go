@@ -207,6 +207,19 @@ func TestAPIContracts(t *testing.T) {
 					"status": "active",
 					"ip_whitelist": null,
 					"ip_blacklist": null,
+					"scopes": null,
+					"priority": "",
+					"moderation_mode": "",
+					"context_auto_trim": false,
+					"max_body_size": 0,
+					"request_coalescing": false,
+					"token_bucket_burst": 0,
+					"token_bucket_refill_rate": 0,
+					"max_cost_per_request": 0,
+					"account_tag_selector": {},
+					"allowed_platforms": null,
+					"parent_id": 0,
+					"allowed_models": null,
 					"last_used_at": null,
 					"quota": 0,
 					"quota_used": 0,
@@ -256,6 +269,19 @@ func TestAPIContracts(t *testing.T) {
 							"status": "active",
 							"ip_whitelist": null,
 							"ip_blacklist": null,
+							"scopes": null,
+							"priority": "",
+							"moderation_mode": "",
+							"context_auto_trim": false,
+							"max_body_size": 0,
+							"request_coalescing": false,
+							"token_bucket_burst": 0,
+							"token_bucket_refill_rate": 0,
+							"max_cost_per_request": 0,
+							"account_tag_selector": {},
+							"allowed_platforms": null,
+							"parent_id": 0,
+							"allowed_models": null,
 							"last_used_at": null,
 							"quota": 0,
 							"quota_used": 0,
@@ -335,6 +361,7 @@ func TestAPIContracts(t *testing.T) {
 						"require_oauth_only": false,
 						"require_privacy_set": false,
 						"rpm_limit": 0,
+						"priority": "",
 						"created_at": "2025-01-02T03:04:05Z",
 						"updated_at": "2025-01-02T03:04:05Z"
 					}
@@ -538,6 +565,7 @@ func TestAPIContracts(t *testing.T) {
 							"cache_read_tokens": 2,
 							"cache_creation_5m_tokens": 0,
 							"cache_creation_1h_tokens": 0,
+							"cache_hit_ratio": 0.1538,
 							"input_cost": 0,
 							"output_cost": 0,
 							"cache_creation_cost": 0,
@@ -1353,6 +1381,10 @@ func (stubApiKeyCache) DeleteCreateAttemptCount(ctx context.Context, userID int6
 	return nil
 }
 
+func (stubApiKeyCache) ReserveRequestNonce(ctx context.Context, keyID int64, nonce string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
 func (stubApiKeyCache) IncrementDailyUsage(ctx context.Context, apiKey string) error {
 	return nil
 }
@@ -1417,7 +1449,7 @@ func (stubGroupRepo) List(ctx context.Context, params pagination.PaginationParam
 	return nil, nil, errors.New("not implemented")
 }
 
-func (stubGroupRepo) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, status, search string, isExclusive *bool) ([]service.Group, *pagination.PaginationResult, error) {
+func (stubGroupRepo) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, status, search string, isExclusive *bool, organizationID *int64) ([]service.Group, *pagination.PaginationResult, error) {
 	return nil, nil, errors.New("not implemented")
 }
 
@@ -1500,7 +1532,7 @@ func (s *stubAccountRepo) List(ctx context.Context, params pagination.Pagination
 	return nil, nil, errors.New("not implemented")
 }
 
-func (s *stubAccountRepo) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode string) ([]service.Account, *pagination.PaginationResult, error) {
+func (s *stubAccountRepo) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode, tag string) ([]service.Account, *pagination.PaginationResult, error) {
 	return nil, nil, errors.New("not implemented")
 }
 
@@ -1516,6 +1548,25 @@ func (s *stubAccountRepo) ListByPlatform(ctx context.Context, platform string) (
 	return nil, errors.New("not implemented")
 }
 
+func (s *stubAccountRepo) ListByOwnerUserID(ctx context.Context, userID int64) ([]service.Account, error) {
+	return nil, errors.New("not implemented")
+}
+func (s *stubAccountRepo) SetTags(ctx context.Context, accountID int64, tags []string) error {
+	return errors.New("not implemented")
+}
+
+func (s *stubAccountRepo) AddTags(ctx context.Context, accountIDs []int64, tags []string) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (s *stubAccountRepo) RemoveTags(ctx context.Context, accountIDs []int64, tags []string) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (s *stubAccountRepo) ListTags(ctx context.Context) ([]service.AccountTagCount, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (s *stubAccountRepo) UpdateLastUsed(ctx context.Context, id int64) error {
 	return errors.New("not implemented")
 }
@@ -1805,7 +1856,7 @@ func (r *stubUserSubscriptionRepo) ListActiveByUserID(ctx context.Context, userI
 func (stubUserSubscriptionRepo) ListByGroupID(ctx context.Context, groupID int64, params pagination.PaginationParams) ([]service.UserSubscription, *pagination.PaginationResult, error) {
 	return nil, nil, errors.New("not implemented")
 }
-func (stubUserSubscriptionRepo) List(ctx context.Context, params pagination.PaginationParams, userID, groupID *int64, status, platform, sortBy, sortOrder string) ([]service.UserSubscription, *pagination.PaginationResult, error) {
+func (stubUserSubscriptionRepo) List(ctx context.Context, params pagination.PaginationParams, userID, groupID, organizationID *int64, status, platform, sortBy, sortOrder string) ([]service.UserSubscription, *pagination.PaginationResult, error) {
 	return nil, nil, errors.New("not implemented")
 }
 func (stubUserSubscriptionRepo) ExistsByUserIDAndGroupID(ctx context.Context, userID, groupID int64) (bool, error) {
@@ -2017,6 +2068,10 @@ func (r *stubApiKeyRepo) ListByGroupID(ctx context.Context, groupID int64, param
 	return nil, nil, errors.New("not implemented")
 }
 
+func (r *stubApiKeyRepo) ListByParentID(ctx context.Context, parentID int64) ([]service.APIKey, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (r *stubApiKeyRepo) SearchAPIKeys(ctx context.Context, userID int64, keyword string, limit int) ([]service.APIKey, error) {
 	return nil, errors.New("not implemented")
 }
@@ -2053,6 +2108,22 @@ func (r *stubApiKeyRepo) ListKeysByGroupID(ctx context.Context, groupID int64) (
 	return nil, errors.New("not implemented")
 }
 
+func (r *stubApiKeyRepo) ListKeysByParentKey(ctx context.Context, parentKey string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *stubApiKeyRepo) ListExpiredChildTokens(ctx context.Context, now time.Time, limit int) ([]service.APIKey, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *stubApiKeyRepo) ReserveQuota(ctx context.Context, id int64, amount float64) (bool, error) {
+	return false, errors.New("not implemented")
+}
+
+func (r *stubApiKeyRepo) DeleteChildToken(ctx context.Context, id int64) (float64, error) {
+	return 0, errors.New("not implemented")
+}
+
 func (r *stubApiKeyRepo) IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error) {
 	return 0, errors.New("not implemented")
 }
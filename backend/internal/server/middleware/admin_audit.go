@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	adminAuditRecordTimeout = 3 * time.Second
+	// adminAuditMaxCaptureBytes 请求体 / 响应体的最大采集量，超出部分不进入审计记录
+	adminAuditMaxCaptureBytes = 64 << 10
+	adminAuditPathPrefix      = "/api/v1/admin/"
+)
+
+// adminAuditNestedResources 以二级路由段区分资源的分组（如 /admin/payment/plans）
+var adminAuditNestedResources = map[string]bool{
+	"payment": true,
+}
+
+// adminAuditReadOnlySuffixes 使用 POST 但不修改数据的接口（测试连接、批量查询、预览），不记录审计
+var adminAuditReadOnlySuffixes = []string{
+	"/test",
+	"/quality-check",
+	"/preview",
+	"/check-mixed-channel",
+	"/today-stats/batch",
+	"/users-usage",
+	"/api-keys-usage",
+	"/test-smtp",
+	"/send-test-email",
+}
+
+// NewAdminAuditMiddleware 创建管理员操作审计中间件（需位于管理员认证之后）
+func NewAdminAuditMiddleware(auditService *service.AdminAuditService) AdminAuditMiddleware {
+	return AdminAuditMiddleware(adminAudit(auditService))
+}
+
+// adminAudit 记录管理员写操作：执行前加载资源快照，成功后再次加载并与之比较，
+// 连同操作者、IP 与脱敏后的请求体写入只追加的审计表。审计失败只记日志，不影响管理操作本身。
+func adminAudit(auditService *service.AdminAuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if auditService == nil || !isAdminAuditMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		path := c.FullPath()
+		if path == "" || isAdminAuditReadOnlyPath(path) {
+			c.Next()
+			return
+		}
+
+		resource := adminAuditResource(path)
+		resourceID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+		before := auditService.Snapshot(c.Request.Context(), resource, resourceID)
+		requestBody := captureAdminAuditRequestBody(c)
+
+		capture := &adminAuditCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = capture
+		c.Next()
+		c.Writer = capture.ResponseWriter
+
+		status := capture.Status()
+		if status < http.StatusOK || status >= http.StatusMultipleChoices {
+			return
+		}
+		subject, ok := GetAuthSubjectFromContext(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), adminAuditRecordTimeout)
+		defer cancel()
+
+		// 默认以响应 data 作为变更后状态；创建类请求从 data.id 取得新资源 ID
+		responseID, after := adminAuditResponseData(capture)
+		if resourceID <= 0 {
+			resourceID = responseID
+		}
+		switch {
+		case c.Request.Method == http.MethodDelete && strings.HasSuffix(path, "/:id"):
+			// 删除资源本身：变更后状态为空
+			after = nil
+		case auditService.HasSnapshotLoader(resource, resourceID):
+			if snapshot := auditService.Snapshot(ctx, resource, resourceID); snapshot != nil {
+				after = snapshot
+			}
+		}
+
+		rec := &service.AdminAuditRecord{
+			ActorUserID: subject.UserID,
+			AuthMethod:  c.GetString("auth_method"),
+			Method:      c.Request.Method,
+			Path:        path,
+			Resource:    resource,
+			StatusCode:  status,
+			IP:          ip.GetClientIP(c),
+			UserAgent:   c.Request.UserAgent(),
+			RequestBody: requestBody,
+			Before:      before,
+			After:       after,
+		}
+		if resourceID > 0 {
+			rec.ResourceID = &resourceID
+		}
+		if err := auditService.Record(ctx, rec); err != nil {
+			logger.FromContext(c.Request.Context()).Warn("admin_audit.record_failed",
+				zap.String("path", path),
+				zap.Int64("actor_user_id", subject.UserID),
+				zap.Error(err))
+		}
+	}
+}
+
+func isAdminAuditMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isAdminAuditReadOnlyPath(path string) bool {
+	for _, suffix := range adminAuditReadOnlySuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// adminAuditResource 从路由模板提取资源名：/api/v1/admin/accounts/:id -> accounts
+func adminAuditResource(path string) string {
+	rest := strings.TrimPrefix(path, adminAuditPathPrefix)
+	if rest == path {
+		return ""
+	}
+	segments := strings.Split(rest, "/")
+	if len(segments) > 1 && adminAuditNestedResources[segments[0]] && !strings.HasPrefix(segments[1], ":") {
+		return segments[0] + "/" + segments[1]
+	}
+	return segments[0]
+}
+
+// captureAdminAuditRequestBody 读取 JSON 请求体用于审计并原样回放给处理器；
+// 非 JSON（如文件上传）或超过上限时不采集。
+func captureAdminAuditRequestBody(c *gin.Context) []byte {
+	if c.Request.Body == nil || !strings.Contains(c.ContentType(), "json") {
+		return nil
+	}
+	if c.Request.ContentLength > adminAuditMaxCaptureBytes {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, adminAuditMaxCaptureBytes+1))
+	if err != nil {
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err: err}))
+		return nil
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if len(body) > adminAuditMaxCaptureBytes {
+		return nil
+	}
+	return body
+}
+
+// adminAuditResponseData 解析统一响应体中的 data 字段，返回其中的 id（若有）与 data 本身
+func adminAuditResponseData(capture *adminAuditCaptureWriter) (int64, any) {
+	if capture.overflow || capture.buf.Len() == 0 {
+		return 0, nil
+	}
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(capture.buf.Bytes(), &envelope); err != nil || len(envelope.Data) == 0 {
+		return 0, nil
+	}
+	var data any
+	if err := json.Unmarshal(envelope.Data, &data); err != nil {
+		return 0, nil
+	}
+	obj, ok := data.(map[string]any)
+	if !ok {
+		return 0, data
+	}
+	if id, ok := obj["id"].(float64); ok && id > 0 {
+		return int64(id), data
+	}
+	return 0, data
+}
+
+// adminAuditCaptureWriter 透传响应的同时缓存响应体，用于提取新建资源的 ID
+type adminAuditCaptureWriter struct {
+	gin.ResponseWriter
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (w *adminAuditCaptureWriter) Write(p []byte) (int, error) {
+	w.capture(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *adminAuditCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *adminAuditCaptureWriter) capture(p []byte) {
+	if w.overflow {
+		return
+	}
+	if w.buf.Len()+len(p) > adminAuditMaxCaptureBytes {
+		w.overflow = true
+		w.buf.Reset()
+		return
+	}
+	w.buf.Write(p)
+}
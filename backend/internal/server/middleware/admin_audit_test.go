@@ -0,0 +1,116 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type adminAuditRepoStub struct {
+	inserted []*service.AdminAuditLog
+}
+
+func (s *adminAuditRepoStub) Insert(_ context.Context, entry *service.AdminAuditLog) error {
+	s.inserted = append(s.inserted, entry)
+	return nil
+}
+
+func (s *adminAuditRepoStub) List(context.Context, pagination.PaginationParams, service.AdminAuditLogFilters) ([]service.AdminAuditLog, *pagination.PaginationResult, error) {
+	return nil, &pagination.PaginationResult{}, nil
+}
+
+func TestAdminAudit_RecordsMutationsWithSnapshots(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &adminAuditRepoStub{}
+	svc := service.NewAdminAuditService(repo, nil)
+	groups := map[int64]string{1: "old"}
+	svc.RegisterSnapshotLoader("groups", func(_ context.Context, id int64) (any, error) {
+		return map[string]any{"id": id, "name": groups[id]}, nil
+	})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyUser), AuthSubject{UserID: 5})
+		c.Set("auth_method", "jwt")
+		c.Next()
+	})
+	admin := router.Group("/api/v1/admin")
+	admin.Use(adminAudit(svc))
+	admin.PUT("/groups/:id", func(c *gin.Context) {
+		var body struct {
+			Name string `json:"name"`
+		}
+		require.NoError(t, c.ShouldBindJSON(&body))
+		groups[1] = body.Name
+		c.JSON(http.StatusOK, gin.H{"code": 0, "data": gin.H{"id": 1, "name": body.Name}})
+	})
+	admin.POST("/groups", func(c *gin.Context) {
+		groups[2] = "created"
+		c.JSON(http.StatusOK, gin.H{"code": 0, "data": gin.H{"id": 2, "name": "created"}})
+	})
+	admin.POST("/groups/:id/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+	admin.DELETE("/groups/:id", func(c *gin.Context) { c.Status(http.StatusBadRequest) })
+	admin.GET("/groups/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(method, path, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "audit-test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	send(http.MethodPut, "/api/v1/admin/groups/1", `{"name":"new"}`)
+	send(http.MethodPost, "/api/v1/admin/groups", `{"name":"created"}`)
+	send(http.MethodPost, "/api/v1/admin/groups/1/test", `{}`)
+	send(http.MethodDelete, "/api/v1/admin/groups/1", ``)
+	send(http.MethodGet, "/api/v1/admin/groups/1", ``)
+
+	require.Len(t, repo.inserted, 2)
+
+	update := repo.inserted[0]
+	require.Equal(t, int64(5), update.ActorUserID)
+	require.Equal(t, "jwt", update.AuthMethod)
+	require.Equal(t, "/api/v1/admin/groups/:id", update.Path)
+	require.Equal(t, "groups", update.Resource)
+	require.Equal(t, int64(1), *update.ResourceID)
+	require.Equal(t, "audit-test", update.UserAgent)
+	require.Equal(t, "old", gjson.GetBytes(update.Diff, "name.before").String())
+	require.Equal(t, "new", gjson.GetBytes(update.Diff, "name.after").String())
+	require.JSONEq(t, `{"name":"new"}`, string(update.RequestBody))
+
+	create := repo.inserted[1]
+	require.Equal(t, int64(2), *create.ResourceID)
+	require.Nil(t, create.Before)
+	require.Equal(t, "created", gjson.GetBytes(create.After, "name").String())
+}
+
+func TestAdminAuditResource(t *testing.T) {
+	require.Equal(t, "accounts", adminAuditResource("/api/v1/admin/accounts/:id/schedulable"))
+	require.Equal(t, "settings", adminAuditResource("/api/v1/admin/settings"))
+	require.Equal(t, "payment/plans", adminAuditResource("/api/v1/admin/payment/plans/:id"))
+	require.Equal(t, "", adminAuditResource("/api/v1/org/members/:id"))
+}
+
+func TestCaptureAdminAuditRequestBody_ReplaysBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":1}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	require.Equal(t, `{"a":1}`, string(captureAdminAuditRequestBody(c)))
+	replayed, err := io.ReadAll(c.Request.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"a":1}`, string(replayed))
+}
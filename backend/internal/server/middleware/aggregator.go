@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// AggregatorModelRouting 聚合端点模型路由中间件：
+// 解析请求体中的 model（支持 "platform/model" 命名空间与按模型名族推断），
+// 去除命名空间后回写请求体，并在需要时设置强制平台，后续 Handler 与 scope 校验据此调度。
+// 请求体无法解析或缺少 model 时原样放行，由 Handler 返回对应错误。
+func AggregatorModelRouting() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := pkghttputil.ReadRequestBodyWithPrealloc(c.Request)
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeAggregatorError(c, http.StatusRequestEntityTooLarge, "Request body too large, limit is "+strconv.FormatInt(maxErr.Limit, 10)+" bytes")
+				return
+			}
+			writeAggregatorError(c, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		// 请求体已解码，后续读取不再需要 Content-Encoding
+		c.Request.Header.Del("Content-Encoding")
+
+		model := gjson.GetBytes(body, "model")
+		if model.Type == gjson.String && model.String() != "" {
+			groupPlatform := ""
+			if apiKey, ok := GetAPIKeyFromContext(c); ok && apiKey.Group != nil {
+				groupPlatform = apiKey.Group.Platform
+			}
+			route, err := service.ResolveAggregatorRoute(model.String(), groupPlatform)
+			if err != nil {
+				writeAggregatorError(c, http.StatusBadRequest, "Model "+strconv.Quote(model.String())+" is not available for this API key's group")
+				return
+			}
+			if route.Namespaced {
+				if rewritten, err := sjson.SetBytes(body, "model", route.Model); err == nil {
+					body = rewritten
+				}
+			}
+			if route.ForcePlatform != "" {
+				setForcePlatform(c, route.ForcePlatform)
+			}
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Next()
+	}
+}
+
+func writeAggregatorError(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{
+		"error": gin.H{
+			"type":    "invalid_request_error",
+			"message": message,
+		},
+	})
+	c.Abort()
+}
@@ -0,0 +1,53 @@
+//go:build unit
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregatorModelRouting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	apiKey := &service.APIKey{Group: &service.Group{Platform: service.PlatformAnthropic}}
+
+	var gotBody, gotForce string
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.POST("/t", AggregatorModelRouting(), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		gotBody = string(body)
+		gotForce, _ = c.Request.Context().Value(ctxkey.ForcePlatform).(string)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/t", strings.NewReader(`{"model":"antigravity/claude-sonnet-4-5","stream":true}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"model":"claude-sonnet-4-5","stream":true}`, gotBody)
+	require.Equal(t, service.PlatformAntigravity, gotForce)
+
+	// 无命名空间且分组可原生服务：请求体与调度保持不变
+	gotForce = ""
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/t", strings.NewReader(`{"model":"claude-sonnet-4-5"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"model":"claude-sonnet-4-5"}`, gotBody)
+	require.Empty(t, gotForce)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/t", strings.NewReader(`{"model":"openai/gpt-5"}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "invalid_request_error")
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ip"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
@@ -36,6 +37,21 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			return
 		}
 
+		// HMAC 签名请求：不携带 API Key，由签名校验直接得到 Key
+		var apiKey *service.APIKey
+		if isSignedRequest(c, apiKeyService) {
+			signedKey, err := authenticateSignedRequest(c, apiKeyService)
+			if err != nil {
+				if code := infraerrors.Code(err); code < 500 {
+					AbortWithError(c, code, infraerrors.Reason(err), infraerrors.Message(err))
+					return
+				}
+				AbortWithError(c, 500, "INTERNAL_ERROR", "Failed to validate request signature")
+				return
+			}
+			apiKey = signedKey
+		}
+
 		// 尝试从Authorization header中提取API key (Bearer scheme)
 		authHeader := c.GetHeader("Authorization")
 		var apiKeyString string
@@ -59,21 +75,24 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 		}
 
 		// 如果所有header都没有API key
-		if apiKeyString == "" {
+		if apiKeyString == "" && apiKey == nil {
 			AbortWithError(c, 401, "API_KEY_REQUIRED", "API key is required in Authorization header (Bearer scheme), x-api-key header, or x-goog-api-key header")
 			return
 		}
 
 		// ── 2. 验证 Key 存在 ─────────────────────────────────────────
 
-		apiKey, err := apiKeyService.GetByKey(c.Request.Context(), apiKeyString)
-		if err != nil {
-			if errors.Is(err, service.ErrAPIKeyNotFound) {
-				AbortWithError(c, 401, "INVALID_API_KEY", "Invalid API key")
+		if apiKey == nil {
+			var err error
+			apiKey, err = apiKeyService.GetByKey(c.Request.Context(), apiKeyString)
+			if err != nil {
+				if errors.Is(err, service.ErrAPIKeyNotFound) {
+					AbortWithError(c, 401, "INVALID_API_KEY", "Invalid API key")
+					return
+				}
+				AbortWithError(c, 500, "INTERNAL_ERROR", "Failed to validate API key")
 				return
 			}
-			AbortWithError(c, 500, "INTERNAL_ERROR", "Failed to validate API key")
-			return
 		}
 
 		// ── 3. 基础鉴权（始终执行） ─────────────────────────────────
@@ -109,6 +128,16 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			return
 		}
 
+		// 子令牌：父 Key 删除/禁用/过期后随之失效
+		if err := apiKeyService.ValidateChildTokenParent(c.Request.Context(), apiKey); err != nil {
+			if code := infraerrors.Code(err); code < 500 {
+				AbortWithError(c, code, infraerrors.Reason(err), infraerrors.Message(err))
+				return
+			}
+			AbortWithError(c, 500, "INTERNAL_ERROR", "Failed to validate API key")
+			return
+		}
+
 		// ── 4. SimpleMode → early return ─────────────────────────────
 
 		if cfg.RunMode == config.RunModeSimple {
@@ -119,6 +148,9 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 			})
 			c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 			setGroupContext(c, apiKey.Group)
+			setUserContext(c, apiKey.User.ID)
+			setAccountTagSelectorContext(c, apiKey.AccountTagSelector)
+			setAllowedPlatformsContext(c, apiKey.AllowedPlatforms)
 			_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 			c.Next()
 			return
@@ -183,6 +215,7 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 						errors.Is(validateErr, service.ErrMonthlyLimitExceeded) {
 						code = "USAGE_LIMIT_EXCEEDED"
 						status = 429
+						SetRateLimitHeaders(c, service.RateLimitStateFromError(validateErr))
 					}
 					AbortWithError(c, status, code, validateErr.Error())
 					return
@@ -214,6 +247,9 @@ func apiKeyAuthWithSubscription(apiKeyService *service.APIKeyService, subscripti
 		})
 		c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 		setGroupContext(c, apiKey.Group)
+		setUserContext(c, apiKey.User.ID)
+		setAccountTagSelectorContext(c, apiKey.AccountTagSelector)
+		setAllowedPlatformsContext(c, apiKey.AllowedPlatforms)
 		_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 
 		c.Next()
@@ -250,3 +286,27 @@ func setGroupContext(c *gin.Context, group *service.Group) {
 	ctx := context.WithValue(c.Request.Context(), ctxkey.Group, group)
 	c.Request = c.Request.WithContext(ctx)
 }
+
+// setUserContext 将 API Key 所属用户写入请求 context，供调度器过滤用户自助绑定的账号
+func setUserContext(c *gin.Context, userID int64) {
+	if userID <= 0 {
+		return
+	}
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ctxkey.UserID, userID))
+}
+
+// setAccountTagSelectorContext 将 API Key 的账号标签选择器写入请求 context，调度时与分组选择器合并生效
+func setAccountTagSelectorContext(c *gin.Context, sel service.AccountTagSelector) {
+	if sel.IsEmpty() {
+		return
+	}
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ctxkey.AccountTagSelector, sel))
+}
+
+// setAllowedPlatformsContext 将 API Key 的允许平台写入请求 context，调度时剔除其他平台的账号
+func setAllowedPlatformsContext(c *gin.Context, platforms []string) {
+	if len(platforms) == 0 {
+		return
+	}
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ctxkey.AllowedPlatforms, platforms))
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 
@@ -26,20 +27,35 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			abortWithGoogleError(c, 400, "Query parameter api_key is deprecated. Use Authorization header or key instead.")
 			return
 		}
-		apiKeyString := extractAPIKeyForGoogle(c)
-		if apiKeyString == "" {
-			abortWithGoogleError(c, 401, "API key is required")
-			return
-		}
+		var apiKey *service.APIKey
+		if isSignedRequest(c, apiKeyService) {
+			signedKey, err := authenticateSignedRequest(c, apiKeyService)
+			if err != nil {
+				if code := infraerrors.Code(err); code < 500 {
+					abortWithGoogleError(c, code, infraerrors.Message(err))
+					return
+				}
+				abortWithGoogleError(c, 500, "Failed to validate request signature")
+				return
+			}
+			apiKey = signedKey
+		} else {
+			apiKeyString := extractAPIKeyForGoogle(c)
+			if apiKeyString == "" {
+				abortWithGoogleError(c, 401, "API key is required")
+				return
+			}
 
-		apiKey, err := apiKeyService.GetByKey(c.Request.Context(), apiKeyString)
-		if err != nil {
-			if errors.Is(err, service.ErrAPIKeyNotFound) {
-				abortWithGoogleError(c, 401, "Invalid API key")
+			var err error
+			apiKey, err = apiKeyService.GetByKey(c.Request.Context(), apiKeyString)
+			if err != nil {
+				if errors.Is(err, service.ErrAPIKeyNotFound) {
+					abortWithGoogleError(c, 401, "Invalid API key")
+					return
+				}
+				abortWithGoogleError(c, 500, "Failed to validate API key")
 				return
 			}
-			abortWithGoogleError(c, 500, "Failed to validate API key")
-			return
 		}
 
 		if !apiKey.IsActive() {
@@ -54,6 +70,14 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			abortWithGoogleError(c, 401, "User account is not active")
 			return
 		}
+		if err := apiKeyService.ValidateChildTokenParent(c.Request.Context(), apiKey); err != nil {
+			if code := infraerrors.Code(err); code < 500 {
+				abortWithGoogleError(c, code, infraerrors.Message(err))
+				return
+			}
+			abortWithGoogleError(c, 500, "Failed to validate API key")
+			return
+		}
 
 		// 简易模式：跳过余额和订阅检查
 		if cfg.RunMode == config.RunModeSimple {
@@ -64,6 +88,9 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 			})
 			c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 			setGroupContext(c, apiKey.Group)
+			setUserContext(c, apiKey.User.ID)
+			setAccountTagSelectorContext(c, apiKey.AccountTagSelector)
+			setAllowedPlatformsContext(c, apiKey.AllowedPlatforms)
 			_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 			c.Next()
 			return
@@ -88,6 +115,7 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 					errors.Is(err, service.ErrWeeklyLimitExceeded) ||
 					errors.Is(err, service.ErrMonthlyLimitExceeded) {
 					status = 429
+					SetRateLimitHeaders(c, service.RateLimitStateFromError(err))
 				}
 				abortWithGoogleError(c, status, err.Error())
 				return
@@ -113,6 +141,9 @@ func APIKeyAuthWithSubscriptionGoogle(apiKeyService *service.APIKeyService, subs
 		})
 		c.Set(string(ContextKeyUserRole), apiKey.User.Role)
 		setGroupContext(c, apiKey.Group)
+		setUserContext(c, apiKey.User.ID)
+		setAccountTagSelectorContext(c, apiKey.AccountTagSelector)
+		setAllowedPlatformsContext(c, apiKey.AllowedPlatforms)
 		_ = apiKeyService.TouchLastUsed(c.Request.Context(), apiKey.ID)
 		c.Next()
 	}
@@ -154,7 +185,7 @@ func extractAPIKeyForGoogle(c *gin.Context) string {
 }
 
 func allowGoogleQueryKey(path string) bool {
-	return strings.HasPrefix(path, "/v1beta") || strings.HasPrefix(path, "/antigravity/v1beta")
+	return strings.HasPrefix(path, "/v1beta") || strings.HasPrefix(path, "/antigravity/v1beta") || strings.HasPrefix(path, "/gemini/v1beta")
 }
 
 func abortWithGoogleError(c *gin.Context, status int, message string) {
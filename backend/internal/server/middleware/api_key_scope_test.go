@@ -0,0 +1,155 @@
+//go:build unit
+
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func newScopeTestRouter(apiKey *service.APIKey, forcePlatform string, scope string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	if forcePlatform != "" {
+		r.Use(ForcePlatform(forcePlatform))
+	}
+	r.GET("/t", RequireAPIKeyScope(scope, AnthropicErrorWriter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestRequireAPIKeyScope(t *testing.T) {
+	openaiGroup := &service.Group{Platform: service.PlatformOpenAI}
+	tests := []struct {
+		name          string
+		apiKey        *service.APIKey
+		forcePlatform string
+		scope         string
+		want          int
+	}{
+		{"unrestricted", &service.APIKey{Group: openaiGroup}, "", service.APIKeyScopeChat, http.StatusOK},
+		{"scope granted", &service.APIKey{Scopes: []string{"chat"}, Group: openaiGroup}, "", service.APIKeyScopeChat, http.StatusOK},
+		{"scope missing", &service.APIKey{Scopes: []string{"models"}, Group: openaiGroup}, "", service.APIKeyScopeChat, http.StatusForbidden},
+		{"platform granted", &service.APIKey{Scopes: []string{"platform:openai"}, Group: openaiGroup}, "", service.APIKeyScopeChat, http.StatusOK},
+		{"platform denied", &service.APIKey{Scopes: []string{"platform:anthropic"}, Group: openaiGroup}, "", service.APIKeyScopeChat, http.StatusForbidden},
+		{"force platform wins", &service.APIKey{Scopes: []string{"platform:antigravity"}, Group: openaiGroup}, service.PlatformAntigravity, service.APIKeyScopeChat, http.StatusOK},
+		{"ungrouped with platform scope", &service.APIKey{Scopes: []string{"platform:openai"}}, "", service.APIKeyScopeChat, http.StatusForbidden},
+		{"allowed platform granted", &service.APIKey{AllowedPlatforms: []string{"openai"}, Group: openaiGroup}, "", service.APIKeyScopeChat, http.StatusOK},
+		{"allowed platform denied", &service.APIKey{AllowedPlatforms: []string{"anthropic"}, Group: openaiGroup}, "", service.APIKeyScopeChat, http.StatusForbidden},
+		{"allowed platform via mixed scheduling", &service.APIKey{AllowedPlatforms: []string{"antigravity"}, Group: &service.Group{Platform: service.PlatformAnthropic}}, "", service.APIKeyScopeChat, http.StatusOK},
+		{"allowed platform forced", &service.APIKey{AllowedPlatforms: []string{"antigravity"}, Group: openaiGroup}, service.PlatformAnthropic, service.APIKeyScopeChat, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/t", nil)
+			newScopeTestRouter(tt.apiKey, tt.forcePlatform, tt.scope).ServeHTTP(w, req)
+			require.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestRequireAPIKeyScope_AllowedModels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	apiKey := &service.APIKey{AllowedModels: []string{"claude-sonnet-*"}, Group: &service.Group{Platform: service.PlatformAnthropic}}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	r.POST("/v1/messages", RequireAPIKeyScope(service.APIKeyScopeChat, AnthropicErrorWriter), func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		c.String(http.StatusOK, string(body))
+	})
+	r.GET("/v1/messages", RequireAPIKeyScope(service.APIKeyScopeChat, AnthropicErrorWriter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.POST("/v1beta/models/*modelAction", RequireAPIKeyScope(service.APIKeyScopeChat, GoogleErrorWriter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.GET("/v1/models", RequireAPIKeyScope(service.APIKeyScopeModels, AnthropicErrorWriter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.POST("/v1internal:action", RequireAPIKeyScope(service.APIKeyScopeChat, GoogleErrorWriter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("allowed model keeps body", func(t *testing.T) {
+		body := `{"model":"claude-sonnet-4-5","max_tokens":1}`
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, body, w.Body.String())
+	})
+	t.Run("disallowed model", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"claude-opus-4"}`)))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Contains(t, w.Body.String(), "claude-opus-4")
+	})
+	t.Run("gzip body disallowed model", func(t *testing.T) {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		_, err := gw.Write([]byte(`{"model":"claude-opus-4"}`))
+		require.NoError(t, err)
+		require.NoError(t, gw.Close())
+		req := httptest.NewRequest(http.MethodPost, "/v1/messages", &buf)
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Contains(t, w.Body.String(), "claude-opus-4")
+	})
+	t.Run("zstd body allowed model is decoded", func(t *testing.T) {
+		body := `{"model":"claude-sonnet-4-5","max_tokens":1}`
+		enc, err := zstd.NewWriter(nil)
+		require.NoError(t, err)
+		compressed := enc.EncodeAll([]byte(body), nil)
+		require.NoError(t, enc.Close())
+		req := httptest.NewRequest(http.MethodPost, "/v1/messages", bytes.NewReader(compressed))
+		req.Header.Set("Content-Encoding", "zstd")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, body, w.Body.String())
+	})
+	t.Run("code assist request model", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1internal:countTokens", strings.NewReader(`{"request":{"model":"models/claude-opus-4","contents":[]}}`)))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Contains(t, w.Body.String(), "claude-opus-4")
+	})
+	t.Run("gemini path model", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1beta/models/gemini-2.5-pro:generateContent", strings.NewReader(`{}`)))
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+	t.Run("websocket rejected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/messages", nil)
+		req.Header.Set("Upgrade", "websocket")
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+	t.Run("non model scope unaffected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+}
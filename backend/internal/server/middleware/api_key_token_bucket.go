@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyTokenBucketSweepInterval 清理空闲令牌桶的间隔
+const apiKeyTokenBucketSweepInterval = time.Minute
+
+// apiKeyTokenBucket 单个 API Key 的令牌桶；tokens 可为负数，表示已预约但尚未放行的排队请求
+type apiKeyTokenBucket struct {
+	burst  int
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// refill 按流逝时间补充令牌，不超过容量
+func (b *apiKeyTokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.burst), b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// APIKeyTokenBuckets 进程内的 API Key 令牌桶集合（不跨实例），所有网关路由共享同一实例
+type APIKeyTokenBuckets struct {
+	mu        sync.Mutex
+	buckets   map[int64]*apiKeyTokenBucket
+	maxWait   time.Duration
+	lastSweep time.Time
+	nowFn     func() time.Time
+}
+
+// NewAPIKeyTokenBuckets 创建令牌桶集合；排队上限取 gateway.token_bucket_max_wait_seconds
+func NewAPIKeyTokenBuckets(cfg *config.Config) *APIKeyTokenBuckets {
+	var maxWait time.Duration
+	if cfg != nil {
+		maxWait = time.Duration(cfg.Gateway.TokenBucketMaxWaitSeconds) * time.Second
+	}
+	return &APIKeyTokenBuckets{
+		buckets: make(map[int64]*apiKeyTokenBucket),
+		maxWait: maxWait,
+		nowFn:   time.Now,
+	}
+}
+
+// reserve 为请求预约一个令牌，返回需要等待的时长；等待超过上限时不预约并返回 false
+func (s *APIKeyTokenBuckets) reserve(apiKey *service.APIKey) (time.Duration, bool) {
+	now := s.nowFn()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweepLocked(now)
+	b := s.buckets[apiKey.ID]
+	if b == nil || b.burst != apiKey.TokenBucketBurst || b.rate != apiKey.TokenBucketRefillRate {
+		// 新 Key 或配置变更：以满桶重新开始
+		b = &apiKeyTokenBucket{
+			burst:  apiKey.TokenBucketBurst,
+			rate:   apiKey.TokenBucketRefillRate,
+			tokens: float64(apiKey.TokenBucketBurst),
+			last:   now,
+		}
+		s.buckets[apiKey.ID] = b
+	}
+	b.refill(now)
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0, true
+	}
+	wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	if wait > s.maxWait {
+		b.tokens++
+		return wait, false
+	}
+	return wait, true
+}
+
+// cancel 归还已预约但未放行的令牌（客户端在排队期间断开）
+func (s *APIKeyTokenBuckets) cancel(keyID int64) {
+	now := s.nowFn()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b := s.buckets[keyID]; b != nil {
+		b.refill(now)
+		b.tokens = math.Min(float64(b.burst), b.tokens+1)
+	}
+}
+
+// sweepLocked 删除已回满的令牌桶（删除后重建即为满桶，行为等价）
+func (s *APIKeyTokenBuckets) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < apiKeyTokenBucketSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for id, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.burst) {
+			delete(s.buckets, id)
+		}
+	}
+}
+
+// RateLimitErrorWriter 按 Anthropic API 规范输出限流错误（rate_limit_error）
+func RateLimitErrorWriter(c *gin.Context, status int, message string) {
+	c.JSON(status, gin.H{
+		"type":  "error",
+		"error": gin.H{"type": "rate_limit_error", "message": message},
+	})
+}
+
+// APIKeyTokenBucket API Key 令牌桶平滑中间件（需位于 API Key 认证之后，仅对配置了 token_bucket_burst 的 Key 生效）。
+// 位于并发控制之前：突发请求先消耗桶内令牌，令牌耗尽后按补充速率排队放行，
+// 避免单个 Agent 瞬间发起大量并行请求时直接触发等待队列已满错误。
+// 预计排队时间超过 gateway.token_bucket_max_wait_seconds 时返回 429 并携带 Retry-After。
+func APIKeyTokenBucket(buckets *APIKeyTokenBuckets, writeError GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey, ok := GetAPIKeyFromContext(c)
+		if buckets == nil || !ok || !apiKey.TokenBucketEnabled() {
+			c.Next()
+			return
+		}
+
+		wait, ok := buckets.reserve(apiKey)
+		if !ok {
+			resetSeconds := int(math.Ceil(wait.Seconds()))
+			if resetSeconds < 1 {
+				resetSeconds = 1
+			}
+			SetRateLimitHeaders(c, &service.RateLimitState{
+				Limit:        float64(apiKey.TokenBucketBurst),
+				Remaining:    0,
+				ResetSeconds: resetSeconds,
+			})
+			writeError(c, http.StatusTooManyRequests, "API key request rate exceeded, please retry later")
+			c.Abort()
+			return
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-c.Request.Context().Done():
+				timer.Stop()
+				buckets.cancel(apiKey.ID)
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,142 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTokenBucketTestBuckets(maxWaitSeconds int, now *time.Time) *APIKeyTokenBuckets {
+	cfg := &config.Config{}
+	cfg.Gateway.TokenBucketMaxWaitSeconds = maxWaitSeconds
+	buckets := NewAPIKeyTokenBuckets(cfg)
+	buckets.nowFn = func() time.Time { return *now }
+	return buckets
+}
+
+func TestAPIKeyTokenBuckets_ReserveBurstThenQueue(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	buckets := newTokenBucketTestBuckets(2, &now)
+	key := &service.APIKey{ID: 1, TokenBucketBurst: 3, TokenBucketRefillRate: 2}
+
+	for i := 0; i < 3; i++ {
+		wait, ok := buckets.reserve(key)
+		require.True(t, ok)
+		require.Zero(t, wait)
+	}
+
+	// 桶空后按 2 req/s 排队：依次等待 0.5s、1s、1.5s、2s
+	for i := 1; i <= 4; i++ {
+		wait, ok := buckets.reserve(key)
+		require.True(t, ok)
+		require.Equal(t, time.Duration(i)*500*time.Millisecond, wait)
+	}
+
+	// 超过排队上限：拒绝且不占用令牌
+	wait, ok := buckets.reserve(key)
+	require.False(t, ok)
+	require.Equal(t, 2500*time.Millisecond, wait)
+
+	// 归还一个排队令牌后，下一个请求的等待时间回到 2s
+	buckets.cancel(key.ID)
+	wait, ok = buckets.reserve(key)
+	require.True(t, ok)
+	require.Equal(t, 2*time.Second, wait)
+
+	// 时间流逝后回满，空闲桶在清理时被删除
+	now = now.Add(time.Hour)
+	wait, ok = buckets.reserve(&service.APIKey{ID: 2, TokenBucketBurst: 1, TokenBucketRefillRate: 1})
+	require.True(t, ok)
+	require.Zero(t, wait)
+	_, exists := buckets.buckets[key.ID]
+	require.False(t, exists)
+}
+
+func TestAPIKeyTokenBuckets_ConfigChangeResetsBucket(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	buckets := newTokenBucketTestBuckets(0, &now)
+	key := &service.APIKey{ID: 1, TokenBucketBurst: 1, TokenBucketRefillRate: 1}
+
+	_, ok := buckets.reserve(key)
+	require.True(t, ok)
+	_, ok = buckets.reserve(key)
+	require.False(t, ok)
+
+	key.TokenBucketBurst = 2
+	_, ok = buckets.reserve(key)
+	require.True(t, ok)
+}
+
+func TestAPIKeyTokenBucket_RejectsWithRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Unix(1_700_000_000, 0)
+	buckets := newTokenBucketTestBuckets(0, &now)
+	apiKey := &service.APIKey{ID: 9, TokenBucketBurst: 2, TokenBucketRefillRate: 0.5}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	router.POST("/v1/messages", APIKeyTokenBucket(buckets, RateLimitErrorWriter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "2", w.Header().Get("Retry-After"))
+	require.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	require.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	require.Contains(t, w.Body.String(), "rate_limit_error")
+
+	// 未配置令牌桶的 Key 不受影响
+	apiKey = &service.APIKey{ID: 10}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAPIKeyTokenBucket_ClientDisconnectWhileQueuedRefundsToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Unix(1_700_000_000, 0)
+	buckets := newTokenBucketTestBuckets(60, &now)
+	apiKey := &service.APIKey{ID: 3, TokenBucketBurst: 1, TokenBucketRefillRate: 0.1}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), apiKey)
+		c.Next()
+	})
+	called := 0
+	router.POST("/v1/messages", APIKeyTokenBucket(buckets, RateLimitErrorWriter), func(c *gin.Context) {
+		called++
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", nil).WithContext(ctx)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, 1, called)
+	require.InDelta(t, 0, buckets.buckets[apiKey.ID].tokens, 1e-9)
+}
@@ -14,6 +14,11 @@ import (
 // ClientRequestID ensures every request has a unique client_request_id in request.Context().
 //
 // This is used by the Ops monitoring module for end-to-end request correlation.
+//
+// 网关请求统一使用同一个 ID：X-Request-ID 响应头、request_id 日志字段、client_request_id
+// （用量记录 request_id 为 "client:<id>"、运维错误日志 client_request_id）与上游透传头均取该值，
+// 用户反馈的 ID 可据此端到端追踪。客户端自带的 X-Request-ID 不保证唯一，会被替换为网关生成的 ID，
+// 原值仅记录在日志 incoming_request_id 字段中。
 func ClientRequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request == nil {
@@ -26,10 +31,23 @@ func ClientRequestID() gin.HandlerFunc {
 			return
 		}
 
-		id := uuid.New().String()
-		ctx := context.WithValue(c.Request.Context(), ctxkey.ClientRequestID, id)
-		requestLogger := logger.FromContext(ctx).With(zap.String("client_request_id", strings.TrimSpace(id)))
-		ctx = logger.IntoContext(ctx, requestLogger)
+		ctx := c.Request.Context()
+		incomingRequestID := strings.TrimSpace(c.GetHeader(requestIDHeader))
+		requestID, _ := ctx.Value(ctxkey.RequestID).(string)
+		requestID = strings.TrimSpace(requestID)
+		if requestID == "" || incomingRequestID != "" {
+			// RequestLogger 未注入或沿用了客户端提供的 ID：由网关重新生成
+			requestID = uuid.New().String()
+			ctx = context.WithValue(ctx, ctxkey.RequestID, requestID)
+			c.Header(requestIDHeader, requestID)
+		}
+
+		ctx = context.WithValue(ctx, ctxkey.ClientRequestID, requestID)
+		var extra []zap.Field
+		if incomingRequestID != "" {
+			extra = append(extra, zap.String("incoming_request_id", incomingRequestID))
+		}
+		ctx = logger.IntoContext(ctx, newRequestLogger(c, requestID, requestID, extra...))
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
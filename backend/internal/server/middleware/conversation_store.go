@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const conversationStoreRecordTimeout = 3 * time.Second
+
+// ConversationStore 服务端会话存储中间件（需位于 API Key 认证之后）。
+// 请求携带 X-Conversation-ID 时，转发前把已存储的历史拼接到请求 messages，
+// 上游成功响应后记录本轮消息与助手回复；未携带请求头或未启用时不做任何处理。
+func ConversationStore(svc *service.ConversationStoreService, format service.ConversationFormat) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID := strings.TrimSpace(c.GetHeader(service.ConversationIDHeader))
+		if conversationID == "" || !svc.Enabled() {
+			c.Next()
+			return
+		}
+		apiKey, ok := GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil {
+			c.Next()
+			return
+		}
+
+		body, readErr := io.ReadAll(c.Request.Body)
+		if readErr != nil {
+			// 读取失败（如超过请求体上限）时原样回放，由处理器按既有逻辑返回错误
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err: readErr}))
+			c.Next()
+			return
+		}
+
+		merged, turn, err := svc.Prepare(c.Request.Context(), apiKey.ID, conversationID, format, body)
+		if err != nil {
+			if !infraerrors.IsBadRequest(err) {
+				logger.FromContext(c.Request.Context()).Warn("conversation_store.load_failed", zap.Int64("api_key_id", apiKey.ID), zap.Error(err))
+			}
+			writeConversationStoreError(c, format, infraerrors.Code(err), infraerrors.Message(err))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(merged))
+		c.Request.ContentLength = int64(len(merged))
+
+		capture := &conversationCaptureWriter{ResponseWriter: c.Writer, limit: svc.MaxResponseBytes()}
+		c.Writer = capture
+		c.Next()
+		c.Writer = capture.ResponseWriter
+
+		status := capture.Status()
+		if status < http.StatusOK || status >= http.StatusMultipleChoices || capture.overflow {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), conversationStoreRecordTimeout)
+		defer cancel()
+		if _, err := svc.Record(ctx, turn, capture.Header().Get("Content-Type"), capture.buf.Bytes()); err != nil {
+			logger.FromContext(c.Request.Context()).Warn("conversation_store.record_failed", zap.Int64("api_key_id", apiKey.ID), zap.Error(err))
+		}
+	}
+}
+
+// conversationCaptureWriter 透传响应的同时缓存响应体，用于提取助手回复。
+// 超过上限时停止缓存并放弃记录本轮。
+type conversationCaptureWriter struct {
+	gin.ResponseWriter
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *conversationCaptureWriter) Write(p []byte) (int, error) {
+	w.capture(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *conversationCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *conversationCaptureWriter) capture(p []byte) {
+	if w.overflow {
+		return
+	}
+	if w.limit > 0 && w.buf.Len()+len(p) > w.limit {
+		w.overflow = true
+		w.buf.Reset()
+		return
+	}
+	w.buf.Write(p)
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func writeConversationStoreError(c *gin.Context, format service.ConversationFormat, status int, message string) {
+	errType := "invalid_request_error"
+	if status >= http.StatusInternalServerError {
+		errType = "api_error"
+	}
+	if format == service.ConversationFormatMessages {
+		c.JSON(status, gin.H{
+			"type":  "error",
+			"error": gin.H{"type": errType, "message": message},
+		})
+		return
+	}
+	c.JSON(status, gin.H{
+		"error": gin.H{"type": errType, "message": message},
+	})
+}
@@ -0,0 +1,73 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type conversationStoreMemoryCache struct {
+	data map[string][]byte
+}
+
+func (m *conversationStoreMemoryCache) GetConversation(ctx context.Context, key string) ([]byte, error) {
+	return m.data[key], nil
+}
+
+func (m *conversationStoreMemoryCache) SetConversation(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	m.data[key] = data
+	return nil
+}
+
+func TestConversationStore_ReconstructsHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.ConversationStore = config.GatewayConversationStoreConfig{Enabled: true, TTLSeconds: 60, MaxMessages: 50, MaxBytes: 1 << 20}
+	svc := service.NewConversationStoreService(&conversationStoreMemoryCache{data: map[string][]byte{}}, cfg)
+
+	var upstreamMessages []int
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), &service.APIKey{ID: 9})
+		c.Next()
+	})
+	router.POST("/v1/chat/completions", ConversationStore(svc, service.ConversationFormatChatCompletions), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		upstreamMessages = append(upstreamMessages, len(gjson.GetBytes(body, "messages").Array()))
+		c.JSON(http.StatusOK, gin.H{
+			"object":  "chat.completion",
+			"choices": []gin.H{{"index": 0, "message": gin.H{"role": "assistant", "content": "ok"}}},
+		})
+	})
+
+	send := func(conversationID, content string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"m","messages":[{"role":"user","content":"`+content+`"}]}`))
+		if conversationID != "" {
+			req.Header.Set(service.ConversationIDHeader, conversationID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, send("c1", "one").Code)
+	require.Equal(t, http.StatusOK, send("c1", "two").Code)
+	require.Equal(t, http.StatusOK, send("", "three").Code)
+	require.Equal(t, []int{1, 3, 1}, upstreamMessages)
+
+	w := send("bad id", "x")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "invalid_request_error", gjson.Get(w.Body.String(), "error.type").String())
+}
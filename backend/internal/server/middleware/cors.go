@@ -3,6 +3,7 @@ package middleware
 import (
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -12,7 +13,43 @@ import (
 
 var corsWarningOnce sync.Once
 
-// CORS 跨域中间件
+// gatewayCORSDefaultAllowHeaders 网关 CORS 默认放行的请求头：覆盖 OpenAI / Anthropic / Gemini SDK 在浏览器中发送的头部
+var gatewayCORSDefaultAllowHeaders = []string{
+	"Content-Type", "Authorization", "Accept", "Cache-Control", "X-Requested-With",
+	"X-API-Key", "X-Goog-Api-Key", "X-Client-Request-Id", "X-Reasoning-Effort",
+	"Anthropic-Version", "Anthropic-Beta", "Anthropic-Dangerous-Direct-Browser-Access",
+	"OpenAI-Beta", "OpenAI-Organization", "OpenAI-Project",
+}
+
+// gatewayPathPrefixes 网关路由前缀（管理后台与前端页面不在其中）
+var gatewayPathPrefixes = []string{
+	"/v1/", "/v1beta/", "/v1internal", "/responses", "/chat/completions", "/completions", "/images/",
+	"/backend-api/codex", "/antigravity/", "/anthropic/", "/gemini/", "/aggregator/", "/gateway/",
+}
+
+// isGatewayPath 判断请求路径是否属于网关路由
+func isGatewayPath(path string) bool {
+	for _, prefix := range gatewayPathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// corsPolicy 编译后的 CORS 策略
+type corsPolicy struct {
+	allowAll          bool
+	allowedSet        map[string]struct{}
+	allowCredentials  bool
+	allowHeadersValue string
+	// echoRequestHeaders 为 true 时预检请求回显 Access-Control-Request-Headers
+	echoRequestHeaders bool
+	exposeHeadersValue string
+	maxAge             string
+}
+
+// CORS 跨域中间件。启用 cors.gateway 时，网关路由使用独立的网关 CORS 策略。
 func CORS(cfg config.CORSConfig) gin.HandlerFunc {
 	allowedOrigins := normalizeOrigins(cfg.AllowedOrigins)
 	allowAll := false
@@ -39,52 +76,114 @@ func CORS(cfg config.CORSConfig) gin.HandlerFunc {
 			log.Println("Warning: CORS allowed_origins set to '*', disabling allow_credentials.")
 		}
 	})
-	if allowAll && allowCredentials {
-		allowCredentials = false
+
+	allowHeaders := []string{
+		"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization",
+		"accept", "origin", "Cache-Control", "X-Requested-With", "X-API-Key",
+	}
+	// OpenAI Node SDK 会发送 x-stainless-* 请求头，需在 CORS 中显式放行。
+	for _, prop := range openAIStainlessProperties {
+		allowHeaders = append(allowHeaders, "x-stainless-"+prop)
+	}
+
+	global := newCORSPolicy(allowedOrigins, allowCredentials)
+	global.allowHeadersValue = strings.Join(allowHeaders, ", ")
+	global.exposeHeadersValue = "ETag"
+	global.maxAge = "86400"
+	globalHandler := global.handler()
+
+	if !cfg.Gateway.Enabled {
+		return globalHandler
+	}
+	gatewayHandler := newGatewayCORSPolicy(cfg.Gateway).handler()
+	return func(c *gin.Context) {
+		if isGatewayPath(c.Request.URL.Path) {
+			gatewayHandler(c)
+			return
+		}
+		globalHandler(c)
 	}
+}
 
-	allowedSet := make(map[string]struct{}, len(allowedOrigins))
+// openAIStainlessProperties OpenAI Node SDK 发送的 x-stainless-* 请求头后缀
+var openAIStainlessProperties = []string{
+	"lang", "package-version", "os", "arch", "retry-count", "runtime",
+	"runtime-version", "async", "helper-method", "poll-helper", "custom-poll-interval", "timeout",
+}
+
+func newCORSPolicy(allowedOrigins []string, allowCredentials bool) *corsPolicy {
+	p := &corsPolicy{allowedSet: make(map[string]struct{}, len(allowedOrigins))}
 	for _, origin := range allowedOrigins {
-		if origin == "" || origin == "*" {
+		if origin == "*" {
+			p.allowAll = true
 			continue
 		}
-		allowedSet[origin] = struct{}{}
+		if origin == "" {
+			continue
+		}
+		p.allowedSet[origin] = struct{}{}
 	}
-	allowHeaders := []string{
-		"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization",
-		"accept", "origin", "Cache-Control", "X-Requested-With", "X-API-Key",
+	// "*" 与凭证不能同时使用
+	p.allowCredentials = allowCredentials && !p.allowAll
+	return p
+}
+
+func newGatewayCORSPolicy(cfg config.GatewayCORSConfig) *corsPolicy {
+	p := newCORSPolicy(normalizeOrigins(cfg.AllowedOrigins), cfg.AllowCredentials)
+
+	allowHeaders := append([]string{}, gatewayCORSDefaultAllowHeaders...)
+	for _, prop := range openAIStainlessProperties {
+		allowHeaders = append(allowHeaders, "X-Stainless-"+prop)
 	}
-	// OpenAI Node SDK 会发送 x-stainless-* 请求头，需在 CORS 中显式放行。
-	openAIProperties := []string{
-		"lang", "package-version", "os", "arch", "retry-count", "runtime",
-		"runtime-version", "async", "helper-method", "poll-helper", "custom-poll-interval", "timeout",
+	for _, h := range normalizeOrigins(cfg.AllowedHeaders) {
+		if h == "*" {
+			p.echoRequestHeaders = true
+			continue
+		}
+		allowHeaders = append(allowHeaders, h)
 	}
-	for _, prop := range openAIProperties {
-		allowHeaders = append(allowHeaders, "x-stainless-"+prop)
+	p.allowHeadersValue = strings.Join(allowHeaders, ", ")
+	p.exposeHeadersValue = strings.Join(normalizeOrigins(cfg.ExposeHeaders), ", ")
+	if cfg.MaxAgeSeconds > 0 {
+		p.maxAge = strconv.Itoa(cfg.MaxAgeSeconds)
 	}
-	allowHeadersValue := strings.Join(allowHeaders, ", ")
+	return p
+}
 
+func (p *corsPolicy) handler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		origin := strings.TrimSpace(c.GetHeader("Origin"))
-		originAllowed := allowAll
-		if origin != "" && !allowAll {
-			_, originAllowed = allowedSet[origin]
+		originAllowed := p.allowAll
+		if origin != "" && !p.allowAll {
+			_, originAllowed = p.allowedSet[origin]
 		}
 
 		if originAllowed {
-			if allowAll {
-				c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+			h := c.Writer.Header()
+			if p.allowAll {
+				h.Set("Access-Control-Allow-Origin", "*")
 			} else if origin != "" {
-				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-				c.Writer.Header().Add("Vary", "Origin")
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Add("Vary", "Origin")
+			}
+			if p.allowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			allowHeaders := p.allowHeadersValue
+			if p.echoRequestHeaders {
+				if requested := strings.TrimSpace(c.GetHeader("Access-Control-Request-Headers")); requested != "" {
+					allowHeaders = requested
+					h.Add("Vary", "Access-Control-Request-Headers")
+				}
+			}
+			h.Set("Access-Control-Allow-Headers", allowHeaders)
+			h.Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+			if p.exposeHeadersValue != "" {
+				h.Set("Access-Control-Expose-Headers", p.exposeHeadersValue)
 			}
-			if allowCredentials {
-				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			if p.maxAge != "" {
+				h.Set("Access-Control-Max-Age", p.maxAge)
 			}
-			c.Writer.Header().Set("Access-Control-Allow-Headers", allowHeadersValue)
-			c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
-			c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
-			c.Writer.Header().Set("Access-Control-Max-Age", "86400")
 		}
 		// 处理预检请求
 		if c.Request.Method == http.MethodOptions {
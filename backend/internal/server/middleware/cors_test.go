@@ -306,3 +306,67 @@ func TestNormalizeOrigins(t *testing.T) {
 		})
 	}
 }
+
+func TestCORS_GatewayPolicy_AppliesToGatewayPaths(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowedOrigins: []string{"https://admin.example.com"},
+		Gateway: config.GatewayCORSConfig{
+			Enabled:        true,
+			AllowedOrigins: []string{"https://app.example.com"},
+			ExposeHeaders:  []string{"x-request-id", "retry-after"},
+			MaxAgeSeconds:  600,
+		},
+	}
+	middleware := CORS(cfg)
+
+	// 网关路由的 SSE 预检：放行网关来源并暴露 x-request-id
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodOptions, "/v1/chat/completions", nil)
+	c.Request.Header.Set("Origin", "https://app.example.com")
+	c.Request.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+	middleware(c)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "x-request-id, retry-after", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Anthropic-Version")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	// 管理后台来源不能调用网关
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodOptions, "/v1/chat/completions", nil)
+	c.Request.Header.Set("Origin", "https://admin.example.com")
+	middleware(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// 非网关路由仍使用全局策略
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodOptions, "/api/v1/auth/login", nil)
+	c.Request.Header.Set("Origin", "https://app.example.com")
+	middleware(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCORS_GatewayPolicy_EchoesRequestedHeadersWithWildcard(t *testing.T) {
+	cfg := config.CORSConfig{
+		Gateway: config.GatewayCORSConfig{
+			Enabled:        true,
+			AllowedOrigins: []string{"*"},
+			AllowedHeaders: []string{"*"},
+		},
+	}
+	middleware := CORS(cfg)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodOptions, "/v1/messages", nil)
+	c.Request.Header.Set("Origin", "https://any.example.com")
+	c.Request.Header.Set("Access-Control-Request-Headers", "x-custom-trace")
+	middleware(c)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "x-custom-trace", w.Header().Get("Access-Control-Allow-Headers"))
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+const (
+	gatewayIdempotencyHeader         = "Idempotency-Key"
+	gatewayIdempotencyReplayedHeader = "X-Idempotency-Replayed"
+	gatewayIdempotencyRecordTimeout  = 3 * time.Second
+)
+
+// GatewayIdempotency 网关 Idempotency-Key 中间件（需位于 API Key 认证之后）。
+// 仅处理携带请求头的非流式 POST 请求：首个请求正常转发并缓存成功响应，
+// 相同请求的重试直接重放缓存结果（不再转发上游、不再计费）。流式请求忽略该请求头。
+func GatewayIdempotency(svc *service.GatewayIdempotencyService, writeError GatewayErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := strings.TrimSpace(c.GetHeader(gatewayIdempotencyHeader))
+		if idempotencyKey == "" || c.Request.Method != http.MethodPost || !svc.Enabled() {
+			c.Next()
+			return
+		}
+		apiKey, ok := GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil {
+			c.Next()
+			return
+		}
+
+		body, readErr := io.ReadAll(c.Request.Body)
+		if readErr != nil {
+			// 读取失败（如超过请求体上限）时原样回放，由处理器按既有逻辑返回错误
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err: readErr}))
+			c.Next()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if isGatewayStreamRequest(c.Request, body) {
+			c.Next()
+			return
+		}
+
+		claim, replay, err := svc.Begin(c.Request.Context(), apiKey.ID, idempotencyKey, c.Request.Method, c.Request.URL.Path, body)
+		if err != nil {
+			if infraerrors.Code(err) >= http.StatusInternalServerError {
+				logger.FromContext(c.Request.Context()).Warn("gateway_idempotency.begin_failed", zap.Int64("api_key_id", apiKey.ID), zap.Error(err))
+			}
+			if retryAfter := service.RetryAfterSecondsFromError(err); retryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(retryAfter))
+			}
+			writeError(c, infraerrors.Code(err), infraerrors.Message(err))
+			c.Abort()
+			return
+		}
+		if replay != nil {
+			for name, values := range replay.Header {
+				for _, v := range values {
+					c.Writer.Header().Add(name, v)
+				}
+			}
+			c.Header(gatewayIdempotencyReplayedHeader, "true")
+			c.Status(replay.StatusCode)
+			_, _ = c.Writer.Write(replay.Body)
+			c.Abort()
+			return
+		}
+
+		capture := &gatewayIdempotencyCaptureWriter{ResponseWriter: c.Writer, limit: svc.MaxResponseBytes()}
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			// 处理器 panic 时释放占位，避免重试在锁超时前一直返回 409
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), gatewayIdempotencyRecordTimeout)
+			defer cancel()
+			_ = svc.Complete(ctx, claim, http.StatusInternalServerError, nil, nil, false)
+		}()
+		c.Writer = capture
+		c.Next()
+		c.Writer = capture.ResponseWriter
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), gatewayIdempotencyRecordTimeout)
+		defer cancel()
+		completed = true
+		if err := svc.Complete(ctx, claim, capture.Status(), capture.Header(), capture.buf.Bytes(), !capture.overflow); err != nil {
+			logger.FromContext(c.Request.Context()).Warn("gateway_idempotency.record_failed", zap.Int64("api_key_id", apiKey.ID), zap.Error(err))
+		}
+	}
+}
+
+// IdempotencyErrorWriter 按 Anthropic / OpenAI 兼容格式输出幂等冲突等错误
+func IdempotencyErrorWriter(c *gin.Context, status int, message string) {
+	errType := "invalid_request_error"
+	if status >= http.StatusInternalServerError {
+		errType = "api_error"
+	}
+	c.JSON(status, gin.H{
+		"type":  "error",
+		"error": gin.H{"type": errType, "message": message},
+	})
+}
+
+// isGatewayStreamRequest 判断是否为流式请求：请求体 stream=true，或 Gemini 流式方法 / alt=sse
+func isGatewayStreamRequest(r *http.Request, body []byte) bool {
+	if strings.Contains(r.URL.Path, "streamGenerateContent") || r.URL.Query().Get("alt") == "sse" {
+		return true
+	}
+	return gjson.GetBytes(body, "stream").Bool()
+}
+
+// gatewayIdempotencyCaptureWriter 透传响应的同时缓存响应体；超过上限时停止缓存，本次结果不可重放。
+type gatewayIdempotencyCaptureWriter struct {
+	gin.ResponseWriter
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *gatewayIdempotencyCaptureWriter) Write(p []byte) (int, error) {
+	w.capture(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *gatewayIdempotencyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *gatewayIdempotencyCaptureWriter) capture(p []byte) {
+	if w.overflow {
+		return
+	}
+	if w.limit > 0 && w.buf.Len()+len(p) > w.limit {
+		w.overflow = true
+		w.buf.Reset()
+		return
+	}
+	w.buf.Write(p)
+}
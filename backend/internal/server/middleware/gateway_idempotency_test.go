@@ -0,0 +1,132 @@
+//go:build unit
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type gatewayIdempotencyMemoryCache struct {
+	data map[string][]byte
+}
+
+func (m *gatewayIdempotencyMemoryCache) ClaimIdempotency(ctx context.Context, key string, data []byte, ttl time.Duration) (bool, error) {
+	if _, ok := m.data[key]; ok {
+		return false, nil
+	}
+	m.data[key] = data
+	return true, nil
+}
+
+func (m *gatewayIdempotencyMemoryCache) GetIdempotency(ctx context.Context, key string) ([]byte, error) {
+	return m.data[key], nil
+}
+
+func (m *gatewayIdempotencyMemoryCache) SetIdempotency(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	m.data[key] = data
+	return nil
+}
+
+func (m *gatewayIdempotencyMemoryCache) DeleteIdempotency(ctx context.Context, key string) error {
+	delete(m.data, key)
+	return nil
+}
+
+func newGatewayIdempotencyTestRouter(t *testing.T, status *int, calls *int) (*gin.Engine, *gatewayIdempotencyMemoryCache) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Gateway.Idempotency = config.GatewayIdempotencyConfig{Enabled: true, TTLSeconds: 60, ProcessingTimeoutSeconds: 60, MaxResponseBytes: 1 << 20}
+	cache := &gatewayIdempotencyMemoryCache{data: map[string][]byte{}}
+	svc := service.NewGatewayIdempotencyService(cache, cfg)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), &service.APIKey{ID: 9})
+		c.Next()
+	})
+	router.POST("/v1/messages", GatewayIdempotency(svc, IdempotencyErrorWriter), func(c *gin.Context) {
+		*calls++
+		c.Header("Request-Id", "req_1")
+		c.JSON(*status, gin.H{"id": "msg_1", "call": *calls})
+	})
+	return router, cache
+}
+
+func sendGatewayIdempotencyRequest(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGatewayIdempotency_ReplaysSuccessfulResponse(t *testing.T) {
+	status, calls := http.StatusOK, 0
+	router, _ := newGatewayIdempotencyTestRouter(t, &status, &calls)
+
+	first := sendGatewayIdempotencyRequest(router, "k1", `{"model":"m"}`)
+	require.Equal(t, http.StatusOK, first.Code)
+
+	second := sendGatewayIdempotencyRequest(router, "k1", `{"model":"m"}`)
+	require.Equal(t, http.StatusOK, second.Code)
+	require.Equal(t, first.Body.String(), second.Body.String())
+	require.Equal(t, "true", second.Header().Get("X-Idempotency-Replayed"))
+	require.Equal(t, "req_1", second.Header().Get("Request-Id"))
+	require.Equal(t, 1, calls)
+
+	// 同一 Key 用于不同请求体视为冲突
+	conflict := sendGatewayIdempotencyRequest(router, "k1", `{"model":"other"}`)
+	require.Equal(t, http.StatusConflict, conflict.Code)
+	require.Equal(t, 1, calls)
+
+	// 未携带请求头的请求不受影响
+	require.Equal(t, http.StatusOK, sendGatewayIdempotencyRequest(router, "", `{"model":"m"}`).Code)
+	require.Equal(t, 2, calls)
+}
+
+func TestGatewayIdempotency_FailedResponseIsNotCached(t *testing.T) {
+	status, calls := http.StatusBadGateway, 0
+	router, cache := newGatewayIdempotencyTestRouter(t, &status, &calls)
+
+	require.Equal(t, http.StatusBadGateway, sendGatewayIdempotencyRequest(router, "k2", `{"model":"m"}`).Code)
+	require.Empty(t, cache.data)
+
+	status = http.StatusOK
+	require.Equal(t, http.StatusOK, sendGatewayIdempotencyRequest(router, "k2", `{"model":"m"}`).Code)
+	require.Equal(t, 2, calls)
+}
+
+func TestGatewayIdempotency_InProgressAndStream(t *testing.T) {
+	status, calls := http.StatusOK, 0
+	router, cache := newGatewayIdempotencyTestRouter(t, &status, &calls)
+
+	// 流式请求忽略 Idempotency-Key
+	sendGatewayIdempotencyRequest(router, "k3", `{"model":"m","stream":true}`)
+	sendGatewayIdempotencyRequest(router, "k3", `{"model":"m","stream":true}`)
+	require.Equal(t, 2, calls)
+	require.Empty(t, cache.data)
+
+	// 模拟首个请求仍在处理
+	svc := service.NewGatewayIdempotencyService(cache, &config.Config{Gateway: config.GatewayConfig{Idempotency: config.GatewayIdempotencyConfig{Enabled: true, TTLSeconds: 60, ProcessingTimeoutSeconds: 60}}})
+	_, _, err := svc.Begin(context.Background(), 9, "k4", http.MethodPost, "/v1/messages", []byte(`{"model":"m"}`))
+	require.NoError(t, err)
+
+	w := sendGatewayIdempotencyRequest(router, "k4", `{"model":"m"}`)
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	require.Equal(t, 2, calls)
+}
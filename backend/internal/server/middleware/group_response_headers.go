@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// GroupResponseHeaders 在 API Key 认证之后为分组注入自定义静态响应头（如 CORS、X-Provider 提示）。
+// 在处理器写响应前设置，错误响应同样携带；额外透传的上游响应头由网关服务在写响应时处理。
+func GroupResponseHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKey, ok := GetAPIKeyFromContext(c); ok && apiKey != nil {
+			service.ApplyGroupStaticResponseHeaders(c.Writer.Header(), apiKey.Group)
+		}
+		c.Next()
+	}
+}
@@ -1,13 +1,18 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
+	pkghttputil "github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
 	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
 )
 
 // ContextKey 定义上下文键类型
@@ -30,15 +35,19 @@ const (
 // 同时设置 request.Context（供 Service 使用）和 gin.Context（供 Handler 快速检查）
 func ForcePlatform(platform string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 设置到 request.Context，使用 ctxkey.ForcePlatform 供 Service 层读取
-		ctx := context.WithValue(c.Request.Context(), ctxkey.ForcePlatform, platform)
-		c.Request = c.Request.WithContext(ctx)
-		// 同时设置到 gin.Context，供 Handler 快速检查
-		c.Set(string(ContextKeyForcePlatform), platform)
+		setForcePlatform(c, platform)
 		c.Next()
 	}
 }
 
+func setForcePlatform(c *gin.Context, platform string) {
+	// 设置到 request.Context，使用 ctxkey.ForcePlatform 供 Service 层读取
+	ctx := context.WithValue(c.Request.Context(), ctxkey.ForcePlatform, platform)
+	c.Request = c.Request.WithContext(ctx)
+	// 同时设置到 gin.Context，供 Handler 快速检查
+	c.Set(string(ContextKeyForcePlatform), platform)
+}
+
 // HasForcePlatform 检查是否有强制平台（用于 Handler 跳过分组检查）
 func HasForcePlatform(c *gin.Context) bool {
 	_, exists := c.Get(string(ContextKeyForcePlatform))
@@ -119,3 +128,84 @@ func RequireGroupAssignment(settingService *service.SettingService, writeError G
 		c.Abort()
 	}
 }
+
+// ──────────────────────────────────────────────────────────
+// RequireAPIKeyScope — API Key 权限范围校验中间件
+// ──────────────────────────────────────────────────────────
+
+// RequireAPIKeyScope 校验 API Key 是否拥有访问当前端点所需的 scope，
+// 并在分组解析前校验目标平台（强制平台优先，其次为分组平台）是否在 Key 的平台范围与允许平台内；
+// 对话/图片端点额外校验请求模型是否在 Key 的模型白名单（allowed_models）内。
+// 未配置 scopes、allowed_platforms 与 allowed_models 的 Key 不受限制。
+func RequireAPIKeyScope(scope string, writeError GatewayErrorWriter) gin.HandlerFunc {
+	checkModel := scope == service.APIKeyScopeChat || scope == service.APIKeyScopeImages
+	return func(c *gin.Context) {
+		apiKey, ok := GetAPIKeyFromContext(c)
+		if !ok || (len(apiKey.Scopes) == 0 && len(apiKey.AllowedPlatforms) == 0 && len(apiKey.AllowedModels) == 0) {
+			c.Next()
+			return
+		}
+		if !apiKey.HasScope(scope) {
+			writeError(c, http.StatusForbidden, "API Key does not have the required scope: "+scope)
+			c.Abort()
+			return
+		}
+		platform, forced := GetForcePlatformFromContext(c)
+		if platform == "" && apiKey.Group != nil {
+			platform = apiKey.Group.Platform
+		}
+		if !apiKey.AllowsPlatform(platform) || !apiKey.CanRouteToPlatform(platform, forced) {
+			writeError(c, http.StatusForbidden, "API Key is not allowed to access this platform")
+			c.Abort()
+			return
+		}
+		if checkModel && len(apiKey.AllowedModels) > 0 {
+			// WebSocket 会话内的模型无法在握手阶段确定，限制模型的 Key 不允许使用
+			if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+				writeError(c, http.StatusForbidden, "API Key with model restrictions cannot use WebSocket sessions")
+				c.Abort()
+				return
+			}
+			if model := apiKeyRequestModel(c); apiKey.CheckModelAllowed(model) != nil {
+				writeError(c, http.StatusForbidden, "API Key is not allowed to use model: "+model)
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// apiKeyRequestModel 提取请求的模型：Gemini 原生路径取 /models/{model}:{action}，其余取 JSON 请求体的 model 字段
+// （Code Assist countTokens 取 request.model）。请求体按 Content-Encoding 解码后回填，后续处理器读到的即为解码后的内容；
+// 无法确定模型时返回空字符串，由后续处理器按原逻辑校验。
+func apiKeyRequestModel(c *gin.Context) string {
+	if modelAction := strings.TrimPrefix(c.Param("modelAction"), "/"); modelAction != "" {
+		model, _, _ := strings.Cut(modelAction, ":")
+		return strings.TrimSpace(model)
+	}
+	if c.Request.Body == nil || c.Request.Method == http.MethodGet {
+		return ""
+	}
+	raw, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(raw), errReader{err: err}))
+		return ""
+	}
+	// 在副本上解码：解码失败时保留原始请求体，由处理器按原逻辑返回错误
+	probe := &http.Request{Header: c.Request.Header.Clone(), Body: io.NopCloser(bytes.NewReader(raw)), ContentLength: int64(len(raw))}
+	body, err := pkghttputil.ReadRequestBodyWithPrealloc(probe)
+	if err != nil {
+		c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+		return ""
+	}
+	c.Request.Header = probe.Header
+	c.Request.ContentLength = probe.ContentLength
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	model := strings.TrimSpace(gjson.GetBytes(body, "model").String())
+	if model == "" {
+		model = strings.TrimSpace(gjson.GetBytes(body, "request.model").String())
+	}
+	return strings.TrimPrefix(model, "models/")
+}
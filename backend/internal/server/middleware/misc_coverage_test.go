@@ -55,6 +55,30 @@ func TestClientRequestID_PreservesExisting(t *testing.T) {
 	require.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestClientRequestID_UnifiesWithRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RequestLogger(), ClientRequestID())
+	var requestID, clientRequestID string
+	r.GET("/t", func(c *gin.Context) {
+		requestID, _ = c.Request.Context().Value(ctxkey.RequestID).(string)
+		clientRequestID, _ = c.Request.Context().Value(ctxkey.ClientRequestID).(string)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/t", nil)
+	req.Header.Set("X-Request-ID", "client-supplied")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEmpty(t, requestID)
+	require.NotEqual(t, "client-supplied", requestID)
+	require.Equal(t, requestID, clientRequestID)
+	require.Equal(t, requestID, w.Header().Get("X-Request-ID"))
+}
+
 func TestRequestBodyLimit_LimitsBody(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
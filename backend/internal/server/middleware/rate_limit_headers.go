@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SetRateLimitHeaders 为网关自身返回的 429 写入 Retry-After 与 X-RateLimit-* 响应头。
+// X-RateLimit-Reset 为距窗口重置的秒数（与 Retry-After 一致），不依赖客户端时钟。
+func SetRateLimitHeaders(c *gin.Context, state *service.RateLimitState) {
+	if c == nil || state == nil || state.ResetSeconds <= 0 {
+		return
+	}
+	reset := strconv.Itoa(state.ResetSeconds)
+	c.Header("Retry-After", reset)
+	c.Header("X-RateLimit-Limit", strconv.FormatFloat(state.Limit, 'f', -1, 64))
+	c.Header("X-RateLimit-Remaining", strconv.FormatFloat(state.Remaining, 'f', -1, 64))
+	c.Header("X-RateLimit-Reset", reset)
+}
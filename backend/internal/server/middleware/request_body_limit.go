@@ -3,6 +3,8 @@ package middleware
 import (
 	"net/http"
 
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,3 +15,16 @@ func RequestBodyLimit(maxBytes int64) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// APIKeyRequestBodyLimit 在 API Key 认证之后按端点类别与 API Key/分组配置收紧请求体上限。
+// 认证前的 RequestBodyLimit 使用全局硬上限，这里再按实际生效的上限重新包装请求体。
+func APIKeyRequestBodyLimit(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey, _ := GetAPIKeyFromContext(c)
+		limit := service.ResolveRequestBodyLimit(cfg, apiKey, service.RequestBodyClassForPath(c.Request.URL.Path))
+		if limit > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestCoalescedHeader = "X-Sub2api-Coalesced"
+	// requestCoalescingMaxResponseBytes 可共享的最大响应体；超过时跟随请求各自转发
+	requestCoalescingMaxResponseBytes = 4 << 20
+)
+
+// requestCoalescingShareHeaders 共享给跟随请求的响应头子集（其余响应头由网关中间件为各请求生成）
+var requestCoalescingShareHeaders = []string{
+	"Content-Type",
+	"Request-Id",
+	"X-Request-Id",
+}
+
+// requestCoalescingCall 一次进行中的上游调用；done 关闭后结果只读
+type requestCoalescingCall struct {
+	done   chan struct{}
+	shared bool
+	status int
+	header http.Header
+	body   []byte
+}
+
+type requestCoalescer struct {
+	mu    sync.Mutex
+	calls map[string]*requestCoalescingCall
+}
+
+// RequestCoalescing 并发重复请求合并中间件（需位于 API Key 认证之后，仅对开启 request_coalescing 的 Key 生效）。
+// 同一 API Key 下方法、路径与请求体完全相同的非流式 POST 请求，在首个请求处理期间到达的请求不再转发上游，
+// 直接复用首个请求的响应（不再计费）。首个请求未产生完整响应（panic、客户端断开、响应过大）时，跟随请求各自正常转发。
+// 合并状态仅保存在进程内，不跨实例。
+func RequestCoalescing() gin.HandlerFunc {
+	co := &requestCoalescer{calls: make(map[string]*requestCoalescingCall)}
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		apiKey, ok := GetAPIKeyFromContext(c)
+		if !ok || apiKey == nil || !apiKey.RequestCoalescing {
+			c.Next()
+			return
+		}
+
+		body, readErr := io.ReadAll(c.Request.Body)
+		if readErr != nil {
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err: readErr}))
+			c.Next()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if isGatewayStreamRequest(c.Request, body) {
+			c.Next()
+			return
+		}
+
+		key := requestCoalescingKey(apiKey.ID, c.Request.Method, c.Request.URL.RequestURI(), body)
+		co.mu.Lock()
+		if call, exists := co.calls[key]; exists {
+			co.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			}
+			if !call.shared {
+				c.Next()
+				return
+			}
+			for name, values := range call.header {
+				for _, v := range values {
+					c.Writer.Header().Add(name, v)
+				}
+			}
+			c.Header(requestCoalescedHeader, "true")
+			c.Status(call.status)
+			_, _ = c.Writer.Write(call.body)
+			c.Abort()
+			return
+		}
+		call := &requestCoalescingCall{done: make(chan struct{})}
+		co.calls[key] = call
+		co.mu.Unlock()
+
+		// 先移出登记表再关闭 done，之后到达的相同请求将发起新的上游调用
+		defer func() {
+			co.mu.Lock()
+			delete(co.calls, key)
+			co.mu.Unlock()
+			close(call.done)
+		}()
+
+		capture := &gatewayIdempotencyCaptureWriter{ResponseWriter: c.Writer, limit: requestCoalescingMaxResponseBytes}
+		c.Writer = capture
+		c.Next()
+		c.Writer = capture.ResponseWriter
+
+		if capture.overflow || !capture.Written() || c.Request.Context().Err() != nil {
+			return
+		}
+		call.shared = true
+		call.status = capture.Status()
+		call.header = make(http.Header)
+		for _, name := range requestCoalescingShareHeaders {
+			if values := capture.Header().Values(name); len(values) > 0 {
+				call.header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+			}
+		}
+		call.body = capture.buf.Bytes()
+	}
+}
+
+// requestCoalescingKey 按 API Key 隔离，以方法、请求 URI 与原始请求体标识相同请求
+func requestCoalescingKey(apiKeyID int64, method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(uri))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return strconv.FormatInt(apiKeyID, 10) + ":" + hex.EncodeToString(h.Sum(nil))
+}
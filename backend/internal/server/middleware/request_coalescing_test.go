@@ -0,0 +1,103 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequestCoalescingTestRouter(enabled bool, release <-chan struct{}, calls *int32) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(string(ContextKeyAPIKey), &service.APIKey{ID: 5, RequestCoalescing: enabled})
+		c.Next()
+	})
+	router.POST("/v1/messages", RequestCoalescing(), func(c *gin.Context) {
+		n := atomic.AddInt32(calls, 1)
+		<-release
+		c.Header("Request-Id", "req_1")
+		c.JSON(http.StatusOK, gin.H{"id": "msg_1", "call": n})
+	})
+	return router
+}
+
+func sendRequestCoalescingConcurrently(router *gin.Engine, bodies []string) []*httptest.ResponseRecorder {
+	recorders := make([]*httptest.ResponseRecorder, len(bodies))
+	var wg sync.WaitGroup
+	for i, body := range bodies {
+		wg.Add(1)
+		go func(i int, body string) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+			recorders[i] = httptest.NewRecorder()
+			router.ServeHTTP(recorders[i], req)
+		}(i, body)
+	}
+	wg.Wait()
+	return recorders
+}
+
+func TestRequestCoalescing_SharesLeaderResponse(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	router := newRequestCoalescingTestRouter(true, release, &calls)
+
+	go func() {
+		// 等待首个请求进入处理器后再放行，使其余请求作为跟随者挂起
+		for atomic.LoadInt32(&calls) < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	recorders := sendRequestCoalescingConcurrently(router, []string{`{"model":"m"}`, `{"model":"m"}`, `{"model":"m"}`, `{"model":"other"}`})
+
+	// 相同请求体只调用一次处理器，不同请求体各自处理
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	coalesced := 0
+	for _, w := range recorders[:3] {
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, recorders[0].Body.String(), w.Body.String())
+		require.Equal(t, "req_1", w.Header().Get("Request-Id"))
+		if w.Header().Get(requestCoalescedHeader) == "true" {
+			coalesced++
+		}
+	}
+	require.Equal(t, 2, coalesced)
+	require.Empty(t, recorders[3].Header().Get(requestCoalescedHeader))
+}
+
+func TestRequestCoalescing_SkipsDisabledKeyAndStream(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+
+	var calls int32
+	router := newRequestCoalescingTestRouter(false, release, &calls)
+	sendRequestCoalescingConcurrently(router, []string{`{"model":"m"}`, `{"model":"m"}`})
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	calls = 0
+	router = newRequestCoalescingTestRouter(true, release, &calls)
+	recorders := sendRequestCoalescingConcurrently(router, []string{`{"model":"m","stream":true}`, `{"model":"m","stream":true}`})
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	for _, w := range recorders {
+		require.Empty(t, w.Header().Get(requestCoalescedHeader))
+	}
+
+	// 首个请求结束后到达的相同请求发起新的调用
+	calls = 0
+	sendRequestCoalescingConcurrently(router, []string{`{"model":"m"}`})
+	sendRequestCoalescingConcurrently(router, []string{`{"model":"m"}`})
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
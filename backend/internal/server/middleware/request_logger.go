@@ -30,16 +30,20 @@ func RequestLogger() gin.HandlerFunc {
 		ctx := context.WithValue(c.Request.Context(), ctxkey.RequestID, requestID)
 		clientRequestID, _ := ctx.Value(ctxkey.ClientRequestID).(string)
 
-		requestLogger := logger.With(
-			zap.String("component", "http"),
-			zap.String("request_id", requestID),
-			zap.String("client_request_id", strings.TrimSpace(clientRequestID)),
-			zap.String("path", c.Request.URL.Path),
-			zap.String("method", c.Request.Method),
-		)
-
-		ctx = logger.IntoContext(ctx, requestLogger)
+		ctx = logger.IntoContext(ctx, newRequestLogger(c, requestID, clientRequestID))
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
+
+// newRequestLogger 构造携带请求标识的 request-scoped logger
+func newRequestLogger(c *gin.Context, requestID, clientRequestID string, extra ...zap.Field) *zap.Logger {
+	fields := []zap.Field{
+		zap.String("component", "http"),
+		zap.String("request_id", requestID),
+		zap.String("client_request_id", strings.TrimSpace(clientRequestID)),
+		zap.String("path", c.Request.URL.Path),
+		zap.String("method", c.Request.Method),
+	}
+	return logger.With(append(fields, extra...)...)
+}
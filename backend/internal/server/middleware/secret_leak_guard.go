@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SecretLeakGuard 响应内容密钥泄露防护中间件：替换返回给客户端的文本中的网关凭证。
+// SSE 响应按事件扫描文本增量，其余响应按写入块扫描；WebSocket 升级请求与未启用时直接放行。
+func SecretLeakGuard(guard *service.SecretLeakGuard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !guard.Enabled() || isWebSocketUpgradeRequest(c) {
+			c.Next()
+			return
+		}
+
+		writer := &secretLeakGuardWriter{ResponseWriter: c.Writer, stream: guard.NewStream(c.Request.Context())}
+		c.Writer = writer
+		c.Next()
+		writer.finish()
+		c.Writer = writer.ResponseWriter
+
+		if masked := writer.stream.Masked(); masked > 0 {
+			fields := []zap.Field{zap.Int("masked", masked), zap.String("path", c.Request.URL.Path)}
+			if apiKey, ok := GetAPIKeyFromContext(c); ok && apiKey != nil {
+				fields = append(fields, zap.Int64("api_key_id", apiKey.ID))
+			}
+			logger.FromContext(c.Request.Context()).Warn("secret_leak_guard.masked", fields...)
+		}
+	}
+}
+
+// secretLeakGuardWriter 在写出前替换响应内容；SSE 响应中不完整的事件与暂缓文本在后续写入或请求结束时输出。
+// Write 始终返回原始长度，避免上层把替换导致的长度差异当作短写。
+type secretLeakGuardWriter struct {
+	gin.ResponseWriter
+	stream  *service.SecretLeakStream
+	decided bool
+	sse     bool
+}
+
+func (w *secretLeakGuardWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		w.sse = strings.HasPrefix(strings.ToLower(w.Header().Get("Content-Type")), "text/event-stream")
+	}
+	var out []byte
+	if w.sse {
+		out = w.stream.SSE(p)
+	} else {
+		out = w.stream.Body(p)
+	}
+	if _, err := w.ResponseWriter.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *secretLeakGuardWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *secretLeakGuardWriter) finish() {
+	if !w.sse {
+		return
+	}
+	if out := w.stream.Finish(); len(out) > 0 {
+		_, _ = w.ResponseWriter.Write(out)
+		w.ResponseWriter.Flush()
+	}
+}
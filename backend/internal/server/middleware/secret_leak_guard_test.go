@@ -0,0 +1,82 @@
+//go:build unit
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const secretLeakMiddlewareTestSecret = "jwt-secret-0123456789abcdefghijklmnop"
+
+func newSecretLeakGuardTestRouter(enabled bool, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.JWT.Secret = secretLeakMiddlewareTestSecret
+	cfg.Gateway.SecretLeakGuard.Enabled = enabled
+	router := gin.New()
+	router.Use(SecretLeakGuard(service.NewSecretLeakGuard(cfg, nil)))
+	router.POST("/v1/messages", handler)
+	return router
+}
+
+func TestSecretLeakGuard_MasksStreamedDeltas(t *testing.T) {
+	router := newSecretLeakGuardTestRouter(true, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"secret jwt-secret-0123\"}}\n\n")
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString("data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"456789abcdefghijklmnop\"}}\n\n")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "jwt-secret-0123")
+	require.Contains(t, w.Body.String(), "[REDACTED_SECRET]")
+}
+
+func TestSecretLeakGuard_MasksJSONBody(t *testing.T) {
+	router := newSecretLeakGuardTestRouter(true, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"text": "key=" + secretLeakMiddlewareTestSecret})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	require.JSONEq(t, `{"text":"key=[REDACTED_SECRET]"}`, w.Body.String())
+}
+
+func TestSecretLeakGuard_DisabledPassesThrough(t *testing.T) {
+	router := newSecretLeakGuardTestRouter(false, func(c *gin.Context) {
+		c.String(http.StatusOK, secretLeakMiddlewareTestSecret)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	require.Equal(t, secretLeakMiddlewareTestSecret, w.Body.String())
+}
+
+func TestSecretLeakGuard_SSEChunkMaskedOnce(t *testing.T) {
+	var masked int
+	router := newSecretLeakGuardTestRouter(true, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"key " + secretLeakMiddlewareTestSecret + " end\"}}\n\n")
+		masked = c.Writer.(*secretLeakGuardWriter).stream.Masked()
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/messages", nil))
+
+	require.NotContains(t, w.Body.String(), secretLeakMiddlewareTestSecret)
+	require.Equal(t, 1, masked)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// isSignedRequest 判断请求是否使用 HMAC 签名认证（携带 X-Signature）。
+// 未启用签名认证时忽略该请求头，继续走 Bearer / x-api-key 认证。
+func isSignedRequest(c *gin.Context, apiKeyService *service.APIKeyService) bool {
+	return apiKeyService.SignedRequestsEnabled() && c.GetHeader(service.SignedRequestSignatureHeader) != ""
+}
+
+// authenticateSignedRequest 读取请求体并校验 HMAC 签名。
+// 请求体读取后会被重置，后续 handler 可照常读取。
+func authenticateSignedRequest(c *gin.Context, apiKeyService *service.APIKeyService) (*service.APIKey, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				return nil, infraerrors.New(http.StatusRequestEntityTooLarge, "REQUEST_BODY_TOO_LARGE", "Request body too large, limit is "+strconv.FormatInt(maxErr.Limit, 10)+" bytes")
+			}
+			return nil, infraerrors.BadRequest("INVALID_REQUEST_BODY", "Failed to read request body").WithCause(err)
+		}
+		body = data
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return apiKeyService.AuthenticateSignedRequest(c.Request.Context(), service.SignedRequest{
+		KeyID:     c.GetHeader(service.SignedRequestKeyIDHeader),
+		Signature: c.GetHeader(service.SignedRequestSignatureHeader),
+		Timestamp: c.GetHeader(service.SignedRequestTimestampHeader),
+		Nonce:     c.GetHeader(service.SignedRequestNonceHeader),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.RequestURI(),
+		Body:      body,
+	})
+}
@@ -13,9 +13,10 @@ func RegisterAdminRoutes(
 	v1 *gin.RouterGroup,
 	h *handler.Handlers,
 	adminAuth middleware.AdminAuthMiddleware,
+	adminAudit middleware.AdminAuditMiddleware,
 ) {
 	admin := v1.Group("/admin")
-	admin.Use(gin.HandlerFunc(adminAuth))
+	admin.Use(gin.HandlerFunc(adminAuth), gin.HandlerFunc(adminAudit))
 	{
 		// 仪表盘
 		registerDashboardRoutes(admin, h)
@@ -26,6 +27,12 @@ func RegisterAdminRoutes(
 		// 分组管理
 		registerGroupRoutes(admin, h)
 
+		// 组织管理
+		registerOrganizationRoutes(admin, h)
+
+		// 操作审计
+		registerAuditLogRoutes(admin, h)
+
 		// 账号管理
 		registerAccountRoutes(admin, h)
 
@@ -94,6 +101,21 @@ func RegisterAdminRoutes(
 
 		// 邀请返利（专属用户管理）
 		registerAffiliateRoutes(admin, h)
+
+		// 调试工具
+		registerDebugRoutes(admin, h)
+
+		// Webhook 通知
+		registerWebhookRoutes(admin, h)
+
+		// 账号定时轮换策略
+		registerAccountRotationRoutes(admin, h)
+
+		// 模型元数据注册表
+		registerModelCatalogRoutes(admin, h)
+
+		// 流量回放
+		registerTrafficReplayRoutes(admin, h)
 	}
 }
 
@@ -110,9 +132,17 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		// Realtime ops signals
 		ops.GET("/concurrency", h.Admin.Ops.GetConcurrencyStats)
 		ops.GET("/user-concurrency", h.Admin.Ops.GetUserConcurrencyStats)
+		ops.GET("/concurrency-slots", h.Admin.Ops.GetConcurrencySlots)
 		ops.GET("/account-availability", h.Admin.Ops.GetAccountAvailability)
 		ops.GET("/realtime-traffic", h.Admin.Ops.GetRealtimeTrafficSummary)
 
+		// Diagnostics bundle for bug reports (sanitized)
+		ops.GET("/diagnostics", h.Admin.Ops.DownloadDiagnosticsBundle)
+
+		// Profiling (net/http/pprof)
+		ops.GET("/pprof/*profile", h.Admin.Ops.ServePprof)
+		ops.POST("/pprof/*profile", h.Admin.Ops.ServePprof)
+
 		// Alerts (rules + events)
 		ops.GET("/alert-rules", h.Admin.Ops.ListAlertRules)
 		ops.POST("/alert-rules", h.Admin.Ops.CreateAlertRule)
@@ -135,6 +165,9 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 			runtime.GET("/logging", h.Admin.Ops.GetRuntimeLogConfig)
 			runtime.PUT("/logging", h.Admin.Ops.UpdateRuntimeLogConfig)
 			runtime.POST("/logging/reset", h.Admin.Ops.ResetRuntimeLogConfig)
+			runtime.GET("/log-level", h.Admin.Ops.GetLogLevelOverride)
+			runtime.PUT("/log-level", h.Admin.Ops.SetLogLevelOverride)
+			runtime.DELETE("/log-level", h.Admin.Ops.ClearLogLevelOverride)
 		}
 
 		// Advanced settings (DB-backed)
@@ -177,6 +210,7 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 
 		// Request drilldown (success + error)
 		ops.GET("/requests", h.Admin.Ops.ListRequestDetails)
+		ops.GET("/requests/:id", h.Admin.Ops.GetRequestTrace)
 
 		// Indexed system logs
 		ops.GET("/system-logs", h.Admin.Ops.ListSystemLogs)
@@ -191,6 +225,8 @@ func registerOpsRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		ops.GET("/dashboard/error-trend", h.Admin.Ops.GetDashboardErrorTrend)
 		ops.GET("/dashboard/error-distribution", h.Admin.Ops.GetDashboardErrorDistribution)
 		ops.GET("/dashboard/openai-token-stats", h.Admin.Ops.GetDashboardOpenAITokenStats)
+		ops.GET("/dashboard/failover-trend", h.Admin.Ops.GetDashboardFailoverTrend)
+		ops.GET("/dashboard/failover-stats", h.Admin.Ops.GetDashboardFailoverStats)
 	}
 }
 
@@ -269,6 +305,7 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		accounts.PUT("/:id", h.Admin.Account.Update)
 		accounts.DELETE("/:id", h.Admin.Account.Delete)
 		accounts.POST("/:id/test", h.Admin.Account.Test)
+		accounts.POST("/:id/verify", h.Admin.Account.Verify)
 		accounts.POST("/:id/recover-state", h.Admin.Account.RecoverState)
 		accounts.POST("/:id/refresh", h.Admin.Account.Refresh)
 		accounts.POST("/:id/set-privacy", h.Admin.Account.SetPrivacy)
@@ -276,6 +313,7 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		accounts.GET("/:id/stats", h.Admin.Account.GetStats)
 		accounts.POST("/:id/clear-error", h.Admin.Account.ClearError)
 		accounts.GET("/:id/usage", h.Admin.Account.GetUsage)
+		accounts.GET("/:id/forecast", h.Admin.Account.GetForecast)
 		accounts.GET("/:id/today-stats", h.Admin.Account.GetTodayStats)
 		accounts.POST("/today-stats/batch", h.Admin.Account.GetBatchTodayStats)
 		accounts.POST("/:id/clear-rate-limit", h.Admin.Account.ClearRateLimit)
@@ -284,12 +322,16 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		accounts.DELETE("/:id/temp-unschedulable", h.Admin.Account.ClearTempUnschedulable)
 		accounts.POST("/:id/schedulable", h.Admin.Account.SetSchedulable)
 		accounts.GET("/:id/models", h.Admin.Account.GetAvailableModels)
+		accounts.POST("/:id/model-mapping/suggest", h.Admin.Account.SuggestModelMapping)
+		accounts.POST("/:id/model-mapping/apply", h.Admin.Account.ApplyModelMapping)
 		accounts.POST("/batch", h.Admin.Account.BatchCreate)
 		accounts.GET("/data", h.Admin.Account.ExportData)
 		accounts.POST("/data", h.Admin.Account.ImportData)
 		accounts.POST("/batch-update-credentials", h.Admin.Account.BatchUpdateCredentials)
 		accounts.POST("/batch-refresh-tier", h.Admin.Account.BatchRefreshTier)
 		accounts.POST("/bulk-update", h.Admin.Account.BulkUpdate)
+		accounts.GET("/tags", h.Admin.Account.ListTags)
+		accounts.POST("/bulk-tags", h.Admin.Account.BulkUpdateTags)
 		accounts.POST("/batch-clear-error", h.Admin.Account.BatchClearError)
 		accounts.POST("/batch-refresh", h.Admin.Account.BatchRefresh)
 
@@ -301,11 +343,32 @@ func registerAccountRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		accounts.POST("/generate-setup-token-url", h.Admin.OAuth.GenerateSetupTokenURL)
 		accounts.POST("/exchange-code", h.Admin.OAuth.ExchangeCode)
 		accounts.POST("/exchange-setup-token-code", h.Admin.OAuth.ExchangeSetupTokenCode)
+		accounts.POST("/create-from-oauth", h.Admin.OAuth.CreateAccountFromOAuth)
+		accounts.POST("/:id/reauthorize", h.Admin.OAuth.ReauthorizeAccount)
 		accounts.POST("/cookie-auth", h.Admin.OAuth.CookieAuth)
 		accounts.POST("/setup-token-cookie-auth", h.Admin.OAuth.SetupTokenCookieAuth)
 	}
 }
 
+func registerOrganizationRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	organizations := admin.Group("/organizations")
+	{
+		organizations.GET("", h.Admin.Organization.List)
+		organizations.POST("", h.Admin.Organization.Create)
+		organizations.GET("/:id", h.Admin.Organization.GetByID)
+		organizations.PUT("/:id", h.Admin.Organization.Update)
+		organizations.DELETE("/:id", h.Admin.Organization.Delete)
+		organizations.POST("/:id/members", h.Admin.Organization.AddMember)
+		organizations.DELETE("/:id/members/:user_id", h.Admin.Organization.RemoveMember)
+		organizations.PUT("/:id/groups/:group_id", h.Admin.Organization.AssignGroup)
+		organizations.DELETE("/:id/groups/:group_id", h.Admin.Organization.UnassignGroup)
+	}
+}
+
+func registerAuditLogRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	admin.GET("/audit-logs", h.Admin.AuditLog.List)
+}
+
 func registerAnnouncementRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 	announcements := admin.Group("/announcements")
 	{
@@ -612,3 +675,49 @@ func registerAffiliateRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
 		}
 	}
 }
+
+func registerDebugRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	debug := admin.Group("/debug")
+	{
+		debug.POST("/forward", h.Admin.Debug.Forward)
+	}
+}
+
+func registerWebhookRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	webhooks := admin.Group("/webhooks")
+	{
+		webhooks.GET("/config", h.Admin.Webhook.GetConfig)
+		webhooks.PUT("/config", h.Admin.Webhook.UpdateConfig)
+		webhooks.POST("/endpoints/:index/test", h.Admin.Webhook.Test)
+	}
+}
+
+func registerAccountRotationRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	policies := admin.Group("/account-rotation-policies")
+	{
+		policies.GET("", h.Admin.AccountRotation.List)
+		policies.POST("", h.Admin.AccountRotation.Create)
+		policies.PUT("/:id", h.Admin.AccountRotation.Update)
+		policies.DELETE("/:id", h.Admin.AccountRotation.Delete)
+	}
+}
+
+func registerTrafficReplayRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	replays := admin.Group("/traffic-replays")
+	{
+		replays.GET("", h.Admin.TrafficReplay.List)
+		replays.POST("", h.Admin.TrafficReplay.Create)
+		replays.GET("/:id", h.Admin.TrafficReplay.Get)
+		replays.POST("/:id/cancel", h.Admin.TrafficReplay.Cancel)
+	}
+}
+
+func registerModelCatalogRoutes(admin *gin.RouterGroup, h *handler.Handlers) {
+	catalog := admin.Group("/model-catalog")
+	{
+		catalog.GET("", h.Admin.ModelCatalog.Resolve)
+		catalog.GET("/overrides", h.Admin.ModelCatalog.ListOverrides)
+		catalog.PUT("/overrides", h.Admin.ModelCatalog.UpsertOverride)
+		catalog.DELETE("/overrides", h.Admin.ModelCatalog.DeleteOverride)
+	}
+}
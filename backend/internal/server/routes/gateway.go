@@ -20,10 +20,31 @@ func RegisterGatewayRoutes(
 	subscriptionService *service.SubscriptionService,
 	opsService *service.OpsService,
 	settingService *service.SettingService,
+	conversationStore *service.ConversationStoreService,
+	gatewayIdempotency *service.GatewayIdempotencyService,
+	secretLeakGuard *service.SecretLeakGuard,
 	cfg *config.Config,
 ) {
-	bodyLimit := middleware.RequestBodyLimit(cfg.Gateway.MaxBodySize)
+	// 认证前按全局硬上限限制请求体，认证后再按端点类别与 API Key/分组配置收紧
+	bodyLimit := middleware.RequestBodyLimit(service.RequestBodyLimitCeiling(cfg))
+	keyBodyLimit := middleware.APIKeyRequestBodyLimit(cfg)
+	// 分组自定义静态响应头
+	groupHeaders := middleware.GroupResponseHeaders()
+	// 响应内容密钥泄露防护，未启用时直接放行
+	secretGuard := middleware.SecretLeakGuard(secretLeakGuard)
 	clientRequestID := middleware.ClientRequestID()
+	// 服务端会话存储（X-Conversation-ID），未启用时直接放行
+	conversationChat := middleware.ConversationStore(conversationStore, service.ConversationFormatChatCompletions)
+	conversationMessages := middleware.ConversationStore(conversationStore, service.ConversationFormatMessages)
+	// Idempotency-Key：非流式请求的成功响应缓存与重放，避免客户端重试导致重复计费
+	idempotency := middleware.GatewayIdempotency(gatewayIdempotency, middleware.IdempotencyErrorWriter)
+	idempotencyGoogle := middleware.GatewayIdempotency(gatewayIdempotency, middleware.GoogleErrorWriter)
+	// 开启 request_coalescing 的 Key：并发的相同非流式请求共享一次上游调用
+	coalescing := middleware.RequestCoalescing()
+	// 配置了令牌桶的 Key：突发请求按补充速率排队放行，平滑后再进入并发控制
+	tokenBuckets := middleware.NewAPIKeyTokenBuckets(cfg)
+	tokenBucket := middleware.APIKeyTokenBucket(tokenBuckets, middleware.RateLimitErrorWriter)
+	tokenBucketGoogle := middleware.APIKeyTokenBucket(tokenBuckets, middleware.GoogleErrorWriter)
 	opsErrorLogger := handler.OpsErrorLoggerMiddleware(opsService)
 	endpointNorm := handler.InboundEndpointMiddleware()
 
@@ -31,6 +52,15 @@ func RegisterGatewayRoutes(
 	requireGroupAnthropic := middleware.RequireGroupAssignment(settingService, middleware.AnthropicErrorWriter)
 	requireGroupGoogle := middleware.RequireGroupAssignment(settingService, middleware.GoogleErrorWriter)
 
+	// API Key 权限范围校验（按端点类别 + 目标平台）
+	scopeChat := middleware.RequireAPIKeyScope(service.APIKeyScopeChat, middleware.AnthropicErrorWriter)
+	scopeImages := middleware.RequireAPIKeyScope(service.APIKeyScopeImages, middleware.AnthropicErrorWriter)
+	scopeModels := middleware.RequireAPIKeyScope(service.APIKeyScopeModels, middleware.AnthropicErrorWriter)
+	scopeUsage := middleware.RequireAPIKeyScope(service.APIKeyScopeUsage, middleware.AnthropicErrorWriter)
+	scopeTokens := middleware.RequireAPIKeyScope(service.APIKeyScopeTokens, middleware.AnthropicErrorWriter)
+	scopeChatGoogle := middleware.RequireAPIKeyScope(service.APIKeyScopeChat, middleware.GoogleErrorWriter)
+	scopeModelsGoogle := middleware.RequireAPIKeyScope(service.APIKeyScopeModels, middleware.GoogleErrorWriter)
+
 	// API网关（Claude API兼容）
 	gateway := r.Group("/v1")
 	gateway.Use(bodyLimit)
@@ -39,9 +69,13 @@ func RegisterGatewayRoutes(
 	gateway.Use(endpointNorm)
 	gateway.Use(gin.HandlerFunc(apiKeyAuth))
 	gateway.Use(requireGroupAnthropic)
+	gateway.Use(keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket)
 	{
 		// /v1/messages: auto-route based on group platform
-		gateway.POST("/messages", func(c *gin.Context) {
+		gateway.POST("/messages", scopeChat, conversationMessages, func(c *gin.Context) {
+			if rejectCustomProvider(c) {
+				return
+			}
 			if getGroupPlatform(c) == service.PlatformOpenAI {
 				h.OpenAIGateway.Messages(c)
 				return
@@ -49,7 +83,10 @@ func RegisterGatewayRoutes(
 			h.Gateway.Messages(c)
 		})
 		// /v1/messages/count_tokens: OpenAI groups get 404
-		gateway.POST("/messages/count_tokens", func(c *gin.Context) {
+		gateway.POST("/messages/count_tokens", scopeChat, func(c *gin.Context) {
+			if rejectCustomProvider(c) {
+				return
+			}
 			if getGroupPlatform(c) == service.PlatformOpenAI {
 				c.JSON(http.StatusNotFound, gin.H{
 					"type": "error",
@@ -62,33 +99,67 @@ func RegisterGatewayRoutes(
 			}
 			h.Gateway.CountTokens(c)
 		})
-		gateway.GET("/models", h.Gateway.Models)
-		gateway.GET("/usage", h.Gateway.Usage)
+		registerMessageBatchRoutes(gateway, h, scopeChat)
+		gateway.GET("/models", scopeModels, func(c *gin.Context) {
+			// Claude Code 等 Anthropic 格式客户端需要 Anthropic 列表格式
+			if handler.IsAnthropicModelsRequest(c) {
+				h.Gateway.AnthropicModels(c)
+				return
+			}
+			h.Gateway.Models(c)
+		})
+		gateway.GET("/models/:model_id", scopeModels, h.Gateway.AnthropicGetModel)
+		gateway.GET("/usage", scopeUsage, h.Gateway.Usage)
+		// 子令牌：由当前 Key 签发短期 Key（TTL、模型白名单、预算切片）
+		gateway.POST("/tokens", scopeTokens, h.APIKeyToken.Create)
+		gateway.GET("/tokens", scopeTokens, h.APIKeyToken.List)
+		gateway.DELETE("/tokens/:id", scopeTokens, h.APIKeyToken.Revoke)
 		// OpenAI Responses API: auto-route based on group platform
-		gateway.POST("/responses", func(c *gin.Context) {
+		gateway.POST("/responses", scopeChat, func(c *gin.Context) {
+			if getGroupPlatform(c) == service.PlatformCustom {
+				h.Gateway.CustomResponses(c)
+				return
+			}
 			if getGroupPlatform(c) == service.PlatformOpenAI {
 				h.OpenAIGateway.Responses(c)
 				return
 			}
 			h.Gateway.Responses(c)
 		})
-		gateway.POST("/responses/*subpath", func(c *gin.Context) {
+		gateway.POST("/responses/*subpath", scopeChat, func(c *gin.Context) {
+			if rejectCustomProvider(c) {
+				return
+			}
 			if getGroupPlatform(c) == service.PlatformOpenAI {
 				h.OpenAIGateway.Responses(c)
 				return
 			}
 			h.Gateway.Responses(c)
 		})
-		gateway.GET("/responses", h.OpenAIGateway.ResponsesWebSocket)
+		gateway.GET("/responses", scopeChat, h.OpenAIGateway.ResponsesWebSocket)
 		// OpenAI Chat Completions API: auto-route based on group platform
-		gateway.POST("/chat/completions", func(c *gin.Context) {
-			if getGroupPlatform(c) == service.PlatformOpenAI {
+		gateway.POST("/chat/completions", scopeChat, conversationChat, func(c *gin.Context) {
+			switch getGroupPlatform(c) {
+			case service.PlatformOpenAI:
 				h.OpenAIGateway.ChatCompletions(c)
+			case service.PlatformCustom:
+				h.Gateway.CustomChatCompletions(c)
+			default:
+				h.Gateway.ChatCompletions(c)
+			}
+		})
+		// OpenAI 旧版 Completions API：转换为 Chat Completions 处理
+		gateway.POST("/completions", scopeChat, func(c *gin.Context) {
+			if rejectCustomProvider(c) {
 				return
 			}
-			h.Gateway.ChatCompletions(c)
+			if getGroupPlatform(c) == service.PlatformOpenAI {
+				h.OpenAIGateway.Completions(c)
+				return
+			}
+			h.Gateway.Completions(c)
 		})
-		gateway.POST("/images/generations", func(c *gin.Context) {
+		gateway.POST("/images/generations", scopeImages, func(c *gin.Context) {
 			if getGroupPlatform(c) != service.PlatformOpenAI {
 				c.JSON(http.StatusNotFound, gin.H{
 					"error": gin.H{
@@ -100,7 +171,7 @@ func RegisterGatewayRoutes(
 			}
 			h.OpenAIGateway.Images(c)
 		})
-		gateway.POST("/images/edits", func(c *gin.Context) {
+		gateway.POST("/images/edits", scopeImages, func(c *gin.Context) {
 			if getGroupPlatform(c) != service.PlatformOpenAI {
 				c.JSON(http.StatusNotFound, gin.H{
 					"error": gin.H{
@@ -115,47 +186,78 @@ func RegisterGatewayRoutes(
 	}
 
 	// Gemini 原生 API 兼容层（Gemini SDK/CLI 直连）
-	gemini := r.Group("/v1beta")
-	gemini.Use(bodyLimit)
-	gemini.Use(clientRequestID)
-	gemini.Use(opsErrorLogger)
-	gemini.Use(endpointNorm)
-	gemini.Use(middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg))
-	gemini.Use(requireGroupGoogle)
-	{
-		gemini.GET("/models", h.Gateway.GeminiV1BetaListModels)
-		gemini.GET("/models/:model", h.Gateway.GeminiV1BetaGetModel)
-		// Gin treats ":" as a param marker, but Gemini uses "{model}:{action}" in the same segment.
-		gemini.POST("/models/*modelAction", h.Gateway.GeminiV1BetaModels)
+	googleAuth := middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg)
+	// /gemini/v1beta 为带平台前缀的别名（与 /v1beta 行为一致，便于与其他协议共用同一 Base URL 主机）
+	for _, prefix := range []string{"/v1beta", "/gemini/v1beta"} {
+		gemini := r.Group(prefix)
+		gemini.Use(bodyLimit)
+		gemini.Use(clientRequestID)
+		gemini.Use(opsErrorLogger)
+		gemini.Use(endpointNorm)
+		gemini.Use(googleAuth)
+		gemini.Use(requireGroupGoogle)
+		gemini.Use(keyBodyLimit, groupHeaders, secretGuard, idempotencyGoogle, coalescing, tokenBucketGoogle)
+		{
+			gemini.GET("/models", scopeModelsGoogle, h.Gateway.GeminiV1BetaListModels)
+			gemini.GET("/models/:model", scopeModelsGoogle, h.Gateway.GeminiV1BetaGetModel)
+			// Gin treats ":" as a param marker, but Gemini uses "{model}:{action}" in the same segment.
+			// generateContent / streamGenerateContent / countTokens 均由此分发。
+			gemini.POST("/models/*modelAction", scopeChatGoogle, h.Gateway.GeminiV1BetaModels)
+		}
 	}
 
+	// Google Code Assist API（gemini-cli 通过 CODE_ASSIST_ENDPOINT 直连）
+	// Gin 不支持同一路径段内的 ":" 字面量，action 由处理器从请求路径解析。
+	r.POST("/v1internal:action", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, googleAuth, requireGroupGoogle, keyBodyLimit, groupHeaders, secretGuard, idempotencyGoogle, coalescing, tokenBucketGoogle, scopeChatGoogle, h.Gateway.GeminiCodeAssist)
+
 	// OpenAI Responses API（不带v1前缀的别名）— auto-route based on group platform
 	responsesHandler := func(c *gin.Context) {
+		if getGroupPlatform(c) == service.PlatformCustom && c.Param("subpath") == "" {
+			h.Gateway.CustomResponses(c)
+			return
+		}
+		if rejectCustomProvider(c) {
+			return
+		}
 		if getGroupPlatform(c) == service.PlatformOpenAI {
 			h.OpenAIGateway.Responses(c)
 			return
 		}
 		h.Gateway.Responses(c)
 	}
-	r.POST("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
-	r.POST("/responses/*subpath", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, responsesHandler)
-	r.GET("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.OpenAIGateway.ResponsesWebSocket)
+	r.POST("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket, scopeChat, responsesHandler)
+	r.POST("/responses/*subpath", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket, scopeChat, responsesHandler)
+	r.GET("/responses", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, keyBodyLimit, groupHeaders, secretGuard, scopeChat, h.OpenAIGateway.ResponsesWebSocket)
 	codexDirect := r.Group("/backend-api/codex")
-	codexDirect.Use(bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic)
+	codexDirect.Use(bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket)
 	{
-		codexDirect.POST("/responses", responsesHandler)
-		codexDirect.POST("/responses/*subpath", responsesHandler)
-		codexDirect.GET("/responses", h.OpenAIGateway.ResponsesWebSocket)
+		codexDirect.POST("/responses", scopeChat, responsesHandler)
+		codexDirect.POST("/responses/*subpath", scopeChat, responsesHandler)
+		codexDirect.GET("/responses", scopeChat, h.OpenAIGateway.ResponsesWebSocket)
 	}
 	// OpenAI Chat Completions API（不带v1前缀的别名）— auto-route based on group platform
-	r.POST("/chat/completions", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, func(c *gin.Context) {
-		if getGroupPlatform(c) == service.PlatformOpenAI {
+	r.POST("/chat/completions", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket, scopeChat, conversationChat, func(c *gin.Context) {
+		switch getGroupPlatform(c) {
+		case service.PlatformOpenAI:
 			h.OpenAIGateway.ChatCompletions(c)
+		case service.PlatformCustom:
+			h.Gateway.CustomChatCompletions(c)
+		default:
+			h.Gateway.ChatCompletions(c)
+		}
+	})
+	// OpenAI 旧版 Completions API（不带v1前缀的别名）
+	r.POST("/completions", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket, scopeChat, func(c *gin.Context) {
+		if rejectCustomProvider(c) {
 			return
 		}
-		h.Gateway.ChatCompletions(c)
+		if getGroupPlatform(c) == service.PlatformOpenAI {
+			h.OpenAIGateway.Completions(c)
+			return
+		}
+		h.Gateway.Completions(c)
 	})
-	r.POST("/images/generations", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, func(c *gin.Context) {
+	r.POST("/images/generations", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket, scopeImages, func(c *gin.Context) {
 		if getGroupPlatform(c) != service.PlatformOpenAI {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": gin.H{
@@ -167,7 +269,7 @@ func RegisterGatewayRoutes(
 		}
 		h.OpenAIGateway.Images(c)
 	})
-	r.POST("/images/edits", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, func(c *gin.Context) {
+	r.POST("/images/edits", bodyLimit, clientRequestID, opsErrorLogger, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket, scopeImages, func(c *gin.Context) {
 		if getGroupPlatform(c) != service.PlatformOpenAI {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": gin.H{
@@ -180,8 +282,33 @@ func RegisterGatewayRoutes(
 		h.OpenAIGateway.Images(c)
 	})
 
+	// 路由预览（dry-run）：执行鉴权、渠道映射与账号调度，返回命中的账号与预估费用，不请求上游
+	r.POST("/gateway/route-preview", bodyLimit, clientRequestID, endpointNorm, gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, keyBodyLimit, groupHeaders, secretGuard, scopeChat, func(c *gin.Context) {
+		if getGroupPlatform(c) == service.PlatformOpenAI {
+			h.OpenAIGateway.RoutePreview(c)
+			return
+		}
+		h.Gateway.RoutePreview(c)
+	})
+
+	// Anthropic 风格前缀（/anthropic/v1），承载 Messages Batches 与模型列表接口
+	anthropicV1 := r.Group("/anthropic/v1")
+	anthropicV1.Use(bodyLimit)
+	anthropicV1.Use(clientRequestID)
+	anthropicV1.Use(opsErrorLogger)
+	anthropicV1.Use(endpointNorm)
+	anthropicV1.Use(gin.HandlerFunc(apiKeyAuth))
+	anthropicV1.Use(requireGroupAnthropic)
+	anthropicV1.Use(keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket)
+	registerMessageBatchRoutes(anthropicV1, h, scopeChat)
+	anthropicV1.GET("/models", scopeModels, h.Gateway.AnthropicModels)
+	anthropicV1.GET("/models/:model_id", scopeModels, h.Gateway.AnthropicGetModel)
+
+	// OpenAI 兼容模型列表：仅返回当前 Key 实际可用的模型，供客户端填充模型选择器
+	r.GET("/openai/v1/models", gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, scopeModels, h.Gateway.OpenAIModels)
+
 	// Antigravity 模型列表
-	r.GET("/antigravity/models", gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, h.Gateway.AntigravityModels)
+	r.GET("/antigravity/models", gin.HandlerFunc(apiKeyAuth), requireGroupAnthropic, scopeModels, h.Gateway.AntigravityModels)
 
 	// Antigravity 专用路由（仅使用 antigravity 账户，不混合调度）
 	antigravityV1 := r.Group("/antigravity/v1")
@@ -192,11 +319,37 @@ func RegisterGatewayRoutes(
 	antigravityV1.Use(middleware.ForcePlatform(service.PlatformAntigravity))
 	antigravityV1.Use(gin.HandlerFunc(apiKeyAuth))
 	antigravityV1.Use(requireGroupAnthropic)
+	antigravityV1.Use(keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket)
 	{
-		antigravityV1.POST("/messages", h.Gateway.Messages)
-		antigravityV1.POST("/messages/count_tokens", h.Gateway.CountTokens)
-		antigravityV1.GET("/models", h.Gateway.AntigravityModels)
-		antigravityV1.GET("/usage", h.Gateway.Usage)
+		antigravityV1.POST("/messages", scopeChat, h.Gateway.Messages)
+		antigravityV1.POST("/messages/count_tokens", scopeChat, h.Gateway.CountTokens)
+		antigravityV1.POST("/chat/completions", scopeChat, h.Gateway.ChatCompletions)
+		antigravityV1.GET("/models", scopeModels, h.Gateway.AntigravityModels)
+		antigravityV1.GET("/usage", scopeUsage, h.Gateway.Usage)
+	}
+
+	// 聚合端点：按请求模型（命名空间或模型名族）在 Key 分组内自动选择平台，客户端只需一个 Base URL。
+	// 不跨分组路由：分组无法调度的命名空间（如 Anthropic 分组请求 openai/...）返回 400，计费与限流始终按 Key 的分组
+	aggregatorV1 := r.Group("/aggregator/v1")
+	aggregatorV1.Use(bodyLimit)
+	aggregatorV1.Use(clientRequestID)
+	aggregatorV1.Use(opsErrorLogger)
+	aggregatorV1.Use(endpointNorm)
+	aggregatorV1.Use(gin.HandlerFunc(apiKeyAuth))
+	aggregatorV1.Use(requireGroupAnthropic)
+	aggregatorV1.Use(keyBodyLimit, groupHeaders, secretGuard, idempotency, coalescing, tokenBucket)
+	{
+		aggregatorV1.POST("/chat/completions", middleware.AggregatorModelRouting(), scopeChat, func(c *gin.Context) {
+			switch getGroupPlatform(c) {
+			case service.PlatformOpenAI:
+				h.OpenAIGateway.ChatCompletions(c)
+			case service.PlatformCustom:
+				h.Gateway.CustomChatCompletions(c)
+			default:
+				h.Gateway.ChatCompletions(c)
+			}
+		})
+		aggregatorV1.GET("/models", scopeModels, h.Gateway.AggregatorModels)
 	}
 
 	antigravityV1Beta := r.Group("/antigravity/v1beta")
@@ -207,12 +360,27 @@ func RegisterGatewayRoutes(
 	antigravityV1Beta.Use(middleware.ForcePlatform(service.PlatformAntigravity))
 	antigravityV1Beta.Use(middleware.APIKeyAuthWithSubscriptionGoogle(apiKeyService, subscriptionService, cfg))
 	antigravityV1Beta.Use(requireGroupGoogle)
+	antigravityV1Beta.Use(keyBodyLimit, groupHeaders, secretGuard, idempotencyGoogle, coalescing, tokenBucketGoogle)
 	{
-		antigravityV1Beta.GET("/models", h.Gateway.GeminiV1BetaListModels)
-		antigravityV1Beta.GET("/models/:model", h.Gateway.GeminiV1BetaGetModel)
-		antigravityV1Beta.POST("/models/*modelAction", h.Gateway.GeminiV1BetaModels)
+		antigravityV1Beta.GET("/models", scopeModelsGoogle, h.Gateway.GeminiV1BetaListModels)
+		antigravityV1Beta.GET("/models/:model", scopeModelsGoogle, h.Gateway.GeminiV1BetaGetModel)
+		antigravityV1Beta.POST("/models/*modelAction", scopeChatGoogle, h.Gateway.GeminiV1BetaModels)
 	}
 
+	// 批处理子请求经由本路由引擎执行 /v1/messages，复用鉴权、调度、限流与计费链路
+	if h.MessageBatch != nil {
+		h.MessageBatch.SetDispatcher(r)
+	}
+}
+
+// registerMessageBatchRoutes 注册 Claude Messages Batches 接口
+func registerMessageBatchRoutes(group *gin.RouterGroup, h *handler.Handlers, scopeChat gin.HandlerFunc) {
+	group.POST("/messages/batches", scopeChat, h.MessageBatch.Create)
+	group.GET("/messages/batches", scopeChat, h.MessageBatch.List)
+	group.GET("/messages/batches/:batch_id", scopeChat, h.MessageBatch.Get)
+	group.DELETE("/messages/batches/:batch_id", scopeChat, h.MessageBatch.Delete)
+	group.POST("/messages/batches/:batch_id/cancel", scopeChat, h.MessageBatch.Cancel)
+	group.GET("/messages/batches/:batch_id/results", scopeChat, h.MessageBatch.Results)
 }
 
 // getGroupPlatform extracts the group platform from the API Key stored in context.
@@ -223,3 +391,17 @@ func getGroupPlatform(c *gin.Context) string {
 	}
 	return apiKey.Group.Platform
 }
+
+// rejectCustomProvider 自定义上游分组仅支持 Chat Completions 与 Responses（Azure OpenAI），其余协议端点返回 404
+func rejectCustomProvider(c *gin.Context) bool {
+	if getGroupPlatform(c) != service.PlatformCustom {
+		return false
+	}
+	c.JSON(http.StatusNotFound, gin.H{
+		"error": gin.H{
+			"type":    "not_found_error",
+			"message": "This API is not supported for custom provider groups",
+		},
+	})
+	return true
+}
@@ -36,6 +36,9 @@ func newGatewayRoutesTestRouter() *gin.Engine {
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
+		nil,
 		&config.Config{},
 	)
 
@@ -77,3 +80,17 @@ func TestGatewayRoutesOpenAIImagesPathsAreRegistered(t *testing.T) {
 		require.NotEqual(t, http.StatusNotFound, w.Code, "path=%s should hit OpenAI images handler", path)
 	}
 }
+
+func TestGatewayRoutesGeminiPrefixedAliasIsRegistered(t *testing.T) {
+	router := newGatewayRoutesTestRouter()
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	for _, prefix := range []string{"/v1beta", "/gemini/v1beta"} {
+		require.True(t, registered["GET "+prefix+"/models"], prefix)
+		require.True(t, registered["GET "+prefix+"/models/:model"], prefix)
+		require.True(t, registered["POST "+prefix+"/models/*modelAction"], prefix)
+	}
+}
@@ -63,6 +63,28 @@ func RegisterUserRoutes(
 			keys.DELETE("/:id", h.APIKey.Delete)
 		}
 
+		// 用户自助绑定的上游账号
+		accounts := authenticated.Group("/accounts")
+		{
+			accounts.GET("", h.UserAccount.List)
+			accounts.POST("", h.UserAccount.Create)
+			accounts.PUT("/:id", h.UserAccount.Update)
+			accounts.DELETE("/:id", h.UserAccount.Delete)
+		}
+
+		// 组织管理员接口（仅限本组织范围）
+		org := authenticated.Group("/org")
+		{
+			org.GET("", h.Org.Overview)
+			org.GET("/members", h.Org.ListMembers)
+			org.PUT("/members/:id", h.Org.UpdateMember)
+			org.DELETE("/members/:id", h.Org.RemoveMember)
+			org.PUT("/members/:id/groups", h.Org.SetMemberGroups)
+			org.GET("/members/:id/api-keys", h.Org.ListMemberAPIKeys)
+			org.GET("/groups", h.Org.ListGroups)
+			org.PUT("/api-keys/:id", h.Org.UpdateAPIKey)
+		}
+
 		// 用户可用分组（非管理员接口）
 		groups := authenticated.Group("/groups")
 		{
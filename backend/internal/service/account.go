@@ -52,6 +52,14 @@ type Account struct {
 	SessionWindowEnd    *time.Time
 	SessionWindowStatus string
 
+	// OwnerUserID 自助绑定该账号的用户；nil 表示平台共享账号
+	OwnerUserID *int64
+
+	// Tags 账号标签，供分组/API Key 的账号标签选择器匹配
+	Tags []string
+	// TagsExclusive 为 true 时账号仅服务于 require 中包含其任一标签的选择器
+	TagsExclusive bool
+
 	Proxy         *Proxy
 	AccountGroups []AccountGroup
 	GroupIDs      []int64
@@ -124,6 +132,9 @@ func (a *Account) IsSchedulable() bool {
 	if a.IsAPIKeyOrBedrock() && a.IsQuotaExceeded() {
 		return false
 	}
+	if a.IsInMaintenance(now) {
+		return false
+	}
 	return true
 }
 
@@ -141,6 +152,31 @@ func (a *Account) IsOverloaded() bool {
 	return time.Now().Before(*a.OverloadUntil)
 }
 
+// Account cooldown reasons
+const (
+	AccountCooldownRateLimited       = "rate_limited"
+	AccountCooldownOverloaded        = "overloaded"
+	AccountCooldownTempUnschedulable = "temp_unschedulable"
+	AccountCooldownMaintenance       = "maintenance"
+)
+
+// ActiveCooldown 返回当前生效中结束最晚的冷却（限流/过载/临时不可调度/维护窗口）的结束时间及原因；无冷却时返回 nil。
+// 冷却结束后账号自动恢复调度。
+func (a *Account) ActiveCooldown(now time.Time) (*time.Time, string) {
+	var until *time.Time
+	var reason string
+	consider := func(t *time.Time, r string) {
+		if t != nil && now.Before(*t) && (until == nil || t.After(*until)) {
+			until, reason = t, r
+		}
+	}
+	consider(a.RateLimitResetAt, AccountCooldownRateLimited)
+	consider(a.OverloadUntil, AccountCooldownOverloaded)
+	consider(a.TempUnschedulableUntil, AccountCooldownTempUnschedulable)
+	consider(a.MaintenanceUntil(now), AccountCooldownMaintenance)
+	return until, reason
+}
+
 func (a *Account) IsOAuth() bool {
 	return a.Type == AccountTypeOAuth || a.Type == AccountTypeSetupToken
 }
@@ -733,6 +769,56 @@ func (a *Account) GetBaseURL() string {
 	return baseURL
 }
 
+// GetRegionBaseURLs 返回 API Key 账号配置的全部区域 base URL：credentials.base_url 在前，
+// 其后为 credentials.base_urls 中的备用区域（去重、去除末尾斜杠）。少于两个区域时返回 nil。
+func (a *Account) GetRegionBaseURLs() []string {
+	if a.Type != AccountTypeAPIKey || a.Credentials == nil {
+		return nil
+	}
+	primary := strings.TrimRight(strings.TrimSpace(a.GetCredential("base_url")), "/")
+	if primary == "" {
+		return nil
+	}
+	var extra []string
+	switch raw := a.Credentials["base_urls"].(type) {
+	case []any:
+		for _, v := range raw {
+			if str, ok := v.(string); ok {
+				extra = append(extra, str)
+			}
+		}
+	case []string:
+		extra = raw
+	}
+	urls := []string{primary}
+	seen := map[string]struct{}{primary: {}}
+	for _, u := range extra {
+		u = strings.TrimRight(strings.TrimSpace(u), "/")
+		if _, dup := seen[u]; u == "" || dup {
+			continue
+		}
+		seen[u] = struct{}{}
+		urls = append(urls, u)
+	}
+	if len(urls) < 2 {
+		return nil
+	}
+	return urls
+}
+
+// GetFallbackProxyID 返回 extra.fallback_proxy_id 配置的备用代理 ID：主代理不健康或达到并发上限时改用该代理。
+// 未配置、非法或与主代理相同时返回 0。
+func (a *Account) GetFallbackProxyID() int64 {
+	if a == nil || a.Extra == nil {
+		return 0
+	}
+	id := int64(parseExtraInt(a.Extra["fallback_proxy_id"]))
+	if id <= 0 || (a.ProxyID != nil && *a.ProxyID == id) {
+		return 0
+	}
+	return id
+}
+
 // GetGeminiBaseURL 返回 Gemini 兼容端点的 base URL。
 // Antigravity 平台的 APIKey 账号自动拼接 /antigravity。
 func (a *Account) GetGeminiBaseURL(defaultBaseURL string) string {
@@ -957,6 +1043,21 @@ func (a *Account) IsBedrockAPIKey() bool {
 }
 
 // IsAPIKeyOrBedrock 返回账号类型是否支持配额和池模式等特性
+// IsOllama 是否为 Ollama 本地模型账号
+func (a *Account) IsOllama() bool {
+	return a.Type == AccountTypeOllama
+}
+
+// IsAzureOpenAI 是否为 Azure OpenAI 部署式路由账号
+func (a *Account) IsAzureOpenAI() bool {
+	return a.Type == AccountTypeAzure
+}
+
+// IsZeroCostBilling 自托管模型账号（Ollama）按零成本计费，用量照常记录
+func (a *Account) IsZeroCostBilling() bool {
+	return a != nil && a.IsOllama()
+}
+
 func (a *Account) IsAPIKeyOrBedrock() bool {
 	return a.Type == AccountTypeAPIKey || a.Type == AccountTypeBedrock
 }
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// bodyTransformRulesKey 账号 extra 中保存请求体改写规则的字段
+const bodyTransformRulesKey = "body_transform_rules"
+
+// 请求体改写操作
+const (
+	BodyTransformOpSet     = "set"     // 强制写入 value（覆盖客户端取值）
+	BodyTransformOpDefault = "default" // 客户端未提供时写入 value
+	BodyTransformOpDelete  = "delete"  // 删除 path
+	BodyTransformOpRename  = "rename"  // 将 path 的值移动到 to
+)
+
+// ErrInvalidBodyTransformRules 账号请求体改写规则非法
+var ErrInvalidBodyTransformRules = infraerrors.BadRequest("INVALID_BODY_TRANSFORM_RULES", "body_transform_rules: each rule needs op (set/default/delete/rename) and a plain dotted path; set/default require value, rename requires to")
+
+// BodyTransformRule 账号级请求体改写规则，path/to 使用点分路径（如 reasoning.effort）
+type BodyTransformRule struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	To    string `json:"to,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// GetBodyTransformRules 读取账号配置的请求体改写规则，非法条目直接跳过
+func (a *Account) GetBodyTransformRules() []BodyTransformRule {
+	if a == nil || a.Extra == nil {
+		return nil
+	}
+	rules, _ := parseBodyTransformRules(a.Extra[bodyTransformRulesKey])
+	return rules
+}
+
+// ValidateBodyTransformRules 校验 extra 中的请求体改写规则，任一条目非法即返回错误
+func ValidateBodyTransformRules(extra map[string]any) error {
+	if extra == nil {
+		return nil
+	}
+	raw, ok := extra[bodyTransformRulesKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	if _, valid := parseBodyTransformRules(raw); !valid {
+		return ErrInvalidBodyTransformRules
+	}
+	return nil
+}
+
+// parseBodyTransformRules 解析规则列表；valid 表示全部条目均合法
+func parseBodyTransformRules(raw any) (rules []BodyTransformRule, valid bool) {
+	if raw == nil {
+		return nil, true
+	}
+	arr, ok := raw.([]any)
+	if !ok {
+		return nil, false
+	}
+	valid = true
+	rules = make([]BodyTransformRule, 0, len(arr))
+	for _, item := range arr {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			valid = false
+			continue
+		}
+		op, _ := entry["op"].(string)
+		path, _ := entry["path"].(string)
+		to, _ := entry["to"].(string)
+		value, hasValue := entry["value"]
+		rule := BodyTransformRule{
+			Op:    strings.ToLower(strings.TrimSpace(op)),
+			Path:  strings.TrimSpace(path),
+			To:    strings.TrimSpace(to),
+			Value: value,
+		}
+		if !isPlainBodyTransformPath(rule.Path) {
+			valid = false
+			continue
+		}
+		switch rule.Op {
+		case BodyTransformOpSet, BodyTransformOpDefault:
+			if !hasValue {
+				valid = false
+				continue
+			}
+		case BodyTransformOpDelete:
+		case BodyTransformOpRename:
+			if !isPlainBodyTransformPath(rule.To) || rule.To == rule.Path {
+				valid = false
+				continue
+			}
+		default:
+			valid = false
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, valid
+}
+
+// isPlainBodyTransformPath 仅允许普通点分路径，拒绝 gjson 的通配、查询与修饰符语法
+func isPlainBodyTransformPath(path string) bool {
+	if path == "" || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") || strings.Contains(path, "..") {
+		return false
+	}
+	return !strings.ContainsAny(path, "*?#|@\\")
+}
+
+// ApplyAccountBodyTransforms 按账号规则依次改写即将发往上游的请求体。
+// 规则作用于上游协议格式（已完成格式转换与模型映射），用于兼容个别上游的字段差异。
+// 无规则或请求体非法 JSON 时原样返回；单条规则失败不影响其余规则。
+func ApplyAccountBodyTransforms(body []byte, account *Account) []byte {
+	rules := account.GetBodyTransformRules()
+	if len(rules) == 0 || !gjson.ValidBytes(body) {
+		return body
+	}
+	for _, rule := range rules {
+		body = applyBodyTransformRule(body, rule)
+	}
+	return body
+}
+
+func applyBodyTransformRule(body []byte, rule BodyTransformRule) []byte {
+	switch rule.Op {
+	case BodyTransformOpSet, BodyTransformOpDefault:
+		if rule.Op == BodyTransformOpDefault {
+			if existing := gjson.GetBytes(body, rule.Path); existing.Exists() && existing.Type != gjson.Null {
+				return body
+			}
+		}
+		raw, err := json.Marshal(rule.Value)
+		if err != nil {
+			return body
+		}
+		if next, err := sjson.SetRawBytes(body, rule.Path, raw); err == nil {
+			return next
+		}
+	case BodyTransformOpDelete:
+		if !gjson.GetBytes(body, rule.Path).Exists() {
+			return body
+		}
+		if next, err := sjson.DeleteBytes(body, rule.Path); err == nil {
+			return next
+		}
+	case BodyTransformOpRename:
+		value := gjson.GetBytes(body, rule.Path)
+		if !value.Exists() {
+			return body
+		}
+		next, err := sjson.SetRawBytes(body, rule.To, []byte(value.Raw))
+		if err != nil {
+			return body
+		}
+		if next, err = sjson.DeleteBytes(next, rule.Path); err == nil {
+			return next
+		}
+	}
+	return body
+}
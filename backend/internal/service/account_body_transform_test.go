@@ -0,0 +1,71 @@
+//go:build unit
+
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func bodyTransformAccount(t *testing.T, rulesJSON string) *Account {
+	t.Helper()
+	var rules []any
+	require.NoError(t, json.Unmarshal([]byte(rulesJSON), &rules))
+	return &Account{Extra: map[string]any{bodyTransformRulesKey: rules}}
+}
+
+func TestApplyAccountBodyTransforms(t *testing.T) {
+	account := bodyTransformAccount(t, `[
+		{"op":"set","path":"reasoning_effort","value":"high"},
+		{"op":"default","path":"temperature","value":0.2},
+		{"op":"default","path":"top_p","value":0.9},
+		{"op":"delete","path":"metadata"},
+		{"op":"rename","path":"max_tokens","to":"max_completion_tokens"},
+		{"op":"set","path":"extra_body.safe_mode","value":{"enabled":false}}
+	]`)
+
+	body := []byte(`{"model":"gpt-4o","reasoning_effort":"low","top_p":0.5,"metadata":{"user_id":"u1"},"max_tokens":512}`)
+	out := ApplyAccountBodyTransforms(body, account)
+
+	require.Equal(t, "high", gjson.GetBytes(out, "reasoning_effort").String())
+	require.Equal(t, 0.2, gjson.GetBytes(out, "temperature").Float())
+	require.Equal(t, 0.5, gjson.GetBytes(out, "top_p").Float())
+	require.False(t, gjson.GetBytes(out, "metadata").Exists())
+	require.False(t, gjson.GetBytes(out, "max_tokens").Exists())
+	require.Equal(t, int64(512), gjson.GetBytes(out, "max_completion_tokens").Int())
+	require.False(t, gjson.GetBytes(out, "extra_body.safe_mode.enabled").Bool())
+	require.True(t, gjson.GetBytes(out, "extra_body.safe_mode.enabled").Exists())
+	require.Equal(t, "gpt-4o", gjson.GetBytes(out, "model").String())
+}
+
+func TestApplyAccountBodyTransforms_NoRulesOrInvalidBody(t *testing.T) {
+	body := []byte(`{"model":"gpt-4o"}`)
+	require.Equal(t, body, ApplyAccountBodyTransforms(body, &Account{}))
+
+	account := bodyTransformAccount(t, `[{"op":"delete","path":"model"}]`)
+	invalid := []byte(`{"model":`)
+	require.Equal(t, invalid, ApplyAccountBodyTransforms(invalid, account))
+}
+
+func TestValidateBodyTransformRules(t *testing.T) {
+	valid := bodyTransformAccount(t, `[{"op":"SET","path":"a.b","value":null},{"op":"rename","path":"a","to":"c"}]`)
+	require.NoError(t, ValidateBodyTransformRules(valid.Extra))
+	require.Len(t, valid.GetBodyTransformRules(), 2)
+
+	for _, rules := range []string{
+		`[{"op":"set","path":"a"}]`,
+		`[{"op":"rename","path":"a"}]`,
+		`[{"op":"rename","path":"a","to":"a"}]`,
+		`[{"op":"replace","path":"a","value":1}]`,
+		`[{"op":"delete","path":"messages.#.name"}]`,
+		`[{"op":"delete","path":""}]`,
+		`["delete metadata"]`,
+	} {
+		account := bodyTransformAccount(t, rules)
+		require.ErrorIs(t, ValidateBodyTransformRules(account.Extra), ErrInvalidBodyTransformRules, rules)
+	}
+	require.Error(t, ValidateBodyTransformRules(map[string]any{bodyTransformRulesKey: "delete metadata"}))
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/robfig/cron/v3"
+)
+
+// ErrInvalidMaintenanceWindows 账号维护窗口配置非法
+var ErrInvalidMaintenanceWindows = infraerrors.BadRequest("INVALID_MAINTENANCE_WINDOWS", "maintenance_windows must be a list of at most 20 {cron, duration_minutes} entries with a 5-field cron expression and a duration of 1-10080 minutes; maintenance_timezone must be a valid IANA timezone name")
+
+const accountMaintenanceMaxWindows = 20
+
+// AccountMaintenanceWindow 账号周期性维护窗口（extra.maintenance_windows 中的一项）。
+// 窗口以 Cron（5 段）触发时间为起点，持续 DurationMinutes 分钟；窗口内账号不参与调度，
+// 结束后自动恢复，无需手动启用/停用。Cron 按 extra.maintenance_timezone（默认 UTC）解释。
+type AccountMaintenanceWindow struct {
+	Cron            string `json:"cron"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+var (
+	// accountMaintenanceCronCache 已解析的 cron 表达式，避免调度热路径重复解析
+	accountMaintenanceCronCache sync.Map
+	// accountMaintenanceLocationCache 已加载的时区
+	accountMaintenanceLocationCache sync.Map
+)
+
+// GetMaintenanceWindows 读取账号维护窗口，非法条目被忽略
+func (a *Account) GetMaintenanceWindows() []AccountMaintenanceWindow {
+	if a == nil || a.Extra == nil {
+		return nil
+	}
+	windows, _ := parseMaintenanceWindows(a.Extra["maintenance_windows"])
+	return windows
+}
+
+// GetMaintenanceTimezone 获取维护窗口的时区名（IANA），默认 "UTC"
+func (a *Account) GetMaintenanceTimezone() string {
+	if tz := strings.TrimSpace(a.getExtraString("maintenance_timezone")); tz != "" {
+		return tz
+	}
+	return "UTC"
+}
+
+// MaintenanceUntil 返回 now 所处维护窗口的结束时间（多个窗口重叠时取最晚结束）；不在维护窗口内返回 nil。
+func (a *Account) MaintenanceUntil(now time.Time) *time.Time {
+	if a == nil || a.Extra == nil {
+		return nil
+	}
+	raw, ok := a.Extra["maintenance_windows"]
+	if !ok || raw == nil {
+		return nil
+	}
+	windows, _ := parseMaintenanceWindows(raw)
+	if len(windows) == 0 {
+		return nil
+	}
+	local := now.In(loadMaintenanceLocation(a.GetMaintenanceTimezone()))
+	var until *time.Time
+	for _, w := range windows {
+		sched, err := parseMaintenanceCron(w.Cron)
+		if err != nil {
+			continue
+		}
+		duration := time.Duration(w.DurationMinutes) * time.Minute
+		// 与轮换策略一致：(now-duration, now] 区间内存在触发时间即处于窗口内
+		start := sched.Next(local.Add(-duration))
+		if start.IsZero() || start.After(local) {
+			continue
+		}
+		end := start.Add(duration)
+		if until == nil || end.After(*until) {
+			until = &end
+		}
+	}
+	return until
+}
+
+// IsInMaintenance 账号当前是否处于维护窗口内
+func (a *Account) IsInMaintenance(now time.Time) bool {
+	return a.MaintenanceUntil(now) != nil
+}
+
+// ValidateMaintenanceWindows 校验账号 extra 中的维护窗口与时区配置
+func ValidateMaintenanceWindows(extra map[string]any) error {
+	if raw, ok := extra["maintenance_windows"]; ok && raw != nil {
+		if _, valid := parseMaintenanceWindows(raw); !valid {
+			return ErrInvalidMaintenanceWindows
+		}
+	}
+	if raw, ok := extra["maintenance_timezone"]; ok && raw != nil {
+		tz, isString := raw.(string)
+		if !isString {
+			return ErrInvalidMaintenanceWindows
+		}
+		if tz = strings.TrimSpace(tz); tz != "" {
+			if _, err := time.LoadLocation(tz); err != nil {
+				return ErrInvalidMaintenanceWindows
+			}
+		}
+	}
+	return nil
+}
+
+// parseMaintenanceWindows 解析 [{cron, duration_minutes}] 列表，非法条目被跳过；valid=false 表示存在非法条目
+func parseMaintenanceWindows(raw any) ([]AccountMaintenanceWindow, bool) {
+	if raw == nil {
+		return nil, true
+	}
+	entries, ok := raw.([]any)
+	if !ok {
+		return nil, false
+	}
+	valid := len(entries) <= accountMaintenanceMaxWindows
+	windows := make([]AccountMaintenanceWindow, 0, len(entries))
+	for _, entry := range entries {
+		m, ok := entry.(map[string]any)
+		if !ok {
+			valid = false
+			continue
+		}
+		expr, _ := m["cron"].(string)
+		expr = strings.TrimSpace(expr)
+		duration := parseExtraInt(m["duration_minutes"])
+		if duration <= 0 || duration > accountRotationMaxDurationMin {
+			valid = false
+			continue
+		}
+		if _, err := parseMaintenanceCron(expr); err != nil {
+			valid = false
+			continue
+		}
+		windows = append(windows, AccountMaintenanceWindow{Cron: expr, DurationMinutes: duration})
+	}
+	if len(windows) == 0 {
+		return nil, valid
+	}
+	return windows, valid
+}
+
+func parseMaintenanceCron(expr string) (cron.Schedule, error) {
+	if cached, ok := accountMaintenanceCronCache.Load(expr); ok {
+		return cached.(cron.Schedule), nil
+	}
+	sched, err := scheduledTestCronParser.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	accountMaintenanceCronCache.Store(expr, sched)
+	return sched, nil
+}
+
+func loadMaintenanceLocation(tz string) *time.Location {
+	if cached, ok := accountMaintenanceLocationCache.Load(tz); ok {
+		return cached.(*time.Location)
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+	accountMaintenanceLocationCache.Store(tz, loc)
+	return loc
+}
@@ -0,0 +1,77 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func maintenanceAccount(tz string, windows ...any) *Account {
+	extra := map[string]any{"maintenance_windows": windows}
+	if tz != "" {
+		extra["maintenance_timezone"] = tz
+	}
+	return &Account{ID: 1, Status: StatusActive, Schedulable: true, Extra: extra}
+}
+
+func TestAccountMaintenanceUntil(t *testing.T) {
+	// 每天 02:30 起 60 分钟（上海时间），跨越整点
+	account := maintenanceAccount("Asia/Shanghai", map[string]any{"cron": "30 2 * * *", "duration_minutes": float64(60)})
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+
+	until := account.MaintenanceUntil(time.Date(2026, 10, 14, 3, 0, 0, 0, shanghai))
+	require.NotNil(t, until)
+	require.True(t, until.Equal(time.Date(2026, 10, 14, 3, 30, 0, 0, shanghai)))
+
+	require.Nil(t, account.MaintenanceUntil(time.Date(2026, 10, 14, 3, 30, 0, 0, shanghai)))
+	require.Nil(t, account.MaintenanceUntil(time.Date(2026, 10, 14, 2, 29, 0, 0, shanghai)))
+}
+
+func TestAccountMaintenance_OverlappingWindowsUseLatestEnd(t *testing.T) {
+	account := maintenanceAccount("",
+		map[string]any{"cron": "0 22 * * 6", "duration_minutes": float64(240)}, // 周六 22:00 起，跨越午夜
+		map[string]any{"cron": "0 1 * * *", "duration_minutes": float64(30)},
+	)
+	now := time.Date(2026, 10, 18, 1, 15, 0, 0, time.UTC) // 周日
+
+	until, reason := account.ActiveCooldown(now)
+	require.NotNil(t, until)
+	require.Equal(t, AccountCooldownMaintenance, reason)
+	require.True(t, until.Equal(time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC)))
+}
+
+func TestAccountIsSchedulable_ExcludedDuringMaintenance(t *testing.T) {
+	now := time.Now().UTC()
+	start := now.Add(-5 * time.Minute)
+	cronExpr := time.Date(0, 1, 1, start.Hour(), start.Minute(), 0, 0, time.UTC).Format("4 15") + " * * *"
+
+	account := maintenanceAccount("", map[string]any{"cron": cronExpr, "duration_minutes": float64(30)})
+	require.True(t, account.IsInMaintenance(now))
+	require.False(t, account.IsSchedulable())
+
+	account.Extra["maintenance_windows"] = []any{map[string]any{"cron": cronExpr, "duration_minutes": float64(1)}}
+	require.True(t, account.IsSchedulable())
+}
+
+func TestValidateMaintenanceWindows(t *testing.T) {
+	require.NoError(t, ValidateMaintenanceWindows(map[string]any{}))
+	require.NoError(t, ValidateMaintenanceWindows(map[string]any{
+		"maintenance_windows":  []any{map[string]any{"cron": "0 3 * * 1-5", "duration_minutes": float64(45)}},
+		"maintenance_timezone": "Europe/Berlin",
+	}))
+
+	invalid := []map[string]any{
+		{"maintenance_windows": "0 3 * * *"},
+		{"maintenance_windows": []any{map[string]any{"cron": "bad", "duration_minutes": float64(10)}}},
+		{"maintenance_windows": []any{map[string]any{"cron": "0 3 * * *", "duration_minutes": float64(0)}}},
+		{"maintenance_windows": []any{map[string]any{"cron": "0 3 * * *", "duration_minutes": float64(10081)}}},
+		{"maintenance_timezone": "Mars/Olympus"},
+	}
+	for _, extra := range invalid {
+		require.ErrorIs(t, ValidateMaintenanceWindows(extra), ErrInvalidMaintenanceWindows)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/geminicli"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/openai"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	modelMappingImportTimeout   = 20 * time.Second
+	modelMappingImportBodyLimit = 4 << 20
+)
+
+var (
+	ErrModelMappingImportUnsupported = infraerrors.BadRequest("MODEL_MAPPING_IMPORT_UNSUPPORTED", "model list import is only supported for Anthropic/OpenAI/Gemini API key accounts")
+	ErrModelMappingImportUpstream    = infraerrors.ServiceUnavailable("MODEL_MAPPING_IMPORT_UPSTREAM_FAILED", "failed to fetch upstream model list")
+)
+
+// 归一化时剥离的日期/版本后缀（可叠加）：-20241022、-2024-10-22、-latest、Bedrock 的 -v1:0
+var modelAliasSuffixPattern = regexp.MustCompile(`(?:-\d{8}|-\d{4}-\d{2}-\d{2}|-latest|-v\d+:\d+)+$`)
+
+// ModelMappingSuggestion 单条模型映射建议（请求模型名 → 上游模型 ID）
+type ModelMappingSuggestion struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Reason exact：上游存在同名模型；alias：按别名/日期后缀归一化匹配
+	Reason string `json:"reason"`
+	// Current 账号当前对 From 的映射目标（未配置时为空）
+	Current string `json:"current,omitempty"`
+}
+
+// ModelMappingImportResult 上游模型列表导入结果
+type ModelMappingImportResult struct {
+	AccountID      int64                    `json:"account_id"`
+	UpstreamModels []string                 `json:"upstream_models"`
+	Suggestions    []ModelMappingSuggestion `json:"suggestions"`
+	// Unmatched 未被任何建议引用的上游模型，可按需手动加入映射
+	Unmatched []string `json:"unmatched"`
+}
+
+// SuggestModelMapping 拉取 API Key 账号上游的模型列表，按平台内置模型名生成映射建议：
+// 同名模型直接映射，带日期后缀/点号版本/提供商前缀的上游 ID 按归一化别名匹配。
+// 已与当前映射一致的建议不再返回。
+func (s *AccountTestService) SuggestModelMapping(ctx context.Context, accountID int64) (*ModelMappingImportResult, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, modelMappingImportTimeout)
+	defer cancel()
+
+	upstreamModels, err := s.fetchUpstreamModelIDs(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, unmatched := suggestModelMappings(knownRequestModels(account.Platform), upstreamModels, account.GetModelMapping())
+	return &ModelMappingImportResult{
+		AccountID:      account.ID,
+		UpstreamModels: upstreamModels,
+		Suggestions:    suggestions,
+		Unmatched:      unmatched,
+	}, nil
+}
+
+// fetchUpstreamModelIDs 请求账号上游的模型列表端点，返回去重排序后的模型 ID
+func (s *AccountTestService) fetchUpstreamModelIDs(ctx context.Context, account *Account) ([]string, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+
+	var modelsURL string
+	switch {
+	case account.Platform == PlatformAnthropic && account.Type == AccountTypeAPIKey:
+		baseURL, err := s.validateUpstreamBaseURL(account.GetBaseURL())
+		if err != nil {
+			return nil, infraerrors.BadRequest("INVALID_BASE_URL", err.Error())
+		}
+		header.Set("x-api-key", account.GetCredential("api_key"))
+		header.Set("anthropic-version", "2023-06-01")
+		modelsURL = strings.TrimSuffix(baseURL, "/") + "/v1/models?limit=1000"
+	case account.IsOpenAIApiKey():
+		baseURL, err := s.validateUpstreamBaseURL(account.GetOpenAIBaseURL())
+		if err != nil {
+			return nil, infraerrors.BadRequest("INVALID_BASE_URL", err.Error())
+		}
+		header.Set("Authorization", "Bearer "+account.GetCredential("api_key"))
+		modelsURL = strings.TrimSuffix(baseURL, "/") + "/v1/models"
+	case account.Platform == PlatformGemini && account.Type == AccountTypeAPIKey:
+		baseURL, err := s.validateUpstreamBaseURL(account.GetGeminiBaseURL(geminicli.AIStudioBaseURL))
+		if err != nil {
+			return nil, infraerrors.BadRequest("INVALID_BASE_URL", err.Error())
+		}
+		header.Set("x-goog-api-key", account.GetCredential("api_key"))
+		modelsURL = strings.TrimSuffix(baseURL, "/") + "/v1beta/models?pageSize=1000"
+	default:
+		return nil, ErrModelMappingImportUnsupported
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header = header
+
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.httpUpstream.DoWithTLS(req, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+	if err != nil {
+		return nil, infraerrors.ServiceUnavailable(ErrModelMappingImportUpstream.Reason, fmt.Sprintf("request failed: %v", err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, modelMappingImportBodyLimit))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, infraerrors.ServiceUnavailable(ErrModelMappingImportUpstream.Reason,
+			fmt.Sprintf("upstream returned HTTP %d: %s", resp.StatusCode, truncate(strings.TrimSpace(string(body)), 200)))
+	}
+	return parseUpstreamModelIDs(body), nil
+}
+
+// parseUpstreamModelIDs 兼容 OpenAI/Anthropic 的 data[].id 与 Gemini 的 models[].name（去掉 models/ 前缀）
+func parseUpstreamModelIDs(body []byte) []string {
+	seen := make(map[string]struct{})
+	ids := make([]string, 0)
+	add := func(id string) {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return
+		}
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	gjson.GetBytes(body, "data.#.id").ForEach(func(_, v gjson.Result) bool {
+		add(v.String())
+		return true
+	})
+	gjson.GetBytes(body, "models.#.name").ForEach(func(_, v gjson.Result) bool {
+		add(strings.TrimPrefix(v.String(), "models/"))
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
+
+// knownRequestModels 平台内置的客户端请求模型名（含 Claude 短名），作为映射的源模型
+func knownRequestModels(platform string) []string {
+	var models []string
+	switch platform {
+	case PlatformAnthropic:
+		for _, m := range claude.DefaultModels {
+			models = append(models, m.ID)
+		}
+		for short := range claude.ModelIDOverrides {
+			models = append(models, short)
+		}
+	case PlatformOpenAI:
+		for _, m := range openai.DefaultModels {
+			models = append(models, m.ID)
+		}
+	case PlatformGemini:
+		for _, m := range geminicli.DefaultModels {
+			models = append(models, m.ID)
+		}
+	}
+	sort.Strings(models)
+	return models
+}
+
+// canonicalModelAlias 归一化模型名用于别名匹配：去提供商前缀（anthropic/、anthropic.）、
+// 小写、点号版本转连字符（claude-3.5-sonnet → claude-3-5-sonnet）、去日期/版本后缀
+func canonicalModelAlias(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if idx := strings.LastIndex(id, "/"); idx >= 0 {
+		id = id[idx+1:]
+	}
+	if idx := strings.Index(id, "."); idx >= 0 && !strings.HasPrefix(id, "claude") && strings.HasPrefix(id[idx+1:], "claude") {
+		id = id[idx+1:]
+	}
+	id = strings.ReplaceAll(id, ".", "-")
+	return modelAliasSuffixPattern.ReplaceAllString(id, "")
+}
+
+// suggestModelMappings 为每个已知请求模型在上游列表中挑选目标：优先同名，其次归一化别名
+// （多个候选时取字典序最大者，通常为最新日期快照）。与当前映射一致的建议会被省略。
+func suggestModelMappings(requestModels, upstreamModels []string, current map[string]string) ([]ModelMappingSuggestion, []string) {
+	upstreamSet := make(map[string]struct{}, len(upstreamModels))
+	byAlias := make(map[string]string, len(upstreamModels))
+	for _, id := range upstreamModels {
+		upstreamSet[id] = struct{}{}
+		alias := canonicalModelAlias(id)
+		if prev, ok := byAlias[alias]; !ok || id > prev {
+			byAlias[alias] = id
+		}
+	}
+
+	used := make(map[string]struct{})
+	suggestions := make([]ModelMappingSuggestion, 0)
+	for _, from := range requestModels {
+		to, reason := "", ""
+		if _, ok := upstreamSet[from]; ok {
+			to, reason = from, "exact"
+		} else if target, ok := byAlias[canonicalModelAlias(from)]; ok {
+			to, reason = target, "alias"
+		} else {
+			continue
+		}
+		used[to] = struct{}{}
+		if current[from] == to {
+			continue
+		}
+		suggestions = append(suggestions, ModelMappingSuggestion{From: from, To: to, Reason: reason, Current: current[from]})
+	}
+
+	unmatched := make([]string, 0)
+	for _, id := range upstreamModels {
+		if _, ok := used[id]; !ok {
+			unmatched = append(unmatched, id)
+		}
+	}
+	return suggestions, unmatched
+}
+
+// MergeModelMapping 将批量接受的映射写入账号凭证副本；replace 为 true 时替换整个 model_mapping
+func MergeModelMapping(credentials map[string]any, mapping map[string]string, replace bool) map[string]any {
+	merged := make(map[string]any, len(credentials)+1)
+	for k, v := range credentials {
+		merged[k] = v
+	}
+
+	next := make(map[string]any, len(mapping))
+	if !replace {
+		if existing, ok := credentials["model_mapping"].(map[string]any); ok {
+			for k, v := range existing {
+				next[k] = v
+			}
+		}
+	}
+	for from, to := range mapping {
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if from == "" || to == "" {
+			continue
+		}
+		next[from] = to
+	}
+	merged["model_mapping"] = next
+	return merged
+}
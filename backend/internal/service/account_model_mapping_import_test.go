@@ -0,0 +1,76 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalModelAlias(t *testing.T) {
+	require.Equal(t, "claude-3-5-sonnet", canonicalModelAlias("claude-3-5-sonnet-20241022"))
+	require.Equal(t, "claude-3-5-sonnet", canonicalModelAlias("anthropic/claude-3.5-sonnet"))
+	require.Equal(t, "claude-sonnet-4-5", canonicalModelAlias("anthropic.claude-sonnet-4-5-20250929-v1:0"))
+	require.Equal(t, "gpt-4o", canonicalModelAlias("gpt-4o-2024-08-06"))
+	require.Equal(t, "claude-opus-4-6", canonicalModelAlias("claude-opus-4-6"))
+	require.Equal(t, "deepseek-v3", canonicalModelAlias("deepseek-v3"))
+}
+
+func TestSuggestModelMappings(t *testing.T) {
+	upstream := []string{"anthropic/claude-sonnet-4.5", "claude-opus-4-6", "claude-haiku-4-5-20251001", "claude-haiku-4-5-20250101", "vendor-special"}
+	suggestions, unmatched := suggestModelMappings(
+		[]string{"claude-haiku-4-5", "claude-opus-4-6", "claude-sonnet-4-5", "claude-sonnet-4-6"},
+		upstream,
+		map[string]string{"claude-opus-4-6": "claude-opus-4-6"},
+	)
+
+	require.Equal(t, []ModelMappingSuggestion{
+		{From: "claude-haiku-4-5", To: "claude-haiku-4-5-20251001", Reason: "alias"},
+		{From: "claude-sonnet-4-5", To: "anthropic/claude-sonnet-4.5", Reason: "alias"},
+	}, suggestions, "已一致的 opus 映射省略，haiku 取最新日期快照")
+	require.Equal(t, []string{"claude-haiku-4-5-20250101", "vendor-special"}, unmatched)
+}
+
+func TestSuggestModelMapping_FetchesUpstreamModels(t *testing.T) {
+	account := &Account{ID: 11, Platform: PlatformAnthropic, Type: AccountTypeAPIKey, Status: StatusActive,
+		Credentials: map[string]any{"api_key": "sk-ant", "base_url": "https://relay.example.com/"}}
+	svc, upstream := newAccountVerifyTestService(account, newJSONResponse(http.StatusOK,
+		`{"data":[{"id":"claude-opus-4-6"},{"id":"claude-sonnet-4-5-20250929"},{"id":"claude-opus-4-6"}]}`))
+
+	result, err := svc.SuggestModelMapping(context.Background(), 11)
+	require.NoError(t, err)
+	require.Len(t, upstream.requests, 1)
+	require.Equal(t, "https://relay.example.com/v1/models?limit=1000", upstream.requests[0].URL.String())
+	require.Equal(t, "sk-ant", upstream.requests[0].Header.Get("x-api-key"))
+	require.Equal(t, []string{"claude-opus-4-6", "claude-sonnet-4-5-20250929"}, result.UpstreamModels)
+	require.Contains(t, result.Suggestions, ModelMappingSuggestion{From: "claude-sonnet-4-5", To: "claude-sonnet-4-5-20250929", Reason: "alias"})
+	require.Contains(t, result.Suggestions, ModelMappingSuggestion{From: "claude-opus-4-6", To: "claude-opus-4-6", Reason: "exact"})
+	require.Empty(t, result.Unmatched)
+}
+
+func TestSuggestModelMapping_UpstreamErrorAndUnsupported(t *testing.T) {
+	account := &Account{ID: 12, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Credentials: map[string]any{"api_key": "sk"}}
+	svc, _ := newAccountVerifyTestService(account, newJSONResponse(http.StatusUnauthorized, `{"error":"bad key"}`))
+	_, err := svc.SuggestModelMapping(context.Background(), 12)
+	require.ErrorIs(t, err, ErrModelMappingImportUpstream)
+
+	oauth := &Account{ID: 13, Platform: PlatformAnthropic, Type: AccountTypeOAuth}
+	svc, _ = newAccountVerifyTestService(oauth)
+	_, err = svc.SuggestModelMapping(context.Background(), 13)
+	require.ErrorIs(t, err, ErrModelMappingImportUnsupported)
+}
+
+func TestMergeModelMapping(t *testing.T) {
+	creds := map[string]any{"api_key": "sk", "model_mapping": map[string]any{"a": "a1", "b": "b1"}}
+
+	merged := MergeModelMapping(creds, map[string]string{"b": "b2", "c": "c1", " ": "x"}, false)
+	require.Equal(t, map[string]any{"a": "a1", "b": "b2", "c": "c1"}, merged["model_mapping"])
+	require.Equal(t, "sk", merged["api_key"])
+	require.Equal(t, map[string]any{"a": "a1", "b": "b1"}, creds["model_mapping"], "原凭证不应被修改")
+
+	replaced := MergeModelMapping(creds, map[string]string{"c": "c1"}, true)
+	require.Equal(t, map[string]any{"c": "c1"}, replaced["model_mapping"])
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// requestUserIDFromContext 返回当前请求 API Key 所属用户 ID；无认证上下文时返回 0。
+func requestUserIDFromContext(ctx context.Context) int64 {
+	if ctx == nil {
+		return 0
+	}
+	userID, _ := ctx.Value(ctxkey.UserID).(int64)
+	return userID
+}
+
+// IsUserOwned 账号是否为用户自助绑定的账号
+func (a *Account) IsUserOwned() bool {
+	return a != nil && a.OwnerUserID != nil && *a.OwnerUserID > 0
+}
+
+// IsUsableByUser 共享账号对所有用户可调度；自助绑定的账号仅归属用户可调度。
+func (a *Account) IsUsableByUser(userID int64) bool {
+	if !a.IsUserOwned() {
+		return true
+	}
+	return userID > 0 && *a.OwnerUserID == userID
+}
+
+// applyAccountOwnership 按请求用户过滤调度候选：
+// 剔除其他用户自助绑定的账号；请求用户自己的账号提升到候选最高优先级之前，
+// 使其优先于共享账号被选中，自有账号不可用时仍可回退到共享账号。
+// 返回的自有账号为副本，不影响调度快照缓存。
+func applyAccountOwnership(ctx context.Context, accounts []Account) []Account {
+	hasOwned := false
+	for i := range accounts {
+		if accounts[i].IsUserOwned() {
+			hasOwned = true
+			break
+		}
+	}
+	if !hasOwned {
+		return accounts
+	}
+
+	userID := requestUserIDFromContext(ctx)
+	filtered := make([]Account, 0, len(accounts))
+	minPriority, hasShared := 0, false
+	for i := range accounts {
+		acc := accounts[i]
+		if !acc.IsUsableByUser(userID) {
+			continue
+		}
+		if !acc.IsUserOwned() && (!hasShared || acc.Priority < minPriority) {
+			minPriority, hasShared = acc.Priority, true
+		}
+		filtered = append(filtered, acc)
+	}
+	if !hasShared {
+		return filtered
+	}
+	for i := range filtered {
+		if filtered[i].IsUserOwned() && filtered[i].Priority >= minPriority {
+			filtered[i].Priority = minPriority - 1
+		}
+	}
+	return filtered
+}
+
+// restrictAccountOwnership 按 ID 获取的账号（粘性会话、previous_response_id 等）
+// 不属于请求用户时以不可调度的副本返回，使调度器切换到其他账号。
+func restrictAccountOwnership(ctx context.Context, account *Account) *Account {
+	if account == nil || account.IsUsableByUser(requestUserIDFromContext(ctx)) {
+		return account
+	}
+	cp := *account
+	cp.Schedulable = false
+	return &cp
+}
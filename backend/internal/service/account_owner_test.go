@@ -0,0 +1,62 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func ownedAccount(id, owner int64, priority int) Account {
+	return Account{ID: id, Priority: priority, Schedulable: true, Status: StatusActive, OwnerUserID: &owner}
+}
+
+func TestApplyAccountOwnership_NoOwnedAccountsKeepsSlice(t *testing.T) {
+	accounts := []Account{{ID: 1, Priority: 10}, {ID: 2, Priority: 20}}
+	out := applyAccountOwnership(context.Background(), accounts)
+	require.Equal(t, accounts, out)
+}
+
+func TestApplyAccountOwnership_FiltersOtherUsersAndPrefersOwn(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxkey.UserID, int64(7))
+	accounts := []Account{
+		{ID: 1, Priority: 10},
+		ownedAccount(2, 7, 50),
+		ownedAccount(3, 8, 1),
+		{ID: 4, Priority: 5},
+	}
+
+	out := applyAccountOwnership(ctx, accounts)
+
+	ids := make([]int64, 0, len(out))
+	for _, acc := range out {
+		ids = append(ids, acc.ID)
+	}
+	require.Equal(t, []int64{1, 2, 4}, ids)
+	require.Equal(t, 4, out[1].Priority, "owned account should rank before the best shared account")
+	require.Equal(t, 50, accounts[1].Priority, "input slice must not be mutated")
+}
+
+func TestApplyAccountOwnership_NoUserExcludesOwned(t *testing.T) {
+	out := applyAccountOwnership(context.Background(), []Account{ownedAccount(1, 7, 1), {ID: 2, Priority: 1}})
+	require.Len(t, out, 1)
+	require.Equal(t, int64(2), out[0].ID)
+}
+
+func TestRestrictAccountOwnership(t *testing.T) {
+	owned := ownedAccount(1, 7, 1)
+
+	ownerCtx := context.WithValue(context.Background(), ctxkey.UserID, int64(7))
+	require.Same(t, &owned, restrictAccountOwnership(ownerCtx, &owned))
+
+	otherCtx := context.WithValue(context.Background(), ctxkey.UserID, int64(8))
+	restricted := restrictAccountOwnership(otherCtx, &owned)
+	require.False(t, restricted.IsSchedulable())
+	require.True(t, owned.Schedulable)
+
+	shared := &Account{ID: 2, Schedulable: true}
+	require.Same(t, shared, restrictAccountOwnership(otherCtx, shared))
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// Account rotation policy types
+const (
+	// AccountRotationTypeSchedule 按 cron 时间窗口启用/停用账号
+	AccountRotationTypeSchedule = "schedule"
+	// AccountRotationTypeDailyPrimary 每天轮换一个主账号（按账号列表顺序），主账号获得最高调度优先级
+	AccountRotationTypeDailyPrimary = "daily_primary"
+)
+
+// Account rotation schedule actions
+const (
+	// AccountRotationActionDisable 时间窗口内账号不参与调度
+	AccountRotationActionDisable = "disable"
+	// AccountRotationActionEnable 仅在时间窗口内参与调度
+	AccountRotationActionEnable = "enable"
+)
+
+const (
+	accountRotationCacheTTL       = 30 * time.Second
+	accountRotationMaxPolicies    = 100
+	accountRotationMaxDurationMin = 7 * 24 * 60
+)
+
+// ErrAccountRotationPolicyNotFound 轮换策略不存在
+var ErrAccountRotationPolicyNotFound = infraerrors.NotFound("ACCOUNT_ROTATION_POLICY_NOT_FOUND", "account rotation policy not found")
+
+// AccountRotationPolicy 账号定时轮换策略，存储在 settings 表中（JSON 列表）。
+//
+// schedule 类型：窗口起点由 Cron 表达式（5 段）给出，持续 DurationMinutes 分钟；
+// Action=disable 表示窗口内停用账号（如工作时间不使用个人账号），Action=enable 表示仅窗口内启用。
+// daily_primary 类型：按 Timezone 的自然日在 AccountIDs 中依次轮换主账号。
+type AccountRotationPolicy struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	Enabled         bool      `json:"enabled"`
+	Type            string    `json:"type"`
+	AccountIDs      []int64   `json:"account_ids"`
+	Cron            string    `json:"cron,omitempty"`
+	DurationMinutes int       `json:"duration_minutes,omitempty"`
+	Action          string    `json:"action,omitempty"`
+	Timezone        string    `json:"timezone,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// AccountRotationService 管理账号轮换策略，并在调度选号时应用策略结果。
+type AccountRotationService struct {
+	settingRepo SettingRepository
+	nowFn       func() time.Time
+
+	mu       sync.Mutex
+	cache    []AccountRotationPolicy
+	cachedAt time.Time
+	loaded   bool
+}
+
+// NewAccountRotationService creates a new AccountRotationService.
+func NewAccountRotationService(settingRepo SettingRepository) *AccountRotationService {
+	return &AccountRotationService{
+		settingRepo: settingRepo,
+		nowFn:       time.Now,
+	}
+}
+
+// List 返回全部轮换策略
+func (s *AccountRotationService) List(ctx context.Context) ([]AccountRotationPolicy, error) {
+	if s == nil || s.settingRepo == nil {
+		return []AccountRotationPolicy{}, nil
+	}
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyAccountRotationPolicies)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return []AccountRotationPolicy{}, nil
+		}
+		return nil, err
+	}
+	var policies []AccountRotationPolicy
+	if err := json.Unmarshal([]byte(raw), &policies); err != nil {
+		// 损坏的 JSON 不应影响调度，按未配置处理
+		slog.Warn("account_rotation_policies_invalid", "error", err)
+		return []AccountRotationPolicy{}, nil
+	}
+	if policies == nil {
+		policies = []AccountRotationPolicy{}
+	}
+	return policies, nil
+}
+
+// Create 新建轮换策略
+func (s *AccountRotationService) Create(ctx context.Context, policy *AccountRotationPolicy) (*AccountRotationPolicy, error) {
+	if policy == nil {
+		return nil, infraerrors.BadRequest("INVALID_ROTATION_POLICY", "invalid request")
+	}
+	normalizeAccountRotationPolicy(policy)
+	if err := validateAccountRotationPolicy(policy); err != nil {
+		return nil, err
+	}
+	policies, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) >= accountRotationMaxPolicies {
+		return nil, infraerrors.BadRequest("TOO_MANY_ROTATION_POLICIES", "too many account rotation policies")
+	}
+	var maxID int64
+	for _, p := range policies {
+		if p.ID > maxID {
+			maxID = p.ID
+		}
+	}
+	now := s.nowFn()
+	policy.ID = maxID + 1
+	policy.CreatedAt = now
+	policy.UpdatedAt = now
+	policies = append(policies, *policy)
+	if err := s.save(ctx, policies); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Update 替换指定策略的内容（保留 ID 与创建时间）
+func (s *AccountRotationService) Update(ctx context.Context, id int64, policy *AccountRotationPolicy) (*AccountRotationPolicy, error) {
+	if policy == nil {
+		return nil, infraerrors.BadRequest("INVALID_ROTATION_POLICY", "invalid request")
+	}
+	normalizeAccountRotationPolicy(policy)
+	if err := validateAccountRotationPolicy(policy); err != nil {
+		return nil, err
+	}
+	policies, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range policies {
+		if policies[i].ID != id {
+			continue
+		}
+		policy.ID = id
+		policy.CreatedAt = policies[i].CreatedAt
+		policy.UpdatedAt = s.nowFn()
+		policies[i] = *policy
+		if err := s.save(ctx, policies); err != nil {
+			return nil, err
+		}
+		return policy, nil
+	}
+	return nil, ErrAccountRotationPolicyNotFound
+}
+
+// Delete 删除指定策略
+func (s *AccountRotationService) Delete(ctx context.Context, id int64) error {
+	policies, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range policies {
+		if policies[i].ID == id {
+			policies = append(policies[:i], policies[i+1:]...)
+			return s.save(ctx, policies)
+		}
+	}
+	return ErrAccountRotationPolicyNotFound
+}
+
+func (s *AccountRotationService) save(ctx context.Context, policies []AccountRotationPolicy) error {
+	if s == nil || s.settingRepo == nil {
+		return errors.New("setting repository not initialized")
+	}
+	raw, err := json.Marshal(policies)
+	if err != nil {
+		return err
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyAccountRotationPolicies, string(raw)); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.loaded = false
+	s.cache = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// loadPolicies 读取带短期缓存的策略列表，避免每次选号都访问数据库
+func (s *AccountRotationService) loadPolicies(ctx context.Context) []AccountRotationPolicy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded && s.nowFn().Sub(s.cachedAt) < accountRotationCacheTTL {
+		return s.cache
+	}
+	policies, err := s.List(ctx)
+	if err != nil {
+		slog.Warn("account_rotation_policies_load_failed", "error", err)
+		return s.cache
+	}
+	s.cache = policies
+	s.cachedAt = s.nowFn()
+	s.loaded = true
+	return policies
+}
+
+// accountRotationDecision 某一时刻所有策略的合并结果
+type accountRotationDecision struct {
+	disabled map[int64]struct{}
+	primary  map[int64]struct{}
+}
+
+func (d accountRotationDecision) empty() bool {
+	return len(d.disabled) == 0 && len(d.primary) == 0
+}
+
+func evaluateAccountRotationPolicies(policies []AccountRotationPolicy, now time.Time) accountRotationDecision {
+	decision := accountRotationDecision{}
+	for i := range policies {
+		p := &policies[i]
+		if !p.Enabled || len(p.AccountIDs) == 0 {
+			continue
+		}
+		local := now.In(loadRotationLocation(p.Timezone))
+		switch p.Type {
+		case AccountRotationTypeSchedule:
+			active, err := rotationWindowActive(p.Cron, time.Duration(p.DurationMinutes)*time.Minute, local)
+			if err != nil {
+				continue
+			}
+			if active == (p.Action == AccountRotationActionDisable) {
+				if decision.disabled == nil {
+					decision.disabled = make(map[int64]struct{})
+				}
+				for _, id := range p.AccountIDs {
+					decision.disabled[id] = struct{}{}
+				}
+			}
+		case AccountRotationTypeDailyPrimary:
+			if decision.primary == nil {
+				decision.primary = make(map[int64]struct{})
+			}
+			decision.primary[dailyPrimaryAccountID(p.AccountIDs, local)] = struct{}{}
+		}
+	}
+	return decision
+}
+
+// rotationWindowActive 判断 now 是否处于某个以 cron 触发时间为起点、持续 duration 的窗口内：
+// 即 (now-duration, now] 区间内存在触发时间。
+func rotationWindowActive(cronExpr string, duration time.Duration, now time.Time) (bool, error) {
+	sched, err := scheduledTestCronParser.Parse(cronExpr)
+	if err != nil {
+		return false, err
+	}
+	start := sched.Next(now.Add(-duration))
+	return !start.IsZero() && !start.After(now), nil
+}
+
+// dailyPrimaryAccountID 按本地自然日序号在账号列表中轮换
+func dailyPrimaryAccountID(accountIDs []int64, local time.Time) int64 {
+	y, m, d := local.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400
+	return accountIDs[int(day%int64(len(accountIDs)))]
+}
+
+func loadRotationLocation(tz string) *time.Location {
+	if tz == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// Apply 对候选账号应用轮换策略：移除当前被停用的账号，并把当天的主账号提升到候选集中的最高优先级。
+// accounts 为调用方持有的副本，会被原地修改。
+func (s *AccountRotationService) Apply(ctx context.Context, accounts []Account) []Account {
+	if s == nil || len(accounts) == 0 {
+		return accounts
+	}
+	decision := evaluateAccountRotationPolicies(s.loadPolicies(ctx), s.nowFn())
+	if decision.empty() {
+		return accounts
+	}
+	return applyAccountRotationDecision(accounts, decision)
+}
+
+func applyAccountRotationDecision(accounts []Account, decision accountRotationDecision) []Account {
+	filtered := accounts[:0]
+	for _, acc := range accounts {
+		if _, ok := decision.disabled[acc.ID]; ok {
+			continue
+		}
+		filtered = append(filtered, acc)
+	}
+	if len(decision.primary) == 0 || len(filtered) == 0 {
+		return filtered
+	}
+	minPriority := filtered[0].Priority
+	for _, acc := range filtered[1:] {
+		if acc.Priority < minPriority {
+			minPriority = acc.Priority
+		}
+	}
+	for i := range filtered {
+		if _, ok := decision.primary[filtered[i].ID]; ok {
+			filtered[i].Priority = minPriority - 1
+		}
+	}
+	return filtered
+}
+
+// IsAccountDisabled 判断账号当前是否被轮换策略停用（供粘性会话等按 ID 取号的路径使用）
+func (s *AccountRotationService) IsAccountDisabled(ctx context.Context, accountID int64) bool {
+	if s == nil {
+		return false
+	}
+	decision := evaluateAccountRotationPolicies(s.loadPolicies(ctx), s.nowFn())
+	_, ok := decision.disabled[accountID]
+	return ok
+}
+
+func normalizeAccountRotationPolicy(p *AccountRotationPolicy) {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Type = strings.ToLower(strings.TrimSpace(p.Type))
+	p.Action = strings.ToLower(strings.TrimSpace(p.Action))
+	p.Cron = strings.TrimSpace(p.Cron)
+	p.Timezone = strings.TrimSpace(p.Timezone)
+
+	seen := make(map[int64]struct{}, len(p.AccountIDs))
+	ids := make([]int64, 0, len(p.AccountIDs))
+	for _, id := range p.AccountIDs {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	p.AccountIDs = ids
+
+	if p.Type == AccountRotationTypeDailyPrimary {
+		p.Cron = ""
+		p.DurationMinutes = 0
+		p.Action = ""
+	}
+}
+
+func validateAccountRotationPolicy(p *AccountRotationPolicy) error {
+	if p.Name == "" {
+		return infraerrors.BadRequest("INVALID_ROTATION_POLICY", "name is required")
+	}
+	if len(p.AccountIDs) == 0 {
+		return infraerrors.BadRequest("INVALID_ROTATION_POLICY", "account_ids is required")
+	}
+	if p.Timezone != "" {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			return infraerrors.BadRequest("INVALID_ROTATION_POLICY", "invalid timezone: "+p.Timezone)
+		}
+	}
+	switch p.Type {
+	case AccountRotationTypeSchedule:
+		if _, err := scheduledTestCronParser.Parse(p.Cron); err != nil {
+			return infraerrors.BadRequest("INVALID_ROTATION_POLICY", "invalid cron expression: "+err.Error())
+		}
+		if p.DurationMinutes <= 0 || p.DurationMinutes > accountRotationMaxDurationMin {
+			return infraerrors.BadRequest("INVALID_ROTATION_POLICY", "duration_minutes must be between 1 and 10080")
+		}
+		if p.Action != AccountRotationActionDisable && p.Action != AccountRotationActionEnable {
+			return infraerrors.BadRequest("INVALID_ROTATION_POLICY", "action must be one of: disable, enable")
+		}
+	case AccountRotationTypeDailyPrimary:
+		if len(p.AccountIDs) < 2 {
+			return infraerrors.BadRequest("INVALID_ROTATION_POLICY", "daily_primary requires at least two accounts")
+		}
+	default:
+		return infraerrors.BadRequest("INVALID_ROTATION_POLICY", "type must be one of: schedule, daily_primary")
+	}
+	return nil
+}
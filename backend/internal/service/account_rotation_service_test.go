@@ -0,0 +1,123 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type rotationSettingRepoStub struct {
+	settingRepoStub
+}
+
+func (s *rotationSettingRepoStub) Set(ctx context.Context, key, value string) error {
+	s.values[key] = value
+	return nil
+}
+
+func TestRotationWindowActive(t *testing.T) {
+	loc := time.UTC
+	// 工作日 09:00 起 9 小时
+	cronExpr := "0 9 * * 1-5"
+	duration := 9 * time.Hour
+
+	active, err := rotationWindowActive(cronExpr, duration, time.Date(2026, 10, 14, 10, 30, 0, 0, loc)) // 周三
+	require.NoError(t, err)
+	require.True(t, active)
+
+	active, err = rotationWindowActive(cronExpr, duration, time.Date(2026, 10, 14, 18, 0, 0, 0, loc))
+	require.NoError(t, err)
+	require.False(t, active)
+
+	active, err = rotationWindowActive(cronExpr, duration, time.Date(2026, 10, 17, 10, 0, 0, 0, loc)) // 周六
+	require.NoError(t, err)
+	require.False(t, active)
+
+	_, err = rotationWindowActive("bad cron", duration, time.Now())
+	require.Error(t, err)
+}
+
+func TestEvaluateAccountRotationPolicies(t *testing.T) {
+	policies := []AccountRotationPolicy{
+		{Enabled: true, Type: AccountRotationTypeSchedule, AccountIDs: []int64{1}, Cron: "0 9 * * 1-5", DurationMinutes: 540, Action: AccountRotationActionDisable, Timezone: "UTC"},
+		{Enabled: true, Type: AccountRotationTypeSchedule, AccountIDs: []int64{2}, Cron: "0 9 * * 1-5", DurationMinutes: 540, Action: AccountRotationActionEnable, Timezone: "UTC"},
+		{Enabled: false, Type: AccountRotationTypeSchedule, AccountIDs: []int64{3}, Cron: "* * * * *", DurationMinutes: 60, Action: AccountRotationActionDisable},
+	}
+
+	// 工作时间：个人账号 1 停用，账号 2 启用
+	decision := evaluateAccountRotationPolicies(policies, time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC))
+	require.Contains(t, decision.disabled, int64(1))
+	require.NotContains(t, decision.disabled, int64(2))
+	require.NotContains(t, decision.disabled, int64(3))
+
+	// 非工作时间：反之
+	decision = evaluateAccountRotationPolicies(policies, time.Date(2026, 10, 14, 20, 0, 0, 0, time.UTC))
+	require.NotContains(t, decision.disabled, int64(1))
+	require.Contains(t, decision.disabled, int64(2))
+}
+
+func TestApplyAccountRotationDecision_DailyPrimary(t *testing.T) {
+	policies := []AccountRotationPolicy{
+		{Enabled: true, Type: AccountRotationTypeDailyPrimary, AccountIDs: []int64{1, 2, 3}, Timezone: "UTC"},
+	}
+	day := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	primaries := map[int64]bool{}
+	for i := 0; i < 3; i++ {
+		decision := evaluateAccountRotationPolicies(policies, day.AddDate(0, 0, i))
+		require.Len(t, decision.primary, 1)
+		for id := range decision.primary {
+			primaries[id] = true
+		}
+	}
+	require.Len(t, primaries, 3, "primary should rotate through all accounts over consecutive days")
+
+	accounts := []Account{{ID: 1, Priority: 5}, {ID: 2, Priority: 1}, {ID: 3, Priority: 5}, {ID: 4, Priority: 3}}
+	out := applyAccountRotationDecision(accounts, accountRotationDecision{
+		disabled: map[int64]struct{}{4: {}},
+		primary:  map[int64]struct{}{3: {}},
+	})
+	require.Len(t, out, 3)
+	for _, acc := range out {
+		require.NotEqual(t, int64(4), acc.ID)
+		if acc.ID == 3 {
+			require.Equal(t, 0, acc.Priority)
+		}
+	}
+}
+
+func TestAccountRotationService_CRUD(t *testing.T) {
+	repo := &rotationSettingRepoStub{settingRepoStub{values: map[string]string{}}}
+	svc := NewAccountRotationService(repo)
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, &AccountRotationPolicy{Name: "x", Type: AccountRotationTypeSchedule, AccountIDs: []int64{1}, Cron: "0 9 * * *"})
+	require.Error(t, err)
+	require.Equal(t, "INVALID_ROTATION_POLICY", infraerrors.Reason(err))
+
+	created, err := svc.Create(ctx, &AccountRotationPolicy{
+		Name: "personal off-hours", Enabled: true, Type: AccountRotationTypeSchedule,
+		AccountIDs: []int64{1, 1, 0}, Cron: "0 9 * * 1-5", DurationMinutes: 540, Action: "DISABLE",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), created.ID)
+	require.Equal(t, []int64{1}, created.AccountIDs)
+	require.Equal(t, AccountRotationActionDisable, created.Action)
+
+	created.Enabled = false
+	updated, err := svc.Update(ctx, created.ID, created)
+	require.NoError(t, err)
+	require.False(t, updated.Enabled)
+
+	policies, err := svc.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	require.False(t, policies[0].Enabled)
+
+	require.NoError(t, svc.Delete(ctx, created.ID))
+	require.ErrorIs(t, svc.Delete(ctx, created.ID), ErrAccountRotationPolicyNotFound)
+}
@@ -37,10 +37,12 @@ type AccountRepository interface {
 	Delete(ctx context.Context, id int64) error
 
 	List(ctx context.Context, params pagination.PaginationParams) ([]Account, *pagination.PaginationResult, error)
-	ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode string) ([]Account, *pagination.PaginationResult, error)
+	ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode, tag string) ([]Account, *pagination.PaginationResult, error)
 	ListByGroup(ctx context.Context, groupID int64) ([]Account, error)
 	ListActive(ctx context.Context) ([]Account, error)
 	ListByPlatform(ctx context.Context, platform string) ([]Account, error)
+	// ListByOwnerUserID 列出指定用户自助绑定的账号
+	ListByOwnerUserID(ctx context.Context, userID int64) ([]Account, error)
 
 	UpdateLastUsed(ctx context.Context, id int64) error
 	BatchUpdateLastUsed(ctx context.Context, updates map[int64]time.Time) error
@@ -49,6 +51,12 @@ type AccountRepository interface {
 	SetSchedulable(ctx context.Context, id int64, schedulable bool) error
 	AutoPauseExpiredAccounts(ctx context.Context, now time.Time) (int64, error)
 	BindGroups(ctx context.Context, accountID int64, groupIDs []int64) error
+	// SetTags 整体替换账号标签；AddTags/RemoveTags 批量增删，返回变更的 (账号, 标签) 条数
+	SetTags(ctx context.Context, accountID int64, tags []string) error
+	AddTags(ctx context.Context, accountIDs []int64, tags []string) (int64, error)
+	RemoveTags(ctx context.Context, accountIDs []int64, tags []string) (int64, error)
+	// ListTags 列出所有在用标签及其账号数
+	ListTags(ctx context.Context) ([]AccountTagCount, error)
 
 	ListSchedulable(ctx context.Context) ([]Account, error)
 	ListSchedulableByGroupID(ctx context.Context, groupID int64) ([]Account, error)
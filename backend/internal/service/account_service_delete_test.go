@@ -79,7 +79,7 @@ func (s *accountRepoStub) List(ctx context.Context, params pagination.Pagination
 	panic("unexpected List call")
 }
 
-func (s *accountRepoStub) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode string) ([]Account, *pagination.PaginationResult, error) {
+func (s *accountRepoStub) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode, tag string) ([]Account, *pagination.PaginationResult, error) {
 	panic("unexpected ListWithFilters call")
 }
 
@@ -95,6 +95,25 @@ func (s *accountRepoStub) ListByPlatform(ctx context.Context, platform string) (
 	panic("unexpected ListByPlatform call")
 }
 
+func (s *accountRepoStub) ListByOwnerUserID(ctx context.Context, userID int64) ([]Account, error) {
+	panic("unexpected ListByOwnerUserID call")
+}
+func (s *accountRepoStub) SetTags(ctx context.Context, accountID int64, tags []string) error {
+	panic("unexpected SetTags call")
+}
+
+func (s *accountRepoStub) AddTags(ctx context.Context, accountIDs []int64, tags []string) (int64, error) {
+	panic("unexpected AddTags call")
+}
+
+func (s *accountRepoStub) RemoveTags(ctx context.Context, accountIDs []int64, tags []string) (int64, error) {
+	panic("unexpected RemoveTags call")
+}
+
+func (s *accountRepoStub) ListTags(ctx context.Context) ([]AccountTagCount, error) {
+	panic("unexpected ListTags call")
+}
+
 func (s *accountRepoStub) UpdateLastUsed(ctx context.Context, id int64) error {
 	panic("unexpected UpdateLastUsed call")
 }
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	accountTagMaxEntries = 32
+	accountTagMaxLength  = 64
+)
+
+var (
+	// ErrInvalidAccountTags 账号标签或标签选择器非法
+	ErrInvalidAccountTags = infraerrors.BadRequest("INVALID_ACCOUNT_TAGS", "account tags must be 1-64 characters without whitespace, at most 32 per list, and not both added and removed")
+	// ErrAccountTagsNoChange 批量标签操作未指定任何增删
+	ErrAccountTagsNoChange = infraerrors.BadRequest("ACCOUNT_TAGS_NO_CHANGE", "add or remove is required")
+)
+
+// AccountTagCount 标签及使用该标签的账号数
+type AccountTagCount struct {
+	Tag          string `json:"tag"`
+	AccountCount int64  `json:"account_count"`
+}
+
+// BulkUpdateAccountTagsInput 批量增删账号标签；AccountIDs 为空时按 Filters 解析目标账号
+type BulkUpdateAccountTagsInput struct {
+	AccountIDs []int64
+	Filters    *BulkUpdateAccountFilters
+	Add        []string
+	Remove     []string
+}
+
+// BulkUpdateAccountTagsResult 批量标签操作结果：Added/Removed 为实际变更的 (账号, 标签) 条数
+type BulkUpdateAccountTagsResult struct {
+	AccountCount int   `json:"account_count"`
+	Added        int64 `json:"added"`
+	Removed      int64 `json:"removed"`
+}
+
+// NormalizeAccountTags 校验并规范化账号标签：去除首尾空白、转小写、去重，空白项忽略。
+func NormalizeAccountTags(tags []string) ([]string, error) {
+	if len(tags) > accountTagMaxEntries {
+		return nil, ErrInvalidAccountTags
+	}
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if len(tag) > accountTagMaxLength || strings.ContainsAny(tag, " \t\r\n") {
+			return nil, ErrInvalidAccountTags
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	return out, nil
+}
+
+// NormalizeAccountTagSelector 校验并规范化账号标签选择器（规则同 NormalizeAccountTags）。
+func NormalizeAccountTagSelector(sel AccountTagSelector) (AccountTagSelector, error) {
+	require, err := NormalizeAccountTags(sel.Require)
+	if err != nil {
+		return AccountTagSelector{}, err
+	}
+	exclude, err := NormalizeAccountTags(sel.Exclude)
+	if err != nil {
+		return AccountTagSelector{}, err
+	}
+	out := AccountTagSelector{}
+	if len(require) > 0 {
+		out.Require = require
+	}
+	if len(exclude) > 0 {
+		out.Exclude = exclude
+	}
+	return out, nil
+}
+
+// HasTag 账号是否带有指定标签
+func (a *Account) HasTag(tag string) bool {
+	if a == nil {
+		return false
+	}
+	for _, t := range a.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesTagSelector 判断账号是否满足标签选择器：
+// 带有全部 require 标签且不含任一 exclude 标签；
+// 独占账号（TagsExclusive）还要求 require 中至少包含其一个标签，避免被未声明专属容量的请求占用。
+func (a *Account) MatchesTagSelector(sel AccountTagSelector) bool {
+	if a == nil {
+		return false
+	}
+	for _, tag := range sel.Require {
+		if !a.HasTag(tag) {
+			return false
+		}
+	}
+	for _, tag := range sel.Exclude {
+		if a.HasTag(tag) {
+			return false
+		}
+	}
+	if !a.TagsExclusive {
+		return true
+	}
+	for _, tag := range sel.Require {
+		if a.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeAccountTagSelectors 合并分组与 API Key 的选择器：两者的 require/exclude 条件同时生效。
+func mergeAccountTagSelectors(selectors ...AccountTagSelector) AccountTagSelector {
+	var out AccountTagSelector
+	for _, sel := range selectors {
+		out.Require = append(out.Require, sel.Require...)
+		out.Exclude = append(out.Exclude, sel.Exclude...)
+	}
+	return out
+}
+
+// requestAccountTagSelector 返回当前请求生效的账号标签选择器（当前分组 + API Key）。
+func requestAccountTagSelector(ctx context.Context) AccountTagSelector {
+	if ctx == nil {
+		return AccountTagSelector{}
+	}
+	var groupSel, keySel AccountTagSelector
+	if group, ok := ctx.Value(ctxkey.Group).(*Group); ok && group != nil {
+		groupSel = group.AccountTagSelector
+	}
+	if sel, ok := ctx.Value(ctxkey.AccountTagSelector).(AccountTagSelector); ok {
+		keySel = sel
+	}
+	return mergeAccountTagSelectors(groupSel, keySel)
+}
+
+// applyAccountTagSelector 按请求的账号标签选择器过滤调度候选。
+// 选择器为空时仍需剔除独占账号，因此仅在候选中既无选择器也无独占账号时原样返回。
+func applyAccountTagSelector(ctx context.Context, accounts []Account) []Account {
+	sel := requestAccountTagSelector(ctx)
+	if sel.IsEmpty() {
+		hasExclusive := false
+		for i := range accounts {
+			if accounts[i].TagsExclusive {
+				hasExclusive = true
+				break
+			}
+		}
+		if !hasExclusive {
+			return accounts
+		}
+	}
+	filtered := make([]Account, 0, len(accounts))
+	for i := range accounts {
+		if accounts[i].MatchesTagSelector(sel) {
+			filtered = append(filtered, accounts[i])
+		}
+	}
+	return filtered
+}
+
+// restrictAccountTagSelector 按 ID 获取的账号不满足请求的标签选择器时以不可调度的副本返回。
+func restrictAccountTagSelector(ctx context.Context, account *Account) *Account {
+	if account == nil || account.MatchesTagSelector(requestAccountTagSelector(ctx)) {
+		return account
+	}
+	cp := *account
+	cp.Schedulable = false
+	return &cp
+}
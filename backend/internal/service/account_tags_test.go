@@ -0,0 +1,78 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func taggedAccount(id int64, exclusive bool, tags ...string) Account {
+	return Account{ID: id, Schedulable: true, Status: StatusActive, Tags: tags, TagsExclusive: exclusive}
+}
+
+func accountIDs(accounts []Account) []int64 {
+	ids := make([]int64, 0, len(accounts))
+	for _, acc := range accounts {
+		ids = append(ids, acc.ID)
+	}
+	return ids
+}
+
+func TestNormalizeAccountTags(t *testing.T) {
+	tags, err := NormalizeAccountTags([]string{" Dedicated ", "", "dedicated", "eu"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"dedicated", "eu"}, tags)
+
+	_, err = NormalizeAccountTags([]string{"has space"})
+	require.ErrorIs(t, err, ErrInvalidAccountTags)
+
+	sel, err := NormalizeAccountTagSelector(AccountTagSelector{Require: []string{" "}, Exclude: []string{"Slow"}})
+	require.NoError(t, err)
+	require.Nil(t, sel.Require)
+	require.Equal(t, []string{"slow"}, sel.Exclude)
+}
+
+func TestAccountMatchesTagSelector(t *testing.T) {
+	shared := taggedAccount(1, false, "eu")
+	dedicated := taggedAccount(2, true, "dedicated", "eu")
+
+	require.True(t, shared.MatchesTagSelector(AccountTagSelector{}))
+	require.False(t, dedicated.MatchesTagSelector(AccountTagSelector{}), "exclusive accounts need an explicit require")
+	require.False(t, dedicated.MatchesTagSelector(AccountTagSelector{Require: []string{"eu"}, Exclude: []string{"dedicated"}}))
+	require.True(t, dedicated.MatchesTagSelector(AccountTagSelector{Require: []string{"dedicated"}}))
+	require.False(t, shared.MatchesTagSelector(AccountTagSelector{Require: []string{"dedicated"}}))
+	require.False(t, shared.MatchesTagSelector(AccountTagSelector{Exclude: []string{"eu"}}))
+}
+
+func TestApplyAccountTagSelector_MergesGroupAndAPIKey(t *testing.T) {
+	accounts := []Account{
+		taggedAccount(1, false),
+		taggedAccount(2, true, "dedicated"),
+		taggedAccount(3, false, "dedicated", "slow"),
+		taggedAccount(4, true, "tenant-b"),
+	}
+
+	// 无选择器：仅剔除独占账号
+	require.Equal(t, []int64{1, 3}, accountIDs(applyAccountTagSelector(context.Background(), accounts)))
+
+	ctx := context.WithValue(context.Background(), ctxkey.Group, &Group{ID: 1, AccountTagSelector: AccountTagSelector{Require: []string{"dedicated"}}})
+	require.Equal(t, []int64{2, 3}, accountIDs(applyAccountTagSelector(ctx, accounts)))
+
+	ctx = context.WithValue(ctx, ctxkey.AccountTagSelector, AccountTagSelector{Exclude: []string{"slow"}})
+	require.Equal(t, []int64{2}, accountIDs(applyAccountTagSelector(ctx, accounts)))
+}
+
+func TestRestrictAccountTagSelector(t *testing.T) {
+	dedicated := taggedAccount(1, true, "dedicated")
+
+	restricted := restrictAccountTagSelector(context.Background(), &dedicated)
+	require.False(t, restricted.IsSchedulable())
+	require.True(t, dedicated.Schedulable)
+
+	ctx := context.WithValue(context.Background(), ctxkey.AccountTagSelector, AccountTagSelector{Require: []string{"dedicated"}})
+	require.Same(t, &dedicated, restrictAccountTagSelector(ctx, &dedicated))
+}
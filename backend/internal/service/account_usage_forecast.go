@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// UsageWindowForecast 单个配额窗口的耗尽预测
+//
+// 燃烧速率按窗口开始至今的平均使用率计算（百分点/小时），
+// 据此推算在当前速率下配额何时用尽，以及是否会早于窗口重置。
+type UsageWindowForecast struct {
+	Window        string     `json:"window"`
+	WindowSeconds int64      `json:"window_seconds"`
+	Utilization   float64    `json:"utilization"`
+	ResetsAt      *time.Time `json:"resets_at,omitempty"`
+	// BurnRatePerHour 每小时消耗的配额百分点
+	BurnRatePerHour float64 `json:"burn_rate_per_hour"`
+	// ExhaustsAt 按当前速率的预计耗尽时间；速率为 0 时为空
+	ExhaustsAt                  *time.Time `json:"exhausts_at,omitempty"`
+	SecondsToExhaustion         *int64     `json:"seconds_to_exhaustion,omitempty"`
+	WillExhaustBeforeReset      bool       `json:"will_exhaust_before_reset"`
+	Exhausted                   bool       `json:"exhausted"`
+	ProjectedUtilizationAtReset float64    `json:"projected_utilization_at_reset"`
+}
+
+// AccountUsageForecast 账号配额耗尽预测
+type AccountUsageForecast struct {
+	AccountID   int64                 `json:"account_id"`
+	Platform    string                `json:"platform"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	Windows     []UsageWindowForecast `json:"windows"`
+	// EarliestExhaustion 各窗口中最早会在重置前耗尽的时间，便于调度提前轮换账号
+	EarliestExhaustion *time.Time `json:"earliest_exhaustion,omitempty"`
+}
+
+// usageForecastWindow 参与预测的窗口及其固定时长（分钟级 RPM 窗口波动过大，不参与预测）
+type usageForecastWindow struct {
+	name     string
+	duration time.Duration
+	progress func(*UsageInfo) *UsageProgress
+}
+
+var usageForecastWindows = []usageForecastWindow{
+	{"five_hour", 5 * time.Hour, func(u *UsageInfo) *UsageProgress { return u.FiveHour }},
+	{"seven_day", 7 * 24 * time.Hour, func(u *UsageInfo) *UsageProgress { return u.SevenDay }},
+	{"seven_day_sonnet", 7 * 24 * time.Hour, func(u *UsageInfo) *UsageProgress { return u.SevenDaySonnet }},
+	{"gemini_shared_daily", 24 * time.Hour, func(u *UsageInfo) *UsageProgress { return u.GeminiSharedDaily }},
+	{"gemini_pro_daily", 24 * time.Hour, func(u *UsageInfo) *UsageProgress { return u.GeminiProDaily }},
+	{"gemini_flash_daily", 24 * time.Hour, func(u *UsageInfo) *UsageProgress { return u.GeminiFlashDaily }},
+}
+
+// GetUsageForecast 基于账号当前的窗口使用率（与 GetUsage 同源，共享其缓存）预测各配额窗口的耗尽时间
+func (s *AccountUsageService) GetUsageForecast(ctx context.Context, accountID int64) (*AccountUsageForecast, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.GetUsage(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return buildUsageForecast(account, usage, time.Now()), nil
+}
+
+func buildUsageForecast(account *Account, usage *UsageInfo, now time.Time) *AccountUsageForecast {
+	forecast := &AccountUsageForecast{
+		AccountID:   account.ID,
+		Platform:    account.Platform,
+		GeneratedAt: now,
+		Windows:     []UsageWindowForecast{},
+	}
+	if usage == nil {
+		return forecast
+	}
+	for _, w := range usageForecastWindows {
+		progress := w.progress(usage)
+		if progress == nil {
+			continue
+		}
+		item := forecastUsageWindow(w.name, w.duration, progress, now)
+		if item.WillExhaustBeforeReset && item.ExhaustsAt != nil &&
+			(forecast.EarliestExhaustion == nil || item.ExhaustsAt.Before(*forecast.EarliestExhaustion)) {
+			forecast.EarliestExhaustion = item.ExhaustsAt
+		}
+		forecast.Windows = append(forecast.Windows, item)
+	}
+	return forecast
+}
+
+func forecastUsageWindow(name string, duration time.Duration, progress *UsageProgress, now time.Time) UsageWindowForecast {
+	item := UsageWindowForecast{
+		Window:        name,
+		WindowSeconds: int64(duration.Seconds()),
+		Utilization:   progress.Utilization,
+		ResetsAt:      progress.ResetsAt,
+	}
+	if progress.Utilization >= 100 {
+		item.Exhausted = true
+		item.ProjectedUtilizationAtReset = progress.Utilization
+		exhaustsAt := now
+		item.ExhaustsAt = &exhaustsAt
+		zero := int64(0)
+		item.SecondsToExhaustion = &zero
+		item.WillExhaustBeforeReset = true
+		return item
+	}
+
+	// 窗口已过去的时长：无重置时间时无法推算
+	if progress.ResetsAt == nil {
+		item.ProjectedUtilizationAtReset = progress.Utilization
+		return item
+	}
+	remaining := progress.ResetsAt.Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	elapsed := duration - remaining
+	if elapsed <= 0 || progress.Utilization <= 0 {
+		item.ProjectedUtilizationAtReset = progress.Utilization
+		return item
+	}
+
+	ratePerSecond := progress.Utilization / elapsed.Seconds()
+	item.BurnRatePerHour = ratePerSecond * 3600
+	item.ProjectedUtilizationAtReset = progress.Utilization + ratePerSecond*remaining.Seconds()
+
+	secondsLeft := int64((100 - progress.Utilization) / ratePerSecond)
+	exhaustsAt := now.Add(time.Duration(secondsLeft) * time.Second)
+	item.ExhaustsAt = &exhaustsAt
+	item.SecondsToExhaustion = &secondsLeft
+	item.WillExhaustBeforeReset = exhaustsAt.Before(*progress.ResetsAt)
+	return item
+}
@@ -0,0 +1,49 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildUsageForecast(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	fiveHourReset := now.Add(3 * time.Hour)      // 已过去 2h
+	sevenDayReset := now.Add(6 * 24 * time.Hour) // 已过去 1d
+	usage := &UsageInfo{
+		FiveHour: &UsageProgress{Utilization: 80, ResetsAt: &fiveHourReset},
+		SevenDay: &UsageProgress{Utilization: 10, ResetsAt: &sevenDayReset},
+	}
+
+	forecast := buildUsageForecast(&Account{ID: 7, Platform: PlatformAnthropic}, usage, now)
+	require.Len(t, forecast.Windows, 2)
+
+	fiveHour := forecast.Windows[0]
+	require.Equal(t, "five_hour", fiveHour.Window)
+	require.InDelta(t, 40, fiveHour.BurnRatePerHour, 0.001)
+	require.True(t, fiveHour.WillExhaustBeforeReset)
+	require.Equal(t, int64(30*60), *fiveHour.SecondsToExhaustion)
+	require.Equal(t, now.Add(30*time.Minute), *fiveHour.ExhaustsAt)
+
+	sevenDay := forecast.Windows[1]
+	require.False(t, sevenDay.WillExhaustBeforeReset)
+	require.InDelta(t, 70, sevenDay.ProjectedUtilizationAtReset, 0.001)
+
+	require.NotNil(t, forecast.EarliestExhaustion)
+	require.Equal(t, now.Add(30*time.Minute), *forecast.EarliestExhaustion)
+}
+
+func TestForecastUsageWindow_EdgeCases(t *testing.T) {
+	now := time.Now()
+	item := forecastUsageWindow("five_hour", 5*time.Hour, &UsageProgress{Utilization: 100}, now)
+	require.True(t, item.Exhausted)
+	require.Equal(t, int64(0), *item.SecondsToExhaustion)
+
+	reset := now.Add(time.Hour)
+	item = forecastUsageWindow("five_hour", 5*time.Hour, &UsageProgress{Utilization: 0, ResetsAt: &reset}, now)
+	require.Nil(t, item.ExhaustsAt)
+	require.False(t, item.WillExhaustBeforeReset)
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/geminicli"
+)
+
+const (
+	verifyClaudeUsageURL   = "https://api.anthropic.com/api/oauth/usage"
+	verifyGoogleTokenInfo  = "https://oauth2.googleapis.com/tokeninfo"
+	accountVerifyTimeout   = 20 * time.Second
+	accountVerifyBodyLimit = 64 << 10
+)
+
+var ErrAccountVerifyUnsupported = infraerrors.BadRequest("ACCOUNT_VERIFY_UNSUPPORTED", "credential verification is not supported for this account type")
+
+// AccountVerifyResult 账号凭证校验结果
+type AccountVerifyResult struct {
+	AccountID int64  `json:"account_id"`
+	Platform  string `json:"platform"`
+	Type      string `json:"type"`
+	// Valid 凭证是否可用
+	Valid bool `json:"valid"`
+	// Conclusive 为 false 表示校验因网络/上游故障未能得出结论（不会修改账号状态）
+	Conclusive bool   `json:"conclusive"`
+	Method     string `json:"method"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Message    string `json:"message,omitempty"`
+	// ExpiresAt 访问令牌过期时间（OAuth 账号）
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	Plan      string     `json:"plan,omitempty"`
+	// AccountStatus 校验并应用状态变更后的账号状态
+	AccountStatus string    `json:"account_status"`
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// verifyProbe 一次平台凭证校验请求
+type verifyProbe struct {
+	method string
+	url    string
+	header http.Header
+	// parse 解析成功响应体，补充过期时间/权限范围等信息
+	parse func(body []byte, result *AccountVerifyResult)
+}
+
+// VerifyAccountCredentials 按平台发起轻量请求校验账号凭证：
+// Anthropic OAuth 查询用量端点，API Key 账号列出模型，OpenAI OAuth 查询 ChatGPT 账号信息，
+// Gemini/Antigravity OAuth 获取（必要时刷新）访问令牌后查询 Google tokeninfo。
+func (s *AccountTestService) VerifyAccountCredentials(ctx context.Context, accountID int64) (*AccountVerifyResult, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AccountVerifyResult{
+		AccountID:     account.ID,
+		Platform:      account.Platform,
+		Type:          account.Type,
+		Plan:          accountPlanHint(account),
+		Scopes:        strings.Fields(account.GetCredential("scope")),
+		ExpiresAt:     account.GetCredentialAsTime("expires_at"),
+		AccountStatus: account.Status,
+		CheckedAt:     time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, accountVerifyTimeout)
+	defer cancel()
+
+	probe, err := s.buildVerifyProbe(ctx, account, result)
+	if err != nil {
+		return nil, err
+	}
+	if probe == nil {
+		return result, nil
+	}
+	result.Method = probe.method
+	s.runVerifyProbe(ctx, account, probe, result)
+	return result, nil
+}
+
+// buildVerifyProbe 构造校验请求。获取/刷新访问令牌失败时无法区分凭证失效还是网络问题，
+// 只记录到 result.Message 并返回 nil probe，不修改账号状态。
+func (s *AccountTestService) buildVerifyProbe(ctx context.Context, account *Account, result *AccountVerifyResult) (*verifyProbe, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+
+	switch {
+	case account.Platform == PlatformAnthropic && account.IsOAuth():
+		if s.claudeTokenProvider == nil {
+			return nil, ErrAccountVerifyUnsupported
+		}
+		token, err := s.claudeTokenProvider.GetAccessToken(ctx, account)
+		if err != nil {
+			result.Message = fmt.Sprintf("get access token: %v", err)
+			return nil, nil
+		}
+		header.Set("Authorization", "Bearer "+token)
+		header.Set("anthropic-beta", "oauth-2025-04-20")
+		return &verifyProbe{method: "anthropic_usage", url: verifyClaudeUsageURL, header: header}, nil
+
+	case account.Platform == PlatformAnthropic && account.Type == AccountTypeAPIKey:
+		baseURL, err := s.validateUpstreamBaseURL(account.GetBaseURL())
+		if err != nil {
+			return nil, infraerrors.BadRequest("INVALID_BASE_URL", err.Error())
+		}
+		header.Set("x-api-key", account.GetCredential("api_key"))
+		header.Set("anthropic-version", "2023-06-01")
+		return &verifyProbe{method: "anthropic_models", url: strings.TrimSuffix(baseURL, "/") + "/v1/models", header: header}, nil
+
+	case account.IsOpenAIApiKey():
+		baseURL, err := s.validateUpstreamBaseURL(account.GetOpenAIBaseURL())
+		if err != nil {
+			return nil, infraerrors.BadRequest("INVALID_BASE_URL", err.Error())
+		}
+		header.Set("Authorization", "Bearer "+account.GetCredential("api_key"))
+		return &verifyProbe{method: "openai_models", url: strings.TrimSuffix(baseURL, "/") + "/v1/models", header: header}, nil
+
+	case account.IsOpenAIOAuth():
+		token := account.GetOpenAIAccessToken()
+		if s.openAITokenProvider != nil {
+			t, err := s.openAITokenProvider.GetAccessToken(ctx, account)
+			if err != nil {
+				result.Message = fmt.Sprintf("get access token: %v", err)
+				return nil, nil
+			}
+			token = t
+		}
+		header.Set("Authorization", "Bearer "+token)
+		header.Set("Origin", "https://chatgpt.com")
+		header.Set("Referer", "https://chatgpt.com/")
+		return &verifyProbe{method: "chatgpt_account_check", url: chatGPTAccountsCheckURL, header: header, parse: parseChatGPTVerifyBody}, nil
+
+	case account.Platform == PlatformGemini && account.Type == AccountTypeAPIKey:
+		baseURL, err := s.validateUpstreamBaseURL(account.GetGeminiBaseURL(geminicli.AIStudioBaseURL))
+		if err != nil {
+			return nil, infraerrors.BadRequest("INVALID_BASE_URL", err.Error())
+		}
+		header.Set("x-goog-api-key", account.GetCredential("api_key"))
+		return &verifyProbe{method: "gemini_models", url: strings.TrimSuffix(baseURL, "/") + "/v1beta/models?pageSize=1", header: header}, nil
+
+	case account.Platform == PlatformGemini && account.Type == AccountTypeOAuth:
+		if s.geminiTokenProvider == nil {
+			return nil, ErrAccountVerifyUnsupported
+		}
+		token, err := s.geminiTokenProvider.GetAccessToken(ctx, account)
+		if err != nil {
+			result.Message = fmt.Sprintf("get access token: %v", err)
+			return nil, nil
+		}
+		return newGoogleTokenInfoProbe(token), nil
+
+	case account.Platform == PlatformAntigravity && account.Type == AccountTypeOAuth:
+		if s.antigravityGatewayService == nil || s.antigravityGatewayService.GetTokenProvider() == nil {
+			return nil, ErrAccountVerifyUnsupported
+		}
+		token, err := s.antigravityGatewayService.GetTokenProvider().GetAccessToken(ctx, account)
+		if err != nil {
+			result.Message = fmt.Sprintf("get access token: %v", err)
+			return nil, nil
+		}
+		return newGoogleTokenInfoProbe(token), nil
+	}
+	return nil, ErrAccountVerifyUnsupported
+}
+
+func newGoogleTokenInfoProbe(accessToken string) *verifyProbe {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	return &verifyProbe{
+		method: "google_tokeninfo",
+		url:    verifyGoogleTokenInfo + "?access_token=" + url.QueryEscape(accessToken),
+		header: header,
+		parse:  parseGoogleTokenInfoBody,
+	}
+}
+
+func (s *AccountTestService) runVerifyProbe(ctx context.Context, account *Account, probe *verifyProbe, result *AccountVerifyResult) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.url, nil)
+	if err != nil {
+		result.Message = fmt.Sprintf("create request: %v", err)
+		return
+	}
+	req.Header = probe.header
+
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.httpUpstream.DoWithTLS(req, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+	if err != nil {
+		result.Message = fmt.Sprintf("request failed: %v", err)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, accountVerifyBodyLimit))
+
+	result.StatusCode = resp.StatusCode
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		result.Valid = true
+		result.Conclusive = true
+		result.Message = "Credentials are valid"
+		if probe.parse != nil {
+			probe.parse(body, result)
+		}
+	case isCredentialRejectedStatus(probe.method, resp.StatusCode):
+		result.Conclusive = true
+		result.Message = fmt.Sprintf("Credentials rejected by upstream (HTTP %d): %s", resp.StatusCode, truncate(strings.TrimSpace(string(body)), 200))
+	default:
+		result.Message = fmt.Sprintf("Upstream returned HTTP %d: %s", resp.StatusCode, truncate(strings.TrimSpace(string(body)), 200))
+	}
+}
+
+// isCredentialRejectedStatus 仅认证类状态码视为凭证失效；Google tokeninfo 对无效令牌返回 400
+func isCredentialRejectedStatus(method string, status int) bool {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return true
+	}
+	return method == "google_tokeninfo" && status == http.StatusBadRequest
+}
+
+func parseGoogleTokenInfoBody(body []byte, result *AccountVerifyResult) {
+	var info struct {
+		Scope     string `json:"scope"`
+		ExpiresIn string `json:"expires_in"`
+		Exp       string `json:"exp"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return
+	}
+	if scopes := strings.Fields(info.Scope); len(scopes) > 0 {
+		result.Scopes = scopes
+	}
+	if exp, err := strconv.ParseInt(info.Exp, 10, 64); err == nil && exp > 0 {
+		t := time.Unix(exp, 0)
+		result.ExpiresAt = &t
+	} else if sec, err := strconv.ParseInt(info.ExpiresIn, 10, 64); err == nil && sec > 0 {
+		t := result.CheckedAt.Add(time.Duration(sec) * time.Second)
+		result.ExpiresAt = &t
+	}
+}
+
+func parseChatGPTVerifyBody(body []byte, result *AccountVerifyResult) {
+	var payload struct {
+		Accounts map[string]map[string]any `json:"accounts"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return
+	}
+	for _, acct := range payload.Accounts {
+		if planType := extractPlanType(acct); planType != "" {
+			result.Plan = planType
+			if account, ok := acct["account"].(map[string]any); ok {
+				if isDefault, _ := account["is_default"].(bool); isDefault {
+					return
+				}
+			}
+		}
+	}
+}
+
+// accountPlanHint 返回凭证中已记录的订阅/层级信息
+func accountPlanHint(account *Account) string {
+	for _, key := range []string{"plan_type", "tier_id", "subscription_type"} {
+		if v := strings.TrimSpace(account.GetCredential(key)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccountVerifyTestService(account *Account, responses ...*http.Response) (*AccountTestService, *queuedHTTPUpstream) {
+	upstream := &queuedHTTPUpstream{responses: responses}
+	repo := &mockAccountRepoForGemini{accountsByID: map[int64]*Account{account.ID: account}}
+	return &AccountTestService{accountRepo: repo, httpUpstream: upstream, cfg: &config.Config{}}, upstream
+}
+
+func TestVerifyAccountCredentials_OpenAIAPIKey(t *testing.T) {
+	account := &Account{ID: 1, Platform: PlatformOpenAI, Type: AccountTypeAPIKey, Status: StatusActive,
+		Credentials: map[string]any{"api_key": "sk-test", "base_url": "https://api.example.com/"}}
+	svc, upstream := newAccountVerifyTestService(account, newJSONResponse(http.StatusOK, `{"data":[]}`))
+
+	result, err := svc.VerifyAccountCredentials(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, result.Valid)
+	require.True(t, result.Conclusive)
+	require.Equal(t, "openai_models", result.Method)
+	require.Len(t, upstream.requests, 1)
+	require.Equal(t, "https://api.example.com/v1/models", upstream.requests[0].URL.String())
+	require.Equal(t, "Bearer sk-test", upstream.requests[0].Header.Get("Authorization"))
+}
+
+func TestVerifyAccountCredentials_RejectedAndInconclusive(t *testing.T) {
+	account := &Account{ID: 2, Platform: PlatformAnthropic, Type: AccountTypeAPIKey, Status: StatusActive,
+		Credentials: map[string]any{"api_key": "bad"}}
+
+	svc, _ := newAccountVerifyTestService(account, newJSONResponse(http.StatusUnauthorized, `{"error":"invalid x-api-key"}`))
+	result, err := svc.VerifyAccountCredentials(context.Background(), 2)
+	require.NoError(t, err)
+	require.False(t, result.Valid)
+	require.True(t, result.Conclusive)
+	require.Equal(t, http.StatusUnauthorized, result.StatusCode)
+
+	svc, _ = newAccountVerifyTestService(account, newJSONResponse(http.StatusServiceUnavailable, `overloaded`))
+	result, err = svc.VerifyAccountCredentials(context.Background(), 2)
+	require.NoError(t, err)
+	require.False(t, result.Valid)
+	require.False(t, result.Conclusive)
+}
+
+func TestVerifyAccountCredentials_Unsupported(t *testing.T) {
+	account := &Account{ID: 3, Platform: PlatformAnthropic, Type: AccountTypeBedrock}
+	svc, _ := newAccountVerifyTestService(account)
+	_, err := svc.VerifyAccountCredentials(context.Background(), 3)
+	require.ErrorIs(t, err, ErrAccountVerifyUnsupported)
+}
+
+func TestParseGoogleTokenInfoBody(t *testing.T) {
+	result := &AccountVerifyResult{CheckedAt: time.Unix(1000, 0)}
+	parseGoogleTokenInfoBody([]byte(`{"scope":"a b","exp":"2000"}`), result)
+	require.Equal(t, []string{"a", "b"}, result.Scopes)
+	require.Equal(t, int64(2000), result.ExpiresAt.Unix())
+
+	result = &AccountVerifyResult{CheckedAt: time.Unix(1000, 0)}
+	parseGoogleTokenInfoBody([]byte(`{"expires_in":"60"}`), result)
+	require.Equal(t, int64(1060), result.ExpiresAt.Unix())
+}
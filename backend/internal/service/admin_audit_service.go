@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+// adminAuditRedacted 审计记录中敏感字段的替换值
+const adminAuditRedacted = "***"
+
+// adminAuditMaxRedactDepth 限制脱敏递归深度
+const adminAuditMaxRedactDepth = 32
+
+// AdminAuditLog 管理员写操作审计记录（只追加，不可修改）
+type AdminAuditLog struct {
+	ID          int64
+	ActorUserID int64
+	ActorEmail  string
+	AuthMethod  string
+	Method      string
+	Path        string
+	Resource    string
+	ResourceID  *int64
+	StatusCode  int
+	IP          string
+	UserAgent   string
+	RequestBody json.RawMessage
+	Before      json.RawMessage
+	After       json.RawMessage
+	Diff        json.RawMessage
+	CreatedAt   time.Time
+}
+
+// AdminAuditLogFilters 审计日志查询条件
+type AdminAuditLogFilters struct {
+	ActorUserID *int64
+	Resource    string
+	ResourceID  *int64
+	Method      string
+	StartTime   *time.Time
+	EndTime     *time.Time
+}
+
+// AdminAuditRepository 审计日志存储；实现必须保证只追加
+type AdminAuditRepository interface {
+	Insert(ctx context.Context, entry *AdminAuditLog) error
+	List(ctx context.Context, params pagination.PaginationParams, filters AdminAuditLogFilters) ([]AdminAuditLog, *pagination.PaginationResult, error)
+}
+
+// AdminAuditSnapshotLoader 按 ID 加载资源当前状态，用于生成变更前后快照
+type AdminAuditSnapshotLoader func(ctx context.Context, id int64) (any, error)
+
+type adminAuditSnapshotSource struct {
+	load      AdminAuditSnapshotLoader
+	singleton bool
+}
+
+// AdminAuditRecord 中间件采集的一次写操作；Before/After 为未脱敏的快照，由 Record 统一脱敏与计算差异
+type AdminAuditRecord struct {
+	ActorUserID int64
+	AuthMethod  string
+	Method      string
+	Path        string
+	Resource    string
+	ResourceID  *int64
+	StatusCode  int
+	IP          string
+	UserAgent   string
+	RequestBody []byte
+	Before      any
+	After       any
+}
+
+// AdminAuditService 管理员操作审计
+type AdminAuditService struct {
+	repo     AdminAuditRepository
+	userRepo UserRepository
+	sources  map[string]adminAuditSnapshotSource
+}
+
+// NewAdminAuditService 创建审计服务；快照加载器通过 RegisterSnapshotLoader 注册
+func NewAdminAuditService(repo AdminAuditRepository, userRepo UserRepository) *AdminAuditService {
+	return &AdminAuditService{
+		repo:     repo,
+		userRepo: userRepo,
+		sources:  make(map[string]adminAuditSnapshotSource),
+	}
+}
+
+// RegisterSnapshotLoader 为按 ID 寻址的资源（如 accounts/:id）注册快照加载器。
+// 仅在启动装配阶段调用，运行期只读。
+func (s *AdminAuditService) RegisterSnapshotLoader(resource string, load AdminAuditSnapshotLoader) {
+	s.sources[resource] = adminAuditSnapshotSource{load: load}
+}
+
+// RegisterSingletonSnapshotLoader 为无 ID 的单例资源（如系统设置）注册快照加载器
+func (s *AdminAuditService) RegisterSingletonSnapshotLoader(resource string, load func(ctx context.Context) (any, error)) {
+	s.sources[resource] = adminAuditSnapshotSource{
+		load:      func(ctx context.Context, _ int64) (any, error) { return load(ctx) },
+		singleton: true,
+	}
+}
+
+// HasSnapshotLoader 资源是否支持快照（单例资源忽略 id）
+func (s *AdminAuditService) HasSnapshotLoader(resource string, id int64) bool {
+	src, ok := s.sources[resource]
+	return ok && (src.singleton || id > 0)
+}
+
+// Snapshot 加载资源当前状态；不支持或加载失败时返回 nil（审计不应阻断管理操作）
+func (s *AdminAuditService) Snapshot(ctx context.Context, resource string, id int64) any {
+	if !s.HasSnapshotLoader(resource, id) {
+		return nil
+	}
+	v, err := s.sources[resource].load(ctx, id)
+	if err != nil || v == nil {
+		return nil
+	}
+	return v
+}
+
+// Record 脱敏快照与请求体、计算字段级差异并写入审计表
+func (s *AdminAuditService) Record(ctx context.Context, rec *AdminAuditRecord) error {
+	before := adminAuditNormalize(rec.Before)
+	after := adminAuditNormalize(rec.After)
+
+	entry := &AdminAuditLog{
+		ActorUserID: rec.ActorUserID,
+		AuthMethod:  rec.AuthMethod,
+		Method:      rec.Method,
+		Path:        rec.Path,
+		Resource:    rec.Resource,
+		ResourceID:  rec.ResourceID,
+		StatusCode:  rec.StatusCode,
+		IP:          rec.IP,
+		UserAgent:   rec.UserAgent,
+		Before:      adminAuditMarshal(redactAdminAuditValue(before, 0)),
+		After:       adminAuditMarshal(redactAdminAuditValue(after, 0)),
+		Diff:        adminAuditMarshal(adminAuditDiff(before, after)),
+	}
+	if len(rec.RequestBody) > 0 {
+		var body any
+		if err := json.Unmarshal(rec.RequestBody, &body); err == nil {
+			entry.RequestBody = adminAuditMarshal(redactAdminAuditValue(body, 0))
+		}
+	}
+	if s.userRepo != nil && rec.ActorUserID > 0 {
+		if actor, err := s.userRepo.GetByID(ctx, rec.ActorUserID); err == nil && actor != nil {
+			entry.ActorEmail = actor.Email
+		}
+	}
+	return s.repo.Insert(ctx, entry)
+}
+
+// List 分页查询审计日志（按时间倒序）
+func (s *AdminAuditService) List(ctx context.Context, page, pageSize int, filters AdminAuditLogFilters) ([]AdminAuditLog, int64, error) {
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
+	logs, result, err := s.repo.List(ctx, params, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, result.Total, nil
+}
+
+// adminAuditNormalize 将任意快照转换为 JSON 通用结构（map/slice/标量），便于比较与脱敏
+func adminAuditNormalize(v any) any {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// adminAuditDiff 计算顶层字段差异：{"field": {"before": x, "after": y}}。
+// 在脱敏前比较，敏感字段变化时仍会出现在差异中，但值被替换为 ***。
+func adminAuditDiff(before, after any) map[string]any {
+	b, _ := before.(map[string]any)
+	a, _ := after.(map[string]any)
+	if b == nil && a == nil {
+		return nil
+	}
+	diff := make(map[string]any)
+	for k, bv := range b {
+		av, ok := a[k]
+		if ok && reflect.DeepEqual(bv, av) {
+			continue
+		}
+		diff[k] = adminAuditDiffEntry(k, bv, av)
+	}
+	for k, av := range a {
+		if _, ok := b[k]; ok {
+			continue
+		}
+		diff[k] = adminAuditDiffEntry(k, nil, av)
+	}
+	if len(diff) == 0 {
+		return nil
+	}
+	return diff
+}
+
+func adminAuditDiffEntry(key string, before, after any) map[string]any {
+	if isAdminAuditSensitiveKey(key) {
+		return map[string]any{"before": adminAuditRedactedOrNil(before), "after": adminAuditRedactedOrNil(after)}
+	}
+	return map[string]any{"before": redactAdminAuditValue(before, 0), "after": redactAdminAuditValue(after, 0)}
+}
+
+func adminAuditRedactedOrNil(v any) any {
+	if v == nil {
+		return nil
+	}
+	return adminAuditRedacted
+}
+
+func redactAdminAuditValue(v any, depth int) any {
+	if depth > adminAuditMaxRedactDepth {
+		return adminAuditRedacted
+	}
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			if isAdminAuditSensitiveKey(k) {
+				out[k] = adminAuditRedactedOrNil(val)
+				continue
+			}
+			out[k] = redactAdminAuditValue(val, depth+1)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = redactAdminAuditValue(val, depth+1)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// isAdminAuditSensitiveKey 判断字段是否包含密钥类数据。
+// 快照来自服务层结构体（驼峰字段名），请求体为蛇形命名，统一去掉下划线后按小写匹配。
+func isAdminAuditSensitiveKey(key string) bool {
+	k := strings.ToLower(strings.ReplaceAll(key, "_", ""))
+	switch k {
+	case "key", "credentials", "code":
+		return true
+	}
+	for _, suffix := range []string{"password", "passwordhash", "apikey", "token", "privatekey", "accesskey", "encrypted"} {
+		if strings.HasSuffix(k, suffix) {
+			return true
+		}
+	}
+	return strings.Contains(k, "secret")
+}
+
+func adminAuditMarshal(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	if m, ok := v.(map[string]any); ok && len(m) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
@@ -0,0 +1,84 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type adminAuditRepoStub struct {
+	AdminAuditRepository
+	inserted []*AdminAuditLog
+}
+
+func (s *adminAuditRepoStub) Insert(_ context.Context, entry *AdminAuditLog) error {
+	s.inserted = append(s.inserted, entry)
+	return nil
+}
+
+func TestAdminAuditService_RecordDiffAndRedaction(t *testing.T) {
+	repo := &adminAuditRepoStub{}
+	users := &orgUserRepoStub{users: map[int64]*User{1: {ID: 1, Email: "admin@example.com"}}}
+	svc := NewAdminAuditService(repo, users)
+
+	before := &Account{ID: 7, Name: "old", Status: StatusActive, Credentials: map[string]any{"api_key": "sk-old"}}
+	after := &Account{ID: 7, Name: "new", Status: StatusActive, Credentials: map[string]any{"api_key": "sk-new"}}
+	id := int64(7)
+	err := svc.Record(context.Background(), &AdminAuditRecord{
+		ActorUserID: 1,
+		Method:      "PUT",
+		Path:        "/api/v1/admin/accounts/:id",
+		Resource:    "accounts",
+		ResourceID:  &id,
+		StatusCode:  200,
+		RequestBody: []byte(`{"name":"new","credentials":{"api_key":"sk-new"},"proxy_password":"p"}`),
+		Before:      before,
+		After:       after,
+	})
+	require.NoError(t, err)
+	require.Len(t, repo.inserted, 1)
+	entry := repo.inserted[0]
+	require.Equal(t, "admin@example.com", entry.ActorEmail)
+
+	var diff map[string]map[string]any
+	require.NoError(t, json.Unmarshal(entry.Diff, &diff))
+	require.Equal(t, map[string]any{"before": "old", "after": "new"}, diff["Name"])
+	require.Equal(t, map[string]any{"before": "***", "after": "***"}, diff["Credentials"])
+	require.NotContains(t, diff, "Status")
+
+	for _, raw := range []json.RawMessage{entry.Before, entry.After, entry.RequestBody} {
+		require.NotContains(t, string(raw), "sk-old")
+		require.NotContains(t, string(raw), "sk-new")
+	}
+	require.Contains(t, string(entry.RequestBody), `"proxy_password":"***"`)
+}
+
+func TestAdminAuditService_SnapshotLoaders(t *testing.T) {
+	svc := NewAdminAuditService(&adminAuditRepoStub{}, nil)
+	svc.RegisterSnapshotLoader("groups", func(_ context.Context, id int64) (any, error) {
+		return &Group{ID: id}, nil
+	})
+	svc.RegisterSingletonSnapshotLoader("settings", func(context.Context) (any, error) {
+		return &SystemSettings{}, nil
+	})
+
+	require.True(t, svc.HasSnapshotLoader("groups", 3))
+	require.False(t, svc.HasSnapshotLoader("groups", 0))
+	require.True(t, svc.HasSnapshotLoader("settings", 0))
+	require.False(t, svc.HasSnapshotLoader("proxies", 3))
+	require.Nil(t, svc.Snapshot(context.Background(), "proxies", 3))
+	require.Equal(t, int64(3), svc.Snapshot(context.Background(), "groups", 3).(*Group).ID)
+}
+
+func TestIsAdminAuditSensitiveKey(t *testing.T) {
+	for _, key := range []string{"Credentials", "api_key", "Key", "PasswordHash", "smtp_password", "TurnstileSecretKey", "refresh_token", "TotpSecretEncrypted"} {
+		require.True(t, isAdminAuditSensitiveKey(key), key)
+	}
+	for _, key := range []string{"Name", "MaxTokens", "Status", "TokenVersion", "RateMultiplier"} {
+		require.False(t, isAdminAuditSensitiveKey(key), key)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -42,7 +43,7 @@ type AdminService interface {
 	BindUserAuthIdentity(ctx context.Context, userID int64, input AdminBindAuthIdentityInput) (*AdminBoundAuthIdentity, error)
 
 	// Group management
-	ListGroups(ctx context.Context, page, pageSize int, platform, status, search string, isExclusive *bool, sortBy, sortOrder string) ([]Group, int64, error)
+	ListGroups(ctx context.Context, page, pageSize int, platform, status, search string, isExclusive *bool, organizationID *int64, sortBy, sortOrder string) ([]Group, int64, error)
 	GetAllGroups(ctx context.Context) ([]Group, error)
 	GetAllGroupsByPlatform(ctx context.Context, platform string) ([]Group, error)
 	GetGroup(ctx context.Context, id int64) (*Group, error)
@@ -60,12 +61,20 @@ type AdminService interface {
 	// API Key management (admin)
 	AdminUpdateAPIKeyGroupID(ctx context.Context, keyID int64, groupID *int64) (*AdminUpdateAPIKeyGroupIDResult, error)
 	AdminResetAPIKeyRateLimitUsage(ctx context.Context, keyID int64) (*APIKey, error)
+	AdminUpdateAPIKeyPriority(ctx context.Context, keyID int64, priority string) (*APIKey, error)
+	AdminUpdateAPIKeyModerationMode(ctx context.Context, keyID int64, mode string) (*APIKey, error)
+	AdminUpdateAPIKeyContextAutoTrim(ctx context.Context, keyID int64, enabled bool) (*APIKey, error)
+	AdminUpdateAPIKeyMaxBodySize(ctx context.Context, keyID int64, maxBodySize int64) (*APIKey, error)
+	AdminUpdateAPIKeyRequestCoalescing(ctx context.Context, keyID int64, enabled bool) (*APIKey, error)
+	AdminUpdateAPIKeyTokenBucket(ctx context.Context, keyID int64, burst *int, refillRate *float64) (*APIKey, error)
+	AdminUpdateAPIKeyMaxCostPerRequest(ctx context.Context, keyID int64, maxCost float64) (*APIKey, error)
+	AdminUpdateAPIKeyAccountTagSelector(ctx context.Context, keyID int64, sel AccountTagSelector) (*APIKey, error)
 
 	// ReplaceUserGroup 替换用户的专属分组：授予新分组权限、迁移 Key、移除旧分组权限
 	ReplaceUserGroup(ctx context.Context, userID, oldGroupID, newGroupID int64) (*ReplaceUserGroupResult, error)
 
 	// Account management
-	ListAccounts(ctx context.Context, page, pageSize int, platform, accountType, status, search string, groupID int64, privacyMode string, sortBy, sortOrder string) ([]Account, int64, error)
+	ListAccounts(ctx context.Context, page, pageSize int, platform, accountType, status, search string, groupID int64, privacyMode, tag string, sortBy, sortOrder string) ([]Account, int64, error)
 	GetAccount(ctx context.Context, id int64) (*Account, error)
 	GetAccountsByIDs(ctx context.Context, ids []int64) ([]*Account, error)
 	CreateAccount(ctx context.Context, input *CreateAccountInput) (*Account, error)
@@ -84,6 +93,9 @@ type AdminService interface {
 	ForceAntigravityPrivacy(ctx context.Context, account *Account) string
 	SetAccountSchedulable(ctx context.Context, id int64, schedulable bool) (*Account, error)
 	BulkUpdateAccounts(ctx context.Context, input *BulkUpdateAccountsInput) (*BulkUpdateAccountsResult, error)
+	// BulkUpdateAccountTags 批量为账号增删标签；ListAccountTags 列出在用标签及账号数
+	BulkUpdateAccountTags(ctx context.Context, input *BulkUpdateAccountTagsInput) (*BulkUpdateAccountTagsResult, error)
+	ListAccountTags(ctx context.Context) ([]AccountTagCount, error)
 	CheckMixedChannelRisk(ctx context.Context, currentAccountID int64, currentAccountPlatform string, groupIDs []int64) error
 
 	// Proxy management
@@ -209,6 +221,23 @@ type CreateGroupInput struct {
 	MessagesDispatchModelConfig OpenAIMessagesDispatchModelConfig
 	// RPMLimit 分组 RPM 上限（0 = 不限制）
 	RPMLimit int
+	// Priority 并发等待队列优先级（high/normal/low），为空默认 normal
+	Priority string
+	// RequestParamOverrides 请求参数策略（默认/强制参数与 max_tokens 上限）
+	RequestParamOverrides RequestParamOverrides
+	// SystemPrompt 分组系统提示词；SystemPromptMode 注入位置（prepend/append），为空默认 prepend
+	SystemPrompt     string
+	SystemPromptMode string
+	// PIIRedactionMode 敏感信息过滤策略（空/mask/reject）
+	PIIRedactionMode string
+	// ModerationEnabled 转发前是否执行内容审核
+	ModerationEnabled bool
+	// MaxBodySize 请求体最大字节数，0 表示使用端点类别默认值
+	MaxBodySize int64
+	// ResponseHeaders 自定义响应头（静态响应头与额外透传的上游响应头）
+	ResponseHeaders GroupResponseHeaders
+	// AccountTagSelector 账号标签选择器（require 全部命中、exclude 均不命中）
+	AccountTagSelector AccountTagSelector
 	// 从指定分组复制账号（创建分组后在同一事务内绑定）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -246,6 +275,23 @@ type UpdateGroupInput struct {
 	MessagesDispatchModelConfig *OpenAIMessagesDispatchModelConfig
 	// RPMLimit 分组 RPM 上限（0 = 不限制），nil 表示未提供不改动。
 	RPMLimit *int
+	// Priority 并发等待队列优先级（high/normal/low），nil 表示未提供不改动。
+	Priority *string
+	// RequestParamOverrides 请求参数策略，nil 表示未提供不改动。
+	RequestParamOverrides *RequestParamOverrides
+	// SystemPrompt / SystemPromptMode 分组系统提示词及注入位置，nil 表示未提供不改动。
+	SystemPrompt     *string
+	SystemPromptMode *string
+	// PIIRedactionMode 敏感信息过滤策略，nil 表示未提供不改动，空字符串表示关闭。
+	PIIRedactionMode *string
+	// ModerationEnabled 是否执行内容审核，nil 表示未提供不改动。
+	ModerationEnabled *bool
+	// MaxBodySize 请求体最大字节数，nil 表示未提供不改动，0 表示使用端点类别默认值。
+	MaxBodySize *int64
+	// ResponseHeaders 自定义响应头，nil 表示未提供不改动。
+	ResponseHeaders *GroupResponseHeaders
+	// AccountTagSelector 账号标签选择器，nil 表示未提供不改动。
+	AccountTagSelector *AccountTagSelector
 	// 从指定分组复制账号（同步操作：先清空当前分组的账号绑定，再绑定源分组的账号）
 	CopyAccountsFromGroupIDs []int64
 }
@@ -265,6 +311,9 @@ type CreateAccountInput struct {
 	GroupIDs           []int64
 	ExpiresAt          *int64
 	AutoPauseOnExpired *bool
+	// Tags 账号标签；TagsExclusive 为 true 时仅服务 require 其标签的分组/API Key
+	Tags          []string
+	TagsExclusive bool
 	// SkipDefaultGroupBind prevents auto-binding to platform default group when GroupIDs is empty.
 	SkipDefaultGroupBind bool
 	// SkipMixedChannelCheck skips the mixed channel risk check when binding groups.
@@ -287,6 +336,8 @@ type UpdateAccountInput struct {
 	GroupIDs              *[]int64
 	ExpiresAt             *int64
 	AutoPauseOnExpired    *bool
+	Tags                  *[]string // nil 表示未提供不改动
+	TagsExclusive         *bool
 	SkipMixedChannelCheck bool // 跳过混合渠道检查（用户已确认风险）
 }
 
@@ -317,6 +368,7 @@ type BulkUpdateAccountFilters struct {
 	Group       string
 	Search      string
 	PrivacyMode string
+	Tag         string
 }
 
 // BulkUpdateAccountResult captures the result for a single account update.
@@ -365,12 +417,13 @@ type BulkUpdateAccountsResult struct {
 }
 
 type CreateProxyInput struct {
-	Name     string
-	Protocol string
-	Host     string
-	Port     int
-	Username string
-	Password string
+	Name           string
+	Protocol       string
+	Host           string
+	Port           int
+	Username       string
+	Password       string
+	MaxConcurrency int
 }
 
 type UpdateProxyInput struct {
@@ -381,6 +434,8 @@ type UpdateProxyInput struct {
 	Username string
 	Password string
 	Status   string
+	// MaxConcurrency 为 nil 时保持不变
+	MaxConcurrency *int
 }
 
 type GenerateRedeemCodesInput struct {
@@ -1314,9 +1369,9 @@ func cloneAdminAuthIdentityMetadata(input map[string]any) map[string]any {
 }
 
 // Group management implementations
-func (s *adminServiceImpl) ListGroups(ctx context.Context, page, pageSize int, platform, status, search string, isExclusive *bool, sortBy, sortOrder string) ([]Group, int64, error) {
+func (s *adminServiceImpl) ListGroups(ctx context.Context, page, pageSize int, platform, status, search string, isExclusive *bool, organizationID *int64, sortBy, sortOrder string) ([]Group, int64, error) {
 	params := pagination.PaginationParams{Page: page, PageSize: pageSize, SortBy: sortBy, SortOrder: sortOrder}
-	groups, result, err := s.groupRepo.ListWithFilters(ctx, params, platform, status, search, isExclusive)
+	groups, result, err := s.groupRepo.ListWithFilters(ctx, params, platform, status, search, isExclusive, organizationID)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -1350,6 +1405,39 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		subscriptionType = SubscriptionTypeStandard
 	}
 
+	priority := input.Priority
+	if priority == "" {
+		priority = PriorityNormal
+	} else if !IsValidPriority(priority) {
+		return nil, ErrInvalidPriority
+	}
+
+	paramOverrides, err := ValidateRequestParamOverrides(input.RequestParamOverrides)
+	if err != nil {
+		return nil, err
+	}
+
+	systemPromptMode := input.SystemPromptMode
+	if systemPromptMode == "" {
+		systemPromptMode = SystemPromptModePrepend
+	} else if !IsValidSystemPromptMode(systemPromptMode) {
+		return nil, ErrInvalidSystemPromptMode
+	}
+	if !IsValidPIIRedactionMode(input.PIIRedactionMode) {
+		return nil, ErrInvalidPIIRedactionMode
+	}
+	if input.MaxBodySize < 0 {
+		return nil, ErrInvalidMaxBodySize
+	}
+	responseHeaders, err := ValidateGroupResponseHeaders(input.ResponseHeaders)
+	if err != nil {
+		return nil, err
+	}
+	accountTagSelector, err := NormalizeAccountTagSelector(input.AccountTagSelector)
+	if err != nil {
+		return nil, err
+	}
+
 	// 限额字段：nil/负数 表示"无限制"，0 表示"不允许用量"，正数表示具体限额
 	dailyLimit := normalizeLimit(input.DailyLimitUSD)
 	weeklyLimit := normalizeLimit(input.WeeklyLimitUSD)
@@ -1441,6 +1529,15 @@ func (s *adminServiceImpl) CreateGroup(ctx context.Context, input *CreateGroupIn
 		DefaultMappedModel:              input.DefaultMappedModel,
 		MessagesDispatchModelConfig:     normalizeOpenAIMessagesDispatchModelConfig(input.MessagesDispatchModelConfig),
 		RPMLimit:                        input.RPMLimit,
+		Priority:                        priority,
+		RequestParamOverrides:           paramOverrides,
+		SystemPrompt:                    input.SystemPrompt,
+		SystemPromptMode:                systemPromptMode,
+		PIIRedactionMode:                input.PIIRedactionMode,
+		ModerationEnabled:               input.ModerationEnabled,
+		MaxBodySize:                     input.MaxBodySize,
+		ResponseHeaders:                 responseHeaders,
+		AccountTagSelector:              accountTagSelector,
 	}
 	sanitizeGroupMessagesDispatchFields(group)
 	if err := s.groupRepo.Create(ctx, group); err != nil {
@@ -1678,6 +1775,57 @@ func (s *adminServiceImpl) UpdateGroup(ctx context.Context, id int64, input *Upd
 	if input.RPMLimit != nil {
 		group.RPMLimit = *input.RPMLimit
 	}
+	if input.Priority != nil {
+		if !IsValidPriority(*input.Priority) {
+			return nil, ErrInvalidPriority
+		}
+		group.Priority = *input.Priority
+	}
+	if input.RequestParamOverrides != nil {
+		paramOverrides, err := ValidateRequestParamOverrides(*input.RequestParamOverrides)
+		if err != nil {
+			return nil, err
+		}
+		group.RequestParamOverrides = paramOverrides
+	}
+	if input.SystemPrompt != nil {
+		group.SystemPrompt = *input.SystemPrompt
+	}
+	if input.SystemPromptMode != nil {
+		if !IsValidSystemPromptMode(*input.SystemPromptMode) {
+			return nil, ErrInvalidSystemPromptMode
+		}
+		group.SystemPromptMode = *input.SystemPromptMode
+	}
+	if input.PIIRedactionMode != nil {
+		if !IsValidPIIRedactionMode(*input.PIIRedactionMode) {
+			return nil, ErrInvalidPIIRedactionMode
+		}
+		group.PIIRedactionMode = *input.PIIRedactionMode
+	}
+	if input.ModerationEnabled != nil {
+		group.ModerationEnabled = *input.ModerationEnabled
+	}
+	if input.MaxBodySize != nil {
+		if *input.MaxBodySize < 0 {
+			return nil, ErrInvalidMaxBodySize
+		}
+		group.MaxBodySize = *input.MaxBodySize
+	}
+	if input.ResponseHeaders != nil {
+		responseHeaders, err := ValidateGroupResponseHeaders(*input.ResponseHeaders)
+		if err != nil {
+			return nil, err
+		}
+		group.ResponseHeaders = responseHeaders
+	}
+	if input.AccountTagSelector != nil {
+		accountTagSelector, err := NormalizeAccountTagSelector(*input.AccountTagSelector)
+		if err != nil {
+			return nil, err
+		}
+		group.AccountTagSelector = accountTagSelector
+	}
 	sanitizeGroupMessagesDispatchFields(group)
 
 	if err := s.groupRepo.Update(ctx, group); err != nil {
@@ -1997,6 +2145,158 @@ func (s *adminServiceImpl) AdminResetAPIKeyRateLimitUsage(ctx context.Context, k
 	return apiKey, nil
 }
 
+// AdminUpdateAPIKeyPriority 管理员设置 API Key 的并发等待队列优先级，空字符串表示继承分组。
+func (s *adminServiceImpl) AdminUpdateAPIKeyPriority(ctx context.Context, keyID int64, priority string) (*APIKey, error) {
+	if priority != "" && !IsValidPriority(priority) {
+		return nil, ErrInvalidPriority
+	}
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.Priority = priority
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key priority: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminUpdateAPIKeyModerationMode 管理员设置 API Key 的内容审核覆盖，空字符串表示继承分组。
+func (s *adminServiceImpl) AdminUpdateAPIKeyModerationMode(ctx context.Context, keyID int64, mode string) (*APIKey, error) {
+	if !IsValidModerationMode(mode) {
+		return nil, ErrInvalidModerationMode
+	}
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.ModerationMode = mode
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key moderation mode: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminUpdateAPIKeyContextAutoTrim 管理员开启/关闭 API Key 的上下文窗口自动裁剪。
+func (s *adminServiceImpl) AdminUpdateAPIKeyContextAutoTrim(ctx context.Context, keyID int64, enabled bool) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.ContextAutoTrim = enabled
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key context auto trim: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminUpdateAPIKeyMaxBodySize 管理员设置 API Key 的请求体上限（0 表示继承分组/端点类别配置）。
+func (s *adminServiceImpl) AdminUpdateAPIKeyMaxBodySize(ctx context.Context, keyID int64, maxBodySize int64) (*APIKey, error) {
+	if maxBodySize < 0 {
+		return nil, ErrInvalidMaxBodySize
+	}
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.MaxBodySize = maxBodySize
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key max body size: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminUpdateAPIKeyRequestCoalescing 管理员开启/关闭 API Key 的并发重复请求合并。
+func (s *adminServiceImpl) AdminUpdateAPIKeyRequestCoalescing(ctx context.Context, keyID int64, enabled bool) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.RequestCoalescing = enabled
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key request coalescing: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminUpdateAPIKeyTokenBucket 管理员设置 API Key 的令牌桶（容量 + 每秒补充速率），nil 表示不修改该项。
+func (s *adminServiceImpl) AdminUpdateAPIKeyTokenBucket(ctx context.Context, keyID int64, burst *int, refillRate *float64) (*APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if burst != nil {
+		apiKey.TokenBucketBurst = *burst
+	}
+	if refillRate != nil {
+		apiKey.TokenBucketRefillRate = *refillRate
+	}
+	if err := ValidateTokenBucket(apiKey.TokenBucketBurst, apiKey.TokenBucketRefillRate); err != nil {
+		return nil, err
+	}
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key token bucket: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminUpdateAPIKeyMaxCostPerRequest 管理员设置 API Key 的单次请求预估费用上限（USD），0 表示不限制。
+func (s *adminServiceImpl) AdminUpdateAPIKeyMaxCostPerRequest(ctx context.Context, keyID int64, maxCost float64) (*APIKey, error) {
+	if err := ValidateMaxCostPerRequest(maxCost); err != nil {
+		return nil, err
+	}
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.MaxCostPerRequest = maxCost
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key max cost per request: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
+// AdminUpdateAPIKeyAccountTagSelector 管理员设置 API Key 的账号标签选择器（与分组选择器合并生效）。
+func (s *adminServiceImpl) AdminUpdateAPIKeyAccountTagSelector(ctx context.Context, keyID int64, sel AccountTagSelector) (*APIKey, error) {
+	normalized, err := NormalizeAccountTagSelector(sel)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.AccountTagSelector = normalized
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("update api key account tag selector: %w", err)
+	}
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByKey(ctx, apiKey.Key)
+	}
+	return apiKey, nil
+}
+
 // ReplaceUserGroup 替换用户的专属分组
 func (s *adminServiceImpl) ReplaceUserGroup(ctx context.Context, userID, oldGroupID, newGroupID int64) (*ReplaceUserGroupResult, error) {
 	if oldGroupID == newGroupID {
@@ -2063,9 +2363,9 @@ func (s *adminServiceImpl) ReplaceUserGroup(ctx context.Context, userID, oldGrou
 }
 
 // Account management implementations
-func (s *adminServiceImpl) ListAccounts(ctx context.Context, page, pageSize int, platform, accountType, status, search string, groupID int64, privacyMode string, sortBy, sortOrder string) ([]Account, int64, error) {
+func (s *adminServiceImpl) ListAccounts(ctx context.Context, page, pageSize int, platform, accountType, status, search string, groupID int64, privacyMode, tag string, sortBy, sortOrder string) ([]Account, int64, error) {
 	params := pagination.PaginationParams{Page: page, PageSize: pageSize, SortBy: sortBy, SortOrder: sortOrder}
-	accounts, result, err := s.accountRepo.ListWithFilters(ctx, params, platform, accountType, status, search, groupID, privacyMode)
+	accounts, result, err := s.accountRepo.ListWithFilters(ctx, params, platform, accountType, status, search, groupID, privacyMode, tag)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -2131,8 +2431,20 @@ func (s *adminServiceImpl) CreateAccount(ctx context.Context, input *CreateAccou
 		if err := ValidateQuotaResetConfig(account.Extra); err != nil {
 			return nil, err
 		}
+		if err := ValidateBodyTransformRules(account.Extra); err != nil {
+			return nil, err
+		}
+		if err := ValidateUpstreamHostOverrides(account.Extra); err != nil {
+			return nil, err
+		}
+		if err := ValidateMaintenanceWindows(account.Extra); err != nil {
+			return nil, err
+		}
 		ComputeQuotaResetAt(account.Extra)
 	}
+	if err := ValidateTLSClientCertificate(account.Credentials); err != nil {
+		return nil, err
+	}
 	if input.ExpiresAt != nil && *input.ExpiresAt > 0 {
 		expiresAt := time.Unix(*input.ExpiresAt, 0)
 		account.ExpiresAt = &expiresAt
@@ -2154,6 +2466,12 @@ func (s *adminServiceImpl) CreateAccount(ctx context.Context, input *CreateAccou
 		}
 		account.LoadFactor = input.LoadFactor
 	}
+	tags, err := NormalizeAccountTags(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+	account.Tags = tags
+	account.TagsExclusive = input.TagsExclusive
 	if err := s.accountRepo.Create(ctx, account); err != nil {
 		return nil, err
 	}
@@ -2164,6 +2482,11 @@ func (s *adminServiceImpl) CreateAccount(ctx context.Context, input *CreateAccou
 			return nil, err
 		}
 	}
+	if len(account.Tags) > 0 {
+		if err := s.accountRepo.SetTags(ctx, account.ID, account.Tags); err != nil {
+			return nil, err
+		}
+	}
 
 	// OAuth 账号：创建后异步设置隐私。
 	// 使用 Ensure（幂等）而非 Force：新建账号 Extra 为空时效果相同，但更安全。
@@ -2210,6 +2533,9 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 		account.Notes = normalizeAccountNotes(input.Notes)
 	}
 	if len(input.Credentials) > 0 {
+		if err := ValidateTLSClientCertificate(input.Credentials); err != nil {
+			return nil, err
+		}
 		account.Credentials = input.Credentials
 	}
 	// Extra 使用 map：需要区分“未提供(nil)”与“显式清空({})”。
@@ -2237,6 +2563,15 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 		if err := ValidateQuotaResetConfig(account.Extra); err != nil {
 			return nil, err
 		}
+		if err := ValidateBodyTransformRules(account.Extra); err != nil {
+			return nil, err
+		}
+		if err := ValidateUpstreamHostOverrides(account.Extra); err != nil {
+			return nil, err
+		}
+		if err := ValidateMaintenanceWindows(account.Extra); err != nil {
+			return nil, err
+		}
 		ComputeQuotaResetAt(account.Extra)
 	}
 	if input.ProxyID != nil {
@@ -2271,6 +2606,16 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 			account.LoadFactor = input.LoadFactor
 		}
 	}
+	if input.Tags != nil {
+		tags, err := NormalizeAccountTags(*input.Tags)
+		if err != nil {
+			return nil, err
+		}
+		account.Tags = tags
+	}
+	if input.TagsExclusive != nil {
+		account.TagsExclusive = *input.TagsExclusive
+	}
 	if input.Status != "" {
 		account.Status = input.Status
 	}
@@ -2310,6 +2655,11 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 			return nil, err
 		}
 	}
+	if input.Tags != nil {
+		if err := s.accountRepo.SetTags(ctx, account.ID, account.Tags); err != nil {
+			return nil, err
+		}
+	}
 
 	// 重新查询以确保返回完整数据（包括正确的 Proxy 关联对象）
 	updated, err := s.accountRepo.GetByID(ctx, id)
@@ -2319,6 +2669,51 @@ func (s *adminServiceImpl) UpdateAccount(ctx context.Context, id int64, input *U
 	return updated, nil
 }
 
+// BulkUpdateAccountTags 批量增删账号标签：未指定 AccountIDs 时按 Filters 解析目标账号。
+// 同一标签同时出现在 Add 与 Remove 中视为非法请求。
+func (s *adminServiceImpl) BulkUpdateAccountTags(ctx context.Context, input *BulkUpdateAccountTagsInput) (*BulkUpdateAccountTagsResult, error) {
+	add, err := NormalizeAccountTags(input.Add)
+	if err != nil {
+		return nil, err
+	}
+	remove, err := NormalizeAccountTags(input.Remove)
+	if err != nil {
+		return nil, err
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, ErrAccountTagsNoChange
+	}
+	for _, tag := range add {
+		if slices.Contains(remove, tag) {
+			return nil, ErrInvalidAccountTags
+		}
+	}
+
+	accountIDs := input.AccountIDs
+	if len(accountIDs) == 0 && input.Filters != nil {
+		accountIDs, err = s.resolveBulkUpdateTargetIDs(ctx, input.Filters)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result := &BulkUpdateAccountTagsResult{AccountCount: len(accountIDs)}
+	if len(accountIDs) == 0 {
+		return result, nil
+	}
+	if result.Added, err = s.accountRepo.AddTags(ctx, accountIDs, add); err != nil {
+		return nil, err
+	}
+	if result.Removed, err = s.accountRepo.RemoveTags(ctx, accountIDs, remove); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListAccountTags 列出所有在用标签及使用该标签的账号数
+func (s *adminServiceImpl) ListAccountTags(ctx context.Context) ([]AccountTagCount, error) {
+	return s.accountRepo.ListTags(ctx)
+}
+
 // BulkUpdateAccounts updates multiple accounts in one request.
 // It merges credentials/extra keys instead of overwriting the whole object.
 func (s *adminServiceImpl) BulkUpdateAccounts(ctx context.Context, input *BulkUpdateAccountsInput) (*BulkUpdateAccountsResult, error) {
@@ -2478,6 +2873,7 @@ func (s *adminServiceImpl) resolveBulkUpdateTargetIDs(ctx context.Context, filte
 			filters.Search,
 			groupID,
 			filters.PrivacyMode,
+			filters.Tag,
 			"",
 			"",
 		)
@@ -2587,13 +2983,14 @@ func (s *adminServiceImpl) GetProxiesByIDs(ctx context.Context, ids []int64) ([]
 
 func (s *adminServiceImpl) CreateProxy(ctx context.Context, input *CreateProxyInput) (*Proxy, error) {
 	proxy := &Proxy{
-		Name:     input.Name,
-		Protocol: input.Protocol,
-		Host:     input.Host,
-		Port:     input.Port,
-		Username: input.Username,
-		Password: input.Password,
-		Status:   StatusActive,
+		Name:           input.Name,
+		Protocol:       input.Protocol,
+		Host:           input.Host,
+		Port:           input.Port,
+		Username:       input.Username,
+		Password:       input.Password,
+		Status:         StatusActive,
+		MaxConcurrency: max(input.MaxConcurrency, 0),
 	}
 	if err := s.proxyRepo.Create(ctx, proxy); err != nil {
 		return nil, err
@@ -2630,6 +3027,9 @@ func (s *adminServiceImpl) UpdateProxy(ctx context.Context, id int64, input *Upd
 	if input.Status != "" {
 		proxy.Status = input.Status
 	}
+	if input.MaxConcurrency != nil {
+		proxy.MaxConcurrency = max(*input.MaxConcurrency, 0)
+	}
 
 	if err := s.proxyRepo.Update(ctx, proxy); err != nil {
 		return nil, err
@@ -3236,30 +3636,7 @@ func (s *adminServiceImpl) attachProxyLatency(ctx context.Context, proxies []Pro
 }
 
 func (s *adminServiceImpl) saveProxyLatency(ctx context.Context, proxyID int64, info *ProxyLatencyInfo) {
-	if s.proxyLatencyCache == nil || info == nil {
-		return
-	}
-
-	merged := *info
-	if latencies, err := s.proxyLatencyCache.GetProxyLatencies(ctx, []int64{proxyID}); err == nil {
-		if existing := latencies[proxyID]; existing != nil {
-			if merged.QualityCheckedAt == nil &&
-				merged.QualityScore == nil &&
-				merged.QualityGrade == "" &&
-				merged.QualityStatus == "" &&
-				merged.QualitySummary == "" &&
-				merged.QualityCFRay == "" {
-				merged.QualityStatus = existing.QualityStatus
-				merged.QualityScore = existing.QualityScore
-				merged.QualityGrade = existing.QualityGrade
-				merged.QualitySummary = existing.QualitySummary
-				merged.QualityCheckedAt = existing.QualityCheckedAt
-				merged.QualityCFRay = existing.QualityCFRay
-			}
-		}
-	}
-
-	if err := s.proxyLatencyCache.SetProxyLatency(ctx, proxyID, &merged); err != nil {
+	if err := storeProxyLatency(ctx, s.proxyLatencyCache, proxyID, info); err != nil {
 		logger.LegacyPrintf("service.admin", "Warning: store proxy latency cache failed: %v", err)
 	}
 }
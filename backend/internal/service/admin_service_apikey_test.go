@@ -151,6 +151,9 @@ func (s *apiKeyRepoStubForGroupUpdate) ExistsByKey(context.Context, string) (boo
 func (s *apiKeyRepoStubForGroupUpdate) ListByGroupID(context.Context, int64, pagination.PaginationParams) ([]APIKey, *pagination.PaginationResult, error) {
 	panic("unexpected")
 }
+func (s *apiKeyRepoStubForGroupUpdate) ListByParentID(context.Context, int64) ([]APIKey, error) {
+	panic("unexpected")
+}
 func (s *apiKeyRepoStubForGroupUpdate) SearchAPIKeys(context.Context, int64, string, int) ([]APIKey, error) {
 	panic("unexpected")
 }
@@ -166,6 +169,18 @@ func (s *apiKeyRepoStubForGroupUpdate) ListKeysByUserID(context.Context, int64)
 func (s *apiKeyRepoStubForGroupUpdate) ListKeysByGroupID(context.Context, int64) ([]string, error) {
 	panic("unexpected")
 }
+func (s *apiKeyRepoStubForGroupUpdate) ListKeysByParentKey(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+func (s *apiKeyRepoStubForGroupUpdate) ListExpiredChildTokens(context.Context, time.Time, int) ([]APIKey, error) {
+	panic("unexpected")
+}
+func (s *apiKeyRepoStubForGroupUpdate) ReserveQuota(context.Context, int64, float64) (bool, error) {
+	panic("unexpected")
+}
+func (s *apiKeyRepoStubForGroupUpdate) DeleteChildToken(context.Context, int64) (float64, error) {
+	panic("unexpected")
+}
 func (s *apiKeyRepoStubForGroupUpdate) IncrementQuotaUsed(context.Context, int64, float64) (float64, error) {
 	panic("unexpected")
 }
@@ -214,7 +229,7 @@ func (s *groupRepoStubForGroupUpdate) DeleteCascade(context.Context, int64) ([]i
 func (s *groupRepoStubForGroupUpdate) List(context.Context, pagination.PaginationParams) ([]Group, *pagination.PaginationResult, error) {
 	panic("unexpected")
 }
-func (s *groupRepoStubForGroupUpdate) ListWithFilters(context.Context, pagination.PaginationParams, string, string, string, *bool) ([]Group, *pagination.PaginationResult, error) {
+func (s *groupRepoStubForGroupUpdate) ListWithFilters(context.Context, pagination.PaginationParams, string, string, string, *bool, *int64) ([]Group, *pagination.PaginationResult, error) {
 	panic("unexpected")
 }
 func (s *groupRepoStubForGroupUpdate) ListActive(context.Context) ([]Group, error) {
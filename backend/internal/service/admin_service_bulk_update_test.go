@@ -39,7 +39,12 @@ type accountRepoStubForBulkUpdate struct {
 		search      string
 		groupID     int64
 		privacyMode string
+		tag         string
 	}
+	addTagsIDs    []int64
+	addTags       []string
+	removeTagsIDs []int64
+	removeTags    []string
 }
 
 func (s *accountRepoStubForBulkUpdate) BulkUpdate(_ context.Context, ids []int64, _ AccountBulkUpdate) (int64, error) {
@@ -88,7 +93,19 @@ func (s *accountRepoStubForBulkUpdate) ListByGroup(_ context.Context, groupID in
 	return nil, nil
 }
 
-func (s *accountRepoStubForBulkUpdate) ListWithFilters(_ context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode string) ([]Account, *pagination.PaginationResult, error) {
+func (s *accountRepoStubForBulkUpdate) AddTags(_ context.Context, accountIDs []int64, tags []string) (int64, error) {
+	s.addTagsIDs = append([]int64{}, accountIDs...)
+	s.addTags = append([]string{}, tags...)
+	return int64(len(accountIDs) * len(tags)), nil
+}
+
+func (s *accountRepoStubForBulkUpdate) RemoveTags(_ context.Context, accountIDs []int64, tags []string) (int64, error) {
+	s.removeTagsIDs = append([]int64{}, accountIDs...)
+	s.removeTags = append([]string{}, tags...)
+	return 0, nil
+}
+
+func (s *accountRepoStubForBulkUpdate) ListWithFilters(_ context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode, tag string) ([]Account, *pagination.PaginationResult, error) {
 	s.listCalled = true
 	s.lastListParams = params
 	s.lastListFilters.platform = platform
@@ -97,6 +114,7 @@ func (s *accountRepoStubForBulkUpdate) ListWithFilters(_ context.Context, params
 	s.lastListFilters.search = search
 	s.lastListFilters.groupID = groupID
 	s.lastListFilters.privacyMode = privacyMode
+	s.lastListFilters.tag = tag
 	if s.listErr != nil {
 		return nil, nil, s.listErr
 	}
@@ -246,3 +264,38 @@ func TestAdminServiceBulkUpdateAccounts_ResolvesIDsFromFilters(t *testing.T) {
 	require.Equal(t, 0, result.Failed)
 	require.Equal(t, []int64{7, 11}, result.SuccessIDs)
 }
+
+func TestAdminServiceBulkUpdateAccountTags_ResolvesIDsFromFilters(t *testing.T) {
+	repo := &accountRepoStubForBulkUpdate{
+		listData: []Account{{ID: 3}, {ID: 5}},
+	}
+	svc := &adminServiceImpl{accountRepo: repo}
+
+	result, err := svc.BulkUpdateAccountTags(context.Background(), &BulkUpdateAccountTagsInput{
+		Filters: &BulkUpdateAccountFilters{Platform: PlatformOpenAI, Tag: "eu"},
+		Add:     []string{" Plan-Pro ", "plan-pro"},
+		Remove:  []string{"trial"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "eu", repo.lastListFilters.tag)
+	require.Equal(t, []int64{3, 5}, repo.addTagsIDs)
+	require.Equal(t, []string{"plan-pro"}, repo.addTags)
+	require.Equal(t, []string{"trial"}, repo.removeTags)
+	require.Equal(t, &BulkUpdateAccountTagsResult{AccountCount: 2, Added: 2}, result)
+}
+
+func TestAdminServiceBulkUpdateAccountTags_RejectsInvalidInput(t *testing.T) {
+	repo := &accountRepoStubForBulkUpdate{}
+	svc := &adminServiceImpl{accountRepo: repo}
+
+	_, err := svc.BulkUpdateAccountTags(context.Background(), &BulkUpdateAccountTagsInput{AccountIDs: []int64{1}})
+	require.ErrorIs(t, err, ErrAccountTagsNoChange)
+
+	_, err = svc.BulkUpdateAccountTags(context.Background(), &BulkUpdateAccountTagsInput{
+		AccountIDs: []int64{1},
+		Add:        []string{"eu"},
+		Remove:     []string{"EU"},
+	})
+	require.ErrorIs(t, err, ErrInvalidAccountTags)
+	require.Nil(t, repo.addTagsIDs)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/antigravity"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/gemini"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/openai"
+	"go.uber.org/zap"
+)
+
+// AggregatorModel 聚合端点模型列表条目（OpenAI /v1/models 格式）。
+// ID 带平台命名空间（如 antigravity/claude-sonnet-4-5），可直接作为聚合端点请求的 model 使用。
+type AggregatorModel struct {
+	ID       string `json:"id"`
+	Object   string `json:"object"`
+	Created  int64  `json:"created"`
+	OwnedBy  string `json:"owned_by"`
+	Platform string `json:"platform"`
+	Model    string `json:"model"`
+}
+
+// ListAggregatorModels 合并 API Key 分组内所有可调度平台的模型列表。
+// 平台范围与聚合端点的路由一致（aggregatorPlatformsForGroup），只列出聚合 Chat Completions 能够转发的模型。
+// 每个账号的模型集合按账号 ID + 更新时间缓存（复用 /v1/models 短缓存 TTL），账号配置变更后自然失效。
+func (s *GatewayService) ListAggregatorModels(ctx context.Context, groupID *int64, groupPlatform string) []AggregatorModel {
+	seen := make(map[string]struct{})
+	models := make([]AggregatorModel, 0)
+	for _, platform := range aggregatorPlatformsForGroup(groupPlatform) {
+		accounts, _, err := s.listSchedulableAccounts(ctx, groupID, platform, true)
+		if err != nil {
+			logger.FromContext(ctx).Warn("aggregator.list_accounts_failed",
+				zap.String("platform", platform),
+				zap.Error(err))
+			continue
+		}
+		for i := range accounts {
+			for _, modelID := range s.aggregatorAccountModels(&accounts[i]) {
+				id := platform + "/" + modelID
+				if _, ok := seen[id]; ok {
+					continue
+				}
+				seen[id] = struct{}{}
+				models = append(models, AggregatorModel{
+					ID:       id,
+					Object:   "model",
+					OwnedBy:  aggregatorModelOwner(modelID, platform),
+					Platform: platform,
+					Model:    modelID,
+				})
+			}
+		}
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
+
+// aggregatorAccountModels 返回账号可服务的模型：优先取 model_mapping 中的精确模型名，未配置时使用平台默认列表。
+// 自定义上游没有默认模型，只列出 model_mapping 中配置的模型。
+func (s *GatewayService) aggregatorAccountModels(account *Account) []string {
+	cacheKey := fmt.Sprintf("aggregator|%d|%d", account.ID, account.UpdatedAt.UnixNano())
+	if s.modelsListCache != nil {
+		if cached, found := s.modelsListCache.Get(cacheKey); found {
+			if models, ok := cached.([]string); ok {
+				return models
+			}
+		}
+	}
+
+	var models []string
+	for model := range account.GetModelMapping() {
+		if !strings.Contains(model, "*") {
+			models = append(models, model)
+		}
+	}
+	if len(models) == 0 {
+		models = defaultPlatformModelIDs(account.Platform)
+	}
+	sort.Strings(models)
+
+	if s.modelsListCache != nil {
+		s.modelsListCache.Set(cacheKey, models, s.modelsListCacheTTL)
+	}
+	return models
+}
+
+func defaultPlatformModelIDs(platform string) []string {
+	var ids []string
+	switch platform {
+	case PlatformCustom:
+		return nil
+	case PlatformOpenAI:
+		ids = openai.DefaultModelIDs()
+	case PlatformGemini:
+		for _, m := range gemini.DefaultModels() {
+			ids = append(ids, strings.TrimPrefix(m.Name, "models/"))
+		}
+	case PlatformAntigravity:
+		for _, m := range antigravity.DefaultModels() {
+			ids = append(ids, m.ID)
+		}
+	default:
+		for _, m := range claude.DefaultModels {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
+
+// aggregatorModelOwner 按模型名族返回模型厂商（owned_by），无法识别时回退为服务平台
+func aggregatorModelOwner(model, platform string) string {
+	switch InferModelPlatform(model) {
+	case PlatformAnthropic:
+		return "anthropic"
+	case PlatformGemini:
+		return "google"
+	case PlatformOpenAI:
+		return "openai"
+	}
+	return platform
+}
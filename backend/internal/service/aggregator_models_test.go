@@ -0,0 +1,106 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAggregatorModels_MergesGroupPlatforms(t *testing.T) {
+	groupID := int64(7)
+	repo := &mockAccountRepoForPlatform{
+		accounts: []Account{
+			{
+				ID: 1, Platform: PlatformAnthropic, Status: StatusActive, Schedulable: true,
+				Credentials: map[string]any{
+					"model_mapping": map[string]any{
+						"claude-sonnet-4-5": "claude-sonnet-4-5",
+						"claude-*":          "claude-sonnet-4-5",
+					},
+				},
+			},
+			{ID: 2, Platform: PlatformAntigravity, Status: StatusActive, Schedulable: true},
+			{ID: 3, Platform: PlatformOpenAI, Status: StatusActive, Schedulable: true},
+		},
+	}
+	svc := &GatewayService{
+		accountRepo:        repo,
+		modelsListCache:    gocache.New(time.Minute, time.Minute),
+		modelsListCacheTTL: time.Minute,
+	}
+
+	models := svc.ListAggregatorModels(context.Background(), &groupID, PlatformAnthropic)
+
+	byID := make(map[string]AggregatorModel, len(models))
+	for _, m := range models {
+		byID[m.ID] = m
+	}
+	// 通配映射不出现在列表中；其他平台（OpenAI）账号不属于 Anthropic 分组的可调度范围
+	require.Contains(t, byID, "anthropic/claude-sonnet-4-5")
+	require.NotContains(t, byID, "anthropic/claude-*")
+	for id := range byID {
+		require.NotContains(t, id, "openai/")
+	}
+
+	ag := "claude-sonnet-4-5"
+	entry := byID["antigravity/"+ag]
+	require.Equal(t, PlatformAntigravity, entry.Platform)
+	require.Equal(t, ag, entry.Model)
+	require.Equal(t, "anthropic", entry.OwnedBy)
+	require.Equal(t, "google", byID["antigravity/gemini-2.5-pro"].OwnedBy)
+}
+
+func TestListAggregatorModels_OnlyForwardablePlatforms(t *testing.T) {
+	groupID := int64(8)
+	repo := &mockAccountRepoForPlatform{
+		accounts: []Account{
+			{ID: 1, Platform: PlatformGemini, Status: StatusActive, Schedulable: true},
+			{ID: 2, Platform: PlatformAntigravity, Status: StatusActive, Schedulable: true},
+			{
+				ID: 3, Platform: PlatformCustom, Status: StatusActive, Schedulable: true,
+				Credentials: map[string]any{"model_mapping": map[string]any{"deepseek-chat": "deepseek-chat"}},
+			},
+			{ID: 4, Platform: PlatformCustom, Status: StatusActive, Schedulable: true},
+		},
+	}
+	svc := &GatewayService{accountRepo: repo}
+
+	// Gemini 分组只列出可走 Chat Completions 的 Antigravity 账号模型
+	models := svc.ListAggregatorModels(context.Background(), &groupID, PlatformGemini)
+	require.NotEmpty(t, models)
+	for _, m := range models {
+		require.Equal(t, PlatformAntigravity, m.Platform, m.ID)
+	}
+
+	// 自定义上游只列出显式配置的模型，不回退到 Claude 默认列表
+	var ids []string
+	for _, m := range svc.ListAggregatorModels(context.Background(), &groupID, PlatformCustom) {
+		ids = append(ids, m.ID)
+	}
+	require.Equal(t, []string{"custom/deepseek-chat"}, ids)
+}
+
+func TestAggregatorAccountModels_CachePerAccountVersion(t *testing.T) {
+	svc := &GatewayService{
+		modelsListCache:    gocache.New(time.Minute, time.Minute),
+		modelsListCacheTTL: time.Minute,
+	}
+	account := &Account{
+		ID: 1, Platform: PlatformOpenAI, UpdatedAt: time.Unix(100, 0),
+		Credentials: map[string]any{"model_mapping": map[string]any{"gpt-5": "gpt-5"}},
+	}
+	require.Equal(t, []string{"gpt-5"}, svc.aggregatorAccountModels(account))
+
+	// 同一版本命中缓存
+	account.Credentials = map[string]any{"model_mapping": map[string]any{"gpt-5.4": "gpt-5.4"}}
+	require.Equal(t, []string{"gpt-5"}, svc.aggregatorAccountModels(account))
+
+	// 账号更新后按新版本重新计算
+	account.UpdatedAt = time.Unix(200, 0)
+	require.Equal(t, []string{"gpt-5.4"}, svc.aggregatorAccountModels(account))
+}
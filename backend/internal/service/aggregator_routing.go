@@ -0,0 +1,120 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// ErrAggregatorPlatformUnavailable 命名空间指定的平台不在 API Key 分组的可调度范围内。
+// 聚合端点不会切换到用户可用的其他分组：计费、订阅额度与限流均绑定 Key 的分组。
+var ErrAggregatorPlatformUnavailable = infraerrors.BadRequest("AGGREGATOR_PLATFORM_UNAVAILABLE", "model namespace is not available for this API key's group; use an API key bound to a group of that platform")
+
+// aggregatorNamespacePlatforms 聚合端点支持的模型命名空间前缀（如 antigravity/claude-sonnet-4-5）
+var aggregatorNamespacePlatforms = map[string]string{
+	"anthropic":   PlatformAnthropic,
+	"claude":      PlatformAnthropic,
+	"openai":      PlatformOpenAI,
+	"gemini":      PlatformGemini,
+	"google":      PlatformGemini,
+	"antigravity": PlatformAntigravity,
+	"custom":      PlatformCustom,
+}
+
+// AggregatorRoute 聚合端点对单个请求的路由结果
+type AggregatorRoute struct {
+	// Model 去除命名空间后实际发往上游的模型名
+	Model string
+	// Platform 目标平台
+	Platform string
+	// ForcePlatform 需要在分组内强制调度的平台；为空表示沿用分组默认调度（含混合调度）
+	ForcePlatform string
+	// Namespaced 请求模型是否携带了已知命名空间
+	Namespaced bool
+}
+
+// SplitModelNamespace 拆分 "namespace/model" 形式的模型名。
+// 仅识别已知平台命名空间，其余带斜杠的模型名（如 OpenAI 兼容上游的 "deepseek/deepseek-chat"）原样返回。
+func SplitModelNamespace(model string) (platform, bare string, ok bool) {
+	prefix, rest, found := strings.Cut(strings.TrimSpace(model), "/")
+	if !found || rest == "" {
+		return "", model, false
+	}
+	platform, ok = aggregatorNamespacePlatforms[strings.ToLower(prefix)]
+	if !ok {
+		return "", model, false
+	}
+	return platform, rest, true
+}
+
+// InferModelPlatform 按模型名族推断其原生平台，无法识别时返回空字符串
+func InferModelPlatform(model string) string {
+	lower := strings.ToLower(strings.TrimSpace(model))
+	switch {
+	case strings.HasPrefix(lower, "claude"):
+		return PlatformAnthropic
+	case strings.HasPrefix(lower, "gemini"):
+		return PlatformGemini
+	case strings.HasPrefix(lower, "gpt-"),
+		strings.HasPrefix(lower, "chatgpt-"),
+		strings.HasPrefix(lower, "codex-"),
+		strings.HasPrefix(lower, "o1"),
+		strings.HasPrefix(lower, "o3"),
+		strings.HasPrefix(lower, "o4"):
+		return PlatformOpenAI
+	}
+	return ""
+}
+
+// aggregatorPlatformsForGroup 返回指定分组平台下聚合端点可调度的平台集合。
+// Anthropic 分组可混合调度 Antigravity 账号；Gemini 账号没有 Chat Completions 转发链路，
+// 因此 Gemini 分组只调度其中混合的 Antigravity 账号。
+func aggregatorPlatformsForGroup(groupPlatform string) []string {
+	switch groupPlatform {
+	case PlatformAnthropic, "":
+		return []string{PlatformAnthropic, PlatformAntigravity}
+	case PlatformGemini:
+		return []string{PlatformAntigravity}
+	default:
+		return []string{groupPlatform}
+	}
+}
+
+// ResolveAggregatorRoute 为聚合端点解析请求模型应由分组内哪个平台处理：
+//   - 带命名空间的模型：目标平台必须在分组可调度平台内，否则返回 ErrAggregatorPlatformUnavailable；
+//     目标平台与分组平台不同（如 Anthropic 分组请求 antigravity/...）或需要排除混合调度时强制该平台
+//   - 无命名空间的模型：按模型名族推断平台，仅当分组平台无法服务而 Antigravity 可以时强制 Antigravity，
+//     其余情况沿用分组默认调度（由账号模型映射决定最终可用性）
+//   - Gemini 分组始终强制 Antigravity，避免调度到无法处理 Chat Completions 的 Gemini 账号
+//
+// 解析范围仅限 Key 自身分组（groupPlatform），不在用户的其他分组间查找目标平台，
+// 例如 Anthropic 分组的 Key 请求 openai/gpt-5 返回 ErrAggregatorPlatformUnavailable。
+func ResolveAggregatorRoute(model, groupPlatform string) (AggregatorRoute, error) {
+	allowed := aggregatorPlatformsForGroup(groupPlatform)
+	if platform, bare, ok := SplitModelNamespace(model); ok {
+		if !slices.Contains(allowed, platform) {
+			return AggregatorRoute{}, ErrAggregatorPlatformUnavailable
+		}
+		route := AggregatorRoute{Model: bare, Platform: platform, Namespaced: true}
+		if len(allowed) > 1 || platform != groupPlatform {
+			route.ForcePlatform = platform
+		}
+		return route, nil
+	}
+
+	route := AggregatorRoute{Model: model, Platform: allowed[0]}
+	if groupPlatform == PlatformGemini {
+		route.ForcePlatform = PlatformAntigravity
+		return route, nil
+	}
+	inferred := InferModelPlatform(model)
+	if inferred == "" || inferred == allowed[0] {
+		return route, nil
+	}
+	if slices.Contains(allowed, PlatformAntigravity) && (inferred == PlatformAnthropic || inferred == PlatformGemini) {
+		route.Platform = PlatformAntigravity
+		route.ForcePlatform = PlatformAntigravity
+	}
+	return route, nil
+}
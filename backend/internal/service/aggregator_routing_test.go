@@ -0,0 +1,67 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitModelNamespace(t *testing.T) {
+	platform, bare, ok := SplitModelNamespace("Antigravity/claude-sonnet-4-5")
+	require.True(t, ok)
+	require.Equal(t, PlatformAntigravity, platform)
+	require.Equal(t, "claude-sonnet-4-5", bare)
+
+	// 未知命名空间与普通模型名原样返回
+	for _, model := range []string{"deepseek/deepseek-chat", "gpt-5", "openai/"} {
+		_, bare, ok = SplitModelNamespace(model)
+		require.False(t, ok, model)
+		require.Equal(t, model, bare)
+	}
+}
+
+func TestResolveAggregatorRoute(t *testing.T) {
+	tests := []struct {
+		name          string
+		model         string
+		groupPlatform string
+		want          AggregatorRoute
+		wantErr       bool
+	}{
+		{"plain claude in anthropic group", "claude-sonnet-4-5", PlatformAnthropic,
+			AggregatorRoute{Model: "claude-sonnet-4-5", Platform: PlatformAnthropic}, false},
+		{"gemini in anthropic group uses antigravity", "gemini-3-pro", PlatformAnthropic,
+			AggregatorRoute{Model: "gemini-3-pro", Platform: PlatformAntigravity, ForcePlatform: PlatformAntigravity}, false},
+		{"claude in openai group keeps group routing", "claude-sonnet-4-5", PlatformOpenAI,
+			AggregatorRoute{Model: "claude-sonnet-4-5", Platform: PlatformOpenAI}, false},
+		{"namespaced antigravity in anthropic group", "antigravity/claude-opus-4-6", PlatformAnthropic,
+			AggregatorRoute{Model: "claude-opus-4-6", Platform: PlatformAntigravity, ForcePlatform: PlatformAntigravity, Namespaced: true}, false},
+		{"namespaced anthropic excludes mixed accounts", "anthropic/claude-opus-4-6", PlatformAnthropic,
+			AggregatorRoute{Model: "claude-opus-4-6", Platform: PlatformAnthropic, ForcePlatform: PlatformAnthropic, Namespaced: true}, false},
+		{"namespaced openai in openai group", "openai/gpt-5", PlatformOpenAI,
+			AggregatorRoute{Model: "gpt-5", Platform: PlatformOpenAI, Namespaced: true}, false},
+		{"namespaced openai in anthropic group", "openai/gpt-5", PlatformAnthropic, AggregatorRoute{}, true},
+		{"gemini group forces antigravity", "gemini-2.5-pro", PlatformGemini,
+			AggregatorRoute{Model: "gemini-2.5-pro", Platform: PlatformAntigravity, ForcePlatform: PlatformAntigravity}, false},
+		{"namespaced gemini in gemini group", "gemini/gemini-2.5-pro", PlatformGemini, AggregatorRoute{}, true},
+		{"namespaced antigravity in gemini group", "antigravity/gemini-3-pro", PlatformGemini,
+			AggregatorRoute{Model: "gemini-3-pro", Platform: PlatformAntigravity, ForcePlatform: PlatformAntigravity, Namespaced: true}, false},
+		{"namespaced custom in custom group", "custom/deepseek/deepseek-chat", PlatformCustom,
+			AggregatorRoute{Model: "deepseek/deepseek-chat", Platform: PlatformCustom, Namespaced: true}, false},
+		{"unknown namespace passes through", "copilot/claude-sonnet-4", PlatformOpenAI,
+			AggregatorRoute{Model: "copilot/claude-sonnet-4", Platform: PlatformOpenAI}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveAggregatorRoute(tt.model, tt.groupPlatform)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrAggregatorPlatformUnavailable)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
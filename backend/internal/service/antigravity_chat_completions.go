@@ -0,0 +1,243 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// ForwardAsChatCompletions 接收 OpenAI Chat Completions 请求，转换为 Claude Messages 格式后
+// 复用 Forward 走 Antigravity 上游（模型映射、重试、限流与 usage 提取均与 /messages 一致），
+// 再把 Forward 写出的 Claude 响应（SSE / JSON / 错误）实时转换回 Chat Completions 格式。
+func (s *AntigravityGatewayService) ForwardAsChatCompletions(ctx context.Context, c *gin.Context, account *Account, body []byte, isStickySession bool) (*ForwardResult, error) {
+	var ccReq apicompat.ChatCompletionsRequest
+	if err := json.Unmarshal(body, &ccReq); err != nil {
+		writeGatewayCCError(c, http.StatusBadRequest, "invalid_request_error", "Invalid request body")
+		return nil, fmt.Errorf("parse chat completions request: %w", err)
+	}
+	originalModel := ccReq.Model
+	includeUsage := ccReq.StreamOptions != nil && ccReq.StreamOptions.IncludeUsage
+
+	// CC → Responses → Anthropic（与 GatewayService.ForwardAsChatCompletions 相同的链式转换）
+	responsesReq, err := apicompat.ChatCompletionsToResponses(&ccReq)
+	if err != nil {
+		writeGatewayCCError(c, http.StatusBadRequest, "invalid_request_error", "Invalid request")
+		return nil, fmt.Errorf("convert chat completions to responses: %w", err)
+	}
+	anthropicReq, err := apicompat.ResponsesToAnthropicRequest(responsesReq)
+	if err != nil {
+		writeGatewayCCError(c, http.StatusBadRequest, "invalid_request_error", "Invalid request")
+		return nil, fmt.Errorf("convert responses to anthropic: %w", err)
+	}
+	anthropicReq.Model = originalModel
+	anthropicReq.Stream = ccReq.Stream
+	anthropicBody, err := json.Marshal(anthropicReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal anthropic request: %w", err)
+	}
+
+	writer := newAntigravityChatCompletionsWriter(c, originalModel, includeUsage)
+	writer.estimatedPromptTokens = estimateChatPromptTokens(body, includeUsage)
+	if s.settingService != nil {
+		writer.ccState.ToolArgsCoalesce = resolveToolArgsCoalesce(s.settingService.cfg)
+	}
+	original := c.Writer
+	c.Writer = writer
+	result, err := s.Forward(ctx, c, account, anthropicBody, isStickySession)
+	c.Writer = original
+	writer.finish(result)
+
+	if result != nil {
+		result.ReasoningEffort = extractCCReasoningEffortFromBody(body)
+	}
+	return result, err
+}
+
+// antigravityChatCompletionsWriter 拦截 Forward 写出的 Claude 格式响应并转换为 Chat Completions：
+//   - text/event-stream：逐事件经 Anthropic → Responses → CC 状态机转换后实时写出
+//   - 其他（非流式 JSON、错误响应）：缓冲后在 finish 中整体转换
+type antigravityChatCompletionsWriter struct {
+	gin.ResponseWriter
+	c     *gin.Context
+	model string
+
+	mode      int
+	pending   []byte
+	eventData []string
+	buffered  bytes.Buffer
+
+	anthState *apicompat.AnthropicEventToResponsesState
+	ccState   *apicompat.ResponsesEventToChatState
+	// estimatedPromptTokens 上游流未携带用量时补发用量块使用的 prompt tokens 估算值
+	estimatedPromptTokens int
+}
+
+// antigravityChatCompletionsWriter 的输出模式，在首次写入时按状态码与 Content-Type 确定
+const (
+	antigravityCCModeUnknown = iota
+	antigravityCCModeStream
+	antigravityCCModeBuffer
+)
+
+func newAntigravityChatCompletionsWriter(c *gin.Context, model string, includeUsage bool) *antigravityChatCompletionsWriter {
+	anthState := apicompat.NewAnthropicEventToResponsesState()
+	anthState.Model = model
+	ccState := apicompat.NewResponsesEventToChatState()
+	ccState.Model = model
+	ccState.IncludeUsage = includeUsage
+	return &antigravityChatCompletionsWriter{
+		ResponseWriter: c.Writer,
+		c:              c,
+		model:          model,
+		anthState:      anthState,
+		ccState:        ccState,
+	}
+}
+
+func (w *antigravityChatCompletionsWriter) resolveMode() int {
+	if w.mode == antigravityCCModeUnknown {
+		w.mode = antigravityCCModeBuffer
+		if w.Status() < http.StatusBadRequest && strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+			w.mode = antigravityCCModeStream
+		}
+	}
+	return w.mode
+}
+
+func (w *antigravityChatCompletionsWriter) Write(b []byte) (int, error) {
+	if w.resolveMode() == antigravityCCModeBuffer {
+		return w.buffered.Write(b)
+	}
+	w.pending = append(w.pending, b...)
+	for {
+		idx := bytes.IndexByte(w.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		line := strings.TrimSuffix(string(w.pending[:idx]), "\r")
+		w.pending = w.pending[idx+1:]
+		if err := w.processLine(line); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *antigravityChatCompletionsWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *antigravityChatCompletionsWriter) processLine(line string) error {
+	if line != "" {
+		if data, ok := strings.CutPrefix(line, "data:"); ok {
+			w.eventData = append(w.eventData, strings.TrimPrefix(data, " "))
+		}
+		return nil
+	}
+	if len(w.eventData) == 0 {
+		return nil
+	}
+	payload := strings.Join(w.eventData, "\n")
+	w.eventData = w.eventData[:0]
+	return w.processEvent(payload)
+}
+
+func (w *antigravityChatCompletionsWriter) processEvent(payload string) error {
+	switch gjson.Get(payload, "type").String() {
+	case "ping":
+		_, err := w.ResponseWriter.WriteString(streamKeepaliveComment)
+		return err
+	case "error":
+		errType := gjson.Get(payload, "error.type").String()
+		if errType == "" {
+			errType = "server_error"
+		}
+		_, err := w.ResponseWriter.WriteString(streamErrorEvent(streamErrorEventChatCompletions, errType, http.StatusBadGateway, gjson.Get(payload, "error.message").String(), gatewayRequestID(w.c)))
+		return err
+	}
+
+	var event apicompat.AnthropicStreamEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		logger.L().Warn("antigravity chat completions: failed to parse event", zap.Error(err))
+		return nil
+	}
+	for _, resEvt := range apicompat.AnthropicEventToResponsesEvents(&event, w.anthState) {
+		if err := w.writeChunks(apicompat.ResponsesEventToChatChunks(&resEvt, w.ccState)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *antigravityChatCompletionsWriter) writeChunks(chunks []apicompat.ChatCompletionsChunk) error {
+	for _, chunk := range chunks {
+		sse, err := apicompat.ChatChunkToSSE(chunk)
+		if err != nil {
+			continue
+		}
+		if _, err := w.ResponseWriter.WriteString(string(reverseToolNamesIfPresent(w.c, []byte(sse)))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish 在 Forward 返回后收尾：流式补发结束块、缺失的用量块与 [DONE]，缓冲模式整体转换后写出
+func (w *antigravityChatCompletionsWriter) finish(result *ForwardResult) {
+	switch w.mode {
+	case antigravityCCModeStream:
+		if len(w.pending) > 0 {
+			_ = w.processLine(strings.TrimSuffix(string(w.pending), "\r"))
+			w.pending = nil
+		}
+		_ = w.processLine("")
+		for _, resEvt := range apicompat.FinalizeAnthropicResponsesStream(w.anthState) {
+			_ = w.writeChunks(apicompat.ResponsesEventToChatChunks(&resEvt, w.ccState))
+		}
+		_ = w.writeChunks(apicompat.FinalizeResponsesChatStream(w.ccState))
+		var usage ClaudeUsage
+		if result != nil {
+			usage = result.Usage
+		}
+		_ = w.writeChunks(apicompat.FinalizeChatStreamUsage(w.ccState, chatStreamUsageFallback(
+			usage.InputTokens, usage.OutputTokens, usage.CacheReadInputTokens, w.estimatedPromptTokens, w.ccState.OutputChars)))
+		_, _ = w.ResponseWriter.WriteString("data: [DONE]\n\n")
+		w.ResponseWriter.Flush()
+	case antigravityCCModeBuffer:
+		w.Header().Del("Content-Length")
+		body := w.buffered.Bytes()
+		if w.Status() >= http.StatusBadRequest {
+			errType := gjson.GetBytes(body, "error.type").String()
+			if errType == "" {
+				errType = "server_error"
+			}
+			message := gjson.GetBytes(body, "error.message").String()
+			if message == "" {
+				message = http.StatusText(w.Status())
+			}
+			writeGatewayCCError(w.c, w.Status(), errType, message)
+			return
+		}
+		var anthropicResp apicompat.AnthropicResponse
+		if err := json.Unmarshal(body, &anthropicResp); err != nil {
+			writeGatewayCCError(w.c, http.StatusBadGateway, "server_error", "Failed to parse upstream response")
+			return
+		}
+		ccResp := apicompat.ResponsesToChatCompletions(apicompat.AnthropicToResponsesResponse(&anthropicResp), w.model)
+		respBytes, err := json.Marshal(ccResp)
+		if err != nil {
+			writeGatewayCCError(w.c, http.StatusBadGateway, "server_error", "Failed to encode response")
+			return
+		}
+		w.c.Data(w.Status(), "application/json; charset=utf-8", reverseToolNamesIfPresent(w.c, respBytes))
+	}
+}
@@ -0,0 +1,71 @@
+//go:build unit
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestAntigravityChatCompletionsWriter_ConvertsClaudeStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	w := newAntigravityChatCompletionsWriter(c, "claude-sonnet-4-5", true)
+	c.Writer = w
+	c.Header("Content-Type", "text/event-stream")
+	c.Status(http.StatusOK)
+
+	// 事件可能被拆成多次写入
+	_, _ = w.WriteString("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"usage\":{\"input_tokens\":5,\"output_tokens\":0}}}\n\n")
+	_, _ = w.WriteString("event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\nevent: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,")
+	_, _ = w.WriteString("\"delta\":{\"type\":\"text_delta\",\"text\":\"hello\"}}\n\n")
+	_, _ = w.WriteString("event: ping\ndata: {\"type\":\"ping\"}\n\n")
+	_, _ = w.WriteString("event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":3}}\n\nevent: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	w.finish(nil)
+
+	out := rec.Body.String()
+	require.NotContains(t, out, "event: message_start")
+	require.Contains(t, out, `"content":"hello"`)
+	require.Contains(t, out, `"object":"chat.completion.chunk"`)
+	require.Contains(t, out, ":\n\n")
+	require.True(t, strings.HasSuffix(out, "data: [DONE]\n\n"))
+}
+
+func TestAntigravityChatCompletionsWriter_ConvertsJSONAndErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	original := c.Writer
+	w := newAntigravityChatCompletionsWriter(c, "claude-sonnet-4-5", false)
+	c.Writer = w
+	c.Data(http.StatusOK, "application/json", []byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"hi"}],"stop_reason":"end_turn","usage":{"input_tokens":4,"output_tokens":2}}`))
+	c.Writer = original
+	w.finish(nil)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "chat.completion", gjson.Get(rec.Body.String(), "object").String())
+	require.Equal(t, "hi", gjson.Get(rec.Body.String(), "choices.0.message.content").String())
+
+	rec = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(rec)
+	original = c.Writer
+	w = newAntigravityChatCompletionsWriter(c, "claude-sonnet-4-5", false)
+	c.Writer = w
+	c.JSON(http.StatusForbidden, gin.H{"type": "error", "error": gin.H{"type": "permission_error", "message": "model not in whitelist"}})
+	c.Writer = original
+	w.finish(nil)
+
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Equal(t, "permission_error", gjson.Get(rec.Body.String(), "error.type").String())
+	require.Equal(t, "model not in whitelist", gjson.Get(rec.Body.String(), "error.message").String())
+	require.False(t, gjson.Get(rec.Body.String(), "type").Exists())
+}
@@ -36,6 +36,34 @@ type APIKey struct {
 	Status      string
 	IPWhitelist []string
 	IPBlacklist []string
+	// Scopes 权限范围（如 chat、platform:openai），为空表示不受限
+	Scopes []string
+	// Priority 并发等待队列优先级（high/normal/low），为空表示继承分组
+	Priority string
+	// ModerationMode 内容审核覆盖（enabled/disabled），为空表示继承分组
+	ModerationMode string
+	// ContextAutoTrim 预估提示词超出模型上下文窗口时自动丢弃最早的消息
+	ContextAutoTrim bool
+	// MaxBodySize 请求体最大字节数，0 表示继承分组/端点类别配置
+	MaxBodySize int64
+	// RequestCoalescing 相同的并发非流式请求合并为一次上游调用
+	RequestCoalescing bool
+	// TokenBucketBurst 令牌桶容量（请求数），0 表示不启用
+	TokenBucketBurst int
+	// TokenBucketRefillRate 令牌桶每秒补充的请求数
+	TokenBucketRefillRate float64
+	// MaxCostPerRequest 单次请求预估费用上限（USD），0 表示不限制
+	MaxCostPerRequest float64
+	// AccountTagSelector 账号标签选择器，调度时与分组选择器合并生效
+	AccountTagSelector AccountTagSelector
+	// AllowedPlatforms 允许路由到的上游平台，为空表示不受限
+	AllowedPlatforms []string
+	// ParentID 子令牌的父 Key ID，0 表示普通 Key
+	ParentID int64
+	// AllowedModels 允许请求的模型（支持 * 后缀通配），为空表示不受限
+	AllowedModels []string
+	// ParentState 子令牌父 Key 的状态快照，随认证缓存加载；为空时认证阶段回源查询
+	ParentState *APIKeyParentState `json:"-"`
 	// 预编译的 IP 规则，用于认证热路径避免重复 ParseIP/ParseCIDR。
 	CompiledIPWhitelist *ip.CompiledIPRules `json:"-"`
 	CompiledIPBlacklist *ip.CompiledIPRules `json:"-"`
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// ErrInvalidAllowedPlatforms API Key 允许平台列表包含未知平台
+var ErrInvalidAllowedPlatforms = infraerrors.BadRequest("INVALID_ALLOWED_PLATFORMS", "invalid allowed platforms")
+
+// NormalizeAPIKeyAllowedPlatforms 去除空白、转小写、去重并排序，返回非法的平台列表。
+func NormalizeAPIKeyAllowedPlatforms(platforms []string) (normalized []string, invalid []string) {
+	seen := make(map[string]struct{}, len(platforms))
+	for _, raw := range platforms {
+		platform := strings.ToLower(strings.TrimSpace(raw))
+		if platform == "" {
+			continue
+		}
+		if _, ok := apiKeyScopePlatforms[platform]; !ok {
+			invalid = append(invalid, raw)
+			continue
+		}
+		if _, ok := seen[platform]; ok {
+			continue
+		}
+		seen[platform] = struct{}{}
+		normalized = append(normalized, platform)
+	}
+	sort.Strings(normalized)
+	return normalized, invalid
+}
+
+// IsPlatformAllowed 判断上游平台是否在 Key 的允许平台列表内；未配置列表时不受限。
+func (k *APIKey) IsPlatformAllowed(platform string) bool {
+	if k == nil {
+		return false
+	}
+	return len(k.AllowedPlatforms) == 0 || slices.Contains(k.AllowedPlatforms, platform)
+}
+
+// CanRouteToPlatform 在分组解析前判断请求的目标平台（强制平台优先，其次为分组平台）能否命中允许的账号。
+// anthropic/gemini 分组在非强制平台时支持混合调度 antigravity 账号，因此仅允许 antigravity 的 Key 也可访问；
+// 目标平台未知（如未分组 Key）时放行，由调度阶段按账号平台过滤。
+func (k *APIKey) CanRouteToPlatform(platform string, forced bool) bool {
+	if k == nil {
+		return false
+	}
+	if len(k.AllowedPlatforms) == 0 || platform == "" || k.IsPlatformAllowed(platform) {
+		return true
+	}
+	mixed := !forced && (platform == PlatformAnthropic || platform == PlatformGemini)
+	return mixed && k.IsPlatformAllowed(PlatformAntigravity)
+}
+
+// requestAllowedPlatforms 返回当前请求 API Key 的允许平台列表，未限制时返回 nil。
+func requestAllowedPlatforms(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	platforms, _ := ctx.Value(ctxkey.AllowedPlatforms).([]string)
+	return platforms
+}
+
+// applyAllowedPlatforms 剔除平台不在 API Key 允许列表内的调度候选（混合调度分组中的其他平台账号）。
+func applyAllowedPlatforms(ctx context.Context, accounts []Account) []Account {
+	allowed := requestAllowedPlatforms(ctx)
+	if len(allowed) == 0 {
+		return accounts
+	}
+	filtered := make([]Account, 0, len(accounts))
+	for i := range accounts {
+		if slices.Contains(allowed, accounts[i].Platform) {
+			filtered = append(filtered, accounts[i])
+		}
+	}
+	return filtered
+}
+
+// restrictAllowedPlatforms 按 ID 获取的账号平台不在允许列表内时以不可调度的副本返回。
+func restrictAllowedPlatforms(ctx context.Context, account *Account) *Account {
+	allowed := requestAllowedPlatforms(ctx)
+	if account == nil || len(allowed) == 0 || slices.Contains(allowed, account.Platform) {
+		return account
+	}
+	cp := *account
+	cp.Schedulable = false
+	return &cp
+}
+
+// applyRequestAccountFilters 按请求上下文过滤调度候选：账号归属、账号标签选择器、API Key 允许平台。
+func applyRequestAccountFilters(ctx context.Context, accounts []Account) []Account {
+	return applyAllowedPlatforms(ctx, applyAccountTagSelector(ctx, applyAccountOwnership(ctx, accounts)))
+}
+
+// restrictRequestAccount 对按 ID 获取的账号应用与 applyRequestAccountFilters 相同的限制。
+func restrictRequestAccount(ctx context.Context, account *Account) *Account {
+	return restrictAllowedPlatforms(ctx, restrictAccountTagSelector(ctx, restrictAccountOwnership(ctx, account)))
+}
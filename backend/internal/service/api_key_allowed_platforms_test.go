@@ -0,0 +1,46 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyAllowedPlatforms(t *testing.T) {
+	normalized, invalid := NormalizeAPIKeyAllowedPlatforms([]string{" OpenAI ", "anthropic", "openai", "", "copilot"})
+	require.Equal(t, []string{"anthropic", "openai"}, normalized)
+	require.Equal(t, []string{"copilot"}, invalid)
+}
+
+func TestAPIKeyCanRouteToPlatform(t *testing.T) {
+	require.True(t, (&APIKey{}).CanRouteToPlatform(PlatformOpenAI, false))
+
+	key := &APIKey{AllowedPlatforms: []string{PlatformAntigravity}}
+	require.True(t, key.CanRouteToPlatform(PlatformAntigravity, true))
+	// anthropic/gemini 分组可混合调度 antigravity 账号
+	require.True(t, key.CanRouteToPlatform(PlatformGemini, false))
+	require.False(t, key.CanRouteToPlatform(PlatformGemini, true))
+	require.False(t, key.CanRouteToPlatform(PlatformOpenAI, false))
+	require.True(t, key.CanRouteToPlatform("", false))
+}
+
+func TestApplyRequestAccountFilters_AllowedPlatforms(t *testing.T) {
+	accounts := []Account{
+		{ID: 1, Platform: PlatformAnthropic, Schedulable: true},
+		{ID: 2, Platform: PlatformAntigravity, Schedulable: true},
+		{ID: 3, Platform: PlatformAnthropic, Schedulable: true},
+	}
+	require.Equal(t, []int64{1, 2, 3}, accountIDs(applyRequestAccountFilters(context.Background(), accounts)))
+
+	ctx := context.WithValue(context.Background(), ctxkey.AllowedPlatforms, []string{PlatformAntigravity})
+	require.Equal(t, []int64{2}, accountIDs(applyRequestAccountFilters(ctx, accounts)))
+
+	restricted := restrictRequestAccount(ctx, &accounts[0])
+	require.False(t, restricted.Schedulable)
+	require.True(t, accounts[0].Schedulable)
+	require.Same(t, &accounts[1], restrictRequestAccount(ctx, &accounts[1]))
+}
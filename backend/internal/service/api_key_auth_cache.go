@@ -4,15 +4,29 @@ import "time"
 
 // APIKeyAuthSnapshot API Key 认证缓存快照（仅包含认证所需字段）
 type APIKeyAuthSnapshot struct {
-	Version     int                      `json:"version"`
-	APIKeyID    int64                    `json:"api_key_id"`
-	UserID      int64                    `json:"user_id"`
-	GroupID     *int64                   `json:"group_id,omitempty"`
-	Status      string                   `json:"status"`
-	IPWhitelist []string                 `json:"ip_whitelist,omitempty"`
-	IPBlacklist []string                 `json:"ip_blacklist,omitempty"`
-	User        APIKeyAuthUserSnapshot   `json:"user"`
-	Group       *APIKeyAuthGroupSnapshot `json:"group,omitempty"`
+	Version               int                      `json:"version"`
+	APIKeyID              int64                    `json:"api_key_id"`
+	UserID                int64                    `json:"user_id"`
+	GroupID               *int64                   `json:"group_id,omitempty"`
+	Status                string                   `json:"status"`
+	IPWhitelist           []string                 `json:"ip_whitelist,omitempty"`
+	IPBlacklist           []string                 `json:"ip_blacklist,omitempty"`
+	Scopes                []string                 `json:"scopes,omitempty"`
+	Priority              string                   `json:"priority,omitempty"`
+	ModerationMode        string                   `json:"moderation_mode,omitempty"`
+	ContextAutoTrim       bool                     `json:"context_auto_trim,omitempty"`
+	MaxBodySize           int64                    `json:"max_body_size,omitempty"`
+	RequestCoalescing     bool                     `json:"request_coalescing,omitempty"`
+	TokenBucketBurst      int                      `json:"token_bucket_burst,omitempty"`
+	TokenBucketRefillRate float64                  `json:"token_bucket_refill_rate,omitempty"`
+	MaxCostPerRequest     float64                  `json:"max_cost_per_request,omitempty"`
+	AccountTagSelector    AccountTagSelector       `json:"account_tag_selector,omitempty"`
+	AllowedPlatforms      []string                 `json:"allowed_platforms,omitempty"`
+	ParentID              int64                    `json:"parent_id,omitempty"`
+	AllowedModels         []string                 `json:"allowed_models,omitempty"`
+	Parent                *APIKeyParentState       `json:"parent,omitempty"`
+	User                  APIKeyAuthUserSnapshot   `json:"user"`
+	Group                 *APIKeyAuthGroupSnapshot `json:"group,omitempty"`
 
 	// Quota fields for API Key independent quota feature
 	Quota     float64 `json:"quota"`      // Quota limit in USD (0 = unlimited)
@@ -50,6 +64,9 @@ type APIKeyAuthUserSnapshot struct {
 	// UserGroupRPMOverride 该 API Key 对应的 (user, group) 专属 RPM 覆盖值。
 	// nil = 无 override（回退到 group/user 级）；0 = 不限流；>0 = 专属上限。
 	UserGroupRPMOverride *int `json:"user_group_rpm_override,omitempty"`
+
+	// OrganizationID 所属组织；用于 billing_cache_service 的组织预算校验。
+	OrganizationID *int64 `json:"organization_id,omitempty"`
 }
 
 // APIKeyAuthGroupSnapshot 分组快照
@@ -86,6 +103,31 @@ type APIKeyAuthGroupSnapshot struct {
 
 	// RPMLimit 分组级每分钟请求数上限（0 = 不限制）；用于 billing_cache_service.checkRPM 级联判断。
 	RPMLimit int `json:"rpm_limit"`
+
+	// Priority 并发等待队列优先级；API Key 未单独设置时继承该值。
+	Priority string `json:"priority,omitempty"`
+
+	// RequestParamOverrides 请求参数策略，网关热路径据此改写请求体。
+	RequestParamOverrides RequestParamOverrides `json:"request_param_overrides,omitempty"`
+
+	// SystemPrompt / SystemPromptMode 分组系统提示词及注入位置。
+	SystemPrompt     string `json:"system_prompt,omitempty"`
+	SystemPromptMode string `json:"system_prompt_mode,omitempty"`
+
+	// PIIRedactionMode 敏感信息过滤策略（空/mask/reject）。
+	PIIRedactionMode string `json:"pii_redaction_mode,omitempty"`
+
+	// ModerationEnabled 是否启用内容审核；API Key 未单独设置时继承该值。
+	ModerationEnabled bool `json:"moderation_enabled,omitempty"`
+
+	// MaxBodySize 分组请求体上限（字节），0 表示使用端点类别默认值。
+	MaxBodySize int64 `json:"max_body_size,omitempty"`
+
+	// ResponseHeaders 自定义响应头，网关写响应时注入静态头并放行额外的上游头。
+	ResponseHeaders GroupResponseHeaders `json:"response_headers,omitempty"`
+
+	// AccountTagSelector 账号标签选择器，调度时过滤候选账号。
+	AccountTagSelector AccountTagSelector `json:"account_tag_selector,omitempty"`
 }
 
 // APIKeyAuthCacheEntry 缓存条目，支持负缓存
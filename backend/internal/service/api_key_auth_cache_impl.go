@@ -14,7 +14,7 @@ import (
 	"github.com/dgraph-io/ristretto"
 )
 
-const apiKeyAuthSnapshotVersion = 7 // v7: added UserGroupRPMOverride on user snapshot
+const apiKeyAuthSnapshotVersion = 24 // v24: added Parent (child token parent state)
 
 type apiKeyAuthCacheConfig struct {
 	l1Size        int
@@ -176,6 +176,10 @@ func (s *APIKeyService) loadAuthCacheEntry(ctx context.Context, key, cacheKey st
 		return nil, fmt.Errorf("get api key: %w", err)
 	}
 	apiKey.Key = key
+	if apiKey.IsChildToken() {
+		// 父 Key 状态随快照缓存，父 Key 变更时通过 InvalidateAuthCacheByKey 级联失效
+		apiKey.ParentState, _ = s.loadChildTokenParentState(ctx, apiKey.ParentID)
+	}
 	snapshot := s.snapshotFromAPIKey(ctx, apiKey)
 	if snapshot == nil {
 		return nil, fmt.Errorf("get api key: %w", ErrAPIKeyNotFound)
@@ -206,19 +210,33 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 		return nil
 	}
 	snapshot := &APIKeyAuthSnapshot{
-		Version:     apiKeyAuthSnapshotVersion,
-		APIKeyID:    apiKey.ID,
-		UserID:      apiKey.UserID,
-		GroupID:     apiKey.GroupID,
-		Status:      apiKey.Status,
-		IPWhitelist: apiKey.IPWhitelist,
-		IPBlacklist: apiKey.IPBlacklist,
-		Quota:       apiKey.Quota,
-		QuotaUsed:   apiKey.QuotaUsed,
-		ExpiresAt:   apiKey.ExpiresAt,
-		RateLimit5h: apiKey.RateLimit5h,
-		RateLimit1d: apiKey.RateLimit1d,
-		RateLimit7d: apiKey.RateLimit7d,
+		Version:               apiKeyAuthSnapshotVersion,
+		APIKeyID:              apiKey.ID,
+		UserID:                apiKey.UserID,
+		GroupID:               apiKey.GroupID,
+		Status:                apiKey.Status,
+		IPWhitelist:           apiKey.IPWhitelist,
+		IPBlacklist:           apiKey.IPBlacklist,
+		Scopes:                apiKey.Scopes,
+		Priority:              apiKey.Priority,
+		ModerationMode:        apiKey.ModerationMode,
+		ContextAutoTrim:       apiKey.ContextAutoTrim,
+		MaxBodySize:           apiKey.MaxBodySize,
+		RequestCoalescing:     apiKey.RequestCoalescing,
+		TokenBucketBurst:      apiKey.TokenBucketBurst,
+		TokenBucketRefillRate: apiKey.TokenBucketRefillRate,
+		MaxCostPerRequest:     apiKey.MaxCostPerRequest,
+		AccountTagSelector:    apiKey.AccountTagSelector,
+		AllowedPlatforms:      apiKey.AllowedPlatforms,
+		ParentID:              apiKey.ParentID,
+		AllowedModels:         apiKey.AllowedModels,
+		Parent:                apiKey.ParentState,
+		Quota:                 apiKey.Quota,
+		QuotaUsed:             apiKey.QuotaUsed,
+		ExpiresAt:             apiKey.ExpiresAt,
+		RateLimit5h:           apiKey.RateLimit5h,
+		RateLimit1d:           apiKey.RateLimit1d,
+		RateLimit7d:           apiKey.RateLimit7d,
 		User: APIKeyAuthUserSnapshot{
 			ID:                         apiKey.User.ID,
 			Status:                     apiKey.User.Status,
@@ -233,6 +251,7 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 			BalanceNotifyExtraEmails:   apiKey.User.BalanceNotifyExtraEmails,
 			TotalRecharged:             apiKey.User.TotalRecharged,
 			RPMLimit:                   apiKey.User.RPMLimit,
+			OrganizationID:             apiKey.User.OrganizationID,
 		},
 	}
 
@@ -269,6 +288,15 @@ func (s *APIKeyService) snapshotFromAPIKey(ctx context.Context, apiKey *APIKey)
 			DefaultMappedModel:              apiKey.Group.DefaultMappedModel,
 			MessagesDispatchModelConfig:     apiKey.Group.MessagesDispatchModelConfig,
 			RPMLimit:                        apiKey.Group.RPMLimit,
+			Priority:                        apiKey.Group.Priority,
+			RequestParamOverrides:           apiKey.Group.RequestParamOverrides,
+			SystemPrompt:                    apiKey.Group.SystemPrompt,
+			SystemPromptMode:                apiKey.Group.SystemPromptMode,
+			PIIRedactionMode:                apiKey.Group.PIIRedactionMode,
+			ModerationEnabled:               apiKey.Group.ModerationEnabled,
+			MaxBodySize:                     apiKey.Group.MaxBodySize,
+			ResponseHeaders:                 apiKey.Group.ResponseHeaders,
+			AccountTagSelector:              apiKey.Group.AccountTagSelector,
 		}
 	}
 	return snapshot
@@ -279,19 +307,33 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 		return nil
 	}
 	apiKey := &APIKey{
-		ID:          snapshot.APIKeyID,
-		UserID:      snapshot.UserID,
-		GroupID:     snapshot.GroupID,
-		Key:         key,
-		Status:      snapshot.Status,
-		IPWhitelist: snapshot.IPWhitelist,
-		IPBlacklist: snapshot.IPBlacklist,
-		Quota:       snapshot.Quota,
-		QuotaUsed:   snapshot.QuotaUsed,
-		ExpiresAt:   snapshot.ExpiresAt,
-		RateLimit5h: snapshot.RateLimit5h,
-		RateLimit1d: snapshot.RateLimit1d,
-		RateLimit7d: snapshot.RateLimit7d,
+		ID:                    snapshot.APIKeyID,
+		UserID:                snapshot.UserID,
+		GroupID:               snapshot.GroupID,
+		Key:                   key,
+		Status:                snapshot.Status,
+		IPWhitelist:           snapshot.IPWhitelist,
+		IPBlacklist:           snapshot.IPBlacklist,
+		Scopes:                snapshot.Scopes,
+		Priority:              snapshot.Priority,
+		ModerationMode:        snapshot.ModerationMode,
+		ContextAutoTrim:       snapshot.ContextAutoTrim,
+		MaxBodySize:           snapshot.MaxBodySize,
+		RequestCoalescing:     snapshot.RequestCoalescing,
+		TokenBucketBurst:      snapshot.TokenBucketBurst,
+		TokenBucketRefillRate: snapshot.TokenBucketRefillRate,
+		MaxCostPerRequest:     snapshot.MaxCostPerRequest,
+		AccountTagSelector:    snapshot.AccountTagSelector,
+		AllowedPlatforms:      snapshot.AllowedPlatforms,
+		ParentID:              snapshot.ParentID,
+		AllowedModels:         snapshot.AllowedModels,
+		ParentState:           snapshot.Parent,
+		Quota:                 snapshot.Quota,
+		QuotaUsed:             snapshot.QuotaUsed,
+		ExpiresAt:             snapshot.ExpiresAt,
+		RateLimit5h:           snapshot.RateLimit5h,
+		RateLimit1d:           snapshot.RateLimit1d,
+		RateLimit7d:           snapshot.RateLimit7d,
 		User: &User{
 			ID:                         snapshot.User.ID,
 			Status:                     snapshot.User.Status,
@@ -307,6 +349,7 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			TotalRecharged:             snapshot.User.TotalRecharged,
 			RPMLimit:                   snapshot.User.RPMLimit,
 			UserGroupRPMOverride:       snapshot.User.UserGroupRPMOverride,
+			OrganizationID:             snapshot.User.OrganizationID,
 		},
 	}
 	if snapshot.Group != nil {
@@ -335,6 +378,15 @@ func (s *APIKeyService) snapshotToAPIKey(key string, snapshot *APIKeyAuthSnapsho
 			DefaultMappedModel:              snapshot.Group.DefaultMappedModel,
 			MessagesDispatchModelConfig:     snapshot.Group.MessagesDispatchModelConfig,
 			RPMLimit:                        snapshot.Group.RPMLimit,
+			Priority:                        snapshot.Group.Priority,
+			RequestParamOverrides:           snapshot.Group.RequestParamOverrides,
+			SystemPrompt:                    snapshot.Group.SystemPrompt,
+			SystemPromptMode:                snapshot.Group.SystemPromptMode,
+			PIIRedactionMode:                snapshot.Group.PIIRedactionMode,
+			ModerationEnabled:               snapshot.Group.ModerationEnabled,
+			MaxBodySize:                     snapshot.Group.MaxBodySize,
+			ResponseHeaders:                 snapshot.Group.ResponseHeaders,
+			AccountTagSelector:              snapshot.Group.AccountTagSelector,
 		}
 	}
 	s.compileAPIKeyIPRules(apiKey)
@@ -9,6 +9,16 @@ func (s *APIKeyService) InvalidateAuthCacheByKey(ctx context.Context, key string
 	}
 	cacheKey := s.authCacheKey(key)
 	s.deleteAuthCache(ctx, cacheKey)
+
+	// 子令牌快照缓存了父 Key 状态，父 Key 变更时一并失效
+	if s.apiKeyRepo == nil {
+		return
+	}
+	childKeys, err := s.apiKeyRepo.ListKeysByParentKey(ctx, key)
+	if err != nil {
+		return
+	}
+	s.deleteAuthCacheByKeys(ctx, childKeys)
 }
 
 // InvalidateAuthCacheByUserID 清除用户相关的 API Key 认证缓存
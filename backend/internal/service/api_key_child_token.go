@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 子令牌（child token）：由 API Key 通过 POST /v1/tokens 签发的短期 Key。
+//
+// 子令牌是一条普通的 api_keys 记录（parent_id 指向父 Key），继承父 Key 的用户、分组与各项限制，
+// 并在此基础上附加有效期（TTL）、模型白名单与预算切片（quota）：
+//   - 父 Key 设置了总额度时，预算必须在父 Key 剩余额度内，签发时从父 Key 额度中预留，撤销时退还未用部分
+//   - 过期的子令牌由 ChildTokenExpiryService 周期删除，未用预算同样退还父 Key
+//   - 认证时校验父 Key 仍然有效（未删除/禁用/过期），父 Key 状态随子令牌认证快照缓存，父 Key 失效后子令牌随之失效
+//   - 子令牌不能再签发子令牌
+const (
+	// DefaultChildTokenTTL 未指定 ttl_seconds 时的默认有效期
+	DefaultChildTokenTTL = time.Hour
+	// MinChildTokenTTL 子令牌最短有效期
+	MinChildTokenTTL = time.Minute
+	// MaxChildTokenTTL 子令牌最长有效期
+	MaxChildTokenTTL = 7 * 24 * time.Hour
+	// MaxChildTokenAllowedModels 模型白名单最大条目数
+	MaxChildTokenAllowedModels = 50
+	// defaultChildTokenName 未指定名称时使用的子令牌名称
+	defaultChildTokenName = "child token"
+)
+
+var (
+	// ErrChildTokenNestingNotAllowed 子令牌不能再签发子令牌
+	ErrChildTokenNestingNotAllowed = infraerrors.Forbidden("CHILD_TOKEN_NESTING_NOT_ALLOWED", "child tokens cannot mint further tokens")
+	// ErrInvalidChildTokenTTL 有效期不在允许范围内
+	ErrInvalidChildTokenTTL = infraerrors.BadRequest("INVALID_CHILD_TOKEN_TTL", "ttl_seconds must be between 60 and 604800")
+	// ErrInvalidChildTokenName 名称超过长度限制
+	ErrInvalidChildTokenName = infraerrors.BadRequest("INVALID_CHILD_TOKEN_NAME", "name must be at most 100 characters")
+	// ErrInvalidChildTokenBudget 预算不合法
+	ErrInvalidChildTokenBudget = infraerrors.BadRequest("INVALID_CHILD_TOKEN_BUDGET", "budget must be a non-negative number")
+	// ErrChildTokenBudgetRequired 父 Key 设置了总额度时子令牌必须指定预算
+	ErrChildTokenBudgetRequired = infraerrors.BadRequest("CHILD_TOKEN_BUDGET_REQUIRED", "budget is required when the parent key has a quota")
+	// ErrChildTokenBudgetExceeded 预算超过父 Key 剩余额度
+	ErrChildTokenBudgetExceeded = infraerrors.BadRequest("CHILD_TOKEN_BUDGET_EXCEEDED", "budget exceeds the remaining quota of the parent key")
+	// ErrInvalidChildTokenModels 模型白名单不合法或超出父 Key 允许的模型
+	ErrInvalidChildTokenModels = infraerrors.BadRequest("INVALID_CHILD_TOKEN_MODELS", "invalid allowed_models")
+	// ErrChildTokenNotFound 子令牌不存在或不属于当前 Key
+	ErrChildTokenNotFound = infraerrors.NotFound("CHILD_TOKEN_NOT_FOUND", "token not found")
+	// ErrParentAPIKeyInvalid 子令牌的父 Key 已删除、禁用或过期
+	ErrParentAPIKeyInvalid = infraerrors.Unauthorized("PARENT_API_KEY_INVALID", "parent api key is no longer valid")
+	// ErrModelNotAllowedForAPIKey 请求的模型不在 API Key 的模型白名单内
+	ErrModelNotAllowedForAPIKey = infraerrors.Forbidden("MODEL_NOT_ALLOWED", "model is not allowed for this api key")
+)
+
+// APIKeyParentState 子令牌认证所需的父 Key 状态，随子令牌的认证快照一起缓存
+type APIKeyParentState struct {
+	Deleted   bool       `json:"deleted,omitempty"`
+	UserID    int64      `json:"user_id"`
+	Status    string     `json:"status"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateChildTokenRequest 签发子令牌请求
+type CreateChildTokenRequest struct {
+	Name          string
+	TTLSeconds    int
+	AllowedModels []string
+	Budget        float64
+}
+
+// IsChildToken 是否为子令牌
+func (k *APIKey) IsChildToken() bool {
+	return k != nil && k.ParentID > 0
+}
+
+// IsModelAllowed 判断模型是否在 Key 的模型白名单内；未配置白名单时不受限。
+func (k *APIKey) IsModelAllowed(model string) bool {
+	if k == nil {
+		return false
+	}
+	return len(k.AllowedModels) == 0 || matchModelWhitelist(model, k.AllowedModels)
+}
+
+// CheckModelAllowed 转发前校验请求模型，模型未知（如请求体缺少 model）时交由后续校验处理。
+func (k *APIKey) CheckModelAllowed(model string) error {
+	if k == nil || len(k.AllowedModels) == 0 || strings.TrimSpace(model) == "" {
+		return nil
+	}
+	if !k.IsModelAllowed(model) {
+		return ErrModelNotAllowedForAPIKey
+	}
+	return nil
+}
+
+// NormalizeAPIKeyAllowedModels 去除空白、去重并排序；仅支持精确匹配与末尾 * 通配。
+func NormalizeAPIKeyAllowedModels(models []string) (normalized []string, invalid []string) {
+	seen := make(map[string]struct{}, len(models))
+	for _, raw := range models {
+		model := strings.TrimSpace(raw)
+		if model == "" {
+			continue
+		}
+		if model == "*" || strings.Contains(strings.TrimSuffix(model, "*"), "*") {
+			invalid = append(invalid, raw)
+			continue
+		}
+		if _, ok := seen[model]; ok {
+			continue
+		}
+		seen[model] = struct{}{}
+		normalized = append(normalized, model)
+	}
+	sort.Strings(normalized)
+	return normalized, invalid
+}
+
+// resolveChildTokenModels 校验子令牌模型白名单：父 Key 已限制模型时，子令牌只能进一步收窄，未指定则继承父 Key。
+func resolveChildTokenModels(parent *APIKey, requested []string) ([]string, error) {
+	models, invalid := NormalizeAPIKeyAllowedModels(requested)
+	if len(invalid) > 0 {
+		return nil, infraerrors.BadRequest(ErrInvalidChildTokenModels.Reason, fmt.Sprintf("invalid allowed_models: %v", invalid))
+	}
+	if len(models) > MaxChildTokenAllowedModels {
+		return nil, infraerrors.BadRequest(ErrInvalidChildTokenModels.Reason, fmt.Sprintf("allowed_models supports at most %d entries", MaxChildTokenAllowedModels))
+	}
+	if len(parent.AllowedModels) == 0 {
+		return models, nil
+	}
+	if len(models) == 0 {
+		return append([]string(nil), parent.AllowedModels...), nil
+	}
+	var outside []string
+	for _, model := range models {
+		if !matchModelWhitelist(model, parent.AllowedModels) {
+			outside = append(outside, model)
+		}
+	}
+	if len(outside) > 0 {
+		return nil, infraerrors.BadRequest(ErrInvalidChildTokenModels.Reason, fmt.Sprintf("allowed_models not allowed by parent key: %v", outside))
+	}
+	return models, nil
+}
+
+// resolveChildTokenTTL 校验有效期，0 表示使用默认值
+func resolveChildTokenTTL(ttlSeconds int) (time.Duration, error) {
+	if ttlSeconds == 0 {
+		return DefaultChildTokenTTL, nil
+	}
+	ttl := time.Duration(ttlSeconds) * time.Second
+	if ttl < MinChildTokenTTL || ttl > MaxChildTokenTTL {
+		return 0, ErrInvalidChildTokenTTL
+	}
+	return ttl, nil
+}
+
+// CreateChildToken 由父 Key 签发子令牌。parent 为认证上下文中的 Key，额度以数据库最新值为准。
+func (s *APIKeyService) CreateChildToken(ctx context.Context, parent *APIKey, req CreateChildTokenRequest) (*APIKey, error) {
+	if parent == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	if parent.IsChildToken() {
+		return nil, ErrChildTokenNestingNotAllowed
+	}
+	ttl, err := resolveChildTokenTTL(req.TTLSeconds)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsNaN(req.Budget) || math.IsInf(req.Budget, 0) || req.Budget < 0 {
+		return nil, ErrInvalidChildTokenBudget
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = defaultChildTokenName
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		return nil, ErrInvalidChildTokenName
+	}
+
+	// 认证缓存中的 quota_used 可能滞后，预算校验读取最新记录
+	current, err := s.apiKeyRepo.GetByID(ctx, parent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get parent api key: %w", err)
+	}
+	if current.UserID != parent.UserID {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err := s.CheckAPIKeyQuotaAndExpiry(current); err != nil {
+		return nil, err
+	}
+
+	models, err := resolveChildTokenModels(current, req.AllowedModels)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := 0.0
+	if current.Quota > 0 {
+		if req.Budget <= 0 {
+			return nil, ErrChildTokenBudgetRequired
+		}
+		reserved = req.Budget
+	}
+
+	key, err := s.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	if current.ExpiresAt != nil && current.ExpiresAt.Before(expiresAt) {
+		expiresAt = *current.ExpiresAt
+	}
+
+	child := &APIKey{
+		UserID:                current.UserID,
+		Key:                   key,
+		Name:                  name,
+		GroupID:               current.GroupID,
+		Status:                StatusActive,
+		IPWhitelist:           current.IPWhitelist,
+		IPBlacklist:           current.IPBlacklist,
+		Scopes:                current.Scopes,
+		Priority:              current.Priority,
+		ModerationMode:        current.ModerationMode,
+		ContextAutoTrim:       current.ContextAutoTrim,
+		MaxBodySize:           current.MaxBodySize,
+		RequestCoalescing:     current.RequestCoalescing,
+		TokenBucketBurst:      current.TokenBucketBurst,
+		TokenBucketRefillRate: current.TokenBucketRefillRate,
+		MaxCostPerRequest:     current.MaxCostPerRequest,
+		AccountTagSelector:    current.AccountTagSelector,
+		AllowedPlatforms:      current.AllowedPlatforms,
+		ParentID:              current.ID,
+		AllowedModels:         models,
+		Quota:                 req.Budget,
+		ExpiresAt:             &expiresAt,
+		RateLimit5h:           current.RateLimit5h,
+		RateLimit1d:           current.RateLimit1d,
+		RateLimit7d:           current.RateLimit7d,
+	}
+
+	// 先从父 Key 预留预算（单条条件更新，并发签发不会超额），创建失败时退还
+	if reserved > 0 {
+		ok, err := s.apiKeyRepo.ReserveQuota(ctx, current.ID, reserved)
+		if err != nil {
+			return nil, fmt.Errorf("reserve parent quota: %w", err)
+		}
+		if !ok {
+			return nil, ErrChildTokenBudgetExceeded
+		}
+		s.InvalidateAuthCacheByKey(ctx, current.Key)
+	}
+	if err := s.apiKeyRepo.Create(ctx, child); err != nil {
+		if reserved > 0 {
+			_ = s.refundParentQuota(ctx, current.ID, reserved)
+		}
+		return nil, fmt.Errorf("create child token: %w", err)
+	}
+
+	s.InvalidateAuthCacheByKey(ctx, child.Key)
+	s.compileAPIKeyIPRules(child)
+	return child, nil
+}
+
+// ListChildTokens 列出父 Key 签发的子令牌
+func (s *APIKeyService) ListChildTokens(ctx context.Context, parentID int64) ([]APIKey, error) {
+	tokens, err := s.apiKeyRepo.ListByParentID(ctx, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("list child tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeChildToken 撤销子令牌，并将未使用的预算退还给父 Key
+func (s *APIKeyService) RevokeChildToken(ctx context.Context, parentID, tokenID int64) error {
+	token, err := s.apiKeyRepo.GetByID(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return ErrChildTokenNotFound
+		}
+		return fmt.Errorf("get child token: %w", err)
+	}
+	if token.ParentID != parentID {
+		return ErrChildTokenNotFound
+	}
+
+	return s.deleteChildToken(ctx, token)
+}
+
+// SweepExpiredChildTokens 删除已过期的子令牌并将未使用的预算退还给父 Key，返回删除数量。
+// 每轮最多处理 limit 个，由 ChildTokenExpiryService 周期调用。
+func (s *APIKeyService) SweepExpiredChildTokens(ctx context.Context, now time.Time, limit int) (int, error) {
+	tokens, err := s.apiKeyRepo.ListExpiredChildTokens(ctx, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("list expired child tokens: %w", err)
+	}
+	swept := 0
+	for i := range tokens {
+		if err := s.deleteChildToken(ctx, &tokens[i]); err != nil {
+			if errors.Is(err, ErrChildTokenNotFound) {
+				continue
+			}
+			return swept, err
+		}
+		swept++
+	}
+	return swept, nil
+}
+
+// deleteChildToken 删除子令牌并退还未使用的预算；删除与预算读取在同一条件更新中完成，
+// 并发的撤销与过期清理只有一方退还预算。
+func (s *APIKeyService) deleteChildToken(ctx context.Context, token *APIKey) error {
+	s.InvalidateAuthCacheByKey(ctx, token.Key)
+	unused, err := s.apiKeyRepo.DeleteChildToken(ctx, token.ID)
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return ErrChildTokenNotFound
+		}
+		return fmt.Errorf("delete child token: %w", err)
+	}
+	s.lastUsedTouchL1.Delete(token.ID)
+
+	if unused > 0 {
+		if err := s.refundParentQuota(ctx, token.ParentID, unused); err != nil && !errors.Is(err, ErrAPIKeyNotFound) {
+			return fmt.Errorf("refund parent quota: %w", err)
+		}
+	}
+	return nil
+}
+
+// refundParentQuota 退还预留的预算；父 Key 因预留而额度耗尽时恢复为 active
+func (s *APIKeyService) refundParentQuota(ctx context.Context, parentID int64, amount float64) error {
+	if _, err := s.apiKeyRepo.IncrementQuotaUsed(ctx, parentID, -amount); err != nil {
+		return err
+	}
+	parent, err := s.apiKeyRepo.GetByID(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	if parent.Status == StatusAPIKeyQuotaExhausted && !parent.IsQuotaExhausted() {
+		parent.Status = StatusActive
+		if err := s.apiKeyRepo.Update(ctx, parent); err != nil {
+			return err
+		}
+	}
+	s.InvalidateAuthCacheByKey(ctx, parent.Key)
+	return nil
+}
+
+// ValidateChildTokenParent 认证时校验子令牌的父 Key 仍然有效。
+// 父 Key 状态优先取认证快照中的缓存，缓存缺失时回源查询。
+// 父 Key 因签发预算而额度耗尽时子令牌仍可使用（额度已预留到子令牌），因此仅拦截删除/禁用/过期。
+func (s *APIKeyService) ValidateChildTokenParent(ctx context.Context, token *APIKey) error {
+	if !token.IsChildToken() {
+		return nil
+	}
+	parent := token.ParentState
+	if parent == nil {
+		state, err := s.loadChildTokenParentState(ctx, token.ParentID)
+		if err != nil {
+			return fmt.Errorf("get parent api key: %w", err)
+		}
+		parent = state
+	}
+	if parent.Deleted || parent.UserID != token.UserID {
+		return ErrParentAPIKeyInvalid
+	}
+	if parent.ExpiresAt != nil && time.Now().After(*parent.ExpiresAt) {
+		return ErrParentAPIKeyInvalid
+	}
+	if parent.Status != StatusActive && parent.Status != StatusAPIKeyQuotaExhausted {
+		return ErrParentAPIKeyInvalid
+	}
+	return nil
+}
+
+// loadChildTokenParentState 读取父 Key 状态，父 Key 不存在时返回 Deleted 状态
+func (s *APIKeyService) loadChildTokenParentState(ctx context.Context, parentID int64) (*APIKeyParentState, error) {
+	parent, err := s.apiKeyRepo.GetByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return &APIKeyParentState{Deleted: true}, nil
+		}
+		return nil, err
+	}
+	return &APIKeyParentState{
+		UserID:    parent.UserID,
+		Status:    parent.Status,
+		ExpiresAt: parent.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,263 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type childTokenRepoStub struct {
+	quotaBaseAPIKeyRepoStub
+	keys    map[int64]*APIKey
+	nextID  int64
+	deleted []int64
+}
+
+func newChildTokenRepoStub(keys ...*APIKey) *childTokenRepoStub {
+	repo := &childTokenRepoStub{keys: make(map[int64]*APIKey), nextID: 100}
+	for _, k := range keys {
+		repo.keys[k.ID] = k
+	}
+	return repo
+}
+
+func (s *childTokenRepoStub) Create(_ context.Context, key *APIKey) error {
+	s.nextID++
+	key.ID = s.nextID
+	clone := *key
+	s.keys[key.ID] = &clone
+	return nil
+}
+
+func (s *childTokenRepoStub) GetByID(_ context.Context, id int64) (*APIKey, error) {
+	s.getByIDCalls++
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+	clone := *key
+	return &clone, nil
+}
+
+func (s *childTokenRepoStub) Update(_ context.Context, key *APIKey) error {
+	clone := *key
+	s.keys[key.ID] = &clone
+	return nil
+}
+
+func (s *childTokenRepoStub) Delete(_ context.Context, id int64) error {
+	delete(s.keys, id)
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func (s *childTokenRepoStub) IncrementQuotaUsed(_ context.Context, id int64, amount float64) (float64, error) {
+	key, ok := s.keys[id]
+	if !ok {
+		return 0, ErrAPIKeyNotFound
+	}
+	key.QuotaUsed += amount
+	return key.QuotaUsed, nil
+}
+
+func (s *childTokenRepoStub) ReserveQuota(_ context.Context, id int64, amount float64) (bool, error) {
+	key, ok := s.keys[id]
+	if !ok || key.Quota <= 0 || key.Quota-key.QuotaUsed < amount {
+		return false, nil
+	}
+	key.QuotaUsed += amount
+	if key.QuotaUsed >= key.Quota {
+		key.Status = StatusAPIKeyQuotaExhausted
+	}
+	return true, nil
+}
+
+func (s *childTokenRepoStub) DeleteChildToken(_ context.Context, id int64) (float64, error) {
+	key, ok := s.keys[id]
+	if !ok || key.ParentID == 0 {
+		return 0, ErrAPIKeyNotFound
+	}
+	delete(s.keys, id)
+	s.deleted = append(s.deleted, id)
+	return math.Max(key.Quota-key.QuotaUsed, 0), nil
+}
+
+func (s *childTokenRepoStub) ListExpiredChildTokens(_ context.Context, now time.Time, limit int) ([]APIKey, error) {
+	var out []APIKey
+	for _, key := range s.keys {
+		if key.ParentID > 0 && key.ExpiresAt != nil && !key.ExpiresAt.After(now) && len(out) < limit {
+			out = append(out, *key)
+		}
+	}
+	return out, nil
+}
+
+func (s *childTokenRepoStub) ListKeysByParentKey(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
+func newChildTokenService(repo *childTokenRepoStub) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: repo, cache: &quotaStateCacheStub{}, cfg: &config.Config{}}
+}
+
+func TestCreateChildToken_ReservesBudgetAndInheritsParent(t *testing.T) {
+	groupID := int64(7)
+	parent := &APIKey{ID: 1, UserID: 9, Key: "sk-parent", GroupID: &groupID, Status: StatusActive, Quota: 10, QuotaUsed: 4, Scopes: []string{APIKeyScopeChat, APIKeyScopeTokens}}
+	repo := newChildTokenRepoStub(parent)
+	svc := newChildTokenService(repo)
+
+	child, err := svc.CreateChildToken(context.Background(), parent, CreateChildTokenRequest{
+		TTLSeconds:    600,
+		AllowedModels: []string{" claude-sonnet-* ", "claude-sonnet-*"},
+		Budget:        5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), child.ParentID)
+	require.Equal(t, int64(9), child.UserID)
+	require.Equal(t, &groupID, child.GroupID)
+	require.Equal(t, defaultChildTokenName, child.Name)
+	require.Equal(t, []string{"claude-sonnet-*"}, child.AllowedModels)
+	require.Equal(t, 5.0, child.Quota)
+	require.NotNil(t, child.ExpiresAt)
+	require.WithinDuration(t, time.Now().Add(10*time.Minute), *child.ExpiresAt, 5*time.Second)
+	require.Equal(t, 9.0, repo.keys[1].QuotaUsed)
+
+	// 剩余额度 1，预留失败时不修改父 Key
+	_, err = svc.CreateChildToken(context.Background(), parent, CreateChildTokenRequest{Budget: 2})
+	require.ErrorIs(t, err, ErrChildTokenBudgetExceeded)
+	require.Equal(t, 9.0, repo.keys[1].QuotaUsed)
+}
+
+func TestCreateChildToken_Validation(t *testing.T) {
+	parent := &APIKey{ID: 1, UserID: 9, Key: "sk-parent", Status: StatusActive, Quota: 10, QuotaUsed: 8, AllowedModels: []string{"gpt-5*"}}
+
+	tests := []struct {
+		name string
+		key  *APIKey
+		req  CreateChildTokenRequest
+		want error
+	}{
+		{name: "nested", key: &APIKey{ID: 2, UserID: 9, ParentID: 1}, want: ErrChildTokenNestingNotAllowed},
+		{name: "ttl too short", key: parent, req: CreateChildTokenRequest{TTLSeconds: 10, Budget: 1}, want: ErrInvalidChildTokenTTL},
+		{name: "negative budget", key: parent, req: CreateChildTokenRequest{Budget: -1}, want: ErrInvalidChildTokenBudget},
+		{name: "budget required", key: parent, req: CreateChildTokenRequest{}, want: ErrChildTokenBudgetRequired},
+		{name: "budget exceeded", key: parent, req: CreateChildTokenRequest{Budget: 3}, want: ErrChildTokenBudgetExceeded},
+		{name: "model outside parent", key: parent, req: CreateChildTokenRequest{Budget: 1, AllowedModels: []string{"claude-opus-4"}}, want: ErrInvalidChildTokenModels},
+		{name: "bare wildcard", key: parent, req: CreateChildTokenRequest{Budget: 1, AllowedModels: []string{"*"}}, want: ErrInvalidChildTokenModels},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newChildTokenRepoStub(&APIKey{ID: parent.ID, UserID: parent.UserID, Key: parent.Key, Status: StatusActive, Quota: 10, QuotaUsed: 8, AllowedModels: parent.AllowedModels})
+			_, err := newChildTokenService(repo).CreateChildToken(context.Background(), tt.key, tt.req)
+			require.ErrorIs(t, err, tt.want)
+			require.Equal(t, 8.0, repo.keys[1].QuotaUsed)
+		})
+	}
+}
+
+func TestCreateChildToken_InheritsParentModelsAndExpiry(t *testing.T) {
+	parentExpiry := time.Now().Add(30 * time.Minute)
+	parent := &APIKey{ID: 1, UserID: 9, Key: "sk-parent", Status: StatusActive, AllowedModels: []string{"gpt-5*"}, ExpiresAt: &parentExpiry}
+	svc := newChildTokenService(newChildTokenRepoStub(parent))
+
+	child, err := svc.CreateChildToken(context.Background(), parent, CreateChildTokenRequest{TTLSeconds: 3600})
+	require.NoError(t, err)
+	require.Equal(t, []string{"gpt-5*"}, child.AllowedModels)
+	require.Equal(t, parentExpiry, *child.ExpiresAt)
+	require.Zero(t, child.Quota)
+}
+
+func TestRevokeChildToken_RefundsUnusedBudget(t *testing.T) {
+	parent := &APIKey{ID: 1, UserID: 9, Key: "sk-parent", Status: StatusAPIKeyQuotaExhausted, Quota: 10, QuotaUsed: 10}
+	child := &APIKey{ID: 2, UserID: 9, Key: "sk-child", ParentID: 1, Status: StatusActive, Quota: 6, QuotaUsed: 2}
+	repo := newChildTokenRepoStub(parent, child)
+	svc := newChildTokenService(repo)
+
+	require.ErrorIs(t, svc.RevokeChildToken(context.Background(), 3, 2), ErrChildTokenNotFound)
+
+	require.NoError(t, svc.RevokeChildToken(context.Background(), 1, 2))
+	require.Equal(t, []int64{2}, repo.deleted)
+	require.Equal(t, 6.0, repo.keys[1].QuotaUsed)
+	require.Equal(t, StatusActive, repo.keys[1].Status)
+}
+
+func TestSweepExpiredChildTokens_RefundsUnusedBudget(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	parent := &APIKey{ID: 1, UserID: 9, Key: "sk-parent", Status: StatusAPIKeyQuotaExhausted, Quota: 10, QuotaUsed: 10}
+	expired := &APIKey{ID: 2, UserID: 9, Key: "sk-expired", ParentID: 1, Status: StatusActive, Quota: 6, QuotaUsed: 2, ExpiresAt: &past}
+	overspent := &APIKey{ID: 3, UserID: 9, Key: "sk-overspent", ParentID: 1, Status: StatusActive, Quota: 1, QuotaUsed: 1.5, ExpiresAt: &past}
+	live := &APIKey{ID: 4, UserID: 9, Key: "sk-live", ParentID: 1, Status: StatusActive, Quota: 3, ExpiresAt: &future}
+	repo := newChildTokenRepoStub(parent, expired, overspent, live)
+	svc := newChildTokenService(repo)
+
+	swept, err := svc.SweepExpiredChildTokens(context.Background(), time.Now(), 100)
+	require.NoError(t, err)
+	require.Equal(t, 2, swept)
+	require.ElementsMatch(t, []int64{2, 3}, repo.deleted)
+	require.Contains(t, repo.keys, int64(4))
+	require.Equal(t, 6.0, repo.keys[1].QuotaUsed)
+	require.Equal(t, StatusActive, repo.keys[1].Status)
+
+	// 已被撤销的子令牌不会重复退还
+	require.ErrorIs(t, svc.deleteChildToken(context.Background(), expired), ErrChildTokenNotFound)
+	require.Equal(t, 6.0, repo.keys[1].QuotaUsed)
+}
+
+func TestValidateChildTokenParent_UsesCachedParentState(t *testing.T) {
+	repo := newChildTokenRepoStub()
+	svc := newChildTokenService(repo)
+
+	active := &APIKey{ID: 2, UserID: 9, ParentID: 1, ParentState: &APIKeyParentState{UserID: 9, Status: StatusActive}}
+	require.NoError(t, svc.ValidateChildTokenParent(context.Background(), active))
+	require.Zero(t, repo.getByIDCalls)
+
+	deleted := &APIKey{ID: 2, UserID: 9, ParentID: 1, ParentState: &APIKeyParentState{Deleted: true}}
+	require.ErrorIs(t, svc.ValidateChildTokenParent(context.Background(), deleted), ErrParentAPIKeyInvalid)
+}
+
+func TestValidateChildTokenParent(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name   string
+		parent *APIKey
+		want   error
+	}{
+		{name: "active", parent: &APIKey{ID: 1, UserID: 9, Status: StatusActive}},
+		{name: "quota exhausted by reservations", parent: &APIKey{ID: 1, UserID: 9, Status: StatusAPIKeyQuotaExhausted}},
+		{name: "disabled", parent: &APIKey{ID: 1, UserID: 9, Status: StatusDisabled}, want: ErrParentAPIKeyInvalid},
+		{name: "expired", parent: &APIKey{ID: 1, UserID: 9, Status: StatusActive, ExpiresAt: &past}, want: ErrParentAPIKeyInvalid},
+		{name: "other user", parent: &APIKey{ID: 1, UserID: 10, Status: StatusActive}, want: ErrParentAPIKeyInvalid},
+		{name: "deleted", parent: &APIKey{ID: 5, UserID: 9, Status: StatusActive}, want: ErrParentAPIKeyInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newChildTokenService(newChildTokenRepoStub(tt.parent))
+			err := svc.ValidateChildTokenParent(context.Background(), &APIKey{ID: 2, UserID: 9, ParentID: 1})
+			if tt.want == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	svc := newChildTokenService(newChildTokenRepoStub())
+	require.NoError(t, svc.ValidateChildTokenParent(context.Background(), &APIKey{ID: 3, UserID: 9}))
+}
+
+func TestAPIKeyIsModelAllowed(t *testing.T) {
+	require.True(t, (&APIKey{}).IsModelAllowed("anything"))
+	key := &APIKey{AllowedModels: []string{"claude-sonnet-*", "gpt-5"}}
+	require.True(t, key.IsModelAllowed("claude-sonnet-4-5"))
+	require.True(t, key.IsModelAllowed("gpt-5"))
+	require.False(t, key.IsModelAllowed("gpt-5-mini"))
+	require.ErrorIs(t, key.CheckModelAllowed("claude-opus-4"), ErrModelNotAllowedForAPIKey)
+	require.NoError(t, key.CheckModelAllowed(""))
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// MaxAPIKeyCostPerRequest 单次请求预估费用上限的最大可配置值（USD）
+const MaxAPIKeyCostPerRequest = 1000000
+
+var (
+	// ErrInvalidMaxCostPerRequest API Key 单次请求费用上限配置不合法
+	ErrInvalidMaxCostPerRequest = infraerrors.BadRequest(
+		"INVALID_MAX_COST_PER_REQUEST",
+		"max_cost_per_request must be between 0 and 1000000 (0 = unlimited)",
+	)
+	// ErrRequestCostLimitExceeded 请求预估费用超过 API Key 的单次请求上限
+	ErrRequestCostLimitExceeded = infraerrors.BadRequest(
+		"REQUEST_COST_LIMIT_EXCEEDED",
+		"estimated request cost exceeds the api key max_cost_per_request limit",
+	)
+)
+
+// ValidateMaxCostPerRequest 校验单次请求费用上限：0 表示不限制。
+func ValidateMaxCostPerRequest(maxCost float64) error {
+	if math.IsNaN(maxCost) || maxCost < 0 || maxCost > MaxAPIKeyCostPerRequest {
+		return ErrInvalidMaxCostPerRequest
+	}
+	return nil
+}
+
+// CheckRequestCostLimit 转发前按「提示词 token 估算 + 请求声明的 max_tokens」预估本次请求费用，
+// 可能超过 API Key 的 max_cost_per_request 时拒绝。模型无定价信息时放行（无法估算）。
+func (s *GatewayService) CheckRequestCostLimit(ctx context.Context, apiKey *APIKey, model string, body []byte) error {
+	if s == nil || apiKey == nil || apiKey.MaxCostPerRequest <= 0 {
+		return nil
+	}
+	inputTokens, outputTokens := estimateRequestCostTokens(body)
+	result := &ForwardResult{
+		Model: model,
+		Usage: ClaudeUsage{
+			InputTokens:  inputTokens,
+			OutputTokens: outputTokens,
+		},
+	}
+	cost := s.calculateTokenCost(ctx, result, apiKey, model, s.resolveAPIKeyRateMultiplier(ctx, apiKey), &recordUsageOpts{})
+	if cost == nil {
+		return nil
+	}
+	return checkRequestCostLimit(apiKey, cost.ActualCost, inputTokens, outputTokens)
+}
+
+// CheckRequestCostLimit OpenAI 网关的单次请求费用上限检查，语义同 GatewayService.CheckRequestCostLimit。
+func (s *OpenAIGatewayService) CheckRequestCostLimit(ctx context.Context, apiKey *APIKey, model string, body []byte) error {
+	if s == nil || apiKey == nil || apiKey.MaxCostPerRequest <= 0 {
+		return nil
+	}
+	inputTokens, outputTokens := estimateRequestCostTokens(body)
+	tokens := UsageTokens{
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+	}
+	cost, err := s.calculateOpenAIRecordUsageCost(ctx, nil, apiKey, model, s.resolveAPIKeyRateMultiplier(ctx, apiKey), tokens, "")
+	if err != nil || cost == nil {
+		return nil
+	}
+	return checkRequestCostLimit(apiKey, cost.ActualCost, inputTokens, outputTokens)
+}
+
+// estimateRequestCostTokens 估算请求的输入 token（按请求体文本）与输出 token 上限（按 max_tokens 等字段）。
+func estimateRequestCostTokens(body []byte) (int, int) {
+	return estimateTokensForText(string(body)), estimateRoutePreviewOutputTokens(body)
+}
+
+func checkRequestCostLimit(apiKey *APIKey, estimatedCost float64, inputTokens, outputTokens int) error {
+	if estimatedCost <= apiKey.MaxCostPerRequest {
+		return nil
+	}
+	return infraerrors.Newf(
+		int(ErrRequestCostLimitExceeded.Code),
+		ErrRequestCostLimitExceeded.Reason,
+		"estimated request cost $%.6f (input ~%d tokens, max output %d tokens) exceeds the api key limit of $%.6f per request; reduce max_tokens or the prompt size",
+		estimatedCost, inputTokens, outputTokens, apiKey.MaxCostPerRequest,
+	).WithMetadata(map[string]string{
+		"estimated_cost":          strconv.FormatFloat(estimatedCost, 'f', 8, 64),
+		"max_cost_per_request":    strconv.FormatFloat(apiKey.MaxCostPerRequest, 'f', 8, 64),
+		"estimated_input_tokens":  strconv.Itoa(inputTokens),
+		"estimated_output_tokens": strconv.Itoa(outputTokens),
+	})
+}
@@ -0,0 +1,41 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatewayServiceCheckRequestCostLimit(t *testing.T) {
+	svc := &GatewayService{billingService: NewBillingService(&config.Config{}, nil)}
+	body := []byte(`{"model":"claude-sonnet-4","max_tokens":100000,"messages":[{"role":"user","content":"hi"}]}`)
+
+	// 未设置上限时不做估算
+	require.NoError(t, svc.CheckRequestCostLimit(context.Background(), &APIKey{ID: 1}, "claude-sonnet-4", body))
+	require.NoError(t, svc.CheckRequestCostLimit(context.Background(), nil, "claude-sonnet-4", body))
+
+	// 100k 输出 token 按 sonnet 定价约 $1.5，超过 $0.5 上限
+	err := svc.CheckRequestCostLimit(context.Background(), &APIKey{ID: 1, MaxCostPerRequest: 0.5}, "claude-sonnet-4", body)
+	require.ErrorIs(t, err, ErrRequestCostLimitExceeded)
+	require.Equal(t, 400, infraerrors.Code(err))
+	var appErr *infraerrors.ApplicationError
+	require.ErrorAs(t, err, &appErr)
+	require.Equal(t, "100000", appErr.Metadata["estimated_output_tokens"])
+	require.Equal(t, "0.50000000", appErr.Metadata["max_cost_per_request"])
+
+	require.NoError(t, svc.CheckRequestCostLimit(context.Background(), &APIKey{ID: 1, MaxCostPerRequest: 5}, "claude-sonnet-4", body))
+}
+
+func TestValidateMaxCostPerRequest(t *testing.T) {
+	require.NoError(t, ValidateMaxCostPerRequest(0))
+	require.NoError(t, ValidateMaxCostPerRequest(0.25))
+	require.ErrorIs(t, ValidateMaxCostPerRequest(-1), ErrInvalidMaxCostPerRequest)
+	require.ErrorIs(t, ValidateMaxCostPerRequest(math.NaN()), ErrInvalidMaxCostPerRequest)
+	require.ErrorIs(t, ValidateMaxCostPerRequest(MaxAPIKeyCostPerRequest+1), ErrInvalidMaxCostPerRequest)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/openai"
+	"go.uber.org/zap"
+)
+
+// ListAPIKeyModels 返回 API Key 实际可用的模型列表（OpenAI /v1/models 格式）。
+// 合并 Key 分组平台（含混合调度）下各可调度账号的模型集合（model_mapping 中的精确模型名，未配置时取平台默认列表），
+// 再按 Key 的允许平台与模型白名单过滤。forcePlatform 非空时仅列出该平台账号。
+func (s *GatewayService) ListAPIKeyModels(ctx context.Context, apiKey *APIKey, forcePlatform string) []openai.Model {
+	var groupID *int64
+	platform := PlatformAnthropic
+	if apiKey != nil && apiKey.Group != nil {
+		groupID = &apiKey.Group.ID
+		if apiKey.Group.Platform != "" {
+			platform = apiKey.Group.Platform
+		}
+	}
+	if forcePlatform != "" {
+		platform = forcePlatform
+	}
+
+	accounts, _, err := s.listSchedulableAccounts(ctx, groupID, platform, forcePlatform != "")
+	if err != nil {
+		logger.FromContext(ctx).Warn("api_key_models.list_accounts_failed",
+			zap.String("platform", platform),
+			zap.Error(err))
+		return []openai.Model{}
+	}
+
+	seen := make(map[string]struct{})
+	models := make([]openai.Model, 0)
+	for i := range accounts {
+		account := &accounts[i]
+		if apiKey != nil && (!apiKey.AllowsPlatform(account.Platform) || !apiKey.IsPlatformAllowed(account.Platform)) {
+			continue
+		}
+		for _, modelID := range s.aggregatorAccountModels(account) {
+			if _, ok := seen[modelID]; ok {
+				continue
+			}
+			if apiKey != nil && !apiKey.IsModelAllowed(modelID) {
+				continue
+			}
+			seen[modelID] = struct{}{}
+			models = append(models, apiKeyOpenAIModel(modelID, account.Platform))
+		}
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
+
+// apiKeyOpenAIModel 内置 OpenAI 模型沿用其元数据，其余按模型名族推断 owned_by
+func apiKeyOpenAIModel(modelID, platform string) openai.Model {
+	for _, m := range openai.DefaultModels {
+		if strings.EqualFold(m.ID, modelID) {
+			return m
+		}
+	}
+	return openai.Model{
+		ID:          modelID,
+		Object:      "model",
+		OwnedBy:     aggregatorModelOwner(modelID, platform),
+		Type:        "model",
+		DisplayName: modelID,
+	}
+}
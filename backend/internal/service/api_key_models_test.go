@@ -0,0 +1,58 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAPIKeyModels_FiltersByKeyRestrictions(t *testing.T) {
+	repo := &mockAccountRepoForPlatform{
+		accounts: []Account{
+			{
+				ID: 1, Platform: PlatformOpenAI, Status: StatusActive, Schedulable: true,
+				Credentials: map[string]any{
+					"model_mapping": map[string]any{
+						"gpt-5.4":      "gpt-5.4",
+						"my-alias":     "gpt-5.4-mini",
+						"gpt-5-codex*": "gpt-5.3-codex",
+					},
+				},
+			},
+			{
+				ID: 2, Platform: PlatformOpenAI, Status: StatusActive, Schedulable: true,
+				Credentials: map[string]any{"model_mapping": map[string]any{"gpt-5.4": "gpt-5.4"}},
+			},
+		},
+	}
+	svc := &GatewayService{
+		accountRepo:        repo,
+		modelsListCache:    gocache.New(time.Minute, time.Minute),
+		modelsListCacheTTL: time.Minute,
+	}
+	apiKey := &APIKey{Group: &Group{ID: 7, Platform: PlatformOpenAI}}
+
+	models := svc.ListAPIKeyModels(context.Background(), apiKey, "")
+	ids := make([]string, 0, len(models))
+	for _, m := range models {
+		ids = append(ids, m.ID)
+	}
+	// 通配映射不出现在列表中，多账号重复模型只保留一条
+	require.Equal(t, []string{"gpt-5.4", "my-alias"}, ids)
+	require.Equal(t, "GPT-5.4", models[0].DisplayName)
+	require.Equal(t, "model", models[1].Object)
+
+	apiKey.AllowedModels = []string{"my-*"}
+	models = svc.ListAPIKeyModels(context.Background(), apiKey, "")
+	require.Len(t, models, 1)
+	require.Equal(t, "my-alias", models[0].ID)
+
+	apiKey.AllowedModels = nil
+	apiKey.AllowedPlatforms = []string{PlatformAnthropic}
+	require.Empty(t, svc.ListAPIKeyModels(context.Background(), apiKey, ""))
+}
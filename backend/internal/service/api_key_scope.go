@@ -0,0 +1,114 @@
+package service
+
+import (
+	"sort"
+	"strings"
+)
+
+// API Key 权限范围（scopes）。
+//
+// 分为两个相互独立的维度：
+//   - 端点范围：限制 Key 可访问的网关端点类别（chat/images/models/usage/tokens）
+//   - 平台范围：限制 Key 可使用的上游平台（platform:<platform>）
+//
+// 某一维度未配置任何 scope 时视为该维度不受限；Scopes 为空表示完全不受限（兼容旧 Key）。
+const (
+	// APIKeyScopeChat 对话类端点：messages / chat completions / responses / Gemini generateContent 等
+	APIKeyScopeChat = "chat"
+	// APIKeyScopeImages 图片生成/编辑端点
+	APIKeyScopeImages = "images"
+	// APIKeyScopeModels 模型列表端点
+	APIKeyScopeModels = "models"
+	// APIKeyScopeUsage 用量查询端点
+	APIKeyScopeUsage = "usage"
+	// APIKeyScopeTokens 子令牌签发/管理端点（/v1/tokens）
+	APIKeyScopeTokens = "tokens"
+
+	// APIKeyScopePlatformPrefix 平台范围前缀，如 platform:openai
+	APIKeyScopePlatformPrefix = "platform:"
+)
+
+var apiKeyEndpointScopes = map[string]struct{}{
+	APIKeyScopeChat:   {},
+	APIKeyScopeImages: {},
+	APIKeyScopeModels: {},
+	APIKeyScopeUsage:  {},
+	APIKeyScopeTokens: {},
+}
+
+var apiKeyScopePlatforms = map[string]struct{}{
+	PlatformAnthropic:   {},
+	PlatformOpenAI:      {},
+	PlatformGemini:      {},
+	PlatformAntigravity: {},
+	PlatformCustom:      {},
+}
+
+// NormalizeAPIKeyScopes 去除空白、转小写、去重并排序，返回非法的 scope 列表。
+func NormalizeAPIKeyScopes(scopes []string) (normalized []string, invalid []string) {
+	seen := make(map[string]struct{}, len(scopes))
+	for _, raw := range scopes {
+		scope := strings.ToLower(strings.TrimSpace(raw))
+		if scope == "" {
+			continue
+		}
+		if !isValidAPIKeyScope(scope) {
+			invalid = append(invalid, raw)
+			continue
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		normalized = append(normalized, scope)
+	}
+	sort.Strings(normalized)
+	return normalized, invalid
+}
+
+func isValidAPIKeyScope(scope string) bool {
+	if platform, ok := strings.CutPrefix(scope, APIKeyScopePlatformPrefix); ok {
+		_, valid := apiKeyScopePlatforms[platform]
+		return valid
+	}
+	_, valid := apiKeyEndpointScopes[scope]
+	return valid
+}
+
+// HasScope 判断 API Key 是否允许访问指定端点范围。
+func (k *APIKey) HasScope(scope string) bool {
+	if k == nil {
+		return false
+	}
+	restricted := false
+	for _, s := range k.Scopes {
+		if strings.HasPrefix(s, APIKeyScopePlatformPrefix) {
+			continue
+		}
+		if s == scope {
+			return true
+		}
+		restricted = true
+	}
+	return !restricted
+}
+
+// AllowsPlatform 判断 API Key 是否允许使用指定平台。
+// platform 为空（无法确定平台，如未分组 Key）时仅在未配置平台范围时放行。
+func (k *APIKey) AllowsPlatform(platform string) bool {
+	if k == nil {
+		return false
+	}
+	restricted := false
+	for _, s := range k.Scopes {
+		p, ok := strings.CutPrefix(s, APIKeyScopePlatformPrefix)
+		if !ok {
+			continue
+		}
+		if platform != "" && p == platform {
+			return true
+		}
+		restricted = true
+	}
+	return !restricted
+}
@@ -0,0 +1,39 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAPIKeyScopes(t *testing.T) {
+	scopes, invalid := NormalizeAPIKeyScopes([]string{" Chat ", "platform:openai", "chat", "", "admin", "platform:unknown"})
+	require.Equal(t, []string{"chat", "platform:openai"}, scopes)
+	require.Equal(t, []string{"admin", "platform:unknown"}, invalid)
+
+	scopes, invalid = NormalizeAPIKeyScopes(nil)
+	require.Empty(t, scopes)
+	require.Empty(t, invalid)
+}
+
+func TestAPIKey_HasScope(t *testing.T) {
+	unrestricted := &APIKey{}
+	require.True(t, unrestricted.HasScope(APIKeyScopeChat))
+	require.True(t, unrestricted.AllowsPlatform(PlatformOpenAI))
+	require.True(t, unrestricted.AllowsPlatform(""))
+
+	// 仅配置平台范围时，端点维度不受限
+	platformOnly := &APIKey{Scopes: []string{"platform:openai"}}
+	require.True(t, platformOnly.HasScope(APIKeyScopeImages))
+	require.True(t, platformOnly.AllowsPlatform(PlatformOpenAI))
+	require.False(t, platformOnly.AllowsPlatform(PlatformAnthropic))
+	require.False(t, platformOnly.AllowsPlatform(""))
+
+	// 仅配置端点范围时，平台维度不受限
+	modelsOnly := &APIKey{Scopes: []string{APIKeyScopeModels}}
+	require.True(t, modelsOnly.HasScope(APIKeyScopeModels))
+	require.False(t, modelsOnly.HasScope(APIKeyScopeChat))
+	require.True(t, modelsOnly.AllowsPlatform(PlatformGemini))
+}
@@ -27,6 +27,7 @@ var (
 	ErrAPIKeyInvalidChars = infraerrors.BadRequest("API_KEY_INVALID_CHARS", "api key can only contain letters, numbers, underscores, and hyphens")
 	ErrAPIKeyRateLimited  = infraerrors.TooManyRequests("API_KEY_RATE_LIMITED", "too many failed attempts, please try again later")
 	ErrInvalidIPPattern   = infraerrors.BadRequest("INVALID_IP_PATTERN", "invalid IP or CIDR pattern")
+	ErrInvalidAPIKeyScope = infraerrors.BadRequest("INVALID_API_KEY_SCOPE", "invalid api key scope")
 	// ErrAPIKeyExpired        = infraerrors.Forbidden("API_KEY_EXPIRED", "api key has expired")
 	ErrAPIKeyExpired = infraerrors.Forbidden("API_KEY_EXPIRED", "api key 已过期")
 	// ErrAPIKeyQuotaExhausted = infraerrors.TooManyRequests("API_KEY_QUOTA_EXHAUSTED", "api key quota exhausted")
@@ -61,6 +62,8 @@ type APIKeyRepository interface {
 	CountByUserID(ctx context.Context, userID int64) (int64, error)
 	ExistsByKey(ctx context.Context, key string) (bool, error)
 	ListByGroupID(ctx context.Context, groupID int64, params pagination.PaginationParams) ([]APIKey, *pagination.PaginationResult, error)
+	// ListByParentID 列出父 Key 签发的子令牌
+	ListByParentID(ctx context.Context, parentID int64) ([]APIKey, error)
 	SearchAPIKeys(ctx context.Context, userID int64, keyword string, limit int) ([]APIKey, error)
 	ClearGroupIDByGroupID(ctx context.Context, groupID int64) (int64, error)
 	// UpdateGroupIDByUserAndGroup 将用户下绑定 oldGroupID 的所有 Key 迁移到 newGroupID
@@ -68,9 +71,17 @@ type APIKeyRepository interface {
 	CountByGroupID(ctx context.Context, groupID int64) (int64, error)
 	ListKeysByUserID(ctx context.Context, userID int64) ([]string, error)
 	ListKeysByGroupID(ctx context.Context, groupID int64) ([]string, error)
+	// ListKeysByParentKey 列出父 Key 签发的子令牌 key，用于级联失效认证缓存
+	ListKeysByParentKey(ctx context.Context, parentKey string) ([]string, error)
+	// ListExpiredChildTokens 列出已过期但未删除的子令牌
+	ListExpiredChildTokens(ctx context.Context, now time.Time, limit int) ([]APIKey, error)
 
 	// Quota methods
 	IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error)
+	// ReserveQuota 仅在剩余额度 >= amount 时原子预留，返回是否成功
+	ReserveQuota(ctx context.Context, id int64, amount float64) (bool, error)
+	// DeleteChildToken 软删除子令牌并返回未使用的预算，已删除时返回 ErrAPIKeyNotFound
+	DeleteChildToken(ctx context.Context, id int64) (float64, error)
 	UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error
 
 	// Rate limit methods
@@ -135,6 +146,9 @@ type APIKeyCache interface {
 	SetAuthCache(ctx context.Context, key string, entry *APIKeyAuthCacheEntry, ttl time.Duration) error
 	DeleteAuthCache(ctx context.Context, key string) error
 
+	// ReserveRequestNonce 占用签名请求 nonce；已存在（重放）时返回 false
+	ReserveRequestNonce(ctx context.Context, keyID int64, nonce string, ttl time.Duration) (bool, error)
+
 	// Pub/Sub for L1 cache invalidation across instances
 	PublishAuthCacheInvalidation(ctx context.Context, cacheKey string) error
 	SubscribeAuthCacheInvalidation(ctx context.Context, handler func(cacheKey string)) error
@@ -154,6 +168,10 @@ type CreateAPIKeyRequest struct {
 	CustomKey   *string  `json:"custom_key"`   // 可选的自定义key
 	IPWhitelist []string `json:"ip_whitelist"` // IP 白名单
 	IPBlacklist []string `json:"ip_blacklist"` // IP 黑名单
+	Scopes      []string `json:"scopes"`       // 权限范围（空 = 不受限）
+
+	// AllowedPlatforms 允许路由到的上游平台（空 = 不受限）
+	AllowedPlatforms []string `json:"allowed_platforms"`
 
 	// Quota fields
 	Quota         float64 `json:"quota"`           // Quota limit in USD (0 = unlimited)
@@ -163,15 +181,22 @@ type CreateAPIKeyRequest struct {
 	RateLimit5h float64 `json:"rate_limit_5h"`
 	RateLimit1d float64 `json:"rate_limit_1d"`
 	RateLimit7d float64 `json:"rate_limit_7d"`
+
+	// MaxCostPerRequest 单次请求预估费用上限（USD，0 = 不限制）
+	MaxCostPerRequest float64 `json:"max_cost_per_request"`
 }
 
 // UpdateAPIKeyRequest 更新API Key请求
 type UpdateAPIKeyRequest struct {
-	Name        *string  `json:"name"`
-	GroupID     *int64   `json:"group_id"`
-	Status      *string  `json:"status"`
-	IPWhitelist []string `json:"ip_whitelist"` // IP 白名单（空数组清空）
-	IPBlacklist []string `json:"ip_blacklist"` // IP 黑名单（空数组清空）
+	Name        *string   `json:"name"`
+	GroupID     *int64    `json:"group_id"`
+	Status      *string   `json:"status"`
+	IPWhitelist []string  `json:"ip_whitelist"` // IP 白名单（空数组清空）
+	IPBlacklist []string  `json:"ip_blacklist"` // IP 黑名单（空数组清空）
+	Scopes      *[]string `json:"scopes"`       // 权限范围（nil = 不修改，空数组 = 不受限）
+
+	// AllowedPlatforms 允许路由到的上游平台（nil = 不修改，空数组 = 不受限）
+	AllowedPlatforms *[]string `json:"allowed_platforms"`
 
 	// Quota fields
 	Quota           *float64   `json:"quota"`       // Quota limit in USD (nil = no change, 0 = unlimited)
@@ -184,6 +209,9 @@ type UpdateAPIKeyRequest struct {
 	RateLimit1d         *float64 `json:"rate_limit_1d"`
 	RateLimit7d         *float64 `json:"rate_limit_7d"`
 	ResetRateLimitUsage *bool    `json:"reset_rate_limit_usage"` // Reset all usage counters to 0
+
+	// MaxCostPerRequest 单次请求预估费用上限（USD，nil = 不修改，0 = 不限制）
+	MaxCostPerRequest *float64 `json:"max_cost_per_request"`
 }
 
 // APIKeyService API Key服务
@@ -347,6 +375,23 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		}
 	}
 
+	// 验证权限范围
+	scopes, invalidScopes := NormalizeAPIKeyScopes(req.Scopes)
+	if len(invalidScopes) > 0 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAPIKeyScope, invalidScopes)
+	}
+
+	// 验证允许平台
+	allowedPlatforms, invalidPlatforms := NormalizeAPIKeyAllowedPlatforms(req.AllowedPlatforms)
+	if len(invalidPlatforms) > 0 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAllowedPlatforms, invalidPlatforms)
+	}
+
+	// 验证单次请求费用上限
+	if err := ValidateMaxCostPerRequest(req.MaxCostPerRequest); err != nil {
+		return nil, err
+	}
+
 	// 验证分组权限（如果指定了分组）
 	if req.GroupID != nil {
 		group, err := s.groupRepo.GetByID(ctx, *req.GroupID)
@@ -404,11 +449,15 @@ func (s *APIKeyService) Create(ctx context.Context, userID int64, req CreateAPIK
 		Status:      StatusActive,
 		IPWhitelist: req.IPWhitelist,
 		IPBlacklist: req.IPBlacklist,
+		Scopes:      scopes,
 		Quota:       req.Quota,
 		QuotaUsed:   0,
 		RateLimit5h: req.RateLimit5h,
 		RateLimit1d: req.RateLimit1d,
 		RateLimit7d: req.RateLimit7d,
+
+		MaxCostPerRequest: req.MaxCostPerRequest,
+		AllowedPlatforms:  allowedPlatforms,
 	}
 
 	// Set expiration time if specified
@@ -536,6 +585,24 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 		}
 	}
 
+	// 验证权限范围
+	if req.Scopes != nil {
+		scopes, invalidScopes := NormalizeAPIKeyScopes(*req.Scopes)
+		if len(invalidScopes) > 0 {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAPIKeyScope, invalidScopes)
+		}
+		apiKey.Scopes = scopes
+	}
+
+	// 验证允许平台
+	if req.AllowedPlatforms != nil {
+		allowedPlatforms, invalidPlatforms := NormalizeAPIKeyAllowedPlatforms(*req.AllowedPlatforms)
+		if len(invalidPlatforms) > 0 {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAllowedPlatforms, invalidPlatforms)
+		}
+		apiKey.AllowedPlatforms = allowedPlatforms
+	}
+
 	// 更新字段
 	if req.Name != nil {
 		apiKey.Name = *req.Name
@@ -611,6 +678,12 @@ func (s *APIKeyService) Update(ctx context.Context, id int64, userID int64, req
 	if req.RateLimit7d != nil {
 		apiKey.RateLimit7d = *req.RateLimit7d
 	}
+	if req.MaxCostPerRequest != nil {
+		if err := ValidateMaxCostPerRequest(*req.MaxCostPerRequest); err != nil {
+			return nil, err
+		}
+		apiKey.MaxCostPerRequest = *req.MaxCostPerRequest
+	}
 	resetRateLimit := req.ResetRateLimitUsage != nil && *req.ResetRateLimitUsage
 	if resetRateLimit {
 		apiKey.Usage5h = 0
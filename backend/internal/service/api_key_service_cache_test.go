@@ -20,6 +20,8 @@ type authRepoStub struct {
 	getByKeyForAuth   func(ctx context.Context, key string) (*APIKey, error)
 	listKeysByUserID  func(ctx context.Context, userID int64) ([]string, error)
 	listKeysByGroupID func(ctx context.Context, groupID int64) ([]string, error)
+	listKeysByParent  func(ctx context.Context, parentKey string) ([]string, error)
+	getByID           func(ctx context.Context, id int64) (*APIKey, error)
 }
 
 func (s *authRepoStub) Create(ctx context.Context, key *APIKey) error {
@@ -27,7 +29,10 @@ func (s *authRepoStub) Create(ctx context.Context, key *APIKey) error {
 }
 
 func (s *authRepoStub) GetByID(ctx context.Context, id int64) (*APIKey, error) {
-	panic("unexpected GetByID call")
+	if s.getByID == nil {
+		panic("unexpected GetByID call")
+	}
+	return s.getByID(ctx, id)
 }
 
 func (s *authRepoStub) GetKeyAndOwnerID(ctx context.Context, id int64) (string, int64, error) {
@@ -73,6 +78,10 @@ func (s *authRepoStub) ListByGroupID(ctx context.Context, groupID int64, params
 	panic("unexpected ListByGroupID call")
 }
 
+func (s *authRepoStub) ListByParentID(ctx context.Context, parentID int64) ([]APIKey, error) {
+	panic("unexpected ListByParentID call")
+}
+
 func (s *authRepoStub) SearchAPIKeys(ctx context.Context, userID int64, keyword string, limit int) ([]APIKey, error) {
 	panic("unexpected SearchAPIKeys call")
 }
@@ -102,6 +111,25 @@ func (s *authRepoStub) ListKeysByGroupID(ctx context.Context, groupID int64) ([]
 	return s.listKeysByGroupID(ctx, groupID)
 }
 
+func (s *authRepoStub) ListKeysByParentKey(ctx context.Context, parentKey string) ([]string, error) {
+	if s.listKeysByParent == nil {
+		return nil, nil
+	}
+	return s.listKeysByParent(ctx, parentKey)
+}
+
+func (s *authRepoStub) ListExpiredChildTokens(ctx context.Context, now time.Time, limit int) ([]APIKey, error) {
+	panic("unexpected ListExpiredChildTokens call")
+}
+
+func (s *authRepoStub) ReserveQuota(ctx context.Context, id int64, amount float64) (bool, error) {
+	panic("unexpected ReserveQuota call")
+}
+
+func (s *authRepoStub) DeleteChildToken(ctx context.Context, id int64) (float64, error) {
+	panic("unexpected DeleteChildToken call")
+}
+
 func (s *authRepoStub) IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error) {
 	panic("unexpected IncrementQuotaUsed call")
 }
@@ -137,6 +165,10 @@ func (s *authCacheStub) DeleteCreateAttemptCount(ctx context.Context, userID int
 	return nil
 }
 
+func (s *authCacheStub) ReserveRequestNonce(ctx context.Context, keyID int64, nonce string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
 func (s *authCacheStub) IncrementDailyUsage(ctx context.Context, apiKey string) error {
 	return nil
 }
@@ -502,6 +534,64 @@ func TestAPIKeyService_InvalidateAuthCacheByKey(t *testing.T) {
 	require.Len(t, cache.deleteAuthKeys, 1)
 }
 
+func TestAPIKeyService_InvalidateAuthCacheByKey_CascadesToChildTokens(t *testing.T) {
+	cache := &authCacheStub{}
+	repo := &authRepoStub{
+		listKeysByParent: func(ctx context.Context, parentKey string) ([]string, error) {
+			if parentKey == "parent" {
+				return []string{"child-1", "child-2"}, nil
+			}
+			return nil, nil
+		},
+	}
+	cfg := &config.Config{
+		APIKeyAuth: config.APIKeyAuthCacheConfig{
+			L2TTLSeconds: 60,
+		},
+	}
+	svc := NewAPIKeyService(repo, nil, nil, nil, nil, cache, cfg)
+
+	svc.InvalidateAuthCacheByKey(context.Background(), "parent")
+	require.Equal(t, []string{svc.authCacheKey("parent"), svc.authCacheKey("child-1"), svc.authCacheKey("child-2")}, cache.deleteAuthKeys)
+}
+
+func TestAPIKeyService_GetByKey_CachesChildTokenParentState(t *testing.T) {
+	cache := &authCacheStub{}
+	var parentLoads int32
+	repo := &authRepoStub{
+		getByKeyForAuth: func(ctx context.Context, key string) (*APIKey, error) {
+			return &APIKey{
+				ID:       31,
+				UserID:   3,
+				ParentID: 30,
+				Status:   StatusActive,
+				User:     &User{ID: 3, Status: StatusActive, Role: RoleUser, Concurrency: 1},
+			}, nil
+		},
+		getByID: func(ctx context.Context, id int64) (*APIKey, error) {
+			atomic.AddInt32(&parentLoads, 1)
+			return &APIKey{ID: id, UserID: 3, Status: StatusDisabled}, nil
+		},
+	}
+	cfg := &config.Config{
+		APIKeyAuth: config.APIKeyAuthCacheConfig{
+			L1Size:       1000,
+			L1TTLSeconds: 60,
+		},
+	}
+	svc := NewAPIKeyService(repo, nil, nil, nil, nil, cache, cfg)
+
+	apiKey, err := svc.GetByKey(context.Background(), "child")
+	require.NoError(t, err)
+	require.Equal(t, &APIKeyParentState{UserID: 3, Status: StatusDisabled}, apiKey.ParentState)
+	svc.authCacheL1.Wait()
+
+	apiKey, err = svc.GetByKey(context.Background(), "child")
+	require.NoError(t, err)
+	require.ErrorIs(t, svc.ValidateChildTokenParent(context.Background(), apiKey), ErrParentAPIKeyInvalid)
+	require.Equal(t, int32(1), atomic.LoadInt32(&parentLoads))
+}
+
 func TestAPIKeyService_GetByKey_CachesNegativeOnRepoMiss(t *testing.T) {
 	cache := &authCacheStub{}
 	repo := &authRepoStub{
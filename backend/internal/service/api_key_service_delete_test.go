@@ -101,6 +101,10 @@ func (s *apiKeyRepoStub) ListByGroupID(ctx context.Context, groupID int64, param
 	panic("unexpected ListByGroupID call")
 }
 
+func (s *apiKeyRepoStub) ListByParentID(ctx context.Context, parentID int64) ([]APIKey, error) {
+	panic("unexpected ListByParentID call")
+}
+
 func (s *apiKeyRepoStub) SearchAPIKeys(ctx context.Context, userID int64, keyword string, limit int) ([]APIKey, error) {
 	panic("unexpected SearchAPIKeys call")
 }
@@ -124,6 +128,22 @@ func (s *apiKeyRepoStub) ListKeysByGroupID(ctx context.Context, groupID int64) (
 	panic("unexpected ListKeysByGroupID call")
 }
 
+func (s *apiKeyRepoStub) ListKeysByParentKey(ctx context.Context, parentKey string) ([]string, error) {
+	return nil, nil
+}
+
+func (s *apiKeyRepoStub) ListExpiredChildTokens(ctx context.Context, now time.Time, limit int) ([]APIKey, error) {
+	panic("unexpected ListExpiredChildTokens call")
+}
+
+func (s *apiKeyRepoStub) ReserveQuota(ctx context.Context, id int64, amount float64) (bool, error) {
+	panic("unexpected ReserveQuota call")
+}
+
+func (s *apiKeyRepoStub) DeleteChildToken(ctx context.Context, id int64) (float64, error) {
+	panic("unexpected DeleteChildToken call")
+}
+
 func (s *apiKeyRepoStub) IncrementQuotaUsed(ctx context.Context, id int64, amount float64) (float64, error) {
 	panic("unexpected IncrementQuotaUsed call")
 }
@@ -176,6 +196,10 @@ func (s *apiKeyCacheStub) DeleteCreateAttemptCount(ctx context.Context, userID i
 	return nil
 }
 
+func (s *apiKeyCacheStub) ReserveRequestNonce(ctx context.Context, keyID int64, nonce string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
 // IncrementDailyUsage 空实现，本测试不验证此行为
 func (s *apiKeyCacheStub) IncrementDailyUsage(ctx context.Context, apiKey string) error {
 	return nil
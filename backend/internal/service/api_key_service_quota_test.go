@@ -46,6 +46,10 @@ func (s *quotaStateCacheStub) DeleteCreateAttemptCount(context.Context, int64) e
 	return nil
 }
 
+func (s *quotaStateCacheStub) ReserveRequestNonce(context.Context, int64, string, time.Duration) (bool, error) {
+	return true, nil
+}
+
 func (s *quotaStateCacheStub) IncrementDailyUsage(context.Context, string) error {
 	return nil
 }
@@ -116,6 +120,9 @@ func (s *quotaBaseAPIKeyRepoStub) ExistsByKey(context.Context, string) (bool, er
 func (s *quotaBaseAPIKeyRepoStub) ListByGroupID(context.Context, int64, pagination.PaginationParams) ([]APIKey, *pagination.PaginationResult, error) {
 	panic("unexpected ListByGroupID call")
 }
+func (s *quotaBaseAPIKeyRepoStub) ListByParentID(context.Context, int64) ([]APIKey, error) {
+	panic("unexpected ListByParentID call")
+}
 func (s *quotaBaseAPIKeyRepoStub) SearchAPIKeys(context.Context, int64, string, int) ([]APIKey, error) {
 	panic("unexpected SearchAPIKeys call")
 }
@@ -134,6 +141,18 @@ func (s *quotaBaseAPIKeyRepoStub) ListKeysByUserID(context.Context, int64) ([]st
 func (s *quotaBaseAPIKeyRepoStub) ListKeysByGroupID(context.Context, int64) ([]string, error) {
 	panic("unexpected ListKeysByGroupID call")
 }
+func (s *quotaBaseAPIKeyRepoStub) ListKeysByParentKey(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+func (s *quotaBaseAPIKeyRepoStub) ListExpiredChildTokens(context.Context, time.Time, int) ([]APIKey, error) {
+	panic("unexpected ListExpiredChildTokens call")
+}
+func (s *quotaBaseAPIKeyRepoStub) ReserveQuota(context.Context, int64, float64) (bool, error) {
+	panic("unexpected ReserveQuota call")
+}
+func (s *quotaBaseAPIKeyRepoStub) DeleteChildToken(context.Context, int64) (float64, error) {
+	panic("unexpected DeleteChildToken call")
+}
 func (s *quotaBaseAPIKeyRepoStub) IncrementQuotaUsed(context.Context, int64, float64) (float64, error) {
 	panic("unexpected IncrementQuotaUsed call")
 }
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// HMAC 签名请求（机器对机器调用）。
+//
+// 客户端不在请求中携带 API Key，而是使用 API Key 作为共享密钥对请求签名：
+//
+//	payload   = timestamp + "\n" + nonce + "\n" + METHOD + "\n" + path?query + "\n" + hex(sha256(body))
+//	signature = hex(hmac_sha256(api_key, payload))
+//
+// 服务端校验时间戳偏差与签名，并通过 Redis 记录 (key_id, nonce) 防止重放。
+const (
+	SignedRequestKeyIDHeader     = "X-Api-Key-Id"
+	SignedRequestSignatureHeader = "X-Signature"
+	SignedRequestTimestampHeader = "X-Timestamp"
+	SignedRequestNonceHeader     = "X-Nonce"
+
+	defaultSignedRequestMaxSkew = 5 * time.Minute
+	signedRequestMaxNonceLen    = 128
+)
+
+var (
+	ErrSignedRequestDisabled   = infraerrors.Unauthorized("SIGNED_REQUEST_DISABLED", "signed requests are not enabled")
+	ErrInvalidRequestSignature = infraerrors.Unauthorized("INVALID_SIGNATURE", "invalid request signature")
+	ErrRequestTimestampSkew    = infraerrors.Unauthorized("REQUEST_TIMESTAMP_SKEW", "request timestamp is missing or outside the allowed window")
+	ErrRequestNonceReplayed    = infraerrors.Unauthorized("REQUEST_NONCE_REPLAYED", "request nonce is missing or has already been used")
+)
+
+// SignedRequest 待校验的签名请求要素。
+type SignedRequest struct {
+	KeyID     string
+	Signature string
+	Timestamp string
+	Nonce     string
+	Method    string
+	Path      string // 含 query，例如 /v1/messages?beta=true
+	Body      []byte
+}
+
+// BuildRequestSignaturePayload 构造待签名串。
+func BuildRequestSignaturePayload(timestamp, nonce, method, path string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		timestamp,
+		nonce,
+		strings.ToUpper(method),
+		path,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// ComputeRequestSignature 使用 API Key 计算签名（hex 编码）。
+func ComputeRequestSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *APIKeyService) signedRequestMaxSkew() time.Duration {
+	if s.cfg != nil && s.cfg.Security.RequestSigning.MaxClockSkewSeconds > 0 {
+		return time.Duration(s.cfg.Security.RequestSigning.MaxClockSkewSeconds) * time.Second
+	}
+	return defaultSignedRequestMaxSkew
+}
+
+// SignedRequestsEnabled 是否启用了 HMAC 签名请求认证。
+func (s *APIKeyService) SignedRequestsEnabled() bool {
+	return s != nil && s.cfg != nil && s.cfg.Security.RequestSigning.Enabled
+}
+
+// AuthenticateSignedRequest 校验 HMAC 签名请求并返回对应的 API Key（与 GetByKey 返回结构一致）。
+func (s *APIKeyService) AuthenticateSignedRequest(ctx context.Context, req SignedRequest) (*APIKey, error) {
+	if !s.SignedRequestsEnabled() {
+		return nil, ErrSignedRequestDisabled
+	}
+
+	maxSkew := s.signedRequestMaxSkew()
+	ts, err := strconv.ParseInt(strings.TrimSpace(req.Timestamp), 10, 64)
+	if err != nil {
+		return nil, ErrRequestTimestampSkew
+	}
+	if skew := time.Since(time.Unix(ts, 0)); skew > maxSkew || skew < -maxSkew {
+		return nil, ErrRequestTimestampSkew
+	}
+
+	nonce := strings.TrimSpace(req.Nonce)
+	if nonce == "" || len(nonce) > signedRequestMaxNonceLen {
+		return nil, ErrRequestNonceReplayed
+	}
+
+	keyID, err := strconv.ParseInt(strings.TrimSpace(req.KeyID), 10, 64)
+	if err != nil || keyID <= 0 {
+		return nil, ErrInvalidRequestSignature
+	}
+	key, _, err := s.apiKeyRepo.GetKeyAndOwnerID(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return nil, ErrInvalidRequestSignature
+		}
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+
+	got, err := hex.DecodeString(strings.TrimSpace(req.Signature))
+	if err != nil {
+		return nil, ErrInvalidRequestSignature
+	}
+	payload := BuildRequestSignaturePayload(strings.TrimSpace(req.Timestamp), nonce, req.Method, req.Path, req.Body)
+	want, _ := hex.DecodeString(ComputeRequestSignature(key, payload))
+	if !hmac.Equal(got, want) {
+		return nil, ErrInvalidRequestSignature
+	}
+
+	if s.cache == nil {
+		return nil, errors.New("request nonce cache unavailable")
+	}
+	// nonce 需覆盖整个时间戳窗口（前后各 maxSkew），签名通过后再占用，避免伪造请求耗尽 nonce。
+	reserved, err := s.cache.ReserveRequestNonce(ctx, keyID, nonce, 2*maxSkew)
+	if err != nil {
+		return nil, fmt.Errorf("reserve request nonce: %w", err)
+	}
+	if !reserved {
+		return nil, ErrRequestNonceReplayed
+	}
+
+	return s.GetByKey(ctx, key)
+}
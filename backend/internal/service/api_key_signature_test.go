@@ -0,0 +1,124 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type signedRequestRepoStub struct {
+	authRepoStub
+	keys map[int64]string
+}
+
+func (s *signedRequestRepoStub) GetKeyAndOwnerID(ctx context.Context, id int64) (string, int64, error) {
+	key, ok := s.keys[id]
+	if !ok {
+		return "", 0, ErrAPIKeyNotFound
+	}
+	return key, 2, nil
+}
+
+type signedRequestCacheStub struct {
+	authCacheStub
+	nonces map[string]struct{}
+}
+
+func (s *signedRequestCacheStub) ReserveRequestNonce(ctx context.Context, keyID int64, nonce string, ttl time.Duration) (bool, error) {
+	k := strconv.FormatInt(keyID, 10) + ":" + nonce
+	if _, ok := s.nonces[k]; ok {
+		return false, nil
+	}
+	s.nonces[k] = struct{}{}
+	return true, nil
+}
+
+func newSignedRequestTestService(enabled bool) *APIKeyService {
+	repo := &signedRequestRepoStub{keys: map[int64]string{1: "sk-signed"}}
+	repo.getByKeyForAuth = func(ctx context.Context, key string) (*APIKey, error) {
+		return &APIKey{
+			ID:     1,
+			UserID: 2,
+			Key:    key,
+			Status: StatusActive,
+			User:   &User{ID: 2, Status: StatusActive, Role: RoleUser, Balance: 10, Concurrency: 1},
+		}, nil
+	}
+	cache := &signedRequestCacheStub{nonces: map[string]struct{}{}}
+	cfg := &config.Config{}
+	cfg.Security.RequestSigning.Enabled = enabled
+	cfg.Security.RequestSigning.MaxClockSkewSeconds = 300
+	return NewAPIKeyService(repo, nil, nil, nil, nil, cache, cfg)
+}
+
+func signTestRequest(key string, ts time.Time, nonce string) SignedRequest {
+	req := SignedRequest{
+		KeyID:     "1",
+		Timestamp: strconv.FormatInt(ts.Unix(), 10),
+		Nonce:     nonce,
+		Method:    "post",
+		Path:      "/v1/messages?beta=true",
+		Body:      []byte(`{"model":"claude"}`),
+	}
+	req.Signature = ComputeRequestSignature(key, BuildRequestSignaturePayload(req.Timestamp, req.Nonce, req.Method, req.Path, req.Body))
+	return req
+}
+
+func TestAuthenticateSignedRequest_Valid(t *testing.T) {
+	svc := newSignedRequestTestService(true)
+
+	apiKey, err := svc.AuthenticateSignedRequest(context.Background(), signTestRequest("sk-signed", time.Now(), "n-1"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), apiKey.ID)
+	require.Equal(t, "sk-signed", apiKey.Key)
+}
+
+func TestAuthenticateSignedRequest_Replay(t *testing.T) {
+	svc := newSignedRequestTestService(true)
+	req := signTestRequest("sk-signed", time.Now(), "n-1")
+
+	_, err := svc.AuthenticateSignedRequest(context.Background(), req)
+	require.NoError(t, err)
+	_, err = svc.AuthenticateSignedRequest(context.Background(), req)
+	require.ErrorIs(t, err, ErrRequestNonceReplayed)
+}
+
+func TestAuthenticateSignedRequest_Rejects(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		mutate func(*SignedRequest)
+		want   error
+	}{
+		{"wrong key", func(r *SignedRequest) { *r = signTestRequest("sk-other", now, "n") }, ErrInvalidRequestSignature},
+		{"tampered body", func(r *SignedRequest) { r.Body = []byte(`{"model":"opus"}`) }, ErrInvalidRequestSignature},
+		{"tampered path", func(r *SignedRequest) { r.Path = "/v1/models" }, ErrInvalidRequestSignature},
+		{"unknown key id", func(r *SignedRequest) { r.KeyID = "99" }, ErrInvalidRequestSignature},
+		{"non-hex signature", func(r *SignedRequest) { r.Signature = "zz" }, ErrInvalidRequestSignature},
+		{"stale timestamp", func(r *SignedRequest) { *r = signTestRequest("sk-signed", now.Add(-10*time.Minute), "n") }, ErrRequestTimestampSkew},
+		{"future timestamp", func(r *SignedRequest) { *r = signTestRequest("sk-signed", now.Add(10*time.Minute), "n") }, ErrRequestTimestampSkew},
+		{"missing nonce", func(r *SignedRequest) { *r = signTestRequest("sk-signed", now, "") }, ErrRequestNonceReplayed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newSignedRequestTestService(true)
+			req := signTestRequest("sk-signed", now, "n")
+			tt.mutate(&req)
+			_, err := svc.AuthenticateSignedRequest(context.Background(), req)
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestAuthenticateSignedRequest_Disabled(t *testing.T) {
+	svc := newSignedRequestTestService(false)
+
+	_, err := svc.AuthenticateSignedRequest(context.Background(), signTestRequest("sk-signed", time.Now(), "n"))
+	require.ErrorIs(t, err, ErrSignedRequestDisabled)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"math"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	// MaxTokenBucketBurst 令牌桶容量上限（请求数）
+	MaxTokenBucketBurst = 10000
+	// MaxTokenBucketRefillRate 令牌桶补充速率上限（请求/秒）
+	MaxTokenBucketRefillRate = 1000
+)
+
+// ErrInvalidTokenBucket API Key 令牌桶配置不合法
+var ErrInvalidTokenBucket = infraerrors.BadRequest(
+	"INVALID_TOKEN_BUCKET",
+	"token_bucket_burst must be 0-10000 and token_bucket_refill_rate must be 0-1000; a non-zero burst requires a positive refill rate",
+)
+
+// TokenBucketEnabled 是否为该 Key 启用令牌桶平滑（容量与补充速率均为正数）
+func (k *APIKey) TokenBucketEnabled() bool {
+	return k != nil && k.TokenBucketBurst > 0 && k.TokenBucketRefillRate > 0
+}
+
+// ValidateTokenBucket 校验令牌桶配置：burst=0 表示关闭；开启时补充速率必须为正数。
+func ValidateTokenBucket(burst int, refillRate float64) error {
+	if burst < 0 || burst > MaxTokenBucketBurst {
+		return ErrInvalidTokenBucket
+	}
+	if math.IsNaN(refillRate) || refillRate < 0 || refillRate > MaxTokenBucketRefillRate {
+		return ErrInvalidTokenBucket
+	}
+	if burst > 0 && refillRate <= 0 {
+		return ErrInvalidTokenBucket
+	}
+	return nil
+}
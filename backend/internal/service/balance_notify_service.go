@@ -39,9 +39,10 @@ type AccountQuotaReader interface {
 
 // BalanceNotifyService handles balance and quota threshold notifications.
 type BalanceNotifyService struct {
-	emailService *EmailService
-	settingRepo  SettingRepository
-	accountRepo  AccountQuotaReader
+	emailService   *EmailService
+	settingRepo    SettingRepository
+	accountRepo    AccountQuotaReader
+	webhookService *WebhookService
 }
 
 // NewBalanceNotifyService creates a new BalanceNotifyService.
@@ -53,6 +54,11 @@ func NewBalanceNotifyService(emailService *EmailService, settingRepo SettingRepo
 	}
 }
 
+// SetWebhookService sets the optional webhook notifier for account quota threshold events.
+func (s *BalanceNotifyService) SetWebhookService(webhookService *WebhookService) {
+	s.webhookService = webhookService
+}
+
 // resolveBalanceThreshold returns the effective balance threshold.
 // For percentage type, it computes threshold = totalRecharged * percentage / 100.
 func resolveBalanceThreshold(threshold float64, thresholdType string, totalRecharged float64) float64 {
@@ -176,7 +182,18 @@ func buildQuotaDimsFromState(account *Account, state *AccountQuotaState) []quota
 // When quotaState is non-nil (from DB transaction RETURNING), it is used directly for threshold
 // checking, avoiding a separate DB read. Otherwise it falls back to fetching fresh account data.
 func (s *BalanceNotifyService) CheckAccountQuotaAfterIncrement(ctx context.Context, account *Account, cost float64, quotaState *AccountQuotaState) {
-	if account == nil || s.emailService == nil || s.settingRepo == nil || cost <= 0 {
+	if account == nil || cost <= 0 {
+		return
+	}
+	// Webhook 80%/100% 阈值事件独立于邮件通知开关
+	if s.webhookService != nil {
+		webhookAccount := account
+		if quotaState == nil {
+			webhookAccount = s.fetchFreshAccount(ctx, account)
+		}
+		s.webhookService.CheckAccountQuota(webhookAccount, cost, quotaState)
+	}
+	if s.emailService == nil || s.settingRepo == nil {
 		return
 	}
 	if !s.isAccountQuotaNotifyEnabled(ctx) {
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -20,6 +21,8 @@ import (
 var (
 	ErrSubscriptionInvalid       = infraerrors.Forbidden("SUBSCRIPTION_INVALID", "subscription is invalid or expired")
 	ErrBillingServiceUnavailable = infraerrors.ServiceUnavailable("BILLING_SERVICE_ERROR", "Billing service temporarily unavailable. Please retry later.")
+	// ErrBillingDatabaseUnavailable 计费数据库不可用且降级模式为 fail_closed 时拒绝请求
+	ErrBillingDatabaseUnavailable = infraerrors.ServiceUnavailable("BILLING_DATABASE_UNAVAILABLE", "Billing database is unavailable; requests are rejected until it recovers. Please retry later.")
 	// RPM 超限错误。gateway_handler 负责映射为 HTTP 429。
 	ErrGroupRPMExceeded = infraerrors.TooManyRequests("GROUP_RPM_EXCEEDED", "group requests-per-minute limit exceeded")
 	ErrUserRPMExceeded  = infraerrors.TooManyRequests("USER_RPM_EXCEEDED", "user requests-per-minute limit exceeded")
@@ -95,6 +98,13 @@ type BillingCacheService struct {
 	cfg                   *config.Config
 	circuitBreaker        *billingCircuitBreaker
 
+	// 组织月度预算校验（可选，见 checkOrganizationBudget）
+	orgRepo        OrganizationRepository
+	orgBudgetCache sync.Map // orgID -> *orgBudgetCacheEntry
+
+	// 计费数据库健康状态与降级模式（可选，见 billing_db_health.go）
+	dbHealth *BillingDBHealthService
+
 	cacheWriteChan     chan cacheWriteTask
 	cacheWriteWg       sync.WaitGroup
 	cacheWriteStopOnce sync.Once
@@ -630,15 +640,16 @@ func (s *BillingCacheService) evaluateRateLimits(ctx context.Context, apiKey *AP
 		}()
 	}
 
-	// Check limits
+	// Check limits（附带窗口重置时间，供网关写 Retry-After / X-RateLimit-*）
+	now := time.Now()
 	if apiKey.RateLimit5h > 0 && usage5h >= apiKey.RateLimit5h {
-		return ErrAPIKeyRateLimit5hExceeded
+		return withRateLimitState(ErrAPIKeyRateLimit5hExceeded, apiKey.RateLimit5h, apiKey.RateLimit5h-usage5h, untilWindowReset(w5h, RateLimitWindow5h, now))
 	}
 	if apiKey.RateLimit1d > 0 && usage1d >= apiKey.RateLimit1d {
-		return ErrAPIKeyRateLimit1dExceeded
+		return withRateLimitState(ErrAPIKeyRateLimit1dExceeded, apiKey.RateLimit1d, apiKey.RateLimit1d-usage1d, untilWindowReset(w1d, RateLimitWindow1d, now))
 	}
 	if apiKey.RateLimit7d > 0 && usage7d >= apiKey.RateLimit7d {
-		return ErrAPIKeyRateLimit7dExceeded
+		return withRateLimitState(ErrAPIKeyRateLimit7dExceeded, apiKey.RateLimit7d, apiKey.RateLimit7d-usage7d, untilWindowReset(w7d, RateLimitWindow7d, now))
 	}
 	return nil
 }
@@ -667,21 +678,29 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 	if s.cfg.RunMode == config.RunModeSimple {
 		return nil
 	}
-	if s.circuitBreaker != nil && !s.circuitBreaker.Allow() {
+	// 计费数据库不可用：fail_closed 直接拒绝；fail_open 放行依赖数据库的检查，使用量由本地溢出日志兜底
+	failOpen := false
+	if !s.dbHealth.Available() {
+		if !s.dbHealth.FailOpen() {
+			return ErrBillingDatabaseUnavailable
+		}
+		failOpen = true
+	}
+	if s.circuitBreaker != nil && !s.circuitBreaker.Allow() && !failOpen {
 		return ErrBillingServiceUnavailable
 	}
 
 	// 判断计费模式
 	isSubscriptionMode := group != nil && group.IsSubscriptionType() && subscription != nil
 
+	var eligibilityErr error
 	if isSubscriptionMode {
-		if err := s.checkSubscriptionEligibility(ctx, user.ID, group, subscription); err != nil {
-			return err
-		}
+		eligibilityErr = s.checkSubscriptionEligibility(ctx, user.ID, group, subscription)
 	} else {
-		if err := s.checkBalanceEligibility(ctx, user.ID); err != nil {
-			return err
-		}
+		eligibilityErr = s.checkBalanceEligibility(ctx, user.ID)
+	}
+	if eligibilityErr != nil && !(failOpen && errors.Is(eligibilityErr, ErrBillingServiceUnavailable)) {
+		return eligibilityErr
 	}
 
 	// Check API Key rate limits (applies to both billing modes)
@@ -691,6 +710,11 @@ func (s *BillingCacheService) CheckBillingEligibility(ctx context.Context, user
 		}
 	}
 
+	// 组织月度预算：组织停用或当月消费已达预算时拒绝
+	if err := s.checkOrganizationBudget(ctx, user); err != nil {
+		return err
+	}
+
 	// RPM 限流：级联回落（Override → Group → User），放在最后以避免为注定失败的请求增加计数。
 	if err := s.checkRPM(ctx, user, group); err != nil {
 		return err
@@ -744,7 +768,7 @@ func (s *BillingCacheService) checkRPM(ctx context.Context, user *User, group *G
 					)
 					// fail-open
 				} else if count > *override {
-					return ErrGroupRPMExceeded
+					return withRateLimitState(ErrGroupRPMExceeded, float64(*override), 0, untilNextMinute(time.Now()))
 				}
 			}
 			// override 命中后跳过 group.rpm_limit（override 替代 group），但不 return——继续检查 user 级。
@@ -759,7 +783,7 @@ func (s *BillingCacheService) checkRPM(ctx context.Context, user *User, group *G
 				)
 				// fail-open
 			} else if count > group.RPMLimit {
-				return ErrGroupRPMExceeded
+				return withRateLimitState(ErrGroupRPMExceeded, float64(group.RPMLimit), 0, untilNextMinute(time.Now()))
 			}
 		}
 	}
@@ -776,7 +800,7 @@ func (s *BillingCacheService) checkRPM(ctx context.Context, user *User, group *G
 			return nil // fail-open
 		}
 		if count > user.RPMLimit {
-			return ErrUserRPMExceeded
+			return withRateLimitState(ErrUserRPMExceeded, float64(user.RPMLimit), 0, untilNextMinute(time.Now()))
 		}
 	}
 
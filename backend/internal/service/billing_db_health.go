@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBillingDBProbeInterval    = 5 * time.Second
+	defaultBillingDBProbeTimeout     = 2 * time.Second
+	defaultBillingDBFailureThreshold = 3
+)
+
+// BillingDBPinger 计费数据库连通性探测（*sql.DB 天然满足）。
+type BillingDBPinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// BillingDBHealthService 周期性探测计费数据库，并按配置的降级模式决定数据库不可用时的请求处理方式：
+// fail_closed 拒绝新请求；fail_open 继续转发，使用量由本地溢出日志兜底并在恢复后回放。
+type BillingDBHealthService struct {
+	pinger           BillingDBPinger
+	mode             string
+	interval         time.Duration
+	timeout          time.Duration
+	failureThreshold int
+
+	unavailable         atomic.Bool
+	consecutiveFailures int
+	probeMu             sync.Mutex
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewBillingDBHealthService 创建计费数据库健康探测服务。
+func NewBillingDBHealthService(pinger BillingDBPinger, cfg *config.Config) *BillingDBHealthService {
+	s := &BillingDBHealthService{
+		pinger:           pinger,
+		mode:             config.BillingDBOutageModeFailClosed,
+		interval:         defaultBillingDBProbeInterval,
+		timeout:          defaultBillingDBProbeTimeout,
+		failureThreshold: defaultBillingDBFailureThreshold,
+		stopCh:           make(chan struct{}),
+	}
+	if cfg == nil {
+		return s
+	}
+	outage := cfg.Billing.DBOutage
+	if strings.EqualFold(strings.TrimSpace(outage.Mode), config.BillingDBOutageModeFailOpen) {
+		s.mode = config.BillingDBOutageModeFailOpen
+	}
+	if outage.ProbeIntervalSeconds > 0 {
+		s.interval = time.Duration(outage.ProbeIntervalSeconds) * time.Second
+	}
+	if outage.ProbeTimeoutSeconds > 0 {
+		s.timeout = time.Duration(outage.ProbeTimeoutSeconds) * time.Second
+	}
+	if outage.FailureThreshold > 0 {
+		s.failureThreshold = outage.FailureThreshold
+	}
+	return s
+}
+
+// SetBillingDBHealth 注入计费数据库健康状态，用于请求准入时的降级判断。
+func (s *BillingCacheService) SetBillingDBHealth(health *BillingDBHealthService) {
+	s.dbHealth = health
+}
+
+// Start 启动后台探测循环。
+func (s *BillingDBHealthService) Start() {
+	if s == nil || s.pinger == nil {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stopCh:
+				return
+			case <-ticker.C:
+				s.probe(context.Background())
+			}
+		}
+	}()
+}
+
+// Stop 停止探测循环。
+func (s *BillingDBHealthService) Stop() {
+	if s == nil || s.pinger == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		s.wg.Wait()
+	})
+}
+
+// Available 返回计费数据库当前是否可用（未探测到故障时视为可用）。
+func (s *BillingDBHealthService) Available() bool {
+	return s == nil || !s.unavailable.Load()
+}
+
+// FailOpen 返回数据库不可用时是否继续放行请求。
+func (s *BillingDBHealthService) FailOpen() bool {
+	return s != nil && s.mode == config.BillingDBOutageModeFailOpen
+}
+
+// Mode 返回当前降级模式。
+func (s *BillingDBHealthService) Mode() string {
+	if s == nil {
+		return config.BillingDBOutageModeFailClosed
+	}
+	return s.mode
+}
+
+func (s *BillingDBHealthService) probe(ctx context.Context) {
+	s.probeMu.Lock()
+	defer s.probeMu.Unlock()
+
+	probeCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	err := s.pinger.PingContext(probeCtx)
+	cancel()
+
+	if err == nil {
+		s.consecutiveFailures = 0
+		if s.unavailable.CompareAndSwap(true, false) {
+			logger.L().With(
+				zap.String("component", "service.billing_db_health"),
+				zap.String("mode", s.mode),
+			).Info("billing.db_recovered")
+		}
+		return
+	}
+
+	s.consecutiveFailures++
+	if s.consecutiveFailures < s.failureThreshold {
+		return
+	}
+	if s.unavailable.CompareAndSwap(false, true) {
+		logger.L().With(
+			zap.String("component", "service.billing_db_health"),
+			zap.String("mode", s.mode),
+			zap.Int("consecutive_failures", s.consecutiveFailures),
+			zap.Error(err),
+		).Error("billing.db_unavailable")
+	}
+}
@@ -0,0 +1,115 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type billingDBPingerStub struct {
+	err   error
+	calls int
+}
+
+func (p *billingDBPingerStub) PingContext(ctx context.Context) error {
+	p.calls++
+	return p.err
+}
+
+type billingDBDownUserRepoStub struct {
+	UserRepository
+}
+
+func (r *billingDBDownUserRepoStub) GetByID(ctx context.Context, id int64) (*User, error) {
+	return nil, errors.New("dial tcp: connection refused")
+}
+
+func newBillingDBHealthForTest(pinger BillingDBPinger, mode string) *BillingDBHealthService {
+	cfg := &config.Config{}
+	cfg.Billing.DBOutage = config.BillingDBOutageConfig{
+		Mode:                 mode,
+		ProbeIntervalSeconds: 1,
+		ProbeTimeoutSeconds:  1,
+		FailureThreshold:     2,
+	}
+	return NewBillingDBHealthService(pinger, cfg)
+}
+
+func TestBillingDBHealthService_ThresholdAndRecovery(t *testing.T) {
+	pinger := &billingDBPingerStub{err: errors.New("connection refused")}
+	health := newBillingDBHealthForTest(pinger, config.BillingDBOutageModeFailOpen)
+	require.True(t, health.FailOpen())
+	require.True(t, health.Available())
+
+	health.probe(context.Background())
+	require.True(t, health.Available(), "单次失败不应判定不可用")
+	health.probe(context.Background())
+	require.False(t, health.Available())
+
+	pinger.err = nil
+	health.probe(context.Background())
+	require.True(t, health.Available())
+	require.Equal(t, 3, pinger.calls)
+}
+
+func TestBillingDBHealthService_NilAndDefaults(t *testing.T) {
+	var nilHealth *BillingDBHealthService
+	require.True(t, nilHealth.Available())
+	require.False(t, nilHealth.FailOpen())
+	require.Equal(t, config.BillingDBOutageModeFailClosed, nilHealth.Mode())
+
+	health := NewBillingDBHealthService(nil, nil)
+	require.Equal(t, config.BillingDBOutageModeFailClosed, health.Mode())
+	require.Equal(t, defaultBillingDBProbeInterval, health.interval)
+	health.Start()
+	health.Stop()
+}
+
+func TestCheckBillingEligibility_DBOutageFailClosed(t *testing.T) {
+	health := newBillingDBHealthForTest(&billingDBPingerStub{}, config.BillingDBOutageModeFailClosed)
+	health.unavailable.Store(true)
+
+	svc := NewBillingCacheService(&billingCacheWorkerStub{}, &billingDBDownUserRepoStub{}, nil, nil, nil, nil, &config.Config{})
+	t.Cleanup(svc.Stop)
+	svc.SetBillingDBHealth(health)
+
+	err := svc.CheckBillingEligibility(context.Background(), &User{ID: 1}, &APIKey{ID: 2}, nil, nil)
+	require.ErrorIs(t, err, ErrBillingDatabaseUnavailable)
+}
+
+func TestCheckBillingEligibility_DBOutageFailOpen(t *testing.T) {
+	health := newBillingDBHealthForTest(&billingDBPingerStub{}, config.BillingDBOutageModeFailOpen)
+	svc := NewBillingCacheService(&billingCacheWorkerStub{}, &billingDBDownUserRepoStub{}, nil, nil, nil, nil, &config.Config{})
+	t.Cleanup(svc.Stop)
+	svc.SetBillingDBHealth(health)
+
+	// 数据库可用时，余额回源失败仍按原逻辑拒绝
+	err := svc.CheckBillingEligibility(context.Background(), &User{ID: 1}, &APIKey{ID: 2}, nil, nil)
+	require.ErrorIs(t, err, ErrBillingServiceUnavailable)
+
+	// 数据库判定不可用后放行，使用量由本地溢出日志兜底
+	health.unavailable.Store(true)
+	require.NoError(t, svc.CheckBillingEligibility(context.Background(), &User{ID: 1}, &APIKey{ID: 2}, nil, nil))
+}
+
+func TestUsageRecordJournalReplayer_SkipsWhileDBUnavailable(t *testing.T) {
+	journal := newUsageRecordJournalForTest(t)
+	require.NoError(t, journal.Append(newUsageRecordJournalEntry(UsageRecordJournalReasonApplyFailed, &UsageBillingCommand{RequestID: "req-1"}, nil)))
+
+	health := newBillingDBHealthForTest(&billingDBPingerStub{}, config.BillingDBOutageModeFailOpen)
+	health.unavailable.Store(true)
+	billingRepo := &openAIRecordUsageBillingRepoStub{}
+	replayer := NewUsageRecordJournalReplayer(journal, billingRepo, nil, nil, health, nil)
+
+	replayer.replayOnce()
+	require.Zero(t, billingRepo.calls)
+
+	health.unavailable.Store(false)
+	replayer.replayOnce()
+	require.Equal(t, 1, billingRepo.calls)
+}
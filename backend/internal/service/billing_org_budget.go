@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// orgBudgetCacheTTL 组织预算状态的进程内缓存时间。
+// 组织消费需要聚合 usage_logs，不适合每请求查询；预算超出后最多延迟一个 TTL 生效。
+const orgBudgetCacheTTL = 30 * time.Second
+
+type orgBudgetCacheEntry struct {
+	err       error
+	expiresAt time.Time
+}
+
+// SetOrganizationRepository 启用组织月度预算校验
+func (s *BillingCacheService) SetOrganizationRepository(repo OrganizationRepository) {
+	s.orgRepo = repo
+}
+
+// checkOrganizationBudget 校验用户所属组织：组织停用或本月消费已达 monthly_budget_usd 时拒绝。
+// 查询失败时 fail-open（打 warning，不阻塞业务）。
+func (s *BillingCacheService) checkOrganizationBudget(ctx context.Context, user *User) error {
+	if s == nil || s.orgRepo == nil || user == nil || user.OrganizationID == nil {
+		return nil
+	}
+	orgID := *user.OrganizationID
+	now := time.Now()
+	if v, ok := s.orgBudgetCache.Load(orgID); ok {
+		if entry := v.(*orgBudgetCacheEntry); now.Before(entry.expiresAt) {
+			return entry.err
+		}
+	}
+
+	result, err := s.loadOrganizationBudgetState(ctx, orgID)
+	if err != nil {
+		logger.LegacyPrintf("service.billing_cache", "Warning: load organization budget failed: org_id=%d err=%v", orgID, err)
+		return nil
+	}
+	s.orgBudgetCache.Store(orgID, &orgBudgetCacheEntry{err: result, expiresAt: now.Add(orgBudgetCacheTTL)})
+	return result
+}
+
+// loadOrganizationBudgetState 返回组织当前的准入结果（nil = 放行）与查询错误
+func (s *BillingCacheService) loadOrganizationBudgetState(ctx context.Context, orgID int64) (error, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !org.IsActive() {
+		return ErrOrganizationDisabled, nil
+	}
+	if !org.HasBudget() {
+		return nil, nil
+	}
+	spend, err := s.orgRepo.GetSpendSince(ctx, orgID, timezone.StartOfMonth(timezone.Now()))
+	if err != nil {
+		return nil, err
+	}
+	if spend >= org.MonthlyBudgetUSD {
+		return ErrOrganizationBudgetExceeded, nil
+	}
+	return nil, nil
+}
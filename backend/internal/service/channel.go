@@ -14,6 +14,7 @@ const (
 	BillingModeToken      BillingMode = "token"       // 按 token 区间计费
 	BillingModePerRequest BillingMode = "per_request" // 按次计费（支持上下文窗口分层）
 	BillingModeImage      BillingMode = "image"       // 图片计费（当前按次，预留 token 计费）
+	BillingModeFree       BillingMode = "free"        // 自托管模型零成本（仅记录用量，不用于渠道定价）
 )
 
 // IsValid 检查 BillingMode 是否为合法值
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/tidwall/gjson"
+)
+
+// Chat Completions 流式用量补发：客户端请求了 stream_options.include_usage 而上游流未携带用量时
+// （转换路径上游缺少最终用量事件、第三方上游忽略 include_usage 等），在 [DONE] 前合成一个纯用量 chunk，
+// 让 OpenAI SDK 的用量统计在各条路径上表现一致。仅影响返回给客户端的内容，不改变计费用量。
+
+// estimateChatPromptTokens 按请求体中的文本字段估算 prompt tokens（仅在请求了 include_usage 的流式请求上计算）
+func estimateChatPromptTokens(body []byte, includeUsage bool) int {
+	if !includeUsage || len(body) == 0 {
+		return 0
+	}
+	return estimateContextTokens(gjson.ParseBytes(body))
+}
+
+// chatStreamUsageFallback 合成补发的用量：网关已提取到的用量优先，
+// 缺失的 prompt / completion tokens 分别按请求体估算值与已输出字符数估算。
+func chatStreamUsageFallback(inputTokens, outputTokens, cachedTokens, estimatedPromptTokens, outputChars int) *apicompat.ChatUsage {
+	if inputTokens <= 0 && cachedTokens <= 0 {
+		inputTokens = estimatedPromptTokens
+	}
+	if outputTokens <= 0 {
+		outputTokens = estimatePartialOutputTokens(outputChars)
+	}
+	usage := &apicompat.ChatUsage{
+		PromptTokens:     inputTokens,
+		CompletionTokens: outputTokens,
+		TotalTokens:      inputTokens + outputTokens,
+	}
+	if cachedTokens > 0 {
+		usage.PromptTokensDetails = &apicompat.ChatTokenDetails{CachedTokens: cachedTokens}
+	}
+	return usage
+}
+
+// chatChunkOutputChars 返回透传的 Chat Completions chunk 中增量内容（文本/推理/工具参数）的字符数
+func chatChunkOutputChars(data []byte) int {
+	delta := gjson.GetBytes(data, "choices.0.delta")
+	if !delta.Exists() {
+		return 0
+	}
+	chars := utf8.RuneCountInString(delta.Get("content").String()) +
+		utf8.RuneCountInString(delta.Get("reasoning_content").String())
+	delta.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+		chars += utf8.RuneCountInString(call.Get("function.arguments").String())
+		return true
+	})
+	return chars
+}
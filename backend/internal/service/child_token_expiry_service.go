@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// childTokenSweepBatchSize 每轮清理的过期子令牌上限，未清理完的留到下一轮
+const childTokenSweepBatchSize = 500
+
+// ChildTokenExpiryService periodically deletes expired child tokens and refunds their unused budget to the parent key.
+type ChildTokenExpiryService struct {
+	apiKeyService *APIKeyService
+	interval      time.Duration
+	stopCh        chan struct{}
+	stopOnce      sync.Once
+	wg            sync.WaitGroup
+}
+
+func NewChildTokenExpiryService(apiKeyService *APIKeyService, interval time.Duration) *ChildTokenExpiryService {
+	return &ChildTokenExpiryService{
+		apiKeyService: apiKeyService,
+		interval:      interval,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+func (s *ChildTokenExpiryService) Start() {
+	if s == nil || s.apiKeyService == nil || s.interval <= 0 {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.runOnce()
+		for {
+			select {
+			case <-ticker.C:
+				s.runOnce()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (s *ChildTokenExpiryService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+func (s *ChildTokenExpiryService) runOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	swept, err := s.apiKeyService.SweepExpiredChildTokens(ctx, time.Now(), childTokenSweepBatchSize)
+	if err != nil {
+		log.Printf("[ChildTokenExpiry] Sweep expired child tokens failed (swept=%d): %v", swept, err)
+		return
+	}
+	if swept > 0 {
+		log.Printf("[ChildTokenExpiry] Deleted %d expired child tokens", swept)
+	}
+}
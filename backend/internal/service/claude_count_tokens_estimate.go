@@ -0,0 +1,167 @@
+package service
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// CountTokensEstimateHeader 标记 count_tokens 响应为本地估算值
+const CountTokensEstimateHeader = "X-Sub2api-Token-Estimate"
+
+// countTokensUnsupportedTTL 上游 count_tokens 无权限（403/404）后改用本地估算的时长，到期后重新尝试上游
+const countTokensUnsupportedTTL = time.Hour
+
+// claudeTokenProfile 按模型族校准的本地 token 估算参数
+type claudeTokenProfile struct {
+	// asciiCharsPerToken 英文/代码等 ASCII 文本平均每 token 字符数
+	asciiCharsPerToken float64
+	// wideRunesPerToken CJK 等非 ASCII 字符平均每 token 字符数
+	wideRunesPerToken float64
+	// messageOverhead 每条消息的角色/分隔符开销
+	messageOverhead int
+	// toolUseSystemOverhead 请求携带工具时上游注入的工具使用系统提示开销
+	toolUseSystemOverhead int
+}
+
+var (
+	// Claude 3.x（含 3.5/3.7）分词器
+	claude3TokenProfile = claudeTokenProfile{asciiCharsPerToken: 3.8, wideRunesPerToken: 1.1, messageOverhead: 4, toolUseSystemOverhead: 346}
+	// Claude 4.x 分词器对同样文本产生更多 token
+	claude4TokenProfile = claudeTokenProfile{asciiCharsPerToken: 3.4, wideRunesPerToken: 1.0, messageOverhead: 4, toolUseSystemOverhead: 346}
+)
+
+// claudeImageTokenEstimate 单张图片的估算 token 数（Anthropic 对长边 1568px 以内图片约为 w*h/750，上限约 1600）
+const claudeImageTokenEstimate = 1600
+
+// claudeTokenProfileForModel 按模型名选择估算参数，未知模型使用 Claude 4.x 参数
+func claudeTokenProfileForModel(model string) claudeTokenProfile {
+	m := strings.ToLower(model)
+	if strings.HasPrefix(m, "claude-3") || strings.HasPrefix(m, "claude-2") || strings.HasPrefix(m, "claude-instant") {
+		return claude3TokenProfile
+	}
+	return claude4TokenProfile
+}
+
+// EstimateClaudeInputTokens 本地估算 Anthropic Messages 请求的输入 token 数，
+// 用于上游 count_tokens 不可用的账号（setup-token、无权限的 API Key）。
+// 覆盖 system、消息文本、工具调用/结果、图片与工具定义；历史 thinking 块不计入（与上游一致）。
+func EstimateClaudeInputTokens(body []byte, model string) int {
+	profile := claudeTokenProfileForModel(model)
+	root := gjson.ParseBytes(body)
+
+	total := 0
+	textTokens := profile.textTokens
+
+	system := root.Get("system")
+	if system.Type == gjson.String {
+		total += textTokens(system.String())
+	} else {
+		total += profile.blocksTokens(system)
+	}
+
+	root.Get("messages").ForEach(func(_, msg gjson.Result) bool {
+		total += profile.messageOverhead
+		content := msg.Get("content")
+		if content.Type == gjson.String {
+			total += textTokens(content.String())
+		} else {
+			total += profile.blocksTokens(content)
+		}
+		return true
+	})
+
+	tools := root.Get("tools")
+	if tools.IsArray() && len(tools.Array()) > 0 {
+		total += profile.toolUseSystemOverhead
+		tools.ForEach(func(_, tool gjson.Result) bool {
+			total += textTokens(tool.Get("name").String())
+			total += textTokens(tool.Get("description").String())
+			if schema := tool.Get("input_schema"); schema.Exists() {
+				total += textTokens(schema.Raw)
+			}
+			return true
+		})
+	}
+
+	if total < 1 {
+		return 1
+	}
+	return total
+}
+
+// blocksTokens 累计内容块数组的估算 token 数
+func (p claudeTokenProfile) blocksTokens(blocks gjson.Result) int {
+	total := 0
+	blocks.ForEach(func(_, block gjson.Result) bool {
+		switch block.Get("type").String() {
+		case "text":
+			total += p.textTokens(block.Get("text").String())
+		case "image":
+			total += claudeImageTokenEstimate
+		case "document":
+			if data := block.Get("source.data"); block.Get("source.type").String() == "text" {
+				total += p.textTokens(data.String())
+			} else {
+				total += claudeImageTokenEstimate
+			}
+		case "tool_use", "server_tool_use":
+			total += p.textTokens(block.Get("name").String())
+			total += p.textTokens(block.Get("input").Raw)
+		case "tool_result":
+			content := block.Get("content")
+			if content.Type == gjson.String {
+				total += p.textTokens(content.String())
+			} else {
+				total += p.blocksTokens(content)
+			}
+		}
+		return true
+	})
+	return total
+}
+
+// textTokens 按 ASCII 与宽字符分别折算 token 数
+func (p claudeTokenProfile) textTokens(s string) int {
+	if s == "" {
+		return 0
+	}
+	ascii, wide := 0, 0
+	for _, r := range s {
+		if r <= unicode.MaxASCII {
+			ascii++
+		} else {
+			wide++
+		}
+	}
+	return int(math.Ceil(float64(ascii)/p.asciiCharsPerToken + float64(wide)/p.wideRunesPerToken))
+}
+
+// respondEstimatedCountTokens 以 count_tokens 响应格式返回本地估算值
+func (s *GatewayService) respondEstimatedCountTokens(c *gin.Context, parsed *ParsedRequest, model string) {
+	c.Header(CountTokensEstimateHeader, "true")
+	c.JSON(http.StatusOK, gin.H{"input_tokens": EstimateClaudeInputTokens(parsed.Body, model)})
+}
+
+// countTokensUnsupported 账号近期是否已确认无 count_tokens 权限
+func (s *GatewayService) countTokensUnsupported(accountID int64) bool {
+	v, ok := s.countTokensUnsupportedUntil.Load(accountID)
+	if !ok {
+		return false
+	}
+	if time.Now().Before(v.(time.Time)) {
+		return true
+	}
+	s.countTokensUnsupportedUntil.Delete(accountID)
+	return false
+}
+
+// markCountTokensUnsupported 记录账号无 count_tokens 权限，TTL 内直接本地估算
+func (s *GatewayService) markCountTokensUnsupported(accountID int64) {
+	s.countTokensUnsupportedUntil.Store(accountID, time.Now().Add(countTokensUnsupportedTTL))
+}
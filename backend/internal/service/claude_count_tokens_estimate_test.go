@@ -0,0 +1,105 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestEstimateClaudeInputTokens_CountsAllParts(t *testing.T) {
+	base := EstimateClaudeInputTokens([]byte(`{"messages":[{"role":"user","content":"hello world"}]}`), "claude-sonnet-4-5")
+	require.Greater(t, base, 0)
+
+	withSystem := EstimateClaudeInputTokens([]byte(`{"system":"You are a helpful assistant.","messages":[{"role":"user","content":"hello world"}]}`), "claude-sonnet-4-5")
+	require.Greater(t, withSystem, base)
+
+	withImage := EstimateClaudeInputTokens([]byte(`{"messages":[{"role":"user","content":[{"type":"text","text":"hello world"},{"type":"image","source":{"type":"base64","media_type":"image/png","data":"AAAA"}}]}]}`), "claude-sonnet-4-5")
+	require.Equal(t, base+claudeImageTokenEstimate, withImage)
+
+	withTools := EstimateClaudeInputTokens([]byte(`{"messages":[{"role":"user","content":"hello world"}],"tools":[{"name":"get_weather","description":"Get weather","input_schema":{"type":"object"}}]}`), "claude-sonnet-4-5")
+	require.Greater(t, withTools, base+claude4TokenProfile.toolUseSystemOverhead)
+
+	toolTurn := EstimateClaudeInputTokens([]byte(`{"messages":[{"role":"user","content":"hello world"},{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"get_weather","input":{"city":"Tokyo"}}]},{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"sunny"}]}]}]}`), "claude-sonnet-4-5")
+	require.Greater(t, toolTurn, base+2*claude4TokenProfile.messageOverhead)
+}
+
+func TestEstimateClaudeInputTokens_IgnoresThinkingAndFamilies(t *testing.T) {
+	plain := []byte(`{"messages":[{"role":"user","content":"hi"},{"role":"assistant","content":[{"type":"text","text":"ok"}]}]}`)
+	thinking := []byte(`{"messages":[{"role":"user","content":"hi"},{"role":"assistant","content":[{"type":"thinking","thinking":"long reasoning here","signature":"sig"},{"type":"text","text":"ok"}]}]}`)
+	require.Equal(t, EstimateClaudeInputTokens(plain, "claude-opus-4-1"), EstimateClaudeInputTokens(thinking, "claude-opus-4-1"))
+
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 50)
+	body := []byte(`{"messages":[{"role":"user","content":"` + text + `"}]}`)
+	require.Greater(t, EstimateClaudeInputTokens(body, "claude-sonnet-4-5"), EstimateClaudeInputTokens(body, "claude-3-5-sonnet-20241022"))
+
+	require.Equal(t, 1, EstimateClaudeInputTokens([]byte(`{}`), ""))
+}
+
+func newCountTokensTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages/count_tokens", nil)
+	return c, rec
+}
+
+func TestGatewayService_ForwardCountTokens_SetupTokenEstimatesLocally(t *testing.T) {
+	c, rec := newCountTokensTestContext()
+	upstream := &anthropicHTTPUpstreamRecorder{}
+	svc := &GatewayService{cfg: &config.Config{}, httpUpstream: upstream}
+	account := &Account{ID: 401, Platform: PlatformAnthropic, Type: AccountTypeSetupToken, Credentials: map[string]any{"access_token": "tok"}}
+	parsed := &ParsedRequest{Body: []byte(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hello"}]}`), Model: "claude-sonnet-4-5"}
+
+	require.NoError(t, svc.ForwardCountTokens(context.Background(), c, account, parsed))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(CountTokensEstimateHeader))
+	require.Greater(t, gjson.Get(rec.Body.String(), "input_tokens").Int(), int64(0))
+	require.Nil(t, upstream.lastReq, "setup-token 账号不应调用上游 count_tokens")
+}
+
+func TestGatewayService_ForwardCountTokens_APIKeyForbiddenFallsBackAndRemembers(t *testing.T) {
+	upstream := &anthropicHTTPUpstreamRecorder{
+		resp: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"type":"error","error":{"type":"not_found_error","message":"Not Found"}}`)),
+		},
+	}
+	svc := &GatewayService{
+		cfg:              &config.Config{Gateway: config.GatewayConfig{MaxLineSize: defaultMaxLineSize}},
+		httpUpstream:     upstream,
+		rateLimitService: &RateLimitService{},
+	}
+	account := &Account{
+		ID:          402,
+		Platform:    PlatformAnthropic,
+		Type:        AccountTypeAPIKey,
+		Concurrency: 1,
+		Credentials: map[string]any{"api_key": "upstream-key", "base_url": "https://relay.example.com"},
+	}
+	parsed := &ParsedRequest{Body: []byte(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hello"}]}`), Model: "claude-sonnet-4-5"}
+
+	c, rec := newCountTokensTestContext()
+	require.NoError(t, svc.ForwardCountTokens(context.Background(), c, account, parsed))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(CountTokensEstimateHeader))
+	require.NotNil(t, upstream.lastReq)
+	require.True(t, svc.countTokensUnsupported(account.ID))
+
+	// 记住后不再请求上游
+	upstream.lastReq = nil
+	c, rec = newCountTokensTestContext()
+	require.NoError(t, svc.ForwardCountTokens(context.Background(), c, account, parsed))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Nil(t, upstream.lastReq)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// 优先级等待队列作用域
+const (
+	PriorityWaitScopeUser    = "user"
+	PriorityWaitScopeAccount = "account"
+)
+
+// SetPriorityQueueEnabled 开关优先级等待队列。关闭时等待逻辑退化为原有的 FIFO 轮询，不产生额外 Redis 调用。
+func (s *ConcurrencyService) SetPriorityQueueEnabled(enabled bool) {
+	if s == nil {
+		return
+	}
+	s.priorityQueueEnabled = enabled
+}
+
+// PriorityQueueEnabled 返回优先级等待队列是否启用（需要 Redis 可用）。
+func (s *ConcurrencyService) PriorityQueueEnabled() bool {
+	return s != nil && s.cache != nil && s.priorityQueueEnabled
+}
+
+// EnterPriorityWait 将请求登记到对应优先级的等待队列，返回离开队列的函数（必须调用）。
+// 登记失败时放行（fail open），返回 no-op。
+func (s *ConcurrencyService) EnterPriorityWait(ctx context.Context, scope string, id int64, priority string) func() {
+	if !s.PriorityQueueEnabled() {
+		return func() {}
+	}
+	rank := PriorityRank(priority)
+	requestID := generateRequestID()
+	if err := s.cache.AddPriorityWaiter(ctx, scope, id, rank, requestID); err != nil {
+		logger.LegacyPrintf("service.concurrency", "Warning: add priority waiter failed for %s %d: %v", scope, id, err)
+		return func() {}
+	}
+	return func() {
+		bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.cache.RemovePriorityWaiter(bgCtx, scope, id, rank, requestID); err != nil {
+			logger.LegacyPrintf("service.concurrency", "Warning: remove priority waiter failed for %s %d (req=%s): %v", scope, id, requestID, err)
+		}
+	}
+}
+
+// ShouldYieldToHigherPriority 判断当前请求是否应让位于更高优先级的等待者（暂不尝试获取槽位）。
+// 最高优先级永不让位；Redis 出错时不让位，避免请求被无限阻塞。
+func (s *ConcurrencyService) ShouldYieldToHigherPriority(ctx context.Context, scope string, id int64, priority string) bool {
+	if !s.PriorityQueueEnabled() {
+		return false
+	}
+	rank := PriorityRank(priority)
+	if rank == 0 {
+		return false
+	}
+	yield, err := s.cache.HasHigherPriorityWaiters(ctx, scope, id, rank)
+	if err != nil {
+		logger.LegacyPrintf("service.concurrency", "Warning: check priority waiters failed for %s %d: %v", scope, id, err)
+		return false
+	}
+	return yield
+}
@@ -0,0 +1,62 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyEffectivePriority(t *testing.T) {
+	var nilKey *APIKey
+	require.Equal(t, PriorityNormal, nilKey.EffectivePriority())
+	require.Equal(t, PriorityNormal, (&APIKey{}).EffectivePriority())
+	require.Equal(t, PriorityLow, (&APIKey{Group: &Group{Priority: PriorityLow}}).EffectivePriority())
+	require.Equal(t, PriorityHigh, (&APIKey{Priority: PriorityHigh, Group: &Group{Priority: PriorityLow}}).EffectivePriority())
+	require.Equal(t, PriorityLow, (&APIKey{Priority: "urgent", Group: &Group{Priority: PriorityLow}}).EffectivePriority())
+}
+
+func TestPriorityWait_DisabledIsNoop(t *testing.T) {
+	cache := &stubConcurrencyCacheForTest{}
+	svc := NewConcurrencyService(cache)
+
+	leave := svc.EnterPriorityWait(context.Background(), PriorityWaitScopeUser, 1, PriorityHigh)
+	leave()
+	require.Empty(t, cache.priorityWaiters)
+	require.False(t, svc.ShouldYieldToHigherPriority(context.Background(), PriorityWaitScopeUser, 1, PriorityLow))
+}
+
+func TestPriorityWait_LowerPriorityYields(t *testing.T) {
+	ctx := context.Background()
+	cache := &stubConcurrencyCacheForTest{}
+	svc := NewConcurrencyService(cache)
+	svc.SetPriorityQueueEnabled(true)
+
+	leaveNormal := svc.EnterPriorityWait(ctx, PriorityWaitScopeUser, 1, PriorityNormal)
+	require.True(t, svc.ShouldYieldToHigherPriority(ctx, PriorityWaitScopeUser, 1, PriorityLow))
+	require.False(t, svc.ShouldYieldToHigherPriority(ctx, PriorityWaitScopeUser, 1, PriorityNormal))
+	require.False(t, svc.ShouldYieldToHigherPriority(ctx, PriorityWaitScopeAccount, 1, PriorityLow), "scopes are independent")
+
+	leaveHigh := svc.EnterPriorityWait(ctx, PriorityWaitScopeUser, 1, PriorityHigh)
+	require.True(t, svc.ShouldYieldToHigherPriority(ctx, PriorityWaitScopeUser, 1, PriorityNormal))
+	require.False(t, svc.ShouldYieldToHigherPriority(ctx, PriorityWaitScopeUser, 1, PriorityHigh))
+
+	leaveHigh()
+	leaveNormal()
+	require.False(t, svc.ShouldYieldToHigherPriority(ctx, PriorityWaitScopeUser, 1, PriorityLow))
+}
+
+func TestPriorityWait_FailOpen(t *testing.T) {
+	ctx := context.Background()
+	cache := &stubConcurrencyCacheForTest{priorityWaiterErr: errors.New("redis down")}
+	svc := NewConcurrencyService(cache)
+	svc.SetPriorityQueueEnabled(true)
+
+	leave := svc.EnterPriorityWait(ctx, PriorityWaitScopeAccount, 2, PriorityHigh)
+	require.NotNil(t, leave)
+	leave()
+	require.False(t, svc.ShouldYieldToHigherPriority(ctx, PriorityWaitScopeAccount, 2, PriorityLow))
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"os"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -37,6 +38,13 @@ type ConcurrencyCache interface {
 	IncrementWaitCount(ctx context.Context, userID int64, maxWait int) (bool, error)
 	DecrementWaitCount(ctx context.Context, userID int64) error
 
+	// 优先级等待队列（每个优先级一个有序集合，成员为 requestID，分数为加入时间）
+	// 键格式: concurrency:prio_wait:{scope}:{id}:{rank}
+	AddPriorityWaiter(ctx context.Context, scope string, id int64, rank int, requestID string) error
+	RemovePriorityWaiter(ctx context.Context, scope string, id int64, rank int, requestID string) error
+	// HasHigherPriorityWaiters 判断是否存在排序值小于 rank（优先级更高）的等待者
+	HasHigherPriorityWaiters(ctx context.Context, scope string, id int64, rank int) (bool, error)
+
 	// 批量负载查询（只读）
 	GetAccountsLoadBatch(ctx context.Context, accounts []AccountWithConcurrency) (map[int64]*AccountLoadInfo, error)
 	GetUsersLoadBatch(ctx context.Context, users []UserWithConcurrency) (map[int64]*UserLoadInfo, error)
@@ -46,6 +54,9 @@ type ConcurrencyCache interface {
 
 	// 启动时清理旧进程遗留槽位与等待计数
 	CleanupStaleProcessSlots(ctx context.Context, activeRequestPrefix string) error
+
+	// 槽位占用快照（只读，扫描所有账号 / 用户槽位键）
+	ListSlotOccupancy(ctx context.Context) ([]SlotOccupancy, error)
 }
 
 var (
@@ -86,6 +97,14 @@ const (
 // ConcurrencyService manages concurrent request limiting for accounts and users
 type ConcurrencyService struct {
 	cache ConcurrencyCache
+	// priorityQueueEnabled 启用后，等待槽位时高优先级请求先于低优先级请求获得槽位
+	priorityQueueEnabled bool
+
+	// 本实例持有的槽位登记与卡死槽位回收（见 concurrency_slot_reaper.go）
+	slotsMu     sync.Mutex
+	heldSlots   map[string]*heldSlot
+	maxSlotHold time.Duration
+	reapedSlots atomic.Int64
 }
 
 // NewConcurrencyService creates a new ConcurrencyService
@@ -144,9 +163,11 @@ func (s *ConcurrencyService) AcquireAccountSlot(ctx context.Context, accountID i
 	}
 
 	if acquired {
+		s.trackSlot(ctx, SlotScopeAccount, accountID, requestID)
 		return &AcquireResult{
 			Acquired: true,
 			ReleaseFunc: func() {
+				s.untrackSlot(requestID)
 				bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
 				if err := s.cache.ReleaseAccountSlot(bgCtx, accountID, requestID); err != nil {
@@ -183,9 +204,11 @@ func (s *ConcurrencyService) AcquireUserSlot(ctx context.Context, userID int64,
 	}
 
 	if acquired {
+		s.trackSlot(ctx, SlotScopeUser, userID, requestID)
 		return &AcquireResult{
 			Acquired: true,
 			ReleaseFunc: func() {
+				s.untrackSlot(requestID)
 				bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
 				if err := s.cache.ReleaseUserSlot(bgCtx, userID, requestID); err != nil {
@@ -28,10 +28,15 @@ type stubConcurrencyCacheForTest struct {
 	usersLoadBatch map[int64]*UserLoadInfo
 	usersLoadErr   error
 	cleanupErr     error
+	occupancy      []SlotOccupancy
 
 	// 记录调用
 	releasedAccountIDs []int64
 	releasedRequestIDs []string
+
+	// 优先级等待者：key 为 scope:id:rank
+	priorityWaiters   map[string]map[string]struct{}
+	priorityWaiterErr error
 }
 
 var _ ConcurrencyCache = (*stubConcurrencyCacheForTest)(nil)
@@ -94,6 +99,45 @@ func (c *stubConcurrencyCacheForTest) CleanupExpiredAccountSlots(_ context.Conte
 func (c *stubConcurrencyCacheForTest) CleanupStaleProcessSlots(_ context.Context, _ string) error {
 	return c.cleanupErr
 }
+func (c *stubConcurrencyCacheForTest) ListSlotOccupancy(context.Context) ([]SlotOccupancy, error) {
+	return c.occupancy, nil
+}
+
+func priorityWaiterKeyForTest(scope string, id int64, rank int) string {
+	return scope + ":" + strconv.FormatInt(id, 10) + ":" + strconv.Itoa(rank)
+}
+
+func (c *stubConcurrencyCacheForTest) AddPriorityWaiter(_ context.Context, scope string, id int64, rank int, requestID string) error {
+	if c.priorityWaiterErr != nil {
+		return c.priorityWaiterErr
+	}
+	if c.priorityWaiters == nil {
+		c.priorityWaiters = make(map[string]map[string]struct{})
+	}
+	key := priorityWaiterKeyForTest(scope, id, rank)
+	if c.priorityWaiters[key] == nil {
+		c.priorityWaiters[key] = make(map[string]struct{})
+	}
+	c.priorityWaiters[key][requestID] = struct{}{}
+	return nil
+}
+
+func (c *stubConcurrencyCacheForTest) RemovePriorityWaiter(_ context.Context, scope string, id int64, rank int, requestID string) error {
+	delete(c.priorityWaiters[priorityWaiterKeyForTest(scope, id, rank)], requestID)
+	return nil
+}
+
+func (c *stubConcurrencyCacheForTest) HasHigherPriorityWaiters(_ context.Context, scope string, id int64, rank int) (bool, error) {
+	if c.priorityWaiterErr != nil {
+		return false, c.priorityWaiterErr
+	}
+	for r := 0; r < rank; r++ {
+		if len(c.priorityWaiters[priorityWaiterKeyForTest(scope, id, r)]) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
 type trackingConcurrencyCache struct {
 	stubConcurrencyCacheForTest
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// 并发槽位追踪与卡死槽位回收：
+// 本实例获取的每个槽位都登记在内存中（含 HTTP 请求 ID），ReleaseFunc 调用时注销。
+// 回收任务定期检查持有时间超过 concurrency.max_slot_hold_seconds 的槽位——
+// 这类槽位通常是某条罕见路径跳过了 ReleaseFunc——强制从 Redis 释放并记录请求 ID。
+// 被回收后原 ReleaseFunc 再被调用也是安全的（ZREM 不存在的成员为空操作）。
+
+const (
+	SlotScopeAccount = "account"
+	SlotScopeUser    = "user"
+)
+
+// heldSlot 本实例登记的已获取槽位
+type heldSlot struct {
+	scope      string
+	ownerID    int64
+	slotID     string
+	requestID  string
+	acquiredAt time.Time
+}
+
+// HeldSlotInfo 本实例当前持有的槽位
+type HeldSlotInfo struct {
+	Scope       string    `json:"scope"`
+	OwnerID     int64     `json:"owner_id"`
+	SlotID      string    `json:"slot_id"`
+	RequestID   string    `json:"request_id,omitempty"`
+	AcquiredAt  time.Time `json:"acquired_at"`
+	HeldSeconds int64     `json:"held_seconds"`
+}
+
+// SlotOccupancy Redis 中单个账号 / 用户的槽位占用（所有实例合计）
+type SlotOccupancy struct {
+	Scope            string     `json:"scope"`
+	OwnerID          int64      `json:"owner_id"`
+	Count            int        `json:"count"`
+	OldestAcquiredAt *time.Time `json:"oldest_acquired_at,omitempty"`
+}
+
+// ConcurrencySlotSnapshot 槽位占用快照
+type ConcurrencySlotSnapshot struct {
+	Accounts       []SlotOccupancy `json:"accounts"`
+	Users          []SlotOccupancy `json:"users"`
+	LocalHeld      []HeldSlotInfo  `json:"local_held"`
+	MaxHoldSeconds int64           `json:"max_hold_seconds"`
+	ReapedTotal    int64           `json:"reaped_total"`
+	CollectedAt    time.Time       `json:"collected_at"`
+}
+
+// trackSlot 登记已获取的槽位
+func (s *ConcurrencyService) trackSlot(ctx context.Context, scope string, ownerID int64, slotID string) {
+	requestID, _ := ctx.Value(ctxkey.RequestID).(string)
+	s.slotsMu.Lock()
+	if s.heldSlots == nil {
+		s.heldSlots = make(map[string]*heldSlot)
+	}
+	s.heldSlots[slotID] = &heldSlot{
+		scope:      scope,
+		ownerID:    ownerID,
+		slotID:     slotID,
+		requestID:  requestID,
+		acquiredAt: time.Now(),
+	}
+	s.slotsMu.Unlock()
+}
+
+// untrackSlot 注销槽位登记
+func (s *ConcurrencyService) untrackSlot(slotID string) {
+	s.slotsMu.Lock()
+	delete(s.heldSlots, slotID)
+	s.slotsMu.Unlock()
+}
+
+// StartSlotReaper 启动卡死槽位回收任务；maxHold 或 interval 非正数时不启动。
+func (s *ConcurrencyService) StartSlotReaper(maxHold, interval time.Duration) {
+	if s == nil || s.cache == nil || maxHold <= 0 || interval <= 0 {
+		return
+	}
+	s.slotsMu.Lock()
+	s.maxSlotHold = maxHold
+	s.slotsMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.reapStuckSlots(time.Now())
+		}
+	}()
+}
+
+// reapStuckSlots 强制释放持有时间超过上限的槽位，返回回收数量
+func (s *ConcurrencyService) reapStuckSlots(now time.Time) int {
+	s.slotsMu.Lock()
+	maxHold := s.maxSlotHold
+	if maxHold <= 0 {
+		s.slotsMu.Unlock()
+		return 0
+	}
+	var stuck []*heldSlot
+	for id, slot := range s.heldSlots {
+		if now.Sub(slot.acquiredAt) > maxHold {
+			stuck = append(stuck, slot)
+			delete(s.heldSlots, id)
+		}
+	}
+	s.slotsMu.Unlock()
+
+	for _, slot := range stuck {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		var err error
+		if slot.scope == SlotScopeUser {
+			err = s.cache.ReleaseUserSlot(ctx, slot.ownerID, slot.slotID)
+		} else {
+			err = s.cache.ReleaseAccountSlot(ctx, slot.ownerID, slot.slotID)
+		}
+		cancel()
+		if err != nil {
+			logger.LegacyPrintf("service.concurrency", "Warning: failed to reap stuck %s slot for %d (slot=%s request_id=%s): %v",
+				slot.scope, slot.ownerID, slot.slotID, slot.requestID, err)
+			continue
+		}
+		s.reapedSlots.Add(1)
+		logger.LegacyPrintf("service.concurrency", "Warning: reaped stuck %s slot for %d (slot=%s request_id=%s held=%s)",
+			slot.scope, slot.ownerID, slot.slotID, slot.requestID, now.Sub(slot.acquiredAt).Round(time.Second))
+	}
+	return len(stuck)
+}
+
+// GetSlotSnapshot 返回 Redis 中各账号 / 用户的槽位占用，以及本实例持有的槽位与回收统计
+func (s *ConcurrencyService) GetSlotSnapshot(ctx context.Context) (*ConcurrencySlotSnapshot, error) {
+	now := time.Now()
+	snapshot := &ConcurrencySlotSnapshot{
+		Accounts:    []SlotOccupancy{},
+		Users:       []SlotOccupancy{},
+		LocalHeld:   []HeldSlotInfo{},
+		ReapedTotal: s.reapedSlots.Load(),
+		CollectedAt: now.UTC(),
+	}
+	if s.cache == nil {
+		return snapshot, nil
+	}
+
+	occupancy, err := s.cache.ListSlotOccupancy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range occupancy {
+		if entry.Scope == SlotScopeUser {
+			snapshot.Users = append(snapshot.Users, entry)
+		} else {
+			snapshot.Accounts = append(snapshot.Accounts, entry)
+		}
+	}
+	byCount := func(list []SlotOccupancy) {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Count != list[j].Count {
+				return list[i].Count > list[j].Count
+			}
+			return list[i].OwnerID < list[j].OwnerID
+		})
+	}
+	byCount(snapshot.Accounts)
+	byCount(snapshot.Users)
+
+	s.slotsMu.Lock()
+	snapshot.MaxHoldSeconds = int64(s.maxSlotHold / time.Second)
+	for _, slot := range s.heldSlots {
+		snapshot.LocalHeld = append(snapshot.LocalHeld, HeldSlotInfo{
+			Scope:       slot.scope,
+			OwnerID:     slot.ownerID,
+			SlotID:      slot.slotID,
+			RequestID:   slot.requestID,
+			AcquiredAt:  slot.acquiredAt.UTC(),
+			HeldSeconds: int64(now.Sub(slot.acquiredAt) / time.Second),
+		})
+	}
+	s.slotsMu.Unlock()
+	sort.Slice(snapshot.LocalHeld, func(i, j int) bool {
+		return snapshot.LocalHeld[i].AcquiredAt.Before(snapshot.LocalHeld[j].AcquiredAt)
+	})
+	return snapshot, nil
+}
@@ -0,0 +1,65 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyService_ReapStuckSlots(t *testing.T) {
+	cache := &stubConcurrencyCacheForTest{acquireResult: true}
+	svc := NewConcurrencyService(cache)
+	svc.maxSlotHold = time.Minute
+
+	ctx := context.WithValue(context.Background(), ctxkey.RequestID, "req-leaked")
+	leaked, err := svc.AcquireAccountSlot(ctx, 7, 2)
+	require.NoError(t, err)
+	require.True(t, leaked.Acquired)
+	released, err := svc.AcquireAccountSlot(context.Background(), 8, 2)
+	require.NoError(t, err)
+	released.ReleaseFunc()
+	require.Equal(t, []int64{8}, cache.releasedAccountIDs)
+
+	// 未超过上限时不回收
+	require.Equal(t, 0, svc.reapStuckSlots(time.Now()))
+
+	require.Equal(t, 1, svc.reapStuckSlots(time.Now().Add(2*time.Minute)))
+	require.Equal(t, []int64{8, 7}, cache.releasedAccountIDs)
+	require.Equal(t, int64(1), svc.reapedSlots.Load())
+
+	snapshot, err := svc.GetSlotSnapshot(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, snapshot.LocalHeld)
+	require.Equal(t, int64(1), snapshot.ReapedTotal)
+	require.Equal(t, int64(60), snapshot.MaxHoldSeconds)
+
+	// 回收后原 ReleaseFunc 仍可安全调用
+	leaked.ReleaseFunc()
+}
+
+func TestConcurrencyService_GetSlotSnapshot(t *testing.T) {
+	cache := &stubConcurrencyCacheForTest{acquireResult: true, occupancy: []SlotOccupancy{
+		{Scope: SlotScopeAccount, OwnerID: 1, Count: 1},
+		{Scope: SlotScopeUser, OwnerID: 5, Count: 2},
+		{Scope: SlotScopeAccount, OwnerID: 2, Count: 3},
+	}}
+	svc := NewConcurrencyService(cache)
+
+	ctx := context.WithValue(context.Background(), ctxkey.RequestID, "req-1")
+	_, err := svc.AcquireUserSlot(ctx, 5, 3)
+	require.NoError(t, err)
+
+	snapshot, err := svc.GetSlotSnapshot(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 1}, []int64{snapshot.Accounts[0].OwnerID, snapshot.Accounts[1].OwnerID})
+	require.Len(t, snapshot.Users, 1)
+	require.Len(t, snapshot.LocalHeld, 1)
+	require.Equal(t, SlotScopeUser, snapshot.LocalHeld[0].Scope)
+	require.Equal(t, "req-1", snapshot.LocalHeld[0].RequestID)
+	require.Zero(t, snapshot.MaxHoldSeconds)
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidModerationMode API Key 内容审核覆盖取值非法
+	ErrInvalidModerationMode = infraerrors.BadRequest("INVALID_MODERATION_MODE", "moderation_mode must be empty or one of enabled, disabled")
+	// ErrContentFlagged 请求内容被内容审核拦截
+	ErrContentFlagged = infraerrors.BadRequest("CONTENT_POLICY_VIOLATION", "Request was blocked by content moderation policy")
+	// ErrModerationUnavailable 审核端点不可用且配置为 fail-closed
+	ErrModerationUnavailable = infraerrors.ServiceUnavailable("MODERATION_UNAVAILABLE", "Content moderation is temporarily unavailable, please retry later")
+)
+
+const (
+	defaultModerationModel   = "omni-moderation-latest"
+	defaultModerationTimeout = 5 * time.Second
+	// moderationResponseMaxBytes 审核端点响应体读取上限
+	moderationResponseMaxBytes = 1 << 20
+)
+
+// IsValidModerationMode 判断是否为合法的 API Key 内容审核覆盖（空字符串表示继承分组）
+func IsValidModerationMode(mode string) bool {
+	return mode == "" || mode == ModerationModeEnabled || mode == ModerationModeDisabled
+}
+
+// ModerationEnabledFor 解析 API Key 的有效审核开关：Key 级 enabled/disabled 优先，否则继承分组。
+func ModerationEnabledFor(apiKey *APIKey) bool {
+	if apiKey == nil {
+		return false
+	}
+	switch apiKey.ModerationMode {
+	case ModerationModeEnabled:
+		return true
+	case ModerationModeDisabled:
+		return false
+	}
+	return apiKey.Group != nil && apiKey.Group.ModerationEnabled
+}
+
+type moderationRule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// ContentModerator 在转发前对用户可见文本执行内容审核：先匹配本地规则，
+// 再调用 OpenAI /moderations 兼容端点；命中时返回 ErrContentFlagged，并记录类别分数。
+type ContentModerator struct {
+	endpoint string
+	apiKey   string
+	model    string
+	failOpen bool
+	blocked  map[string]struct{}
+	rules    []moderationRule
+	client   *http.Client
+	nowFn    func() time.Time
+}
+
+// NewContentModerator 根据网关配置构建审核器。
+// 配置中的非法正则已由 config.Validate 拦截，此处跳过以防御性兜底。
+func NewContentModerator(cfg *config.Config) *ContentModerator {
+	m := &ContentModerator{
+		model:    defaultModerationModel,
+		failOpen: true,
+		client:   &http.Client{Timeout: defaultModerationTimeout},
+		nowFn:    time.Now,
+	}
+	if cfg == nil {
+		return m
+	}
+	mc := cfg.Gateway.Moderation
+	m.endpoint = strings.TrimSpace(mc.Endpoint)
+	m.apiKey = strings.TrimSpace(mc.APIKey)
+	if model := strings.TrimSpace(mc.Model); model != "" {
+		m.model = model
+	}
+	m.failOpen = mc.FailOpen
+	if mc.TimeoutSeconds > 0 {
+		m.client.Timeout = time.Duration(mc.TimeoutSeconds) * time.Second
+	}
+	if len(mc.BlockedCategories) > 0 {
+		m.blocked = make(map[string]struct{}, len(mc.BlockedCategories))
+		for _, category := range mc.BlockedCategories {
+			if category = strings.TrimSpace(category); category != "" {
+				m.blocked[category] = struct{}{}
+			}
+		}
+	}
+	for _, rule := range mc.LocalRules {
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil || rule.Regex == "" {
+			continue
+		}
+		m.rules = append(m.rules, moderationRule{name: strings.TrimSpace(rule.Name), re: re})
+	}
+	return m
+}
+
+// moderationResult 审核端点返回的首条结果
+type moderationResult struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
+
+// Check 对请求体执行内容审核。未启用、无审核手段或无可审核文本时直接放行；
+// 命中本地规则或端点判定拦截时返回 ErrContentFlagged；端点异常时按 fail_open 决定放行或返回 ErrModerationUnavailable。
+// 需在注入分组系统提示词之前调用，避免管理员配置的提示词参与审核。
+func (m *ContentModerator) Check(ctx context.Context, body []byte, apiKey *APIKey, format RequestParamFormat) error {
+	if m == nil || (m.endpoint == "" && len(m.rules) == 0) || !ModerationEnabledFor(apiKey) || !gjson.ValidBytes(body) {
+		return nil
+	}
+	text := moderationInputText(body, format)
+	if text == "" {
+		return nil
+	}
+	log := logger.FromContext(ctx).With(
+		zap.String("component", "service.content_moderation"),
+		zap.Int64("api_key_id", apiKey.ID),
+		zap.Any("group_id", apiKey.GroupID),
+	)
+
+	for _, rule := range m.rules {
+		if rule.re.MatchString(text) {
+			log.Warn("content_moderation.blocked", zap.String("source", "local_rule"), zap.Strings("categories", []string{rule.name}))
+			return ErrContentFlagged
+		}
+	}
+	if m.endpoint == "" {
+		return nil
+	}
+
+	start := m.nowFn()
+	result, err := m.callEndpoint(ctx, text)
+	if err != nil {
+		log.Warn("content_moderation.endpoint_failed", zap.Error(err), zap.Bool("fail_open", m.failOpen))
+		if m.failOpen {
+			return nil
+		}
+		return ErrModerationUnavailable
+	}
+
+	categories := m.blockingCategories(result)
+	fields := []zap.Field{
+		zap.String("source", "endpoint"),
+		zap.Bool("flagged", result.Flagged),
+		zap.Any("category_scores", result.CategoryScores),
+		zap.Int64("latency_ms", m.nowFn().Sub(start).Milliseconds()),
+	}
+	if len(categories) == 0 {
+		log.Debug("content_moderation.passed", fields...)
+		return nil
+	}
+	log.Warn("content_moderation.blocked", append(fields, zap.Strings("categories", categories))...)
+	return ErrContentFlagged
+}
+
+// blockingCategories 返回导致拦截的类别；配置了 blocked_categories 时仅这些类别生效，
+// 否则端点 flagged 即拦截（类别为所有命中项）。
+func (m *ContentModerator) blockingCategories(result *moderationResult) []string {
+	var categories []string
+	for category, hit := range result.Categories {
+		if !hit {
+			continue
+		}
+		if m.blocked != nil {
+			if _, ok := m.blocked[category]; !ok {
+				continue
+			}
+		}
+		categories = append(categories, category)
+	}
+	if m.blocked == nil && result.Flagged && len(categories) == 0 {
+		categories = append(categories, "flagged")
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+func (m *ContentModerator) callEndpoint(ctx context.Context, text string) (*moderationResult, error) {
+	payload, err := json.Marshal(map[string]any{"model": m.model, "input": text})
+	if err != nil {
+		return nil, fmt.Errorf("marshal moderation request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("build moderation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("moderation request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, moderationResponseMaxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read moderation response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("moderation endpoint returned status %d", resp.StatusCode)
+	}
+
+	var parsed struct {
+		Results []moderationResult `json:"results"`
+	}
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, fmt.Errorf("decode moderation response: %w", err)
+	}
+	if len(parsed.Results) == 0 {
+		return nil, fmt.Errorf("moderation response has no results")
+	}
+	return &parsed.Results[0], nil
+}
+
+// moderationInputText 按协议格式提取待审核文本（与敏感信息过滤扫描的字段一致），以换行拼接
+func moderationInputText(body []byte, format RequestParamFormat) string {
+	paths := piiTextPaths(body, format)
+	if len(paths) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if text := gjson.GetBytes(body, path).String(); strings.TrimSpace(text) != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,99 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func moderationTestConfig(endpoint string, failOpen bool) *config.Config {
+	cfg := &config.Config{}
+	cfg.Gateway.Moderation = config.GatewayModerationConfig{
+		Endpoint:       endpoint,
+		APIKey:         "mod-key",
+		TimeoutSeconds: 2,
+		FailOpen:       failOpen,
+	}
+	return cfg
+}
+
+func TestModerationEnabledFor(t *testing.T) {
+	require.False(t, ModerationEnabledFor(nil))
+	require.False(t, ModerationEnabledFor(&APIKey{}))
+	require.True(t, ModerationEnabledFor(&APIKey{Group: &Group{ModerationEnabled: true}}))
+	require.False(t, ModerationEnabledFor(&APIKey{ModerationMode: ModerationModeDisabled, Group: &Group{ModerationEnabled: true}}))
+	require.True(t, ModerationEnabledFor(&APIKey{ModerationMode: ModerationModeEnabled}))
+}
+
+func TestContentModerator_LocalRules(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.Moderation.LocalRules = []config.GatewayModerationRule{{Name: "codename", Regex: `(?i)project\s+phoenix`}}
+	m := NewContentModerator(cfg)
+	apiKey := &APIKey{ModerationMode: ModerationModeEnabled}
+
+	err := m.Check(context.Background(), []byte(`{"messages":[{"role":"user","content":"tell me about Project Phoenix"}]}`), apiKey, RequestParamFormatAnthropic)
+	require.ErrorIs(t, err, ErrContentFlagged)
+
+	require.NoError(t, m.Check(context.Background(), []byte(`{"messages":[{"role":"user","content":"hello"}]}`), apiKey, RequestParamFormatAnthropic))
+
+	// 未启用时不审核
+	require.NoError(t, m.Check(context.Background(), []byte(`{"messages":[{"role":"user","content":"project phoenix"}]}`), &APIKey{}, RequestParamFormatAnthropic))
+}
+
+func TestContentModerator_Endpoint(t *testing.T) {
+	var gotInput string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer mod-key", r.Header.Get("Authorization"))
+		var req struct {
+			Model string `json:"model"`
+			Input string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, defaultModerationModel, req.Model)
+		gotInput = req.Input
+		flagged := req.Input == "bad words"
+		_ = json.NewEncoder(w).Encode(map[string]any{"results": []map[string]any{{
+			"flagged":         flagged,
+			"categories":      map[string]bool{"harassment": flagged, "violence": false},
+			"category_scores": map[string]float64{"harassment": 0.91, "violence": 0.01},
+		}}})
+	}))
+	defer srv.Close()
+
+	m := NewContentModerator(moderationTestConfig(srv.URL, true))
+	apiKey := &APIKey{Group: &Group{ModerationEnabled: true}}
+
+	err := m.Check(context.Background(), []byte(`{"input":"bad words"}`), apiKey, RequestParamFormatResponses)
+	require.ErrorIs(t, err, ErrContentFlagged)
+	require.Equal(t, "bad words", gotInput)
+
+	require.NoError(t, m.Check(context.Background(), []byte(`{"contents":[{"parts":[{"text":"hi"}]}]}`), apiKey, RequestParamFormatGemini))
+	require.Equal(t, "hi", gotInput)
+
+	// blocked_categories 限定类别时，未列出的类别不拦截
+	cfg := moderationTestConfig(srv.URL, true)
+	cfg.Gateway.Moderation.BlockedCategories = []string{"violence"}
+	require.NoError(t, NewContentModerator(cfg).Check(context.Background(), []byte(`{"input":"bad words"}`), apiKey, RequestParamFormatResponses))
+}
+
+func TestContentModerator_EndpointFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	apiKey := &APIKey{ModerationMode: ModerationModeEnabled}
+	body := []byte(`{"messages":[{"role":"user","content":"hello"}]}`)
+
+	require.NoError(t, NewContentModerator(moderationTestConfig(srv.URL, true)).Check(context.Background(), body, apiKey, RequestParamFormatChatCompletions))
+
+	err := NewContentModerator(moderationTestConfig(srv.URL, false)).Check(context.Background(), body, apiKey, RequestParamFormatChatCompletions)
+	require.ErrorIs(t, err, ErrModerationUnavailable)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ContextTrimmedHeader 自动裁剪上下文后返回给客户端的响应头
+const ContextTrimmedHeader = "X-Sub2api-Context-Trimmed"
+
+// contextTrimSkipKeys 不计入 token 估算的字段（图片/文件的 base64 数据、签名等不可读内容）
+var contextTrimSkipKeys = map[string]struct{}{
+	"data":              {},
+	"url":               {},
+	"image_url":         {},
+	"file_data":         {},
+	"signature":         {},
+	"encrypted_content": {},
+	"thoughtSignature":  {},
+}
+
+// ContextTrimResult 上下文裁剪结果
+type ContextTrimResult struct {
+	DroppedMessages       int
+	EstimatedTokensBefore int
+	EstimatedTokensAfter  int
+	ContextWindow         int
+}
+
+// HeaderValue 生成 X-Sub2api-Context-Trimmed 响应头内容
+func (r *ContextTrimResult) HeaderValue() string {
+	return fmt.Sprintf("dropped_messages=%d; estimated_tokens_before=%d; estimated_tokens_after=%d; context_window=%d",
+		r.DroppedMessages, r.EstimatedTokensBefore, r.EstimatedTokensAfter, r.ContextWindow)
+}
+
+// ContextTrimmer 为开启 context_auto_trim 的 API Key 在转发前裁剪对话历史：
+// 预估提示词 token 数超出模型上下文窗口（来自模型元数据注册表）时，
+// 从最早的非系统消息开始丢弃，直到能够容纳为止。
+//
+// 裁剪点总是落在一条普通用户消息上（不含 tool_result / functionResponse），
+// 保证剩余对话以用户消息开头且不会留下找不到对应工具调用的工具结果。
+type ContextTrimmer struct {
+	modelCatalog *ModelCatalogService
+}
+
+// NewContextTrimmer creates a new ContextTrimmer.
+func NewContextTrimmer(modelCatalog *ModelCatalogService) *ContextTrimmer {
+	return &ContextTrimmer{modelCatalog: modelCatalog}
+}
+
+// Apply 按 API Key 设置裁剪请求体，返回处理后的请求体；未裁剪时结果为 nil。
+// token 数为按文本长度的粗略估算，不计入图片等二进制内容。
+func (t *ContextTrimmer) Apply(ctx context.Context, body []byte, apiKey *APIKey, model string, format RequestParamFormat) ([]byte, *ContextTrimResult) {
+	if t == nil || apiKey == nil || !apiKey.ContextAutoTrim || model == "" {
+		return body, nil
+	}
+	window := t.modelCatalog.ContextWindow(ctx, model)
+	if window <= 0 || !gjson.ValidBytes(body) {
+		return body, nil
+	}
+	return trimContextWindow(body, window, format)
+}
+
+func trimContextWindow(body []byte, window int, format RequestParamFormat) ([]byte, *ContextTrimResult) {
+	path := contextTrimMessagesPath(format)
+	messages := gjson.GetBytes(body, path)
+	if path == "" || !messages.IsArray() {
+		return body, nil
+	}
+	items := messages.Array()
+
+	tokens := make([]int, len(items))
+	for i, item := range items {
+		tokens[i] = estimateContextTokens(item)
+	}
+	total := estimateContextTokens(gjson.ParseBytes(body))
+	if total <= window {
+		return body, nil
+	}
+
+	var removable []int
+	for i, item := range items {
+		if !isPinnedContextMessage(item, format) {
+			removable = append(removable, i)
+		}
+	}
+
+	// 在保留至少一条非系统消息的前提下，找到最小的、能放入窗口的合法裁剪点；
+	// 无法放入时使用最大的合法裁剪点尽量缩减。
+	cut := 0
+	dropped := 0
+	droppedAtCut := 0
+	for j := 1; j < len(removable); j++ {
+		dropped += tokens[removable[j-1]]
+		if !isContextTrimStart(items[removable[j]], format) {
+			continue
+		}
+		cut = j
+		droppedAtCut = dropped
+		if total-dropped <= window {
+			break
+		}
+	}
+	if cut == 0 {
+		return body, nil
+	}
+
+	droppedSet := make(map[int]struct{}, cut)
+	for _, idx := range removable[:cut] {
+		droppedSet[idx] = struct{}{}
+	}
+	kept := make([]string, 0, len(items)-cut)
+	for i, item := range items {
+		if _, ok := droppedSet[i]; !ok {
+			kept = append(kept, item.Raw)
+		}
+	}
+	updated, err := sjson.SetRawBytes(body, path, []byte("["+strings.Join(kept, ",")+"]"))
+	if err != nil {
+		return body, nil
+	}
+	return updated, &ContextTrimResult{
+		DroppedMessages:       cut,
+		EstimatedTokensBefore: total,
+		EstimatedTokensAfter:  total - droppedAtCut,
+		ContextWindow:         window,
+	}
+}
+
+func contextTrimMessagesPath(format RequestParamFormat) string {
+	switch format {
+	case RequestParamFormatAnthropic, RequestParamFormatChatCompletions:
+		return "messages"
+	case RequestParamFormatResponses:
+		return "input"
+	case RequestParamFormatGemini:
+		return "contents"
+	}
+	return ""
+}
+
+// isPinnedContextMessage 系统/开发者消息始终保留（Anthropic 与 Gemini 的系统提示词不在消息列表中）
+func isPinnedContextMessage(item gjson.Result, format RequestParamFormat) bool {
+	if format != RequestParamFormatChatCompletions && format != RequestParamFormatResponses {
+		return false
+	}
+	role := item.Get("role").String()
+	return role == "system" || role == "developer"
+}
+
+// isContextTrimStart 判断裁剪后剩余对话能否以该消息开头
+func isContextTrimStart(item gjson.Result, format RequestParamFormat) bool {
+	switch format {
+	case RequestParamFormatAnthropic:
+		if item.Get("role").String() != "user" {
+			return false
+		}
+		for _, block := range item.Get("content").Array() {
+			if block.Get("type").String() == "tool_result" {
+				return false
+			}
+		}
+		return true
+	case RequestParamFormatChatCompletions:
+		return item.Get("role").String() == "user"
+	case RequestParamFormatResponses:
+		itemType := item.Get("type").String()
+		return (itemType == "" || itemType == "message") && item.Get("role").String() == "user"
+	case RequestParamFormatGemini:
+		if role := item.Get("role").String(); role != "" && role != "user" {
+			return false
+		}
+		for _, part := range item.Get("parts").Array() {
+			if part.Get("functionResponse").Exists() {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// estimateContextTokens 递归累计 JSON 中字符串字段的估算 token 数
+func estimateContextTokens(value gjson.Result) int {
+	switch {
+	case value.Type == gjson.String:
+		return estimateTokensForText(value.String())
+	case value.IsObject():
+		total := 0
+		value.ForEach(func(key, v gjson.Result) bool {
+			if _, skip := contextTrimSkipKeys[key.String()]; !skip {
+				total += estimateContextTokens(v)
+			}
+			return true
+		})
+		return total
+	case value.IsArray():
+		total := 0
+		value.ForEach(func(_, v gjson.Result) bool {
+			total += estimateContextTokens(v)
+			return true
+		})
+		return total
+	}
+	return 0
+}
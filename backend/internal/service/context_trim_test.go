@@ -0,0 +1,82 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// 每条约 100 token 的英文文本
+var contextTrimFiller = strings.Repeat("word ", 80)
+
+func TestTrimContextWindow_AnthropicDropsOldestTurns(t *testing.T) {
+	body := []byte(`{"model":"claude-sonnet-4-5","system":"be brief","messages":[` +
+		`{"role":"user","content":"` + contextTrimFiller + `"},` +
+		`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"ls","input":{}}]},` +
+		`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"` + contextTrimFiller + `"}]},` +
+		`{"role":"assistant","content":"` + contextTrimFiller + `"},` +
+		`{"role":"user","content":"latest question"}]}`)
+
+	out, result := trimContextWindow(body, 150, RequestParamFormatAnthropic)
+	require.NotNil(t, result)
+	// tool_result 消息不能作为开头，因此一直裁剪到下一条普通用户消息
+	require.Equal(t, 4, result.DroppedMessages)
+	require.Equal(t, 150, result.ContextWindow)
+	require.Less(t, result.EstimatedTokensAfter, result.EstimatedTokensBefore)
+	msgs := gjson.GetBytes(out, "messages").Array()
+	require.Len(t, msgs, 1)
+	require.Equal(t, "latest question", msgs[0].Get("content").String())
+	require.Equal(t, "be brief", gjson.GetBytes(out, "system").String())
+	require.Contains(t, result.HeaderValue(), "dropped_messages=4")
+}
+
+func TestTrimContextWindow_FitsWithoutTrim(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"user","content":"hi"}]}`)
+	out, result := trimContextWindow(body, 1000, RequestParamFormatAnthropic)
+	require.Nil(t, result)
+	require.Equal(t, body, out)
+}
+
+func TestTrimContextWindow_ChatCompletionsKeepsSystem(t *testing.T) {
+	body := []byte(`{"messages":[` +
+		`{"role":"system","content":"sys"},` +
+		`{"role":"user","content":"` + contextTrimFiller + `"},` +
+		`{"role":"assistant","content":"` + contextTrimFiller + `"},` +
+		`{"role":"user","content":"` + contextTrimFiller + `"},` +
+		`{"role":"assistant","content":"ok"},` +
+		`{"role":"user","content":"now"}]}`)
+
+	out, result := trimContextWindow(body, 150, RequestParamFormatChatCompletions)
+	require.NotNil(t, result)
+	require.Equal(t, 2, result.DroppedMessages)
+	msgs := gjson.GetBytes(out, "messages").Array()
+	require.Len(t, msgs, 4)
+	require.Equal(t, "system", msgs[0].Get("role").String())
+	require.Equal(t, "user", msgs[1].Get("role").String())
+}
+
+func TestTrimContextWindow_NoValidCut(t *testing.T) {
+	// 只有一条非系统消息时无法裁剪
+	body := []byte(`{"input":[{"role":"developer","content":"d"},{"role":"user","content":"` + contextTrimFiller + `"}]}`)
+	out, result := trimContextWindow(body, 10, RequestParamFormatResponses)
+	require.Nil(t, result)
+	require.Equal(t, body, out)
+}
+
+func TestContextTrimmer_RequiresAPIKeyOptIn(t *testing.T) {
+	trimmer := NewContextTrimmer(nil)
+	body := []byte(`{"messages":[{"role":"user","content":"hi"}]}`)
+	out, result := trimmer.Apply(context.Background(), body, &APIKey{ContextAutoTrim: false}, "claude-sonnet-4-5", RequestParamFormatAnthropic)
+	require.Nil(t, result)
+	require.Equal(t, body, out)
+
+	// 上下文窗口未知时不裁剪
+	out, result = trimmer.Apply(context.Background(), body, &APIKey{ContextAutoTrim: true}, "claude-sonnet-4-5", RequestParamFormatAnthropic)
+	require.Nil(t, result)
+	require.Equal(t, body, out)
+}
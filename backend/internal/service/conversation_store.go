@@ -0,0 +1,460 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+)
+
+// ConversationIDHeader 客户端用于标识服务端会话的请求头
+const ConversationIDHeader = "X-Conversation-ID"
+
+const conversationIDMaxLen = 128
+
+// ConversationFormat 会话存储支持的入站协议
+type ConversationFormat string
+
+const (
+	// ConversationFormatChatCompletions OpenAI Chat Completions（messages 内含 system/developer 消息）
+	ConversationFormatChatCompletions ConversationFormat = "chat_completions"
+	// ConversationFormatMessages Anthropic Messages（system 为顶层字段）
+	ConversationFormatMessages ConversationFormat = "messages"
+)
+
+var (
+	ErrInvalidConversationID        = infraerrors.BadRequest("INVALID_CONVERSATION_ID", "X-Conversation-ID must be 1-128 printable ASCII characters")
+	ErrInvalidConversationRequest   = infraerrors.BadRequest("INVALID_CONVERSATION_REQUEST", "request body must contain a messages array")
+	ErrConversationStoreUnavailable = infraerrors.ServiceUnavailable("CONVERSATION_STORE_UNAVAILABLE", "conversation store is temporarily unavailable")
+)
+
+// ConversationStoreCache 会话历史存储（Redis）。
+// GetConversation 在会话不存在时返回 (nil, nil)。
+type ConversationStoreCache interface {
+	GetConversation(ctx context.Context, key string) ([]byte, error)
+	SetConversation(ctx context.Context, key string, data []byte, ttl time.Duration) error
+}
+
+// ConversationStoreService 为无状态客户端提供服务端会话历史：
+// 请求携带 X-Conversation-ID 时，在转发前把已存储的历史消息拼接到请求 messages 之前，
+// 上游成功响应后把本轮请求消息与助手回复追加到历史。
+//
+// Chat Completions 的 system/developer 消息不会写入历史，客户端需要每轮携带。
+type ConversationStoreService struct {
+	cache ConversationStoreCache
+	cfg   config.GatewayConversationStoreConfig
+}
+
+func NewConversationStoreService(cache ConversationStoreCache, cfg *config.Config) *ConversationStoreService {
+	svc := &ConversationStoreService{cache: cache}
+	if cfg != nil {
+		svc.cfg = cfg.Gateway.ConversationStore
+	}
+	return svc
+}
+
+// Enabled 是否启用会话存储
+func (s *ConversationStoreService) Enabled() bool {
+	return s != nil && s.cache != nil && s.cfg.Enabled
+}
+
+// MaxResponseBytes 记录助手回复时最多缓存的响应字节数
+func (s *ConversationStoreService) MaxResponseBytes() int {
+	if s == nil {
+		return 0
+	}
+	return s.cfg.MaxBytes
+}
+
+// ConversationTurn 一轮会话的上下文，由 Prepare 生成、Record 消费
+type ConversationTurn struct {
+	key             string
+	format          ConversationFormat
+	history         []json.RawMessage
+	requestMessages []json.RawMessage
+}
+
+// Prepare 校验会话 ID 并读取历史，返回补全历史后的请求体。
+// 会话 ID 按 API Key 隔离，不同 Key 使用相同 ID 互不可见。
+func (s *ConversationStoreService) Prepare(ctx context.Context, apiKeyID int64, conversationID string, format ConversationFormat, body []byte) ([]byte, *ConversationTurn, error) {
+	if !validConversationID(conversationID) {
+		return nil, nil, ErrInvalidConversationID
+	}
+	messagesResult := gjson.GetBytes(body, "messages")
+	if !messagesResult.IsArray() {
+		return nil, nil, ErrInvalidConversationRequest
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, nil, ErrInvalidConversationRequest
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(fields["messages"], &messages); err != nil {
+		return nil, nil, ErrInvalidConversationRequest
+	}
+
+	key := conversationStoreKey(apiKeyID, conversationID)
+	data, err := s.cache.GetConversation(ctx, key)
+	if err != nil {
+		return nil, nil, ErrConversationStoreUnavailable.WithCause(err)
+	}
+	var history []json.RawMessage
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &history); err != nil {
+			// 损坏的历史视为新会话，下一轮写入时覆盖
+			history = nil
+		}
+	}
+
+	// Chat Completions：system/developer 消息保持在最前，不进入历史
+	var leading, turnMessages []json.RawMessage
+	for _, msg := range messages {
+		if format == ConversationFormatChatCompletions && isConversationInstructionMessage(msg) {
+			leading = append(leading, msg)
+			continue
+		}
+		turnMessages = append(turnMessages, msg)
+	}
+
+	turn := &ConversationTurn{
+		key:             key,
+		format:          format,
+		history:         history,
+		requestMessages: turnMessages,
+	}
+	if len(history) == 0 {
+		return body, turn, nil
+	}
+
+	merged := make([]json.RawMessage, 0, len(leading)+len(history)+len(turnMessages))
+	merged = append(merged, leading...)
+	merged = append(merged, history...)
+	merged = append(merged, turnMessages...)
+	mergedJSON, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	fields["messages"] = mergedJSON
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, turn, nil
+}
+
+// Record 从上游成功响应（JSON 或 SSE）中提取助手消息，连同本轮请求消息追加到历史。
+// 响应不完整或无法解析时不写入，返回 false。
+func (s *ConversationStoreService) Record(ctx context.Context, turn *ConversationTurn, contentType string, respBody []byte) (bool, error) {
+	if !s.Enabled() || turn == nil {
+		return false, nil
+	}
+	stream := strings.Contains(strings.ToLower(contentType), "text/event-stream")
+	var assistant json.RawMessage
+	var ok bool
+	switch turn.format {
+	case ConversationFormatChatCompletions:
+		if stream {
+			assistant, ok = chatAssistantMessageFromSSE(respBody)
+		} else {
+			assistant, ok = chatAssistantMessageFromJSON(respBody)
+		}
+	case ConversationFormatMessages:
+		if stream {
+			assistant, ok = anthropicAssistantMessageFromSSE(respBody)
+		} else {
+			assistant, ok = anthropicAssistantMessageFromJSON(respBody)
+		}
+	}
+	if !ok {
+		return false, nil
+	}
+
+	history := make([]json.RawMessage, 0, len(turn.history)+len(turn.requestMessages)+1)
+	history = append(history, turn.history...)
+	history = append(history, turn.requestMessages...)
+	history = append(history, assistant)
+	history = s.trimHistory(history)
+	if len(history) == 0 {
+		return false, nil
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		return false, err
+	}
+	if err := s.cache.SetConversation(ctx, turn.key, data, time.Duration(s.cfg.TTLSeconds)*time.Second); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// trimHistory 按条数与字节数上限丢弃最早的消息，并保证历史以 user 消息开头。
+func (s *ConversationStoreService) trimHistory(history []json.RawMessage) []json.RawMessage {
+	total := 2
+	for _, msg := range history {
+		total += len(msg) + 1
+	}
+	for len(history) > 0 && ((s.cfg.MaxMessages > 0 && len(history) > s.cfg.MaxMessages) || (s.cfg.MaxBytes > 0 && total > s.cfg.MaxBytes)) {
+		total -= len(history[0]) + 1
+		history = history[1:]
+	}
+	// 开头的 assistant/tool 消息缺少对应的 user 轮次，一并丢弃
+	for len(history) > 0 && !isConversationTurnStart(history[0]) {
+		history = history[1:]
+	}
+	return history
+}
+
+// isConversationTurnStart 判断消息能否作为历史开头：普通 user 消息，
+// 且不是 Anthropic 的 tool_result（其对应的 tool_use 已被裁剪）。
+func isConversationTurnStart(msg json.RawMessage) bool {
+	if gjson.GetBytes(msg, "role").String() != "user" {
+		return false
+	}
+	toolResult := false
+	gjson.GetBytes(msg, "content").ForEach(func(_, block gjson.Result) bool {
+		toolResult = block.Get("type").String() == "tool_result"
+		return !toolResult
+	})
+	return !toolResult
+}
+
+func validConversationID(id string) bool {
+	if id == "" || len(id) > conversationIDMaxLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func conversationStoreKey(apiKeyID int64, conversationID string) string {
+	sum := sha256.Sum256([]byte(conversationID))
+	return strconv.FormatInt(apiKeyID, 10) + ":" + hex.EncodeToString(sum[:16])
+}
+
+func isConversationInstructionMessage(msg json.RawMessage) bool {
+	role := gjson.GetBytes(msg, "role").String()
+	return role == "system" || role == "developer"
+}
+
+// ---------------------------------------------------------------------------
+// 助手消息提取
+// ---------------------------------------------------------------------------
+
+type conversationToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type conversationChatAssistant struct {
+	Role      string                 `json:"role"`
+	Content   *string                `json:"content"`
+	ToolCalls []conversationToolCall `json:"tool_calls,omitempty"`
+}
+
+func chatAssistantMessageFromJSON(body []byte) (json.RawMessage, bool) {
+	message := gjson.GetBytes(body, "choices.0.message")
+	if !message.IsObject() {
+		return nil, false
+	}
+	out := conversationChatAssistant{Role: "assistant"}
+	if content := message.Get("content"); content.Type == gjson.String {
+		text := content.String()
+		out.Content = &text
+	}
+	if toolCalls := message.Get("tool_calls"); toolCalls.IsArray() {
+		if err := json.Unmarshal([]byte(toolCalls.Raw), &out.ToolCalls); err != nil {
+			return nil, false
+		}
+	}
+	if out.Content == nil && len(out.ToolCalls) == 0 {
+		return nil, false
+	}
+	return marshalConversationMessage(out)
+}
+
+func chatAssistantMessageFromSSE(body []byte) (json.RawMessage, bool) {
+	var content strings.Builder
+	hasContent := false
+	finished := false
+	toolCalls := map[int64]*conversationToolCall{}
+	forEachSSEData(body, func(data []byte) {
+		if bytes.Equal(data, []byte("[DONE]")) {
+			finished = true
+			return
+		}
+		choice := gjson.GetBytes(data, "choices.0")
+		if !choice.Exists() {
+			return
+		}
+		if choice.Get("finish_reason").Type == gjson.String {
+			finished = true
+		}
+		delta := choice.Get("delta")
+		if text := delta.Get("content"); text.Type == gjson.String {
+			content.WriteString(text.String())
+			hasContent = true
+		}
+		delta.Get("tool_calls").ForEach(func(_, tc gjson.Result) bool {
+			idx := tc.Get("index").Int()
+			call := toolCalls[idx]
+			if call == nil {
+				call = &conversationToolCall{Type: "function"}
+				toolCalls[idx] = call
+			}
+			if id := tc.Get("id").String(); id != "" {
+				call.ID = id
+			}
+			if typ := tc.Get("type").String(); typ != "" {
+				call.Type = typ
+			}
+			if name := tc.Get("function.name").String(); name != "" {
+				call.Function.Name += name
+			}
+			call.Function.Arguments += tc.Get("function.arguments").String()
+			return true
+		})
+	})
+	if !finished || (!hasContent && len(toolCalls) == 0) {
+		return nil, false
+	}
+
+	out := conversationChatAssistant{Role: "assistant"}
+	if hasContent {
+		text := content.String()
+		out.Content = &text
+	}
+	indexes := make([]int64, 0, len(toolCalls))
+	for idx := range toolCalls {
+		indexes = append(indexes, idx)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	for _, idx := range indexes {
+		out.ToolCalls = append(out.ToolCalls, *toolCalls[idx])
+	}
+	return marshalConversationMessage(out)
+}
+
+func anthropicAssistantMessageFromJSON(body []byte) (json.RawMessage, bool) {
+	content := gjson.GetBytes(body, "content")
+	if gjson.GetBytes(body, "type").String() != "message" || !content.IsArray() {
+		return nil, false
+	}
+	return marshalConversationMessage(map[string]json.RawMessage{
+		"role":    json.RawMessage(`"assistant"`),
+		"content": json.RawMessage(content.Raw),
+	})
+}
+
+// anthropicStreamBlock 流式内容块累积状态
+type anthropicStreamBlock struct {
+	block       map[string]any
+	text        strings.Builder
+	thinking    strings.Builder
+	partialJSON strings.Builder
+}
+
+func anthropicAssistantMessageFromSSE(body []byte) (json.RawMessage, bool) {
+	blocks := map[int64]*anthropicStreamBlock{}
+	finished := false
+	forEachSSEData(body, func(data []byte) {
+		switch gjson.GetBytes(data, "type").String() {
+		case "content_block_start":
+			var block map[string]any
+			if err := json.Unmarshal([]byte(gjson.GetBytes(data, "content_block").Raw), &block); err != nil {
+				return
+			}
+			blocks[gjson.GetBytes(data, "index").Int()] = &anthropicStreamBlock{block: block}
+		case "content_block_delta":
+			state := blocks[gjson.GetBytes(data, "index").Int()]
+			if state == nil {
+				return
+			}
+			delta := gjson.GetBytes(data, "delta")
+			switch delta.Get("type").String() {
+			case "text_delta":
+				state.text.WriteString(delta.Get("text").String())
+			case "thinking_delta":
+				state.thinking.WriteString(delta.Get("thinking").String())
+			case "signature_delta":
+				state.block["signature"] = delta.Get("signature").String()
+			case "input_json_delta":
+				state.partialJSON.WriteString(delta.Get("partial_json").String())
+			}
+		case "message_stop":
+			finished = true
+		}
+	})
+	if !finished || len(blocks) == 0 {
+		return nil, false
+	}
+
+	indexes := make([]int64, 0, len(blocks))
+	for idx := range blocks {
+		indexes = append(indexes, idx)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	content := make([]map[string]any, 0, len(indexes))
+	for _, idx := range indexes {
+		state := blocks[idx]
+		switch state.block["type"] {
+		case "text":
+			state.block["text"] = state.text.String()
+		case "thinking":
+			state.block["thinking"] = state.thinking.String()
+		case "tool_use", "server_tool_use":
+			input := json.RawMessage("{}")
+			if raw := strings.TrimSpace(state.partialJSON.String()); raw != "" {
+				if !json.Valid([]byte(raw)) {
+					return nil, false
+				}
+				input = json.RawMessage(raw)
+			}
+			state.block["input"] = input
+		}
+		content = append(content, state.block)
+	}
+	return marshalConversationMessage(map[string]any{
+		"role":    "assistant",
+		"content": content,
+	})
+}
+
+func marshalConversationMessage(v any) (json.RawMessage, bool) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// forEachSSEData 逐条回调 SSE 的 data 负载（已去除 "data:" 前缀与首尾空白）
+func forEachSSEData(body []byte, fn func(data []byte)) {
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		payload, ok := bytes.CutPrefix(bytes.TrimRight(line, "\r"), []byte("data:"))
+		if !ok {
+			continue
+		}
+		payload = bytes.TrimSpace(payload)
+		if len(payload) > 0 {
+			fn(payload)
+		}
+	}
+}
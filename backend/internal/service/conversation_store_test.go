@@ -0,0 +1,136 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type conversationStoreCacheStub struct {
+	data map[string][]byte
+	ttl  time.Duration
+}
+
+func (s *conversationStoreCacheStub) GetConversation(ctx context.Context, key string) ([]byte, error) {
+	return s.data[key], nil
+}
+
+func (s *conversationStoreCacheStub) SetConversation(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	if s.data == nil {
+		s.data = map[string][]byte{}
+	}
+	s.data[key] = data
+	s.ttl = ttl
+	return nil
+}
+
+func newConversationStoreServiceForTest(maxMessages int) (*ConversationStoreService, *conversationStoreCacheStub) {
+	cache := &conversationStoreCacheStub{}
+	cfg := &config.Config{}
+	cfg.Gateway.ConversationStore = config.GatewayConversationStoreConfig{
+		Enabled:     true,
+		TTLSeconds:  60,
+		MaxMessages: maxMessages,
+		MaxBytes:    1 << 20,
+	}
+	return NewConversationStoreService(cache, cfg), cache
+}
+
+func TestConversationStore_ChatCompletionsRoundTrip(t *testing.T) {
+	svc, cache := newConversationStoreServiceForTest(100)
+	ctx := context.Background()
+
+	body := []byte(`{"model":"gpt-4o","messages":[{"role":"system","content":"be brief"},{"role":"user","content":"hi"}]}`)
+	out, turn, err := svc.Prepare(ctx, 1, "conv-1", ConversationFormatChatCompletions, body)
+	require.NoError(t, err)
+	require.Equal(t, body, out, "无历史时请求体保持不变")
+
+	stored, err := svc.Record(ctx, turn, "application/json", []byte(`{"object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hello","refusal":null}}]}`))
+	require.NoError(t, err)
+	require.True(t, stored)
+	require.Equal(t, time.Minute, cache.ttl)
+
+	body = []byte(`{"model":"gpt-4o","messages":[{"role":"system","content":"be brief"},{"role":"user","content":"again"}]}`)
+	out, turn, err = svc.Prepare(ctx, 1, "conv-1", ConversationFormatChatCompletions, body)
+	require.NoError(t, err)
+	messages := gjson.GetBytes(out, "messages").Array()
+	require.Len(t, messages, 4)
+	require.Equal(t, "system", messages[0].Get("role").String())
+	require.Equal(t, "hi", messages[1].Get("content").String())
+	require.Equal(t, "hello", messages[2].Get("content").String())
+	require.False(t, messages[2].Get("refusal").Exists())
+	require.Equal(t, "again", messages[3].Get("content").String())
+	require.Equal(t, "gpt-4o", gjson.GetBytes(out, "model").String())
+
+	// 不同 API Key 使用相同会话 ID 互不可见
+	out, _, err = svc.Prepare(ctx, 2, "conv-1", ConversationFormatChatCompletions, body)
+	require.NoError(t, err)
+	require.Len(t, gjson.GetBytes(out, "messages").Array(), 2)
+
+	sse := "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"lookup\",\"arguments\":\"{\\\"q\\\":\"}}]}}]}\n\n" +
+		"data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"1}\"}}]}}]}\n\n" +
+		"data: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"tool_calls\"}]}\n\n" +
+		"data: [DONE]\n\n"
+	stored, err = svc.Record(ctx, turn, "text/event-stream", []byte(sse))
+	require.NoError(t, err)
+	require.True(t, stored)
+
+	history := gjson.ParseBytes(cache.data[conversationStoreKey(1, "conv-1")]).Array()
+	require.Len(t, history, 4)
+	require.Equal(t, "call_1", history[3].Get("tool_calls.0.id").String())
+	require.Equal(t, `{"q":1}`, history[3].Get("tool_calls.0.function.arguments").String())
+}
+
+func TestConversationStore_MessagesStreamAndTrim(t *testing.T) {
+	svc, cache := newConversationStoreServiceForTest(3)
+	ctx := context.Background()
+
+	_, turn, err := svc.Prepare(ctx, 1, "conv-a", ConversationFormatMessages, []byte(`{"system":"s","messages":[{"role":"user","content":"q1"}]}`))
+	require.NoError(t, err)
+	sse := "event: message_start\ndata: {\"type\":\"message_start\"}\n\n" +
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"a\"}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"1\"}}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"
+	stored, err := svc.Record(ctx, turn, "text/event-stream", []byte(sse))
+	require.NoError(t, err)
+	require.True(t, stored)
+
+	out, turn, err := svc.Prepare(ctx, 1, "conv-a", ConversationFormatMessages, []byte(`{"system":"s","messages":[{"role":"user","content":"q2"}]}`))
+	require.NoError(t, err)
+	messages := gjson.GetBytes(out, "messages").Array()
+	require.Len(t, messages, 3)
+	require.Equal(t, "a1", messages[1].Get("content.0.text").String())
+	require.Equal(t, "s", gjson.GetBytes(out, "system").String())
+
+	stored, err = svc.Record(ctx, turn, "application/json", []byte(`{"type":"message","role":"assistant","content":[{"type":"text","text":"a2"}]}`))
+	require.NoError(t, err)
+	require.True(t, stored)
+
+	// 上限 3 条：丢弃最早的消息后，开头的 assistant 消息也一并丢弃
+	history := gjson.ParseBytes(cache.data[conversationStoreKey(1, "conv-a")]).Array()
+	require.Len(t, history, 2)
+	require.Equal(t, "q2", history[0].Get("content").String())
+
+	// 未完成的流不记录
+	stored, err = svc.Record(ctx, turn, "text/event-stream", []byte("data: {\"type\":\"message_start\"}\n\n"))
+	require.NoError(t, err)
+	require.False(t, stored)
+}
+
+func TestConversationStore_PrepareValidation(t *testing.T) {
+	svc, _ := newConversationStoreServiceForTest(10)
+	ctx := context.Background()
+
+	_, _, err := svc.Prepare(ctx, 1, "bad id", ConversationFormatChatCompletions, []byte(`{"messages":[]}`))
+	require.ErrorIs(t, err, ErrInvalidConversationID)
+
+	_, _, err = svc.Prepare(ctx, 1, "ok", ConversationFormatChatCompletions, []byte(`{"prompt":"x"}`))
+	require.ErrorIs(t, err, ErrInvalidConversationRequest)
+}
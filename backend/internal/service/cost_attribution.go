@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// 单次请求费用归因：在响应头（非流式）或流末尾的 SSE 注释（流式）中
+// 附带按定价表估算的本次调用费用与 token 数，便于下游工具向终端用户展示。
+// 注意：该值为转发完成时的即时估算，最终扣费仍以 usage 记录为准。
+
+const (
+	defaultCostAttributionCostHeader   = "X-Sub2api-Cost"
+	defaultCostAttributionTokensHeader = "X-Sub2api-Tokens"
+)
+
+// costAttribution 单次调用的费用归因结果
+type costAttribution struct {
+	Cost   float64 // 应用倍率后的实际费用 (USD)
+	Tokens int     // 输入 + 输出 + 缓存 token 总数
+}
+
+func costAttributionConfig(cfg *config.Config) (config.GatewayCostAttributionConfig, bool) {
+	if cfg == nil || !cfg.Gateway.CostAttribution.Enabled {
+		return config.GatewayCostAttributionConfig{}, false
+	}
+	return cfg.Gateway.CostAttribution, true
+}
+
+// resolveCostAttribution 基于定价表与分组/用户倍率估算本次调用费用。
+// 倍率解析与 RecordUsage 保持一致：用户专属 > 分组默认 > 系统默认。
+func resolveCostAttribution(
+	ctx context.Context,
+	c *gin.Context,
+	cfg *config.Config,
+	billingService *BillingService,
+	resolveMultiplier func(ctx context.Context, userID, groupID int64, groupDefaultMultiplier float64) float64,
+	model string,
+	tokens UsageTokens,
+) (costAttribution, bool) {
+	if _, ok := costAttributionConfig(cfg); !ok || billingService == nil {
+		return costAttribution{}, false
+	}
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return costAttribution{}, false
+	}
+
+	multiplier := cfg.Default.RateMultiplier
+	if apiKey := costAttributionAPIKey(c); apiKey != nil && apiKey.GroupID != nil && apiKey.Group != nil {
+		multiplier = apiKey.Group.RateMultiplier
+		if resolveMultiplier != nil {
+			multiplier = resolveMultiplier(ctx, apiKey.UserID, *apiKey.GroupID, apiKey.Group.RateMultiplier)
+		}
+	}
+
+	breakdown, err := billingService.CalculateCost(model, tokens, multiplier)
+	if err != nil || breakdown == nil {
+		return costAttribution{}, false
+	}
+	total := tokens.InputTokens + tokens.OutputTokens + tokens.CacheCreationTokens + tokens.CacheReadTokens
+	return costAttribution{Cost: breakdown.ActualCost, Tokens: total}, true
+}
+
+func costAttributionAPIKey(c *gin.Context) *APIKey {
+	if c == nil {
+		return nil
+	}
+	v, exists := c.Get("api_key")
+	if !exists {
+		return nil
+	}
+	apiKey, _ := v.(*APIKey)
+	return apiKey
+}
+
+// writeCostAttributionHeaders 在非流式响应写出前设置费用归因响应头。
+func writeCostAttributionHeaders(h http.Header, cfg config.GatewayCostAttributionConfig, attr costAttribution) {
+	if h == nil {
+		return
+	}
+	h.Set(costAttributionCostHeaderName(cfg), formatCostAttributionCost(attr.Cost))
+	h.Set(costAttributionTokensHeaderName(cfg), strconv.Itoa(attr.Tokens))
+}
+
+// costAttributionSSEComment 构建流式响应末尾的 SSE 注释行。
+// SSE 规范要求客户端忽略以 ":" 开头的行，因此不会破坏 Anthropic/OpenAI SDK 的事件解析。
+func costAttributionSSEComment(cfg config.GatewayCostAttributionConfig, attr costAttribution) string {
+	return ": " + strings.ToLower(costAttributionCostHeaderName(cfg)) + "=" + formatCostAttributionCost(attr.Cost) +
+		" " + strings.ToLower(costAttributionTokensHeaderName(cfg)) + "=" + strconv.Itoa(attr.Tokens) + "\n\n"
+}
+
+func costAttributionCostHeaderName(cfg config.GatewayCostAttributionConfig) string {
+	if name := strings.TrimSpace(cfg.CostHeader); name != "" {
+		return name
+	}
+	return defaultCostAttributionCostHeader
+}
+
+func costAttributionTokensHeaderName(cfg config.GatewayCostAttributionConfig) string {
+	if name := strings.TrimSpace(cfg.TokensHeader); name != "" {
+		return name
+	}
+	return defaultCostAttributionTokensHeader
+}
+
+func formatCostAttributionCost(cost float64) string {
+	if cost < 0 {
+		cost = 0
+	}
+	return strconv.FormatFloat(cost, 'f', 8, 64)
+}
+
+func claudeUsageToCostTokens(usage *ClaudeUsage) UsageTokens {
+	if usage == nil {
+		return UsageTokens{}
+	}
+	return UsageTokens{
+		InputTokens:           usage.InputTokens,
+		OutputTokens:          usage.OutputTokens,
+		CacheCreationTokens:   usage.CacheCreationInputTokens,
+		CacheReadTokens:       usage.CacheReadInputTokens,
+		CacheCreation5mTokens: usage.CacheCreation5mTokens,
+		CacheCreation1hTokens: usage.CacheCreation1hTokens,
+		ImageOutputTokens:     usage.ImageOutputTokens,
+	}
+}
+
+// openAIUsageToCostTokens OpenAI 的 input_tokens 包含 cached_tokens，计费时需扣除。
+func openAIUsageToCostTokens(usage *OpenAIUsage) UsageTokens {
+	if usage == nil {
+		return UsageTokens{}
+	}
+	inputTokens := usage.InputTokens - usage.CacheReadInputTokens
+	if inputTokens < 0 {
+		inputTokens = 0
+	}
+	return UsageTokens{
+		InputTokens:         inputTokens,
+		OutputTokens:        usage.OutputTokens,
+		CacheCreationTokens: usage.CacheCreationInputTokens,
+		CacheReadTokens:     usage.CacheReadInputTokens,
+		ImageOutputTokens:   usage.ImageOutputTokens,
+	}
+}
+
+// applyCostAttributionHeaders 非流式：在 c.Data 之前调用。
+func (s *GatewayService) applyCostAttributionHeaders(ctx context.Context, c *gin.Context, model string, usage *ClaudeUsage) {
+	cfg, ok := costAttributionConfig(s.cfg)
+	if !ok {
+		return
+	}
+	attr, ok := resolveCostAttribution(ctx, c, s.cfg, s.billingService, s.getUserGroupRateMultiplier, model, claudeUsageToCostTokens(usage))
+	if !ok {
+		return
+	}
+	writeCostAttributionHeaders(c.Writer.Header(), cfg, attr)
+}
+
+// streamCostAttributionComment 流式：返回需追加在流末尾的 SSE 注释，未启用时返回空串。
+func (s *GatewayService) streamCostAttributionComment(ctx context.Context, c *gin.Context, model string, usage *ClaudeUsage) string {
+	cfg, ok := costAttributionConfig(s.cfg)
+	if !ok || !cfg.StreamComment {
+		return ""
+	}
+	attr, ok := resolveCostAttribution(ctx, c, s.cfg, s.billingService, s.getUserGroupRateMultiplier, model, claudeUsageToCostTokens(usage))
+	if !ok {
+		return ""
+	}
+	return costAttributionSSEComment(cfg, attr)
+}
+
+func (s *OpenAIGatewayService) applyCostAttributionHeaders(ctx context.Context, c *gin.Context, model string, usage *OpenAIUsage) {
+	cfg, ok := costAttributionConfig(s.cfg)
+	if !ok {
+		return
+	}
+	attr, ok := resolveCostAttribution(ctx, c, s.cfg, s.billingService, s.userGroupRateResolver.Resolve, model, openAIUsageToCostTokens(usage))
+	if !ok {
+		return
+	}
+	writeCostAttributionHeaders(c.Writer.Header(), cfg, attr)
+}
+
+func (s *OpenAIGatewayService) streamCostAttributionComment(ctx context.Context, c *gin.Context, model string, usage *OpenAIUsage) string {
+	cfg, ok := costAttributionConfig(s.cfg)
+	if !ok || !cfg.StreamComment {
+		return ""
+	}
+	attr, ok := resolveCostAttribution(ctx, c, s.cfg, s.billingService, s.userGroupRateResolver.Resolve, model, openAIUsageToCostTokens(usage))
+	if !ok {
+		return ""
+	}
+	return costAttributionSSEComment(cfg, attr)
+}
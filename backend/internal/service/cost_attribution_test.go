@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newCostAttributionTestConfig(enabled bool) *config.Config {
+	cfg := &config.Config{}
+	cfg.Default.RateMultiplier = 1.0
+	cfg.Gateway.CostAttribution = config.GatewayCostAttributionConfig{
+		Enabled:       enabled,
+		CostHeader:    "X-Sub2api-Cost",
+		TokensHeader:  "X-Sub2api-Tokens",
+		StreamComment: true,
+	}
+	return cfg
+}
+
+func TestResolveCostAttribution_DisabledReturnsFalse(t *testing.T) {
+	cfg := newCostAttributionTestConfig(false)
+	_, ok := resolveCostAttribution(context.Background(), nil, cfg, NewBillingService(cfg, nil), nil, "claude-sonnet-4", UsageTokens{InputTokens: 10})
+	require.False(t, ok)
+}
+
+func TestResolveCostAttribution_UsesGroupMultiplier(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := newCostAttributionTestConfig(true)
+	billing := NewBillingService(cfg, nil)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	groupID := int64(7)
+	c.Set("api_key", &APIKey{UserID: 3, GroupID: &groupID, Group: &Group{ID: groupID, RateMultiplier: 2}})
+
+	tokens := UsageTokens{InputTokens: 1000, OutputTokens: 500, CacheReadTokens: 100}
+	attr, ok := resolveCostAttribution(context.Background(), c, cfg, billing, nil, "claude-sonnet-4", tokens)
+	require.True(t, ok)
+	require.Equal(t, 1600, attr.Tokens)
+
+	base, err := billing.CalculateCost("claude-sonnet-4", tokens, 1.0)
+	require.NoError(t, err)
+	require.InDelta(t, base.ActualCost*2, attr.Cost, 1e-12)
+
+	var seenUser, seenGroup int64
+	attr, ok = resolveCostAttribution(context.Background(), c, cfg, billing, func(_ context.Context, userID, groupID int64, _ float64) float64 {
+		seenUser, seenGroup = userID, groupID
+		return 0.5
+	}, "claude-sonnet-4", tokens)
+	require.True(t, ok)
+	require.Equal(t, int64(3), seenUser)
+	require.Equal(t, int64(7), seenGroup)
+	require.InDelta(t, base.ActualCost*0.5, attr.Cost, 1e-12)
+}
+
+func TestWriteCostAttributionHeaders_DefaultNames(t *testing.T) {
+	h := http.Header{}
+	writeCostAttributionHeaders(h, config.GatewayCostAttributionConfig{}, costAttribution{Cost: 0.0123, Tokens: 42})
+	require.Equal(t, "0.01230000", h.Get("X-Sub2api-Cost"))
+	require.Equal(t, "42", h.Get("X-Sub2api-Tokens"))
+}
+
+func TestCostAttributionSSEComment_IsSSEComment(t *testing.T) {
+	comment := costAttributionSSEComment(config.GatewayCostAttributionConfig{CostHeader: "X-Cost", TokensHeader: "X-Tok"}, costAttribution{Cost: 1.5, Tokens: 9})
+	require.True(t, strings.HasPrefix(comment, ": "))
+	require.True(t, strings.HasSuffix(comment, "\n\n"))
+	require.Contains(t, comment, "x-cost=1.50000000")
+	require.Contains(t, comment, "x-tok=9")
+}
+
+func TestOpenAIUsageToCostTokens_ExcludesCachedInput(t *testing.T) {
+	tokens := openAIUsageToCostTokens(&OpenAIUsage{InputTokens: 100, OutputTokens: 20, CacheReadInputTokens: 30})
+	require.Equal(t, 70, tokens.InputTokens)
+	require.Equal(t, 30, tokens.CacheReadTokens)
+	require.Equal(t, 20, tokens.OutputTokens)
+}
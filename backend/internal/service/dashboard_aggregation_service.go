@@ -34,7 +34,12 @@ type DashboardAggregationRepository interface {
 	GetAggregationWatermark(ctx context.Context) (time.Time, error)
 	UpdateAggregationWatermark(ctx context.Context, aggregatedAt time.Time) error
 	CleanupAggregates(ctx context.Context, hourlyCutoff, dailyCutoff time.Time) error
+	// CleanupUsageLogs 删除 cutoff 之前的原始日志；删除前先归档到维度日汇总（usage_daily_summaries）。
 	CleanupUsageLogs(ctx context.Context, cutoff time.Time) error
+	CleanupUsageSummaries(ctx context.Context, cutoff time.Time) error
+	// HasUsageSummaries / SummarizeUsageRange 用于升级后一次性回填维度小时 / 日汇总。
+	HasUsageSummaries(ctx context.Context) (bool, error)
+	SummarizeUsageRange(ctx context.Context, start, end time.Time) error
 	CleanupUsageBillingDedup(ctx context.Context, cutoff time.Time) error
 	EnsureUsageLogsPartitions(ctx context.Context, now time.Time) error
 }
@@ -79,6 +84,7 @@ func (s *DashboardAggregationService) Start() {
 	if s.cfg.RecomputeDays > 0 {
 		go s.recomputeRecentDays()
 	}
+	go s.backfillUsageSummaries()
 
 	s.timingWheel.ScheduleRecurring("dashboard:aggregation", interval, func() {
 		s.runScheduledAggregation()
@@ -169,6 +175,42 @@ func (s *DashboardAggregationService) recomputeRecentDays() {
 	}
 }
 
+// backfillUsageSummaries 维度小时汇总为空时（首次启用或升级），按天补齐现存原始日志的小时 / 日汇总。
+// 不占用聚合运行锁：汇总为幂等 upsert，与定时聚合并发执行不会产生不一致。
+func (s *DashboardAggregationService) backfillUsageSummaries() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDashboardAggregationBackfillTimeout)
+	defer cancel()
+
+	exists, err := s.repo.HasUsageSummaries(ctx)
+	if err != nil {
+		logger.LegacyPrintf("service.dashboard_aggregation", "[DashboardAggregation] 检查维度汇总失败: %v", err)
+		return
+	}
+	if exists {
+		return
+	}
+	retentionDays := s.cfg.Retention.UsageLogsDays
+	if retentionDays <= 0 {
+		return
+	}
+
+	jobStart := time.Now().UTC()
+	end := jobStart
+	cursor := truncateToDayUTC(end.AddDate(0, 0, -retentionDays))
+	for cursor.Before(end) {
+		windowEnd := cursor.Add(24 * time.Hour)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+		if err := s.repo.SummarizeUsageRange(ctx, cursor, windowEnd); err != nil {
+			logger.LegacyPrintf("service.dashboard_aggregation", "[DashboardAggregation] 维度汇总回填失败: %v", err)
+			return
+		}
+		cursor = windowEnd
+	}
+	logger.LegacyPrintf("service.dashboard_aggregation", "[DashboardAggregation] 维度汇总回填完成 (days=%d duration=%s)", retentionDays, time.Since(jobStart).String())
+}
+
 func (s *DashboardAggregationService) recomputeRange(ctx context.Context, start, end time.Time) error {
 	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
 		return errDashboardAggregationRunning
@@ -311,7 +353,15 @@ func (s *DashboardAggregationService) maybeCleanupRetention(ctx context.Context,
 	if dedupErr != nil {
 		logger.LegacyPrintf("service.dashboard_aggregation", "[DashboardAggregation] usage_billing_dedup 保留清理失败: %v", dedupErr)
 	}
-	if aggErr == nil && usageErr == nil && dedupErr == nil {
+	var summaryErr error
+	if s.cfg.Retention.SummaryDays > 0 {
+		// summary_days=0 表示维度日汇总永久保留
+		summaryErr = s.repo.CleanupUsageSummaries(ctx, now.AddDate(0, 0, -s.cfg.Retention.SummaryDays))
+	}
+	if summaryErr != nil {
+		logger.LegacyPrintf("service.dashboard_aggregation", "[DashboardAggregation] 维度汇总保留清理失败: %v", summaryErr)
+	}
+	if aggErr == nil && usageErr == nil && dedupErr == nil && summaryErr == nil {
 		s.lastRetentionCleanup.Store(now)
 	}
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,6 +16,9 @@ type dashboardAggregationRepoTestStub struct {
 	recomputeCalls       int
 	cleanupUsageCalls    int
 	cleanupDedupCalls    int
+	cleanupSummaryCalls  int
+	summarizeCalls       int
+	hasSummaries         bool
 	ensurePartitionCalls int
 	lastStart            time.Time
 	lastEnd              time.Time
@@ -60,6 +64,20 @@ func (s *dashboardAggregationRepoTestStub) CleanupUsageBillingDedup(ctx context.
 	return s.cleanupDedupErr
 }
 
+func (s *dashboardAggregationRepoTestStub) CleanupUsageSummaries(ctx context.Context, cutoff time.Time) error {
+	s.cleanupSummaryCalls++
+	return nil
+}
+
+func (s *dashboardAggregationRepoTestStub) HasUsageSummaries(ctx context.Context) (bool, error) {
+	return s.hasSummaries, nil
+}
+
+func (s *dashboardAggregationRepoTestStub) SummarizeUsageRange(ctx context.Context, start, end time.Time) error {
+	s.summarizeCalls++
+	return nil
+}
+
 func (s *dashboardAggregationRepoTestStub) EnsureUsageLogsPartitions(ctx context.Context, now time.Time) error {
 	s.ensurePartitionCalls++
 	return s.ensurePartitionErr
@@ -127,6 +145,48 @@ func TestDashboardAggregationService_CleanupDedupFailure_DoesNotRecord(t *testin
 	require.Equal(t, 1, repo.cleanupDedupCalls)
 }
 
+func TestDashboardAggregationService_CleanupUsageSummaries_ZeroKeepsForever(t *testing.T) {
+	repo := &dashboardAggregationRepoTestStub{}
+	svc := &DashboardAggregationService{
+		repo: repo,
+		cfg: config.DashboardAggregationConfig{
+			Retention: config.DashboardAggregationRetentionConfig{
+				UsageLogsDays:         1,
+				UsageBillingDedupDays: 2,
+				HourlyDays:            1,
+				DailyDays:             1,
+			},
+		},
+	}
+
+	svc.maybeCleanupRetention(context.Background(), time.Now().UTC())
+	require.Equal(t, 0, repo.cleanupSummaryCalls)
+	require.NotNil(t, svc.lastRetentionCleanup.Load())
+
+	svc.cfg.Retention.SummaryDays = 30
+	svc.lastRetentionCleanup = atomic.Value{}
+	svc.maybeCleanupRetention(context.Background(), time.Now().UTC())
+	require.Equal(t, 1, repo.cleanupSummaryCalls)
+}
+
+func TestDashboardAggregationService_BackfillUsageSummaries(t *testing.T) {
+	repo := &dashboardAggregationRepoTestStub{}
+	svc := &DashboardAggregationService{
+		repo: repo,
+		cfg: config.DashboardAggregationConfig{
+			Retention: config.DashboardAggregationRetentionConfig{UsageLogsDays: 3},
+		},
+	}
+
+	svc.backfillUsageSummaries()
+	// 起点对齐到 UTC 零点，覆盖 3 个整天加当天已过去的部分
+	require.Equal(t, 4, repo.summarizeCalls)
+
+	repo.hasSummaries = true
+	svc.backfillUsageSummaries()
+	require.Equal(t, 4, repo.summarizeCalls)
+}
+
 func TestDashboardAggregationService_PartitionFailure_DoesNotAggregate(t *testing.T) {
 	repo := &dashboardAggregationRepoTestStub{ensurePartitionErr: errors.New("partition failed")}
 	svc := &DashboardAggregationService{
@@ -18,6 +18,12 @@ const (
 	RoleUser  = domain.RoleUser
 )
 
+// Organization role constants
+const (
+	OrgRoleMember = domain.OrgRoleMember
+	OrgRoleAdmin  = domain.OrgRoleAdmin
+)
+
 // Affiliate rebate settings
 const (
 	AffiliateRebateRateDefault          = 20.0
@@ -37,6 +43,7 @@ const (
 	PlatformOpenAI      = domain.PlatformOpenAI
 	PlatformGemini      = domain.PlatformGemini
 	PlatformAntigravity = domain.PlatformAntigravity
+	PlatformCustom      = domain.PlatformCustom
 )
 
 // Account type constants
@@ -47,6 +54,8 @@ const (
 	AccountTypeUpstream       = domain.AccountTypeUpstream       // 上游透传类型账号（通过 Base URL + API Key 连接上游）
 	AccountTypeBedrock        = domain.AccountTypeBedrock        // AWS Bedrock 类型账号（通过 SigV4 签名或 API Key 连接 Bedrock，由 credentials.auth_mode 区分）
 	AccountTypeServiceAccount = domain.AccountTypeServiceAccount // Google Service Account 类型账号（用于 Vertex AI）
+	AccountTypeOllama         = domain.AccountTypeOllama         // Ollama 本地模型账号（仅 custom 平台）
+	AccountTypeAzure          = domain.AccountTypeAzure          // Azure OpenAI 账号（仅 custom 平台）
 )
 
 // Redeem type constants
@@ -75,6 +84,31 @@ const (
 	SubscriptionTypeSubscription = domain.SubscriptionTypeSubscription // 订阅模式（按限额控制）
 )
 
+// Request priority constants（并发等待队列优先级）
+const (
+	PriorityHigh   = domain.PriorityHigh
+	PriorityNormal = domain.PriorityNormal
+	PriorityLow    = domain.PriorityLow
+)
+
+// Group system prompt position constants（分组系统提示词注入位置）
+const (
+	SystemPromptModePrepend = domain.SystemPromptModePrepend
+	SystemPromptModeAppend  = domain.SystemPromptModeAppend
+)
+
+// Group PII redaction policy constants（分组敏感信息过滤策略，空字符串表示关闭）
+const (
+	PIIRedactionModeMask   = domain.PIIRedactionModeMask
+	PIIRedactionModeReject = domain.PIIRedactionModeReject
+)
+
+// API key moderation override constants（API Key 内容审核覆盖，空字符串表示继承分组）
+const (
+	ModerationModeEnabled  = domain.ModerationModeEnabled
+	ModerationModeDisabled = domain.ModerationModeDisabled
+)
+
 // Subscription status constants
 const (
 	SubscriptionStatusActive    = domain.SubscriptionStatusActive
@@ -262,6 +296,15 @@ const (
 	// SettingKeyOpsRuntimeLogConfig stores JSON config for runtime log settings.
 	SettingKeyOpsRuntimeLogConfig = "ops_runtime_log_config"
 
+	// SettingKeyWebhookConfig stores JSON config for admin webhook notifications (account state changes).
+	SettingKeyWebhookConfig = "webhook_config"
+
+	// SettingKeyAccountRotationPolicies stores JSON list of scheduled account rotation policies.
+	SettingKeyAccountRotationPolicies = "account_rotation_policies"
+
+	// SettingKeyModelCatalogOverrides stores JSON list of admin overrides for model metadata.
+	SettingKeyModelCatalogOverrides = "model_catalog_overrides"
+
 	// =========================
 	// Channel Monitor (渠道监控)
 	// =========================
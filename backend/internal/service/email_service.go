@@ -24,6 +24,8 @@ var (
 	ErrInvalidVerifyCode     = infraerrors.BadRequest("INVALID_VERIFY_CODE", "invalid or expired verification code")
 	ErrVerifyCodeTooFrequent = infraerrors.TooManyRequests("VERIFY_CODE_TOO_FREQUENT", "please wait before requesting a new code")
 	ErrVerifyCodeMaxAttempts = infraerrors.TooManyRequests("VERIFY_CODE_MAX_ATTEMPTS", "too many failed attempts, please request a new code")
+	// ErrEmailCacheUnavailable 验证码与重置令牌存放在 Redis，concurrency.backend=memory 不部署 Redis 时不可用
+	ErrEmailCacheUnavailable = infraerrors.ServiceUnavailable("EMAIL_CACHE_UNAVAILABLE", "email verification is unavailable without redis")
 
 	// Password reset errors
 	ErrInvalidResetToken = infraerrors.BadRequest("INVALID_RESET_TOKEN", "invalid or expired password reset token")
@@ -302,6 +304,10 @@ func (s *EmailService) GenerateVerifyCode() (string, error) {
 
 // SendVerifyCode 发送验证码邮件
 func (s *EmailService) SendVerifyCode(ctx context.Context, email, siteName string) error {
+	if s.cache == nil {
+		return ErrEmailCacheUnavailable
+	}
+
 	// 检查是否在冷却期内
 	existing, err := s.cache.GetVerificationCode(ctx, email)
 	if err == nil && existing != nil {
@@ -341,6 +347,9 @@ func (s *EmailService) SendVerifyCode(ctx context.Context, email, siteName strin
 
 // VerifyCode 验证验证码
 func (s *EmailService) VerifyCode(ctx context.Context, email, code string) error {
+	if s.cache == nil {
+		return ErrEmailCacheUnavailable
+	}
 	data, err := s.cache.GetVerificationCode(ctx, email)
 	if err != nil || data == nil {
 		return ErrInvalidVerifyCode
@@ -470,6 +479,9 @@ func (s *EmailService) GeneratePasswordResetToken() (string, error) {
 
 // SendPasswordResetEmail sends a password reset email with a reset link
 func (s *EmailService) SendPasswordResetEmail(ctx context.Context, email, siteName, resetURL string) error {
+	if s.cache == nil {
+		return ErrEmailCacheUnavailable
+	}
 	var token string
 	var needSaveToken bool
 
@@ -517,6 +529,10 @@ func (s *EmailService) SendPasswordResetEmail(ctx context.Context, email, siteNa
 // SendPasswordResetEmailWithCooldown sends password reset email with cooldown check (called by queue worker)
 // This method wraps SendPasswordResetEmail with email cooldown to prevent email bombing
 func (s *EmailService) SendPasswordResetEmailWithCooldown(ctx context.Context, email, siteName, resetURL string) error {
+	if s.cache == nil {
+		return ErrEmailCacheUnavailable
+	}
+
 	// Check email cooldown to prevent email bombing
 	if s.cache.IsPasswordResetEmailInCooldown(ctx, email) {
 		slog.Info("password reset email skipped due to cooldown", "email", email)
@@ -538,6 +554,9 @@ func (s *EmailService) SendPasswordResetEmailWithCooldown(ctx context.Context, e
 
 // VerifyPasswordResetToken verifies the password reset token without consuming it
 func (s *EmailService) VerifyPasswordResetToken(ctx context.Context, email, token string) error {
+	if s.cache == nil {
+		return ErrEmailCacheUnavailable
+	}
 	data, err := s.cache.GetPasswordResetToken(ctx, email)
 	if err != nil || data == nil {
 		return ErrInvalidResetToken
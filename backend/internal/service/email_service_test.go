@@ -0,0 +1,20 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailService_WithoutCacheReportsUnavailable(t *testing.T) {
+	ctx := context.Background()
+	svc := NewEmailService(nil, nil)
+
+	require.ErrorIs(t, svc.SendVerifyCode(ctx, "user@example.com", "Sub2API"), ErrEmailCacheUnavailable)
+	require.ErrorIs(t, svc.VerifyCode(ctx, "user@example.com", "123456"), ErrEmailCacheUnavailable)
+	require.ErrorIs(t, svc.SendPasswordResetEmailWithCooldown(ctx, "user@example.com", "Sub2API", "https://example.com/reset"), ErrEmailCacheUnavailable)
+	require.ErrorIs(t, svc.ConsumePasswordResetToken(ctx, "user@example.com", "token"), ErrEmailCacheUnavailable)
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/model"
@@ -23,6 +24,10 @@ type ErrorPassthroughRepository interface {
 	Update(ctx context.Context, rule *model.ErrorPassthroughRule) (*model.ErrorPassthroughRule, error)
 	// Delete 删除规则
 	Delete(ctx context.Context, id int64) error
+	// IncrementHits 累加命中次数并更新最近命中时间
+	IncrementHits(ctx context.Context, id int64, delta int64, lastHitAt time.Time) error
+	// DisableExpired 禁用已过期的启用规则，返回禁用数量
+	DisableExpired(ctx context.Context, now time.Time) (int, error)
 }
 
 // ErrorPassthroughCache 定义错误透传规则的缓存接口
@@ -47,6 +52,20 @@ type ErrorPassthroughService struct {
 	// 本地内存缓存，用于快速匹配
 	localCache   []*cachedPassthroughRule
 	localCacheMu sync.RWMutex
+
+	// 命中计数先在内存中累加（map[int64]*passthroughRuleHits），由后台任务定期批量写入数据库
+	pendingHits sync.Map
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+// passthroughRuleHits 单条规则尚未落库的命中统计
+type passthroughRuleHits struct {
+	count   atomic.Int64
+	lastHit atomic.Int64 // UnixNano
 }
 
 // cachedPassthroughRule 预计算的规则缓存，避免运行时重复 ToLower
@@ -59,14 +78,19 @@ type cachedPassthroughRule struct {
 
 const maxBodyMatchLen = 8 << 10 // 8KB，错误信息不会在 8KB 之后才出现
 
+// errorPassthroughMaintenanceInterval 命中计数落库与过期规则自动禁用的执行周期
+const errorPassthroughMaintenanceInterval = 30 * time.Second
+
 // NewErrorPassthroughService 创建错误透传规则服务
 func NewErrorPassthroughService(
 	repo ErrorPassthroughRepository,
 	cache ErrorPassthroughCache,
 ) *ErrorPassthroughService {
 	svc := &ErrorPassthroughService{
-		repo:  repo,
-		cache: cache,
+		repo:   repo,
+		cache:  cache,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
 	}
 
 	// 启动时加载规则到本地缓存
@@ -90,14 +114,134 @@ func NewErrorPassthroughService(
 	return svc
 }
 
-// List 获取所有规则
+// Start 启动后台任务：定期将命中计数写入数据库，并自动禁用已过期的规则
+func (s *ErrorPassthroughService) Start() {
+	if s == nil || s.stopCh == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		go s.runMaintenanceLoop()
+	})
+}
+
+// Stop 停止后台任务，并在退出前写入剩余的命中计数
+func (s *ErrorPassthroughService) Stop() {
+	if s == nil || s.stopCh == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		started := true
+		s.startOnce.Do(func() { started = false })
+		if started {
+			<-s.doneCh
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.flushHits(ctx)
+	})
+}
+
+func (s *ErrorPassthroughService) runMaintenanceLoop() {
+	defer close(s.doneCh)
+	ticker := time.NewTicker(errorPassthroughMaintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			s.runMaintenance(ctx, time.Now())
+			cancel()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// runMaintenance 写入命中计数并禁用已过期的规则
+func (s *ErrorPassthroughService) runMaintenance(ctx context.Context, now time.Time) {
+	s.flushHits(ctx)
+
+	disabled, err := s.repo.DisableExpired(ctx, now)
+	if err != nil {
+		logger.LegacyPrintf("service.error_passthrough", "[ErrorPassthroughService] Failed to disable expired rules: %v", err)
+		return
+	}
+	if disabled > 0 {
+		logger.LegacyPrintf("service.error_passthrough", "[ErrorPassthroughService] Auto-disabled %d expired rule(s)", disabled)
+		s.invalidateAndNotify(ctx)
+	}
+}
+
+// recordHit 在内存中累加规则命中
+func (s *ErrorPassthroughService) recordHit(ruleID int64, now time.Time) {
+	v, ok := s.pendingHits.Load(ruleID)
+	if !ok {
+		v, _ = s.pendingHits.LoadOrStore(ruleID, &passthroughRuleHits{})
+	}
+	hits := v.(*passthroughRuleHits)
+	hits.count.Add(1)
+	hits.lastHit.Store(now.UnixNano())
+}
+
+// flushHits 将内存中的命中计数批量写入数据库，写入失败的计数保留到下次重试
+func (s *ErrorPassthroughService) flushHits(ctx context.Context) {
+	s.pendingHits.Range(func(key, value any) bool {
+		ruleID := key.(int64)
+		hits := value.(*passthroughRuleHits)
+		delta := hits.count.Swap(0)
+		if delta <= 0 {
+			return true
+		}
+		lastHit := time.Unix(0, hits.lastHit.Load())
+		if err := s.repo.IncrementHits(ctx, ruleID, delta, lastHit); err != nil {
+			hits.count.Add(delta)
+			logger.LegacyPrintf("service.error_passthrough", "[ErrorPassthroughService] Failed to flush hits for rule %d: %v", ruleID, err)
+		}
+		return true
+	})
+}
+
+// applyPendingHits 将尚未落库的命中计数合并到规则上，保证列表中的统计是实时的
+func (s *ErrorPassthroughService) applyPendingHits(rule *model.ErrorPassthroughRule) {
+	if rule == nil {
+		return
+	}
+	v, ok := s.pendingHits.Load(rule.ID)
+	if !ok {
+		return
+	}
+	hits := v.(*passthroughRuleHits)
+	rule.HitCount += hits.count.Load()
+	if last := hits.lastHit.Load(); last > 0 {
+		t := time.Unix(0, last)
+		if rule.LastHitAt == nil || t.After(*rule.LastHitAt) {
+			rule.LastHitAt = &t
+		}
+	}
+}
+
+// List 获取所有规则（包含实时命中统计）
 func (s *ErrorPassthroughService) List(ctx context.Context) ([]*model.ErrorPassthroughRule, error) {
-	return s.repo.List(ctx)
+	rules, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		s.applyPendingHits(rule)
+	}
+	return rules, nil
 }
 
 // GetByID 根据 ID 获取规则
 func (s *ErrorPassthroughService) GetByID(ctx context.Context, id int64) (*model.ErrorPassthroughRule, error) {
-	return s.repo.GetByID(ctx, id)
+	rule, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.applyPendingHits(rule)
+	return rule, nil
 }
 
 // Create 创建规则
@@ -143,6 +287,7 @@ func (s *ErrorPassthroughService) Delete(ctx context.Context, id int64) error {
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return err
 	}
+	s.pendingHits.Delete(id)
 
 	// 刷新缓存
 	refreshCtx, cancel := s.newCacheRefreshContext()
@@ -152,26 +297,35 @@ func (s *ErrorPassthroughService) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-// MatchRule 匹配透传规则
-// 返回第一个匹配的规则，如果没有匹配则返回 nil
+// MatchRule 匹配透传规则并记录命中
+// 返回第一个匹配的规则，如果没有匹配则返回 nil；已过期的规则不参与匹配
 func (s *ErrorPassthroughService) MatchRule(platform string, statusCode int, body []byte) *model.ErrorPassthroughRule {
+	return s.matchRule(platform, statusCode, body, true)
+}
+
+// matchRule 匹配透传规则；recordHit=false 用于仅判断监控行为的场景，避免同一请求重复计数
+func (s *ErrorPassthroughService) matchRule(platform string, statusCode int, body []byte, recordHit bool) *model.ErrorPassthroughRule {
 	rules := s.getCachedRules()
 	if len(rules) == 0 {
 		return nil
 	}
 
+	now := time.Now()
 	lowerPlatform := strings.ToLower(platform)
 	var bodyLower string // 延迟初始化，只在需要关键词匹配时计算
 	var bodyLowerDone bool
 
 	for _, rule := range rules {
-		if !rule.Enabled {
+		if !rule.Enabled || rule.IsExpired(now) {
 			continue
 		}
 		if !s.platformMatchesCached(rule, lowerPlatform) {
 			continue
 		}
 		if s.ruleMatchesOptimized(rule, statusCode, body, &bodyLower, &bodyLowerDone) {
+			if recordHit {
+				s.recordHit(rule.ID, now)
+			}
 			return rule.ErrorPassthroughRule
 		}
 	}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/model"
 	"github.com/stretchr/testify/assert"
@@ -133,6 +134,30 @@ func (m *mockErrorPassthroughRepo) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (m *mockErrorPassthroughRepo) IncrementHits(ctx context.Context, id int64, delta int64, lastHitAt time.Time) error {
+	for _, r := range m.rules {
+		if r.ID == id {
+			r.HitCount += delta
+			if r.LastHitAt == nil || lastHitAt.After(*r.LastHitAt) {
+				t := lastHitAt
+				r.LastHitAt = &t
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mockErrorPassthroughRepo) DisableExpired(ctx context.Context, now time.Time) (int, error) {
+	n := 0
+	for _, r := range m.rules {
+		if r.Enabled && r.IsExpired(now) {
+			r.Enabled = false
+			n++
+		}
+	}
+	return n, nil
+}
+
 // newTestService 创建测试用的服务实例
 func newTestService(rules []*model.ErrorPassthroughRule) *ErrorPassthroughService {
 	repo := &mockErrorPassthroughRepo{rules: rules}
@@ -1012,3 +1037,57 @@ func newPassthroughRuleForWritePathTest(id int64, keyword, customMsg string) *mo
 // Helper functions
 func testIntPtr(i int) *int       { return &i }
 func testStrPtr(s string) *string { return &s }
+
+func TestMatchRule_SkipsExpiredRule(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	rules := []*model.ErrorPassthroughRule{
+		{ID: 1, Name: "expired", Enabled: true, Priority: 1, ErrorCodes: []int{429}, MatchMode: model.MatchModeAny, ExpiresAt: &past},
+		{ID: 2, Name: "active", Enabled: true, Priority: 2, ErrorCodes: []int{429}, MatchMode: model.MatchModeAny, ExpiresAt: &future},
+	}
+	svc := newTestService(rules)
+
+	matched := svc.MatchRule("anthropic", 429, []byte("rate limited"))
+	require.NotNil(t, matched)
+	assert.Equal(t, int64(2), matched.ID)
+}
+
+func TestMatchRule_RecordsHitsAndFlushes(t *testing.T) {
+	rules := []*model.ErrorPassthroughRule{
+		{ID: 1, Name: "r1", Enabled: true, ErrorCodes: []int{500}, MatchMode: model.MatchModeAny},
+	}
+	svc := newTestService(rules)
+	ctx := context.Background()
+
+	require.NotNil(t, svc.MatchRule("openai", 500, nil))
+	require.NotNil(t, svc.MatchRule("openai", 500, nil))
+	// 仅用于监控判断的匹配不计数
+	require.NotNil(t, svc.matchRule("openai", 500, nil, false))
+
+	listed, err := svc.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), listed[0].HitCount)
+	require.NotNil(t, listed[0].LastHitAt)
+
+	// 落库后内存计数清零，重复读取不会重复累加
+	rules[0].HitCount = 0
+	svc.flushHits(ctx)
+	assert.Equal(t, int64(2), rules[0].HitCount)
+	got, err := svc.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), got.HitCount)
+}
+
+func TestRunMaintenance_DisablesExpiredRules(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	rules := []*model.ErrorPassthroughRule{
+		{ID: 1, Name: "temp", Enabled: true, ErrorCodes: []int{529}, MatchMode: model.MatchModeAny, ExpiresAt: &past},
+		{ID: 2, Name: "permanent", Enabled: true, ErrorCodes: []int{503}, MatchMode: model.MatchModeAny},
+	}
+	svc := newTestService(rules)
+
+	svc.runMaintenance(context.Background(), time.Now())
+
+	assert.False(t, rules[0].Enabled)
+	assert.True(t, rules[1].Enabled)
+}
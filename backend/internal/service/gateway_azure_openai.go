@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// Azure OpenAI 账号（custom 平台，type=azure）凭证字段：
+//
+//	base_url               资源地址，如 https://my-resource.openai.azure.com
+//	api_key                资源密钥，默认通过 api-key 头发送（可用 auth_header_template 覆盖）
+//	api_version            Chat Completions 的 api-version，默认 azureOpenAIDefaultAPIVersion
+//	responses_api_version  Responses API 的 api-version，默认 azureOpenAIDefaultResponsesAPIVersion
+//	model_mapping          请求模型 → 部署名（deployment）；未命中时以请求模型名作为部署名
+const (
+	azureOpenAIAuthHeaderTemplate         = "api-key: {{api_key}}"
+	azureOpenAIDefaultAPIVersion          = "2024-10-21"
+	azureOpenAIDefaultResponsesAPIVersion = "2025-04-01-preview"
+)
+
+// azureOpenAIResponsesUsagePaths Responses API 用量格式（input_tokens 含缓存命中部分）
+var azureOpenAIResponsesUsagePaths = customProviderUsagePaths{
+	InputTokens:     "usage.input_tokens",
+	OutputTokens:    "usage.output_tokens",
+	CacheReadTokens: "usage.input_tokens_details.cached_tokens",
+}
+
+// azureOpenAIResponsesStreamUsagePaths Responses 流式 response.completed 事件中的用量路径
+var azureOpenAIResponsesStreamUsagePaths = customProviderUsagePaths{
+	InputTokens:     "response.usage.input_tokens",
+	OutputTokens:    "response.usage.output_tokens",
+	CacheReadTokens: "response.usage.input_tokens_details.cached_tokens",
+}
+
+func azureOpenAIAPIVersion(account *Account) string {
+	if v := strings.TrimSpace(account.GetCredential("api_version")); v != "" {
+		return v
+	}
+	return azureOpenAIDefaultAPIVersion
+}
+
+func azureOpenAIResponsesAPIVersion(account *Account) string {
+	if v := strings.TrimSpace(account.GetCredential("responses_api_version")); v != "" {
+		return v
+	}
+	return azureOpenAIDefaultResponsesAPIVersion
+}
+
+// azureOpenAIResourceBase 去掉 base_url 中多余的 /openai 段，兼容填写了完整前缀的配置
+func azureOpenAIResourceBase(baseURL string) string {
+	base := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	return strings.TrimSuffix(base, "/openai")
+}
+
+// azureOpenAIChatCompletionsURL 部署式路由：/openai/deployments/{deployment}/chat/completions?api-version=...
+func azureOpenAIChatCompletionsURL(baseURL, deployment, apiVersion string) string {
+	return azureOpenAIResourceBase(baseURL) + "/openai/deployments/" + url.PathEscape(deployment) +
+		"/chat/completions?api-version=" + url.QueryEscape(apiVersion)
+}
+
+// azureOpenAIResponsesURL Responses API 不区分部署路径，部署名通过请求体 model 指定
+func azureOpenAIResponsesURL(baseURL, apiVersion string) string {
+	return azureOpenAIResourceBase(baseURL) + "/openai/responses?api-version=" + url.QueryEscape(apiVersion)
+}
+
+// ForwardCustomResponses 将 Responses API 请求透传到 custom 平台账号。
+// 目前仅 Azure OpenAI 账号提供 Responses API，其余账号返回 400。
+func (s *GatewayService) ForwardCustomResponses(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+) (*ForwardResult, error) {
+	startTime := time.Now()
+	if !account.IsAzureOpenAI() {
+		writeResponsesError(c, http.StatusBadRequest, "invalid_request_error", "Responses API is not available for this account")
+		return nil, fmt.Errorf("custom provider account %d (%s) does not support responses api", account.ID, account.Type)
+	}
+
+	originalModel := gjson.GetBytes(body, "model").String()
+	clientStream := gjson.GetBytes(body, "stream").Bool()
+	deployment := account.GetMappedModel(originalModel)
+	if deployment != originalModel {
+		if next, err := sjson.SetBytes(body, "model", deployment); err == nil {
+			body = next
+		}
+	}
+
+	baseURL, err := s.resolveCustomProviderBaseURL(c, account, writeResponsesError)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.L().Debug("gateway azure openai responses: forwarding",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("deployment", deployment),
+		zap.Bool("client_stream", clientStream),
+	)
+
+	resp, err := s.doCustomProviderRequest(ctx, c, account, azureOpenAIResponsesURL(baseURL, azureOpenAIResponsesAPIVersion(account)), body, clientStream, writeResponsesError)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if clientStream {
+		stopIdleTimeout := guardStreamIdleTimeout(ctx, resp, s.cfg, s.rateLimitService, account, originalModel)
+		defer stopIdleTimeout()
+		return s.handleAzureResponsesStreaming(resp, c, originalModel, deployment, startTime)
+	}
+
+	respBody, err := readUpstreamResponseBodyLimited(resp.Body, resolveUpstreamResponseReadLimit(s.cfg))
+	if err != nil {
+		writeResponsesError(c, http.StatusBadGateway, "server_error", "Failed to read upstream response")
+		return nil, fmt.Errorf("read upstream response: %w", err)
+	}
+	usage, _ := extractCustomProviderUsage(respBody, azureOpenAIResponsesUsagePaths, true)
+	if deployment != originalModel && gjson.GetBytes(respBody, "model").Exists() {
+		if next, err := sjson.SetBytes(respBody, "model", originalModel); err == nil {
+			respBody = next
+		}
+	}
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", respBody)
+
+	return &ForwardResult{
+		RequestID:     resp.Header.Get("x-request-id"),
+		Usage:         usage,
+		Model:         originalModel,
+		UpstreamModel: deployment,
+		Stream:        false,
+		Duration:      time.Since(startTime),
+	}, nil
+}
+
+// handleAzureResponsesStreaming 透传 Responses SSE 事件（保留 event 行），从 response.completed 提取用量
+func (s *GatewayService) handleAzureResponsesStreaming(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	deployment string,
+	startTime time.Time,
+) (*ForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
+	}
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	stopKeepalive := startStreamKeepalive(c, s.cfg)
+	defer stopKeepalive()
+
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	reader := getSSEReader(resp.Body, maxLineSize)
+	defer putSSEReader(reader)
+
+	var usage ClaudeUsage
+	var firstTokenMs *int
+	clientDisconnected := false
+
+	resultWithUsage := func() *ForwardResult {
+		return &ForwardResult{
+			RequestID:        requestID,
+			Usage:            usage,
+			Model:            originalModel,
+			UpstreamModel:    deployment,
+			Stream:           true,
+			Duration:         time.Since(startTime),
+			FirstTokenMs:     firstTokenMs,
+			ClientDisconnect: clientDisconnected,
+		}
+	}
+
+	for {
+		event, err := reader.ReadEvent()
+		if err != nil {
+			break
+		}
+		if !event.HasData {
+			continue
+		}
+		data := []byte(event.Data)
+		eventType := gjson.GetBytes(data, "type").String()
+		if firstTokenMs == nil && strings.HasSuffix(eventType, ".delta") {
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+		if eventType == "response.completed" || eventType == "response.incomplete" {
+			if u, ok := extractCustomProviderUsage(data, azureOpenAIResponsesStreamUsagePaths, true); ok {
+				usage = u
+			}
+		}
+		if deployment != originalModel && gjson.GetBytes(data, "response.model").Exists() {
+			if next, err := sjson.SetBytes(data, "response.model", originalModel); err == nil {
+				data = next
+			}
+		}
+		if clientDisconnected {
+			continue
+		}
+		var writeErr error
+		if event.Event != "" {
+			_, writeErr = fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Event, data)
+		} else {
+			_, writeErr = fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		}
+		if writeErr != nil {
+			clientDisconnected = true
+			continue
+		}
+		c.Writer.Flush()
+	}
+
+	if err := reader.Err(); err != nil {
+		if errors.Is(err, ErrStreamIdleTimeout) {
+			return resultWithUsage(), handleStreamingIdleTimeout(c, s.cfg, streamErrorEventResponses)
+		}
+		if errors.Is(err, sse.ErrLineTooLong) {
+			return resultWithUsage(), handleStreamingLineTooLong(c, streamErrorEventResponses, maxLineSize, requestID)
+		}
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			logger.L().Warn("gateway azure openai responses stream: read error",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+		}
+	}
+	return resultWithUsage(), nil
+}
@@ -0,0 +1,453 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+// 自定义上游（PlatformCustom）账号凭证字段：
+//
+//	base_url              上游 OpenAI 兼容 API 地址，如 https://openrouter.ai/api/v1、http://127.0.0.1:11434
+//	api_key               上游密钥（本地 vLLM / Ollama 可留空）
+//	auth_header_template  认证头模板，"{{api_key}}" 替换为 api_key，默认 "Authorization: Bearer {{api_key}}"
+//	usage_paths           用量字段的 JSON 路径（gjson 语法），键为 input_tokens / output_tokens /
+//	                      cache_read_tokens / cache_creation_tokens，未配置的键使用 OpenAI 默认路径
+const (
+	customProviderDefaultAuthHeaderTemplate = "Authorization: Bearer {{api_key}}"
+	customProviderAPIKeyPlaceholder         = "{{api_key}}"
+)
+
+// customProviderErrorWriter 按入站协议格式写回错误（writeGatewayCCError / writeResponsesError）
+type customProviderErrorWriter func(c *gin.Context, statusCode int, errType, message string)
+
+var customProviderVersionSuffix = regexp.MustCompile(`/v\d+[a-z0-9]*$`)
+
+// customProviderUsagePaths 从上游响应提取用量的 JSON 路径
+type customProviderUsagePaths struct {
+	InputTokens         string
+	OutputTokens        string
+	CacheReadTokens     string
+	CacheCreationTokens string
+}
+
+// defaultCustomProviderUsagePaths OpenAI Chat Completions 用量格式（prompt_tokens 含缓存命中部分）
+var defaultCustomProviderUsagePaths = customProviderUsagePaths{
+	InputTokens:     "usage.prompt_tokens",
+	OutputTokens:    "usage.completion_tokens",
+	CacheReadTokens: "usage.prompt_tokens_details.cached_tokens",
+}
+
+// customProviderChatCompletionsURL 由 base_url 构造 Chat Completions 地址：
+// 已以版本段（/v1、/v1beta 等）结尾时直接拼接 /chat/completions，否则补全 /v1/chat/completions。
+func customProviderChatCompletionsURL(baseURL string) string {
+	base := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if strings.HasSuffix(base, "/chat/completions") {
+		return base
+	}
+	if customProviderVersionSuffix.MatchString(base) {
+		return base + "/chat/completions"
+	}
+	return base + "/v1/chat/completions"
+}
+
+// customProviderAuthHeader 按认证头模板生成上游认证头；api_key 为空时不发送认证头
+func customProviderAuthHeader(account *Account) (name, value string, ok bool) {
+	apiKey := strings.TrimSpace(account.GetCredential("api_key"))
+	if apiKey == "" {
+		return "", "", false
+	}
+	template := strings.TrimSpace(account.GetCredential("auth_header_template"))
+	if template == "" {
+		template = customProviderDefaultAuthHeaderTemplate
+		if account.IsAzureOpenAI() {
+			template = azureOpenAIAuthHeaderTemplate
+		}
+	}
+	name, value, found := strings.Cut(template, ":")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return "", "", false
+	}
+	return name, strings.ReplaceAll(strings.TrimSpace(value), customProviderAPIKeyPlaceholder, apiKey), true
+}
+
+// resolveCustomProviderUsagePaths 合并账号配置的 usage_paths 与默认路径。
+// 只要配置了 input_tokens，即视为上游的输入 token 已不含缓存命中部分，不再扣减 cache_read。
+func resolveCustomProviderUsagePaths(account *Account) (paths customProviderUsagePaths, inputIncludesCache bool) {
+	paths = defaultCustomProviderUsagePaths
+	inputIncludesCache = true
+	raw, _ := account.Credentials["usage_paths"].(map[string]any)
+	pick := func(key string, dst *string) bool {
+		if v, ok := raw[key].(string); ok && strings.TrimSpace(v) != "" {
+			*dst = strings.TrimSpace(v)
+			return true
+		}
+		return false
+	}
+	if pick("input_tokens", &paths.InputTokens) {
+		inputIncludesCache = false
+	}
+	pick("output_tokens", &paths.OutputTokens)
+	pick("cache_read_tokens", &paths.CacheReadTokens)
+	pick("cache_creation_tokens", &paths.CacheCreationTokens)
+	return paths, inputIncludesCache
+}
+
+// extractCustomProviderUsage 按配置路径从响应体（或流式 chunk）提取用量；未找到任何用量字段时 ok=false
+func extractCustomProviderUsage(data []byte, paths customProviderUsagePaths, inputIncludesCache bool) (usage ClaudeUsage, ok bool) {
+	get := func(path string) int {
+		if path == "" {
+			return 0
+		}
+		r := gjson.GetBytes(data, path)
+		if r.Exists() {
+			ok = true
+		}
+		return int(r.Int())
+	}
+	usage.InputTokens = get(paths.InputTokens)
+	usage.OutputTokens = get(paths.OutputTokens)
+	usage.CacheReadInputTokens = get(paths.CacheReadTokens)
+	usage.CacheCreationInputTokens = get(paths.CacheCreationTokens)
+	if inputIncludesCache {
+		usage.InputTokens = max(usage.InputTokens-usage.CacheReadInputTokens, 0)
+	}
+	return usage, ok
+}
+
+// ForwardCustomChatCompletions 将 Chat Completions 请求透传到自定义 OpenAI 兼容上游
+// （OpenRouter、DeepSeek、vLLM、Ollama 等）。上游地址、认证头与用量字段均来自账号凭证，
+// 接入新的兼容上游无需修改代码。
+func (s *GatewayService) ForwardCustomChatCompletions(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+) (*ForwardResult, error) {
+	startTime := time.Now()
+	if account.IsOllama() {
+		return s.forwardOllamaChatCompletions(ctx, c, account, body, startTime)
+	}
+
+	originalModel := gjson.GetBytes(body, "model").String()
+	clientStream := gjson.GetBytes(body, "stream").Bool()
+	clientIncludeUsage := gjson.GetBytes(body, "stream_options.include_usage").Bool()
+
+	mappedModel := account.GetMappedModel(originalModel)
+	if mappedModel != originalModel {
+		if next, err := sjson.SetBytes(body, "model", mappedModel); err == nil {
+			body = next
+		}
+	}
+	// 流式请求强制上游返回用量 chunk，否则无法计费
+	if clientStream && !clientIncludeUsage {
+		if next, err := sjson.SetBytes(body, "stream_options.include_usage", true); err == nil {
+			body = next
+		}
+	}
+
+	baseURL, err := s.resolveCustomProviderBaseURL(c, account, writeGatewayCCError)
+	if err != nil {
+		return nil, err
+	}
+	targetURL := customProviderChatCompletionsURL(baseURL)
+	if account.IsAzureOpenAI() {
+		targetURL = azureOpenAIChatCompletionsURL(baseURL, mappedModel, azureOpenAIAPIVersion(account))
+	}
+
+	logger.L().Debug("gateway custom provider: forwarding",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+		zap.Bool("client_stream", clientStream),
+	)
+
+	resp, err := s.doCustomProviderRequest(ctx, c, account, targetURL, body, clientStream, writeGatewayCCError)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	usagePaths, inputIncludesCache := resolveCustomProviderUsagePaths(account)
+	if clientStream {
+		stopIdleTimeout := guardStreamIdleTimeout(ctx, resp, s.cfg, s.rateLimitService, account, originalModel)
+		defer stopIdleTimeout()
+		return s.handleCustomProviderStreaming(resp, c, originalModel, mappedModel, usagePaths, inputIncludesCache, clientIncludeUsage, estimateChatPromptTokens(body, clientIncludeUsage), startTime)
+	}
+	return s.handleCustomProviderBuffered(resp, c, originalModel, mappedModel, usagePaths, inputIncludesCache, startTime)
+}
+
+// resolveCustomProviderBaseURL 校验账号 base_url；未配置或不合法时按协议格式写回 502
+func (s *GatewayService) resolveCustomProviderBaseURL(c *gin.Context, account *Account, writeError customProviderErrorWriter) (string, error) {
+	baseURL := account.GetCredential("base_url")
+	if strings.TrimSpace(baseURL) == "" {
+		writeError(c, http.StatusBadGateway, "server_error", "Custom provider base_url is not configured")
+		return "", fmt.Errorf("custom provider account %d: base_url is empty", account.ID)
+	}
+	validatedURL, err := s.validateUpstreamBaseURL(baseURL)
+	if err != nil {
+		writeError(c, http.StatusBadGateway, "server_error", "Custom provider base_url is invalid")
+		return "", err
+	}
+	return validatedURL, nil
+}
+
+// doCustomProviderRequest 向自定义上游发送 POST 请求并统一处理错误：网络错误与可 failover 的状态码
+// 返回 *UpstreamFailoverError，其余错误状态按协议格式写回客户端。成功时调用方负责关闭响应体。
+func (s *GatewayService) doCustomProviderRequest(ctx context.Context, c *gin.Context, account *Account, targetURL string, body []byte, stream bool, writeError customProviderErrorWriter) (*http.Response, error) {
+	body = ApplyAccountBodyTransforms(body, account)
+	upstreamCtx, releaseUpstreamCtx := detachStreamUpstreamContext(ctx, stream)
+	upstreamReq, err := http.NewRequestWithContext(upstreamCtx, http.MethodPost, targetURL, bytes.NewReader(body))
+	releaseUpstreamCtx()
+	if err != nil {
+		return nil, fmt.Errorf("build upstream request: %w", err)
+	}
+	upstreamReq.Header.Set("Content-Type", "application/json")
+	if stream {
+		upstreamReq.Header.Set("Accept", "text/event-stream")
+	}
+	if name, value, ok := customProviderAuthHeader(account); ok {
+		upstreamReq.Header.Set(name, value)
+	}
+
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+
+	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		if resp != nil && resp.Body != nil {
+			_ = resp.Body.Close()
+		}
+		safeErr := sanitizeUpstreamErrorMessage(err.Error())
+		setOpsUpstreamError(c, 0, safeErr, "")
+		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+			Platform:           account.Platform,
+			AccountID:          account.ID,
+			AccountName:        account.Name,
+			UpstreamStatusCode: 0,
+			Kind:               "request_error",
+			Message:            safeErr,
+		})
+		// 自建上游（vLLM / Ollama）宕机较常见，网络错误切换账号重试
+		return nil, &UpstreamFailoverError{StatusCode: http.StatusBadGateway}
+	}
+
+	if resp.StatusCode >= 400 {
+		defer func() { _ = resp.Body.Close() }()
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+		upstreamMsg := sanitizeUpstreamErrorMessage(strings.TrimSpace(extractUpstreamErrorMessage(respBody)))
+
+		if s.shouldFailoverUpstreamError(resp.StatusCode) {
+			appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
+				Platform:           account.Platform,
+				AccountID:          account.ID,
+				AccountName:        account.Name,
+				UpstreamStatusCode: resp.StatusCode,
+				UpstreamRequestID:  resp.Header.Get("x-request-id"),
+				Kind:               "failover",
+				Message:            upstreamMsg,
+			})
+			if s.rateLimitService != nil {
+				s.rateLimitService.HandleUpstreamError(ctx, account, resp.StatusCode, resp.Header, respBody)
+			}
+			return nil, &UpstreamFailoverError{
+				StatusCode:   resp.StatusCode,
+				ResponseBody: respBody,
+			}
+		}
+
+		writeError(c, mapUpstreamStatusCode(resp.StatusCode), "server_error", upstreamMsg)
+		return nil, fmt.Errorf("upstream error: %d %s", resp.StatusCode, upstreamMsg)
+	}
+	return resp, nil
+}
+
+// handleCustomProviderBuffered 透传非流式响应并提取用量
+func (s *GatewayService) handleCustomProviderBuffered(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	usagePaths customProviderUsagePaths,
+	inputIncludesCache bool,
+	startTime time.Time,
+) (*ForwardResult, error) {
+	respBody, err := readUpstreamResponseBodyLimited(resp.Body, resolveUpstreamResponseReadLimit(s.cfg))
+	if err != nil {
+		writeGatewayCCError(c, http.StatusBadGateway, "server_error", "Failed to read upstream response")
+		return nil, fmt.Errorf("read upstream response: %w", err)
+	}
+	usage, _ := extractCustomProviderUsage(respBody, usagePaths, inputIncludesCache)
+	if mappedModel != originalModel && gjson.GetBytes(respBody, "model").Exists() {
+		if next, err := sjson.SetBytes(respBody, "model", originalModel); err == nil {
+			respBody = next
+		}
+	}
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Data(http.StatusOK, contentType, respBody)
+
+	return &ForwardResult{
+		RequestID:     resp.Header.Get("x-request-id"),
+		Usage:         usage,
+		Model:         originalModel,
+		UpstreamModel: mappedModel,
+		Stream:        false,
+		Duration:      time.Since(startTime),
+	}, nil
+}
+
+// handleCustomProviderStreaming 逐条透传 SSE chunk 并累计用量。
+// 客户端未请求 include_usage 时，丢弃为计费注入的纯用量 chunk（choices 为空）；
+// 客户端请求了 include_usage 而上游未返回用量时，在 [DONE] 前补发按估算值合成的用量 chunk。
+// 客户端断开后继续读取上游直至结束，以便拿到最终用量。
+func (s *GatewayService) handleCustomProviderStreaming(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	usagePaths customProviderUsagePaths,
+	inputIncludesCache bool,
+	clientIncludeUsage bool,
+	estimatedPromptTokens int,
+	startTime time.Time,
+) (*ForwardResult, error) {
+	requestID := resp.Header.Get("x-request-id")
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
+	}
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	stopKeepalive := startStreamKeepalive(c, s.cfg)
+	defer stopKeepalive()
+
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	reader := getSSEReader(resp.Body, maxLineSize)
+	defer putSSEReader(reader)
+
+	var usage ClaudeUsage
+	var firstTokenMs *int
+	clientDisconnected := false
+	usageSeen := false
+	outputChars := 0
+	var lastChunk gjson.Result
+
+	resultWithUsage := func() *ForwardResult {
+		return &ForwardResult{
+			RequestID:        requestID,
+			Usage:            usage,
+			Model:            originalModel,
+			UpstreamModel:    mappedModel,
+			Stream:           true,
+			Duration:         time.Since(startTime),
+			FirstTokenMs:     firstTokenMs,
+			ClientDisconnect: clientDisconnected,
+		}
+	}
+
+	for {
+		event, err := reader.ReadEvent()
+		if err != nil {
+			break
+		}
+		if !event.HasData {
+			continue
+		}
+		data := []byte(event.Data)
+		if firstTokenMs == nil {
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+		usageOnly := false
+		if len(data) > 0 && data[0] == '{' {
+			if u, ok := extractCustomProviderUsage(data, usagePaths, inputIncludesCache); ok {
+				usage = u
+				usageSeen = true
+				usageOnly = !clientIncludeUsage && len(gjson.GetBytes(data, "choices").Array()) == 0
+			}
+			if clientIncludeUsage && !usageSeen {
+				outputChars += chatChunkOutputChars(data)
+				lastChunk = gjson.ParseBytes(data)
+			}
+			if mappedModel != originalModel && gjson.GetBytes(data, "model").Exists() {
+				if next, err := sjson.SetBytes(data, "model", originalModel); err == nil {
+					data = next
+				}
+			}
+		}
+		if clientDisconnected || usageOnly {
+			continue
+		}
+		if clientIncludeUsage && !usageSeen && event.Data == "[DONE]" {
+			usageSeen = true
+			chunk := apicompat.ChatCompletionsChunk{
+				ID:      lastChunk.Get("id").String(),
+				Object:  "chat.completion.chunk",
+				Created: lastChunk.Get("created").Int(),
+				Model:   originalModel,
+				Choices: []apicompat.ChatChunkChoice{},
+				Usage: chatStreamUsageFallback(usage.InputTokens, usage.OutputTokens, usage.CacheReadInputTokens,
+					estimatedPromptTokens, outputChars),
+			}
+			if sse, err := apicompat.ChatChunkToSSE(chunk); err == nil {
+				if _, err := fmt.Fprint(c.Writer, sse); err != nil {
+					clientDisconnected = true
+					continue
+				}
+			}
+		}
+		if _, err := fmt.Fprintf(c.Writer, "data: %s\n\n", data); err != nil {
+			clientDisconnected = true
+			continue
+		}
+		c.Writer.Flush()
+	}
+
+	if err := reader.Err(); err != nil {
+		if errors.Is(err, ErrStreamIdleTimeout) {
+			return resultWithUsage(), handleStreamingIdleTimeout(c, s.cfg, streamErrorEventChatCompletions)
+		}
+		if errors.Is(err, sse.ErrLineTooLong) {
+			return resultWithUsage(), handleStreamingLineTooLong(c, streamErrorEventChatCompletions, maxLineSize, requestID)
+		}
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			logger.L().Warn("gateway custom provider stream: read error",
+				zap.Error(err),
+				zap.String("request_id", requestID),
+			)
+		}
+	}
+	return resultWithUsage(), nil
+}
@@ -0,0 +1,252 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newCustomProviderTestAccount(credentials map[string]any) *Account {
+	return &Account{ID: 7, Name: "custom", Platform: PlatformCustom, Type: AccountTypeAPIKey, Concurrency: 1, Credentials: credentials}
+}
+
+func newCustomProviderTestService(resp *http.Response) (*GatewayService, *httpUpstreamRecorder) {
+	cfg := &config.Config{}
+	cfg.Security.URLAllowlist.AllowInsecureHTTP = true
+	upstream := &httpUpstreamRecorder{resp: resp}
+	return &GatewayService{cfg: cfg, httpUpstream: upstream}, upstream
+}
+
+func TestCustomProviderChatCompletionsURL(t *testing.T) {
+	require.Equal(t, "https://openrouter.ai/api/v1/chat/completions", customProviderChatCompletionsURL("https://openrouter.ai/api/v1/"))
+	require.Equal(t, "http://127.0.0.1:11434/v1/chat/completions", customProviderChatCompletionsURL("http://127.0.0.1:11434"))
+	require.Equal(t, "https://api.deepseek.com/v1/chat/completions", customProviderChatCompletionsURL("https://api.deepseek.com"))
+	require.Equal(t, "https://example.com/v1beta/chat/completions", customProviderChatCompletionsURL("https://example.com/v1beta"))
+	require.Equal(t, "https://example.com/x/chat/completions", customProviderChatCompletionsURL("https://example.com/x/chat/completions"))
+}
+
+func TestCustomProviderAuthHeader(t *testing.T) {
+	name, value, ok := customProviderAuthHeader(newCustomProviderTestAccount(map[string]any{"api_key": "sk-1"}))
+	require.True(t, ok)
+	require.Equal(t, "Authorization", name)
+	require.Equal(t, "Bearer sk-1", value)
+
+	name, value, ok = customProviderAuthHeader(newCustomProviderTestAccount(map[string]any{"api_key": "sk-1", "auth_header_template": "X-Api-Key: {{api_key}}"}))
+	require.True(t, ok)
+	require.Equal(t, "X-Api-Key", name)
+	require.Equal(t, "sk-1", value)
+
+	_, _, ok = customProviderAuthHeader(newCustomProviderTestAccount(map[string]any{}))
+	require.False(t, ok)
+}
+
+func TestExtractCustomProviderUsage(t *testing.T) {
+	paths, includesCache := resolveCustomProviderUsagePaths(newCustomProviderTestAccount(nil))
+	usage, ok := extractCustomProviderUsage([]byte(`{"usage":{"prompt_tokens":100,"completion_tokens":20,"prompt_tokens_details":{"cached_tokens":30}}}`), paths, includesCache)
+	require.True(t, ok)
+	require.Equal(t, ClaudeUsage{InputTokens: 70, OutputTokens: 20, CacheReadInputTokens: 30}, usage)
+
+	_, ok = extractCustomProviderUsage([]byte(`{"choices":[]}`), paths, includesCache)
+	require.False(t, ok)
+
+	// DeepSeek 风格：输入 token 已拆分为缓存命中与未命中
+	account := newCustomProviderTestAccount(map[string]any{"usage_paths": map[string]any{
+		"input_tokens":      "usage.prompt_cache_miss_tokens",
+		"cache_read_tokens": "usage.prompt_cache_hit_tokens",
+	}})
+	paths, includesCache = resolveCustomProviderUsagePaths(account)
+	usage, ok = extractCustomProviderUsage([]byte(`{"usage":{"prompt_tokens":100,"completion_tokens":5,"prompt_cache_hit_tokens":60,"prompt_cache_miss_tokens":40}}`), paths, includesCache)
+	require.True(t, ok)
+	require.Equal(t, ClaudeUsage{InputTokens: 40, OutputTokens: 5, CacheReadInputTokens: 60}, usage)
+}
+
+func TestForwardCustomChatCompletions_StreamPassthrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stream := "data: {\"model\":\"up-model\",\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n" +
+		"data: {\"model\":\"up-model\",\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3}}\n\n" +
+		"data: [DONE]\n\n"
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": []string{"text/event-stream"}}, Body: io.NopCloser(strings.NewReader(stream))}
+	svc, upstream := newCustomProviderTestService(resp)
+	account := newCustomProviderTestAccount(map[string]any{
+		"base_url":      "http://127.0.0.1:8000/v1",
+		"api_key":       "sk-local",
+		"model_mapping": map[string]any{"my-model": "up-model"},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	result, err := svc.ForwardCustomChatCompletions(context.Background(), c, account, []byte(`{"model":"my-model","stream":true,"messages":[]}`))
+	require.NoError(t, err)
+	require.Equal(t, "http://127.0.0.1:8000/v1/chat/completions", upstream.lastReq.URL.String())
+	require.Equal(t, "Bearer sk-local", upstream.lastReq.Header.Get("Authorization"))
+	require.Equal(t, "up-model", gjson.GetBytes(upstream.lastBody, "model").String())
+	require.True(t, gjson.GetBytes(upstream.lastBody, "stream_options.include_usage").Bool())
+
+	require.Equal(t, 12, result.Usage.InputTokens)
+	require.Equal(t, 3, result.Usage.OutputTokens)
+	require.Equal(t, "my-model", result.Model)
+	require.Equal(t, "up-model", result.UpstreamModel)
+	require.NotNil(t, result.FirstTokenMs)
+
+	// 客户端未请求 include_usage：纯用量 chunk 不下发，模型名还原为请求模型
+	body := w.Body.String()
+	require.Contains(t, body, `"model":"my-model"`)
+	require.NotContains(t, body, "usage")
+	require.Contains(t, body, "data: [DONE]")
+}
+
+func TestForwardCustomChatCompletions_StreamSynthesizesMissingUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stream := "data: {\"id\":\"chatcmpl-1\",\"created\":1700000000,\"model\":\"m\",\"choices\":[{\"delta\":{\"content\":\"hello world!\"}}]}\n\n" +
+		"data: [DONE]\n\n"
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": []string{"text/event-stream"}}, Body: io.NopCloser(strings.NewReader(stream))}
+	svc, _ := newCustomProviderTestService(resp)
+	account := newCustomProviderTestAccount(map[string]any{"base_url": "http://127.0.0.1:8000/v1"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	_, err := svc.ForwardCustomChatCompletions(context.Background(), c, account, []byte(`{"model":"m","stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"hi there"}]}`))
+	require.NoError(t, err)
+
+	// 上游忽略 include_usage：在 [DONE] 前补发估算的纯用量 chunk
+	body := w.Body.String()
+	usageIdx := strings.Index(body, `"usage"`)
+	require.Positive(t, usageIdx)
+	require.Less(t, usageIdx, strings.Index(body, "data: [DONE]"))
+	chunks := strings.Split(strings.TrimSpace(body), "\n\n")
+	usageChunk := strings.TrimPrefix(chunks[len(chunks)-2], "data: ")
+	require.Equal(t, "chatcmpl-1", gjson.Get(usageChunk, "id").String())
+	require.Equal(t, int64(3), gjson.Get(usageChunk, "usage.completion_tokens").Int())
+	require.Positive(t, gjson.Get(usageChunk, "usage.prompt_tokens").Int())
+	require.Empty(t, gjson.Get(usageChunk, "choices").Array())
+}
+
+func TestForwardCustomChatCompletions_FailoverOnUpstreamError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"error":{"message":"overloaded"}}`))}
+	svc, _ := newCustomProviderTestService(resp)
+	account := newCustomProviderTestAccount(map[string]any{"base_url": "http://127.0.0.1:11434"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	_, err := svc.ForwardCustomChatCompletions(context.Background(), c, account, []byte(`{"model":"llama3","messages":[]}`))
+	var failoverErr *UpstreamFailoverError
+	require.ErrorAs(t, err, &failoverErr)
+	require.Equal(t, http.StatusServiceUnavailable, failoverErr.StatusCode)
+	require.Zero(t, w.Body.Len())
+}
+
+func TestForwardCustomChatCompletions_OllamaStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ndjson := `{"model":"llama3.1","message":{"role":"assistant","content":"he"},"done":false}` + "\n" +
+		`{"model":"llama3.1","message":{"role":"assistant","content":"llo"},"done":false}` + "\n" +
+		`{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":9,"eval_count":2}` + "\n"
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": []string{"application/x-ndjson"}}, Body: io.NopCloser(strings.NewReader(ndjson))}
+	svc, upstream := newCustomProviderTestService(resp)
+	account := newCustomProviderTestAccount(map[string]any{"base_url": "http://127.0.0.1:11434/v1"})
+	account.Type = AccountTypeOllama
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	result, err := svc.ForwardCustomChatCompletions(context.Background(), c, account, []byte(`{"model":"llama3.1","stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+	require.NoError(t, err)
+	require.Equal(t, "http://127.0.0.1:11434/api/chat", upstream.lastReq.URL.String())
+	require.Empty(t, upstream.lastReq.Header.Get("Authorization"))
+	require.Equal(t, "hi", gjson.GetBytes(upstream.lastBody, "messages.0.content").String())
+	require.True(t, gjson.GetBytes(upstream.lastBody, "stream").Bool())
+
+	require.Equal(t, ClaudeUsage{InputTokens: 9, OutputTokens: 2}, result.Usage)
+	body := w.Body.String()
+	require.Contains(t, body, `"content":"he"`)
+	require.Contains(t, body, `"finish_reason":"stop"`)
+	require.True(t, strings.HasSuffix(body, "data: [DONE]\n\n"))
+}
+
+func TestAccountIsZeroCostBilling(t *testing.T) {
+	require.True(t, (&Account{Platform: PlatformCustom, Type: AccountTypeOllama}).IsZeroCostBilling())
+	require.False(t, (&Account{Platform: PlatformCustom, Type: AccountTypeAPIKey}).IsZeroCostBilling())
+	require.False(t, (*Account)(nil).IsZeroCostBilling())
+}
+
+func TestAzureOpenAIURLs(t *testing.T) {
+	require.Equal(t, "https://res.openai.azure.com/openai/deployments/gpt-4o-prod/chat/completions?api-version=2024-10-21",
+		azureOpenAIChatCompletionsURL("https://res.openai.azure.com/openai/", "gpt-4o-prod", azureOpenAIDefaultAPIVersion))
+	require.Equal(t, "https://res.openai.azure.com/openai/responses?api-version=2025-04-01-preview",
+		azureOpenAIResponsesURL("https://res.openai.azure.com", azureOpenAIDefaultResponsesAPIVersion))
+}
+
+func TestForwardCustomChatCompletions_AzureDeployment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body: io.NopCloser(strings.NewReader(`{"model":"gpt-4o","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":1}}`))}
+	svc, upstream := newCustomProviderTestService(resp)
+	account := newCustomProviderTestAccount(map[string]any{
+		"base_url":      "https://res.openai.azure.com",
+		"api_key":       "azure-key",
+		"api_version":   "2024-06-01",
+		"model_mapping": map[string]any{"gpt-4o": "gpt-4o-prod"},
+	})
+	account.Type = AccountTypeAzure
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	result, err := svc.ForwardCustomChatCompletions(context.Background(), c, account, []byte(`{"model":"gpt-4o","messages":[]}`))
+	require.NoError(t, err)
+	require.Equal(t, "https://res.openai.azure.com/openai/deployments/gpt-4o-prod/chat/completions?api-version=2024-06-01", upstream.lastReq.URL.String())
+	require.Equal(t, "azure-key", upstream.lastReq.Header.Get("api-key"))
+	require.Empty(t, upstream.lastReq.Header.Get("Authorization"))
+	require.Equal(t, 5, result.Usage.InputTokens)
+}
+
+func TestForwardCustomResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stream := "event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"delta\":\"hi\"}\n\n" +
+		"event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"model\":\"gpt-4o-prod\",\"usage\":{\"input_tokens\":20,\"output_tokens\":4,\"input_tokens_details\":{\"cached_tokens\":8}}}}\n\n"
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(stream))}
+	svc, upstream := newCustomProviderTestService(resp)
+	account := newCustomProviderTestAccount(map[string]any{
+		"base_url":      "https://res.openai.azure.com",
+		"api_key":       "azure-key",
+		"model_mapping": map[string]any{"gpt-4o": "gpt-4o-prod"},
+	})
+	account.Type = AccountTypeAzure
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+
+	result, err := svc.ForwardCustomResponses(context.Background(), c, account, []byte(`{"model":"gpt-4o","stream":true,"input":"hi"}`))
+	require.NoError(t, err)
+	require.Equal(t, "gpt-4o-prod", gjson.GetBytes(upstream.lastBody, "model").String())
+	require.Equal(t, ClaudeUsage{InputTokens: 12, OutputTokens: 4, CacheReadInputTokens: 8}, result.Usage)
+	require.Contains(t, w.Body.String(), "event: response.completed\n")
+	require.Contains(t, w.Body.String(), `"model":"gpt-4o"`)
+
+	// 非 Azure 账号不提供 Responses API
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+	_, err = svc.ForwardCustomResponses(context.Background(), c, newCustomProviderTestAccount(nil), []byte(`{"model":"m"}`))
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -15,6 +14,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
 	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
 	"github.com/gin-gonic/gin"
 	"github.com/tidwall/gjson"
@@ -126,7 +126,7 @@ func (s *GatewayService) ForwardAsChatCompletions(
 	}
 
 	// 11. Send request
-	resp, err := s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+	resp, err := s.httpUpstream.DoWithTLS(withAccountTransport(upstreamReq, account), proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
 	if err != nil {
 		if resp != nil && resp.Body != nil {
 			_ = resp.Body.Close()
@@ -185,8 +185,12 @@ func (s *GatewayService) ForwardAsChatCompletions(
 	// Read Anthropic SSE → convert to Responses events → convert to CC format
 	var result *ForwardResult
 	var handleErr error
+	stopIdleTimeout := guardStreamIdleTimeout(ctx, resp, s.cfg, s.rateLimitService, account, originalModel)
+	defer stopIdleTimeout()
 	if clientStream {
-		result, handleErr = s.handleCCStreamingFromAnthropic(resp, c, originalModel, mappedModel, reasoningEffort, startTime, includeUsage)
+		stopKeepalive := startStreamKeepalive(c, s.cfg)
+		defer stopKeepalive()
+		result, handleErr = s.handleCCStreamingFromAnthropic(resp, c, originalModel, mappedModel, reasoningEffort, startTime, includeUsage, estimateChatPromptTokens(body, includeUsage))
 	} else {
 		result, handleErr = s.handleCCBufferedFromAnthropic(resp, c, originalModel, mappedModel, reasoningEffort, startTime)
 	}
@@ -224,30 +228,24 @@ func (s *GatewayService) handleCCBufferedFromAnthropic(
 ) (*ForwardResult, error) {
 	requestID := resp.Header.Get("x-request-id")
 
-	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	reader := sse.NewReader(resp.Body, maxLineSize)
 
 	var finalResp *apicompat.AnthropicResponse
 	var usage ClaudeUsage
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(line, "event: ") {
-			continue
-		}
-
-		if !scanner.Scan() {
+	for {
+		sseEvent, err := reader.ReadEvent()
+		if err != nil {
 			break
 		}
-		dataLine := scanner.Text()
-		if !strings.HasPrefix(dataLine, "data: ") {
+		if !sseEvent.HasData {
 			continue
 		}
-		payload := dataLine[6:]
+		payload := sseEvent.Data
 
 		var event apicompat.AnthropicStreamEvent
 		if err := json.Unmarshal([]byte(payload), &event); err != nil {
@@ -287,7 +285,13 @@ func (s *GatewayService) handleCCBufferedFromAnthropic(
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
+	if err := reader.Err(); err != nil {
+		if errors.Is(err, ErrStreamIdleTimeout) {
+			return nil, handleBufferedStreamIdleTimeout(c, s.cfg, writeGatewayCCError)
+		}
+		if errors.Is(err, sse.ErrLineTooLong) {
+			return nil, handleBufferedLineTooLong(c, maxLineSize, requestID, writeGatewayCCError)
+		}
 		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 			logger.L().Warn("forward_as_cc buffered: read error",
 				zap.Error(err),
@@ -316,7 +320,7 @@ func (s *GatewayService) handleCCBufferedFromAnthropic(
 	ccResp := apicompat.ResponsesToChatCompletions(responsesResp, originalModel)
 
 	if s.responseHeaderFilter != nil {
-		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 	}
 	// Marshal then bytes-replace so tool name mapping is reversed at byte level
 	// (parity with Parrot non-stream flow that marshals → restore → emit).
@@ -348,11 +352,12 @@ func (s *GatewayService) handleCCStreamingFromAnthropic(
 	reasoningEffort *string,
 	startTime time.Time,
 	includeUsage bool,
+	estimatedPromptTokens int,
 ) (*ForwardResult, error) {
 	requestID := resp.Header.Get("x-request-id")
 
 	if s.responseHeaderFilter != nil {
-		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 	}
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
@@ -366,17 +371,17 @@ func (s *GatewayService) handleCCStreamingFromAnthropic(
 	ccState := apicompat.NewResponsesEventToChatState()
 	ccState.Model = originalModel
 	ccState.IncludeUsage = includeUsage
+	ccState.ToolArgsCoalesce = resolveToolArgsCoalesce(s.cfg)
 
 	var usage ClaudeUsage
 	var firstTokenMs *int
 	firstChunk := true
 
-	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	reader := sse.NewReader(resp.Body, maxLineSize)
 
 	resultWithUsage := func() *ForwardResult {
 		return &ForwardResult{
@@ -435,20 +440,15 @@ func (s *GatewayService) handleCCStreamingFromAnthropic(
 		return false
 	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(line, "event: ") {
-			continue
-		}
-
-		if !scanner.Scan() {
+	for {
+		sseEvent, err := reader.ReadEvent()
+		if err != nil {
 			break
 		}
-		dataLine := scanner.Text()
-		if !strings.HasPrefix(dataLine, "data: ") {
+		if !sseEvent.HasData {
 			continue
 		}
-		payload := dataLine[6:]
+		payload := sseEvent.Data
 
 		var event apicompat.AnthropicStreamEvent
 		if err := json.Unmarshal([]byte(payload), &event); err != nil {
@@ -460,7 +460,13 @@ func (s *GatewayService) handleCCStreamingFromAnthropic(
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
+	if err := reader.Err(); err != nil {
+		if errors.Is(err, ErrStreamIdleTimeout) {
+			return resultWithUsage(), handleStreamingIdleTimeout(c, s.cfg, streamErrorEventChatCompletions)
+		}
+		if errors.Is(err, sse.ErrLineTooLong) {
+			return resultWithUsage(), handleStreamingLineTooLong(c, streamErrorEventChatCompletions, maxLineSize, requestID)
+		}
 		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 			logger.L().Warn("forward_as_cc stream: read error",
 				zap.Error(err),
@@ -478,6 +484,8 @@ func (s *GatewayService) handleCCStreamingFromAnthropic(
 		}
 	}
 	finalCCChunks := apicompat.FinalizeResponsesChatStream(ccState)
+	finalCCChunks = append(finalCCChunks, apicompat.FinalizeChatStreamUsage(ccState, chatStreamUsageFallback(
+		usage.InputTokens, usage.OutputTokens, usage.CacheReadInputTokens, estimatedPromptTokens, ccState.OutputChars))...)
 	for _, chunk := range finalCCChunks {
 		writeChunk(chunk) //nolint:errcheck
 	}
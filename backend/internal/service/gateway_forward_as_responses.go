@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -15,6 +14,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
 	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
 	"github.com/gin-gonic/gin"
 	"github.com/tidwall/gjson"
@@ -123,7 +123,7 @@ func (s *GatewayService) ForwardAsResponses(
 	}
 
 	// 11. Send request
-	resp, err := s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+	resp, err := s.httpUpstream.DoWithTLS(withAccountTransport(upstreamReq, account), proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
 	if err != nil {
 		if resp != nil && resp.Body != nil {
 			_ = resp.Body.Close()
@@ -179,7 +179,11 @@ func (s *GatewayService) ForwardAsResponses(
 	// 13. Handle normal response (convert Anthropic → Responses)
 	var result *ForwardResult
 	var handleErr error
+	stopIdleTimeout := guardStreamIdleTimeout(ctx, resp, s.cfg, s.rateLimitService, account, originalModel)
+	defer stopIdleTimeout()
 	if clientStream {
+		stopKeepalive := startStreamKeepalive(c, s.cfg)
+		defer stopKeepalive()
 		result, handleErr = s.handleResponsesStreamingResponse(resp, c, originalModel, mappedModel, reasoningEffort, startTime)
 	} else {
 		result, handleErr = s.handleResponsesBufferedStreamingResponse(resp, c, originalModel, mappedModel, reasoningEffort, startTime)
@@ -233,33 +237,26 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 ) (*ForwardResult, error) {
 	requestID := resp.Header.Get("x-request-id")
 
-	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	reader := sse.NewReader(resp.Body, maxLineSize)
 
 	// Accumulate the final Anthropic response from streaming events
 	var finalResp *apicompat.AnthropicResponse
 	var usage ClaudeUsage
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(line, "event: ") {
-			continue
-		}
-		eventType := strings.TrimPrefix(line, "event: ")
-
-		// Read the data line
-		if !scanner.Scan() {
+	for {
+		sseEvent, err := reader.ReadEvent()
+		if err != nil {
 			break
 		}
-		dataLine := scanner.Text()
-		if !strings.HasPrefix(dataLine, "data: ") {
+		if !sseEvent.HasData {
 			continue
 		}
-		payload := dataLine[6:]
+		eventType := sseEvent.Event
+		payload := sseEvent.Data
 
 		var event apicompat.AnthropicStreamEvent
 		if err := json.Unmarshal([]byte(payload), &event); err != nil {
@@ -306,7 +303,13 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
+	if err := reader.Err(); err != nil {
+		if errors.Is(err, ErrStreamIdleTimeout) {
+			return nil, handleBufferedStreamIdleTimeout(c, s.cfg, writeResponsesError)
+		}
+		if errors.Is(err, sse.ErrLineTooLong) {
+			return nil, handleBufferedLineTooLong(c, maxLineSize, requestID, writeResponsesError)
+		}
 		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 			logger.L().Warn("forward_as_responses buffered: read error",
 				zap.Error(err),
@@ -335,7 +338,7 @@ func (s *GatewayService) handleResponsesBufferedStreamingResponse(
 	responsesResp.Model = originalModel // Use original model name
 
 	if s.responseHeaderFilter != nil {
-		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 	}
 	if respBytes, err := json.Marshal(responsesResp); err == nil {
 		respBytes = reverseToolNamesIfPresent(c, respBytes)
@@ -368,7 +371,7 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 	requestID := resp.Header.Get("x-request-id")
 
 	if s.responseHeaderFilter != nil {
-		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 	}
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
@@ -382,12 +385,11 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 	var firstTokenMs *int
 	firstChunk := true
 
-	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	reader := sse.NewReader(resp.Body, maxLineSize)
 
 	resultWithUsage := func() *ForwardResult {
 		return &ForwardResult{
@@ -460,22 +462,16 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 	}
 
 	// Read Anthropic SSE events
-	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(line, "event: ") {
-			continue
-		}
-		eventType := strings.TrimPrefix(line, "event: ")
-
-		// Read data line
-		if !scanner.Scan() {
+	for {
+		sseEvent, err := reader.ReadEvent()
+		if err != nil {
 			break
 		}
-		dataLine := scanner.Text()
-		if !strings.HasPrefix(dataLine, "data: ") {
+		if !sseEvent.HasData {
 			continue
 		}
-		payload := dataLine[6:]
+		eventType := sseEvent.Event
+		payload := sseEvent.Data
 
 		var event apicompat.AnthropicStreamEvent
 		if err := json.Unmarshal([]byte(payload), &event); err != nil {
@@ -492,7 +488,13 @@ func (s *GatewayService) handleResponsesStreamingResponse(
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
+	if err := reader.Err(); err != nil {
+		if errors.Is(err, ErrStreamIdleTimeout) {
+			return resultWithUsage(), handleStreamingIdleTimeout(c, s.cfg, streamErrorEventResponses)
+		}
+		if errors.Is(err, sse.ErrLineTooLong) {
+			return resultWithUsage(), handleStreamingLineTooLong(c, streamErrorEventResponses, maxLineSize, requestID)
+		}
 		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 			logger.L().Warn("forward_as_responses stream: read error",
 				zap.Error(err),
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+const (
+	gatewayIdempotencyStateProcessing = "processing"
+	gatewayIdempotencyStateDone       = "done"
+)
+
+// gatewayIdempotencyReplayHeaders 重放时恢复的响应头子集（其余响应头由网关中间件重新生成）
+var gatewayIdempotencyReplayHeaders = []string{
+	"Content-Type",
+	"Request-Id",
+	"X-Request-Id",
+}
+
+// GatewayIdempotencyCache 网关幂等记录存储（Redis）。
+// ClaimIdempotency 仅在键不存在时写入（SET NX）；GetIdempotency 在键不存在时返回 (nil, nil)。
+type GatewayIdempotencyCache interface {
+	ClaimIdempotency(ctx context.Context, key string, data []byte, ttl time.Duration) (bool, error)
+	GetIdempotency(ctx context.Context, key string) ([]byte, error)
+	SetIdempotency(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	DeleteIdempotency(ctx context.Context, key string) error
+}
+
+// GatewayIdempotencyService 为非流式网关请求提供 Idempotency-Key 语义：
+// 首个请求占位并转发上游，成功响应缓存 TTL；相同 Key、相同请求的重试直接重放缓存结果，
+// 首个请求仍在处理时返回 409，Key 复用于不同请求体时返回 409。
+// 失败响应不缓存，重试会重新转发。
+type GatewayIdempotencyService struct {
+	cache GatewayIdempotencyCache
+	cfg   config.GatewayIdempotencyConfig
+}
+
+func NewGatewayIdempotencyService(cache GatewayIdempotencyCache, cfg *config.Config) *GatewayIdempotencyService {
+	svc := &GatewayIdempotencyService{cache: cache}
+	if cfg != nil {
+		svc.cfg = cfg.Gateway.Idempotency
+	}
+	return svc
+}
+
+// Enabled 是否处理 Idempotency-Key 请求头
+func (s *GatewayIdempotencyService) Enabled() bool {
+	return s != nil && s.cache != nil && s.cfg.Enabled
+}
+
+// MaxResponseBytes 可缓存的最大响应体字节数
+func (s *GatewayIdempotencyService) MaxResponseBytes() int {
+	if s == nil {
+		return 0
+	}
+	return s.cfg.MaxResponseBytes
+}
+
+// GatewayIdempotencyClaim 首个请求持有的占位，由 Complete 释放或转为缓存结果
+type GatewayIdempotencyClaim struct {
+	key         string
+	fingerprint string
+}
+
+// GatewayIdempotentResponse 缓存的最终响应
+type GatewayIdempotentResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+type gatewayIdempotencyEntry struct {
+	State       string              `json:"state"`
+	Fingerprint string              `json:"fingerprint"`
+	LockedUntil int64               `json:"locked_until,omitempty"`
+	StatusCode  int                 `json:"status_code,omitempty"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+}
+
+// Begin 按 API Key 隔离占用幂等键。返回 claim 表示本请求需转发上游并在结束后调用 Complete；
+// 返回 replay 表示应直接重放缓存结果。
+func (s *GatewayIdempotencyService) Begin(ctx context.Context, apiKeyID int64, idempotencyKey, method, path string, body []byte) (*GatewayIdempotencyClaim, *GatewayIdempotentResponse, error) {
+	key, err := NormalizeIdempotencyKey(idempotencyKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == "" {
+		return nil, nil, ErrIdempotencyKeyRequired
+	}
+	claim := &GatewayIdempotencyClaim{
+		key:         strconv.FormatInt(apiKeyID, 10) + ":" + HashIdempotencyKey(key),
+		fingerprint: gatewayIdempotencyFingerprint(method, path, body),
+	}
+
+	lease := time.Duration(s.cfg.ProcessingTimeoutSeconds) * time.Second
+	lockedUntil := time.Now().Add(lease)
+	placeholder, err := json.Marshal(gatewayIdempotencyEntry{
+		State:       gatewayIdempotencyStateProcessing,
+		Fingerprint: claim.fingerprint,
+		LockedUntil: lockedUntil.Unix(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 占位与读取之间记录可能恰好过期，重试一次
+	for attempt := 0; attempt < 2; attempt++ {
+		claimed, err := s.cache.ClaimIdempotency(ctx, claim.key, placeholder, lease)
+		if err != nil {
+			return nil, nil, ErrIdempotencyStoreUnavail.WithCause(err)
+		}
+		if claimed {
+			return claim, nil, nil
+		}
+		data, err := s.cache.GetIdempotency(ctx, claim.key)
+		if err != nil {
+			return nil, nil, ErrIdempotencyStoreUnavail.WithCause(err)
+		}
+		if data == nil {
+			continue
+		}
+		var entry gatewayIdempotencyEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, nil, ErrIdempotencyStoreUnavail.WithCause(err)
+		}
+		if entry.Fingerprint != claim.fingerprint {
+			return nil, nil, ErrIdempotencyKeyConflict
+		}
+		if entry.State == gatewayIdempotencyStateDone {
+			return nil, &GatewayIdempotentResponse{
+				StatusCode: entry.StatusCode,
+				Header:     http.Header(entry.Header),
+				Body:       entry.Body,
+			}, nil
+		}
+		retryAfter := entry.LockedUntil - time.Now().Unix()
+		if retryAfter <= 0 {
+			retryAfter = 1
+		}
+		return nil, nil, ErrIdempotencyInProgress.WithMetadata(map[string]string{"retry_after": strconv.FormatInt(retryAfter, 10)})
+	}
+	return nil, nil, ErrIdempotencyInProgress
+}
+
+// Complete 首个请求结束：成功且完整缓存的响应写入缓存，否则释放占位以允许重试重新转发。
+func (s *GatewayIdempotencyService) Complete(ctx context.Context, claim *GatewayIdempotencyClaim, statusCode int, header http.Header, body []byte, complete bool) error {
+	if claim == nil {
+		return nil
+	}
+	if !complete || statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		return s.cache.DeleteIdempotency(ctx, claim.key)
+	}
+	entry := gatewayIdempotencyEntry{
+		State:       gatewayIdempotencyStateDone,
+		Fingerprint: claim.fingerprint,
+		StatusCode:  statusCode,
+		Header:      make(map[string][]string),
+		Body:        body,
+	}
+	for _, name := range gatewayIdempotencyReplayHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			entry.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.cache.SetIdempotency(ctx, claim.key, data, time.Duration(s.cfg.TTLSeconds)*time.Second)
+}
+
+// gatewayIdempotencyFingerprint 以方法、路径与原始请求体标识请求；同一 Key 用于不同请求视为冲突
+func gatewayIdempotencyFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(path))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
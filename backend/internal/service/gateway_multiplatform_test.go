@@ -92,7 +92,7 @@ func (m *mockAccountRepoForPlatform) Delete(ctx context.Context, id int64) error
 func (m *mockAccountRepoForPlatform) List(ctx context.Context, params pagination.PaginationParams) ([]Account, *pagination.PaginationResult, error) {
 	return nil, nil, nil
 }
-func (m *mockAccountRepoForPlatform) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode string) ([]Account, *pagination.PaginationResult, error) {
+func (m *mockAccountRepoForPlatform) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, accountType, status, search string, groupID int64, privacyMode, tag string) ([]Account, *pagination.PaginationResult, error) {
 	return nil, nil, nil
 }
 func (m *mockAccountRepoForPlatform) ListByGroup(ctx context.Context, groupID int64) ([]Account, error) {
@@ -104,6 +104,25 @@ func (m *mockAccountRepoForPlatform) ListActive(ctx context.Context) ([]Account,
 func (m *mockAccountRepoForPlatform) ListByPlatform(ctx context.Context, platform string) ([]Account, error) {
 	return nil, nil
 }
+func (m *mockAccountRepoForPlatform) ListByOwnerUserID(ctx context.Context, userID int64) ([]Account, error) {
+	return nil, nil
+}
+func (m *mockAccountRepoForPlatform) SetTags(ctx context.Context, accountID int64, tags []string) error {
+	return nil
+}
+
+func (m *mockAccountRepoForPlatform) AddTags(ctx context.Context, accountIDs []int64, tags []string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockAccountRepoForPlatform) RemoveTags(ctx context.Context, accountIDs []int64, tags []string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockAccountRepoForPlatform) ListTags(ctx context.Context) ([]AccountTagCount, error) {
+	return nil, nil
+}
+
 func (m *mockAccountRepoForPlatform) UpdateLastUsed(ctx context.Context, id int64) error {
 	return nil
 }
@@ -266,7 +285,7 @@ func (m *mockGroupRepoForGateway) DeleteCascade(ctx context.Context, id int64) (
 func (m *mockGroupRepoForGateway) List(ctx context.Context, params pagination.PaginationParams) ([]Group, *pagination.PaginationResult, error) {
 	return nil, nil, nil
 }
-func (m *mockGroupRepoForGateway) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, status, search string, isExclusive *bool) ([]Group, *pagination.PaginationResult, error) {
+func (m *mockGroupRepoForGateway) ListWithFilters(ctx context.Context, params pagination.PaginationParams, platform, status, search string, isExclusive *bool, organizationID *int64) ([]Group, *pagination.PaginationResult, error) {
 	return nil, nil, nil
 }
 func (m *mockGroupRepoForGateway) ListActive(ctx context.Context) ([]Group, error) {
@@ -1989,6 +2008,21 @@ func (m *mockConcurrencyCache) CleanupExpiredAccountSlots(ctx context.Context, a
 func (m *mockConcurrencyCache) CleanupStaleProcessSlots(ctx context.Context, activeRequestPrefix string) error {
 	return nil
 }
+func (m *mockConcurrencyCache) ListSlotOccupancy(context.Context) ([]SlotOccupancy, error) {
+	return nil, nil
+}
+
+func (m *mockConcurrencyCache) AddPriorityWaiter(ctx context.Context, scope string, id int64, rank int, requestID string) error {
+	return nil
+}
+
+func (m *mockConcurrencyCache) RemovePriorityWaiter(ctx context.Context, scope string, id int64, rank int, requestID string) error {
+	return nil
+}
+
+func (m *mockConcurrencyCache) HasHigherPriorityWaiters(ctx context.Context, scope string, id int64, rank int) (bool, error) {
+	return false, nil
+}
 
 func (m *mockConcurrencyCache) GetUsersLoadBatch(ctx context.Context, users []UserWithConcurrency) (map[int64]*UserLoadInfo, error) {
 	result := make(map[int64]*UserLoadInfo, len(users))
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap"
+)
+
+// ollamaAPIURL 由 base_url 构造 Ollama 原生 API 地址；兼容填写了 OpenAI 兼容前缀 /v1 的 base_url
+func ollamaAPIURL(baseURL, path string) string {
+	base := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	base = strings.TrimSuffix(base, "/v1")
+	return base + path
+}
+
+// forwardOllamaChatCompletions 将 Chat Completions 请求转换为 Ollama /api/chat 请求，
+// 并把响应（非流式 JSON 或流式 NDJSON）转换回 Chat Completions 格式。
+func (s *GatewayService) forwardOllamaChatCompletions(
+	ctx context.Context,
+	c *gin.Context,
+	account *Account,
+	body []byte,
+	startTime time.Time,
+) (*ForwardResult, error) {
+	var ccReq apicompat.ChatCompletionsRequest
+	if err := json.Unmarshal(body, &ccReq); err != nil {
+		return nil, fmt.Errorf("parse chat completions request: %w", err)
+	}
+	originalModel := ccReq.Model
+	clientStream := ccReq.Stream
+	includeUsage := ccReq.StreamOptions != nil && ccReq.StreamOptions.IncludeUsage
+
+	ollamaReq, err := apicompat.ChatCompletionsToOllama(&ccReq)
+	if err != nil {
+		writeGatewayCCError(c, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return nil, fmt.Errorf("convert chat completions to ollama: %w", err)
+	}
+	mappedModel := account.GetMappedModel(originalModel)
+	ollamaReq.Model = mappedModel
+	// response_format 不在 ChatCompletionsRequest 中，JSON 模式单独映射为 Ollama 的 format
+	if gjson.GetBytes(body, "response_format.type").String() == "json_object" {
+		ollamaReq.Format = json.RawMessage(`"json"`)
+	} else if schema := gjson.GetBytes(body, "response_format.json_schema.schema"); schema.IsObject() {
+		ollamaReq.Format = json.RawMessage(schema.Raw)
+	}
+
+	ollamaBody, err := json.Marshal(ollamaReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal ollama request: %w", err)
+	}
+
+	baseURL, err := s.resolveCustomProviderBaseURL(c, account, writeGatewayCCError)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.L().Debug("gateway ollama: forwarding",
+		zap.Int64("account_id", account.ID),
+		zap.String("original_model", originalModel),
+		zap.String("mapped_model", mappedModel),
+		zap.Bool("client_stream", clientStream),
+	)
+
+	resp, err := s.doCustomProviderRequest(ctx, c, account, ollamaAPIURL(baseURL, "/api/chat"), ollamaBody, clientStream, writeGatewayCCError)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if clientStream {
+		stopIdleTimeout := guardStreamIdleTimeout(ctx, resp, s.cfg, s.rateLimitService, account, originalModel)
+		defer stopIdleTimeout()
+		return s.handleOllamaStreaming(resp, c, originalModel, mappedModel, includeUsage, startTime)
+	}
+	return s.handleOllamaBuffered(resp, c, originalModel, mappedModel, startTime)
+}
+
+func (s *GatewayService) handleOllamaBuffered(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	startTime time.Time,
+) (*ForwardResult, error) {
+	respBody, err := readUpstreamResponseBodyLimited(resp.Body, resolveUpstreamResponseReadLimit(s.cfg))
+	if err != nil {
+		writeGatewayCCError(c, http.StatusBadGateway, "server_error", "Failed to read upstream response")
+		return nil, fmt.Errorf("read upstream response: %w", err)
+	}
+	var ollamaResp apicompat.OllamaChatResponse
+	if err := json.Unmarshal(respBody, &ollamaResp); err != nil {
+		writeGatewayCCError(c, http.StatusBadGateway, "server_error", "Invalid upstream response")
+		return nil, fmt.Errorf("parse ollama response: %w", err)
+	}
+
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
+	}
+	c.JSON(http.StatusOK, apicompat.OllamaToChatCompletions(&ollamaResp, originalModel))
+
+	return &ForwardResult{
+		Usage:         ClaudeUsage{InputTokens: ollamaResp.PromptEvalCount, OutputTokens: ollamaResp.EvalCount},
+		Model:         originalModel,
+		UpstreamModel: mappedModel,
+		Stream:        false,
+		Duration:      time.Since(startTime),
+	}, nil
+}
+
+// handleOllamaStreaming 逐行读取 Ollama NDJSON 流并转换为 Chat Completions SSE。
+// 客户端断开后继续读取上游直至结束，以便拿到最终用量。
+func (s *GatewayService) handleOllamaStreaming(
+	resp *http.Response,
+	c *gin.Context,
+	originalModel string,
+	mappedModel string,
+	includeUsage bool,
+	startTime time.Time,
+) (*ForwardResult, error) {
+	if s.responseHeaderFilter != nil {
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
+	}
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+
+	stopKeepalive := startStreamKeepalive(c, s.cfg)
+	defer stopKeepalive()
+
+	maxLineSize := defaultMaxLineSize
+	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
+		maxLineSize = s.cfg.Gateway.MaxLineSize
+	}
+	reader := sse.NewReader(resp.Body, maxLineSize)
+
+	state := apicompat.NewOllamaChatStreamState(originalModel, includeUsage)
+	var usage ClaudeUsage
+	var firstTokenMs *int
+	clientDisconnected := false
+
+	resultWithUsage := func() *ForwardResult {
+		return &ForwardResult{
+			Usage:            usage,
+			Model:            originalModel,
+			UpstreamModel:    mappedModel,
+			Stream:           true,
+			Duration:         time.Since(startTime),
+			FirstTokenMs:     firstTokenMs,
+			ClientDisconnect: clientDisconnected,
+		}
+	}
+
+	for reader.Scan() {
+		line := []byte(reader.Text())
+		if len(line) == 0 {
+			continue
+		}
+		if upstreamErr := gjson.GetBytes(line, "error"); upstreamErr.Exists() {
+			msg := sanitizeUpstreamErrorMessage(upstreamErr.String())
+			if !c.Writer.Written() {
+				return nil, &UpstreamFailoverError{StatusCode: http.StatusBadGateway, ResponseBody: append([]byte(nil), line...)}
+			}
+			if !clientDisconnected {
+				_, _ = fmt.Fprint(c.Writer, streamErrorEvent(streamErrorEventChatCompletions, "server_error", http.StatusBadGateway, msg, gatewayRequestID(c)))
+				c.Writer.Flush()
+			}
+			return resultWithUsage(), fmt.Errorf("ollama stream error: %s", msg)
+		}
+
+		var chunk apicompat.OllamaChatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			continue
+		}
+		if firstTokenMs == nil {
+			ms := int(time.Since(startTime).Milliseconds())
+			firstTokenMs = &ms
+		}
+		if chunk.Done {
+			usage = ClaudeUsage{InputTokens: chunk.PromptEvalCount, OutputTokens: chunk.EvalCount}
+		}
+		if clientDisconnected {
+			continue
+		}
+		for _, cc := range apicompat.OllamaChunkToChatChunks(&chunk, state) {
+			sse, err := apicompat.ChatChunkToSSE(cc)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprint(c.Writer, sse); err != nil {
+				clientDisconnected = true
+				break
+			}
+		}
+		if !clientDisconnected {
+			c.Writer.Flush()
+		}
+	}
+
+	if err := reader.Err(); err != nil {
+		if errors.Is(err, ErrStreamIdleTimeout) {
+			return resultWithUsage(), handleStreamingIdleTimeout(c, s.cfg, streamErrorEventChatCompletions)
+		}
+		if errors.Is(err, sse.ErrLineTooLong) {
+			return resultWithUsage(), handleStreamingLineTooLong(c, streamErrorEventChatCompletions, maxLineSize, "")
+		}
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			logger.L().Warn("gateway ollama stream: read error", zap.Error(err))
+		}
+	}
+
+	if !clientDisconnected {
+		_, _ = fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+		c.Writer.Flush()
+	}
+	return resultWithUsage(), nil
+}
+
+// ListOllamaModels 通过 /api/tags 获取 Ollama 账号本地已拉取的模型名
+func (s *GatewayService) ListOllamaModels(ctx context.Context, account *Account) ([]string, error) {
+	if account == nil || !account.IsOllama() {
+		return nil, errors.New("not an ollama account")
+	}
+	baseURL, err := s.validateUpstreamBaseURL(account.GetCredential("base_url"))
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ollamaAPIURL(baseURL, "/api/tags"), nil)
+	if err != nil {
+		return nil, err
+	}
+	if name, value, ok := customProviderAuthHeader(account); ok {
+		req.Header.Set(name, value)
+	}
+	proxyURL := ""
+	if account.ProxyID != nil && account.Proxy != nil {
+		proxyURL = account.Proxy.URL()
+	}
+	resp, err := s.httpUpstream.Do(req, proxyURL, account.ID, account.Concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("list ollama models: %s", sanitizeUpstreamErrorMessage(err.Error()))
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list ollama models: upstream status %d", resp.StatusCode)
+	}
+
+	var tags apicompat.OllamaTagsResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 8<<20)).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("parse ollama tags: %w", err)
+	}
+	models := make([]string, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		name := m.Name
+		if name == "" {
+			name = m.Model
+		}
+		if name != "" {
+			models = append(models, name)
+		}
+	}
+	sort.Strings(models)
+	return models, nil
+}
@@ -0,0 +1,36 @@
+//go:build unit
+
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleOllamaStreaming_LineBeyondScannerDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	long := strings.Repeat("a", 128*1024)
+	body := `{"model":"llama3","message":{"role":"assistant","content":"` + long + `"},"done":false}` + "\n" +
+		`{"model":"llama3","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":5,"eval_count":7}` + "\n"
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+
+	svc := &GatewayService{cfg: &config.Config{}}
+	result, err := svc.handleOllamaStreaming(resp, c, "llama3", "llama3", false, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Usage.InputTokens)
+	require.Equal(t, 7, result.Usage.OutputTokens)
+	require.Contains(t, rec.Body.String(), long)
+	require.Contains(t, rec.Body.String(), "data: [DONE]")
+}
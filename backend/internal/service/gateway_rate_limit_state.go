@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// 网关自身返回 429 时的限流状态（RPM、API Key 额度窗口、订阅额度窗口等）。
+// 状态通过 ApplicationError.Metadata 携带，handler / middleware 据此写 Retry-After 与 X-RateLimit-* 响应头，
+// 让 SDK 按真实的窗口重置时间退避。retry_after 键与幂等冲突保持一致（见 RetryAfterSecondsFromError）。
+const (
+	rateLimitMetaLimit     = "ratelimit_limit"
+	rateLimitMetaRemaining = "ratelimit_remaining"
+	rateLimitMetaReset     = "ratelimit_reset"
+)
+
+// RateLimitState 限流器当前状态
+type RateLimitState struct {
+	// Limit 窗口内的限额（请求数、并发数或美元额度）
+	Limit float64
+	// Remaining 窗口内的剩余额度
+	Remaining float64
+	// ResetSeconds 距窗口重置的秒数（至少为 1）
+	ResetSeconds int
+}
+
+// rateLimitResetSeconds 将距重置的时长向上取整为秒，至少为 1
+func rateLimitResetSeconds(resetAfter time.Duration) int {
+	seconds := int(math.Ceil(resetAfter.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// withRateLimitState 在 429 错误上附加限流状态
+func withRateLimitState(base *infraerrors.ApplicationError, limit, remaining float64, resetAfter time.Duration) error {
+	if remaining < 0 {
+		remaining = 0
+	}
+	reset := strconv.Itoa(rateLimitResetSeconds(resetAfter))
+	return base.WithMetadata(map[string]string{
+		"retry_after":          reset,
+		rateLimitMetaLimit:     strconv.FormatFloat(limit, 'f', -1, 64),
+		rateLimitMetaRemaining: strconv.FormatFloat(remaining, 'f', -1, 64),
+		rateLimitMetaReset:     reset,
+	})
+}
+
+// RateLimitStateFromError 从错误中读取限流状态；未携带时返回 nil
+func RateLimitStateFromError(err error) *RateLimitState {
+	appErr := new(infraerrors.ApplicationError)
+	if !errors.As(err, &appErr) || appErr == nil || appErr.Metadata == nil {
+		return nil
+	}
+	limit, limitErr := strconv.ParseFloat(strings.TrimSpace(appErr.Metadata[rateLimitMetaLimit]), 64)
+	remaining, remainingErr := strconv.ParseFloat(strings.TrimSpace(appErr.Metadata[rateLimitMetaRemaining]), 64)
+	reset, resetErr := strconv.Atoi(strings.TrimSpace(appErr.Metadata[rateLimitMetaReset]))
+	if limitErr != nil || remainingErr != nil || resetErr != nil || reset <= 0 {
+		return nil
+	}
+	return &RateLimitState{Limit: limit, Remaining: remaining, ResetSeconds: reset}
+}
+
+// untilNextMinute 返回距下一个整分钟的时长（RPM 计数按整分钟分桶）
+func untilNextMinute(now time.Time) time.Duration {
+	return now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+}
+
+// untilWindowReset 返回固定窗口的剩余时长；窗口未激活时按完整窗口计算
+func untilWindowReset(windowStart *time.Time, window time.Duration, now time.Time) time.Duration {
+	if windowStart == nil {
+		return window
+	}
+	return windowStart.Add(window).Sub(now)
+}
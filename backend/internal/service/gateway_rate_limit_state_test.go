@@ -0,0 +1,70 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRPM_AttachesRateLimitState(t *testing.T) {
+	cache := &userRPMCacheStub{userCounts: []int{1, 2, 3}}
+	svc := newBillingServiceForRPM(t, cache, nil)
+	user := &User{ID: 1, RPMLimit: 2}
+
+	require.NoError(t, svc.checkRPM(context.Background(), user, nil))
+	require.NoError(t, svc.checkRPM(context.Background(), user, nil))
+	err := svc.checkRPM(context.Background(), user, nil)
+	require.ErrorIs(t, err, ErrUserRPMExceeded)
+
+	state := RateLimitStateFromError(err)
+	require.NotNil(t, state)
+	require.Equal(t, float64(2), state.Limit)
+	require.Zero(t, state.Remaining)
+	require.GreaterOrEqual(t, state.ResetSeconds, 1)
+	require.LessOrEqual(t, state.ResetSeconds, 60)
+	require.Equal(t, state.ResetSeconds, RetryAfterSecondsFromError(err))
+}
+
+func TestEvaluateRateLimits_ResetFollowsWindowStart(t *testing.T) {
+	svc := newBillingServiceForRPM(t, nil, nil)
+	apiKey := &APIKey{ID: 1, RateLimit1d: 10}
+	windowStart := time.Now().Add(-20 * time.Hour)
+
+	err := svc.evaluateRateLimits(context.Background(), apiKey, 0, 12.5, 0, nil, &windowStart, nil)
+	require.ErrorIs(t, err, ErrAPIKeyRateLimit1dExceeded)
+
+	state := RateLimitStateFromError(err)
+	require.NotNil(t, state)
+	require.Equal(t, float64(10), state.Limit)
+	require.Zero(t, state.Remaining, "remaining never goes negative")
+	require.InDelta(t, 4*3600, state.ResetSeconds, 5)
+}
+
+func TestValidateAndCheckLimits_AttachesSubscriptionWindowState(t *testing.T) {
+	svc := &SubscriptionService{}
+	limit := 5.0
+	windowStart := time.Now().Add(-1 * time.Hour)
+	sub := &UserSubscription{
+		Status:           SubscriptionStatusActive,
+		ExpiresAt:        time.Now().Add(24 * time.Hour),
+		DailyWindowStart: &windowStart,
+		DailyUsageUSD:    6,
+	}
+
+	_, err := svc.ValidateAndCheckLimits(sub, &Group{DailyLimitUSD: &limit})
+	require.ErrorIs(t, err, ErrDailyLimitExceeded)
+	state := RateLimitStateFromError(err)
+	require.NotNil(t, state)
+	require.Equal(t, 5.0, state.Limit)
+	require.InDelta(t, 23*3600, state.ResetSeconds, 5)
+}
+
+func TestRateLimitStateFromError_MissingMetadata(t *testing.T) {
+	require.Nil(t, RateLimitStateFromError(ErrUserRPMExceeded))
+	require.Nil(t, RateLimitStateFromError(nil))
+	require.Equal(t, 1, rateLimitResetSeconds(-time.Second))
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// RoutePreview 路由预览（dry-run）结果：执行鉴权、渠道映射、分组解析与账号调度，但不请求上游。
+type RoutePreview struct {
+	Platform  string `json:"platform"`
+	GroupID   *int64 `json:"group_id,omitempty"`
+	GroupName string `json:"group_name,omitempty"`
+
+	RequestedModel     string `json:"requested_model"`
+	ChannelID          int64  `json:"channel_id,omitempty"`
+	ChannelMappedModel string `json:"channel_mapped_model"`
+	UpstreamModel      string `json:"upstream_model"`
+	BillingModel       string `json:"billing_model"`
+
+	AccountID       int64  `json:"account_id"`
+	AccountName     string `json:"account_name"`
+	AccountPlatform string `json:"account_platform"`
+	AccountType     string `json:"account_type"`
+	// StickyAccountID 当前会话已绑定的账号；真实请求中若该账号仍可调度会优先命中它
+	StickyAccountID int64 `json:"sticky_account_id,omitempty"`
+
+	RateMultiplier        float64 `json:"rate_multiplier"`
+	EstimatedInputTokens  int     `json:"estimated_input_tokens"`
+	EstimatedOutputTokens int     `json:"estimated_output_tokens"`
+	EstimatedCost         float64 `json:"estimated_cost"`
+}
+
+// RoutePreviewInput 路由预览输入。
+type RoutePreviewInput struct {
+	APIKey *APIKey
+	Model  string
+	Body   []byte
+	// SessionKey 粘性会话 key（仅用于只读查询已有绑定）
+	SessionKey string
+}
+
+// PreviewRoute 复用真实请求的渠道映射与调度逻辑，返回本次请求将命中的账号与预估费用。
+//
+// 调度使用与真实请求相同的负载感知选择；为避免产生副作用，不传入会话 hash（不会新建或改写粘性绑定），
+// 选中时占用的并发槽位立即释放，已存在的粘性绑定通过 StickyAccountID 单独返回。
+func (s *GatewayService) PreviewRoute(ctx context.Context, input RoutePreviewInput) (*RoutePreview, error) {
+	apiKey := input.APIKey
+	mapping, restricted := s.ResolveChannelMappingAndRestrict(ctx, apiKey.GroupID, input.Model)
+	if restricted {
+		return nil, routePreviewRestrictedError(input.Model)
+	}
+
+	selection, err := s.SelectAccountWithLoadAwareness(ctx, apiKey.GroupID, "", input.Model, nil, "", apiKey.UserID)
+	if err != nil {
+		return nil, err
+	}
+	account := releaseRoutePreviewSelection(selection)
+	if account == nil {
+		return nil, ErrNoAvailableAccounts
+	}
+	preview := newRoutePreview(apiKey, input, mapping, account, resolveAccountUpstreamModel(account, mapping.MappedModel))
+	if input.SessionKey != "" {
+		preview.StickyAccountID, _ = s.GetCachedSessionAccountID(ctx, apiKey.GroupID, input.SessionKey)
+	}
+
+	multiplier := s.resolveAPIKeyRateMultiplier(ctx, apiKey)
+	preview.RateMultiplier = multiplier
+
+	result := &ForwardResult{
+		Model:         preview.BillingModel,
+		UpstreamModel: preview.UpstreamModel,
+		Usage: ClaudeUsage{
+			InputTokens:  preview.EstimatedInputTokens,
+			OutputTokens: preview.EstimatedOutputTokens,
+		},
+	}
+	if cost := s.calculateTokenCost(ctx, result, apiKey, preview.BillingModel, multiplier, &recordUsageOpts{}); cost != nil {
+		preview.EstimatedCost = cost.ActualCost
+	}
+	return preview, nil
+}
+
+// PreviewRoute OpenAI 分组的路由预览，语义同 GatewayService.PreviewRoute。
+func (s *OpenAIGatewayService) PreviewRoute(ctx context.Context, input RoutePreviewInput) (*RoutePreview, error) {
+	apiKey := input.APIKey
+	mapping, restricted := s.ResolveChannelMappingAndRestrict(ctx, apiKey.GroupID, input.Model)
+	if restricted {
+		return nil, routePreviewRestrictedError(input.Model)
+	}
+
+	selection, _, err := s.SelectAccountWithScheduler(ctx, apiKey.GroupID, "", "", input.Model, nil, OpenAIUpstreamTransportAny, false)
+	if err != nil {
+		return nil, err
+	}
+	account := releaseRoutePreviewSelection(selection)
+	if account == nil {
+		return nil, ErrNoAvailableAccounts
+	}
+	preview := newRoutePreview(apiKey, input, mapping, account, account.GetMappedModel(mapping.MappedModel))
+	if input.SessionKey != "" {
+		preview.StickyAccountID, _ = s.getStickySessionAccountID(ctx, apiKey.GroupID, input.SessionKey)
+	}
+
+	multiplier := s.resolveAPIKeyRateMultiplier(ctx, apiKey)
+	preview.RateMultiplier = multiplier
+
+	tokens := UsageTokens{
+		InputTokens:  preview.EstimatedInputTokens,
+		OutputTokens: preview.EstimatedOutputTokens,
+	}
+	if cost, err := s.calculateOpenAIRecordUsageCost(ctx, nil, apiKey, preview.BillingModel, multiplier, tokens, ""); err == nil && cost != nil {
+		preview.EstimatedCost = cost.ActualCost
+	}
+	return preview, nil
+}
+
+// routePreviewRestrictedError 渠道限制拒绝该模型时的错误，与调度阶段的限制错误一致
+func routePreviewRestrictedError(model string) error {
+	return fmt.Errorf("%w supporting model: %s (channel restriction)", ErrNoAvailableAccounts, model)
+}
+
+// releaseRoutePreviewSelection 释放调度时占用的并发槽位并返回选中的账号
+func releaseRoutePreviewSelection(selection *AccountSelectionResult) *Account {
+	if selection == nil {
+		return nil
+	}
+	if selection.Acquired && selection.ReleaseFunc != nil {
+		selection.ReleaseFunc()
+	}
+	return selection.Account
+}
+
+// resolveAPIKeyRateMultiplier 计费倍率：分组（含用户专属倍率）优先，否则使用全局默认倍率。
+func (s *GatewayService) resolveAPIKeyRateMultiplier(ctx context.Context, apiKey *APIKey) float64 {
+	multiplier := 1.0
+	if s.cfg != nil {
+		multiplier = s.cfg.Default.RateMultiplier
+	}
+	if apiKey.GroupID != nil && apiKey.Group != nil {
+		multiplier = s.getUserGroupRateMultiplier(ctx, apiKey.UserID, *apiKey.GroupID, apiKey.Group.RateMultiplier)
+	}
+	return multiplier
+}
+
+// resolveAPIKeyRateMultiplier 语义同 GatewayService.resolveAPIKeyRateMultiplier。
+func (s *OpenAIGatewayService) resolveAPIKeyRateMultiplier(ctx context.Context, apiKey *APIKey) float64 {
+	multiplier := 1.0
+	if s.cfg != nil {
+		multiplier = s.cfg.Default.RateMultiplier
+	}
+	if apiKey.GroupID != nil && apiKey.Group != nil {
+		resolver := s.userGroupRateResolver
+		if resolver == nil {
+			resolver = newUserGroupRateResolver(nil, nil, resolveUserGroupRateCacheTTL(s.cfg), nil, "service.openai_gateway")
+		}
+		multiplier = resolver.Resolve(ctx, apiKey.UserID, *apiKey.GroupID, apiKey.Group.RateMultiplier)
+	}
+	return multiplier
+}
+
+func newRoutePreview(apiKey *APIKey, input RoutePreviewInput, mapping ChannelMappingResult, account *Account, upstreamModel string) *RoutePreview {
+	channelMapped := input.Model
+	if mapping.Mapped {
+		channelMapped = mapping.MappedModel
+	}
+	billingModel := channelMapped
+	switch mapping.BillingModelSource {
+	case BillingModelSourceRequested:
+		billingModel = input.Model
+	case BillingModelSourceUpstream:
+		billingModel = upstreamModel
+	}
+
+	preview := &RoutePreview{
+		GroupID:               apiKey.GroupID,
+		RequestedModel:        input.Model,
+		ChannelID:             mapping.ChannelID,
+		ChannelMappedModel:    channelMapped,
+		UpstreamModel:         upstreamModel,
+		BillingModel:          billingModel,
+		AccountID:             account.ID,
+		AccountName:           account.Name,
+		AccountPlatform:       account.Platform,
+		AccountType:           account.Type,
+		EstimatedInputTokens:  estimateTokensForText(string(input.Body)),
+		EstimatedOutputTokens: estimateRoutePreviewOutputTokens(input.Body),
+	}
+	if apiKey.Group != nil {
+		preview.Platform = apiKey.Group.Platform
+		preview.GroupName = apiKey.Group.Name
+	}
+	return preview
+}
+
+// estimateRoutePreviewOutputTokens 以请求声明的最大输出 token 数作为输出上限估算。
+func estimateRoutePreviewOutputTokens(body []byte) int {
+	for _, path := range []string{"max_tokens", "max_output_tokens", "max_completion_tokens", "generationConfig.maxOutputTokens"} {
+		if v := gjson.GetBytes(body, path); v.Exists() && v.Int() > 0 {
+			return int(v.Int())
+		}
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRoutePreview_BillingModelSource(t *testing.T) {
+	groupID := int64(3)
+	apiKey := &APIKey{ID: 1, GroupID: &groupID, Group: &Group{ID: groupID, Name: "claude", Platform: PlatformAnthropic}}
+	account := &Account{ID: 9, Name: "acc", Platform: PlatformAnthropic, Type: AccountTypeAPIKey}
+	input := RoutePreviewInput{APIKey: apiKey, Model: "claude-sonnet", Body: []byte(`{"model":"claude-sonnet","max_tokens":512}`)}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"", "claude-sonnet-4"},
+		{BillingModelSourceChannelMapped, "claude-sonnet-4"},
+		{BillingModelSourceRequested, "claude-sonnet"},
+		{BillingModelSourceUpstream, "claude-sonnet-4-20250514"},
+	}
+	for _, tt := range tests {
+		mapping := ChannelMappingResult{MappedModel: "claude-sonnet-4", ChannelID: 5, Mapped: true, BillingModelSource: tt.source}
+		preview := newRoutePreview(apiKey, input, mapping, account, "claude-sonnet-4-20250514")
+		require.Equal(t, tt.want, preview.BillingModel, "source=%q", tt.source)
+		require.Equal(t, "claude-sonnet-4", preview.ChannelMappedModel)
+		require.Equal(t, int64(9), preview.AccountID)
+		require.Equal(t, PlatformAnthropic, preview.Platform)
+		require.Equal(t, "claude", preview.GroupName)
+		require.Equal(t, 512, preview.EstimatedOutputTokens)
+		require.Positive(t, preview.EstimatedInputTokens)
+	}
+
+	preview := newRoutePreview(apiKey, input, ChannelMappingResult{MappedModel: "claude-sonnet"}, account, "claude-sonnet")
+	require.Equal(t, "claude-sonnet", preview.ChannelMappedModel)
+	require.Equal(t, "claude-sonnet", preview.BillingModel)
+}
+
+func TestEstimateRoutePreviewOutputTokens(t *testing.T) {
+	require.Equal(t, 100, estimateRoutePreviewOutputTokens([]byte(`{"max_tokens":100}`)))
+	require.Equal(t, 200, estimateRoutePreviewOutputTokens([]byte(`{"max_output_tokens":200}`)))
+	require.Equal(t, 300, estimateRoutePreviewOutputTokens([]byte(`{"max_completion_tokens":300}`)))
+	require.Equal(t, 0, estimateRoutePreviewOutputTokens([]byte(`{"model":"gpt-5"}`)))
+}
+
+func TestReleaseRoutePreviewSelection(t *testing.T) {
+	released := 0
+	account := &Account{ID: 9}
+	got := releaseRoutePreviewSelection(&AccountSelectionResult{Account: account, Acquired: true, ReleaseFunc: func() { released++ }})
+	require.Same(t, account, got)
+	require.Equal(t, 1, released)
+
+	// 未占用槽位（仅返回等待计划）时不调用释放函数
+	got = releaseRoutePreviewSelection(&AccountSelectionResult{Account: account, ReleaseFunc: func() { released++ }})
+	require.Same(t, account, got)
+	require.Equal(t, 1, released)
+	require.Nil(t, releaseRoutePreviewSelection(nil))
+}
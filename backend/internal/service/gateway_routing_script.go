@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// 路由脚本（gateway.scheduling.routing_script）：在负载感知调度的 Layer 2 对候选账号做排序或否决。
+//
+// 脚本为 expr 表达式（https://expr-lang.org），可读取：
+//
+//	model             请求模型
+//	estimated_tokens  预估输入 token 数
+//	headers           请求头（小写键，多值以逗号连接）
+//	candidates        候选账号列表，字段见 routingScriptCandidate
+//
+// 返回值：
+//   - nil：不干预，沿用内置的 优先级 → 负载率 → LRU 策略
+//   - 账号 ID 列表或 candidates 子集：按返回顺序尝试，未出现在列表中的账号本次被否决
+//
+// 例：filter(candidates, .priority <= 1 || estimated_tokens < 50000)
+//
+// 脚本编译或执行失败时记录告警并回退到内置策略，不影响请求。
+// 模型路由（Layer 1）与粘性会话命中不经过脚本。
+
+// routingScriptCandidate 脚本可见的候选账号字段
+type routingScriptCandidate struct {
+	ID             int64   `expr:"id"`
+	Name           string  `expr:"name"`
+	Platform       string  `expr:"platform"`
+	Type           string  `expr:"type"`
+	Priority       int     `expr:"priority"`
+	Concurrency    int     `expr:"concurrency"`
+	RateMultiplier float64 `expr:"rate_multiplier"`
+	LastUsedUnix   int64   `expr:"last_used_unix"`
+}
+
+type routingScriptEnv struct {
+	Model           string                   `expr:"model"`
+	EstimatedTokens int                      `expr:"estimated_tokens"`
+	Headers         map[string]string        `expr:"headers"`
+	Candidates      []routingScriptCandidate `expr:"candidates"`
+}
+
+type routingScriptRequestContextKey struct{}
+
+// routingScriptRequest handler 在调度前写入 context 的原始请求信息，仅在配置了脚本时才被解析
+type routingScriptRequest struct {
+	body    []byte
+	headers http.Header
+}
+
+// WithRoutingScriptRequest 记录供路由脚本读取的请求体与请求头
+func WithRoutingScriptRequest(ctx context.Context, body []byte, headers http.Header) context.Context {
+	return context.WithValue(ctx, routingScriptRequestContextKey{}, &routingScriptRequest{body: body, headers: headers})
+}
+
+// CompileRoutingScript 编译路由脚本（含对输入变量的类型检查）
+func CompileRoutingScript(source string) (*vm.Program, error) {
+	return expr.Compile(source, expr.Env(routingScriptEnv{}))
+}
+
+// routingScriptProgram 惰性编译配置中的路由脚本；未配置或编译失败时返回 nil
+func (s *GatewayService) routingScriptProgram() *vm.Program {
+	s.routingScriptOnce.Do(func() {
+		source := strings.TrimSpace(s.schedulingConfig().RoutingScript)
+		if source == "" {
+			return
+		}
+		program, err := CompileRoutingScript(source)
+		if err != nil {
+			slog.Error("routing_script_compile_failed", "error", err)
+			return
+		}
+		s.routingScript = program
+	})
+	return s.routingScript
+}
+
+// applyRoutingScript 执行路由脚本，返回脚本给出的候选顺序；ordered=false 表示沿用内置策略
+func (s *GatewayService) applyRoutingScript(ctx context.Context, requestedModel string, candidates []*Account) (result []*Account, ordered bool) {
+	program := s.routingScriptProgram()
+	if program == nil {
+		return candidates, false
+	}
+
+	env := routingScriptEnv{
+		Model:      requestedModel,
+		Headers:    map[string]string{},
+		Candidates: make([]routingScriptCandidate, 0, len(candidates)),
+	}
+	if req, ok := ctx.Value(routingScriptRequestContextKey{}).(*routingScriptRequest); ok && req != nil {
+		env.EstimatedTokens = estimateTokensForText(string(req.body))
+		for name, values := range req.headers {
+			env.Headers[strings.ToLower(name)] = strings.Join(values, ",")
+		}
+	}
+	for _, acc := range candidates {
+		candidate := routingScriptCandidate{
+			ID:             acc.ID,
+			Name:           acc.Name,
+			Platform:       acc.Platform,
+			Type:           acc.Type,
+			Priority:       acc.Priority,
+			Concurrency:    acc.Concurrency,
+			RateMultiplier: acc.BillingRateMultiplier(),
+		}
+		if acc.LastUsedAt != nil {
+			candidate.LastUsedUnix = acc.LastUsedAt.Unix()
+		}
+		env.Candidates = append(env.Candidates, candidate)
+	}
+
+	output, err := runRoutingScript(program, env)
+	if err != nil {
+		slog.Warn("routing_script_eval_failed", "model", requestedModel, "error", err)
+		return candidates, false
+	}
+	if output == nil {
+		return candidates, false
+	}
+	ids, err := routingScriptAccountIDs(output)
+	if err != nil {
+		slog.Warn("routing_script_invalid_result", "model", requestedModel, "error", err)
+		return candidates, false
+	}
+
+	byID := make(map[int64]*Account, len(candidates))
+	for _, acc := range candidates {
+		byID[acc.ID] = acc
+	}
+	result = make([]*Account, 0, len(ids))
+	for _, id := range ids {
+		if acc, ok := byID[id]; ok {
+			result = append(result, acc)
+			delete(byID, id)
+		}
+	}
+	return result, true
+}
+
+// runRoutingScript 执行脚本；expr 内置函数的运行时 panic 转为错误
+func runRoutingScript(program *vm.Program, env routingScriptEnv) (output any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("routing script panic: %v", r)
+		}
+	}()
+	return expr.Run(program, env)
+}
+
+// routingScriptAccountIDs 将脚本返回值（ID 列表或候选账号列表）解析为账号 ID 序列
+func routingScriptAccountIDs(output any) ([]int64, error) {
+	items, ok := output.([]any)
+	if !ok {
+		if typed, isCandidates := output.([]routingScriptCandidate); isCandidates {
+			items = make([]any, 0, len(typed))
+			for _, c := range typed {
+				items = append(items, c)
+			}
+		} else {
+			return nil, fmt.Errorf("routing script must return a list, got %T", output)
+		}
+	}
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case routingScriptCandidate:
+			ids = append(ids, v.ID)
+		case int:
+			ids = append(ids, int64(v))
+		case int64:
+			ids = append(ids, v)
+		case float64:
+			ids = append(ids, int64(v))
+		default:
+			return nil, fmt.Errorf("routing script list item must be an account id or candidate, got %T", item)
+		}
+	}
+	return ids, nil
+}
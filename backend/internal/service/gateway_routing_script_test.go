@@ -0,0 +1,72 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newRoutingScriptTestService(script string) *GatewayService {
+	cfg := &config.Config{}
+	cfg.Gateway.Scheduling.RoutingScript = script
+	return &GatewayService{cfg: cfg}
+}
+
+func routingScriptTestCandidates() []*Account {
+	return []*Account{
+		{ID: 1, Name: "primary", Platform: PlatformAnthropic, Type: AccountTypeOAuth, Priority: 1},
+		{ID: 2, Name: "cheap", Platform: PlatformAnthropic, Type: AccountTypeAPIKey, Priority: 5},
+		{ID: 3, Name: "backup", Platform: PlatformAnthropic, Type: AccountTypeAPIKey, Priority: 3},
+	}
+}
+
+func routingScriptIDs(accounts []*Account) []int64 {
+	ids := make([]int64, 0, len(accounts))
+	for _, acc := range accounts {
+		ids = append(ids, acc.ID)
+	}
+	return ids
+}
+
+func TestApplyRoutingScript_OrderAndVeto(t *testing.T) {
+	ctx := WithRoutingScriptRequest(context.Background(), []byte(`{"model":"claude-sonnet-4-5"}`), http.Header{"X-Tier": []string{"batch"}})
+
+	cases := []struct {
+		name    string
+		script  string
+		ordered bool
+		want    []int64
+	}{
+		{name: "disabled", script: "", ordered: false, want: []int64{1, 2, 3}},
+		{name: "nil keeps builtin", script: `nil`, ordered: false, want: []int64{1, 2, 3}},
+		{name: "id list", script: `[3, 1, 42, 3]`, ordered: true, want: []int64{3, 1}},
+		{name: "sort candidates", script: `sortBy(candidates, .priority, "desc")`, ordered: true, want: []int64{2, 3, 1}},
+		{name: "header veto", script: `headers["x-tier"] == "batch" ? filter(candidates, .type == "apikey") : nil`, ordered: true, want: []int64{2, 3}},
+		{name: "model and tokens", script: `model startsWith "claude" && estimated_tokens > 0 ? map(filter(candidates, .name == "backup"), .id) : nil`, ordered: true, want: []int64{3}},
+		{name: "veto all", script: `[]`, ordered: true, want: []int64{}},
+		{name: "invalid result", script: `"primary"`, ordered: false, want: []int64{1, 2, 3}},
+		{name: "compile error", script: `candidates[`, ordered: false, want: []int64{1, 2, 3}},
+		{name: "runtime error", script: `[candidates[10].id]`, ordered: false, want: []int64{1, 2, 3}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := newRoutingScriptTestService(tc.script)
+			got, ordered := svc.applyRoutingScript(ctx, "claude-sonnet-4-5", routingScriptTestCandidates())
+			require.Equal(t, tc.ordered, ordered)
+			require.Equal(t, tc.want, routingScriptIDs(got))
+		})
+	}
+}
+
+func TestCompileRoutingScript_TypeChecksVariables(t *testing.T) {
+	_, err := CompileRoutingScript(`filter(candidates, .priority < 3)`)
+	require.NoError(t, err)
+
+	_, err = CompileRoutingScript(`filter(candidates, .unknown_field)`)
+	require.Error(t, err)
+}
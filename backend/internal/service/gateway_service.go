@@ -20,18 +20,22 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/claude"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
 	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
 	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
 	"github.com/cespare/xxhash/v2"
+	"github.com/expr-lang/expr/vm"
 	"github.com/google/uuid"
 	gocache "github.com/patrickmn/go-cache"
 	"github.com/tidwall/gjson"
@@ -422,6 +426,17 @@ func resolveModelsListCacheTTL(cfg *config.Config) time.Duration {
 	return time.Duration(cfg.Gateway.ModelsListCacheTTLSeconds) * time.Second
 }
 
+// resolveToolArgsCoalesce 返回协议转换流中工具调用参数增量的合并配置，未配置时逐条输出
+func resolveToolArgsCoalesce(cfg *config.Config) apicompat.ToolArgsCoalesceConfig {
+	if cfg == nil {
+		return apicompat.ToolArgsCoalesceConfig{}
+	}
+	return apicompat.ToolArgsCoalesceConfig{
+		Window:   time.Duration(cfg.Gateway.ToolArgsCoalesceMs) * time.Millisecond,
+		MaxBytes: cfg.Gateway.ToolArgsCoalesceBytes,
+	}
+}
+
 func modelsListCacheKey(groupID *int64, platform string) string {
 	return fmt.Sprintf("%d|%s", derefGroupID(groupID), strings.TrimSpace(platform))
 }
@@ -500,6 +515,10 @@ type ForwardResult struct {
 	ClientDisconnect bool // 客户端是否在流式传输过程中断开
 	ReasoningEffort  *string
 
+	// 部分用量（客户端断开后提前取消上游，见 gateway.cancel_upstream_on_client_disconnect）
+	PartialUsage       bool // usage 为部分值，记录时按 PartialOutputChars 估算缺失的 output tokens
+	PartialOutputChars int  // 取消前已转发的输出字符数
+
 	// 图片生成计费字段（图片生成模型使用）
 	ImageCount int    // 生成的图片数量
 	ImageSize  string // 图片尺寸 "1K", "2K", "4K"
@@ -539,6 +558,7 @@ type GatewayService struct {
 	groupRepo             GroupRepository
 	usageLogRepo          UsageLogRepository
 	usageBillingRepo      UsageBillingRepository
+	usageEventExporter    *UsageEventExporter
 	userRepo              UserRepository
 	userSubRepo           UserSubscriptionRepository
 	userGroupRateRepo     UserGroupRateRepository
@@ -570,6 +590,10 @@ type GatewayService struct {
 	debugGatewayBodyFile  atomic.Pointer[os.File] // non-nil when SUB2API_DEBUG_GATEWAY_BODY is set
 	tlsFPProfileService   *TLSFingerprintProfileService
 	balanceNotifyService  *BalanceNotifyService
+	routingScriptOnce     sync.Once
+	routingScript         *vm.Program // 惰性编译的路由脚本，未配置时为 nil
+	// countTokensUnsupportedUntil 上游 count_tokens 无权限的账号 → 恢复尝试时间（accountID → time.Time）
+	countTokensUnsupportedUntil sync.Map
 }
 
 // NewGatewayService creates a new GatewayService
@@ -1737,6 +1761,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 				if loadInfo == nil {
 					loadInfo = &AccountLoadInfo{AccountID: acc.ID}
 				}
+				loadInfo = withUpstreamRateLimitLoad(acc, loadInfo, time.Now())
 				if loadInfo.LoadRate < 100 {
 					routingAvailable = append(routingAvailable, accountWithLoad{account: acc, loadInfo: loadInfo})
 				}
@@ -1968,6 +1993,8 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 		candidates = append(candidates, acc)
 	}
 
+	// 路由脚本可对候选账号排序或否决；返回顺序时跳过内置的分层过滤与兜底排序
+	candidates, scriptOrdered := s.applyRoutingScript(ctx, requestedModel, candidates)
 	if len(candidates) == 0 {
 		return nil, ErrNoAvailableAccounts
 	}
@@ -1994,6 +2021,7 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			if loadInfo == nil {
 				loadInfo = &AccountLoadInfo{AccountID: acc.ID}
 			}
+			loadInfo = withUpstreamRateLimitLoad(acc, loadInfo, time.Now())
 			if loadInfo.LoadRate < 100 {
 				available = append(available, accountWithLoad{
 					account:  acc,
@@ -2002,14 +2030,19 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 			}
 		}
 
-		// 分层过滤选择：优先级 → 负载率 → LRU
+		// 分层过滤选择：优先级 → 负载率 → LRU（路由脚本给出顺序时按脚本顺序依次尝试）
 		for len(available) > 0 {
-			// 1. 取优先级最小的集合
-			candidates := filterByMinPriority(available)
-			// 2. 取负载率最低的集合
-			candidates = filterByMinLoadRate(candidates)
-			// 3. LRU 选择最久未用的账号
-			selected := selectByLRU(candidates, preferOAuth)
+			var selected *accountWithLoad
+			if scriptOrdered {
+				selected = &available[0]
+			} else {
+				// 1. 取优先级最小的集合
+				candidates := filterByMinPriority(available)
+				// 2. 取负载率最低的集合
+				candidates = filterByMinLoadRate(candidates)
+				// 3. LRU 选择最久未用的账号
+				selected = selectByLRU(candidates, preferOAuth)
+			}
 			if selected == nil {
 				break
 			}
@@ -2040,7 +2073,9 @@ func (s *GatewayService) SelectAccountWithLoadAwareness(ctx context.Context, gro
 	}
 
 	// ============ Layer 3: 兜底排队 ============
-	s.sortCandidatesForFallback(candidates, preferOAuth, cfg.FallbackSelectionMode)
+	if !scriptOrdered {
+		s.sortCandidatesForFallback(candidates, preferOAuth, cfg.FallbackSelectionMode)
+	}
 	for _, acc := range candidates {
 		// 会话数量限制检查（等待计划也需要占用会话配额）
 		if !s.checkAndRegisterSession(ctx, acc, sessionHash) {
@@ -2242,7 +2277,7 @@ func (s *GatewayService) listSchedulableAccounts(ctx context.Context, groupID *i
 					"tls_fingerprint", acc.IsTLSFingerprintEnabled())
 			}
 		}
-		return accounts, useMixed, err
+		return applyRequestAccountFilters(ctx, accounts), useMixed, err
 	}
 	useMixed := (platform == PlatformAnthropic || platform == PlatformGemini) && !hasForcePlatform
 	if useMixed {
@@ -2284,7 +2319,7 @@ func (s *GatewayService) listSchedulableAccounts(ctx context.Context, groupID *i
 				"status", acc.Status,
 				"tls_fingerprint", acc.IsTLSFingerprintEnabled())
 		}
-		return filtered, useMixed, nil
+		return applyRequestAccountFilters(ctx, filtered), useMixed, nil
 	}
 
 	var accounts []Account
@@ -2317,7 +2352,7 @@ func (s *GatewayService) listSchedulableAccounts(ctx context.Context, groupID *i
 			"status", acc.Status,
 			"tls_fingerprint", acc.IsTLSFingerprintEnabled())
 	}
-	return accounts, useMixed, nil
+	return applyRequestAccountFilters(ctx, accounts), useMixed, nil
 }
 
 // IsSingleAntigravityAccountGroup 检查指定分组是否只有一个 antigravity 平台的可调度账号。
@@ -2688,10 +2723,19 @@ func (s *GatewayService) checkAndRegisterSession(ctx context.Context, account *A
 }
 
 func (s *GatewayService) getSchedulableAccount(ctx context.Context, accountID int64) (*Account, error) {
+	var (
+		account *Account
+		err     error
+	)
 	if s.schedulerSnapshot != nil {
-		return s.schedulerSnapshot.GetAccount(ctx, accountID)
+		account, err = s.schedulerSnapshot.GetAccount(ctx, accountID)
+	} else {
+		account, err = s.accountRepo.GetByID(ctx, accountID)
 	}
-	return s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return restrictRequestAccount(ctx, account), nil
 }
 
 func (s *GatewayService) hydrateSelectedAccount(ctx context.Context, account *Account) (*Account, error) {
@@ -4514,7 +4558,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		}
 
 		// 发送请求
-		resp, err = s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, tlsProfile)
+		resp, err = s.httpUpstream.DoWithTLS(withAccountTransport(upstreamReq, account), proxyURL, account.ID, account.Concurrency, tlsProfile)
 		if err != nil {
 			if resp != nil && resp.Body != nil {
 				_ = resp.Body.Close()
@@ -4592,7 +4636,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 					retryReq, buildErr := s.buildUpstreamRequest(retryCtx, c, account, filteredBody, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
 					releaseRetryCtx()
 					if buildErr == nil {
-						retryResp, retryErr := s.httpUpstream.DoWithTLS(retryReq, proxyURL, account.ID, account.Concurrency, tlsProfile)
+						retryResp, retryErr := s.httpUpstream.DoWithTLS(withAccountTransport(retryReq, account), proxyURL, account.ID, account.Concurrency, tlsProfile)
 						if retryErr == nil {
 							if retryResp.StatusCode < 400 {
 								logger.LegacyPrintf("service.gateway", "Account %d: thinking block retry succeeded (blocks downgraded)", account.ID)
@@ -4627,7 +4671,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 									retryReq2, buildErr2 := s.buildUpstreamRequest(retryCtx2, c, account, filteredBody2, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
 									releaseRetryCtx2()
 									if buildErr2 == nil {
-										retryResp2, retryErr2 := s.httpUpstream.DoWithTLS(retryReq2, proxyURL, account.ID, account.Concurrency, tlsProfile)
+										retryResp2, retryErr2 := s.httpUpstream.DoWithTLS(withAccountTransport(retryReq2, account), proxyURL, account.ID, account.Concurrency, tlsProfile)
 										if retryErr2 == nil {
 											resp = retryResp2
 											break
@@ -4698,7 +4742,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 						budgetRetryReq, buildErr := s.buildUpstreamRequest(budgetRetryCtx, c, account, rectifiedBody, token, tokenType, reqModel, reqStream, shouldMimicClaudeCode)
 						releaseBudgetRetryCtx()
 						if buildErr == nil {
-							budgetRetryResp, retryErr := s.httpUpstream.DoWithTLS(budgetRetryReq, proxyURL, account.ID, account.Concurrency, tlsProfile)
+							budgetRetryResp, retryErr := s.httpUpstream.DoWithTLS(withAccountTransport(budgetRetryReq, account), proxyURL, account.ID, account.Concurrency, tlsProfile)
 							if retryErr == nil {
 								resp = budgetRetryResp
 								break
@@ -4904,6 +4948,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 	var usage *ClaudeUsage
 	var firstTokenMs *int
 	var clientDisconnect bool
+	var partialStream *streamingResult
 	if reqStream {
 		streamResult, err := s.handleStreamingResponse(ctx, resp, c, account, startTime, originalModel, reqModel, shouldMimicClaudeCode)
 		if err != nil {
@@ -4917,6 +4962,9 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		usage = streamResult.usage
 		firstTokenMs = streamResult.firstTokenMs
 		clientDisconnect = streamResult.clientDisconnect
+		if streamResult.partial {
+			partialStream = streamResult
+		}
 	} else {
 		usage, err = s.handleNonStreamingResponse(ctx, resp, c, account, originalModel, reqModel)
 		if err != nil {
@@ -4924,7 +4972,7 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		}
 	}
 
-	return &ForwardResult{
+	result := &ForwardResult{
 		RequestID:        resp.Header.Get("x-request-id"),
 		Usage:            *usage,
 		Model:            originalModel, // 使用原始模型用于计费和日志
@@ -4933,7 +4981,12 @@ func (s *GatewayService) Forward(ctx context.Context, c *gin.Context, account *A
 		Duration:         time.Since(startTime),
 		FirstTokenMs:     firstTokenMs,
 		ClientDisconnect: clientDisconnect,
-	}, nil
+	}
+	if partialStream != nil {
+		result.PartialUsage = true
+		result.PartialOutputChars = partialStream.partialOutputChars
+	}
+	return result, nil
 }
 
 type anthropicPassthroughForwardInput struct {
@@ -5004,7 +5057,7 @@ func (s *GatewayService) forwardAnthropicAPIKeyPassthroughWithInput(
 			return nil, err
 		}
 
-		resp, err = s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+		resp, err = s.httpUpstream.DoWithTLS(withAccountTransport(upstreamReq, account), proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
 		if err != nil {
 			if resp != nil && resp.Body != nil {
 				_ = resp.Body.Close()
@@ -5159,7 +5212,9 @@ func (s *GatewayService) forwardAnthropicAPIKeyPassthroughWithInput(
 	var firstTokenMs *int
 	var clientDisconnect bool
 	if input.RequestStream {
+		stopKeepalive := startStreamKeepalive(c, s.cfg)
 		streamResult, err := s.handleStreamingResponseAnthropicAPIKeyPassthrough(ctx, resp, c, account, input.StartTime, input.RequestModel)
+		stopKeepalive()
 		if err != nil {
 			return nil, err
 		}
@@ -5167,7 +5222,7 @@ func (s *GatewayService) forwardAnthropicAPIKeyPassthroughWithInput(
 		firstTokenMs = streamResult.firstTokenMs
 		clientDisconnect = streamResult.clientDisconnect
 	} else {
-		usage, err = s.handleNonStreamingResponseAnthropicAPIKeyPassthrough(ctx, resp, c, account)
+		usage, err = s.handleNonStreamingResponseAnthropicAPIKeyPassthrough(ctx, resp, c, account, input.RequestModel)
 		if err != nil {
 			return nil, err
 		}
@@ -5252,7 +5307,7 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 		s.rateLimitService.UpdateSessionWindow(ctx, account, resp.Header)
 	}
 
-	writeAnthropicPassthroughResponseHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	writeAnthropicPassthroughResponseHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 
 	contentType := strings.TrimSpace(resp.Header.Get("Content-Type"))
 	if contentType == "" {
@@ -5281,13 +5336,11 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 	clientDisconnected := false
 	sawTerminalEvent := false
 
-	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanBuf := getSSEScannerBuf64K()
-	scanner.Buffer(scanBuf[:0], maxLineSize)
+	scanner := getSSEReader(resp.Body, maxLineSize)
 
 	type scanEvent struct {
 		line string
@@ -5305,8 +5358,8 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 	}
 	var lastReadAt int64
 	atomic.StoreInt64(&lastReadAt, time.Now().UnixNano())
-	go func(scanBuf *sseScannerBuf64K) {
-		defer putSSEScannerBuf64K(scanBuf)
+	go func(scanner *sse.Reader) {
+		defer putSSEReader(scanner)
 		defer close(events)
 		for scanner.Scan() {
 			atomic.StoreInt64(&lastReadAt, time.Now().UnixNano())
@@ -5317,7 +5370,7 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 		if err := scanner.Err(); err != nil {
 			_ = sendEvent(scanEvent{err: err})
 		}
-	}(scanBuf)
+	}(scanner)
 	defer close(done)
 
 	streamInterval := time.Duration(0)
@@ -5345,6 +5398,13 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 				if !sawTerminalEvent {
 					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs, clientDisconnect: clientDisconnected}, fmt.Errorf("stream usage incomplete: missing terminal event")
 				}
+				if !clientDisconnected {
+					if comment := s.streamCostAttributionComment(ctx, c, model, usage) + s.streamPromptCacheStatusComment(usage); comment != "" {
+						if _, err := io.WriteString(w, comment); err == nil {
+							flusher.Flush()
+						}
+					}
+				}
 				return &streamingResult{usage: usage, firstTokenMs: firstTokenMs, clientDisconnect: clientDisconnected}, nil
 			}
 			if ev.err != nil {
@@ -5408,6 +5468,9 @@ func (s *GatewayService) handleStreamingResponseAnthropicAPIKeyPassthrough(
 			if s.rateLimitService != nil {
 				s.rateLimitService.HandleStreamTimeout(ctx, account, model)
 			}
+			if streamTimeoutFailoverEnabled(s.cfg) && !c.Writer.Written() {
+				return nil, newStreamTimeoutFailoverError(streamInterval)
+			}
 			return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, fmt.Errorf("stream data interval timeout")
 		}
 	}
@@ -5537,6 +5600,7 @@ func (s *GatewayService) handleNonStreamingResponseAnthropicAPIKeyPassthrough(
 	resp *http.Response,
 	c *gin.Context,
 	account *Account,
+	model string,
 ) (*ClaudeUsage, error) {
 	if s.rateLimitService != nil {
 		s.rateLimitService.UpdateSessionWindow(ctx, account, resp.Header)
@@ -5549,12 +5613,14 @@ func (s *GatewayService) handleNonStreamingResponseAnthropicAPIKeyPassthrough(
 
 	usage := parseClaudeUsageFromResponseBody(body)
 
-	writeAnthropicPassthroughResponseHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	writeAnthropicPassthroughResponseHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 	contentType := strings.TrimSpace(resp.Header.Get("Content-Type"))
 	if contentType == "" {
 		contentType = "application/json"
 	}
 	body = reverseToolNamesIfPresent(c, body)
+	s.applyCostAttributionHeaders(ctx, c, model, usage)
+	s.applyPromptCacheStatusHeaders(c, usage)
 	c.Data(resp.StatusCode, contentType, body)
 	return usage, nil
 }
@@ -5658,7 +5724,9 @@ func (s *GatewayService) forwardBedrock(
 	var firstTokenMs *int
 	var clientDisconnect bool
 	if reqStream {
+		stopKeepalive := startStreamKeepalive(c, s.cfg)
 		streamResult, err := s.handleBedrockStreamingResponse(ctx, resp, c, account, startTime, reqModel)
+		stopKeepalive()
 		if err != nil {
 			return nil, err
 		}
@@ -5714,7 +5782,7 @@ func (s *GatewayService) executeBedrockUpstream(
 			return nil, err
 		}
 
-		resp, err = s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, nil)
+		resp, err = s.httpUpstream.DoWithTLS(withAccountTransport(upstreamReq, account), proxyURL, account.ID, account.Concurrency, nil)
 		if err != nil {
 			if resp != nil && resp.Body != nil {
 				_ = resp.Body.Close()
@@ -5930,6 +5998,8 @@ func (s *GatewayService) handleBedrockNonStreamingResponse(
 }
 
 func (s *GatewayService) buildUpstreamRequest(ctx context.Context, c *gin.Context, account *Account, body []byte, token, tokenType, modelID string, reqStream bool, mimicClaudeCode bool) (*http.Request, error) {
+	body = ApplyAccountBodyTransforms(body, account)
+
 	if account.Platform == PlatformAnthropic && account.Type == AccountTypeServiceAccount {
 		return s.buildUpstreamRequestAnthropicVertex(ctx, c, account, body, token, modelID, reqStream)
 	}
@@ -7110,9 +7180,11 @@ func (s *GatewayService) handleRetryExhaustedError(ctx context.Context, resp *ht
 
 // streamingResult 流式响应结果
 type streamingResult struct {
-	usage            *ClaudeUsage
-	firstTokenMs     *int
-	clientDisconnect bool // 客户端是否在流式传输过程中断开
+	usage              *ClaudeUsage
+	firstTokenMs       *int
+	clientDisconnect   bool // 客户端是否在流式传输过程中断开
+	partial            bool // 客户端断开后提前取消了上游，usage 不完整
+	partialOutputChars int  // 取消前已转发的输出字符数（用于估算 output tokens）
 }
 
 func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http.Response, c *gin.Context, account *Account, startTime time.Time, originalModel, mappedModel string, mimicClaudeCode bool) (*streamingResult, error) {
@@ -7120,7 +7192,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 	s.rateLimitService.UpdateSessionWindow(ctx, account, resp.Header)
 
 	if s.responseHeaderFilter != nil {
-		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 	}
 
 	// 设置SSE响应头
@@ -7142,14 +7214,11 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 
 	usage := &ClaudeUsage{}
 	var firstTokenMs *int
-	scanner := bufio.NewScanner(resp.Body)
-	// 设置更大的buffer以处理长行
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanBuf := getSSEScannerBuf64K()
-	scanner.Buffer(scanBuf[:0], maxLineSize)
+	scanner := getSSEReader(resp.Body, maxLineSize)
 
 	type scanEvent struct {
 		line string
@@ -7168,8 +7237,8 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 	}
 	var lastReadAt int64
 	atomic.StoreInt64(&lastReadAt, time.Now().UnixNano())
-	go func(scanBuf *sseScannerBuf64K) {
-		defer putSSEScannerBuf64K(scanBuf)
+	go func(scanner *sse.Reader) {
+		defer putSSEReader(scanner)
 		defer close(events)
 		for scanner.Scan() {
 			atomic.StoreInt64(&lastReadAt, time.Now().UnixNano())
@@ -7180,7 +7249,7 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 		if err := scanner.Err(); err != nil {
 			_ = sendEvent(scanEvent{err: err})
 		}
-	}(scanBuf)
+	}(scanner)
 	defer close(done)
 
 	streamInterval := time.Duration(0)
@@ -7246,6 +7315,25 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 	clientDisconnected := false // 客户端断开标志，断开后继续读取上游以获取完整usage
 	sawTerminalEvent := false
 
+	// 开启 cancel_upstream_on_client_disconnect 时，客户端断开（请求 context 取消或写入失败）后立即结束，
+	// 返回后关闭上游 body 即取消上游请求；usage 为部分值，缺失的 output tokens 在记录时按已输出字符估算。
+	cancelOnDisconnect := s.cfg != nil && s.cfg.Gateway.CancelUpstreamOnClientDisconnect
+	var clientDone <-chan struct{}
+	if cancelOnDisconnect && c.Request != nil {
+		clientDone = c.Request.Context().Done()
+	}
+	outputChars := 0
+	partialResult := func() (*streamingResult, error) {
+		logger.LegacyPrintf("service.gateway", "Client disconnected, cancelling upstream stream: account=%d model=%s output_chars=%d", account.ID, originalModel, outputChars)
+		return &streamingResult{
+			usage:              usage,
+			firstTokenMs:       firstTokenMs,
+			clientDisconnect:   true,
+			partial:            !sawTerminalEvent,
+			partialOutputChars: outputChars,
+		}, nil
+	}
+
 	pendingEventLines := make([]string, 0, 4)
 
 	processSSEEvent := func(lines []string) ([]string, string, *sseUsagePatch, error) {
@@ -7342,6 +7430,9 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 		}
 
 		usagePatch := s.extractSSEUsagePatch(event)
+		if eventType == "content_block_delta" {
+			outputChars += anthropicDeltaChars(event)
+		}
 		if anthropicStreamEventIsTerminal(eventName, dataLine) {
 			sawTerminalEvent = true
 		}
@@ -7381,6 +7472,13 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 				if !sawTerminalEvent {
 					return &streamingResult{usage: usage, firstTokenMs: firstTokenMs, clientDisconnect: clientDisconnected}, fmt.Errorf("stream usage incomplete: missing terminal event")
 				}
+				if !clientDisconnected {
+					if comment := s.streamCostAttributionComment(ctx, c, mappedModel, usage) + s.streamPromptCacheStatusComment(usage); comment != "" {
+						if _, werr := fmt.Fprint(w, comment); werr == nil {
+							flusher.Flush()
+						}
+					}
+				}
 				return &streamingResult{usage: usage, firstTokenMs: firstTokenMs, clientDisconnect: clientDisconnected}, nil
 			}
 			if ev.err != nil {
@@ -7448,6 +7546,9 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 						restored := reverseToolNamesIfPresent(c, []byte(block))
 						if _, werr := fmt.Fprint(w, string(restored)); werr != nil {
 							clientDisconnected = true
+							if cancelOnDisconnect {
+								return partialResult()
+							}
 							logger.LegacyPrintf("service.gateway", "Client disconnected during streaming, continuing to drain upstream for billing")
 							break
 						}
@@ -7469,6 +7570,9 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 
 			pendingEventLines = append(pendingEventLines, line)
 
+		case <-clientDone:
+			return partialResult()
+
 		case <-intervalCh:
 			lastRead := time.Unix(0, atomic.LoadInt64(&lastReadAt))
 			if time.Since(lastRead) < streamInterval {
@@ -7482,6 +7586,10 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 			if s.rateLimitService != nil {
 				s.rateLimitService.HandleStreamTimeout(ctx, account, originalModel)
 			}
+			// 尚未向客户端输出任何内容时，可按配置切换账号重试
+			if streamTimeoutFailoverEnabled(s.cfg) && !c.Writer.Written() {
+				return nil, newStreamTimeoutFailoverError(streamInterval)
+			}
 			sendErrorEvent("stream_timeout", fmt.Sprintf("upstream stream idle for %s", streamInterval))
 			return &streamingResult{usage: usage, firstTokenMs: firstTokenMs}, fmt.Errorf("stream data interval timeout")
 
@@ -7496,6 +7604,9 @@ func (s *GatewayService) handleStreamingResponse(ctx context.Context, resp *http
 			// 同时保持连接活跃防止 Cloudflare Tunnel 等代理断开
 			if _, werr := fmt.Fprint(w, "event: ping\ndata: {\"type\": \"ping\"}\n\n"); werr != nil {
 				clientDisconnected = true
+				if cancelOnDisconnect {
+					return partialResult()
+				}
 				logger.LegacyPrintf("service.gateway", "Client disconnected during keepalive ping, continuing to drain upstream for billing")
 				continue
 			}
@@ -7792,7 +7903,7 @@ func (s *GatewayService) handleNonStreamingResponse(ctx context.Context, resp *h
 		body = s.replaceModelInResponseBody(body, mappedModel, originalModel)
 	}
 
-	responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 
 	contentType := "application/json"
 	if s.cfg != nil && !s.cfg.Security.ResponseHeaders.Enabled {
@@ -7802,6 +7913,8 @@ func (s *GatewayService) handleNonStreamingResponse(ctx context.Context, resp *h
 	}
 
 	body = reverseToolNamesIfPresent(c, body)
+	s.applyCostAttributionHeaders(ctx, c, mappedModel, &response.Usage)
+	s.applyPromptCacheStatusHeaders(c, &response.Usage)
 
 	// 写入响应
 	c.Data(resp.StatusCode, contentType, body)
@@ -7853,6 +7966,7 @@ type RecordUsageInput struct {
 	IPAddress          string             // 请求的客户端 IP 地址
 	RequestPayloadHash string             // 请求体语义哈希，用于降低 request_id 误复用时的静默误去重风险
 	ForceCacheBilling  bool               // 强制缓存计费：将 input_tokens 转为 cache_read 计费（用于粘性会话切换）
+	PIIRedactions      int                // 请求内容敏感信息脱敏次数
 	APIKeyService      APIKeyQuotaUpdater // 可选：用于更新API Key配额
 
 	ChannelUsageFields // 渠道映射信息（由 handler 在 Forward 前解析）
@@ -8044,12 +8158,28 @@ func applyUsageBilling(ctx context.Context, requestID string, usageLog *UsageLog
 		postUsageBilling(ctx, p, deps)
 		return true, nil
 	}
+	cmd.IdempotencyKey = BuildUsageIdempotencyKey(cmd.RequestID, cmd.AccountID, usageBillingAttempt(ctx))
+
+	// 使用量记录池溢出时直接落盘到本地日志，避免在已饱和的数据库上继续堆积
+	journal, overflow := usageRecordJournalFromContext(ctx)
+	if overflow {
+		if err := journal.Append(newUsageRecordJournalEntry(UsageRecordJournalReasonOverflow, cmd, usageLog)); err != nil {
+			return false, fmt.Errorf("usage record journal append: %w", err)
+		}
+		return false, errUsageRecordJournaled
+	}
 
 	billingCtx, cancel := detachedBillingContext(ctx)
 	defer cancel()
 
 	result, err := repo.Apply(billingCtx, cmd)
 	if err != nil {
+		if journal != nil && !errors.Is(err, ErrUsageBillingRequestConflict) {
+			if appendErr := journal.Append(newUsageRecordJournalEntry(UsageRecordJournalReasonApplyFailed, cmd, usageLog)); appendErr == nil {
+				logger.LegacyPrintf("service.gateway", "[UsageRecord] billing apply failed, journaled for replay: request_id=%s err=%v", cmd.RequestID, err)
+				return false, errUsageRecordJournaled
+			}
+		}
 		return false, err
 	}
 
@@ -8257,6 +8387,7 @@ func (s *GatewayService) RecordUsage(ctx context.Context, input *RecordUsageInpu
 		IPAddress:          input.IPAddress,
 		RequestPayloadHash: input.RequestPayloadHash,
 		ForceCacheBilling:  input.ForceCacheBilling,
+		PIIRedactions:      input.PIIRedactions,
 		APIKeyService:      input.APIKeyService,
 		ChannelUsageFields: input.ChannelUsageFields,
 	}, &recordUsageOpts{
@@ -8279,6 +8410,7 @@ type RecordUsageLongContextInput struct {
 	LongContextThreshold  int                // 长上下文阈值（如 200000）
 	LongContextMultiplier float64            // 超出阈值部分的倍率（如 2.0）
 	ForceCacheBilling     bool               // 强制缓存计费：将 input_tokens 转为 cache_read 计费（用于粘性会话切换）
+	PIIRedactions         int                // 请求内容敏感信息脱敏次数
 	APIKeyService         APIKeyQuotaUpdater // API Key 配额服务（可选）
 
 	ChannelUsageFields // 渠道映射信息（由 handler 在 Forward 前解析）
@@ -8298,6 +8430,7 @@ func (s *GatewayService) RecordUsageWithLongContext(ctx context.Context, input *
 		IPAddress:          input.IPAddress,
 		RequestPayloadHash: input.RequestPayloadHash,
 		ForceCacheBilling:  input.ForceCacheBilling,
+		PIIRedactions:      input.PIIRedactions,
 		APIKeyService:      input.APIKeyService,
 		ChannelUsageFields: input.ChannelUsageFields,
 	}, &recordUsageOpts{
@@ -8319,6 +8452,7 @@ type recordUsageCoreInput struct {
 	IPAddress          string
 	RequestPayloadHash string
 	ForceCacheBilling  bool
+	PIIRedactions      int
 	APIKeyService      APIKeyQuotaUpdater
 	ChannelUsageFields
 }
@@ -8334,6 +8468,9 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 	account := input.Account
 	subscription := input.Subscription
 
+	// 客户端断开后提前取消上游：补齐缺失的 output tokens 估算
+	applyPartialUsageEstimate(result)
+
 	// 强制缓存计费：将 input_tokens 转为 cache_read_input_tokens
 	// 用于粘性会话切换时的特殊计费处理
 	if input.ForceCacheBilling && result.Usage.InputTokens > 0 {
@@ -8376,8 +8513,13 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 		requestedModel = input.OriginalModel
 	}
 
-	// 计算费用
-	cost := s.calculateRecordUsageCost(ctx, result, apiKey, billingModel, multiplier, opts)
+	// 计算费用（自托管模型账号不产生上游成本，仅记录用量）
+	var cost *CostBreakdown
+	if account.IsZeroCostBilling() {
+		cost = &CostBreakdown{BillingMode: string(BillingModeFree)}
+	} else {
+		cost = s.calculateRecordUsageCost(ctx, result, apiKey, billingModel, multiplier, opts)
+	}
 
 	// 判断计费方式：订阅模式 vs 余额模式
 	isSubscriptionBilling := subscription != nil && apiKey.Group != nil && apiKey.Group.IsSubscriptionType()
@@ -8410,6 +8552,7 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 
 	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
 		writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.gateway")
+		s.usageEventExporter.Export(usageLog)
 		logger.LegacyPrintf("service.gateway", "[SIMPLE MODE] Usage recorded (not billed): user=%d, tokens=%d", usageLog.UserID, usageLog.TotalTokens())
 		s.deferredService.ScheduleLastUsedUpdate(account.ID)
 		return nil
@@ -8428,10 +8571,14 @@ func (s *GatewayService) recordUsageCore(ctx context.Context, input *recordUsage
 		APIKeyService:         input.APIKeyService,
 	}, s.billingDeps(), s.usageBillingRepo)
 
+	if errors.Is(billingErr, errUsageRecordJournaled) {
+		return nil
+	}
 	if billingErr != nil {
 		return billingErr
 	}
 	writeUsageLogBestEffort(ctx, s.usageLogRepo, usageLog, "service.gateway")
+	s.usageEventExporter.Export(usageLog)
 
 	return nil
 }
@@ -8609,6 +8756,7 @@ func (s *GatewayService) buildRecordUsageLog(
 		ImageCount:            result.ImageCount,
 		ImageSize:             optionalTrimmedStringPtr(result.ImageSize),
 		CacheTTLOverridden:    cacheTTLOverridden,
+		PIIRedactions:         input.PIIRedactions,
 		ChannelID:             optionalInt64Ptr(input.ChannelID),
 		ModelMappingChain:     optionalTrimmedStringPtr(input.ModelMappingChain),
 		UserAgent:             optionalTrimmedStringPtr(input.UserAgent),
@@ -8786,6 +8934,14 @@ func (s *GatewayService) ForwardCountTokens(ctx context.Context, c *gin.Context,
 		return nil
 	}
 
+	// setup-token 仅有推理权限，无法调用 count_tokens；近期确认无权限的 API Key 账号同样直接本地估算，
+	// 避免每次请求都打到上游再失败
+	if account != nil && account.Platform == PlatformAnthropic &&
+		(account.Type == AccountTypeSetupToken || (account.Type == AccountTypeAPIKey && s.countTokensUnsupported(account.ID))) {
+		s.respondEstimatedCountTokens(c, parsed, parsed.Model)
+		return nil
+	}
+
 	body := parsed.Body
 	reqModel := parsed.Model
 
@@ -8864,7 +9020,7 @@ func (s *GatewayService) ForwardCountTokens(ctx context.Context, c *gin.Context,
 	}
 
 	// 发送请求
-	resp, err := s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+	resp, err := s.httpUpstream.DoWithTLS(withAccountTransport(upstreamReq, account), proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
 	if err != nil {
 		setOpsUpstreamError(c, 0, sanitizeUpstreamErrorMessage(err.Error()), "")
 		s.countTokensError(c, http.StatusBadGateway, "upstream_error", "Request failed")
@@ -8891,7 +9047,7 @@ func (s *GatewayService) ForwardCountTokens(ctx context.Context, c *gin.Context,
 		filteredBody := FilterThinkingBlocksForRetry(body)
 		retryReq, buildErr := s.buildCountTokensRequest(ctx, c, account, filteredBody, token, tokenType, reqModel, shouldMimicClaudeCode)
 		if buildErr == nil {
-			retryResp, retryErr := s.httpUpstream.DoWithTLS(retryReq, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+			retryResp, retryErr := s.httpUpstream.DoWithTLS(withAccountTransport(retryReq, account), proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
 			if retryErr == nil {
 				resp = retryResp
 				respBody, err = ReadUpstreamResponseBody(resp.Body, s.cfg, c, countTokensTooLarge)
@@ -8906,6 +9062,15 @@ func (s *GatewayService) ForwardCountTokens(ctx context.Context, c *gin.Context,
 		}
 	}
 
+	// API Key 账号无 count_tokens 权限（403/404）：记住该账号并改用本地估算，不计入账号错误
+	if account.Platform == PlatformAnthropic && account.Type == AccountTypeAPIKey &&
+		(resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound) {
+		logger.LegacyPrintf("service.gateway", "Account %d: count_tokens not available upstream (status=%d), falling back to local estimate", account.ID, resp.StatusCode)
+		s.markCountTokensUnsupported(account.ID)
+		s.respondEstimatedCountTokens(c, parsed, reqModel)
+		return nil
+	}
+
 	// 处理错误响应
 	if resp.StatusCode >= 400 {
 		// 标记账号状态（429/529等）
@@ -8977,7 +9142,7 @@ func (s *GatewayService) forwardCountTokensAnthropicAPIKeyPassthrough(ctx contex
 		proxyURL = account.Proxy.URL()
 	}
 
-	resp, err := s.httpUpstream.DoWithTLS(upstreamReq, proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
+	resp, err := s.httpUpstream.DoWithTLS(withAccountTransport(upstreamReq, account), proxyURL, account.ID, account.Concurrency, s.tlsFPProfileService.ResolveTLSProfile(account))
 	if err != nil {
 		setOpsUpstreamError(c, 0, sanitizeUpstreamErrorMessage(err.Error()), "")
 		appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
@@ -9061,7 +9226,7 @@ func (s *GatewayService) forwardCountTokensAnthropicAPIKeyPassthrough(ctx contex
 		return fmt.Errorf("upstream error: %d message=%s", resp.StatusCode, upstreamMsg)
 	}
 
-	writeAnthropicPassthroughResponseHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	writeAnthropicPassthroughResponseHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 	contentType := strings.TrimSpace(resp.Header.Get("Content-Type"))
 	if contentType == "" {
 		contentType = "application/json"
@@ -9368,6 +9533,19 @@ func (s *GatewayService) GetAvailableModels(ctx context.Context, groupID *int64,
 			for model := range mapping {
 				modelSet[model] = struct{}{}
 			}
+			continue
+		}
+		// 未配置映射的 Ollama 账号以 /api/tags 中本地已拉取的模型作为可用模型
+		if acc.IsOllama() {
+			tags, err := s.ListOllamaModels(ctx, &acc)
+			if err != nil {
+				logger.LegacyPrintf("service.gateway", "list ollama models failed: account=%d err=%v", acc.ID, err)
+				continue
+			}
+			for _, model := range tags {
+				hasAnyMapping = true
+				modelSet[model] = struct{}{}
+			}
 		}
 	}
 
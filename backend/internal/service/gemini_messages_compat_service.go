@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"crypto/rand"
@@ -23,6 +22,7 @@ import (
 	"github.com/Wei-Shaw/sub2api/internal/pkg/geminicli"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/googleapi"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
 	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
 	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
 
@@ -410,10 +410,19 @@ func (s *GeminiMessagesCompatService) GetAntigravityGatewayService() *Antigravit
 }
 
 func (s *GeminiMessagesCompatService) getSchedulableAccount(ctx context.Context, accountID int64) (*Account, error) {
+	var (
+		account *Account
+		err     error
+	)
 	if s.schedulerSnapshot != nil {
-		return s.schedulerSnapshot.GetAccount(ctx, accountID)
+		account, err = s.schedulerSnapshot.GetAccount(ctx, accountID)
+	} else {
+		account, err = s.accountRepo.GetByID(ctx, accountID)
+	}
+	if err != nil {
+		return nil, err
 	}
-	return s.accountRepo.GetByID(ctx, accountID)
+	return restrictRequestAccount(ctx, account), nil
 }
 
 func (s *GeminiMessagesCompatService) hydrateSelectedAccount(ctx context.Context, account *Account) (*Account, error) {
@@ -433,7 +442,7 @@ func (s *GeminiMessagesCompatService) hydrateSelectedAccount(ctx context.Context
 func (s *GeminiMessagesCompatService) listSchedulableAccountsOnce(ctx context.Context, groupID *int64, platform string, hasForcePlatform bool) ([]Account, error) {
 	if s.schedulerSnapshot != nil {
 		accounts, _, err := s.schedulerSnapshot.ListSchedulableAccounts(ctx, groupID, platform, hasForcePlatform)
-		return accounts, err
+		return applyRequestAccountFilters(ctx, accounts), err
 	}
 
 	useMixedScheduling := platform == PlatformGemini && !hasForcePlatform
@@ -442,13 +451,21 @@ func (s *GeminiMessagesCompatService) listSchedulableAccountsOnce(ctx context.Co
 		queryPlatforms = []string{platform, PlatformAntigravity}
 	}
 
+	var (
+		accounts []Account
+		err      error
+	)
 	if groupID != nil {
-		return s.accountRepo.ListSchedulableByGroupIDAndPlatforms(ctx, *groupID, queryPlatforms)
+		accounts, err = s.accountRepo.ListSchedulableByGroupIDAndPlatforms(ctx, *groupID, queryPlatforms)
+	} else if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
+		accounts, err = s.accountRepo.ListSchedulableByPlatforms(ctx, queryPlatforms)
+	} else {
+		accounts, err = s.accountRepo.ListSchedulableUngroupedByPlatforms(ctx, queryPlatforms)
 	}
-	if s.cfg != nil && s.cfg.RunMode == config.RunModeSimple {
-		return s.accountRepo.ListSchedulableByPlatforms(ctx, queryPlatforms)
+	if err != nil {
+		return nil, err
 	}
-	return s.accountRepo.ListSchedulableUngroupedByPlatforms(ctx, queryPlatforms)
+	return applyRequestAccountFilters(ctx, accounts), nil
 }
 
 func (s *GeminiMessagesCompatService) validateUpstreamBaseURL(raw string) (string, error) {
@@ -772,7 +789,7 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 			c.Set(OpsUpstreamRequestBodyKey, string(body))
 		}
 
-		resp, err = s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+		resp, err = s.httpUpstream.Do(withAccountTransport(upstreamReq, account), proxyURL, account.ID, account.Concurrency)
 		if err != nil {
 			safeErr := sanitizeUpstreamErrorMessage(err.Error())
 			appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
@@ -1042,8 +1059,12 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 
 	var usage *ClaudeUsage
 	var firstTokenMs *int
+	stopIdleTimeout := guardStreamIdleTimeout(ctx, resp, s.cfg, s.rateLimitService, account, originalModel)
+	defer stopIdleTimeout()
 	if req.Stream {
+		stopKeepalive := startStreamKeepalive(c, s.cfg)
 		streamRes, err := s.handleStreamingResponse(c, resp, startTime, originalModel)
+		stopKeepalive()
 		if err != nil {
 			return nil, err
 		}
@@ -1051,7 +1072,17 @@ func (s *GeminiMessagesCompatService) Forward(ctx context.Context, c *gin.Contex
 		firstTokenMs = streamRes.firstTokenMs
 	} else {
 		if useUpstreamStream {
-			collected, usageObj, err := collectGeminiSSE(resp.Body, true)
+			collected, usageObj, err := collectGeminiSSE(resp.Body, true, streamMaxLineSize(s.cfg))
+			if errors.Is(err, ErrStreamIdleTimeout) {
+				return nil, handleBufferedStreamIdleTimeout(c, s.cfg, func(c *gin.Context, status int, errType, message string) {
+					_ = s.writeClaudeError(c, status, errType, message)
+				})
+			}
+			if errors.Is(err, sse.ErrLineTooLong) {
+				return nil, handleBufferedLineTooLong(c, streamMaxLineSize(s.cfg), "", func(c *gin.Context, status int, errType, message string) {
+					_ = s.writeClaudeError(c, status, errType, message)
+				})
+			}
 			if err != nil {
 				return nil, s.writeClaudeError(c, http.StatusBadGateway, "upstream_error", "Failed to read upstream stream")
 			}
@@ -1144,7 +1175,11 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 		useUpstreamStream = true
 		upstreamAction = "streamGenerateContent"
 	}
-	forceAIStudio := action == "countTokens"
+	// Code Assist（OAuth + project_id）没有可用的 countTokens 端点，其令牌也缺少 AI Studio 权限范围，
+	// 直接本地估算，避免每次预检都浪费一次必然失败的上游请求。
+	if action == "countTokens" && account.Type == AccountTypeOAuth && strings.TrimSpace(account.GetCredential("project_id")) != "" {
+		return writeGeminiCountTokensEstimate(c, body, "", originalModel, mappedModel, startTime), nil
+	}
 
 	var requestIDHeader string
 	var buildReq func(ctx context.Context) (*http.Request, string, error)
@@ -1193,7 +1228,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 			// Two modes for OAuth:
 			// 1. With project_id -> Code Assist API (wrapped request)
 			// 2. Without project_id -> AI Studio API (direct OAuth, like API key but with Bearer token)
-			if projectID != "" && !forceAIStudio {
+			if projectID != "" {
 				// Mode 1: Code Assist API
 				baseURL, err := s.validateUpstreamBaseURL(geminicli.GeminiCliBaseURL)
 				if err != nil {
@@ -1297,7 +1332,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 			c.Set(OpsUpstreamRequestBodyKey, string(body))
 		}
 
-		resp, err = s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+		resp, err = s.httpUpstream.Do(withAccountTransport(upstreamReq, account), proxyURL, account.ID, account.Concurrency)
 		if err != nil {
 			safeErr := sanitizeUpstreamErrorMessage(err.Error())
 			appendOpsUpstreamError(c, OpsUpstreamErrorEvent{
@@ -1314,17 +1349,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 				continue
 			}
 			if action == "countTokens" {
-				estimated := estimateGeminiCountTokens(body)
-				c.JSON(http.StatusOK, map[string]any{"totalTokens": estimated})
-				return &ForwardResult{
-					RequestID:     "",
-					Usage:         ClaudeUsage{},
-					Model:         originalModel,
-					UpstreamModel: mappedModel,
-					Stream:        false,
-					Duration:      time.Since(startTime),
-					FirstTokenMs:  nil,
-				}, nil
+				return writeGeminiCountTokensEstimate(c, body, "", originalModel, mappedModel, startTime), nil
 			}
 			setOpsUpstreamError(c, 0, safeErr, "")
 			return nil, s.writeGoogleError(c, http.StatusBadGateway, "Upstream request failed after retries: "+safeErr)
@@ -1384,17 +1409,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 				continue
 			}
 			if action == "countTokens" {
-				estimated := estimateGeminiCountTokens(body)
-				c.JSON(http.StatusOK, map[string]any{"totalTokens": estimated})
-				return &ForwardResult{
-					RequestID:     "",
-					Usage:         ClaudeUsage{},
-					Model:         originalModel,
-					UpstreamModel: mappedModel,
-					Stream:        false,
-					Duration:      time.Since(startTime),
-					FirstTokenMs:  nil,
-				}, nil
+				return writeGeminiCountTokensEstimate(c, body, "", originalModel, mappedModel, startTime), nil
 			}
 			// Final attempt: surface the upstream error body (passed through below) instead of a generic retry error.
 			resp = &http.Response{
@@ -1425,17 +1440,7 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 		// This avoids Gemini SDKs failing hard during preflight token counting.
 		// Checked before error policy so it always works regardless of custom error codes.
 		if action == "countTokens" && isOAuth && isGeminiInsufficientScope(resp.Header, respBody) {
-			estimated := estimateGeminiCountTokens(body)
-			c.JSON(http.StatusOK, map[string]any{"totalTokens": estimated})
-			return &ForwardResult{
-				RequestID:     requestID,
-				Usage:         ClaudeUsage{},
-				Model:         originalModel,
-				UpstreamModel: mappedModel,
-				Stream:        false,
-				Duration:      time.Since(startTime),
-				FirstTokenMs:  nil,
-			}, nil
+			return writeGeminiCountTokensEstimate(c, body, requestID, originalModel, mappedModel, startTime), nil
 		}
 
 		// 统一错误策略：自定义错误码 + 临时不可调度
@@ -1569,8 +1574,12 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 	var usage *ClaudeUsage
 	var firstTokenMs *int
 
+	stopIdleTimeout := guardStreamIdleTimeout(ctx, resp, s.cfg, s.rateLimitService, account, originalModel)
+	defer stopIdleTimeout()
 	if stream {
+		stopKeepalive := startStreamKeepalive(c, s.cfg)
 		streamRes, err := s.handleNativeStreamingResponse(c, resp, startTime, isOAuth)
+		stopKeepalive()
 		if err != nil {
 			return nil, err
 		}
@@ -1578,7 +1587,17 @@ func (s *GeminiMessagesCompatService) ForwardNative(ctx context.Context, c *gin.
 		firstTokenMs = streamRes.firstTokenMs
 	} else {
 		if useUpstreamStream {
-			collected, usageObj, err := collectGeminiSSE(resp.Body, isOAuth)
+			collected, usageObj, err := collectGeminiSSE(resp.Body, isOAuth, streamMaxLineSize(s.cfg))
+			if errors.Is(err, ErrStreamIdleTimeout) {
+				return nil, handleBufferedStreamIdleTimeout(c, s.cfg, func(c *gin.Context, status int, _, message string) {
+					_ = s.writeGoogleError(c, status, message)
+				})
+			}
+			if errors.Is(err, sse.ErrLineTooLong) {
+				return nil, handleBufferedLineTooLong(c, streamMaxLineSize(s.cfg), "", func(c *gin.Context, status int, _, message string) {
+					_ = s.writeGoogleError(c, status, message)
+				})
+			}
 			if err != nil {
 				return nil, s.writeGoogleError(c, http.StatusBadGateway, "Failed to read upstream stream")
 			}
@@ -2001,9 +2020,16 @@ func (s *GeminiMessagesCompatService) handleStreamingResponse(c *gin.Context, re
 	openToolName := ""
 	seenToolJSON := ""
 
-	reader := bufio.NewReader(resp.Body)
+	maxLineSize := streamMaxLineSize(s.cfg)
+	reader := sse.NewReader(resp.Body, maxLineSize)
 	for {
-		line, err := reader.ReadString('\n')
+		line, err := reader.ReadLine()
+		if errors.Is(err, ErrStreamIdleTimeout) {
+			return nil, handleStreamingIdleTimeout(c, s.cfg, streamErrorEventAnthropic)
+		}
+		if errors.Is(err, sse.ErrLineTooLong) {
+			return nil, handleStreamingLineTooLong(c, streamErrorEventAnthropic, maxLineSize, messageID)
+		}
 		if err != nil && !errors.Is(err, io.EOF) {
 			return nil, fmt.Errorf("stream read error: %w", err)
 		}
@@ -2252,8 +2278,8 @@ func unwrapIfNeeded(isOAuth bool, raw []byte) []byte {
 	return inner
 }
 
-func collectGeminiSSE(body io.Reader, isOAuth bool) (map[string]any, *ClaudeUsage, error) {
-	reader := bufio.NewReader(body)
+func collectGeminiSSE(body io.Reader, isOAuth bool, maxLineSize int) (map[string]any, *ClaudeUsage, error) {
+	reader := sse.NewReader(body, maxLineSize)
 
 	var last map[string]any
 	var lastWithParts map[string]any
@@ -2261,9 +2287,8 @@ func collectGeminiSSE(body io.Reader, isOAuth bool) (map[string]any, *ClaudeUsag
 	usage := &ClaudeUsage{}
 
 	for {
-		line, err := reader.ReadString('\n')
-		if len(line) > 0 {
-			trimmed := strings.TrimRight(line, "\r\n")
+		trimmed, err := reader.ReadLine()
+		if len(trimmed) > 0 {
 			if strings.HasPrefix(trimmed, "data:") {
 				payload := strings.TrimSpace(strings.TrimPrefix(trimmed, "data:"))
 				switch payload {
@@ -2414,23 +2439,56 @@ func isGeminiInsufficientScope(headers http.Header, body []byte) bool {
 	return strings.Contains(lower, "insufficient authentication scopes") || strings.Contains(lower, "access_token_scope_insufficient")
 }
 
+// writeGeminiCountTokensEstimate 以本地估算值响应 countTokens（上游不可用或不支持时的兜底）
+func writeGeminiCountTokensEstimate(c *gin.Context, body []byte, requestID, originalModel, mappedModel string, startTime time.Time) *ForwardResult {
+	c.JSON(http.StatusOK, map[string]any{"totalTokens": estimateGeminiCountTokens(body)})
+	return &ForwardResult{
+		RequestID:     requestID,
+		Usage:         ClaudeUsage{},
+		Model:         originalModel,
+		UpstreamModel: mappedModel,
+		Stream:        false,
+		Duration:      time.Since(startTime),
+		FirstTokenMs:  nil,
+	}
+}
+
 func estimateGeminiCountTokens(reqBody []byte) int {
+	root := gjson.ParseBytes(reqBody)
+	// countTokens 也接受 {"generateContentRequest": {...}} 形式（含 systemInstruction/tools）
+	if wrapped := root.Get("generateContentRequest"); wrapped.IsObject() {
+		root = wrapped
+	}
+
 	total := 0
+	countParts := func(parts gjson.Result) {
+		parts.ForEach(func(_, part gjson.Result) bool {
+			if t := strings.TrimSpace(part.Get("text").String()); t != "" {
+				total += estimateTokensForText(t)
+			}
+			// 工具调用与工具结果按其 JSON 文本估算
+			for _, key := range []string{"functionCall", "functionResponse"} {
+				if v := part.Get(key); v.Exists() {
+					total += estimateTokensForText(v.Raw)
+				}
+			}
+			return true
+		})
+	}
 
-	// systemInstruction.parts[].text
-	gjson.GetBytes(reqBody, "systemInstruction.parts").ForEach(func(_, part gjson.Result) bool {
-		if t := strings.TrimSpace(part.Get("text").String()); t != "" {
-			total += estimateTokensForText(t)
-		}
+	// systemInstruction.parts[]
+	countParts(root.Get("systemInstruction.parts"))
+
+	// contents[].parts[]
+	root.Get("contents").ForEach(func(_, content gjson.Result) bool {
+		countParts(content.Get("parts"))
 		return true
 	})
 
-	// contents[].parts[].text
-	gjson.GetBytes(reqBody, "contents").ForEach(func(_, content gjson.Result) bool {
-		content.Get("parts").ForEach(func(_, part gjson.Result) bool {
-			if t := strings.TrimSpace(part.Get("text").String()); t != "" {
-				total += estimateTokensForText(t)
-			}
+	// tools[].functionDeclarations[]
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		tool.Get("functionDeclarations").ForEach(func(_, decl gjson.Result) bool {
+			total += estimateTokensForText(decl.Raw)
 			return true
 		})
 		return true
@@ -2495,7 +2553,7 @@ func (s *GeminiMessagesCompatService) handleNativeNonStreamingResponse(c *gin.Co
 		}
 	}
 
-	responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+	responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 
 	contentType := resp.Header.Get("Content-Type")
 	if contentType == "" {
@@ -2521,7 +2579,7 @@ func (s *GeminiMessagesCompatService) handleNativeStreamingResponse(c *gin.Conte
 	}
 
 	if s.responseHeaderFilter != nil {
-		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 	}
 
 	c.Status(resp.StatusCode)
@@ -2540,14 +2598,16 @@ func (s *GeminiMessagesCompatService) handleNativeStreamingResponse(c *gin.Conte
 		return nil, errors.New("streaming not supported")
 	}
 
-	reader := bufio.NewReader(resp.Body)
+	maxLineSize := streamMaxLineSize(s.cfg)
+	reader := sse.NewReader(resp.Body, maxLineSize)
 	usage := &ClaudeUsage{}
 	var firstTokenMs *int
 
 	for {
-		line, err := reader.ReadString('\n')
-		if len(line) > 0 {
-			trimmed := strings.TrimRight(line, "\r\n")
+		trimmed, err := reader.ReadLine()
+		if err == nil {
+			// 读取时已去掉行尾，透传时统一补回 \n
+			line := trimmed + "\n"
 			if strings.HasPrefix(trimmed, "data:") {
 				payload := strings.TrimSpace(strings.TrimPrefix(trimmed, "data:"))
 				// Keepalive / done markers
@@ -2596,6 +2656,12 @@ func (s *GeminiMessagesCompatService) handleNativeStreamingResponse(c *gin.Conte
 		if errors.Is(err, io.EOF) {
 			break
 		}
+		if errors.Is(err, ErrStreamIdleTimeout) {
+			return nil, handleStreamingIdleTimeout(c, s.cfg, streamErrorEventGemini)
+		}
+		if errors.Is(err, sse.ErrLineTooLong) {
+			return nil, handleStreamingLineTooLong(c, streamErrorEventGemini, maxLineSize, "")
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -2654,7 +2720,7 @@ func (s *GeminiMessagesCompatService) ForwardAIStudioGET(ctx context.Context, ac
 		return nil, fmt.Errorf("unsupported account type: %s", account.Type)
 	}
 
-	resp, err := s.httpUpstream.Do(req, proxyURL, account.ID, account.Concurrency)
+	resp, err := s.httpUpstream.Do(withAccountTransport(req, account), proxyURL, account.ID, account.Concurrency)
 	if err != nil {
 		return nil, err
 	}
@@ -308,6 +308,33 @@ func TestGeminiMessagesCompatServiceForward_NormalizesWebSearchToolForAIStudio(t
 	require.False(t, hasFuncDecl)
 }
 
+func TestGeminiMessagesCompatServiceForwardNative_CodeAssistCountTokensEstimatesLocally(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/gemini/v1beta/models/gemini-2.5-pro:countTokens", nil)
+
+	httpStub := &geminiCompatHTTPUpstreamStub{}
+	svc := &GeminiMessagesCompatService{httpUpstream: httpStub, cfg: &config.Config{}}
+	account := &Account{
+		ID:          1,
+		Type:        AccountTypeOAuth,
+		Credentials: map[string]any{"project_id": "proj-1"},
+	}
+	body := []byte(`{"contents":[{"role":"user","parts":[{"text":"Hello, how are you?"}]}]}`)
+
+	result, err := svc.ForwardNative(context.Background(), c, account, "gemini-2.5-pro", "countTokens", false, body)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, 0, httpStub.calls)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]int
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, estimateGeminiCountTokens(body), resp["totalTokens"])
+	require.Positive(t, resp["totalTokens"])
+}
+
 func TestConvertClaudeMessagesToGeminiGenerateContent_AddsThoughtSignatureForToolUse(t *testing.T) {
 	claudeReq := map[string]any{
 		"model":      "claude-haiku-4-5-20251001",
@@ -601,6 +628,16 @@ func TestEstimateGeminiCountTokens(t *testing.T) {
 			wantGt0:   false,
 			wantExact: intPtr(0),
 		},
+		{
+			name:    "generateContentRequest 包装",
+			input:   `{"generateContentRequest":{"model":"models/gemini-2.5-pro","contents":[{"parts":[{"text":"Hello"}]}]}}`,
+			wantGt0: true,
+		},
+		{
+			name:    "工具调用与工具声明",
+			input:   `{"contents":[{"parts":[{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}}]}],"tools":[{"functionDeclarations":[{"name":"get_weather"}]}]}`,
+			wantGt0: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -9,6 +9,14 @@ import (
 
 type OpenAIMessagesDispatchModelConfig = domain.OpenAIMessagesDispatchModelConfig
 
+type RequestParamOverrides = domain.RequestParamOverrides
+
+// GroupResponseHeaders 分组自定义响应头策略（见 ApplyGroupStaticResponseHeaders）
+type GroupResponseHeaders = domain.GroupResponseHeaders
+
+// AccountTagSelector 账号标签选择器（见 Account.MatchesTagSelector）
+type AccountTagSelector = domain.AccountTagSelector
+
 type Group struct {
 	ID             int64
 	Name           string
@@ -63,6 +71,34 @@ type Group struct {
 	// 一旦设置即接管该分组用户的限流（覆盖用户级 rpm_limit），可被 user-group rpm_override 进一步覆盖。
 	RPMLimit int
 
+	// Priority 并发等待队列优先级（high/normal/low）。槽位紧张时高优先级等待者先获得槽位。
+	Priority string
+
+	// RequestParamOverrides 请求参数策略，转发前注入默认值或裁剪上限（见 ApplyRequestParamOverrides）。
+	RequestParamOverrides RequestParamOverrides
+
+	// SystemPrompt 分组系统提示词，为空表示不注入；SystemPromptMode 为注入位置（prepend/append，见 ApplyGroupSystemPrompt）。
+	SystemPrompt     string
+	SystemPromptMode string
+
+	// PIIRedactionMode 敏感信息过滤策略：空表示关闭，mask 脱敏后转发，reject 拒绝请求（见 PIIRedactor）。
+	PIIRedactionMode string
+
+	// ModerationEnabled 转发前是否执行内容审核（API Key 可通过 ModerationMode 单独覆盖，见 ContentModerator）。
+	ModerationEnabled bool
+
+	// MaxBodySize 请求体最大字节数，0 表示使用端点类别默认值（API Key 可单独覆盖，见 ResolveRequestBodyLimit）。
+	MaxBodySize int64
+
+	// ResponseHeaders 自定义响应头：注入静态响应头，并额外透传指定的上游响应头。
+	ResponseHeaders GroupResponseHeaders
+
+	// OrganizationID 所属组织；nil 表示平台分组。组织分组由组织管理员分配给本组织成员。
+	OrganizationID *int64
+
+	// AccountTagSelector 账号标签选择器：仅调度带有全部 require 标签且不含任一 exclude 标签的账号。
+	AccountTagSelector AccountTagSelector
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
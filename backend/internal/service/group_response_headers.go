@@ -0,0 +1,111 @@
+package service
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/http/httpguts"
+)
+
+// ErrInvalidGroupResponseHeaders 分组自定义响应头配置非法
+var ErrInvalidGroupResponseHeaders = infraerrors.BadRequest("INVALID_GROUP_RESPONSE_HEADERS", "response_headers: header names must be valid tokens, values must not contain control characters, and hop-by-hop or framing headers are not allowed")
+
+// groupResponseHeadersMaxEntries 静态头与透传头各自的条目上限
+const groupResponseHeadersMaxEntries = 32
+
+// reservedGroupResponseHeaders 由 HTTP 库或网关自身管理的头部，不允许分组注入或透传
+var reservedGroupResponseHeaders = map[string]struct{}{
+	"content-length":    {},
+	"transfer-encoding": {},
+	"connection":        {},
+	"keep-alive":        {},
+	"upgrade":           {},
+	"trailer":           {},
+	"te":                {},
+	"content-encoding":  {},
+	"content-type":      {},
+	"set-cookie":        {},
+}
+
+func isReservedGroupResponseHeader(name string) bool {
+	_, ok := reservedGroupResponseHeaders[strings.ToLower(name)]
+	return ok
+}
+
+// ValidateGroupResponseHeaders 校验并规范化分组自定义响应头：
+//   - 静态头名称按 canonical 形式存储，值去除首尾空白，空值条目丢弃
+//   - 透传头统一小写并去重，以 "*" 结尾表示前缀匹配（前缀本身不能为空）
+func ValidateGroupResponseHeaders(h GroupResponseHeaders) (GroupResponseHeaders, error) {
+	var out GroupResponseHeaders
+	if len(h.Static) > groupResponseHeadersMaxEntries || len(h.ExposeUpstream) > groupResponseHeadersMaxEntries {
+		return out, ErrInvalidGroupResponseHeaders
+	}
+
+	if len(h.Static) > 0 {
+		out.Static = make(map[string]string, len(h.Static))
+		for name, value := range h.Static {
+			name = strings.TrimSpace(name)
+			value = strings.TrimSpace(value)
+			if name == "" || value == "" {
+				continue
+			}
+			if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) || isReservedGroupResponseHeader(name) {
+				return GroupResponseHeaders{}, ErrInvalidGroupResponseHeaders
+			}
+			out.Static[http.CanonicalHeaderKey(name)] = value
+		}
+		if len(out.Static) == 0 {
+			out.Static = nil
+		}
+	}
+
+	seen := make(map[string]struct{}, len(h.ExposeUpstream))
+	for _, pattern := range h.ExposeUpstream {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		name := strings.TrimSuffix(pattern, "*")
+		if name == "" || !httpguts.ValidHeaderFieldName(name) || strings.Contains(name, "*") || isReservedGroupResponseHeader(name) {
+			return GroupResponseHeaders{}, ErrInvalidGroupResponseHeaders
+		}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		seen[pattern] = struct{}{}
+		out.ExposeUpstream = append(out.ExposeUpstream, pattern)
+	}
+	sort.Strings(out.ExposeUpstream)
+	return out, nil
+}
+
+// ApplyGroupStaticResponseHeaders 将分组配置的静态响应头写入响应（覆盖同名头）。
+func ApplyGroupStaticResponseHeaders(h http.Header, group *Group) {
+	if h == nil || group == nil || len(group.ResponseHeaders.Static) == 0 {
+		return
+	}
+	for name, value := range group.ResponseHeaders.Static {
+		h.Set(name, value)
+	}
+}
+
+// groupResponseHeaderFilter 返回当前请求实际使用的上游响应头过滤器：
+// 分组配置了额外透传的上游头时在全局过滤器基础上放行，否则直接返回全局过滤器。
+func groupResponseHeaderFilter(c *gin.Context, base *responseheaders.CompiledHeaderFilter) *responseheaders.CompiledHeaderFilter {
+	if c == nil {
+		return base
+	}
+	v, exists := c.Get("api_key")
+	if !exists {
+		return base
+	}
+	apiKey, ok := v.(*APIKey)
+	if !ok || apiKey == nil || apiKey.Group == nil || len(apiKey.Group.ResponseHeaders.ExposeUpstream) == 0 {
+		return base
+	}
+	return base.WithExtraAllowed(apiKey.Group.ResponseHeaders.ExposeUpstream)
+}
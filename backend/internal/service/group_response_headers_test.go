@@ -0,0 +1,56 @@
+//go:build unit
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateGroupResponseHeaders_Normalizes(t *testing.T) {
+	out, err := ValidateGroupResponseHeaders(GroupResponseHeaders{
+		Static:         map[string]string{"access-control-allow-origin": " * ", "x-empty": " "},
+		ExposeUpstream: []string{" Anthropic-Ratelimit-* ", "x-provider", "X-Provider", ""},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"Access-Control-Allow-Origin": "*"}, out.Static)
+	require.Equal(t, []string{"anthropic-ratelimit-*", "x-provider"}, out.ExposeUpstream)
+}
+
+func TestValidateGroupResponseHeaders_RejectsInvalid(t *testing.T) {
+	cases := []GroupResponseHeaders{
+		{Static: map[string]string{"Content-Length": "1"}},
+		{Static: map[string]string{"Bad Header": "x"}},
+		{Static: map[string]string{"X-Ok": "a\r\nb"}},
+		{ExposeUpstream: []string{"*"}},
+		{ExposeUpstream: []string{"transfer-encoding"}},
+		{ExposeUpstream: []string{"x-*-y"}},
+	}
+	for _, tc := range cases {
+		_, err := ValidateGroupResponseHeaders(tc)
+		require.ErrorIs(t, err, ErrInvalidGroupResponseHeaders)
+	}
+}
+
+func TestGroupResponseHeaderFilter_ExposesConfiguredUpstreamHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	base := responseheaders.CompileHeaderFilter(config.ResponseHeaderConfig{})
+
+	src := http.Header{}
+	src.Set("Anthropic-Ratelimit-Tokens-Remaining", "100")
+
+	// 未配置时使用全局过滤器
+	require.Same(t, base, groupResponseHeaderFilter(c, base))
+	require.Empty(t, responseheaders.FilterHeaders(src, base).Get("Anthropic-Ratelimit-Tokens-Remaining"))
+
+	c.Set("api_key", &APIKey{Group: &Group{ResponseHeaders: GroupResponseHeaders{ExposeUpstream: []string{"anthropic-ratelimit-*"}}}})
+	filter := groupResponseHeaderFilter(c, base)
+	require.Equal(t, "100", responseheaders.FilterHeaders(src, filter).Get("Anthropic-Ratelimit-Tokens-Remaining"))
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ErrInvalidSystemPromptMode 分组系统提示词注入位置取值非法
+var ErrInvalidSystemPromptMode = infraerrors.BadRequest("INVALID_SYSTEM_PROMPT_MODE", "system_prompt_mode must be one of prepend, append")
+
+// IsValidSystemPromptMode 判断是否为合法的分组系统提示词注入位置
+func IsValidSystemPromptMode(mode string) bool {
+	return mode == SystemPromptModePrepend || mode == SystemPromptModeAppend
+}
+
+// ApplyGroupSystemPrompt 将分组系统提示词注入请求体的系统指令：
+//   - Anthropic：system 统一为 text block 数组，prepend 时插在 Claude Code 身份提示词之后
+//   - Chat Completions：插入一条 role=system 消息（prepend 位于 messages[0]，append 位于开头连续 system/developer 消息之后）
+//   - Responses：与 instructions 以空行拼接
+//   - Gemini：在 systemInstruction.parts 首/尾追加 text part
+//
+// 与 ApplyRequestParamOverrides 一样在解析与格式转换前调用，转换链路（CC/Responses → Anthropic、
+// Anthropic → Responses）会把注入结果映射到目标格式的系统指令。未配置或请求体非法 JSON 时原样返回。
+func ApplyGroupSystemPrompt(body []byte, group *Group, format RequestParamFormat) []byte {
+	if group == nil || strings.TrimSpace(group.SystemPrompt) == "" || !gjson.ValidBytes(body) {
+		return body
+	}
+	prompt := group.SystemPrompt
+	appendMode := group.SystemPromptMode == SystemPromptModeAppend
+
+	var out []byte
+	var err error
+	switch format {
+	case RequestParamFormatAnthropic:
+		out, err = injectAnthropicGroupSystemPrompt(body, prompt, appendMode)
+	case RequestParamFormatChatCompletions:
+		out, err = injectChatGroupSystemPrompt(body, prompt, appendMode)
+	case RequestParamFormatResponses:
+		out, err = injectResponsesGroupSystemPrompt(body, prompt, appendMode)
+	case RequestParamFormatGemini:
+		out, err = injectGeminiGroupSystemPrompt(body, prompt, appendMode)
+	default:
+		return body
+	}
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+func injectAnthropicGroupSystemPrompt(body []byte, prompt string, appendMode bool) ([]byte, error) {
+	var blocks []string
+	system := gjson.GetBytes(body, "system")
+	switch {
+	case !system.Exists() || system.Type == gjson.Null:
+	case system.Type == gjson.String:
+		if system.String() != "" {
+			block, err := marshalAnthropicSystemTextBlock(system.String(), false)
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, string(block))
+		}
+	case system.IsArray():
+		for _, item := range system.Array() {
+			blocks = append(blocks, item.Raw)
+		}
+	default:
+		return body, nil
+	}
+
+	block, err := marshalAnthropicSystemTextBlock(prompt, false)
+	if err != nil {
+		return nil, err
+	}
+	insertAt := len(blocks)
+	if !appendMode {
+		// Claude Code 身份提示词需保持在首位（客户端识别与 OAuth 伪装依赖），分组提示词插在其后
+		insertAt = 0
+		for insertAt < len(blocks) && hasClaudeCodePrefix(gjson.Get(blocks[insertAt], "text").String()) {
+			insertAt++
+		}
+	}
+	return sjson.SetRawBytes(body, "system", []byte(spliceRawJSONArray(blocks, insertAt, string(block))))
+}
+
+func injectChatGroupSystemPrompt(body []byte, prompt string, appendMode bool) ([]byte, error) {
+	messages := gjson.GetBytes(body, "messages")
+	if !messages.IsArray() {
+		return body, nil
+	}
+	var items []string
+	for _, item := range messages.Array() {
+		items = append(items, item.Raw)
+	}
+	message, err := json.Marshal(map[string]string{"role": "system", "content": prompt})
+	if err != nil {
+		return nil, err
+	}
+	insertAt := 0
+	if appendMode {
+		for insertAt < len(items) {
+			role := gjson.Get(items[insertAt], "role").String()
+			if role != "system" && role != "developer" {
+				break
+			}
+			insertAt++
+		}
+	}
+	return sjson.SetRawBytes(body, "messages", []byte(spliceRawJSONArray(items, insertAt, string(message))))
+}
+
+func injectResponsesGroupSystemPrompt(body []byte, prompt string, appendMode bool) ([]byte, error) {
+	existing := gjson.GetBytes(body, "instructions").String()
+	switch {
+	case strings.TrimSpace(existing) == "":
+		return sjson.SetBytes(body, "instructions", prompt)
+	case appendMode:
+		return sjson.SetBytes(body, "instructions", existing+"\n\n"+prompt)
+	default:
+		return sjson.SetBytes(body, "instructions", prompt+"\n\n"+existing)
+	}
+}
+
+func injectGeminiGroupSystemPrompt(body []byte, prompt string, appendMode bool) ([]byte, error) {
+	key := "systemInstruction"
+	if gjson.GetBytes(body, "system_instruction").Exists() {
+		key = "system_instruction"
+	}
+	var parts []string
+	for _, item := range gjson.GetBytes(body, key+".parts").Array() {
+		parts = append(parts, item.Raw)
+	}
+	part, err := json.Marshal(map[string]string{"text": prompt})
+	if err != nil {
+		return nil, err
+	}
+	insertAt := 0
+	if appendMode {
+		insertAt = len(parts)
+	}
+	return sjson.SetRawBytes(body, key+".parts", []byte(spliceRawJSONArray(parts, insertAt, string(part))))
+}
+
+// spliceRawJSONArray 在 idx 处插入 raw 并拼接为 JSON 数组
+func spliceRawJSONArray(items []string, idx int, raw string) string {
+	out := make([]string, 0, len(items)+1)
+	out = append(out, items[:idx]...)
+	out = append(out, raw)
+	out = append(out, items[idx:]...)
+	return "[" + strings.Join(out, ",") + "]"
+}
@@ -0,0 +1,57 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyGroupSystemPrompt_Anthropic(t *testing.T) {
+	group := &Group{SystemPrompt: "Follow org policy.", SystemPromptMode: SystemPromptModePrepend}
+
+	out := ApplyGroupSystemPrompt([]byte(`{"system":"Be brief.","messages":[]}`), group, RequestParamFormatAnthropic)
+	require.Equal(t, "Follow org policy.", gjson.GetBytes(out, "system.0.text").String())
+	require.Equal(t, "Be brief.", gjson.GetBytes(out, "system.1.text").String())
+
+	// Claude Code 身份提示词保持在首位
+	body := []byte(`{"system":[{"type":"text","text":"` + claudeCodeSystemPrompt + `"},{"type":"text","text":"ctx","cache_control":{"type":"ephemeral"}}]}`)
+	out = ApplyGroupSystemPrompt(body, group, RequestParamFormatAnthropic)
+	require.Equal(t, claudeCodeSystemPrompt, gjson.GetBytes(out, "system.0.text").String())
+	require.Equal(t, "Follow org policy.", gjson.GetBytes(out, "system.1.text").String())
+	require.Equal(t, "ephemeral", gjson.GetBytes(out, "system.2.cache_control.type").String())
+
+	group.SystemPromptMode = SystemPromptModeAppend
+	out = ApplyGroupSystemPrompt([]byte(`{"messages":[]}`), group, RequestParamFormatAnthropic)
+	require.Equal(t, int64(1), gjson.GetBytes(out, "system.#").Int())
+	out = ApplyGroupSystemPrompt(body, group, RequestParamFormatAnthropic)
+	require.Equal(t, "Follow org policy.", gjson.GetBytes(out, "system.2.text").String())
+}
+
+func TestApplyGroupSystemPrompt_OtherFormats(t *testing.T) {
+	prepend := &Group{SystemPrompt: "P", SystemPromptMode: SystemPromptModePrepend}
+	appendGroup := &Group{SystemPrompt: "P", SystemPromptMode: SystemPromptModeAppend}
+
+	chat := []byte(`{"messages":[{"role":"system","content":"S"},{"role":"user","content":"hi"}]}`)
+	out := ApplyGroupSystemPrompt(chat, prepend, RequestParamFormatChatCompletions)
+	require.Equal(t, "P", gjson.GetBytes(out, "messages.0.content").String())
+	require.Equal(t, "S", gjson.GetBytes(out, "messages.1.content").String())
+	out = ApplyGroupSystemPrompt(chat, appendGroup, RequestParamFormatChatCompletions)
+	require.Equal(t, "P", gjson.GetBytes(out, "messages.1.content").String())
+	require.Equal(t, "user", gjson.GetBytes(out, "messages.2.role").String())
+
+	out = ApplyGroupSystemPrompt([]byte(`{"instructions":"I","input":"hi"}`), prepend, RequestParamFormatResponses)
+	require.Equal(t, "P\n\nI", gjson.GetBytes(out, "instructions").String())
+	out = ApplyGroupSystemPrompt([]byte(`{"input":"hi"}`), appendGroup, RequestParamFormatResponses)
+	require.Equal(t, "P", gjson.GetBytes(out, "instructions").String())
+
+	out = ApplyGroupSystemPrompt([]byte(`{"system_instruction":{"parts":[{"text":"G"}]},"contents":[]}`), appendGroup, RequestParamFormatGemini)
+	require.Equal(t, "P", gjson.GetBytes(out, "system_instruction.parts.1.text").String())
+	out = ApplyGroupSystemPrompt([]byte(`{"contents":[]}`), prepend, RequestParamFormatGemini)
+	require.Equal(t, "P", gjson.GetBytes(out, "systemInstruction.parts.0.text").String())
+
+	// 未配置提示词时原样返回
+	require.Equal(t, chat, ApplyGroupSystemPrompt(chat, &Group{}, RequestParamFormatChatCompletions))
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	stddraw "image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// imageSniffableMediaTypes 可以通过文件头识别的图片类型，声明为这些类型时要求数据与声明一致
+var imageSniffableMediaTypes = map[string]struct{}{
+	"image/jpeg": {},
+	"image/png":  {},
+	"image/gif":  {},
+	"image/webp": {},
+}
+
+// imageDownscaleSteps 仍然超限时依次缩小的比例
+var imageDownscaleSteps = []float64{1, 0.75, 0.5, 0.35, 0.25}
+
+// imageJPEGQualityFloor 逐步降低 JPEG 质量的下限
+const imageJPEGQualityFloor = 40
+
+// inlineImageRef 请求体中一张内联图片的位置
+type inlineImageRef struct {
+	// location 用于错误提示的 JSON 路径
+	location string
+	// dataPath 指向 base64 数据（dataURL=true 时为完整的 data: URL）
+	dataPath string
+	// mediaTypePath 指向单独的媒体类型字段，dataURL=true 时为空
+	mediaTypePath string
+	mediaType     string
+	data          string
+	dataURL       bool
+}
+
+// ImageProcessor 在转发前处理请求体中的内联 base64 图片：
+// 校验媒体类型（并修正与实际数据不符的声明），将超过字节上限的图片缩放并重新压缩为 JPEG，
+// 避免超大图片触发上游请求体大小限制。URL 形式引用的图片不做处理。
+type ImageProcessor struct {
+	allowed      map[string]struct{}
+	allowedList  string
+	maxBytes     int
+	maxDimension int
+	quality      int
+}
+
+// NewImageProcessor 根据网关配置构建图片处理器；未配置允许类型与大小上限时不做任何处理。
+func NewImageProcessor(cfg *config.Config) *ImageProcessor {
+	p := &ImageProcessor{}
+	if cfg == nil {
+		return p
+	}
+	limit := cfg.Gateway.ImageLimit
+	if len(limit.AllowedMediaTypes) > 0 {
+		p.allowed = make(map[string]struct{}, len(limit.AllowedMediaTypes))
+		names := make([]string, 0, len(limit.AllowedMediaTypes))
+		for _, mt := range limit.AllowedMediaTypes {
+			mt = strings.ToLower(strings.TrimSpace(mt))
+			if mt == "" {
+				continue
+			}
+			p.allowed[mt] = struct{}{}
+			names = append(names, mt)
+		}
+		p.allowedList = strings.Join(names, ", ")
+	}
+	if limit.MaxBytes > 0 {
+		p.maxBytes = limit.MaxBytes
+		p.maxDimension = limit.MaxDimension
+		p.quality = limit.JPEGQuality
+		if p.quality <= 0 || p.quality > 100 {
+			p.quality = 85
+		}
+	}
+	return p
+}
+
+// Apply 校验并按需压缩请求体中的内联图片，返回处理后的请求体。
+// 图片类型不受支持、base64 非法或数据与声明类型不符时返回 400 错误。
+func (p *ImageProcessor) Apply(body []byte, format RequestParamFormat) ([]byte, error) {
+	if p == nil || (len(p.allowed) == 0 && p.maxBytes <= 0) {
+		return body, nil
+	}
+	if !bytes.Contains(body, []byte("base64")) && !bytes.Contains(body, []byte("inlineData")) && !bytes.Contains(body, []byte("inline_data")) {
+		return body, nil
+	}
+	if !gjson.ValidBytes(body) {
+		return body, nil
+	}
+
+	downscaled := 0
+	for _, ref := range collectInlineImages(body, format) {
+		mediaType := strings.ToLower(strings.TrimSpace(ref.mediaType))
+		sniffed, err := sniffInlineImage(ref.data)
+		if err != nil {
+			return nil, infraerrors.BadRequest("INVALID_IMAGE_DATA", fmt.Sprintf("image at %s is not valid base64 data", ref.location))
+		}
+		// 声明类型与实际数据不一致时以实际数据为准（上游会直接拒绝不一致的请求）
+		if strings.HasPrefix(sniffed, "image/") && sniffed != mediaType {
+			mediaType = sniffed
+		} else if _, sniffable := imageSniffableMediaTypes[mediaType]; sniffable && !strings.HasPrefix(sniffed, "image/") {
+			return nil, infraerrors.BadRequest("INVALID_IMAGE_DATA", fmt.Sprintf("image at %s is declared as %s but its data is not a valid image", ref.location, mediaType))
+		}
+		if len(p.allowed) > 0 {
+			if _, ok := p.allowed[mediaType]; !ok {
+				return nil, infraerrors.BadRequest("UNSUPPORTED_IMAGE_FORMAT", fmt.Sprintf("image at %s has unsupported media type %q; supported types: %s", ref.location, mediaType, p.allowedList))
+			}
+		}
+
+		data := ref.data
+		if p.maxBytes > 0 && base64.StdEncoding.DecodedLen(len(data)) > p.maxBytes {
+			if compressed, ok := p.downscale(data); ok {
+				data = compressed
+				mediaType = "image/jpeg"
+				downscaled++
+			}
+		}
+		if data == ref.data && mediaType == strings.ToLower(strings.TrimSpace(ref.mediaType)) {
+			continue
+		}
+		if updated, err := setInlineImage(body, ref, mediaType, data); err == nil {
+			body = updated
+		}
+	}
+	if downscaled > 0 {
+		slog.Info("inline_image_downscaled", "format", int(format), "count", downscaled, "max_bytes", p.maxBytes)
+	}
+	return body, nil
+}
+
+// downscale 将图片缩放到 maxDimension 以内并压缩为 JPEG，直到不超过 maxBytes；
+// 无法解码或压缩后没有变小时返回 false，保持原图。
+func (p *ImageProcessor) downscale(data string) (string, bool) {
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", false
+	}
+	src, _, err := image.Decode(bytes.NewReader(decoded))
+	if err != nil {
+		slog.Warn("inline_image_decode_failed", "error", err)
+		return "", false
+	}
+	srcBounds := src.Bounds()
+	if srcBounds.Empty() {
+		return "", false
+	}
+
+	fit := 1.0
+	if longEdge := max(srcBounds.Dx(), srcBounds.Dy()); longEdge > p.maxDimension {
+		fit = float64(p.maxDimension) / float64(longEdge)
+	}
+	var best []byte
+	for _, step := range imageDownscaleSteps {
+		scale := fit * step
+		width := max(1, int(float64(srcBounds.Dx())*scale))
+		height := max(1, int(float64(srcBounds.Dy())*scale))
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		// 透明背景转 JPEG 时填充白色
+		stddraw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, stddraw.Src)
+		xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, srcBounds, stddraw.Over, nil)
+
+		for quality := p.quality; quality >= imageJPEGQualityFloor; quality -= 15 {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: quality}); err != nil {
+				return "", false
+			}
+			if best == nil || buf.Len() < len(best) {
+				best = buf.Bytes()
+			}
+			if buf.Len() <= p.maxBytes {
+				return base64.StdEncoding.EncodeToString(buf.Bytes()), true
+			}
+		}
+	}
+	// 无法压到上限以内时使用最小的结果，由上游决定是否接受
+	if best == nil || len(best) >= len(decoded) {
+		return "", false
+	}
+	return base64.StdEncoding.EncodeToString(best), true
+}
+
+// sniffInlineImage 解码 base64 数据的开头部分并识别实际类型
+func sniffInlineImage(data string) (string, error) {
+	prefix := data
+	if len(prefix) > 64 {
+		prefix = prefix[:64]
+	}
+	head, err := base64.StdEncoding.DecodeString(prefix)
+	if err != nil {
+		return "", err
+	}
+	mediaType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	return mediaType, nil
+}
+
+// collectInlineImages 收集各协议格式下的内联 base64 图片
+func collectInlineImages(body []byte, format RequestParamFormat) []inlineImageRef {
+	var refs []inlineImageRef
+	switch format {
+	case RequestParamFormatAnthropic:
+		for i, msg := range gjson.GetBytes(body, "messages").Array() {
+			for j, block := range msg.Get("content").Array() {
+				path := fmt.Sprintf("messages.%d.content.%d", i, j)
+				refs = appendAnthropicImage(refs, block, path)
+				// tool_result 中也可能包含图片（如截图工具）
+				if block.Get("type").String() == "tool_result" {
+					for k, inner := range block.Get("content").Array() {
+						refs = appendAnthropicImage(refs, inner, fmt.Sprintf("%s.content.%d", path, k))
+					}
+				}
+			}
+		}
+	case RequestParamFormatChatCompletions:
+		for i, msg := range gjson.GetBytes(body, "messages").Array() {
+			for j, part := range msg.Get("content").Array() {
+				if part.Get("type").String() != "image_url" {
+					continue
+				}
+				path := fmt.Sprintf("messages.%d.content.%d", i, j)
+				refs = appendDataURLImage(refs, part.Get("image_url.url").String(), path, path+".image_url.url")
+			}
+		}
+	case RequestParamFormatResponses:
+		for i, item := range gjson.GetBytes(body, "input").Array() {
+			for j, part := range item.Get("content").Array() {
+				if part.Get("type").String() != "input_image" {
+					continue
+				}
+				path := fmt.Sprintf("input.%d.content.%d", i, j)
+				refs = appendDataURLImage(refs, part.Get("image_url").String(), path, path+".image_url")
+			}
+		}
+	case RequestParamFormatGemini:
+		for i, content := range gjson.GetBytes(body, "contents").Array() {
+			for j, part := range content.Get("parts").Array() {
+				path := fmt.Sprintf("contents.%d.parts.%d", i, j)
+				key, mimeKey := "inlineData", "mimeType"
+				if !part.Get(key).Exists() {
+					key, mimeKey = "inline_data", "mime_type"
+				}
+				inline := part.Get(key)
+				mimeType := inline.Get(mimeKey).String()
+				// inlineData 也可能是音频、PDF 等非图片内容
+				if !inline.Exists() || !strings.HasPrefix(strings.ToLower(mimeType), "image/") {
+					continue
+				}
+				refs = append(refs, inlineImageRef{
+					location:      path,
+					dataPath:      path + "." + key + ".data",
+					mediaTypePath: path + "." + key + "." + mimeKey,
+					mediaType:     mimeType,
+					data:          inline.Get("data").String(),
+				})
+			}
+		}
+	}
+	return refs
+}
+
+func appendAnthropicImage(refs []inlineImageRef, block gjson.Result, path string) []inlineImageRef {
+	if block.Get("type").String() != "image" || block.Get("source.type").String() != "base64" {
+		return refs
+	}
+	return append(refs, inlineImageRef{
+		location:      path,
+		dataPath:      path + ".source.data",
+		mediaTypePath: path + ".source.media_type",
+		mediaType:     block.Get("source.media_type").String(),
+		data:          block.Get("source.data").String(),
+	})
+}
+
+func appendDataURLImage(refs []inlineImageRef, url, location, path string) []inlineImageRef {
+	header, data, ok := strings.Cut(url, ",")
+	if !ok || !strings.HasPrefix(header, "data:") || !strings.HasSuffix(header, ";base64") {
+		return refs
+	}
+	return append(refs, inlineImageRef{
+		location:  location,
+		dataPath:  path,
+		mediaType: strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64"),
+		data:      data,
+		dataURL:   true,
+	})
+}
+
+func setInlineImage(body []byte, ref inlineImageRef, mediaType, data string) ([]byte, error) {
+	if ref.dataURL {
+		return sjson.SetBytes(body, ref.dataPath, "data:"+mediaType+";base64,"+data)
+	}
+	updated, err := sjson.SetBytes(body, ref.dataPath, data)
+	if err != nil {
+		return nil, err
+	}
+	return sjson.SetBytes(updated, ref.mediaTypePath, mediaType)
+}
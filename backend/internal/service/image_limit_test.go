@@ -0,0 +1,99 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newTestImageProcessor(maxBytes int) *ImageProcessor {
+	cfg := &config.Config{}
+	cfg.Gateway.ImageLimit.AllowedMediaTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+	cfg.Gateway.ImageLimit.MaxBytes = maxBytes
+	cfg.Gateway.ImageLimit.MaxDimension = 256
+	cfg.Gateway.ImageLimit.JPEGQuality = 85
+	return NewImageProcessor(cfg)
+}
+
+// testPNGBase64 生成带噪点的 PNG（噪点使 PNG 难以压缩，便于触发缩放）
+func testPNGBase64(t *testing.T, size int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 31), G: uint8(y * 17), B: uint8((x * y) % 251), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestImageProcessor_DownscalesLargeImages(t *testing.T) {
+	p := newTestImageProcessor(20 * 1024)
+	data := testPNGBase64(t, 512)
+	require.Greater(t, base64.StdEncoding.DecodedLen(len(data)), 20*1024)
+
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + data + `"}}]}]}`)
+	out, err := p.Apply(body, RequestParamFormatAnthropic)
+	require.NoError(t, err)
+	require.Equal(t, "image/jpeg", gjson.GetBytes(out, "messages.0.content.0.source.media_type").String())
+
+	decoded, err := base64.StdEncoding.DecodeString(gjson.GetBytes(out, "messages.0.content.0.source.data").String())
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(decoded), 20*1024)
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(decoded))
+	require.NoError(t, err)
+	require.LessOrEqual(t, cfg.Width, 256)
+
+	// Chat Completions data URL 同样处理
+	body = []byte(`{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"data:image/png;base64,` + data + `"}}]}]}`)
+	out, err = p.Apply(body, RequestParamFormatChatCompletions)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(gjson.GetBytes(out, "messages.0.content.0.image_url.url").String(), "data:image/jpeg;base64,"))
+}
+
+func TestImageProcessor_MediaTypeValidation(t *testing.T) {
+	p := newTestImageProcessor(0)
+	pngData := testPNGBase64(t, 4)
+
+	// 声明类型与实际数据不符时按实际类型修正
+	out, err := p.Apply([]byte(`{"input":[{"role":"user","content":[{"type":"input_image","image_url":"data:image/jpeg;base64,`+pngData+`"}]}]}`), RequestParamFormatResponses)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(gjson.GetBytes(out, "input.0.content.0.image_url").String(), "data:image/png;base64,"))
+
+	// 不支持的格式
+	bmp := base64.StdEncoding.EncodeToString(append([]byte("BM"), make([]byte, 40)...))
+	_, err = p.Apply([]byte(`{"contents":[{"parts":[{"inlineData":{"mimeType":"image/bmp","data":"`+bmp+`"}}]}]}`), RequestParamFormatGemini)
+	require.Equal(t, "UNSUPPORTED_IMAGE_FORMAT", infraerrors.Reason(err))
+	require.Contains(t, infraerrors.Message(err), "contents.0.parts.0")
+
+	// 数据不是图片
+	text := base64.StdEncoding.EncodeToString([]byte("hello, this is definitely not an image"))
+	_, err = p.Apply([]byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"`+text+`"}}]}]}`), RequestParamFormatAnthropic)
+	require.Equal(t, "INVALID_IMAGE_DATA", infraerrors.Reason(err))
+
+	// Gemini 非图片 inlineData（如 PDF）不做校验
+	body := []byte(`{"contents":[{"parts":[{"inlineData":{"mimeType":"application/pdf","data":"` + text + `"}}]}]}`)
+	out, err = p.Apply(body, RequestParamFormatGemini)
+	require.NoError(t, err)
+	require.Equal(t, body, out)
+}
+
+func TestImageProcessor_Disabled(t *testing.T) {
+	body := []byte(`{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/bmp","data":"Qk0="}}]}]}`)
+	out, err := NewImageProcessor(&config.Config{}).Apply(body, RequestParamFormatAnthropic)
+	require.NoError(t, err)
+	require.Equal(t, body, out)
+}
@@ -0,0 +1,652 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+// Claude Messages Batches 接口模拟：批次与子请求存储在数据库中，后台按受控并发将每条子请求
+// 通过网关自身的 /v1/messages 路由执行（认证、分组调度、限流、计费与普通请求一致），
+// 结果按 Anthropic 批处理结果格式保存，供 results 接口以 JSONL 下载。
+
+const (
+	MessageBatchStatusInProgress = "in_progress"
+	MessageBatchStatusCanceling  = "canceling"
+	MessageBatchStatusEnded      = "ended"
+)
+
+// 子请求状态；succeeded/errored/canceled/expired 同时作为结果类型
+const (
+	MessageBatchRequestPending    = "pending"
+	MessageBatchRequestProcessing = "processing"
+	MessageBatchResultSucceeded   = "succeeded"
+	MessageBatchResultErrored     = "errored"
+	MessageBatchResultCanceled    = "canceled"
+	MessageBatchResultExpired     = "expired"
+)
+
+const (
+	messageBatchPollInterval    = time.Second
+	messageBatchSweepInterval   = 30 * time.Second
+	messageBatchCleanupInterval = time.Hour
+	messageBatchRetryBaseDelay  = 5 * time.Second
+	messageBatchRetryMaxDelay   = 2 * time.Minute
+	// messageBatchMaxResponseBytes 单条结果保存的最大响应体字节数
+	messageBatchMaxResponseBytes = 8 << 20
+	// MessageBatchResultsPageSize results 接口每次从数据库读取的结果条数
+	MessageBatchResultsPageSize = 500
+)
+
+var messageBatchCustomIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+var (
+	ErrMessageBatchDisabled   = infraerrors.NotFound("MESSAGE_BATCH_DISABLED", "message batches are not enabled on this gateway")
+	ErrMessageBatchNotFound   = infraerrors.NotFound("MESSAGE_BATCH_NOT_FOUND", "message batch not found")
+	ErrMessageBatchNotEnded   = infraerrors.BadRequest("MESSAGE_BATCH_NOT_ENDED", "message batch is still processing; results are available once processing_status is ended")
+	ErrMessageBatchNotDeleted = infraerrors.BadRequest("MESSAGE_BATCH_IN_PROGRESS", "message batch must be ended or canceled before it can be deleted")
+)
+
+// MessageBatchRequestCounts 各结果类型的子请求数（processing 含尚未开始的请求）
+type MessageBatchRequestCounts struct {
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+	Errored    int `json:"errored"`
+	Canceled   int `json:"canceled"`
+	Expired    int `json:"expired"`
+}
+
+// MessageBatch 批次
+type MessageBatch struct {
+	ID                string
+	UserID            int64
+	APIKeyID          int64
+	ProcessingStatus  string
+	RequestCounts     MessageBatchRequestCounts
+	ClientIP          string
+	AnthropicBeta     string
+	ResultsURL        string
+	CreatedAt         time.Time
+	ExpiresAt         time.Time
+	CancelInitiatedAt *time.Time
+	EndedAt           *time.Time
+}
+
+// MessageBatchRequest 批次中的单条子请求
+type MessageBatchRequest struct {
+	BatchID  string
+	Index    int
+	CustomID string
+	Params   []byte
+	Status   string
+	// Result Anthropic 结果对象（JSON），例如 {"type":"succeeded","message":{...}}
+	Result   []byte
+	Attempts int
+}
+
+// MessageBatchWork 领取到的待执行子请求及其所属批次的执行上下文
+type MessageBatchWork struct {
+	MessageBatchRequest
+	APIKeyID      int64
+	ClientIP      string
+	AnthropicBeta string
+}
+
+// MessageBatchCreateInput 创建批次的参数
+type MessageBatchCreateInput struct {
+	UserID        int64
+	APIKeyID      int64
+	ClientIP      string
+	AnthropicBeta string
+	// ResultsBaseURL 批次资源的外部 URL 前缀（.../messages/batches），用于生成 results_url
+	ResultsBaseURL string
+	Body           []byte
+}
+
+// MessageBatchRepository 批次存储
+type MessageBatchRepository interface {
+	Create(ctx context.Context, batch *MessageBatch, requests []MessageBatchRequest) error
+	// GetByID 返回批次及请求计数，不存在时返回 ErrMessageBatchNotFound
+	GetByID(ctx context.Context, id string) (*MessageBatch, error)
+	// ListByAPIKey 按创建时间倒序分页；beforeID/afterID 为 Anthropic 游标语义，返回是否还有更多
+	ListByAPIKey(ctx context.Context, apiKeyID int64, beforeID, afterID string, limit int) ([]*MessageBatch, bool, error)
+	// Cancel 将进行中的批次置为 canceling，并把尚未开始的子请求标记为 canceled
+	Cancel(ctx context.Context, id string, now time.Time, canceledResult []byte) error
+	Delete(ctx context.Context, id string) error
+	// ListResults 按序号返回 afterIndex 之后已完成的子请求
+	ListResults(ctx context.Context, id string, afterIndex, limit int) ([]MessageBatchRequest, error)
+
+	// ClaimPending 领取可执行的子请求（含超时未完成的 processing 请求），多实例间互斥
+	ClaimPending(ctx context.Context, now, staleBefore time.Time, limit int) ([]MessageBatchWork, error)
+	CompleteRequest(ctx context.Context, batchID string, index int, status string, result []byte, now time.Time) error
+	// ReleaseRequest 将子请求放回待执行队列，notBefore 前不再领取
+	ReleaseRequest(ctx context.Context, batchID string, index int, notBefore time.Time) error
+	// SweepRequests 处理过期批次的剩余请求（expired）与取消中批次的超时请求（canceled）
+	SweepRequests(ctx context.Context, now, staleBefore time.Time, expiredResult, canceledResult []byte) (int64, error)
+	// FinalizeBatches 将没有剩余请求的批次置为 ended
+	FinalizeBatches(ctx context.Context, now time.Time) (int64, error)
+	DeleteEndedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// MessageBatchService 管理批次与后台执行
+type MessageBatchService struct {
+	repo       MessageBatchRepository
+	apiKeyRepo APIKeyRepository
+	cfg        config.GatewayMessageBatchConfig
+
+	dispatchMu sync.RWMutex
+	dispatcher http.Handler
+
+	sem      chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+	nowFn    func() time.Time
+}
+
+// NewMessageBatchService creates a new MessageBatchService
+func NewMessageBatchService(repo MessageBatchRepository, apiKeyRepo APIKeyRepository, cfg *config.Config) *MessageBatchService {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &MessageBatchService{
+		repo:       repo,
+		apiKeyRepo: apiKeyRepo,
+		ctx:        ctx,
+		cancel:     cancel,
+		nowFn:      time.Now,
+	}
+	if cfg != nil {
+		s.cfg = cfg.Gateway.MessageBatch
+	}
+	if s.cfg.Concurrency > 0 {
+		s.sem = make(chan struct{}, s.cfg.Concurrency)
+	}
+	return s
+}
+
+// Enabled 是否启用批处理接口
+func (s *MessageBatchService) Enabled() bool {
+	return s != nil && s.repo != nil && s.cfg.Enabled
+}
+
+// SetDispatcher 设置执行子请求的 HTTP 处理器（网关路由引擎），路由注册完成后调用
+func (s *MessageBatchService) SetDispatcher(h http.Handler) {
+	if s == nil {
+		return
+	}
+	s.dispatchMu.Lock()
+	s.dispatcher = h
+	s.dispatchMu.Unlock()
+}
+
+func (s *MessageBatchService) getDispatcher() http.Handler {
+	s.dispatchMu.RLock()
+	defer s.dispatchMu.RUnlock()
+	return s.dispatcher
+}
+
+// Create 校验批处理请求体并创建批次
+func (s *MessageBatchService) Create(ctx context.Context, input MessageBatchCreateInput) (*MessageBatch, error) {
+	if !s.Enabled() {
+		return nil, ErrMessageBatchDisabled
+	}
+	requests, err := parseMessageBatchRequests(input.Body, s.cfg.MaxRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.nowFn().UTC().Truncate(time.Microsecond)
+	batch := &MessageBatch{
+		ID:               "msgbatch_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		UserID:           input.UserID,
+		APIKeyID:         input.APIKeyID,
+		ProcessingStatus: MessageBatchStatusInProgress,
+		RequestCounts:    MessageBatchRequestCounts{Processing: len(requests)},
+		ClientIP:         input.ClientIP,
+		AnthropicBeta:    input.AnthropicBeta,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(time.Duration(s.cfg.ExpireHours) * time.Hour),
+	}
+	if base := strings.TrimRight(input.ResultsBaseURL, "/"); base != "" {
+		batch.ResultsURL = base + "/" + batch.ID + "/results"
+	}
+	for i := range requests {
+		requests[i].BatchID = batch.ID
+	}
+	if err := s.repo.Create(ctx, batch, requests); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// Get 返回属于该 API Key 的批次
+func (s *MessageBatchService) Get(ctx context.Context, apiKeyID int64, id string) (*MessageBatch, error) {
+	if !s.Enabled() {
+		return nil, ErrMessageBatchDisabled
+	}
+	batch, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.APIKeyID != apiKeyID {
+		return nil, ErrMessageBatchNotFound
+	}
+	return batch, nil
+}
+
+// List 分页返回该 API Key 的批次（新批次在前）
+func (s *MessageBatchService) List(ctx context.Context, apiKeyID int64, beforeID, afterID string, limit int) ([]*MessageBatch, bool, error) {
+	if !s.Enabled() {
+		return nil, false, ErrMessageBatchDisabled
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 20
+	}
+	return s.repo.ListByAPIKey(ctx, apiKeyID, beforeID, afterID, limit)
+}
+
+// Cancel 取消批次：尚未开始的请求标记为 canceled，执行中的请求完成后批次结束
+func (s *MessageBatchService) Cancel(ctx context.Context, apiKeyID int64, id string) (*MessageBatch, error) {
+	batch, err := s.Get(ctx, apiKeyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.ProcessingStatus == MessageBatchStatusInProgress {
+		if err := s.repo.Cancel(ctx, id, s.nowFn().UTC(), messageBatchCanceledResult); err != nil {
+			return nil, err
+		}
+		if _, err := s.repo.FinalizeBatches(ctx, s.nowFn().UTC()); err != nil {
+			slog.Warn("message_batch_finalize_failed", "batch_id", id, "error", err)
+		}
+		return s.repo.GetByID(ctx, id)
+	}
+	return batch, nil
+}
+
+// Delete 删除已结束的批次及其结果
+func (s *MessageBatchService) Delete(ctx context.Context, apiKeyID int64, id string) error {
+	batch, err := s.Get(ctx, apiKeyID, id)
+	if err != nil {
+		return err
+	}
+	if batch.ProcessingStatus != MessageBatchStatusEnded {
+		return ErrMessageBatchNotDeleted
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Results 返回已结束批次的结果（按请求顺序分页读取）
+func (s *MessageBatchService) Results(ctx context.Context, apiKeyID int64, id string, afterIndex int) ([]MessageBatchRequest, error) {
+	batch, err := s.Get(ctx, apiKeyID, id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.ProcessingStatus != MessageBatchStatusEnded {
+		return nil, ErrMessageBatchNotEnded
+	}
+	return s.repo.ListResults(ctx, id, afterIndex, MessageBatchResultsPageSize)
+}
+
+// MessageBatchResultLine 构造 results JSONL 中的一行
+func MessageBatchResultLine(req MessageBatchRequest) []byte {
+	result := req.Result
+	if len(result) == 0 || !json.Valid(result) {
+		result = messageBatchErroredResult("api_error", "batch request result is unavailable")
+	}
+	line, _ := json.Marshal(struct {
+		CustomID string          `json:"custom_id"`
+		Result   json.RawMessage `json:"result"`
+	}{CustomID: req.CustomID, Result: result})
+	return line
+}
+
+// parseMessageBatchRequests 解析并校验 {"requests":[{"custom_id","params"}]}
+func parseMessageBatchRequests(body []byte, maxRequests int) ([]MessageBatchRequest, error) {
+	var payload struct {
+		Requests []struct {
+			CustomID string          `json:"custom_id"`
+			Params   json.RawMessage `json:"params"`
+		} `json:"requests"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID", "request body must be a JSON object with a requests array")
+	}
+	if len(payload.Requests) == 0 {
+		return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID", "requests must contain at least one request")
+	}
+	if maxRequests > 0 && len(payload.Requests) > maxRequests {
+		return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID", "requests must contain at most "+strconv.Itoa(maxRequests)+" requests")
+	}
+
+	seen := make(map[string]struct{}, len(payload.Requests))
+	out := make([]MessageBatchRequest, 0, len(payload.Requests))
+	for i, item := range payload.Requests {
+		where := "requests." + strconv.Itoa(i)
+		if !messageBatchCustomIDPattern.MatchString(item.CustomID) {
+			return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID", where+".custom_id must be 1-64 characters of letters, digits, '_' or '-'")
+		}
+		if _, dup := seen[item.CustomID]; dup {
+			return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID", where+".custom_id is duplicated: "+item.CustomID)
+		}
+		seen[item.CustomID] = struct{}{}
+
+		params := gjson.ParseBytes(item.Params)
+		switch {
+		case !params.IsObject():
+			return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID", where+".params must be an object")
+		case strings.TrimSpace(params.Get("model").String()) == "":
+			return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID", where+".params.model is required")
+		case params.Get("max_tokens").Int() <= 0:
+			return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID", where+".params.max_tokens must be a positive integer")
+		case !params.Get("messages").IsArray():
+			return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID", where+".params.messages must be an array")
+		case params.Get("stream").Bool():
+			return nil, infraerrors.BadRequest("MESSAGE_BATCH_INVALID", where+".params.stream is not supported in batches")
+		}
+		out = append(out, MessageBatchRequest{
+			Index:    i,
+			CustomID: item.CustomID,
+			Params:   append([]byte(nil), item.Params...),
+			Status:   MessageBatchRequestPending,
+		})
+	}
+	return out, nil
+}
+
+var (
+	messageBatchCanceledResult = []byte(`{"type":"canceled"}`)
+	messageBatchExpiredResult  = []byte(`{"type":"expired"}`)
+)
+
+func messageBatchErroredResult(errType, message string) []byte {
+	out, _ := json.Marshal(map[string]any{
+		"type": MessageBatchResultErrored,
+		"error": map[string]any{
+			"type":  "error",
+			"error": map[string]string{"type": errType, "message": message},
+		},
+	})
+	return out
+}
+
+// messageBatchResultFromResponse 将 /v1/messages 的响应转换为批处理结果对象
+func messageBatchResultFromResponse(status int, body []byte) (string, []byte) {
+	trimmed := bytes.TrimSpace(body)
+	if status == http.StatusOK && json.Valid(trimmed) {
+		out, _ := json.Marshal(map[string]json.RawMessage{
+			"type":    json.RawMessage(`"` + MessageBatchResultSucceeded + `"`),
+			"message": trimmed,
+		})
+		return MessageBatchResultSucceeded, out
+	}
+	if status != http.StatusOK && gjson.GetBytes(trimmed, "error.type").Exists() {
+		out, _ := json.Marshal(map[string]json.RawMessage{
+			"type":  json.RawMessage(`"` + MessageBatchResultErrored + `"`),
+			"error": trimmed,
+		})
+		return MessageBatchResultErrored, out
+	}
+	return MessageBatchResultErrored, messageBatchErroredResult("api_error", "batch request failed with status "+strconv.Itoa(status))
+}
+
+// messageBatchRetryable 是否为可重试的失败（限流、过载与上游 5xx）
+func messageBatchRetryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529:
+		return true
+	default:
+		return false
+	}
+}
+
+// messageBatchRetryDelay 优先使用 Retry-After，否则按尝试次数指数退避
+func messageBatchRetryDelay(header http.Header, attempts int) time.Duration {
+	if secs, err := strconv.Atoi(strings.TrimSpace(header.Get("Retry-After"))); err == nil && secs > 0 {
+		return min(time.Duration(secs)*time.Second, messageBatchRetryMaxDelay)
+	}
+	delay := messageBatchRetryBaseDelay << max(attempts-1, 0)
+	return min(delay, messageBatchRetryMaxDelay)
+}
+
+// Start 启动后台执行循环（未启用时不启动）
+func (s *MessageBatchService) Start() {
+	if !s.Enabled() || s.sem == nil {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.loop()
+	}()
+}
+
+// Stop 停止领取新请求并等待执行中的子请求结束（中断的请求在超时后由其他实例重新领取）
+func (s *MessageBatchService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(s.cancel)
+	s.wg.Wait()
+}
+
+func (s *MessageBatchService) loop() {
+	ticker := time.NewTicker(messageBatchPollInterval)
+	defer ticker.Stop()
+	var lastSweep, lastCleanup time.Time
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := s.nowFn()
+		if now.Sub(lastSweep) >= messageBatchSweepInterval {
+			lastSweep = now
+			s.sweep(now)
+		}
+		if now.Sub(lastCleanup) >= messageBatchCleanupInterval {
+			lastCleanup = now
+			s.cleanup(now)
+		}
+		s.dispatchPending(now)
+	}
+}
+
+func (s *MessageBatchService) requestTimeout() time.Duration {
+	return time.Duration(s.cfg.RequestTimeoutSeconds) * time.Second
+}
+
+// staleBefore 早于该时间领取且仍未完成的请求视为执行实例已退出
+func (s *MessageBatchService) staleBefore(now time.Time) time.Time {
+	return now.Add(-s.requestTimeout() - time.Minute)
+}
+
+func (s *MessageBatchService) dispatchPending(now time.Time) {
+	dispatcher := s.getDispatcher()
+	free := cap(s.sem) - len(s.sem)
+	if dispatcher == nil || free <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	works, err := s.repo.ClaimPending(ctx, now.UTC(), s.staleBefore(now).UTC(), free)
+	cancel()
+	if err != nil {
+		slog.Warn("message_batch_claim_failed", "error", err)
+		return
+	}
+	for _, work := range works {
+		s.sem <- struct{}{}
+		s.wg.Add(1)
+		go func(work MessageBatchWork) {
+			defer func() {
+				<-s.sem
+				s.wg.Done()
+			}()
+			s.execute(dispatcher, work)
+		}(work)
+	}
+	if len(works) > 0 {
+		s.finalize()
+	}
+}
+
+// execute 通过网关路由执行单条子请求并保存结果
+func (s *MessageBatchService) execute(dispatcher http.Handler, work MessageBatchWork) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.requestTimeout())
+	defer cancel()
+
+	status, header, body, err := s.dispatch(ctx, dispatcher, work)
+	if err != nil {
+		if s.ctx.Err() != nil {
+			// 进程退出：保持 processing，由超时回收后重新执行
+			return
+		}
+		status = http.StatusGatewayTimeout
+	}
+	if messageBatchRetryable(status) && work.Attempts < s.cfg.MaxAttempts {
+		notBefore := s.nowFn().Add(messageBatchRetryDelay(header, work.Attempts)).UTC()
+		if err := s.repo.ReleaseRequest(context.Background(), work.BatchID, work.Index, notBefore); err != nil {
+			slog.Warn("message_batch_release_failed", "batch_id", work.BatchID, "index", work.Index, "error", err)
+		}
+		return
+	}
+
+	var resultType string
+	var result []byte
+	if err != nil {
+		resultType, result = MessageBatchResultErrored, messageBatchErroredResult("timeout_error", "batch request timed out")
+	} else {
+		resultType, result = messageBatchResultFromResponse(status, body)
+	}
+	if err := s.repo.CompleteRequest(context.Background(), work.BatchID, work.Index, resultType, result, s.nowFn().UTC()); err != nil {
+		slog.Warn("message_batch_complete_failed", "batch_id", work.BatchID, "index", work.Index, "error", err)
+		return
+	}
+	s.finalize()
+}
+
+// dispatch 以批次所属 API Key 构造 /v1/messages 请求并交给网关路由处理
+func (s *MessageBatchService) dispatch(ctx context.Context, dispatcher http.Handler, work MessageBatchWork) (int, http.Header, []byte, error) {
+	key, _, err := s.apiKeyRepo.GetKeyAndOwnerID(ctx, work.APIKeyID)
+	if err != nil || key == "" {
+		return http.StatusUnauthorized, nil, messageBatchAPIError("authentication_error", "the API key that created this batch is no longer available"), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/v1/messages", bytes.NewReader(work.Params))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", key)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	if work.AnthropicBeta != "" {
+		req.Header.Set("anthropic-beta", work.AnthropicBeta)
+	}
+	// 保留创建批次时的客户端 IP，使 API Key IP 白名单与审计保持一致
+	clientIP := work.ClientIP
+	if net.ParseIP(clientIP) == nil {
+		clientIP = "127.0.0.1"
+	}
+	req.RemoteAddr = net.JoinHostPort(clientIP, "0")
+
+	w := newMessageBatchResponseWriter()
+	dispatcher.ServeHTTP(w, req)
+	if ctx.Err() != nil && w.status == 0 {
+		return 0, nil, nil, ctx.Err()
+	}
+	return w.statusCode(), w.header, w.body.Bytes(), nil
+}
+
+func messageBatchAPIError(errType, message string) []byte {
+	out, _ := json.Marshal(map[string]any{
+		"type":  "error",
+		"error": map[string]string{"type": errType, "message": message},
+	})
+	return out
+}
+
+func (s *MessageBatchService) finalize() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := s.repo.FinalizeBatches(ctx, s.nowFn().UTC()); err != nil {
+		slog.Warn("message_batch_finalize_failed", "error", err)
+	}
+}
+
+func (s *MessageBatchService) sweep(now time.Time) {
+	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+	defer cancel()
+	if n, err := s.repo.SweepRequests(ctx, now.UTC(), s.staleBefore(now).UTC(), messageBatchExpiredResult, messageBatchCanceledResult); err != nil {
+		slog.Warn("message_batch_sweep_failed", "error", err)
+	} else if n > 0 {
+		slog.Info("message_batch_requests_swept", "count", n)
+	}
+	s.finalize()
+}
+
+func (s *MessageBatchService) cleanup(now time.Time) {
+	ctx, cancel := context.WithTimeout(s.ctx, time.Minute)
+	defer cancel()
+	cutoff := now.Add(-time.Duration(s.cfg.RetentionHours) * time.Hour).UTC()
+	if n, err := s.repo.DeleteEndedBefore(ctx, cutoff); err != nil {
+		slog.Warn("message_batch_cleanup_failed", "error", err)
+	} else if n > 0 {
+		slog.Info("message_batch_cleanup_deleted", "count", n)
+	}
+}
+
+// messageBatchResponseWriter 收集子请求的响应（状态码、响应头与限长响应体）
+type messageBatchResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newMessageBatchResponseWriter() *messageBatchResponseWriter {
+	return &messageBatchResponseWriter{header: make(http.Header)}
+}
+
+func (w *messageBatchResponseWriter) Header() http.Header { return w.header }
+
+func (w *messageBatchResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *messageBatchResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if remaining := messageBatchMaxResponseBytes - w.body.Len(); remaining > 0 {
+		if len(p) > remaining {
+			w.body.Write(p[:remaining])
+		} else {
+			w.body.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (w *messageBatchResponseWriter) Flush() {}
+
+func (w *messageBatchResponseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,140 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestParseMessageBatchRequests(t *testing.T) {
+	valid := `{"model":"claude-sonnet-4-5","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`
+
+	requests, err := parseMessageBatchRequests([]byte(`{"requests":[{"custom_id":"a-1","params":`+valid+`},{"custom_id":"b_2","params":`+valid+`}]}`), 10)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	require.Equal(t, 1, requests[1].Index)
+	require.Equal(t, "b_2", requests[1].CustomID)
+	require.Equal(t, MessageBatchRequestPending, requests[1].Status)
+	require.JSONEq(t, valid, string(requests[0].Params))
+
+	cases := map[string]string{
+		"not json":      `[`,
+		"empty":         `{"requests":[]}`,
+		"too many":      `{"requests":[{"custom_id":"a","params":` + valid + `},{"custom_id":"b","params":` + valid + `},{"custom_id":"c","params":` + valid + `}]}`,
+		"bad custom_id": `{"requests":[{"custom_id":"a b","params":` + valid + `}]}`,
+		"duplicate":     `{"requests":[{"custom_id":"a","params":` + valid + `},{"custom_id":"a","params":` + valid + `}]}`,
+		"no model":      `{"requests":[{"custom_id":"a","params":{"max_tokens":1,"messages":[]}}]}`,
+		"no max_tokens": `{"requests":[{"custom_id":"a","params":{"model":"m","messages":[]}}]}`,
+		"no messages":   `{"requests":[{"custom_id":"a","params":{"model":"m","max_tokens":1}}]}`,
+		"stream":        `{"requests":[{"custom_id":"a","params":{"model":"m","max_tokens":1,"messages":[],"stream":true}}]}`,
+	}
+	for name, body := range cases {
+		_, err := parseMessageBatchRequests([]byte(body), 2)
+		require.Error(t, err, name)
+	}
+}
+
+func TestMessageBatchResultFromResponse(t *testing.T) {
+	resultType, result := messageBatchResultFromResponse(http.StatusOK, []byte(`{"id":"msg_1","type":"message"}`+"\n"))
+	require.Equal(t, MessageBatchResultSucceeded, resultType)
+	require.JSONEq(t, `{"type":"succeeded","message":{"id":"msg_1","type":"message"}}`, string(result))
+
+	resultType, result = messageBatchResultFromResponse(http.StatusBadRequest, []byte(`{"type":"error","error":{"type":"invalid_request_error","message":"bad"}}`))
+	require.Equal(t, MessageBatchResultErrored, resultType)
+	require.Equal(t, "invalid_request_error", gjson.GetBytes(result, "error.error.type").String())
+
+	resultType, result = messageBatchResultFromResponse(http.StatusBadGateway, []byte(`<html>bad gateway</html>`))
+	require.Equal(t, MessageBatchResultErrored, resultType)
+	require.Equal(t, "api_error", gjson.GetBytes(result, "error.error.type").String())
+}
+
+func TestMessageBatchResultLine(t *testing.T) {
+	line := MessageBatchResultLine(MessageBatchRequest{CustomID: "a", Result: []byte(`{"type":"canceled"}`)})
+	require.JSONEq(t, `{"custom_id":"a","result":{"type":"canceled"}}`, string(line))
+
+	line = MessageBatchResultLine(MessageBatchRequest{CustomID: "b"})
+	require.Equal(t, MessageBatchResultErrored, gjson.GetBytes(line, "result.type").String())
+}
+
+func TestMessageBatchRetryDelay(t *testing.T) {
+	require.Equal(t, 3*time.Second, messageBatchRetryDelay(http.Header{"Retry-After": []string{"3"}}, 1))
+	require.Equal(t, messageBatchRetryBaseDelay, messageBatchRetryDelay(http.Header{}, 1))
+	require.Equal(t, 2*messageBatchRetryBaseDelay, messageBatchRetryDelay(http.Header{}, 2))
+	require.Equal(t, messageBatchRetryMaxDelay, messageBatchRetryDelay(http.Header{}, 20))
+}
+
+type messageBatchRepoStub struct {
+	MessageBatchRepository
+	completed  map[int]string
+	results    map[int][]byte
+	released   []int
+	finalizeNo int
+}
+
+func (r *messageBatchRepoStub) CompleteRequest(_ context.Context, _ string, index int, status string, result []byte, _ time.Time) error {
+	r.completed[index] = status
+	r.results[index] = result
+	return nil
+}
+
+func (r *messageBatchRepoStub) ReleaseRequest(_ context.Context, _ string, index int, _ time.Time) error {
+	r.released = append(r.released, index)
+	return nil
+}
+
+func (r *messageBatchRepoStub) FinalizeBatches(context.Context, time.Time) (int64, error) {
+	r.finalizeNo++
+	return 0, nil
+}
+
+func TestMessageBatchService_ExecuteDispatchesThroughGatewayAndRetries(t *testing.T) {
+	repo := &messageBatchRepoStub{completed: map[int]string{}, results: map[int][]byte{}}
+	cfg := &config.Config{}
+	cfg.Gateway.MessageBatch = config.GatewayMessageBatchConfig{Enabled: true, Concurrency: 1, MaxAttempts: 2, RequestTimeoutSeconds: 5}
+	svc := NewMessageBatchService(repo, &apiKeyRepoStub{apiKey: &APIKey{Key: "sk-batch", UserID: 7}}, cfg)
+	defer svc.Stop()
+
+	var gotKey, gotBeta, gotRemote string
+	dispatcher := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey, gotBeta, gotRemote = r.Header.Get("x-api-key"), r.Header.Get("anthropic-beta"), r.RemoteAddr
+		var params map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		if params["model"] == "overloaded" {
+			w.WriteHeader(529)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message"}`))
+	})
+
+	work := MessageBatchWork{
+		MessageBatchRequest: MessageBatchRequest{BatchID: "msgbatch_1", Index: 0, Params: []byte(`{"model":"m"}`), Attempts: 1},
+		APIKeyID:            1,
+		ClientIP:            "10.0.0.1",
+		AnthropicBeta:       "beta-1",
+	}
+	svc.execute(dispatcher, work)
+	require.Equal(t, "sk-batch", gotKey)
+	require.Equal(t, "beta-1", gotBeta)
+	require.Equal(t, "10.0.0.1:0", gotRemote)
+	require.Equal(t, MessageBatchResultSucceeded, repo.completed[0])
+	require.Equal(t, 1, repo.finalizeNo)
+
+	// 可重试失败且未达上限时放回队列；达到上限后记录为 errored
+	overloaded := work
+	overloaded.Index, overloaded.Params = 1, []byte(`{"model":"overloaded"}`)
+	svc.execute(dispatcher, overloaded)
+	require.Equal(t, []int{1}, repo.released)
+	require.NotContains(t, repo.completed, 1)
+
+	overloaded.Attempts = 2
+	svc.execute(dispatcher, overloaded)
+	require.Equal(t, MessageBatchResultErrored, repo.completed[1])
+}
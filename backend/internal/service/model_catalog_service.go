@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/antigravity"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// Model catalog entry sources (later sources take precedence)
+const (
+	ModelCatalogSourceBuiltin  = "builtin"
+	ModelCatalogSourcePricing  = "pricing"
+	ModelCatalogSourceUpstream = "upstream"
+	ModelCatalogSourceOverride = "override"
+)
+
+// Tokenizer families
+const (
+	TokenizerFamilyClaude = "claude"
+	TokenizerFamilyO200k  = "o200k"
+	TokenizerFamilyGemini = "gemini"
+)
+
+const (
+	modelCatalogCacheTTL      = 30 * time.Second
+	modelCatalogMaxOverrides  = 500
+	modelCatalogMaxModelChars = 200
+)
+
+// ErrModelCatalogOverrideNotFound 模型元数据覆盖项不存在
+var ErrModelCatalogOverrideNotFound = infraerrors.NotFound("MODEL_CATALOG_OVERRIDE_NOT_FOUND", "model catalog override not found")
+
+// ModelCatalogEntry 单个模型的元数据。
+// 作为管理员覆盖项时，零值/nil 字段表示不覆盖，沿用下层来源的值。
+type ModelCatalogEntry struct {
+	Model             string `json:"model"`
+	ContextWindow     int    `json:"context_window,omitempty"`
+	MaxOutputTokens   int    `json:"max_output_tokens,omitempty"`
+	SupportsVision    *bool  `json:"supports_vision,omitempty"`
+	SupportsTools     *bool  `json:"supports_tools,omitempty"`
+	SupportsReasoning *bool  `json:"supports_reasoning,omitempty"`
+	TokenizerFamily   string `json:"tokenizer_family,omitempty"`
+	// PricingModel 读取定价数据时使用的模型名（未设置时使用 Model 本身）
+	PricingModel string `json:"pricing_model,omitempty"`
+	// Sources 解析结果依次合并的来源，仅在查询结果中返回
+	Sources   []string  `json:"sources,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// ModelCatalogService 模型元数据注册表，按以下优先级（由低到高）合并每个模型的元数据：
+//  1. 内置规则：按模型名推断分词器家族与常见能力
+//  2. 定价数据：LiteLLM 的 max_input_tokens / supports_vision 等字段
+//  3. 上游模型列表：账号拉取额度时上游返回的模型能力（如 Antigravity fetchAvailableModels）
+//  4. 管理员覆盖：存储在 settings 表中
+type ModelCatalogService struct {
+	settingRepo    SettingRepository
+	pricingService *PricingService
+	nowFn          func() time.Time
+
+	upstreamMu sync.RWMutex
+	upstream   map[string]ModelCatalogEntry
+
+	mu       sync.Mutex
+	cache    map[string]ModelCatalogEntry
+	cachedAt time.Time
+	loaded   bool
+}
+
+// NewModelCatalogService creates a new ModelCatalogService.
+func NewModelCatalogService(settingRepo SettingRepository, pricingService *PricingService) *ModelCatalogService {
+	return &ModelCatalogService{
+		settingRepo:    settingRepo,
+		pricingService: pricingService,
+		nowFn:          time.Now,
+		upstream:       make(map[string]ModelCatalogEntry),
+	}
+}
+
+// Resolve 返回合并后的模型元数据；未知模型也会返回仅含内置规则结果的条目
+func (s *ModelCatalogService) Resolve(ctx context.Context, model string) *ModelCatalogEntry {
+	key := normalizeModelCatalogKey(model)
+	entry := builtinModelCatalogEntry(key)
+	entry.Model = strings.TrimSpace(model)
+	if s == nil || key == "" {
+		return entry
+	}
+
+	override, hasOverride := s.loadOverrides(ctx)[key]
+	pricingModel := key
+	if hasOverride && override.PricingModel != "" {
+		pricingModel = override.PricingModel
+	}
+	if s.pricingService != nil {
+		if pricing := s.pricingService.GetModelPricing(pricingModel); pricing != nil {
+			mergeModelCatalogEntry(entry, pricingModelCatalogEntry(pricing), ModelCatalogSourcePricing)
+		}
+	}
+
+	s.upstreamMu.RLock()
+	upstream, hasUpstream := s.upstream[key]
+	s.upstreamMu.RUnlock()
+	if hasUpstream {
+		mergeModelCatalogEntry(entry, &upstream, ModelCatalogSourceUpstream)
+	}
+	if hasOverride {
+		mergeModelCatalogEntry(entry, &override, ModelCatalogSourceOverride)
+		entry.PricingModel = override.PricingModel
+	}
+	return entry
+}
+
+// ContextWindow 返回模型上下文窗口（输入 token 上限），未知时返回 0
+func (s *ModelCatalogService) ContextWindow(ctx context.Context, model string) int {
+	return s.Resolve(ctx, model).ContextWindow
+}
+
+// SupportsVision 返回模型是否支持图片输入；known=false 表示没有任何来源给出结论
+func (s *ModelCatalogService) SupportsVision(ctx context.Context, model string) (supported bool, known bool) {
+	v := s.Resolve(ctx, model).SupportsVision
+	if v == nil {
+		return false, false
+	}
+	return *v, true
+}
+
+// ObserveAntigravityModels 记录 Antigravity fetchAvailableModels 返回的模型能力
+func (s *ModelCatalogService) ObserveAntigravityModels(models map[string]antigravity.ModelInfo) {
+	if s == nil || len(models) == 0 {
+		return
+	}
+	now := s.nowFn()
+	s.upstreamMu.Lock()
+	defer s.upstreamMu.Unlock()
+	for name, info := range models {
+		key := normalizeModelCatalogKey(name)
+		if key == "" {
+			continue
+		}
+		entry := ModelCatalogEntry{
+			Model:             key,
+			SupportsVision:    info.SupportsImages,
+			SupportsReasoning: info.SupportsThinking,
+			UpdatedAt:         now,
+		}
+		if info.MaxTokens != nil {
+			entry.ContextWindow = *info.MaxTokens
+		}
+		if info.MaxOutputTokens != nil {
+			entry.MaxOutputTokens = *info.MaxOutputTokens
+		}
+		s.upstream[key] = entry
+	}
+}
+
+// ListOverrides 返回全部管理员覆盖项（按模型名排序）
+func (s *ModelCatalogService) ListOverrides(ctx context.Context) ([]ModelCatalogEntry, error) {
+	if s == nil || s.settingRepo == nil {
+		return []ModelCatalogEntry{}, nil
+	}
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyModelCatalogOverrides)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return []ModelCatalogEntry{}, nil
+		}
+		return nil, err
+	}
+	var overrides []ModelCatalogEntry
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		// 损坏的 JSON 不应影响请求转发，按未配置处理
+		slog.Warn("model_catalog_overrides_invalid", "error", err)
+		return []ModelCatalogEntry{}, nil
+	}
+	if overrides == nil {
+		overrides = []ModelCatalogEntry{}
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Model < overrides[j].Model })
+	return overrides, nil
+}
+
+// UpsertOverride 新增或替换指定模型的覆盖项
+func (s *ModelCatalogService) UpsertOverride(ctx context.Context, entry *ModelCatalogEntry) (*ModelCatalogEntry, error) {
+	if entry == nil {
+		return nil, infraerrors.BadRequest("INVALID_MODEL_CATALOG_OVERRIDE", "invalid request")
+	}
+	entry.Model = normalizeModelCatalogKey(entry.Model)
+	entry.PricingModel = strings.TrimSpace(entry.PricingModel)
+	entry.TokenizerFamily = strings.ToLower(strings.TrimSpace(entry.TokenizerFamily))
+	entry.Sources = nil
+	if entry.Model == "" || len(entry.Model) > modelCatalogMaxModelChars {
+		return nil, infraerrors.BadRequest("INVALID_MODEL_CATALOG_OVERRIDE", "model is required")
+	}
+	if entry.ContextWindow < 0 || entry.MaxOutputTokens < 0 {
+		return nil, infraerrors.BadRequest("INVALID_MODEL_CATALOG_OVERRIDE", "token limits must be non-negative")
+	}
+
+	overrides, err := s.ListOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry.UpdatedAt = s.nowFn()
+	replaced := false
+	for i := range overrides {
+		if overrides[i].Model == entry.Model {
+			overrides[i] = *entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		if len(overrides) >= modelCatalogMaxOverrides {
+			return nil, infraerrors.BadRequest("TOO_MANY_MODEL_CATALOG_OVERRIDES", "too many model catalog overrides")
+		}
+		overrides = append(overrides, *entry)
+	}
+	if err := s.save(ctx, overrides); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// DeleteOverride 删除指定模型的覆盖项
+func (s *ModelCatalogService) DeleteOverride(ctx context.Context, model string) error {
+	key := normalizeModelCatalogKey(model)
+	overrides, err := s.ListOverrides(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range overrides {
+		if overrides[i].Model == key {
+			overrides = append(overrides[:i], overrides[i+1:]...)
+			return s.save(ctx, overrides)
+		}
+	}
+	return ErrModelCatalogOverrideNotFound
+}
+
+func (s *ModelCatalogService) save(ctx context.Context, overrides []ModelCatalogEntry) error {
+	if s == nil || s.settingRepo == nil {
+		return errors.New("setting repository not initialized")
+	}
+	raw, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyModelCatalogOverrides, string(raw)); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.loaded = false
+	s.cache = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// loadOverrides 读取带短期缓存的覆盖项，避免每次请求都访问数据库
+func (s *ModelCatalogService) loadOverrides(ctx context.Context) map[string]ModelCatalogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded && s.nowFn().Sub(s.cachedAt) < modelCatalogCacheTTL {
+		return s.cache
+	}
+	overrides, err := s.ListOverrides(ctx)
+	if err != nil {
+		slog.Warn("model_catalog_overrides_load_failed", "error", err)
+		return s.cache
+	}
+	cache := make(map[string]ModelCatalogEntry, len(overrides))
+	for _, o := range overrides {
+		cache[o.Model] = o
+	}
+	s.cache = cache
+	s.cachedAt = s.nowFn()
+	s.loaded = true
+	return cache
+}
+
+// mergeModelCatalogEntry 用 src 中已设置的字段覆盖 dst
+func mergeModelCatalogEntry(dst, src *ModelCatalogEntry, source string) {
+	if src.ContextWindow > 0 {
+		dst.ContextWindow = src.ContextWindow
+	}
+	if src.MaxOutputTokens > 0 {
+		dst.MaxOutputTokens = src.MaxOutputTokens
+	}
+	if src.SupportsVision != nil {
+		dst.SupportsVision = src.SupportsVision
+	}
+	if src.SupportsTools != nil {
+		dst.SupportsTools = src.SupportsTools
+	}
+	if src.SupportsReasoning != nil {
+		dst.SupportsReasoning = src.SupportsReasoning
+	}
+	if src.TokenizerFamily != "" {
+		dst.TokenizerFamily = src.TokenizerFamily
+	}
+	dst.Sources = append(dst.Sources, source)
+}
+
+// pricingModelCatalogEntry LiteLLM 数据只在能力为 true 时给出字段，缺失不代表不支持，因此只采纳 true
+func pricingModelCatalogEntry(pricing *LiteLLMModelPricing) *ModelCatalogEntry {
+	entry := &ModelCatalogEntry{
+		ContextWindow:   pricing.MaxInputTokens,
+		MaxOutputTokens: pricing.MaxOutputTokens,
+	}
+	if pricing.SupportsVision {
+		entry.SupportsVision = boolPtr(true)
+	}
+	if pricing.SupportsFunctionCalling {
+		entry.SupportsTools = boolPtr(true)
+	}
+	if pricing.SupportsReasoning {
+		entry.SupportsReasoning = boolPtr(true)
+	}
+	return entry
+}
+
+// builtinModelCatalogEntry 按模型名推断分词器家族与图片生成模型等确定的能力
+func builtinModelCatalogEntry(key string) *ModelCatalogEntry {
+	entry := &ModelCatalogEntry{}
+	switch {
+	case key == "":
+		return entry
+	case strings.HasPrefix(key, "claude-"):
+		entry.TokenizerFamily = TokenizerFamilyClaude
+	case strings.HasPrefix(key, "gemini-"):
+		entry.TokenizerFamily = TokenizerFamilyGemini
+	case strings.HasPrefix(key, "gpt-"), strings.HasPrefix(key, "o1"), strings.HasPrefix(key, "o3"), strings.HasPrefix(key, "o4"), strings.HasPrefix(key, "codex-"):
+		entry.TokenizerFamily = TokenizerFamilyO200k
+	default:
+		return entry
+	}
+	// 图片生成模型接受图片输入，但不支持工具调用
+	if isOpenAIImageGenerationModel(key) || isImageGenerationModel(key) {
+		entry.SupportsVision = boolPtr(true)
+		entry.SupportsTools = boolPtr(false)
+	}
+	entry.Sources = []string{ModelCatalogSourceBuiltin}
+	return entry
+}
+
+func normalizeModelCatalogKey(model string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(model)), "models/")
+}
@@ -0,0 +1,104 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/antigravity"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestModelCatalogService() *ModelCatalogService {
+	repo := &rotationSettingRepoStub{settingRepoStub{values: map[string]string{}}}
+	pricing := &PricingService{
+		pricingData: map[string]*LiteLLMModelPricing{
+			"claude-sonnet-4-5": {
+				InputCostPerToken:       3e-6,
+				MaxInputTokens:          200000,
+				MaxOutputTokens:         64000,
+				SupportsVision:          true,
+				SupportsFunctionCalling: true,
+			},
+			"gpt-5": {InputCostPerToken: 1.25e-6, MaxInputTokens: 272000},
+		},
+	}
+	return NewModelCatalogService(repo, pricing)
+}
+
+func TestModelCatalogService_ResolveLayers(t *testing.T) {
+	svc := newTestModelCatalogService()
+	ctx := context.Background()
+
+	entry := svc.Resolve(ctx, "claude-sonnet-4-5")
+	require.Equal(t, 200000, entry.ContextWindow)
+	require.Equal(t, 64000, entry.MaxOutputTokens)
+	require.Equal(t, TokenizerFamilyClaude, entry.TokenizerFamily)
+	require.Equal(t, []string{ModelCatalogSourceBuiltin, ModelCatalogSourcePricing}, entry.Sources)
+	supported, known := svc.SupportsVision(ctx, "claude-sonnet-4-5")
+	require.True(t, supported)
+	require.True(t, known)
+
+	// 定价数据缺少能力字段时视为未知
+	_, known = svc.SupportsVision(ctx, "gpt-5")
+	require.False(t, known)
+
+	// 上游模型列表覆盖定价数据
+	svc.ObserveAntigravityModels(map[string]antigravity.ModelInfo{
+		"claude-sonnet-4-5": {MaxTokens: intPtrHelper(100000)},
+	})
+	require.Equal(t, 100000, svc.ContextWindow(ctx, "models/claude-sonnet-4-5"))
+
+	// 管理员覆盖优先级最高，并可指定定价引用
+	_, err := svc.UpsertOverride(ctx, &ModelCatalogEntry{Model: "my-sonnet", PricingModel: "claude-sonnet-4-5", SupportsVision: boolPtr(false)})
+	require.NoError(t, err)
+	entry = svc.Resolve(ctx, "My-Sonnet")
+	require.Equal(t, 200000, entry.ContextWindow)
+	require.Equal(t, "claude-sonnet-4-5", entry.PricingModel)
+	supported, known = svc.SupportsVision(ctx, "my-sonnet")
+	require.False(t, supported)
+	require.True(t, known)
+}
+
+func TestModelCatalogService_OverrideCRUD(t *testing.T) {
+	svc := newTestModelCatalogService()
+	ctx := context.Background()
+
+	_, err := svc.UpsertOverride(ctx, &ModelCatalogEntry{Model: " "})
+	require.Equal(t, "INVALID_MODEL_CATALOG_OVERRIDE", infraerrors.Reason(err))
+	_, err = svc.UpsertOverride(ctx, &ModelCatalogEntry{Model: "x", ContextWindow: -1})
+	require.Error(t, err)
+
+	_, err = svc.UpsertOverride(ctx, &ModelCatalogEntry{Model: "b-model", ContextWindow: 1000})
+	require.NoError(t, err)
+	_, err = svc.UpsertOverride(ctx, &ModelCatalogEntry{Model: "a-model", ContextWindow: 2000})
+	require.NoError(t, err)
+	_, err = svc.UpsertOverride(ctx, &ModelCatalogEntry{Model: "b-model", ContextWindow: 3000})
+	require.NoError(t, err)
+
+	overrides, err := svc.ListOverrides(ctx)
+	require.NoError(t, err)
+	require.Len(t, overrides, 2)
+	require.Equal(t, "a-model", overrides[0].Model)
+	require.Equal(t, 3000, overrides[1].ContextWindow)
+
+	require.NoError(t, svc.DeleteOverride(ctx, "B-MODEL"))
+	require.ErrorIs(t, svc.DeleteOverride(ctx, "b-model"), ErrModelCatalogOverrideNotFound)
+}
+
+func TestBuiltinModelCatalogEntry(t *testing.T) {
+	entry := builtinModelCatalogEntry("gpt-image-1")
+	require.Equal(t, TokenizerFamilyO200k, entry.TokenizerFamily)
+	require.NotNil(t, entry.SupportsTools)
+	require.False(t, *entry.SupportsTools)
+
+	entry = builtinModelCatalogEntry("gemini-2.5-flash-image")
+	require.Equal(t, TokenizerFamilyGemini, entry.TokenizerFamily)
+	require.True(t, *entry.SupportsVision)
+
+	entry = builtinModelCatalogEntry("llama-3")
+	require.Empty(t, entry.TokenizerFamily)
+	require.Empty(t, entry.Sources)
+}
@@ -19,7 +19,16 @@ type OAuthRefreshExecutor interface {
 	CacheKey(account *Account) string
 }
 
-const defaultRefreshLockTTL = 60 * time.Second
+const (
+	defaultRefreshLockTTL       = 60 * time.Second
+	defaultPeerWaitPollInterval = 250 * time.Millisecond
+)
+
+// refreshLockInspector 可选能力：查询分布式刷新锁是否仍被持有。
+// 实现该接口的 tokenCache 可以让等待方在锁释放前跳过 DB 重读。
+type refreshLockInspector interface {
+	IsRefreshLockHeld(ctx context.Context, cacheKey string) (bool, error)
+}
 
 // OAuthRefreshResult 统一刷新结果
 type OAuthRefreshResult struct {
@@ -27,6 +36,7 @@ type OAuthRefreshResult struct {
 	NewCredentials map[string]any // 刷新后的 credentials（nil 表示未刷新）
 	Account        *Account       // 从 DB 重新读取的最新 account
 	LockHeld       bool           // 锁被其他 worker 持有（未执行刷新）
+	SharedResult   bool           // 复用了其他副本的刷新结果（Account 为其刷新后的最新 account）
 }
 
 // OAuthRefreshAPI 统一的 OAuth Token 刷新入口
@@ -36,6 +46,10 @@ type OAuthRefreshAPI struct {
 	tokenCache  GeminiTokenCache // 可选，nil = 无分布式锁
 	lockTTL     time.Duration
 	localLocks  sync.Map // key: cacheKey string -> value: *sync.Mutex
+
+	// 锁被其他副本持有时等待其刷新结果（0 = 不等待，直接返回 LockHeld）
+	peerWaitTimeout      time.Duration
+	peerWaitPollInterval time.Duration
 }
 
 // NewOAuthRefreshAPI 创建统一刷新 API
@@ -52,6 +66,20 @@ func NewOAuthRefreshAPI(accountRepo AccountRepository, tokenCache GeminiTokenCac
 	}
 }
 
+// SetPeerWait 配置锁被其他副本持有时的等待策略。
+// 多副本部署下，等待方在 timeout 内轮询 DB，一旦发现对方已完成刷新即直接复用其结果，
+// 避免等待方使用即将过期的旧 token 或自行刷新导致对方的 refresh_token 失效。
+func (api *OAuthRefreshAPI) SetPeerWait(timeout, pollInterval time.Duration) {
+	if timeout < 0 {
+		timeout = 0
+	}
+	if pollInterval <= 0 {
+		pollInterval = defaultPeerWaitPollInterval
+	}
+	api.peerWaitTimeout = timeout
+	api.peerWaitPollInterval = pollInterval
+}
+
 // getLocalLock 返回指定 cacheKey 的进程内互斥锁
 func (api *OAuthRefreshAPI) getLocalLock(cacheKey string) *sync.Mutex {
 	actual, _ := api.localLocks.LoadOrStore(cacheKey, &sync.Mutex{})
@@ -97,7 +125,14 @@ func (api *OAuthRefreshAPI) RefreshIfNeeded(
 				"error", lockErr,
 			)
 		} else if !acquired {
-			// 锁被其他 worker 持有
+			// 锁被其他 worker 持有：在允许的时间内等待并复用其刷新结果
+			if shared := api.waitForPeerRefresh(ctx, account, executor, cacheKey, refreshWindow); shared != nil {
+				slog.Debug("oauth_refresh_peer_result_shared",
+					"account_id", account.ID,
+					"cache_key", cacheKey,
+				)
+				return &OAuthRefreshResult{Account: shared, SharedResult: true}, nil
+			}
 			return &OAuthRefreshResult{LockHeld: true}, nil
 		} else {
 			lockAcquired = true
@@ -165,6 +200,61 @@ func (api *OAuthRefreshAPI) RefreshIfNeeded(
 	}, nil
 }
 
+// waitForPeerRefresh 在锁被其他副本持有时轮询等待其刷新结果。
+// 返回 nil 表示超时、ctx 取消或对方刷新未成功（调用侧按 LockHeld 处理）。
+func (api *OAuthRefreshAPI) waitForPeerRefresh(
+	ctx context.Context,
+	account *Account,
+	executor OAuthRefreshExecutor,
+	cacheKey string,
+	refreshWindow time.Duration,
+) *Account {
+	if api.peerWaitTimeout <= 0 || api.accountRepo == nil {
+		return nil
+	}
+	interval := api.peerWaitPollInterval
+	if interval <= 0 {
+		interval = defaultPeerWaitPollInterval
+	}
+	inspector, _ := api.tokenCache.(refreshLockInspector)
+
+	timer := time.NewTimer(api.peerWaitTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+			return nil
+		case <-ticker.C:
+		}
+
+		lockReleased := false
+		if inspector != nil {
+			held, err := inspector.IsRefreshLockHeld(ctx, cacheKey)
+			if err == nil && held {
+				continue
+			}
+			lockReleased = err == nil
+		}
+
+		freshAccount, err := api.accountRepo.GetByID(ctx, account.ID)
+		if err != nil || freshAccount == nil {
+			continue
+		}
+		if !executor.NeedsRefresh(freshAccount, refreshWindow) {
+			return freshAccount
+		}
+		if lockReleased {
+			// 对方已释放锁但仍需刷新，说明其刷新失败，不再继续等待
+			return nil
+		}
+	}
+}
+
 // isInvalidGrantError 检查错误是否为 invalid_grant
 func isInvalidGrantError(err error) bool {
 	return err != nil && strings.Contains(strings.ToLower(err.Error()), "invalid_grant")
@@ -652,3 +652,77 @@ func TestAntigravityProviderRefreshPolicy(t *testing.T) {
 	require.Equal(t, ProviderLockHeldUseExistingToken, p.OnLockHeld)
 	require.Equal(t, time.Duration(0), p.FailureTTL)
 }
+
+// ========== peer refresh sharing tests ==========
+
+// peerRefreshExecutorStub 以 access_token 是否已更新判断是否仍需刷新
+type peerRefreshExecutorStub struct {
+	refreshAPIExecutorStub
+}
+
+func (e *peerRefreshExecutorStub) NeedsRefresh(account *Account, _ time.Duration) bool {
+	return account.GetCredential("access_token") != "peer-refreshed"
+}
+
+type peerLockCacheStub struct {
+	refreshAPICacheStub
+	held bool
+}
+
+func (c *peerLockCacheStub) IsRefreshLockHeld(context.Context, string) (bool, error) {
+	return c.held, nil
+}
+
+func TestRefreshIfNeeded_LockHeld_SharesPeerResult(t *testing.T) {
+	account := &Account{ID: 20, Platform: PlatformAnthropic, Credentials: map[string]any{"access_token": "old"}}
+	repo := &refreshAPIAccountRepo{account: &Account{
+		ID:          20,
+		Platform:    PlatformAnthropic,
+		Credentials: map[string]any{"access_token": "peer-refreshed"},
+	}}
+	cache := &refreshAPICacheStub{lockResult: false}
+	executor := &peerRefreshExecutorStub{}
+
+	api := NewOAuthRefreshAPI(repo, cache)
+	api.SetPeerWait(time.Second, 10*time.Millisecond)
+	result, err := api.RefreshIfNeeded(context.Background(), account, executor, 3*time.Minute)
+
+	require.NoError(t, err)
+	require.False(t, result.LockHeld)
+	require.True(t, result.SharedResult)
+	require.False(t, result.Refreshed)
+	require.Equal(t, "peer-refreshed", result.Account.GetCredential("access_token"))
+	require.Equal(t, 0, executor.refreshCalls)
+}
+
+func TestRefreshIfNeeded_LockHeld_PeerWaitTimesOut(t *testing.T) {
+	account := &Account{ID: 21, Platform: PlatformAnthropic, Credentials: map[string]any{"access_token": "old"}}
+	repo := &refreshAPIAccountRepo{account: account}
+	cache := &peerLockCacheStub{held: true}
+	executor := &peerRefreshExecutorStub{}
+
+	api := NewOAuthRefreshAPI(repo, cache)
+	api.SetPeerWait(50*time.Millisecond, 10*time.Millisecond)
+	result, err := api.RefreshIfNeeded(context.Background(), account, executor, 3*time.Minute)
+
+	require.NoError(t, err)
+	require.True(t, result.LockHeld)
+	require.False(t, result.SharedResult)
+	require.Equal(t, 0, executor.refreshCalls)
+}
+
+func TestRefreshIfNeeded_LockHeld_PeerReleasedWithoutRefresh(t *testing.T) {
+	account := &Account{ID: 22, Platform: PlatformAnthropic, Credentials: map[string]any{"access_token": "old"}}
+	repo := &refreshAPIAccountRepo{account: account}
+	cache := &peerLockCacheStub{held: false}
+	executor := &peerRefreshExecutorStub{}
+
+	api := NewOAuthRefreshAPI(repo, cache)
+	api.SetPeerWait(5*time.Second, 10*time.Millisecond)
+	start := time.Now()
+	result, err := api.RefreshIfNeeded(context.Background(), account, executor, 3*time.Minute)
+
+	require.NoError(t, err)
+	require.True(t, result.LockHeld)
+	require.Less(t, time.Since(start), time.Second, "should stop waiting once the peer released the lock")
+}
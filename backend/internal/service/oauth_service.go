@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/oauth"
@@ -158,6 +160,86 @@ func (s *OAuthService) ExchangeCode(ctx context.Context, input *ExchangeCodeInpu
 	return tokenInfo, nil
 }
 
+// OAuthAccountGrant 授权码兑换后可直接落库的账号凭据
+type OAuthAccountGrant struct {
+	TokenInfo   *TokenInfo
+	AccountType string // oauth 或 setup-token，由生成授权链接时的 scope 决定
+	Credentials map[string]any
+	Extra       map[string]any
+}
+
+// ExchangeCodeForAccount 兑换授权码并构建账号 credentials/extra，
+// 供管理端一步完成 Claude OAuth 账号的创建或重新授权。
+func (s *OAuthService) ExchangeCodeForAccount(ctx context.Context, input *ExchangeCodeInput) (*OAuthAccountGrant, error) {
+	session, ok := s.sessionStore.Get(input.SessionID)
+	if !ok {
+		return nil, fmt.Errorf("session not found or expired")
+	}
+	accountType := AccountTypeOAuth
+	if session.Scope == oauth.ScopeInference {
+		accountType = AccountTypeSetupToken
+	}
+
+	tokenInfo, err := s.ExchangeCode(ctx, &ExchangeCodeInput{
+		SessionID: input.SessionID,
+		Code:      NormalizeClaudeOAuthCode(input.Code),
+		ProxyID:   input.ProxyID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthAccountGrant{
+		TokenInfo:   tokenInfo,
+		AccountType: accountType,
+		Credentials: BuildClaudeAccountCredentials(tokenInfo),
+		Extra:       BuildClaudeAccountExtra(tokenInfo),
+	}, nil
+}
+
+// NormalizeClaudeOAuthCode 兼容管理员粘贴的几种授权码形式：
+// 纯 code、回调页展示的 "code#state"、以及完整的回调 URL（?code=...&state=...）。
+func NormalizeClaudeOAuthCode(raw string) string {
+	code := strings.TrimSpace(raw)
+	if !strings.HasPrefix(code, "http://") && !strings.HasPrefix(code, "https://") {
+		return code
+	}
+	u, err := url.Parse(code)
+	if err != nil {
+		return code
+	}
+	query := u.Query()
+	authCode := strings.TrimSpace(query.Get("code"))
+	if authCode == "" {
+		return code
+	}
+	if state := strings.TrimSpace(query.Get("state")); state != "" && !strings.Contains(authCode, "#") {
+		return authCode + "#" + state
+	}
+	return authCode
+}
+
+// BuildClaudeAccountExtra 从 TokenInfo 提取账号 extra 信息（组织/账号 UUID、邮箱）
+func BuildClaudeAccountExtra(tokenInfo *TokenInfo) map[string]any {
+	if tokenInfo == nil {
+		return nil
+	}
+	extra := make(map[string]any)
+	if tokenInfo.OrgUUID != "" {
+		extra["org_uuid"] = tokenInfo.OrgUUID
+	}
+	if tokenInfo.AccountUUID != "" {
+		extra["account_uuid"] = tokenInfo.AccountUUID
+	}
+	if tokenInfo.EmailAddress != "" {
+		extra["email_address"] = tokenInfo.EmailAddress
+	}
+	if len(extra) == 0 {
+		return nil
+	}
+	return extra
+}
+
 // CookieAuthInput represents the input for cookie-based authentication
 type CookieAuthInput struct {
 	SessionKey string
@@ -605,3 +605,33 @@ func TestOAuthService_Stop_NoPanic(t *testing.T) {
 	// 多次调用也不应 panic
 	svc.Stop()
 }
+
+func TestNormalizeClaudeOAuthCode(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "  abc#xyz  ", want: "abc#xyz"},
+		{raw: "https://console.anthropic.com/oauth/code/callback?code=abc&state=xyz", want: "abc#xyz"},
+		{raw: "https://console.anthropic.com/oauth/code/callback?code=abc", want: "abc"},
+		{raw: "https://console.anthropic.com/oauth/code/callback?state=xyz", want: "https://console.anthropic.com/oauth/code/callback?state=xyz"},
+	}
+	for _, tc := range cases {
+		if got := NormalizeClaudeOAuthCode(tc.raw); got != tc.want {
+			t.Errorf("NormalizeClaudeOAuthCode(%q) = %q, want %q", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestBuildClaudeAccountExtra(t *testing.T) {
+	if extra := BuildClaudeAccountExtra(&TokenInfo{}); extra != nil {
+		t.Fatalf("空 token 信息应返回 nil: got=%v", extra)
+	}
+	extra := BuildClaudeAccountExtra(&TokenInfo{OrgUUID: "org", EmailAddress: "a@b.c"})
+	if extra["org_uuid"] != "org" || extra["email_address"] != "a@b.c" {
+		t.Fatalf("extra 不匹配: %v", extra)
+	}
+	if _, ok := extra["account_uuid"]; ok {
+		t.Fatalf("不应包含空 account_uuid: %v", extra)
+	}
+}
@@ -650,6 +650,7 @@ func (s *defaultOpenAIAccountScheduler) selectByLoadBalance(
 		if loadInfo == nil {
 			loadInfo = &AccountLoadInfo{AccountID: account.ID}
 		}
+		loadInfo = withUpstreamRateLimitLoad(account, loadInfo, time.Now())
 		errorRate, ttft, hasTTFT := s.stats.snapshot(account.ID)
 		allCandidates = append(allCandidates, openAIAccountCandidateScore{
 			account:   account,
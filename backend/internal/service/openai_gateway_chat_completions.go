@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -14,6 +13,7 @@ import (
 
 	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
 	"github.com/Wei-Shaw/sub2api/internal/util/responseheaders"
 	"github.com/gin-gonic/gin"
 	"github.com/tidwall/gjson"
@@ -203,7 +203,7 @@ func (s *OpenAIGatewayService) ForwardAsChatCompletions(
 	if account.Proxy != nil {
 		proxyURL = account.Proxy.URL()
 	}
-	resp, err := s.httpUpstream.Do(upstreamReq, proxyURL, account.ID, account.Concurrency)
+	resp, err := s.httpUpstream.Do(withAccountTransport(upstreamReq, account), proxyURL, account.ID, account.Concurrency)
 	if err != nil {
 		safeErr := sanitizeUpstreamErrorMessage(err.Error())
 		setOpsUpstreamError(c, 0, safeErr, "")
@@ -222,7 +222,7 @@ func (s *OpenAIGatewayService) ForwardAsChatCompletions(
 
 	// 8. Handle error response with failover
 	if resp.StatusCode >= 400 {
-		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+		respBody, _ := readUpstreamErrorBody(resp.Body)
 		_ = resp.Body.Close()
 		resp.Body = io.NopCloser(bytes.NewReader(respBody))
 
@@ -262,8 +262,10 @@ func (s *OpenAIGatewayService) ForwardAsChatCompletions(
 	// 9. Handle normal response
 	var result *OpenAIForwardResult
 	var handleErr error
+	stopIdleTimeout := guardStreamIdleTimeout(ctx, resp, s.cfg, s.rateLimitService, account, billingModel)
+	defer stopIdleTimeout()
 	if clientStream {
-		result, handleErr = s.handleChatStreamingResponse(resp, c, originalModel, billingModel, upstreamModel, includeUsage, startTime)
+		result, handleErr = s.handleChatStreamingResponse(resp, c, originalModel, billingModel, upstreamModel, includeUsage, estimateChatPromptTokens(body, includeUsage), startTime)
 	} else {
 		result, handleErr = s.handleChatBufferedStreamingResponse(resp, c, originalModel, billingModel, upstreamModel, startTime)
 	}
@@ -348,12 +350,11 @@ func (s *OpenAIGatewayService) handleChatBufferedStreamingResponse(
 ) (*OpenAIForwardResult, error) {
 	requestID := resp.Header.Get("x-request-id")
 
-	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	scanner := sse.NewReader(resp.Body, maxLineSize)
 
 	var finalResponse *apicompat.ResponsesResponse
 	var usage OpenAIUsage
@@ -395,6 +396,12 @@ func (s *OpenAIGatewayService) handleChatBufferedStreamingResponse(
 	}
 
 	if err := scanner.Err(); err != nil {
+		if errors.Is(err, ErrStreamIdleTimeout) && finalResponse == nil {
+			return nil, handleBufferedStreamIdleTimeout(c, s.cfg, writeChatCompletionsError)
+		}
+		if errors.Is(err, sse.ErrLineTooLong) && finalResponse == nil {
+			return nil, handleBufferedLineTooLong(c, maxLineSize, requestID, writeChatCompletionsError)
+		}
 		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 			logger.L().Warn("openai chat_completions buffered: read error",
 				zap.Error(err),
@@ -415,7 +422,7 @@ func (s *OpenAIGatewayService) handleChatBufferedStreamingResponse(
 	chatResp := apicompat.ResponsesToChatCompletions(finalResponse, originalModel)
 
 	if s.responseHeaderFilter != nil {
-		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 	}
 	c.JSON(http.StatusOK, chatResp)
 
@@ -439,12 +446,13 @@ func (s *OpenAIGatewayService) handleChatStreamingResponse(
 	billingModel string,
 	upstreamModel string,
 	includeUsage bool,
+	estimatedPromptTokens int,
 	startTime time.Time,
 ) (*OpenAIForwardResult, error) {
 	requestID := resp.Header.Get("x-request-id")
 
 	if s.responseHeaderFilter != nil {
-		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, s.responseHeaderFilter)
+		responseheaders.WriteFilteredHeaders(c.Writer.Header(), resp.Header, groupResponseHeaderFilter(c, s.responseHeaderFilter))
 	}
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
@@ -455,17 +463,17 @@ func (s *OpenAIGatewayService) handleChatStreamingResponse(
 	state := apicompat.NewResponsesEventToChatState()
 	state.Model = originalModel
 	state.IncludeUsage = includeUsage
+	state.ToolArgsCoalesce = resolveToolArgsCoalesce(s.cfg)
 
 	var usage OpenAIUsage
 	var firstTokenMs *int
 	firstChunk := true
 
-	scanner := bufio.NewScanner(resp.Body)
 	maxLineSize := defaultMaxLineSize
 	if s.cfg != nil && s.cfg.Gateway.MaxLineSize > 0 {
 		maxLineSize = s.cfg.Gateway.MaxLineSize
 	}
-	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	scanner := sse.NewReader(resp.Body, maxLineSize)
 
 	resultWithUsage := func() *OpenAIForwardResult {
 		return &OpenAIForwardResult{
@@ -532,7 +540,10 @@ func (s *OpenAIGatewayService) handleChatStreamingResponse(
 	}
 
 	finalizeStream := func() (*OpenAIForwardResult, error) {
-		if finalChunks := apicompat.FinalizeResponsesChatStream(state); len(finalChunks) > 0 {
+		finalChunks := apicompat.FinalizeResponsesChatStream(state)
+		finalChunks = append(finalChunks, apicompat.FinalizeChatStreamUsage(state, chatStreamUsageFallback(
+			usage.InputTokens, usage.OutputTokens, usage.CacheReadInputTokens, estimatedPromptTokens, state.OutputChars))...)
+		if len(finalChunks) > 0 {
 			for _, chunk := range finalChunks {
 				sse, err := apicompat.ChatChunkToSSE(chunk)
 				if err != nil {
@@ -573,6 +584,12 @@ func (s *OpenAIGatewayService) handleChatStreamingResponse(
 				return resultWithUsage(), nil
 			}
 		}
+		if err := scanner.Err(); errors.Is(err, ErrStreamIdleTimeout) {
+			return resultWithUsage(), handleStreamingIdleTimeout(c, s.cfg, streamErrorEventChatCompletions)
+		}
+		if err := scanner.Err(); errors.Is(err, sse.ErrLineTooLong) {
+			return resultWithUsage(), handleStreamingLineTooLong(c, streamErrorEventChatCompletions, maxLineSize, requestID)
+		}
 		handleScanErr(scanner.Err())
 		return finalizeStream()
 	}
@@ -616,6 +633,12 @@ func (s *OpenAIGatewayService) handleChatStreamingResponse(
 				return finalizeStream()
 			}
 			if ev.err != nil {
+				if errors.Is(ev.err, ErrStreamIdleTimeout) {
+					return resultWithUsage(), handleStreamingIdleTimeout(c, s.cfg, streamErrorEventChatCompletions)
+				}
+				if errors.Is(ev.err, sse.ErrLineTooLong) {
+					return resultWithUsage(), handleStreamingLineTooLong(c, streamErrorEventChatCompletions, maxLineSize, requestID)
+				}
 				handleScanErr(ev.err)
 				return finalizeStream()
 			}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// openAIHTTPResponseIDContextKey 记录 HTTP Responses 请求中上游返回的 response.id，
+// 由 Forward 在请求成功后绑定到当前账号，供后续 previous_response_id 粘连调度使用。
+const openAIHTTPResponseIDContextKey = "openai_http_response_id"
+
+// openAIHTTPPreviousResponseSupported 判断账号在 HTTP 上游下是否支持 previous_response_id。
+// 仅 API Key 账号（OpenAI 平台 Responses API）在服务端保存响应；
+// OAuth 账号（Codex 上游）强制 store=false，无法续接历史响应。
+func openAIHTTPPreviousResponseSupported(account *Account) bool {
+	return account != nil && account.IsOpenAI() && account.Type == AccountTypeAPIKey
+}
+
+// setOpenAIHTTPResponseID 记录首个上游 response.id，后续调用不覆盖。
+func setOpenAIHTTPResponseID(c *gin.Context, responseID string) {
+	if c == nil {
+		return
+	}
+	responseID = strings.TrimSpace(responseID)
+	if responseID == "" {
+		return
+	}
+	if _, exists := c.Get(openAIHTTPResponseIDContextKey); exists {
+		return
+	}
+	c.Set(openAIHTTPResponseIDContextKey, responseID)
+}
+
+// captureOpenAIHTTPResponseIDFromSSE 从 response.* 事件中提取 response.id。
+// response.created 通常是首个事件，命中后即不再解析后续数据行。
+func captureOpenAIHTTPResponseIDFromSSE(c *gin.Context, data []byte) {
+	if c == nil || len(data) == 0 || !bytes.Contains(data, []byte(`"response.`)) {
+		return
+	}
+	if _, exists := c.Get(openAIHTTPResponseIDContextKey); exists {
+		return
+	}
+	if !strings.HasPrefix(gjson.GetBytes(data, "type").String(), "response.") {
+		return
+	}
+	setOpenAIHTTPResponseID(c, gjson.GetBytes(data, "response.id").String())
+}
+
+func getOpenAIHTTPResponseID(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	value, _ := c.Get(openAIHTTPResponseIDContextKey)
+	responseID, _ := value.(string)
+	return responseID
+}
+
+// bindOpenAIHTTPResponseAccount 将 HTTP Responses 请求返回的 response.id 绑定到服务该请求的账号，
+// 使携带 previous_response_id 的后续请求路由回同一上游账号（其他账号会返回 404）。
+func (s *OpenAIGatewayService) bindOpenAIHTTPResponseAccount(ctx context.Context, c *gin.Context, account *Account) string {
+	responseID := getOpenAIHTTPResponseID(c)
+	if s == nil || responseID == "" || !openAIHTTPPreviousResponseSupported(account) {
+		return responseID
+	}
+	store := s.getOpenAIWSStateStore()
+	if store == nil {
+		return responseID
+	}
+	groupID := getOpenAIGroupIDFromContext(c)
+	logOpenAIWSBindResponseAccountWarn(
+		groupID,
+		account.ID,
+		responseID,
+		store.BindResponseAccount(ctx, groupID, responseID, account.ID, s.openAIWSResponseStickyTTL()),
+	)
+	return responseID
+}
@@ -0,0 +1,52 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureOpenAIHTTPResponseIDFromSSE_KeepsFirstResponseID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	captureOpenAIHTTPResponseIDFromSSE(c, []byte(`{"type":"response.output_text.delta","delta":"hi"}`))
+	require.Empty(t, getOpenAIHTTPResponseID(c))
+
+	captureOpenAIHTTPResponseIDFromSSE(c, []byte(`{"type":"response.created","response":{"id":"resp_first"}}`))
+	captureOpenAIHTTPResponseIDFromSSE(c, []byte(`{"type":"response.completed","response":{"id":"resp_other"}}`))
+	require.Equal(t, "resp_first", getOpenAIHTTPResponseID(c))
+}
+
+func TestBindOpenAIHTTPResponseAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	groupID := int64(7)
+
+	newContext := func(responseID string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("api_key", &APIKey{ID: 1, GroupID: &groupID})
+		setOpenAIHTTPResponseID(c, responseID)
+		return c
+	}
+
+	store := NewOpenAIWSStateStore(&stubGatewayCache{})
+	svc := &OpenAIGatewayService{openaiWSStateStore: store}
+
+	apiKeyAccount := &Account{ID: 21, Platform: PlatformOpenAI, Type: AccountTypeAPIKey}
+	require.Equal(t, "resp_api", svc.bindOpenAIHTTPResponseAccount(ctx, newContext("resp_api"), apiKeyAccount))
+	accountID, err := store.GetResponseAccount(ctx, groupID, "resp_api")
+	require.NoError(t, err)
+	require.Equal(t, apiKeyAccount.ID, accountID)
+
+	oauthAccount := &Account{ID: 22, Platform: PlatformOpenAI, Type: AccountTypeOAuth}
+	require.Equal(t, "resp_oauth", svc.bindOpenAIHTTPResponseAccount(ctx, newContext("resp_oauth"), oauthAccount))
+	accountID, err = store.GetResponseAccount(ctx, groupID, "resp_oauth")
+	require.NoError(t, err)
+	require.Zero(t, accountID, "OAuth 账号不支持 HTTP previous_response_id，不应绑定")
+}
@@ -3896,9 +3896,15 @@ func (s *OpenAIGatewayService) SelectAccountByPreviousResponseID(
 		_ = store.DeleteResponseAccount(ctx, derefGroupID(groupID), responseID)
 		return nil, nil
 	}
-	// 非 WSv2 场景（如 force_http/全局关闭）不应使用 previous_response_id 粘连，
-	// 以保持“回滚到 HTTP”后的历史行为一致性。
-	if s.getOpenAIWSProtocolResolver().Resolve(account).Transport != OpenAIUpstreamTransportResponsesWebsocketV2 {
+	// 非 WSv2 场景（如 force_http/全局关闭）仅 API Key 账号支持 HTTP previous_response_id 续接，
+	// 其他账号不使用粘连，以保持“回滚到 HTTP”后的历史行为一致性。
+	switch s.getOpenAIWSProtocolResolver().Resolve(account).Transport {
+	case OpenAIUpstreamTransportResponsesWebsocketV2:
+	case OpenAIUpstreamTransportHTTPSSE:
+		if !openAIHTTPPreviousResponseSupported(account) {
+			return nil, nil
+		}
+	default:
 		return nil, nil
 	}
 	if shouldClearStickySession(account, requestedModel) || !account.IsOpenAI() || !account.IsSchedulable() {
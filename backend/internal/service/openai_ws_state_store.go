@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -13,12 +15,29 @@ import (
 
 const (
 	openAIWSResponseAccountCachePrefix = "openai:response:"
+	openAIWSTurnStateCachePrefix       = "openai:turn_state:"
 	openAIWSStateStoreCleanupInterval  = time.Minute
 	openAIWSStateStoreCleanupMaxPerMap = 512
 	openAIWSStateStoreMaxEntriesPerMap = 65536
 	openAIWSStateStoreRedisTimeout     = 3 * time.Second
 )
 
+// 粘性绑定变更通知的类型
+const (
+	openAIWSInvalidateResponse  = "response"
+	openAIWSInvalidateTurnState = "turn_state"
+)
+
+// SharedStickyStateCache 多副本共享的粘性状态存储与变更通知，由 Redis 后端的 GatewayCache 实现；
+// 进程内后端不实现该接口，此时状态仅在本进程内有效。
+type SharedStickyStateCache interface {
+	GetStickyState(ctx context.Context, key string) (string, error)
+	SetStickyState(ctx context.Context, key, value string, ttl time.Duration) error
+	DeleteStickyState(ctx context.Context, key string) error
+	PublishStickyInvalidation(ctx context.Context, message string) error
+	SubscribeStickyInvalidation(ctx context.Context, handler func(message string)) error
+}
+
 type openAIWSAccountBinding struct {
 	accountID int64
 	expiresAt time.Time
@@ -43,8 +62,9 @@ type openAIWSSessionConnBinding struct {
 // - response_id -> account_id 用于续链路由
 // - response_id -> conn_id 用于连接内上下文复用
 //
-// response_id -> account_id 优先走 GatewayCache（Redis），同时维护本地热缓存。
-// response_id -> conn_id 仅在本进程内有效。
+// response_id -> account_id 与 session -> turn_state 优先走 GatewayCache（Redis），同时维护本地热缓存；
+// 绑定变更时通过 Redis Pub/Sub 通知其他副本清理本地热缓存，保证多副本间一致。
+// response_id -> conn_id、session -> conn_id 指向本进程的上游连接，仅在本进程内有效。
 type OpenAIWSStateStore interface {
 	BindResponseAccount(ctx context.Context, groupID int64, responseID string, accountID int64, ttl time.Duration) error
 	GetResponseAccount(ctx context.Context, groupID int64, responseID string) (int64, error)
@@ -64,7 +84,10 @@ type OpenAIWSStateStore interface {
 }
 
 type defaultOpenAIWSStateStore struct {
-	cache GatewayCache
+	cache  GatewayCache
+	shared SharedStickyStateCache
+	// instanceID 用于忽略本副本发出的变更通知
+	instanceID string
 
 	responseToAccountMu  sync.RWMutex
 	responseToAccount    map[string]openAIWSAccountBinding
@@ -88,9 +111,51 @@ func NewOpenAIWSStateStore(cache GatewayCache) OpenAIWSStateStore {
 		sessionToConn:      make(map[string]openAIWSSessionConnBinding, 256),
 	}
 	store.lastCleanupUnixNano.Store(time.Now().UnixNano())
+	if shared, ok := cache.(SharedStickyStateCache); ok {
+		store.shared = shared
+		store.instanceID = newOpenAIWSStateStoreInstanceID()
+		if err := shared.SubscribeStickyInvalidation(context.Background(), store.handleInvalidation); err != nil {
+			// 订阅失败不影响主流程，本地热缓存按 TTL 过期
+			slog.Warn("failed to start sticky session invalidation subscriber", "error", err)
+		}
+	}
 	return store
 }
 
+func newOpenAIWSStateStoreInstanceID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// publishInvalidation 通知其他副本清理本地热缓存，失败时仅依赖 TTL 过期
+func (s *defaultOpenAIWSStateStore) publishInvalidation(ctx context.Context, kind, key string) {
+	if s.shared == nil {
+		return
+	}
+	cacheCtx, cancel := withOpenAIWSStateStoreRedisTimeout(ctx)
+	defer cancel()
+	_ = s.shared.PublishStickyInvalidation(cacheCtx, s.instanceID+"|"+kind+"|"+key)
+}
+
+// handleInvalidation 处理其他副本的绑定变更通知：删除本地热缓存，下次读取回源 Redis
+func (s *defaultOpenAIWSStateStore) handleInvalidation(message string) {
+	parts := strings.SplitN(message, "|", 3)
+	if len(parts) != 3 || parts[0] == s.instanceID {
+		return
+	}
+	switch parts[1] {
+	case openAIWSInvalidateResponse:
+		s.responseToAccountMu.Lock()
+		delete(s.responseToAccount, parts[2])
+		s.responseToAccountMu.Unlock()
+	case openAIWSInvalidateTurnState:
+		s.sessionToTurnStateMu.Lock()
+		delete(s.sessionToTurnState, parts[2])
+		s.sessionToTurnStateMu.Unlock()
+	}
+}
+
 func (s *defaultOpenAIWSStateStore) BindResponseAccount(ctx context.Context, groupID int64, responseID string, accountID int64, ttl time.Duration) error {
 	id := normalizeOpenAIWSResponseID(responseID)
 	if id == "" || accountID <= 0 {
@@ -111,7 +176,11 @@ func (s *defaultOpenAIWSStateStore) BindResponseAccount(ctx context.Context, gro
 	cacheKey := openAIWSResponseAccountCacheKey(id)
 	cacheCtx, cancel := withOpenAIWSStateStoreRedisTimeout(ctx)
 	defer cancel()
-	return s.cache.SetSessionAccountID(cacheCtx, groupID, cacheKey, accountID, ttl)
+	if err := s.cache.SetSessionAccountID(cacheCtx, groupID, cacheKey, accountID, ttl); err != nil {
+		return err
+	}
+	s.publishInvalidation(ctx, openAIWSInvalidateResponse, id)
+	return nil
 }
 
 func (s *defaultOpenAIWSStateStore) GetResponseAccount(ctx context.Context, groupID int64, responseID string) (int64, error) {
@@ -144,6 +213,13 @@ func (s *defaultOpenAIWSStateStore) GetResponseAccount(ctx context.Context, grou
 		// 缓存读取失败不阻断主流程，按未命中降级。
 		return 0, nil
 	}
+	if s.shared != nil {
+		// 其他副本写入的绑定回填本地热缓存；剩余 TTL 未知，按短时缓存处理，变更时由通知清理
+		s.responseToAccountMu.Lock()
+		ensureBindingCapacity(s.responseToAccount, id, openAIWSStateStoreMaxEntriesPerMap)
+		s.responseToAccount[id] = openAIWSAccountBinding{accountID: accountID, expiresAt: now.Add(openAIWSStateStoreCleanupInterval)}
+		s.responseToAccountMu.Unlock()
+	}
 	return accountID, nil
 }
 
@@ -161,7 +237,11 @@ func (s *defaultOpenAIWSStateStore) DeleteResponseAccount(ctx context.Context, g
 	}
 	cacheCtx, cancel := withOpenAIWSStateStoreRedisTimeout(ctx)
 	defer cancel()
-	return s.cache.DeleteSessionAccountID(cacheCtx, groupID, openAIWSResponseAccountCacheKey(id))
+	if err := s.cache.DeleteSessionAccountID(cacheCtx, groupID, openAIWSResponseAccountCacheKey(id)); err != nil {
+		return err
+	}
+	s.publishInvalidation(ctx, openAIWSInvalidateResponse, id)
+	return nil
 }
 
 func (s *defaultOpenAIWSStateStore) BindResponseConn(responseID, connID string, ttl time.Duration) {
@@ -225,6 +305,15 @@ func (s *defaultOpenAIWSStateStore) BindSessionTurnState(groupID int64, sessionH
 		expiresAt: time.Now().Add(ttl),
 	}
 	s.sessionToTurnStateMu.Unlock()
+
+	if s.shared == nil {
+		return
+	}
+	cacheCtx, cancel := withOpenAIWSStateStoreRedisTimeout(context.Background())
+	defer cancel()
+	if err := s.shared.SetStickyState(cacheCtx, openAIWSTurnStateCachePrefix+key, state, ttl); err == nil {
+		s.publishInvalidation(context.Background(), openAIWSInvalidateTurnState, key)
+	}
 }
 
 func (s *defaultOpenAIWSStateStore) GetSessionTurnState(groupID int64, sessionHash string) (string, bool) {
@@ -238,10 +327,24 @@ func (s *defaultOpenAIWSStateStore) GetSessionTurnState(groupID int64, sessionHa
 	s.sessionToTurnStateMu.RLock()
 	binding, ok := s.sessionToTurnState[key]
 	s.sessionToTurnStateMu.RUnlock()
-	if !ok || now.After(binding.expiresAt) || strings.TrimSpace(binding.turnState) == "" {
+	if ok && now.Before(binding.expiresAt) && strings.TrimSpace(binding.turnState) != "" {
+		return binding.turnState, true
+	}
+	if s.shared == nil {
+		return "", false
+	}
+
+	cacheCtx, cancel := withOpenAIWSStateStoreRedisTimeout(context.Background())
+	defer cancel()
+	state, err := s.shared.GetStickyState(cacheCtx, openAIWSTurnStateCachePrefix+key)
+	if err != nil || strings.TrimSpace(state) == "" {
 		return "", false
 	}
-	return binding.turnState, true
+	s.sessionToTurnStateMu.Lock()
+	ensureBindingCapacity(s.sessionToTurnState, key, openAIWSStateStoreMaxEntriesPerMap)
+	s.sessionToTurnState[key] = openAIWSTurnStateBinding{turnState: state, expiresAt: now.Add(openAIWSStateStoreCleanupInterval)}
+	s.sessionToTurnStateMu.Unlock()
+	return state, true
 }
 
 func (s *defaultOpenAIWSStateStore) DeleteSessionTurnState(groupID int64, sessionHash string) {
@@ -252,6 +355,15 @@ func (s *defaultOpenAIWSStateStore) DeleteSessionTurnState(groupID int64, sessio
 	s.sessionToTurnStateMu.Lock()
 	delete(s.sessionToTurnState, key)
 	s.sessionToTurnStateMu.Unlock()
+
+	if s.shared == nil {
+		return
+	}
+	cacheCtx, cancel := withOpenAIWSStateStoreRedisTimeout(context.Background())
+	defer cancel()
+	if err := s.shared.DeleteStickyState(cacheCtx, openAIWSTurnStateCachePrefix+key); err == nil {
+		s.publishInvalidation(context.Background(), openAIWSInvalidateTurnState, key)
+	}
 }
 
 func (s *defaultOpenAIWSStateStore) BindSessionConn(groupID int64, sessionHash, connID string, ttl time.Duration) {
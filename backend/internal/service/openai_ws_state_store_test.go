@@ -233,3 +233,77 @@ func TestWithOpenAIWSStateStoreRedisTimeout_WithParentContext(t *testing.T) {
 	_, ok := ctx.Deadline()
 	require.True(t, ok, "应附加短超时")
 }
+
+// sharedStickyStateStub 模拟 Redis 后端：多个副本共享同一份存储，变更通知同步投递给所有订阅者
+type sharedStickyStateStub struct {
+	*stubGatewayCache
+	states   map[string]string
+	handlers []func(string)
+}
+
+func (c *sharedStickyStateStub) GetStickyState(ctx context.Context, key string) (string, error) {
+	if v, ok := c.states[key]; ok {
+		return v, nil
+	}
+	return "", errors.New("not found")
+}
+
+func (c *sharedStickyStateStub) SetStickyState(ctx context.Context, key, value string, ttl time.Duration) error {
+	c.states[key] = value
+	return nil
+}
+
+func (c *sharedStickyStateStub) DeleteStickyState(ctx context.Context, key string) error {
+	delete(c.states, key)
+	return nil
+}
+
+func (c *sharedStickyStateStub) PublishStickyInvalidation(ctx context.Context, message string) error {
+	for _, h := range c.handlers {
+		h(message)
+	}
+	return nil
+}
+
+func (c *sharedStickyStateStub) SubscribeStickyInvalidation(ctx context.Context, handler func(string)) error {
+	c.handlers = append(c.handlers, handler)
+	return nil
+}
+
+func TestOpenAIWSStateStore_SharedBackendKeepsReplicasConsistent(t *testing.T) {
+	shared := &sharedStickyStateStub{stubGatewayCache: &stubGatewayCache{}, states: map[string]string{}}
+	replicaA := NewOpenAIWSStateStore(shared)
+	replicaB := NewOpenAIWSStateStore(shared)
+	ctx := context.Background()
+
+	require.NoError(t, replicaA.BindResponseAccount(ctx, 1, "resp_shared", 11, time.Minute))
+	accountID, err := replicaB.GetResponseAccount(ctx, 1, "resp_shared")
+	require.NoError(t, err)
+	require.Equal(t, int64(11), accountID)
+
+	// B 已缓存旧绑定，A 重新绑定后 B 的本地热缓存应被通知清理
+	require.NoError(t, replicaA.BindResponseAccount(ctx, 1, "resp_shared", 22, time.Minute))
+	accountID, err = replicaB.GetResponseAccount(ctx, 1, "resp_shared")
+	require.NoError(t, err)
+	require.Equal(t, int64(22), accountID)
+
+	require.NoError(t, replicaB.DeleteResponseAccount(ctx, 1, "resp_shared"))
+	accountID, err = replicaA.GetResponseAccount(ctx, 1, "resp_shared")
+	require.NoError(t, err)
+	require.Zero(t, accountID)
+
+	// turn state 跨副本可见（模拟客户端重连到另一个副本）
+	replicaA.BindSessionTurnState(1, "session_hash", "turn_state_a", time.Minute)
+	state, ok := replicaB.GetSessionTurnState(1, "session_hash")
+	require.True(t, ok)
+	require.Equal(t, "turn_state_a", state)
+
+	replicaA.BindSessionTurnState(1, "session_hash", "turn_state_b", time.Minute)
+	state, ok = replicaB.GetSessionTurnState(1, "session_hash")
+	require.True(t, ok)
+	require.Equal(t, "turn_state_b", state)
+
+	replicaB.DeleteSessionTurnState(1, "session_hash")
+	_, ok = replicaA.GetSessionTurnState(1, "session_hash")
+	require.False(t, ok)
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
 	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
 )
 
@@ -24,7 +25,7 @@ func (s *OpsService) listAllAccountsForOps(ctx context.Context, platformFilter s
 		accounts, pageInfo, err := s.accountRepo.ListWithFilters(ctx, pagination.PaginationParams{
 			Page:     page,
 			PageSize: opsAccountsPageSize,
-		}, platformFilter, "", "", "", 0, "")
+		}, platformFilter, "", "", "", 0, "", "")
 		if err != nil {
 			return nil, err
 		}
@@ -395,3 +396,15 @@ func (s *OpsService) GetUserConcurrencyStats(ctx context.Context) (map[int64]*Us
 
 	return result, &collectedAt, nil
 }
+
+// GetConcurrencySlotSnapshot returns raw slot occupancy per account/user plus the slots held
+// by this instance (with request IDs) and stuck-slot reaper statistics.
+func (s *OpsService) GetConcurrencySlotSnapshot(ctx context.Context) (*ConcurrencySlotSnapshot, error) {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return nil, err
+	}
+	if s.concurrencyService == nil {
+		return nil, infraerrors.ServiceUnavailable("CONCURRENCY_UNAVAILABLE", "concurrency service not available")
+	}
+	return s.concurrencyService.GetSlotSnapshot(ctx)
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// OpsDebugForwardInput 管理员调试转发请求：以指定 API Key 的身份、绕过调度直接发往指定账号。
+type OpsDebugForwardInput struct {
+	APIKey    *APIKey
+	AccountID int64
+	// Path 网关路径，决定请求协议：/v1/messages、/v1/responses、/v1beta/models/{model}:{action}
+	Path    string
+	Headers map[string]string
+	Body    []byte
+}
+
+// OpsDebugForwardResult 调试转发结果，包含客户端视角响应与完整的上游交互记录。
+type OpsDebugForwardResult struct {
+	APIKeyID    int64  `json:"api_key_id"`
+	AccountID   int64  `json:"account_id"`
+	AccountName string `json:"account_name"`
+	Platform    string `json:"platform"`
+
+	Status            string            `json:"status"`
+	HTTPStatusCode    int               `json:"http_status_code"`
+	UpstreamRequestID string            `json:"upstream_request_id,omitempty"`
+	ResponseHeaders   map[string]string `json:"response_headers"`
+	ResponseBody      string            `json:"response_body"`
+	ResponseTruncated bool              `json:"response_truncated"`
+	ErrorMessage      string            `json:"error_message,omitempty"`
+	DurationMs        int64             `json:"duration_ms"`
+
+	// Upstream 实际发往上游的请求与响应（含网关内部重试），敏感头已脱敏
+	Upstream []UpstreamExchange `json:"upstream"`
+}
+
+// DebugForward 以指定 API Key 身份将请求直接转发到指定账号，并返回完整的上游请求/响应详情。
+//
+// 与正常网关请求的区别：
+//   - 不做账号调度，也不检查账号是否可调度，便于排查已被限流/停用账号的具体错误
+//   - 仍占用账号并发槽位，避免调试请求压垮上游
+//   - 不写入使用记录、不扣费
+func (s *OpsService) DebugForward(ctx context.Context, input OpsDebugForwardInput) (*OpsDebugForwardResult, error) {
+	if input.APIKey == nil {
+		return nil, infraerrors.BadRequest("DEBUG_FORWARD_API_KEY_REQUIRED", "api key is required")
+	}
+	if len(input.Body) == 0 {
+		return nil, infraerrors.BadRequest("DEBUG_FORWARD_EMPTY_BODY", "request body is required")
+	}
+	if s.accountRepo == nil {
+		return nil, infraerrors.ServiceUnavailable("DEBUG_FORWARD_UNAVAILABLE", "account repository not available")
+	}
+
+	path := strings.TrimSpace(input.Path)
+	if path == "" {
+		path = "/v1/messages"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	reqType := detectOpsRetryType(path)
+
+	account, err := s.accountRepo.GetByID(ctx, input.AccountID)
+	if err != nil {
+		if errors.Is(err, ErrAccountNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("get account: %w", err)
+	}
+	if account == nil {
+		return nil, ErrAccountNotFound
+	}
+	if err := checkDebugForwardPlatform(reqType, account.Platform); err != nil {
+		return nil, err
+	}
+
+	// 复用重试链路的头部白名单（anthropic-beta / anthropic-version），不透传任何认证头
+	headers := make(map[string]string, len(input.Headers))
+	for k, v := range input.Headers {
+		headers[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	errorLog := &OpsErrorLogDetail{UserAgent: headers["user-agent"]}
+	errorLog.RequestPath = path
+	if len(headers) > 0 {
+		if raw, err := json.Marshal(headers); err == nil {
+			errorLog.RequestHeaders = string(raw)
+		}
+	}
+	if reqType == opsRetryTypeGeminiV1B {
+		model, action, ok := parseGeminiDebugPath(path)
+		if !ok {
+			return nil, infraerrors.BadRequest("DEBUG_FORWARD_INVALID_PATH", "gemini path must look like /v1beta/models/{model}:{action}")
+		}
+		errorLog.Model = model
+		errorLog.Stream = action == "streamGenerateContent"
+	}
+
+	var release func()
+	if s.concurrencyService != nil {
+		acq, err := s.concurrencyService.AcquireAccountSlot(ctx, account.ID, account.Concurrency)
+		if err != nil {
+			return nil, fmt.Errorf("acquire account slot: %w", err)
+		}
+		if acq == nil || !acq.Acquired {
+			return nil, infraerrors.TooManyRequests("DEBUG_FORWARD_ACCOUNT_BUSY", "account concurrency limit reached")
+		}
+		release = acq.ReleaseFunc
+	}
+	if release != nil {
+		defer release()
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, opsRetryTimeout)
+	defer cancel()
+	capture := &UpstreamCapture{}
+	execCtx = WithUpstreamCapture(execCtx, capture)
+	if group := input.APIKey.Group; IsGroupContextValid(group) {
+		execCtx = context.WithValue(execCtx, ctxkey.Group, group)
+	}
+
+	c, w := newOpsRetryContext(execCtx, errorLog)
+	c.Set("api_key", input.APIKey)
+
+	startedAt := time.Now()
+	exec := s.executeWithAccountContext(execCtx, c, w, reqType, errorLog, input.Body, account)
+
+	responseHeaders := make(map[string]string, len(w.Header()))
+	for k, vs := range w.Header() {
+		responseHeaders[k] = strings.Join(vs, ", ")
+	}
+	return &OpsDebugForwardResult{
+		APIKeyID:          input.APIKey.ID,
+		AccountID:         account.ID,
+		AccountName:       account.Name,
+		Platform:          account.Platform,
+		Status:            exec.status,
+		HTTPStatusCode:    exec.httpStatusCode,
+		UpstreamRequestID: exec.upstreamRequestID,
+		ResponseHeaders:   responseHeaders,
+		ResponseBody:      string(w.bodyBytes()),
+		ResponseTruncated: w.truncated(),
+		ErrorMessage:      exec.errorMessage,
+		DurationMs:        time.Since(startedAt).Milliseconds(),
+		Upstream:          capture.Exchanges(),
+	}, nil
+}
+
+func checkDebugForwardPlatform(reqType opsRetryRequestType, platform string) error {
+	ok := true
+	switch reqType {
+	case opsRetryTypeOpenAI:
+		ok = platform == PlatformOpenAI
+	case opsRetryTypeGeminiV1B:
+		ok = platform == PlatformGemini || platform == PlatformAntigravity
+	case opsRetryTypeMessages:
+		ok = platform != PlatformOpenAI
+	}
+	if !ok {
+		return infraerrors.BadRequest("DEBUG_FORWARD_PLATFORM_MISMATCH",
+			fmt.Sprintf("account platform %s does not support this request path", platform))
+	}
+	return nil
+}
+
+// parseGeminiDebugPath 从 /v1beta/models/{model}:{action} 中解析模型与动作。
+func parseGeminiDebugPath(path string) (model, action string, ok bool) {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	_, rest, found := strings.Cut(path, "/models/")
+	if !found {
+		return "", "", false
+	}
+	model, action, found = strings.Cut(rest, ":")
+	model = strings.TrimSpace(model)
+	action = strings.TrimSpace(action)
+	if !found || model == "" || action == "" {
+		return "", "", false
+	}
+	return model, action, true
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+const (
+	defaultOpsFailoverTopN = 10
+	maxOpsFailoverTopN     = 100
+)
+
+// GetFailoverTrend 按时间桶统计账号切换（failover）次数
+func (s *OpsService) GetFailoverTrend(ctx context.Context, filter *OpsDashboardFilter, bucketSeconds int) (*OpsFailoverTrendResponse, error) {
+	if err := s.validateOpsFailoverFilter(ctx, filter); err != nil {
+		return nil, err
+	}
+	return s.opsRepo.GetFailoverTrend(ctx, filter, bucketSeconds)
+}
+
+// GetFailoverStats 汇总账号切换原因：按账号/平台/上游状态码的切换次数、高频错误信息与平均切换次数。
+// topN<=0 时使用默认值 10。
+func (s *OpsService) GetFailoverStats(ctx context.Context, filter *OpsDashboardFilter, topN int) (*OpsFailoverStatsResponse, error) {
+	if err := s.validateOpsFailoverFilter(ctx, filter); err != nil {
+		return nil, err
+	}
+	if topN <= 0 {
+		topN = defaultOpsFailoverTopN
+	}
+	if topN > maxOpsFailoverTopN {
+		return nil, infraerrors.BadRequest("OPS_TOPN_INVALID", "top_n must be between 1 and 100")
+	}
+	return s.opsRepo.GetFailoverStats(ctx, filter, topN)
+}
+
+func (s *OpsService) validateOpsFailoverFilter(ctx context.Context, filter *OpsDashboardFilter) error {
+	if err := s.RequireMonitoringEnabled(ctx); err != nil {
+		return err
+	}
+	if s.opsRepo == nil {
+		return infraerrors.ServiceUnavailable("OPS_REPO_UNAVAILABLE", "Ops repository not available")
+	}
+	if filter == nil {
+		return infraerrors.BadRequest("OPS_FILTER_REQUIRED", "filter is required")
+	}
+	if filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+		return infraerrors.BadRequest("OPS_TIME_RANGE_REQUIRED", "start_time/end_time are required")
+	}
+	if filter.StartTime.After(filter.EndTime) {
+		return infraerrors.BadRequest("OPS_TIME_RANGE_INVALID", "start_time must be <= end_time")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import "time"
+
+// Failover analytics are aggregated from ops_error_logs.upstream_errors: every
+// event whose kind is a failover kind (see OpsFailoverEventKinds) counts as one
+// account switch, attributed to the account that failed.
+
+// OpsFailoverEventKinds lists the upstream error event kinds that represent a
+// switch to another account.
+var OpsFailoverEventKinds = []string{"failover", "retry_exhausted_failover", "failover_on_400"}
+
+type OpsFailoverTrendPoint struct {
+	BucketStart time.Time `json:"bucket_start"`
+
+	// FailoverCount is the number of account switches in the bucket.
+	FailoverCount int64 `json:"failover_count"`
+	// RequestCount is the number of requests that switched at least once.
+	RequestCount int64 `json:"request_count"`
+}
+
+type OpsFailoverTrendResponse struct {
+	Bucket string                   `json:"bucket"`
+	Points []*OpsFailoverTrendPoint `json:"points"`
+}
+
+type OpsFailoverSummary struct {
+	FailoverCount int64 `json:"failover_count"`
+	// RequestCount is the number of requests that switched at least once.
+	RequestCount int64 `json:"request_count"`
+	// RecoveredCount is the number of those requests that still succeeded.
+	RecoveredCount int64 `json:"recovered_count"`
+	// AvgSwitchesPerRequest is FailoverCount / RequestCount.
+	AvgSwitchesPerRequest float64 `json:"avg_switches_per_request"`
+}
+
+type OpsFailoverAccountItem struct {
+	AccountID     int64  `json:"account_id"`
+	AccountName   string `json:"account_name"`
+	Platform      string `json:"platform"`
+	FailoverCount int64  `json:"failover_count"`
+	RequestCount  int64  `json:"request_count"`
+}
+
+type OpsFailoverPlatformItem struct {
+	Platform      string `json:"platform"`
+	FailoverCount int64  `json:"failover_count"`
+	RequestCount  int64  `json:"request_count"`
+}
+
+type OpsFailoverStatusCodeItem struct {
+	// StatusCode is the upstream status code of the failed attempt (0 = network/request error).
+	StatusCode    int   `json:"status_code"`
+	FailoverCount int64 `json:"failover_count"`
+}
+
+type OpsFailoverMessageItem struct {
+	Message       string    `json:"message"`
+	FailoverCount int64     `json:"failover_count"`
+	AccountCount  int64     `json:"account_count"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+}
+
+type OpsFailoverStatsResponse struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	TopN      int       `json:"top_n"`
+
+	Summary      *OpsFailoverSummary          `json:"summary"`
+	ByAccount    []*OpsFailoverAccountItem    `json:"by_account"`
+	ByPlatform   []*OpsFailoverPlatformItem   `json:"by_platform"`
+	ByStatusCode []*OpsFailoverStatusCodeItem `json:"by_status_code"`
+	TopMessages  []*OpsFailoverMessageItem    `json:"top_messages"`
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type failoverStatsRepoStub struct {
+	OpsRepository
+	capturedTopN int
+}
+
+func (s *failoverStatsRepoStub) GetFailoverStats(ctx context.Context, filter *OpsDashboardFilter, topN int) (*OpsFailoverStatsResponse, error) {
+	s.capturedTopN = topN
+	return &OpsFailoverStatsResponse{TopN: topN}, nil
+}
+
+func TestOpsServiceGetFailoverStats(t *testing.T) {
+	now := time.Now().UTC()
+	repo := &failoverStatsRepoStub{}
+	svc := &OpsService{opsRepo: repo}
+
+	_, err := svc.GetFailoverStats(context.Background(), nil, 0)
+	require.Equal(t, "OPS_FILTER_REQUIRED", infraerrors.Reason(err))
+
+	_, err = svc.GetFailoverStats(context.Background(), &OpsDashboardFilter{StartTime: now, EndTime: now.Add(-time.Minute)}, 0)
+	require.Equal(t, "OPS_TIME_RANGE_INVALID", infraerrors.Reason(err))
+
+	filter := &OpsDashboardFilter{StartTime: now.Add(-time.Hour), EndTime: now}
+	_, err = svc.GetFailoverStats(context.Background(), filter, 101)
+	require.Equal(t, "OPS_TOPN_INVALID", infraerrors.Reason(err))
+
+	// top_n 未指定时使用默认值
+	resp, err := svc.GetFailoverStats(context.Background(), filter, 0)
+	require.NoError(t, err)
+	require.Equal(t, 10, resp.TopN)
+	require.Equal(t, 10, repo.capturedTopN)
+}
@@ -89,6 +89,12 @@ type OpsErrorLogDetail struct {
 	ResponseLatencyMs  *int64 `json:"response_latency_ms"`
 	TimeToFirstTokenMs *int64 `json:"time_to_first_token_ms"`
 
+	// Upstream network timing breakdown of the last attempt (optional)
+	UpstreamDNSMs       *int64 `json:"upstream_dns_ms"`
+	UpstreamConnectMs   *int64 `json:"upstream_connect_ms"`
+	UpstreamTLSMs       *int64 `json:"upstream_tls_ms"`
+	UpstreamFirstByteMs *int64 `json:"upstream_first_byte_ms"`
+
 	// Retry context
 	RequestBody          string `json:"request_body"`
 	RequestBodyTruncated bool   `json:"request_body_truncated"`
@@ -33,6 +33,8 @@ type OpsRepository interface {
 	GetErrorTrend(ctx context.Context, filter *OpsDashboardFilter, bucketSeconds int) (*OpsErrorTrendResponse, error)
 	GetErrorDistribution(ctx context.Context, filter *OpsDashboardFilter) (*OpsErrorDistributionResponse, error)
 	GetOpenAITokenStats(ctx context.Context, filter *OpsOpenAITokenStatsFilter) (*OpsOpenAITokenStatsResponse, error)
+	GetFailoverTrend(ctx context.Context, filter *OpsDashboardFilter, bucketSeconds int) (*OpsFailoverTrendResponse, error)
+	GetFailoverStats(ctx context.Context, filter *OpsDashboardFilter, topN int) (*OpsFailoverStatsResponse, error)
 
 	InsertSystemMetrics(ctx context.Context, input *OpsInsertSystemMetricsInput) error
 	GetLatestSystemMetrics(ctx context.Context, windowMinutes int) (*OpsSystemMetricsSnapshot, error)
@@ -121,6 +123,12 @@ type OpsInsertErrorLogInput struct {
 	ResponseLatencyMs  *int64
 	TimeToFirstTokenMs *int64
 
+	// 最后一次上游尝试的网络阶段耗时（httptrace）
+	UpstreamDNSMs       *int64
+	UpstreamConnectMs   *int64
+	UpstreamTLSMs       *int64
+	UpstreamFirstByteMs *int64
+
 	RequestBodyJSON      *string // sanitized json string (not raw bytes)
 	RequestBodyTruncated bool
 	RequestBodyBytes     *int
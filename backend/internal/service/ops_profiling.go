@@ -0,0 +1,213 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultOpsMaxProfileDuration          = 60 * time.Second
+	defaultOpsMaxLogLevelOverrideDuration = 2 * time.Hour
+	// DefaultOpsLogLevelOverrideDuration 未指定时长时临时日志级别的默认持续时间
+	DefaultOpsLogLevelOverrideDuration = 15 * time.Minute
+)
+
+var (
+	ErrOpsProfilingDisabled = infraerrors.NotFound("OPS_PROFILING_DISABLED", "Ops profiling is disabled")
+	ErrOpsInvalidLogLevel   = infraerrors.BadRequest("OPS_INVALID_LOG_LEVEL", "level must be one of debug, info, warn, error")
+)
+
+// OpsLogLevelOverride 临时日志级别覆盖状态。
+type OpsLogLevelOverride struct {
+	Level           string     `json:"level"`
+	BaselineLevel   string     `json:"baseline_level"`
+	Active          bool       `json:"active"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	UpdatedByUserID int64      `json:"updated_by_user_id,omitempty"`
+}
+
+// opsLogLevelOverrideState 记录临时覆盖前的基线级别，到期后自动恢复，不写入持久化的运行时日志配置。
+type opsLogLevelOverrideState struct {
+	mu         sync.Mutex
+	active     bool
+	level      string
+	baseline   string
+	expiresAt  time.Time
+	operatorID int64
+	timer      *time.Timer
+}
+
+// ProfilingEnabled 返回管理端 pprof 与临时日志级别接口是否开放。
+func (s *OpsService) ProfilingEnabled() bool {
+	if s == nil {
+		return false
+	}
+	return s.cfg == nil || s.cfg.Ops.Profiling.Enabled
+}
+
+// RequireProfilingEnabled 未开放性能剖析时返回 ErrOpsProfilingDisabled。
+func (s *OpsService) RequireProfilingEnabled() error {
+	if s.ProfilingEnabled() {
+		return nil
+	}
+	return ErrOpsProfilingDisabled
+}
+
+// MaxProfileDuration 返回 CPU profile / trace 单次采样允许的最长时长。
+func (s *OpsService) MaxProfileDuration() time.Duration {
+	if s == nil || s.cfg == nil || s.cfg.Ops.Profiling.MaxProfileSeconds <= 0 {
+		return defaultOpsMaxProfileDuration
+	}
+	return time.Duration(s.cfg.Ops.Profiling.MaxProfileSeconds) * time.Second
+}
+
+func (s *OpsService) maxLogLevelOverrideDuration() time.Duration {
+	if s == nil || s.cfg == nil || s.cfg.Ops.Profiling.MaxLogLevelOverrideMinutes <= 0 {
+		return defaultOpsMaxLogLevelOverrideDuration
+	}
+	return time.Duration(s.cfg.Ops.Profiling.MaxLogLevelOverrideMinutes) * time.Minute
+}
+
+// GetLogLevelOverride 返回当前日志级别及临时覆盖状态。
+func (s *OpsService) GetLogLevelOverride() *OpsLogLevelOverride {
+	st := &s.logLevelOverride
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.snapshotLocked()
+}
+
+// SetLogLevelOverride 临时调整日志级别，duration 到期后自动恢复到覆盖前的级别。
+// duration <= 0 时使用默认时长（不超过配置上限）；超过配置上限时返回错误。
+func (s *OpsService) SetLogLevelOverride(level string, duration time.Duration, operatorID int64) (*OpsLogLevelOverride, error) {
+	if err := s.RequireProfilingEnabled(); err != nil {
+		return nil, err
+	}
+	level = strings.ToLower(strings.TrimSpace(level))
+	if !isValidOpsLogLevel(level) {
+		return nil, ErrOpsInvalidLogLevel
+	}
+	maxDuration := s.maxLogLevelOverrideDuration()
+	if duration <= 0 {
+		duration = min(DefaultOpsLogLevelOverrideDuration, maxDuration)
+	}
+	if duration > maxDuration {
+		return nil, infraerrors.BadRequest("OPS_LOG_LEVEL_DURATION_TOO_LONG", "duration exceeds max "+maxDuration.String())
+	}
+
+	st := &s.logLevelOverride
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	baseline := logger.CurrentLevel()
+	if st.active {
+		// 连续覆盖时保留最初的基线级别
+		baseline = st.baseline
+	}
+	if err := logger.SetLevel(level); err != nil {
+		return nil, infraerrors.BadRequest("OPS_INVALID_LOG_LEVEL", err.Error())
+	}
+	if st.timer != nil {
+		st.timer.Stop()
+	}
+	st.active = true
+	st.level = level
+	st.baseline = baseline
+	st.expiresAt = time.Now().Add(duration)
+	st.operatorID = operatorID
+	st.timer = time.AfterFunc(duration, func() { s.expireLogLevelOverride(level) })
+
+	logger.With(
+		zap.String("component", "audit.log_level_override"),
+		zap.String("action", "set"),
+		zap.Int64("operator_id", operatorID),
+		zap.String("level", level),
+		zap.String("baseline_level", baseline),
+		zap.Duration("duration", duration),
+	).Info("runtime log level overridden")
+
+	return st.snapshotLocked(), nil
+}
+
+// ClearLogLevelOverride 立即结束临时覆盖并恢复基线级别；无覆盖时直接返回当前状态。
+func (s *OpsService) ClearLogLevelOverride(operatorID int64) (*OpsLogLevelOverride, error) {
+	if err := s.RequireProfilingEnabled(); err != nil {
+		return nil, err
+	}
+	st := &s.logLevelOverride
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if !st.active {
+		return st.snapshotLocked(), nil
+	}
+	restored := st.restoreLocked()
+	logger.With(
+		zap.String("component", "audit.log_level_override"),
+		zap.String("action", "cleared"),
+		zap.Int64("operator_id", operatorID),
+		zap.String("level", logger.CurrentLevel()),
+		zap.Bool("restored", restored),
+	).Info("runtime log level override cleared")
+	return st.snapshotLocked(), nil
+}
+
+func (s *OpsService) expireLogLevelOverride(level string) {
+	st := &s.logLevelOverride
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if !st.active || st.level != level || time.Now().Before(st.expiresAt) {
+		return
+	}
+	restored := st.restoreLocked()
+	logger.With(
+		zap.String("component", "audit.log_level_override"),
+		zap.String("action", "expired"),
+		zap.String("level", logger.CurrentLevel()),
+		zap.Bool("restored", restored),
+	).Info("runtime log level override expired")
+}
+
+// restoreLocked 恢复基线级别。若期间运行时日志配置已被修改（当前级别不再是覆盖值），则保留新配置。
+func (st *opsLogLevelOverrideState) restoreLocked() bool {
+	if st.timer != nil {
+		st.timer.Stop()
+		st.timer = nil
+	}
+	restored := false
+	if logger.CurrentLevel() == st.level {
+		restored = logger.SetLevel(st.baseline) == nil
+	}
+	st.active = false
+	st.level = ""
+	st.baseline = ""
+	st.expiresAt = time.Time{}
+	st.operatorID = 0
+	return restored
+}
+
+func (st *opsLogLevelOverrideState) snapshotLocked() *OpsLogLevelOverride {
+	out := &OpsLogLevelOverride{Level: logger.CurrentLevel()}
+	if !st.active {
+		out.BaselineLevel = out.Level
+		return out
+	}
+	expiresAt := st.expiresAt.UTC()
+	out.Active = true
+	out.BaselineLevel = st.baseline
+	out.ExpiresAt = &expiresAt
+	out.UpdatedByUserID = st.operatorID
+	return out
+}
+
+func isValidOpsLogLevel(level string) bool {
+	switch level {
+	case "debug", "info", "warn", "error":
+		return true
+	default:
+		return false
+	}
+}
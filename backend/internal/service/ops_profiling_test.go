@@ -0,0 +1,86 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func newOpsProfilingServiceForTest(t *testing.T) *OpsService {
+	t.Helper()
+	logger.InitBootstrap()
+	baseline := logger.CurrentLevel()
+	require.NoError(t, logger.SetLevel("info"))
+	t.Cleanup(func() { _ = logger.SetLevel(baseline) })
+
+	cfg := &config.Config{}
+	cfg.Ops.Profiling = config.OpsProfilingConfig{Enabled: true, MaxProfileSeconds: 30, MaxLogLevelOverrideMinutes: 10}
+	return &OpsService{cfg: cfg}
+}
+
+func TestOpsService_LogLevelOverrideSetAndClear(t *testing.T) {
+	svc := newOpsProfilingServiceForTest(t)
+
+	got, err := svc.SetLogLevelOverride("DEBUG", 0, 7)
+	require.NoError(t, err)
+	require.True(t, got.Active)
+	require.Equal(t, "debug", got.Level)
+	require.Equal(t, "info", got.BaselineLevel)
+	require.Equal(t, int64(7), got.UpdatedByUserID)
+	require.WithinDuration(t, time.Now().Add(10*time.Minute), *got.ExpiresAt, 5*time.Second, "默认时长不超过配置上限")
+
+	// 连续覆盖保留最初的基线级别
+	got, err = svc.SetLogLevelOverride("warn", time.Minute, 7)
+	require.NoError(t, err)
+	require.Equal(t, "warn", logger.CurrentLevel())
+	require.Equal(t, "info", got.BaselineLevel)
+
+	got, err = svc.ClearLogLevelOverride(7)
+	require.NoError(t, err)
+	require.False(t, got.Active)
+	require.Equal(t, "info", logger.CurrentLevel())
+}
+
+func TestOpsService_LogLevelOverrideExpires(t *testing.T) {
+	svc := newOpsProfilingServiceForTest(t)
+
+	_, err := svc.SetLogLevelOverride("debug", 20*time.Millisecond, 1)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return !svc.GetLogLevelOverride().Active
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, "info", logger.CurrentLevel())
+}
+
+func TestOpsService_LogLevelOverrideKeepsConcurrentRuntimeChange(t *testing.T) {
+	svc := newOpsProfilingServiceForTest(t)
+
+	_, err := svc.SetLogLevelOverride("debug", time.Minute, 1)
+	require.NoError(t, err)
+	// 覆盖期间运行时日志配置被修改，恢复时不应覆盖新配置
+	require.NoError(t, logger.SetLevel("error"))
+
+	_, err = svc.ClearLogLevelOverride(1)
+	require.NoError(t, err)
+	require.Equal(t, "error", logger.CurrentLevel())
+}
+
+func TestOpsService_LogLevelOverrideValidation(t *testing.T) {
+	svc := newOpsProfilingServiceForTest(t)
+
+	_, err := svc.SetLogLevelOverride("verbose", time.Minute, 1)
+	require.ErrorIs(t, err, ErrOpsInvalidLogLevel)
+	_, err = svc.SetLogLevelOverride("debug", time.Hour, 1)
+	require.Error(t, err)
+	require.Equal(t, "info", logger.CurrentLevel())
+	require.Equal(t, 30*time.Second, svc.MaxProfileDuration())
+
+	svc.cfg.Ops.Profiling.Enabled = false
+	_, err = svc.SetLogLevelOverride("debug", time.Minute, 1)
+	require.ErrorIs(t, err, ErrOpsProfilingDisabled)
+}
@@ -121,6 +121,14 @@ func (m *opsRepoMock) GetOpenAITokenStats(ctx context.Context, filter *OpsOpenAI
 	return &OpsOpenAITokenStatsResponse{}, nil
 }
 
+func (m *opsRepoMock) GetFailoverTrend(ctx context.Context, filter *OpsDashboardFilter, bucketSeconds int) (*OpsFailoverTrendResponse, error) {
+	return &OpsFailoverTrendResponse{}, nil
+}
+
+func (m *opsRepoMock) GetFailoverStats(ctx context.Context, filter *OpsDashboardFilter, topN int) (*OpsFailoverStatsResponse, error) {
+	return &OpsFailoverStatsResponse{}, nil
+}
+
 func (m *opsRepoMock) InsertSystemMetrics(ctx context.Context, input *OpsInsertSystemMetricsInput) error {
 	return nil
 }
@@ -58,6 +58,9 @@ type OpsRequestDetailFilter struct {
 	RequestID string
 	Query     string
 
+	// StatusCode matches the client-visible status; successful requests count as 200.
+	StatusCode *int
+
 	MinDurationMs *int
 	MaxDurationMs *int
 
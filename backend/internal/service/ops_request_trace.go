@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+var ErrOpsRequestTraceNotFound = infraerrors.NotFound("OPS_REQUEST_TRACE_NOT_FOUND", "request trace not found")
+
+// OpsRequestTraceRepository assembles the stored data of a single request (usage_logs + ops_error_logs)
+// keyed by request_id (or client_request_id for requests that failed before an upstream id was assigned).
+type OpsRequestTraceRepository interface {
+	GetRequestTrace(ctx context.Context, requestID string) (*OpsRequestTrace, error)
+}
+
+// OpsRequestTraceLatencies breaks down where time was spent for a request.
+// Auth/routing/upstream/response latencies are only recorded for requests that hit ops_error_logs;
+// successful requests only carry duration and TTFT from usage_logs.
+type OpsRequestTraceLatencies struct {
+	AuthMs             *int64 `json:"auth_ms,omitempty"`
+	RoutingMs          *int64 `json:"routing_ms,omitempty"`
+	UpstreamMs         *int64 `json:"upstream_ms,omitempty"`
+	ResponseMs         *int64 `json:"response_ms,omitempty"`
+	TimeToFirstTokenMs *int64 `json:"time_to_first_token_ms,omitempty"`
+	DurationMs         *int64 `json:"duration_ms,omitempty"`
+}
+
+// OpsRequestTraceUsage is the billing outcome of a successful request.
+type OpsRequestTraceUsage struct {
+	UsageLogID          int64   `json:"usage_log_id"`
+	InputTokens         int     `json:"input_tokens"`
+	OutputTokens        int     `json:"output_tokens"`
+	CacheCreationTokens int     `json:"cache_creation_tokens"`
+	CacheReadTokens     int     `json:"cache_read_tokens"`
+	TotalCost           float64 `json:"total_cost"`
+	ActualCost          float64 `json:"actual_cost"`
+}
+
+// OpsRequestTraceErrorLog is one ops_error_logs row recorded for the request.
+// Rows with status < 400 are upstream failures that were recovered by failover.
+type OpsRequestTraceErrorLog struct {
+	ID                 int64     `json:"id"`
+	CreatedAt          time.Time `json:"created_at"`
+	Phase              string    `json:"phase"`
+	Type               string    `json:"type"`
+	Severity           string    `json:"severity"`
+	StatusCode         *int      `json:"status_code,omitempty"`
+	UpstreamStatusCode *int      `json:"upstream_status_code,omitempty"`
+	Message            string    `json:"message"`
+	AccountID          *int64    `json:"account_id,omitempty"`
+	AccountName        string    `json:"account_name,omitempty"`
+}
+
+// OpsRequestTrace is the detail view behind GET /admin/ops/requests/:id.
+type OpsRequestTrace struct {
+	RequestID       string         `json:"request_id"`
+	ClientRequestID string         `json:"client_request_id,omitempty"`
+	Kind            OpsRequestKind `json:"kind"`
+	CreatedAt       time.Time      `json:"created_at"`
+
+	Platform         string `json:"platform,omitempty"`
+	Model            string `json:"model,omitempty"`
+	RequestedModel   string `json:"requested_model,omitempty"`
+	UpstreamModel    string `json:"upstream_model,omitempty"`
+	InboundEndpoint  string `json:"inbound_endpoint,omitempty"`
+	UpstreamEndpoint string `json:"upstream_endpoint,omitempty"`
+	Stream           bool   `json:"stream"`
+	StatusCode       *int   `json:"status_code,omitempty"`
+
+	UserID   *int64 `json:"user_id,omitempty"`
+	APIKeyID *int64 `json:"api_key_id,omitempty"`
+	GroupID  *int64 `json:"group_id,omitempty"`
+
+	// AccountID/AccountName is the account that finally served (or failed) the request.
+	AccountID   *int64 `json:"account_id,omitempty"`
+	AccountName string `json:"account_name,omitempty"`
+
+	Latencies OpsRequestTraceLatencies `json:"latencies"`
+	Usage     *OpsRequestTraceUsage    `json:"usage,omitempty"`
+
+	ErrorLogs []*OpsRequestTraceErrorLog `json:"error_logs"`
+	// UpstreamErrors merges the upstream attempts of all error logs, each with its
+	// sanitized upstream request/response body snapshot.
+	UpstreamErrors []*OpsUpstreamErrorEvent `json:"upstream_errors"`
+	// UpstreamErrorsRaw holds the raw upstream_errors JSON of each error log, parsed by the service.
+	UpstreamErrorsRaw []string `json:"-"`
+
+	// Client request body snapshot (only stored for failed requests).
+	RequestBody          string `json:"request_body,omitempty"`
+	RequestBodyTruncated bool   `json:"request_body_truncated"`
+	RequestBodyBytes     *int   `json:"request_body_bytes,omitempty"`
+}
+
+// OpsRequestTraceService serves the request trace detail view.
+type OpsRequestTraceService struct {
+	repo       OpsRequestTraceRepository
+	opsService *OpsService
+}
+
+func NewOpsRequestTraceService(repo OpsRequestTraceRepository, opsService *OpsService) *OpsRequestTraceService {
+	return &OpsRequestTraceService{repo: repo, opsService: opsService}
+}
+
+func (s *OpsRequestTraceService) GetRequestTrace(ctx context.Context, requestID string) (*OpsRequestTrace, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrOpsRequestTraceNotFound
+	}
+	if s.opsService != nil {
+		if err := s.opsService.RequireMonitoringEnabled(ctx); err != nil {
+			return nil, err
+		}
+	}
+	requestID = strings.TrimSpace(requestID)
+	if requestID == "" {
+		return nil, infraerrors.BadRequest("INVALID_REQUEST_ID", "request id is required")
+	}
+
+	trace, err := s.repo.GetRequestTrace(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if trace == nil {
+		return nil, ErrOpsRequestTraceNotFound
+	}
+
+	trace.UpstreamErrors = make([]*OpsUpstreamErrorEvent, 0)
+	for _, raw := range trace.UpstreamErrorsRaw {
+		events, err := ParseOpsUpstreamErrors(raw)
+		if err != nil {
+			// Corrupt JSON in a single row should not hide the rest of the trace.
+			continue
+		}
+		for _, ev := range events {
+			if ev != nil {
+				trace.UpstreamErrors = append(trace.UpstreamErrors, ev)
+			}
+		}
+	}
+	if trace.ErrorLogs == nil {
+		trace.ErrorLogs = []*OpsRequestTraceErrorLog{}
+	}
+	return trace, nil
+}
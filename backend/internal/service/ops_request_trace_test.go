@@ -0,0 +1,49 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type opsRequestTraceRepoStub struct {
+	trace *OpsRequestTrace
+}
+
+func (s *opsRequestTraceRepoStub) GetRequestTrace(ctx context.Context, requestID string) (*OpsRequestTrace, error) {
+	return s.trace, nil
+}
+
+func TestOpsRequestTraceService_ParsesUpstreamErrors(t *testing.T) {
+	repo := &opsRequestTraceRepoStub{trace: &OpsRequestTrace{
+		RequestID: "req-1",
+		Kind:      OpsRequestKindSuccess,
+		UpstreamErrorsRaw: []string{
+			`[{"account_id":1,"upstream_status_code":529,"kind":"failover","upstream_request_body":"{\"model\":\"x\"}"}]`,
+			`not json`,
+			`[{"account_id":2,"upstream_status_code":500}]`,
+		},
+	}}
+	svc := NewOpsRequestTraceService(repo, nil)
+
+	trace, err := svc.GetRequestTrace(context.Background(), " req-1 ")
+	require.NoError(t, err)
+	require.Len(t, trace.UpstreamErrors, 2)
+	require.Equal(t, int64(1), trace.UpstreamErrors[0].AccountID)
+	require.Equal(t, `{"model":"x"}`, trace.UpstreamErrors[0].UpstreamRequestBody)
+	require.Equal(t, 500, trace.UpstreamErrors[1].UpstreamStatusCode)
+	require.NotNil(t, trace.ErrorLogs)
+}
+
+func TestOpsRequestTraceService_NotFound(t *testing.T) {
+	svc := NewOpsRequestTraceService(&opsRequestTraceRepoStub{}, nil)
+
+	_, err := svc.GetRequestTrace(context.Background(), "missing")
+	require.ErrorIs(t, err, ErrOpsRequestTraceNotFound)
+
+	_, err = svc.GetRequestTrace(context.Background(), "  ")
+	require.Error(t, err)
+}
@@ -558,7 +558,11 @@ func (s *OpsService) executeWithAccount(ctx context.Context, reqType opsRetryReq
 	}
 
 	c, w := newOpsRetryContext(ctx, errorLog)
+	return s.executeWithAccountContext(ctx, c, w, reqType, errorLog, body, account)
+}
 
+// executeWithAccountContext 使用调用方准备好的 gin 上下文向指定账号转发请求。
+func (s *OpsService) executeWithAccountContext(ctx context.Context, c *gin.Context, w *limitedResponseWriter, reqType opsRetryRequestType, errorLog *OpsErrorLogDetail, body []byte, account *Account) *opsRetryExecution {
 	var err error
 	switch reqType {
 	case opsRetryTypeOpenAI:
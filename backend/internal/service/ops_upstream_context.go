@@ -109,6 +109,9 @@ type OpsUpstreamErrorEvent struct {
 
 	Message string `json:"message,omitempty"`
 	Detail  string `json:"detail,omitempty"`
+
+	// Timing is the network timing breakdown (DNS/connect/TLS/first byte) of this attempt.
+	Timing *OpsUpstreamTiming `json:"timing,omitempty"`
 }
 
 func appendOpsUpstreamError(c *gin.Context, ev OpsUpstreamErrorEvent) {
@@ -126,6 +129,9 @@ func appendOpsUpstreamError(c *gin.Context, ev OpsUpstreamErrorEvent) {
 	ev.UpstreamURL = strings.TrimSpace(ev.UpstreamURL)
 	ev.Message = strings.TrimSpace(ev.Message)
 	ev.Detail = strings.TrimSpace(ev.Detail)
+	if ev.Timing == nil && c.Request != nil {
+		ev.Timing = claimOpsUpstreamTiming(c.Request.Context())
+	}
 	if ev.Message != "" {
 		ev.Message = sanitizeUpstreamErrorMessage(ev.Message)
 	}
@@ -180,7 +186,8 @@ func checkSkipMonitoringForUpstreamEvent(c *gin.Context, ev *OpsUpstreamErrorEve
 		body = ev.Message
 	}
 
-	rule := svc.MatchRule(ev.Platform, ev.UpstreamStatusCode, []byte(body))
+	// 仅用于判断是否跳过监控，命中统计由最终改写响应的匹配负责
+	rule := svc.matchRule(ev.Platform, ev.UpstreamStatusCode, []byte(body), false)
 	if rule != nil && rule.SkipMonitoring {
 		c.Set(OpsSkipPassthroughKey, true)
 	}
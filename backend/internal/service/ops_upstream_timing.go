@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+)
+
+// OpsUpstreamTiming 单次上游尝试的网络阶段耗时（毫秒，基于 httptrace），
+// 用于区分“账号慢”是网络问题（DNS/建连/TLS）还是模型首字节慢。
+// 未发生的阶段为 nil（例如复用连接时没有 DNS/建连/TLS）。
+type OpsUpstreamTiming struct {
+	DNSMs       *int64 `json:"dns_ms,omitempty"`
+	ConnectMs   *int64 `json:"connect_ms,omitempty"`
+	TLSMs       *int64 `json:"tls_ms,omitempty"`
+	FirstByteMs *int64 `json:"first_byte_ms,omitempty"`
+	ConnReused  bool   `json:"conn_reused,omitempty"`
+}
+
+// OpsUpstreamTimingRecorder 记录当前请求最近一次上游尝试的网络耗时（并发安全）
+type OpsUpstreamTimingRecorder struct {
+	mu   sync.Mutex
+	last *OpsUpstreamTiming
+	// claimed 表示 last 已附加到某个上游错误事件，避免被后续未发出请求的事件误用
+	claimed bool
+}
+
+// WithOpsUpstreamTimingRecorder 在 context 中注入上游耗时记录器（已存在时原样返回）
+func WithOpsUpstreamTimingRecorder(ctx context.Context) context.Context {
+	if ctx == nil || opsUpstreamTimingRecorderFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, ctxkey.OpsUpstreamTiming, &OpsUpstreamTimingRecorder{})
+}
+
+func opsUpstreamTimingRecorderFromContext(ctx context.Context) *OpsUpstreamTimingRecorder {
+	if ctx == nil {
+		return nil
+	}
+	recorder, _ := ctx.Value(ctxkey.OpsUpstreamTiming).(*OpsUpstreamTimingRecorder)
+	return recorder
+}
+
+// OpsUpstreamTimingFromContext 返回最近一次上游尝试的网络耗时副本，未记录时返回 nil
+func OpsUpstreamTimingFromContext(ctx context.Context) *OpsUpstreamTiming {
+	recorder := opsUpstreamTimingRecorderFromContext(ctx)
+	if recorder == nil {
+		return nil
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.last == nil {
+		return nil
+	}
+	out := *recorder.last
+	return &out
+}
+
+// claimOpsUpstreamTiming 取出尚未附加到上游错误事件的最近一次耗时
+func claimOpsUpstreamTiming(ctx context.Context) *OpsUpstreamTiming {
+	recorder := opsUpstreamTimingRecorderFromContext(ctx)
+	if recorder == nil {
+		return nil
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.last == nil || recorder.claimed {
+		return nil
+	}
+	recorder.claimed = true
+	out := *recorder.last
+	return &out
+}
+
+// TraceOpsUpstreamTiming 为上游请求挂载 httptrace（仅当 context 中存在耗时记录器时），
+// 每次调用视为一次新的上游尝试。
+func TraceOpsUpstreamTiming(req *http.Request) *http.Request {
+	if req == nil {
+		return req
+	}
+	recorder := opsUpstreamTimingRecorderFromContext(req.Context())
+	if recorder == nil {
+		return req
+	}
+
+	timing := &OpsUpstreamTiming{}
+	recorder.mu.Lock()
+	recorder.last = timing
+	recorder.claimed = false
+	recorder.mu.Unlock()
+
+	var dnsStart, connectStart, tlsStart time.Time
+	sentAt := time.Now()
+	record := func(fn func()) {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		fn()
+	}
+	elapsedMs := func(since time.Time) *int64 {
+		ms := time.Since(since).Milliseconds()
+		return &ms
+	}
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			record(func() { dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			record(func() {
+				if !dnsStart.IsZero() && timing.DNSMs == nil {
+					timing.DNSMs = elapsedMs(dnsStart)
+				}
+			})
+		},
+		ConnectStart: func(string, string) {
+			record(func() {
+				if connectStart.IsZero() {
+					connectStart = time.Now()
+				}
+			})
+		},
+		ConnectDone: func(_, _ string, err error) {
+			record(func() {
+				if err == nil && !connectStart.IsZero() && timing.ConnectMs == nil {
+					timing.ConnectMs = elapsedMs(connectStart)
+				}
+			})
+		},
+		TLSHandshakeStart: func() {
+			record(func() { tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			record(func() {
+				if !tlsStart.IsZero() && timing.TLSMs == nil {
+					timing.TLSMs = elapsedMs(tlsStart)
+				}
+			})
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			record(func() { timing.ConnReused = info.Reused })
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			// 首字节耗时从请求写完开始计算，只反映上游处理等待时间
+			record(func() { sentAt = time.Now() })
+		},
+		GotFirstResponseByte: func() {
+			record(func() { timing.FirstByteMs = elapsedMs(sentAt) })
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
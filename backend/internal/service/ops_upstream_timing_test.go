@@ -0,0 +1,60 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceOpsUpstreamTiming_RecordsAttemptAndAttachesToEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx := WithOpsUpstreamTimingRecorder(context.Background())
+	require.Nil(t, OpsUpstreamTimingFromContext(ctx))
+
+	client := &http.Client{Transport: &http.Transport{}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(TraceOpsUpstreamTiming(req))
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	timing := OpsUpstreamTimingFromContext(ctx)
+	require.NotNil(t, timing)
+	require.NotNil(t, timing.ConnectMs)
+	require.NotNil(t, timing.FirstByteMs)
+	require.Nil(t, timing.TLSMs)
+	require.False(t, timing.ConnReused)
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil).WithContext(ctx)
+
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{Kind: "http_error", UpstreamStatusCode: http.StatusBadGateway})
+	// 未发出新请求的后续事件不复用上一次尝试的耗时
+	appendOpsUpstreamError(c, OpsUpstreamErrorEvent{Kind: "failover", Message: "no available accounts"})
+
+	v, ok := c.Get(OpsUpstreamErrorsKey)
+	require.True(t, ok)
+	events := v.([]*OpsUpstreamErrorEvent)
+	require.Len(t, events, 2)
+	require.NotNil(t, events[0].Timing)
+	require.Equal(t, timing.FirstByteMs, events[0].Timing.FirstByteMs)
+	require.Nil(t, events[1].Timing)
+}
+
+func TestTraceOpsUpstreamTiming_NoRecorderLeavesRequestUntouched(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	require.Same(t, req, TraceOpsUpstreamTiming(req))
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+)
+
+var (
+	ErrOrganizationNotFound       = infraerrors.NotFound("ORGANIZATION_NOT_FOUND", "organization not found")
+	ErrOrganizationNameRequired   = infraerrors.BadRequest("ORGANIZATION_NAME_REQUIRED", "organization name is required")
+	ErrOrganizationNameExists     = infraerrors.Conflict("ORGANIZATION_NAME_EXISTS", "organization name already exists")
+	ErrOrganizationInvalidStatus  = infraerrors.BadRequest("ORGANIZATION_STATUS_INVALID", "organization status is invalid")
+	ErrOrganizationInvalidBudget  = infraerrors.BadRequest("ORGANIZATION_BUDGET_INVALID", "monthly_budget_usd must be >= 0")
+	ErrOrganizationHasMembers     = infraerrors.Conflict("ORGANIZATION_HAS_MEMBERS", "organization still has members")
+	ErrOrganizationInvalidRole    = infraerrors.BadRequest("ORGANIZATION_ROLE_INVALID", "org_role must be member or admin")
+	ErrOrganizationAdminRequired  = infraerrors.Forbidden("ORGANIZATION_ADMIN_REQUIRED", "organization admin role required")
+	ErrOrganizationMemberNotFound = infraerrors.NotFound("ORGANIZATION_MEMBER_NOT_FOUND", "user is not a member of this organization")
+	ErrOrganizationUserInOtherOrg = infraerrors.Conflict("ORGANIZATION_USER_IN_OTHER_ORG", "user already belongs to another organization")
+	ErrOrganizationCannotSelf     = infraerrors.BadRequest("ORGANIZATION_CANNOT_MODIFY_SELF", "organization admins cannot modify themselves")
+	ErrOrganizationPlatformAdmin  = infraerrors.Forbidden("ORGANIZATION_PLATFORM_ADMIN", "platform admins cannot be managed by organization admins")
+	ErrOrganizationGroupNotOwned  = infraerrors.BadRequest("ORGANIZATION_GROUP_NOT_OWNED", "group does not belong to this organization")
+	ErrOrganizationGroupInOther   = infraerrors.Conflict("ORGANIZATION_GROUP_IN_OTHER_ORG", "group already belongs to another organization")
+	ErrOrganizationGroupPublic    = infraerrors.BadRequest("ORGANIZATION_GROUP_NOT_EXCLUSIVE", "only exclusive groups can be assigned to an organization")
+	ErrOrganizationDisabled       = infraerrors.Forbidden("ORGANIZATION_DISABLED", "organization is disabled")
+	ErrOrganizationBudgetExceeded = infraerrors.Forbidden("ORGANIZATION_BUDGET_EXCEEDED", "organization monthly budget exceeded")
+)
+
+// Organization 组织（团队）。位于用户之上，组织管理员可在本组织范围内管理成员、分组、API Key，
+// MonthlyBudgetUSD 限制全体成员当月总消费（0 = 不限制）。
+type Organization struct {
+	ID               int64
+	Name             string
+	Description      string
+	Status           string
+	MonthlyBudgetUSD float64
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+
+	// MemberCount 成员数，仅列表/详情接口填充
+	MemberCount int64
+}
+
+// IsActive 组织是否启用
+func (o *Organization) IsActive() bool {
+	return o != nil && o.Status == StatusActive
+}
+
+// HasBudget 是否设置了月度预算
+func (o *Organization) HasBudget() bool {
+	return o != nil && o.MonthlyBudgetUSD > 0
+}
+
+// OrganizationListFilters 组织列表过滤条件
+type OrganizationListFilters struct {
+	Status string
+	Search string
+}
+
+// OrganizationRepository 组织数据访问
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *Organization) error
+	GetByID(ctx context.Context, id int64) (*Organization, error)
+	Update(ctx context.Context, org *Organization) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, params pagination.PaginationParams, filters OrganizationListFilters) ([]Organization, *pagination.PaginationResult, error)
+
+	// CountMembers 统计组织成员数
+	CountMembers(ctx context.Context, orgID int64) (int64, error)
+	// GetSpendSince 统计组织成员自 since 起的实际消费（usage_logs.actual_cost）
+	GetSpendSince(ctx context.Context, orgID int64, since time.Time) (float64, error)
+}
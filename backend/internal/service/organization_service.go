@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/timezone"
+)
+
+// orgGroupListLimit 组织分组列表上限（单组织分组数远小于该值）
+const orgGroupListLimit = 1000
+
+// CreateOrganizationInput 创建组织参数
+type CreateOrganizationInput struct {
+	Name             string
+	Description      string
+	Status           string
+	MonthlyBudgetUSD float64
+}
+
+// UpdateOrganizationInput 更新组织参数；nil 表示不修改
+type UpdateOrganizationInput struct {
+	Name             *string
+	Description      *string
+	Status           *string
+	MonthlyBudgetUSD *float64
+}
+
+// OrganizationOverview 组织概览（组织管理员首页）
+type OrganizationOverview struct {
+	Organization  *Organization
+	MemberCount   int64
+	MonthSpendUSD float64
+}
+
+// UpdateOrgMemberInput 组织管理员更新成员参数；nil 表示不修改
+type UpdateOrgMemberInput struct {
+	Status  *string
+	OrgRole *string
+}
+
+// UpdateOrgAPIKeyInput 组织管理员更新成员 API Key 参数；nil 表示不修改
+type UpdateOrgAPIKeyInput struct {
+	Status     *string
+	Quota      *float64
+	ResetQuota *bool
+}
+
+// OrganizationService 组织管理。
+//
+// 平台管理员负责创建组织、分配成员与组织分组；组织管理员（users.org_role=admin）
+// 只能在本组织范围内管理成员状态/角色、成员可用分组与成员 API Key，并查看组织用量。
+type OrganizationService struct {
+	orgRepo              OrganizationRepository
+	userRepo             UserRepository
+	groupRepo            GroupRepository
+	apiKeyService        *APIKeyService
+	authCacheInvalidator APIKeyAuthCacheInvalidator
+}
+
+// NewOrganizationService 创建组织服务
+func NewOrganizationService(
+	orgRepo OrganizationRepository,
+	userRepo UserRepository,
+	groupRepo GroupRepository,
+	apiKeyService *APIKeyService,
+	authCacheInvalidator APIKeyAuthCacheInvalidator,
+) *OrganizationService {
+	return &OrganizationService{
+		orgRepo:              orgRepo,
+		userRepo:             userRepo,
+		groupRepo:            groupRepo,
+		apiKeyService:        apiKeyService,
+		authCacheInvalidator: authCacheInvalidator,
+	}
+}
+
+// List 列出组织（平台管理员）
+func (s *OrganizationService) List(ctx context.Context, page, pageSize int, filters OrganizationListFilters, sortBy, sortOrder string) ([]Organization, int64, error) {
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize, SortBy: sortBy, SortOrder: sortOrder}
+	orgs, result, err := s.orgRepo.List(ctx, params, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range orgs {
+		count, err := s.orgRepo.CountMembers(ctx, orgs[i].ID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("count organization members: %w", err)
+		}
+		orgs[i].MemberCount = count
+	}
+	return orgs, result.Total, nil
+}
+
+// Get 获取组织详情（含成员数）
+func (s *OrganizationService) Get(ctx context.Context, id int64) (*Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.orgRepo.CountMembers(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("count organization members: %w", err)
+	}
+	org.MemberCount = count
+	return org, nil
+}
+
+// Create 创建组织
+func (s *OrganizationService) Create(ctx context.Context, input *CreateOrganizationInput) (*Organization, error) {
+	org := &Organization{
+		Name:             strings.TrimSpace(input.Name),
+		Description:      strings.TrimSpace(input.Description),
+		Status:           input.Status,
+		MonthlyBudgetUSD: input.MonthlyBudgetUSD,
+	}
+	if org.Status == "" {
+		org.Status = StatusActive
+	}
+	if err := validateOrganization(org); err != nil {
+		return nil, err
+	}
+	if err := s.orgRepo.Create(ctx, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// Update 更新组织
+func (s *OrganizationService) Update(ctx context.Context, id int64, input *UpdateOrganizationInput) (*Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		org.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Description != nil {
+		org.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Status != nil {
+		org.Status = *input.Status
+	}
+	if input.MonthlyBudgetUSD != nil {
+		org.MonthlyBudgetUSD = *input.MonthlyBudgetUSD
+	}
+	if err := validateOrganization(org); err != nil {
+		return nil, err
+	}
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// Delete 删除组织：仍有成员时拒绝；组织分组解除归属后保留为平台分组。
+func (s *OrganizationService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.orgRepo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	count, err := s.orgRepo.CountMembers(ctx, id)
+	if err != nil {
+		return fmt.Errorf("count organization members: %w", err)
+	}
+	if count > 0 {
+		return ErrOrganizationHasMembers
+	}
+	groups, err := s.ListGroups(ctx, id)
+	if err != nil {
+		return err
+	}
+	for i := range groups {
+		if err := s.UnassignGroup(ctx, id, groups[i].ID); err != nil {
+			return err
+		}
+	}
+	return s.orgRepo.Delete(ctx, id)
+}
+
+func validateOrganization(org *Organization) error {
+	if org.Name == "" {
+		return ErrOrganizationNameRequired
+	}
+	if org.Status != StatusActive && org.Status != StatusDisabled {
+		return ErrOrganizationInvalidStatus
+	}
+	if org.MonthlyBudgetUSD < 0 {
+		return ErrOrganizationInvalidBudget
+	}
+	return nil
+}
+
+// AddMember 将用户加入组织（平台管理员）。用户已属于其他组织时拒绝。
+func (s *OrganizationService) AddMember(ctx context.Context, orgID, userID int64, orgRole string) (*User, error) {
+	if _, err := s.orgRepo.GetByID(ctx, orgID); err != nil {
+		return nil, err
+	}
+	if orgRole == "" {
+		orgRole = OrgRoleMember
+	}
+	if !isValidOrgRole(orgRole) {
+		return nil, ErrOrganizationInvalidRole
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrganizationID != nil && *user.OrganizationID != orgID {
+		return nil, ErrOrganizationUserInOtherOrg
+	}
+	user.OrganizationID = &orgID
+	user.OrgRole = orgRole
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.invalidateUser(ctx, user.ID)
+	return user, nil
+}
+
+// RemoveMember 将成员移出组织，同时收回其组织分组的使用权限。
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	user, err := s.getMember(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	orgGroupIDs, err := s.orgGroupIDSet(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	kept := make([]int64, 0, len(user.AllowedGroups))
+	for _, id := range user.AllowedGroups {
+		if _, ok := orgGroupIDs[id]; !ok {
+			kept = append(kept, id)
+		}
+	}
+	user.AllowedGroups = kept
+	user.OrganizationID = nil
+	user.OrgRole = ""
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	s.invalidateUser(ctx, user.ID)
+	return nil
+}
+
+// AssignGroup 将分组划归组织（平台管理员）。仅专属分组可划归，避免组织外用户绕过分配直接使用。
+func (s *OrganizationService) AssignGroup(ctx context.Context, orgID, groupID int64) (*Group, error) {
+	if _, err := s.orgRepo.GetByID(ctx, orgID); err != nil {
+		return nil, err
+	}
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.OrganizationID != nil && *group.OrganizationID != orgID {
+		return nil, ErrOrganizationGroupInOther
+	}
+	if !group.IsExclusive {
+		return nil, ErrOrganizationGroupPublic
+	}
+	group.OrganizationID = &orgID
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// UnassignGroup 解除分组的组织归属；成员已有的分组权限保持不变。
+func (s *OrganizationService) UnassignGroup(ctx context.Context, orgID, groupID int64) error {
+	group, err := s.groupRepo.GetByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group.OrganizationID == nil || *group.OrganizationID != orgID {
+		return ErrOrganizationGroupNotOwned
+	}
+	group.OrganizationID = nil
+	return s.groupRepo.Update(ctx, group)
+}
+
+// ResolveAdminOrg 返回用户作为组织管理员所管理的组织；非组织管理员或组织已停用时返回 Forbidden。
+func (s *OrganizationService) ResolveAdminOrg(ctx context.Context, userID int64) (*Organization, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrganizationID == nil || user.OrgRole != OrgRoleAdmin || !user.IsActive() {
+		return nil, ErrOrganizationAdminRequired
+	}
+	org, err := s.orgRepo.GetByID(ctx, *user.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !org.IsActive() {
+		return nil, ErrOrganizationDisabled
+	}
+	return org, nil
+}
+
+// Overview 组织概览：成员数与本月消费
+func (s *OrganizationService) Overview(ctx context.Context, org *Organization) (*OrganizationOverview, error) {
+	count, err := s.orgRepo.CountMembers(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("count organization members: %w", err)
+	}
+	spend, err := s.orgRepo.GetSpendSince(ctx, org.ID, timezone.StartOfMonth(timezone.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("get organization spend: %w", err)
+	}
+	return &OrganizationOverview{Organization: org, MemberCount: count, MonthSpendUSD: spend}, nil
+}
+
+// ListMembers 列出组织成员
+func (s *OrganizationService) ListMembers(ctx context.Context, orgID int64, page, pageSize int, filters UserListFilters) ([]User, int64, error) {
+	filters.OrganizationID = &orgID
+	includeSubscriptions := false
+	filters.IncludeSubscriptions = &includeSubscriptions
+	params := pagination.PaginationParams{Page: page, PageSize: pageSize, SortBy: "created_at", SortOrder: pagination.SortOrderDesc}
+	users, result, err := s.userRepo.ListWithFilters(ctx, params, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, result.Total, nil
+}
+
+// UpdateMember 组织管理员更新成员的状态与组织角色。
+// 不允许修改自己（避免唯一管理员误操作失去管理权），也不允许管理平台管理员。
+func (s *OrganizationService) UpdateMember(ctx context.Context, orgID, actorID, memberID int64, input *UpdateOrgMemberInput) (*User, error) {
+	if actorID == memberID {
+		return nil, ErrOrganizationCannotSelf
+	}
+	user, err := s.getManageableMember(ctx, orgID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Status != nil {
+		if *input.Status != StatusActive && *input.Status != StatusDisabled {
+			return nil, ErrOrganizationInvalidStatus
+		}
+		user.Status = *input.Status
+	}
+	if input.OrgRole != nil {
+		if !isValidOrgRole(*input.OrgRole) {
+			return nil, ErrOrganizationInvalidRole
+		}
+		user.OrgRole = *input.OrgRole
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.invalidateUser(ctx, user.ID)
+	return user, nil
+}
+
+// RemoveMemberAsAdmin 组织管理员将成员移出组织
+func (s *OrganizationService) RemoveMemberAsAdmin(ctx context.Context, orgID, actorID, memberID int64) error {
+	if actorID == memberID {
+		return ErrOrganizationCannotSelf
+	}
+	if _, err := s.getManageableMember(ctx, orgID, memberID); err != nil {
+		return err
+	}
+	return s.RemoveMember(ctx, orgID, memberID)
+}
+
+// ListGroups 列出组织分组
+func (s *OrganizationService) ListGroups(ctx context.Context, orgID int64) ([]Group, error) {
+	params := pagination.PaginationParams{Page: 1, PageSize: orgGroupListLimit, SortBy: "sort_order", SortOrder: pagination.SortOrderAsc}
+	groups, _, err := s.groupRepo.ListWithFilters(ctx, params, "", "", "", nil, &orgID)
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// SetMemberGroups 设置成员可用的组织分组。只替换组织分组部分，平台管理员分配的其他分组保持不变。
+func (s *OrganizationService) SetMemberGroups(ctx context.Context, orgID, memberID int64, groupIDs []int64) (*User, error) {
+	user, err := s.getManageableMember(ctx, orgID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	orgGroupIDs, err := s.orgGroupIDSet(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range groupIDs {
+		if _, ok := orgGroupIDs[id]; !ok {
+			return nil, ErrOrganizationGroupNotOwned
+		}
+	}
+	allowed := make([]int64, 0, len(user.AllowedGroups)+len(groupIDs))
+	for _, id := range user.AllowedGroups {
+		if _, ok := orgGroupIDs[id]; !ok {
+			allowed = append(allowed, id)
+		}
+	}
+	allowed = append(allowed, groupIDs...)
+	user.AllowedGroups = allowed
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.invalidateUser(ctx, user.ID)
+	return user, nil
+}
+
+// ListMemberAPIKeys 列出成员的 API Key
+func (s *OrganizationService) ListMemberAPIKeys(ctx context.Context, orgID, memberID int64, params pagination.PaginationParams) ([]APIKey, *pagination.PaginationResult, error) {
+	if _, err := s.getMember(ctx, orgID, memberID); err != nil {
+		return nil, nil, err
+	}
+	return s.apiKeyService.List(ctx, memberID, params, APIKeyListFilters{})
+}
+
+// UpdateMemberAPIKey 组织管理员更新成员 API Key 的状态与额度
+func (s *OrganizationService) UpdateMemberAPIKey(ctx context.Context, orgID, keyID int64, input *UpdateOrgAPIKeyInput) (*APIKey, error) {
+	apiKey, err := s.apiKeyService.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getManageableMember(ctx, orgID, apiKey.UserID); err != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	return s.apiKeyService.Update(ctx, keyID, apiKey.UserID, UpdateAPIKeyRequest{
+		Status:     input.Status,
+		Quota:      input.Quota,
+		ResetQuota: input.ResetQuota,
+	})
+}
+
+// getMember 获取组织成员；非本组织用户统一返回 ErrOrganizationMemberNotFound，避免泄露其他用户信息。
+func (s *OrganizationService) getMember(ctx context.Context, orgID, userID int64) (*User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrOrganizationMemberNotFound
+		}
+		return nil, err
+	}
+	if user.OrganizationID == nil || *user.OrganizationID != orgID {
+		return nil, ErrOrganizationMemberNotFound
+	}
+	return user, nil
+}
+
+// getManageableMember 获取组织管理员可管理的成员（排除平台管理员）
+func (s *OrganizationService) getManageableMember(ctx context.Context, orgID, userID int64) (*User, error) {
+	user, err := s.getMember(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAdmin() {
+		return nil, ErrOrganizationPlatformAdmin
+	}
+	return user, nil
+}
+
+func (s *OrganizationService) orgGroupIDSet(ctx context.Context, orgID int64) (map[int64]struct{}, error) {
+	groups, err := s.ListGroups(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]struct{}, len(groups))
+	for i := range groups {
+		out[groups[i].ID] = struct{}{}
+	}
+	return out, nil
+}
+
+func (s *OrganizationService) invalidateUser(ctx context.Context, userID int64) {
+	if s.authCacheInvalidator != nil {
+		s.authCacheInvalidator.InvalidateAuthCacheByUserID(ctx, userID)
+	}
+}
+
+func isValidOrgRole(role string) bool {
+	return role == OrgRoleMember || role == OrgRoleAdmin
+}
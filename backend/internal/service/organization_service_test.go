@@ -0,0 +1,173 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+type orgRepoStub struct {
+	OrganizationRepository
+	orgs       map[int64]*Organization
+	members    int64
+	spend      float64
+	spendCalls int
+}
+
+func (s *orgRepoStub) GetByID(_ context.Context, id int64) (*Organization, error) {
+	org, ok := s.orgs[id]
+	if !ok {
+		return nil, ErrOrganizationNotFound
+	}
+	cp := *org
+	return &cp, nil
+}
+
+func (s *orgRepoStub) CountMembers(context.Context, int64) (int64, error) {
+	return s.members, nil
+}
+
+func (s *orgRepoStub) GetSpendSince(context.Context, int64, time.Time) (float64, error) {
+	s.spendCalls++
+	return s.spend, nil
+}
+
+type orgUserRepoStub struct {
+	UserRepository
+	users   map[int64]*User
+	updated []*User
+}
+
+func (s *orgUserRepoStub) GetByID(_ context.Context, id int64) (*User, error) {
+	u, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	cp := *u
+	cp.AllowedGroups = append([]int64(nil), u.AllowedGroups...)
+	return &cp, nil
+}
+
+func (s *orgUserRepoStub) Update(_ context.Context, u *User) error {
+	s.updated = append(s.updated, u)
+	s.users[u.ID] = u
+	return nil
+}
+
+type orgGroupRepoStub struct {
+	GroupRepository
+	groups []Group
+}
+
+func (s *orgGroupRepoStub) ListWithFilters(_ context.Context, _ pagination.PaginationParams, _, _, _ string, _ *bool, organizationID *int64) ([]Group, *pagination.PaginationResult, error) {
+	out := make([]Group, 0, len(s.groups))
+	for _, g := range s.groups {
+		if organizationID == nil || (g.OrganizationID != nil && *g.OrganizationID == *organizationID) {
+			out = append(out, g)
+		}
+	}
+	return out, &pagination.PaginationResult{Total: int64(len(out))}, nil
+}
+
+func newOrgServiceForTest() (*OrganizationService, *orgUserRepoStub) {
+	orgID := int64(1)
+	users := &orgUserRepoStub{users: map[int64]*User{
+		10: {ID: 10, Status: StatusActive, Role: RoleUser, OrganizationID: &orgID, OrgRole: OrgRoleAdmin},
+		11: {ID: 11, Status: StatusActive, Role: RoleUser, OrganizationID: &orgID, OrgRole: OrgRoleMember, AllowedGroups: []int64{100, 200}},
+		12: {ID: 12, Status: StatusActive, Role: RoleUser},
+		13: {ID: 13, Status: StatusActive, Role: RoleAdmin, OrganizationID: &orgID, OrgRole: OrgRoleMember},
+	}}
+	groups := &orgGroupRepoStub{groups: []Group{
+		{ID: 100, OrganizationID: &orgID, IsExclusive: true},
+		{ID: 101, OrganizationID: &orgID, IsExclusive: true},
+		{ID: 200, IsExclusive: true},
+	}}
+	orgs := &orgRepoStub{orgs: map[int64]*Organization{
+		1: {ID: 1, Name: "acme", Status: StatusActive},
+	}}
+	return NewOrganizationService(orgs, users, groups, nil, nil), users
+}
+
+func TestOrganizationService_ResolveAdminOrg(t *testing.T) {
+	svc, _ := newOrgServiceForTest()
+
+	org, err := svc.ResolveAdminOrg(context.Background(), 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), org.ID)
+
+	_, err = svc.ResolveAdminOrg(context.Background(), 11)
+	require.ErrorIs(t, err, ErrOrganizationAdminRequired)
+
+	_, err = svc.ResolveAdminOrg(context.Background(), 12)
+	require.ErrorIs(t, err, ErrOrganizationAdminRequired)
+}
+
+func TestOrganizationService_SetMemberGroupsKeepsNonOrgGroups(t *testing.T) {
+	svc, users := newOrgServiceForTest()
+
+	user, err := svc.SetMemberGroups(context.Background(), 1, 11, []int64{101})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []int64{200, 101}, user.AllowedGroups)
+	require.ElementsMatch(t, []int64{200, 101}, users.users[11].AllowedGroups)
+
+	_, err = svc.SetMemberGroups(context.Background(), 1, 11, []int64{200})
+	require.ErrorIs(t, err, ErrOrganizationGroupNotOwned)
+}
+
+func TestOrganizationService_UpdateMemberScope(t *testing.T) {
+	svc, _ := newOrgServiceForTest()
+	disabled := StatusDisabled
+
+	_, err := svc.UpdateMember(context.Background(), 1, 10, 10, &UpdateOrgMemberInput{Status: &disabled})
+	require.ErrorIs(t, err, ErrOrganizationCannotSelf)
+
+	_, err = svc.UpdateMember(context.Background(), 1, 10, 12, &UpdateOrgMemberInput{Status: &disabled})
+	require.ErrorIs(t, err, ErrOrganizationMemberNotFound)
+
+	_, err = svc.UpdateMember(context.Background(), 1, 10, 13, &UpdateOrgMemberInput{Status: &disabled})
+	require.ErrorIs(t, err, ErrOrganizationPlatformAdmin)
+
+	user, err := svc.UpdateMember(context.Background(), 1, 10, 11, &UpdateOrgMemberInput{Status: &disabled})
+	require.NoError(t, err)
+	require.Equal(t, StatusDisabled, user.Status)
+}
+
+func TestOrganizationService_RemoveMemberRevokesOrgGroups(t *testing.T) {
+	svc, users := newOrgServiceForTest()
+
+	require.NoError(t, svc.RemoveMemberAsAdmin(context.Background(), 1, 10, 11))
+	removed := users.users[11]
+	require.Nil(t, removed.OrganizationID)
+	require.Empty(t, removed.OrgRole)
+	require.Equal(t, []int64{200}, removed.AllowedGroups)
+}
+
+func TestBillingCacheService_CheckOrganizationBudget(t *testing.T) {
+	orgID := int64(1)
+	repo := &orgRepoStub{orgs: map[int64]*Organization{
+		1: {ID: 1, Status: StatusActive, MonthlyBudgetUSD: 50},
+	}, spend: 49.5}
+	svc := &BillingCacheService{}
+	svc.SetOrganizationRepository(repo)
+	user := &User{ID: 11, OrganizationID: &orgID}
+
+	require.NoError(t, svc.checkOrganizationBudget(context.Background(), user))
+	require.NoError(t, svc.checkOrganizationBudget(context.Background(), &User{ID: 12}))
+
+	// 缓存期内不重复查询消费
+	repo.spend = 60
+	require.NoError(t, svc.checkOrganizationBudget(context.Background(), user))
+	require.Equal(t, 1, repo.spendCalls)
+
+	svc.orgBudgetCache.Delete(orgID)
+	require.ErrorIs(t, svc.checkOrganizationBudget(context.Background(), user), ErrOrganizationBudgetExceeded)
+
+	repo.orgs[1].Status = StatusDisabled
+	svc.orgBudgetCache.Delete(orgID)
+	require.ErrorIs(t, svc.checkOrganizationBudget(context.Background(), user), ErrOrganizationDisabled)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+)
+
+// anthropicDeltaChars 返回 content_block_delta 事件中增量内容（文本/思考/工具参数）的字符数
+func anthropicDeltaChars(event map[string]any) int {
+	delta, ok := event["delta"].(map[string]any)
+	if !ok {
+		return 0
+	}
+	chars := 0
+	for _, key := range []string{"text", "thinking", "partial_json"} {
+		if v, ok := delta[key].(string); ok {
+			chars += utf8.RuneCountInString(v)
+		}
+	}
+	return chars
+}
+
+// estimatePartialOutputTokens 按已输出字符数估算 output tokens（约 4 字符/token）
+func estimatePartialOutputTokens(chars int) int {
+	if chars <= 0 {
+		return 0
+	}
+	return (chars + 3) / 4
+}
+
+// applyPartialUsageEstimate 客户端断开后上游被提前取消时，上游尚未返回最终 output tokens，
+// 按已转发的输出内容估算补齐；上游已返回的值更大时以上游为准。
+func applyPartialUsageEstimate(result *ForwardResult) {
+	if result == nil || !result.PartialUsage {
+		return
+	}
+	estimated := estimatePartialOutputTokens(result.PartialOutputChars)
+	if estimated <= result.Usage.OutputTokens {
+		return
+	}
+	logger.LegacyPrintf("service.gateway", "partial usage estimated after client disconnect: request_id=%s model=%s output_tokens=%d->%d",
+		result.RequestID, result.Model, result.Usage.OutputTokens, estimated)
+	result.Usage.OutputTokens = estimated
+}
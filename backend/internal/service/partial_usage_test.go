@@ -0,0 +1,75 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPartialUsageEstimate(t *testing.T) {
+	result := &ForwardResult{Usage: ClaudeUsage{InputTokens: 10, OutputTokens: 1}, PartialUsage: true, PartialOutputChars: 40}
+	applyPartialUsageEstimate(result)
+	require.Equal(t, 10, result.Usage.OutputTokens)
+	require.Equal(t, 10, result.Usage.InputTokens)
+
+	// 上游已返回更大的 output tokens 时以上游为准
+	result = &ForwardResult{Usage: ClaudeUsage{OutputTokens: 50}, PartialUsage: true, PartialOutputChars: 40}
+	applyPartialUsageEstimate(result)
+	require.Equal(t, 50, result.Usage.OutputTokens)
+
+	// 非部分用量不做估算
+	result = &ForwardResult{Usage: ClaudeUsage{OutputTokens: 1}, PartialOutputChars: 400}
+	applyPartialUsageEstimate(result)
+	require.Equal(t, 1, result.Usage.OutputTokens)
+}
+
+func TestHandleStreamingResponse_CancelUpstreamOnClientDisconnect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := newMinimalGatewayService()
+	svc.cfg.Gateway.CancelUpstreamOnClientDisconnect = true
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	clientCtx, cancelClient := context.WithCancel(context.Background())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/messages", nil).WithContext(clientCtx)
+
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: pr}
+
+	go func() {
+		_, _ = pw.Write([]byte("data: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":10,\"output_tokens\":1}}}\n\n"))
+		_, _ = pw.Write([]byte("data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"abcdefghijklmnopqrstuvwxyz0123456789abcd\"}}\n\n"))
+		// 上游继续生成但客户端已断开
+		time.Sleep(20 * time.Millisecond)
+		cancelClient()
+	}()
+
+	done := make(chan struct{})
+	var result *streamingResult
+	var err error
+	go func() {
+		defer close(done)
+		result, err = svc.handleStreamingResponse(context.Background(), resp, c, &Account{ID: 1}, time.Now(), "model", "model", false)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("客户端断开后应立即结束流，而不是继续读取上游")
+	}
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.True(t, result.clientDisconnect)
+	require.True(t, result.partial)
+	require.Equal(t, 40, result.partialOutputChars)
+	require.Equal(t, 10, result.usage.InputTokens)
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+var (
+	// ErrInvalidPIIRedactionMode 分组敏感信息过滤策略取值非法
+	ErrInvalidPIIRedactionMode = infraerrors.BadRequest("INVALID_PII_REDACTION_MODE", "pii_redaction_mode must be empty or one of mask, reject")
+	// ErrPIIDetected 请求内容命中敏感信息规则且分组策略为 reject
+	ErrPIIDetected = infraerrors.BadRequest("PII_DETECTED", "Request contains sensitive information (email, API key or card number) and was rejected by group policy")
+)
+
+// IsValidPIIRedactionMode 判断是否为合法的分组敏感信息过滤策略（空字符串表示关闭）
+func IsValidPIIRedactionMode(mode string) bool {
+	return mode == "" || mode == PIIRedactionModeMask || mode == PIIRedactionModeReject
+}
+
+type piiRedactionRule struct {
+	name        string
+	re          *regexp.Regexp
+	replacement string
+	// validate 对候选匹配做二次校验（如信用卡 Luhn 校验），nil 表示正则命中即生效
+	validate func(match string) bool
+}
+
+// defaultPIIRedactionRules 未配置 gateway.pii_redaction.patterns 时使用的内置规则
+var defaultPIIRedactionRules = []piiRedactionRule{
+	{
+		name: "email",
+		re:   regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	},
+	{
+		name: "api_key",
+		re:   regexp.MustCompile(`\b(?:sk-(?:ant-|proj-)?[A-Za-z0-9_\-]{20,}|AKIA[0-9A-Z]{16}|AIza[0-9A-Za-z_\-]{35}|gh[pousr]_[A-Za-z0-9]{36,}|xox[abprs]-[A-Za-z0-9\-]{10,})`),
+	},
+	{
+		name:     "credit_card",
+		re:       regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		validate: luhnValid,
+	},
+}
+
+// PIIRedactor 按分组策略扫描请求体中的用户可见文本（消息、系统提示词、指令），
+// mask 模式将命中内容替换为占位文本后转发，reject 模式直接拒绝请求。
+type PIIRedactor struct {
+	rules []piiRedactionRule
+}
+
+// NewPIIRedactor 根据网关配置构建过滤器；未配置自定义规则时使用内置规则。
+// 配置中的非法正则已由 config.Validate 拦截，此处跳过以防御性兜底。
+func NewPIIRedactor(cfg *config.Config) *PIIRedactor {
+	var patterns []config.GatewayPIIRedactionPattern
+	if cfg != nil {
+		patterns = cfg.Gateway.PIIRedaction.Patterns
+	}
+	if len(patterns) == 0 {
+		rules := make([]piiRedactionRule, len(defaultPIIRedactionRules))
+		copy(rules, defaultPIIRedactionRules)
+		for i := range rules {
+			rules[i].replacement = defaultPIIReplacement(rules[i].name)
+		}
+		return &PIIRedactor{rules: rules}
+	}
+
+	rules := make([]piiRedactionRule, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil || p.Regex == "" {
+			continue
+		}
+		replacement := p.Replacement
+		if replacement == "" {
+			replacement = defaultPIIReplacement(p.Name)
+		}
+		rules = append(rules, piiRedactionRule{name: p.Name, re: re, replacement: replacement})
+	}
+	return &PIIRedactor{rules: rules}
+}
+
+func defaultPIIReplacement(name string) string {
+	return fmt.Sprintf("[REDACTED_%s]", strings.ToUpper(strings.TrimSpace(name)))
+}
+
+// Apply 按分组策略处理请求体，返回处理后的请求体与脱敏次数。
+// 策略为 reject 且命中任一规则时返回 ErrPIIDetected；未启用、无规则或请求体非法 JSON 时原样返回。
+// 需在注入分组系统提示词之前调用，避免管理员配置的提示词被误判。
+func (r *PIIRedactor) Apply(body []byte, group *Group, format RequestParamFormat) ([]byte, int, error) {
+	if r == nil || len(r.rules) == 0 || group == nil || group.PIIRedactionMode == "" || !gjson.ValidBytes(body) {
+		return body, 0, nil
+	}
+	reject := group.PIIRedactionMode == PIIRedactionModeReject
+
+	total := 0
+	for _, path := range piiTextPaths(body, format) {
+		text := gjson.GetBytes(body, path).String()
+		redacted, n := r.redact(text)
+		if n == 0 {
+			continue
+		}
+		if reject {
+			return body, 0, ErrPIIDetected
+		}
+		updated, err := sjson.SetBytes(body, path, redacted)
+		if err != nil {
+			continue
+		}
+		body = updated
+		total += n
+	}
+	return body, total, nil
+}
+
+func (r *PIIRedactor) redact(text string) (string, int) {
+	count := 0
+	for _, rule := range r.rules {
+		text = rule.re.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.validate != nil && !rule.validate(match) {
+				return match
+			}
+			count++
+			return rule.replacement
+		})
+	}
+	return text, count
+}
+
+// piiTextPaths 收集各协议格式下需要扫描的文本字段路径
+func piiTextPaths(body []byte, format RequestParamFormat) []string {
+	var paths []string
+	switch format {
+	case RequestParamFormatAnthropic:
+		collectPIIContentPaths(gjson.GetBytes(body, "system"), "system", &paths)
+		for i, msg := range gjson.GetBytes(body, "messages").Array() {
+			collectPIIContentPaths(msg.Get("content"), fmt.Sprintf("messages.%d.content", i), &paths)
+		}
+	case RequestParamFormatChatCompletions:
+		for i, msg := range gjson.GetBytes(body, "messages").Array() {
+			collectPIIContentPaths(msg.Get("content"), fmt.Sprintf("messages.%d.content", i), &paths)
+		}
+	case RequestParamFormatResponses:
+		if gjson.GetBytes(body, "instructions").Type == gjson.String {
+			paths = append(paths, "instructions")
+		}
+		input := gjson.GetBytes(body, "input")
+		if input.Type == gjson.String {
+			paths = append(paths, "input")
+			break
+		}
+		for i, item := range input.Array() {
+			prefix := fmt.Sprintf("input.%d", i)
+			collectPIIContentPaths(item.Get("content"), prefix+".content", &paths)
+			if item.Get("output").Type == gjson.String {
+				paths = append(paths, prefix+".output")
+			}
+		}
+	case RequestParamFormatGemini:
+		for _, key := range []string{"systemInstruction", "system_instruction"} {
+			collectPIIPartPaths(gjson.GetBytes(body, key+".parts"), key+".parts", &paths)
+		}
+		for i, content := range gjson.GetBytes(body, "contents").Array() {
+			collectPIIPartPaths(content.Get("parts"), fmt.Sprintf("contents.%d.parts", i), &paths)
+		}
+	}
+	return paths
+}
+
+// collectPIIContentPaths 处理 content 字段：字符串直接扫描；数组中扫描 text 字段，
+// 并递归处理嵌套 content（如 Anthropic tool_result）。
+func collectPIIContentPaths(content gjson.Result, path string, paths *[]string) {
+	switch {
+	case content.Type == gjson.String:
+		*paths = append(*paths, path)
+	case content.IsArray():
+		for i, item := range content.Array() {
+			itemPath := fmt.Sprintf("%s.%d", path, i)
+			if item.Get("text").Type == gjson.String {
+				*paths = append(*paths, itemPath+".text")
+			}
+			if nested := item.Get("content"); nested.Exists() {
+				collectPIIContentPaths(nested, itemPath+".content", paths)
+			}
+		}
+	}
+}
+
+func collectPIIPartPaths(parts gjson.Result, path string, paths *[]string) {
+	for i, part := range parts.Array() {
+		if part.Get("text").Type == gjson.String {
+			*paths = append(*paths, fmt.Sprintf("%s.%d.text", path, i))
+		}
+	}
+}
+
+// luhnValid 校验数字串（允许空格与连字符分隔）是否通过 Luhn 校验
+func luhnValid(s string) bool {
+	sum := 0
+	digits := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && sum%10 == 0
+}
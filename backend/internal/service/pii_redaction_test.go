@@ -0,0 +1,82 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestPIIRedactor_MaskAnthropic(t *testing.T) {
+	r := NewPIIRedactor(nil)
+	group := &Group{PIIRedactionMode: PIIRedactionModeMask}
+
+	body := []byte(`{"system":"Contact ops@example.com","messages":[` +
+		`{"role":"user","content":"card 4111 1111 1111 1111, order 1234567890123"},` +
+		`{"role":"user","content":[{"type":"text","text":"key sk-ant-REDACTED"},` +
+		`{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"mail bob@corp.io"}]}]}]}`)
+	out, n, err := r.Apply(body, group, RequestParamFormatAnthropic)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	require.Equal(t, "Contact [REDACTED_EMAIL]", gjson.GetBytes(out, "system").String())
+	// 未通过 Luhn 校验的长数字串不脱敏
+	require.Equal(t, "card [REDACTED_CREDIT_CARD], order 1234567890123", gjson.GetBytes(out, "messages.0.content").String())
+	require.Equal(t, "key [REDACTED_API_KEY]", gjson.GetBytes(out, "messages.1.content.0.text").String())
+	require.Equal(t, "mail [REDACTED_EMAIL]", gjson.GetBytes(out, "messages.1.content.1.content.0.text").String())
+}
+
+func TestPIIRedactor_Formats(t *testing.T) {
+	r := NewPIIRedactor(nil)
+	group := &Group{PIIRedactionMode: PIIRedactionModeMask}
+
+	out, n, err := r.Apply([]byte(`{"messages":[{"role":"user","content":[{"type":"text","text":"a@b.co"}]}]}`), group, RequestParamFormatChatCompletions)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, "[REDACTED_EMAIL]", gjson.GetBytes(out, "messages.0.content.0.text").String())
+
+	out, n, err = r.Apply([]byte(`{"instructions":"x@y.io","input":[{"role":"user","content":[{"type":"input_text","text":"z@y.io"}]},{"type":"function_call_output","output":"w@y.io"}]}`), group, RequestParamFormatResponses)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, "[REDACTED_EMAIL]", gjson.GetBytes(out, "input.1.output").String())
+
+	out, n, err = r.Apply([]byte(`{"systemInstruction":{"parts":[{"text":"q@y.io"}]},"contents":[{"role":"user","parts":[{"text":"hi"},{"text":"p@y.io"}]}]}`), group, RequestParamFormatGemini)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, "hi", gjson.GetBytes(out, "contents.0.parts.0.text").String())
+	require.Equal(t, "[REDACTED_EMAIL]", gjson.GetBytes(out, "contents.0.parts.1.text").String())
+}
+
+func TestPIIRedactor_RejectAndDisabled(t *testing.T) {
+	r := NewPIIRedactor(nil)
+	body := []byte(`{"messages":[{"role":"user","content":"ping a@b.co"}]}`)
+
+	_, _, err := r.Apply(body, &Group{PIIRedactionMode: PIIRedactionModeReject}, RequestParamFormatChatCompletions)
+	require.ErrorIs(t, err, ErrPIIDetected)
+
+	out, n, err := r.Apply([]byte(`{"messages":[{"role":"user","content":"clean"}]}`), &Group{PIIRedactionMode: PIIRedactionModeReject}, RequestParamFormatChatCompletions)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.Equal(t, "clean", gjson.GetBytes(out, "messages.0.content").String())
+
+	out, n, err = r.Apply(body, &Group{}, RequestParamFormatChatCompletions)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.Equal(t, string(body), string(out))
+}
+
+func TestPIIRedactor_CustomPatterns(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.PIIRedaction.Patterns = []config.GatewayPIIRedactionPattern{
+		{Name: "phone", Regex: `\b1[3-9]\d{9}\b`, Replacement: "***"},
+	}
+	r := NewPIIRedactor(cfg)
+
+	out, n, err := r.Apply([]byte(`{"messages":[{"role":"user","content":"call 13812345678, mail a@b.co"}]}`), &Group{PIIRedactionMode: PIIRedactionModeMask}, RequestParamFormatChatCompletions)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	// 自定义规则替换内置规则
+	require.Equal(t, "call ***, mail a@b.co", gjson.GetBytes(out, "messages.0.content").String())
+}
@@ -72,6 +72,11 @@ type LiteLLMModelPricing struct {
 	SupportsPromptCaching               bool    `json:"supports_prompt_caching"`
 	OutputCostPerImage                  float64 `json:"output_cost_per_image"`       // 图片生成模型每张图片价格
 	OutputCostPerImageToken             float64 `json:"output_cost_per_image_token"` // 图片输出 token 价格
+	MaxInputTokens                      int     `json:"max_input_tokens,omitempty"`  // 上下文窗口（输入 token 上限）
+	MaxOutputTokens                     int     `json:"max_output_tokens,omitempty"`
+	SupportsVision                      bool    `json:"supports_vision,omitempty"`
+	SupportsFunctionCalling             bool    `json:"supports_function_calling,omitempty"`
+	SupportsReasoning                   bool    `json:"supports_reasoning,omitempty"`
 }
 
 // PricingRemoteClient 远程价格数据获取接口
@@ -96,6 +101,11 @@ type LiteLLMRawEntry struct {
 	SupportsPromptCaching               bool     `json:"supports_prompt_caching"`
 	OutputCostPerImage                  *float64 `json:"output_cost_per_image"`
 	OutputCostPerImageToken             *float64 `json:"output_cost_per_image_token"`
+	MaxInputTokens                      *float64 `json:"max_input_tokens"`
+	MaxOutputTokens                     *float64 `json:"max_output_tokens"`
+	SupportsVision                      bool     `json:"supports_vision"`
+	SupportsFunctionCalling             bool     `json:"supports_function_calling"`
+	SupportsReasoning                   bool     `json:"supports_reasoning"`
 }
 
 // PricingService 动态价格服务
@@ -377,10 +387,13 @@ func (s *PricingService) parsePricingData(body []byte) (map[string]*LiteLLMModel
 		}
 
 		pricing := &LiteLLMModelPricing{
-			LiteLLMProvider:       entry.LiteLLMProvider,
-			Mode:                  entry.Mode,
-			SupportsPromptCaching: entry.SupportsPromptCaching,
-			SupportsServiceTier:   entry.SupportsServiceTier,
+			LiteLLMProvider:         entry.LiteLLMProvider,
+			Mode:                    entry.Mode,
+			SupportsPromptCaching:   entry.SupportsPromptCaching,
+			SupportsServiceTier:     entry.SupportsServiceTier,
+			SupportsVision:          entry.SupportsVision,
+			SupportsFunctionCalling: entry.SupportsFunctionCalling,
+			SupportsReasoning:       entry.SupportsReasoning,
 		}
 
 		if entry.InputCostPerToken != nil {
@@ -413,6 +426,12 @@ func (s *PricingService) parsePricingData(body []byte) (map[string]*LiteLLMModel
 		if entry.OutputCostPerImageToken != nil {
 			pricing.OutputCostPerImageToken = *entry.OutputCostPerImageToken
 		}
+		if entry.MaxInputTokens != nil {
+			pricing.MaxInputTokens = int(*entry.MaxInputTokens)
+		}
+		if entry.MaxOutputTokens != nil {
+			pricing.MaxOutputTokens = int(*entry.MaxOutputTokens)
+		}
 
 		result[modelName] = pricing
 	}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 提示缓存命中情况：Anthropic 请求命中或写入提示缓存时，在响应头（非流式）或流末尾的 SSE 注释（流式）中
+// 返回 cache_read / cache_creation token 数与命中率，便于用户确认缓存经过网关后仍然生效。
+
+const (
+	PromptCacheReadTokensHeader     = "X-Sub2api-Cache-Read-Tokens"
+	PromptCacheCreationTokensHeader = "X-Sub2api-Cache-Creation-Tokens"
+	PromptCacheHitRatioHeader       = "X-Sub2api-Cache-Hit-Ratio"
+)
+
+// promptCacheStatus 单次调用的提示缓存命中情况
+type promptCacheStatus struct {
+	ReadTokens     int
+	CreationTokens int
+	HitRatio       float64
+}
+
+// PromptCacheHitRatio 缓存命中率 = cache_read / (input + cache_creation + cache_read)。
+// Anthropic 的 input_tokens 不含缓存部分；结果保留 4 位小数，未发生缓存读写时返回 false。
+func PromptCacheHitRatio(inputTokens, cacheCreationTokens, cacheReadTokens int) (float64, bool) {
+	if cacheCreationTokens <= 0 && cacheReadTokens <= 0 {
+		return 0, false
+	}
+	total := max(inputTokens, 0) + max(cacheCreationTokens, 0) + max(cacheReadTokens, 0)
+	return math.Round(float64(max(cacheReadTokens, 0))/float64(total)*1e4) / 1e4, true
+}
+
+func resolvePromptCacheStatus(usage *ClaudeUsage) (promptCacheStatus, bool) {
+	if usage == nil {
+		return promptCacheStatus{}, false
+	}
+	ratio, ok := PromptCacheHitRatio(usage.InputTokens, usage.CacheCreationInputTokens, usage.CacheReadInputTokens)
+	if !ok {
+		return promptCacheStatus{}, false
+	}
+	return promptCacheStatus{
+		ReadTokens:     usage.CacheReadInputTokens,
+		CreationTokens: usage.CacheCreationInputTokens,
+		HitRatio:       ratio,
+	}, true
+}
+
+func writePromptCacheStatusHeaders(h http.Header, status promptCacheStatus) {
+	if h == nil {
+		return
+	}
+	h.Set(PromptCacheReadTokensHeader, strconv.Itoa(status.ReadTokens))
+	h.Set(PromptCacheCreationTokensHeader, strconv.Itoa(status.CreationTokens))
+	h.Set(PromptCacheHitRatioHeader, formatPromptCacheHitRatio(status.HitRatio))
+}
+
+// promptCacheStatusSSEComment 构建流式响应末尾的 SSE 注释行（客户端会忽略以 ":" 开头的行）。
+func promptCacheStatusSSEComment(status promptCacheStatus) string {
+	return ": " + strings.ToLower(PromptCacheReadTokensHeader) + "=" + strconv.Itoa(status.ReadTokens) +
+		" " + strings.ToLower(PromptCacheCreationTokensHeader) + "=" + strconv.Itoa(status.CreationTokens) +
+		" " + strings.ToLower(PromptCacheHitRatioHeader) + "=" + formatPromptCacheHitRatio(status.HitRatio) + "\n\n"
+}
+
+func formatPromptCacheHitRatio(ratio float64) string {
+	return strconv.FormatFloat(ratio, 'f', 4, 64)
+}
+
+// applyPromptCacheStatusHeaders 非流式：在 c.Data 之前调用。
+func (s *GatewayService) applyPromptCacheStatusHeaders(c *gin.Context, usage *ClaudeUsage) {
+	if s.cfg == nil || !s.cfg.Gateway.CacheStatus.Enabled || c == nil {
+		return
+	}
+	if status, ok := resolvePromptCacheStatus(usage); ok {
+		writePromptCacheStatusHeaders(c.Writer.Header(), status)
+	}
+}
+
+// streamPromptCacheStatusComment 流式：返回需追加在流末尾的 SSE 注释，未启用或未发生缓存读写时返回空串。
+func (s *GatewayService) streamPromptCacheStatusComment(usage *ClaudeUsage) string {
+	if s.cfg == nil || !s.cfg.Gateway.CacheStatus.Enabled || !s.cfg.Gateway.CacheStatus.StreamComment {
+		return ""
+	}
+	status, ok := resolvePromptCacheStatus(usage)
+	if !ok {
+		return ""
+	}
+	return promptCacheStatusSSEComment(status)
+}
@@ -0,0 +1,51 @@
+//go:build unit
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptCacheHitRatio(t *testing.T) {
+	_, ok := PromptCacheHitRatio(100, 0, 0)
+	require.False(t, ok, "未发生缓存读写时不返回命中率")
+
+	ratio, ok := PromptCacheHitRatio(100, 100, 800)
+	require.True(t, ok)
+	require.InDelta(t, 0.8, ratio, 1e-9)
+
+	ratio, ok = PromptCacheHitRatio(50, 950, 0)
+	require.True(t, ok)
+	require.Zero(t, ratio)
+}
+
+func TestGatewayService_PromptCacheStatusHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	usage := &ClaudeUsage{InputTokens: 10, CacheCreationInputTokens: 30, CacheReadInputTokens: 60}
+
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	disabled := &GatewayService{cfg: &config.Config{}}
+	disabled.applyPromptCacheStatusHeaders(c, usage)
+	require.Empty(t, rec.Header().Get(PromptCacheHitRatioHeader))
+	require.Empty(t, disabled.streamPromptCacheStatusComment(usage))
+
+	cfg := &config.Config{}
+	cfg.Gateway.CacheStatus = config.GatewayCacheStatusConfig{Enabled: true, StreamComment: true}
+	svc := &GatewayService{cfg: cfg}
+	svc.applyPromptCacheStatusHeaders(c, usage)
+	c.Status(http.StatusOK)
+	require.Equal(t, "60", rec.Header().Get(PromptCacheReadTokensHeader))
+	require.Equal(t, "30", rec.Header().Get(PromptCacheCreationTokensHeader))
+	require.Equal(t, "0.6000", rec.Header().Get(PromptCacheHitRatioHeader))
+
+	require.Equal(t, ": x-sub2api-cache-read-tokens=60 x-sub2api-cache-creation-tokens=30 x-sub2api-cache-hit-ratio=0.6000\n\n",
+		svc.streamPromptCacheStatusComment(usage))
+	require.Empty(t, svc.streamPromptCacheStatusComment(&ClaudeUsage{InputTokens: 10}))
+}
@@ -8,16 +8,18 @@ import (
 )
 
 type Proxy struct {
-	ID        int64
-	Name      string
-	Protocol  string
-	Host      string
-	Port      int
-	Username  string
-	Password  string
-	Status    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       int64
+	Name     string
+	Protocol string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Status   string
+	// MaxConcurrency 经该代理的最大并发上游请求数，0 表示不限制
+	MaxConcurrency int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 func (p *Proxy) IsActive() bool {
@@ -27,3 +27,30 @@ type ProxyLatencyCache interface {
 	GetProxyLatencies(ctx context.Context, proxyIDs []int64) (map[int64]*ProxyLatencyInfo, error)
 	SetProxyLatency(ctx context.Context, proxyID int64, info *ProxyLatencyInfo) error
 }
+
+// storeProxyLatency 写入代理延迟探测结果；info 未携带质量检测字段时保留缓存中已有的质量检测结果
+func storeProxyLatency(ctx context.Context, cache ProxyLatencyCache, proxyID int64, info *ProxyLatencyInfo) error {
+	if cache == nil || info == nil {
+		return nil
+	}
+
+	merged := *info
+	if latencies, err := cache.GetProxyLatencies(ctx, []int64{proxyID}); err == nil {
+		if existing := latencies[proxyID]; existing != nil {
+			if merged.QualityCheckedAt == nil &&
+				merged.QualityScore == nil &&
+				merged.QualityGrade == "" &&
+				merged.QualityStatus == "" &&
+				merged.QualitySummary == "" &&
+				merged.QualityCFRay == "" {
+				merged.QualityStatus = existing.QualityStatus
+				merged.QualityScore = existing.QualityScore
+				merged.QualityGrade = existing.QualityGrade
+				merged.QualitySummary = existing.QualitySummary
+				merged.QualityCheckedAt = existing.QualityCheckedAt
+				merged.QualityCFRay = existing.QualityCFRay
+			}
+		}
+	}
+	return cache.SetProxyLatency(ctx, proxyID, &merged)
+}
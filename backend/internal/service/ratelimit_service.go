@@ -28,6 +28,8 @@ type RateLimitService struct {
 	openAI403CounterCache OpenAI403CounterCache
 	settingService        *SettingService
 	tokenCacheInvalidator TokenCacheInvalidator
+	webhookService        *WebhookService
+	timingWheel           *TimingWheelService
 	usageCacheMu          sync.RWMutex
 	usageCache            map[int64]*geminiUsageCacheEntry
 }
@@ -93,6 +95,69 @@ func (s *RateLimitService) SetTokenCacheInvalidator(invalidator TokenCacheInvali
 	s.tokenCacheInvalidator = invalidator
 }
 
+// SetWebhookService 设置 Webhook 通知服务（可选依赖）
+func (s *RateLimitService) SetWebhookService(webhookService *WebhookService) {
+	s.webhookService = webhookService
+}
+
+// SetTimingWheel 设置时间轮，用于限流到期时精确恢复账号（可选依赖）
+func (s *RateLimitService) SetTimingWheel(timingWheel *TimingWheelService) {
+	s.timingWheel = timingWheel
+}
+
+// markAccountError 将账号置为错误状态并推送 account.error 事件
+func (s *RateLimitService) markAccountError(ctx context.Context, account *Account, errorMsg string) error {
+	if err := s.accountRepo.SetError(ctx, account.ID, errorMsg); err != nil {
+		return err
+	}
+	s.webhookService.NotifyAccountError(account, errorMsg)
+	return nil
+}
+
+// markRateLimited 标记账号限流并推送 account.rate_limited 事件
+func (s *RateLimitService) markRateLimited(ctx context.Context, account *Account, resetAt time.Time) error {
+	if err := s.accountRepo.SetRateLimited(ctx, account.ID, resetAt); err != nil {
+		return err
+	}
+	s.webhookService.NotifyAccountRateLimited(account, resetAt)
+	s.scheduleRateLimitRecovery(account.ID, resetAt)
+	return nil
+}
+
+// scheduleRateLimitRecovery 在限流到期时刻清除账号限流状态，使调度快照与管理列表即时恢复，
+// 并推送 account.recovered 事件。同一账号重复限流时覆盖之前的定时器。
+func (s *RateLimitService) scheduleRateLimitRecovery(accountID int64, resetAt time.Time) {
+	if s.timingWheel == nil {
+		return
+	}
+	delay := time.Until(resetAt)
+	if delay <= 0 {
+		return
+	}
+	s.timingWheel.Schedule("ratelimit:recover:"+strconv.FormatInt(accountID, 10), delay, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		s.recoverExpiredRateLimit(ctx, accountID)
+	})
+}
+
+// recoverExpiredRateLimit 仅在限流确已到期（未被后续 429 延长）且无未到期过载状态时清除
+func (s *RateLimitService) recoverExpiredRateLimit(ctx context.Context, accountID int64) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil || account == nil {
+		return
+	}
+	if account.RateLimitResetAt == nil || account.IsRateLimited() || account.IsOverloaded() {
+		return
+	}
+	if err := s.accountRepo.ClearRateLimit(ctx, accountID); err != nil {
+		slog.Warn("rate_limit_auto_recover_failed", "account_id", accountID, "error", err)
+		return
+	}
+	slog.Info("rate_limit_auto_recovered", "account_id", accountID)
+	s.webhookService.NotifyAccountRecovered(account, false, true)
+}
+
 // ErrorPolicyResult 表示错误策略检查的结果
 type ErrorPolicyResult int
 
@@ -662,7 +727,7 @@ func (s *RateLimitService) GeminiCooldown(ctx context.Context, account *Account)
 
 // handleAuthError 处理认证类错误(401/403)，停止账号调度
 func (s *RateLimitService) handleAuthError(ctx context.Context, account *Account, errorMsg string) {
-	if err := s.accountRepo.SetError(ctx, account.ID, errorMsg); err != nil {
+	if err := s.markAccountError(ctx, account, errorMsg); err != nil {
 		slog.Warn("account_set_error_failed", "account_id", account.ID, "error", err)
 		return
 	}
@@ -807,7 +872,7 @@ func (s *RateLimitService) handleAntigravity403(ctx context.Context, account *Ac
 // handleCustomErrorCode 处理自定义错误码，停止账号调度
 func (s *RateLimitService) handleCustomErrorCode(ctx context.Context, account *Account, statusCode int, errorMsg string) {
 	msg := "Custom error code " + strconv.Itoa(statusCode) + ": " + errorMsg
-	if err := s.accountRepo.SetError(ctx, account.ID, msg); err != nil {
+	if err := s.markAccountError(ctx, account, msg); err != nil {
 		slog.Warn("account_set_error_failed", "account_id", account.ID, "status_code", statusCode, "error", err)
 		return
 	}
@@ -821,7 +886,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 	if account.Platform == PlatformOpenAI {
 		s.persistOpenAICodexSnapshot(ctx, account, headers)
 		if resetAt := s.calculateOpenAI429ResetTime(headers); resetAt != nil {
-			if err := s.accountRepo.SetRateLimited(ctx, account.ID, *resetAt); err != nil {
+			if err := s.markRateLimited(ctx, account, *resetAt); err != nil {
 				slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
 				return
 			}
@@ -832,7 +897,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 
 	// 2. Anthropic 平台：尝试解析 per-window 头（5h / 7d），选择实际触发的窗口
 	if result := calculateAnthropic429ResetTime(headers); result != nil {
-		if err := s.accountRepo.SetRateLimited(ctx, account.ID, result.resetAt); err != nil {
+		if err := s.markRateLimited(ctx, account, result.resetAt); err != nil {
 			slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
 			return
 		}
@@ -861,7 +926,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 			// 尝试解析 OpenAI 的 usage_limit_reached 错误
 			if resetAt := parseOpenAIRateLimitResetTime(responseBody); resetAt != nil {
 				resetTime := time.Unix(*resetAt, 0)
-				if err := s.accountRepo.SetRateLimited(ctx, account.ID, resetTime); err != nil {
+				if err := s.markRateLimited(ctx, account, resetTime); err != nil {
 					slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
 					return
 				}
@@ -872,7 +937,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 			// 尝试解析 Gemini 格式（用于其他平台）
 			if resetAt := ParseGeminiRateLimitResetTime(responseBody); resetAt != nil {
 				resetTime := time.Unix(*resetAt, 0)
-				if err := s.accountRepo.SetRateLimited(ctx, account.ID, resetTime); err != nil {
+				if err := s.markRateLimited(ctx, account, resetTime); err != nil {
 					slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
 					return
 				}
@@ -881,6 +946,18 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 			}
 		}
 
+		// 通用限流头：Retry-After / x-ratelimit-reset*，按上游给出的时间精确冷却
+		if account.Platform != PlatformAnthropic {
+			if resetAt := parseRateLimitResetHeaders(headers, time.Now()); resetAt != nil {
+				if err := s.markRateLimited(ctx, account, *resetAt); err != nil {
+					slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
+					return
+				}
+				slog.Info("account_rate_limited", "account_id", account.ID, "platform", account.Platform, "source", "retry_after_headers", "reset_at", *resetAt, "reset_in", time.Until(*resetAt).Truncate(time.Second))
+				return
+			}
+		}
+
 		// Anthropic 平台：没有限流重置时间的 429 可能是非真实限流（如 Extra usage required），
 		// 不标记账号限流状态，直接透传错误给客户端
 		if account.Platform == PlatformAnthropic {
@@ -894,7 +971,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 		// 其他平台：没有重置时间，使用默认5分钟
 		resetAt := time.Now().Add(5 * time.Minute)
 		slog.Warn("rate_limit_no_reset_time", "account_id", account.ID, "platform", account.Platform, "using_default", "5m")
-		if err := s.accountRepo.SetRateLimited(ctx, account.ID, resetAt); err != nil {
+		if err := s.markRateLimited(ctx, account, resetAt); err != nil {
 			slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
 		}
 		return
@@ -905,7 +982,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 	if err != nil {
 		slog.Warn("rate_limit_reset_parse_failed", "reset_timestamp", resetTimestamp, "error", err)
 		resetAt := time.Now().Add(5 * time.Minute)
-		if err := s.accountRepo.SetRateLimited(ctx, account.ID, resetAt); err != nil {
+		if err := s.markRateLimited(ctx, account, resetAt); err != nil {
 			slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
 		}
 		return
@@ -914,7 +991,7 @@ func (s *RateLimitService) handle429(ctx context.Context, account *Account, head
 	resetAt := time.Unix(ts, 0)
 
 	// 标记限流状态
-	if err := s.accountRepo.SetRateLimited(ctx, account.ID, resetAt); err != nil {
+	if err := s.markRateLimited(ctx, account, resetAt); err != nil {
 		slog.Warn("rate_limit_set_failed", "account_id", account.ID, "error", err)
 		return
 	}
@@ -982,6 +1059,80 @@ func (s *RateLimitService) calculateOpenAI429ResetTime(headers http.Header) *tim
 }
 
 // anthropic429Result holds the parsed Anthropic 429 rate-limit information.
+// maxHeaderRateLimitCooldown 通用限流头推导的冷却上限，防止异常头值导致账号长期不可调度
+const maxHeaderRateLimitCooldown = 7 * 24 * time.Hour
+
+// parseRateLimitResetHeaders 从通用限流响应头推导冷却结束时间，多个头同时存在时取最晚者：
+//   - retry-after-ms: 毫秒数
+//   - Retry-After: 秒数或 HTTP 日期
+//   - x-ratelimit-reset: Unix 时间戳（秒/毫秒）、秒数或 Go duration（如 "6m0s"）
+//   - x-ratelimit-reset-requests / x-ratelimit-reset-tokens: 同上（OpenAI 兼容上游）
+//
+// 无可用头或结果不在未来时返回 nil。
+func parseRateLimitResetHeaders(headers http.Header, now time.Time) *time.Time {
+	if headers == nil {
+		return nil
+	}
+	var latest time.Time
+	consider := func(t time.Time) {
+		if t.After(now) && t.After(latest) {
+			latest = t
+		}
+	}
+
+	if v := strings.TrimSpace(headers.Get("retry-after-ms")); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil && ms > 0 {
+			consider(now.Add(time.Duration(ms * float64(time.Millisecond))))
+		}
+	}
+	if v := strings.TrimSpace(headers.Get("Retry-After")); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			if secs > 0 {
+				consider(now.Add(time.Duration(secs * float64(time.Second))))
+			}
+		} else if t, err := http.ParseTime(v); err == nil {
+			consider(t)
+		}
+	}
+	for _, name := range []string{"x-ratelimit-reset", "x-ratelimit-reset-requests", "x-ratelimit-reset-tokens"} {
+		if t, ok := parseRateLimitResetValue(headers.Get(name), now); ok {
+			consider(t)
+		}
+	}
+
+	if latest.IsZero() {
+		return nil
+	}
+	if latest.Sub(now) > maxHeaderRateLimitCooldown {
+		latest = now.Add(maxHeaderRateLimitCooldown)
+	}
+	return &latest
+}
+
+// parseRateLimitResetValue 解析 x-ratelimit-reset* 头的取值
+func parseRateLimitResetValue(raw string, now time.Time) (time.Time, bool) {
+	v := strings.TrimSpace(raw)
+	if v == "" {
+		return time.Time{}, false
+	}
+	if n, err := strconv.ParseFloat(v, 64); err == nil {
+		switch {
+		case n <= 0:
+			return time.Time{}, false
+		case n > 1e12:
+			return time.UnixMilli(int64(n)), true
+		case n > 1e9:
+			return time.Unix(int64(n), 0), true
+		default:
+			return now.Add(time.Duration(n * float64(time.Second))), true
+		}
+	}
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return now.Add(d), true
+	}
+	return time.Time{}, false
+}
+
 type anthropic429Result struct {
 	resetAt       time.Time  // The correct reset time to use for SetRateLimited
 	fiveHourReset *time.Time // 5h window reset timestamp (for session window calculation), nil if not available
@@ -1294,6 +1445,8 @@ func (s *RateLimitService) UpdateSessionWindow(ctx context.Context, account *Acc
 	if status == "allowed" && account.IsRateLimited() {
 		if err := s.ClearRateLimit(ctx, account.ID); err != nil {
 			slog.Warn("rate_limit_clear_failed", "account_id", account.ID, "error", err)
+		} else {
+			s.webhookService.NotifyAccountRecovered(account, false, true)
 		}
 	}
 }
@@ -1359,11 +1512,45 @@ func (s *RateLimitService) RecoverAccountState(ctx context.Context, accountID in
 	}
 	if result.ClearedError || result.ClearedRateLimit {
 		s.ResetOpenAI403Counter(ctx, accountID)
+		s.webhookService.NotifyAccountRecovered(account, result.ClearedError, result.ClearedRateLimit)
 	}
 
 	return result, nil
 }
 
+// ApplyCredentialVerification 根据凭证校验结果更新账号状态：凭证被上游拒绝时置为 error，
+// 校验通过且账号处于 error 状态时清除错误（限流等运行时状态不受影响）。未得出结论的结果不做修改。
+func (s *RateLimitService) ApplyCredentialVerification(ctx context.Context, result *AccountVerifyResult) error {
+	if result == nil || !result.Conclusive {
+		return nil
+	}
+	account, err := s.accountRepo.GetByID(ctx, result.AccountID)
+	if err != nil {
+		return err
+	}
+	if !result.Valid {
+		if err := s.markAccountError(ctx, account, "Credential verification failed: "+result.Message); err != nil {
+			return err
+		}
+		result.AccountStatus = StatusError
+		return nil
+	}
+	if account.Status != StatusError {
+		return nil
+	}
+	if err := s.accountRepo.ClearError(ctx, account.ID); err != nil {
+		return err
+	}
+	if s.tokenCacheInvalidator != nil && account.IsOAuth() {
+		if invalidateErr := s.tokenCacheInvalidator.InvalidateToken(ctx, account); invalidateErr != nil {
+			slog.Warn("credential_verify_invalidate_token_failed", "account_id", account.ID, "error", invalidateErr)
+		}
+	}
+	result.AccountStatus = StatusActive
+	s.webhookService.NotifyAccountRecovered(account, true, false)
+	return nil
+}
+
 // RecoverAccountAfterSuccessfulTest 将一次成功测试视为正常请求，
 // 按需恢复 error / rate-limit / overload / temp-unsched / model-rate-limit 等运行时状态。
 func (s *RateLimitService) RecoverAccountAfterSuccessfulTest(ctx context.Context, accountID int64) (*SuccessfulTestRecoveryResult, error) {
@@ -1723,7 +1910,7 @@ func (s *RateLimitService) triggerStreamTimeoutTempUnsched(ctx context.Context,
 func (s *RateLimitService) triggerStreamTimeoutError(ctx context.Context, account *Account, model string) bool {
 	errorMsg := "Stream data interval timeout (repeated failures) for model: " + model
 
-	if err := s.accountRepo.SetError(ctx, account.ID, errorMsg); err != nil {
+	if err := s.markAccountError(ctx, account, errorMsg); err != nil {
 		slog.Warn("stream_timeout_set_error_failed", "account_id", account.ID, "error", err)
 		return false
 	}
@@ -0,0 +1,111 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimitResetHeaders(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	h := http.Header{}
+	require.Nil(t, parseRateLimitResetHeaders(h, now))
+
+	h.Set("Retry-After", "30")
+	require.Equal(t, now.Add(30*time.Second), *parseRateLimitResetHeaders(h, now))
+
+	h = http.Header{}
+	h.Set("Retry-After", now.Add(2*time.Minute).Format(http.TimeFormat))
+	require.Equal(t, now.Add(2*time.Minute), *parseRateLimitResetHeaders(h, now))
+
+	// 多个头取最晚者：duration / Unix 秒
+	h = http.Header{}
+	h.Set("retry-after-ms", "1500")
+	h.Set("x-ratelimit-reset-requests", "6m0s")
+	h.Set("x-ratelimit-reset-tokens", "20ms")
+	require.Equal(t, now.Add(6*time.Minute), *parseRateLimitResetHeaders(h, now))
+
+	h = http.Header{}
+	h.Set("x-ratelimit-reset", "1767272400") // 2026-01-01 13:00:00 UTC
+	require.True(t, now.Add(time.Hour).Equal(*parseRateLimitResetHeaders(h, now)))
+
+	// 已过去的时间与非法值忽略；超长冷却被截断
+	h = http.Header{}
+	h.Set("x-ratelimit-reset", "1767268800")
+	h.Set("Retry-After", "soon")
+	require.Nil(t, parseRateLimitResetHeaders(h, now))
+
+	h = http.Header{}
+	h.Set("Retry-After", "99999999")
+	require.Equal(t, now.Add(maxHeaderRateLimitCooldown), *parseRateLimitResetHeaders(h, now))
+}
+
+type retryAfterRateLimitRepo struct {
+	mockAccountRepoForGemini
+	account        *Account
+	rateLimitedAt  time.Time
+	clearRateCalls int
+}
+
+func (r *retryAfterRateLimitRepo) SetRateLimited(_ context.Context, _ int64, resetAt time.Time) error {
+	r.rateLimitedAt = resetAt
+	return nil
+}
+
+func (r *retryAfterRateLimitRepo) GetByID(_ context.Context, _ int64) (*Account, error) {
+	return r.account, nil
+}
+
+func (r *retryAfterRateLimitRepo) ClearRateLimit(_ context.Context, _ int64) error {
+	r.clearRateCalls++
+	return nil
+}
+
+func TestHandle429_UsesRetryAfterHeader(t *testing.T) {
+	repo := &retryAfterRateLimitRepo{}
+	svc := NewRateLimitService(repo, nil, nil, nil, nil)
+	account := &Account{ID: 5, Platform: PlatformGemini, Type: AccountTypeAPIKey}
+
+	headers := http.Header{}
+	headers.Set("Retry-After", "120")
+	before := time.Now()
+	svc.handle429(context.Background(), account, headers, nil)
+
+	require.WithinDuration(t, before.Add(120*time.Second), repo.rateLimitedAt, 2*time.Second)
+}
+
+func TestRecoverExpiredRateLimit(t *testing.T) {
+	expired := time.Now().Add(-time.Second)
+	repo := &retryAfterRateLimitRepo{account: &Account{ID: 5, RateLimitResetAt: &expired}}
+	svc := NewRateLimitService(repo, nil, nil, nil, nil)
+
+	svc.recoverExpiredRateLimit(context.Background(), 5)
+	require.Equal(t, 1, repo.clearRateCalls)
+
+	// 后续 429 已延长冷却时不提前清除
+	extended := time.Now().Add(time.Minute)
+	repo.account.RateLimitResetAt = &extended
+	svc.recoverExpiredRateLimit(context.Background(), 5)
+	require.Equal(t, 1, repo.clearRateCalls)
+}
+
+func TestAccountActiveCooldown(t *testing.T) {
+	now := time.Now()
+	rl := now.Add(time.Minute)
+	temp := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	until, reason := (&Account{}).ActiveCooldown(now)
+	require.Nil(t, until)
+	require.Empty(t, reason)
+
+	until, reason = (&Account{RateLimitResetAt: &rl, TempUnschedulableUntil: &temp, OverloadUntil: &past}).ActiveCooldown(now)
+	require.Equal(t, temp, *until)
+	require.Equal(t, AccountCooldownTempUnschedulable, reason)
+}
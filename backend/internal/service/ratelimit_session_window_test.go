@@ -81,7 +81,7 @@ func (m *sessionWindowMockRepo) Delete(context.Context, int64) error    { panic(
 func (m *sessionWindowMockRepo) List(context.Context, pagination.PaginationParams) ([]Account, *pagination.PaginationResult, error) {
 	panic("unexpected")
 }
-func (m *sessionWindowMockRepo) ListWithFilters(context.Context, pagination.PaginationParams, string, string, string, string, int64, string) ([]Account, *pagination.PaginationResult, error) {
+func (m *sessionWindowMockRepo) ListWithFilters(context.Context, pagination.PaginationParams, string, string, string, string, int64, string, string) ([]Account, *pagination.PaginationResult, error) {
 	panic("unexpected")
 }
 func (m *sessionWindowMockRepo) ListByGroup(context.Context, int64) ([]Account, error) {
@@ -93,6 +93,19 @@ func (m *sessionWindowMockRepo) ListActive(context.Context) ([]Account, error) {
 func (m *sessionWindowMockRepo) ListByPlatform(context.Context, string) ([]Account, error) {
 	panic("unexpected")
 }
+func (m *sessionWindowMockRepo) ListByOwnerUserID(context.Context, int64) ([]Account, error) {
+	panic("unexpected")
+}
+func (m *sessionWindowMockRepo) SetTags(context.Context, int64, []string) error { panic("unexpected") }
+func (m *sessionWindowMockRepo) AddTags(context.Context, []int64, []string) (int64, error) {
+	panic("unexpected")
+}
+func (m *sessionWindowMockRepo) RemoveTags(context.Context, []int64, []string) (int64, error) {
+	panic("unexpected")
+}
+func (m *sessionWindowMockRepo) ListTags(context.Context) ([]AccountTagCount, error) {
+	panic("unexpected")
+}
 func (m *sessionWindowMockRepo) UpdateLastUsed(context.Context, int64) error { panic("unexpected") }
 func (m *sessionWindowMockRepo) BatchUpdateLastUsed(context.Context, map[int64]time.Time) error {
 	panic("unexpected")
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// RequestBodyClass 请求体上限所属的端点类别
+type RequestBodyClass string
+
+const (
+	// RequestBodyClassChat 对话类端点（messages / chat completions / responses / Gemini）
+	RequestBodyClassChat RequestBodyClass = "chat"
+	// RequestBodyClassImages 图片生成与编辑端点
+	RequestBodyClassImages RequestBodyClass = "images"
+)
+
+// ErrInvalidMaxBodySize 分组或 API Key 的请求体上限不能为负数
+var ErrInvalidMaxBodySize = infraerrors.BadRequest("INVALID_MAX_BODY_SIZE", "max_body_size must not be negative")
+
+// RequestBodyClassForPath 根据请求路径判断端点类别
+func RequestBodyClassForPath(path string) RequestBodyClass {
+	if strings.Contains(path, "/images/") {
+		return RequestBodyClassImages
+	}
+	return RequestBodyClassChat
+}
+
+// RequestBodyLimitCeiling 返回请求体的全局硬上限：优先使用 server.max_request_body_size，
+// 未配置时取 gateway.max_body_size 与各端点类别上限中的最大值。
+// 分组或 API Key 配置的上限超过该值时按该值截断。
+func RequestBodyLimitCeiling(cfg *config.Config) int64 {
+	if cfg == nil {
+		return 0
+	}
+	if cfg.Server.MaxRequestBodySize > 0 {
+		return cfg.Server.MaxRequestBodySize
+	}
+	ceiling := cfg.Gateway.MaxBodySize
+	for _, limit := range []int64{cfg.Gateway.BodySizeLimits.Chat, cfg.Gateway.BodySizeLimits.Images} {
+		if limit > ceiling {
+			ceiling = limit
+		}
+	}
+	return ceiling
+}
+
+// ResolveRequestBodyLimit 计算请求实际生效的请求体上限：
+// API Key > 分组 > 端点类别 > gateway.max_body_size，结果不超过 RequestBodyLimitCeiling。
+func ResolveRequestBodyLimit(cfg *config.Config, apiKey *APIKey, class RequestBodyClass) int64 {
+	if cfg == nil {
+		return 0
+	}
+	var limit int64
+	switch {
+	case apiKey != nil && apiKey.MaxBodySize > 0:
+		limit = apiKey.MaxBodySize
+	case apiKey != nil && apiKey.Group != nil && apiKey.Group.MaxBodySize > 0:
+		limit = apiKey.Group.MaxBodySize
+	case class == RequestBodyClassImages && cfg.Gateway.BodySizeLimits.Images > 0:
+		limit = cfg.Gateway.BodySizeLimits.Images
+	case class == RequestBodyClassChat && cfg.Gateway.BodySizeLimits.Chat > 0:
+		limit = cfg.Gateway.BodySizeLimits.Chat
+	default:
+		limit = cfg.Gateway.MaxBodySize
+	}
+	if ceiling := RequestBodyLimitCeiling(cfg); ceiling > 0 && limit > ceiling {
+		limit = ceiling
+	}
+	return limit
+}
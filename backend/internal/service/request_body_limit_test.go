@@ -0,0 +1,48 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestBodyClassForPath(t *testing.T) {
+	require.Equal(t, RequestBodyClassImages, RequestBodyClassForPath("/v1/images/generations"))
+	require.Equal(t, RequestBodyClassImages, RequestBodyClassForPath("/images/edits"))
+	require.Equal(t, RequestBodyClassChat, RequestBodyClassForPath("/v1/messages"))
+	require.Equal(t, RequestBodyClassChat, RequestBodyClassForPath("/v1beta/models/gemini-2.5-pro:generateContent"))
+}
+
+func TestResolveRequestBodyLimit_Precedence(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxRequestBodySize = 100 << 20
+	cfg.Gateway.MaxBodySize = 10 << 20
+	cfg.Gateway.BodySizeLimits.Images = 40 << 20
+
+	require.Equal(t, int64(10<<20), ResolveRequestBodyLimit(cfg, nil, RequestBodyClassChat))
+	require.Equal(t, int64(40<<20), ResolveRequestBodyLimit(cfg, nil, RequestBodyClassImages))
+
+	group := &Group{MaxBodySize: 20 << 20}
+	key := &APIKey{Group: group}
+	require.Equal(t, int64(20<<20), ResolveRequestBodyLimit(cfg, key, RequestBodyClassImages))
+
+	key.MaxBodySize = 60 << 20
+	require.Equal(t, int64(60<<20), ResolveRequestBodyLimit(cfg, key, RequestBodyClassChat))
+
+	// 超过全局硬上限时截断
+	key.MaxBodySize = 500 << 20
+	require.Equal(t, int64(100<<20), ResolveRequestBodyLimit(cfg, key, RequestBodyClassChat))
+}
+
+func TestRequestBodyLimitCeiling_FallsBackToLargestGatewayLimit(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Gateway.MaxBodySize = 10 << 20
+	cfg.Gateway.BodySizeLimits.Chat = 30 << 20
+	require.Equal(t, int64(30<<20), RequestBodyLimitCeiling(cfg))
+
+	cfg.Server.MaxRequestBodySize = 50 << 20
+	require.Equal(t, int64(50<<20), RequestBodyLimitCeiling(cfg))
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ErrInvalidRequestParamOverrides 分组请求参数策略取值非法
+var ErrInvalidRequestParamOverrides = infraerrors.BadRequest("INVALID_REQUEST_PARAM_OVERRIDES", "request_param_overrides: temperature must be 0-2, top_p 0-1, max_tokens_cap >= 0, reasoning_effort one of minimal/low/medium/high/xhigh")
+
+// ErrInvalidReasoningEffort 统一推理强度参数（X-Reasoning-Effort）取值非法
+var ErrInvalidReasoningEffort = infraerrors.BadRequest("INVALID_REASONING_EFFORT", "X-Reasoning-Effort must be one of none/minimal/low/medium/high/xhigh")
+
+// RequestParamFormat 请求体协议格式，决定各参数在 JSON 中的字段路径
+type RequestParamFormat int
+
+const (
+	RequestParamFormatAnthropic RequestParamFormat = iota
+	RequestParamFormatChatCompletions
+	RequestParamFormatResponses
+	RequestParamFormatGemini
+)
+
+var validReasoningEfforts = map[string]bool{
+	"minimal": true,
+	"low":     true,
+	"medium":  true,
+	"high":    true,
+	"xhigh":   true,
+}
+
+// requestParamPaths 各格式下参数的字段路径；空字符串表示该格式不支持此参数。
+// maxTokens 列出所有可能出现的上限字段（Chat Completions 同时存在 max_tokens 与 max_completion_tokens），
+// 首个路径用于客户端未提供时注入。推理强度的字段随模型变化，由 apicompat.ApplyReasoningEffort 决定。
+type requestParamPaths struct {
+	temperature     string
+	topP            string
+	reasoningFormat apicompat.ReasoningFormat
+	maxTokens       []string
+}
+
+var requestParamPathsByFormat = map[RequestParamFormat]requestParamPaths{
+	RequestParamFormatAnthropic: {
+		temperature:     "temperature",
+		topP:            "top_p",
+		reasoningFormat: apicompat.ReasoningFormatAnthropic,
+		maxTokens:       []string{"max_tokens"},
+	},
+	RequestParamFormatChatCompletions: {
+		temperature:     "temperature",
+		topP:            "top_p",
+		reasoningFormat: apicompat.ReasoningFormatChatCompletions,
+		maxTokens:       []string{"max_completion_tokens", "max_tokens"},
+	},
+	RequestParamFormatResponses: {
+		temperature:     "temperature",
+		topP:            "top_p",
+		reasoningFormat: apicompat.ReasoningFormatResponses,
+		maxTokens:       []string{"max_output_tokens"},
+	},
+	RequestParamFormatGemini: {
+		temperature:     "generationConfig.temperature",
+		topP:            "generationConfig.topP",
+		reasoningFormat: apicompat.ReasoningFormatGemini,
+		maxTokens:       []string{"generationConfig.maxOutputTokens"},
+	},
+}
+
+// ValidateRequestParamOverrides 校验并规范化分组请求参数策略
+func ValidateRequestParamOverrides(o RequestParamOverrides) (RequestParamOverrides, error) {
+	o.ReasoningEffort = strings.ToLower(strings.TrimSpace(o.ReasoningEffort))
+	if o.Temperature != nil && (*o.Temperature < 0 || *o.Temperature > 2) {
+		return o, ErrInvalidRequestParamOverrides
+	}
+	if o.TopP != nil && (*o.TopP < 0 || *o.TopP > 1) {
+		return o, ErrInvalidRequestParamOverrides
+	}
+	if o.MaxTokensCap < 0 {
+		return o, ErrInvalidRequestParamOverrides
+	}
+	if o.ReasoningEffort != "" && !validReasoningEfforts[o.ReasoningEffort] {
+		return o, ErrInvalidRequestParamOverrides
+	}
+	return o, nil
+}
+
+// ApplyRequestParamOverrides 按分组策略改写请求体：
+//   - temperature / top_p / reasoning_effort：客户端未提供时注入，Force 时强制覆盖
+//     （推理强度按请求体中的 model 映射到对应上游字段；Gemini 请求体不含模型，由 ApplyReasoningEffort 处理）
+//   - max_tokens 上限：超过上限时裁剪，未提供时以上限值注入
+//
+// Anthropic 开启 thinking 时不接受自定义 temperature / top_p，此时跳过这两项；
+// 裁剪后的思考预算低于 Anthropic 下限（1024）时移除 thinking。
+//
+// 需在解析请求体与格式转换之前调用，使后续链路（含 CC/Responses → Anthropic 转换）沿用改写后的值。
+// 策略为空或请求体非法 JSON 时原样返回。
+func ApplyRequestParamOverrides(body []byte, group *Group, format RequestParamFormat) []byte {
+	if group == nil || group.RequestParamOverrides.IsEmpty() || !gjson.ValidBytes(body) {
+		return body
+	}
+	o := group.RequestParamOverrides
+	paths := requestParamPathsByFormat[format]
+
+	if o.ReasoningEffort != "" {
+		body = apicompat.ApplyReasoningEffort(body, paths.reasoningFormat, gjson.GetBytes(body, "model").String(), o.ReasoningEffort, o.Force)
+	}
+	if o.MaxTokensCap > 0 && len(paths.maxTokens) > 0 {
+		present := false
+		for _, path := range paths.maxTokens {
+			value := gjson.GetBytes(body, path)
+			if !value.Exists() || value.Type == gjson.Null {
+				continue
+			}
+			present = true
+			if value.Int() > int64(o.MaxTokensCap) {
+				body = setRequestParam(body, path, o.MaxTokensCap, true)
+			}
+		}
+		if !present {
+			body = setRequestParam(body, paths.maxTokens[0], o.MaxTokensCap, true)
+		}
+		// Anthropic 要求 thinking.budget_tokens < max_tokens，裁剪后同步收紧思考预算
+		if format == RequestParamFormatAnthropic {
+			if budget := gjson.GetBytes(body, "thinking.budget_tokens"); budget.Exists() && budget.Int() >= int64(o.MaxTokensCap) {
+				if o.MaxTokensCap-1 < anthropicMinThinkingBudget {
+					body = deleteRequestParam(body, "thinking")
+				} else {
+					body = setRequestParam(body, "thinking.budget_tokens", o.MaxTokensCap-1, true)
+				}
+			}
+		}
+	}
+	if !(format == RequestParamFormatAnthropic && anthropicThinkingEnabled(body)) {
+		if o.Temperature != nil {
+			body = setRequestParam(body, paths.temperature, *o.Temperature, o.Force)
+		}
+		if o.TopP != nil {
+			body = setRequestParam(body, paths.topP, *o.TopP, o.Force)
+		}
+	}
+	return body
+}
+
+// anthropicMinThinkingBudget Anthropic 接受的最小 thinking.budget_tokens
+const anthropicMinThinkingBudget = 1024
+
+// anthropicThinkingEnabled 请求体是否开启了 Anthropic extended thinking
+func anthropicThinkingEnabled(body []byte) bool {
+	return gjson.GetBytes(body, "thinking.type").String() == "enabled"
+}
+
+// dropGroupSamplingParamsForThinking 移除由分组策略注入、与 Anthropic thinking 冲突的 temperature / top_p。
+// 仅移除与分组取值一致的字段，客户端自行设置的其他取值保持不变。
+func dropGroupSamplingParamsForThinking(body []byte, o RequestParamOverrides) []byte {
+	if o.Temperature != nil {
+		if v := gjson.GetBytes(body, "temperature"); v.Exists() && v.Float() == *o.Temperature {
+			body = deleteRequestParam(body, "temperature")
+		}
+	}
+	if o.TopP != nil {
+		if v := gjson.GetBytes(body, "top_p"); v.Exists() && v.Float() == *o.TopP {
+			body = deleteRequestParam(body, "top_p")
+		}
+	}
+	return body
+}
+
+func deleteRequestParam(body []byte, path string) []byte {
+	if updated, err := sjson.DeleteBytes(body, path); err == nil {
+		return updated
+	}
+	return body
+}
+
+func setRequestParam(body []byte, path string, value any, force bool) []byte {
+	if path == "" {
+		return body
+	}
+	if !force {
+		if existing := gjson.GetBytes(body, path); existing.Exists() && existing.Type != gjson.Null {
+			return body
+		}
+	}
+	if updated, err := sjson.SetBytes(body, path, value); err == nil {
+		return updated
+	}
+	return body
+}
+
+// ApplyReasoningEffort 统一推理强度：将客户端通过 X-Reasoning-Effort 指定的强度（none/minimal/low/medium/high/xhigh）
+// 改写为目标格式与模型对应的上游字段（Anthropic thinking/output_config.effort、OpenAI reasoning_effort/reasoning.effort、
+// Gemini thinkingConfig），覆盖请求体中已有的推理配置。
+//
+// 客户端未指定时回落到分组默认值（request_param_overrides.reasoning_effort）；分组策略 Force 时以分组为准。
+// 需在 ApplyRequestParamOverrides 之后调用；取值非法时返回 ErrInvalidReasoningEffort。
+// Anthropic 请求因此开启 thinking 时，移除分组策略注入的 temperature / top_p。
+func ApplyReasoningEffort(body []byte, group *Group, format RequestParamFormat, model, requested string) ([]byte, error) {
+	effort, force := "", true
+	if requested = strings.TrimSpace(requested); requested != "" {
+		normalized, ok := apicompat.NormalizeReasoningEffort(requested)
+		if !ok {
+			return body, ErrInvalidReasoningEffort
+		}
+		effort = normalized
+	}
+	if group != nil && group.RequestParamOverrides.ReasoningEffort != "" && (effort == "" || group.RequestParamOverrides.Force) {
+		effort, force = group.RequestParamOverrides.ReasoningEffort, group.RequestParamOverrides.Force
+	}
+	if effort == "" {
+		return body, nil
+	}
+	thinkingBefore := format == RequestParamFormatAnthropic && anthropicThinkingEnabled(body)
+	body = apicompat.ApplyReasoningEffort(body, requestParamPathsByFormat[format].reasoningFormat, model, effort, force)
+	// 请求头开启了 thinking：撤销此前由分组策略注入的 temperature / top_p
+	if group != nil && format == RequestParamFormatAnthropic && !thinkingBefore && anthropicThinkingEnabled(body) {
+		body = dropGroupSamplingParamsForThinking(body, group.RequestParamOverrides)
+	}
+	return body, nil
+}
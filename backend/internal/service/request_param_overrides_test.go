@@ -0,0 +1,125 @@
+//go:build unit
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyRequestParamOverrides_DefaultsAndCap(t *testing.T) {
+	group := &Group{RequestParamOverrides: RequestParamOverrides{
+		Temperature:     float64Ptr(0),
+		ReasoningEffort: "low",
+		MaxTokensCap:    8192,
+	}}
+
+	// 客户端已提供 temperature 时保留；max_tokens 超限被裁剪，thinking 预算同步收紧
+	body := []byte(`{"model":"claude-sonnet-4-5","temperature":0.7,"max_tokens":32000,"thinking":{"type":"enabled","budget_tokens":16000}}`)
+	out := ApplyRequestParamOverrides(body, group, RequestParamFormatAnthropic)
+	require.Equal(t, 0.7, gjson.GetBytes(out, "temperature").Float())
+	require.Equal(t, int64(8192), gjson.GetBytes(out, "max_tokens").Int())
+	require.Equal(t, int64(8191), gjson.GetBytes(out, "thinking.budget_tokens").Int())
+	require.Equal(t, "low", gjson.GetBytes(out, "output_config.effort").String())
+
+	// 未提供的参数按格式路径注入
+	out = ApplyRequestParamOverrides([]byte(`{"model":"gpt-5","max_tokens":100}`), group, RequestParamFormatChatCompletions)
+	require.True(t, gjson.GetBytes(out, "temperature").Exists())
+	require.Equal(t, 0.0, gjson.GetBytes(out, "temperature").Float())
+	require.Equal(t, "low", gjson.GetBytes(out, "reasoning_effort").String())
+	require.Equal(t, int64(100), gjson.GetBytes(out, "max_tokens").Int())
+	require.False(t, gjson.GetBytes(out, "max_completion_tokens").Exists())
+
+	out = ApplyRequestParamOverrides([]byte(`{"model":"gpt-5"}`), group, RequestParamFormatResponses)
+	require.Equal(t, int64(8192), gjson.GetBytes(out, "max_output_tokens").Int())
+	require.Equal(t, "low", gjson.GetBytes(out, "reasoning.effort").String())
+
+	out = ApplyRequestParamOverrides([]byte(`{"contents":[],"generationConfig":{"maxOutputTokens":65536}}`), group, RequestParamFormatGemini)
+	require.Equal(t, int64(8192), gjson.GetBytes(out, "generationConfig.maxOutputTokens").Int())
+	require.True(t, gjson.GetBytes(out, "generationConfig.temperature").Exists())
+}
+
+func TestApplyRequestParamOverrides_Force(t *testing.T) {
+	group := &Group{RequestParamOverrides: RequestParamOverrides{TopP: float64Ptr(0.5), Force: true}}
+	out := ApplyRequestParamOverrides([]byte(`{"top_p":0.9}`), group, RequestParamFormatChatCompletions)
+	require.Equal(t, 0.5, gjson.GetBytes(out, "top_p").Float())
+
+	// 无策略或非法 JSON 原样返回
+	raw := []byte(`{"top_p":0.9`)
+	require.Equal(t, raw, ApplyRequestParamOverrides(raw, group, RequestParamFormatChatCompletions))
+	require.Equal(t, []byte(`{"top_p":0.9}`), ApplyRequestParamOverrides([]byte(`{"top_p":0.9}`), &Group{}, RequestParamFormatChatCompletions))
+}
+
+func TestValidateRequestParamOverrides(t *testing.T) {
+	got, err := ValidateRequestParamOverrides(RequestParamOverrides{ReasoningEffort: " High "})
+	require.NoError(t, err)
+	require.Equal(t, "high", got.ReasoningEffort)
+
+	for _, o := range []RequestParamOverrides{
+		{Temperature: float64Ptr(2.5)},
+		{TopP: float64Ptr(-0.1)},
+		{MaxTokensCap: -1},
+		{ReasoningEffort: "extreme"},
+	} {
+		_, err := ValidateRequestParamOverrides(o)
+		require.ErrorIs(t, err, ErrInvalidRequestParamOverrides)
+	}
+}
+
+func TestApplyReasoningEffort_HeaderAndGroupDefault(t *testing.T) {
+	body := []byte(`{"model":"gpt-5","reasoning_effort":"low"}`)
+
+	// 统一参数覆盖请求体中的原生字段
+	out, err := ApplyReasoningEffort(body, nil, RequestParamFormatChatCompletions, "gpt-5", "X-High")
+	require.NoError(t, err)
+	require.Equal(t, "xhigh", gjson.GetBytes(out, "reasoning_effort").String())
+
+	// 未指定时回落到分组默认值（Gemini 请求体不含模型，依赖显式传入）
+	group := &Group{RequestParamOverrides: RequestParamOverrides{ReasoningEffort: "high"}}
+	out, err = ApplyReasoningEffort([]byte(`{"contents":[]}`), group, RequestParamFormatGemini, "gemini-2.5-flash", "")
+	require.NoError(t, err)
+	require.Equal(t, int64(24576), gjson.GetBytes(out, "generationConfig.thinkingConfig.thinkingBudget").Int())
+
+	// 分组强制策略优先于客户端
+	group.RequestParamOverrides.Force = true
+	out, err = ApplyReasoningEffort(body, group, RequestParamFormatChatCompletions, "gpt-5", "minimal")
+	require.NoError(t, err)
+	require.Equal(t, "high", gjson.GetBytes(out, "reasoning_effort").String())
+
+	_, err = ApplyReasoningEffort(body, nil, RequestParamFormatChatCompletions, "gpt-5", "extreme")
+	require.ErrorIs(t, err, ErrInvalidReasoningEffort)
+}
+
+func TestApplyRequestParamOverrides_AnthropicThinking(t *testing.T) {
+	group := &Group{RequestParamOverrides: RequestParamOverrides{
+		Temperature:  float64Ptr(0.2),
+		TopP:         float64Ptr(0.8),
+		MaxTokensCap: 8192,
+	}}
+
+	// thinking 开启时不注入 temperature / top_p
+	body := []byte(`{"model":"claude-sonnet-4-5","max_tokens":4096,"thinking":{"type":"enabled","budget_tokens":2048}}`)
+	out := ApplyRequestParamOverrides(body, group, RequestParamFormatAnthropic)
+	require.False(t, gjson.GetBytes(out, "temperature").Exists())
+	require.False(t, gjson.GetBytes(out, "top_p").Exists())
+	require.Equal(t, int64(2048), gjson.GetBytes(out, "thinking.budget_tokens").Int())
+
+	// 裁剪后的思考预算低于 1024 时移除 thinking
+	group.RequestParamOverrides.MaxTokensCap = 1000
+	out = ApplyRequestParamOverrides(body, group, RequestParamFormatAnthropic)
+	require.Equal(t, int64(1000), gjson.GetBytes(out, "max_tokens").Int())
+	require.False(t, gjson.GetBytes(out, "thinking").Exists())
+	require.Equal(t, 0.2, gjson.GetBytes(out, "temperature").Float())
+
+	// 请求头开启 thinking 时撤销分组注入的采样参数
+	group.RequestParamOverrides.MaxTokensCap = 0
+	out = ApplyRequestParamOverrides([]byte(`{"model":"claude-sonnet-4-5","max_tokens":32000}`), group, RequestParamFormatAnthropic)
+	require.True(t, gjson.GetBytes(out, "temperature").Exists())
+	out, err := ApplyReasoningEffort(out, group, RequestParamFormatAnthropic, "claude-sonnet-4-5", "high")
+	require.NoError(t, err)
+	require.Equal(t, "enabled", gjson.GetBytes(out, "thinking.type").String())
+	require.False(t, gjson.GetBytes(out, "temperature").Exists())
+	require.False(t, gjson.GetBytes(out, "top_p").Exists())
+}
@@ -0,0 +1,41 @@
+package service
+
+import infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+
+// ErrInvalidPriority 优先级取值非法。
+var ErrInvalidPriority = infraerrors.BadRequest("INVALID_PRIORITY", "priority must be one of high, normal, low")
+
+// IsValidPriority 判断是否为合法的并发等待队列优先级。
+func IsValidPriority(priority string) bool {
+	switch priority {
+	case PriorityHigh, PriorityNormal, PriorityLow:
+		return true
+	}
+	return false
+}
+
+// PriorityRank 返回优先级的排序值，数值越小优先级越高；未知值按 normal 处理。
+func PriorityRank(priority string) int {
+	switch priority {
+	case PriorityHigh:
+		return 0
+	case PriorityLow:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// EffectivePriority 返回 API Key 的生效优先级：Key 自身设置优先，其次继承分组，默认 normal。
+func (k *APIKey) EffectivePriority() string {
+	if k == nil {
+		return PriorityNormal
+	}
+	if IsValidPriority(k.Priority) {
+		return k.Priority
+	}
+	if k.Group != nil && IsValidPriority(k.Group.Priority) {
+		return k.Group.Priority
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/apicompat"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// RequestValidator 转发前按端点对请求体做严格校验（基于 apicompat 的请求类型定义），
+// 返回精确到字段的错误（如 "messages[2].content[0].type invalid"），
+// 避免把格式错误的请求转发给上游后只得到含糊的 400。Gemini 原生格式不做校验。
+type RequestValidator struct {
+	enabled bool
+	opts    apicompat.ValidateOptions
+}
+
+// NewRequestValidator 根据网关配置构建校验器；未启用时不做任何处理。
+func NewRequestValidator(cfg *config.Config) *RequestValidator {
+	if cfg == nil || !cfg.Gateway.RequestValidation.Enabled {
+		return &RequestValidator{}
+	}
+	return &RequestValidator{
+		enabled: true,
+		opts:    apicompat.ValidateOptions{AllowUnknownFields: cfg.Gateway.RequestValidation.AllowUnknownFields},
+	}
+}
+
+// Validate 校验请求体，不合法时返回 400 错误
+func (v *RequestValidator) Validate(body []byte, format RequestParamFormat) error {
+	if v == nil || !v.enabled {
+		return nil
+	}
+	var err error
+	switch format {
+	case RequestParamFormatAnthropic:
+		err = apicompat.ValidateAnthropicRequest(body, v.opts)
+	case RequestParamFormatChatCompletions:
+		err = apicompat.ValidateChatCompletionsRequest(body, v.opts)
+	case RequestParamFormatResponses:
+		err = apicompat.ValidateResponsesRequest(body, v.opts)
+	}
+	if err != nil {
+		return infraerrors.BadRequest("INVALID_REQUEST_BODY", "invalid request body: "+err.Error())
+	}
+	return nil
+}
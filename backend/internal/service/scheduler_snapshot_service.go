@@ -40,6 +40,7 @@ type SchedulerSnapshotService struct {
 	fallbackLimit *fallbackLimiter
 	lagMu         sync.Mutex
 	lagFailures   int
+	rotation      *AccountRotationService
 }
 
 func NewSchedulerSnapshotService(
@@ -64,6 +65,14 @@ func NewSchedulerSnapshotService(
 	}
 }
 
+// SetAccountRotationService 设置账号轮换策略，选号结果会经其过滤/调整优先级
+func (s *SchedulerSnapshotService) SetAccountRotationService(rotation *AccountRotationService) {
+	if s == nil {
+		return
+	}
+	s.rotation = rotation
+}
+
 func (s *SchedulerSnapshotService) Start() {
 	if s == nil || s.cache == nil {
 		return
@@ -114,7 +123,7 @@ func (s *SchedulerSnapshotService) ListSchedulableAccounts(ctx context.Context,
 		if err != nil {
 			logger.LegacyPrintf("service.scheduler_snapshot", "[Scheduler] cache read failed: bucket=%s err=%v", bucket.String(), err)
 		} else if hit {
-			return derefAccounts(cached), useMixed, nil
+			return s.rotation.Apply(ctx, derefAccounts(cached)), useMixed, nil
 		}
 	}
 
@@ -136,7 +145,8 @@ func (s *SchedulerSnapshotService) ListSchedulableAccounts(ctx context.Context,
 		}
 	}
 
-	return accounts, useMixed, nil
+	// 缓存写入的是原始快照，轮换策略只作用于返回的副本
+	return s.rotation.Apply(ctx, append([]Account(nil), accounts...)), useMixed, nil
 }
 
 func (s *SchedulerSnapshotService) GetAccount(ctx context.Context, accountID int64) (*Account, error) {
@@ -148,7 +158,7 @@ func (s *SchedulerSnapshotService) GetAccount(ctx context.Context, accountID int
 		if err != nil {
 			logger.LegacyPrintf("service.scheduler_snapshot", "[Scheduler] account cache read failed: id=%d err=%v", accountID, err)
 		} else if account != nil {
-			return account, nil
+			return s.applyRotationToAccount(ctx, account), nil
 		}
 	}
 
@@ -157,7 +167,21 @@ func (s *SchedulerSnapshotService) GetAccount(ctx context.Context, accountID int
 	}
 	fallbackCtx, cancel := s.withFallbackTimeout(ctx)
 	defer cancel()
-	return s.accountRepo.GetByID(fallbackCtx, accountID)
+	account, err := s.accountRepo.GetByID(fallbackCtx, accountID)
+	if err != nil || account == nil {
+		return account, err
+	}
+	return s.applyRotationToAccount(ctx, account), nil
+}
+
+// applyRotationToAccount 被轮换策略停用的账号以不可调度的副本返回，使粘性会话切换到其他账号
+func (s *SchedulerSnapshotService) applyRotationToAccount(ctx context.Context, account *Account) *Account {
+	if !s.rotation.IsAccountDisabled(ctx, account.ID) {
+		return account
+	}
+	cp := *account
+	cp.Schedulable = false
+	return &cp
 }
 
 // GetGroupByID 获取分组信息（供调度器使用）
@@ -481,7 +505,7 @@ func (s *SchedulerSnapshotService) rebuildByGroupIDs(ctx context.Context, groupI
 	if len(groupIDs) == 0 {
 		return nil
 	}
-	platforms := []string{PlatformAnthropic, PlatformGemini, PlatformOpenAI, PlatformAntigravity}
+	platforms := []string{PlatformAnthropic, PlatformGemini, PlatformOpenAI, PlatformAntigravity, PlatformCustom}
 	var firstErr error
 	for _, platform := range platforms {
 		if err := s.rebuildBucketsForPlatform(ctx, platform, groupIDs, reason, seen); err != nil && firstErr == nil {
@@ -783,7 +807,7 @@ func (s *SchedulerSnapshotService) fullRebuildInterval() time.Duration {
 
 func (s *SchedulerSnapshotService) defaultBuckets(ctx context.Context) ([]SchedulerBucket, error) {
 	buckets := make([]SchedulerBucket, 0)
-	platforms := []string{PlatformAnthropic, PlatformGemini, PlatformOpenAI, PlatformAntigravity}
+	platforms := []string{PlatformAnthropic, PlatformGemini, PlatformOpenAI, PlatformAntigravity, PlatformCustom}
 	for _, platform := range platforms {
 		buckets = append(buckets, SchedulerBucket{GroupID: 0, Platform: platform, Mode: SchedulerModeSingle})
 		buckets = append(buckets, SchedulerBucket{GroupID: 0, Platform: platform, Mode: SchedulerModeForced})
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSecretLeakReplacement     = "[REDACTED_SECRET]"
+	defaultSecretLeakRefreshInterval = 5 * time.Minute
+	secretLeakLoadTimeout            = 10 * time.Second
+	secretLeakMinSecretLength        = 16
+	secretLeakMaxHoldBytes           = 512
+)
+
+// secretLeakCredentialKeys 账号凭证中需要防泄露的字段
+var secretLeakCredentialKeys = []string{
+	"api_key", "access_token", "refresh_token", "id_token", "session_key", "session_token",
+	"aws_access_key_id", "aws_secret_access_key", "aws_session_token",
+}
+
+// SecretLeakGuard 响应内容密钥泄露防护：扫描返回给客户端的文本，
+// 将网关自身持有的凭证（上游账号 API Key/令牌、JWT 密钥）与配置的正则命中内容替换为占位文本，
+// 防止提示词注入诱导模型把系统提示词中的网关凭证输出给调用方。
+type SecretLeakGuard struct {
+	cfg             config.GatewaySecretLeakGuardConfig
+	accountRepo     AccountRepository
+	rules           []piiRedactionRule
+	static          []string
+	replacement     string
+	refreshInterval time.Duration
+
+	mu         sync.Mutex
+	refreshing bool
+	snapshot   atomic.Pointer[secretLeakSnapshot]
+}
+
+type secretLeakSnapshot struct {
+	// secrets 按长度降序，保证较长的凭证先被替换
+	secrets  []string
+	loadedAt time.Time
+}
+
+// NewSecretLeakGuard 根据网关配置构建防护器；配置中的非法正则已由 config.Validate 拦截，此处跳过以防御性兜底。
+func NewSecretLeakGuard(cfg *config.Config, accountRepo AccountRepository) *SecretLeakGuard {
+	g := &SecretLeakGuard{accountRepo: accountRepo, replacement: defaultSecretLeakReplacement, refreshInterval: defaultSecretLeakRefreshInterval}
+	if cfg == nil {
+		return g
+	}
+	g.cfg = cfg.Gateway.SecretLeakGuard
+	if r := strings.TrimSpace(g.cfg.Replacement); r != "" {
+		g.replacement = r
+	}
+	if g.cfg.RefreshIntervalSeconds > 0 {
+		g.refreshInterval = time.Duration(g.cfg.RefreshIntervalSeconds) * time.Second
+	}
+	for _, p := range g.cfg.Patterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil || p.Regex == "" {
+			continue
+		}
+		replacement := p.Replacement
+		if replacement == "" {
+			replacement = g.replacement
+		}
+		g.rules = append(g.rules, piiRedactionRule{name: p.Name, re: re, replacement: replacement})
+	}
+	if secret := strings.TrimSpace(cfg.JWT.Secret); len(secret) >= secretLeakMinSecretLength {
+		g.static = append(g.static, secret)
+	}
+	return g
+}
+
+// Enabled 是否启用响应内容防护
+func (g *SecretLeakGuard) Enabled() bool {
+	return g != nil && g.cfg.Enabled
+}
+
+// secrets 返回当前凭证快照；首次调用同步加载，过期后在后台刷新并继续使用旧快照
+func (g *SecretLeakGuard) secrets(ctx context.Context) []string {
+	snap := g.snapshot.Load()
+	if snap == nil {
+		g.mu.Lock()
+		if snap = g.snapshot.Load(); snap == nil {
+			snap = g.load(ctx)
+			g.snapshot.Store(snap)
+		}
+		g.mu.Unlock()
+		return snap.secrets
+	}
+	if time.Since(snap.loadedAt) >= g.refreshInterval {
+		g.mu.Lock()
+		if !g.refreshing {
+			g.refreshing = true
+			go func() {
+				loaded := g.load(context.Background())
+				g.mu.Lock()
+				g.snapshot.Store(loaded)
+				g.refreshing = false
+				g.mu.Unlock()
+			}()
+		}
+		g.mu.Unlock()
+	}
+	return snap.secrets
+}
+
+func (g *SecretLeakGuard) load(ctx context.Context) *secretLeakSnapshot {
+	seen := make(map[string]struct{})
+	secrets := make([]string, 0, len(g.static))
+	add := func(s string) {
+		s = strings.TrimSpace(s)
+		if len(s) < secretLeakMinSecretLength {
+			return
+		}
+		if _, ok := seen[s]; ok {
+			return
+		}
+		seen[s] = struct{}{}
+		secrets = append(secrets, s)
+	}
+	for _, s := range g.static {
+		add(s)
+	}
+
+	if g.cfg.IncludeAccountCredentials && g.accountRepo != nil {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), secretLeakLoadTimeout)
+		defer cancel()
+		accounts, err := g.accountRepo.ListActive(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Warn("secret_leak_guard.load_accounts_failed", zap.Error(err))
+		}
+		for i := range accounts {
+			for _, key := range secretLeakCredentialKeys {
+				add(accounts[i].GetCredential(key))
+			}
+		}
+	}
+
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return &secretLeakSnapshot{secrets: secrets, loadedAt: time.Now()}
+}
+
+// mask 替换文本中的已知凭证与正则命中内容，返回替换后的文本与命中次数
+func (g *SecretLeakGuard) mask(text string, secrets []string) (string, int) {
+	count := 0
+	for _, secret := range secrets {
+		if n := strings.Count(text, secret); n > 0 {
+			text = strings.ReplaceAll(text, secret, g.replacement)
+			count += n
+		}
+	}
+	for _, rule := range g.rules {
+		text = rule.re.ReplaceAllStringFunc(text, func(string) string {
+			count++
+			return rule.replacement
+		})
+	}
+	return text, count
+}
+
+// NewStream 为单个响应创建扫描器
+func (g *SecretLeakGuard) NewStream(ctx context.Context) *SecretLeakStream {
+	return &SecretLeakStream{guard: g, secrets: g.secrets(ctx), pending: make(map[string]*secretLeakPending)}
+}
+
+// SecretLeakStream 单个响应的扫描状态。
+// 流式响应中凭证通常被拆分到多个文本增量事件里，因此按内容块累积文本：
+// 末尾可能属于未完整凭证的连续字符暂缓输出，直到遇到分隔符或内容块结束，再整体替换后输出。
+// 非 SSE 响应按写入块直接替换。
+type SecretLeakStream struct {
+	guard   *SecretLeakGuard
+	secrets []string
+	buf     []byte
+	pending map[string]*secretLeakPending
+	order   []string
+	masked  int
+}
+
+// secretLeakPending 某个内容块暂缓输出的文本及用于补发的事件模板
+type secretLeakPending struct {
+	text    string
+	lines   []string
+	payload string
+	path    string
+}
+
+type secretLeakTextPath struct {
+	key  string
+	path string
+}
+
+// Masked 本次响应累计替换次数
+func (s *SecretLeakStream) Masked() int {
+	return s.masked
+}
+
+// Body 处理非 SSE 响应的一次写入
+func (s *SecretLeakStream) Body(p []byte) []byte {
+	masked, n := s.guard.mask(string(p), s.secrets)
+	if n == 0 {
+		return p
+	}
+	s.masked += n
+	return []byte(masked)
+}
+
+// SSE 处理 SSE 响应的一次写入，返回可以立即发送给客户端的字节；不完整的事件留待后续写入
+func (s *SecretLeakStream) SSE(p []byte) []byte {
+	s.buf = append(s.buf, p...)
+	var out strings.Builder
+	for {
+		idx := strings.Index(string(s.buf), "\n\n")
+		if idx < 0 {
+			break
+		}
+		block := string(s.buf[:idx+2])
+		s.buf = s.buf[idx+2:]
+		out.WriteString(s.event(block))
+	}
+	return []byte(out.String())
+}
+
+// Finish 响应结束：补发所有暂缓文本并输出剩余的不完整事件
+func (s *SecretLeakStream) Finish() []byte {
+	out := s.flushPending()
+	if len(s.buf) > 0 {
+		out += s.maskRaw(string(s.buf))
+		s.buf = nil
+	}
+	return []byte(out)
+}
+
+func (s *SecretLeakStream) event(block string) string {
+	lines := strings.Split(strings.TrimSuffix(block, "\n\n"), "\n")
+	dataIdx := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "data:") {
+			if dataIdx >= 0 {
+				dataIdx = -1
+				break
+			}
+			dataIdx = i
+		}
+	}
+	if dataIdx < 0 {
+		return s.flushPending() + s.maskRaw(block)
+	}
+	payload := strings.TrimSpace(strings.TrimPrefix(lines[dataIdx], "data:"))
+	if !gjson.Valid(payload) {
+		return s.flushPending() + s.maskRaw(block)
+	}
+	paths := secretLeakTextPaths(gjson.Parse(payload))
+	if len(paths) == 0 {
+		return s.flushPending() + s.maskRaw(block)
+	}
+
+	// 模板中清空全部文本字段，补发时只填入对应内容块的暂缓文本
+	template := payload
+	for _, tp := range paths {
+		template, _ = sjson.Set(template, tp.path, "")
+	}
+	for _, tp := range paths {
+		text := gjson.Get(payload, tp.path).String()
+		p := s.pending[tp.key]
+		if p == nil {
+			p = &secretLeakPending{}
+			s.pending[tp.key] = p
+			s.order = append(s.order, tp.key)
+		}
+		emit, hold := splitSecretLeakHold(p.text + text)
+		p.text = hold
+		p.lines, p.payload, p.path = lines, template, tp.path
+		emitted, n := s.guard.mask(emit, s.secrets)
+		s.masked += n
+		payload, _ = sjson.Set(payload, tp.path, emitted)
+	}
+	lines[dataIdx] = "data: " + payload
+	return s.maskRaw(strings.Join(lines, "\n") + "\n\n")
+}
+
+// flushPending 按出现顺序补发各内容块的暂缓文本
+func (s *SecretLeakStream) flushPending() string {
+	var out strings.Builder
+	for _, key := range s.order {
+		p := s.pending[key]
+		if p == nil || p.text == "" {
+			continue
+		}
+		text, n := s.guard.mask(p.text, s.secrets)
+		s.masked += n
+		payload, err := sjson.Set(p.payload, p.path, text)
+		p.text = ""
+		if err != nil {
+			continue
+		}
+		lines := make([]string, 0, len(p.lines))
+		for _, line := range p.lines {
+			if strings.HasPrefix(line, "data:") {
+				line = "data: " + payload
+			}
+			lines = append(lines, line)
+		}
+		out.WriteString(strings.Join(lines, "\n") + "\n\n")
+	}
+	return out.String()
+}
+
+func (s *SecretLeakStream) maskRaw(raw string) string {
+	masked, n := s.guard.mask(raw, s.secrets)
+	s.masked += n
+	return masked
+}
+
+// splitSecretLeakHold 把文本拆为可立即输出的部分与末尾暂缓部分：
+// 末尾连续的凭证字符（字母数字与 -_.+/=:~）可能是被截断的凭证，超过上限时不再暂缓
+func splitSecretLeakHold(text string) (string, string) {
+	i := len(text)
+	for i > 0 && isSecretLeakTokenByte(text[i-1]) {
+		i--
+	}
+	if len(text)-i > secretLeakMaxHoldBytes {
+		return text, ""
+	}
+	return text[:i], text[i:]
+}
+
+func isSecretLeakTokenByte(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-_.+/=:~", c) >= 0
+}
+
+// secretLeakTextPaths 识别各协议流式事件中的文本增量字段：
+// Anthropic content_block_delta、OpenAI Responses *.delta、Chat Completions / Completions choices、Gemini candidates
+func secretLeakTextPaths(event gjson.Result) []secretLeakTextPath {
+	var paths []secretLeakTextPath
+	eventType := event.Get("type").String()
+
+	if eventType == "content_block_delta" {
+		index := event.Get("index").String()
+		for _, field := range []string{"text", "thinking", "partial_json"} {
+			if event.Get("delta."+field).Type == gjson.String {
+				paths = append(paths, secretLeakTextPath{key: "anthropic:" + index + ":" + field, path: "delta." + field})
+			}
+		}
+		return paths
+	}
+	if strings.HasPrefix(eventType, "response.") && strings.HasSuffix(eventType, ".delta") && event.Get("delta").Type == gjson.String {
+		key := strings.Join([]string{eventType, event.Get("output_index").String(), event.Get("content_index").String(), event.Get("summary_index").String()}, ":")
+		return append(paths, secretLeakTextPath{key: key, path: "delta"})
+	}
+
+	event.Get("choices").ForEach(func(i, choice gjson.Result) bool {
+		prefix := "choices." + i.String()
+		for _, field := range []string{"delta.content", "delta.reasoning_content", "text"} {
+			if choice.Get(field).Type == gjson.String {
+				paths = append(paths, secretLeakTextPath{key: "chat:" + i.String() + ":" + field, path: prefix + "." + field})
+			}
+		}
+		return true
+	})
+
+	for _, root := range []string{"", "response."} {
+		event.Get(root + "candidates").ForEach(func(i, candidate gjson.Result) bool {
+			candidate.Get("content.parts").ForEach(func(j, part gjson.Result) bool {
+				if part.Get("text").Type == gjson.String {
+					path := root + "candidates." + i.String() + ".content.parts." + j.String() + ".text"
+					paths = append(paths, secretLeakTextPath{key: "gemini:" + i.String() + ":" + j.String(), path: path})
+				}
+				return true
+			})
+			return true
+		})
+	}
+	return paths
+}
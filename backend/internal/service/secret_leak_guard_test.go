@@ -0,0 +1,157 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const secretLeakTestKey = "sk-ant-REDACTED"
+
+type secretLeakAccountRepoStub struct {
+	AccountRepository
+	accounts []Account
+}
+
+func (s *secretLeakAccountRepoStub) ListActive(ctx context.Context) ([]Account, error) {
+	return s.accounts, nil
+}
+
+func newSecretLeakTestGuard(patterns ...config.GatewayPIIRedactionPattern) *SecretLeakGuard {
+	cfg := &config.Config{}
+	cfg.Gateway.SecretLeakGuard = config.GatewaySecretLeakGuardConfig{
+		Enabled:                   true,
+		IncludeAccountCredentials: true,
+		Patterns:                  patterns,
+	}
+	repo := &secretLeakAccountRepoStub{accounts: []Account{
+		{ID: 1, Credentials: map[string]any{"api_key": secretLeakTestKey}},
+		{ID: 2, Credentials: map[string]any{"api_key": "short"}},
+	}}
+	return NewSecretLeakGuard(cfg, repo)
+}
+
+// anthropicTextDeltas 把文本按固定长度拆分为 content_block_delta 事件
+func anthropicTextDeltas(text string, size int) string {
+	var b strings.Builder
+	b.WriteString("event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")
+	for i := 0; i < len(text); i += size {
+		chunk := text[i:min(i+size, len(text))]
+		b.WriteString("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":" + jsonQuote(chunk) + "}}\n\n")
+	}
+	b.WriteString("event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n")
+	b.WriteString("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	return b.String()
+}
+
+func jsonQuote(s string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+// collectAnthropicText 拼接 SSE 输出中的全部 text_delta 文本
+func collectAnthropicText(t *testing.T, sse string) string {
+	var b strings.Builder
+	for _, block := range strings.Split(sse, "\n\n") {
+		for _, line := range strings.Split(block, "\n") {
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			payload := strings.TrimPrefix(line, "data: ")
+			require.True(t, gjson.Valid(payload), payload)
+			if gjson.Get(payload, "type").String() == "content_block_delta" {
+				b.WriteString(gjson.Get(payload, "delta.text").String())
+			}
+		}
+	}
+	return b.String()
+}
+
+func TestSecretLeakStream_MasksSecretSplitAcrossDeltas(t *testing.T) {
+	guard := newSecretLeakTestGuard()
+	stream := guard.NewStream(context.Background())
+
+	input := anthropicTextDeltas("Here is the key: "+secretLeakTestKey+" - keep it safe.", 5)
+	var out strings.Builder
+	// 按任意字节边界写入，模拟上游分片
+	for i := 0; i < len(input); i += 7 {
+		out.Write(stream.SSE([]byte(input[i:min(i+7, len(input))])))
+	}
+	out.Write(stream.Finish())
+
+	require.NotContains(t, out.String(), secretLeakTestKey)
+	require.Equal(t, "Here is the key: [REDACTED_SECRET] - keep it safe.", collectAnthropicText(t, out.String()))
+	require.Equal(t, 1, stream.Masked())
+	require.Less(t, strings.Index(out.String(), "REDACTED"), strings.Index(out.String(), "content_block_stop"))
+}
+
+func TestSecretLeakStream_FlushesHeldTextAtBlockEnd(t *testing.T) {
+	guard := newSecretLeakTestGuard()
+	stream := guard.NewStream(context.Background())
+
+	out := string(stream.SSE([]byte(anthropicTextDeltas("hello world", 20))))
+	out += string(stream.Finish())
+
+	require.Equal(t, "hello world", collectAnthropicText(t, out))
+	require.Zero(t, stream.Masked())
+}
+
+func TestSecretLeakStream_ChatCompletionsAndPatterns(t *testing.T) {
+	guard := newSecretLeakTestGuard(config.GatewayPIIRedactionPattern{Name: "internal", Regex: `itk_[a-z0-9]{8}`, Replacement: "[INTERNAL]"})
+	stream := guard.NewStream(context.Background())
+
+	chunks := []string{"token itk_", "abcd1234 and ", "sk-ant-api03-AbCdEf", "GhIjKlMnOpQrStUvWxYz0123456789."}
+	var out strings.Builder
+	for _, chunk := range chunks {
+		out.Write(stream.SSE([]byte("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":" + jsonQuote(chunk) + "}}]}\n\n")))
+	}
+	out.Write(stream.SSE([]byte("data: [DONE]\n\n")))
+	out.Write(stream.Finish())
+
+	var text strings.Builder
+	for _, block := range strings.Split(out.String(), "\n\n") {
+		payload := strings.TrimPrefix(block, "data: ")
+		if gjson.Valid(payload) {
+			text.WriteString(gjson.Get(payload, "choices.0.delta.content").String())
+		}
+	}
+	require.Equal(t, "token [INTERNAL] and [REDACTED_SECRET].", text.String())
+	require.True(t, strings.HasSuffix(out.String(), "data: [DONE]\n\n"))
+}
+
+func TestSecretLeakStream_BodyMasksNonStreamResponse(t *testing.T) {
+	guard := newSecretLeakTestGuard()
+	stream := guard.NewStream(context.Background())
+
+	out := stream.Body([]byte(`{"content":[{"type":"text","text":"` + secretLeakTestKey + `"}]}`))
+	require.JSONEq(t, `{"content":[{"type":"text","text":"[REDACTED_SECRET]"}]}`, string(out))
+	require.Equal(t, 1, stream.Masked())
+}
+
+func TestSecretLeakGuard_DisabledAndShortCredentialsIgnored(t *testing.T) {
+	require.False(t, NewSecretLeakGuard(&config.Config{}, nil).Enabled())
+	require.False(t, (*SecretLeakGuard)(nil).Enabled())
+
+	guard := newSecretLeakTestGuard()
+	require.Equal(t, []string{secretLeakTestKey}, guard.secrets(context.Background()))
+}
+
+func TestSplitSecretLeakHold(t *testing.T) {
+	emit, hold := splitSecretLeakHold("abc def sk-12")
+	require.Equal(t, "abc def ", emit)
+	require.Equal(t, "sk-12", hold)
+
+	emit, hold = splitSecretLeakHold("done. ")
+	require.Equal(t, "done. ", emit)
+	require.Empty(t, hold)
+
+	long := strings.Repeat("a", secretLeakMaxHoldBytes+1)
+	emit, hold = splitSecretLeakHold(long)
+	require.Equal(t, long, emit)
+	require.Empty(t, hold)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"io"
+	"sync"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
+)
+
+var sseReaderPool = sync.Pool{
+	New: func() any {
+		return sse.NewReader(nil, defaultMaxLineSize)
+	},
+}
+
+// getSSEReader 从对象池获取 SSE 读取器（复用 64KB 读缓冲）
+func getSSEReader(r io.Reader, maxLineSize int) *sse.Reader {
+	v := sseReaderPool.Get()
+	reader, ok := v.(*sse.Reader)
+	if !ok || reader == nil {
+		return sse.NewReader(r, maxLineSize)
+	}
+	reader.Reset(r, maxLineSize)
+	return reader
+}
+
+func putSSEReader(reader *sse.Reader) {
+	if reader == nil {
+		return
+	}
+	reader.Reset(nil, defaultMaxLineSize)
+	sseReaderPool.Put(reader)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEReaderPool_GetPutDoesNotPanic(t *testing.T) {
+	reader := getSSEReader(strings.NewReader("data: a\n\ndata: b\n"), 1024)
+	require.NotNil(t, reader)
+	require.True(t, reader.Scan())
+	require.Equal(t, "data: a", reader.Text())
+	putSSEReader(reader)
+
+	// 复用后读取新的数据源，不残留上一次的状态
+	reader = getSSEReader(strings.NewReader("data: c\n"), 1024)
+	require.True(t, reader.Scan())
+	require.Equal(t, "data: c", reader.Text())
+	require.False(t, reader.Scan())
+	require.NoError(t, reader.Err())
+	putSSEReader(reader)
+
+	// 允许传入 nil，确保不会 panic
+	putSSEReader(nil)
+}
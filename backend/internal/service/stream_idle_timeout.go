@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/ctxkey"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrStreamIdleTimeout 上游流在 gateway.stream_data_interval_timeout 内未返回任何数据
+var ErrStreamIdleTimeout = errors.New("upstream stream idle timeout")
+
+// streamIdleTimeoutBody 为上游流式响应体增加逐次读取的空闲超时。
+// 超时后关闭底层 body 以解除阻塞中的 Read，并让后续 Read 返回 ErrStreamIdleTimeout，
+// 使同步 scanner 循环也能及时退出、释放并发槽位。
+type streamIdleTimeoutBody struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+}
+
+// wrapStreamIdleTimeout 包装上游响应体；timeout<=0 时原样返回。
+// onTimeout 在超时触发时调用一次（可为 nil），用于记录账号流超时。
+func wrapStreamIdleTimeout(body io.ReadCloser, timeout time.Duration, onTimeout func()) io.ReadCloser {
+	if body == nil || timeout <= 0 {
+		return body
+	}
+	b := &streamIdleTimeoutBody{body: body, timeout: timeout}
+	b.timer = time.AfterFunc(timeout, func() {
+		if !b.timedOut.CompareAndSwap(false, true) {
+			return
+		}
+		_ = b.body.Close()
+		if onTimeout != nil {
+			onTimeout()
+		}
+	})
+	return b
+}
+
+func (b *streamIdleTimeoutBody) Read(p []byte) (int, error) {
+	if b.timedOut.Load() {
+		return 0, ErrStreamIdleTimeout
+	}
+	n, err := b.body.Read(p)
+	if b.timedOut.Load() {
+		return n, ErrStreamIdleTimeout
+	}
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *streamIdleTimeoutBody) Close() error {
+	b.timer.Stop()
+	if b.timedOut.Load() {
+		return nil
+	}
+	return b.body.Close()
+}
+
+// streamIdleTimeout 返回配置的流数据间隔超时，0 表示禁用
+func streamIdleTimeout(cfg *config.Config) time.Duration {
+	if cfg == nil || cfg.Gateway.StreamDataIntervalTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(cfg.Gateway.StreamDataIntervalTimeout) * time.Second
+}
+
+// streamTimeoutFailoverEnabled 流超时且未向客户端输出时是否按 failover 处理
+func streamTimeoutFailoverEnabled(cfg *config.Config) bool {
+	return cfg != nil && cfg.Gateway.StreamTimeoutFailover
+}
+
+// newStreamTimeoutFailoverError 构造流空闲超时的 failover 错误（切换账号重试，不在同账号重试）
+func newStreamTimeoutFailoverError(timeout time.Duration) *UpstreamFailoverError {
+	body, _ := json.Marshal(map[string]any{
+		"type": "error",
+		"error": map[string]string{
+			"type":    "stream_timeout",
+			"message": fmt.Sprintf("upstream stream idle for %s", timeout),
+		},
+	})
+	return &UpstreamFailoverError{
+		StatusCode:   http.StatusGatewayTimeout,
+		ResponseBody: body,
+	}
+}
+
+// guardStreamIdleTimeout 为上游流式响应体挂载空闲超时（用于未自带超时监控的兼容转发路径）。
+// 超时时记录账号流超时；返回的 stop 必须在响应处理结束后调用，避免计时器在请求结束后误触发。
+func guardStreamIdleTimeout(ctx context.Context, resp *http.Response, cfg *config.Config, rateLimitService *RateLimitService, account *Account, model string) (stop func()) {
+	timeout := streamIdleTimeout(cfg)
+	if resp == nil || resp.Body == nil || timeout <= 0 {
+		return func() {}
+	}
+	body := wrapStreamIdleTimeout(resp.Body, timeout, func() {
+		logger.LegacyPrintf("service.gateway", "Stream data interval timeout: account=%d model=%s interval=%s", account.ID, model, timeout)
+		if rateLimitService != nil {
+			rateLimitService.HandleStreamTimeout(ctx, account, model)
+		}
+	})
+	resp.Body = body
+	return func() { _ = body.Close() }
+}
+
+// streamErrorEventFormat 流式错误事件的协议格式
+type streamErrorEventFormat int
+
+const (
+	streamErrorEventAnthropic streamErrorEventFormat = iota
+	streamErrorEventChatCompletions
+	streamErrorEventResponses
+	streamErrorEventGemini
+)
+
+// streamErrorEvent 按协议构造流式 SSE 错误事件（errType 同时用作 Responses 的 code）。
+// requestID 非空时在事件顶层附带 request_id（与 X-Request-ID 响应头一致），便于用户反馈问题时定位请求。
+func streamErrorEvent(format streamErrorEventFormat, errType string, statusCode int, message, requestID string) string {
+	quoted := strconv.Quote(message)
+	typ := strconv.Quote(errType)
+	rid := ""
+	if requestID != "" {
+		rid = `,"request_id":` + strconv.Quote(requestID)
+	}
+	switch format {
+	case streamErrorEventChatCompletions:
+		return `data: {"error":{"type":` + typ + `,"message":` + quoted + "}" + rid + "}\n\n"
+	case streamErrorEventResponses:
+		return `data: {"type":"error","sequence_number":0,"error":{"type":"upstream_error","message":` + quoted + `,"code":` + typ + `}` + rid + `}` + "\n\n"
+	case streamErrorEventGemini:
+		status := "INTERNAL"
+		if statusCode == http.StatusGatewayTimeout {
+			status = "DEADLINE_EXCEEDED"
+		}
+		return `data: {"error":{"code":` + strconv.Itoa(statusCode) + `,"message":` + quoted + `,"status":"` + status + `"}` + rid + `}` + "\n\n"
+	default:
+		return `event: error` + "\n" + `data: {"type":"error","error":{"type":` + typ + `,"message":` + quoted + "}" + rid + "}\n\n"
+	}
+}
+
+// gatewayRequestID 返回网关为当前请求生成的请求 ID（X-Request-ID）
+func gatewayRequestID(c *gin.Context) string {
+	if c == nil || c.Request == nil {
+		return ""
+	}
+	requestID, _ := c.Request.Context().Value(ctxkey.RequestID).(string)
+	return strings.TrimSpace(requestID)
+}
+
+// streamIdleTimeoutEvent 按协议构造流空闲超时的 SSE 错误事件
+func streamIdleTimeoutEvent(format streamErrorEventFormat, message, requestID string) string {
+	return streamErrorEvent(format, "stream_timeout", http.StatusGatewayTimeout, message, requestID)
+}
+
+// handleStreamingIdleTimeout 流式客户端遇到上游流空闲超时：
+// 尚未向客户端输出且开启 failover 时返回 failover 错误，否则写入协议对应的 SSE 错误事件并结束流。
+func handleStreamingIdleTimeout(c *gin.Context, cfg *config.Config, format streamErrorEventFormat) error {
+	timeout := streamIdleTimeout(cfg)
+	if streamTimeoutFailoverEnabled(cfg) && !c.Writer.Written() {
+		return newStreamTimeoutFailoverError(timeout)
+	}
+	if _, err := fmt.Fprint(c.Writer, streamIdleTimeoutEvent(format, fmt.Sprintf("upstream stream idle for %s", timeout), gatewayRequestID(c))); err == nil {
+		c.Writer.Flush()
+	}
+	return ErrStreamIdleTimeout
+}
+
+// handleBufferedStreamIdleTimeout 非流式客户端（缓冲上游流）遇到上游流空闲超时：
+// 此时尚未输出任何内容，开启 failover 时切换账号重试，否则返回 504。
+func handleBufferedStreamIdleTimeout(c *gin.Context, cfg *config.Config, writeError func(c *gin.Context, statusCode int, errType, message string)) error {
+	timeout := streamIdleTimeout(cfg)
+	if streamTimeoutFailoverEnabled(cfg) {
+		return newStreamTimeoutFailoverError(timeout)
+	}
+	writeError(c, http.StatusGatewayTimeout, "stream_timeout", fmt.Sprintf("upstream stream idle for %s", timeout))
+	return ErrStreamIdleTimeout
+}
@@ -0,0 +1,89 @@
+//go:build unit
+
+package service
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapStreamIdleTimeout_Disabled(t *testing.T) {
+	body := io.NopCloser(strings.NewReader("data"))
+	require.Equal(t, body, wrapStreamIdleTimeout(body, 0, nil))
+}
+
+func TestWrapStreamIdleTimeout_UnblocksStalledRead(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+
+	var fired atomic.Int32
+	body := wrapStreamIdleTimeout(pr, 50*time.Millisecond, func() { fired.Add(1) })
+	defer func() { _ = body.Close() }()
+
+	go func() { _, _ = pw.Write([]byte("data: first\n")) }()
+	buf := make([]byte, 64)
+	n, err := body.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "data: first\n", string(buf[:n]))
+
+	// 上游不再写入，Read 应在空闲超时后返回 ErrStreamIdleTimeout
+	_, err = body.Read(buf)
+	require.ErrorIs(t, err, ErrStreamIdleTimeout)
+	require.Equal(t, int32(1), fired.Load())
+}
+
+func TestWrapStreamIdleTimeout_StopAfterCloseDoesNotFire(t *testing.T) {
+	var fired atomic.Int32
+	body := wrapStreamIdleTimeout(io.NopCloser(strings.NewReader("")), 20*time.Millisecond, func() { fired.Add(1) })
+	require.NoError(t, body.Close())
+	time.Sleep(60 * time.Millisecond)
+	require.Zero(t, fired.Load())
+}
+
+func TestHandleAnthropicStreamingResponse_IdleTimeoutEmitsErrorEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+	resp := &http.Response{Header: http.Header{}, Body: wrapStreamIdleTimeout(pr, 50*time.Millisecond, nil)}
+
+	cfg := &config.Config{}
+	cfg.Gateway.StreamDataIntervalTimeout = 1
+	svc := &OpenAIGatewayService{cfg: cfg}
+	_, err := svc.handleAnthropicStreamingResponse(resp, c, "claude-sonnet-4.5", "gpt-5", "gpt-5", time.Now())
+	require.True(t, errors.Is(err, ErrStreamIdleTimeout))
+	require.Contains(t, rec.Body.String(), "event: error")
+	require.Contains(t, rec.Body.String(), `"type":"stream_timeout"`)
+}
+
+func TestHandleStreamingIdleTimeout_FailoverBeforeOutput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	cfg := &config.Config{}
+	cfg.Gateway.StreamDataIntervalTimeout = 60
+	cfg.Gateway.StreamTimeoutFailover = true
+
+	err := handleStreamingIdleTimeout(c, cfg, streamErrorEventChatCompletions)
+	var failoverErr *UpstreamFailoverError
+	require.ErrorAs(t, err, &failoverErr)
+	require.Equal(t, http.StatusGatewayTimeout, failoverErr.StatusCode)
+	require.False(t, failoverErr.RetryableOnSameAccount)
+
+	// 已向客户端输出后只能写入错误事件
+	_, _ = c.Writer.WriteString("data: {}\n\n")
+	err = handleStreamingIdleTimeout(c, cfg, streamErrorEventChatCompletions)
+	require.ErrorIs(t, err, ErrStreamIdleTimeout)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// streamKeepaliveComment SSE 注释行，所有协议的 SSE 客户端都会忽略
+const streamKeepaliveComment = ":\n\n"
+
+// keepaliveResponseWriter 串行化流式响应写入，并在下游空闲超过间隔时注入 SSE 注释 ping。
+// 用于没有内置 keepalive 的同步流式转发路径（scanner 阻塞读取上游期间也能保持下游连接活跃）。
+type keepaliveResponseWriter struct {
+	gin.ResponseWriter
+	mu        sync.Mutex
+	lastWrite time.Time
+	stopped   bool
+}
+
+func (w *keepaliveResponseWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastWrite = time.Now()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *keepaliveResponseWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastWrite = time.Now()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *keepaliveResponseWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *keepaliveResponseWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ResponseWriter.Flush()
+}
+
+// ping 下游空闲超过 interval 时写入 SSE 注释；返回 false 表示应停止
+func (w *keepaliveResponseWriter) ping(interval time.Duration) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return false
+	}
+	if time.Since(w.lastWrite) < interval {
+		return true
+	}
+	// 仅在已声明为 SSE 响应时注入，避免错误响应等非流式输出被提前提交
+	if !strings.HasPrefix(w.ResponseWriter.Header().Get("Content-Type"), "text/event-stream") {
+		return true
+	}
+	if _, err := w.ResponseWriter.WriteString(streamKeepaliveComment); err != nil {
+		return false
+	}
+	w.ResponseWriter.Flush()
+	w.lastWrite = time.Now()
+	return true
+}
+
+// startStreamKeepalive 为流式响应开启下游 keepalive（gateway.stream_keepalive_interval，0 表示禁用）。
+// 会临时替换 c.Writer；返回的 stop 必须在流处理结束后调用以停止 ping 并恢复原 writer。
+func startStreamKeepalive(c *gin.Context, cfg *config.Config) (stop func()) {
+	if c == nil || cfg == nil || cfg.Gateway.StreamKeepaliveInterval <= 0 {
+		return func() {}
+	}
+	return startStreamKeepaliveWithInterval(c, time.Duration(cfg.Gateway.StreamKeepaliveInterval)*time.Second)
+}
+
+func startStreamKeepaliveWithInterval(c *gin.Context, interval time.Duration) (stop func()) {
+	original := c.Writer
+	w := &keepaliveResponseWriter{ResponseWriter: original, lastWrite: time.Now()}
+	c.Writer = w
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if !w.ping(interval) {
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			w.mu.Lock()
+			w.stopped = true
+			w.mu.Unlock()
+			close(done)
+			c.Writer = original
+		})
+	}
+}
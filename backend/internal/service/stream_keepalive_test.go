@@ -0,0 +1,58 @@
+//go:build unit
+
+package service
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartStreamKeepalive_InjectsCommentWhenIdle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	original := c.Writer
+
+	stop := startStreamKeepaliveWithInterval(c, 20*time.Millisecond)
+	c.Header("Content-Type", "text/event-stream")
+	_, _ = c.Writer.WriteString("data: first\n\n")
+	time.Sleep(80 * time.Millisecond)
+	stop()
+
+	require.Equal(t, original, c.Writer, "stop 后应恢复原 writer")
+	body := rec.Body.String()
+	require.True(t, strings.HasPrefix(body, "data: first\n\n"))
+	require.Contains(t, body, streamKeepaliveComment)
+
+	// 停止后不再注入
+	size := rec.Body.Len()
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, size, rec.Body.Len())
+}
+
+func TestStartStreamKeepalive_SkipsNonSSEResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	stop := startStreamKeepaliveWithInterval(c, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	stop()
+
+	require.Zero(t, rec.Body.Len(), "未声明 SSE 响应时不应提前写入")
+}
+
+func TestStartStreamKeepalive_DisabledByConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	original := c.Writer
+
+	stop := startStreamKeepalive(c, newMinimalGatewayService().cfg)
+	require.Equal(t, original, c.Writer)
+	stop()
+}
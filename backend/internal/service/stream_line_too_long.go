@@ -0,0 +1,41 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// streamMaxLineSize 返回上游 SSE 单行上限（gateway.max_line_size）
+func streamMaxLineSize(cfg *config.Config) int {
+	if cfg != nil && cfg.Gateway.MaxLineSize > 0 {
+		return cfg.Gateway.MaxLineSize
+	}
+	return defaultMaxLineSize
+}
+
+// streamLineTooLongMessage 上游 SSE 单行超过 gateway.max_line_size 时返回给客户端的提示
+func streamLineTooLongMessage(maxLineSize int) string {
+	return fmt.Sprintf("upstream SSE line exceeded %d bytes", maxLineSize)
+}
+
+// handleStreamingLineTooLong 流式客户端遇到上游超长 SSE 行：写入协议对应的错误事件并结束流，
+// 避免客户端只看到一个被静默截断的流。
+func handleStreamingLineTooLong(c *gin.Context, format streamErrorEventFormat, maxLineSize int, requestID string) error {
+	logger.LegacyPrintf("service.gateway", "SSE line too long: request_id=%s max_size=%d", requestID, maxLineSize)
+	if _, err := fmt.Fprint(c.Writer, streamErrorEvent(format, "response_too_large", http.StatusBadGateway, streamLineTooLongMessage(maxLineSize), gatewayRequestID(c))); err == nil {
+		c.Writer.Flush()
+	}
+	return sse.ErrLineTooLong
+}
+
+// handleBufferedLineTooLong 非流式客户端（缓冲上游流）遇到上游超长 SSE 行：返回 502。
+func handleBufferedLineTooLong(c *gin.Context, maxLineSize int, requestID string, writeError func(c *gin.Context, statusCode int, errType, message string)) error {
+	logger.LegacyPrintf("service.gateway", "SSE line too long (buffered): request_id=%s max_size=%d", requestID, maxLineSize)
+	writeError(c, http.StatusBadGateway, "response_too_large", streamLineTooLongMessage(maxLineSize))
+	return sse.ErrLineTooLong
+}
@@ -0,0 +1,55 @@
+//go:build unit
+
+package service
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleAnthropicStreamingResponse_LineTooLongEmitsErrorEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	body := "data: " + strings.Repeat("x", 256) + "\n\n"
+	resp := &http.Response{Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+
+	cfg := &config.Config{}
+	cfg.Gateway.MaxLineSize = 128
+	svc := &OpenAIGatewayService{cfg: cfg}
+	_, err := svc.handleAnthropicStreamingResponse(resp, c, "claude-sonnet-4.5", "gpt-5", "gpt-5", time.Now())
+	require.True(t, errors.Is(err, sse.ErrLineTooLong))
+	require.Contains(t, rec.Body.String(), "event: error")
+	require.Contains(t, rec.Body.String(), `"type":"response_too_large"`)
+}
+
+func TestHandleResponsesStreamingResponse_MultiLineData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	// data 字段按 SSE 规范拆成多行时应拼接后再解析
+	body := "event: message_start\n" +
+		"data: {\"type\":\"message_start\",\n" +
+		"data: \"message\":{\"id\":\"msg_1\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"usage\":{\"input_tokens\":12,\"output_tokens\":1}}}\n\n" +
+		"event: message_delta\n" +
+		"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":5}}\n\n"
+	resp := &http.Response{Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+
+	svc := &GatewayService{cfg: &config.Config{}}
+	result, err := svc.handleResponsesStreamingResponse(resp, c, "claude-sonnet-4.5", "claude-sonnet-4.5", nil, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 12, result.Usage.InputTokens)
+	require.Equal(t, 5, result.Usage.OutputTokens)
+}
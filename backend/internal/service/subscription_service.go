@@ -634,9 +634,9 @@ func (s *SubscriptionService) ListGroupSubscriptions(ctx context.Context, groupI
 }
 
 // List 获取所有订阅（分页，支持筛选和排序）
-func (s *SubscriptionService) List(ctx context.Context, page, pageSize int, userID, groupID *int64, status, platform, sortBy, sortOrder string) ([]UserSubscription, *pagination.PaginationResult, error) {
+func (s *SubscriptionService) List(ctx context.Context, page, pageSize int, userID, groupID, organizationID *int64, status, platform, sortBy, sortOrder string) ([]UserSubscription, *pagination.PaginationResult, error) {
 	params := pagination.PaginationParams{Page: page, PageSize: pageSize}
-	subs, pag, err := s.userSubRepo.List(ctx, params, userID, groupID, status, platform, sortBy, sortOrder)
+	subs, pag, err := s.userSubRepo.List(ctx, params, userID, groupID, organizationID, status, platform, sortBy, sortOrder)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -831,15 +831,16 @@ func (s *SubscriptionService) ValidateAndCheckLimits(sub *UserSubscription, grou
 		needsMaintenance = true
 	}
 
-	// 3. 检查用量限额
+	// 3. 检查用量限额（附带窗口重置时间，供网关写 Retry-After / X-RateLimit-*）
+	now := time.Now()
 	if !sub.CheckDailyLimit(group, 0) {
-		return needsMaintenance, ErrDailyLimitExceeded
+		return needsMaintenance, withRateLimitState(ErrDailyLimitExceeded, *group.DailyLimitUSD, *group.DailyLimitUSD-sub.DailyUsageUSD, untilWindowReset(sub.DailyWindowStart, 24*time.Hour, now))
 	}
 	if !sub.CheckWeeklyLimit(group, 0) {
-		return needsMaintenance, ErrWeeklyLimitExceeded
+		return needsMaintenance, withRateLimitState(ErrWeeklyLimitExceeded, *group.WeeklyLimitUSD, *group.WeeklyLimitUSD-sub.WeeklyUsageUSD, untilWindowReset(sub.WeeklyWindowStart, 7*24*time.Hour, now))
 	}
 	if !sub.CheckMonthlyLimit(group, 0) {
-		return needsMaintenance, ErrMonthlyLimitExceeded
+		return needsMaintenance, withRateLimitState(ErrMonthlyLimitExceeded, *group.MonthlyLimitUSD, *group.MonthlyLimitUSD-sub.MonthlyUsageUSD, untilWindowReset(sub.MonthlyWindowStart, 30*24*time.Hour, now))
 	}
 
 	return needsMaintenance, nil
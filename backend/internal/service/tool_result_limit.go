@@ -0,0 +1,193 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Tool result truncation modes
+const (
+	// ToolResultLimitModeTruncate 仅保留结果开头
+	ToolResultLimitModeTruncate = "truncate"
+	// ToolResultLimitModeHeadTail 保留结果开头与结尾，省略中间部分（日志/堆栈类输出的关键信息通常在首尾）
+	ToolResultLimitModeHeadTail = "head_tail"
+)
+
+// ToolResultLimiter 在转发前截断超大的工具调用结果（Anthropic tool_result、Chat Completions role=tool 消息、
+// Responses function_call_output、Gemini functionResponse），并在截断处插入标记，
+// 避免 Agent 客户端回传数 MB 的工具输出导致上游直接返回不透明的 400。
+type ToolResultLimiter struct {
+	maxBytes int
+	mode     string
+}
+
+// NewToolResultLimiter 根据网关配置构建截断器；未配置 max_bytes 时不做任何处理。
+func NewToolResultLimiter(cfg *config.Config) *ToolResultLimiter {
+	if cfg == nil || cfg.Gateway.ToolResultLimit.MaxBytes <= 0 {
+		return &ToolResultLimiter{}
+	}
+	mode := cfg.Gateway.ToolResultLimit.Mode
+	if mode != ToolResultLimitModeTruncate {
+		mode = ToolResultLimitModeHeadTail
+	}
+	return &ToolResultLimiter{maxBytes: cfg.Gateway.ToolResultLimit.MaxBytes, mode: mode}
+}
+
+// Apply 截断请求体中超出上限的工具调用结果，返回处理后的请求体；未启用或请求体非法 JSON 时原样返回。
+// 同一个工具结果由多个文本块组成时共享同一份字节预算。
+func (l *ToolResultLimiter) Apply(body []byte, format RequestParamFormat) []byte {
+	// 整个请求体都未超限时不可能存在超限的工具结果，跳过解析
+	if l == nil || l.maxBytes <= 0 || len(body) <= l.maxBytes || !gjson.ValidBytes(body) {
+		return body
+	}
+
+	truncated := 0
+	if format == RequestParamFormatGemini {
+		body, truncated = l.applyGemini(body)
+	} else {
+		for _, group := range toolResultTextPaths(body, format) {
+			remaining := l.maxBytes
+			for _, path := range group {
+				text := gjson.GetBytes(body, path).String()
+				if len(text) <= remaining {
+					remaining -= len(text)
+					continue
+				}
+				updated, err := sjson.SetBytes(body, path, truncateToolResultText(text, remaining, l.mode))
+				if err != nil {
+					continue
+				}
+				body = updated
+				remaining = 0
+				truncated++
+			}
+		}
+	}
+	if truncated > 0 {
+		slog.Info("tool_result_truncated", "format", int(format), "count", truncated, "max_bytes", l.maxBytes)
+	}
+	return body
+}
+
+// applyGemini functionResponse.response 为任意 JSON 对象，超限时整体替换为 {"output": "<截断后的原始 JSON>"}
+func (l *ToolResultLimiter) applyGemini(body []byte) ([]byte, int) {
+	truncated := 0
+	for i, content := range gjson.GetBytes(body, "contents").Array() {
+		for j, part := range content.Get("parts").Array() {
+			resp := part.Get("functionResponse.response")
+			if !resp.Exists() || len(resp.Raw) <= l.maxBytes {
+				continue
+			}
+			replacement, err := json.Marshal(map[string]string{"output": truncateToolResultText(resp.Raw, l.maxBytes, l.mode)})
+			if err != nil {
+				continue
+			}
+			updated, err := sjson.SetRawBytes(body, fmt.Sprintf("contents.%d.parts.%d.functionResponse.response", i, j), replacement)
+			if err != nil {
+				continue
+			}
+			body = updated
+			truncated++
+		}
+	}
+	return body, truncated
+}
+
+// toolResultTextPaths 按工具结果分组收集各协议格式下工具结果文本字段的路径
+func toolResultTextPaths(body []byte, format RequestParamFormat) [][]string {
+	var groups [][]string
+	switch format {
+	case RequestParamFormatAnthropic:
+		for i, msg := range gjson.GetBytes(body, "messages").Array() {
+			content := msg.Get("content")
+			if !content.IsArray() {
+				continue
+			}
+			for j, block := range content.Array() {
+				if block.Get("type").String() != "tool_result" {
+					continue
+				}
+				groups = appendToolResultGroup(groups, block.Get("content"), fmt.Sprintf("messages.%d.content.%d.content", i, j))
+			}
+		}
+	case RequestParamFormatChatCompletions:
+		for i, msg := range gjson.GetBytes(body, "messages").Array() {
+			if msg.Get("role").String() != "tool" {
+				continue
+			}
+			groups = appendToolResultGroup(groups, msg.Get("content"), fmt.Sprintf("messages.%d.content", i))
+		}
+	case RequestParamFormatResponses:
+		for i, item := range gjson.GetBytes(body, "input").Array() {
+			if item.Get("type").String() != "function_call_output" {
+				continue
+			}
+			groups = appendToolResultGroup(groups, item.Get("output"), fmt.Sprintf("input.%d.output", i))
+		}
+	}
+	return groups
+}
+
+// appendToolResultGroup 工具结果为字符串时直接截断；为数组时截断其中的 text 字段（图片等其他块保持不变）
+func appendToolResultGroup(groups [][]string, value gjson.Result, path string) [][]string {
+	switch {
+	case value.Type == gjson.String:
+		return append(groups, []string{path})
+	case value.IsArray():
+		var group []string
+		for k, item := range value.Array() {
+			if item.Get("text").Type == gjson.String {
+				group = append(group, fmt.Sprintf("%s.%d.text", path, k))
+			}
+		}
+		if len(group) > 0 {
+			return append(groups, group)
+		}
+	}
+	return groups
+}
+
+// truncateToolResultText 将文本截断到 maxBytes 字节以内（按 UTF-8 字符边界），并插入截断标记
+func truncateToolResultText(text string, maxBytes int, mode string) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
+	head := utf8SafePrefix(text, maxBytes)
+	tail := ""
+	if mode == ToolResultLimitModeHeadTail {
+		head = utf8SafePrefix(text, maxBytes/2)
+		tail = utf8SafeSuffix(text, maxBytes-len(head))
+	}
+	omitted := len(text) - len(head) - len(tail)
+	return fmt.Sprintf("%s\n\n[... %d bytes of tool output truncated by gateway ...]\n\n%s", head, omitted, tail)
+}
+
+func utf8SafePrefix(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func utf8SafeSuffix(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	start := len(s) - n
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return s[start:]
+}
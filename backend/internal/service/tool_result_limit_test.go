@@ -0,0 +1,76 @@
+//go:build unit
+
+package service
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newTestToolResultLimiter(maxBytes int, mode string) *ToolResultLimiter {
+	cfg := &config.Config{}
+	cfg.Gateway.ToolResultLimit.MaxBytes = maxBytes
+	cfg.Gateway.ToolResultLimit.Mode = mode
+	return NewToolResultLimiter(cfg)
+}
+
+func TestTruncateToolResultText(t *testing.T) {
+	text := strings.Repeat("a", 50) + strings.Repeat("b", 50)
+
+	out := truncateToolResultText(text, 20, ToolResultLimitModeTruncate)
+	require.True(t, strings.HasPrefix(out, strings.Repeat("a", 20)+"\n\n[... 80 bytes"))
+
+	out = truncateToolResultText(text, 20, ToolResultLimitModeHeadTail)
+	require.True(t, strings.HasPrefix(out, strings.Repeat("a", 10)+"\n\n[... 80 bytes"))
+	require.True(t, strings.HasSuffix(out, "\n\n"+strings.Repeat("b", 10)))
+
+	// 不在多字节字符中间截断
+	out = truncateToolResultText(strings.Repeat("中", 10), 7, ToolResultLimitModeHeadTail)
+	require.True(t, utf8.ValidString(out))
+
+	require.Equal(t, "short", truncateToolResultText("short", 20, ToolResultLimitModeTruncate))
+}
+
+func TestToolResultLimiter_Anthropic(t *testing.T) {
+	l := newTestToolResultLimiter(16, ToolResultLimitModeTruncate)
+	big := strings.Repeat("x", 40)
+
+	body := []byte(`{"messages":[{"role":"user","content":[` +
+		`{"type":"text","text":"` + big + `"},` +
+		`{"type":"tool_result","tool_use_id":"t1","content":"` + big + `"},` +
+		`{"type":"tool_result","tool_use_id":"t2","content":[{"type":"text","text":"0123456789"},{"type":"text","text":"` + big + `"}]}]}]}`)
+	out := l.Apply(body, RequestParamFormatAnthropic)
+
+	// 普通文本块不受影响
+	require.Equal(t, big, gjson.GetBytes(out, "messages.0.content.0.text").String())
+	require.Contains(t, gjson.GetBytes(out, "messages.0.content.1.content").String(), "[... 24 bytes of tool output truncated by gateway ...]")
+	// 同一工具结果的多个文本块共享预算
+	require.Equal(t, "0123456789", gjson.GetBytes(out, "messages.0.content.2.content.0.text").String())
+	require.Contains(t, gjson.GetBytes(out, "messages.0.content.2.content.1.text").String(), "[... 34 bytes")
+}
+
+func TestToolResultLimiter_OtherFormats(t *testing.T) {
+	l := newTestToolResultLimiter(8, "")
+	big := strings.Repeat("y", 32)
+
+	out := l.Apply([]byte(`{"messages":[{"role":"user","content":"`+big+`"},{"role":"tool","tool_call_id":"c1","content":"`+big+`"}]}`), RequestParamFormatChatCompletions)
+	require.Equal(t, big, gjson.GetBytes(out, "messages.0.content").String())
+	require.Contains(t, gjson.GetBytes(out, "messages.1.content").String(), "truncated by gateway")
+
+	out = l.Apply([]byte(`{"input":[{"type":"function_call_output","call_id":"c1","output":"`+big+`"}]}`), RequestParamFormatResponses)
+	require.Contains(t, gjson.GetBytes(out, "input.0.output").String(), "truncated by gateway")
+
+	out = l.Apply([]byte(`{"contents":[{"role":"user","parts":[{"functionResponse":{"name":"f","response":{"result":"`+big+`"}}}]}]}`), RequestParamFormatGemini)
+	require.Contains(t, gjson.GetBytes(out, "contents.0.parts.0.functionResponse.response.output").String(), "truncated by gateway")
+}
+
+func TestToolResultLimiter_Disabled(t *testing.T) {
+	body := []byte(`{"input":[{"type":"function_call_output","output":"` + strings.Repeat("z", 64) + `"}]}`)
+	require.Equal(t, body, NewToolResultLimiter(nil).Apply(body, RequestParamFormatResponses))
+	require.Equal(t, body, newTestToolResultLimiter(0, "").Apply(body, RequestParamFormatResponses))
+}
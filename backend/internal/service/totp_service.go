@@ -23,6 +23,8 @@ var (
 	ErrTotpTooManyAttempts = infraerrors.TooManyRequests("TOTP_TOO_MANY_ATTEMPTS", "too many verification attempts, please try again later")
 	ErrVerifyCodeRequired  = infraerrors.BadRequest("VERIFY_CODE_REQUIRED", "email verification code is required")
 	ErrPasswordRequired    = infraerrors.BadRequest("PASSWORD_REQUIRED", "password is required")
+	// ErrTotpUnavailable setup/login sessions and attempt counters live in Redis (not deployed when concurrency.backend=memory)
+	ErrTotpUnavailable = infraerrors.ServiceUnavailable("TOTP_UNAVAILABLE", "totp is unavailable without redis")
 )
 
 // TotpCache defines cache operations for TOTP service
@@ -140,6 +142,10 @@ func (s *TotpService) GetStatus(ctx context.Context, userID int64) (*TotpStatus,
 // InitiateSetup starts the TOTP setup process
 // If email verification is enabled, emailCode is required; otherwise password is required
 func (s *TotpService) InitiateSetup(ctx context.Context, userID int64, emailCode, password string) (*TotpSetupResponse, error) {
+	if s.cache == nil {
+		return nil, ErrTotpUnavailable
+	}
+
 	// Check if TOTP feature is enabled globally
 	if !s.settingService.IsTotpEnabled(ctx) {
 		return nil, ErrTotpNotEnabled
@@ -210,6 +216,10 @@ func (s *TotpService) InitiateSetup(ctx context.Context, userID int64, emailCode
 
 // CompleteSetup completes the TOTP setup by verifying the code
 func (s *TotpService) CompleteSetup(ctx context.Context, userID int64, totpCode, setupToken string) error {
+	if s.cache == nil {
+		return ErrTotpUnavailable
+	}
+
 	// Check if TOTP feature is enabled globally
 	if !s.settingService.IsTotpEnabled(ctx) {
 		return ErrTotpNotEnabled
@@ -331,6 +341,9 @@ func (s *TotpService) Disable(ctx context.Context, userID int64, emailCode, pass
 
 // VerifyCode verifies a TOTP code for a user
 func (s *TotpService) VerifyCode(ctx context.Context, userID int64, code string) error {
+	if s.cache == nil {
+		return ErrTotpUnavailable
+	}
 	slog.Debug("totp_verify_code_called",
 		"user_id", userID,
 		"code_len", len(code))
@@ -427,6 +440,10 @@ func (s *TotpService) createLoginSession(
 	email string,
 	pendingOAuthBind *PendingOAuthBindLoginSession,
 ) (string, error) {
+	if s.cache == nil {
+		return "", ErrTotpUnavailable
+	}
+
 	// Generate a random temp token
 	tempToken, err := generateRandomToken(32)
 	if err != nil {
@@ -449,11 +466,17 @@ func (s *TotpService) createLoginSession(
 
 // GetLoginSession retrieves a login session
 func (s *TotpService) GetLoginSession(ctx context.Context, tempToken string) (*TotpLoginSession, error) {
+	if s.cache == nil {
+		return nil, ErrTotpUnavailable
+	}
 	return s.cache.GetLoginSession(ctx, tempToken)
 }
 
 // DeleteLoginSession deletes a login session
 func (s *TotpService) DeleteLoginSession(ctx context.Context, tempToken string) error {
+	if s.cache == nil {
+		return nil
+	}
 	return s.cache.DeleteLoginSession(ctx, tempToken)
 }
 
@@ -0,0 +1,22 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTotpService_WithoutCacheReportsUnavailable(t *testing.T) {
+	ctx := context.Background()
+	svc := &TotpService{}
+
+	_, err := svc.CreateLoginSession(ctx, 1, "user@example.com")
+	require.ErrorIs(t, err, ErrTotpUnavailable)
+	_, err = svc.GetLoginSession(ctx, "temp-token")
+	require.ErrorIs(t, err, ErrTotpUnavailable)
+	require.ErrorIs(t, svc.VerifyCode(ctx, 1, "123456"), ErrTotpUnavailable)
+	require.ErrorIs(t, svc.CompleteSetup(ctx, 1, "123456", "setup-token"), ErrTotpUnavailable)
+}
@@ -0,0 +1,651 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// 流量回放：按使用记录的模型、流式标记与 token 规模构造等量请求，以受控速率发往指定账号，
+// 并将延迟、token 数与错误率与原始记录对比。使用记录不保存请求体，因此回放内容为合成文本。
+// 回放请求直接调用转发层，不经过调度、并发槽位与计费。
+
+const (
+	TrafficReplayStatusRunning   = "running"
+	TrafficReplayStatusCompleted = "completed"
+	TrafficReplayStatusCancelled = "cancelled"
+	TrafficReplayStatusFailed    = "failed"
+)
+
+const (
+	trafficReplayMaxSamples             = 200
+	trafficReplayDefaultSamples         = 20
+	trafficReplayDefaultRatePerMinute   = 30
+	trafficReplayMaxRatePerMinute       = 600
+	trafficReplayDefaultMaxInputTokens  = 4000
+	trafficReplayMaxInputTokensLimit    = 32000
+	trafficReplayDefaultMaxOutputTokens = 1024
+	trafficReplayMaxOutputTokensLimit   = 8192
+	trafficReplayRequestTimeout         = 5 * time.Minute
+	trafficReplayResponseCaptureLimit   = 8 * 1024
+	trafficReplayRetainedJobs           = 20
+)
+
+var (
+	ErrTrafficReplayNotFound      = infraerrors.NotFound("TRAFFIC_REPLAY_NOT_FOUND", "traffic replay job not found")
+	ErrTrafficReplayInvalidInput  = infraerrors.BadRequest("TRAFFIC_REPLAY_INVALID_INPUT", "target_account_id is required; limit must be 1-200, rate_per_minute 1-600")
+	ErrTrafficReplayNoSamples     = infraerrors.BadRequest("TRAFFIC_REPLAY_NO_SAMPLES", "no replayable usage logs matched the selection")
+	ErrTrafficReplayUnsupported   = infraerrors.BadRequest("TRAFFIC_REPLAY_UNSUPPORTED_PLATFORM", "traffic replay is not supported for this account platform")
+	ErrTrafficReplayAlreadyActive = infraerrors.Conflict("TRAFFIC_REPLAY_ALREADY_RUNNING", "a traffic replay job is already running for this account")
+)
+
+// TrafficReplayInput 回放任务参数。指定 usage_log_ids 时忽略其余筛选条件。
+type TrafficReplayInput struct {
+	TargetAccountID int64 `json:"target_account_id"`
+	// TargetModel 非空时所有回放请求统一使用该模型（用于验证不同模型名的新供应商）
+	TargetModel string `json:"target_model,omitempty"`
+
+	UsageLogIDs     []int64    `json:"usage_log_ids,omitempty"`
+	SourceAccountID int64      `json:"source_account_id,omitempty"`
+	GroupID         int64      `json:"group_id,omitempty"`
+	Model           string     `json:"model,omitempty"`
+	StartTime       *time.Time `json:"start_time,omitempty"`
+	EndTime         *time.Time `json:"end_time,omitempty"`
+	Limit           int        `json:"limit,omitempty"`
+
+	RatePerMinute   int `json:"rate_per_minute,omitempty"`
+	MaxInputTokens  int `json:"max_input_tokens,omitempty"`
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+}
+
+// TrafficReplayMeasurement 单次请求的延迟与 token 数
+type TrafficReplayMeasurement struct {
+	DurationMs   int64 `json:"duration_ms"`
+	FirstTokenMs *int  `json:"first_token_ms,omitempty"`
+	InputTokens  int   `json:"input_tokens"`
+	OutputTokens int   `json:"output_tokens"`
+}
+
+// TrafficReplaySample 单条使用记录的回放结果
+type TrafficReplaySample struct {
+	UsageLogID int64                    `json:"usage_log_id"`
+	Model      string                   `json:"model"`
+	Stream     bool                     `json:"stream"`
+	Original   TrafficReplayMeasurement `json:"original"`
+	Replay     TrafficReplayMeasurement `json:"replay"`
+	StatusCode int                      `json:"status_code"`
+	Success    bool                     `json:"success"`
+	Error      string                   `json:"error,omitempty"`
+}
+
+// TrafficReplayAggregate 一侧（原始/回放）的汇总指标
+type TrafficReplayAggregate struct {
+	AvgDurationMs     int64 `json:"avg_duration_ms"`
+	P50DurationMs     int64 `json:"p50_duration_ms"`
+	P95DurationMs     int64 `json:"p95_duration_ms"`
+	AvgFirstTokenMs   int64 `json:"avg_first_token_ms"`
+	TotalInputTokens  int64 `json:"total_input_tokens"`
+	TotalOutputTokens int64 `json:"total_output_tokens"`
+}
+
+// TrafficReplayReport 对比报告；延迟与 token 汇总仅统计回放成功的样本
+type TrafficReplayReport struct {
+	Total     int                    `json:"total"`
+	Completed int                    `json:"completed"`
+	Succeeded int                    `json:"succeeded"`
+	Failed    int                    `json:"failed"`
+	ErrorRate float64                `json:"error_rate"`
+	Original  TrafficReplayAggregate `json:"original"`
+	Replay    TrafficReplayAggregate `json:"replay"`
+}
+
+// TrafficReplayJob 回放任务（仅保存在内存中，重启后丢失）
+type TrafficReplayJob struct {
+	ID                string                `json:"id"`
+	Status            string                `json:"status"`
+	TargetAccountID   int64                 `json:"target_account_id"`
+	TargetAccountName string                `json:"target_account_name"`
+	TargetPlatform    string                `json:"target_platform"`
+	Input             TrafficReplayInput    `json:"input"`
+	Report            TrafficReplayReport   `json:"report"`
+	Samples           []TrafficReplaySample `json:"samples"`
+	Error             string                `json:"error,omitempty"`
+	RequestedBy       int64                 `json:"requested_by"`
+	CreatedAt         time.Time             `json:"created_at"`
+	FinishedAt        *time.Time            `json:"finished_at,omitempty"`
+
+	cancel context.CancelFunc
+}
+
+// TrafficReplayService 管理流量回放任务
+type TrafficReplayService struct {
+	usageLogRepo              UsageLogRepository
+	accountRepo               AccountRepository
+	gatewayService            *GatewayService
+	openAIGatewayService      *OpenAIGatewayService
+	geminiCompatService       *GeminiMessagesCompatService
+	antigravityGatewayService *AntigravityGatewayService
+
+	mu     sync.Mutex
+	jobs   []*TrafficReplayJob // 按创建时间升序，超出保留数量时淘汰最早的已结束任务
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// replayFn 单条回放执行函数，单测可替换
+	replayFn func(ctx context.Context, account *Account, model string, stream bool, body []byte) (*TrafficReplayMeasurement, int, error)
+}
+
+// NewTrafficReplayService creates a new TrafficReplayService
+func NewTrafficReplayService(
+	usageLogRepo UsageLogRepository,
+	accountRepo AccountRepository,
+	gatewayService *GatewayService,
+	openAIGatewayService *OpenAIGatewayService,
+	geminiCompatService *GeminiMessagesCompatService,
+	antigravityGatewayService *AntigravityGatewayService,
+) *TrafficReplayService {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &TrafficReplayService{
+		usageLogRepo:              usageLogRepo,
+		accountRepo:               accountRepo,
+		gatewayService:            gatewayService,
+		openAIGatewayService:      openAIGatewayService,
+		geminiCompatService:       geminiCompatService,
+		antigravityGatewayService: antigravityGatewayService,
+		ctx:                       ctx,
+		cancel:                    cancel,
+	}
+	s.replayFn = s.forwardReplay
+	return s
+}
+
+func normalizeTrafficReplayInput(in TrafficReplayInput) (TrafficReplayInput, error) {
+	in.TargetModel = strings.TrimSpace(in.TargetModel)
+	in.Model = strings.TrimSpace(in.Model)
+	if in.TargetAccountID <= 0 || in.Limit < 0 || in.RatePerMinute < 0 || in.MaxInputTokens < 0 || in.MaxOutputTokens < 0 {
+		return in, ErrTrafficReplayInvalidInput
+	}
+	if len(in.UsageLogIDs) > trafficReplayMaxSamples || in.Limit > trafficReplayMaxSamples || in.RatePerMinute > trafficReplayMaxRatePerMinute {
+		return in, ErrTrafficReplayInvalidInput
+	}
+	if in.Limit == 0 {
+		in.Limit = trafficReplayDefaultSamples
+	}
+	if in.RatePerMinute == 0 {
+		in.RatePerMinute = trafficReplayDefaultRatePerMinute
+	}
+	if in.MaxInputTokens == 0 {
+		in.MaxInputTokens = trafficReplayDefaultMaxInputTokens
+	}
+	in.MaxInputTokens = min(in.MaxInputTokens, trafficReplayMaxInputTokensLimit)
+	if in.MaxOutputTokens == 0 {
+		in.MaxOutputTokens = trafficReplayDefaultMaxOutputTokens
+	}
+	in.MaxOutputTokens = min(in.MaxOutputTokens, trafficReplayMaxOutputTokensLimit)
+	return in, nil
+}
+
+// Start 创建并在后台启动回放任务；同一目标账号同时只允许一个运行中的任务
+func (s *TrafficReplayService) Start(ctx context.Context, requestedBy int64, input TrafficReplayInput) (*TrafficReplayJob, error) {
+	input, err := normalizeTrafficReplayInput(input)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.accountRepo.GetByID(ctx, input.TargetAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !trafficReplaySupportsPlatform(account.Platform) {
+		return nil, ErrTrafficReplayUnsupported
+	}
+	logs, err := s.loadUsageLogs(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, ErrTrafficReplayNoSamples
+	}
+
+	jobCtx, cancel := context.WithCancel(s.ctx)
+	job := &TrafficReplayJob{
+		ID:                uuid.NewString(),
+		Status:            TrafficReplayStatusRunning,
+		TargetAccountID:   account.ID,
+		TargetAccountName: account.Name,
+		TargetPlatform:    account.Platform,
+		Input:             input,
+		Report:            TrafficReplayReport{Total: len(logs)},
+		Samples:           make([]TrafficReplaySample, 0, len(logs)),
+		RequestedBy:       requestedBy,
+		CreatedAt:         time.Now(),
+		cancel:            cancel,
+	}
+
+	s.mu.Lock()
+	for _, existing := range s.jobs {
+		if existing.TargetAccountID == account.ID && existing.Status == TrafficReplayStatusRunning {
+			s.mu.Unlock()
+			cancel()
+			return nil, ErrTrafficReplayAlreadyActive
+		}
+	}
+	s.jobs = append(s.jobs, job)
+	s.pruneJobsLocked()
+	snapshot := job.snapshot()
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(jobCtx, job, account, logs)
+	}()
+	return snapshot, nil
+}
+
+// List 返回保留的回放任务（新任务在前），不含逐条样本
+func (s *TrafficReplayService) List() []*TrafficReplayJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*TrafficReplayJob, 0, len(s.jobs))
+	for i := len(s.jobs) - 1; i >= 0; i-- {
+		snap := s.jobs[i].snapshot()
+		snap.Samples = nil
+		out = append(out, snap)
+	}
+	return out
+}
+
+// Get 返回回放任务详情（含逐条样本）
+func (s *TrafficReplayService) Get(id string) (*TrafficReplayJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if job.ID == id {
+			return job.snapshot(), nil
+		}
+	}
+	return nil, ErrTrafficReplayNotFound
+}
+
+// Cancel 取消运行中的回放任务；已结束的任务原样返回
+func (s *TrafficReplayService) Cancel(id string) (*TrafficReplayJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if job.ID != id {
+			continue
+		}
+		if job.Status == TrafficReplayStatusRunning && job.cancel != nil {
+			job.cancel()
+		}
+		return job.snapshot(), nil
+	}
+	return nil, ErrTrafficReplayNotFound
+}
+
+// Stop 取消全部运行中的任务并等待退出
+func (s *TrafficReplayService) Stop() {
+	if s == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *TrafficReplayService) pruneJobsLocked() {
+	for len(s.jobs) > trafficReplayRetainedJobs {
+		idx := -1
+		for i, job := range s.jobs {
+			if job.Status != TrafficReplayStatusRunning {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return
+		}
+		s.jobs = append(s.jobs[:idx], s.jobs[idx+1:]...)
+	}
+}
+
+func (j *TrafficReplayJob) snapshot() *TrafficReplayJob {
+	cp := *j
+	cp.Samples = append([]TrafficReplaySample(nil), j.Samples...)
+	cp.Input.UsageLogIDs = append([]int64(nil), j.Input.UsageLogIDs...)
+	cp.cancel = nil
+	return &cp
+}
+
+func (s *TrafficReplayService) loadUsageLogs(ctx context.Context, input TrafficReplayInput) ([]UsageLog, error) {
+	var logs []UsageLog
+	if len(input.UsageLogIDs) > 0 {
+		for _, id := range input.UsageLogIDs {
+			log, err := s.usageLogRepo.GetByID(ctx, id)
+			if err != nil {
+				if errors.Is(err, ErrUsageLogNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			logs = append(logs, *log)
+		}
+	} else {
+		filters := usagestats.UsageLogFilters{
+			AccountID: input.SourceAccountID,
+			GroupID:   input.GroupID,
+			Model:     input.Model,
+			StartTime: input.StartTime,
+			EndTime:   input.EndTime,
+		}
+		var err error
+		logs, _, err = s.usageLogRepo.ListWithFilters(ctx, pagination.PaginationParams{Page: 1, PageSize: input.Limit}, filters)
+		if err != nil {
+			return nil, fmt.Errorf("list usage logs: %w", err)
+		}
+	}
+
+	replayable := logs[:0]
+	for _, log := range logs {
+		// 图片生成记录的规模无法用文本请求复现
+		if log.ImageCount > 0 || trafficReplayModel(&log, "") == "" {
+			continue
+		}
+		replayable = append(replayable, log)
+	}
+	return replayable, nil
+}
+
+func (s *TrafficReplayService) run(ctx context.Context, job *TrafficReplayJob, account *Account, logs []UsageLog) {
+	interval := time.Minute / time.Duration(job.Input.RatePerMinute)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	status := TrafficReplayStatusCompleted
+	for i := range logs {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+			case <-ticker.C:
+			}
+		}
+		if ctx.Err() != nil {
+			status = TrafficReplayStatusCancelled
+			break
+		}
+		sample := s.replayOne(ctx, account, &logs[i], job.Input)
+
+		s.mu.Lock()
+		job.Samples = append(job.Samples, sample)
+		job.Report = buildTrafficReplayReport(job.Report.Total, job.Samples)
+		s.mu.Unlock()
+	}
+
+	finishedAt := time.Now()
+	s.mu.Lock()
+	job.Status = status
+	job.FinishedAt = &finishedAt
+	job.cancel = nil
+	s.mu.Unlock()
+	slog.Info("traffic_replay_finished",
+		"job_id", job.ID,
+		"account_id", account.ID,
+		"status", status,
+		"completed", job.Report.Completed,
+		"failed", job.Report.Failed)
+}
+
+func (s *TrafficReplayService) replayOne(ctx context.Context, account *Account, log *UsageLog, input TrafficReplayInput) TrafficReplaySample {
+	model := trafficReplayModel(log, input.TargetModel)
+	sample := TrafficReplaySample{
+		UsageLogID: log.ID,
+		Model:      model,
+		Stream:     log.Stream,
+		Original: TrafficReplayMeasurement{
+			FirstTokenMs: log.FirstTokenMs,
+			InputTokens:  log.InputTokens + log.CacheCreationTokens + log.CacheReadTokens,
+			OutputTokens: log.OutputTokens,
+		},
+	}
+	if log.DurationMs != nil {
+		sample.Original.DurationMs = int64(*log.DurationMs)
+	}
+
+	inputTokens := min(max(sample.Original.InputTokens, 1), input.MaxInputTokens)
+	outputTokens := min(max(log.OutputTokens, 16), input.MaxOutputTokens)
+	body, err := buildTrafficReplayBody(account.Platform, model, log.Stream, inputTokens, outputTokens)
+	if err != nil {
+		sample.Error = err.Error()
+		return sample
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, trafficReplayRequestTimeout)
+	defer cancel()
+	measurement, statusCode, err := s.replayFn(reqCtx, account, model, log.Stream, body)
+	sample.StatusCode = statusCode
+	if measurement != nil {
+		sample.Replay = *measurement
+	}
+	if err != nil {
+		sample.Error = sanitizeUpstreamErrorMessage(err.Error())
+		return sample
+	}
+	if statusCode >= http.StatusBadRequest {
+		sample.Error = fmt.Sprintf("upstream returned status %d", statusCode)
+		return sample
+	}
+	sample.Success = true
+	return sample
+}
+
+func trafficReplayModel(log *UsageLog, targetModel string) string {
+	if targetModel != "" {
+		return targetModel
+	}
+	if strings.TrimSpace(log.RequestedModel) != "" {
+		return log.RequestedModel
+	}
+	return log.Model
+}
+
+func trafficReplaySupportsPlatform(platform string) bool {
+	switch platform {
+	case PlatformAnthropic, PlatformOpenAI, PlatformGemini, PlatformAntigravity, PlatformCustom:
+		return true
+	default:
+		return false
+	}
+}
+
+// trafficReplayFiller 生成约 tokens 个 token 的英文填充文本（按约 4 字符/token 估算）
+func trafficReplayFiller(tokens int) string {
+	const sentence = "Replay benchmark filler text for upstream comparison. "
+	var b strings.Builder
+	target := tokens * 4
+	b.Grow(target + len(sentence))
+	b.WriteString("Reply with a short acknowledgement. ")
+	for b.Len() < target {
+		b.WriteString(sentence)
+	}
+	return b.String()
+}
+
+// buildTrafficReplayBody 按目标账号平台构造请求体：OpenAI 使用 Responses 格式，
+// custom 平台使用 Chat Completions 格式，其余平台使用 Anthropic Messages 格式。
+func buildTrafficReplayBody(platform, model string, stream bool, inputTokens, outputTokens int) ([]byte, error) {
+	prompt := trafficReplayFiller(inputTokens)
+	var payload map[string]any
+	switch platform {
+	case PlatformOpenAI:
+		payload = map[string]any{
+			"model":             model,
+			"stream":            stream,
+			"max_output_tokens": outputTokens,
+			"input": []map[string]any{
+				{"role": "user", "content": []map[string]any{{"type": "input_text", "text": prompt}}},
+			},
+		}
+	case PlatformCustom:
+		payload = map[string]any{
+			"model":      model,
+			"stream":     stream,
+			"max_tokens": outputTokens,
+			"messages":   []map[string]any{{"role": "user", "content": prompt}},
+		}
+		if stream {
+			payload["stream_options"] = map[string]any{"include_usage": true}
+		}
+	default:
+		payload = map[string]any{
+			"model":      model,
+			"stream":     stream,
+			"max_tokens": outputTokens,
+			"messages":   []map[string]any{{"role": "user", "content": prompt}},
+		}
+	}
+	return json.Marshal(payload)
+}
+
+// forwardReplay 直接调用对应平台的转发实现，响应写入丢弃型 writer
+func (s *TrafficReplayService) forwardReplay(ctx context.Context, account *Account, model string, stream bool, body []byte) (*TrafficReplayMeasurement, int, error) {
+	c, _ := newTrafficReplayContext(ctx, account.Platform)
+
+	var (
+		result *ForwardResult
+		err    error
+	)
+	switch account.Platform {
+	case PlatformOpenAI:
+		if s.openAIGatewayService == nil {
+			return nil, 0, errors.New("openai gateway service not available")
+		}
+		var openAIResult *OpenAIForwardResult
+		openAIResult, err = s.openAIGatewayService.Forward(ctx, c, account, body)
+		if openAIResult != nil {
+			result = &ForwardResult{
+				Usage: ClaudeUsage{
+					InputTokens:          openAIResult.Usage.InputTokens,
+					OutputTokens:         openAIResult.Usage.OutputTokens,
+					CacheReadInputTokens: openAIResult.Usage.CacheReadInputTokens,
+				},
+				Duration:     openAIResult.Duration,
+				FirstTokenMs: openAIResult.FirstTokenMs,
+			}
+		}
+	case PlatformCustom:
+		if s.gatewayService == nil {
+			return nil, 0, errors.New("gateway service not available")
+		}
+		result, err = s.gatewayService.ForwardCustomChatCompletions(ctx, c, account, body)
+	case PlatformGemini:
+		if s.geminiCompatService == nil {
+			return nil, 0, errors.New("gemini gateway service not available")
+		}
+		result, err = s.geminiCompatService.Forward(ctx, c, account, body)
+	case PlatformAntigravity:
+		if s.antigravityGatewayService == nil {
+			return nil, 0, errors.New("antigravity gateway service not available")
+		}
+		result, err = s.antigravityGatewayService.Forward(ctx, c, account, body, false)
+	default:
+		if s.gatewayService == nil {
+			return nil, 0, errors.New("gateway service not available")
+		}
+		parsed, parseErr := ParseGatewayRequest(body, domain.PlatformAnthropic)
+		if parseErr != nil {
+			return nil, 0, fmt.Errorf("parse replay request: %w", parseErr)
+		}
+		result, err = s.gatewayService.Forward(ctx, c, account, parsed)
+	}
+
+	statusCode := c.Writer.Status()
+	if result == nil {
+		return nil, statusCode, err
+	}
+	return &TrafficReplayMeasurement{
+		DurationMs:   result.Duration.Milliseconds(),
+		FirstTokenMs: result.FirstTokenMs,
+		InputTokens:  result.Usage.InputTokens + result.Usage.CacheCreationInputTokens + result.Usage.CacheReadInputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+	}, statusCode, err
+}
+
+func newTrafficReplayContext(ctx context.Context, platform string) (*gin.Context, *limitedResponseWriter) {
+	w := newLimitedResponseWriter(trafficReplayResponseCaptureLimit)
+	c, _ := gin.CreateTestContext(w)
+
+	path := "/v1/messages"
+	switch platform {
+	case PlatformOpenAI:
+		path = "/v1/responses"
+	case PlatformCustom:
+		path = "/v1/chat/completions"
+	}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://localhost"+path, bytes.NewReader(nil))
+	req.Header.Set("content-type", "application/json")
+	c.Request = req
+	SetOpenAIClientTransport(c, OpenAIClientTransportHTTP)
+	return c, w
+}
+
+func buildTrafficReplayReport(total int, samples []TrafficReplaySample) TrafficReplayReport {
+	report := TrafficReplayReport{Total: total, Completed: len(samples)}
+	var original, replay []TrafficReplayMeasurement
+	for _, sample := range samples {
+		if !sample.Success {
+			report.Failed++
+			continue
+		}
+		report.Succeeded++
+		original = append(original, sample.Original)
+		replay = append(replay, sample.Replay)
+	}
+	if report.Completed > 0 {
+		report.ErrorRate = float64(report.Failed) / float64(report.Completed)
+	}
+	report.Original = aggregateTrafficReplay(original)
+	report.Replay = aggregateTrafficReplay(replay)
+	return report
+}
+
+func aggregateTrafficReplay(measurements []TrafficReplayMeasurement) TrafficReplayAggregate {
+	var agg TrafficReplayAggregate
+	if len(measurements) == 0 {
+		return agg
+	}
+	durations := make([]int64, 0, len(measurements))
+	var durationSum, firstTokenSum, firstTokenCount int64
+	for _, m := range measurements {
+		durations = append(durations, m.DurationMs)
+		durationSum += m.DurationMs
+		if m.FirstTokenMs != nil {
+			firstTokenSum += int64(*m.FirstTokenMs)
+			firstTokenCount++
+		}
+		agg.TotalInputTokens += int64(m.InputTokens)
+		agg.TotalOutputTokens += int64(m.OutputTokens)
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	agg.AvgDurationMs = durationSum / int64(len(measurements))
+	agg.P50DurationMs = durations[(len(durations)-1)*50/100]
+	agg.P95DurationMs = durations[(len(durations)-1)*95/100]
+	if firstTokenCount > 0 {
+		agg.AvgFirstTokenMs = firstTokenSum / firstTokenCount
+	}
+	return agg
+}
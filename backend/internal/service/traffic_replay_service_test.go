@@ -0,0 +1,176 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/pagination"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/usagestats"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+type trafficReplayUsageRepoStub struct {
+	UsageLogRepository
+	logs        map[int64]*UsageLog
+	listed      []UsageLog
+	listFilters usagestats.UsageLogFilters
+}
+
+func (r *trafficReplayUsageRepoStub) GetByID(_ context.Context, id int64) (*UsageLog, error) {
+	if log, ok := r.logs[id]; ok {
+		return log, nil
+	}
+	return nil, ErrUsageLogNotFound
+}
+
+func (r *trafficReplayUsageRepoStub) ListWithFilters(_ context.Context, _ pagination.PaginationParams, filters usagestats.UsageLogFilters) ([]UsageLog, *pagination.PaginationResult, error) {
+	r.listFilters = filters
+	return append([]UsageLog(nil), r.listed...), &pagination.PaginationResult{}, nil
+}
+
+type trafficReplayAccountRepoStub struct {
+	AccountRepository
+	account *Account
+}
+
+func (r *trafficReplayAccountRepoStub) GetByID(_ context.Context, id int64) (*Account, error) {
+	if r.account != nil && r.account.ID == id {
+		return r.account, nil
+	}
+	return nil, ErrAccountNotFound
+}
+
+func waitTrafficReplayJob(t *testing.T, svc *TrafficReplayService, id string) *TrafficReplayJob {
+	t.Helper()
+	var job *TrafficReplayJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = svc.Get(id)
+		require.NoError(t, err)
+		return job.Status != TrafficReplayStatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestTrafficReplayService_RunProducesComparisonReport(t *testing.T) {
+	durationA, durationB := 1000, 3000
+	firstToken := 200
+	usageRepo := &trafficReplayUsageRepoStub{logs: map[int64]*UsageLog{
+		1: {ID: 1, Model: "claude-sonnet-4-5", RequestedModel: "claude-sonnet-4-5", Stream: true, InputTokens: 100, CacheReadTokens: 900, OutputTokens: 200, DurationMs: &durationA, FirstTokenMs: &firstToken},
+		2: {ID: 2, Model: "claude-sonnet-4-5", InputTokens: 50, OutputTokens: 10, DurationMs: &durationB},
+		3: {ID: 3, Model: "claude-sonnet-4-5", ImageCount: 1},
+	}}
+	accountRepo := &trafficReplayAccountRepoStub{account: &Account{ID: 7, Name: "candidate", Platform: PlatformAnthropic}}
+	svc := NewTrafficReplayService(usageRepo, accountRepo, nil, nil, nil, nil)
+	defer svc.Stop()
+
+	var calls atomic.Int32
+	svc.replayFn = func(_ context.Context, account *Account, model string, stream bool, body []byte) (*TrafficReplayMeasurement, int, error) {
+		require.Equal(t, int64(7), account.ID)
+		require.Equal(t, "target-model", model)
+		require.Equal(t, "target-model", gjson.GetBytes(body, "model").String())
+		require.Equal(t, stream, gjson.GetBytes(body, "stream").Bool())
+		if calls.Add(1) == 2 {
+			return nil, 529, errors.New("overloaded")
+		}
+		require.Equal(t, int64(200), gjson.GetBytes(body, "max_tokens").Int())
+		return &TrafficReplayMeasurement{DurationMs: 800, InputTokens: 990, OutputTokens: 180}, 200, nil
+	}
+
+	job, err := svc.Start(context.Background(), 1, TrafficReplayInput{
+		TargetAccountID: 7,
+		TargetModel:     "target-model",
+		UsageLogIDs:     []int64{1, 2, 3, 404},
+		RatePerMinute:   600,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TrafficReplayStatusRunning, job.Status)
+	require.Equal(t, 2, job.Report.Total)
+
+	job = waitTrafficReplayJob(t, svc, job.ID)
+	require.Equal(t, TrafficReplayStatusCompleted, job.Status)
+	require.Len(t, job.Samples, 2)
+	require.True(t, job.Samples[0].Success)
+	require.False(t, job.Samples[1].Success)
+	require.Equal(t, 529, job.Samples[1].StatusCode)
+
+	report := job.Report
+	require.Equal(t, 2, report.Completed)
+	require.Equal(t, 1, report.Succeeded)
+	require.Equal(t, 1, report.Failed)
+	require.InDelta(t, 0.5, report.ErrorRate, 1e-9)
+	require.Equal(t, int64(1000), report.Original.AvgDurationMs)
+	require.Equal(t, int64(200), report.Original.AvgFirstTokenMs)
+	require.Equal(t, int64(1000), report.Original.TotalInputTokens)
+	require.Equal(t, int64(800), report.Replay.AvgDurationMs)
+	require.Equal(t, int64(180), report.Replay.TotalOutputTokens)
+
+	require.Len(t, svc.List(), 1)
+	require.Nil(t, svc.List()[0].Samples)
+}
+
+func TestTrafficReplayService_StartValidation(t *testing.T) {
+	usageRepo := &trafficReplayUsageRepoStub{}
+	accountRepo := &trafficReplayAccountRepoStub{account: &Account{ID: 7, Platform: PlatformAnthropic}}
+	svc := NewTrafficReplayService(usageRepo, accountRepo, nil, nil, nil, nil)
+	defer svc.Stop()
+
+	_, err := svc.Start(context.Background(), 1, TrafficReplayInput{})
+	require.ErrorIs(t, err, ErrTrafficReplayInvalidInput)
+
+	_, err = svc.Start(context.Background(), 1, TrafficReplayInput{TargetAccountID: 7, RatePerMinute: 10000})
+	require.ErrorIs(t, err, ErrTrafficReplayInvalidInput)
+
+	// 未指定 ID 时按筛选条件查询；无可回放记录时报错
+	_, err = svc.Start(context.Background(), 1, TrafficReplayInput{TargetAccountID: 7, SourceAccountID: 3, Model: "claude-opus-4"})
+	require.ErrorIs(t, err, ErrTrafficReplayNoSamples)
+	require.Equal(t, int64(3), usageRepo.listFilters.AccountID)
+	require.Equal(t, "claude-opus-4", usageRepo.listFilters.Model)
+
+	accountRepo.account.Platform = "unknown"
+	_, err = svc.Start(context.Background(), 1, TrafficReplayInput{TargetAccountID: 7})
+	require.ErrorIs(t, err, ErrTrafficReplayUnsupported)
+}
+
+func TestTrafficReplayService_CancelAndSingleRunningJobPerAccount(t *testing.T) {
+	usageRepo := &trafficReplayUsageRepoStub{listed: []UsageLog{
+		{ID: 1, Model: "gpt-5"}, {ID: 2, Model: "gpt-5"}, {ID: 3, Model: "gpt-5"},
+	}}
+	accountRepo := &trafficReplayAccountRepoStub{account: &Account{ID: 9, Platform: PlatformOpenAI}}
+	svc := NewTrafficReplayService(usageRepo, accountRepo, nil, nil, nil, nil)
+	defer svc.Stop()
+	svc.replayFn = func(_ context.Context, _ *Account, _ string, _ bool, body []byte) (*TrafficReplayMeasurement, int, error) {
+		require.True(t, gjson.GetBytes(body, "input.0.content.0.text").Exists())
+		return &TrafficReplayMeasurement{DurationMs: 10}, 200, nil
+	}
+
+	// 1 次/分钟：首条执行后等待下一个节拍，便于在运行中取消
+	job, err := svc.Start(context.Background(), 1, TrafficReplayInput{TargetAccountID: 9, RatePerMinute: 1})
+	require.NoError(t, err)
+
+	_, err = svc.Start(context.Background(), 1, TrafficReplayInput{TargetAccountID: 9})
+	require.ErrorIs(t, err, ErrTrafficReplayAlreadyActive)
+
+	_, err = svc.Cancel(job.ID)
+	require.NoError(t, err)
+	job = waitTrafficReplayJob(t, svc, job.ID)
+	require.Equal(t, TrafficReplayStatusCancelled, job.Status)
+	require.Less(t, len(job.Samples), 3)
+
+	_, err = svc.Get("missing")
+	require.ErrorIs(t, err, ErrTrafficReplayNotFound)
+}
+
+func TestBuildTrafficReplayBody_FillerMatchesTokenBudget(t *testing.T) {
+	body, err := buildTrafficReplayBody(PlatformCustom, "qwen", true, 1000, 64)
+	require.NoError(t, err)
+	require.True(t, gjson.GetBytes(body, "stream_options.include_usage").Bool())
+	prompt := gjson.GetBytes(body, "messages.0.content").String()
+	require.InDelta(t, 1000, estimateTokensForText(prompt), 20)
+}
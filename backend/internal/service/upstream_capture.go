@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// upstreamCaptureBodyLimit 单次上游交互中请求/响应体的最大记录字节数。
+const upstreamCaptureBodyLimit = 64 * 1024
+
+// upstreamCaptureSensitiveHeaders 记录时需要脱敏的头部（小写）。
+var upstreamCaptureSensitiveHeaders = map[string]struct{}{
+	"authorization":       {},
+	"proxy-authorization": {},
+	"x-api-key":           {},
+	"x-goog-api-key":      {},
+	"cookie":              {},
+	"set-cookie":          {},
+}
+
+// upstreamCaptureSensitiveQuery 记录时需要脱敏的 query 参数。
+var upstreamCaptureSensitiveQuery = []string{"key", "access_token"}
+
+type upstreamCaptureKey struct{}
+
+// UpstreamCapture 收集一次调试请求期间经由 HTTPUpstream 发出的所有上游交互（含重试）。
+// 通过 WithUpstreamCapture 挂载到 context 上；未挂载时 HTTPUpstream 不产生任何额外开销。
+type UpstreamCapture struct {
+	mu        sync.Mutex
+	exchanges []*UpstreamExchangeRecorder
+}
+
+// UpstreamExchange 单次上游请求/响应的记录，敏感头与 query 参数已脱敏。
+type UpstreamExchange struct {
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	RequestHeaders  map[string]string `json:"request_headers"`
+	RequestBody     string            `json:"request_body,omitempty"`
+	RequestTrunc    bool              `json:"request_body_truncated,omitempty"`
+	StatusCode      int               `json:"status_code,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	ResponseBody    string            `json:"response_body,omitempty"`
+	ResponseTrunc   bool              `json:"response_body_truncated,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	DurationMs      int64             `json:"duration_ms"`
+}
+
+// UpstreamExchangeRecorder 记录进行中的单次上游交互，响应体随业务层读取逐步写入。
+type UpstreamExchangeRecorder struct {
+	mu        sync.Mutex
+	ex        UpstreamExchange
+	startedAt time.Time
+	respBuf   bytes.Buffer
+}
+
+// WithUpstreamCapture 返回挂载了 capture 的 context。
+func WithUpstreamCapture(ctx context.Context, capture *UpstreamCapture) context.Context {
+	return context.WithValue(ctx, upstreamCaptureKey{}, capture)
+}
+
+// Exchanges 返回已记录的上游交互快照。
+func (c *UpstreamCapture) Exchanges() []UpstreamExchange {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]UpstreamExchange, 0, len(c.exchanges))
+	for _, rec := range c.exchanges {
+		rec.mu.Lock()
+		ex := rec.ex
+		ex.ResponseBody = rec.respBuf.String()
+		rec.mu.Unlock()
+		out = append(out, ex)
+	}
+	return out
+}
+
+// BeginUpstreamCapture 在发送上游请求前调用；context 未挂载 capture 时返回 nil。
+// 请求体通过 req.GetBody 复制读取，不影响实际发送。
+func BeginUpstreamCapture(req *http.Request) *UpstreamExchangeRecorder {
+	if req == nil {
+		return nil
+	}
+	capture, _ := req.Context().Value(upstreamCaptureKey{}).(*UpstreamCapture)
+	if capture == nil {
+		return nil
+	}
+	rec := &UpstreamExchangeRecorder{startedAt: time.Now()}
+	ex := &rec.ex
+	ex.Method = req.Method
+	ex.RequestHeaders = redactCaptureHeaders(req.Header)
+	if req.URL != nil {
+		ex.URL = redactCaptureURL(req.URL)
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(body, upstreamCaptureBodyLimit+1))
+			_ = body.Close()
+			if len(data) > upstreamCaptureBodyLimit {
+				data = data[:upstreamCaptureBodyLimit]
+				ex.RequestTrunc = true
+			}
+			ex.RequestBody = string(data)
+		}
+	}
+	capture.mu.Lock()
+	capture.exchanges = append(capture.exchanges, rec)
+	capture.mu.Unlock()
+	return rec
+}
+
+// FinishUpstreamCapture 在收到上游响应后调用，记录状态与头部，并包装响应体以在业务层读取时同步记录。
+func FinishUpstreamCapture(rec *UpstreamExchangeRecorder, resp *http.Response, err error) {
+	if rec == nil {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	ex := &rec.ex
+	ex.DurationMs = time.Since(rec.startedAt).Milliseconds()
+	if err != nil {
+		ex.Error = err.Error()
+		return
+	}
+	if resp == nil {
+		return
+	}
+	ex.StatusCode = resp.StatusCode
+	ex.ResponseHeaders = redactCaptureHeaders(resp.Header)
+	if resp.Body != nil {
+		resp.Body = &captureBody{ReadCloser: resp.Body, rec: rec}
+	}
+}
+
+type captureBody struct {
+	io.ReadCloser
+	rec *UpstreamExchangeRecorder
+}
+
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.rec.mu.Lock()
+		if remaining := upstreamCaptureBodyLimit - b.rec.respBuf.Len(); remaining > 0 {
+			if n > remaining {
+				_, _ = b.rec.respBuf.Write(p[:remaining])
+				b.rec.ex.ResponseTrunc = true
+			} else {
+				_, _ = b.rec.respBuf.Write(p[:n])
+			}
+		} else {
+			b.rec.ex.ResponseTrunc = true
+		}
+		b.rec.mu.Unlock()
+	}
+	return n, err
+}
+
+func redactCaptureHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, vs := range h {
+		if _, sensitive := upstreamCaptureSensitiveHeaders[strings.ToLower(k)]; sensitive {
+			out[k] = "[REDACTED]"
+			continue
+		}
+		out[k] = strings.Join(vs, ", ")
+	}
+	return out
+}
+
+func redactCaptureURL(u *url.URL) string {
+	cp := *u
+	cp.User = nil
+	q := cp.Query()
+	changed := false
+	for _, name := range upstreamCaptureSensitiveQuery {
+		if q.Has(name) {
+			q.Set(name, "REDACTED")
+			changed = true
+		}
+	}
+	if changed {
+		cp.RawQuery = q.Encode()
+	}
+	return cp.String()
+}
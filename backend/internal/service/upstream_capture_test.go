@@ -0,0 +1,108 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpstreamCapture_NoCaptureInContext(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://api.anthropic.com/v1/messages", strings.NewReader("{}"))
+	require.NoError(t, err)
+
+	ex := BeginUpstreamCapture(req)
+	require.Nil(t, ex)
+	FinishUpstreamCapture(ex, &http.Response{StatusCode: http.StatusOK}, nil)
+}
+
+func TestUpstreamCapture_RecordsRedactedExchange(t *testing.T) {
+	capture := &UpstreamCapture{}
+	ctx := WithUpstreamCapture(context.Background(), capture)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		"https://generativelanguage.googleapis.com/v1beta/models/gemini:generateContent?key=secret&alt=sse",
+		bytes.NewReader([]byte(`{"contents":[]}`)))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	req.Header.Set("x-api-key", "sk-secret")
+	req.Header.Set("anthropic-beta", "tools")
+
+	ex := BeginUpstreamCapture(req)
+	require.NotNil(t, ex)
+
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Set-Cookie": {"session=1"}, "Retry-After": {"30"}},
+		Body:       io.NopCloser(strings.NewReader(`{"error":"rate_limited"}`)),
+	}
+	FinishUpstreamCapture(ex, resp, nil)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"error":"rate_limited"}`, string(body), "business layer must still see the full body")
+
+	exchanges := capture.Exchanges()
+	require.Len(t, exchanges, 1)
+	got := exchanges[0]
+	require.Equal(t, http.MethodPost, got.Method)
+	require.NotContains(t, got.URL, "secret")
+	require.Contains(t, got.URL, "alt=sse")
+	require.Equal(t, "[REDACTED]", got.RequestHeaders["Authorization"])
+	require.Equal(t, "[REDACTED]", got.RequestHeaders["X-Api-Key"])
+	require.Equal(t, "tools", got.RequestHeaders["Anthropic-Beta"])
+	require.Equal(t, `{"contents":[]}`, got.RequestBody)
+	require.Equal(t, http.StatusTooManyRequests, got.StatusCode)
+	require.Equal(t, "[REDACTED]", got.ResponseHeaders["Set-Cookie"])
+	require.Equal(t, "30", got.ResponseHeaders["Retry-After"])
+	require.Equal(t, `{"error":"rate_limited"}`, got.ResponseBody)
+	require.False(t, got.ResponseTrunc)
+}
+
+func TestUpstreamCapture_RecordsErrorAndTruncates(t *testing.T) {
+	capture := &UpstreamCapture{}
+	ctx := WithUpstreamCapture(context.Background(), capture)
+
+	failed, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/responses", nil)
+	require.NoError(t, err)
+	FinishUpstreamCapture(BeginUpstreamCapture(failed), nil, errors.New("dial tcp: timeout"))
+
+	big := strings.Repeat("a", upstreamCaptureBodyLimit+10)
+	ok, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/responses", strings.NewReader(big))
+	require.NoError(t, err)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(big))}
+	FinishUpstreamCapture(BeginUpstreamCapture(ok), resp, nil)
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+
+	exchanges := capture.Exchanges()
+	require.Len(t, exchanges, 2)
+	require.Equal(t, "dial tcp: timeout", exchanges[0].Error)
+	require.True(t, exchanges[1].RequestTrunc)
+	require.Len(t, exchanges[1].RequestBody, upstreamCaptureBodyLimit)
+	require.True(t, exchanges[1].ResponseTrunc)
+	require.Len(t, exchanges[1].ResponseBody, upstreamCaptureBodyLimit)
+}
+
+func TestParseGeminiDebugPath(t *testing.T) {
+	model, action, ok := parseGeminiDebugPath("/v1beta/models/gemini-2.5-pro:streamGenerateContent?alt=sse")
+	require.True(t, ok)
+	require.Equal(t, "gemini-2.5-pro", model)
+	require.Equal(t, "streamGenerateContent", action)
+
+	_, _, ok = parseGeminiDebugPath("/v1beta/models/gemini-2.5-pro")
+	require.False(t, ok)
+}
+
+func TestCheckDebugForwardPlatform(t *testing.T) {
+	require.NoError(t, checkDebugForwardPlatform(opsRetryTypeMessages, PlatformAnthropic))
+	require.NoError(t, checkDebugForwardPlatform(opsRetryTypeOpenAI, PlatformOpenAI))
+	require.NoError(t, checkDebugForwardPlatform(opsRetryTypeGeminiV1B, PlatformAntigravity))
+	require.Error(t, checkDebugForwardPlatform(opsRetryTypeMessages, PlatformOpenAI))
+	require.Error(t, checkDebugForwardPlatform(opsRetryTypeOpenAI, PlatformGemini))
+}
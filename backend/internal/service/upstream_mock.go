@@ -0,0 +1,447 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/google/uuid"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// mockUpstreamMinChunkInterval 流式输出的最小事件间隔，高速率时合并多个 token 为一个事件
+	mockUpstreamMinChunkInterval = 20 * time.Millisecond
+	// mockUpstreamTokenText 每个模拟 token 对应的文本
+	mockUpstreamTokenText = "mock "
+)
+
+type mockUpstreamProtocol int
+
+const (
+	mockProtocolAnthropic mockUpstreamProtocol = iota
+	mockProtocolChatCompletions
+	mockProtocolResponses
+	mockProtocolGemini
+)
+
+// MockHTTPUpstream 压测模式下的模拟上游（gateway.mock_upstream.enabled）。
+// 按请求路径识别协议（Anthropic Messages / Chat Completions / Responses / Gemini），
+// 以配置的首 token 延迟与输出速率返回合法的流式或非流式响应，并携带用量信息，
+// 使网关的并发控制、等待队列与用量记录链路可以在不消耗真实 token 的情况下被压测。
+type MockHTTPUpstream struct {
+	firstTokenLatency time.Duration
+	tokensPerSecond   int
+	outputTokens      int
+	errorRate         float64
+
+	sleepFn func(ctx context.Context, d time.Duration) error
+}
+
+// NewMockHTTPUpstream creates a mock upstream from the load test config.
+func NewMockHTTPUpstream(cfg config.GatewayMockUpstreamConfig) *MockHTTPUpstream {
+	outputTokens := cfg.OutputTokens
+	if outputTokens <= 0 {
+		outputTokens = 256
+	}
+	return &MockHTTPUpstream{
+		firstTokenLatency: time.Duration(cfg.FirstTokenLatencyMs) * time.Millisecond,
+		tokensPerSecond:   cfg.TokensPerSecond,
+		outputTokens:      outputTokens,
+		errorRate:         cfg.ErrorRate,
+		sleepFn:           sleepWithContext,
+	}
+}
+
+// Do implements HTTPUpstream.
+func (m *MockHTTPUpstream) Do(req *http.Request, _ string, _ int64, _ int) (*http.Response, error) {
+	return m.respond(req)
+}
+
+// DoWithTLS implements HTTPUpstream.
+func (m *MockHTTPUpstream) DoWithTLS(req *http.Request, _ string, _ int64, _ int, _ *tlsfingerprint.Profile) (*http.Response, error) {
+	return m.respond(req)
+}
+
+func (m *MockHTTPUpstream) respond(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	path := req.URL.Path
+	if strings.HasSuffix(path, "/count_tokens") {
+		return mockJSONResponse(req, http.StatusOK, map[string]any{"input_tokens": estimateTokensForText(string(body))}), nil
+	}
+	protocol, ok := detectMockUpstreamProtocol(path)
+	if !ok {
+		return mockJSONResponse(req, http.StatusNotFound, map[string]any{
+			"error": map[string]any{"type": "not_found_error", "message": "mock upstream does not simulate " + path},
+		}), nil
+	}
+	if m.errorRate > 0 && rand.Float64() < m.errorRate {
+		return mockJSONResponse(req, http.StatusServiceUnavailable, map[string]any{
+			"error": map[string]any{"type": "overloaded_error", "message": "mock upstream simulated error"},
+		}), nil
+	}
+
+	gen := &mockGeneration{
+		protocol:     protocol,
+		model:        gjson.GetBytes(body, "model").String(),
+		inputTokens:  estimateTokensForText(string(body)),
+		outputTokens: m.outputTokensFor(protocol, body),
+		id:           strings.ReplaceAll(uuid.NewString(), "-", ""),
+		created:      time.Now().Unix(),
+		// Antigravity（v1internal）在 Gemini 响应外层包一层 response
+		wrapResponse: strings.Contains(path, "v1internal"),
+	}
+	if gen.model == "" {
+		gen.model = mockGeminiModelFromPath(path)
+	}
+
+	stream := gjson.GetBytes(body, "stream").Bool()
+	if protocol == mockProtocolGemini {
+		stream = strings.Contains(path, ":streamGenerateContent")
+	}
+	if !stream {
+		if err := m.sleepFn(req.Context(), m.firstTokenLatency+m.generationTime(gen.outputTokens)); err != nil {
+			return nil, err
+		}
+		return mockJSONResponse(req, http.StatusOK, gen.finalPayload()), nil
+	}
+
+	pr, pw := io.Pipe()
+	go m.streamGeneration(req.Context(), pw, gen)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       pr,
+		Request:    req,
+	}, nil
+}
+
+// outputTokensFor 输出 token 数取配置值与请求中 max_tokens 类字段的较小者
+func (m *MockHTTPUpstream) outputTokensFor(protocol mockUpstreamProtocol, body []byte) int {
+	var limit int64
+	switch protocol {
+	case mockProtocolAnthropic:
+		limit = gjson.GetBytes(body, "max_tokens").Int()
+	case mockProtocolChatCompletions:
+		limit = gjson.GetBytes(body, "max_completion_tokens").Int()
+		if limit <= 0 {
+			limit = gjson.GetBytes(body, "max_tokens").Int()
+		}
+	case mockProtocolResponses:
+		limit = gjson.GetBytes(body, "max_output_tokens").Int()
+	case mockProtocolGemini:
+		limit = gjson.GetBytes(body, "generationConfig.maxOutputTokens").Int()
+		if limit <= 0 {
+			limit = gjson.GetBytes(body, "request.generationConfig.maxOutputTokens").Int()
+		}
+	}
+	if limit > 0 && limit < int64(m.outputTokens) {
+		return int(limit)
+	}
+	return m.outputTokens
+}
+
+func (m *MockHTTPUpstream) generationTime(tokens int) time.Duration {
+	if m.tokensPerSecond <= 0 {
+		return 0
+	}
+	return time.Duration(tokens) * time.Second / time.Duration(m.tokensPerSecond)
+}
+
+// streamGeneration 按首 token 延迟与输出速率写出 SSE 事件；客户端断开时中止
+func (m *MockHTTPUpstream) streamGeneration(ctx context.Context, pw *io.PipeWriter, gen *mockGeneration) {
+	write := func(events []string) error {
+		for _, event := range events {
+			if _, err := io.WriteString(pw, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := write(gen.startEvents()); err != nil {
+		_ = pw.CloseWithError(err)
+		return
+	}
+	if err := m.sleepFn(ctx, m.firstTokenLatency); err != nil {
+		_ = pw.CloseWithError(err)
+		return
+	}
+
+	chunkTokens := 1
+	var interval time.Duration
+	if m.tokensPerSecond > 0 {
+		interval = time.Second / time.Duration(m.tokensPerSecond)
+		if interval < mockUpstreamMinChunkInterval {
+			chunkTokens = int(mockUpstreamMinChunkInterval / interval)
+			interval = m.generationTime(chunkTokens)
+		}
+	} else {
+		chunkTokens = gen.outputTokens
+	}
+
+	for sent := 0; sent < gen.outputTokens; sent += chunkTokens {
+		n := min(chunkTokens, gen.outputTokens-sent)
+		if sent > 0 && interval > 0 {
+			if err := m.sleepFn(ctx, interval); err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+		if err := write([]string{gen.deltaEvent(strings.Repeat(mockUpstreamTokenText, n))}); err != nil {
+			_ = pw.CloseWithError(err)
+			return
+		}
+	}
+	if err := write(gen.endEvents()); err != nil {
+		_ = pw.CloseWithError(err)
+		return
+	}
+	_ = pw.Close()
+}
+
+func detectMockUpstreamProtocol(path string) (mockUpstreamProtocol, bool) {
+	switch {
+	case strings.Contains(path, ":generateContent"), strings.Contains(path, ":streamGenerateContent"):
+		return mockProtocolGemini, true
+	case strings.HasSuffix(path, "/chat/completions"):
+		return mockProtocolChatCompletions, true
+	case strings.HasSuffix(path, "/responses"):
+		return mockProtocolResponses, true
+	case strings.HasSuffix(path, "/messages"):
+		return mockProtocolAnthropic, true
+	}
+	return 0, false
+}
+
+// mockGeminiModelFromPath 从 /v1beta/models/{model}:generateContent 中提取模型名
+func mockGeminiModelFromPath(path string) string {
+	idx := strings.Index(path, "/models/")
+	if idx < 0 {
+		return ""
+	}
+	model := path[idx+len("/models/"):]
+	if colon := strings.Index(model, ":"); colon >= 0 {
+		model = model[:colon]
+	}
+	return model
+}
+
+func mockJSONResponse(req *http.Request, status int, payload any) *http.Response {
+	data, _ := json.Marshal(payload)
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}
+}
+
+// mockGeneration 单次模拟生成，负责按协议拼装响应体与 SSE 事件
+type mockGeneration struct {
+	protocol     mockUpstreamProtocol
+	model        string
+	inputTokens  int
+	outputTokens int
+	id           string
+	created      int64
+	wrapResponse bool
+}
+
+func (g *mockGeneration) text() string {
+	return strings.Repeat(mockUpstreamTokenText, g.outputTokens)
+}
+
+func (g *mockGeneration) finalPayload() any {
+	switch g.protocol {
+	case mockProtocolChatCompletions:
+		return map[string]any{
+			"id":      "chatcmpl-" + g.id,
+			"object":  "chat.completion",
+			"created": g.created,
+			"model":   g.model,
+			"choices": []any{map[string]any{
+				"index":         0,
+				"message":       map[string]any{"role": "assistant", "content": g.text()},
+				"finish_reason": "stop",
+			}},
+			"usage": g.chatUsage(),
+		}
+	case mockProtocolResponses:
+		return g.responsesObject("completed", g.text())
+	case mockProtocolGemini:
+		return g.geminiChunk(g.text(), true)
+	default:
+		return map[string]any{
+			"id":            "msg_" + g.id,
+			"type":          "message",
+			"role":          "assistant",
+			"model":         g.model,
+			"content":       []any{map[string]any{"type": "text", "text": g.text()}},
+			"stop_reason":   "end_turn",
+			"stop_sequence": nil,
+			"usage":         map[string]any{"input_tokens": g.inputTokens, "output_tokens": g.outputTokens},
+		}
+	}
+}
+
+func (g *mockGeneration) startEvents() []string {
+	switch g.protocol {
+	case mockProtocolAnthropic:
+		return []string{
+			mockSSEEvent("message_start", map[string]any{
+				"type": "message_start",
+				"message": map[string]any{
+					"id": "msg_" + g.id, "type": "message", "role": "assistant", "model": g.model,
+					"content": []any{}, "stop_reason": nil, "stop_sequence": nil,
+					"usage": map[string]any{"input_tokens": g.inputTokens, "output_tokens": 1},
+				},
+			}),
+			mockSSEEvent("content_block_start", map[string]any{
+				"type": "content_block_start", "index": 0,
+				"content_block": map[string]any{"type": "text", "text": ""},
+			}),
+		}
+	case mockProtocolResponses:
+		return []string{mockSSEEvent("response.created", map[string]any{
+			"type": "response.created", "response": g.responsesObject("in_progress", ""),
+		})}
+	}
+	return nil
+}
+
+func (g *mockGeneration) deltaEvent(text string) string {
+	switch g.protocol {
+	case mockProtocolChatCompletions:
+		return mockSSEEvent("", g.chatChunk(map[string]any{"role": "assistant", "content": text}, nil))
+	case mockProtocolResponses:
+		return mockSSEEvent("response.output_text.delta", map[string]any{
+			"type": "response.output_text.delta", "item_id": "msg_" + g.id,
+			"output_index": 0, "content_index": 0, "delta": text,
+		})
+	case mockProtocolGemini:
+		return mockSSEEvent("", g.geminiChunk(text, false))
+	default:
+		return mockSSEEvent("content_block_delta", map[string]any{
+			"type": "content_block_delta", "index": 0,
+			"delta": map[string]any{"type": "text_delta", "text": text},
+		})
+	}
+}
+
+func (g *mockGeneration) endEvents() []string {
+	switch g.protocol {
+	case mockProtocolChatCompletions:
+		usageChunk := g.chatChunk(nil, nil)
+		usageChunk["choices"] = []any{}
+		usageChunk["usage"] = g.chatUsage()
+		return []string{
+			mockSSEEvent("", g.chatChunk(map[string]any{}, "stop")),
+			mockSSEEvent("", usageChunk),
+			"data: [DONE]\n\n",
+		}
+	case mockProtocolResponses:
+		return []string{mockSSEEvent("response.completed", map[string]any{
+			"type": "response.completed", "response": g.responsesObject("completed", g.text()),
+		})}
+	case mockProtocolGemini:
+		return []string{mockSSEEvent("", g.geminiChunk("", true))}
+	default:
+		return []string{
+			mockSSEEvent("content_block_stop", map[string]any{"type": "content_block_stop", "index": 0}),
+			mockSSEEvent("message_delta", map[string]any{
+				"type":  "message_delta",
+				"delta": map[string]any{"stop_reason": "end_turn", "stop_sequence": nil},
+				"usage": map[string]any{"input_tokens": g.inputTokens, "output_tokens": g.outputTokens},
+			}),
+			mockSSEEvent("message_stop", map[string]any{"type": "message_stop"}),
+		}
+	}
+}
+
+func (g *mockGeneration) chatUsage() map[string]any {
+	return map[string]any{
+		"prompt_tokens":     g.inputTokens,
+		"completion_tokens": g.outputTokens,
+		"total_tokens":      g.inputTokens + g.outputTokens,
+	}
+}
+
+func (g *mockGeneration) chatChunk(delta map[string]any, finishReason any) map[string]any {
+	choice := map[string]any{"index": 0, "delta": delta, "finish_reason": finishReason}
+	return map[string]any{
+		"id":      "chatcmpl-" + g.id,
+		"object":  "chat.completion.chunk",
+		"created": g.created,
+		"model":   g.model,
+		"choices": []any{choice},
+	}
+}
+
+func (g *mockGeneration) responsesObject(status, text string) map[string]any {
+	obj := map[string]any{
+		"id":         "resp_" + g.id,
+		"object":     "response",
+		"created_at": g.created,
+		"status":     status,
+		"model":      g.model,
+		"output":     []any{},
+	}
+	if status == "completed" {
+		obj["output"] = []any{map[string]any{
+			"type": "message", "id": "msg_" + g.id, "role": "assistant", "status": "completed",
+			"content": []any{map[string]any{"type": "output_text", "text": text, "annotations": []any{}}},
+		}}
+		obj["usage"] = map[string]any{
+			"input_tokens":  g.inputTokens,
+			"output_tokens": g.outputTokens,
+			"total_tokens":  g.inputTokens + g.outputTokens,
+		}
+	}
+	return obj
+}
+
+func (g *mockGeneration) geminiChunk(text string, final bool) any {
+	candidate := map[string]any{
+		"index":   0,
+		"content": map[string]any{"role": "model", "parts": []any{map[string]any{"text": text}}},
+	}
+	chunk := map[string]any{"candidates": []any{candidate}, "modelVersion": g.model}
+	if final {
+		candidate["finishReason"] = "STOP"
+		chunk["usageMetadata"] = map[string]any{
+			"promptTokenCount":     g.inputTokens,
+			"candidatesTokenCount": g.outputTokens,
+			"totalTokenCount":      g.inputTokens + g.outputTokens,
+		}
+	}
+	if g.wrapResponse {
+		return map[string]any{"response": chunk}
+	}
+	return chunk
+}
+
+func mockSSEEvent(event string, payload any) string {
+	data, _ := json.Marshal(payload)
+	if event == "" {
+		return fmt.Sprintf("data: %s\n\n", data)
+	}
+	return fmt.Sprintf("event: %s\ndata: %s\n\n", event, data)
+}
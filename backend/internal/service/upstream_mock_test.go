@@ -0,0 +1,88 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newMockUpstreamForTest(cfg config.GatewayMockUpstreamConfig) *MockHTTPUpstream {
+	m := NewMockHTTPUpstream(cfg)
+	m.sleepFn = func(ctx context.Context, _ time.Duration) error { return ctx.Err() }
+	return m
+}
+
+func doMockUpstream(t *testing.T, m *MockHTTPUpstream, url, body string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(body)))
+	require.NoError(t, err)
+	resp, err := m.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(data)
+}
+
+func TestMockHTTPUpstream_AnthropicStreamCarriesUsage(t *testing.T) {
+	m := newMockUpstreamForTest(config.GatewayMockUpstreamConfig{OutputTokens: 100, TokensPerSecond: 1000})
+	resp, body := doMockUpstream(t, m, "https://api.anthropic.com/v1/messages?beta=true",
+		`{"model":"claude-sonnet-4-5","stream":true,"max_tokens":30,"messages":[{"role":"user","content":"hi"}]}`)
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Contains(t, body, "event: message_start")
+	require.Contains(t, body, "event: message_stop")
+
+	var text strings.Builder
+	var outputTokens int64
+	for _, line := range strings.Split(body, "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		switch gjson.Get(data, "type").String() {
+		case "content_block_delta":
+			text.WriteString(gjson.Get(data, "delta.text").String())
+		case "message_delta":
+			outputTokens = gjson.Get(data, "usage.output_tokens").Int()
+		}
+	}
+	// max_tokens 小于配置值时以请求为准
+	require.Equal(t, int64(30), outputTokens)
+	require.Equal(t, strings.Repeat(mockUpstreamTokenText, 30), text.String())
+}
+
+func TestMockHTTPUpstream_ProtocolsAndErrors(t *testing.T) {
+	m := newMockUpstreamForTest(config.GatewayMockUpstreamConfig{OutputTokens: 8})
+
+	resp, body := doMockUpstream(t, m, "https://api.openai.com/v1/chat/completions", `{"model":"gpt-5","messages":[]}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "chat.completion", gjson.Get(body, "object").String())
+	require.Equal(t, int64(8), gjson.Get(body, "usage.completion_tokens").Int())
+
+	_, body = doMockUpstream(t, m, "https://api.openai.com/v1/responses", `{"model":"gpt-5","stream":true,"input":"hi"}`)
+	require.Contains(t, body, "event: response.completed")
+
+	_, body = doMockUpstream(t, m, "https://cloudcode-pa.googleapis.com/v1internal:generateContent", `{"model":"gemini-2.5-pro","request":{}}`)
+	require.Equal(t, int64(8), gjson.Get(body, "response.usageMetadata.candidatesTokenCount").Int())
+
+	_, body = doMockUpstream(t, m, "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash:generateContent", `{}`)
+	require.Equal(t, "gemini-2.5-flash", gjson.Get(body, "modelVersion").String())
+
+	resp, _ = doMockUpstream(t, m, "https://api.openai.com/v1/embeddings", `{}`)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	failing := newMockUpstreamForTest(config.GatewayMockUpstreamConfig{OutputTokens: 8, ErrorRate: 1})
+	resp, _ = doMockUpstream(t, failing, "https://api.anthropic.com/v1/messages", `{}`)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"go.uber.org/zap"
+)
+
+const (
+	// proxyListCacheTTL 活跃代理列表缓存时长
+	proxyListCacheTTL = time.Minute
+	// proxyFallbackCacheTTL 账号备用代理配置缓存时长
+	proxyFallbackCacheTTL = time.Minute
+
+	defaultProxyUnhealthyCooldown = 60 * time.Second
+	proxyHealthCheckTimeout       = 15 * time.Second
+)
+
+// ErrProxyConcurrencyLimit 代理达到 max_concurrency 且没有可用的备用代理
+var ErrProxyConcurrencyLimit = errors.New("proxy concurrency limit reached")
+
+// proxyRuntime 单个代理的运行时状态（健康、在途请求数）
+type proxyRuntime struct {
+	proxy Proxy
+	url   string
+
+	inFlight int
+	// checkFailed 最近一次主动探测失败，直到下一次探测成功前都视为不健康
+	checkFailed bool
+	// unhealthyUntil 请求中出现代理连接错误后的冷却截止时间
+	unhealthyUntil time.Time
+	lastError      string
+}
+
+type proxyFallbackEntry struct {
+	proxyID   int64
+	fetchedAt time.Time
+}
+
+// ProxyFailoverHTTPUpstream 为经代理发出的上游请求提供代理健康检查、账号级备用代理切换和代理并发上限：
+//   - 周期性探测所有活跃代理，结果同时写入代理延迟缓存供管理端展示；
+//   - 主代理不健康或达到 max_concurrency 时改用账号 extra.fallback_proxy_id 指定的备用代理；
+//   - 请求因传输错误失败且请求体可重放时，通过备用代理重试一次。
+//
+// 并发计数为进程内计数，多实例部署时上限按实例分别生效。
+// 未配置代理或代理不在活跃列表中的请求直接透传给底层 HTTPUpstream。
+type ProxyFailoverHTTPUpstream struct {
+	inner        HTTPUpstream
+	proxyRepo    ProxyRepository
+	accountRepo  AccountRepository
+	prober       ProxyExitInfoProber
+	latencyCache ProxyLatencyCache
+
+	checkInterval     time.Duration
+	unhealthyCooldown time.Duration
+
+	mu               sync.Mutex
+	proxies          map[int64]*proxyRuntime
+	proxiesByURL     map[string]*proxyRuntime
+	proxiesFetchedAt time.Time
+	fallbacks        map[int64]proxyFallbackEntry
+
+	nowFn     func() time.Time
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+}
+
+// NewProxyFailoverHTTPUpstream wraps inner with proxy health checks and fallback.
+func NewProxyFailoverHTTPUpstream(inner HTTPUpstream, proxyRepo ProxyRepository, accountRepo AccountRepository, prober ProxyExitInfoProber, latencyCache ProxyLatencyCache, cfg *config.Config) *ProxyFailoverHTTPUpstream {
+	s := &ProxyFailoverHTTPUpstream{
+		inner:             inner,
+		proxyRepo:         proxyRepo,
+		accountRepo:       accountRepo,
+		prober:            prober,
+		latencyCache:      latencyCache,
+		unhealthyCooldown: defaultProxyUnhealthyCooldown,
+		proxies:           make(map[int64]*proxyRuntime),
+		proxiesByURL:      make(map[string]*proxyRuntime),
+		fallbacks:         make(map[int64]proxyFallbackEntry),
+		nowFn:             time.Now,
+		stopCh:            make(chan struct{}),
+	}
+	if cfg != nil {
+		pc := cfg.Gateway.ProxyHealth
+		s.checkInterval = time.Duration(pc.CheckIntervalSeconds) * time.Second
+		if pc.UnhealthyCooldownSeconds > 0 {
+			s.unhealthyCooldown = time.Duration(pc.UnhealthyCooldownSeconds) * time.Second
+		}
+	}
+	return s
+}
+
+// Do implements HTTPUpstream.
+func (s *ProxyFailoverHTTPUpstream) Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error) {
+	return s.do(req, proxyURL, accountID, func(r *http.Request, proxyURL string) (*http.Response, error) {
+		return s.inner.Do(r, proxyURL, accountID, accountConcurrency)
+	})
+}
+
+// DoWithTLS implements HTTPUpstream.
+func (s *ProxyFailoverHTTPUpstream) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile) (*http.Response, error) {
+	return s.do(req, proxyURL, accountID, func(r *http.Request, proxyURL string) (*http.Response, error) {
+		return s.inner.DoWithTLS(r, proxyURL, accountID, accountConcurrency, profile)
+	})
+}
+
+// Start 启动代理周期健康检查
+func (s *ProxyFailoverHTTPUpstream) Start() {
+	if s == nil || s.checkInterval <= 0 || s.proxyRepo == nil || s.prober == nil {
+		return
+	}
+	s.startOnce.Do(func() {
+		go s.checkLoop()
+	})
+}
+
+// Stop 停止代理健康检查
+func (s *ProxyFailoverHTTPUpstream) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
+
+func (s *ProxyFailoverHTTPUpstream) do(req *http.Request, proxyURL string, accountID int64, send func(*http.Request, string) (*http.Response, error)) (*http.Response, error) {
+	if req == nil || proxyURL == "" || accountID <= 0 {
+		return send(req, proxyURL)
+	}
+	ctx := req.Context()
+	primary := s.lookupByURL(ctx, proxyURL)
+	if primary == nil {
+		return send(req, proxyURL)
+	}
+	candidates := s.orderCandidates(primary, s.fallbackFor(ctx, accountID, primary.proxy.ID))
+	// 无法重放请求体时只能实际发送一次
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	var lastErr error
+	sent := false
+	for _, p := range candidates {
+		if !s.acquire(p) {
+			lastErr = fmt.Errorf("%w: proxy %d (max_concurrency=%d)", ErrProxyConcurrencyLimit, p.proxy.ID, p.proxy.MaxConcurrency)
+			continue
+		}
+		attempt := req
+		if sent {
+			replayed, err := replayRequest(req)
+			if err != nil {
+				s.release(p)
+				break
+			}
+			attempt = replayed
+		}
+		if p != primary {
+			logger.FromContext(ctx).Warn("upstream_proxy.fallback",
+				zap.Int64("account_id", accountID),
+				zap.Int64("from_proxy_id", primary.proxy.ID),
+				zap.Int64("to_proxy_id", p.proxy.ID),
+				zap.Error(lastErr),
+			)
+		}
+		sent = true
+		resp, err := send(attempt, p.url)
+		if err != nil {
+			s.release(p)
+			s.observeError(p, err)
+			lastErr = err
+			if !canRetry || ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if resp.Body == nil {
+			s.release(p)
+		} else {
+			resp.Body = &proxySlotReleaser{ReadCloser: resp.Body, release: func() { s.release(p) }}
+		}
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// orderCandidates 主代理不健康而备用代理健康时优先使用备用代理
+func (s *ProxyFailoverHTTPUpstream) orderCandidates(primary, fallback *proxyRuntime) []*proxyRuntime {
+	if fallback == nil {
+		return []*proxyRuntime{primary}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.nowFn()
+	if !primary.healthyAt(now) && fallback.healthyAt(now) {
+		return []*proxyRuntime{fallback, primary}
+	}
+	return []*proxyRuntime{primary, fallback}
+}
+
+func (p *proxyRuntime) healthyAt(now time.Time) bool {
+	return !p.checkFailed && !now.Before(p.unhealthyUntil)
+}
+
+func (s *ProxyFailoverHTTPUpstream) acquire(p *proxyRuntime) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.proxy.MaxConcurrency > 0 && p.inFlight >= p.proxy.MaxConcurrency {
+		return false
+	}
+	p.inFlight++
+	return true
+}
+
+func (s *ProxyFailoverHTTPUpstream) release(p *proxyRuntime) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.inFlight > 0 {
+		p.inFlight--
+	}
+}
+
+// observeError 代理自身的连接错误（而非上游错误）使代理进入冷却期
+func (s *ProxyFailoverHTTPUpstream) observeError(p *proxyRuntime, err error) {
+	if !isProxyConnectError(err) {
+		return
+	}
+	s.mu.Lock()
+	p.unhealthyUntil = s.nowFn().Add(s.unhealthyCooldown)
+	p.lastError = err.Error()
+	s.mu.Unlock()
+	logger.LegacyPrintf("service.upstream_proxy", "[UpstreamProxy] proxy %d marked unhealthy: %v", p.proxy.ID, err)
+}
+
+// lookupByURL 按代理 URL 找到对应的活跃代理，列表按 proxyListCacheTTL 刷新
+func (s *ProxyFailoverHTTPUpstream) lookupByURL(ctx context.Context, proxyURL string) *proxyRuntime {
+	s.refreshProxies(ctx, false)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.proxiesByURL[proxyURL]
+}
+
+func (s *ProxyFailoverHTTPUpstream) lookupByID(ctx context.Context, id int64) *proxyRuntime {
+	s.refreshProxies(ctx, false)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.proxies[id]
+}
+
+// refreshProxies 重新加载活跃代理列表，保留已有代理的运行时状态
+func (s *ProxyFailoverHTTPUpstream) refreshProxies(ctx context.Context, force bool) []*proxyRuntime {
+	if s.proxyRepo == nil {
+		return nil
+	}
+	now := s.nowFn()
+	s.mu.Lock()
+	fresh := now.Sub(s.proxiesFetchedAt) < proxyListCacheTTL
+	s.mu.Unlock()
+	if fresh && !force {
+		return nil
+	}
+
+	list, err := s.proxyRepo.ListActive(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proxiesFetchedAt = now
+	if err != nil {
+		logger.LegacyPrintf("service.upstream_proxy", "[UpstreamProxy] load active proxies failed: %v", err)
+		return nil
+	}
+	proxies := make(map[int64]*proxyRuntime, len(list))
+	byURL := make(map[string]*proxyRuntime, len(list))
+	out := make([]*proxyRuntime, 0, len(list))
+	for i := range list {
+		p := s.proxies[list[i].ID]
+		if p == nil {
+			p = &proxyRuntime{}
+		}
+		p.proxy = list[i]
+		p.url = list[i].URL()
+		proxies[p.proxy.ID] = p
+		byURL[p.url] = p
+		out = append(out, p)
+	}
+	s.proxies = proxies
+	s.proxiesByURL = byURL
+	return out
+}
+
+// fallbackFor 返回账号配置的备用代理（须在活跃代理列表中），未配置时返回 nil
+func (s *ProxyFailoverHTTPUpstream) fallbackFor(ctx context.Context, accountID, primaryID int64) *proxyRuntime {
+	now := s.nowFn()
+	s.mu.Lock()
+	entry, ok := s.fallbacks[accountID]
+	s.mu.Unlock()
+	if !ok || now.Sub(entry.fetchedAt) >= proxyFallbackCacheTTL {
+		entry = proxyFallbackEntry{fetchedAt: now}
+		if s.accountRepo != nil {
+			if account, err := s.accountRepo.GetByID(ctx, accountID); err == nil && account != nil {
+				entry.proxyID = account.GetFallbackProxyID()
+			}
+		}
+		s.mu.Lock()
+		s.fallbacks[accountID] = entry
+		s.mu.Unlock()
+	}
+	if entry.proxyID <= 0 || entry.proxyID == primaryID {
+		return nil
+	}
+	return s.lookupByID(ctx, entry.proxyID)
+}
+
+func (s *ProxyFailoverHTTPUpstream) checkLoop() {
+	s.checkOnce()
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.checkOnce()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// checkOnce 逐个探测活跃代理，更新健康状态并写入延迟缓存
+func (s *ProxyFailoverHTTPUpstream) checkOnce() {
+	proxies := s.refreshProxies(context.Background(), true)
+	for _, p := range proxies {
+		select {
+		case <-s.stopCh:
+			return
+		default:
+		}
+		s.check(p)
+	}
+}
+
+func (s *ProxyFailoverHTTPUpstream) check(p *proxyRuntime) {
+	ctx, cancel := context.WithTimeout(context.Background(), proxyHealthCheckTimeout)
+	defer cancel()
+	exitInfo, latencyMs, err := s.prober.ProbeProxy(ctx, p.url)
+
+	s.mu.Lock()
+	wasFailed := p.checkFailed
+	p.checkFailed = err != nil
+	if err != nil {
+		p.lastError = err.Error()
+	} else {
+		p.unhealthyUntil = time.Time{}
+		p.lastError = ""
+	}
+	s.mu.Unlock()
+
+	info := &ProxyLatencyInfo{UpdatedAt: s.nowFn()}
+	if err != nil {
+		info.Message = err.Error()
+		if !wasFailed {
+			logger.LegacyPrintf("service.upstream_proxy", "[UpstreamProxy] health check failed for proxy %d: %v", p.proxy.ID, err)
+		}
+	} else {
+		info.Success = true
+		info.LatencyMs = &latencyMs
+		info.Message = "Proxy is accessible"
+		if exitInfo != nil {
+			info.IPAddress = exitInfo.IP
+			info.Country = exitInfo.Country
+			info.CountryCode = exitInfo.CountryCode
+			info.Region = exitInfo.Region
+			info.City = exitInfo.City
+		}
+		if wasFailed {
+			logger.LegacyPrintf("service.upstream_proxy", "[UpstreamProxy] proxy %d recovered", p.proxy.ID)
+		}
+	}
+	if err := storeProxyLatency(ctx, s.latencyCache, p.proxy.ID, info); err != nil {
+		logger.LegacyPrintf("service.upstream_proxy", "[UpstreamProxy] store proxy latency failed: %v", err)
+	}
+}
+
+// isProxyConnectError 判断错误是否发生在与代理建立连接/握手阶段
+func isProxyConnectError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "proxyconnect") || strings.Contains(msg, "socks connect")
+}
+
+// replayRequest 复制请求并通过 GetBody 重放请求体
+func replayRequest(req *http.Request) (*http.Request, error) {
+	attempt := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("replay request body: %w", err)
+		}
+		attempt.Body = body
+	}
+	return attempt, nil
+}
+
+// proxySlotReleaser 在响应体关闭时释放代理并发槽位（流式响应需等待读完）
+type proxySlotReleaser struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *proxySlotReleaser) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
@@ -0,0 +1,145 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/stretchr/testify/require"
+)
+
+type activeProxyRepoStub struct {
+	proxyRepoStub
+	active []Proxy
+}
+
+func (s *activeProxyRepoStub) ListActive(ctx context.Context) ([]Proxy, error) {
+	return s.active, nil
+}
+
+type proxyUpstreamStub struct {
+	proxyURLs []string
+	bodies    []string
+	errs      map[string]error
+}
+
+func (u *proxyUpstreamStub) Do(req *http.Request, proxyURL string, _ int64, _ int) (*http.Response, error) {
+	u.proxyURLs = append(u.proxyURLs, proxyURL)
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		u.bodies = append(u.bodies, string(body))
+	}
+	if err := u.errs[proxyURL]; err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func (u *proxyUpstreamStub) DoWithTLS(req *http.Request, proxyURL string, accountID int64, concurrency int, _ *tlsfingerprint.Profile) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, concurrency)
+}
+
+const (
+	testPrimaryProxyURL  = "socks5h://primary.example.com:1080"
+	testFallbackProxyURL = "http://fallback.example.com:8080"
+)
+
+func newProxyFailoverTestUpstream(inner HTTPUpstream, primaryMaxConcurrency int) *ProxyFailoverHTTPUpstream {
+	proxies := &activeProxyRepoStub{active: []Proxy{
+		{ID: 10, Protocol: "socks5h", Host: "primary.example.com", Port: 1080, Status: StatusActive, MaxConcurrency: primaryMaxConcurrency},
+		{ID: 20, Protocol: "http", Host: "fallback.example.com", Port: 8080, Status: StatusActive},
+	}}
+	primaryID := int64(10)
+	accounts := &mockAccountRepoForGemini{accountsByID: map[int64]*Account{
+		1: {ID: 1, ProxyID: &primaryID, Extra: map[string]any{"fallback_proxy_id": float64(20)}},
+		2: {ID: 2, ProxyID: &primaryID},
+	}}
+	return NewProxyFailoverHTTPUpstream(inner, proxies, accounts, nil, nil, nil)
+}
+
+func TestAccountGetFallbackProxyID(t *testing.T) {
+	primaryID := int64(3)
+	require.Zero(t, (&Account{}).GetFallbackProxyID())
+	require.Zero(t, (&Account{ProxyID: &primaryID, Extra: map[string]any{"fallback_proxy_id": 3}}).GetFallbackProxyID())
+	require.Equal(t, int64(7), (&Account{ProxyID: &primaryID, Extra: map[string]any{"fallback_proxy_id": "7"}}).GetFallbackProxyID())
+}
+
+func TestProxyFailoverHTTPUpstream_RetryViaFallbackOnProxyError(t *testing.T) {
+	inner := &proxyUpstreamStub{errs: map[string]error{
+		testPrimaryProxyURL: errors.New("socks connect tcp primary.example.com:1080->api.example.com:443: connection refused"),
+	}}
+	u := newProxyFailoverTestUpstream(inner, 0)
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.example.com/v1/messages", bytes.NewReader([]byte(`{"a":1}`)))
+	require.NoError(t, err)
+	resp, err := u.Do(req, testPrimaryProxyURL, 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, []string{testPrimaryProxyURL, testFallbackProxyURL}, inner.proxyURLs)
+	require.Equal(t, []string{`{"a":1}`, `{"a":1}`}, inner.bodies)
+
+	// 主代理进入冷却，下次优先使用备用代理
+	inner.proxyURLs = nil
+	req, _ = http.NewRequest(http.MethodGet, "https://api.example.com/v1/models", nil)
+	resp, err = u.Do(req, testPrimaryProxyURL, 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, []string{testFallbackProxyURL}, inner.proxyURLs)
+}
+
+func TestProxyFailoverHTTPUpstream_NoFallbackReturnsError(t *testing.T) {
+	inner := &proxyUpstreamStub{errs: map[string]error{
+		testPrimaryProxyURL: errors.New("proxyconnect tcp: dial tcp: connection refused"),
+	}}
+	u := newProxyFailoverTestUpstream(inner, 0)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/v1/models", nil)
+	_, err := u.Do(req, testPrimaryProxyURL, 2, 1)
+	require.EqualError(t, err, "proxyconnect tcp: dial tcp: connection refused")
+	require.Equal(t, []string{testPrimaryProxyURL}, inner.proxyURLs)
+}
+
+func TestProxyFailoverHTTPUpstream_ConcurrencyLimit(t *testing.T) {
+	inner := &proxyUpstreamStub{}
+	u := newProxyFailoverTestUpstream(inner, 1)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/v1/models", nil)
+	held, err := u.Do(req, testPrimaryProxyURL, 2, 1)
+	require.NoError(t, err)
+
+	// 主代理槽位已满：有备用代理的账号切换，无备用代理的账号直接报错
+	resp, err := u.Do(req, testPrimaryProxyURL, 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, []string{testPrimaryProxyURL, testFallbackProxyURL}, inner.proxyURLs)
+
+	_, err = u.Do(req, testPrimaryProxyURL, 2, 1)
+	require.ErrorIs(t, err, ErrProxyConcurrencyLimit)
+
+	// 响应体关闭后释放槽位
+	require.NoError(t, held.Body.Close())
+	resp, err = u.Do(req, testPrimaryProxyURL, 2, 1)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestProxyFailoverHTTPUpstream_PassthroughUnknownProxy(t *testing.T) {
+	inner := &proxyUpstreamStub{}
+	u := newProxyFailoverTestUpstream(inner, 0)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/v1/models", nil)
+	resp, err := u.Do(req, "http://unknown.example.com:3128", 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	resp, err = u.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, []string{"http://unknown.example.com:3128", ""}, inner.proxyURLs)
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/domain"
+)
+
+// UpstreamRateLimitSnapshot 上游响应头中的限流/配额快照（见 domain.UpstreamRateLimitSnapshot）
+type UpstreamRateLimitSnapshot = domain.UpstreamRateLimitSnapshot
+
+// UpstreamRateLimitWindow 上游限流计数窗口
+type UpstreamRateLimitWindow = domain.UpstreamRateLimitWindow
+
+// UpstreamQuotaSummary 上游百分比配额（Copilot x-quota-snapshot-*）
+type UpstreamQuotaSummary = domain.UpstreamQuotaSummary
+
+const (
+	// accountExtraUpstreamRateLimitKey 账号 extra 中保存最近一次上游限流快照的键
+	accountExtraUpstreamRateLimitKey = "upstream_rate_limit"
+	// upstreamRateLimitPersistMinInterval 同一账号快照的最小写库间隔
+	upstreamRateLimitPersistMinInterval = 30 * time.Second
+	// upstreamRateLimitSnapshotMaxAge 超过该时长未刷新的快照不再参与调度
+	upstreamRateLimitSnapshotMaxAge = time.Hour
+
+	copilotQuotaSnapshotHeaderPrefix = "x-quota-snapshot-"
+)
+
+// ParseUpstreamRateLimitHeaders 解析上游响应头中的限流/配额信息；不含任何相关头时返回 nil。
+//
+// 支持：
+//   - Anthropic：anthropic-ratelimit-{requests,tokens,input-tokens,output-tokens}-{limit,remaining,reset}（reset 为 RFC3339）
+//   - OpenAI：x-ratelimit-{limit,remaining,reset}-{requests,tokens}（reset 为时长，如 "6m0s"）
+//   - Copilot：x-quota-snapshot-<配额名>，值形如 "ent=300&ov=0.0&ovPerm=false&rem=87.5&rst=2026-11-01T00:00:00Z"
+func ParseUpstreamRateLimitHeaders(headers http.Header, now time.Time) *UpstreamRateLimitSnapshot {
+	if len(headers) == 0 {
+		return nil
+	}
+	snapshot := &UpstreamRateLimitSnapshot{SampledAt: now.UTC()}
+	windows := []struct {
+		dst             **UpstreamRateLimitWindow
+		anthropicPrefix string
+		openAISuffix    string
+	}{
+		{&snapshot.Requests, "anthropic-ratelimit-requests-", "requests"},
+		{&snapshot.Tokens, "anthropic-ratelimit-tokens-", "tokens"},
+		{&snapshot.InputTokens, "anthropic-ratelimit-input-tokens-", ""},
+		{&snapshot.OutputTokens, "anthropic-ratelimit-output-tokens-", ""},
+	}
+	for _, w := range windows {
+		*w.dst = parseAnthropicRateLimitWindow(headers, w.anthropicPrefix)
+		if *w.dst == nil && w.openAISuffix != "" {
+			*w.dst = parseOpenAIRateLimitWindow(headers, w.openAISuffix, now)
+		}
+	}
+	for key, values := range headers {
+		lower := strings.ToLower(key)
+		if !strings.HasPrefix(lower, copilotQuotaSnapshotHeaderPrefix) || len(values) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(lower, copilotQuotaSnapshotHeaderPrefix)
+		if quota := parseCopilotQuotaSnapshot(values[0]); name != "" && quota != nil {
+			if snapshot.Quotas == nil {
+				snapshot.Quotas = make(map[string]*UpstreamQuotaSummary)
+			}
+			snapshot.Quotas[name] = quota
+		}
+	}
+	if snapshot.IsEmpty() {
+		return nil
+	}
+	return snapshot
+}
+
+func parseAnthropicRateLimitWindow(headers http.Header, prefix string) *UpstreamRateLimitWindow {
+	limit, limitOK := parseHeaderInt64(headers.Get(prefix + "limit"))
+	remaining, remainingOK := parseHeaderInt64(headers.Get(prefix + "remaining"))
+	if !limitOK || !remainingOK {
+		return nil
+	}
+	window := &UpstreamRateLimitWindow{Limit: limit, Remaining: remaining}
+	if reset, err := time.Parse(time.RFC3339, strings.TrimSpace(headers.Get(prefix+"reset"))); err == nil {
+		reset = reset.UTC()
+		window.ResetAt = &reset
+	}
+	return window
+}
+
+func parseOpenAIRateLimitWindow(headers http.Header, suffix string, now time.Time) *UpstreamRateLimitWindow {
+	limit, limitOK := parseHeaderInt64(headers.Get("x-ratelimit-limit-" + suffix))
+	remaining, remainingOK := parseHeaderInt64(headers.Get("x-ratelimit-remaining-" + suffix))
+	if !limitOK || !remainingOK {
+		return nil
+	}
+	window := &UpstreamRateLimitWindow{Limit: limit, Remaining: remaining}
+	if d, err := time.ParseDuration(strings.TrimSpace(headers.Get("x-ratelimit-reset-" + suffix))); err == nil && d >= 0 {
+		reset := now.Add(d).UTC()
+		window.ResetAt = &reset
+	}
+	return window
+}
+
+func parseCopilotQuotaSnapshot(raw string) *UpstreamQuotaSummary {
+	values, err := url.ParseQuery(strings.TrimSpace(raw))
+	if err != nil {
+		return nil
+	}
+	rem, err := strconv.ParseFloat(values.Get("rem"), 64)
+	if err != nil {
+		return nil
+	}
+	quota := &UpstreamQuotaSummary{RemainingPercent: rem}
+	if ent, ok := parseHeaderInt64(values.Get("ent")); ok {
+		quota.Entitlement = ent
+		// ent=-1 表示不限量
+		quota.Unlimited = ent < 0
+	}
+	if ov, err := strconv.ParseFloat(values.Get("ov"), 64); err == nil {
+		quota.Overage = ov
+	}
+	quota.OverageAllowed, _ = strconv.ParseBool(values.Get("ovPerm"))
+	if reset, err := time.Parse(time.RFC3339, values.Get("rst")); err == nil {
+		reset = reset.UTC()
+		quota.ResetAt = &reset
+	}
+	return quota
+}
+
+func parseHeaderInt64(raw string) (int64, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// GetUpstreamRateLimit 读取账号最近一次上游限流快照（extra.upstream_rate_limit），不存在时返回 nil
+func (a *Account) GetUpstreamRateLimit() *UpstreamRateLimitSnapshot {
+	if a == nil || a.Extra == nil {
+		return nil
+	}
+	raw, ok := a.Extra[accountExtraUpstreamRateLimitKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	if snapshot, ok := raw.(*UpstreamRateLimitSnapshot); ok {
+		return snapshot
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var snapshot UpstreamRateLimitSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil || snapshot.IsEmpty() {
+		return nil
+	}
+	return &snapshot
+}
+
+// UpstreamRateLimitLoadRate 将上游限流余量换算为负载率（0-100）：剩余 30% 时为 70，耗尽时为 100。
+// 无快照、快照过旧或计数已重置时 ok=false。
+func (a *Account) UpstreamRateLimitLoadRate(now time.Time) (int, bool) {
+	snapshot := a.GetUpstreamRateLimit()
+	if snapshot == nil || now.Sub(snapshot.SampledAt) > upstreamRateLimitSnapshotMaxAge {
+		return 0, false
+	}
+	remaining, ok := snapshot.RemainingFraction(now)
+	if !ok {
+		return 0, false
+	}
+	return int(math.Ceil((1 - remaining) * 100)), true
+}
+
+// withUpstreamRateLimitLoad 负载感知调度：以上游限流余量作为负载率下限，
+// 使上游即将限流的账号排在后面，余量耗尽（负载率 100）的账号不参与立即调度。
+func withUpstreamRateLimitLoad(account *Account, loadInfo *AccountLoadInfo, now time.Time) *AccountLoadInfo {
+	rate, ok := account.UpstreamRateLimitLoadRate(now)
+	if !ok || loadInfo == nil || rate <= loadInfo.LoadRate {
+		return loadInfo
+	}
+	adjusted := *loadInfo
+	adjusted.LoadRate = rate
+	return &adjusted
+}
+
+// UpstreamRateLimitRecorder 从每个上游响应头采集限流/配额快照，按账号节流后写入 extra.upstream_rate_limit，
+// 供管理后台展示与负载感知调度使用。
+type UpstreamRateLimitRecorder struct {
+	accountRepo AccountRepository
+	throttle    *accountWriteThrottle
+	nowFn       func() time.Time
+}
+
+// NewUpstreamRateLimitRecorder 创建上游限流快照采集器
+func NewUpstreamRateLimitRecorder(accountRepo AccountRepository) *UpstreamRateLimitRecorder {
+	return &UpstreamRateLimitRecorder{
+		accountRepo: accountRepo,
+		throttle:    newAccountWriteThrottle(upstreamRateLimitPersistMinInterval),
+		nowFn:       time.Now,
+	}
+}
+
+// Observe 解析响应头并异步保存快照；同一账号在节流间隔内只写一次
+func (r *UpstreamRateLimitRecorder) Observe(accountID int64, resp *http.Response) {
+	if r == nil || r.accountRepo == nil || accountID <= 0 || resp == nil {
+		return
+	}
+	now := r.nowFn()
+	snapshot := ParseUpstreamRateLimitHeaders(resp.Header, now)
+	if snapshot == nil || !r.throttle.Allow(accountID, now) {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := r.accountRepo.UpdateExtra(ctx, accountID, map[string]any{accountExtraUpstreamRateLimitKey: snapshot}); err != nil {
+			slog.Debug("upstream_rate_limit_persist_failed", "account_id", accountID, "error", err)
+		}
+	}()
+}
@@ -0,0 +1,86 @@
+//go:build unit
+
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUpstreamRateLimitHeaders(t *testing.T) {
+	now := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+
+	anthropic := http.Header{}
+	anthropic.Set("anthropic-ratelimit-requests-limit", "50")
+	anthropic.Set("anthropic-ratelimit-requests-remaining", "10")
+	anthropic.Set("anthropic-ratelimit-requests-reset", "2026-10-17T08:00:30Z")
+	anthropic.Set("anthropic-ratelimit-output-tokens-limit", "8000")
+	anthropic.Set("anthropic-ratelimit-output-tokens-remaining", "8000")
+	snapshot := ParseUpstreamRateLimitHeaders(anthropic, now)
+	require.NotNil(t, snapshot)
+	require.Equal(t, int64(10), snapshot.Requests.Remaining)
+	require.True(t, snapshot.Requests.ResetAt.Equal(now.Add(30*time.Second)))
+	require.Nil(t, snapshot.Tokens)
+	require.Equal(t, int64(8000), snapshot.OutputTokens.Limit)
+
+	openai := http.Header{}
+	openai.Set("x-ratelimit-limit-tokens", "100000")
+	openai.Set("x-ratelimit-remaining-tokens", "25000")
+	openai.Set("x-ratelimit-reset-tokens", "6m0s")
+	snapshot = ParseUpstreamRateLimitHeaders(openai, now)
+	require.NotNil(t, snapshot)
+	require.Equal(t, int64(25000), snapshot.Tokens.Remaining)
+	require.True(t, snapshot.Tokens.ResetAt.Equal(now.Add(6*time.Minute)))
+
+	copilot := http.Header{}
+	copilot.Set("X-Quota-Snapshot-Premium_interactions", "ent=300&ov=0.0&ovPerm=false&rem=12.5&rst=2026-11-01T00%3A00%3A00Z")
+	copilot.Set("X-Quota-Snapshot-Chat", "ent=-1&ov=0.0&ovPerm=false&rem=100.0")
+	snapshot = ParseUpstreamRateLimitHeaders(copilot, now)
+	require.NotNil(t, snapshot)
+	require.Equal(t, 12.5, snapshot.Quotas["premium_interactions"].RemainingPercent)
+	require.NotNil(t, snapshot.Quotas["premium_interactions"].ResetAt)
+	require.True(t, snapshot.Quotas["chat"].Unlimited)
+
+	require.Nil(t, ParseUpstreamRateLimitHeaders(http.Header{"Content-Type": []string{"application/json"}}, now))
+}
+
+func TestAccountUpstreamRateLimitLoadRate(t *testing.T) {
+	now := time.Now().UTC()
+	reset := now.Add(time.Minute).Format(time.RFC3339)
+	past := now.Add(-time.Minute).Format(time.RFC3339)
+
+	// 从 extra（JSON 解码后的 map）读取；取所有计数中最紧的余量
+	account := &Account{ID: 1, Extra: map[string]any{
+		"upstream_rate_limit": map[string]any{
+			"requests":   map[string]any{"limit": float64(100), "remaining": float64(60), "reset_at": reset},
+			"tokens":     map[string]any{"limit": float64(1000), "remaining": float64(250), "reset_at": reset},
+			"sampled_at": now.Format(time.RFC3339),
+		},
+	}}
+	rate, ok := account.UpstreamRateLimitLoadRate(now)
+	require.True(t, ok)
+	require.Equal(t, 75, rate)
+
+	load := withUpstreamRateLimitLoad(account, &AccountLoadInfo{AccountID: 1, LoadRate: 20}, now)
+	require.Equal(t, 75, load.LoadRate)
+	busy := &AccountLoadInfo{AccountID: 1, LoadRate: 90}
+	require.Same(t, busy, withUpstreamRateLimitLoad(account, busy, now))
+
+	// 计数已重置或快照过旧时不再影响调度
+	account.Extra["upstream_rate_limit"] = map[string]any{
+		"requests":   map[string]any{"limit": float64(100), "remaining": float64(0), "reset_at": past},
+		"sampled_at": now.Format(time.RFC3339),
+	}
+	_, ok = account.UpstreamRateLimitLoadRate(now)
+	require.False(t, ok)
+
+	account.Extra["upstream_rate_limit"] = map[string]any{
+		"requests":   map[string]any{"limit": float64(100), "remaining": float64(0)},
+		"sampled_at": now.Add(-2 * time.Hour).Format(time.RFC3339),
+	}
+	_, ok = account.UpstreamRateLimitLoadRate(now)
+	require.False(t, ok)
+}
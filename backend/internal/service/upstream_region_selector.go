@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+	"go.uber.org/zap"
+)
+
+const (
+	// regionAccountCacheTTL 账号区域列表缓存时长，避免每个请求都查库
+	regionAccountCacheTTL = time.Minute
+	// regionProbeIdleTTL 账号超过该时长无请求后停止探测其区域
+	regionProbeIdleTTL = 10 * time.Minute
+	// regionLatencyEWMAAlpha 延迟指数滑动平均的新样本权重
+	regionLatencyEWMAAlpha = 0.3
+
+	defaultRegionProbeTimeout      = 5 * time.Second
+	defaultRegionUnhealthyCooldown = 30 * time.Second
+)
+
+// regionAccountEntry 账号的区域列表及最近一次请求使用的连接参数（供探测复用）
+type regionAccountEntry struct {
+	regions     []string
+	proxyURL    string
+	concurrency int
+	fetchedAt   time.Time
+	lastUsedAt  time.Time
+}
+
+// regionStats 单个区域（按 base URL + 代理区分）的延迟与健康状态
+type regionStats struct {
+	latency        time.Duration
+	unhealthyUntil time.Time
+}
+
+// RegionAwareHTTPUpstream 为配置了多区域 base URL（credentials.base_urls）的账号选择上游区域：
+// 按周期探测与真实请求的延迟（EWMA）选出最低延迟的健康区域，并在连接失败或 5xx 时
+// 先在同账号的其他区域间切换，全部失败后才把错误交给网关做账号级故障切换。
+// 未配置多区域的账号直接透传给底层 HTTPUpstream。
+type RegionAwareHTTPUpstream struct {
+	inner       HTTPUpstream
+	accountRepo AccountRepository
+	cfg         *config.Config
+
+	probeInterval     time.Duration
+	probeTimeout      time.Duration
+	unhealthyCooldown time.Duration
+
+	mu       sync.Mutex
+	accounts map[int64]*regionAccountEntry
+	stats    map[string]*regionStats
+
+	// rateLimits 采集每个上游响应的限流/配额头（所有平台的上游请求都经过此处）
+	rateLimits *UpstreamRateLimitRecorder
+
+	nowFn     func() time.Time
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+}
+
+// NewRegionAwareHTTPUpstream wraps inner with multi-region selection.
+func NewRegionAwareHTTPUpstream(inner HTTPUpstream, accountRepo AccountRepository, cfg *config.Config) *RegionAwareHTTPUpstream {
+	s := &RegionAwareHTTPUpstream{
+		inner:             inner,
+		accountRepo:       accountRepo,
+		cfg:               cfg,
+		probeTimeout:      defaultRegionProbeTimeout,
+		unhealthyCooldown: defaultRegionUnhealthyCooldown,
+		accounts:          make(map[int64]*regionAccountEntry),
+		stats:             make(map[string]*regionStats),
+		rateLimits:        NewUpstreamRateLimitRecorder(accountRepo),
+		nowFn:             time.Now,
+		stopCh:            make(chan struct{}),
+	}
+	if cfg != nil {
+		rc := cfg.Gateway.RegionSelection
+		s.probeInterval = time.Duration(rc.ProbeIntervalSeconds) * time.Second
+		if rc.ProbeTimeoutSeconds > 0 {
+			s.probeTimeout = time.Duration(rc.ProbeTimeoutSeconds) * time.Second
+		}
+		if rc.UnhealthyCooldownSeconds > 0 {
+			s.unhealthyCooldown = time.Duration(rc.UnhealthyCooldownSeconds) * time.Second
+		}
+	}
+	return s
+}
+
+// Do implements HTTPUpstream.
+func (s *RegionAwareHTTPUpstream) Do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int) (*http.Response, error) {
+	resp, err := s.do(req, proxyURL, accountID, accountConcurrency, func(r *http.Request) (*http.Response, error) {
+		return s.inner.Do(r, proxyURL, accountID, accountConcurrency)
+	})
+	s.rateLimits.Observe(accountID, resp)
+	return resp, err
+}
+
+// DoWithTLS implements HTTPUpstream.
+func (s *RegionAwareHTTPUpstream) DoWithTLS(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, profile *tlsfingerprint.Profile) (*http.Response, error) {
+	resp, err := s.do(req, proxyURL, accountID, accountConcurrency, func(r *http.Request) (*http.Response, error) {
+		return s.inner.DoWithTLS(r, proxyURL, accountID, accountConcurrency, profile)
+	})
+	s.rateLimits.Observe(accountID, resp)
+	return resp, err
+}
+
+// Stop 停止后台探测
+func (s *RegionAwareHTTPUpstream) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
+
+func (s *RegionAwareHTTPUpstream) do(req *http.Request, proxyURL string, accountID int64, accountConcurrency int, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if req == nil || req.URL == nil || accountID <= 0 {
+		return send(req)
+	}
+	regions := s.regionsFor(req.Context(), accountID, proxyURL, accountConcurrency)
+	if len(regions) < 2 {
+		return send(req)
+	}
+	rawURL := req.URL.String()
+	suffix, ok := matchRegionSuffix(rawURL, regions)
+	if !ok {
+		return send(req)
+	}
+	// 无法重放请求体时只能尝试一个区域
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	ordered := s.rankRegions(regions, proxyURL)
+	var lastResp *http.Response
+	var lastErr error
+	for i, base := range ordered {
+		attempt, err := cloneRequestForRegion(req, base+suffix, i > 0)
+		if err != nil {
+			break
+		}
+		start := s.nowFn()
+		resp, err := send(attempt)
+		failed := err != nil || isRegionFailoverStatus(resp.StatusCode)
+		s.observe(base, proxyURL, s.nowFn().Sub(start), failed)
+
+		if !failed {
+			return resp, nil
+		}
+		if lastResp != nil {
+			drainAndClose(lastResp)
+		}
+		lastResp, lastErr = resp, err
+		if !canRetry || req.Context().Err() != nil || i == len(ordered)-1 {
+			break
+		}
+		logger.FromContext(req.Context()).Warn("upstream_region.failover",
+			zap.Int64("account_id", accountID),
+			zap.String("from_region", base),
+			zap.String("to_region", ordered[i+1]),
+			zap.Error(err),
+		)
+	}
+	return lastResp, lastErr
+}
+
+// regionsFor 返回账号经校验的区域列表，并记录本次请求的代理/并发参数供探测使用
+func (s *RegionAwareHTTPUpstream) regionsFor(ctx context.Context, accountID int64, proxyURL string, concurrency int) []string {
+	now := s.nowFn()
+	s.mu.Lock()
+	entry, ok := s.accounts[accountID]
+	if ok && now.Sub(entry.fetchedAt) < regionAccountCacheTTL {
+		entry.proxyURL, entry.concurrency, entry.lastUsedAt = proxyURL, concurrency, now
+		regions := entry.regions
+		s.mu.Unlock()
+		return regions
+	}
+	s.mu.Unlock()
+
+	var regions []string
+	if s.accountRepo != nil {
+		if account, err := s.accountRepo.GetByID(ctx, accountID); err == nil && account != nil {
+			regions = s.validRegions(account.GetRegionBaseURLs())
+		}
+	}
+
+	s.mu.Lock()
+	s.accounts[accountID] = &regionAccountEntry{
+		regions:     regions,
+		proxyURL:    proxyURL,
+		concurrency: concurrency,
+		fetchedAt:   now,
+		lastUsedAt:  now,
+	}
+	s.mu.Unlock()
+	if len(regions) >= 2 {
+		s.startProbing()
+	}
+	return regions
+}
+
+// validRegions 按上游 URL 安全策略过滤区域，非法的备用区域被丢弃（主区域由网关自身校验）
+func (s *RegionAwareHTTPUpstream) validRegions(regions []string) []string {
+	if len(regions) < 2 {
+		return nil
+	}
+	valid := regions[:1:1]
+	for _, raw := range regions[1:] {
+		if err := s.validateRegionURL(raw); err != nil {
+			logger.LegacyPrintf("service.upstream_region", "[UpstreamRegion] skip invalid region base_url %q: %v", raw, err)
+			continue
+		}
+		valid = append(valid, raw)
+	}
+	if len(valid) < 2 {
+		return nil
+	}
+	return valid
+}
+
+func (s *RegionAwareHTTPUpstream) validateRegionURL(raw string) error {
+	if s.cfg == nil || !s.cfg.Security.URLAllowlist.Enabled {
+		allowInsecure := s.cfg != nil && s.cfg.Security.URLAllowlist.AllowInsecureHTTP
+		_, err := urlvalidator.ValidateURLFormat(raw, allowInsecure)
+		return err
+	}
+	_, err := urlvalidator.ValidateHTTPSURL(raw, urlvalidator.ValidationOptions{
+		AllowedHosts:     s.cfg.Security.URLAllowlist.UpstreamHosts,
+		RequireAllowlist: true,
+		AllowPrivate:     s.cfg.Security.URLAllowlist.AllowPrivateHosts,
+	})
+	return err
+}
+
+// rankRegions 健康区域优先；其中已测得延迟的按延迟升序，未测得的保持配置顺序排在后面
+func (s *RegionAwareHTTPUpstream) rankRegions(regions []string, proxyURL string) []string {
+	now := s.nowFn()
+	type ranked struct {
+		base    string
+		order   int
+		healthy bool
+		latency time.Duration
+	}
+	items := make([]ranked, len(regions))
+	s.mu.Lock()
+	for i, base := range regions {
+		item := ranked{base: base, order: i, healthy: true}
+		if st, ok := s.stats[regionStatsKey(base, proxyURL)]; ok {
+			item.healthy = !now.Before(st.unhealthyUntil)
+			item.latency = st.latency
+		}
+		items[i] = item
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.healthy != b.healthy {
+			return a.healthy
+		}
+		if (a.latency > 0) != (b.latency > 0) {
+			return a.latency > 0
+		}
+		if a.latency != b.latency {
+			return a.latency < b.latency
+		}
+		return a.order < b.order
+	})
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = item.base
+	}
+	return out
+}
+
+// observe 记录一次请求/探测结果：成功时更新延迟 EWMA，失败时在冷却期内降低该区域优先级
+func (s *RegionAwareHTTPUpstream) observe(base, proxyURL string, latency time.Duration, failed bool) {
+	key := regionStatsKey(base, proxyURL)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.stats[key]
+	if !ok {
+		st = &regionStats{}
+		s.stats[key] = st
+	}
+	if failed {
+		st.unhealthyUntil = s.nowFn().Add(s.unhealthyCooldown)
+		return
+	}
+	st.unhealthyUntil = time.Time{}
+	if st.latency <= 0 {
+		st.latency = latency
+		return
+	}
+	st.latency = time.Duration(regionLatencyEWMAAlpha*float64(latency) + (1-regionLatencyEWMAAlpha)*float64(st.latency))
+}
+
+func (s *RegionAwareHTTPUpstream) startProbing() {
+	if s.probeInterval <= 0 {
+		return
+	}
+	s.startOnce.Do(func() {
+		go s.probeLoop()
+	})
+}
+
+func (s *RegionAwareHTTPUpstream) probeLoop() {
+	ticker := time.NewTicker(s.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.probeOnce()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+type regionProbeTarget struct {
+	accountID   int64
+	base        string
+	proxyURL    string
+	concurrency int
+}
+
+// probeOnce 对近期有请求的多区域账号逐个探测各区域根路径。任何非 5xx 响应都视为可达，记录其延迟。
+func (s *RegionAwareHTTPUpstream) probeOnce() {
+	now := s.nowFn()
+	var targets []regionProbeTarget
+	seen := make(map[string]struct{})
+	s.mu.Lock()
+	for id, entry := range s.accounts {
+		if now.Sub(entry.lastUsedAt) > regionProbeIdleTTL {
+			delete(s.accounts, id)
+			continue
+		}
+		for _, base := range entry.regions {
+			key := regionStatsKey(base, entry.proxyURL)
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			targets = append(targets, regionProbeTarget{accountID: id, base: base, proxyURL: entry.proxyURL, concurrency: entry.concurrency})
+		}
+	}
+	s.mu.Unlock()
+
+	for _, t := range targets {
+		s.probe(t)
+	}
+}
+
+func (s *RegionAwareHTTPUpstream) probe(t regionProbeTarget) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.base+"/", nil)
+	if err != nil {
+		return
+	}
+	start := s.nowFn()
+	resp, err := s.inner.Do(req, t.proxyURL, t.accountID, t.concurrency)
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+	if resp != nil {
+		drainAndClose(resp)
+	}
+	s.observe(t.base, t.proxyURL, s.nowFn().Sub(start), failed)
+}
+
+func regionStatsKey(base, proxyURL string) string {
+	return base + "|" + proxyURL
+}
+
+// matchRegionSuffix 找到请求 URL 所使用的区域前缀，返回其后的路径部分
+func matchRegionSuffix(rawURL string, regions []string) (string, bool) {
+	for _, base := range regions {
+		if !strings.HasPrefix(rawURL, base) {
+			continue
+		}
+		suffix := rawURL[len(base):]
+		if suffix == "" || strings.HasPrefix(suffix, "/") || strings.HasPrefix(suffix, "?") {
+			return suffix, true
+		}
+	}
+	return "", false
+}
+
+// isRegionFailoverStatus 区域级故障（网关/服务不可用）才切换区域，业务错误交给上层处理
+func isRegionFailoverStatus(status int) bool {
+	switch status {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// cloneRequestForRegion 复制请求并指向目标区域；非首次尝试时通过 GetBody 重放请求体
+func cloneRequestForRegion(req *http.Request, target string, replayBody bool) (*http.Request, error) {
+	attempt := req.Clone(req.Context())
+	u, err := req.URL.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("parse region url: %w", err)
+	}
+	if u.Host != req.URL.Host {
+		attempt.Host = ""
+	}
+	attempt.URL = u
+	if replayBody && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("replay request body: %w", err)
+		}
+		attempt.Body = body
+	}
+	return attempt, nil
+}
+
+func drainAndClose(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,113 @@
+//go:build unit
+
+package service
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/pkg/tlsfingerprint"
+	"github.com/stretchr/testify/require"
+)
+
+type regionUpstreamStub struct {
+	hosts  []string
+	bodies []string
+	status map[string]int
+	errs   map[string]error
+}
+
+func (u *regionUpstreamStub) Do(req *http.Request, _ string, _ int64, _ int) (*http.Response, error) {
+	u.hosts = append(u.hosts, req.URL.Host)
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		u.bodies = append(u.bodies, string(body))
+	}
+	if err := u.errs[req.URL.Host]; err != nil {
+		return nil, err
+	}
+	status := http.StatusOK
+	if code, ok := u.status[req.URL.Host]; ok {
+		status = code
+	}
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func (u *regionUpstreamStub) DoWithTLS(req *http.Request, proxyURL string, accountID int64, concurrency int, _ *tlsfingerprint.Profile) (*http.Response, error) {
+	return u.Do(req, proxyURL, accountID, concurrency)
+}
+
+func newRegionTestUpstream(inner HTTPUpstream) *RegionAwareHTTPUpstream {
+	repo := &mockAccountRepoForGemini{accountsByID: map[int64]*Account{
+		1: {ID: 1, Type: AccountTypeAPIKey, Credentials: map[string]any{
+			"base_url":  "https://us.example.com/",
+			"base_urls": []any{"https://eu.example.com", "https://us.example.com", "ftp://bad.example.com"},
+		}},
+		2: {ID: 2, Type: AccountTypeAPIKey, Credentials: map[string]any{"base_url": "https://single.example.com"}},
+	}}
+	return NewRegionAwareHTTPUpstream(inner, repo, nil)
+}
+
+func TestAccountGetRegionBaseURLs(t *testing.T) {
+	require.Nil(t, (&Account{Type: AccountTypeOAuth, Credentials: map[string]any{"base_url": "https://a", "base_urls": []any{"https://b"}}}).GetRegionBaseURLs())
+	require.Nil(t, (&Account{Type: AccountTypeAPIKey, Credentials: map[string]any{"base_url": "https://a"}}).GetRegionBaseURLs())
+	require.Equal(t, []string{"https://a", "https://b"},
+		(&Account{Type: AccountTypeAPIKey, Credentials: map[string]any{"base_url": "https://a/", "base_urls": []any{"https://b/", "https://a", ""}}}).GetRegionBaseURLs())
+}
+
+func TestRegionAwareHTTPUpstream_FailoverBetweenRegions(t *testing.T) {
+	inner := &regionUpstreamStub{status: map[string]int{"us.example.com": http.StatusBadGateway}}
+	u := newRegionTestUpstream(inner)
+
+	req, err := http.NewRequest(http.MethodPost, "https://us.example.com/v1/messages?beta=true", bytes.NewReader([]byte(`{"a":1}`)))
+	require.NoError(t, err)
+	resp, err := u.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"us.example.com", "eu.example.com"}, inner.hosts)
+	require.Equal(t, []string{`{"a":1}`, `{"a":1}`}, inner.bodies)
+
+	// 失败区域进入冷却，下次直接选择健康区域
+	inner.hosts = nil
+	req, _ = http.NewRequest(http.MethodGet, "https://us.example.com/v1/models", nil)
+	resp, err = u.Do(req, "", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"eu.example.com"}, inner.hosts)
+}
+
+func TestRegionAwareHTTPUpstream_AllRegionsFail(t *testing.T) {
+	inner := &regionUpstreamStub{errs: map[string]error{
+		"us.example.com": errors.New("dial timeout"),
+		"eu.example.com": errors.New("connection refused"),
+	}}
+	u := newRegionTestUpstream(inner)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://us.example.com/v1/models", nil)
+	_, err := u.Do(req, "", 1, 1)
+	require.EqualError(t, err, "connection refused")
+	require.Len(t, inner.hosts, 2)
+}
+
+func TestRegionAwareHTTPUpstream_PassthroughAndLatencyRanking(t *testing.T) {
+	inner := &regionUpstreamStub{}
+	u := newRegionTestUpstream(inner)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://single.example.com/v1/models", nil)
+	_, err := u.Do(req, "", 2, 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"single.example.com"}, inner.hosts)
+
+	u.observe("https://us.example.com", "", 300*time.Millisecond, false)
+	u.observe("https://eu.example.com", "", 80*time.Millisecond, false)
+	require.Equal(t, []string{"https://eu.example.com", "https://us.example.com"},
+		u.rankRegions([]string{"https://us.example.com", "https://eu.example.com"}, ""))
+	// 不同代理的延迟独立统计
+	require.Equal(t, []string{"https://us.example.com", "https://eu.example.com"},
+		u.rankRegions([]string{"https://us.example.com", "https://eu.example.com"}, "http://proxy:8080"))
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/httputil"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,6 +17,9 @@ var ErrUpstreamResponseBodyTooLarge = errors.New("upstream response body too lar
 // 仅在 cfg 为 nil 时作为兜底（测试或极端场景）。
 const defaultUpstreamResponseReadMaxBytes = config.DefaultUpstreamResponseReadMaxBytes
 
+// upstreamErrorBodyMaxBytes 上游错误响应体的读取上限
+const upstreamErrorBodyMaxBytes = 2 << 20
+
 func resolveUpstreamResponseReadLimit(cfg *config.Config) int64 {
 	if cfg != nil && cfg.Gateway.UpstreamResponseReadMaxBytes > 0 {
 		return cfg.Gateway.UpstreamResponseReadMaxBytes
@@ -38,7 +42,16 @@ func readUpstreamResponseBodyLimited(reader io.Reader, maxBytes int64) ([]byte,
 	if int64(len(body)) > maxBytes {
 		return nil, fmt.Errorf("%w: limit=%d", ErrUpstreamResponseBodyTooLarge, maxBytes)
 	}
-	return body, nil
+	// 声明了 Content-Encoding 的响应已在 HTTPUpstream 层解压；
+	// 部分 OpenAI 兼容上游返回压缩内容却不带 Content-Encoding，这里按魔数兜底解码。
+	return httputil.DecodeSniffedBody(body), nil
+}
+
+// readUpstreamErrorBody 读取上游错误响应体（最多 2MB），同样兜底解码未声明编码的压缩内容，
+// 保证错误消息提取与透传给客户端的都是明文。
+func readUpstreamErrorBody(reader io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(reader, upstreamErrorBodyMaxBytes))
+	return httputil.DecodeSniffedBody(body), err
 }
 
 // TooLargeWriter 在响应超限时向客户端写格式化的错误响应。
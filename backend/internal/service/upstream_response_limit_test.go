@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"testing"
 	"testing/iotest"
@@ -78,3 +79,18 @@ func TestReadUpstreamResponseBody(t *testing.T) {
 		require.False(t, called)
 	})
 }
+
+func TestReadUpstreamErrorBody_DecodesUndeclaredGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, _ = gw.Write([]byte(`{"error":{"message":"rate limited"}}`))
+	require.NoError(t, gw.Close())
+
+	body, err := readUpstreamErrorBody(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, `{"error":{"message":"rate limited"}}`, string(body))
+
+	body, err = readUpstreamResponseBodyLimited(bytes.NewReader(buf.Bytes()), 1024)
+	require.NoError(t, err)
+	require.Equal(t, `{"error":{"message":"rate limited"}}`, string(body))
+}
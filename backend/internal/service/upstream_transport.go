@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+)
+
+// ErrInvalidTLSClientCertificate 账号 mTLS 客户端证书配置非法
+var ErrInvalidTLSClientCertificate = infraerrors.BadRequest("INVALID_TLS_CLIENT_CERTIFICATE", "tls_client_cert and tls_client_key must both be set to a valid PEM certificate and matching private key")
+
+// ErrInvalidUpstreamHostOverrides 账号上游域名映射 / SNI 覆盖配置非法
+var ErrInvalidUpstreamHostOverrides = infraerrors.BadRequest("INVALID_UPSTREAM_HOST_OVERRIDES", "upstream_host_map must map host names to IP addresses and upstream_sni must be a host name")
+
+// accountClientCertCache 已解析的客户端证书（键为 PEM 内容摘要），避免每个请求重复解析私钥
+var accountClientCertCache sync.Map
+
+// UpstreamTransportOptions 账号级上游连接池参数（存储于 account.extra）。
+// 零值表示使用全局 gateway 连接池配置；任一字段非零时该账号使用独立的连接池，
+// 避免高吞吐账号与其他账号共享默认连接池。
+type UpstreamTransportOptions struct {
+	// MaxIdleConns 每主机最大空闲连接数（extra.upstream_max_idle_conns）
+	MaxIdleConns int
+	// IdleConnTimeout 空闲连接超时（extra.upstream_idle_conn_timeout_seconds）
+	IdleConnTimeout time.Duration
+	// ForceHTTP1 强制使用 HTTP/1.1，禁用 HTTP/2 协商（extra.upstream_force_http1）
+	ForceHTTP1 bool
+	// ClientCert mTLS 客户端证书（credentials.tls_client_cert / tls_client_key，PEM）
+	ClientCert *tls.Certificate
+	// HostMap 静态域名映射：直连时将域名解析为指定 IP（extra.upstream_host_map，键为小写域名）
+	HostMap map[string]string
+	// TLSServerName TLS 握手使用的 SNI 覆盖，证书按该名称校验（extra.upstream_sni）
+	TLSServerName string
+
+	clientCertID string
+}
+
+// IsZero 是否未设置任何账号级参数
+func (o UpstreamTransportOptions) IsZero() bool {
+	return o.MaxIdleConns <= 0 && o.IdleConnTimeout <= 0 && !o.ForceHTTP1 && o.ClientCert == nil &&
+		len(o.HostMap) == 0 && o.TLSServerName == ""
+}
+
+// Key 返回参数签名，用于连接池缓存键（参数变更时重建客户端）
+func (o UpstreamTransportOptions) Key() string {
+	if o.IsZero() {
+		return ""
+	}
+	key := fmt.Sprintf("idle=%d,timeout=%d,h1=%t", o.MaxIdleConns, int64(o.IdleConnTimeout/time.Second), o.ForceHTTP1)
+	if o.ClientCert != nil {
+		key += ",cert=" + o.clientCertID
+	}
+	if len(o.HostMap) > 0 {
+		hosts := make([]string, 0, len(o.HostMap))
+		for host, ip := range o.HostMap {
+			hosts = append(hosts, host+"="+ip)
+		}
+		sort.Strings(hosts)
+		key += ",hosts=" + strings.Join(hosts, ";")
+	}
+	if o.TLSServerName != "" {
+		key += ",sni=" + o.TLSServerName
+	}
+	return key
+}
+
+// GetUpstreamTransportOptions 读取账号级上游连接池参数与 mTLS 客户端证书，非法值视为未设置。
+func (a *Account) GetUpstreamTransportOptions() UpstreamTransportOptions {
+	if a == nil {
+		return UpstreamTransportOptions{}
+	}
+	var opts UpstreamTransportOptions
+	if a.Extra != nil {
+		if n := parseExtraPositiveInt(a.Extra["upstream_max_idle_conns"]); n > 0 {
+			opts.MaxIdleConns = n
+		}
+		if n := parseExtraPositiveInt(a.Extra["upstream_idle_conn_timeout_seconds"]); n > 0 {
+			opts.IdleConnTimeout = time.Duration(n) * time.Second
+		}
+		if v, ok := a.Extra["upstream_force_http1"].(bool); ok {
+			opts.ForceHTTP1 = v
+		}
+		opts.HostMap, _ = parseUpstreamHostMap(a.Extra["upstream_host_map"])
+		if sni, ok := a.Extra["upstream_sni"].(string); ok && isUpstreamHostName(strings.TrimSpace(sni)) {
+			opts.TLSServerName = strings.ToLower(strings.TrimSpace(sni))
+		}
+	}
+	if certPEM, keyPEM := a.GetCredential("tls_client_cert"), a.GetCredential("tls_client_key"); certPEM != "" && keyPEM != "" {
+		if cert, id, err := loadAccountClientCertificate(certPEM, keyPEM); err == nil {
+			opts.ClientCert = cert
+			opts.clientCertID = id
+		}
+	}
+	return opts
+}
+
+// ValidateUpstreamHostOverrides 校验账号 extra 中的静态域名映射与 SNI 覆盖
+func ValidateUpstreamHostOverrides(extra map[string]any) error {
+	if raw, ok := extra["upstream_host_map"]; ok && raw != nil {
+		if _, valid := parseUpstreamHostMap(raw); !valid {
+			return ErrInvalidUpstreamHostOverrides
+		}
+	}
+	if raw, ok := extra["upstream_sni"]; ok && raw != nil {
+		sni, isString := raw.(string)
+		if !isString || (strings.TrimSpace(sni) != "" && !isUpstreamHostName(strings.TrimSpace(sni))) {
+			return ErrInvalidUpstreamHostOverrides
+		}
+	}
+	return nil
+}
+
+// parseUpstreamHostMap 解析 {域名: IP} 映射，非法条目被跳过；valid=false 表示存在非法条目
+func parseUpstreamHostMap(raw any) (map[string]string, bool) {
+	if raw == nil {
+		return nil, true
+	}
+	entries, ok := raw.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	valid := true
+	var hostMap map[string]string
+	for host, value := range entries {
+		host = strings.ToLower(strings.TrimSpace(host))
+		ipText, _ := value.(string)
+		ip := net.ParseIP(strings.TrimSpace(ipText))
+		if !isUpstreamHostName(host) || ip == nil {
+			valid = false
+			continue
+		}
+		if hostMap == nil {
+			hostMap = make(map[string]string, len(entries))
+		}
+		hostMap[host] = ip.String()
+	}
+	return hostMap, valid
+}
+
+// isUpstreamHostName 是否为合法的域名（不含端口、路径与通配符）
+func isUpstreamHostName(host string) bool {
+	if host == "" || len(host) > 253 || net.ParseIP(host) != nil {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ValidateTLSClientCertificate 校验账号凭证中的 mTLS 客户端证书：证书与私钥需同时配置且相互匹配
+func ValidateTLSClientCertificate(credentials map[string]any) error {
+	certPEM, _ := credentials["tls_client_cert"].(string)
+	keyPEM, _ := credentials["tls_client_key"].(string)
+	certPEM, keyPEM = strings.TrimSpace(certPEM), strings.TrimSpace(keyPEM)
+	if certPEM == "" && keyPEM == "" {
+		return nil
+	}
+	if certPEM == "" || keyPEM == "" {
+		return ErrInvalidTLSClientCertificate
+	}
+	if _, _, err := loadAccountClientCertificate(certPEM, keyPEM); err != nil {
+		return ErrInvalidTLSClientCertificate
+	}
+	return nil
+}
+
+// loadAccountClientCertificate 解析 PEM 证书与私钥，返回证书及其内容摘要（用于连接池缓存键）
+func loadAccountClientCertificate(certPEM, keyPEM string) (*tls.Certificate, string, error) {
+	sum := sha256.Sum256([]byte(certPEM + "\x00" + keyPEM))
+	id := hex.EncodeToString(sum[:8])
+	if cached, ok := accountClientCertCache.Load(id); ok {
+		return cached.(*tls.Certificate), id, nil
+	}
+	cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return nil, "", err
+	}
+	accountClientCertCache.Store(id, &cert)
+	return &cert, id, nil
+}
+
+func parseExtraPositiveInt(value any) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i)
+		}
+	case string:
+		if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return i
+		}
+	}
+	return 0
+}
+
+type upstreamTransportKey struct{}
+
+// UpstreamTransportOptionsFromContext 读取请求 context 上的账号级连接池参数。
+func UpstreamTransportOptionsFromContext(ctx context.Context) UpstreamTransportOptions {
+	if ctx == nil {
+		return UpstreamTransportOptions{}
+	}
+	opts, _ := ctx.Value(upstreamTransportKey{}).(UpstreamTransportOptions)
+	return opts
+}
+
+// withAccountTransport 将账号级连接池参数挂载到上游请求；账号未配置时原样返回，无额外分配。
+func withAccountTransport(req *http.Request, account *Account) *http.Request {
+	if req == nil {
+		return nil
+	}
+	opts := account.GetUpstreamTransportOptions()
+	if opts.IsZero() {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), upstreamTransportKey{}, opts))
+}
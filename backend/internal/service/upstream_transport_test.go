@@ -0,0 +1,111 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUpstreamTransportOptions(t *testing.T) {
+	require.True(t, (&Account{}).GetUpstreamTransportOptions().IsZero())
+	require.True(t, (*Account)(nil).GetUpstreamTransportOptions().IsZero())
+
+	account := &Account{Extra: map[string]any{
+		"upstream_max_idle_conns":            json.Number("64"),
+		"upstream_idle_conn_timeout_seconds": "45",
+		"upstream_force_http1":               true,
+	}}
+	opts := account.GetUpstreamTransportOptions()
+	require.Equal(t, 64, opts.MaxIdleConns)
+	require.Equal(t, 45*time.Second, opts.IdleConnTimeout)
+	require.True(t, opts.ForceHTTP1)
+	require.Equal(t, "idle=64,timeout=45,h1=true", opts.Key())
+
+	invalid := &Account{Extra: map[string]any{
+		"upstream_max_idle_conns":            float64(-1),
+		"upstream_idle_conn_timeout_seconds": "abc",
+		"upstream_force_http1":               "yes",
+	}}
+	require.True(t, invalid.GetUpstreamTransportOptions().IsZero())
+	require.Empty(t, invalid.GetUpstreamTransportOptions().Key())
+}
+
+func TestWithAccountTransport(t *testing.T) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://api.anthropic.com/v1/messages", nil)
+	require.NoError(t, err)
+
+	require.Same(t, req, withAccountTransport(req, &Account{}), "未配置时不应复制请求")
+
+	tuned := withAccountTransport(req, &Account{Extra: map[string]any{"upstream_force_http1": true}})
+	require.NotSame(t, req, tuned)
+	require.True(t, UpstreamTransportOptionsFromContext(tuned.Context()).ForceHTTP1)
+	require.True(t, UpstreamTransportOptionsFromContext(req.Context()).IsZero())
+}
+
+func testClientCertificatePEM(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sub2api-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+func TestGetUpstreamTransportOptions_ClientCertificate(t *testing.T) {
+	certPEM, keyPEM := testClientCertificatePEM(t)
+	account := &Account{Credentials: map[string]any{"tls_client_cert": certPEM, "tls_client_key": keyPEM}}
+
+	opts := account.GetUpstreamTransportOptions()
+	require.False(t, opts.IsZero())
+	require.NotNil(t, opts.ClientCert)
+	require.Contains(t, opts.Key(), ",cert=")
+	require.Same(t, opts.ClientCert, account.GetUpstreamTransportOptions().ClientCert, "解析结果应被缓存")
+
+	require.NoError(t, ValidateTLSClientCertificate(account.Credentials))
+	require.NoError(t, ValidateTLSClientCertificate(map[string]any{"api_key": "sk-test"}))
+	require.ErrorIs(t, ValidateTLSClientCertificate(map[string]any{"tls_client_cert": certPEM}), ErrInvalidTLSClientCertificate)
+	require.ErrorIs(t, ValidateTLSClientCertificate(map[string]any{"tls_client_cert": certPEM, "tls_client_key": "garbage"}), ErrInvalidTLSClientCertificate)
+
+	broken := &Account{Credentials: map[string]any{"tls_client_cert": certPEM, "tls_client_key": "garbage"}}
+	require.True(t, broken.GetUpstreamTransportOptions().IsZero())
+}
+
+func TestGetUpstreamTransportOptions_HostOverrides(t *testing.T) {
+	account := &Account{Extra: map[string]any{
+		"upstream_host_map": map[string]any{"API.Anthropic.com": "203.0.113.10", "bad host": "1.2.3.4", "x.example.com": "nope"},
+		"upstream_sni":      "Front.Example.com",
+	}}
+	opts := account.GetUpstreamTransportOptions()
+	require.Equal(t, map[string]string{"api.anthropic.com": "203.0.113.10"}, opts.HostMap)
+	require.Equal(t, "front.example.com", opts.TLSServerName)
+	require.Equal(t, "idle=0,timeout=0,h1=false,hosts=api.anthropic.com=203.0.113.10,sni=front.example.com", opts.Key())
+
+	require.NoError(t, ValidateUpstreamHostOverrides(map[string]any{"upstream_host_map": map[string]any{"api.openai.com": "2001:db8::1"}, "upstream_sni": ""}))
+	require.ErrorIs(t, ValidateUpstreamHostOverrides(account.Extra), ErrInvalidUpstreamHostOverrides)
+	require.ErrorIs(t, ValidateUpstreamHostOverrides(map[string]any{"upstream_host_map": "api.openai.com=1.2.3.4"}), ErrInvalidUpstreamHostOverrides)
+	require.ErrorIs(t, ValidateUpstreamHostOverrides(map[string]any{"upstream_sni": "front.example.com:443"}), ErrInvalidUpstreamHostOverrides)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const usageAnomalyAlertKeyPrefix = "ops:usage_anomaly:alerted:"
+
+// APIKeyTokenWindowUsage API Key 在检测窗口与基线窗口内的 token 用量
+type APIKeyTokenWindowUsage struct {
+	APIKeyID       int64
+	KeyName        string
+	UserID         int64
+	CurrentTokens  int64
+	BaselineTokens int64
+}
+
+// UsageAnomalyRepository 用量异常检测所需的聚合查询
+type UsageAnomalyRepository interface {
+	// ListAPIKeyTokenWindows 返回 [currentStart, end) 内用量不低于 minTokens 的 API Key（按该窗口用量降序，最多 limit 个），
+	// 以及各 Key 在 [baselineStart, currentStart) 内的 token 用量
+	ListAPIKeyTokenWindows(ctx context.Context, baselineStart, currentStart, end time.Time, minTokens int64, limit int) ([]APIKeyTokenWindowUsage, error)
+}
+
+// UsageAnomaly 一次检测到的用量突增
+type UsageAnomaly struct {
+	APIKeyID       int64
+	KeyName        string
+	UserID         int64
+	CurrentTokens  int64
+	BaselineHourly float64
+	Factor         float64
+}
+
+// UsageAnomalyService 周期性比较每个 API Key 最近一小时的 token 用量与其之前 baseline_hours 的平均每小时用量，
+// 超过 spike_factor 倍时推送 usage.anomaly Webhook 并写入运维日志，用于尽早发现泄露的 Key。
+// 基线为 0 的 Key（新 Key 或长期未使用后突然大量调用）只要达到 min_tokens 即视为异常。
+type UsageAnomalyService struct {
+	repo           UsageAnomalyRepository
+	webhookService *WebhookService
+	redisClient    *redis.Client
+	cfg            config.OpsUsageAnomalyConfig
+
+	alertedMu sync.Mutex
+	alerted   map[int64]time.Time
+
+	nowFn     func() time.Time
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+}
+
+// NewUsageAnomalyService creates a UsageAnomalyService.
+func NewUsageAnomalyService(repo UsageAnomalyRepository, webhookService *WebhookService, redisClient *redis.Client, cfg *config.Config) *UsageAnomalyService {
+	s := &UsageAnomalyService{
+		repo:           repo,
+		webhookService: webhookService,
+		redisClient:    redisClient,
+		alerted:        make(map[int64]time.Time),
+		nowFn:          time.Now,
+		stopCh:         make(chan struct{}),
+	}
+	if cfg != nil {
+		s.cfg = cfg.Ops.UsageAnomaly
+		if !cfg.Ops.Enabled {
+			s.cfg.Enabled = false
+		}
+	}
+	return s
+}
+
+// Start 启动后台检测（未启用时不启动）
+func (s *UsageAnomalyService) Start() {
+	if s == nil || s.repo == nil || !s.cfg.Enabled || s.cfg.CheckIntervalMinutes <= 0 {
+		return
+	}
+	s.startOnce.Do(func() {
+		logger.LegacyPrintf("service.usage_anomaly", "[UsageAnomaly] started interval=%dm baseline=%dh factor=%.1f",
+			s.cfg.CheckIntervalMinutes, s.cfg.BaselineHours, s.cfg.SpikeFactor)
+		go s.runLoop()
+	})
+}
+
+// Stop 停止后台检测
+func (s *UsageAnomalyService) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
+
+func (s *UsageAnomalyService) runLoop() {
+	ticker := time.NewTicker(time.Duration(s.cfg.CheckIntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if _, err := s.DetectOnce(ctx); err != nil {
+				logger.LegacyPrintf("service.usage_anomaly", "[UsageAnomaly] detect failed: %v", err)
+			}
+			cancel()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// DetectOnce 执行一轮检测，返回本轮新告警的异常（冷却期内的 Key 不重复返回）
+func (s *UsageAnomalyService) DetectOnce(ctx context.Context) ([]UsageAnomaly, error) {
+	now := s.nowFn()
+	currentStart := now.Add(-time.Hour)
+	baselineHours := s.cfg.BaselineHours
+	if baselineHours <= 0 {
+		baselineHours = 24
+	}
+	baselineStart := currentStart.Add(-time.Duration(baselineHours) * time.Hour)
+
+	rows, err := s.repo.ListAPIKeyTokenWindows(ctx, baselineStart, currentStart, now, s.cfg.MinTokens, s.cfg.MaxKeys)
+	if err != nil {
+		return nil, fmt.Errorf("list api key token windows: %w", err)
+	}
+
+	var anomalies []UsageAnomaly
+	for _, row := range rows {
+		anomaly, ok := s.evaluate(row, baselineHours)
+		if !ok || !s.claimAlert(ctx, row.APIKeyID, now) {
+			continue
+		}
+		anomalies = append(anomalies, anomaly)
+		s.report(ctx, anomaly)
+	}
+	return anomalies, nil
+}
+
+func (s *UsageAnomalyService) evaluate(row APIKeyTokenWindowUsage, baselineHours int) (UsageAnomaly, bool) {
+	if row.CurrentTokens <= 0 || row.CurrentTokens < s.cfg.MinTokens {
+		return UsageAnomaly{}, false
+	}
+	baselineHourly := float64(row.BaselineTokens) / float64(baselineHours)
+	if float64(row.CurrentTokens) <= baselineHourly*s.cfg.SpikeFactor {
+		return UsageAnomaly{}, false
+	}
+	factor := 0.0
+	if baselineHourly > 0 {
+		factor = float64(row.CurrentTokens) / baselineHourly
+	}
+	return UsageAnomaly{
+		APIKeyID:       row.APIKeyID,
+		KeyName:        row.KeyName,
+		UserID:         row.UserID,
+		CurrentTokens:  row.CurrentTokens,
+		BaselineHourly: baselineHourly,
+		Factor:         factor,
+	}, true
+}
+
+// claimAlert 在冷却期内对同一 Key 只告警一次；配置了 Redis 时跨实例生效
+func (s *UsageAnomalyService) claimAlert(ctx context.Context, apiKeyID int64, now time.Time) bool {
+	cooldown := time.Duration(s.cfg.CooldownMinutes) * time.Minute
+	if cooldown <= 0 {
+		return true
+	}
+	if s.redisClient != nil {
+		ok, err := s.redisClient.SetNX(ctx, usageAnomalyAlertKeyPrefix+strconv.FormatInt(apiKeyID, 10), now.Unix(), cooldown).Result()
+		if err == nil {
+			return ok
+		}
+		logger.LegacyPrintf("service.usage_anomaly", "[UsageAnomaly] redis SetNX failed, falling back to local cooldown: %v", err)
+	}
+
+	s.alertedMu.Lock()
+	defer s.alertedMu.Unlock()
+	if last, ok := s.alerted[apiKeyID]; ok && now.Sub(last) < cooldown {
+		return false
+	}
+	for id, t := range s.alerted {
+		if now.Sub(t) >= cooldown {
+			delete(s.alerted, id)
+		}
+	}
+	s.alerted[apiKeyID] = now
+	return true
+}
+
+func (s *UsageAnomalyService) report(ctx context.Context, a UsageAnomaly) {
+	logger.FromContext(ctx).With(zap.String("component", "service.usage_anomaly")).Warn("usage_anomaly.detected",
+		zap.Int64("api_key_id", a.APIKeyID),
+		zap.String("api_key_name", a.KeyName),
+		zap.Int64("user_id", a.UserID),
+		zap.Int64("current_tokens", a.CurrentTokens),
+		zap.Float64("baseline_hourly_tokens", a.BaselineHourly),
+		zap.Float64("factor", a.Factor),
+	)
+
+	var summary string
+	if a.BaselineHourly > 0 {
+		summary = fmt.Sprintf("API key #%d %s used %d tokens in the last hour, %.1fx its %dh hourly baseline (%.0f)",
+			a.APIKeyID, a.KeyName, a.CurrentTokens, a.Factor, s.cfg.BaselineHours, a.BaselineHourly)
+	} else {
+		summary = fmt.Sprintf("API key #%d %s used %d tokens in the last hour with no usage in the previous %dh",
+			a.APIKeyID, a.KeyName, a.CurrentTokens, s.cfg.BaselineHours)
+	}
+	s.webhookService.Dispatch(WebhookEventUsageAnomaly, a.APIKeyID, summary, map[string]any{
+		"api_key_id":             a.APIKeyID,
+		"api_key_name":           a.KeyName,
+		"user_id":                a.UserID,
+		"current_tokens":         a.CurrentTokens,
+		"baseline_hourly_tokens": a.BaselineHourly,
+		"baseline_hours":         s.cfg.BaselineHours,
+		"factor":                 a.Factor,
+		"spike_factor":           s.cfg.SpikeFactor,
+	})
+}
@@ -0,0 +1,66 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type usageAnomalyRepoStub struct {
+	rows          []APIKeyTokenWindowUsage
+	baselineStart time.Time
+	currentStart  time.Time
+}
+
+func (r *usageAnomalyRepoStub) ListAPIKeyTokenWindows(_ context.Context, baselineStart, currentStart, _ time.Time, _ int64, _ int) ([]APIKeyTokenWindowUsage, error) {
+	r.baselineStart = baselineStart
+	r.currentStart = currentStart
+	return r.rows, nil
+}
+
+func TestUsageAnomalyService_DetectOnce(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Ops.Enabled = true
+	cfg.Ops.UsageAnomaly = config.OpsUsageAnomalyConfig{
+		Enabled:         true,
+		BaselineHours:   24,
+		SpikeFactor:     5,
+		MinTokens:       1000,
+		MaxKeys:         10,
+		CooldownMinutes: 60,
+	}
+	repo := &usageAnomalyRepoStub{rows: []APIKeyTokenWindowUsage{
+		{APIKeyID: 1, KeyName: "spiky", CurrentTokens: 60000, BaselineTokens: 24 * 1000},
+		{APIKeyID: 2, KeyName: "steady", CurrentTokens: 4000, BaselineTokens: 24 * 1000},
+		{APIKeyID: 3, KeyName: "fresh", CurrentTokens: 5000},
+		{APIKeyID: 4, KeyName: "tiny", CurrentTokens: 500},
+	}}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	svc := NewUsageAnomalyService(repo, nil, nil, cfg)
+	svc.nowFn = func() time.Time { return now }
+
+	anomalies, err := svc.DetectOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, anomalies, 2)
+	require.Equal(t, int64(1), anomalies[0].APIKeyID)
+	require.InDelta(t, 60.0, anomalies[0].Factor, 0.001)
+	require.Equal(t, int64(3), anomalies[1].APIKeyID)
+	require.Zero(t, anomalies[1].BaselineHourly)
+	require.Equal(t, now.Add(-time.Hour), repo.currentStart)
+	require.Equal(t, now.Add(-25*time.Hour), repo.baselineStart)
+
+	// 冷却期内不重复告警
+	anomalies, err = svc.DetectOnce(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, anomalies)
+
+	now = now.Add(61 * time.Minute)
+	anomalies, err = svc.DetectOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, anomalies, 2)
+}
@@ -18,6 +18,8 @@ type UsageBillingCommand struct {
 	APIKeyID           int64
 	RequestFingerprint string
 	RequestPayloadHash string
+	// IdempotencyKey 由 (request_id, account_id, attempt) 派生，用于跨副本的 Redis 预检；不参与指纹计算
+	IdempotencyKey string
 
 	UserID              int64
 	AccountID           int64
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+)
+
+const (
+	// usageBillingDedupProcessingTTL 记账进行中的占用时长；进程崩溃时到期自动释放，由数据库去重兜底
+	usageBillingDedupProcessingTTL = 5 * time.Minute
+	defaultUsageBillingDedupTTL    = 24 * time.Hour
+	usageBillingDedupRedisTimeout  = time.Second
+)
+
+// UsageBillingDedupCache 跨副本的记账幂等键预检（Redis 实现）
+type UsageBillingDedupCache interface {
+	// ClaimUsageBilling 占用幂等键；键已存在（已记账或其他副本正在记账）时返回 false
+	ClaimUsageBilling(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// MarkUsageBillingApplied 记账完成后延长幂等键保留时间
+	MarkUsageBillingApplied(ctx context.Context, key string, ttl time.Duration) error
+	// ReleaseUsageBilling 记账失败时释放幂等键，允许重试
+	ReleaseUsageBilling(ctx context.Context, key string) error
+}
+
+// BuildUsageIdempotencyKey 由 (request_id, account_id, attempt) 派生记账幂等键。
+// attempt 为账号切换次数：同一请求在不同账号/尝试上的记录互不影响，同一次尝试的重复记录被拦截。
+func BuildUsageIdempotencyKey(requestID string, accountID int64, attempt int) string {
+	if requestID == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(requestID + "|" + strconv.FormatInt(accountID, 10) + "|" + strconv.Itoa(attempt)))
+	return hex.EncodeToString(sum[:])
+}
+
+// usageBillingAttempt 当前请求的账号切换次数，未记录时视为首次尝试
+func usageBillingAttempt(ctx context.Context) int {
+	attempt, _ := AccountSwitchCountFromContext(ctx)
+	return attempt
+}
+
+// dedupUsageBillingRepository 在数据库去重之前增加 Redis 幂等键预检，
+// 拦截多副本重复投递与记录池重试导致的重复记账，避免每次都进入数据库事务。
+// Redis 不可用时放行，由 usage_billing_dedup 表保证最终幂等。
+type dedupUsageBillingRepository struct {
+	inner UsageBillingRepository
+	cache UsageBillingDedupCache
+	ttl   time.Duration
+}
+
+// NewDedupUsageBillingRepository 包装记账仓储；未启用或缓存为空时原样返回
+func NewDedupUsageBillingRepository(inner UsageBillingRepository, cache UsageBillingDedupCache, cfg *config.Config) UsageBillingRepository {
+	if inner == nil || cache == nil || cfg == nil || !cfg.Gateway.UsageBillingDedup.Enabled {
+		return inner
+	}
+	ttl := defaultUsageBillingDedupTTL
+	if cfg.Gateway.UsageBillingDedup.TTLSeconds > 0 {
+		ttl = time.Duration(cfg.Gateway.UsageBillingDedup.TTLSeconds) * time.Second
+	}
+	return &dedupUsageBillingRepository{inner: inner, cache: cache, ttl: ttl}
+}
+
+func (r *dedupUsageBillingRepository) Apply(ctx context.Context, cmd *UsageBillingCommand) (*UsageBillingApplyResult, error) {
+	if cmd == nil || cmd.IdempotencyKey == "" {
+		return r.inner.Apply(ctx, cmd)
+	}
+
+	claimCtx, cancel := context.WithTimeout(ctx, usageBillingDedupRedisTimeout)
+	claimed, err := r.cache.ClaimUsageBilling(claimCtx, cmd.IdempotencyKey, usageBillingDedupProcessingTTL)
+	cancel()
+	if err != nil {
+		slog.Warn("usage billing dedup pre-check failed", "request_id", cmd.RequestID, "error", err)
+		return r.inner.Apply(ctx, cmd)
+	}
+	if !claimed {
+		slog.Info("usage billing skipped by dedup pre-check", "request_id", cmd.RequestID, "api_key_id", cmd.APIKeyID, "account_id", cmd.AccountID)
+		return &UsageBillingApplyResult{}, nil
+	}
+
+	result, err := r.inner.Apply(ctx, cmd)
+
+	markCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), usageBillingDedupRedisTimeout)
+	defer cancel()
+	if err != nil {
+		_ = r.cache.ReleaseUsageBilling(markCtx, cmd.IdempotencyKey)
+		return nil, err
+	}
+	_ = r.cache.MarkUsageBillingApplied(markCtx, cmd.IdempotencyKey, r.ttl)
+	return result, nil
+}
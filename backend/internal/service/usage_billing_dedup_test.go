@@ -0,0 +1,135 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type usageBillingDedupCacheStub struct {
+	keys     map[string]string
+	claimErr error
+}
+
+func (c *usageBillingDedupCacheStub) ClaimUsageBilling(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if c.claimErr != nil {
+		return false, c.claimErr
+	}
+	if _, ok := c.keys[key]; ok {
+		return false, nil
+	}
+	c.keys[key] = "processing"
+	return true, nil
+}
+
+func (c *usageBillingDedupCacheStub) MarkUsageBillingApplied(ctx context.Context, key string, ttl time.Duration) error {
+	c.keys[key] = "applied"
+	return nil
+}
+
+func (c *usageBillingDedupCacheStub) ReleaseUsageBilling(ctx context.Context, key string) error {
+	delete(c.keys, key)
+	return nil
+}
+
+type usageBillingRepoCountingStub struct {
+	calls int
+	err   error
+}
+
+func (r *usageBillingRepoCountingStub) Apply(ctx context.Context, cmd *UsageBillingCommand) (*UsageBillingApplyResult, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &UsageBillingApplyResult{Applied: true}, nil
+}
+
+func newUsageBillingDedupTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Gateway.UsageBillingDedup.Enabled = true
+	return cfg
+}
+
+func TestBuildUsageIdempotencyKey(t *testing.T) {
+	key := BuildUsageIdempotencyKey("client:req-1", 7, 0)
+	require.Len(t, key, 64)
+	require.Equal(t, key, BuildUsageIdempotencyKey("client:req-1", 7, 0))
+	require.NotEqual(t, key, BuildUsageIdempotencyKey("client:req-1", 8, 0))
+	require.NotEqual(t, key, BuildUsageIdempotencyKey("client:req-1", 7, 1))
+	require.Empty(t, BuildUsageIdempotencyKey("", 7, 0))
+}
+
+func TestDedupUsageBillingRepository_SkipsDuplicateAcrossReplicas(t *testing.T) {
+	cache := &usageBillingDedupCacheStub{keys: map[string]string{}}
+	inner := &usageBillingRepoCountingStub{}
+	// 两个副本共享同一份 Redis
+	replicaA := NewDedupUsageBillingRepository(inner, cache, newUsageBillingDedupTestConfig())
+	replicaB := NewDedupUsageBillingRepository(inner, cache, newUsageBillingDedupTestConfig())
+
+	cmd := &UsageBillingCommand{RequestID: "client:req-1", AccountID: 7, IdempotencyKey: BuildUsageIdempotencyKey("client:req-1", 7, 0)}
+	result, err := replicaA.Apply(context.Background(), cmd)
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+
+	result, err = replicaB.Apply(context.Background(), cmd)
+	require.NoError(t, err)
+	require.False(t, result.Applied)
+	require.Equal(t, 1, inner.calls)
+	require.Equal(t, "applied", cache.keys[cmd.IdempotencyKey])
+}
+
+func TestDedupUsageBillingRepository_ReleasesKeyOnFailure(t *testing.T) {
+	cache := &usageBillingDedupCacheStub{keys: map[string]string{}}
+	inner := &usageBillingRepoCountingStub{err: errors.New("db down")}
+	repo := NewDedupUsageBillingRepository(inner, cache, newUsageBillingDedupTestConfig())
+
+	cmd := &UsageBillingCommand{RequestID: "client:req-2", IdempotencyKey: "k2"}
+	_, err := repo.Apply(context.Background(), cmd)
+	require.Error(t, err)
+	require.NotContains(t, cache.keys, "k2")
+
+	// 重试时可以再次进入数据库
+	inner.err = nil
+	result, err := repo.Apply(context.Background(), cmd)
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+	require.Equal(t, 2, inner.calls)
+}
+
+func TestDedupUsageBillingRepository_FailsOpenWhenRedisUnavailable(t *testing.T) {
+	cache := &usageBillingDedupCacheStub{keys: map[string]string{}, claimErr: errors.New("redis down")}
+	inner := &usageBillingRepoCountingStub{}
+	repo := NewDedupUsageBillingRepository(inner, cache, newUsageBillingDedupTestConfig())
+
+	result, err := repo.Apply(context.Background(), &UsageBillingCommand{RequestID: "client:req-3", IdempotencyKey: "k3"})
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+	require.Equal(t, 1, inner.calls)
+}
+
+func TestNewDedupUsageBillingRepository_DisabledReturnsInner(t *testing.T) {
+	inner := &usageBillingRepoCountingStub{}
+	require.Same(t, UsageBillingRepository(inner), NewDedupUsageBillingRepository(inner, &usageBillingDedupCacheStub{}, &config.Config{}))
+}
+
+func TestApplyUsageBilling_SetsIdempotencyKeyFromAttempt(t *testing.T) {
+	repo := &openAIRecordUsageBillingRepoStub{result: &UsageBillingApplyResult{Applied: false}}
+	ctx := WithAccountSwitchCount(context.Background(), 2, false)
+	p := &postUsageBillingParams{
+		Cost:    &CostBreakdown{},
+		User:    &User{ID: 1},
+		APIKey:  &APIKey{ID: 2},
+		Account: &Account{ID: 3},
+	}
+	_, err := applyUsageBilling(ctx, "client:req-4", &UsageLog{}, p, &billingDeps{deferredService: &DeferredService{}}, repo)
+	require.NoError(t, err)
+	require.NotNil(t, repo.lastCmd)
+	require.Equal(t, BuildUsageIdempotencyKey("client:req-4", 3, 2), repo.lastCmd.IdempotencyKey)
+}
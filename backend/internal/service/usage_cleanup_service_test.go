@@ -85,6 +85,18 @@ func (s *dashboardRepoStub) CleanupUsageLogs(ctx context.Context, cutoff time.Ti
 	return nil
 }
 
+func (s *dashboardRepoStub) CleanupUsageSummaries(ctx context.Context, cutoff time.Time) error {
+	return nil
+}
+
+func (s *dashboardRepoStub) HasUsageSummaries(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (s *dashboardRepoStub) SummarizeUsageRange(ctx context.Context, start, end time.Time) error {
+	return nil
+}
+
 func (s *dashboardRepoStub) CleanupUsageBillingDedup(ctx context.Context, cutoff time.Time) error {
 	return nil
 }
@@ -0,0 +1,550 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	UsageEventTypeCompleted     = "usage.completed"
+	usageEventSchemaVersion     = 1
+	usageEventDeadLetterTimeout = 5 * time.Second
+	// usageEventRedriveMaxBackoffFactor 死信重投退避上限（相对重投周期的倍数）
+	usageEventRedriveMaxBackoffFactor = 32
+)
+
+// 死信原因
+const (
+	UsageEventDeadLetterReasonPublishFailed = "publish_failed"
+	UsageEventDeadLetterReasonQueueFull     = "queue_full"
+	UsageEventDeadLetterReasonShutdown      = "shutdown"
+)
+
+// UsageEvent 导出到事件总线的使用记录（JSON 结构对下游稳定，字段只增不改）。
+// 投递语义为至少一次，下游按 event_id 去重。
+type UsageEvent struct {
+	SchemaVersion int       `json:"schema_version"`
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	OccurredAt    time.Time `json:"occurred_at"`
+
+	RequestID      string `json:"request_id"`
+	UserID         int64  `json:"user_id"`
+	APIKeyID       int64  `json:"api_key_id"`
+	AccountID      int64  `json:"account_id"`
+	GroupID        *int64 `json:"group_id,omitempty"`
+	SubscriptionID *int64 `json:"subscription_id,omitempty"`
+	ChannelID      *int64 `json:"channel_id,omitempty"`
+
+	Model            string  `json:"model"`
+	RequestedModel   string  `json:"requested_model,omitempty"`
+	UpstreamModel    *string `json:"upstream_model,omitempty"`
+	BillingMode      *string `json:"billing_mode,omitempty"`
+	ServiceTier      *string `json:"service_tier,omitempty"`
+	ReasoningEffort  *string `json:"reasoning_effort,omitempty"`
+	InboundEndpoint  *string `json:"inbound_endpoint,omitempty"`
+	UpstreamEndpoint *string `json:"upstream_endpoint,omitempty"`
+	RequestType      string  `json:"request_type"`
+	BillingType      int8    `json:"billing_type"`
+
+	InputTokens           int `json:"input_tokens"`
+	OutputTokens          int `json:"output_tokens"`
+	CacheCreationTokens   int `json:"cache_creation_tokens"`
+	CacheReadTokens       int `json:"cache_read_tokens"`
+	CacheCreation5mTokens int `json:"cache_creation_5m_tokens"`
+	CacheCreation1hTokens int `json:"cache_creation_1h_tokens"`
+	ImageOutputTokens     int `json:"image_output_tokens"`
+	TotalTokens           int `json:"total_tokens"`
+
+	InputCost             float64  `json:"input_cost"`
+	OutputCost            float64  `json:"output_cost"`
+	CacheCreationCost     float64  `json:"cache_creation_cost"`
+	CacheReadCost         float64  `json:"cache_read_cost"`
+	ImageOutputCost       float64  `json:"image_output_cost"`
+	TotalCost             float64  `json:"total_cost"`
+	ActualCost            float64  `json:"actual_cost"`
+	RateMultiplier        float64  `json:"rate_multiplier"`
+	AccountRateMultiplier *float64 `json:"account_rate_multiplier,omitempty"`
+
+	DurationMs   *int    `json:"duration_ms,omitempty"`
+	FirstTokenMs *int    `json:"first_token_ms,omitempty"`
+	ImageCount   int     `json:"image_count,omitempty"`
+	ImageSize    *string `json:"image_size,omitempty"`
+}
+
+// NewUsageEvent 由使用记录构建导出事件（不含客户端 IP / User-Agent）。
+func NewUsageEvent(usageLog *UsageLog) *UsageEvent {
+	occurredAt := usageLog.CreatedAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	return &UsageEvent{
+		SchemaVersion: usageEventSchemaVersion,
+		EventID:       fmt.Sprintf("%s:%d", usageLog.RequestID, usageLog.APIKeyID),
+		EventType:     UsageEventTypeCompleted,
+		OccurredAt:    occurredAt.UTC(),
+
+		RequestID:      usageLog.RequestID,
+		UserID:         usageLog.UserID,
+		APIKeyID:       usageLog.APIKeyID,
+		AccountID:      usageLog.AccountID,
+		GroupID:        usageLog.GroupID,
+		SubscriptionID: usageLog.SubscriptionID,
+		ChannelID:      usageLog.ChannelID,
+
+		Model:            usageLog.Model,
+		RequestedModel:   usageLog.RequestedModel,
+		UpstreamModel:    usageLog.UpstreamModel,
+		BillingMode:      usageLog.BillingMode,
+		ServiceTier:      usageLog.ServiceTier,
+		ReasoningEffort:  usageLog.ReasoningEffort,
+		InboundEndpoint:  usageLog.InboundEndpoint,
+		UpstreamEndpoint: usageLog.UpstreamEndpoint,
+		RequestType:      usageLog.EffectiveRequestType().String(),
+		BillingType:      usageLog.BillingType,
+
+		InputTokens:           usageLog.InputTokens,
+		OutputTokens:          usageLog.OutputTokens,
+		CacheCreationTokens:   usageLog.CacheCreationTokens,
+		CacheReadTokens:       usageLog.CacheReadTokens,
+		CacheCreation5mTokens: usageLog.CacheCreation5mTokens,
+		CacheCreation1hTokens: usageLog.CacheCreation1hTokens,
+		ImageOutputTokens:     usageLog.ImageOutputTokens,
+		TotalTokens:           usageLog.TotalTokens(),
+
+		InputCost:             usageLog.InputCost,
+		OutputCost:            usageLog.OutputCost,
+		CacheCreationCost:     usageLog.CacheCreationCost,
+		CacheReadCost:         usageLog.CacheReadCost,
+		ImageOutputCost:       usageLog.ImageOutputCost,
+		TotalCost:             usageLog.TotalCost,
+		ActualCost:            usageLog.ActualCost,
+		RateMultiplier:        usageLog.RateMultiplier,
+		AccountRateMultiplier: usageLog.AccountRateMultiplier,
+
+		DurationMs:   usageLog.DurationMs,
+		FirstTokenMs: usageLog.FirstTokenMs,
+		ImageCount:   usageLog.ImageCount,
+		ImageSize:    usageLog.ImageSize,
+	}
+}
+
+// UsageEventMessage 待发布的单条消息，Key 用于 Kafka 分区（同一 API Key 的事件保持有序）。
+type UsageEventMessage struct {
+	EventID string
+	Key     string
+	Payload []byte
+}
+
+// UsageEventPublisher 事件总线发布端。Publish 仅在整批消息均被 broker 确认后返回 nil。
+type UsageEventPublisher interface {
+	Backend() string
+	Publish(ctx context.Context, msgs []UsageEventMessage) error
+	Close() error
+}
+
+// UsageEventDeadLetter 发布失败、待重投的事件。
+type UsageEventDeadLetter struct {
+	ID            int64
+	EventID       string
+	Backend       string
+	Payload       []byte
+	Reason        string
+	Attempts      int
+	LastError     string
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+}
+
+// UsageEventDeadLetterRepository 死信存储（数据库）。
+type UsageEventDeadLetterRepository interface {
+	Insert(ctx context.Context, letters []*UsageEventDeadLetter) error
+	// ListDue 按 id 顺序返回 next_attempt_at 已到期的死信
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*UsageEventDeadLetter, error)
+	Delete(ctx context.Context, ids []int64) error
+	MarkFailed(ctx context.Context, ids []int64, lastError string, nextAttemptAt time.Time) error
+}
+
+// UsageEventExporterStats 导出器运行统计。
+type UsageEventExporterStats struct {
+	Backend          string `json:"backend"`
+	QueueLength      int    `json:"queue_length"`
+	QueueCapacity    int    `json:"queue_capacity"`
+	Published        uint64 `json:"published"`
+	PublishFailures  uint64 `json:"publish_failures"`
+	DeadLettered     uint64 `json:"dead_lettered"`
+	DeadLetterErrors uint64 `json:"dead_letter_errors"`
+	Redriven         uint64 `json:"redriven"`
+}
+
+// UsageEventExporter 将每个完成请求的使用记录异步发布到 Kafka / NATS。
+// 批量发布失败按退避重试，重试耗尽、队列满或停机时未投递的事件写入死信表，并由后台定期重投（至少一次）。
+type UsageEventExporter struct {
+	publisher  UsageEventPublisher
+	deadLetter UsageEventDeadLetterRepository
+
+	queue          chan UsageEventMessage
+	batchSize      int
+	flushInterval  time.Duration
+	maxAttempts    int
+	retryBackoff   time.Duration
+	publishTimeout time.Duration
+	redriveEvery   time.Duration
+	redriveBatch   int
+
+	published        atomic.Uint64
+	publishFailures  atomic.Uint64
+	deadLettered     atomic.Uint64
+	deadLetterErrors atomic.Uint64
+	redriven         atomic.Uint64
+
+	stopCh    chan struct{}
+	stopOnce  sync.Once
+	startOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewUsageEventExporter 创建使用记录事件导出器；未启用或缺少发布端时返回 nil（所有方法对 nil 安全）。
+func NewUsageEventExporter(publisher UsageEventPublisher, deadLetter UsageEventDeadLetterRepository, cfg *config.Config) *UsageEventExporter {
+	if cfg == nil || !cfg.UsageExport.Enabled || publisher == nil {
+		return nil
+	}
+	exportCfg := cfg.UsageExport
+	return &UsageEventExporter{
+		publisher:      publisher,
+		deadLetter:     deadLetter,
+		queue:          make(chan UsageEventMessage, max(exportCfg.QueueSize, 1)),
+		batchSize:      max(exportCfg.BatchSize, 1),
+		flushInterval:  time.Duration(max(exportCfg.FlushIntervalMs, 1)) * time.Millisecond,
+		maxAttempts:    max(exportCfg.MaxAttempts, 1),
+		retryBackoff:   time.Duration(max(exportCfg.RetryBackoffMs, 0)) * time.Millisecond,
+		publishTimeout: time.Duration(max(exportCfg.PublishTimeoutSeconds, 1)) * time.Second,
+		redriveEvery:   time.Duration(max(exportCfg.DeadLetterRedriveIntervalSeconds, 1)) * time.Second,
+		redriveBatch:   max(exportCfg.DeadLetterBatchSize, 1),
+		stopCh:         make(chan struct{}),
+	}
+}
+
+// Start 启动发布与死信重投循环。
+func (e *UsageEventExporter) Start() {
+	if e == nil {
+		return
+	}
+	e.startOnce.Do(func() {
+		e.wg.Add(1)
+		go e.publishLoop()
+		if e.deadLetter != nil {
+			e.wg.Add(1)
+			go e.redriveLoop()
+		}
+	})
+}
+
+// Stop 停止循环：尽力发布队列中剩余事件，未成功的写入死信表。
+func (e *UsageEventExporter) Stop() {
+	if e == nil || e.stopCh == nil {
+		return
+	}
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+		e.wg.Wait()
+		if err := e.publisher.Close(); err != nil {
+			logger.L().With(zap.String("component", "service.usage_event_export"), zap.Error(err)).
+				Warn("usage_event_export.publisher_close_failed")
+		}
+	})
+}
+
+// Export 将使用记录加入发布队列（非阻塞）；队列满时直接写入死信表，保证不丢失。
+func (e *UsageEventExporter) Export(usageLog *UsageLog) {
+	if e == nil || usageLog == nil {
+		return
+	}
+	event := NewUsageEvent(usageLog)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.L().With(zap.String("component", "service.usage_event_export"), zap.String("request_id", usageLog.RequestID), zap.Error(err)).
+			Error("usage_event_export.marshal_failed")
+		return
+	}
+	msg := UsageEventMessage{EventID: event.EventID, Key: fmt.Sprintf("%d", event.APIKeyID), Payload: payload}
+
+	select {
+	case <-e.stopCh:
+		e.deadLetterMessages([]UsageEventMessage{msg}, UsageEventDeadLetterReasonShutdown, nil)
+		return
+	default:
+	}
+	select {
+	case e.queue <- msg:
+	default:
+		e.deadLetterMessages([]UsageEventMessage{msg}, UsageEventDeadLetterReasonQueueFull, nil)
+	}
+}
+
+// Stats 返回运行统计。
+func (e *UsageEventExporter) Stats() UsageEventExporterStats {
+	if e == nil {
+		return UsageEventExporterStats{}
+	}
+	return UsageEventExporterStats{
+		Backend:          e.publisher.Backend(),
+		QueueLength:      len(e.queue),
+		QueueCapacity:    cap(e.queue),
+		Published:        e.published.Load(),
+		PublishFailures:  e.publishFailures.Load(),
+		DeadLettered:     e.deadLettered.Load(),
+		DeadLetterErrors: e.deadLetterErrors.Load(),
+		Redriven:         e.redriven.Load(),
+	}
+}
+
+func (e *UsageEventExporter) publishLoop() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]UsageEventMessage, 0, e.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		e.publishBatch(batch)
+		batch = make([]UsageEventMessage, 0, e.batchSize)
+	}
+
+	for {
+		select {
+		case <-e.stopCh:
+			e.drainOnStop(batch)
+			return
+		case msg := <-e.queue:
+			batch = append(batch, msg)
+			if len(batch) >= e.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// drainOnStop 停机时对剩余事件做一次发布尝试，失败则写入死信表。
+func (e *UsageEventExporter) drainOnStop(pending []UsageEventMessage) {
+	for {
+		select {
+		case msg := <-e.queue:
+			pending = append(pending, msg)
+			continue
+		default:
+		}
+		break
+	}
+	for len(pending) > 0 {
+		n := min(len(pending), e.batchSize)
+		chunk := pending[:n]
+		pending = pending[n:]
+		ctx, cancel := context.WithTimeout(context.Background(), e.publishTimeout)
+		err := e.publisher.Publish(ctx, chunk)
+		cancel()
+		if err == nil {
+			e.published.Add(uint64(len(chunk)))
+			continue
+		}
+		e.publishFailures.Add(1)
+		e.deadLetterMessages(chunk, UsageEventDeadLetterReasonShutdown, err)
+	}
+}
+
+// publishBatch 按退避重试发布一批事件，重试耗尽后写入死信表。
+func (e *UsageEventExporter) publishBatch(batch []UsageEventMessage) {
+	var lastErr error
+	for attempt := 1; attempt <= e.maxAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), e.publishTimeout)
+		lastErr = e.publisher.Publish(ctx, batch)
+		cancel()
+		if lastErr == nil {
+			e.published.Add(uint64(len(batch)))
+			return
+		}
+		e.publishFailures.Add(1)
+		if attempt == e.maxAttempts || e.retryBackoff <= 0 {
+			continue
+		}
+		select {
+		case <-e.stopCh:
+			// 停机期间不再等待退避，直接进入死信
+			attempt = e.maxAttempts
+		case <-time.After(e.retryBackoff << (attempt - 1)):
+		}
+	}
+	logger.L().With(
+		zap.String("component", "service.usage_event_export"),
+		zap.String("backend", e.publisher.Backend()),
+		zap.Int("batch_size", len(batch)),
+		zap.Error(lastErr),
+	).Warn("usage_event_export.publish_failed")
+	e.deadLetterMessages(batch, UsageEventDeadLetterReasonPublishFailed, lastErr)
+}
+
+func (e *UsageEventExporter) deadLetterMessages(msgs []UsageEventMessage, reason string, cause error) {
+	if len(msgs) == 0 {
+		return
+	}
+	if e.deadLetter == nil {
+		e.deadLetterErrors.Add(uint64(len(msgs)))
+		logger.L().With(
+			zap.String("component", "service.usage_event_export"),
+			zap.String("reason", reason),
+			zap.Int("events", len(msgs)),
+		).Error("usage_event_export.events_lost_no_dead_letter_store")
+		return
+	}
+	lastError := ""
+	attempts := 0
+	if cause != nil {
+		lastError = truncateString(cause.Error(), 2048)
+		attempts = e.maxAttempts
+	}
+	now := time.Now()
+	letters := make([]*UsageEventDeadLetter, 0, len(msgs))
+	for _, msg := range msgs {
+		letters = append(letters, &UsageEventDeadLetter{
+			EventID:       msg.EventID,
+			Backend:       e.publisher.Backend(),
+			Payload:       msg.Payload,
+			Reason:        reason,
+			Attempts:      attempts,
+			LastError:     lastError,
+			CreatedAt:     now,
+			NextAttemptAt: now,
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), usageEventDeadLetterTimeout)
+	defer cancel()
+	if err := e.deadLetter.Insert(ctx, letters); err != nil {
+		e.deadLetterErrors.Add(uint64(len(msgs)))
+		logger.L().With(
+			zap.String("component", "service.usage_event_export"),
+			zap.String("reason", reason),
+			zap.Int("events", len(msgs)),
+			zap.Error(err),
+		).Error("usage_event_export.dead_letter_insert_failed")
+		return
+	}
+	e.deadLettered.Add(uint64(len(msgs)))
+}
+
+func (e *UsageEventExporter) redriveLoop() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.redriveEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stopCh:
+			return
+		case <-ticker.C:
+			e.redriveOnce()
+		}
+	}
+}
+
+// redriveOnce 重投一轮到期死信：成功则删除，失败则按尝试次数指数退避。
+func (e *UsageEventExporter) redriveOnce() {
+	ctx, cancel := context.WithTimeout(context.Background(), usageEventDeadLetterTimeout)
+	letters, err := e.deadLetter.ListDue(ctx, time.Now(), e.redriveBatch)
+	cancel()
+	if err != nil {
+		logger.L().With(zap.String("component", "service.usage_event_export"), zap.Error(err)).
+			Warn("usage_event_export.dead_letter_list_failed")
+		return
+	}
+
+	for start := 0; start < len(letters); start += e.batchSize {
+		select {
+		case <-e.stopCh:
+			return
+		default:
+		}
+		chunk := letters[start:min(start+e.batchSize, len(letters))]
+		msgs := make([]UsageEventMessage, 0, len(chunk))
+		ids := make([]int64, 0, len(chunk))
+		maxAttempts := 0
+		for _, letter := range chunk {
+			msgs = append(msgs, UsageEventMessage{EventID: letter.EventID, Key: usageEventKeyFromPayload(letter.Payload), Payload: letter.Payload})
+			ids = append(ids, letter.ID)
+			maxAttempts = max(maxAttempts, letter.Attempts)
+		}
+
+		pubCtx, pubCancel := context.WithTimeout(context.Background(), e.publishTimeout)
+		pubErr := e.publisher.Publish(pubCtx, msgs)
+		pubCancel()
+
+		dbCtx, dbCancel := context.WithTimeout(context.Background(), usageEventDeadLetterTimeout)
+		if pubErr == nil {
+			e.published.Add(uint64(len(msgs)))
+			e.redriven.Add(uint64(len(msgs)))
+			// 删除失败时下一轮会重复投递，符合至少一次语义
+			if err := e.deadLetter.Delete(dbCtx, ids); err != nil {
+				logger.L().With(zap.String("component", "service.usage_event_export"), zap.Error(err)).
+					Warn("usage_event_export.dead_letter_delete_failed")
+			}
+			dbCancel()
+			continue
+		}
+
+		e.publishFailures.Add(1)
+		factor := min(1<<min(maxAttempts, 5), usageEventRedriveMaxBackoffFactor)
+		next := time.Now().Add(e.redriveEvery * time.Duration(factor))
+		if err := e.deadLetter.MarkFailed(dbCtx, ids, truncateString(pubErr.Error(), 2048), next); err != nil {
+			logger.L().With(zap.String("component", "service.usage_event_export"), zap.Error(err)).
+				Warn("usage_event_export.dead_letter_mark_failed")
+		}
+		dbCancel()
+		logger.L().With(
+			zap.String("component", "service.usage_event_export"),
+			zap.String("backend", e.publisher.Backend()),
+			zap.Int("events", len(msgs)),
+			zap.Error(pubErr),
+		).Warn("usage_event_export.redrive_failed")
+		// broker 仍不可用，本轮不再继续
+		return
+	}
+}
+
+func usageEventKeyFromPayload(payload []byte) string {
+	var probe struct {
+		APIKeyID int64 `json:"api_key_id"`
+	}
+	if err := json.Unmarshal(payload, &probe); err != nil || probe.APIKeyID == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", probe.APIKeyID)
+}
+
+// SetUsageEventExporter 注入使用记录事件导出器。
+func (s *GatewayService) SetUsageEventExporter(exporter *UsageEventExporter) {
+	s.usageEventExporter = exporter
+}
+
+// SetUsageEventExporter 注入使用记录事件导出器。
+func (s *OpenAIGatewayService) SetUsageEventExporter(exporter *UsageEventExporter) {
+	s.usageEventExporter = exporter
+}
+
+// SetUsageEventExporter 注入使用记录事件导出器，回放补写的使用记录同样导出。
+func (r *UsageRecordJournalReplayer) SetUsageEventExporter(exporter *UsageEventExporter) {
+	if r == nil {
+		return
+	}
+	r.usageEventExporter = exporter
+}
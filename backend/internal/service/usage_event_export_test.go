@@ -0,0 +1,304 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+type usageEventPublisherStub struct {
+	mu      sync.Mutex
+	err     error
+	calls   int
+	batches [][]UsageEventMessage
+	closed  bool
+}
+
+func (p *usageEventPublisherStub) Backend() string { return config.UsageExportBackendKafka }
+
+func (p *usageEventPublisherStub) Publish(_ context.Context, msgs []UsageEventMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.err != nil {
+		return p.err
+	}
+	p.batches = append(p.batches, append([]UsageEventMessage(nil), msgs...))
+	return nil
+}
+
+func (p *usageEventPublisherStub) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *usageEventPublisherStub) setErr(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+func (p *usageEventPublisherStub) published() []UsageEventMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []UsageEventMessage
+	for _, batch := range p.batches {
+		out = append(out, batch...)
+	}
+	return out
+}
+
+type usageEventDeadLetterRepoStub struct {
+	mu      sync.Mutex
+	nextID  int64
+	letters map[int64]*UsageEventDeadLetter
+	failed  []int64
+}
+
+func newUsageEventDeadLetterRepoStub() *usageEventDeadLetterRepoStub {
+	return &usageEventDeadLetterRepoStub{letters: map[int64]*UsageEventDeadLetter{}}
+}
+
+func (r *usageEventDeadLetterRepoStub) Insert(_ context.Context, letters []*UsageEventDeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, letter := range letters {
+		r.nextID++
+		copied := *letter
+		copied.ID = r.nextID
+		r.letters[copied.ID] = &copied
+	}
+	return nil
+}
+
+func (r *usageEventDeadLetterRepoStub) ListDue(_ context.Context, now time.Time, limit int) ([]*UsageEventDeadLetter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*UsageEventDeadLetter
+	for id := int64(1); id <= r.nextID && len(out) < limit; id++ {
+		if letter, ok := r.letters[id]; ok && !letter.NextAttemptAt.After(now) {
+			copied := *letter
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (r *usageEventDeadLetterRepoStub) Delete(_ context.Context, ids []int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		delete(r.letters, id)
+	}
+	return nil
+}
+
+func (r *usageEventDeadLetterRepoStub) MarkFailed(_ context.Context, ids []int64, lastError string, nextAttemptAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		if letter, ok := r.letters[id]; ok {
+			letter.Attempts++
+			letter.LastError = lastError
+			letter.NextAttemptAt = nextAttemptAt
+		}
+	}
+	r.failed = append(r.failed, ids...)
+	return nil
+}
+
+func (r *usageEventDeadLetterRepoStub) all() []*UsageEventDeadLetter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]*UsageEventDeadLetter, 0, len(r.letters))
+	for id := int64(1); id <= r.nextID; id++ {
+		if letter, ok := r.letters[id]; ok {
+			out = append(out, letter)
+		}
+	}
+	return out
+}
+
+func newUsageEventExportConfigForTest() *config.Config {
+	cfg := &config.Config{}
+	cfg.UsageExport = config.UsageExportConfig{
+		Enabled:                          true,
+		Backend:                          config.UsageExportBackendKafka,
+		QueueSize:                        16,
+		BatchSize:                        2,
+		FlushIntervalMs:                  10,
+		MaxAttempts:                      2,
+		RetryBackoffMs:                   1,
+		PublishTimeoutSeconds:            1,
+		DeadLetterRedriveIntervalSeconds: 3600,
+		DeadLetterBatchSize:              10,
+	}
+	return cfg
+}
+
+func newUsageLogForExportTest(requestID string, apiKeyID int64) *UsageLog {
+	return &UsageLog{
+		RequestID:    requestID,
+		UserID:       7,
+		APIKeyID:     apiKeyID,
+		AccountID:    3,
+		Model:        "claude-sonnet-4",
+		InputTokens:  100,
+		OutputTokens: 20,
+		TotalCost:    0.5,
+		ActualCost:   0.4,
+		IPAddress:    strPtr("203.0.113.9"),
+		UserAgent:    strPtr("curl/8"),
+		CreatedAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestNewUsageEventExporter_DisabledReturnsNil(t *testing.T) {
+	cfg := newUsageEventExportConfigForTest()
+	cfg.UsageExport.Enabled = false
+	require.Nil(t, NewUsageEventExporter(&usageEventPublisherStub{}, nil, cfg))
+	require.Nil(t, NewUsageEventExporter(nil, nil, newUsageEventExportConfigForTest()))
+
+	var exporter *UsageEventExporter
+	exporter.Start()
+	exporter.Export(newUsageLogForExportTest("req", 1))
+	exporter.Stop()
+	(&UsageEventExporter{}).Stop()
+}
+
+func TestNewUsageEvent_Fields(t *testing.T) {
+	event := NewUsageEvent(newUsageLogForExportTest("req-1", 42))
+
+	require.Equal(t, "req-1:42", event.EventID)
+	require.Equal(t, UsageEventTypeCompleted, event.EventType)
+	require.Equal(t, 1, event.SchemaVersion)
+	require.Equal(t, 120, event.TotalTokens)
+
+	raw, err := json.Marshal(event)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "203.0.113.9")
+	require.NotContains(t, string(raw), "curl/8")
+	require.Equal(t, "42", usageEventKeyFromPayload(raw))
+}
+
+func TestUsageEventExporter_PublishesInBatches(t *testing.T) {
+	publisher := &usageEventPublisherStub{}
+	deadLetters := newUsageEventDeadLetterRepoStub()
+	exporter := NewUsageEventExporter(publisher, deadLetters, newUsageEventExportConfigForTest())
+	exporter.Start()
+
+	for i, id := range []string{"a", "b", "c"} {
+		exporter.Export(newUsageLogForExportTest(id, int64(i+1)))
+	}
+	require.Eventually(t, func() bool { return len(publisher.published()) == 3 }, time.Second, 5*time.Millisecond)
+	exporter.Stop()
+
+	msgs := publisher.published()
+	require.Equal(t, "a:1", msgs[0].EventID)
+	require.Equal(t, "1", msgs[0].Key)
+	require.Empty(t, deadLetters.all())
+	require.True(t, publisher.closed)
+	require.Equal(t, uint64(3), exporter.Stats().Published)
+}
+
+func TestUsageEventExporter_RetryExhaustedDeadLetters(t *testing.T) {
+	publisher := &usageEventPublisherStub{err: errors.New("broker down")}
+	deadLetters := newUsageEventDeadLetterRepoStub()
+	exporter := NewUsageEventExporter(publisher, deadLetters, newUsageEventExportConfigForTest())
+	exporter.Start()
+
+	exporter.Export(newUsageLogForExportTest("a", 1))
+	exporter.Export(newUsageLogForExportTest("b", 1))
+	require.Eventually(t, func() bool { return len(deadLetters.all()) == 2 }, time.Second, 5*time.Millisecond)
+	exporter.Stop()
+
+	letters := deadLetters.all()
+	require.Equal(t, UsageEventDeadLetterReasonPublishFailed, letters[0].Reason)
+	require.Equal(t, 2, letters[0].Attempts)
+	require.Equal(t, "broker down", letters[0].LastError)
+	require.Equal(t, config.UsageExportBackendKafka, letters[0].Backend)
+	require.GreaterOrEqual(t, publisher.calls, 2)
+}
+
+func TestUsageEventExporter_QueueFullDeadLetters(t *testing.T) {
+	cfg := newUsageEventExportConfigForTest()
+	cfg.UsageExport.QueueSize = 1
+	publisher := &usageEventPublisherStub{}
+	deadLetters := newUsageEventDeadLetterRepoStub()
+	// 未 Start，队列不会被消费
+	exporter := NewUsageEventExporter(publisher, deadLetters, cfg)
+
+	exporter.Export(newUsageLogForExportTest("a", 1))
+	exporter.Export(newUsageLogForExportTest("b", 1))
+
+	letters := deadLetters.all()
+	require.Len(t, letters, 1)
+	require.Equal(t, "b:1", letters[0].EventID)
+	require.Equal(t, UsageEventDeadLetterReasonQueueFull, letters[0].Reason)
+	require.Zero(t, letters[0].Attempts)
+}
+
+func TestUsageEventExporter_StopDrainsQueue(t *testing.T) {
+	publisher := &usageEventPublisherStub{err: errors.New("broker down")}
+	deadLetters := newUsageEventDeadLetterRepoStub()
+	exporter := NewUsageEventExporter(publisher, deadLetters, newUsageEventExportConfigForTest())
+
+	exporter.Export(newUsageLogForExportTest("a", 1))
+	exporter.Start()
+	exporter.Stop()
+
+	letters := deadLetters.all()
+	require.Len(t, letters, 1)
+	require.Contains(t, []string{UsageEventDeadLetterReasonShutdown, UsageEventDeadLetterReasonPublishFailed}, letters[0].Reason)
+
+	exporter.Export(newUsageLogForExportTest("b", 1))
+	letters = deadLetters.all()
+	require.Len(t, letters, 2)
+	require.Equal(t, UsageEventDeadLetterReasonShutdown, letters[1].Reason)
+}
+
+func TestUsageEventExporter_RedriveDeletesOnSuccessAndBacksOffOnFailure(t *testing.T) {
+	publisher := &usageEventPublisherStub{err: errors.New("still down")}
+	deadLetters := newUsageEventDeadLetterRepoStub()
+	exporter := NewUsageEventExporter(publisher, deadLetters, newUsageEventExportConfigForTest())
+
+	payload, err := json.Marshal(NewUsageEvent(newUsageLogForExportTest("a", 9)))
+	require.NoError(t, err)
+	require.NoError(t, deadLetters.Insert(context.Background(), []*UsageEventDeadLetter{
+		{EventID: "a:9", Backend: config.UsageExportBackendKafka, Payload: payload, Reason: UsageEventDeadLetterReasonQueueFull},
+	}))
+
+	exporter.redriveOnce()
+	letters := deadLetters.all()
+	require.Len(t, letters, 1)
+	require.Equal(t, 1, letters[0].Attempts)
+	require.Equal(t, "still down", letters[0].LastError)
+	require.True(t, letters[0].NextAttemptAt.After(time.Now()))
+
+	// 未到期的死信不重投
+	publisher.setErr(nil)
+	exporter.redriveOnce()
+	require.Empty(t, publisher.published())
+
+	deadLetters.mu.Lock()
+	letters[0].NextAttemptAt = time.Now().Add(-time.Second)
+	deadLetters.mu.Unlock()
+	exporter.redriveOnce()
+
+	require.Empty(t, deadLetters.all())
+	msgs := publisher.published()
+	require.Len(t, msgs, 1)
+	require.Equal(t, "a:9", msgs[0].EventID)
+	require.Equal(t, "9", msgs[0].Key)
+	require.Equal(t, uint64(1), exporter.Stats().Redriven)
+}
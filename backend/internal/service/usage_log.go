@@ -161,6 +161,9 @@ type UsageLog struct {
 	// Cache TTL Override 标记（管理员强制替换了缓存 TTL 计费）
 	CacheTTLOverridden bool
 
+	// PIIRedactions 请求内容中被分组敏感信息过滤策略脱敏的命中次数
+	PIIRedactions int
+
 	// 图片生成字段
 	ImageCount int
 	ImageSize  *string
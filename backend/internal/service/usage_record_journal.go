@@ -0,0 +1,426 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/Wei-Shaw/sub2api/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultUsageRecordJournalReplayInterval = 30 * time.Second
+	usageRecordJournalReplayTimeout         = 10 * time.Second
+	usageRecordJournalMaxLineBytes          = 4 << 20
+	usageRecordJournalReplayingSuffix       = ".replaying"
+
+	// UsageRecordJournalReasonApplyFailed 扣费入库失败（如数据库不可用）。
+	UsageRecordJournalReasonApplyFailed = "apply_failed"
+	// UsageRecordJournalReasonOverflow 使用量记录池队列已满或已停止。
+	UsageRecordJournalReasonOverflow = "overflow"
+)
+
+// errUsageRecordJournaled 表示使用量记录已写入本地溢出日志，将由回放任务补写入库。
+var errUsageRecordJournaled = errors.New("usage record persisted to local journal")
+
+// UsageRecordJournalEntry 本地溢出日志中的一条使用量记录。
+// 保存可序列化的扣费命令与使用日志，回放时按原幂等键重新入库。
+type UsageRecordJournalEntry struct {
+	Reason     string               `json:"reason"`
+	RecordedAt time.Time            `json:"recorded_at"`
+	Command    *UsageBillingCommand `json:"command,omitempty"`
+	UsageLog   *UsageLog            `json:"usage_log,omitempty"`
+}
+
+// UsageRecordJournalStats 本地溢出日志计数。
+type UsageRecordJournalStats struct {
+	Appended       uint64
+	AppendFailed   uint64
+	Replayed       uint64
+	ReplayFailed   uint64
+	ReplayDiscards uint64
+}
+
+// UsageRecordJournal 使用量记录本地溢出日志（JSONL 追加写）。
+// 数据库不可用或使用量记录池溢出时，将记录落盘，待回放任务在数据库恢复后补写，保证使用量不丢失。
+type UsageRecordJournal struct {
+	path string
+
+	mu       sync.Mutex
+	replayMu sync.Mutex
+
+	appended       atomic.Uint64
+	appendFailed   atomic.Uint64
+	replayed       atomic.Uint64
+	replayFailed   atomic.Uint64
+	replayDiscards atomic.Uint64
+}
+
+// NewUsageRecordJournal 从配置构建本地溢出日志；未启用时返回 nil。
+func NewUsageRecordJournal(cfg *config.Config) *UsageRecordJournal {
+	if cfg == nil || !cfg.Gateway.UsageRecord.JournalEnabled {
+		return nil
+	}
+	path := strings.TrimSpace(cfg.Gateway.UsageRecord.JournalPath)
+	if path == "" {
+		return nil
+	}
+	return NewUsageRecordJournalWithPath(path)
+}
+
+// NewUsageRecordJournalWithPath 使用指定文件路径构建本地溢出日志。
+func NewUsageRecordJournalWithPath(path string) *UsageRecordJournal {
+	return &UsageRecordJournal{path: path}
+}
+
+// Path 返回日志文件路径。
+func (j *UsageRecordJournal) Path() string {
+	if j == nil {
+		return ""
+	}
+	return j.path
+}
+
+// Append 追加一条记录并刷盘。
+func (j *UsageRecordJournal) Append(entry *UsageRecordJournalEntry) error {
+	if j == nil || entry == nil {
+		return nil
+	}
+	if entry.RecordedAt.IsZero() {
+		entry.RecordedAt = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		j.appendFailed.Add(1)
+		return fmt.Errorf("marshal usage record journal entry: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.appendLinesLocked([][]byte{line}); err != nil {
+		j.appendFailed.Add(1)
+		return err
+	}
+	j.appended.Add(1)
+	return nil
+}
+
+// Replay 回放日志中的记录。
+// 回放期间日志文件被切换为 .replaying，新记录继续写入原文件；
+// apply 失败时停止本轮回放，剩余记录追加回原文件等待下一轮。
+func (j *UsageRecordJournal) Replay(ctx context.Context, apply func(context.Context, *UsageRecordJournalEntry) error) (replayed int, remaining int, err error) {
+	if j == nil || apply == nil {
+		return 0, 0, nil
+	}
+	j.replayMu.Lock()
+	defer j.replayMu.Unlock()
+
+	replayingPath := j.path + usageRecordJournalReplayingSuffix
+	// 上次回放中断（进程退出）时遗留的 .replaying 优先处理，避免覆盖丢失
+	if _, statErr := os.Stat(replayingPath); errors.Is(statErr, os.ErrNotExist) {
+		j.mu.Lock()
+		renameErr := os.Rename(j.path, replayingPath)
+		j.mu.Unlock()
+		if renameErr != nil {
+			if errors.Is(renameErr, os.ErrNotExist) {
+				return 0, 0, nil
+			}
+			return 0, 0, fmt.Errorf("rotate usage record journal: %w", renameErr)
+		}
+	} else if statErr != nil {
+		return 0, 0, fmt.Errorf("stat usage record journal: %w", statErr)
+	}
+
+	lines, err := readUsageRecordJournalLines(replayingPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var pending [][]byte
+	var applyErr error
+	for i, line := range lines {
+		if applyErr != nil || ctx.Err() != nil {
+			pending = append(pending, lines[i:]...)
+			break
+		}
+		var entry UsageRecordJournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			j.replayDiscards.Add(1)
+			logger.L().With(
+				zap.String("component", "service.usage_record_journal"),
+				zap.Error(err),
+			).Error("usage_record.journal_entry_corrupted")
+			continue
+		}
+		if err := apply(ctx, &entry); err != nil {
+			j.replayFailed.Add(1)
+			applyErr = err
+			pending = append(pending, line)
+			continue
+		}
+		j.replayed.Add(1)
+		replayed++
+	}
+
+	if len(pending) > 0 {
+		j.mu.Lock()
+		err = j.appendLinesLocked(pending)
+		j.mu.Unlock()
+		if err != nil {
+			// 写回失败时保留 .replaying 文件，下一轮继续从中回放
+			return replayed, len(pending), err
+		}
+	}
+	if err := os.Remove(replayingPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return replayed, len(pending), fmt.Errorf("remove replayed usage record journal: %w", err)
+	}
+	return replayed, len(pending), applyErr
+}
+
+// Stats 返回日志计数。
+func (j *UsageRecordJournal) Stats() UsageRecordJournalStats {
+	if j == nil {
+		return UsageRecordJournalStats{}
+	}
+	return UsageRecordJournalStats{
+		Appended:       j.appended.Load(),
+		AppendFailed:   j.appendFailed.Load(),
+		Replayed:       j.replayed.Load(),
+		ReplayFailed:   j.replayFailed.Load(),
+		ReplayDiscards: j.replayDiscards.Load(),
+	}
+}
+
+func (j *UsageRecordJournal) appendLinesLocked(lines [][]byte) error {
+	if dir := filepath.Dir(j.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create usage record journal dir: %w", err)
+		}
+	}
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open usage record journal: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	for _, line := range lines {
+		_, _ = w.Write(line)
+		_ = w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write usage record journal: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("sync usage record journal: %w", err)
+	}
+	return f.Close()
+}
+
+func readUsageRecordJournalLines(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open usage record journal: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), usageRecordJournalMaxLineBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		lines = append(lines, append([]byte(nil), line...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read usage record journal: %w", err)
+	}
+	return lines, nil
+}
+
+// newUsageRecordJournalEntry 构建日志条目；关联对象（User/APIKey 等）不落盘，回放只需外键。
+func newUsageRecordJournalEntry(reason string, cmd *UsageBillingCommand, usageLog *UsageLog) *UsageRecordJournalEntry {
+	entry := &UsageRecordJournalEntry{
+		Reason:     reason,
+		RecordedAt: time.Now(),
+		Command:    cmd,
+	}
+	if usageLog != nil {
+		logCopy := *usageLog
+		logCopy.User = nil
+		logCopy.APIKey = nil
+		logCopy.Account = nil
+		logCopy.Group = nil
+		logCopy.Subscription = nil
+		entry.UsageLog = &logCopy
+	}
+	return entry
+}
+
+type usageRecordJournalCtxKey struct{}
+
+type usageRecordJournalCtxValue struct {
+	journal *UsageRecordJournal
+	// overflow 为 true 时任务来自池溢出路径：跳过数据库，直接写入日志
+	overflow bool
+}
+
+func withUsageRecordJournal(ctx context.Context, journal *UsageRecordJournal, overflow bool) context.Context {
+	if journal == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, usageRecordJournalCtxKey{}, usageRecordJournalCtxValue{journal: journal, overflow: overflow})
+}
+
+func usageRecordJournalFromContext(ctx context.Context) (*UsageRecordJournal, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	v, ok := ctx.Value(usageRecordJournalCtxKey{}).(usageRecordJournalCtxValue)
+	if !ok {
+		return nil, false
+	}
+	return v.journal, v.overflow
+}
+
+// UsageRecordJournalReplayer 周期性回放本地溢出日志，将记录补写到数据库。
+type UsageRecordJournalReplayer struct {
+	journal      *UsageRecordJournal
+	billingRepo  UsageBillingRepository
+	usageLogRepo UsageLogRepository
+	billingCache *BillingCacheService
+	dbHealth     *BillingDBHealthService
+	interval     time.Duration
+
+	usageEventExporter *UsageEventExporter
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewUsageRecordJournalReplayer 创建回放任务。
+// dbHealth 非 nil 时，计费数据库不可用期间跳过回放，避免每轮都在超时上空等。
+func NewUsageRecordJournalReplayer(journal *UsageRecordJournal, billingRepo UsageBillingRepository, usageLogRepo UsageLogRepository, billingCache *BillingCacheService, dbHealth *BillingDBHealthService, cfg *config.Config) *UsageRecordJournalReplayer {
+	interval := defaultUsageRecordJournalReplayInterval
+	if cfg != nil && cfg.Gateway.UsageRecord.JournalReplayIntervalSeconds > 0 {
+		interval = time.Duration(cfg.Gateway.UsageRecord.JournalReplayIntervalSeconds) * time.Second
+	}
+	return &UsageRecordJournalReplayer{
+		journal:      journal,
+		billingRepo:  billingRepo,
+		usageLogRepo: usageLogRepo,
+		billingCache: billingCache,
+		dbHealth:     dbHealth,
+		interval:     interval,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start 启动后台回放循环；未启用日志时不做任何事。
+func (r *UsageRecordJournalReplayer) Start() {
+	if r == nil || r.journal == nil {
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		r.replayOnce()
+		for {
+			select {
+			case <-r.stopCh:
+				return
+			case <-ticker.C:
+				r.replayOnce()
+			}
+		}
+	}()
+}
+
+// Stop 停止回放循环，并做最后一次回放尝试。
+func (r *UsageRecordJournalReplayer) Stop() {
+	if r == nil || r.journal == nil {
+		return
+	}
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+		r.wg.Wait()
+		r.replayOnce()
+	})
+}
+
+func (r *UsageRecordJournalReplayer) replayOnce() {
+	if !r.dbHealth.Available() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+	defer cancel()
+
+	replayed, remaining, err := r.journal.Replay(ctx, r.applyEntry)
+	if replayed == 0 && remaining == 0 && err == nil {
+		return
+	}
+	log := logger.L().With(
+		zap.String("component", "service.usage_record_journal"),
+		zap.String("path", r.journal.Path()),
+		zap.Int("replayed", replayed),
+		zap.Int("remaining", remaining),
+	)
+	if err != nil {
+		log.Warn("usage_record.journal_replay_incomplete", zap.Error(err))
+		return
+	}
+	log.Info("usage_record.journal_replayed")
+}
+
+// applyEntry 将单条日志记录写回数据库（扣费幂等，使用日志按 request_id 去重）。
+func (r *UsageRecordJournalReplayer) applyEntry(ctx context.Context, entry *UsageRecordJournalEntry) error {
+	if entry == nil {
+		return nil
+	}
+	entryCtx, cancel := context.WithTimeout(ctx, usageRecordJournalReplayTimeout)
+	defer cancel()
+
+	if entry.Command != nil && r.billingRepo != nil {
+		result, err := r.billingRepo.Apply(entryCtx, entry.Command)
+		if err != nil {
+			if errors.Is(err, ErrUsageBillingRequestConflict) {
+				// 同一 request_id 已按不同内容入账，无法安全补写，丢弃该条并告警
+				logger.L().With(
+					zap.String("component", "service.usage_record_journal"),
+					zap.String("request_id", entry.Command.RequestID),
+					zap.Int64("api_key_id", entry.Command.APIKeyID),
+				).Error("usage_record.journal_entry_conflict")
+				return nil
+			}
+			return err
+		}
+		if result != nil && result.Applied && r.billingCache != nil {
+			_ = r.billingCache.InvalidateUserBalance(entryCtx, entry.Command.UserID)
+		}
+	}
+	if entry.UsageLog != nil && r.usageLogRepo != nil {
+		if _, err := r.usageLogRepo.Create(entryCtx, entry.UsageLog); err != nil {
+			return err
+		}
+	}
+	if entry.UsageLog != nil {
+		r.usageEventExporter.Export(entry.UsageLog)
+	}
+	return nil
+}
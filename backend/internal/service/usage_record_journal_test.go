@@ -0,0 +1,234 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newUsageRecordJournalForTest(t *testing.T) *UsageRecordJournal {
+	t.Helper()
+	return NewUsageRecordJournalWithPath(filepath.Join(t.TempDir(), "journal", "usage.jsonl"))
+}
+
+func TestNewUsageRecordJournal_DisabledReturnsNil(t *testing.T) {
+	require.Nil(t, NewUsageRecordJournal(nil))
+	require.Nil(t, NewUsageRecordJournal(&config.Config{}))
+
+	cfg := &config.Config{}
+	cfg.Gateway.UsageRecord.JournalEnabled = true
+	cfg.Gateway.UsageRecord.JournalPath = "/tmp/usage.jsonl"
+	require.Equal(t, "/tmp/usage.jsonl", NewUsageRecordJournal(cfg).Path())
+}
+
+func TestUsageRecordJournal_AppendAndReplay(t *testing.T) {
+	journal := newUsageRecordJournalForTest(t)
+	apiKey := &APIKey{ID: 9, Key: "sk-secret"}
+	require.NoError(t, journal.Append(newUsageRecordJournalEntry(UsageRecordJournalReasonApplyFailed,
+		&UsageBillingCommand{RequestID: "req-1", APIKeyID: 9, BalanceCost: 1.5},
+		&UsageLog{RequestID: "req-1", APIKeyID: 9, APIKey: apiKey})))
+	require.NoError(t, journal.Append(newUsageRecordJournalEntry(UsageRecordJournalReasonOverflow,
+		&UsageBillingCommand{RequestID: "req-2", APIKeyID: 9}, nil)))
+
+	raw, err := os.ReadFile(journal.Path())
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "sk-secret", "关联对象不应落盘")
+
+	var got []*UsageRecordJournalEntry
+	replayed, remaining, err := journal.Replay(context.Background(), func(ctx context.Context, entry *UsageRecordJournalEntry) error {
+		got = append(got, entry)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, replayed)
+	require.Zero(t, remaining)
+	require.Len(t, got, 2)
+	require.Equal(t, "req-1", got[0].Command.RequestID)
+	require.Equal(t, 1.5, got[0].Command.BalanceCost)
+	require.Equal(t, UsageRecordJournalReasonApplyFailed, got[0].Reason)
+	require.Equal(t, "req-1", got[0].UsageLog.RequestID)
+	require.Nil(t, got[1].UsageLog)
+
+	_, err = os.Stat(journal.Path())
+	require.True(t, errors.Is(err, os.ErrNotExist))
+	_, err = os.Stat(journal.Path() + usageRecordJournalReplayingSuffix)
+	require.True(t, errors.Is(err, os.ErrNotExist))
+	require.Equal(t, uint64(2), journal.Stats().Replayed)
+}
+
+func TestUsageRecordJournal_ReplayKeepsRemainingOnFailure(t *testing.T) {
+	journal := newUsageRecordJournalForTest(t)
+	for _, id := range []string{"req-1", "req-2", "req-3"} {
+		require.NoError(t, journal.Append(newUsageRecordJournalEntry(UsageRecordJournalReasonApplyFailed, &UsageBillingCommand{RequestID: id}, nil)))
+	}
+
+	dbDown := errors.New("db down")
+	replayed, remaining, err := journal.Replay(context.Background(), func(ctx context.Context, entry *UsageRecordJournalEntry) error {
+		if entry.Command.RequestID == "req-2" {
+			return dbDown
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, dbDown)
+	require.Equal(t, 1, replayed)
+	require.Equal(t, 2, remaining)
+
+	// 数据库恢复后，剩余记录按原顺序补写
+	var ids []string
+	_, _, err = journal.Replay(context.Background(), func(ctx context.Context, entry *UsageRecordJournalEntry) error {
+		ids = append(ids, entry.Command.RequestID)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"req-2", "req-3"}, ids)
+}
+
+func TestUsageRecordJournal_ReplayResumesInterruptedFile(t *testing.T) {
+	journal := newUsageRecordJournalForTest(t)
+	require.NoError(t, journal.Append(newUsageRecordJournalEntry(UsageRecordJournalReasonOverflow, &UsageBillingCommand{RequestID: "old"}, nil)))
+	require.NoError(t, os.Rename(journal.Path(), journal.Path()+usageRecordJournalReplayingSuffix))
+	require.NoError(t, journal.Append(newUsageRecordJournalEntry(UsageRecordJournalReasonOverflow, &UsageBillingCommand{RequestID: "new"}, nil)))
+
+	var ids []string
+	apply := func(ctx context.Context, entry *UsageRecordJournalEntry) error {
+		ids = append(ids, entry.Command.RequestID)
+		return nil
+	}
+	_, _, err := journal.Replay(context.Background(), apply)
+	require.NoError(t, err)
+	require.Equal(t, []string{"old"}, ids)
+
+	_, _, err = journal.Replay(context.Background(), apply)
+	require.NoError(t, err)
+	require.Equal(t, []string{"old", "new"}, ids)
+}
+
+func TestUsageRecordJournal_ReplaySkipsCorruptedLines(t *testing.T) {
+	journal := newUsageRecordJournalForTest(t)
+	require.NoError(t, journal.Append(newUsageRecordJournalEntry(UsageRecordJournalReasonOverflow, &UsageBillingCommand{RequestID: "req-1"}, nil)))
+	f, err := os.OpenFile(journal.Path(), os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString("{not json\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	replayed, remaining, err := journal.Replay(context.Background(), func(ctx context.Context, entry *UsageRecordJournalEntry) error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, 1, replayed)
+	require.Zero(t, remaining)
+	require.Equal(t, uint64(1), journal.Stats().ReplayDiscards)
+}
+
+func TestApplyUsageBilling_JournalsOnApplyFailure(t *testing.T) {
+	journal := newUsageRecordJournalForTest(t)
+	repo := &openAIRecordUsageBillingRepoStub{err: errors.New("connection refused")}
+	ctx := withUsageRecordJournal(context.Background(), journal, false)
+	p := &postUsageBillingParams{
+		Cost:    &CostBreakdown{ActualCost: 1},
+		User:    &User{ID: 1},
+		APIKey:  &APIKey{ID: 2},
+		Account: &Account{ID: 3},
+	}
+
+	applied, err := applyUsageBilling(ctx, "req-1", &UsageLog{RequestID: "req-1"}, p, &billingDeps{deferredService: &DeferredService{}}, repo)
+	require.ErrorIs(t, err, errUsageRecordJournaled)
+	require.False(t, applied)
+	require.Equal(t, 1, repo.calls)
+	require.Equal(t, uint64(1), journal.Stats().Appended)
+
+	// 指纹冲突不是可恢复错误，不落盘
+	repo.err = ErrUsageBillingRequestConflict
+	_, err = applyUsageBilling(ctx, "req-1", &UsageLog{RequestID: "req-1"}, p, &billingDeps{deferredService: &DeferredService{}}, repo)
+	require.ErrorIs(t, err, ErrUsageBillingRequestConflict)
+	require.Equal(t, uint64(1), journal.Stats().Appended)
+}
+
+func TestApplyUsageBilling_OverflowWritesJournalWithoutDB(t *testing.T) {
+	journal := newUsageRecordJournalForTest(t)
+	repo := &openAIRecordUsageBillingRepoStub{}
+	ctx := withUsageRecordJournal(context.Background(), journal, true)
+	p := &postUsageBillingParams{
+		Cost:    &CostBreakdown{ActualCost: 1},
+		User:    &User{ID: 1},
+		APIKey:  &APIKey{ID: 2},
+		Account: &Account{ID: 3},
+	}
+
+	_, err := applyUsageBilling(ctx, "req-1", &UsageLog{RequestID: "req-1"}, p, &billingDeps{deferredService: &DeferredService{}}, repo)
+	require.ErrorIs(t, err, errUsageRecordJournaled)
+	require.Zero(t, repo.calls)
+
+	var entry *UsageRecordJournalEntry
+	_, _, err = journal.Replay(context.Background(), func(ctx context.Context, e *UsageRecordJournalEntry) error {
+		entry = e
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, UsageRecordJournalReasonOverflow, entry.Reason)
+	require.Equal(t, BuildUsageIdempotencyKey("req-1", 3, 0), entry.Command.IdempotencyKey)
+}
+
+func TestUsageRecordJournalReplayer_ApplyEntry(t *testing.T) {
+	billingRepo := &openAIRecordUsageBillingRepoStub{}
+	usageLogRepo := &openAIRecordUsageLogRepoStub{inserted: true}
+	replayer := NewUsageRecordJournalReplayer(newUsageRecordJournalForTest(t), billingRepo, usageLogRepo, nil, nil, nil)
+	require.Equal(t, defaultUsageRecordJournalReplayInterval, replayer.interval)
+
+	entry := &UsageRecordJournalEntry{Command: &UsageBillingCommand{RequestID: "req-1"}, UsageLog: &UsageLog{RequestID: "req-1"}}
+	require.NoError(t, replayer.applyEntry(context.Background(), entry))
+	require.Equal(t, 1, billingRepo.calls)
+	require.Equal(t, 1, usageLogRepo.calls)
+
+	billingRepo.err = errors.New("db down")
+	require.Error(t, replayer.applyEntry(context.Background(), entry))
+	require.Equal(t, 1, usageLogRepo.calls)
+
+	// 指纹冲突的记录丢弃，不阻塞后续回放
+	billingRepo.err = ErrUsageBillingRequestConflict
+	require.NoError(t, replayer.applyEntry(context.Background(), entry))
+}
+
+func TestUsageRecordWorkerPool_OverflowJournalsInsteadOfDropping(t *testing.T) {
+	journal := newUsageRecordJournalForTest(t)
+	pool := NewUsageRecordWorkerPoolWithOptions(UsageRecordWorkerPoolOptions{
+		WorkerCount:              1,
+		QueueSize:                1,
+		TaskTimeout:              time.Second,
+		OverflowPolicy:           config.UsageRecordOverflowPolicyDrop,
+		BackpressureQueuePercent: 50,
+		Journal:                  journal,
+	})
+	t.Cleanup(pool.Stop)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	require.Equal(t, UsageRecordSubmitModeEnqueued, pool.Submit(func(ctx context.Context) {
+		close(started)
+		<-block
+	}))
+	<-started
+	require.Equal(t, UsageRecordSubmitModeEnqueued, pool.Submit(func(ctx context.Context) {}))
+
+	var overflow bool
+	require.Equal(t, UsageRecordSubmitModeJournaled, pool.Submit(func(ctx context.Context) {
+		got, isOverflow := usageRecordJournalFromContext(ctx)
+		overflow = isOverflow && got == journal
+	}))
+	require.True(t, overflow)
+	close(block)
+
+	stats := pool.Stats()
+	require.Equal(t, 1, stats.QueueCapacity)
+	require.Equal(t, uint64(1), stats.JournaledTasks)
+	require.Zero(t, stats.DroppedQueueFull)
+	require.Equal(t, uint64(1), stats.PeakWaitingTasks)
+	require.GreaterOrEqual(t, stats.BackpressureEvents, uint64(1))
+}
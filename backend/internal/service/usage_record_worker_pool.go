@@ -29,7 +29,9 @@ const (
 	defaultUsageRecordAutoScaleDownStep    = 16
 	defaultUsageRecordAutoScaleInterval    = 3 * time.Second
 	defaultUsageRecordAutoScaleCooldown    = 10 * time.Second
+	defaultUsageRecordBackpressurePercent  = 80
 	usageRecordDropLogInterval             = 5 * time.Second
+	usageRecordBackpressureLogInterval     = 5 * time.Second
 )
 
 // UsageRecordTask 是提交到使用量记录池的任务。
@@ -43,6 +45,8 @@ const (
 	UsageRecordSubmitModeEnqueued UsageRecordSubmitMode = "enqueued"
 	UsageRecordSubmitModeDropped  UsageRecordSubmitMode = "dropped"
 	UsageRecordSubmitModeSync     UsageRecordSubmitMode = "sync_fallback"
+	// UsageRecordSubmitModeJournaled 队列溢出时任务在调用方执行，记录写入本地溢出日志而非直接入库。
+	UsageRecordSubmitModeJournaled UsageRecordSubmitMode = "journaled"
 )
 
 // UsageRecordWorkerPoolOptions 使用量记录池配置。
@@ -61,6 +65,10 @@ type UsageRecordWorkerPoolOptions struct {
 	AutoScaleDownStep     int
 	AutoScaleInterval     time.Duration
 	AutoScaleCooldown     time.Duration
+	// BackpressureQueuePercent 队列占用率达到该阈值时记录背压告警（0 表示关闭）
+	BackpressureQueuePercent int
+	// Journal 本地溢出日志；非 nil 时溢出任务改为落盘而非丢弃
+	Journal *UsageRecordJournal
 }
 
 // UsageRecordWorkerPoolStats 使用量记录池运行时统计。
@@ -76,6 +84,12 @@ type UsageRecordWorkerPoolStats struct {
 	DroppedQueueFull   uint64
 	DroppedPoolStopped uint64
 	SyncFallbackTasks  uint64
+
+	QueueCapacity           int
+	QueueUtilizationPercent int
+	PeakWaitingTasks        uint64
+	BackpressureEvents      uint64
+	JournaledTasks          uint64
 }
 
 // UsageRecordWorkerPool 提供“有界队列 + 固定 worker”的异步执行器。
@@ -90,6 +104,13 @@ type UsageRecordWorkerPool struct {
 	droppedPoolStopped    atomic.Uint64
 	syncFallback          atomic.Uint64
 	lastDropLogNanos      atomic.Int64
+	queueSize             int
+	backpressurePercent   int
+	peakWaiting           atomic.Uint64
+	backpressureEvents    atomic.Uint64
+	lastBackpressureNanos atomic.Int64
+	journal               *UsageRecordJournal
+	journaled             atomic.Uint64
 	autoScaleEnabled      bool
 	autoScaleMinWorkers   int
 	autoScaleMaxWorkers   int
@@ -106,8 +127,10 @@ type UsageRecordWorkerPool struct {
 }
 
 // NewUsageRecordWorkerPool 从配置构建使用量记录池。
-func NewUsageRecordWorkerPool(cfg *config.Config) *UsageRecordWorkerPool {
+// journal 为 nil 时溢出任务按 overflowPolicy 处理（可能丢弃）。
+func NewUsageRecordWorkerPool(cfg *config.Config, journal *UsageRecordJournal) *UsageRecordWorkerPool {
 	opts := usageRecordPoolOptionsFromConfig(cfg)
+	opts.Journal = journal
 	return NewUsageRecordWorkerPoolWithOptions(opts)
 }
 
@@ -128,6 +151,9 @@ func NewUsageRecordWorkerPoolWithOptions(opts UsageRecordWorkerPoolOptions) *Usa
 		autoScaleDownStep:     opts.AutoScaleDownStep,
 		autoScaleInterval:     opts.AutoScaleInterval,
 		autoScaleCooldown:     opts.AutoScaleCooldown,
+		queueSize:             opts.QueueSize,
+		backpressurePercent:   opts.BackpressureQueuePercent,
+		journal:               opts.Journal,
 	}
 
 	p.pool = pond.NewPool(
@@ -141,29 +167,28 @@ func NewUsageRecordWorkerPoolWithOptions(opts UsageRecordWorkerPoolOptions) *Usa
 }
 
 // Submit 提交一个使用量记录任务。
-// 提交失败（队列满）时按 overflowPolicy 执行降级策略：drop/sample/sync。
+// 提交失败（队列满）时按 overflowPolicy 执行降级策略：drop/sample/sync；
+// 启用本地溢出日志时，原本会被丢弃的任务改为写入日志，待回放任务补写入库。
 func (p *UsageRecordWorkerPool) Submit(task UsageRecordTask) UsageRecordSubmitMode {
 	if p == nil || task == nil {
 		return UsageRecordSubmitModeDropped
 	}
 	if p.pool == nil || p.pool.Stopped() {
-		p.droppedPoolStopped.Add(1)
-		p.logDrop("stopped")
-		return UsageRecordSubmitModeDropped
+		return p.dropOrJournal(task, "stopped")
 	}
 
 	_, ok := p.pool.TrySubmit(func() {
 		p.execute(task)
 	})
 	if ok {
+		p.observeQueue()
 		return UsageRecordSubmitModeEnqueued
 	}
 
 	if p.pool.Stopped() {
-		p.droppedPoolStopped.Add(1)
-		p.logDrop("stopped")
-		return UsageRecordSubmitModeDropped
+		return p.dropOrJournal(task, "stopped")
 	}
+	p.observeQueue()
 
 	switch p.overflowPolicy {
 	case config.UsageRecordOverflowPolicySync:
@@ -178,20 +203,77 @@ func (p *UsageRecordWorkerPool) Submit(task UsageRecordTask) UsageRecordSubmitMo
 		}
 	}
 
-	p.droppedQueueFull.Add(1)
-	p.logDrop("full")
+	return p.dropOrJournal(task, "full")
+}
+
+// dropOrJournal 处理无法入队的任务：有本地溢出日志时在调用方执行并落盘，否则丢弃。
+func (p *UsageRecordWorkerPool) dropOrJournal(task UsageRecordTask, reason string) UsageRecordSubmitMode {
+	if p.journal != nil {
+		p.journaled.Add(1)
+		p.executeWithContext(task, withUsageRecordJournal(context.Background(), p.journal, true))
+		return UsageRecordSubmitModeJournaled
+	}
+	if reason == "stopped" {
+		p.droppedPoolStopped.Add(1)
+	} else {
+		p.droppedQueueFull.Add(1)
+	}
+	p.logDrop(reason)
 	return UsageRecordSubmitModeDropped
 }
 
+// observeQueue 记录队列峰值，并在占用率超过背压阈值时计数与限频告警。
+func (p *UsageRecordWorkerPool) observeQueue() {
+	waiting := p.pool.WaitingTasks()
+	for {
+		peak := p.peakWaiting.Load()
+		if waiting <= peak || p.peakWaiting.CompareAndSwap(peak, waiting) {
+			break
+		}
+	}
+	if p.backpressurePercent <= 0 || p.queueSize <= 0 {
+		return
+	}
+	queuePercent := int(waiting * 100 / uint64(p.queueSize))
+	if queuePercent < p.backpressurePercent {
+		return
+	}
+	p.backpressureEvents.Add(1)
+
+	now := time.Now().UnixNano()
+	last := p.lastBackpressureNanos.Load()
+	if now-last < int64(usageRecordBackpressureLogInterval) {
+		return
+	}
+	if !p.lastBackpressureNanos.CompareAndSwap(last, now) {
+		return
+	}
+	logger.L().With(
+		zap.String("component", "service.usage_record_worker_pool"),
+		zap.Int("queue_percent", queuePercent),
+		zap.Uint64("waiting_tasks", waiting),
+		zap.Int("queue_size", p.queueSize),
+		zap.Int("max_concurrency", p.pool.MaxConcurrency()),
+		zap.Int64("running_workers", p.pool.RunningWorkers()),
+		zap.Uint64("backpressure_events", p.backpressureEvents.Load()),
+		zap.Bool("journal_enabled", p.journal != nil),
+	).Warn("usage_record.backpressure")
+}
+
 // Stats 返回当前池状态与计数器。
 func (p *UsageRecordWorkerPool) Stats() UsageRecordWorkerPoolStats {
 	if p == nil || p.pool == nil {
 		return UsageRecordWorkerPoolStats{}
 	}
+	waiting := p.pool.WaitingTasks()
+	utilization := 0
+	if p.queueSize > 0 {
+		utilization = min(100, int(waiting*100/uint64(p.queueSize)))
+	}
 	return UsageRecordWorkerPoolStats{
 		MaxConcurrency:     p.pool.MaxConcurrency(),
 		RunningWorkers:     p.pool.RunningWorkers(),
-		WaitingTasks:       p.pool.WaitingTasks(),
+		WaitingTasks:       waiting,
 		SubmittedTasks:     p.pool.SubmittedTasks(),
 		CompletedTasks:     p.pool.CompletedTasks(),
 		SuccessfulTasks:    p.pool.SuccessfulTasks(),
@@ -200,6 +282,12 @@ func (p *UsageRecordWorkerPool) Stats() UsageRecordWorkerPoolStats {
 		DroppedQueueFull:   p.droppedQueueFull.Load(),
 		DroppedPoolStopped: p.droppedPoolStopped.Load(),
 		SyncFallbackTasks:  p.syncFallback.Load(),
+
+		QueueCapacity:           p.queueSize,
+		QueueUtilizationPercent: utilization,
+		PeakWaitingTasks:        p.peakWaiting.Load(),
+		BackpressureEvents:      p.backpressureEvents.Load(),
+		JournaledTasks:          p.journaled.Load(),
 	}
 }
 
@@ -315,7 +403,11 @@ func (p *UsageRecordWorkerPool) shouldSyncFallback() bool {
 }
 
 func (p *UsageRecordWorkerPool) execute(task UsageRecordTask) {
-	ctx, cancel := context.WithTimeout(context.Background(), p.taskTimeout)
+	p.executeWithContext(task, withUsageRecordJournal(context.Background(), p.journal, false))
+}
+
+func (p *UsageRecordWorkerPool) executeWithContext(task UsageRecordTask, parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, p.taskTimeout)
 	defer cancel()
 
 	defer func() {
@@ -355,20 +447,21 @@ func (p *UsageRecordWorkerPool) logDrop(reason string) {
 
 func usageRecordPoolOptionsFromConfig(cfg *config.Config) UsageRecordWorkerPoolOptions {
 	opts := UsageRecordWorkerPoolOptions{
-		WorkerCount:           defaultUsageRecordWorkerCount,
-		QueueSize:             defaultUsageRecordQueueSize,
-		TaskTimeout:           time.Duration(defaultUsageRecordTaskTimeoutSeconds) * time.Second,
-		OverflowPolicy:        defaultUsageRecordOverflowPolicy,
-		OverflowSamplePercent: defaultUsageRecordOverflowSampleRatio,
-		AutoScaleEnabled:      defaultUsageRecordAutoScaleEnabled,
-		AutoScaleMinWorkers:   defaultUsageRecordAutoScaleMinWorkers,
-		AutoScaleMaxWorkers:   defaultUsageRecordAutoScaleMaxWorkers,
-		AutoScaleUpPercent:    defaultUsageRecordAutoScaleUpPercent,
-		AutoScaleDownPercent:  defaultUsageRecordAutoScaleDownPercent,
-		AutoScaleUpStep:       defaultUsageRecordAutoScaleUpStep,
-		AutoScaleDownStep:     defaultUsageRecordAutoScaleDownStep,
-		AutoScaleInterval:     defaultUsageRecordAutoScaleInterval,
-		AutoScaleCooldown:     defaultUsageRecordAutoScaleCooldown,
+		WorkerCount:              defaultUsageRecordWorkerCount,
+		QueueSize:                defaultUsageRecordQueueSize,
+		TaskTimeout:              time.Duration(defaultUsageRecordTaskTimeoutSeconds) * time.Second,
+		OverflowPolicy:           defaultUsageRecordOverflowPolicy,
+		OverflowSamplePercent:    defaultUsageRecordOverflowSampleRatio,
+		AutoScaleEnabled:         defaultUsageRecordAutoScaleEnabled,
+		AutoScaleMinWorkers:      defaultUsageRecordAutoScaleMinWorkers,
+		AutoScaleMaxWorkers:      defaultUsageRecordAutoScaleMaxWorkers,
+		AutoScaleUpPercent:       defaultUsageRecordAutoScaleUpPercent,
+		AutoScaleDownPercent:     defaultUsageRecordAutoScaleDownPercent,
+		AutoScaleUpStep:          defaultUsageRecordAutoScaleUpStep,
+		AutoScaleDownStep:        defaultUsageRecordAutoScaleDownStep,
+		AutoScaleInterval:        defaultUsageRecordAutoScaleInterval,
+		AutoScaleCooldown:        defaultUsageRecordAutoScaleCooldown,
+		BackpressureQueuePercent: defaultUsageRecordBackpressurePercent,
 	}
 	if cfg == nil {
 		return opts
@@ -413,6 +506,9 @@ func usageRecordPoolOptionsFromConfig(cfg *config.Config) UsageRecordWorkerPoolO
 	if cfg.Gateway.UsageRecord.AutoScaleCooldownSeconds >= 0 {
 		opts.AutoScaleCooldown = time.Duration(cfg.Gateway.UsageRecord.AutoScaleCooldownSeconds) * time.Second
 	}
+	if cfg.Gateway.UsageRecord.BackpressureQueuePercent >= 0 {
+		opts.BackpressureQueuePercent = cfg.Gateway.UsageRecord.BackpressureQueuePercent
+	}
 	return normalizeUsageRecordPoolOptions(opts)
 }
 
@@ -443,6 +539,9 @@ func normalizeUsageRecordPoolOptions(opts UsageRecordWorkerPoolOptions) UsageRec
 	if opts.OverflowPolicy == config.UsageRecordOverflowPolicySample && opts.OverflowSamplePercent == 0 {
 		opts.OverflowSamplePercent = defaultUsageRecordOverflowSampleRatio
 	}
+	if opts.BackpressureQueuePercent < 0 || opts.BackpressureQueuePercent > 100 {
+		opts.BackpressureQueuePercent = defaultUsageRecordBackpressurePercent
+	}
 	if opts.AutoScaleEnabled {
 		if opts.AutoScaleMinWorkers <= 0 {
 			opts.AutoScaleMinWorkers = defaultUsageRecordAutoScaleMinWorkers
@@ -492,5 +591,5 @@ func (m UsageRecordSubmitMode) String() string {
 }
 
 func (s UsageRecordWorkerPoolStats) String() string {
-	return fmt.Sprintf("running=%d waiting=%d submitted=%d dropped=%d", s.RunningWorkers, s.WaitingTasks, s.SubmittedTasks, s.DroppedTasks)
+	return fmt.Sprintf("running=%d waiting=%d/%d submitted=%d dropped=%d journaled=%d", s.RunningWorkers, s.WaitingTasks, s.QueueCapacity, s.SubmittedTasks, s.DroppedTasks, s.JournaledTasks)
 }
@@ -343,7 +343,7 @@ func TestNewUsageRecordWorkerPool_FromConfig(t *testing.T) {
 	cfg.Gateway.UsageRecord.OverflowPolicy = config.UsageRecordOverflowPolicyDrop
 	cfg.Gateway.UsageRecord.AutoScaleEnabled = false
 
-	pool := NewUsageRecordWorkerPool(cfg)
+	pool := NewUsageRecordWorkerPool(cfg, nil)
 	t.Cleanup(pool.Stop)
 
 	stats := pool.Stats()
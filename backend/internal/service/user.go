@@ -58,6 +58,10 @@ type User struct {
 	// 避免每请求查 DB。字段不持久化到数据库。
 	UserGroupRPMOverride *int
 
+	// OrganizationID 所属组织（nil = 不属于任何组织）；OrgRole 为组织内角色（member / admin）。
+	OrganizationID *int64
+	OrgRole        string
+
 	APIKeys       []APIKey
 	Subscriptions []UserSubscription
 }
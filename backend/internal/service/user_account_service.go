@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/Wei-Shaw/sub2api/internal/util/urlvalidator"
+)
+
+var (
+	ErrUserAccountsDisabled      = infraerrors.Forbidden("USER_ACCOUNTS_DISABLED", "linking personal accounts is disabled")
+	ErrUserAccountLimitReached   = infraerrors.Forbidden("USER_ACCOUNT_LIMIT_REACHED", "linked account limit reached")
+	ErrUserAccountPlatform       = infraerrors.BadRequest("USER_ACCOUNT_PLATFORM_NOT_ALLOWED", "platform is not allowed for linked accounts")
+	ErrUserAccountType           = infraerrors.BadRequest("USER_ACCOUNT_TYPE_NOT_ALLOWED", "account type is not allowed for linked accounts")
+	ErrUserAccountGroupsRequired = infraerrors.BadRequest("USER_ACCOUNT_GROUPS_REQUIRED", "at least one group is required")
+	ErrUserAccountGroupMismatch  = infraerrors.BadRequest("USER_ACCOUNT_GROUP_PLATFORM_MISMATCH", "group platform does not match account platform")
+	ErrUserAccountCredentials    = infraerrors.BadRequest("USER_ACCOUNT_INVALID_CREDENTIALS", "invalid account credentials")
+)
+
+// userAccountAllowedTypes 允许用户自助绑定的账号类型（需要服务端 OAuth 流程或云厂商凭证的类型由管理员创建）
+var userAccountAllowedTypes = []string{AccountTypeAPIKey, AccountTypeOAuth, AccountTypeSetupToken, AccountTypeUpstream}
+
+// UserAccountInput 用户自助绑定账号的创建/更新参数
+type UserAccountInput struct {
+	Name        string
+	Platform    string
+	Type        string
+	Credentials map[string]any
+	GroupIDs    []int64
+}
+
+// UserAccountUpdateInput 用户更新自助绑定账号的参数；nil 表示不修改
+type UserAccountUpdateInput struct {
+	Name        *string
+	Credentials map[string]any
+	GroupIDs    *[]int64
+	Schedulable *bool
+}
+
+// UserAccountService 用户自助绑定上游账号（BYO account）。
+// 绑定的账号写入 owner_user_id，调度器仅对归属用户的 API Key 使用该账号，并优先于分组内共享账号。
+type UserAccountService struct {
+	accountRepo   AccountRepository
+	apiKeyService *APIKeyService
+	cfg           *config.Config
+}
+
+// NewUserAccountService 创建用户自助账号服务
+func NewUserAccountService(accountRepo AccountRepository, apiKeyService *APIKeyService, cfg *config.Config) *UserAccountService {
+	return &UserAccountService{accountRepo: accountRepo, apiKeyService: apiKeyService, cfg: cfg}
+}
+
+func (s *UserAccountService) settings() config.GatewayUserAccountsConfig {
+	if s == nil || s.cfg == nil {
+		return config.GatewayUserAccountsConfig{}
+	}
+	return s.cfg.Gateway.UserAccounts
+}
+
+// Enabled 是否允许用户自助绑定账号
+func (s *UserAccountService) Enabled() bool {
+	return s.settings().Enabled
+}
+
+// List 列出用户自助绑定的账号
+func (s *UserAccountService) List(ctx context.Context, userID int64) ([]Account, error) {
+	accounts, err := s.accountRepo.ListByOwnerUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list user accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// Get 获取用户自助绑定的账号；非归属用户一律按不存在处理
+func (s *UserAccountService) Get(ctx context.Context, userID, accountID int64) (*Account, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil || account.OwnerUserID == nil || *account.OwnerUserID != userID {
+		return nil, ErrAccountNotFound
+	}
+	return account, nil
+}
+
+// Create 绑定用户自己的上游账号
+func (s *UserAccountService) Create(ctx context.Context, userID int64, input *UserAccountInput) (*Account, error) {
+	settings := s.settings()
+	if !settings.Enabled {
+		return nil, ErrUserAccountsDisabled
+	}
+	platform := strings.TrimSpace(input.Platform)
+	if len(settings.AllowedPlatforms) > 0 && !slices.Contains(settings.AllowedPlatforms, platform) {
+		return nil, ErrUserAccountPlatform
+	}
+	switch platform {
+	case PlatformAnthropic, PlatformOpenAI, PlatformGemini, PlatformAntigravity:
+	default:
+		return nil, ErrUserAccountPlatform
+	}
+	if !slices.Contains(userAccountAllowedTypes, input.Type) {
+		return nil, ErrUserAccountType
+	}
+	if err := s.validateCredentials(input.Type, input.Credentials); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.accountRepo.ListByOwnerUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list user accounts: %w", err)
+	}
+	if len(existing) >= settings.MaxPerUser {
+		return nil, ErrUserAccountLimitReached
+	}
+	if err := s.validateGroups(ctx, userID, platform, input.GroupIDs); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = platform + "-" + input.Type
+	}
+	owner := userID
+	account := &Account{
+		Name:               name,
+		Platform:           platform,
+		Type:               input.Type,
+		Credentials:        input.Credentials,
+		Extra:              map[string]any{},
+		Concurrency:        settings.Concurrency,
+		Status:             StatusActive,
+		Schedulable:        true,
+		AutoPauseOnExpired: true,
+		OwnerUserID:        &owner,
+	}
+	if err := s.accountRepo.Create(ctx, account); err != nil {
+		return nil, fmt.Errorf("create user account: %w", err)
+	}
+	if err := s.accountRepo.BindGroups(ctx, account.ID, input.GroupIDs); err != nil {
+		return nil, fmt.Errorf("bind user account groups: %w", err)
+	}
+	account.GroupIDs = input.GroupIDs
+	return account, nil
+}
+
+// Update 更新用户自助绑定的账号
+func (s *UserAccountService) Update(ctx context.Context, userID, accountID int64, input *UserAccountUpdateInput) (*Account, error) {
+	if !s.Enabled() {
+		return nil, ErrUserAccountsDisabled
+	}
+	account, err := s.Get(ctx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil && strings.TrimSpace(*input.Name) != "" {
+		account.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Credentials != nil {
+		if err := s.validateCredentials(account.Type, input.Credentials); err != nil {
+			return nil, err
+		}
+		account.Credentials = input.Credentials
+	}
+	if input.Schedulable != nil {
+		account.Schedulable = *input.Schedulable
+	}
+	if input.GroupIDs != nil {
+		if err := s.validateGroups(ctx, userID, account.Platform, *input.GroupIDs); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return nil, fmt.Errorf("update user account: %w", err)
+	}
+	if input.GroupIDs != nil {
+		if err := s.accountRepo.BindGroups(ctx, account.ID, *input.GroupIDs); err != nil {
+			return nil, fmt.Errorf("bind user account groups: %w", err)
+		}
+	}
+	return s.accountRepo.GetByID(ctx, account.ID)
+}
+
+// Delete 解绑用户自助绑定的账号（关闭自助绑定后仍允许删除）
+func (s *UserAccountService) Delete(ctx context.Context, userID, accountID int64) error {
+	if _, err := s.Get(ctx, userID, accountID); err != nil {
+		return err
+	}
+	return s.accountRepo.Delete(ctx, accountID)
+}
+
+// validateGroups 校验分组：用户必须有权使用，且分组平台与账号平台一致
+func (s *UserAccountService) validateGroups(ctx context.Context, userID int64, platform string, groupIDs []int64) error {
+	if len(groupIDs) == 0 {
+		return ErrUserAccountGroupsRequired
+	}
+	available, err := s.apiKeyService.GetAvailableGroups(ctx, userID)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int64]*Group, len(available))
+	for i := range available {
+		byID[available[i].ID] = &available[i]
+	}
+	for _, id := range groupIDs {
+		group, ok := byID[id]
+		if !ok {
+			return ErrGroupNotAllowed
+		}
+		if group.Platform != platform {
+			return ErrUserAccountGroupMismatch
+		}
+	}
+	return nil
+}
+
+// validateCredentials 校验凭证的必填项；所有承载上游地址的字段（base_url、base_urls）必须是公网 HTTPS 地址，
+// 防止借用户账号访问内网
+func (s *UserAccountService) validateCredentials(accountType string, credentials map[string]any) error {
+	if len(credentials) == 0 {
+		return ErrUserAccountCredentials
+	}
+	required := "access_token"
+	if accountType == AccountTypeAPIKey || accountType == AccountTypeUpstream {
+		required = "api_key"
+	}
+	if value, _ := credentials[required].(string); strings.TrimSpace(value) == "" {
+		return infraerrors.BadRequest("USER_ACCOUNT_INVALID_CREDENTIALS", required+" is required")
+	}
+	if raw, ok := credentials["base_urls"]; ok {
+		normalized, err := s.validateCredentialURLList(raw)
+		if err != nil {
+			return err
+		}
+		if len(normalized) == 0 {
+			delete(credentials, "base_urls")
+		} else {
+			credentials["base_urls"] = normalized
+		}
+	}
+	if raw, ok := credentials["base_url"]; ok {
+		baseURL, _ := raw.(string)
+		if strings.TrimSpace(baseURL) == "" && accountType != AccountTypeUpstream {
+			delete(credentials, "base_url")
+			return nil
+		}
+		normalized, err := s.validateCredentialURL(baseURL)
+		if err != nil {
+			return infraerrors.BadRequest("USER_ACCOUNT_INVALID_CREDENTIALS", "invalid base_url: "+err.Error())
+		}
+		credentials["base_url"] = normalized
+	} else if accountType == AccountTypeUpstream {
+		return infraerrors.BadRequest("USER_ACCOUNT_INVALID_CREDENTIALS", "base_url is required")
+	}
+	return nil
+}
+
+// validateCredentialURLList 校验 base_urls（字符串数组），返回去除空项后的规范化地址
+func (s *UserAccountService) validateCredentialURLList(raw any) ([]any, error) {
+	var values []string
+	switch v := raw.(type) {
+	case nil:
+	case []string:
+		values = v
+	case []any:
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, infraerrors.BadRequest("USER_ACCOUNT_INVALID_CREDENTIALS", "base_urls must be a list of strings")
+			}
+			values = append(values, str)
+		}
+	default:
+		return nil, infraerrors.BadRequest("USER_ACCOUNT_INVALID_CREDENTIALS", "base_urls must be a list of strings")
+	}
+	normalized := make([]any, 0, len(values))
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		u, err := s.validateCredentialURL(value)
+		if err != nil {
+			return nil, infraerrors.BadRequest("USER_ACCOUNT_INVALID_CREDENTIALS", "invalid base_urls: "+err.Error())
+		}
+		normalized = append(normalized, u)
+	}
+	return normalized, nil
+}
+
+// validateCredentialURL 按上游地址规则校验单个 URL：公网 HTTPS，开启白名单时须命中上游白名单
+func (s *UserAccountService) validateCredentialURL(raw string) (string, error) {
+	opts := urlvalidator.ValidationOptions{}
+	if s.cfg != nil && s.cfg.Security.URLAllowlist.Enabled {
+		opts.AllowedHosts = s.cfg.Security.URLAllowlist.UpstreamHosts
+		opts.RequireAllowlist = true
+	}
+	return urlvalidator.ValidateHTTPSURL(raw, opts)
+}
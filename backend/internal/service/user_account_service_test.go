@@ -0,0 +1,56 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Wei-Shaw/sub2api/internal/config"
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAccountService_ValidateCredentials(t *testing.T) {
+	svc := NewUserAccountService(nil, nil, &config.Config{})
+
+	require.Error(t, svc.validateCredentials(AccountTypeAPIKey, nil))
+	require.Error(t, svc.validateCredentials(AccountTypeAPIKey, map[string]any{"api_key": " "}))
+	require.NoError(t, svc.validateCredentials(AccountTypeAPIKey, map[string]any{"api_key": "sk-test"}))
+	require.Error(t, svc.validateCredentials(AccountTypeOAuth, map[string]any{"api_key": "sk-test"}))
+	require.NoError(t, svc.validateCredentials(AccountTypeOAuth, map[string]any{"access_token": "tok"}))
+
+	// 自定义 base_url 必须为公网 HTTPS
+	err := svc.validateCredentials(AccountTypeAPIKey, map[string]any{"api_key": "sk", "base_url": "http://example.com"})
+	require.True(t, infraerrors.IsBadRequest(err))
+	err = svc.validateCredentials(AccountTypeAPIKey, map[string]any{"api_key": "sk", "base_url": "https://127.0.0.1"})
+	require.True(t, infraerrors.IsBadRequest(err))
+	require.NoError(t, svc.validateCredentials(AccountTypeAPIKey, map[string]any{"api_key": "sk", "base_url": "https://api.example.com"}))
+
+	require.Error(t, svc.validateCredentials(AccountTypeUpstream, map[string]any{"api_key": "sk"}))
+
+	// 备用区域 base_urls 同样必须为公网 HTTPS，即使未设置 base_url
+	err = svc.validateCredentials(AccountTypeAPIKey, map[string]any{"api_key": "sk", "base_url": "https://api.example.com", "base_urls": []any{"https://eu.example.com", "https://10.0.0.5"}})
+	require.True(t, infraerrors.IsBadRequest(err))
+	err = svc.validateCredentials(AccountTypeAPIKey, map[string]any{"api_key": "sk", "base_urls": []any{"https://localhost"}})
+	require.True(t, infraerrors.IsBadRequest(err))
+	err = svc.validateCredentials(AccountTypeAPIKey, map[string]any{"api_key": "sk", "base_urls": "https://eu.example.com"})
+	require.True(t, infraerrors.IsBadRequest(err))
+	creds := map[string]any{"api_key": "sk", "base_url": "https://api.example.com", "base_urls": []any{"https://eu.example.com/", " "}}
+	require.NoError(t, svc.validateCredentials(AccountTypeAPIKey, creds))
+	require.Equal(t, []any{"https://eu.example.com"}, creds["base_urls"])
+}
+
+func TestUserAccountService_CreateRejectsWhenDisabledOrNotAllowed(t *testing.T) {
+	svc := NewUserAccountService(nil, nil, &config.Config{})
+	_, err := svc.Create(context.Background(), 1, &UserAccountInput{Platform: PlatformOpenAI, Type: AccountTypeAPIKey})
+	require.ErrorIs(t, err, ErrUserAccountsDisabled)
+
+	cfg := &config.Config{}
+	cfg.Gateway.UserAccounts = config.GatewayUserAccountsConfig{Enabled: true, MaxPerUser: 1, Concurrency: 1, AllowedPlatforms: []string{PlatformOpenAI}}
+	svc = NewUserAccountService(nil, nil, cfg)
+	_, err = svc.Create(context.Background(), 1, &UserAccountInput{Platform: PlatformAnthropic, Type: AccountTypeAPIKey})
+	require.ErrorIs(t, err, ErrUserAccountPlatform)
+	_, err = svc.Create(context.Background(), 1, &UserAccountInput{Platform: PlatformOpenAI, Type: AccountTypeBedrock})
+	require.ErrorIs(t, err, ErrUserAccountType)
+}
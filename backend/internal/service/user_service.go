@@ -70,6 +70,8 @@ type UserListFilters struct {
 	Search     string           // Search in email, username
 	GroupName  string           // Filter by allowed group name (fuzzy match)
 	Attributes map[int64]string // Custom attribute filters: attributeID -> value
+	// OrganizationID restricts results to members of the organization (nil = no filter).
+	OrganizationID *int64
 	// IncludeSubscriptions controls whether ListWithFilters should load active subscriptions.
 	// For large datasets this can be expensive; admin list pages should enable it on demand.
 	// nil means not specified (default: load subscriptions for backward compatibility).
@@ -1110,6 +1112,9 @@ func (s *UserService) Delete(ctx context.Context, userID int64) error {
 
 // SendNotifyEmailCode sends a verification code to the extra notification email.
 func (s *UserService) SendNotifyEmailCode(ctx context.Context, userID int64, email string, emailService *EmailService, cache EmailCache) error {
+	if cache == nil {
+		return ErrEmailCacheUnavailable
+	}
 	if err := checkNotifyCodeRateLimit(ctx, cache, userID, email); err != nil {
 		return err
 	}
@@ -1181,6 +1186,9 @@ func (s *UserService) sendNotifyVerifyEmail(ctx context.Context, emailService *E
 
 // VerifyAndAddNotifyEmail verifies the code and adds the email to user's extra emails.
 func (s *UserService) VerifyAndAddNotifyEmail(ctx context.Context, userID int64, email, code string, cache EmailCache) error {
+	if cache == nil {
+		return ErrEmailCacheUnavailable
+	}
 	if err := verifyNotifyCode(ctx, cache, email, code); err != nil {
 		return err
 	}
@@ -0,0 +1,466 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	infraerrors "github.com/Wei-Shaw/sub2api/internal/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// Webhook event types
+const (
+	WebhookEventAccountError          = "account.error"
+	WebhookEventAccountRateLimited    = "account.rate_limited"
+	WebhookEventAccountQuotaThreshold = "account.quota_threshold"
+	WebhookEventAccountRecovered      = "account.recovered"
+	WebhookEventUsageAnomaly          = "usage.anomaly"
+	WebhookEventTest                  = "webhook.test"
+)
+
+// Webhook payload formats
+const (
+	WebhookFormatJSON    = "json"
+	WebhookFormatSlack   = "slack"
+	WebhookFormatDiscord = "discord"
+)
+
+const (
+	webhookDeliveryTimeout   = 10 * time.Second
+	webhookMaxAttempts       = 3
+	webhookConfigCacheTTL    = 30 * time.Second
+	webhookDedupeWindow      = 5 * time.Minute
+	webhookMaxEndpoints      = 20
+	webhookSignatureHeader   = "X-Sub2API-Signature"
+	webhookTimestampHeader   = "X-Sub2API-Timestamp"
+	webhookEventHeader       = "X-Sub2API-Event"
+	webhookDeliveryIDHeader  = "X-Sub2API-Delivery"
+	webhookResponseBodyLimit = 4 << 10
+)
+
+// webhookQuotaThresholds 账号配额触发 account.quota_threshold 的用量百分比
+var webhookQuotaThresholds = []float64{80, 100}
+
+// webhookEventTypes 可订阅的事件类型（webhook.test 仅用于手动测试，不可订阅）
+var webhookEventTypes = []string{
+	WebhookEventAccountError,
+	WebhookEventAccountRateLimited,
+	WebhookEventAccountQuotaThreshold,
+	WebhookEventAccountRecovered,
+	WebhookEventUsageAnomaly,
+}
+
+// ErrWebhookEndpointNotFound 测试推送时指定的接收端不存在
+var ErrWebhookEndpointNotFound = infraerrors.NotFound("WEBHOOK_ENDPOINT_NOT_FOUND", "webhook endpoint not found")
+
+// WebhookConfig 存储在 settings 表中的 Webhook 配置（JSON）
+type WebhookConfig struct {
+	Enabled   bool              `json:"enabled"`
+	Endpoints []WebhookEndpoint `json:"endpoints"`
+}
+
+// WebhookEndpoint 单个 Webhook 接收端
+type WebhookEndpoint struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Enabled bool   `json:"enabled"`
+	// Format 请求体格式：json（默认，完整事件）/slack/discord（适配对应的 incoming webhook）
+	Format string `json:"format"`
+	// Secret HMAC-SHA256 签名密钥，为空时不签名
+	Secret string `json:"secret"`
+	// Events 订阅的事件类型，为空表示订阅全部
+	Events []string `json:"events"`
+}
+
+// subscribes 判断接收端是否订阅了指定事件
+func (e WebhookEndpoint) subscribes(eventType string) bool {
+	if eventType == WebhookEventTest || len(e.Events) == 0 {
+		return true
+	}
+	for _, ev := range e.Events {
+		if ev == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent 对外推送的事件
+type WebhookEvent struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	CreatedAt time.Time      `json:"created_at"`
+	Summary   string         `json:"summary"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
+// WebhookService 管理员可配置的 Webhook 通知：账号进入错误/限流状态、配额达到阈值或恢复时推送事件，
+// 供运维在 Slack/Discord 等渠道接收告警而无需轮询仪表盘。投递异步进行，失败时有限重试。
+type WebhookService struct {
+	settingRepo SettingRepository
+	client      *http.Client
+	nowFn       func() time.Time
+
+	cfgMu       sync.Mutex
+	cfgCache    *WebhookConfig
+	cfgCachedAt time.Time
+
+	dedupeMu sync.Mutex
+	dedupe   map[string]time.Time
+}
+
+// NewWebhookService creates a new WebhookService.
+func NewWebhookService(settingRepo SettingRepository) *WebhookService {
+	return &WebhookService{
+		settingRepo: settingRepo,
+		client:      &http.Client{Timeout: webhookDeliveryTimeout},
+		nowFn:       time.Now,
+		dedupe:      make(map[string]time.Time),
+	}
+}
+
+// GetConfig 读取 Webhook 配置；未配置时返回关闭状态的空配置。
+func (s *WebhookService) GetConfig(ctx context.Context) (*WebhookConfig, error) {
+	if s == nil || s.settingRepo == nil {
+		return &WebhookConfig{}, nil
+	}
+	raw, err := s.settingRepo.GetValue(ctx, SettingKeyWebhookConfig)
+	if err != nil {
+		if errors.Is(err, ErrSettingNotFound) {
+			return &WebhookConfig{}, nil
+		}
+		return nil, err
+	}
+	cfg := &WebhookConfig{}
+	if err := json.Unmarshal([]byte(raw), cfg); err != nil {
+		// 损坏的 JSON 不应影响管理页面，按未配置处理
+		return &WebhookConfig{}, nil
+	}
+	normalizeWebhookConfig(cfg)
+	return cfg, nil
+}
+
+// UpdateConfig 校验并保存 Webhook 配置。
+func (s *WebhookService) UpdateConfig(ctx context.Context, cfg *WebhookConfig) (*WebhookConfig, error) {
+	if s == nil || s.settingRepo == nil {
+		return nil, errors.New("setting repository not initialized")
+	}
+	if cfg == nil {
+		return nil, infraerrors.BadRequest("INVALID_WEBHOOK_CONFIG", "invalid request")
+	}
+	normalizeWebhookConfig(cfg)
+	if err := validateWebhookConfig(cfg); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.settingRepo.Set(ctx, SettingKeyWebhookConfig, string(raw)); err != nil {
+		return nil, err
+	}
+	s.cfgMu.Lock()
+	s.cfgCache = nil
+	s.cfgMu.Unlock()
+	return cfg, nil
+}
+
+// SendTest 同步向指定接收端发送一条测试事件，返回投递错误（不重试）。
+func (s *WebhookService) SendTest(ctx context.Context, index int) error {
+	cfg, err := s.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(cfg.Endpoints) {
+		return ErrWebhookEndpointNotFound
+	}
+	event := s.newEvent(WebhookEventTest, "Sub2API webhook test event", nil)
+	return s.deliver(ctx, cfg.Endpoints[index], event)
+}
+
+func normalizeWebhookConfig(cfg *WebhookConfig) {
+	for i := range cfg.Endpoints {
+		ep := &cfg.Endpoints[i]
+		ep.Name = strings.TrimSpace(ep.Name)
+		ep.URL = strings.TrimSpace(ep.URL)
+		ep.Format = strings.ToLower(strings.TrimSpace(ep.Format))
+		if ep.Format == "" {
+			ep.Format = WebhookFormatJSON
+		}
+		events := make([]string, 0, len(ep.Events))
+		for _, ev := range ep.Events {
+			if ev = strings.TrimSpace(ev); ev != "" {
+				events = append(events, ev)
+			}
+		}
+		ep.Events = events
+	}
+}
+
+func validateWebhookConfig(cfg *WebhookConfig) error {
+	if len(cfg.Endpoints) > webhookMaxEndpoints {
+		return infraerrors.BadRequest("INVALID_WEBHOOK_CONFIG", fmt.Sprintf("at most %d webhook endpoints are allowed", webhookMaxEndpoints))
+	}
+	for i, ep := range cfg.Endpoints {
+		u, err := url.Parse(ep.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return infraerrors.BadRequest("INVALID_WEBHOOK_CONFIG", fmt.Sprintf("endpoints[%d].url must be an absolute http(s) URL", i))
+		}
+		switch ep.Format {
+		case WebhookFormatJSON, WebhookFormatSlack, WebhookFormatDiscord:
+		default:
+			return infraerrors.BadRequest("INVALID_WEBHOOK_CONFIG", fmt.Sprintf("endpoints[%d].format must be one of json, slack, discord", i))
+		}
+		for _, ev := range ep.Events {
+			if !isWebhookEventType(ev) {
+				return infraerrors.BadRequest("INVALID_WEBHOOK_CONFIG", fmt.Sprintf("endpoints[%d].events contains unknown event %q", i, ev))
+			}
+		}
+	}
+	return nil
+}
+
+func isWebhookEventType(eventType string) bool {
+	for _, ev := range webhookEventTypes {
+		if ev == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyAccountError 账号进入错误状态（停止调度）
+func (s *WebhookService) NotifyAccountError(account *Account, errorMsg string) {
+	if account == nil {
+		return
+	}
+	s.Dispatch(WebhookEventAccountError, account.ID,
+		fmt.Sprintf("Account #%d %s (%s) entered error state: %s", account.ID, account.Name, account.Platform, errorMsg),
+		map[string]any{
+			"account_id":   account.ID,
+			"account_name": account.Name,
+			"platform":     account.Platform,
+			"error":        errorMsg,
+		})
+}
+
+// NotifyAccountRateLimited 账号被上游限流
+func (s *WebhookService) NotifyAccountRateLimited(account *Account, resetAt time.Time) {
+	if account == nil {
+		return
+	}
+	s.Dispatch(WebhookEventAccountRateLimited, account.ID,
+		fmt.Sprintf("Account #%d %s (%s) is rate limited until %s", account.ID, account.Name, account.Platform, resetAt.UTC().Format(time.RFC3339)),
+		map[string]any{
+			"account_id":          account.ID,
+			"account_name":        account.Name,
+			"platform":            account.Platform,
+			"rate_limit_reset_at": resetAt.UTC(),
+		})
+}
+
+// NotifyAccountRecovered 账号从错误/限流状态恢复
+func (s *WebhookService) NotifyAccountRecovered(account *Account, clearedError, clearedRateLimit bool) {
+	if account == nil {
+		return
+	}
+	s.Dispatch(WebhookEventAccountRecovered, account.ID,
+		fmt.Sprintf("Account #%d %s (%s) recovered", account.ID, account.Name, account.Platform),
+		map[string]any{
+			"account_id":         account.ID,
+			"account_name":       account.Name,
+			"platform":           account.Platform,
+			"cleared_error":      clearedError,
+			"cleared_rate_limit": clearedRateLimit,
+		})
+}
+
+// CheckAccountQuota 账号配额扣减后检查各维度是否跨越 80%/100% 阈值。
+// quotaState 非空时使用事务返回的用量，否则使用账号快照。
+func (s *WebhookService) CheckAccountQuota(account *Account, cost float64, quotaState *AccountQuotaState) {
+	if s == nil || account == nil || cost <= 0 {
+		return
+	}
+	var dims []quotaDim
+	if quotaState != nil {
+		dims = buildQuotaDimsFromState(account, quotaState)
+	} else {
+		dims = buildQuotaDims(account)
+	}
+	for _, dim := range dims {
+		if dim.limit <= 0 {
+			continue
+		}
+		oldUsed := dim.currentUsed - cost
+		for _, pct := range webhookQuotaThresholds {
+			trigger := dim.limit * pct / 100
+			if oldUsed >= trigger || dim.currentUsed < trigger {
+				continue
+			}
+			s.Dispatch(WebhookEventAccountQuotaThreshold, account.ID,
+				fmt.Sprintf("Account #%d %s (%s) reached %.0f%% of its %s quota ($%.2f / $%.2f)", account.ID, account.Name, account.Platform, pct, dim.name, dim.currentUsed, dim.limit),
+				map[string]any{
+					"account_id":        account.ID,
+					"account_name":      account.Name,
+					"platform":          account.Platform,
+					"dimension":         dim.name,
+					"threshold_percent": pct,
+					"used":              dim.currentUsed,
+					"limit":             dim.limit,
+				})
+		}
+	}
+}
+
+// Dispatch 异步推送事件到所有订阅的接收端。dedupeID > 0 时同一事件类型在去重窗口内只推送一次
+// （账号限流/错误可能在短时间内被多次触发）；配额阈值事件本身只在跨越时触发，由 data 区分维度。
+func (s *WebhookService) Dispatch(eventType string, dedupeID int64, summary string, data map[string]any) {
+	if s == nil {
+		return
+	}
+	if eventType != WebhookEventAccountQuotaThreshold && dedupeID > 0 && !s.markDispatched(eventType+":"+strconv.FormatInt(dedupeID, 10)) {
+		return
+	}
+	event := s.newEvent(eventType, summary, data)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("panic in webhook dispatch", "recover", r)
+			}
+		}()
+		ctx := context.Background()
+		cfg := s.loadConfig(ctx)
+		if cfg == nil || !cfg.Enabled {
+			return
+		}
+		for _, ep := range cfg.Endpoints {
+			if !ep.Enabled || !ep.subscribes(event.Type) {
+				continue
+			}
+			s.deliverWithRetry(ctx, ep, event)
+		}
+	}()
+}
+
+func (s *WebhookService) newEvent(eventType, summary string, data map[string]any) *WebhookEvent {
+	return &WebhookEvent{
+		ID:        "evt_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Type:      eventType,
+		CreatedAt: s.nowFn().UTC(),
+		Summary:   summary,
+		Data:      data,
+	}
+}
+
+// markDispatched 记录一次推送，返回 false 表示仍在去重窗口内
+func (s *WebhookService) markDispatched(key string) bool {
+	now := s.nowFn()
+	s.dedupeMu.Lock()
+	defer s.dedupeMu.Unlock()
+	if last, ok := s.dedupe[key]; ok && now.Sub(last) < webhookDedupeWindow {
+		return false
+	}
+	// 顺带清理过期条目，避免 map 无限增长
+	for k, t := range s.dedupe {
+		if now.Sub(t) >= webhookDedupeWindow {
+			delete(s.dedupe, k)
+		}
+	}
+	s.dedupe[key] = now
+	return true
+}
+
+// loadConfig 读取带短期缓存的配置，避免每个事件都访问数据库
+func (s *WebhookService) loadConfig(ctx context.Context) *WebhookConfig {
+	s.cfgMu.Lock()
+	defer s.cfgMu.Unlock()
+	if s.cfgCache != nil && s.nowFn().Sub(s.cfgCachedAt) < webhookConfigCacheTTL {
+		return s.cfgCache
+	}
+	cfg, err := s.GetConfig(ctx)
+	if err != nil {
+		slog.Warn("webhook_config_load_failed", "error", err)
+		return s.cfgCache
+	}
+	s.cfgCache = cfg
+	s.cfgCachedAt = s.nowFn()
+	return cfg
+}
+
+func (s *WebhookService) deliverWithRetry(ctx context.Context, ep WebhookEndpoint, event *WebhookEvent) {
+	var err error
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		if err = s.deliver(ctx, ep, event); err == nil {
+			return
+		}
+		if attempt < webhookMaxAttempts {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+	slog.Warn("webhook_delivery_failed", "endpoint", ep.Name, "event", event.Type, "event_id", event.ID, "attempts", webhookMaxAttempts, "error", err)
+}
+
+func (s *WebhookService) deliver(ctx context.Context, ep WebhookEndpoint, event *WebhookEvent) error {
+	body, err := buildWebhookBody(ep.Format, event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(event.CreatedAt.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, event.Type)
+	req.Header.Set(webhookDeliveryIDHeader, event.ID)
+	req.Header.Set(webhookTimestampHeader, timestamp)
+	if ep.Secret != "" {
+		req.Header.Set(webhookSignatureHeader, "sha256="+signWebhookPayload(ep.Secret, timestamp, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned status %d: %s", resp.StatusCode, truncateForLog(respBody, 256))
+	}
+	return nil
+}
+
+// signWebhookPayload 计算签名：hex(HMAC-SHA256(secret, "<timestamp>.<body>"))。
+// 接收端应校验时间戳新鲜度以防重放。
+func signWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func buildWebhookBody(format string, event *WebhookEvent) ([]byte, error) {
+	switch format {
+	case WebhookFormatSlack:
+		return json.Marshal(map[string]string{"text": "[" + event.Type + "] " + event.Summary})
+	case WebhookFormatDiscord:
+		return json.Marshal(map[string]string{"content": "[" + event.Type + "] " + event.Summary})
+	default:
+		return json.Marshal(event)
+	}
+}
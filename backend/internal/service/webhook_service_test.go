@@ -0,0 +1,147 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type webhookSettingRepoStub struct {
+	SettingRepository
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (r *webhookSettingRepoStub) GetValue(_ context.Context, key string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.values[key]
+	if !ok {
+		return "", ErrSettingNotFound
+	}
+	return v, nil
+}
+
+func (r *webhookSettingRepoStub) Set(_ context.Context, key, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[key] = value
+	return nil
+}
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	received chan struct{}
+}
+
+func newWebhookReceiver(t *testing.T) (*webhookReceiver, *httptest.Server) {
+	rec := &webhookReceiver{received: make(chan struct{}, 16)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, r)
+		rec.bodies = append(rec.bodies, body)
+		rec.mu.Unlock()
+		rec.received <- struct{}{}
+	}))
+	t.Cleanup(srv.Close)
+	return rec, srv
+}
+
+func (r *webhookReceiver) wait(t *testing.T) {
+	select {
+	case <-r.received:
+	case <-time.After(3 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
+
+func TestWebhookService_UpdateConfigValidation(t *testing.T) {
+	svc := NewWebhookService(&webhookSettingRepoStub{values: map[string]string{}})
+
+	_, err := svc.UpdateConfig(context.Background(), &WebhookConfig{Endpoints: []WebhookEndpoint{{URL: "ftp://example.com"}}})
+	require.Error(t, err)
+	_, err = svc.UpdateConfig(context.Background(), &WebhookConfig{Endpoints: []WebhookEndpoint{{URL: "https://example.com", Format: "teams"}}})
+	require.Error(t, err)
+	_, err = svc.UpdateConfig(context.Background(), &WebhookConfig{Endpoints: []WebhookEndpoint{{URL: "https://example.com", Events: []string{"account.deleted"}}}})
+	require.Error(t, err)
+
+	cfg, err := svc.UpdateConfig(context.Background(), &WebhookConfig{Enabled: true, Endpoints: []WebhookEndpoint{{URL: " https://example.com/hook ", Events: []string{" account.error "}}}})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/hook", cfg.Endpoints[0].URL)
+	require.Equal(t, WebhookFormatJSON, cfg.Endpoints[0].Format)
+	require.Equal(t, []string{WebhookEventAccountError}, cfg.Endpoints[0].Events)
+}
+
+func TestWebhookService_DispatchSignsAndFilters(t *testing.T) {
+	rec, srv := newWebhookReceiver(t)
+	svc := NewWebhookService(&webhookSettingRepoStub{values: map[string]string{}})
+	_, err := svc.UpdateConfig(context.Background(), &WebhookConfig{Enabled: true, Endpoints: []WebhookEndpoint{
+		{Name: "ops", URL: srv.URL, Enabled: true, Secret: "s3cret", Events: []string{WebhookEventAccountRateLimited}},
+	}})
+	require.NoError(t, err)
+
+	account := &Account{ID: 7, Name: "claude-1", Platform: PlatformAnthropic}
+	// 未订阅的事件不投递
+	svc.NotifyAccountError(account, "401 unauthorized")
+	svc.NotifyAccountRateLimited(account, time.Unix(1700000000, 0))
+	rec.wait(t)
+
+	rec.mu.Lock()
+	req, body := rec.requests[0], rec.bodies[0]
+	rec.mu.Unlock()
+	require.Equal(t, WebhookEventAccountRateLimited, req.Header.Get(webhookEventHeader))
+	ts := req.Header.Get(webhookTimestampHeader)
+	require.Equal(t, "sha256="+signWebhookPayload("s3cret", ts, body), req.Header.Get(webhookSignatureHeader))
+
+	var event WebhookEvent
+	require.NoError(t, json.Unmarshal(body, &event))
+	require.Equal(t, WebhookEventAccountRateLimited, event.Type)
+	require.EqualValues(t, 7, event.Data["account_id"])
+
+	// 去重窗口内重复触发不再投递
+	svc.NotifyAccountRateLimited(account, time.Unix(1700000000, 0))
+	select {
+	case <-rec.received:
+		t.Fatal("duplicate event delivered within dedupe window")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWebhookService_CheckAccountQuotaThresholds(t *testing.T) {
+	rec, srv := newWebhookReceiver(t)
+	svc := NewWebhookService(&webhookSettingRepoStub{values: map[string]string{}})
+	_, err := svc.UpdateConfig(context.Background(), &WebhookConfig{Enabled: true, Endpoints: []WebhookEndpoint{
+		{URL: srv.URL, Enabled: true, Format: WebhookFormatSlack},
+	}})
+	require.NoError(t, err)
+
+	account := &Account{ID: 9, Name: "key-acct", Platform: PlatformOpenAI}
+	// 75 -> 85：跨越 80%
+	svc.CheckAccountQuota(account, 10, &AccountQuotaState{TotalUsed: 85, TotalLimit: 100})
+	rec.wait(t)
+	rec.mu.Lock()
+	var slack map[string]string
+	require.NoError(t, json.Unmarshal(rec.bodies[0], &slack))
+	rec.mu.Unlock()
+	require.Contains(t, slack["text"], "reached 80% of its total quota")
+
+	// 85 -> 90：未跨越新阈值
+	svc.CheckAccountQuota(account, 5, &AccountQuotaState{TotalUsed: 90, TotalLimit: 100})
+	select {
+	case <-rec.received:
+		t.Fatal("unexpected quota event")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
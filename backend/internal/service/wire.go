@@ -39,9 +39,17 @@ func ProvideEmailQueueService(emailService *EmailService) *EmailQueueService {
 	return NewEmailQueueService(emailService, 3)
 }
 
-// ProvideOAuthRefreshAPI creates OAuthRefreshAPI with the default lock TTL.
-func ProvideOAuthRefreshAPI(accountRepo AccountRepository, tokenCache GeminiTokenCache) *OAuthRefreshAPI {
-	return NewOAuthRefreshAPI(accountRepo, tokenCache)
+// ProvideOAuthRefreshAPI creates OAuthRefreshAPI with the configured lock TTL and peer-wait policy.
+func ProvideOAuthRefreshAPI(accountRepo AccountRepository, tokenCache GeminiTokenCache, cfg *config.Config) *OAuthRefreshAPI {
+	if cfg == nil {
+		return NewOAuthRefreshAPI(accountRepo, tokenCache)
+	}
+	api := NewOAuthRefreshAPI(accountRepo, tokenCache, time.Duration(cfg.TokenRefresh.LockTTLSeconds)*time.Second)
+	api.SetPeerWait(
+		time.Duration(cfg.TokenRefresh.PeerWaitTimeoutMs)*time.Millisecond,
+		time.Duration(cfg.TokenRefresh.PeerWaitPollIntervalMs)*time.Millisecond,
+	)
+	return api
 }
 
 // ProvideTokenRefreshService creates and starts TokenRefreshService
@@ -156,6 +164,13 @@ func ProvideSubscriptionExpiryService(userSubRepo UserSubscriptionRepository) *S
 	return svc
 }
 
+// ProvideChildTokenExpiryService creates and starts ChildTokenExpiryService.
+func ProvideChildTokenExpiryService(apiKeyService *APIKeyService) *ChildTokenExpiryService {
+	svc := NewChildTokenExpiryService(apiKeyService, time.Minute)
+	svc.Start()
+	return svc
+}
+
 // ProvideTimingWheelService creates and starts TimingWheelService
 func ProvideTimingWheelService() (*TimingWheelService, error) {
 	svc, err := NewTimingWheelService()
@@ -166,6 +181,34 @@ func ProvideTimingWheelService() (*TimingWheelService, error) {
 	return svc, nil
 }
 
+// ProvideUsageRecordJournalReplayer 创建并启动使用量记录本地溢出日志回放任务
+func ProvideUsageRecordJournalReplayer(journal *UsageRecordJournal, billingRepo UsageBillingRepository, usageLogRepo UsageLogRepository, billingCache *BillingCacheService, dbHealth *BillingDBHealthService, cfg *config.Config) *UsageRecordJournalReplayer {
+	svc := NewUsageRecordJournalReplayer(journal, billingRepo, usageLogRepo, billingCache, dbHealth, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideUsageEventExporter creates UsageEventExporter, injects it into the gateways and journal replayer, and starts it.
+// Returns nil when usage_export is disabled.
+func ProvideUsageEventExporter(
+	publisher UsageEventPublisher,
+	deadLetterRepo UsageEventDeadLetterRepository,
+	cfg *config.Config,
+	gatewayService *GatewayService,
+	openAIGatewayService *OpenAIGatewayService,
+	journalReplayer *UsageRecordJournalReplayer,
+) *UsageEventExporter {
+	exporter := NewUsageEventExporter(publisher, deadLetterRepo, cfg)
+	if exporter == nil {
+		return nil
+	}
+	gatewayService.SetUsageEventExporter(exporter)
+	openAIGatewayService.SetUsageEventExporter(exporter)
+	journalReplayer.SetUsageEventExporter(exporter)
+	exporter.Start()
+	return exporter
+}
+
 // ProvideDeferredService creates and starts DeferredService
 func ProvideDeferredService(accountRepo AccountRepository, timingWheel *TimingWheelService) *DeferredService {
 	svc := NewDeferredService(accountRepo, timingWheel, 10*time.Second)
@@ -173,14 +216,19 @@ func ProvideDeferredService(accountRepo AccountRepository, timingWheel *TimingWh
 	return svc
 }
 
-// ProvideConcurrencyService creates ConcurrencyService and starts slot cleanup worker.
+// ProvideConcurrencyService creates ConcurrencyService and starts slot cleanup and stuck-slot reaper workers.
 func ProvideConcurrencyService(cache ConcurrencyCache, accountRepo AccountRepository, cfg *config.Config) *ConcurrencyService {
 	svc := NewConcurrencyService(cache)
 	if err := svc.CleanupStaleProcessSlots(context.Background()); err != nil {
 		logger.LegacyPrintf("service.concurrency", "Warning: startup cleanup stale process slots failed: %v", err)
 	}
 	if cfg != nil {
+		svc.SetPriorityQueueEnabled(cfg.Gateway.PriorityQueue.Enabled)
 		svc.StartSlotCleanupWorker(accountRepo, cfg.Gateway.Scheduling.SlotCleanupInterval)
+		svc.StartSlotReaper(
+			time.Duration(cfg.Concurrency.MaxSlotHoldSeconds)*time.Second,
+			time.Duration(cfg.Concurrency.SlotReaperIntervalSeconds)*time.Second,
+		)
 	}
 	return svc
 }
@@ -201,8 +249,10 @@ func ProvideSchedulerSnapshotService(
 	accountRepo AccountRepository,
 	groupRepo GroupRepository,
 	cfg *config.Config,
+	rotationService *AccountRotationService,
 ) *SchedulerSnapshotService {
 	svc := NewSchedulerSnapshotService(cache, outboxRepo, accountRepo, groupRepo, cfg)
+	svc.SetAccountRotationService(rotationService)
 	svc.Start()
 	return svc
 }
@@ -218,12 +268,16 @@ func ProvideRateLimitService(
 	openAI403CounterCache OpenAI403CounterCache,
 	settingService *SettingService,
 	tokenCacheInvalidator TokenCacheInvalidator,
+	webhookService *WebhookService,
+	timingWheel *TimingWheelService,
 ) *RateLimitService {
 	svc := NewRateLimitService(accountRepo, usageRepo, cfg, geminiQuotaService, tempUnschedCache)
 	svc.SetTimeoutCounterCache(timeoutCounterCache)
 	svc.SetOpenAI403CounterCache(openAI403CounterCache)
 	svc.SetSettingService(settingService)
 	svc.SetTokenCacheInvalidator(tokenCacheInvalidator)
+	svc.SetWebhookService(webhookService)
+	svc.SetTimingWheel(timingWheel)
 	return svc
 }
 
@@ -329,6 +383,20 @@ func ProvideIdempotencyCleanupService(repo IdempotencyRepository, cfg *config.Co
 	return svc
 }
 
+// ProvideUsageAnomalyService creates and starts UsageAnomalyService.
+func ProvideUsageAnomalyService(repo UsageAnomalyRepository, webhookService *WebhookService, redisClient *redis.Client, cfg *config.Config) *UsageAnomalyService {
+	svc := NewUsageAnomalyService(repo, webhookService, redisClient, cfg)
+	svc.Start()
+	return svc
+}
+
+// ProvideErrorPassthroughService creates ErrorPassthroughService and starts its hit-count/expiry maintenance loop.
+func ProvideErrorPassthroughService(repo ErrorPassthroughRepository, cache ErrorPassthroughCache) *ErrorPassthroughService {
+	svc := NewErrorPassthroughService(repo, cache)
+	svc.Start()
+	return svc
+}
+
 // ProvideScheduledTestService creates ScheduledTestService.
 func ProvideScheduledTestService(
 	planRepo ScheduledTestPlanRepository,
@@ -399,9 +467,21 @@ func ProvideBillingCacheService(
 	apiKeyRepo APIKeyRepository,
 	rpmCache UserRPMCache,
 	rateRepo UserGroupRateRepository,
+	orgRepo OrganizationRepository,
+	dbHealth *BillingDBHealthService,
 	cfg *config.Config,
 ) *BillingCacheService {
-	return NewBillingCacheService(cache, userRepo, subRepo, apiKeyRepo, rpmCache, rateRepo, cfg)
+	svc := NewBillingCacheService(cache, userRepo, subRepo, apiKeyRepo, rpmCache, rateRepo, cfg)
+	svc.SetOrganizationRepository(orgRepo)
+	svc.SetBillingDBHealth(dbHealth)
+	return svc
+}
+
+// ProvideBillingDBHealthService 创建并启动计费数据库健康探测
+func ProvideBillingDBHealthService(pinger BillingDBPinger, cfg *config.Config) *BillingDBHealthService {
+	svc := NewBillingDBHealthService(pinger, cfg)
+	svc.Start()
+	return svc
 }
 
 // ProvideAPIKeyService wires APIKeyService and connects rate-limit cache invalidation.
@@ -420,6 +500,30 @@ func ProvideAPIKeyService(
 	return svc
 }
 
+// ProvideAdminAuditService wires AdminAuditService with snapshot loaders for audited resources.
+// 资源名与 /api/v1/admin/<resource> 路由段一致；未注册的资源只记录请求体与响应。
+func ProvideAdminAuditService(
+	repo AdminAuditRepository,
+	userRepo UserRepository,
+	adminService AdminService,
+	apiKeyService *APIKeyService,
+	channelService *ChannelService,
+	organizationService *OrganizationService,
+	settingService *SettingService,
+) *AdminAuditService {
+	svc := NewAdminAuditService(repo, userRepo)
+	svc.RegisterSnapshotLoader("users", func(ctx context.Context, id int64) (any, error) { return adminService.GetUser(ctx, id) })
+	svc.RegisterSnapshotLoader("groups", func(ctx context.Context, id int64) (any, error) { return adminService.GetGroup(ctx, id) })
+	svc.RegisterSnapshotLoader("accounts", func(ctx context.Context, id int64) (any, error) { return adminService.GetAccount(ctx, id) })
+	svc.RegisterSnapshotLoader("proxies", func(ctx context.Context, id int64) (any, error) { return adminService.GetProxy(ctx, id) })
+	svc.RegisterSnapshotLoader("redeem-codes", func(ctx context.Context, id int64) (any, error) { return adminService.GetRedeemCode(ctx, id) })
+	svc.RegisterSnapshotLoader("api-keys", func(ctx context.Context, id int64) (any, error) { return apiKeyService.GetByID(ctx, id) })
+	svc.RegisterSnapshotLoader("channels", func(ctx context.Context, id int64) (any, error) { return channelService.GetByID(ctx, id) })
+	svc.RegisterSnapshotLoader("organizations", func(ctx context.Context, id int64) (any, error) { return organizationService.Get(ctx, id) })
+	svc.RegisterSingletonSnapshotLoader("settings", func(ctx context.Context) (any, error) { return settingService.GetAllSettings(ctx) })
+	return svc
+}
+
 // ProviderSet is the Wire provider set for all services
 var ProviderSet = wire.NewSet(
 	// Core services
@@ -435,6 +539,7 @@ var ProviderSet = wire.NewSet(
 	NewUsageService,
 	NewDashboardService,
 	ProvidePricingService,
+	NewContextTrimmer,
 	NewBillingService,
 	ProvideBillingCacheService,
 	NewAnnouncementService,
@@ -463,6 +568,13 @@ var ProviderSet = wire.NewSet(
 	ProvideBackupService,
 	ProvideOpsSystemLogSink,
 	NewOpsService,
+	NewOpsRequestTraceService,
+	NewConversationStoreService,
+	NewGatewayIdempotencyService,
+	NewSecretLeakGuard,
+	NewUserAccountService,
+	NewOrganizationService,
+	ProvideAdminAuditService,
 	ProvideOpsMetricsCollector,
 	ProvideOpsAggregationService,
 	ProvideOpsAlertEvaluatorService,
@@ -475,14 +587,23 @@ var ProviderSet = wire.NewSet(
 	wire.Bind(new(DefaultSubscriptionAssigner), new(*SubscriptionService)),
 	ProvideConcurrencyService,
 	ProvideUserMessageQueueService,
+	NewUsageRecordJournal,
+	ProvideBillingDBHealthService,
 	NewUsageRecordWorkerPool,
+	ProvideUsageRecordJournalReplayer,
+	ProvideUsageEventExporter,
 	ProvideSchedulerSnapshotService,
+	NewAccountRotationService,
+	NewTrafficReplayService,
+	ProvideMessageBatchService,
+	NewModelCatalogService,
 	NewIdentityService,
 	NewCRSSyncService,
 	ProvideUpdateService,
 	ProvideTokenRefreshService,
 	ProvideAccountExpiryService,
 	ProvideSubscriptionExpiryService,
+	ProvideChildTokenExpiryService,
 	ProvideTimingWheelService,
 	ProvideDashboardAggregationService,
 	ProvideUsageCleanupService,
@@ -491,12 +612,13 @@ var ProviderSet = wire.NewSet(
 	NewUserAttributeService,
 	NewUsageCache,
 	NewTotpService,
-	NewErrorPassthroughService,
+	ProvideErrorPassthroughService,
 	NewTLSFingerprintProfileService,
 	NewDigestSessionStore,
 	ProvideIdempotencyCoordinator,
 	ProvideSystemOperationLockService,
 	ProvideIdempotencyCleanupService,
+	ProvideUsageAnomalyService,
 	ProvideScheduledTestService,
 	ProvideScheduledTestRunnerService,
 	NewGroupCapacityService,
@@ -507,6 +629,7 @@ var ProviderSet = wire.NewSet(
 	NewPaymentService,
 	ProvidePaymentOrderExpiryService,
 	ProvideBalanceNotifyService,
+	NewWebhookService,
 	ProvideChannelMonitorService,
 	ProvideChannelMonitorRunner,
 	NewChannelMonitorRequestTemplateService,
@@ -519,11 +642,20 @@ func ProvidePaymentConfigService(entClient *dbent.Client, settingRepo SettingRep
 }
 
 // ProvideBalanceNotifyService creates BalanceNotifyService
-func ProvideBalanceNotifyService(emailService *EmailService, settingRepo SettingRepository, accountRepo AccountRepository) *BalanceNotifyService {
-	return NewBalanceNotifyService(emailService, settingRepo, accountRepo)
+func ProvideBalanceNotifyService(emailService *EmailService, settingRepo SettingRepository, accountRepo AccountRepository, webhookService *WebhookService) *BalanceNotifyService {
+	svc := NewBalanceNotifyService(emailService, settingRepo, accountRepo)
+	svc.SetWebhookService(webhookService)
+	return svc
 }
 
 // ProvidePaymentOrderExpiryService creates and starts PaymentOrderExpiryService.
+// ProvideMessageBatchService 创建 Messages Batches 服务并启动后台执行（未启用时不启动）
+func ProvideMessageBatchService(repo MessageBatchRepository, apiKeyRepo APIKeyRepository, cfg *config.Config) *MessageBatchService {
+	svc := NewMessageBatchService(repo, apiKeyRepo, cfg)
+	svc.Start()
+	return svc
+}
+
 func ProvidePaymentOrderExpiryService(paymentSvc *PaymentService) *PaymentOrderExpiryService {
 	svc := NewPaymentOrderExpiryService(paymentSvc, 60*time.Second)
 	svc.Start()
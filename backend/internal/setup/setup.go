@@ -37,6 +37,11 @@ func setupDefaultAdminConcurrency() int {
 	return defaultUserConcurrency
 }
 
+// setupSkipsRedis concurrency.backend=memory（环境变量 CONCURRENCY_BACKEND）时服务不连接 Redis，安装阶段跳过 Redis 连接测试
+func setupSkipsRedis() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("CONCURRENCY_BACKEND")), config.ConcurrencyBackendMemory)
+}
+
 // GetDataDir returns the data directory for storing config and lock files.
 // Priority: DATA_DIR env > /app/data (if exists and writable) > current directory
 func GetDataDir() string {
@@ -294,8 +299,10 @@ func Install(cfg *SetupConfig) error {
 		return fmt.Errorf("database connection failed: %w", err)
 	}
 
-	if err := TestRedisConnection(&cfg.Redis); err != nil {
-		return fmt.Errorf("redis connection failed: %w", err)
+	if !setupSkipsRedis() {
+		if err := TestRedisConnection(&cfg.Redis); err != nil {
+			return fmt.Errorf("redis connection failed: %w", err)
+		}
 	}
 
 	// Initialize database
@@ -594,11 +601,15 @@ func AutoSetupFromEnv() error {
 	logger.LegacyPrintf("setup", "%s", "Database connection successful")
 
 	// Test Redis connection
-	logger.LegacyPrintf("setup", "%s", "Testing Redis connection...")
-	if err := TestRedisConnection(&cfg.Redis); err != nil {
-		return fmt.Errorf("redis connection failed: %w", err)
+	if setupSkipsRedis() {
+		logger.LegacyPrintf("setup", "%s", "Skipping Redis connection test (concurrency.backend=memory)")
+	} else {
+		logger.LegacyPrintf("setup", "%s", "Testing Redis connection...")
+		if err := TestRedisConnection(&cfg.Redis); err != nil {
+			return fmt.Errorf("redis connection failed: %w", err)
+		}
+		logger.LegacyPrintf("setup", "%s", "Redis connection successful")
 	}
-	logger.LegacyPrintf("setup", "%s", "Redis connection successful")
 
 	// Initialize database
 	logger.LegacyPrintf("setup", "%s", "Initializing database...")
@@ -70,6 +70,17 @@ func TestSetupDefaultAdminConcurrency(t *testing.T) {
 	})
 }
 
+func TestSetupSkipsRedis(t *testing.T) {
+	t.Setenv("CONCURRENCY_BACKEND", "memory")
+	if !setupSkipsRedis() {
+		t.Fatal("setupSkipsRedis()=false, want true for memory backend")
+	}
+	t.Setenv("CONCURRENCY_BACKEND", "redis")
+	if setupSkipsRedis() {
+		t.Fatal("setupSkipsRedis()=true, want false for redis backend")
+	}
+}
+
 func TestWriteConfigFileKeepsDefaultUserConcurrency(t *testing.T) {
 	t.Setenv("RUN_MODE", "simple")
 	t.Setenv("DATA_DIR", t.TempDir())
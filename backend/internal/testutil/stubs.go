@@ -79,6 +79,18 @@ func (c StubConcurrencyCache) CleanupExpiredAccountSlots(_ context.Context, _ in
 func (c StubConcurrencyCache) CleanupStaleProcessSlots(_ context.Context, _ string) error {
 	return nil
 }
+func (c StubConcurrencyCache) ListSlotOccupancy(context.Context) ([]service.SlotOccupancy, error) {
+	return nil, nil
+}
+func (c StubConcurrencyCache) AddPriorityWaiter(_ context.Context, _ string, _ int64, _ int, _ string) error {
+	return nil
+}
+func (c StubConcurrencyCache) RemovePriorityWaiter(_ context.Context, _ string, _ int64, _ int, _ string) error {
+	return nil
+}
+func (c StubConcurrencyCache) HasHigherPriorityWaiters(_ context.Context, _ string, _ int64, _ int) (bool, error) {
+	return false, nil
+}
 
 // ============================================================
 // StubGatewayCache — service.GatewayCache 的空实现
@@ -44,6 +44,8 @@ var hopByHopHeaders = map[string]struct{}{
 type CompiledHeaderFilter struct {
 	allowed     map[string]struct{}
 	forceRemove map[string]struct{}
+	// extraAllowed 额外放行的头部（小写），以 "*" 结尾的条目按前缀匹配
+	extraAllowed []string
 }
 
 var defaultCompiledHeaderFilter = CompileHeaderFilter(config.ResponseHeaderConfig{})
@@ -82,6 +84,49 @@ func CompileHeaderFilter(cfg config.ResponseHeaderConfig) *CompiledHeaderFilter
 	}
 }
 
+// WithExtraAllowed 返回在当前过滤器基础上额外放行指定头部的新过滤器，原过滤器不变。
+// 条目不区分大小写，以 "*" 结尾时按前缀匹配（如 "anthropic-ratelimit-*"）；force_remove 仍优先生效。
+func (f *CompiledHeaderFilter) WithExtraAllowed(patterns []string) *CompiledHeaderFilter {
+	if f == nil {
+		f = defaultCompiledHeaderFilter
+	}
+	if len(patterns) == 0 {
+		return f
+	}
+	extra := make([]string, 0, len(f.extraAllowed)+len(patterns))
+	extra = append(extra, f.extraAllowed...)
+	for _, pattern := range patterns {
+		normalized := strings.ToLower(strings.TrimSpace(pattern))
+		if normalized == "" || normalized == "*" {
+			continue
+		}
+		extra = append(extra, normalized)
+	}
+	return &CompiledHeaderFilter{
+		allowed:      f.allowed,
+		forceRemove:  f.forceRemove,
+		extraAllowed: extra,
+	}
+}
+
+func (f *CompiledHeaderFilter) isAllowed(lower string) bool {
+	if _, ok := f.allowed[lower]; ok {
+		return true
+	}
+	for _, pattern := range f.extraAllowed {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(lower, prefix) {
+				return true
+			}
+			continue
+		}
+		if lower == pattern {
+			return true
+		}
+	}
+	return false
+}
+
 func FilterHeaders(src http.Header, filter *CompiledHeaderFilter) http.Header {
 	if filter == nil {
 		filter = defaultCompiledHeaderFilter
@@ -93,7 +138,7 @@ func FilterHeaders(src http.Header, filter *CompiledHeaderFilter) http.Header {
 		if _, blocked := filter.forceRemove[lower]; blocked {
 			continue
 		}
-		if _, ok := filter.allowed[lower]; !ok {
+		if !filter.isAllowed(lower) {
 			continue
 		}
 		// 跳过 hop-by-hop 头部，这些由 HTTP 库自动处理
@@ -107,9 +152,18 @@ func FilterHeaders(src http.Header, filter *CompiledHeaderFilter) http.Header {
 	return filtered
 }
 
+// UpstreamRequestIDHeader 上游返回的 X-Request-Id 在网关已设置自身请求 ID 时改用该头透传
+const UpstreamRequestIDHeader = "X-Upstream-Request-Id"
+
+// WriteFilteredHeaders 将过滤后的上游响应头写入 dst。
+// dst 已有网关生成的 X-Request-Id 时不再追加上游的值（避免出现两个不同的请求 ID），
+// 上游请求 ID 改写到 X-Upstream-Request-Id。
 func WriteFilteredHeaders(dst http.Header, src http.Header, filter *CompiledHeaderFilter) {
 	filtered := FilterHeaders(src, filter)
 	for key, values := range filtered {
+		if strings.EqualFold(key, "x-request-id") && dst.Get(key) != "" {
+			key = UpstreamRequestIDHeader
+		}
 		for _, value := range values {
 			dst.Add(key, value)
 		}
@@ -65,3 +65,60 @@ func TestFilterHeadersEnabledUsesAllowlist(t *testing.T) {
 		t.Fatalf("expected X-Blocked removed, got %q", filtered.Get("X-Blocked"))
 	}
 }
+
+func TestFilterHeadersWithExtraAllowed(t *testing.T) {
+	src := http.Header{}
+	src.Add("Content-Type", "application/json")
+	src.Add("Anthropic-Ratelimit-Requests-Remaining", "99")
+	src.Add("Anthropic-Ratelimit-Tokens-Reset", "2026-01-01T00:00:00Z")
+	src.Add("X-Provider-Region", "us")
+	src.Add("X-Remove", "nope")
+	src.Add("X-Other", "nope")
+
+	base := CompileHeaderFilter(config.ResponseHeaderConfig{
+		Enabled:     true,
+		ForceRemove: []string{"x-remove"},
+	})
+	filtered := FilterHeaders(src, base.WithExtraAllowed([]string{"anthropic-ratelimit-*", "X-Provider-Region", "x-remove"}))
+
+	if filtered.Get("Anthropic-Ratelimit-Requests-Remaining") != "99" {
+		t.Fatalf("expected prefix-matched header allowed, got %q", filtered.Get("Anthropic-Ratelimit-Requests-Remaining"))
+	}
+	if filtered.Get("Anthropic-Ratelimit-Tokens-Reset") == "" {
+		t.Fatalf("expected prefix-matched header allowed")
+	}
+	if filtered.Get("X-Provider-Region") != "us" {
+		t.Fatalf("expected exact header allowed, got %q", filtered.Get("X-Provider-Region"))
+	}
+	if filtered.Get("X-Remove") != "" {
+		t.Fatalf("expected force_remove to win over extra allowed, got %q", filtered.Get("X-Remove"))
+	}
+	if filtered.Get("X-Other") != "" {
+		t.Fatalf("expected X-Other removed, got %q", filtered.Get("X-Other"))
+	}
+	// 原过滤器不受影响
+	if FilterHeaders(src, base).Get("X-Provider-Region") != "" {
+		t.Fatalf("expected base filter unchanged")
+	}
+}
+
+func TestWriteFilteredHeadersKeepsGatewayRequestID(t *testing.T) {
+	src := http.Header{}
+	src.Add("X-Request-Id", "upstream-1")
+
+	dst := http.Header{}
+	WriteFilteredHeaders(dst, src, nil)
+	if got := dst.Values("X-Request-Id"); len(got) != 1 || got[0] != "upstream-1" {
+		t.Fatalf("expected upstream X-Request-Id passthrough without gateway id, got %v", got)
+	}
+
+	dst = http.Header{}
+	dst.Set("X-Request-ID", "gateway-1")
+	WriteFilteredHeaders(dst, src, nil)
+	if got := dst.Values("X-Request-Id"); len(got) != 1 || got[0] != "gateway-1" {
+		t.Fatalf("expected gateway X-Request-Id kept, got %v", got)
+	}
+	if got := dst.Get(UpstreamRequestIDHeader); got != "upstream-1" {
+		t.Fatalf("expected upstream id in %s, got %q", UpstreamRequestIDHeader, got)
+	}
+}
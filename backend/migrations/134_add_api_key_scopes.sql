@@ -0,0 +1,8 @@
+-- Add permission scopes to api_keys table
+-- scopes: JSON array of granted scopes; NULL/empty keeps the legacy all-access behaviour.
+--   endpoint scopes: chat, images, models, usage
+--   platform scopes: platform:anthropic, platform:openai, platform:gemini, platform:antigravity
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN api_keys.scopes IS 'JSON array of permission scopes, e.g. ["chat", "platform:openai"]; empty = unrestricted';
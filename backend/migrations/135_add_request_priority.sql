@@ -0,0 +1,9 @@
+-- Add concurrency wait-queue priority to groups and api_keys
+-- groups.priority: high/normal/low, default normal
+-- api_keys.priority: high/normal/low; empty string inherits the group's priority
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT 'normal';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN groups.priority IS 'Concurrency wait-queue priority: high/normal/low';
+COMMENT ON COLUMN api_keys.priority IS 'Concurrency wait-queue priority: high/normal/low (empty = inherit from group)';
@@ -0,0 +1,6 @@
+-- Add per-group request parameter policy
+-- groups.request_param_overrides: default/forced temperature, top_p, reasoning_effort and max_tokens cap
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS request_param_overrides JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN groups.request_param_overrides IS 'Request parameter policy: default/forced temperature, top_p, reasoning_effort and max_tokens cap';
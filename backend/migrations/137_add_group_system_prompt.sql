@@ -0,0 +1,9 @@
+-- Add group-level system prompt injection
+-- groups.system_prompt: text injected into the client's system message (empty = disabled)
+-- groups.system_prompt_mode: prepend/append, default prepend
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS system_prompt TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS system_prompt_mode VARCHAR(10) NOT NULL DEFAULT 'prepend';
+
+COMMENT ON COLUMN groups.system_prompt IS 'Group system prompt injected into every request (empty = disabled)';
+COMMENT ON COLUMN groups.system_prompt_mode IS 'Group system prompt position: prepend/append';
@@ -0,0 +1,9 @@
+-- Add opt-in PII redaction for outbound prompts
+-- groups.pii_redaction_mode: empty = disabled, mask = replace matches before forwarding, reject = refuse the request
+-- usage_logs.pii_redactions: number of matches masked in the request content
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS pii_redaction_mode VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE usage_logs ADD COLUMN IF NOT EXISTS pii_redactions INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN groups.pii_redaction_mode IS 'PII redaction policy: empty (disabled)/mask/reject';
+COMMENT ON COLUMN usage_logs.pii_redactions IS 'Number of PII matches masked in the request content';
@@ -0,0 +1,9 @@
+-- Add content moderation before forwarding
+-- groups.moderation_enabled: run the moderation stage for requests in this group
+-- api_keys.moderation_mode: per-key override, enabled/disabled (empty = inherit from group)
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS moderation_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS moderation_mode VARCHAR(10) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN groups.moderation_enabled IS 'Run content moderation before forwarding requests';
+COMMENT ON COLUMN api_keys.moderation_mode IS 'Content moderation override: enabled/disabled (empty = inherit from group)';
@@ -0,0 +1,6 @@
+-- Add per-proxy concurrency caps
+-- proxies.max_concurrency: maximum in-flight upstream requests through this proxy (0 = unlimited)
+
+ALTER TABLE proxies ADD COLUMN IF NOT EXISTS max_concurrency INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN proxies.max_concurrency IS 'Maximum in-flight upstream requests through this proxy (0 = unlimited)';
@@ -0,0 +1,6 @@
+-- Add per-key automatic context window trimming
+-- api_keys.context_auto_trim: drop the oldest messages when the estimated prompt exceeds the model context window
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS context_auto_trim BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN api_keys.context_auto_trim IS 'Drop oldest messages when the prompt exceeds the model context window';
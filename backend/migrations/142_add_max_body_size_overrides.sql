@@ -0,0 +1,8 @@
+-- Add per-key and per-group request body size limits
+-- api_keys.max_body_size / groups.max_body_size: 0 = inherit (key -> group -> endpoint class -> gateway.max_body_size)
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS max_body_size BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS max_body_size BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN api_keys.max_body_size IS 'Max request body size in bytes (0 = inherit from group/endpoint class)';
+COMMENT ON COLUMN groups.max_body_size IS 'Max request body size in bytes (0 = use endpoint class default)';
@@ -0,0 +1,11 @@
+-- Track per-rule hit statistics and support temporary error passthrough rules
+-- error_passthrough_rules.expires_at: rule stops matching and is auto-disabled after this time (NULL = never)
+-- error_passthrough_rules.hit_count / last_hit_at: accumulated matches, flushed periodically by each instance
+
+ALTER TABLE error_passthrough_rules ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+ALTER TABLE error_passthrough_rules ADD COLUMN IF NOT EXISTS hit_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE error_passthrough_rules ADD COLUMN IF NOT EXISTS last_hit_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN error_passthrough_rules.expires_at IS 'Rule expiry time; expired rules stop matching and are auto-disabled (NULL = never)';
+COMMENT ON COLUMN error_passthrough_rules.hit_count IS 'Accumulated number of matches';
+COMMENT ON COLUMN error_passthrough_rules.last_hit_at IS 'Time of the most recent match';
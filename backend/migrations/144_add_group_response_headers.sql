@@ -0,0 +1,6 @@
+-- Add per-group custom response headers
+-- groups.response_headers: static headers injected into gateway responses and extra upstream headers exposed to clients
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS response_headers JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN groups.response_headers IS 'Custom response headers: static headers and extra upstream headers to expose';
@@ -0,0 +1,8 @@
+-- Add per-user account ownership (self-service linked accounts)
+-- accounts.owner_user_id: user who linked the account; NULL means a shared platform account
+
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS owner_user_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_accounts_owner_user_id ON accounts (owner_user_id) WHERE owner_user_id IS NOT NULL;
+
+COMMENT ON COLUMN accounts.owner_user_id IS 'User who linked this account; only that user''s API keys may schedule it';
@@ -0,0 +1,29 @@
+-- Add organizations (team hierarchy above users) with delegated administration
+-- users.organization_id / users.org_role: membership and org-admin role
+-- groups.organization_id: groups owned by an organization
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id                 BIGSERIAL PRIMARY KEY,
+    name               VARCHAR(100)   NOT NULL,
+    description        TEXT           NOT NULL DEFAULT '',
+    status             VARCHAR(20)    NOT NULL DEFAULT 'active',
+    monthly_budget_usd DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    created_at         TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    deleted_at         TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS organizations_name_unique_active ON organizations (name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS organization_status ON organizations (status);
+CREATE INDEX IF NOT EXISTS organization_deleted_at ON organizations (deleted_at);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS organization_id BIGINT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS org_role VARCHAR(20) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_users_organization_id ON users (organization_id) WHERE organization_id IS NOT NULL;
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS organization_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_groups_organization_id ON groups (organization_id) WHERE organization_id IS NOT NULL;
+
+COMMENT ON COLUMN organizations.monthly_budget_usd IS 'Monthly spend cap for all members in USD (0 = unlimited)';
+COMMENT ON COLUMN users.org_role IS 'Role within the organization: member or admin';
+COMMENT ON COLUMN groups.organization_id IS 'Owning organization; NULL means a platform-wide group';
@@ -0,0 +1,42 @@
+-- Append-only audit trail for admin mutations (who changed what, from where, and the before/after state)
+
+CREATE TABLE IF NOT EXISTS admin_audit_logs (
+    id            BIGSERIAL PRIMARY KEY,
+    actor_user_id BIGINT       NOT NULL,
+    actor_email   VARCHAR(255) NOT NULL DEFAULT '',
+    auth_method   VARCHAR(20)  NOT NULL DEFAULT '',
+    method        VARCHAR(10)  NOT NULL,
+    path          VARCHAR(255) NOT NULL,
+    resource      VARCHAR(64)  NOT NULL DEFAULT '',
+    resource_id   BIGINT,
+    status_code   INT          NOT NULL DEFAULT 0,
+    ip            VARCHAR(64)  NOT NULL DEFAULT '',
+    user_agent    TEXT         NOT NULL DEFAULT '',
+    request_body  JSONB,
+    before_state  JSONB,
+    after_state   JSONB,
+    diff          JSONB,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_created_at ON admin_audit_logs (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_actor ON admin_audit_logs (actor_user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_resource ON admin_audit_logs (resource, resource_id, created_at DESC);
+
+CREATE OR REPLACE FUNCTION admin_audit_logs_reject_mutation()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    RAISE EXCEPTION 'admin_audit_logs is append-only';
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_admin_audit_logs_append_only ON admin_audit_logs;
+CREATE TRIGGER trg_admin_audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON admin_audit_logs
+    FOR EACH ROW EXECUTE FUNCTION admin_audit_logs_reject_mutation();
+
+COMMENT ON TABLE admin_audit_logs IS 'Append-only audit trail of admin mutations; UPDATE/DELETE are rejected by trigger';
+COMMENT ON COLUMN admin_audit_logs.path IS 'Route template (e.g. /api/v1/admin/accounts/:id)';
+COMMENT ON COLUMN admin_audit_logs.diff IS 'Changed top-level fields: {"field": {"before": ..., "after": ...}}; secrets are redacted';
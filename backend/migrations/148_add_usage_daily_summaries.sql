@@ -0,0 +1,37 @@
+-- Per-dimension daily usage summaries.
+-- Maintained by the dashboard aggregation job and filled right before raw usage_logs rows are pruned
+-- by retention, so per-user / per-key / per-account / per-model history survives raw log cleanup.
+-- group_id = 0 means "no group"; request_type is normalized (legacy rows resolved via stream/openai_ws_mode).
+
+CREATE TABLE IF NOT EXISTS usage_daily_summaries (
+    bucket_date           DATE           NOT NULL,
+    user_id               BIGINT         NOT NULL,
+    api_key_id            BIGINT         NOT NULL,
+    account_id            BIGINT         NOT NULL,
+    group_id              BIGINT         NOT NULL DEFAULT 0,
+    model                 VARCHAR(100)   NOT NULL,
+    requested_model       VARCHAR(100)   NOT NULL,
+    upstream_model        VARCHAR(100)   NOT NULL,
+    request_type          SMALLINT       NOT NULL,
+    stream                BOOLEAN        NOT NULL,
+    billing_type          SMALLINT       NOT NULL,
+    total_requests        BIGINT         NOT NULL DEFAULT 0,
+    input_tokens          BIGINT         NOT NULL DEFAULT 0,
+    output_tokens         BIGINT         NOT NULL DEFAULT 0,
+    cache_creation_tokens BIGINT         NOT NULL DEFAULT 0,
+    cache_read_tokens     BIGINT         NOT NULL DEFAULT 0,
+    total_cost            DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    actual_cost           DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    account_cost          DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    total_duration_ms     BIGINT         NOT NULL DEFAULT 0,
+    computed_at           TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (bucket_date, user_id, api_key_id, account_id, group_id, model, requested_model, upstream_model, request_type, stream, billing_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_daily_summaries_user ON usage_daily_summaries (user_id, bucket_date);
+CREATE INDEX IF NOT EXISTS idx_usage_daily_summaries_api_key ON usage_daily_summaries (api_key_id, bucket_date);
+CREATE INDEX IF NOT EXISTS idx_usage_daily_summaries_account ON usage_daily_summaries (account_id, bucket_date);
+CREATE INDEX IF NOT EXISTS idx_usage_daily_summaries_group ON usage_daily_summaries (group_id, bucket_date);
+
+COMMENT ON TABLE usage_daily_summaries IS 'Per-dimension daily usage rollup (dates in the app timezone); outlives raw usage_logs retention.';
+COMMENT ON COLUMN usage_daily_summaries.account_cost IS 'SUM(COALESCE(account_stats_cost, total_cost) * COALESCE(account_rate_multiplier, 1))';
@@ -0,0 +1,35 @@
+-- Per-dimension hourly usage rollups (same dimensions as usage_daily_summaries).
+-- Maintained incrementally by the dashboard aggregation job; filtered dashboard trend / model stats
+-- queries read these instead of scanning raw usage_logs when the time range is hour/day aligned.
+
+CREATE TABLE IF NOT EXISTS usage_hourly_summaries (
+    bucket_start          TIMESTAMPTZ    NOT NULL,
+    user_id               BIGINT         NOT NULL,
+    api_key_id            BIGINT         NOT NULL,
+    account_id            BIGINT         NOT NULL,
+    group_id              BIGINT         NOT NULL DEFAULT 0,
+    model                 VARCHAR(100)   NOT NULL,
+    requested_model       VARCHAR(100)   NOT NULL,
+    upstream_model        VARCHAR(100)   NOT NULL,
+    request_type          SMALLINT       NOT NULL,
+    stream                BOOLEAN        NOT NULL,
+    billing_type          SMALLINT       NOT NULL,
+    total_requests        BIGINT         NOT NULL DEFAULT 0,
+    input_tokens          BIGINT         NOT NULL DEFAULT 0,
+    output_tokens         BIGINT         NOT NULL DEFAULT 0,
+    cache_creation_tokens BIGINT         NOT NULL DEFAULT 0,
+    cache_read_tokens     BIGINT         NOT NULL DEFAULT 0,
+    total_cost            DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    actual_cost           DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    account_cost          DECIMAL(20, 10) NOT NULL DEFAULT 0,
+    total_duration_ms     BIGINT         NOT NULL DEFAULT 0,
+    computed_at           TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (bucket_start, user_id, api_key_id, account_id, group_id, model, requested_model, upstream_model, request_type, stream, billing_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_hourly_summaries_user ON usage_hourly_summaries (user_id, bucket_start);
+CREATE INDEX IF NOT EXISTS idx_usage_hourly_summaries_api_key ON usage_hourly_summaries (api_key_id, bucket_start);
+CREATE INDEX IF NOT EXISTS idx_usage_hourly_summaries_account ON usage_hourly_summaries (account_id, bucket_start);
+CREATE INDEX IF NOT EXISTS idx_usage_hourly_summaries_group ON usage_hourly_summaries (group_id, bucket_start);
+
+COMMENT ON TABLE usage_hourly_summaries IS 'Per-dimension hourly usage rollup (hour buckets in the app timezone); retention follows dashboard_aggregation.retention.hourly_days.';
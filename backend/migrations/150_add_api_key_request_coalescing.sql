@@ -0,0 +1,6 @@
+-- Add per-key in-flight request coalescing
+-- api_keys.request_coalescing: identical concurrent non-stream requests share one upstream call
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS request_coalescing BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN api_keys.request_coalescing IS 'Coalesce identical concurrent non-stream requests onto one upstream call';
@@ -0,0 +1,9 @@
+-- Add per-key token bucket request smoothing
+-- api_keys.token_bucket_burst: bucket capacity in requests (0 = disabled)
+-- api_keys.token_bucket_refill_rate: refill rate in requests per second
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS token_bucket_burst INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS token_bucket_refill_rate DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN api_keys.token_bucket_burst IS 'Token bucket capacity in requests (0 = disabled)';
+COMMENT ON COLUMN api_keys.token_bucket_refill_rate IS 'Token bucket refill rate in requests per second';
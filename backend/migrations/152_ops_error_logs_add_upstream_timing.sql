@@ -0,0 +1,12 @@
+-- Add upstream network timing breakdown (httptrace) to ops error logs
+-- Values describe the last upstream attempt of the request; per-attempt timings live in upstream_errors[].timing.
+
+ALTER TABLE ops_error_logs ADD COLUMN IF NOT EXISTS upstream_dns_ms BIGINT;
+ALTER TABLE ops_error_logs ADD COLUMN IF NOT EXISTS upstream_connect_ms BIGINT;
+ALTER TABLE ops_error_logs ADD COLUMN IF NOT EXISTS upstream_tls_ms BIGINT;
+ALTER TABLE ops_error_logs ADD COLUMN IF NOT EXISTS upstream_first_byte_ms BIGINT;
+
+COMMENT ON COLUMN ops_error_logs.upstream_dns_ms IS 'DNS lookup time of the last upstream attempt (ms). NULL when no lookup happened (reused connection, IP host or proxy).';
+COMMENT ON COLUMN ops_error_logs.upstream_connect_ms IS 'TCP connect time of the last upstream attempt (ms). NULL when the connection was reused.';
+COMMENT ON COLUMN ops_error_logs.upstream_tls_ms IS 'TLS handshake time of the last upstream attempt (ms). NULL when the connection was reused or not TLS.';
+COMMENT ON COLUMN ops_error_logs.upstream_first_byte_ms IS 'Time from sending the last upstream request to the first response byte (ms).';
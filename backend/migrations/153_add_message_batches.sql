@@ -0,0 +1,46 @@
+-- Claude Messages Batches emulation
+-- message_batches: one row per batch, owned by the creating API key
+-- message_batch_requests: one row per batch request; result holds the Anthropic result object (JSON text)
+
+CREATE TABLE IF NOT EXISTS message_batches (
+    id                  VARCHAR(64) PRIMARY KEY,
+    user_id             BIGINT NOT NULL,
+    api_key_id          BIGINT NOT NULL,
+    processing_status   VARCHAR(20) NOT NULL DEFAULT 'in_progress',
+    client_ip           VARCHAR(64) NOT NULL DEFAULT '',
+    anthropic_beta      TEXT NOT NULL DEFAULT '',
+    results_url         TEXT NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at          TIMESTAMPTZ NOT NULL,
+    cancel_initiated_at TIMESTAMPTZ,
+    ended_at            TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_batches_api_key_created
+    ON message_batches (api_key_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_message_batches_active
+    ON message_batches (created_at) WHERE processing_status <> 'ended';
+CREATE INDEX IF NOT EXISTS idx_message_batches_ended_at
+    ON message_batches (ended_at) WHERE processing_status = 'ended';
+
+CREATE TABLE IF NOT EXISTS message_batch_requests (
+    batch_id        VARCHAR(64) NOT NULL REFERENCES message_batches(id) ON DELETE CASCADE,
+    idx             INTEGER NOT NULL,
+    custom_id       VARCHAR(64) NOT NULL,
+    params          TEXT NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending',
+    result          TEXT,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    claimed_at      TIMESTAMPTZ,
+    completed_at    TIMESTAMPTZ,
+    PRIMARY KEY (batch_id, idx)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_batch_requests_open
+    ON message_batch_requests (batch_id, idx) WHERE status IN ('pending', 'processing');
+
+COMMENT ON TABLE message_batches IS 'Emulated Claude Messages Batches; requests are executed through the regular /v1/messages path';
+COMMENT ON COLUMN message_batches.processing_status IS 'in_progress | canceling | ended';
+COMMENT ON COLUMN message_batch_requests.status IS 'pending | processing | succeeded | errored | canceled | expired';
+COMMENT ON COLUMN message_batch_requests.params IS 'Messages API request body of this batch request';
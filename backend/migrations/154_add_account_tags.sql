@@ -0,0 +1,14 @@
+-- Add account tags and tag selectors (dedicated capacity pinning)
+-- accounts.tags: free-form labels used by selectors
+-- accounts.tags_exclusive: account only serves selectors that require one of its tags
+-- groups/api_keys.account_tag_selector: {"require": [...], "exclude": [...]}
+
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS tags_exclusive BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS account_tag_selector JSONB NOT NULL DEFAULT '{}'::jsonb;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS account_tag_selector JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN accounts.tags IS 'Account tags matched by group / API key account_tag_selector';
+COMMENT ON COLUMN accounts.tags_exclusive IS 'When true, the account only serves selectors that require at least one of its tags';
+COMMENT ON COLUMN groups.account_tag_selector IS 'Accounts must carry all require tags and none of the exclude tags';
+COMMENT ON COLUMN api_keys.account_tag_selector IS 'Account tag selector combined with the group selector during scheduling';
@@ -0,0 +1,34 @@
+-- Move account tags from accounts.tags (JSONB) into a join table
+-- so that the admin account list can filter by tag and bulk tag operations
+-- touch only the affected (account, tag) rows.
+
+CREATE TABLE IF NOT EXISTS account_tags (
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    tag VARCHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_tags_tag ON account_tags (tag);
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'accounts' AND column_name = 'tags'
+    ) THEN
+        INSERT INTO account_tags (account_id, tag)
+        SELECT a.id, lower(btrim(t.tag))
+        FROM accounts a
+        CROSS JOIN LATERAL jsonb_array_elements_text(
+            CASE WHEN jsonb_typeof(a.tags) = 'array' THEN a.tags ELSE '[]'::jsonb END
+        ) AS t(tag)
+        WHERE btrim(t.tag) <> ''
+        ON CONFLICT DO NOTHING;
+    END IF;
+END $$;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS tags;
+
+COMMENT ON TABLE account_tags IS 'Free-form account tags (region, plan, owner, ...) used by admin filters and account_tag_selector routing';
+COMMENT ON COLUMN account_tags.tag IS 'Normalized tag: lowercase, no whitespace, at most 64 characters';
@@ -0,0 +1,6 @@
+-- Add per-key max estimated cost per request
+-- api_keys.max_cost_per_request: requests whose estimated cost (prompt tokens + max_tokens) exceeds this USD amount are rejected (0 = unlimited)
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS max_cost_per_request DECIMAL(20,8) NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN api_keys.max_cost_per_request IS 'Max estimated cost in USD for a single request (0 = unlimited)';
@@ -0,0 +1,7 @@
+-- Add per-key upstream platform allowlist
+-- api_keys.allowed_platforms: platforms the key may be routed to, checked before group resolution
+-- and applied to candidate accounts during scheduling (empty = unrestricted)
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_platforms JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMENT ON COLUMN api_keys.allowed_platforms IS 'Upstream platforms this key may be routed to (empty = unrestricted)';
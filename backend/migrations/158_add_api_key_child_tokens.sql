@@ -0,0 +1,11 @@
+-- Short-lived child tokens minted from an API key via POST /v1/tokens
+-- api_keys.parent_id: key the token was minted from (0 = regular key)
+-- api_keys.allowed_models: model patterns the key may request (empty = unrestricted)
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS parent_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS allowed_models JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_parent_id ON api_keys(parent_id) WHERE parent_id > 0 AND deleted_at IS NULL;
+
+COMMENT ON COLUMN api_keys.parent_id IS 'Parent key ID when this key is a short-lived child token (0 = regular key)';
+COMMENT ON COLUMN api_keys.allowed_models IS 'Model patterns this key may request (empty = unrestricted)';
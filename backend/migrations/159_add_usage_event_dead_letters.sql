@@ -0,0 +1,23 @@
+-- Dead-letter table for the usage event exporter (Kafka / NATS).
+-- Events that could not be published (retries exhausted, in-memory queue full,
+-- or still pending at shutdown) are stored here and redriven periodically,
+-- which gives downstream analytics pipelines at-least-once delivery.
+
+CREATE TABLE IF NOT EXISTS usage_event_dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(255) NOT NULL,
+    backend VARCHAR(16) NOT NULL,
+    payload JSONB NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_event_dead_letters_next_attempt
+    ON usage_event_dead_letters (next_attempt_at, id);
+
+COMMENT ON TABLE usage_event_dead_letters IS 'Usage events that failed to publish to the event bus; redriven by the usage event exporter';
+COMMENT ON COLUMN usage_event_dead_letters.event_id IS 'Stable event id (request_id:api_key_id) for downstream de-duplication';
+COMMENT ON COLUMN usage_event_dead_letters.reason IS 'Why the event was dead-lettered: publish_failed / queue_full / shutdown';
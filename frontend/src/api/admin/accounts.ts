@@ -444,6 +444,51 @@ export async function getAvailableModels(id: number): Promise<ClaudeModel[]> {
   return data
 }
 
+export interface ModelMappingSuggestion {
+  from: string
+  to: string
+  reason: 'exact' | 'alias'
+  current?: string
+}
+
+export interface ModelMappingImportResult {
+  account_id: number
+  upstream_models: string[]
+  suggestions: ModelMappingSuggestion[]
+  unmatched: string[]
+}
+
+/**
+ * Fetch the account's upstream model list and get model mapping suggestions
+ * @param id - Account ID
+ * @returns Upstream models and suggested mappings
+ */
+export async function suggestModelMapping(id: number): Promise<ModelMappingImportResult> {
+  const { data } = await apiClient.post<ModelMappingImportResult>(
+    `/admin/accounts/${id}/model-mapping/suggest`
+  )
+  return data
+}
+
+/**
+ * Accept model mapping entries in bulk
+ * @param id - Account ID
+ * @param mapping - Request model → upstream model
+ * @param replace - Replace the whole model_mapping instead of merging
+ * @returns Updated account
+ */
+export async function applyModelMapping(
+  id: number,
+  mapping: Record<string, string>,
+  replace = false
+): Promise<Account> {
+  const { data } = await apiClient.post<Account>(`/admin/accounts/${id}/model-mapping/apply`, {
+    mapping,
+    replace
+  })
+  return data
+}
+
 export interface CRSPreviewAccount {
   crs_account_id: string
   kind: string
@@ -653,6 +698,8 @@ export const accountsAPI = {
   resetTempUnschedulable,
   setSchedulable,
   getAvailableModels,
+  suggestModelMapping,
+  applyModelMapping,
   generateAuthUrl,
   exchangeCode,
   refreshOpenAIToken,
@@ -23,6 +23,9 @@ export interface ErrorPassthroughRule {
   custom_message: string | null
   skip_monitoring: boolean
   description: string | null
+  expires_at: string | null
+  hit_count: number
+  last_hit_at: string | null
   created_at: string
   updated_at: string
 }
@@ -44,6 +47,8 @@ export interface CreateRuleRequest {
   custom_message?: string | null
   skip_monitoring?: boolean
   description?: string | null
+  /** RFC3339; empty string clears the expiry */
+  expires_at?: string
 }
 
 /**
@@ -63,6 +68,8 @@ export interface UpdateRuleRequest {
   custom_message?: string | null
   skip_monitoring?: boolean
   description?: string | null
+  /** RFC3339; empty string clears the expiry */
+  expires_at?: string
 }
 
 /**
@@ -206,6 +206,8 @@ export interface OpsRequestDetailsParams {
   request_id?: string
   q?: string
 
+  status_code?: number
+
   min_duration_ms?: number
   max_duration_ms?: number
 
@@ -217,6 +219,70 @@ export interface OpsRequestDetailsParams {
 
 export type OpsRequestDetailsResponse = PaginatedResponse<OpsRequestDetail>
 
+export interface OpsRequestTraceLatencies {
+  auth_ms?: number | null
+  routing_ms?: number | null
+  upstream_ms?: number | null
+  response_ms?: number | null
+  time_to_first_token_ms?: number | null
+  duration_ms?: number | null
+}
+
+export interface OpsRequestTraceUsage {
+  usage_log_id: number
+  input_tokens: number
+  output_tokens: number
+  cache_creation_tokens: number
+  cache_read_tokens: number
+  total_cost: number
+  actual_cost: number
+}
+
+export interface OpsRequestTraceErrorLog {
+  id: number
+  created_at: string
+  phase: string
+  type: string
+  severity: string
+  status_code?: number | null
+  upstream_status_code?: number | null
+  message: string
+  account_id?: number | null
+  account_name?: string
+}
+
+export interface OpsRequestTrace {
+  request_id: string
+  client_request_id?: string
+  kind: OpsRequestKind
+  created_at: string
+
+  platform?: string
+  model?: string
+  requested_model?: string
+  upstream_model?: string
+  inbound_endpoint?: string
+  upstream_endpoint?: string
+  stream: boolean
+  status_code?: number | null
+
+  user_id?: number | null
+  api_key_id?: number | null
+  group_id?: number | null
+  account_id?: number | null
+  account_name?: string
+
+  latencies: OpsRequestTraceLatencies
+  usage?: OpsRequestTraceUsage | null
+
+  error_logs: OpsRequestTraceErrorLog[]
+  upstream_errors: OpsUpstreamErrorEvent[]
+
+  request_body?: string
+  request_body_truncated: boolean
+  request_body_bytes?: number | null
+}
+
 export interface OpsLatencyHistogramBucket {
   range: string
   count: number
@@ -259,6 +325,70 @@ export interface OpsErrorDistributionResponse {
   items: OpsErrorDistributionItem[]
 }
 
+export interface OpsFailoverTrendPoint {
+  bucket_start: string
+  failover_count: number
+  request_count: number
+}
+
+export interface OpsFailoverTrendResponse {
+  bucket: string
+  points: OpsFailoverTrendPoint[]
+}
+
+export interface OpsFailoverSummary {
+  failover_count: number
+  request_count: number
+  recovered_count: number
+  avg_switches_per_request: number
+}
+
+export interface OpsFailoverAccountItem {
+  account_id: number
+  account_name: string
+  platform: string
+  failover_count: number
+  request_count: number
+}
+
+export interface OpsFailoverPlatformItem {
+  platform: string
+  failover_count: number
+  request_count: number
+}
+
+export interface OpsFailoverStatusCodeItem {
+  status_code: number
+  failover_count: number
+}
+
+export interface OpsFailoverMessageItem {
+  message: string
+  failover_count: number
+  account_count: number
+  last_seen_at: string
+}
+
+export interface OpsFailoverStatsResponse {
+  start_time: string
+  end_time: string
+  top_n: number
+  summary: OpsFailoverSummary
+  by_account: OpsFailoverAccountItem[]
+  by_platform: OpsFailoverPlatformItem[]
+  by_status_code: OpsFailoverStatusCodeItem[]
+  top_messages: OpsFailoverMessageItem[]
+}
+
+export interface OpsFailoverParams {
+  time_range?: '5m' | '30m' | '1h' | '6h' | '24h'
+  start_time?: string
+  end_time?: string
+  platform?: string
+  group_id?: number | null
+  top_n?: number
+}
+
 export interface OpsDashboardSnapshotV2Response {
   generated_at: string
   overview: OpsDashboardOverview
@@ -994,6 +1124,12 @@ export interface OpsErrorDetail extends OpsErrorLog {
   response_latency_ms?: number | null
   time_to_first_token_ms?: number | null
 
+  // Upstream network timing breakdown of the last attempt (httptrace)
+  upstream_dns_ms?: number | null
+  upstream_connect_ms?: number | null
+  upstream_tls_ms?: number | null
+  upstream_first_byte_ms?: number | null
+
   request_body: string
   request_body_truncated: boolean
   request_body_bytes?: number | null
@@ -1122,6 +1258,28 @@ export async function getOpenAITokenStats(
   return data
 }
 
+export async function getFailoverTrend(
+  params: OpsFailoverParams,
+  options: OpsRequestOptions = {}
+): Promise<OpsFailoverTrendResponse> {
+  const { data } = await apiClient.get<OpsFailoverTrendResponse>('/admin/ops/dashboard/failover-trend', {
+    params,
+    signal: options.signal
+  })
+  return data
+}
+
+export async function getFailoverStats(
+  params: OpsFailoverParams,
+  options: OpsRequestOptions = {}
+): Promise<OpsFailoverStatsResponse> {
+  const { data } = await apiClient.get<OpsFailoverStatsResponse>('/admin/ops/dashboard/failover-stats', {
+    params,
+    signal: options.signal
+  })
+  return data
+}
+
 export type OpsErrorListView = 'errors' | 'excluded' | 'all'
 
 export type OpsErrorListQueryParams = {
@@ -1230,6 +1388,11 @@ export async function listRequestDetails(params: OpsRequestDetailsParams): Promi
   return data
 }
 
+export async function getRequestTrace(requestId: string): Promise<OpsRequestTrace> {
+  const { data } = await apiClient.get<OpsRequestTrace>(`/admin/ops/requests/${encodeURIComponent(requestId)}`)
+  return data
+}
+
 // Alert rules
 export async function listAlertRules(): Promise<AlertRule[]> {
   const { data } = await apiClient.get<AlertRule[]>('/admin/ops/alert-rules')
@@ -1371,6 +1534,8 @@ export const opsAPI = {
   getErrorTrend,
   getErrorDistribution,
   getOpenAITokenStats,
+  getFailoverTrend,
+  getFailoverStats,
   getConcurrencyStats,
   getUserConcurrencyStats,
   getAccountAvailabilityStats,
@@ -1397,6 +1562,7 @@ export const opsAPI = {
   listRequestErrorUpstreamErrors,
 
   listRequestDetails,
+  getRequestTrace,
   listAlertRules,
   createAlertRule,
   updateAlertRule,
@@ -468,10 +468,20 @@ export interface PaginationConfig {
 
 // ==================== API Key & Group Types ====================
 
-export type GroupPlatform = 'anthropic' | 'openai' | 'gemini' | 'antigravity'
+export type GroupPlatform = 'anthropic' | 'openai' | 'gemini' | 'antigravity' | 'custom'
+
+export type RequestPriority = 'high' | 'normal' | 'low'
 
 export type SubscriptionType = 'standard' | 'subscription'
 
+export type SystemPromptMode = 'prepend' | 'append'
+
+// 敏感信息过滤策略（空字符串表示关闭）
+export type PIIRedactionMode = '' | 'mask' | 'reject'
+
+// API Key 内容审核覆盖（空字符串表示继承分组）
+export type ModerationMode = '' | 'enabled' | 'disabled'
+
 export interface OpenAIMessagesDispatchModelConfig {
   opus_mapped_model?: string
   sonnet_mapped_model?: string
@@ -479,6 +489,27 @@ export interface OpenAIMessagesDispatchModelConfig {
   exact_model_mappings?: Record<string, string>
 }
 
+// Group-level request parameter policy applied before forwarding
+export interface RequestParamOverrides {
+  temperature?: number
+  top_p?: number
+  reasoning_effort?: 'minimal' | 'low' | 'medium' | 'high' | 'xhigh'
+  max_tokens_cap?: number // Clamp for max_tokens / max_output_tokens (0 = no cap)
+  force?: boolean // Override client values instead of only filling missing ones
+}
+
+// Group-level custom response headers
+export interface GroupResponseHeaders {
+  static?: Record<string, string> // Headers set on every gateway response of the group
+  expose_upstream?: string[] // Extra upstream headers to pass through; trailing "*" matches by prefix
+}
+
+// Account tag selector: accounts must carry every `require` tag and none of the `exclude` tags
+export interface AccountTagSelector {
+  require?: string[]
+  exclude?: string[]
+}
+
 export interface Group {
   id: number
   name: string
@@ -486,6 +517,7 @@ export interface Group {
   platform: GroupPlatform
   rate_multiplier: number
   rpm_limit?: number // Group-level RPM cap (0 = unlimited); overrides user-level rpm_limit when set
+  priority?: RequestPriority // Concurrency wait-queue priority (default normal)
   is_exclusive: boolean
   status: 'active' | 'inactive'
   subscription_type: SubscriptionType
@@ -530,6 +562,28 @@ export interface AdminGroup extends Group {
   default_mapped_model?: string
   messages_dispatch_model_config?: OpenAIMessagesDispatchModelConfig
 
+  // 请求参数策略
+  request_param_overrides?: RequestParamOverrides
+
+  // 分组系统提示词（空字符串表示不注入）
+  system_prompt?: string
+  system_prompt_mode?: SystemPromptMode
+
+  // 敏感信息过滤策略
+  pii_redaction_mode?: PIIRedactionMode
+
+  // 内容审核
+  moderation_enabled?: boolean
+
+  // 请求体最大字节数（0 表示使用端点类别默认值）
+  max_body_size?: number
+
+  // 自定义响应头（静态响应头与额外透传的上游响应头）
+  response_headers?: GroupResponseHeaders
+
+  // 账号标签选择器（require 全部命中、exclude 均不命中）
+  account_tag_selector?: AccountTagSelector
+
   // 分组排序
   sort_order: number
 }
@@ -543,6 +597,19 @@ export interface ApiKey {
   status: 'active' | 'inactive' | 'quota_exhausted' | 'expired'
   ip_whitelist: string[]
   ip_blacklist: string[]
+  scopes: string[] | null // Permission scopes, e.g. ['chat', 'platform:openai'] (empty = unrestricted)
+  priority?: RequestPriority | '' // Concurrency wait-queue priority ('' = inherit from group)
+  moderation_mode?: ModerationMode // Content moderation override ('' = inherit from group)
+  context_auto_trim?: boolean // Drop oldest messages when the prompt exceeds the model context window
+  max_body_size?: number // Max request body size in bytes (0 = inherit from group/endpoint class)
+  request_coalescing?: boolean // Coalesce identical concurrent non-stream requests onto one upstream call
+  token_bucket_burst?: number // Token bucket capacity in requests (0 = disabled)
+  token_bucket_refill_rate?: number // Token bucket refill rate in requests per second
+  max_cost_per_request?: number // Max estimated cost in USD for a single request (0 = unlimited)
+  account_tag_selector?: AccountTagSelector // Combined with the group selector during scheduling
+  allowed_platforms?: GroupPlatform[] | null // Upstream platforms this key may be routed to (empty = unrestricted)
+  parent_id?: number // Parent key ID for child tokens minted via POST /v1/tokens (0 = regular key)
+  allowed_models?: string[] | null // Model patterns this key may request (empty = unrestricted)
   last_used_at: string | null
   quota: number // Quota limit in USD (0 = unlimited)
   quota_used: number // Used quota amount in USD
@@ -570,11 +637,14 @@ export interface CreateApiKeyRequest {
   custom_key?: string // Optional custom API Key
   ip_whitelist?: string[]
   ip_blacklist?: string[]
+  scopes?: string[]
   quota?: number // Quota limit in USD (0 = unlimited)
   expires_in_days?: number // Days until expiry (null = never expires)
   rate_limit_5h?: number
   rate_limit_1d?: number
   rate_limit_7d?: number
+  max_cost_per_request?: number // Max estimated cost in USD per request (0 = unlimited)
+  allowed_platforms?: GroupPlatform[] // Empty = unrestricted
 }
 
 export interface UpdateApiKeyRequest {
@@ -583,6 +653,7 @@ export interface UpdateApiKeyRequest {
   status?: 'active' | 'inactive'
   ip_whitelist?: string[]
   ip_blacklist?: string[]
+  scopes?: string[] // Omit to keep, [] to clear
   quota?: number // Quota limit in USD (null = no change, 0 = unlimited)
   expires_at?: string | null // Expiration time (null = no change)
   reset_quota?: boolean // Reset quota_used to 0
@@ -590,6 +661,8 @@ export interface UpdateApiKeyRequest {
   rate_limit_1d?: number
   rate_limit_7d?: number
   reset_rate_limit_usage?: boolean
+  max_cost_per_request?: number // Max estimated cost in USD per request (0 = unlimited)
+  allowed_platforms?: GroupPlatform[] // Omit to keep, [] to clear
 }
 
 export interface CreateGroupRequest {
@@ -612,6 +685,15 @@ export interface CreateGroupRequest {
   supported_model_scopes?: string[]
   require_oauth_only?: boolean
   require_privacy_set?: boolean
+  priority?: RequestPriority
+  request_param_overrides?: RequestParamOverrides
+  system_prompt?: string
+  system_prompt_mode?: SystemPromptMode
+  pii_redaction_mode?: PIIRedactionMode
+  moderation_enabled?: boolean
+  max_body_size?: number
+  response_headers?: GroupResponseHeaders
+  account_tag_selector?: AccountTagSelector
   // 从指定分组复制账号
   copy_accounts_from_group_ids?: number[]
 }
@@ -637,13 +719,22 @@ export interface UpdateGroupRequest {
   supported_model_scopes?: string[]
   require_oauth_only?: boolean
   require_privacy_set?: boolean
+  priority?: RequestPriority
+  request_param_overrides?: RequestParamOverrides
+  system_prompt?: string
+  system_prompt_mode?: SystemPromptMode
+  pii_redaction_mode?: PIIRedactionMode
+  moderation_enabled?: boolean
+  max_body_size?: number
+  response_headers?: GroupResponseHeaders
+  account_tag_selector?: AccountTagSelector
   copy_accounts_from_group_ids?: number[]
 }
 
 // ==================== Account & Proxy Types ====================
 
-export type AccountPlatform = 'anthropic' | 'openai' | 'gemini' | 'antigravity'
-export type AccountType = 'oauth' | 'setup-token' | 'apikey' | 'upstream' | 'bedrock' | 'service_account'
+export type AccountPlatform = 'anthropic' | 'openai' | 'gemini' | 'antigravity' | 'custom'
+export type AccountType = 'oauth' | 'setup-token' | 'apikey' | 'upstream' | 'bedrock' | 'service_account' | 'ollama' | 'azure'
 export type OAuthAddMethod = 'oauth' | 'setup-token'
 export type ProxyProtocol = 'http' | 'https' | 'socks5' | 'socks5h'
 
@@ -751,6 +842,21 @@ export interface TempUnschedulableRule {
   description: string
 }
 
+// Per-account upstream request body rewrite rule (stored in extra.body_transform_rules)
+export interface BodyTransformRule {
+  op: 'set' | 'default' | 'delete' | 'rename'
+  path: string
+  to?: string
+  value?: unknown
+}
+
+// Recurring per-account maintenance window (stored in extra.maintenance_windows).
+// Starts at each cron trigger (evaluated in extra.maintenance_timezone, default UTC) and lasts duration_minutes.
+export interface AccountMaintenanceWindow {
+  cron: string
+  duration_minutes: number
+}
+
 export interface TempUnschedulableState {
   until_unix: number
   triggered_at_unix: number
@@ -765,6 +871,30 @@ export interface TempUnschedulableStatus {
   state?: TempUnschedulableState
 }
 
+export interface UpstreamRateLimitWindow {
+  limit: number
+  remaining: number
+  reset_at?: string
+}
+
+export interface UpstreamQuotaSummary {
+  entitlement: number
+  remaining_percent: number
+  overage?: number
+  overage_allowed?: boolean
+  unlimited?: boolean
+  reset_at?: string
+}
+
+export interface UpstreamRateLimitSnapshot {
+  requests?: UpstreamRateLimitWindow
+  tokens?: UpstreamRateLimitWindow
+  input_tokens?: UpstreamRateLimitWindow
+  output_tokens?: UpstreamRateLimitWindow
+  quotas?: Record<string, UpstreamQuotaSummary> // Copilot quota snapshots keyed by quota name
+  sampled_at: string
+}
+
 export interface Account {
   id: number
   name: string
@@ -776,6 +906,8 @@ export interface Account {
   extra?: (CodexUsageSnapshot & OpenAICompactState & {
     model_rate_limits?: Record<string, { rate_limited_at: string; rate_limit_reset_at: string }>
     antigravity_credits_overages?: Record<string, { activated_at: string; active_until: string }>
+    maintenance_windows?: AccountMaintenanceWindow[]
+    maintenance_timezone?: string
   } & Record<string, unknown>)
   proxy_id: number | null
   concurrency: number
@@ -796,17 +928,28 @@ export interface Account {
 
   // Rate limit & scheduling fields
   schedulable: boolean
+  // User who linked this account (self-service); absent for shared accounts
+  owner_user_id?: number | null
+  // Account tags; exclusive accounts only serve selectors that require one of their tags
+  tags?: string[] | null
+  tags_exclusive?: boolean
   rate_limited_at: string | null
   rate_limit_reset_at: string | null
   overload_until: string | null
   temp_unschedulable_until: string | null
   temp_unschedulable_reason: string | null
+  // Latest active cooldown end (rate limit / overload / temp unschedulable); auto re-enabled when it passes
+  cooldown_until?: string
+  cooldown_reason?: 'rate_limited' | 'overloaded' | 'temp_unschedulable' | 'maintenance'
 
   // Session window fields (5-hour window)
   session_window_start: string | null
   session_window_end: string | null
   session_window_status: 'allowed' | 'allowed_warning' | 'rejected' | null
 
+  // Latest rate-limit / quota headers reported by the upstream (Anthropic, OpenAI, Copilot)
+  upstream_rate_limit?: UpstreamRateLimitSnapshot
+
   // 5h窗口费用控制（仅 Anthropic OAuth/SetupToken 账号有效）
   window_cost_limit?: number | null
   window_cost_sticky_reserve?: number | null
@@ -970,6 +1113,8 @@ export interface CreateAccountRequest {
   group_ids?: number[]
   expires_at?: number | null
   auto_pause_on_expired?: boolean
+  tags?: string[]
+  tags_exclusive?: boolean
   confirm_mixed_channel_risk?: boolean
 }
 
@@ -989,9 +1134,31 @@ export interface UpdateAccountRequest {
   group_ids?: number[]
   expires_at?: number | null
   auto_pause_on_expired?: boolean
+  tags?: string[]
+  tags_exclusive?: boolean
   confirm_mixed_channel_risk?: boolean
 }
 
+// Account tag usage, returned by GET /admin/accounts/tags
+export interface AccountTagCount {
+  tag: string
+  account_count: number
+}
+
+// Bulk tag add/remove; when account_ids is empty the filters select the accounts
+export interface BulkUpdateAccountTagsRequest {
+  account_ids?: number[]
+  filters?: Record<string, unknown>
+  add?: string[]
+  remove?: string[]
+}
+
+export interface BulkUpdateAccountTagsResult {
+  account_count: number
+  added: number
+  removed: number
+}
+
 export interface CheckMixedChannelRequest {
   platform: AccountPlatform
   group_ids: number[]
@@ -1107,6 +1274,7 @@ export interface UsageLog {
   cache_read_tokens: number
   cache_creation_5m_tokens: number
   cache_creation_1h_tokens: number
+  cache_hit_ratio?: number
 
   input_cost: number
   output_cost: number
@@ -1133,6 +1301,9 @@ export interface UsageLog {
   // Cache TTL Override
   cache_ttl_overridden: boolean
 
+  // 敏感信息脱敏次数
+  pii_redactions?: number
+
   // 计费模式
   billing_mode?: string | null
 
@@ -1748,5 +1919,101 @@ export interface UpdateScheduledTestPlanRequest {
   auto_recover?: boolean
 }
 
+// ==================== Webhook Types ====================
+
+export type WebhookEventType =
+  | 'account.error'
+  | 'account.rate_limited'
+  | 'account.quota_threshold'
+  | 'account.recovered'
+  | 'usage.anomaly'
+
+export type WebhookFormat = 'json' | 'slack' | 'discord'
+
+export interface WebhookEndpoint {
+  name: string
+  url: string
+  enabled: boolean
+  format: WebhookFormat
+  secret: string
+  events: WebhookEventType[] // empty = all events
+}
+
+export interface WebhookConfig {
+  enabled: boolean
+  endpoints: WebhookEndpoint[]
+}
+
+// ==================== Traffic Replay Types ====================
+
+export type TrafficReplayStatus = 'running' | 'completed' | 'cancelled' | 'failed'
+
+export interface TrafficReplayInput {
+  target_account_id: number
+  target_model?: string
+  usage_log_ids?: number[]
+  source_account_id?: number
+  group_id?: number
+  model?: string
+  start_time?: string
+  end_time?: string
+  limit?: number
+  rate_per_minute?: number
+  max_input_tokens?: number
+  max_output_tokens?: number
+}
+
+export interface TrafficReplayMeasurement {
+  duration_ms: number
+  first_token_ms?: number
+  input_tokens: number
+  output_tokens: number
+}
+
+export interface TrafficReplaySample {
+  usage_log_id: number
+  model: string
+  stream: boolean
+  original: TrafficReplayMeasurement
+  replay: TrafficReplayMeasurement
+  status_code: number
+  success: boolean
+  error?: string
+}
+
+export interface TrafficReplayAggregate {
+  avg_duration_ms: number
+  p50_duration_ms: number
+  p95_duration_ms: number
+  avg_first_token_ms: number
+  total_input_tokens: number
+  total_output_tokens: number
+}
+
+export interface TrafficReplayReport {
+  total: number
+  completed: number
+  succeeded: number
+  failed: number
+  error_rate: number
+  original: TrafficReplayAggregate
+  replay: TrafficReplayAggregate
+}
+
+export interface TrafficReplayJob {
+  id: string
+  status: TrafficReplayStatus
+  target_account_id: number
+  target_account_name: string
+  target_platform: string
+  input: TrafficReplayInput
+  report: TrafficReplayReport
+  samples: TrafficReplaySample[] | null
+  error?: string
+  requested_by: number
+  created_at: string
+  finished_at?: string
+}
+
 // Payment types
 export type { SubscriptionPlan, PaymentOrder, CheckoutInfoResponse } from './payment'